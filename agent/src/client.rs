@@ -1,9 +1,15 @@
-//! WebSocket client for agent-server communication.
+//! Client for agent-server communication, over WebSocket or HTTP long-polling.
 
 use anyhow::{Context, Result};
 use futures_util::{SinkExt, StreamExt};
 use serde::{Deserialize, Serialize};
-use tokio::time::{interval, Duration};
+use std::collections::{BTreeMap, HashMap};
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::{Arc, Mutex};
+use std::time::{SystemTime, UNIX_EPOCH};
+use tokio::sync::watch;
+use tokio::time::Duration;
 use tokio_tungstenite::{
     connect_async_with_config,
     tungstenite::{
@@ -11,12 +17,18 @@ use tokio_tungstenite::{
         http::header::{HeaderName, HeaderValue},
         Message as WsMessage,
     },
+    MaybeTlsStream, WebSocketStream,
 };
 use tracing::{debug, error, info, warn};
 
-use crate::config::AgentConfig;
-use crate::executor::CommandExecutor;
+use crate::config::{AgentConfig, Transport};
+use crate::executor::{CommandExecutor, ExecutionResult};
+use crate::limits::ResourceLimits;
+use crate::poll::{self, PollSession};
 use crate::system::SystemInfo;
+use crate::update::{self, UpdateChunk, UpdateOffer, Updater, AGENT_VERSION};
+use ring::digest::{digest, SHA256};
+use ring::rand::{SecureRandom, SystemRandom};
 
 /// Message structure for agent-server WebSocket communication.
 #[derive(Debug, Serialize, Deserialize)]
@@ -41,6 +53,20 @@ pub struct RegisterPayload {
     pub platform: String,
     /// Available command executors (sh, bash, powershell, etc.).
     pub executors: Vec<String>,
+    /// Version of the agent build.
+    pub version: String,
+    /// Operating system version.
+    pub os_version: String,
+    /// CPU architecture (x86_64, aarch64, etc.).
+    pub architecture: String,
+    /// Whether the agent runs as root or an administrator.
+    pub elevated: bool,
+    /// Directory domain the host is joined to, empty when not joined.
+    pub domain: String,
+    /// Security products detected on the host.
+    pub security_products: Vec<String>,
+    /// Script interpreters found on the host, with their version.
+    pub interpreters: BTreeMap<String, String>,
 }
 
 /// Payload for task execution requests from the server.
@@ -58,8 +84,156 @@ pub struct TaskPayload {
     pub timeout: Option<u64>,
     /// Optional cleanup command to run after execution.
     pub cleanup: Option<String>,
+    /// Optional CPU and memory limits enforced while the command runs.
+    pub limits: Option<ResourceLimits>,
+    /// W3C trace context of the dispatch, echoed back with the result.
+    pub trace_context: Option<HashMap<String, String>>,
+    /// Optional payload the command downloads from the server.
+    #[serde(default)]
+    pub payload: Option<TaskFile>,
+    /// Files uploaded as artifacts of the result once the command has run.
+    #[serde(default)]
+    pub collect: Vec<String>,
 }
 
+/// Payload file delivered with a task, downloaded by the agent before the command runs.
+#[derive(Debug, Deserialize)]
+pub struct TaskFile {
+    /// Payload name.
+    pub name: String,
+    /// Hex SHA-256 of the payload content.
+    pub sha256: String,
+    /// Payload size in bytes.
+    pub size: u64,
+    /// Download path, scoped to the agent and task and valid for a limited time.
+    pub url: String,
+}
+
+impl TaskFile {
+    /// Downloads the payload into dir with the agent credentials, checks its
+    /// size and SHA-256, and returns the path of the file.
+    pub async fn download(&self, config: &AgentConfig, dir: &Path) -> Result<PathBuf> {
+        let http = reqwest::Client::builder()
+            .danger_accept_invalid_certs(!config.tls.verify)
+            .timeout(PAYLOAD_DOWNLOAD_TIMEOUT)
+            .build()
+            .context("Failed to build the HTTP client")?;
+        let url = format!("{}{}", config.server_url.trim_end_matches('/'), self.url);
+        let mut request = http.get(&url).header("X-Agent-Paw", &config.paw);
+        if let Some(ref secret) = config.agent_secret {
+            request = request.header("X-Agent-Key", secret);
+        }
+        let response = request
+            .send()
+            .await
+            .context("Payload request failed")?
+            .error_for_status()
+            .context("Payload refused by the server")?;
+        let data = response
+            .bytes()
+            .await
+            .context("Failed to read the payload")?;
+        self.verify(&data)?;
+
+        let name = Path::new(&self.name)
+            .file_name()
+            .ok_or_else(|| anyhow::anyhow!("Invalid payload name {}", self.name))?;
+        tokio::fs::create_dir_all(dir)
+            .await
+            .context("Failed to create the payload directory")?;
+        let path = dir.join(name);
+        tokio::fs::write(&path, &data)
+            .await
+            .context("Failed to write the payload")?;
+        Ok(path)
+    }
+
+    /// Checks downloaded content against the size and SHA-256 sent with the task.
+    pub fn verify(&self, data: &[u8]) -> Result<()> {
+        if data.len() as u64 != self.size {
+            anyhow::bail!(
+                "Payload {} is {} bytes, expected {}",
+                self.name,
+                data.len(),
+                self.size
+            );
+        }
+        let sum: String = digest(&SHA256, data)
+            .as_ref()
+            .iter()
+            .map(|b| format!("{:02x}", b))
+            .collect();
+        if !sum.eq_ignore_ascii_case(&self.sha256) {
+            anyhow::bail!("Payload {} SHA-256 mismatch", self.name);
+        }
+        Ok(())
+    }
+
+    /// Replaces the payload variables of a command: #{payload.path} (the
+    /// downloaded file), #{payload.url} (its file URL), #{payload.name} and
+    /// #{payload.sha256}.
+    pub fn substitute(&self, command: &str, path: &Path) -> String {
+        command
+            .replace("#{payload.path}", &path.to_string_lossy())
+            .replace("#{payload.url}", &file_url(path))
+            .replace("#{payload.name}", &self.name)
+            .replace("#{payload.sha256}", &self.sha256)
+    }
+}
+
+/// Returns the file URL of an absolute path, on Unix or Windows.
+fn file_url(path: &Path) -> String {
+    let path = path.to_string_lossy().replace('\\', "/");
+    if path.starts_with('/') {
+        format!("file://{}", path)
+    } else {
+        format!("file:///{}", path)
+    }
+}
+
+/// Longest a payload download may take.
+const PAYLOAD_DOWNLOAD_TIMEOUT: Duration = Duration::from_secs(120);
+
+/// Check-in interval and jitter, set by the server with a `beacon` message.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Deserialize)]
+pub struct Beacon {
+    /// Seconds between heartbeats.
+    pub interval: u64,
+    /// Percentage the interval is randomly varied by, either way.
+    #[serde(default)]
+    pub jitter: u64,
+}
+
+impl Beacon {
+    /// Returns the delay before the next heartbeat for a random sample: the
+    /// interval varied by up to the jitter percentage, at least one second.
+    pub fn delay(&self, sample: u32) -> Duration {
+        let base = self.interval.max(1) * 1000;
+        let spread = base * self.jitter.min(100) / 100;
+        let offset = u64::from(sample) % (2 * spread + 1);
+        Duration::from_millis((base + offset).saturating_sub(spread).max(1000))
+    }
+
+    /// Returns the delay before the next heartbeat, drawn at random.
+    pub fn next_delay(&self) -> Duration {
+        let mut bytes = [0u8; 4];
+        let sample = match SystemRandom::new().fill(&mut bytes) {
+            Ok(()) => u32::from_le_bytes(bytes),
+            Err(_) => 0,
+        };
+        self.delay(sample)
+    }
+}
+
+/// Largest file uploaded as an artifact, the server default; larger files are skipped.
+const MAX_ARTIFACT_SIZE: u64 = 256 * 1024;
+
+/// Most results in a `task_results` batch, the server limit.
+const MAX_RESULT_BATCH: usize = 100;
+
+/// Largest `task_results` batch, below the 512 KB message limit of the server.
+const MAX_RESULT_BATCH_BYTES: usize = 448 * 1024;
+
 /// WebSocket client for communicating with the AutoStrike server.
 pub struct AgentClient {
     /// Agent configuration.
@@ -68,17 +242,39 @@ pub struct AgentClient {
     pub sys_info: SystemInfo,
     /// Command executor instance.
     pub executor: CommandExecutor,
+    /// Receives the releases the server pushes for self-updates.
+    pub updater: Updater,
+    /// Current beacon, kept across reconnections.
+    pub beacon: watch::Sender<Beacon>,
+    /// Generation of the server cancels, bumped by each `cancel` message.
+    pub cancel: Arc<watch::Sender<u64>>,
+    /// Payloads of the results sent but not acknowledged yet, by task id,
+    /// resent in `task_results` batches after reconnecting.
+    pub unacked: Arc<Mutex<BTreeMap<String, serde_json::Value>>>,
+    /// Set while a resend of the results the server was too busy for is pending.
+    resend_scheduled: Arc<AtomicBool>,
 }
 
 impl AgentClient {
     /// Creates a new agent client with the given configuration and system info.
     pub fn new(config: AgentConfig, sys_info: SystemInfo) -> Result<Self> {
         let executor = CommandExecutor::new();
+        let updater = Updater::new(config.update_public_key.as_deref());
+        let (beacon, _) = watch::channel(Beacon {
+            interval: config.heartbeat_interval,
+            jitter: 0,
+        });
+        let (cancel, _) = watch::channel(0);
 
         Ok(Self {
             config,
             sys_info,
             executor,
+            updater,
+            beacon,
+            cancel: Arc::new(cancel),
+            unacked: Arc::new(Mutex::new(BTreeMap::new())),
+            resend_scheduled: Arc::new(AtomicBool::new(false)),
         })
     }
 
@@ -107,6 +303,89 @@ impl AgentClient {
     }
 
     async fn connect_and_run(&mut self) -> Result<()> {
+        if self.config.transport == Transport::Http {
+            return self.run_long_poll().await;
+        }
+
+        let ws_stream = match self.connect_websocket().await {
+            Ok(ws_stream) => ws_stream,
+            Err(e) if self.config.transport == Transport::Auto => {
+                warn!("{:#}, falling back to HTTP long-polling", e);
+                return self.run_long_poll().await;
+            }
+            Err(e) => return Err(e),
+        };
+
+        let (mut write, mut read) = ws_stream.split();
+
+        write
+            .send(WsMessage::Text(serde_json::to_string(
+                &self.register_message()?,
+            )?))
+            .await?;
+        info!("Registered with server");
+        for result in self.unacked_results() {
+            write.send(WsMessage::Text(result)).await?;
+        }
+
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+        self.spawn_heartbeat(tx.clone());
+
+        // Read on its own, so a cancel reaches the running task
+        let (inbox_tx, mut inbox) = tokio::sync::mpsc::channel::<(u64, AgentMessage)>(32);
+        let cancel = self.cancel.clone();
+        let reading = tokio::spawn(async move {
+            // Pings are answered by the WebSocket library while reading
+            while let Some(msg) = read.next().await {
+                match msg {
+                    Ok(WsMessage::Text(text)) => {
+                        match serde_json::from_str::<AgentMessage>(&text) {
+                            Ok(agent_msg) => {
+                                if inbox_tx.send(stamp(&cancel, agent_msg)).await.is_err() {
+                                    return;
+                                }
+                            }
+                            Err(e) => {
+                                warn!("Failed to parse message: {} - content: {}", e, text);
+                            }
+                        }
+                    }
+                    Ok(WsMessage::Close(_)) => {
+                        info!("Server closed connection");
+                        return;
+                    }
+                    Err(e) => {
+                        error!("WebSocket error: {}", e);
+                        return;
+                    }
+                    _ => {}
+                }
+            }
+        });
+
+        let outcome: Result<()> = async {
+            loop {
+                tokio::select! {
+                    Some(msg) = rx.recv() => {
+                        write.send(WsMessage::Text(msg)).await?;
+                    }
+                    msg = inbox.recv() => match msg {
+                        Some(msg) => self.dispatch(msg, &tx).await?,
+                        None => return Ok(()),
+                    }
+                }
+            }
+        }
+        .await;
+
+        reading.abort();
+        outcome
+    }
+
+    /// Opens the WebSocket connection to the server.
+    async fn connect_websocket(
+        &self,
+    ) -> Result<WebSocketStream<MaybeTlsStream<tokio::net::TcpStream>>> {
         let ws_url = self
             .config
             .server_url
@@ -129,10 +408,78 @@ impl AgentClient {
         let (ws_stream, _) = connect_async_with_config(request, None)
             .await
             .context("Failed to connect to server")?;
+        Ok(ws_stream)
+    }
 
-        let (mut write, mut read) = ws_stream.split();
+    /// Exchanges messages with the server over HTTP long-polling, until the
+    /// server closes the session or a request fails.
+    async fn run_long_poll(&mut self) -> Result<()> {
+        let session = Arc::new(PollSession::open(&self.config).await?);
+        info!(
+            "Connected to {} by HTTP long-polling",
+            poll::poll_url(&self.config.server_url)
+        );
+
+        session
+            .send(serde_json::to_string(&self.register_message()?)?)
+            .await?;
+        info!("Registered with server");
+        for result in self.unacked_results() {
+            session.send(result).await?;
+        }
+
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+        self.spawn_heartbeat(tx.clone());
 
-        let register_msg = AgentMessage {
+        // Polled on its own, so server messages keep arriving while a task runs
+        let (inbox_tx, mut inbox) = tokio::sync::mpsc::channel::<(u64, AgentMessage)>(32);
+        let poller = session.clone();
+        let cancel = self.cancel.clone();
+        let polling = tokio::spawn(async move {
+            loop {
+                match poller.poll().await {
+                    Ok(Some(messages)) => {
+                        for msg in messages {
+                            if inbox_tx.send(stamp(&cancel, msg)).await.is_err() {
+                                return;
+                            }
+                        }
+                    }
+                    Ok(None) => {
+                        info!("Server closed the long-poll session");
+                        return;
+                    }
+                    Err(e) => {
+                        error!("Long-poll error: {:#}", e);
+                        return;
+                    }
+                }
+            }
+        });
+
+        let outcome: Result<()> = async {
+            loop {
+                tokio::select! {
+                    Some(msg) = rx.recv() => {
+                        session.send(msg).await?;
+                    }
+                    msg = inbox.recv() => match msg {
+                        Some(msg) => self.dispatch(msg, &tx).await?,
+                        None => return Ok(()),
+                    }
+                }
+            }
+        }
+        .await;
+
+        polling.abort();
+        session.close().await;
+        outcome
+    }
+
+    /// Builds the registration message sent once connected.
+    fn register_message(&self) -> Result<AgentMessage> {
+        Ok(AgentMessage {
             msg_type: "register".to_string(),
             payload: serde_json::to_value(RegisterPayload {
                 paw: self.config.paw.clone(),
@@ -140,31 +487,95 @@ impl AgentClient {
                 username: self.sys_info.username.clone(),
                 platform: self.sys_info.platform.clone(),
                 executors: self.sys_info.executors.clone(),
+                version: AGENT_VERSION.to_string(),
+                os_version: self.sys_info.os_version.clone(),
+                architecture: self.sys_info.architecture.clone(),
+                elevated: self.sys_info.elevated,
+                domain: self.sys_info.domain.clone(),
+                security_products: self.sys_info.security_products.clone(),
+                interpreters: self.sys_info.interpreters.clone(),
             })?,
-        };
+        })
+    }
 
-        write
-            .send(WsMessage::Text(serde_json::to_string(&register_msg)?))
-            .await?;
-        info!("Registered with server");
+    /// Returns the `task_results` batches of the results the server has not
+    /// acknowledged, to resend them under their submission id once registered again.
+    fn unacked_results(&self) -> Vec<String> {
+        let unacked = self.unacked.lock().unwrap_or_else(|e| e.into_inner());
+        if !unacked.is_empty() {
+            info!("Resending {} unacknowledged task results", unacked.len());
+        }
+        result_batches(&unacked)
+    }
 
-        let heartbeat_interval = self.config.heartbeat_interval;
-        let paw = self.config.paw.clone();
+    /// Forgets a result the server acknowledged, whatever its status: a
+    /// failed result would fail again. A result the server was too busy to
+    /// take is kept and resent after the delay it asked for.
+    fn acknowledge(&self, ack: &serde_json::Value, tx: &tokio::sync::mpsc::Sender<String>) {
+        let task_id = ack["task_id"].as_str().unwrap_or_default();
+        let status = ack["status"].as_str().unwrap_or_default();
+        if status == "busy" {
+            self.resend_later(tx, ack["retry_after"].as_u64().unwrap_or_default());
+            return;
+        }
+        self.unacked
+            .lock()
+            .unwrap_or_else(|e| e.into_inner())
+            .remove(task_id);
+        match status {
+            "failed" => warn!(
+                "Result of task {} failed: {}",
+                task_id,
+                ack["error"].as_str().unwrap_or_default()
+            ),
+            status => debug!("Result of task {} acknowledged: {}", task_id, status),
+        }
+    }
 
-        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+    /// Resends the unacknowledged results once `retry_after` seconds elapsed,
+    /// when the result queue of the server was full. A single resend is
+    /// pending at a time, however many results were refused.
+    fn resend_later(&self, tx: &tokio::sync::mpsc::Sender<String>, retry_after: u64) {
+        if self.resend_scheduled.swap(true, Ordering::SeqCst) {
+            return;
+        }
+        warn!(
+            "Server is busy, resending results in {}s",
+            retry_after.max(1)
+        );
+        let unacked = self.unacked.clone();
+        let scheduled = self.resend_scheduled.clone();
+        let tx = tx.clone();
+        tokio::spawn(async move {
+            tokio::time::sleep(Duration::from_secs(retry_after.max(1))).await;
+            scheduled.store(false, Ordering::SeqCst);
+            let batches = result_batches(&unacked.lock().unwrap_or_else(|e| e.into_inner()));
+            for batch in batches {
+                if tx.send(batch).await.is_err() {
+                    break;
+                }
+            }
+        });
+    }
+
+    /// Sends a heartbeat at every beacon, until the connection is closed.
+    fn spawn_heartbeat(&self, tx: tokio::sync::mpsc::Sender<String>) {
+        let mut beacon = self.beacon.subscribe();
+        let paw = self.config.paw.clone();
 
-        let tx_heartbeat = tx.clone();
         tokio::spawn(async move {
-            let mut interval = interval(Duration::from_secs(heartbeat_interval));
             loop {
-                interval.tick().await;
                 let msg = AgentMessage {
                     msg_type: "heartbeat".to_string(),
-                    payload: serde_json::json!({ "paw": paw }),
+                    payload: serde_json::json!({
+                        "paw": paw,
+                        "version": AGENT_VERSION,
+                        "sent_at": unix_millis(),
+                    }),
                 };
                 match serde_json::to_string(&msg) {
                     Ok(json_str) => {
-                        if tx_heartbeat.send(json_str).await.is_err() {
+                        if tx.send(json_str).await.is_err() {
                             break;
                         }
                     }
@@ -173,46 +584,35 @@ impl AgentClient {
                         break;
                     }
                 }
-            }
-        });
-
-        loop {
-            tokio::select! {
-                Some(msg) = rx.recv() => {
-                    write.send(WsMessage::Text(msg)).await?;
-                }
 
-                msg = read.next() => {
-                    match msg {
-                        Some(Ok(WsMessage::Text(text))) => {
-                            match serde_json::from_str::<AgentMessage>(&text) {
-                                Ok(agent_msg) => {
-                                    self.handle_message(agent_msg, &tx).await?;
-                                }
-                                Err(e) => {
-                                    warn!("Failed to parse message: {} - content: {}", e, text);
-                                }
+                // Wait for the next check-in, starting over when the beacon changes
+                loop {
+                    let delay = beacon.borrow_and_update().next_delay();
+                    tokio::select! {
+                        _ = tokio::time::sleep(delay) => break,
+                        changed = beacon.changed() => {
+                            if changed.is_err() {
+                                return;
                             }
                         }
-                        Some(Ok(WsMessage::Ping(data))) => {
-                            write.send(WsMessage::Pong(data)).await?;
-                        }
-                        Some(Ok(WsMessage::Close(_))) => {
-                            info!("Server closed connection");
-                            break;
-                        }
-                        Some(Err(e)) => {
-                            error!("WebSocket error: {}", e);
-                            break;
-                        }
-                        None => break,
-                        _ => {}
                     }
                 }
             }
-        }
+        });
+    }
 
-        Ok(())
+    /// Handles a message stamped by `stamp`, dropping the tasks received
+    /// before a later cancel.
+    async fn dispatch(
+        &self,
+        (generation, msg): (u64, AgentMessage),
+        tx: &tokio::sync::mpsc::Sender<String>,
+    ) -> Result<()> {
+        if msg.msg_type == "task" && generation < *self.cancel.borrow() {
+            warn!("Dropped a task cancelled by the server");
+            return Ok(());
+        }
+        self.handle_message(msg, tx).await
     }
 
     /// Handles incoming messages from the server.
@@ -235,6 +635,75 @@ impl AgentClient {
                 };
                 tx.send(serde_json::to_string(&pong)?).await?;
             }
+            "server_shutdown" => {
+                // The connection loop reconnects once the server is back;
+                // resumable executions are re-dispatched after registration.
+                let resumable = msg
+                    .payload
+                    .get("resumable")
+                    .and_then(|v| v.as_bool())
+                    .unwrap_or(false);
+                info!(
+                    "Server is shutting down (resumable executions: {})",
+                    resumable
+                );
+            }
+            "cancel" => {
+                // Running commands were killed as the message arrived, see `stamp`
+                warn!(
+                    "Server cancelled running tasks: {}",
+                    msg.payload["reason"].as_str().unwrap_or_default()
+                );
+            }
+            "task_ack" => self.acknowledge(&msg.payload, tx),
+            "task_results_ack" => {
+                if let Some(error) = msg.payload["error"].as_str() {
+                    warn!("Task results rejected: {}", error);
+                }
+                if let Some(retry_after) = msg.payload["retry_after"].as_u64() {
+                    self.resend_later(tx, retry_after);
+                }
+                for ack in msg.payload["results"].as_array().into_iter().flatten() {
+                    self.acknowledge(ack, tx);
+                }
+            }
+            "artifact_ack" => {
+                let status = msg.payload["status"].as_str().unwrap_or_default();
+                if status == "stored" {
+                    debug!("Artifact {} stored", msg.payload["path"]);
+                } else {
+                    warn!(
+                        "Artifact {} rejected: {}",
+                        msg.payload["path"], msg.payload["error"]
+                    );
+                }
+            }
+            "beacon" => {
+                let beacon: Beacon = serde_json::from_value(msg.payload)?;
+                info!(
+                    "Beacon set to {}s with {}% jitter",
+                    beacon.interval, beacon.jitter
+                );
+                self.beacon.send_replace(beacon);
+            }
+            "update" => {
+                let offer: UpdateOffer = serde_json::from_value(msg.payload)?;
+                let version = offer.version.clone();
+                match self.updater.begin(offer) {
+                    Ok(()) => info!("Receiving agent release {}", version),
+                    Err(e) if !self.updater.enabled() => debug!("Update ignored: {}", e),
+                    Err(e) => Self::send_update_status(tx, &version, Err(e)).await?,
+                }
+            }
+            "update_chunk" => {
+                let chunk: UpdateChunk = serde_json::from_value(msg.payload)?;
+                let version = chunk.version.clone();
+                match self.updater.add_chunk(chunk) {
+                    Ok(None) => {}
+                    Ok(Some((version, binary))) => self.apply_update(&version, &binary, tx).await?,
+                    Err(e) => Self::send_update_status(tx, &version, Err(e)).await?,
+                }
+            }
             _ => {
                 warn!("Unknown message type: {}", msg.msg_type);
             }
@@ -243,6 +712,51 @@ impl AgentClient {
         Ok(())
     }
 
+    /// Installs a verified release, reports it and restarts the agent once the
+    /// report has had time to reach the server.
+    async fn apply_update(
+        &self,
+        version: &str,
+        binary: &[u8],
+        tx: &tokio::sync::mpsc::Sender<String>,
+    ) -> Result<()> {
+        let exe = match update::install(binary) {
+            Ok(exe) => exe,
+            Err(e) => return Self::send_update_status(tx, version, Err(e)).await,
+        };
+        info!("Installed agent release {}, restarting", version);
+        Self::send_update_status(tx, version, Ok(())).await?;
+
+        tokio::spawn(async move {
+            tokio::time::sleep(Duration::from_secs(1)).await;
+            if let Err(e) = update::restart(&exe) {
+                error!("{}", e);
+            }
+        });
+        Ok(())
+    }
+
+    /// Reports the outcome of a self-update to the server.
+    async fn send_update_status(
+        tx: &tokio::sync::mpsc::Sender<String>,
+        version: &str,
+        outcome: Result<()>,
+    ) -> Result<()> {
+        let payload = match outcome {
+            Ok(()) => serde_json::json!({ "version": version, "status": "installed" }),
+            Err(e) => {
+                warn!("Agent update {} failed: {}", version, e);
+                serde_json::json!({ "version": version, "status": "failed", "error": e.to_string() })
+            }
+        };
+        let msg = AgentMessage {
+            msg_type: "update_status".to_string(),
+            payload,
+        };
+        tx.send(serde_json::to_string(&msg)?).await?;
+        Ok(())
+    }
+
     /// Executes a task and sends the result back to the server.
     pub async fn execute_task(
         &self,
@@ -254,35 +768,211 @@ impl AgentClient {
             task.id, task.technique_id
         );
 
+        let mut command = task.command.clone();
+        let mut cleanup = task.cleanup.clone();
+        let mut payload_dir = None;
+        let mut payload_path = None;
+        let mut payload_error = None;
+        if let Some(ref payload) = task.payload {
+            debug!(
+                "Downloading task payload {} ({} bytes)",
+                payload.name, payload.size
+            );
+            let dir = std::env::temp_dir().join(format!("autostrike-payload-{}", task.id));
+            match payload.download(&self.config, &dir).await {
+                Ok(path) => {
+                    command = payload.substitute(&command, &path);
+                    cleanup = cleanup.map(|c| payload.substitute(&c, &path));
+                    payload_path = Some(path);
+                }
+                Err(e) => {
+                    warn!("Task {} payload not downloaded: {:#}", task.id, e);
+                    payload_error = Some(format!(
+                        "Failed to download payload {}: {:#}",
+                        payload.name, e
+                    ));
+                }
+            }
+            payload_dir = Some(dir);
+        }
+
         let timeout = task.timeout.unwrap_or(300);
-        let result = self
-            .executor
-            .execute(&task.executor, &task.command, Duration::from_secs(timeout))
-            .await;
+        let limits = task.limits.unwrap_or_default();
+        let mut cancel = self.cancel.subscribe();
+        let skipped = payload_error.is_some();
+        let (result, cancelled) = if let Some(output) = payload_error {
+            // The command is not run without its payload
+            (
+                ExecutionResult {
+                    success: false,
+                    output,
+                    exit_code: None,
+                    limit_exceeded: None,
+                },
+                false,
+            )
+        } else {
+            let run = self.executor.execute_with_limits(
+                &task.executor,
+                &command,
+                Duration::from_secs(timeout),
+                &limits,
+            );
+            // Dropping the run kills the command
+            tokio::select! {
+                result = run => (result, false),
+                _ = cancel.changed() => {
+                    warn!("Task {} cancelled by the server", task.id);
+                    (ExecutionResult {
+                        success: false,
+                        output: "Cancelled by the server".to_string(),
+                        exit_code: None,
+                        limit_exceeded: None,
+                    }, true)
+                }
+            }
+        };
+
+        for path in &task.collect {
+            let path = match (&task.payload, &payload_path) {
+                (Some(payload), Some(local)) => payload.substitute(path, local),
+                _ => path.clone(),
+            };
+            if let Some(artifact) = Self::read_artifact(&task.id, &path).await {
+                tx.send(serde_json::to_string(&artifact)?).await?;
+            }
+        }
+
+        let payload = serde_json::json!({
+            "task_id": task.id,
+            "technique_id": task.technique_id,
+            "success": result.success,
+            "output": result.output,
+            "exit_code": result.exit_code,
+            "limit_exceeded": result.limit_exceeded.map(|v| v.as_str()),
+            "trace_context": task.trace_context,
+            "submission_id": uuid::Uuid::new_v4().to_string(),
+        });
 
+        // Kept until acknowledged: a result lost with the connection is resent
+        // under the same submission id, which the server deduplicates
+        self.unacked
+            .lock()
+            .unwrap_or_else(|e| e.into_inner())
+            .insert(task.id.clone(), payload.clone());
         let response = AgentMessage {
             msg_type: "task_result".to_string(),
-            payload: serde_json::json!({
-                "task_id": task.id,
-                "technique_id": task.technique_id,
-                "success": result.success,
-                "output": result.output,
-                "exit_code": result.exit_code,
-            }),
+            payload,
         };
-
         tx.send(serde_json::to_string(&response)?).await?;
 
-        if let Some(cleanup) = task.cleanup {
+        // Nothing more runs after a cancel or without the payload, cleanup included
+        if let (false, Some(cleanup)) = (cancelled || skipped, cleanup) {
             debug!("Executing cleanup command");
             let _ = self
                 .executor
                 .execute(&task.executor, &cleanup, Duration::from_secs(30))
                 .await;
         }
+        if let Some(dir) = payload_dir {
+            let _ = tokio::fs::remove_dir_all(dir).await;
+        }
 
         Ok(())
     }
+
+    /// Reads a collected file into a task_artifact message. Missing and
+    /// oversized files are skipped: the technique may not have produced them.
+    async fn read_artifact(task_id: &str, path: &str) -> Option<AgentMessage> {
+        match tokio::fs::metadata(path).await {
+            Ok(meta) if !meta.is_file() => {
+                warn!("Artifact {} is not a file, skipped", path);
+                return None;
+            }
+            Ok(meta) if meta.len() > MAX_ARTIFACT_SIZE => {
+                warn!(
+                    "Artifact {} is larger than {} bytes, skipped",
+                    path, MAX_ARTIFACT_SIZE
+                );
+                return None;
+            }
+            Ok(_) => {}
+            Err(e) => {
+                warn!("Artifact {} not collected: {}", path, e);
+                return None;
+            }
+        }
+
+        let data = match tokio::fs::read(path).await {
+            Ok(data) => data,
+            Err(e) => {
+                warn!("Failed to read artifact {}: {}", path, e);
+                return None;
+            }
+        };
+        debug!("Uploading artifact {} ({} bytes)", path, data.len());
+
+        Some(AgentMessage {
+            msg_type: "task_artifact".to_string(),
+            payload: serde_json::json!({
+                "task_id": task_id,
+                "path": path,
+                "data": base64::encode(&data),
+            }),
+        })
+    }
+}
+
+/// Splits result payloads in `task_results` messages within the batch limits
+/// of the server.
+fn result_batches(unacked: &BTreeMap<String, serde_json::Value>) -> Vec<String> {
+    let mut batches = Vec::new();
+    let mut batch: Vec<&serde_json::Value> = Vec::new();
+    let mut size = 0;
+    for payload in unacked.values() {
+        let len = payload.to_string().len();
+        if !batch.is_empty()
+            && (batch.len() == MAX_RESULT_BATCH || size + len > MAX_RESULT_BATCH_BYTES)
+        {
+            batches.push(task_results_message(&batch));
+            batch.clear();
+            size = 0;
+        }
+        batch.push(payload);
+        size += len;
+    }
+    if !batch.is_empty() {
+        batches.push(task_results_message(&batch));
+    }
+    batches
+}
+
+/// Builds a `task_results` message carrying a batch of result payloads.
+fn task_results_message(results: &[&serde_json::Value]) -> String {
+    serde_json::json!({
+        "type": "task_results",
+        "payload": { "results": results },
+    })
+    .to_string()
+}
+
+/// Stamps a server message with the cancel generation. A `cancel` starts a
+/// new generation as soon as it is read, even while a task runs: the running
+/// command is killed and the tasks received before it are dropped.
+fn stamp(cancel: &watch::Sender<u64>, msg: AgentMessage) -> (u64, AgentMessage) {
+    if msg.msg_type == "cancel" {
+        cancel.send_modify(|generation| *generation += 1);
+    }
+    (*cancel.borrow(), msg)
+}
+
+/// Returns the agent clock in Unix milliseconds, sent with heartbeats for the
+/// server to measure their latency.
+fn unix_millis() -> u64 {
+    SystemTime::now()
+        .duration_since(UNIX_EPOCH)
+        .map(|d| d.as_millis() as u64)
+        .unwrap_or(0)
 }
 
 #[cfg(test)]
@@ -297,6 +987,8 @@ mod tests {
             heartbeat_interval: 30,
             tls: TlsConfig::default(),
             agent_secret: None,
+            update_public_key: None,
+            transport: Transport::Auto,
         }
     }
 
@@ -307,6 +999,8 @@ mod tests {
             heartbeat_interval: 30,
             tls: TlsConfig::default(),
             agent_secret: Some("test-secret".to_string()),
+            update_public_key: None,
+            transport: Transport::Auto,
         }
     }
 
@@ -318,6 +1012,10 @@ mod tests {
             executors: vec!["sh".to_string(), "bash".to_string()],
             os_version: "5.0".to_string(),
             architecture: "x86_64".to_string(),
+            elevated: false,
+            domain: String::new(),
+            security_products: vec![],
+            interpreters: BTreeMap::new(),
         }
     }
 
@@ -391,6 +1089,24 @@ mod tests {
         assert!(response.contains("pong"));
     }
 
+    #[tokio::test]
+    async fn test_handle_message_server_shutdown() {
+        let config = create_test_config();
+        let sys_info = create_test_sys_info();
+        let client = AgentClient::new(config, sys_info).unwrap();
+
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+
+        let msg = AgentMessage {
+            msg_type: "server_shutdown".to_string(),
+            payload: serde_json::json!({"interrupted_executions": 1, "resumable": true}),
+        };
+
+        let result = client.handle_message(msg, &tx).await;
+        assert!(result.is_ok());
+        assert!(rx.try_recv().is_err());
+    }
+
     #[tokio::test]
     async fn test_handle_message_unknown_type() {
         let config = create_test_config();
@@ -408,6 +1124,92 @@ mod tests {
         assert!(result.is_ok());
     }
 
+    #[test]
+    fn test_beacon_delay() {
+        let beacon = Beacon {
+            interval: 60,
+            jitter: 0,
+        };
+        assert_eq!(beacon.delay(12345), Duration::from_secs(60));
+
+        let beacon = Beacon {
+            interval: 60,
+            jitter: 50,
+        };
+        assert_eq!(beacon.delay(0), Duration::from_secs(30));
+        assert_eq!(beacon.delay(60_000), Duration::from_secs(90));
+        for _ in 0..100 {
+            let delay = beacon.next_delay();
+            assert!(delay >= Duration::from_secs(30) && delay <= Duration::from_secs(90));
+        }
+
+        // Never below a second
+        let beacon = Beacon {
+            interval: 1,
+            jitter: 90,
+        };
+        assert_eq!(beacon.delay(0), Duration::from_secs(1));
+    }
+
+    #[tokio::test]
+    async fn test_handle_message_beacon() {
+        let client = AgentClient::new(create_test_config(), create_test_sys_info()).unwrap();
+        assert_eq!(client.beacon.borrow().interval, 30);
+
+        let (tx, _rx) = tokio::sync::mpsc::channel::<String>(32);
+        let msg = AgentMessage {
+            msg_type: "beacon".to_string(),
+            payload: serde_json::json!({ "interval": 300, "jitter": 20 }),
+        };
+        assert!(client.handle_message(msg, &tx).await.is_ok());
+        assert_eq!(
+            *client.beacon.borrow(),
+            Beacon {
+                interval: 300,
+                jitter: 20
+            }
+        );
+    }
+
+    #[tokio::test]
+    async fn test_handle_message_update() {
+        let offer = serde_json::json!({
+            "version": "999.0.0",
+            "sha256": "00",
+            "signature": "",
+            "size": 5,
+            "chunks": 1,
+            "chunk_size": 524288
+        });
+        let chunk = serde_json::json!({ "version": "999.0.0", "index": 0, "data": "YWdlbnQ=" });
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+
+        // Without a public key the update is ignored
+        let client = AgentClient::new(create_test_config(), create_test_sys_info()).unwrap();
+        let msg = AgentMessage {
+            msg_type: "update".to_string(),
+            payload: offer.clone(),
+        };
+        assert!(client.handle_message(msg, &tx).await.is_ok());
+        assert!(rx.try_recv().is_err());
+
+        // A release that does not verify is reported as failed
+        let mut config = create_test_config();
+        config.update_public_key = Some(base64::encode([7u8; 32]));
+        let client = AgentClient::new(config, create_test_sys_info()).unwrap();
+        for (msg_type, payload) in [("update", offer), ("update_chunk", chunk)] {
+            let msg = AgentMessage {
+                msg_type: msg_type.to_string(),
+                payload,
+            };
+            assert!(client.handle_message(msg, &tx).await.is_ok());
+        }
+        let status: serde_json::Value = serde_json::from_str(&rx.recv().await.unwrap()).unwrap();
+        assert_eq!(status["type"], "update_status");
+        assert_eq!(status["payload"]["status"], "failed");
+        assert_eq!(status["payload"]["version"], "999.0.0");
+    }
+
     #[tokio::test]
     async fn test_handle_message_task() {
         let config = create_test_config();
@@ -434,6 +1236,152 @@ mod tests {
         assert!(response.contains("task-test"));
     }
 
+    #[tokio::test]
+    async fn test_unacked_results_resent_until_ack() {
+        let client = AgentClient::new(create_test_config(), create_test_sys_info()).unwrap();
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+
+        for id in ["task-a", "task-b"] {
+            let task = AgentMessage {
+                msg_type: "task".to_string(),
+                payload: serde_json::json!({
+                    "id": id,
+                    "technique_id": "T1082",
+                    "command": "echo hello",
+                    "executor": "sh"
+                }),
+            };
+            client.handle_message(task, &tx).await.unwrap();
+        }
+        let sent: serde_json::Value = serde_json::from_str(&rx.recv().await.unwrap()).unwrap();
+        assert_eq!(sent["type"], "task_result");
+        assert!(sent["payload"]["submission_id"].as_str().is_some());
+
+        // Resent unchanged in a batch, under the same submission id
+        let batches = client.unacked_results();
+        assert_eq!(batches.len(), 1);
+        let batch: serde_json::Value = serde_json::from_str(&batches[0]).unwrap();
+        assert_eq!(batch["type"], "task_results");
+        assert_eq!(batch["payload"]["results"][0], sent["payload"]);
+        assert_eq!(batch["payload"]["results"].as_array().unwrap().len(), 2);
+
+        // Forgotten once acknowledged, one by one or in a batch
+        let ack = AgentMessage {
+            msg_type: "task_ack".to_string(),
+            payload: serde_json::json!({ "task_id": "task-a", "status": "received" }),
+        };
+        client.handle_message(ack, &tx).await.unwrap();
+        let ack = AgentMessage {
+            msg_type: "task_results_ack".to_string(),
+            payload: serde_json::json!({ "results": [{ "task_id": "task-b", "status": "duplicate" }] }),
+        };
+        client.handle_message(ack, &tx).await.unwrap();
+        assert!(client.unacked_results().is_empty());
+    }
+
+    #[tokio::test]
+    async fn test_busy_results_resent_after_delay() {
+        let client = AgentClient::new(create_test_config(), create_test_sys_info()).unwrap();
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+        client.unacked.lock().unwrap().insert(
+            "task-a".to_string(),
+            serde_json::json!({ "task_id": "task-a", "submission_id": "sub-a" }),
+        );
+
+        // Kept while the server is busy, then resent once
+        for _ in 0..2 {
+            let ack = AgentMessage {
+                msg_type: "task_ack".to_string(),
+                payload: serde_json::json!({ "task_id": "task-a", "status": "busy", "retry_after": 1 }),
+            };
+            client.handle_message(ack, &tx).await.unwrap();
+        }
+        assert_eq!(client.unacked.lock().unwrap().len(), 1);
+        let resent: serde_json::Value = serde_json::from_str(&rx.recv().await.unwrap()).unwrap();
+        assert_eq!(resent["type"], "task_results");
+        assert_eq!(resent["payload"]["results"][0]["submission_id"], "sub-a");
+        assert!(rx.try_recv().is_err());
+    }
+
+    #[test]
+    fn test_unacked_results_split_in_batches() {
+        let client = AgentClient::new(create_test_config(), create_test_sys_info()).unwrap();
+        {
+            let mut unacked = client.unacked.lock().unwrap();
+            for i in 0..MAX_RESULT_BATCH + 1 {
+                unacked.insert(format!("task-{}", i), serde_json::json!({ "task_id": i }));
+            }
+            unacked.insert(
+                "task-large".to_string(),
+                serde_json::json!({ "output": "x".repeat(MAX_RESULT_BATCH_BYTES) }),
+            );
+        }
+        let batches = client.unacked_results();
+        assert_eq!(batches.len(), 3);
+        assert!(batches.iter().all(|b| b.contains("task_results")));
+    }
+
+    #[tokio::test]
+    async fn test_cancel_drops_earlier_tasks() {
+        let client = AgentClient::new(create_test_config(), create_test_sys_info()).unwrap();
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+        let message = |msg_type: &str| AgentMessage {
+            msg_type: msg_type.to_string(),
+            payload: serde_json::json!({
+                "id": "task-cancelled",
+                "technique_id": "T1082",
+                "command": "echo hello",
+                "executor": "sh",
+                "reason": "test"
+            }),
+        };
+
+        // Stamped before the cancel, handled after it
+        let task = stamp(&client.cancel, message("task"));
+        let cancel = stamp(&client.cancel, message("cancel"));
+        assert_eq!(task.0 + 1, cancel.0);
+
+        assert!(client.dispatch(task, &tx).await.is_ok());
+        assert!(client.dispatch(cancel, &tx).await.is_ok());
+        assert!(rx.try_recv().is_err());
+
+        // Tasks received after the cancel run
+        let task = stamp(&client.cancel, message("task"));
+        assert!(client.dispatch(task, &tx).await.is_ok());
+        assert!(rx.recv().await.unwrap().contains("task-cancelled"));
+    }
+
+    #[tokio::test]
+    async fn test_cancel_kills_running_task() {
+        let client = AgentClient::new(create_test_config(), create_test_sys_info()).unwrap();
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+        let task = TaskPayload {
+            id: "long-task".to_string(),
+            technique_id: "T1059".to_string(),
+            command: "sleep 30".to_string(),
+            executor: "sh".to_string(),
+            timeout: Some(60),
+            cleanup: None,
+            limits: None,
+            trace_context: None,
+            payload: None,
+            collect: vec![],
+        };
+
+        let cancel = client.cancel.clone();
+        tokio::spawn(async move {
+            tokio::time::sleep(Duration::from_millis(200)).await;
+            cancel.send_modify(|generation| *generation += 1);
+        });
+        let started = std::time::Instant::now();
+        assert!(client.execute_task(task, &tx).await.is_ok());
+        assert!(started.elapsed() < Duration::from_secs(10));
+
+        let response: serde_json::Value = serde_json::from_str(&rx.recv().await.unwrap()).unwrap();
+        assert_eq!(response["payload"]["success"], false);
+        assert_eq!(response["payload"]["output"], "Cancelled by the server");
+    }
+
     #[test]
     fn test_url_conversion_https_to_wss() {
         let url = "https://server:8443".replace("https://", "wss://");
@@ -480,6 +1428,128 @@ mod tests {
         let task: TaskPayload = serde_json::from_str(json).unwrap();
         assert!(task.timeout.is_none());
         assert!(task.cleanup.is_none());
+        assert!(task.limits.is_none());
+    }
+
+    #[test]
+    fn test_task_payload_with_limits() {
+        let json = r#"{
+            "id": "task-limits",
+            "technique_id": "T1082",
+            "command": "uname -a",
+            "executor": "sh",
+            "timeout": 60,
+            "limits": {"cpu_percent": 25, "memory_mb": 128}
+        }"#;
+
+        let task: TaskPayload = serde_json::from_str(json).unwrap();
+        let limits = task.limits.unwrap();
+        assert_eq!(limits.cpu_percent(), Some(25));
+        assert_eq!(limits.memory_bytes(), Some(128 * 1024 * 1024));
+    }
+
+    #[test]
+    fn test_task_payload_with_payload() {
+        let json = r#"{
+            "id": "task-payload",
+            "technique_id": "T1105",
+            "command": "cp #{payload.path} /tmp/#{payload.name} || curl -so #{payload.name} #{payload.url}",
+            "executor": "sh",
+            "payload": {"name": "tool.sh", "sha256": "abc", "size": 3, "url": "/payloads/token"}
+        }"#;
+
+        let task: TaskPayload = serde_json::from_str(json).unwrap();
+        let payload = task.payload.unwrap();
+        assert_eq!(
+            payload.substitute(&task.command, Path::new("/tmp/autostrike-payload-1/tool.sh")),
+            "cp /tmp/autostrike-payload-1/tool.sh /tmp/tool.sh || curl -so tool.sh file:///tmp/autostrike-payload-1/tool.sh"
+        );
+        assert_eq!(
+            payload.substitute("#{payload.sha256}", Path::new("")),
+            "abc"
+        );
+        assert_eq!(
+            file_url(Path::new(r"C:\Temp\tool.sh")),
+            "file:///C:/Temp/tool.sh"
+        );
+    }
+
+    #[test]
+    fn test_task_payload_verify() {
+        let payload = TaskFile {
+            name: "tool.sh".to_string(),
+            // SHA-256 of "abc"
+            sha256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad".to_string(),
+            size: 3,
+            url: "/payloads/token".to_string(),
+        };
+        assert!(payload.verify(b"abc").is_ok());
+        assert!(payload.verify(b"abd").is_err());
+        assert!(payload.verify(b"abcd").is_err());
+    }
+
+    #[tokio::test]
+    async fn test_execute_task_fails_without_payload() {
+        let config = create_test_config();
+        let client = AgentClient::new(config, create_test_sys_info()).unwrap();
+        let (tx, mut rx) = tokio::sync::mpsc::channel(10);
+
+        // Nothing listens on the server URL of the test config
+        let task: TaskPayload = serde_json::from_str(
+            r#"{
+                "id": "task-no-payload",
+                "technique_id": "T1105",
+                "command": "echo ran",
+                "executor": "sh",
+                "payload": {"name": "tool.sh", "sha256": "abc", "size": 3, "url": "/payloads/token"}
+            }"#,
+        )
+        .unwrap();
+        client.execute_task(task, &tx).await.unwrap();
+
+        let msg: serde_json::Value = serde_json::from_str(&rx.recv().await.unwrap()).unwrap();
+        assert_eq!(msg["type"], "task_result");
+        assert_eq!(msg["payload"]["success"], false);
+        assert!(msg["payload"]["output"]
+            .as_str()
+            .unwrap()
+            .starts_with("Failed to download payload tool.sh"));
+        assert!(!std::env::temp_dir()
+            .join("autostrike-payload-task-no-payload")
+            .exists());
+    }
+
+    #[tokio::test]
+    async fn test_execute_task_uploads_collected_files() {
+        let config = create_test_config();
+        let sys_info = create_test_sys_info();
+        let client = AgentClient::new(config, sys_info).unwrap();
+
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+
+        let dir =
+            std::env::temp_dir().join(format!("autostrike-artifact-{}", uuid::Uuid::new_v4()));
+        let file = dir.join("loot.txt");
+        let task: TaskPayload = serde_json::from_value(serde_json::json!({
+            "id": "collect-task",
+            "technique_id": "T1005",
+            "command": format!("mkdir -p {} && printf loot > {}", dir.display(), file.display()),
+            "executor": "sh",
+            "collect": [file.display().to_string(), dir.join("missing").display().to_string()],
+        }))
+        .unwrap();
+
+        client.execute_task(task, &tx).await.unwrap();
+        let _ = std::fs::remove_dir_all(&dir);
+
+        let artifact: serde_json::Value = serde_json::from_str(&rx.recv().await.unwrap()).unwrap();
+        assert_eq!(artifact["type"], "task_artifact");
+        assert_eq!(artifact["payload"]["task_id"], "collect-task");
+        assert_eq!(artifact["payload"]["data"], "bG9vdA==");
+
+        // The missing file is skipped, the result follows
+        let response = rx.recv().await.unwrap();
+        assert!(response.contains("task_result"));
     }
 
     #[tokio::test]
@@ -497,6 +1567,10 @@ mod tests {
             executor: "sh".to_string(),
             timeout: Some(5),
             cleanup: Some("echo cleanup".to_string()),
+            limits: None,
+            trace_context: None,
+            payload: None,
+            collect: vec![],
         };
 
         let result = client.execute_task(task, &tx).await;
@@ -521,6 +1595,10 @@ mod tests {
             executor: "sh".to_string(),
             timeout: None,
             cleanup: None,
+            limits: None,
+            trace_context: None,
+            payload: None,
+            collect: vec![],
         };
 
         let result = client.execute_task(task, &tx).await;
@@ -529,4 +1607,31 @@ mod tests {
         let response = rx.recv().await.unwrap();
         assert!(response.contains("timeout-task"));
     }
+
+    #[tokio::test]
+    async fn test_execute_task_echoes_trace_context() {
+        let config = create_test_config();
+        let sys_info = create_test_sys_info();
+        let client = AgentClient::new(config, sys_info).unwrap();
+
+        let (tx, mut rx) = tokio::sync::mpsc::channel::<String>(32);
+
+        let traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01";
+        let task: TaskPayload = serde_json::from_value(serde_json::json!({
+            "id": "traced-task",
+            "technique_id": "T1082",
+            "command": "echo traced",
+            "executor": "sh",
+            "trace_context": {"traceparent": traceparent},
+        }))
+        .unwrap();
+
+        client.execute_task(task, &tx).await.unwrap();
+
+        let response: serde_json::Value = serde_json::from_str(&rx.recv().await.unwrap()).unwrap();
+        assert_eq!(
+            response["payload"]["trace_context"]["traceparent"],
+            traceparent
+        );
+    }
 }
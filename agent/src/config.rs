@@ -18,6 +18,26 @@ pub struct AgentConfig {
     /// Agent authentication secret (X-Agent-Key header).
     #[serde(default)]
     pub agent_secret: Option<String>,
+    /// Base64 Ed25519 public key agent releases must be signed for.
+    /// Self-updates pushed by the server are ignored when unset.
+    #[serde(default)]
+    pub update_public_key: Option<String>,
+    /// How the agent reaches the server.
+    #[serde(default)]
+    pub transport: Transport,
+}
+
+/// Connection to the server.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Transport {
+    /// WebSocket, falling back to HTTP long-polling when it cannot connect.
+    #[default]
+    Auto,
+    /// WebSocket only.
+    Websocket,
+    /// HTTP long-polling only, for proxies that block WebSockets.
+    Http,
 }
 
 impl std::fmt::Debug for AgentConfig {
@@ -31,6 +51,8 @@ impl std::fmt::Debug for AgentConfig {
                 "agent_secret",
                 &self.agent_secret.as_ref().map(|_| "[REDACTED]"),
             )
+            .field("update_public_key", &self.update_public_key)
+            .field("transport", &self.transport)
             .finish()
     }
 }
@@ -99,6 +121,11 @@ impl AgentConfig {
                 .map(|c| c.tls.clone())
                 .unwrap_or_default(),
             agent_secret: resolved_secret,
+            transport: file_config
+                .as_ref()
+                .map(|c| c.transport)
+                .unwrap_or_default(),
+            update_public_key: file_config.and_then(|c| c.update_public_key),
         })
     }
 }
@@ -194,6 +221,8 @@ mod tests {
             heartbeat_interval: 60,
             tls: TlsConfig::default(),
             agent_secret: Some("secret".to_string()),
+            update_public_key: None,
+            transport: Transport::Auto,
         };
 
         let cloned = config.clone();
@@ -211,6 +240,8 @@ mod tests {
             heartbeat_interval: 30,
             tls: TlsConfig::default(),
             agent_secret: None,
+            update_public_key: None,
+            transport: Transport::Auto,
         };
 
         let debug_str = format!("{:?}", config);
@@ -252,6 +283,8 @@ tls:
   ca_file: ~
   verify: false
 agent_secret: "file-secret"
+update_public_key: "dXBkYXRlLWtleQ=="
+transport: "http"
 "#;
 
         let mut file = fs::File::create(&config_path).unwrap();
@@ -265,12 +298,18 @@ agent_secret: "file-secret"
         )
         .unwrap();
 
+        assert_eq!(
+            config.update_public_key.as_deref(),
+            Some("dXBkYXRlLWtleQ==")
+        );
+
         assert_eq!(config.server_url, "https://cli-server:8443");
         assert_eq!(config.paw, "file-paw-123");
         assert_eq!(config.heartbeat_interval, 45);
         assert_eq!(config.tls.cert_file.as_deref(), Some("/path/to/cert.pem"));
         assert!(!config.tls.verify);
         assert_eq!(config.agent_secret, Some("file-secret".to_string()));
+        assert_eq!(config.transport, Transport::Http);
 
         fs::remove_file(&config_path).ok();
     }
@@ -348,6 +387,8 @@ agent_secret: "file-secret"
             heartbeat_interval: 60,
             tls: TlsConfig::default(),
             agent_secret: Some("test-secret".to_string()),
+            update_public_key: None,
+            transport: Transport::Auto,
         };
 
         let json = serde_json::to_string(&config).unwrap();
@@ -376,6 +417,7 @@ agent_secret: "file-secret"
         assert_eq!(config.paw, "deserialized-paw");
         assert_eq!(config.heartbeat_interval, 120);
         assert!(config.agent_secret.is_none()); // Default is None
+        assert_eq!(config.transport, Transport::Auto);
     }
 
     #[test]
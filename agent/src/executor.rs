@@ -1,4 +1,4 @@
-//! Command execution with timeout support.
+//! Command execution with timeout and resource limit support.
 
 use std::process::Stdio;
 use std::sync::atomic::{AtomicUsize, Ordering};
@@ -8,6 +8,8 @@ use tokio::io::AsyncReadExt;
 use tokio::process::Command;
 use tracing::{debug, error};
 
+use crate::limits::{LimitGuard, LimitViolation, ResourceLimits};
+
 /// Result of a command execution.
 pub struct ExecutionResult {
     /// Whether the command executed successfully.
@@ -16,6 +18,8 @@ pub struct ExecutionResult {
     pub output: String,
     /// Process exit code, if available.
     pub exit_code: Option<i32>,
+    /// Limit the command went over, if any.
+    pub limit_exceeded: Option<LimitViolation>,
 }
 
 /// Maximum output size in bytes (1 MB) to prevent memory exhaustion.
@@ -37,11 +41,32 @@ impl CommandExecutor {
         executor_type: &str,
         command: &str,
         time_limit: Duration,
+    ) -> ExecutionResult {
+        self.execute_with_limits(
+            executor_type,
+            command,
+            time_limit,
+            &ResourceLimits::default(),
+        )
+        .await
+    }
+
+    /// Executes a command with the specified executor, timeout and resource limits.
+    /// Going over the timeout or the memory cap is reported in `limit_exceeded`.
+    pub async fn execute_with_limits(
+        &self,
+        executor_type: &str,
+        command: &str,
+        time_limit: Duration,
+        limits: &ResourceLimits,
     ) -> ExecutionResult {
         debug!("Executing command with {}: {}", executor_type, command);
 
         let mut cmd = self.build_command(executor_type, command);
-        cmd.stdout(Stdio::piped()).stderr(Stdio::piped());
+        // A task cancelled by the server drops this future, killing the command
+        cmd.stdout(Stdio::piped())
+            .stderr(Stdio::piped())
+            .kill_on_drop(true);
 
         let mut child = match cmd.spawn() {
             Ok(child) => child,
@@ -51,10 +76,14 @@ impl CommandExecutor {
                     success: false,
                     output: format!("Execution error: {}", e),
                     exit_code: None,
+                    limit_exceeded: None,
                 };
             }
         };
 
+        // Dropped when this function returns, releasing the limits
+        let guard = LimitGuard::apply(limits, &child);
+
         // Take ownership of stdout/stderr for concurrent reads
         let stdout = child.stdout.take().expect("stdout piped");
         let stderr = child.stderr.take().expect("stderr piped");
@@ -100,6 +129,7 @@ impl CommandExecutor {
                         success: status.success(),
                         output,
                         exit_code: status.code(),
+                        limit_exceeded: guard.as_ref().and_then(LimitGuard::violation),
                     },
                     Err(e) => {
                         error!("Failed to wait for child: {}", e);
@@ -107,6 +137,7 @@ impl CommandExecutor {
                             success: false,
                             output,
                             exit_code: None,
+                            limit_exceeded: guard.as_ref().and_then(LimitGuard::violation),
                         }
                     }
                 }
@@ -119,6 +150,7 @@ impl CommandExecutor {
                     success: false,
                     output: "Command timed out".to_string(),
                     exit_code: None,
+                    limit_exceeded: Some(LimitViolation::Time),
                 }
             }
         }
@@ -256,6 +288,7 @@ mod tests {
             success: true,
             output: "test output".to_string(),
             exit_code: Some(0),
+            limit_exceeded: None,
         };
         assert!(result.success);
         assert_eq!(result.output, "test output");
@@ -268,6 +301,7 @@ mod tests {
             success: false,
             output: "error message".to_string(),
             exit_code: Some(1),
+            limit_exceeded: None,
         };
         assert!(!result.success);
         assert_eq!(result.exit_code, Some(1));
@@ -279,6 +313,7 @@ mod tests {
             success: false,
             output: "timed out".to_string(),
             exit_code: None,
+            limit_exceeded: Some(LimitViolation::Time),
         };
         assert!(!result.success);
         assert!(result.exit_code.is_none());
@@ -355,6 +390,36 @@ mod tests {
         assert!(!result.success);
         assert!(result.output.contains("timed out"));
         assert!(result.exit_code.is_none());
+        assert_eq!(result.limit_exceeded, Some(LimitViolation::Time));
+    }
+
+    #[tokio::test]
+    async fn test_command_without_limits_reports_no_violation() {
+        let executor = CommandExecutor::new();
+
+        #[cfg(not(target_os = "windows"))]
+        let result = executor
+            .execute_with_limits(
+                "sh",
+                "echo limited",
+                Duration::from_secs(5),
+                &ResourceLimits::default(),
+            )
+            .await;
+
+        #[cfg(target_os = "windows")]
+        let result = executor
+            .execute_with_limits(
+                "cmd",
+                "echo limited",
+                Duration::from_secs(5),
+                &ResourceLimits::default(),
+            )
+            .await;
+
+        assert!(result.success);
+        assert!(result.output.contains("limited"));
+        assert!(result.limit_exceeded.is_none());
     }
 
     #[tokio::test]
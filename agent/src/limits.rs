@@ -0,0 +1,393 @@
+//! Resource limits for executed commands.
+//!
+//! Each limited command gets its own cgroup (v2) on Linux and its own job object
+//! on Windows. When the limits cannot be applied (no cgroup v2, missing
+//! privileges, unsupported platform) a warning is logged and the command runs
+//! bounded by its timeout only.
+
+use serde::Deserialize;
+use tokio::process::Child;
+use tracing::warn;
+
+/// CPU and memory limits sent by the server with a task.
+#[derive(Debug, Clone, Default, Deserialize, PartialEq, Eq)]
+pub struct ResourceLimits {
+    /// Share of one CPU core in percent. The command is throttled above it.
+    #[serde(default)]
+    pub cpu_percent: Option<u32>,
+    /// Memory cap in megabytes. The command is killed above it.
+    #[serde(default)]
+    pub memory_mb: Option<u64>,
+}
+
+impl ResourceLimits {
+    /// Returns the CPU share, ignoring a zero value.
+    pub fn cpu_percent(&self) -> Option<u32> {
+        self.cpu_percent.filter(|&percent| percent > 0)
+    }
+
+    /// Returns the memory cap in bytes, ignoring a zero value.
+    pub fn memory_bytes(&self) -> Option<u64> {
+        self.memory_mb
+            .filter(|&mb| mb > 0)
+            .map(|mb| mb.saturating_mul(1024 * 1024))
+    }
+
+    /// Returns true when no limit is set.
+    pub fn is_empty(&self) -> bool {
+        self.cpu_percent().is_none() && self.memory_bytes().is_none()
+    }
+}
+
+/// A limit the command went over.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum LimitViolation {
+    /// The command ran past its timeout and was killed.
+    Time,
+    /// The command used more memory than allowed.
+    Memory,
+}
+
+impl LimitViolation {
+    /// Returns the name reported to the server in `limit_exceeded`.
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            LimitViolation::Time => "time",
+            LimitViolation::Memory => "memory",
+        }
+    }
+}
+
+/// Keeps a running command inside its limits and releases them when dropped.
+pub struct LimitGuard {
+    inner: platform::Guard,
+}
+
+impl LimitGuard {
+    /// Applies the limits to a spawned command.
+    /// Returns None when there is nothing to enforce or the platform refused.
+    pub fn apply(limits: &ResourceLimits, child: &Child) -> Option<Self> {
+        if limits.is_empty() {
+            return None;
+        }
+        match platform::Guard::apply(limits, child) {
+            Ok(inner) => Some(Self { inner }),
+            Err(e) => {
+                warn!("Resource limits not enforced: {}", e);
+                None
+            }
+        }
+    }
+
+    /// Reports the limit the command went over. Call it once the command exited.
+    pub fn violation(&self) -> Option<LimitViolation> {
+        self.inner.violation()
+    }
+}
+
+#[cfg(target_os = "linux")]
+mod platform {
+    use super::{LimitViolation, ResourceLimits};
+    use std::fs;
+    use std::io;
+    use std::path::PathBuf;
+    use tokio::process::Child;
+
+    const CGROUP_ROOT: &str = "/sys/fs/cgroup";
+    /// Parent group holding one child group per limited command.
+    const CGROUP_PARENT: &str = "autostrike";
+    const CPU_PERIOD_US: u64 = 100_000;
+    /// Smallest quota accepted by the kernel.
+    const CPU_MIN_QUOTA_US: u64 = 1_000;
+
+    /// Per-command cgroup, removed on drop.
+    pub struct Guard {
+        path: PathBuf,
+    }
+
+    impl Guard {
+        pub fn apply(limits: &ResourceLimits, child: &Child) -> io::Result<Self> {
+            let pid = child
+                .id()
+                .ok_or_else(|| io::Error::new(io::ErrorKind::NotFound, "command already exited"))?;
+
+            let root = PathBuf::from(CGROUP_ROOT);
+            if !root.join("cgroup.controllers").exists() {
+                return Err(io::Error::new(
+                    io::ErrorKind::Unsupported,
+                    "cgroup v2 is not mounted",
+                ));
+            }
+
+            let parent = root.join(CGROUP_PARENT);
+            fs::create_dir_all(&parent)?;
+            // Delegate the controllers to the per-command groups
+            fs::write(parent.join("cgroup.subtree_control"), "+cpu +memory")?;
+
+            let path = parent.join(uuid::Uuid::new_v4().to_string());
+            fs::create_dir(&path)?;
+            // From here on, an error drops the guard and removes the group
+            let guard = Self { path };
+
+            if let Some(percent) = limits.cpu_percent() {
+                fs::write(guard.path.join("cpu.max"), cpu_max(percent))?;
+            }
+            if let Some(bytes) = limits.memory_bytes() {
+                fs::write(guard.path.join("memory.max"), bytes.to_string())?;
+                // Swapping would let the command grow past the cap
+                let _ = fs::write(guard.path.join("memory.swap.max"), "0");
+            }
+
+            fs::write(guard.path.join("cgroup.procs"), pid.to_string())?;
+            Ok(guard)
+        }
+
+        pub fn violation(&self) -> Option<LimitViolation> {
+            let events = fs::read_to_string(self.path.join("memory.events")).ok()?;
+            oom_killed(&events).then_some(LimitViolation::Memory)
+        }
+    }
+
+    impl Drop for Guard {
+        fn drop(&mut self) {
+            // Kill whatever the command left behind so the group can be removed
+            let _ = fs::write(self.path.join("cgroup.kill"), "1");
+            let _ = fs::remove_dir(&self.path);
+        }
+    }
+
+    /// Formats the cpu.max value for a share of one core.
+    pub(super) fn cpu_max(percent: u32) -> String {
+        let quota = (CPU_PERIOD_US * u64::from(percent) / 100).max(CPU_MIN_QUOTA_US);
+        format!("{} {}", quota, CPU_PERIOD_US)
+    }
+
+    /// Reports whether memory.events records a process killed by the OOM killer.
+    pub(super) fn oom_killed(events: &str) -> bool {
+        events
+            .lines()
+            .filter_map(|line| line.strip_prefix("oom_kill "))
+            .any(|count| count.trim().parse::<u64>().map_or(false, |n| n > 0))
+    }
+}
+
+#[cfg(windows)]
+mod platform {
+    use super::{LimitViolation, ResourceLimits};
+    use std::io;
+    use std::mem;
+    use std::ptr;
+    use tokio::process::Child;
+    use winapi::shared::minwindef::{DWORD, LPVOID};
+    use winapi::um::handleapi::CloseHandle;
+    use winapi::um::jobapi2::{
+        AssignProcessToJobObject, CreateJobObjectW, QueryInformationJobObject,
+        SetInformationJobObject,
+    };
+    use winapi::um::winnt::{
+        JobObjectCpuRateControlInformation, JobObjectExtendedLimitInformation, HANDLE,
+        JOBOBJECTINFOCLASS, JOBOBJECT_CPU_RATE_CONTROL_INFORMATION,
+        JOBOBJECT_EXTENDED_LIMIT_INFORMATION, JOB_OBJECT_CPU_RATE_CONTROL_ENABLE,
+        JOB_OBJECT_CPU_RATE_CONTROL_HARD_CAP, JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+        JOB_OBJECT_LIMIT_PROCESS_MEMORY,
+    };
+
+    /// Allocations fail at the cap instead of killing the process, so a peak
+    /// this close to it counts as a violation.
+    const MEMORY_SLACK_BYTES: u64 = 1024 * 1024;
+
+    /// Per-command job object, closed on drop.
+    pub struct Guard {
+        job: HANDLE,
+        memory_limit: Option<u64>,
+    }
+
+    // Job handles are not tied to the thread that created them
+    unsafe impl Send for Guard {}
+
+    impl Guard {
+        pub fn apply(limits: &ResourceLimits, child: &Child) -> io::Result<Self> {
+            let process = child
+                .raw_handle()
+                .ok_or_else(|| io::Error::new(io::ErrorKind::NotFound, "command already exited"))?;
+
+            unsafe {
+                let job = CreateJobObjectW(ptr::null_mut(), ptr::null());
+                if job.is_null() {
+                    return Err(io::Error::last_os_error());
+                }
+                // From here on, an error drops the guard and closes the job
+                let guard = Self {
+                    job,
+                    memory_limit: limits.memory_bytes(),
+                };
+
+                let mut info: JOBOBJECT_EXTENDED_LIMIT_INFORMATION = mem::zeroed();
+                // Closing the job kills whatever the command left behind
+                info.BasicLimitInformation.LimitFlags = JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE;
+                if let Some(bytes) = guard.memory_limit {
+                    info.BasicLimitInformation.LimitFlags |= JOB_OBJECT_LIMIT_PROCESS_MEMORY;
+                    info.ProcessMemoryLimit = bytes as usize;
+                }
+                set_information(job, JobObjectExtendedLimitInformation, &mut info)?;
+
+                if let Some(percent) = limits.cpu_percent() {
+                    let mut rate: JOBOBJECT_CPU_RATE_CONTROL_INFORMATION = mem::zeroed();
+                    rate.ControlFlags =
+                        JOB_OBJECT_CPU_RATE_CONTROL_ENABLE | JOB_OBJECT_CPU_RATE_CONTROL_HARD_CAP;
+                    *rate.u.CpuRate_mut() = cpu_rate(percent);
+                    set_information(job, JobObjectCpuRateControlInformation, &mut rate)?;
+                }
+
+                if AssignProcessToJobObject(job, process as HANDLE) == 0 {
+                    return Err(io::Error::last_os_error());
+                }
+                Ok(guard)
+            }
+        }
+
+        pub fn violation(&self) -> Option<LimitViolation> {
+            let limit = self.memory_limit?;
+            let peak = unsafe {
+                let mut info: JOBOBJECT_EXTENDED_LIMIT_INFORMATION = mem::zeroed();
+                let ok = QueryInformationJobObject(
+                    self.job,
+                    JobObjectExtendedLimitInformation,
+                    &mut info as *mut _ as LPVOID,
+                    mem::size_of::<JOBOBJECT_EXTENDED_LIMIT_INFORMATION>() as DWORD,
+                    ptr::null_mut(),
+                );
+                if ok == 0 {
+                    return None;
+                }
+                info.PeakProcessMemoryUsed as u64
+            };
+            (peak + MEMORY_SLACK_BYTES >= limit).then_some(LimitViolation::Memory)
+        }
+    }
+
+    impl Drop for Guard {
+        fn drop(&mut self) {
+            unsafe {
+                CloseHandle(self.job);
+            }
+        }
+    }
+
+    /// Converts a share of one core into the job CPU rate, expressed in
+    /// hundredths of a percent of all processors.
+    fn cpu_rate(percent: u32) -> DWORD {
+        let cpus = std::thread::available_parallelism()
+            .map(|n| n.get() as u32)
+            .unwrap_or(1);
+        (percent * 100 / cpus).clamp(1, 10_000)
+    }
+
+    unsafe fn set_information<T>(
+        job: HANDLE,
+        class: JOBOBJECTINFOCLASS,
+        info: &mut T,
+    ) -> io::Result<()> {
+        let ok = SetInformationJobObject(
+            job,
+            class,
+            info as *mut T as LPVOID,
+            mem::size_of::<T>() as DWORD,
+        );
+        if ok == 0 {
+            return Err(io::Error::last_os_error());
+        }
+        Ok(())
+    }
+}
+
+#[cfg(not(any(target_os = "linux", windows)))]
+mod platform {
+    use super::{LimitViolation, ResourceLimits};
+    use std::io;
+    use tokio::process::Child;
+
+    pub struct Guard;
+
+    impl Guard {
+        pub fn apply(_limits: &ResourceLimits, _child: &Child) -> io::Result<Self> {
+            Err(io::Error::new(
+                io::ErrorKind::Unsupported,
+                "resource limits are not supported on this platform",
+            ))
+        }
+
+        pub fn violation(&self) -> Option<LimitViolation> {
+            None
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_limits_default_is_empty() {
+        let limits = ResourceLimits::default();
+        assert!(limits.is_empty());
+        assert_eq!(limits.cpu_percent(), None);
+        assert_eq!(limits.memory_bytes(), None);
+    }
+
+    #[test]
+    fn test_limits_zero_values_are_ignored() {
+        let limits = ResourceLimits {
+            cpu_percent: Some(0),
+            memory_mb: Some(0),
+        };
+        assert!(limits.is_empty());
+    }
+
+    #[test]
+    fn test_limits_memory_bytes() {
+        let limits = ResourceLimits {
+            cpu_percent: None,
+            memory_mb: Some(256),
+        };
+        assert!(!limits.is_empty());
+        assert_eq!(limits.memory_bytes(), Some(256 * 1024 * 1024));
+    }
+
+    #[test]
+    fn test_limits_deserialize() {
+        let limits: ResourceLimits =
+            serde_json::from_str(r#"{"cpu_percent": 50, "memory_mb": 128}"#).unwrap();
+        assert_eq!(limits.cpu_percent(), Some(50));
+        assert_eq!(limits.memory_mb, Some(128));
+
+        let empty: ResourceLimits = serde_json::from_str("{}").unwrap();
+        assert!(empty.is_empty());
+    }
+
+    #[test]
+    fn test_limit_violation_as_str() {
+        assert_eq!(LimitViolation::Time.as_str(), "time");
+        assert_eq!(LimitViolation::Memory.as_str(), "memory");
+    }
+
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn test_cpu_max() {
+        assert_eq!(platform::cpu_max(50), "50000 100000");
+        assert_eq!(platform::cpu_max(200), "200000 100000");
+        assert_eq!(platform::cpu_max(0), "1000 100000");
+    }
+
+    #[cfg(target_os = "linux")]
+    #[test]
+    fn test_oom_killed() {
+        assert!(!platform::oom_killed(
+            "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n"
+        ));
+        assert!(platform::oom_killed(
+            "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1\n"
+        ));
+        assert!(!platform::oom_killed(""));
+    }
+}
@@ -1,12 +1,16 @@
 //! AutoStrike Agent - Breach and Attack Simulation agent.
 //!
-//! This agent connects to the AutoStrike server via WebSocket and executes
-//! MITRE ATT&CK techniques for security testing purposes.
+//! This agent connects to the AutoStrike server via WebSocket (or HTTP
+//! long-polling where WebSockets are blocked) and executes MITRE ATT&CK
+//! techniques for security testing purposes.
 
 mod client;
 mod config;
 mod executor;
+mod limits;
+mod poll;
 mod system;
+mod update;
 
 use anyhow::Result;
 use clap::Parser;
@@ -0,0 +1,163 @@
+//! HTTP long-polling transport, for networks where WebSockets are blocked.
+//!
+//! The agent opens a session with `POST /ws/agent/poll`, posts its messages
+//! one per request to `POST /ws/agent/poll/{session}` and waits for the
+//! server's with `GET /ws/agent/poll/{session}`. The messages are the same as
+//! over WebSocket. The server drops a session that is not polled for a minute.
+
+use anyhow::{bail, Context, Result};
+use reqwest::StatusCode;
+use serde::Deserialize;
+use tokio::time::Duration;
+use tracing::warn;
+
+use crate::client::AgentMessage;
+use crate::config::AgentConfig;
+
+/// Seconds each poll request waits for messages, the server default.
+const POLL_WAIT: u64 = 25;
+
+/// Reply of `POST /ws/agent/poll`.
+#[derive(Debug, Deserialize)]
+struct OpenResponse {
+    session_id: String,
+}
+
+/// Reply of `GET /ws/agent/poll/{session}`.
+#[derive(Debug, Deserialize)]
+struct PollResponse {
+    messages: Vec<serde_json::Value>,
+}
+
+/// Long-poll session with the server.
+pub struct PollSession {
+    http: reqwest::Client,
+    url: String,
+    agent_secret: Option<String>,
+}
+
+impl PollSession {
+    /// Opens a session with the server of the configuration.
+    pub async fn open(config: &AgentConfig) -> Result<Self> {
+        let http = reqwest::Client::builder()
+            .danger_accept_invalid_certs(!config.tls.verify)
+            .timeout(Duration::from_secs(POLL_WAIT + 15))
+            .build()
+            .context("Failed to create HTTP client")?;
+
+        let base = poll_url(&config.server_url);
+        let mut request = http.post(&base);
+        if let Some(ref secret) = config.agent_secret {
+            request = request.header("X-Agent-Key", secret);
+        }
+        let response = request
+            .send()
+            .await
+            .context("Failed to open long-poll session")?;
+        if response.status() != StatusCode::CREATED {
+            bail!("Failed to open long-poll session: {}", response.status());
+        }
+        let opened: OpenResponse = response.json().await?;
+
+        Ok(Self {
+            http,
+            url: format!("{}/{}", base, opened.session_id),
+            agent_secret: config.agent_secret.clone(),
+        })
+    }
+
+    /// Sends a serialized message to the server.
+    pub async fn send(&self, message: String) -> Result<()> {
+        let response = self
+            .with_key(self.http.post(&self.url))
+            .header("Content-Type", "application/json")
+            .body(message)
+            .send()
+            .await
+            .context("Failed to send message")?;
+        if !response.status().is_success() {
+            bail!("Server refused message: {}", response.status());
+        }
+        Ok(())
+    }
+
+    /// Waits for the messages of the server. Returns None once the server
+    /// closed the session; the agent then connects again.
+    pub async fn poll(&self) -> Result<Option<Vec<AgentMessage>>> {
+        let response = self
+            .with_key(self.http.get(&self.url))
+            .query(&[("wait", POLL_WAIT)])
+            .send()
+            .await
+            .context("Failed to poll messages")?;
+        match response.status() {
+            StatusCode::OK => {}
+            StatusCode::NOT_FOUND | StatusCode::GONE => return Ok(None),
+            status => bail!("Failed to poll messages: {}", status),
+        }
+        let polled: PollResponse = response.json().await?;
+        Ok(Some(parse_messages(polled.messages)))
+    }
+
+    /// Closes the session, so the server disconnects the agent at once.
+    pub async fn close(&self) {
+        let _ = self.with_key(self.http.delete(&self.url)).send().await;
+    }
+
+    fn with_key(&self, request: reqwest::RequestBuilder) -> reqwest::RequestBuilder {
+        match self.agent_secret {
+            Some(ref secret) => request.header("X-Agent-Key", secret),
+            None => request,
+        }
+    }
+}
+
+/// Returns the URL sessions are opened at for a server URL.
+pub fn poll_url(server_url: &str) -> String {
+    format!("{}/ws/agent/poll", server_url.trim_end_matches('/'))
+}
+
+/// Decodes polled messages, skipping those that are not agent messages.
+fn parse_messages(values: Vec<serde_json::Value>) -> Vec<AgentMessage> {
+    values
+        .into_iter()
+        .filter_map(|value| match serde_json::from_value(value) {
+            Ok(msg) => Some(msg),
+            Err(e) => {
+                warn!("Failed to parse polled message: {}", e);
+                None
+            }
+        })
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn test_poll_url() {
+        assert_eq!(
+            poll_url("https://server:8443/"),
+            "https://server:8443/ws/agent/poll"
+        );
+        assert_eq!(poll_url("http://server"), "http://server/ws/agent/poll");
+    }
+
+    #[test]
+    fn test_parse_messages() {
+        let polled: PollResponse = serde_json::from_str(
+            r#"{"messages": [
+                {"type": "registered", "payload": {"status": "ok"}},
+                {"payload": {}},
+                {"type": "pong", "payload": null}
+            ]}"#,
+        )
+        .unwrap();
+
+        let messages = parse_messages(polled.messages);
+        assert_eq!(messages.len(), 2);
+        assert_eq!(messages[0].msg_type, "registered");
+        assert_eq!(messages[1].msg_type, "pong");
+    }
+}
@@ -1,9 +1,44 @@
 //! System information gathering for agent registration.
 
 use serde::{Deserialize, Serialize};
-use sysinfo::{System, SystemExt};
+use std::collections::BTreeMap;
+use std::process::Command;
+use sysinfo::{ProcessExt, System, SystemExt};
 use which::which;
 
+/// Process names of common security products, lowercase without extension.
+const SECURITY_PRODUCT_PROCESSES: &[(&str, &str)] = &[
+    ("msmpeng", "Microsoft Defender"),
+    ("mssense", "Microsoft Defender for Endpoint"),
+    ("mdatp", "Microsoft Defender for Endpoint"),
+    ("wdavdaemon", "Microsoft Defender for Endpoint"),
+    ("csfalconservice", "CrowdStrike Falcon"),
+    ("falcon-sensor", "CrowdStrike Falcon"),
+    ("falcond", "CrowdStrike Falcon"),
+    ("sentinelagent", "SentinelOne"),
+    ("sentinelone", "SentinelOne"),
+    ("cb", "Carbon Black"),
+    ("cbagentd", "Carbon Black"),
+    ("repmgr", "Carbon Black"),
+    ("cylancesvc", "Cylance"),
+    ("elastic-agent", "Elastic Agent"),
+    ("elastic-endpoint", "Elastic Defend"),
+    ("sophosfs", "Sophos"),
+    ("savservice", "Sophos"),
+    ("ccsvchst", "Symantec Endpoint Protection"),
+    ("xagt", "Trellix Endpoint Security"),
+    ("cyserver", "Palo Alto Cortex XDR"),
+    ("traps_pmd", "Palo Alto Cortex XDR"),
+    ("ekrn", "ESET"),
+    ("avp", "Kaspersky"),
+    ("sysmon", "Sysmon"),
+    ("sysmon64", "Sysmon"),
+    ("osqueryd", "osquery"),
+    ("wazuh-agentd", "Wazuh"),
+    ("ossec-agentd", "Wazuh"),
+    ("auditbeat", "Auditbeat"),
+];
+
 /// System information collected from the host machine.
 #[derive(Debug, Clone, Serialize, Deserialize)]
 pub struct SystemInfo {
@@ -19,6 +54,18 @@ pub struct SystemInfo {
     pub os_version: String,
     /// CPU architecture (x86_64, aarch64, etc.).
     pub architecture: String,
+    /// Whether the agent runs as root or an administrator.
+    #[serde(default)]
+    pub elevated: bool,
+    /// Directory domain the host is joined to, empty when not joined.
+    #[serde(default)]
+    pub domain: String,
+    /// Security products detected from their running processes.
+    #[serde(default)]
+    pub security_products: Vec<String>,
+    /// Script interpreters found on the host, with their version.
+    #[serde(default)]
+    pub interpreters: BTreeMap<String, String>,
 }
 
 impl SystemInfo {
@@ -38,7 +85,10 @@ impl SystemInfo {
         // Detect available executors
         let executors = Self::detect_executors();
 
-        let sys = System::new();
+        let mut sys = System::new();
+        sys.refresh_processes();
+        let security_products =
+            security_products_from_processes(sys.processes().values().map(|p| p.name()));
 
         SystemInfo {
             hostname: sys.host_name().unwrap_or_else(|| "unknown".to_string()),
@@ -47,9 +97,90 @@ impl SystemInfo {
             executors,
             os_version: sys.os_version().unwrap_or_else(|| "unknown".to_string()),
             architecture: std::env::consts::ARCH.to_string(),
+            elevated: Self::detect_elevated(),
+            domain: Self::detect_domain().unwrap_or_default(),
+            security_products,
+            interpreters: Self::detect_interpreters(),
         }
     }
 
+    #[cfg(unix)]
+    fn detect_elevated() -> bool {
+        nix::unistd::geteuid().is_root()
+    }
+
+    #[cfg(windows)]
+    fn detect_elevated() -> bool {
+        // Listing sessions requires administrator rights
+        Command::new("net")
+            .arg("session")
+            .output()
+            .map(|output| output.status.success())
+            .unwrap_or(false)
+    }
+
+    #[cfg(windows)]
+    fn detect_domain() -> Option<String> {
+        std::env::var("USERDNSDOMAIN")
+            .ok()
+            .filter(|domain| !domain.is_empty())
+    }
+
+    #[cfg(target_os = "macos")]
+    fn detect_domain() -> Option<String> {
+        let output = command_output("dsconfigad", &["-show"])?;
+        output.lines().find_map(|line| {
+            let (key, value) = line.split_once('=')?;
+            (key.trim() == "Active Directory Domain").then(|| value.trim().to_string())
+        })
+    }
+
+    #[cfg(all(unix, not(target_os = "macos")))]
+    fn detect_domain() -> Option<String> {
+        command_output("realm", &["list", "--name-only"])?
+            .lines()
+            .next()
+            .map(|line| line.trim().to_string())
+            .filter(|domain| !domain.is_empty())
+    }
+
+    fn detect_interpreters() -> BTreeMap<String, String> {
+        let checks: &[(&str, &str, &[&str])] = if cfg!(target_os = "windows") {
+            &[
+                (
+                    "powershell",
+                    "powershell",
+                    &[
+                        "-NoProfile",
+                        "-Command",
+                        "$PSVersionTable.PSVersion.ToString()",
+                    ],
+                ),
+                ("pwsh", "pwsh", &["--version"]),
+                ("python", "python", &["--version"]),
+            ]
+        } else {
+            &[
+                ("bash", "bash", &["--version"]),
+                ("python3", "python3", &["--version"]),
+                ("python", "python", &["--version"]),
+                ("perl", "perl", &["-e", "print $^V"]),
+                ("pwsh", "pwsh", &["--version"]),
+            ]
+        };
+
+        let mut interpreters = BTreeMap::new();
+        for (name, command, args) in checks {
+            if which(command).is_err() {
+                continue;
+            }
+            if let Some(version) = command_output(command, args).and_then(|o| parse_version(&o)) {
+                interpreters.insert(name.to_string(), version);
+            }
+        }
+        interpreters
+    }
+
     fn detect_executors() -> Vec<String> {
         let mut executors = Vec::new();
 
@@ -80,6 +211,54 @@ impl SystemInfo {
     }
 }
 
+/// Runs a command and returns its standard output, or its standard error
+/// when the output is empty (Python 2 prints its version there).
+fn command_output(command: &str, args: &[&str]) -> Option<String> {
+    let output = Command::new(command).args(args).output().ok()?;
+    if !output.status.success() {
+        return None;
+    }
+    let stdout = String::from_utf8_lossy(&output.stdout).trim().to_string();
+    if !stdout.is_empty() {
+        return Some(stdout);
+    }
+    Some(String::from_utf8_lossy(&output.stderr).trim().to_string())
+}
+
+/// Extracts the first dotted version number from a command output, e.g.
+/// "3.11.4" from "Python 3.11.4" or "5.1.16" from "GNU bash, version 5.1.16(1)-release".
+fn parse_version(output: &str) -> Option<String> {
+    output.split_whitespace().find_map(|word| {
+        let word = word.trim_start_matches('v');
+        let version: String = word
+            .chars()
+            .take_while(|c| c.is_ascii_digit() || *c == '.')
+            .collect();
+        let version = version.trim_end_matches('.');
+        version
+            .starts_with(|c: char| c.is_ascii_digit())
+            .then(|| version.to_string())
+    })
+}
+
+/// Returns the security products matching running process names, sorted and
+/// without duplicates.
+fn security_products_from_processes<'a>(names: impl Iterator<Item = &'a str>) -> Vec<String> {
+    let mut products: Vec<String> = names
+        .filter_map(|name| {
+            let name = name.to_lowercase();
+            let name = name.strip_suffix(".exe").unwrap_or(&name);
+            SECURITY_PRODUCT_PROCESSES
+                .iter()
+                .find(|(process, _)| *process == name)
+                .map(|(_, product)| product.to_string())
+        })
+        .collect();
+    products.sort();
+    products.dedup();
+    products
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
@@ -170,7 +349,11 @@ mod tests {
             "platform": "linux",
             "executors": ["sh", "bash"],
             "os_version": "5.0",
-            "architecture": "x86_64"
+            "architecture": "x86_64",
+            "elevated": true,
+            "domain": "CORP.EXAMPLE.COM",
+            "security_products": ["Microsoft Defender"],
+            "interpreters": {"bash": "5.1.16"}
         }"#;
 
         let info: SystemInfo = serde_json::from_str(json).unwrap();
@@ -181,6 +364,59 @@ mod tests {
         assert_eq!(info.executors, vec!["sh", "bash"]);
         assert_eq!(info.os_version, "5.0");
         assert_eq!(info.architecture, "x86_64");
+        assert!(info.elevated);
+        assert_eq!(info.domain, "CORP.EXAMPLE.COM");
+        assert_eq!(info.security_products, vec!["Microsoft Defender"]);
+        assert_eq!(info.interpreters["bash"], "5.1.16");
+    }
+
+    #[test]
+    fn test_deserialization_without_inventory() {
+        let json = r#"{
+            "hostname": "test-host",
+            "username": "test-user",
+            "platform": "linux",
+            "executors": ["sh"],
+            "os_version": "5.0",
+            "architecture": "x86_64"
+        }"#;
+
+        let info: SystemInfo = serde_json::from_str(json).unwrap();
+        assert!(!info.elevated);
+        assert!(info.domain.is_empty());
+        assert!(info.security_products.is_empty());
+        assert!(info.interpreters.is_empty());
+    }
+
+    #[test]
+    fn test_parse_version() {
+        assert_eq!(parse_version("Python 3.11.4"), Some("3.11.4".to_string()));
+        assert_eq!(
+            parse_version("GNU bash, version 5.1.16(1)-release (x86_64-pc-linux-gnu)"),
+            Some("5.1.16".to_string())
+        );
+        assert_eq!(
+            parse_version("5.1.19041.1"),
+            Some("5.1.19041.1".to_string())
+        );
+        assert_eq!(parse_version("v5.34.0"), Some("5.34.0".to_string()));
+        assert_eq!(parse_version("PowerShell 7.4.1"), Some("7.4.1".to_string()));
+        assert_eq!(parse_version("no version here"), None);
+    }
+
+    #[test]
+    fn test_security_products_from_processes() {
+        let names = [
+            "MsMpEng.exe",
+            "explorer.exe",
+            "falcon-sensor",
+            "CSFalconService.exe",
+            "sshd",
+        ];
+        assert_eq!(
+            security_products_from_processes(names.into_iter()),
+            vec!["CrowdStrike Falcon", "Microsoft Defender"]
+        );
     }
 
     #[test]
@@ -0,0 +1,448 @@
+//! Self-update from signed agent releases pushed by the server.
+//!
+//! The server announces a release with an `update` message, then sends the
+//! binary in base64 `update_chunk` messages. Once every chunk has arrived the
+//! binary is checked against its size and SHA-256, and the Ed25519 signature
+//! of the release version, SHA-256 and size against the public key of the
+//! agent configuration, before it replaces the running executable.
+
+use anyhow::{anyhow, bail, Context, Result};
+use ring::digest::{digest, SHA256};
+use ring::signature::{UnparsedPublicKey, ED25519};
+use serde::Deserialize;
+use std::path::PathBuf;
+use std::sync::Mutex;
+use tracing::warn;
+
+/// Version of this agent build, reported to the server.
+pub const AGENT_VERSION: &str = env!("CARGO_PKG_VERSION");
+
+/// Largest release accepted, well above the server default.
+const MAX_UPDATE_SIZE: u64 = 64 * 1024 * 1024;
+
+/// Largest chunk accepted, twice the server chunk size.
+const MAX_CHUNK_SIZE: u64 = 1024 * 1024;
+
+/// Release announced by the server with an `update` message.
+#[derive(Debug, Clone, Deserialize)]
+pub struct UpdateOffer {
+    /// Version of the release.
+    pub version: String,
+    /// Hex SHA-256 of the binary.
+    pub sha256: String,
+    /// Base64 Ed25519 signature of the release message, see `release_message`.
+    pub signature: String,
+    /// Binary size in bytes.
+    pub size: u64,
+    /// Number of `update_chunk` messages the binary is sent in.
+    pub chunks: usize,
+    /// Size of every chunk but the last, in bytes.
+    pub chunk_size: u64,
+}
+
+/// Slice of a release binary sent with an `update_chunk` message.
+#[derive(Debug, Deserialize)]
+pub struct UpdateChunk {
+    /// Version of the release the chunk belongs to.
+    pub version: String,
+    /// Position of the chunk, from 0.
+    pub index: usize,
+    /// Base64 chunk content.
+    pub data: String,
+}
+
+/// Release being received.
+struct PendingUpdate {
+    offer: UpdateOffer,
+    chunks: Vec<Option<Vec<u8>>>,
+}
+
+/// Receives and verifies the releases pushed by the server.
+pub struct Updater {
+    public_key: Option<Vec<u8>>,
+    pending: Mutex<Option<PendingUpdate>>,
+}
+
+impl Updater {
+    /// Creates an updater verifying releases with a base64 Ed25519 public
+    /// key. Updates are disabled without a valid key.
+    pub fn new(public_key: Option<&str>) -> Self {
+        let public_key = public_key.and_then(|key| match base64::decode(key) {
+            Ok(key) if key.len() == 32 => Some(key),
+            _ => {
+                warn!("Invalid update public key, self-updates are disabled");
+                None
+            }
+        });
+        Self {
+            public_key,
+            pending: Mutex::new(None),
+        }
+    }
+
+    /// Returns true when releases can be verified and installed.
+    pub fn enabled(&self) -> bool {
+        self.public_key.is_some()
+    }
+
+    /// Starts receiving a release. Releases that are not newer than this
+    /// build are refused.
+    pub fn begin(&self, offer: UpdateOffer) -> Result<()> {
+        if !self.enabled() {
+            bail!("self-updates are disabled, no update public key is configured");
+        }
+        if !is_newer(&offer.version, AGENT_VERSION) {
+            bail!(
+                "release {} is not newer than {}",
+                offer.version,
+                AGENT_VERSION
+            );
+        }
+        // Checked before the chunk list is allocated
+        if offer.size == 0
+            || offer.size > MAX_UPDATE_SIZE
+            || offer.chunk_size == 0
+            || offer.chunk_size > MAX_CHUNK_SIZE
+            || offer.chunks as u64 != offer.size.div_ceil(offer.chunk_size)
+        {
+            bail!(
+                "release {} has an invalid size or chunk count",
+                offer.version
+            );
+        }
+
+        let chunks = vec![None; offer.chunks];
+        *self.lock() = Some(PendingUpdate { offer, chunks });
+        Ok(())
+    }
+
+    /// Stores a chunk of the pending release. Once the last chunk arrives the
+    /// binary is verified and returned with the release version.
+    pub fn add_chunk(&self, chunk: UpdateChunk) -> Result<Option<(String, Vec<u8>)>> {
+        let mut pending = self.lock();
+        let update = match pending.as_mut() {
+            Some(update) if update.offer.version == chunk.version => update,
+            _ => bail!("no pending release {}", chunk.version),
+        };
+        if chunk.index >= update.chunks.len() {
+            bail!(
+                "chunk {} out of range for release {}",
+                chunk.index,
+                chunk.version
+            );
+        }
+        let data = base64::decode(&chunk.data).context("Invalid chunk encoding")?;
+        if data.len() as u64 != update.offer.chunk_len(chunk.index) {
+            bail!(
+                "chunk {} of release {} is {} bytes, {} expected",
+                chunk.index,
+                chunk.version,
+                data.len(),
+                update.offer.chunk_len(chunk.index)
+            );
+        }
+        update.chunks[chunk.index] = Some(data);
+        if update.chunks.iter().any(|c| c.is_none()) {
+            return Ok(None);
+        }
+
+        let update = pending.take().expect("pending release");
+        let binary: Vec<u8> = update.chunks.into_iter().flatten().flatten().collect();
+        let public_key = self.public_key.as_deref().expect("updates enabled");
+        verify(public_key, &update.offer, &binary)?;
+        Ok(Some((update.offer.version, binary)))
+    }
+
+    fn lock(&self) -> std::sync::MutexGuard<'_, Option<PendingUpdate>> {
+        self.pending.lock().unwrap_or_else(|e| e.into_inner())
+    }
+}
+
+impl UpdateOffer {
+    /// Returns the size of a chunk: `chunk_size`, or the rest for the last one.
+    fn chunk_len(&self, index: usize) -> u64 {
+        if index + 1 == self.chunks {
+            self.size - self.chunk_size * (self.chunks as u64 - 1)
+        } else {
+            self.chunk_size
+        }
+    }
+}
+
+/// Returns the message a release is signed over: its version, the lowercase
+/// hex SHA-256 and the size of the binary, one per line after a fixed header.
+pub fn release_message(version: &str, sha256: &str, size: u64) -> Vec<u8> {
+    format!(
+        "autostrike-agent-release\n{}\n{}\n{}",
+        version,
+        sha256.to_ascii_lowercase(),
+        size
+    )
+    .into_bytes()
+}
+
+/// Checks a release binary against the size and SHA-256 announced with it,
+/// and the signature of its version, SHA-256 and size. The signed version
+/// must be newer than this build, so an older release cannot be replayed.
+pub fn verify(public_key: &[u8], offer: &UpdateOffer, binary: &[u8]) -> Result<()> {
+    if !is_newer(&offer.version, AGENT_VERSION) {
+        bail!(
+            "release {} is not newer than {}",
+            offer.version,
+            AGENT_VERSION
+        );
+    }
+    if binary.len() as u64 != offer.size {
+        bail!(
+            "release is {} bytes, {} announced",
+            binary.len(),
+            offer.size
+        );
+    }
+
+    let sum: String = digest(&SHA256, binary)
+        .as_ref()
+        .iter()
+        .map(|b| format!("{:02x}", b))
+        .collect();
+    if !sum.eq_ignore_ascii_case(&offer.sha256) {
+        bail!("release checksum mismatch");
+    }
+
+    let signature = base64::decode(&offer.signature).context("Invalid signature encoding")?;
+    let message = release_message(&offer.version, &sum, offer.size);
+    UnparsedPublicKey::new(&ED25519, public_key)
+        .verify(&message, &signature)
+        .map_err(|_| anyhow!("release signature does not verify"))
+}
+
+/// Replaces the running executable with a verified release binary and
+/// returns its path.
+pub fn install(binary: &[u8]) -> Result<PathBuf> {
+    let exe = std::env::current_exe().context("Failed to locate the agent executable")?;
+    let mut staged = exe.clone().into_os_string();
+    staged.push(".new");
+    let staged = PathBuf::from(staged);
+
+    std::fs::write(&staged, binary).context("Failed to write the release")?;
+    #[cfg(unix)]
+    {
+        use std::os::unix::fs::PermissionsExt;
+        std::fs::set_permissions(&staged, std::fs::Permissions::from_mode(0o755))?;
+    }
+    #[cfg(windows)]
+    {
+        // A running executable cannot be overwritten, but it can be renamed
+        let mut old = exe.clone().into_os_string();
+        old.push(".old");
+        let _ = std::fs::remove_file(&old);
+        std::fs::rename(&exe, &old).context("Failed to move the running executable")?;
+    }
+    std::fs::rename(&staged, &exe).context("Failed to install the release")?;
+    Ok(exe)
+}
+
+/// Restarts the agent from its installed executable with the same arguments.
+/// On Unix the process is replaced in place, keeping its PID for supervisors.
+pub fn restart(exe: &std::path::Path) -> Result<()> {
+    let args: Vec<String> = std::env::args().skip(1).collect();
+    #[cfg(unix)]
+    {
+        use std::os::unix::process::CommandExt;
+        let err = std::process::Command::new(exe).args(&args).exec();
+        return Err(anyhow!("Failed to restart the agent: {}", err));
+    }
+    #[cfg(not(unix))]
+    {
+        std::process::Command::new(exe)
+            .args(&args)
+            .spawn()
+            .context("Failed to restart the agent")?;
+        std::process::exit(0);
+    }
+}
+
+/// Returns true when version `candidate` is newer than `current`. Both are
+/// MAJOR.MINOR.PATCH with an optional "v" prefix; a candidate that does not
+/// parse is never newer.
+pub fn is_newer(candidate: &str, current: &str) -> bool {
+    fn parse(version: &str) -> Option<(u64, u64, u64)> {
+        let mut parts = version.trim_start_matches('v').split('.');
+        let major = parts.next()?.parse().ok()?;
+        let minor = parts.next()?.parse().ok()?;
+        let patch = parts.next()?.parse().ok()?;
+        match parts.next() {
+            Some(_) => None,
+            None => Some((major, minor, patch)),
+        }
+    }
+
+    match (parse(candidate), parse(current)) {
+        (Some(candidate), Some(current)) => candidate > current,
+        (Some(_), None) => true,
+        _ => false,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use ring::rand::SystemRandom;
+    use ring::signature::{Ed25519KeyPair, KeyPair};
+
+    fn key_pair() -> Ed25519KeyPair {
+        let pkcs8 = Ed25519KeyPair::generate_pkcs8(&SystemRandom::new()).unwrap();
+        Ed25519KeyPair::from_pkcs8(pkcs8.as_ref()).unwrap()
+    }
+
+    fn sha256_hex(binary: &[u8]) -> String {
+        digest(&SHA256, binary)
+            .as_ref()
+            .iter()
+            .map(|b| format!("{:02x}", b))
+            .collect()
+    }
+
+    fn offer_for(key: &Ed25519KeyPair, binary: &[u8], chunk_size: u64) -> UpdateOffer {
+        let version = "999.0.0".to_string();
+        let sha256 = sha256_hex(binary);
+        let size = binary.len() as u64;
+        let message = release_message(&version, &sha256, size);
+        UpdateOffer {
+            signature: base64::encode(key.sign(&message).as_ref()),
+            version,
+            sha256,
+            size,
+            chunks: size.div_ceil(chunk_size) as usize,
+            chunk_size,
+        }
+    }
+
+    fn chunk(index: usize, data: &[u8]) -> UpdateChunk {
+        UpdateChunk {
+            version: "999.0.0".to_string(),
+            index,
+            data: base64::encode(data),
+        }
+    }
+
+    #[test]
+    fn test_is_newer() {
+        assert!(is_newer("1.2.0", "1.1.9"));
+        assert!(is_newer("v2.0.0", "1.10.0"));
+        assert!(is_newer("0.2.0", "unknown"));
+        assert!(!is_newer("1.2.0", "1.2.0"));
+        assert!(!is_newer("1.2", "1.1.0"));
+        assert!(!is_newer("1.2.0-rc1", "1.1.0"));
+    }
+
+    #[test]
+    fn test_updater_disabled_without_key() {
+        let key = key_pair();
+        let updater = Updater::new(None);
+        assert!(!updater.enabled());
+        assert!(updater.begin(offer_for(&key, b"agent", 8)).is_err());
+        assert!(!Updater::new(Some("not-a-key")).enabled());
+    }
+
+    #[test]
+    fn test_updater_assembles_and_verifies_chunks() {
+        let key = key_pair();
+        let public_key = base64::encode(key.public_key().as_ref());
+        let updater = Updater::new(Some(&public_key));
+        assert!(updater.enabled());
+
+        updater.begin(offer_for(&key, b"agent-binary", 6)).unwrap();
+        assert!(updater.add_chunk(chunk(1, b"binary")).unwrap().is_none());
+        assert!(updater.add_chunk(chunk(5, b"x")).is_err());
+        assert!(updater.add_chunk(chunk(0, b"agent-b")).is_err());
+        let (version, binary) = updater.add_chunk(chunk(0, b"agent-")).unwrap().unwrap();
+        assert_eq!(version, "999.0.0");
+        assert_eq!(binary, b"agent-binary");
+
+        // The pending release is cleared once complete
+        assert!(updater.add_chunk(chunk(0, b"agent-")).is_err());
+    }
+
+    #[test]
+    fn test_updater_rejects_tampered_binary() {
+        let key = key_pair();
+        let updater = Updater::new(Some(&base64::encode(key.public_key().as_ref())));
+
+        let mut offer = offer_for(&key, b"agent-binary", 16);
+        offer.sha256 = sha256_hex(b"agent-binarY");
+        updater.begin(offer).unwrap();
+        assert!(updater.add_chunk(chunk(0, b"agent-binarY")).is_err());
+
+        // Signed with another key
+        let other = key_pair();
+        updater
+            .begin(offer_for(&other, b"agent-binary", 16))
+            .unwrap();
+        let err = updater.add_chunk(chunk(0, b"agent-binary")).unwrap_err();
+        assert!(err.to_string().contains("signature"));
+    }
+
+    #[test]
+    fn test_verify_binds_the_version() {
+        let key = key_pair();
+        let public_key = key.public_key().as_ref().to_vec();
+        let binary = b"agent-binary";
+
+        let offer = offer_for(&key, binary, 16);
+        assert!(verify(&public_key, &offer, binary).is_ok());
+
+        // A release signed as 0.0.1 offered again under a newer version
+        let mut relabeled = offer_for(&key, binary, 16);
+        let message = release_message("0.0.1", &relabeled.sha256, relabeled.size);
+        relabeled.signature = base64::encode(key.sign(&message).as_ref());
+        let err = verify(&public_key, &relabeled, binary).unwrap_err();
+        assert!(err.to_string().contains("signature"));
+
+        // Signed over the binary alone, as before
+        let mut binary_only = offer_for(&key, binary, 16);
+        binary_only.signature = base64::encode(key.sign(binary).as_ref());
+        assert!(verify(&public_key, &binary_only, binary).is_err());
+
+        // Not newer than this build, even when correctly signed
+        let mut older = offer_for(&key, binary, 16);
+        older.version = "0.0.1".to_string();
+        let message = release_message(&older.version, &older.sha256, older.size);
+        older.signature = base64::encode(key.sign(&message).as_ref());
+        let err = verify(&public_key, &older, binary).unwrap_err();
+        assert!(err.to_string().contains("not newer"));
+    }
+
+    #[test]
+    fn test_updater_refuses_invalid_chunking() {
+        let key = key_pair();
+        let updater = Updater::new(Some(&base64::encode(key.public_key().as_ref())));
+
+        let mut offer = offer_for(&key, b"agent-binary", 6);
+        offer.chunks = usize::MAX;
+        assert!(updater.begin(offer).is_err());
+
+        let mut offer = offer_for(&key, b"agent-binary", 6);
+        offer.chunk_size = 0;
+        assert!(updater.begin(offer).is_err());
+
+        let mut offer = offer_for(&key, b"agent-binary", 6);
+        offer.size = MAX_UPDATE_SIZE + 1;
+        offer.chunks = offer.size.div_ceil(offer.chunk_size) as usize;
+        assert!(updater.begin(offer).is_err());
+
+        let mut offer = offer_for(&key, b"agent-binary", 6);
+        offer.chunk_size = MAX_CHUNK_SIZE + 1;
+        offer.chunks = 1;
+        assert!(updater.begin(offer).is_err());
+    }
+
+    #[test]
+    fn test_updater_refuses_older_release() {
+        let key = key_pair();
+        let updater = Updater::new(Some(&base64::encode(key.public_key().as_ref())));
+        let mut offer = offer_for(&key, b"agent", 8);
+        offer.version = "0.0.1".to_string();
+        assert!(updater.begin(offer).is_err());
+    }
+}
@@ -13,6 +13,8 @@ import Settings from './pages/Settings';
 import Matrix from './pages/Matrix';
 import Analytics from './pages/Analytics';
 import Scheduler from './pages/Scheduler';
+import OpenLink from './pages/OpenLink';
+import ResetPassword from './pages/ResetPassword';
 import AdminUsers from './pages/Admin/Users';
 import AdminPermissions from './pages/Admin/Permissions';
 
@@ -26,8 +28,9 @@ function App() {
   return (
     <ErrorBoundary>
       <Routes>
-        {/* Public route */}
+        {/* Public routes */}
         <Route path="/login" element={<Login />} />
+        <Route path="/reset-password" element={<ResetPassword />} />
 
         {/* Protected routes */}
         <Route
@@ -44,6 +47,8 @@ function App() {
                   <Route path="/scenarios" element={<Scenarios />} />
                   <Route path="/executions" element={<Executions />} />
                   <Route path="/executions/:id" element={<ExecutionDetails />} />
+                  {/* Signed notification links, resolved after login */}
+                  <Route path="/links/:token" element={<OpenLink />} />
                   {/* Analytics - requires analyst role or higher */}
                   <Route
                     path="/analytics"
@@ -70,6 +70,24 @@ describe('MitreMatrix', () => {
     expect(screen.getByText('Impact')).toBeInTheDocument();
   });
 
+  it('shows the ICS tactics only when techniques use them', () => {
+    const { rerender } = render(<MitreMatrix techniques={mockTechniques} />);
+    expect(screen.queryByText('Inhibit Response Function')).not.toBeInTheDocument();
+
+    const serviceStop: Technique = {
+      id: 'T0881',
+      name: 'Service Stop',
+      description: 'Adversaries may stop services.',
+      tactic: 'inhibit_response_function',
+      platforms: ['linux'],
+      is_safe: false,
+      detection: [],
+    };
+    rerender(<MitreMatrix techniques={[...mockTechniques, serviceStop]} />);
+    expect(screen.getByText('Inhibit Response Function')).toBeInTheDocument();
+    expect(screen.queryByText('Impair Process Control')).not.toBeInTheDocument();
+  });
+
   it('renders techniques in correct tactic columns', () => {
     render(<MitreMatrix techniques={mockTechniques} />);
 
@@ -260,6 +278,25 @@ describe('MitreMatrix', () => {
     expect(screen.getByText('Abuse Elevation Control Mechanism')).toBeInTheDocument();
   });
 
+  it('shows a technique under each of its tactics', () => {
+    const multiTactic: Technique[] = [
+      {
+        id: 'T1078',
+        name: 'Valid Accounts',
+        description: 'Test',
+        tactic: 'initial-access' as Technique['tactic'],
+        tactics: ['initial-access', 'persistence'] as Technique['tactic'][],
+        platforms: ['windows'],
+        is_safe: true,
+        detection: [],
+      },
+    ];
+
+    render(<MitreMatrix techniques={multiTactic} />);
+
+    expect(screen.getAllByText('T1078')).toHaveLength(2);
+  });
+
   it('sorts techniques by ID within each tactic', () => {
     const unsortedTechniques: Technique[] = [
       {
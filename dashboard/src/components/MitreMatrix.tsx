@@ -2,9 +2,10 @@ import { useState, useEffect } from 'react';
 import { Technique, TacticType } from '../types';
 
 /**
- * Ordered list of MITRE ATT&CK tactics for matrix display.
+ * Ordered list of MITRE ATT&CK tactics for matrix display. ICS tactics are only
+ * shown when techniques use them.
  */
-const TACTICS: { id: TacticType; name: string }[] = [
+const TACTICS: { id: TacticType; name: string; ics?: boolean }[] = [
   { id: 'reconnaissance', name: 'Reconnaissance' },
   { id: 'resource_development', name: 'Resource Development' },
   { id: 'initial_access', name: 'Initial Access' },
@@ -12,12 +13,15 @@ const TACTICS: { id: TacticType; name: string }[] = [
   { id: 'persistence', name: 'Persistence' },
   { id: 'privilege_escalation', name: 'Privilege Escalation' },
   { id: 'defense_evasion', name: 'Defense Evasion' },
+  { id: 'evasion', name: 'Evasion', ics: true },
   { id: 'credential_access', name: 'Credential Access' },
   { id: 'discovery', name: 'Discovery' },
   { id: 'lateral_movement', name: 'Lateral Movement' },
   { id: 'collection', name: 'Collection' },
   { id: 'command_and_control', name: 'C2' },
   { id: 'exfiltration', name: 'Exfiltration' },
+  { id: 'inhibit_response_function', name: 'Inhibit Response Function', ics: true },
+  { id: 'impair_process_control', name: 'Impair Process Control', ics: true },
   { id: 'impact', name: 'Impact' },
 ];
 
@@ -39,6 +43,9 @@ const tacticHeaderColors: Record<TacticType, string> = {
   command_and_control: 'bg-amber-600',
   exfiltration: 'bg-rose-600',
   impact: 'bg-red-700',
+  evasion: 'bg-emerald-600',
+  inhibit_response_function: 'bg-fuchsia-600',
+  impair_process_control: 'bg-violet-600',
 };
 
 /**
@@ -59,6 +66,9 @@ const tacticCellColors: Record<TacticType, string> = {
   command_and_control: 'bg-amber-50 hover:bg-amber-100 border-amber-200 dark:bg-amber-900/30 dark:hover:bg-amber-900/50 dark:border-amber-700',
   exfiltration: 'bg-rose-50 hover:bg-rose-100 border-rose-200 dark:bg-rose-900/30 dark:hover:bg-rose-900/50 dark:border-rose-700',
   impact: 'bg-red-50 hover:bg-red-100 border-red-200 dark:bg-red-900/30 dark:hover:bg-red-900/50 dark:border-red-700',
+  evasion: 'bg-emerald-50 hover:bg-emerald-100 border-emerald-200 dark:bg-emerald-900/30 dark:hover:bg-emerald-900/50 dark:border-emerald-700',
+  inhibit_response_function: 'bg-fuchsia-50 hover:bg-fuchsia-100 border-fuchsia-200 dark:bg-fuchsia-900/30 dark:hover:bg-fuchsia-900/50 dark:border-fuchsia-700',
+  impair_process_control: 'bg-violet-50 hover:bg-violet-100 border-violet-200 dark:bg-violet-900/30 dark:hover:bg-violet-900/50 dark:border-violet-700',
 };
 
 interface MitreMatrixProps {
@@ -78,15 +88,17 @@ export function MitreMatrix({ techniques, onTechniqueClick }: Readonly<MitreMatr
   const techniquesByTactic = TACTICS.reduce((acc, tactic) => {
     acc[tactic.id] = techniques
       .filter(t => {
-        // Map backend tactic format to frontend format
-        const techTactic = String(t.tactic).replaceAll('-', '_');
-        const matches = techTactic === tactic.id;
+        // Map backend tactic format to frontend format; a technique shows under each of its tactics
+        const techTactics = (t.tactics?.length ? t.tactics : [t.tactic]).map(name => String(name).replaceAll('-', '_'));
+        const matches = techTactics.includes(tactic.id);
         const platformMatches = platformFilter === 'all' || t.platforms.includes(platformFilter);
         return matches && platformMatches;
       })
+      // Sorting by ID keeps sub-techniques (T1059.001) right after their parent
       .sort((a, b) => a.id.localeCompare(b.id));
     return acc;
   }, {} as Record<TacticType, Technique[]>);
+  const visibleTactics = TACTICS.filter(tactic => !tactic.ics || techniquesByTactic[tactic.id].length > 0);
 
   const handleTechniqueClick = (technique: Technique) => {
     setSelectedTechnique(technique);
@@ -137,9 +149,9 @@ export function MitreMatrix({ techniques, onTechniqueClick }: Readonly<MitreMatr
 
       {/* Matrix Grid */}
       <div className="overflow-x-auto">
-        <div className="inline-grid" style={{ gridTemplateColumns: `repeat(${TACTICS.length}, minmax(120px, 1fr))` }}>
+        <div className="inline-grid" style={{ gridTemplateColumns: `repeat(${visibleTactics.length}, minmax(120px, 1fr))` }}>
           {/* Header Row */}
-          {TACTICS.map(tactic => (
+          {visibleTactics.map(tactic => (
             <div
               key={tactic.id}
               className={`${tacticHeaderColors[tactic.id]} text-white text-xs font-semibold p-2 text-center border-r border-white/20`}
@@ -153,13 +165,13 @@ export function MitreMatrix({ techniques, onTechniqueClick }: Readonly<MitreMatr
           ))}
 
           {/* Technique Cells */}
-          {TACTICS.map(tactic => (
+          {visibleTactics.map(tactic => (
             <div key={`col-${tactic.id}`} className="flex flex-col gap-1 p-1 bg-gray-50 dark:bg-gray-800 min-h-[200px]">
               {techniquesByTactic[tactic.id]?.map(technique => (
                 <button
                   key={technique.id}
                   onClick={() => handleTechniqueClick(technique)}
-                  className={`${tacticCellColors[tactic.id]} p-2 rounded border text-left transition-all cursor-pointer`}
+                  className={`${tacticCellColors[tactic.id]} ${technique.parent_id ? 'ml-3' : ''} p-2 rounded border text-left transition-all cursor-pointer`}
                   title={`${technique.id}: ${technique.name}`}
                 >
                   <div className="text-xs font-mono text-gray-600 dark:text-gray-400">{technique.id}</div>
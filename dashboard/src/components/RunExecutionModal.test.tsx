@@ -492,4 +492,30 @@ describe('RunExecutionModal', () => {
     const runButton = screen.getByRole('button', { name: /Run on 0 agents/i });
     expect(runButton).toBeDisabled();
   });
+
+  it('shows scenario readiness before launch', async () => {
+    const readiness = {
+      scenario_id: 'scenario-1',
+      scenario_name: 'Test Scenario',
+      score: 66.7,
+      total_techniques: 3,
+      ready_techniques: 2,
+      online_agents: 2,
+      missing_platforms: ['darwin'],
+      unsafe_techniques: ['T1057'],
+      unknown_techniques: [],
+      techniques: [],
+    };
+    vi.mocked(api.get).mockImplementation(((url: string) =>
+      Promise.resolve({ data: url.endsWith('/readiness') ? readiness : mockAgents })) as never);
+
+    renderModal();
+
+    await waitFor(() => {
+      expect(screen.getByTestId('scenario-readiness')).toBeInTheDocument();
+    });
+    expect(screen.getByText('66.7%')).toBeInTheDocument();
+    expect(screen.getByText('Missing platforms: darwin')).toBeInTheDocument();
+    expect(screen.getByText(/skipped in safe mode/)).toBeInTheDocument();
+  });
 });
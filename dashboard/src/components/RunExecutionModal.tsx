@@ -1,7 +1,7 @@
 import { useState } from 'react';
 import { useQuery } from '@tanstack/react-query';
 import { PlayIcon, ComputerDesktopIcon } from '@heroicons/react/24/outline';
-import { api } from '../lib/api';
+import { api, ScenarioReadiness } from '../lib/api';
 import { Agent, Scenario } from '../types';
 
 interface RunExecutionModalProps {
@@ -26,6 +26,15 @@ export function RunExecutionModal({ scenario, onConfirm, onCancel, isLoading }:
 
   const onlineAgents = agents?.filter(a => a.status === 'online') || [];
 
+  // Readiness for the selected agents, or the whole online fleet when none are selected
+  const { data: readiness } = useQuery<ScenarioReadiness>({
+    queryKey: ['scenario-readiness', scenario.id, selectedAgents],
+    queryFn: () => api.get(`/scenarios/${scenario.id}/readiness`, {
+      params: selectedAgents.length > 0 ? { agents: selectedAgents.join(',') } : undefined,
+    }).then(res => res.data),
+  });
+  const hasReadiness = typeof readiness?.score === 'number' && Array.isArray(readiness.missing_platforms);
+
   const handleAgentToggle = (paw: string) => {
     setSelectedAgents(prev =>
       prev.includes(paw)
@@ -74,6 +83,36 @@ export function RunExecutionModal({ scenario, onConfirm, onCancel, isLoading }:
                   </div>
                 </div>
 
+                {/* Readiness */}
+                {hasReadiness && readiness && (
+                  <div className="mt-3 p-3 border border-gray-200 dark:border-gray-600 rounded-md" data-testid="scenario-readiness">
+                    <p className="text-sm font-medium text-gray-900 dark:text-gray-100">
+                      Readiness:{' '}
+                      <span className={readiness.score >= 80 ? 'text-green-600 dark:text-green-400' : 'text-amber-600 dark:text-amber-400'}>
+                        {readiness.score}%
+                      </span>
+                      <span className="ml-1 text-xs text-gray-500 dark:text-gray-400">
+                        ({readiness.ready_techniques}/{readiness.total_techniques} techniques runnable)
+                      </span>
+                    </p>
+                    {readiness.missing_platforms.length > 0 && (
+                      <p className="text-xs text-amber-600 dark:text-amber-400 mt-1">
+                        Missing platforms: {readiness.missing_platforms.join(', ')}
+                      </p>
+                    )}
+                    {readiness.unsafe_techniques.length > 0 && (
+                      <p className="text-xs text-red-600 dark:text-red-400 mt-1">
+                        Unsafe techniques requiring approval ({safeMode ? 'skipped in safe mode' : 'will run'}): {readiness.unsafe_techniques.join(', ')}
+                      </p>
+                    )}
+                    {readiness.unknown_techniques.length > 0 && (
+                      <p className="text-xs text-gray-500 dark:text-gray-400 mt-1">
+                        Unknown techniques: {readiness.unknown_techniques.join(', ')}
+                      </p>
+                    )}
+                  </div>
+                )}
+
                 {/* Agent Selection */}
                 <div className="mt-4">
                   <div className="flex justify-between items-center mb-2">
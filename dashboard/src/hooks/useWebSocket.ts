@@ -5,9 +5,15 @@ export interface WebSocketMessage {
   payload: {
     execution_id?: string;
     data?: unknown;
+    status?: string;
+    paw?: string;
+    unread?: number;
   };
 }
 
+// The access token is offered as a subprotocol, as browsers cannot set headers on WebSocket requests
+const EVENTS_PROTOCOL = 'autostrike.events';
+
 interface UseWebSocketOptions {
   onMessage?: (message: WebSocketMessage) => void;
   onConnect?: () => void;
@@ -55,7 +61,9 @@ export function useWebSocket(options: UseWebSocketOptions = {}): UseWebSocketRet
 
     try {
       const url = getWebSocketUrl();
-      wsRef.current = new WebSocket(url);
+      const token = localStorage.getItem('token');
+      const protocols = token ? [EVENTS_PROTOCOL, `bearer.${token}`] : [EVENTS_PROTOCOL];
+      wsRef.current = new WebSocket(url, protocols);
 
       wsRef.current.onopen = () => {
         setIsConnected(true);
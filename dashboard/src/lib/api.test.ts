@@ -211,6 +211,7 @@ describe('executionApi', () => {
     expect(typeof executionApi.start).toBe('function');
     expect(typeof executionApi.stop).toBe('function');
     expect(typeof executionApi.complete).toBe('function');
+    expect(typeof executionApi.export).toBe('function');
   });
 });
 
@@ -222,6 +223,8 @@ describe('authApi', () => {
     expect(typeof authApi.refresh).toBe('function');
     expect(typeof authApi.logout).toBe('function');
     expect(typeof authApi.me).toBe('function');
+    expect(typeof authApi.forgotPassword).toBe('function');
+    expect(typeof authApi.resetPassword).toBe('function');
   });
 });
 
@@ -451,6 +454,30 @@ describe('adminApi', () => {
     expect(typeof adminApi.deactivateUser).toBe('function');
     expect(typeof adminApi.reactivateUser).toBe('function');
     expect(typeof adminApi.resetPassword).toBe('function');
+    expect(typeof adminApi.listActivityAnomalies).toBe('function');
+    expect(typeof adminApi.recomputeScores).toBe('function');
+    expect(typeof adminApi.getRecomputeJob).toBe('function');
+    expect(typeof adminApi.listLockouts).toBe('function');
+    expect(typeof adminApi.unlockUser).toBe('function');
+    expect(typeof adminApi.unlockIP).toBe('function');
+  });
+
+  it('adminApi calls the login lockout endpoints', async () => {
+    const { api, adminApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    const deleteSpy = vi.spyOn(api, 'delete').mockResolvedValue({ data: {} });
+
+    await adminApi.listLockouts();
+    expect(getSpy).toHaveBeenCalledWith('/admin/lockouts');
+    await adminApi.unlockUser('user-1');
+    expect(postSpy).toHaveBeenCalledWith('/admin/users/user-1/unlock');
+    await adminApi.unlockIP('2001:db8::1');
+    expect(deleteSpy).toHaveBeenCalledWith('/admin/lockouts/ips/2001%3Adb8%3A%3A1');
+
+    getSpy.mockRestore();
+    postSpy.mockRestore();
+    deleteSpy.mockRestore();
   });
 });
 
@@ -466,6 +493,8 @@ describe('scenarioApi', () => {
     expect(typeof scenarioApi.exportAll).toBe('function');
     expect(typeof scenarioApi.exportOne).toBe('function');
     expect(typeof scenarioApi.import).toBe('function');
+    expect(typeof scenarioApi.readiness).toBe('function');
+    expect(typeof scenarioApi.lifecycleReport).toBe('function');
   });
 });
 
@@ -486,6 +515,8 @@ describe('analyticsApi', () => {
     expect(typeof analyticsApi.trend).toBe('function');
     expect(typeof analyticsApi.summary).toBe('function');
     expect(typeof analyticsApi.periodStats).toBe('function');
+    expect(typeof analyticsApi.sigmaCoverage).toBe('function');
+    expect(typeof analyticsApi.calendar).toBe('function');
   });
 });
 
@@ -626,6 +657,52 @@ describe('API method invocations', () => {
     getSpy.mockRestore();
   });
 
+  it('adminApi.listActivityAnomalies passes the limit', async () => {
+    const { api, adminApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    await adminApi.listActivityAnomalies(20);
+    expect(getSpy).toHaveBeenCalledWith('/admin/activity/anomalies', { params: { limit: 20 } });
+    getSpy.mockRestore();
+  });
+
+  it('adminApi.recomputeScores posts the request', async () => {
+    const { api, adminApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: { id: 'job-1' } });
+    await adminApi.recomputeScores({ dry_run: true });
+    expect(postSpy).toHaveBeenCalledWith('/admin/scores/recompute', { dry_run: true });
+    await adminApi.recomputeScores();
+    expect(postSpy).toHaveBeenCalledWith('/admin/scores/recompute', {});
+    postSpy.mockRestore();
+  });
+
+  it('adminApi calls the technique sync endpoints', async () => {
+    const { api, adminApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: { id: 'sync-1' } });
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    await adminApi.syncMitreTechniques(true);
+    expect(postSpy).toHaveBeenCalledWith('/admin/techniques/sync-mitre', { deprecate_removed: true });
+    await adminApi.syncMitreTechniques();
+    expect(postSpy).toHaveBeenCalledWith('/admin/techniques/sync-mitre', { deprecate_removed: false });
+    await adminApi.listTechniqueSyncJobs();
+    expect(getSpy).toHaveBeenCalledWith('/admin/techniques/sync-mitre');
+    await adminApi.getTechniqueSyncJob('sync-1');
+    expect(getSpy).toHaveBeenCalledWith('/admin/techniques/sync-mitre/sync-1');
+    postSpy.mockRestore();
+    getSpy.mockRestore();
+  });
+
+  it('jobApi lists and gets background jobs', async () => {
+    const { api, jobApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    await jobApi.list();
+    expect(getSpy).toHaveBeenCalledWith('/jobs', { params: { type: undefined, limit: 100 } });
+    await jobApi.list('content_import', 20);
+    expect(getSpy).toHaveBeenCalledWith('/jobs', { params: { type: 'content_import', limit: 20 } });
+    await jobApi.get('job-1');
+    expect(getSpy).toHaveBeenCalledWith('/jobs/job-1');
+    getSpy.mockRestore();
+  });
+
   it('adminApi.listUsers defaults to includeInactive=false', async () => {
     const { api, adminApi } = await import('./api');
     const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: { users: [], total: 0 } });
@@ -723,6 +800,22 @@ describe('API method invocations', () => {
     getSpy.mockRestore();
   });
 
+  it('scenarioApi.readiness passes selected agents', async () => {
+    const { api, scenarioApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+    await scenarioApi.readiness('sc-1', ['paw-1', 'paw-2']);
+    expect(getSpy).toHaveBeenCalledWith('/scenarios/sc-1/readiness', { params: { agents: 'paw-1,paw-2' } });
+    getSpy.mockRestore();
+  });
+
+  it('scenarioApi.lifecycleReport calls correct endpoint', async () => {
+    const { api, scenarioApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    await scenarioApi.lifecycleReport();
+    expect(getSpy).toHaveBeenCalledWith('/scenarios/lifecycle-report');
+    getSpy.mockRestore();
+  });
+
   it('scenarioApi.import posts data correctly', async () => {
     const { api, scenarioApi } = await import('./api');
     const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
@@ -750,6 +843,25 @@ describe('API method invocations', () => {
     putSpy.mockRestore();
   });
 
+  it('scenarioApi.clone posts the optional name', async () => {
+    const { api, scenarioApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    await scenarioApi.clone('template-apt29', 'Team APT29');
+    expect(postSpy).toHaveBeenCalledWith('/scenarios/template-apt29/clone', { name: 'Team APT29' });
+    await scenarioApi.clone('template-apt29');
+    expect(postSpy).toHaveBeenCalledWith('/scenarios/template-apt29/clone', {});
+    postSpy.mockRestore();
+  });
+
+  it('scenarioApi.validate posts the draft', async () => {
+    const { api, scenarioApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    const data = { name: 'draft', phases: [], platforms: ['linux'], safe_mode: true };
+    await scenarioApi.validate(data);
+    expect(postSpy).toHaveBeenCalledWith('/scenarios/validate', data);
+    postSpy.mockRestore();
+  });
+
   it('scenarioApi.delete calls delete endpoint', async () => {
     const { api, scenarioApi } = await import('./api');
     const deleteSpy = vi.spyOn(api, 'delete').mockResolvedValue({ data: {} });
@@ -770,6 +882,172 @@ describe('API method invocations', () => {
     postSpy.mockRestore();
   });
 
+  it('executionApi.start posts the scoring profile', async () => {
+    const { api, executionApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    await executionApi.start('scenario-1', ['paw-1'], false, 'prof-1');
+    expect(postSpy).toHaveBeenCalledWith('/executions', {
+      scenario_id: 'scenario-1',
+      agent_paws: ['paw-1'],
+      safe_mode: false,
+      scoring_profile_id: 'prof-1',
+    });
+    postSpy.mockRestore();
+  });
+
+  it('executionApi.startWithSelector posts the selector instead of agents', async () => {
+    const { api, executionApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    await executionApi.startWithSelector('scenario-1', 'sel-1', false);
+    expect(postSpy).toHaveBeenCalledWith('/executions', {
+      scenario_id: 'scenario-1',
+      agent_selector_id: 'sel-1',
+      safe_mode: false,
+    });
+    postSpy.mockRestore();
+  });
+
+  it('agentSelectorApi calls the agent-selectors endpoints', async () => {
+    const { api, agentSelectorApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
+    const deleteSpy = vi.spyOn(api, 'delete').mockResolvedValue({ data: {} });
+
+    await agentSelectorApi.list();
+    expect(getSpy).toHaveBeenCalledWith('/agent-selectors');
+    await agentSelectorApi.get('sel-1');
+    expect(getSpy).toHaveBeenCalledWith('/agent-selectors/sel-1');
+    await agentSelectorApi.getAgents('sel-1');
+    expect(getSpy).toHaveBeenCalledWith('/agent-selectors/sel-1/agents');
+    await agentSelectorApi.create({ name: 'Linux', platforms: ['linux'] });
+    expect(postSpy).toHaveBeenCalledWith('/agent-selectors', { name: 'Linux', platforms: ['linux'] });
+    await agentSelectorApi.update('sel-1', { name: 'Linux prod', tags: { env: 'prod' } });
+    expect(putSpy).toHaveBeenCalledWith('/agent-selectors/sel-1', { name: 'Linux prod', tags: { env: 'prod' } });
+    await agentSelectorApi.delete('sel-1');
+    expect(deleteSpy).toHaveBeenCalledWith('/agent-selectors/sel-1');
+
+    getSpy.mockRestore();
+    postSpy.mockRestore();
+    putSpy.mockRestore();
+    deleteSpy.mockRestore();
+  });
+
+  it('adhocTaskApi calls the agent task endpoints', async () => {
+    const { api, adhocTaskApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+
+    await adhocTaskApi.run('paw-1', { command: 'whoami', timeout: 30 });
+    expect(postSpy).toHaveBeenCalledWith('/agents/paw-1/task', { command: 'whoami', timeout: 30 });
+    await adhocTaskApi.list('paw-1');
+    expect(getSpy).toHaveBeenCalledWith('/agents/paw-1/tasks', { params: { limit: 50 } });
+    await adhocTaskApi.get('paw-1', 'adhoc-1');
+    expect(getSpy).toHaveBeenCalledWith('/agents/paw-1/tasks/adhoc-1');
+
+    getSpy.mockRestore();
+    postSpy.mockRestore();
+  });
+
+  it('agentReleaseApi calls the agent release endpoints', async () => {
+    const { api, agentReleaseApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
+    const deleteSpy = vi.spyOn(api, 'delete').mockResolvedValue({ data: null });
+
+    await agentReleaseApi.list();
+    expect(getSpy).toHaveBeenCalledWith('/agent-releases');
+    await agentReleaseApi.upload(new File(['agent'], 'autostrike-agent'), {
+      version: '1.2.0',
+      platform: 'linux',
+      signature: 'c2ln',
+      rollout_percent: 10,
+    });
+    const form = postSpy.mock.calls[0][1] as FormData;
+    expect(postSpy.mock.calls[0][0]).toBe('/agent-releases');
+    expect(form.get('file')).toBeInstanceOf(File);
+    expect(form.get('version')).toBe('1.2.0');
+    expect(form.get('rollout_percent')).toBe('10');
+    await agentReleaseApi.setRollout('rel-1', 50);
+    expect(putSpy).toHaveBeenCalledWith('/agent-releases/rel-1/rollout', { rollout_percent: 50 });
+    await agentReleaseApi.delete('rel-1');
+    expect(deleteSpy).toHaveBeenCalledWith('/agent-releases/rel-1');
+
+    getSpy.mockRestore();
+    postSpy.mockRestore();
+    putSpy.mockRestore();
+    deleteSpy.mockRestore();
+  });
+
+  it('agentNetworkApi pins the networks of an agent', async () => {
+    const { api, agentNetworkApi } = await import('./api');
+    const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
+
+    await agentNetworkApi.set('paw-1', ['10.0.0.0/8']);
+    expect(putSpy).toHaveBeenCalledWith('/agents/paw-1/networks', { networks: ['10.0.0.0/8'] });
+
+    putSpy.mockRestore();
+  });
+
+  it('agentBulkApi previews and applies bulk operations', async () => {
+    const { api, agentBulkApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    const op = { action: 'delete' as const, filter: { not_seen_for: '72h' } };
+
+    await agentBulkApi.preview(op);
+    expect(postSpy).toHaveBeenCalledWith('/agents/bulk/preview', op);
+
+    await agentBulkApi.apply(op, 'abc');
+    expect(postSpy).toHaveBeenCalledWith('/agents/bulk/apply', { ...op, checksum: 'abc' });
+
+    postSpy.mockRestore();
+  });
+
+  it('agentAvailabilityApi gets the availability of an agent', async () => {
+    const { api, agentAvailabilityApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+
+    await agentAvailabilityApi.get('paw-1', 30);
+    expect(getSpy).toHaveBeenCalledWith('/agents/paw-1/availability', { params: { days: 30 } });
+
+    getSpy.mockRestore();
+  });
+
+  it('agentHealthApi gets the beacon health of an agent', async () => {
+    const { api, agentHealthApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+
+    await agentHealthApi.get('paw-1', 7);
+    expect(getSpy).toHaveBeenCalledWith('/analytics/agents/paw-1/health', { params: { days: 7 } });
+
+    getSpy.mockRestore();
+  });
+
+  it('beaconApi calls the beacon endpoints', async () => {
+    const { api, beaconApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+    const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
+    const deleteSpy = vi.spyOn(api, 'delete').mockResolvedValue({ data: null });
+
+    await beaconApi.list();
+    expect(getSpy).toHaveBeenCalledWith('/beacon-settings');
+    await beaconApi.getAgent('paw-1');
+    expect(getSpy).toHaveBeenCalledWith('/agents/paw-1/beacon');
+    await beaconApi.setAgent('paw-1', { interval: 60, jitter: 10 });
+    expect(putSpy).toHaveBeenCalledWith('/agents/paw-1/beacon', { interval: 60, jitter: 10 });
+    await beaconApi.clearAgent('paw-1');
+    expect(deleteSpy).toHaveBeenCalledWith('/agents/paw-1/beacon');
+    await beaconApi.setSelector('sel-1', { interval: 300, jitter: 20 });
+    expect(putSpy).toHaveBeenCalledWith('/agent-selectors/sel-1/beacon', { interval: 300, jitter: 20 });
+    await beaconApi.clearSelector('sel-1');
+    expect(deleteSpy).toHaveBeenCalledWith('/agent-selectors/sel-1/beacon');
+
+    getSpy.mockRestore();
+    putSpy.mockRestore();
+    deleteSpy.mockRestore();
+  });
+
   it('executionApi.stop calls correct endpoint', async () => {
     const { api, executionApi } = await import('./api');
     const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
@@ -786,6 +1064,41 @@ describe('API method invocations', () => {
     postSpy.mockRestore();
   });
 
+  it('executionApi.export requests full verbosity by default', async () => {
+    const { api, executionApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+    await executionApi.export('exec-1');
+    expect(getSpy).toHaveBeenCalledWith('/executions/exec-1/export', { params: { verbosity: 'full' } });
+    getSpy.mockRestore();
+  });
+
+  it('executionApi.getSnapshot calls correct endpoint', async () => {
+    const { api, executionApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+    await executionApi.getSnapshot('exec-1');
+    expect(getSpy).toHaveBeenCalledWith('/executions/exec-1/snapshot');
+    getSpy.mockRestore();
+  });
+
+  it('executionApi.rescore calls correct endpoint', async () => {
+    const { api, executionApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    await executionApi.rescore('exec-1', 'strict');
+    expect(postSpy).toHaveBeenCalledWith('/executions/exec-1/rescore', { scoring_profile_id: 'strict' });
+    postSpy.mockRestore();
+  });
+
+  it('executionApi.rescoreMany calls correct endpoint', async () => {
+    const { api, executionApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    await executionApi.rescoreMany(['exec-1', 'exec-2']);
+    expect(postSpy).toHaveBeenCalledWith('/executions/rescore', {
+      execution_ids: ['exec-1', 'exec-2'],
+      scoring_profile_id: undefined,
+    });
+    postSpy.mockRestore();
+  });
+
   it('executionApi.getResults calls correct endpoint', async () => {
     const { api, executionApi } = await import('./api');
     const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
@@ -827,6 +1140,56 @@ describe('API method invocations', () => {
     getSpy.mockRestore();
   });
 
+  it('techniqueApi.updateStatus puts the new status', async () => {
+    const { api, techniqueApi } = await import('./api');
+    const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
+    await techniqueApi.updateStatus('T1082', 'deprecated');
+    expect(putSpy).toHaveBeenCalledWith('/techniques/T1082/status', { status: 'deprecated' });
+    putSpy.mockRestore();
+  });
+
+  it('techniqueApi.updateMetadata puts the custom fields', async () => {
+    const { api, techniqueApi } = await import('./api');
+    const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
+    await techniqueApi.updateMetadata('T1082', { owner_team: 'red' });
+    expect(putSpy).toHaveBeenCalledWith('/techniques/T1082/metadata', { metadata: { owner_team: 'red' } });
+    putSpy.mockRestore();
+  });
+
+  it('techniqueApi.listByMetadata prefixes the filter fields', async () => {
+    const { api, techniqueApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    await techniqueApi.listByMetadata({ owner_team: 'red' });
+    expect(getSpy).toHaveBeenCalledWith('/techniques', { params: { 'metadata.owner_team': 'red' } });
+    getSpy.mockRestore();
+  });
+
+  it('techniqueApi.listBySeverity passes the severity filter', async () => {
+    const { api, techniqueApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    await techniqueApi.listBySeverity('critical');
+    expect(getSpy).toHaveBeenCalledWith('/techniques', { params: { severity: 'critical' } });
+    getSpy.mockRestore();
+  });
+
+  it('analyticsApi.sigmaCoverage uses default days parameter', async () => {
+    const { api, analyticsApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+    await analyticsApi.sigmaCoverage();
+    expect(getSpy).toHaveBeenCalledWith('/analytics/sigma-coverage', { params: { days: 30 } });
+    getSpy.mockRestore();
+  });
+
+  it('analyticsApi.calendar joins scenario IDs', async () => {
+    const { api, analyticsApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+    await analyticsApi.calendar({ start: '2024-01-01', scenarioIds: ['s1', 's2'] });
+    expect(getSpy).toHaveBeenCalledWith('/analytics/calendar', {
+      params: { start: '2024-01-01', end: undefined, scenario_id: 's1,s2' },
+    });
+    getSpy.mockRestore();
+  });
+
   it('analyticsApi.compare uses default days parameter', async () => {
     const { api, analyticsApi } = await import('./api');
     const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
@@ -894,7 +1257,7 @@ describe('API method invocations', () => {
   it('notificationApi.createSettings posts data correctly', async () => {
     const { api, notificationApi } = await import('./api');
     const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
-    const settings = { channel: 'email' as const, enabled: true, notify_on_start: true, notify_on_complete: true, notify_on_failure: true, notify_on_score_alert: false, score_alert_threshold: 50, notify_on_agent_offline: false };
+    const settings = { enabled: true, email_address: 'user@example.com', preferences: { execution_failed: ['email' as const] }, score_alert_threshold: 50 };
     await notificationApi.createSettings(settings);
     expect(postSpy).toHaveBeenCalledWith('/notifications/settings', settings);
     postSpy.mockRestore();
@@ -903,7 +1266,7 @@ describe('API method invocations', () => {
   it('notificationApi.updateSettings puts data correctly', async () => {
     const { api, notificationApi } = await import('./api');
     const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
-    const settings = { channel: 'webhook' as const, enabled: true, notify_on_start: false, notify_on_complete: true, notify_on_failure: true, notify_on_score_alert: true, score_alert_threshold: 70, notify_on_agent_offline: true };
+    const settings = { enabled: true, webhook_url: 'https://hooks.example.com', preferences: { execution_completed: ['webhook' as const], execution_failed: ['email' as const, 'webhook' as const] }, score_alert_threshold: 70 };
     await notificationApi.updateSettings('set-1', settings);
     expect(putSpy).toHaveBeenCalledWith('/notifications/settings/set-1', settings);
     putSpy.mockRestore();
@@ -925,6 +1288,88 @@ describe('API method invocations', () => {
     postSpy.mockRestore();
   });
 
+  it('notificationApi webhook delivery methods call the delivery log endpoints', async () => {
+    const { api, notificationApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+
+    await notificationApi.listWebhookDeliveries('failed');
+    expect(getSpy).toHaveBeenCalledWith('/notifications/webhook-deliveries', { params: { status: 'failed', limit: 50 } });
+    await notificationApi.getWebhookDelivery('d-1');
+    expect(getSpy).toHaveBeenCalledWith('/notifications/webhook-deliveries/d-1');
+    await notificationApi.redeliverWebhook('d-1');
+    expect(postSpy).toHaveBeenCalledWith('/notifications/webhook-deliveries/d-1/redeliver');
+
+    getSpy.mockRestore();
+    postSpy.mockRestore();
+  });
+
+  it('notificationApi.resolveLink calls the link endpoint', async () => {
+    const { api, notificationApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: { kind: 'execution', id: 'e-1', path: '/executions/e-1' } });
+    await notificationApi.resolveLink('abc.def.ghi');
+    expect(getSpy).toHaveBeenCalledWith('/links/abc.def.ghi');
+    getSpy.mockRestore();
+  });
+
+  it('contentApi.import posts the raw file to the format endpoint', async () => {
+    const { api, contentApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: { format: 'stratus', techniques: [], scenarios: [], failed: 0 } });
+    await contentApi.import('stratus', '- id: aws.x');
+    expect(postSpy).toHaveBeenCalledWith('/content/import/stratus', '- id: aws.x', {
+      headers: { 'Content-Type': 'text/plain' },
+    });
+    postSpy.mockRestore();
+  });
+
+  it('contentApi.import passes the incremental options as query parameters', async () => {
+    const { api, contentApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: { format: 'caldera', techniques: [], scenarios: [], failed: 0 } });
+    await contentApi.import('caldera', '- id: x', { incremental: true, deprecate_removed: true });
+    expect(postSpy).toHaveBeenCalledWith('/content/import/caldera', '- id: x', {
+      headers: { 'Content-Type': 'text/plain' },
+      params: { incremental: true, deprecate_removed: true },
+    });
+    postSpy.mockRestore();
+  });
+
+  it('payloadApi.upload posts the file as multipart form data', async () => {
+    const { api, payloadApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: { name: 'tool.sh' } });
+    const file = new File(['echo tool'], 'tool.sh');
+    await payloadApi.upload(file, 'renamed.sh', 'Test tool');
+    const form = postSpy.mock.calls[0][1] as FormData;
+    expect(postSpy.mock.calls[0][0]).toBe('/payloads');
+    expect(form.get('file')).toBeInstanceOf(File);
+    expect(form.get('name')).toBe('renamed.sh');
+    expect(form.get('description')).toBe('Test tool');
+    postSpy.mockRestore();
+  });
+
+  it('payloadApi.delete encodes the payload name', async () => {
+    const { api, payloadApi } = await import('./api');
+    const deleteSpy = vi.spyOn(api, 'delete').mockResolvedValue({ data: null });
+    await payloadApi.delete('tool v2.sh');
+    expect(deleteSpy).toHaveBeenCalledWith('/payloads/tool%20v2.sh');
+    deleteSpy.mockRestore();
+  });
+
+  it('artifactApi.list gets the artifacts of a result', async () => {
+    const { api, artifactApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
+    await artifactApi.list('result-1');
+    expect(getSpy).toHaveBeenCalledWith('/results/result-1/artifacts');
+    getSpy.mockRestore();
+  });
+
+  it('artifactApi.download requests the content as a blob', async () => {
+    const { api, artifactApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: new Blob(['loot']) });
+    await artifactApi.download('result-1', 'artifact-1');
+    expect(getSpy).toHaveBeenCalledWith('/results/result-1/artifacts/artifact-1', { responseType: 'blob' });
+    getSpy.mockRestore();
+  });
+
   it('scheduleApi.getRuns uses default limit', async () => {
     const { api, scheduleApi } = await import('./api');
     const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: [] });
@@ -1015,6 +1460,34 @@ describe('API method invocations', () => {
     postSpy.mockRestore();
   });
 
+  it('authApi posts password reset requests correctly', async () => {
+    const { api, authApi } = await import('./api');
+    const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
+    await authApi.forgotPassword('user@example.com');
+    expect(postSpy).toHaveBeenCalledWith('/auth/forgot-password', { email: 'user@example.com' });
+    await authApi.resetPassword('reset-token', 'newpassword');
+    expect(postSpy).toHaveBeenCalledWith('/auth/reset-password', {
+      token: 'reset-token',
+      new_password: 'newpassword',
+    });
+    postSpy.mockRestore();
+  });
+
+  it('profileApi gets and updates the current user', async () => {
+    const { api, profileApi } = await import('./api');
+    const getSpy = vi.spyOn(api, 'get').mockResolvedValue({ data: {} });
+    const putSpy = vi.spyOn(api, 'put').mockResolvedValue({ data: {} });
+    await profileApi.get();
+    expect(getSpy).toHaveBeenCalledWith('/users/me');
+    await profileApi.update({ display_name: 'Jane Doe', preferences: { theme: 'dark' } });
+    expect(putSpy).toHaveBeenCalledWith('/users/me', {
+      display_name: 'Jane Doe',
+      preferences: { theme: 'dark' },
+    });
+    getSpy.mockRestore();
+    putSpy.mockRestore();
+  });
+
   it('authApi.refresh posts refresh token correctly', async () => {
     const { api, authApi } = await import('./api');
     const postSpy = vi.spyOn(api, 'post').mockResolvedValue({ data: {} });
@@ -153,6 +153,26 @@ export interface User {
   last_login_at?: string;
   created_at: string;
   updated_at: string;
+  display_name?: string;
+  timezone?: string;
+  preferences?: UserPreferences;
+}
+
+// Dashboard defaults of a user
+export interface UserPreferences {
+  landing_page?: string;
+  analytics_period?: 7 | 30 | 90;
+  theme?: 'light' | 'dark' | 'system';
+}
+
+// Profile changes; omitted fields are kept
+export interface ProfileUpdate {
+  display_name?: string;
+  email?: string;
+  timezone?: string;
+  preferences?: UserPreferences;
+  current_password?: string;
+  new_password?: string;
 }
 
 // Auth API methods
@@ -178,9 +198,58 @@ export const authApi = {
    * Get current authenticated user
    */
   me: () => api.get<User>('/auth/me'),
+
+  /**
+   * Email a password reset link to the account using this address, if any
+   */
+  forgotPassword: (email: string) => api.post('/auth/forgot-password', { email }),
+
+  /**
+   * Set a new password with the token of a password reset email
+   */
+  resetPassword: (token: string, newPassword: string) =>
+    api.post('/auth/reset-password', { token, new_password: newPassword }),
+};
+
+// API key types
+export interface APIKey {
+  id: string;
+  user_id: string;
+  name: string;
+  prefix: string;
+  created_at: string;
+  expires_at?: string;
+  last_used_at?: string;
+}
+
+export interface CreatedAPIKey extends APIKey {
+  key: string;
+}
+
+// API key methods
+export const apiKeyApi = {
+  list: () => api.get<APIKey[]>('/auth/api-keys'),
+  create: (name: string, expiresInDays?: number) =>
+    api.post<CreatedAPIKey>('/auth/api-keys', { name, expires_in_days: expiresInDays }),
+  revoke: (id: string) => api.delete(`/auth/api-keys/${id}`),
+};
+
+// Profile API methods for the current user
+export const profileApi = {
+  get: () => api.get<User>('/users/me'),
+  update: (update: ProfileUpdate) => api.put<User>('/users/me', update),
 };
 
 // Admin types
+export interface Secret {
+  name: string;
+  description?: string;
+  key_id: string;
+  updated_by?: string;
+  created_at: string;
+  updated_at: string;
+}
+
 export interface CreateUserRequest {
   username: string;
   email: string;
@@ -206,6 +275,110 @@ export interface ListUsersResponse {
   total: number;
 }
 
+export type ActivityAnomalyType =
+  | 'new_ip'
+  | 'new_country'
+  | 'off_hours_execution'
+  | 'mass_deletion'
+  | 'agent_unexpected_network'
+  | 'login_lockout'
+  | 'password_reset';
+
+export interface LoginLockout {
+  scope: 'user' | 'ip';
+  key: string;
+  failures: number;
+  lockouts: number;
+  locked_until?: string;
+  last_failure_at: string;
+}
+
+export interface ActivityAnomaly {
+  id: string;
+  type: ActivityAnomalyType;
+  severity: 'medium' | 'high';
+  user_id?: string;
+  username?: string;
+  message: string;
+  data?: Record<string, unknown>;
+  created_at: string;
+}
+
+export interface RecomputeScoresRequest {
+  from?: string;
+  to?: string;
+  batch_size?: number;
+  dry_run?: boolean;
+}
+
+export interface ScoreChange {
+  execution_id: string;
+  original_overall: number;
+  previous_overall: number;
+  new_overall: number;
+}
+
+export interface RecomputeJob {
+  id: string;
+  status: 'running' | 'completed' | 'failed';
+  dry_run: boolean;
+  from: string;
+  to: string;
+  batch_size: number;
+  total: number;
+  processed: number;
+  changed: number;
+  failed: number;
+  changes: ScoreChange[];
+  error?: string;
+  started_at: string;
+  completed_at?: string;
+}
+
+export interface TechniqueSyncSourceResult {
+  format: 'stix' | 'atomic';
+  url: string;
+  version?: string; // ATT&CK release of a STIX bundle, SHA-256 prefix of the atomics
+  error?: string;
+}
+
+export interface TechniqueSyncJob {
+  id: string;
+  job_id?: string; // Background job running the sync
+  status: 'running' | 'completed' | 'failed';
+  deprecate_removed: boolean;
+  started_by: string;
+  sources: TechniqueSyncSourceResult[];
+  unchanged?: boolean; // Same upstream versions as the last sync, nothing imported
+  result?: ContentImportResult; // Import of the merged catalog
+  added: number;
+  updated: number;
+  deprecated: number;
+  drafts: number; // Techniques without an atomic test, created as drafts
+  failed: number;
+  error?: string;
+  started_at: string;
+  completed_at?: string;
+}
+
+export interface EmergencyStop {
+  id: string;
+  reason: string;
+  automatic: boolean; // Tripped by the server on losing its database
+  tripped_by?: string;
+  tripped_at: string;
+  cancelled_executions: string[];
+  paused_schedules: string[];
+  agents_notified: number;
+  rearmed_by?: string;
+  rearmed_at?: string;
+}
+
+export interface EmergencyStopStatus {
+  engaged: boolean;
+  stop?: EmergencyStop;
+}
+
 // Admin API methods (requires admin role)
 export const adminApi = {
   /**
@@ -222,105 +395,1026 @@ export const adminApi = {
   getUser: (id: string) => api.get<User>(`/admin/users/${id}`),
 
   /**
-   * Create a new user
+   * Create a new user
+   */
+  createUser: (data: CreateUserRequest) => api.post<User>('/admin/users', data),
+
+  /**
+   * Update a user's basic info
+   */
+  updateUser: (id: string, data: UpdateUserRequest) =>
+    api.put<User>(`/admin/users/${id}`, data),
+
+  /**
+   * Update a user's role
+   */
+  updateUserRole: (id: string, data: UpdateRoleRequest) =>
+    api.put<User>(`/admin/users/${id}/role`, data),
+
+  /**
+   * Deactivate a user (soft delete)
+   */
+  deactivateUser: (id: string) => api.delete(`/admin/users/${id}`),
+
+  /**
+   * Reactivate a deactivated user
+   */
+  reactivateUser: (id: string) => api.post<User>(`/admin/users/${id}/reactivate`),
+
+  /**
+   * Reset a user's password
+   */
+  resetPassword: (id: string, data: ResetPasswordRequest) =>
+    api.post(`/admin/users/${id}/reset-password`, data),
+
+  /**
+   * List the usernames and source IPs locked out after failed logins
+   */
+  listLockouts: () => api.get<LoginLockout[]>('/admin/lockouts'),
+
+  /**
+   * Lift the login lockout of a user
+   */
+  unlockUser: (id: string) => api.post(`/admin/users/${id}/unlock`),
+
+  /**
+   * Lift the login lockout of a source IP
+   */
+  unlockIP: (ip: string) => api.delete(`/admin/lockouts/ips/${encodeURIComponent(ip)}`),
+
+  /**
+   * List recent login and activity anomalies
+   */
+  listActivityAnomalies: (limit = 50) =>
+    api.get<ActivityAnomaly[]>('/admin/activity/anomalies', { params: { limit } }),
+
+  /**
+   * Recompute historical execution scores in the background
+   */
+  recomputeScores: (data: RecomputeScoresRequest = {}) =>
+    api.post<RecomputeJob>('/admin/scores/recompute', data),
+
+  /**
+   * Get the progress of a score recompute job
+   */
+  getRecomputeJob: (id: string) => api.get<RecomputeJob>(`/admin/scores/recompute/${id}`),
+
+  /**
+   * Refresh the technique catalog from the configured upstream ATT&CK content in the background
+   */
+  syncMitreTechniques: (deprecateRemoved = false) =>
+    api.post<TechniqueSyncJob>('/admin/techniques/sync-mitre', { deprecate_removed: deprecateRemoved }),
+
+  /**
+   * List the technique sync jobs, newest first
+   */
+  listTechniqueSyncJobs: () => api.get<TechniqueSyncJob[]>('/admin/techniques/sync-mitre'),
+
+  /**
+   * Get the progress of a technique sync job
+   */
+  getTechniqueSyncJob: (id: string) => api.get<TechniqueSyncJob>(`/admin/techniques/sync-mitre/${id}`),
+
+  /**
+   * Tell whether the emergency stop is engaged
+   */
+  getEmergencyStop: () => api.get<EmergencyStopStatus>('/admin/emergency-stop'),
+
+  /**
+   * Stop all activity: agents kill their commands, executions are cancelled, schedules paused
+   */
+  tripEmergencyStop: (reason?: string) =>
+    api.post<EmergencyStop>('/admin/emergency-stop', { reason }),
+
+  /**
+   * Let executions and ad-hoc commands start again
+   */
+  rearmEmergencyStop: () => api.post<EmergencyStop>('/admin/emergency-stop/rearm'),
+
+  /**
+   * List the latest emergency stop trips
+   */
+  listEmergencyStops: (limit = 50) =>
+    api.get<EmergencyStop[]>('/admin/emergency-stop/history', { params: { limit } }),
+
+  /**
+   * List the secrets store, without values
+   */
+  listSecrets: () => api.get<Secret[]>('/admin/secrets'),
+
+  /**
+   * Create or replace a secret, referred to as secret://<name>
+   */
+  putSecret: (name: string, value: string, description?: string) =>
+    api.put<Secret>(`/admin/secrets/${name}`, { value, description }),
+
+  /**
+   * Delete a secret
+   */
+  deleteSecret: (name: string) => api.delete(`/admin/secrets/${name}`),
+};
+
+// Technique types
+export interface Technique {
+  id: string;
+  name: string;
+  description: string;
+  tactic: string;
+  platforms: string[];
+  executors: TechniqueExecutor[];
+  detection: TechniqueDetection[];
+  is_safe: boolean;
+  status?: TechniqueStatus; // Absent means active
+  severity?: TechniqueSeverity; // From the catalog or estimated from the tactics
+  impact?: number; // CVSS-like, 0.1-10
+  deleted_at?: string; // Set while in the trash
+}
+
+export type TechniqueStatus = 'draft' | 'active' | 'deprecated' | 'broken';
+
+export type TechniqueSeverity = 'low' | 'medium' | 'high' | 'critical';
+
+export interface TechniqueExecutor {
+  type: string;
+  command: string;
+  platform?: string;
+  timeout: number;
+  limits?: ResourceLimits; // Enforced by the agent
+  payload?: string; // Downloaded by the agent, available as #{payload.path}
+  collect?: string[]; // Uploaded by the agent as result artifacts
+  elevation_required?: boolean; // Only runs on elevated agents
+  variants?: Record<string, CommandVariant>; // Keyed by platform
+}
+
+export interface CommandVariant {
+  command: string;
+  cleanup?: string;
+}
+
+export interface ResourceLimits {
+  cpu_percent?: number; // Share of one core, throttled
+  memory_mb?: number; // The command is killed above it
+}
+
+export interface TechniqueDetection {
+  source: string;
+  indicator: string;
+}
+
+export interface ImportTechniquesRequest {
+  techniques: Technique[];
+}
+
+export interface ImportTechniquesResponse {
+  imported: number;
+  failed: number;
+  errors?: string[];
+}
+
+// Technique API methods
+export const techniqueApi = {
+  /**
+   * List all techniques
+   */
+  list: () => api.get<Technique[]>('/techniques'),
+
+  /**
+   * Get technique by ID
+   */
+  get: (id: string) => api.get<Technique>(`/techniques/${id}`),
+
+  /**
+   * Get techniques by tactic
+   */
+  getByTactic: (tactic: string) => api.get<Technique[]>(`/techniques/tactic/${tactic}`),
+
+  /**
+   * Get techniques by platform
+   */
+  getByPlatform: (platform: string) => api.get<Technique[]>(`/techniques/platform/${platform}`),
+
+  /**
+   * Get MITRE ATT&CK coverage statistics
+   */
+  getCoverage: () => api.get<Record<string, number>>('/techniques/coverage'),
+
+  /**
+   * Import techniques from JSON
+   */
+  import: (techniques: Technique[]) =>
+    api.post<ImportTechniquesResponse>('/techniques/import/json', { techniques }),
+
+  /**
+   * Move a technique to another lifecycle state
+   */
+  updateStatus: (id: string, status: TechniqueStatus) =>
+    api.put<Technique>(`/techniques/${id}/status`, { status }),
+
+  /**
+   * Replace the organization custom fields of a technique
+   */
+  updateMetadata: (id: string, metadata: Record<string, string>) =>
+    api.put<Technique>(`/techniques/${id}/metadata`, { metadata }),
+
+  /**
+   * List techniques of a severity
+   */
+  listBySeverity: (severity: TechniqueSeverity) =>
+    api.get<Technique[]>('/techniques', { params: { severity } }),
+
+  /**
+   * List techniques whose custom fields match every given value
+   */
+  listByMetadata: (metadata: Record<string, string>) =>
+    api.get<Technique[]>('/techniques', {
+      params: Object.fromEntries(Object.entries(metadata).map(([field, value]) => [`metadata.${field}`, value])),
+    }),
+
+  /**
+   * Move a technique to the trash
+   */
+  delete: (id: string) => api.delete(`/techniques/${id}`),
+
+  /**
+   * List deleted techniques, most recently deleted first
+   */
+  listTrash: () => api.get<Technique[]>('/techniques/trash'),
+
+  /**
+   * Take a technique out of the trash
+   */
+  restore: (id: string) => api.post(`/techniques/${id}/restore`),
+};
+
+// Content import types
+export type ContentFormat = 'prelude' | 'stratus' | 'ctid' | 'caldera' | 'stix' | 'atomic';
+
+export interface ContentImportResult {
+  format: ContentFormat | 'mitre'; // mitre: merged by a technique sync
+  techniques: string[]; // Added or updated
+  added: string[];
+  updated: string[];
+  deprecated: string[]; // Removed upstream, with deprecate_removed
+  unchanged: number;
+  kept?: string[]; // Curated techniques a technique sync left as they are
+  scenarios: Scenario[];
+  failed: number;
+  errors?: string[];
+}
+
+export interface ContentImportOptions {
+  incremental?: boolean; // Only write what changed
+  deprecate_removed?: boolean; // Deprecate the techniques of the format removed upstream
+}
+
+// Content import API methods (Prelude Operator, Stratus Red Team, CTID emulation plans, Caldera)
+export const contentApi = {
+  /**
+   * List the importable content formats
+   */
+  formats: () => api.get<{ formats: ContentFormat[] }>('/content/formats'),
+
+  /**
+   * Convert and import a content file into techniques and scenarios
+   */
+  import: (format: ContentFormat, content: string, options?: ContentImportOptions) =>
+    api.post<ContentImportResult>(`/content/import/${format}`, content, {
+      headers: { 'Content-Type': 'text/plain' },
+      params: options,
+    }),
+};
+
+// Payload types
+export interface Payload {
+  id: string;
+  name: string;
+  description?: string;
+  sha256: string;
+  size: number;
+  uploaded_by?: string;
+  created_at: string;
+}
+
+// Payload API methods (files delivered with technique commands)
+export const payloadApi = {
+  /**
+   * List stored payloads
+   */
+  list: () => api.get<Payload[]>('/payloads'),
+
+  /**
+   * Get a payload by name
+   */
+  get: (name: string) => api.get<Payload>(`/payloads/${encodeURIComponent(name)}`),
+
+  /**
+   * Upload a payload, named after the file unless a name is given
+   */
+  upload: (file: File, name?: string, description?: string) => {
+    const form = new FormData();
+    form.append('file', file);
+    if (name) form.append('name', name);
+    if (description) form.append('description', description);
+    return api.post<Payload>('/payloads', form);
+  },
+
+  /**
+   * Delete a payload no technique references
+   */
+  delete: (name: string) => api.delete(`/payloads/${encodeURIComponent(name)}`),
+};
+
+// Result artifact types
+export interface Artifact {
+  id: string;
+  result_id: string;
+  execution_id: string;
+  agent_paw: string;
+  name: string;
+  path?: string;
+  content_type?: string;
+  sha256: string;
+  size: number;
+  created_at: string;
+}
+
+// A page of the output of a result
+export interface ResultOutputPage {
+  result_id: string;
+  offset: number;
+  size: number; // Bytes of the whole output
+  output: string;
+  next_offset?: number; // Absent on the last page
+  truncated: boolean; // Cut to the server maximum output size when received
+}
+
+interface RescoreScore {
+  overall: number;
+  blocked: number;
+  detected: number;
+  successful: number;
+  total: number;
+}
+
+// Outcome of rescoring an execution. score_version counts its scores, the
+// original being version 1; changed is false when the score was already current.
+export interface RescoreResult {
+  execution_id: string;
+  previous_score?: RescoreScore;
+  new_score?: RescoreScore;
+  scoring_profile_id?: string;
+  scoring_profile_version?: number;
+  score_version?: number;
+  changed: boolean;
+  error?: string;
+}
+
+export interface RescoreReport {
+  id: string;
+  changed: number;
+  failed: number;
+  results: RescoreResult[];
+}
+
+// Environment an execution was launched in. A scenario or technique is
+// modified when it was edited or deleted since; the snapshot is what ran.
+export interface ExecutionSnapshot {
+  execution_id: string;
+  captured_at: string;
+  server: {
+    version?: string;
+    safe_mode: boolean;
+    scoring_profile_id?: string;
+    scoring_profile_version?: number;
+    task_queue_ttl?: string;
+    output_max_size: number;
+  };
+  scenario: { fingerprint: string; scenario: Scenario; modified: boolean };
+  techniques: { fingerprint: string; technique: Technique; modified: boolean }[];
+  agents: {
+    paw: string;
+    hostname: string;
+    platform: string;
+    version?: string;
+    os_version: string;
+    architecture?: string;
+    security_products?: string[];
+  }[];
+}
+
+// Result artifact API methods (files agents collected while running techniques)
+export const artifactApi = {
+  /**
+   * List the artifacts of a result
+   */
+  list: (resultId: string) => api.get<Artifact[]>(`/results/${resultId}/artifacts`),
+
+  /**
+   * Download the content of an artifact
+   */
+  download: (resultId: string, artifactId: string) =>
+    api.get<Blob>(`/results/${resultId}/artifacts/${artifactId}`, { responseType: 'blob' }),
+};
+
+// Result evidence types (files operators attach to results)
+export interface Evidence {
+  id: string;
+  result_id: string;
+  execution_id: string;
+  name: string;
+  description?: string;
+  content_type: string;
+  sha256: string;
+  size: number;
+  uploaded_by?: string;
+  created_at: string;
+}
+
+// Result evidence API methods
+export const evidenceApi = {
+  /**
+   * List the evidence of a result
+   */
+  list: (resultId: string) => api.get<Evidence[]>(`/results/${resultId}/evidence`),
+
+  /**
+   * Attach a file to a result; sha256, when given, is checked against the upload
+   */
+  attach: (resultId: string, file: File, description?: string, sha256?: string) => {
+    const form = new FormData();
+    form.append('file', file);
+    if (description) form.append('description', description);
+    if (sha256) form.append('sha256', sha256);
+    return api.post<Evidence>(`/results/${resultId}/evidence`, form);
+  },
+
+  /**
+   * Download the content of evidence
+   */
+  download: (resultId: string, evidenceId: string) =>
+    api.get<Blob>(`/results/${resultId}/evidence/${evidenceId}`, { responseType: 'blob' }),
+};
+
+// Result annotation types (analyst triage of a result)
+export type ResultTriage = 'true_positive' | 'expected_block' | 'needs_tuning';
+
+export interface ResultComment {
+  id: string;
+  result_id: string;
+  execution_id: string;
+  author_id: string;
+  body: string;
+  created_at: string;
+}
+
+export interface ResultAnnotation {
+  result_id: string;
+  execution_id: string;
+  triage?: ResultTriage;
+  assignee_id?: string;
+  updated_by?: string;
+  updated_at?: string; // Absent until the result is triaged or assigned
+  comments: ResultComment[]; // Oldest first
+}
+
+// Result annotation API methods
+export const annotationApi = {
+  /**
+   * Get the triage, assignee and comments of a result
+   */
+  get: (resultId: string) => api.get<ResultAnnotation>(`/results/${resultId}/annotation`),
+
+  /**
+   * Set the triage verdict and assignee of a result; empty values clear them
+   */
+  triage: (resultId: string, triage: ResultTriage | '', assigneeId?: string) =>
+    api.put<ResultAnnotation>(`/results/${resultId}/annotation`, { triage, assignee_id: assigneeId }),
+
+  /**
+   * Comment a result
+   */
+  addComment: (resultId: string, body: string) =>
+    api.post<ResultComment>(`/results/${resultId}/annotation/comments`, { body }),
+
+  /**
+   * Delete a comment, by its author or an administrator
+   */
+  deleteComment: (resultId: string, commentId: string) =>
+    api.delete(`/results/${resultId}/annotation/comments/${commentId}`),
+};
+
+// Execution review types (purple-team sign-off)
+export type ReviewStatus = 'needs_review' | 'in_review' | 'signed_off';
+
+export interface ExecutionReview {
+  execution_id: string;
+  status: ReviewStatus;
+  reviewer_id?: string;
+  requested_by?: string;
+  requested_at: string;
+  started_at?: string;
+  signed_off_by?: string;
+  signed_off_at?: string;
+  notes?: string;
+}
+
+export interface ReviewSummary extends ExecutionReview {
+  results: number; // Results of the execution
+  dispositioned: number; // Results with a triage verdict
+}
+
+// Execution review API methods
+export const reviewApi = {
+  /**
+   * List reviews, newest request first; reviewer is a user ID or 'me'
+   */
+  list: (params?: { status?: ReviewStatus; reviewer?: string; limit?: number }) =>
+    api.get<ExecutionReview[]>('/reviews', { params }),
+
+  /**
+   * Get the review of an execution with its disposition progress
+   */
+  get: (executionId: string) => api.get<ReviewSummary>(`/executions/${executionId}/review`),
+
+  /**
+   * Request the review of a finished execution
+   */
+  request: (executionId: string, reviewerId?: string) =>
+    api.post<ReviewSummary>(`/executions/${executionId}/review`, { reviewer_id: reviewerId }),
+
+  /**
+   * Hand a review not signed off yet to another reviewer
+   */
+  assign: (executionId: string, reviewerId: string) =>
+    api.put<ReviewSummary>(`/executions/${executionId}/review/reviewer`, { reviewer_id: reviewerId }),
+
+  /**
+   * Start a review; a review without reviewer is assigned to the caller
+   */
+  start: (executionId: string) => api.post<ReviewSummary>(`/executions/${executionId}/review/start`),
+
+  /**
+   * Sign off a review once every result has a triage verdict
+   */
+  signOff: (executionId: string, notes?: string) =>
+    api.post<ReviewSummary>(`/executions/${executionId}/review/sign-off`, { notes }),
+
+  /**
+   * Get the export of the execution frozen at sign-off
+   */
+  snapshot: (executionId: string) => api.get(`/executions/${executionId}/review/snapshot`),
+};
+
+// Agent selector types
+export interface AgentSelector {
+  id: string;
+  name: string;
+  description?: string;
+  platforms?: string[];
+  statuses?: Array<'online' | 'offline' | 'busy' | 'untrusted'>;
+  executors?: string[];
+  tags?: Record<string, string>;
+  hostname_pattern?: string;
+  architectures?: string[];
+  elevated?: boolean;
+  domains?: string[];
+  security_products?: string[];
+  interpreters?: Record<string, string>; // Name to minimum version, '' for any
+  created_by?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export type AgentSelectorRequest = Omit<AgentSelector, 'id' | 'created_by' | 'created_at' | 'updated_at'>;
+
+// Agent selector API methods
+export const agentSelectorApi = {
+  /**
+   * List saved agent selectors
+   */
+  list: () => api.get<AgentSelector[]>('/agent-selectors'),
+
+  /**
+   * Get agent selector by ID
+   */
+  get: (id: string) => api.get<AgentSelector>(`/agent-selectors/${id}`),
+
+  /**
+   * Preview the agents a selector currently matches
+   */
+  getAgents: (id: string) => api.get(`/agent-selectors/${id}/agents`),
+
+  /**
+   * Save a new agent selector
+   */
+  create: (data: AgentSelectorRequest) => api.post<AgentSelector>('/agent-selectors', data),
+
+  /**
+   * Update an agent selector
+   */
+  update: (id: string, data: AgentSelectorRequest) =>
+    api.put<AgentSelector>(`/agent-selectors/${id}`, data),
+
+  /**
+   * Delete an agent selector
+   */
+  delete: (id: string) => api.delete(`/agent-selectors/${id}`),
+};
+
+// Scoring profile types
+export interface ScoringProfile {
+  id: string;
+  version: number;
+  name: string;
+  description?: string;
+  blocked_credit: number; // 0-1
+  detected_credit: number; // 0-1, partial credit of detected-but-not-blocked techniques
+  tactic_weights?: Record<string, number>;
+  severity_weights?: Record<string, number>;
+  undetected_weights?: Record<string, number>; // Per severity, multiply the weight of undetected techniques
+  is_default: boolean;
+  created_by?: string;
+  created_at: string;
+}
+
+export type ScoringProfileRequest = Pick<
+  ScoringProfile,
+  'name' | 'description' | 'tactic_weights' | 'severity_weights' | 'undetected_weights'
+> &
+  Partial<Pick<ScoringProfile, 'blocked_credit' | 'detected_credit'>>;
+
+// Scoring profile API methods
+export const scoringProfileApi = {
+  /**
+   * List the current version of every scoring profile
+   */
+  list: () => api.get<ScoringProfile[]>('/scoring-profiles'),
+
+  /**
+   * Get the current version of a scoring profile
+   */
+  get: (id: string) => api.get<ScoringProfile>(`/scoring-profiles/${id}`),
+
+  /**
+   * List every version of a scoring profile, oldest first
+   */
+  getVersions: (id: string) => api.get<ScoringProfile[]>(`/scoring-profiles/${id}/versions`),
+
+  /**
+   * Get a version of a scoring profile
+   */
+  getVersion: (id: string, version: number) =>
+    api.get<ScoringProfile>(`/scoring-profiles/${id}/versions/${version}`),
+
+  /**
+   * Save a new scoring profile
+   */
+  create: (data: ScoringProfileRequest) => api.post<ScoringProfile>('/scoring-profiles', data),
+
+  /**
+   * Save a new version of a scoring profile
+   */
+  update: (id: string, data: ScoringProfileRequest) =>
+    api.put<ScoringProfile>(`/scoring-profiles/${id}`, data),
+
+  /**
+   * Delete a scoring profile, its versions stay available to pinned executions
+   */
+  delete: (id: string) => api.delete(`/scoring-profiles/${id}`),
+
+  /**
+   * Make a scoring profile the default of new executions
+   */
+  setDefault: (id: string) => api.post<ScoringProfile>(`/scoring-profiles/${id}/default`),
+};
+
+// Ticket types
+export type TicketStatus = 'open' | 'resolved';
+
+export interface Ticket {
+  id: string;
+  provider: string; // jira, servicenow
+  external_key: string;
+  url?: string;
+  technique_id: string;
+  agent_paw: string;
+  execution_id: string; // Latest execution the technique went undetected in
+  result_id: string;
+  status: TicketStatus;
+  external_status?: string;
+  resolution?: 'defended' | 'closed';
+  occurrences: number;
+  created_at: string;
+  updated_at: string;
+  resolved_at?: string;
+}
+
+export interface TicketSyncResponse {
+  provider: string;
+  resolved: number;
+}
+
+// Ticket API methods, available when Jira or ServiceNow is configured
+export const ticketApi = {
+  /**
+   * List the tracker issues opened for undetected techniques, newest first
+   */
+  list: (status?: TicketStatus, limit: number = 100) =>
+    api.get<Ticket[]>('/tickets', { params: { status, limit } }),
+
+  /**
+   * Sync the ticket statuses with the tracker now
+   */
+  sync: () => api.post<TicketSyncResponse>('/tickets/sync'),
+};
+
+// Background job types
+export type JobType = 'content_import' | 'execution_export' | 'retention' | 'technique_sync';
+
+export interface Job<T = unknown> {
+  id: string;
+  type: JobType;
+  status: 'pending' | 'running' | 'completed' | 'failed';
+  progress: number;
+  total: number; // 0 when the job does not know its size
+  message?: string;
+  result?: T; // Set once completed
+  error?: string;
+  created_by: string;
+  created_at: string;
+  started_at?: string;
+  completed_at?: string;
+}
+
+// Background job API methods, scoped to the current user's jobs (every job for admins)
+export const jobApi = {
+  /**
+   * List the latest background jobs, newest first
+   */
+  list: (type?: JobType, limit: number = 100) =>
+    api.get<Job[]>('/jobs', { params: { type, limit } }),
+
+  /**
+   * Get the status, progress and result of a background job
+   */
+  get: <T = unknown>(id: string) => api.get<Job<T>>(`/jobs/${id}`),
+};
+
+// Ad-hoc command types
+export interface AdHocTask {
+  id: string;
+  agent_paw: string;
+  command: string;
+  executor: string;
+  timeout: number;
+  status: 'running' | 'success' | 'failed' | 'timeout' | 'limit_exceeded';
+  output?: string;
+  exit_code: number;
+  requested_by: string;
+  created_at: string;
+  completed_at?: string;
+}
+
+export interface AdHocTaskRequest {
+  command: string;
+  executor?: string; // The agent's first executor by default
+  timeout?: number; // Seconds, 60 by default
+}
+
+// Ad-hoc command API methods (admin only, results also arrive as adhoc_task_completed)
+export const adhocTaskApi = {
+  /**
+   * Run a one-off command on a connected agent
+   */
+  run: (paw: string, data: AdHocTaskRequest) => api.post<AdHocTask>(`/agents/${paw}/task`, data),
+
+  /**
+   * List the ad-hoc commands run on an agent, newest first
+   */
+  list: (paw: string, limit = 50) => api.get<AdHocTask[]>(`/agents/${paw}/tasks`, { params: { limit } }),
+
+  /**
+   * Get an ad-hoc command with its output
+   */
+  get: (paw: string, id: string) => api.get<AdHocTask>(`/agents/${paw}/tasks/${id}`),
+};
+
+// Agent release types
+export interface AgentRelease {
+  id: string;
+  version: string;
+  platform: 'windows' | 'linux' | 'darwin';
+  sha256: string;
+  signature: string;
+  size: number;
+  rollout_percent: number;
+  uploaded_by?: string;
+  created_at: string;
+}
+
+export interface AgentReleaseUpload {
+  version: string;
+  platform: string;
+  signature: string; // Base64 Ed25519 signature of the version, SHA-256 and size
+  rollout_percent?: number; // 0 by default: the release is staged
+}
+
+// Agent release API methods (signed self-updates pushed to agents)
+export const agentReleaseApi = {
+  /**
+   * List agent releases, without their binaries
+   */
+  list: () => api.get<AgentRelease[]>('/agent-releases'),
+
+  /**
+   * Upload a signed agent binary (admin only)
+   */
+  upload: (file: File, data: AgentReleaseUpload) => {
+    const form = new FormData();
+    form.append('file', file);
+    form.append('version', data.version);
+    form.append('platform', data.platform);
+    form.append('signature', data.signature);
+    if (data.rollout_percent !== undefined) form.append('rollout_percent', String(data.rollout_percent));
+    return api.post<AgentRelease>('/agent-releases', form);
+  },
+
+  /**
+   * Set the share of the platform's agents offered a release (admin only)
+   */
+  setRollout: (id: string, rolloutPercent: number) =>
+    api.put<AgentRelease>(`/agent-releases/${id}/rollout`, { rollout_percent: rolloutPercent }),
+
+  /**
+   * Delete an agent release (admin only)
+   */
+  delete: (id: string) => api.delete(`/agent-releases/${id}`),
+};
+
+// Beacon types
+export interface BeaconSettings {
+  interval: number; // Seconds between check-ins, 5 to 86400
+  jitter: number; // Percentage each delay varies by, 0 to 90
+}
+
+export interface BeaconOverride extends BeaconSettings {
+  scope: 'agent' | 'selector';
+  target: string; // Agent paw or selector ID
+  updated_by?: string;
+  updated_at: string;
+}
+
+export interface ResolvedBeacon extends BeaconSettings {
+  source: 'agent' | 'selector' | 'default';
+  selector_id?: string;
+}
+
+// Beacon API methods (agent check-in interval and jitter, pushed at runtime)
+export const beaconApi = {
+  /**
+   * Get the default beacon and every override
+   */
+  list: () => api.get<{ default: BeaconSettings; overrides: BeaconOverride[] }>('/beacon-settings'),
+
+  /**
+   * Get the beacon an agent uses and where it comes from
    */
-  createUser: (data: CreateUserRequest) => api.post<User>('/admin/users', data),
+  getAgent: (paw: string) => api.get<ResolvedBeacon>(`/agents/${paw}/beacon`),
 
   /**
-   * Update a user's basic info
+   * Set the beacon of an agent
    */
-  updateUser: (id: string, data: UpdateUserRequest) =>
-    api.put<User>(`/admin/users/${id}`, data),
+  setAgent: (paw: string, settings: BeaconSettings) =>
+    api.put<BeaconOverride>(`/agents/${paw}/beacon`, settings),
 
   /**
-   * Update a user's role
+   * Remove the beacon override of an agent
    */
-  updateUserRole: (id: string, data: UpdateRoleRequest) =>
-    api.put<User>(`/admin/users/${id}/role`, data),
+  clearAgent: (paw: string) => api.delete(`/agents/${paw}/beacon`),
 
   /**
-   * Deactivate a user (soft delete)
+   * Set the beacon of the agents matching a selector
    */
-  deactivateUser: (id: string) => api.delete(`/admin/users/${id}`),
+  setSelector: (id: string, settings: BeaconSettings) =>
+    api.put<BeaconOverride>(`/agent-selectors/${id}/beacon`, settings),
 
   /**
-   * Reactivate a deactivated user
+   * Remove the beacon override of a selector
    */
-  reactivateUser: (id: string) => api.post<User>(`/admin/users/${id}/reactivate`),
+  clearSelector: (id: string) => api.delete(`/agent-selectors/${id}/beacon`),
+};
 
+// Agent network pinning: a connection from outside the networks raises a security alert
+export const agentNetworkApi = {
   /**
-   * Reset a user's password
+   * Set the networks (CIDR) an agent is expected to connect from, any when empty
    */
-  resetPassword: (id: string, data: ResetPasswordRequest) =>
-    api.post(`/admin/users/${id}/reset-password`, data),
+  set: (paw: string, networks: string[]) => api.put(`/agents/${paw}/networks`, { networks }),
 };
 
-// Technique types
-export interface Technique {
-  id: string;
-  name: string;
-  description: string;
-  tactic: string;
-  platforms: string[];
-  executors: TechniqueExecutor[];
-  detection: TechniqueDetection[];
-  is_safe: boolean;
+export interface AgentFilter {
+  paws?: string[];
+  platforms?: string[];
+  statuses?: string[];
+  tags?: Record<string, string>; // Matched against agent metadata
+  hostname_pattern?: string; // Glob, e.g. "lab-*"
+  not_seen_for?: string; // Duration, e.g. "72h"
 }
 
-export interface TechniqueExecutor {
-  type: string;
-  command: string;
-  platform?: string;
-  timeout: number;
+export interface AgentBulkOperation {
+  action: 'delete' | 'deregister' | 'tag' | 'beacon';
+  filter: AgentFilter;
+  tags?: Record<string, string>; // For tag, an empty value removes the tag
+  beacon?: BeaconSettings; // For beacon
 }
 
-export interface TechniqueDetection {
-  source: string;
-  indicator: string;
+export interface AgentBulkTarget {
+  paw: string;
+  hostname: string;
+  platform: string;
+  status: string;
+  last_seen: string;
+  reason?: string; // Why a skipped agent is left alone
 }
 
-export interface ImportTechniquesRequest {
-  techniques: Technique[];
+export interface AgentBulkPreview {
+  action: AgentBulkOperation['action'];
+  agents: AgentBulkTarget[];
+  skipped: AgentBulkTarget[];
+  checksum: string;
 }
 
-export interface ImportTechniquesResponse {
-  imported: number;
-  failed: number;
+export interface AgentBulkResult {
+  preview: AgentBulkPreview;
+  applied: number;
   errors?: string[];
 }
 
-// Technique API methods
-export const techniqueApi = {
+// Bulk agent operations, previewed then applied with the checksum of the preview
+export const agentBulkApi = {
   /**
-   * List all techniques
+   * List the agents an operation would change, without changing anything
    */
-  list: () => api.get<Technique[]>('/techniques'),
+  preview: (op: AgentBulkOperation) => api.post<AgentBulkPreview>('/agents/bulk/preview', op),
 
   /**
-   * Get technique by ID
+   * Apply a reviewed operation; fails with 409 when the matching agents changed since
    */
-  get: (id: string) => api.get<Technique>(`/techniques/${id}`),
+  apply: (op: AgentBulkOperation, checksum: string) =>
+    api.post<AgentBulkResult>('/agents/bulk/apply', { ...op, checksum }),
+};
 
-  /**
-   * Get techniques by tactic
-   */
-  getByTactic: (tactic: string) => api.get<Technique[]>(`/techniques/tactic/${tactic}`),
+export interface AgentAvailabilityEvent {
+  id: string;
+  agent_paw: string;
+  status: 'online' | 'offline';
+  reason: 'registered' | 'disconnected' | 'heartbeat_timeout';
+  suppressed: boolean; // Not announced, the agent was flapping
+  occurred_at: string;
+}
 
-  /**
-   * Get techniques by platform
-   */
-  getByPlatform: (platform: string) => api.get<Technique[]>(`/techniques/platform/${platform}`),
+export interface AgentAvailability {
+  agent_paw: string;
+  since: string;
+  until: string;
+  uptime_percent: number;
+  transitions: number;
+  flapping: boolean;
+  events: AgentAvailabilityEvent[];
+}
 
+// Agent availability history: online/offline transitions and uptime
+export const agentAvailabilityApi = {
   /**
-   * Get MITRE ATT&CK coverage statistics
+   * Get the transitions and uptime of an agent over the last days (7 by default, up to 90)
    */
-  getCoverage: () => api.get<Record<string, number>>('/techniques/coverage'),
+  get: (paw: string, days = 7) =>
+    api.get<AgentAvailability>(`/agents/${paw}/availability`, { params: { days } }),
+};
+
+export interface AgentHealth {
+  agent_paw: string;
+  since: string;
+  until: string;
+  health: 'healthy' | 'degraded' | 'unknown';
+  issues: ('late_beacons' | 'low_uptime' | 'slow_beacons')[];
+  beacons: number;
+  missed_beacons: number;
+  adherence_percent: number; // Check-ins within the longest delay of their beacon
+  median_gap_seconds: number | null;
+  p95_gap_seconds: number | null;
+  median_latency_ms: number | null;
+  p95_latency_ms: number | null;
+  uptime_percent: number;
+}
 
+// Agent beacon health: interval adherence, missed beacons, latency and uptime
+export const agentHealthApi = {
   /**
-   * Import techniques from JSON
+   * Rate how reliably an agent checked in over the last days (1 by default, up to 30)
    */
-  import: (techniques: Technique[]) =>
-    api.post<ImportTechniquesResponse>('/techniques/import/json', { techniques }),
+  get: (paw: string, days = 1) =>
+    api.get<AgentHealth>(`/analytics/agents/${paw}/health`, { params: { days } }),
 };
 
 // Execution API methods
@@ -341,13 +1435,30 @@ export const executionApi = {
   getResults: (id: string) => api.get(`/executions/${id}/results`),
 
   /**
-   * Start a new execution
+   * Read a page of the full output of a result, from next_offset of the previous page
+   */
+  getResultOutput: (resultId: string, offset = 0, limit?: number) =>
+    api.get<ResultOutputPage>(`/results/${resultId}/output`, { params: { offset, limit } }),
+
+  /**
+   * Start a new execution, scored with the default scoring profile unless one is given
    */
-  start: (scenarioId: string, agentPaws: string[], safeMode: boolean) =>
+  start: (scenarioId: string, agentPaws: string[], safeMode: boolean, scoringProfileId?: string) =>
     api.post('/executions', {
       scenario_id: scenarioId,
       agent_paws: agentPaws,
       safe_mode: safeMode,
+      scoring_profile_id: scoringProfileId,
+    }),
+
+  /**
+   * Start a new execution on the online agents matching a saved selector
+   */
+  startWithSelector: (scenarioId: string, agentSelectorId: string, safeMode: boolean) =>
+    api.post('/executions', {
+      scenario_id: scenarioId,
+      agent_selector_id: agentSelectorId,
+      safe_mode: safeMode,
     }),
 
   /**
@@ -359,6 +1470,32 @@ export const executionApi = {
    * Complete an execution
    */
   complete: (id: string) => api.post(`/executions/${id}/complete`),
+
+  /**
+   * Export an execution with its results ('full' adds technique context)
+   */
+  export: (id: string, verbosity: 'minimal' | 'full' = 'full') =>
+    api.get(`/executions/${id}/export`, { params: { verbosity } }),
+
+  /**
+   * Get the settings, scenario, techniques and agents captured at launch
+   */
+  getSnapshot: (id: string) => api.get<ExecutionSnapshot>(`/executions/${id}/snapshot`),
+
+  /**
+   * Rescore a completed execution, with the default scoring profile unless one is given
+   */
+  rescore: (id: string, scoringProfileId?: string) =>
+    api.post<RescoreResult>(`/executions/${id}/rescore`, { scoring_profile_id: scoringProfileId }),
+
+  /**
+   * Rescore up to 100 completed executions with a scoring profile
+   */
+  rescoreMany: (executionIds: string[], scoringProfileId?: string) =>
+    api.post<RescoreReport>('/executions/rescore', {
+      execution_ids: executionIds,
+      scoring_profile_id: scoringProfileId,
+    }),
 };
 
 // Scenario Import/Export types
@@ -387,6 +1524,32 @@ export interface ImportScenariosResponse {
   scenarios: Scenario[];
 }
 
+export interface ScenarioValidationRequest {
+  /** Validate as an edit of this stored scenario */
+  scenario_id?: string;
+  name?: string;
+  description?: string;
+  phases: ScenarioPhase[];
+  tags?: string[];
+  /** Target platforms, all platforms of each technique when empty */
+  platforms?: string[];
+  safe_mode?: boolean;
+}
+
+export interface ValidationIssue {
+  /** Stable code, e.g. unknown_technique or circular_dependency */
+  code: string;
+  message: string;
+  phase?: string;
+  technique?: string;
+}
+
+export interface ScenarioValidation {
+  valid: boolean;
+  errors: ValidationIssue[];
+  warnings: ValidationIssue[];
+}
+
 // Need to import Scenario type - define locally for API
 export interface Scenario {
   id: string;
@@ -394,12 +1557,50 @@ export interface Scenario {
   description: string;
   phases: ScenarioPhase[];
   tags: string[];
+  deleted_at?: string; // Set while in the trash
 }
 
 export interface ScenarioPhase {
   name: string;
   techniques: string[];
   order?: number;
+  executors?: string[]; // Executor types tried first, in order
+}
+
+export interface TechniqueReadiness {
+  technique_id: string;
+  name?: string;
+  platforms: string[];
+  known: boolean;
+  is_safe: boolean;
+  ready: boolean;
+  compatible_agents: string[];
+  unprivileged_agents?: string[]; // Agents on which the technique is skipped for lack of elevation
+}
+
+export interface ScenarioReadiness {
+  scenario_id: string;
+  scenario_name: string;
+  score: number; // Percentage of techniques with a compatible online agent
+  total_techniques: number;
+  ready_techniques: number;
+  online_agents: number;
+  missing_platforms: string[];
+  unsafe_techniques: string[]; // Require approval, skipped in safe mode
+  unknown_techniques: string[];
+  techniques: TechniqueReadiness[];
+}
+
+export interface FlaggedTechnique {
+  id: string;
+  name: string;
+  status: TechniqueStatus;
+}
+
+export interface ScenarioLifecycleIssue {
+  scenario_id: string;
+  scenario_name: string;
+  techniques: FlaggedTechnique[]; // Deprecated or broken techniques still in use
 }
 
 // Scenario API methods
@@ -426,10 +1627,32 @@ export const scenarioApi = {
     api.put<Scenario>(`/scenarios/${id}`, data),
 
   /**
-   * Delete a scenario
+   * Move a scenario to the trash
    */
   delete: (id: string) => api.delete(`/scenarios/${id}`),
 
+  /**
+   * List deleted scenarios, most recently deleted first
+   */
+  listTrash: () => api.get<Scenario[]>('/scenarios/trash'),
+
+  /**
+   * Take a scenario out of the trash
+   */
+  restore: (id: string) => api.post(`/scenarios/${id}/restore`),
+
+  /**
+   * Create an editable copy of a scenario or built-in template
+   */
+  clone: (id: string, name?: string) =>
+    api.post<Scenario>(`/scenarios/${id}/clone`, name ? { name } : {}),
+
+  /**
+   * Validate a scenario without saving it, against optional target platforms and safe mode
+   */
+  validate: (data: ScenarioValidationRequest) =>
+    api.post<ScenarioValidation>('/scenarios/validate', data),
+
   /**
    * Export all scenarios (or specific ones by IDs)
    */
@@ -443,6 +1666,19 @@ export const scenarioApi = {
    */
   exportOne: (id: string) => api.get<ScenarioExport>(`/scenarios/${id}/export`),
 
+  /**
+   * Get readiness against the online fleet, or against the given agents
+   */
+  readiness: (id: string, agentPaws?: string[]) => {
+    const params = agentPaws?.length ? { agents: agentPaws.join(',') } : undefined;
+    return api.get<ScenarioReadiness>(`/scenarios/${id}/readiness`, { params });
+  },
+
+  /**
+   * List scenarios that use deprecated or broken techniques
+   */
+  lifecycleReport: () => api.get<ScenarioLifecycleIssue[]>('/scenarios/lifecycle-report'),
+
   /**
    * Import scenarios from JSON
    */
@@ -572,6 +1808,158 @@ export interface ExecutionSummary {
   executions_by_status: Record<string, number>;
 }
 
+export interface SigmaRuleCoverage {
+  id: string;
+  title?: string;
+  fired: number;
+}
+
+export interface TechniqueSigmaCoverage {
+  technique_id: string;
+  name?: string;
+  tactic?: string;
+  executions: number;
+  detected: number;
+  sigma_detected: number;
+  coverage: number;
+  rules: SigmaRuleCoverage[];
+}
+
+export interface TacticSigmaCoverage {
+  tactic: string;
+  techniques: number;
+  mapped_rules: number;
+  fired_rules: number;
+  coverage: number;
+}
+
+export interface ParentSigmaCoverage {
+  technique_id: string;
+  name?: string;
+  tactic?: string;
+  sub_techniques: string[];
+  executions: number;
+  detected: number;
+  sigma_detected: number;
+  mapped_rules: number;
+  fired_rules: number;
+  coverage: number;
+}
+
+export interface SigmaCoverageReport {
+  period: string;
+  executed_techniques: number;
+  mapped_techniques: number;
+  total_rules: number;
+  fired_rules: number;
+  coverage: number;
+  unmapped_techniques: string[];
+  by_tactic: TacticSigmaCoverage[];
+  by_parent: ParentSigmaCoverage[];
+  techniques: TechniqueSigmaCoverage[];
+}
+
+export interface CalendarDay {
+  date: string;
+  execution_count: number;
+  completed_count: number;
+  average_score: number | null;
+}
+
+export interface ExecutionCalendar {
+  start_date: string;
+  end_date: string;
+  scenario_ids?: string[];
+  total_executions: number;
+  active_days: number;
+  max_executions: number;
+  days: CalendarDay[];
+}
+
+export interface TechniqueFailure {
+  result_id: string;
+  execution_id: string;
+  agent_paw: string;
+  status: string;
+  at: string;
+}
+
+export interface TechniqueRunStats {
+  runs: number;
+  success_rate: number;
+  blocked_rate: number;
+  detection_rate: number;
+  error_rate: number;
+  median_duration_seconds: number | null;
+  flakiness: number;
+  last_failure?: TechniqueFailure;
+}
+
+export interface PlatformTechniqueStats extends TechniqueRunStats {
+  platform: string;
+}
+
+export interface ScorePoint {
+  execution_id: string;
+  scenario_id: string;
+  started_at: string;
+  overall: number;
+  by_tactic: Record<string, number>;
+}
+
+export interface ScoreTimeline {
+  scenario_id?: string;
+  days: number;
+  since: string;
+  tactics: string[];
+  points: ScorePoint[];
+}
+
+export interface DetectionRegression {
+  technique_id: string;
+  technique_name?: string;
+  agent_paw: string;
+  previous_status: string;
+  status: string;
+  previous_execution_id: string;
+  previous_result_id: string;
+  result_id: string;
+}
+
+export interface ExecutionDiff {
+  scenario_id: string;
+  baseline_execution_id: string;
+  execution_id: string;
+  baseline_score: number;
+  score: number;
+  score_delta: number;
+  tactics: Array<{
+    tactic: string;
+    baseline_score: number | null; // null when the run did not test the tactic
+    score: number | null;
+    delta: number | null;
+  }>;
+  techniques: Array<{
+    technique_id: string;
+    technique_name?: string;
+    agent_paw: string;
+    baseline_status?: string;
+    status?: string;
+    change: 'improved' | 'regressed' | 'changed' | 'added' | 'removed';
+  }>;
+  added_agents: string[];
+  removed_agents: string[];
+}
+
+export interface TechniqueStats {
+  technique_id: string;
+  name?: string;
+  days: number;
+  since: string;
+  overall: TechniqueRunStats;
+  by_platform: PlatformTechniqueStats[];
+}
+
 // Analytics API methods
 export const analyticsApi = {
   /**
@@ -597,6 +1985,52 @@ export const analyticsApi = {
    */
   periodStats: (start: string, end: string) =>
     api.get<PeriodStats>('/analytics/period', { params: { start, end } }),
+
+  /**
+   * Get Sigma rule coverage of executed techniques
+   */
+  sigmaCoverage: (days: number = 30) =>
+    api.get<SigmaCoverageReport>('/analytics/sigma-coverage', { params: { days } }),
+
+  /**
+   * Get executions per day with average scores (calendar heatmap)
+   */
+  calendar: (params: { start?: string; end?: string; scenarioIds?: string[] } = {}) =>
+    api.get<ExecutionCalendar>('/analytics/calendar', {
+      params: {
+        start: params.start,
+        end: params.end,
+        scenario_id: params.scenarioIds?.length ? params.scenarioIds.join(',') : undefined,
+      },
+    }),
+
+  /**
+   * Get run statistics of a technique, overall and per agent platform
+   */
+  techniqueStats: (techniqueId: string, days: number = 90) =>
+    api.get<TechniqueStats>(`/analytics/techniques/${encodeURIComponent(techniqueId)}/stats`, { params: { days } }),
+
+  /**
+   * Get the overall and per tactic score of each completed execution, for a scenario or all of them
+   */
+  scoreTimeline: (params: { scenarioId?: string; days?: number } = {}) =>
+    api.get<ScoreTimeline>('/analytics/scores/timeline', {
+      params: { scenario_id: params.scenarioId, days: params.days ?? 90 },
+    }),
+
+  /**
+   * Get the detection regressions of an execution against the previous run of its scenario
+   */
+  executionRegressions: (executionId: string) =>
+    api.get<DetectionRegression[]>(`/analytics/executions/${encodeURIComponent(executionId)}/regressions`),
+
+  /**
+   * Diff a run of a scenario against a baseline run of the same scenario
+   */
+  compareExecutions: (baselineId: string, executionId: string) =>
+    api.get<ExecutionDiff>(
+      `/executions/${encodeURIComponent(baselineId)}/compare/${encodeURIComponent(executionId)}`
+    ),
 };
 
 // Notification types
@@ -605,9 +2039,12 @@ export type NotificationType =
   | 'execution_completed'
   | 'execution_failed'
   | 'score_alert'
-  | 'agent_offline';
+  | 'agent_offline'
+  | 'security_alert'
+  | 'detection_regression'
+  | 'critical_undetected';
 
-export type NotificationChannel = 'email' | 'webhook';
+export type NotificationChannel = 'email' | 'webhook' | 'teams' | 'pagerduty' | 'opsgenie';
 
 export interface Notification {
   id: string;
@@ -619,36 +2056,51 @@ export interface Notification {
   read: boolean;
   sent_at?: string;
   created_at: string;
+  digest_pending?: boolean;
+}
+
+// Channels each notification type is delivered on
+export type NotificationPreferences = Partial<Record<NotificationType, NotificationChannel[]>>;
+
+// Window (HH:MM, in an IANA timezone) during which emails are held
+export interface QuietHours {
+  start: string;
+  end: string;
+  timezone?: string;
 }
 
+export type NotificationDigest = '' | 'hourly' | 'daily';
+
 export interface NotificationSettings {
   id: string;
   user_id: string;
-  channel: NotificationChannel;
   enabled: boolean;
   email_address?: string;
   webhook_url?: string;
-  notify_on_start: boolean;
-  notify_on_complete: boolean;
-  notify_on_failure: boolean;
-  notify_on_score_alert: boolean;
+  teams_webhook_url?: string;
+  webhook_secret?: string;
+  pagerduty_routing_key?: string;
+  opsgenie_api_key?: string;
+  preferences: NotificationPreferences;
   score_alert_threshold: number;
-  notify_on_agent_offline: boolean;
+  quiet_hours?: QuietHours;
+  digest?: NotificationDigest;
   created_at: string;
   updated_at: string;
 }
 
 export interface NotificationSettingsRequest {
-  channel: NotificationChannel;
   enabled: boolean;
   email_address?: string;
   webhook_url?: string;
-  notify_on_start: boolean;
-  notify_on_complete: boolean;
-  notify_on_failure: boolean;
-  notify_on_score_alert: boolean;
+  teams_webhook_url?: string;
+  webhook_secret?: string;
+  pagerduty_routing_key?: string;
+  opsgenie_api_key?: string;
+  preferences: NotificationPreferences;
   score_alert_threshold: number;
-  notify_on_agent_offline: boolean;
+  quiet_hours?: QuietHours | null;
+  digest?: NotificationDigest;
 }
 
 export interface SMTPConfig {
@@ -657,12 +2109,52 @@ export interface SMTPConfig {
   username: string;
   from: string;
   use_tls: boolean;
+  source?: 'env' | 'api';
+  updated_by?: string;
+  updated_at?: string;
+}
+
+export interface SMTPConfigRequest {
+  host: string;
+  port: number;
+  username?: string;
+  password?: string; // Empty keeps the current password
+  from: string;
+  use_tls: boolean;
 }
 
 export interface UnreadCountResponse {
   count: number;
 }
 
+export type WebhookDeliveryStatus = 'pending' | 'delivered' | 'failed';
+
+export interface WebhookDelivery {
+  id: string;
+  user_id: string;
+  channel: NotificationChannel;
+  event: NotificationType;
+  url: string;
+  payload: unknown;
+  status: WebhookDeliveryStatus;
+  attempts: number;
+  last_status_code?: number;
+  last_error?: string;
+  next_attempt_at?: string;
+  delivered_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export type DeepLinkKind = 'execution' | 'agent';
+
+export interface DeepLinkTarget {
+  kind: DeepLinkKind;
+  id: string;
+  notification_id?: string;
+  path: string;
+}
+
 // Notification API methods
 export const notificationApi = {
   /**
@@ -686,6 +2178,17 @@ export const notificationApi = {
    */
   markAllAsRead: () => api.post('/notifications/read-all'),
 
+  /**
+   * Delete a notification
+   */
+  deleteNotification: (id: string) => api.delete(`/notifications/${id}`),
+
+  /**
+   * Delete notifications by ID, and every read notification when read is set
+   */
+  deleteNotifications: (ids: string[], read = false) =>
+    api.post<{ deleted: number }>('/notifications/delete', { ids, read }),
+
   /**
    * Get notification settings
    */
@@ -713,16 +2216,58 @@ export const notificationApi = {
    */
   getSMTPConfig: () => api.get<SMTPConfig>('/notifications/smtp'),
 
+  /**
+   * Set SMTP configuration, used at once instead of the environment
+   */
+  updateSMTPConfig: (data: SMTPConfigRequest) => api.put<SMTPConfig>('/notifications/smtp', data),
+
+  /**
+   * Delete the SMTP configuration set through the API
+   */
+  deleteSMTPConfig: () => api.delete('/notifications/smtp'),
+
   /**
    * Test SMTP connection
    */
   testSMTP: (email: string) =>
     api.post('/notifications/smtp/test', { email }),
+
+  /**
+   * Resolve a signed notification link to the dashboard page it opens
+   */
+  resolveLink: (token: string) => api.get<DeepLinkTarget>(`/links/${encodeURIComponent(token)}`),
+
+  /**
+   * List webhook deliveries of the current user, newest first
+   */
+  listWebhookDeliveries: (status?: WebhookDeliveryStatus, limit: number = 50) =>
+    api.get<WebhookDelivery[]>('/notifications/webhook-deliveries', { params: { status, limit } }),
+
+  /**
+   * Get a webhook delivery
+   */
+  getWebhookDelivery: (id: string) =>
+    api.get<WebhookDelivery>(`/notifications/webhook-deliveries/${id}`),
+
+  /**
+   * Send a webhook delivery again
+   */
+  redeliverWebhook: (id: string) =>
+    api.post<WebhookDelivery>(`/notifications/webhook-deliveries/${id}/redeliver`),
 };
 
 // Schedule types
-export type ScheduleFrequency = 'once' | 'hourly' | 'daily' | 'weekly' | 'monthly' | 'cron';
+export type ScheduleFrequency = 'once' | 'hourly' | 'daily' | 'weekly' | 'monthly' | 'cron' | 'chained';
 export type ScheduleStatus = 'active' | 'paused' | 'disabled';
+export type MisfirePolicy = 'skip' | 'run_once_immediately' | 'run_all_missed';
+export type ChainCondition = 'success' | 'score_below' | 'score_at_least';
+
+// When the executions of a schedule page on the PagerDuty and Opsgenie channels
+export interface ScheduleAlerting {
+  disabled?: boolean;
+  score_threshold?: number;
+  severities?: string[];
+}
 
 export interface Schedule {
   id: string;
@@ -730,8 +2275,15 @@ export interface Schedule {
   description: string;
   scenario_id: string;
   agent_paw: string;
+  agent_selector_id?: string;
   frequency: ScheduleFrequency;
   cron_expr: string;
+  timezone?: string;
+  misfire_policy?: MisfirePolicy;
+  depends_on?: string;
+  chain_condition?: ChainCondition;
+  chain_threshold?: number;
+  alerting?: ScheduleAlerting;
   safe_mode: boolean;
   status: ScheduleStatus;
   next_run_at: string | null;
@@ -740,6 +2292,7 @@ export interface Schedule {
   created_by: string;
   created_at: string;
   updated_at: string;
+  next_runs?: string[];
 }
 
 export interface ScheduleRun {
@@ -757,8 +2310,15 @@ export interface CreateScheduleRequest {
   description?: string;
   scenario_id: string;
   agent_paw?: string;
+  agent_selector_id?: string;
   frequency: ScheduleFrequency;
   cron_expr?: string;
+  timezone?: string;
+  misfire_policy?: MisfirePolicy;
+  depends_on?: string;
+  chain_condition?: ChainCondition;
+  chain_threshold?: number;
+  alerting?: ScheduleAlerting;
   safe_mode: boolean;
   start_at?: string;
 }
@@ -21,6 +21,9 @@ export const tacticBadgeColors: Record<string, string> = {
   command_and_control: 'bg-amber-100 text-amber-700 dark:bg-amber-900/30 dark:text-amber-400',
   exfiltration: 'bg-rose-100 text-rose-700 dark:bg-rose-900/30 dark:text-rose-400',
   impact: 'bg-red-100 text-red-700 dark:bg-red-900/30 dark:text-red-400',
+  evasion: 'bg-emerald-100 text-emerald-700 dark:bg-emerald-900/30 dark:text-emerald-400',
+  inhibit_response_function: 'bg-fuchsia-100 text-fuchsia-700 dark:bg-fuchsia-900/30 dark:text-fuchsia-400',
+  impair_process_control: 'bg-violet-100 text-violet-700 dark:bg-violet-900/30 dark:text-violet-400',
 };
 
 /**
@@ -58,6 +61,9 @@ export const tacticBarColors: Record<string, string> = {
   command_and_control: 'bg-amber-500',
   exfiltration: 'bg-rose-500',
   impact: 'bg-red-600',
+  evasion: 'bg-emerald-500',
+  inhibit_response_function: 'bg-fuchsia-500',
+  impair_process_control: 'bg-violet-500',
 };
 
 /**
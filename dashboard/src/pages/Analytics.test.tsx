@@ -28,12 +28,14 @@ vi.mock('chart.js', () => ({
 const mockCompare = vi.fn();
 const mockTrend = vi.fn();
 const mockSummary = vi.fn();
+const mockSigmaCoverage = vi.fn();
 
 vi.mock('../lib/api', () => ({
   analyticsApi: {
     compare: (...args: unknown[]) => mockCompare(...args),
     trend: (...args: unknown[]) => mockTrend(...args),
     summary: (...args: unknown[]) => mockSummary(...args),
+    sigmaCoverage: (...args: unknown[]) => mockSigmaCoverage(...args),
   },
 }));
 
@@ -149,6 +151,7 @@ describe('Analytics Page', () => {
     mockCompare.mockResolvedValue({ data: mockComparisonData });
     mockTrend.mockResolvedValue({ data: mockTrendData });
     mockSummary.mockResolvedValue({ data: mockSummaryData });
+    mockSigmaCoverage.mockResolvedValue({ data: null });
   });
 
   it('renders loading state initially', () => {
@@ -1430,4 +1433,44 @@ describe('Analytics Null Query Data Fallbacks', () => {
       expect(screen.getByText('No scenario data available')).toBeInTheDocument();
     });
   });
+
+  it('renders sigma coverage heatmap when techniques were executed', async () => {
+    mockSigmaCoverage.mockResolvedValue({
+      data: {
+        period: '30d',
+        executed_techniques: 2,
+        mapped_techniques: 1,
+        total_rules: 2,
+        fired_rules: 1,
+        coverage: 50,
+        unmapped_techniques: ['T1082'],
+        by_tactic: [
+          { tactic: 'discovery', techniques: 1, mapped_rules: 0, fired_rules: 0, coverage: 0 },
+          { tactic: 'execution', techniques: 1, mapped_rules: 2, fired_rules: 1, coverage: 50 },
+        ],
+        by_parent: [],
+        techniques: [],
+      },
+    });
+
+    renderAnalytics();
+
+    await waitFor(() => {
+      expect(screen.getByTestId('sigma-coverage')).toBeInTheDocument();
+    });
+    expect(screen.getByText('1/2 rules fired (50%)')).toBeInTheDocument();
+    expect(screen.getByText('execution')).toBeInTheDocument();
+    expect(screen.getByText('No Sigma rules mapped for: T1082')).toBeInTheDocument();
+  });
+
+  it('hides sigma coverage when the report fails', async () => {
+    mockSigmaCoverage.mockRejectedValue(new Error('technique catalog not configured'));
+
+    renderAnalytics();
+
+    await waitFor(() => {
+      expect(screen.getByText('Execution Summary')).toBeInTheDocument();
+    });
+    expect(screen.queryByTestId('sigma-coverage')).not.toBeInTheDocument();
+  });
 });
@@ -21,7 +21,7 @@ import {
   ExclamationTriangleIcon,
   ArrowPathIcon,
 } from '@heroicons/react/24/outline';
-import { analyticsApi, ScoreComparison, ScoreTrend, ExecutionSummary } from '../lib/api';
+import { analyticsApi, ScoreComparison, ScoreTrend, ExecutionSummary, SigmaCoverageReport } from '../lib/api';
 import { LoadingState } from '../components/LoadingState';
 
 ChartJS.register(
@@ -36,6 +36,13 @@ ChartJS.register(
   Filler
 );
 
+function sigmaCellColor(mappedRules: number, coverage: number): string {
+  if (mappedRules === 0) return 'bg-gray-100 dark:bg-gray-800 text-gray-500';
+  if (coverage >= 75) return 'bg-green-100 dark:bg-green-900/30 text-green-700 dark:text-green-400';
+  if (coverage >= 40) return 'bg-yellow-100 dark:bg-yellow-900/30 text-yellow-700 dark:text-yellow-400';
+  return 'bg-red-100 dark:bg-red-900/30 text-red-700 dark:text-red-400';
+}
+
 function formatScoreChange(change?: number): string {
   if (!change) return '0';
   const prefix = change > 0 ? '+' : '';
@@ -81,6 +88,12 @@ export default function Analytics() {
     queryFn: () => analyticsApi.summary(period).then((res) => res.data),
   });
 
+  // Sigma coverage is optional, it does not block the page
+  const { data: sigmaCoverage } = useQuery<SigmaCoverageReport>({
+    queryKey: ['analytics', 'sigma-coverage', period],
+    queryFn: () => analyticsApi.sigmaCoverage(period).then((res) => res.data),
+  });
+
   const isLoading = comparisonLoading || trendLoading || summaryLoading;
   const hasError = comparisonError || trendError || summaryError;
 
@@ -381,6 +394,38 @@ export default function Analytics() {
           </div>
         </div>
       </div>
+
+      {sigmaCoverage && sigmaCoverage.executed_techniques > 0 && (
+        <div className="card" data-testid="sigma-coverage">
+          <div className="flex items-center justify-between mb-4">
+            <h2 className="text-lg font-semibold">Sigma Rule Coverage</h2>
+            <span className="text-sm text-gray-500 dark:text-gray-400">
+              {sigmaCoverage.fired_rules}/{sigmaCoverage.total_rules} rules fired ({sigmaCoverage.coverage.toFixed(0)}%)
+            </span>
+          </div>
+          <div className="grid grid-cols-2 md:grid-cols-4 lg:grid-cols-7 gap-2">
+            {sigmaCoverage.by_tactic.map((cell) => (
+              <div
+                key={cell.tactic || 'unknown'}
+                className={`rounded-lg p-3 text-center ${sigmaCellColor(cell.mapped_rules, cell.coverage)}`}
+                title={`${cell.techniques} technique(s), ${cell.fired_rules}/${cell.mapped_rules} rules fired`}
+              >
+                <div className="text-xs font-medium capitalize truncate">
+                  {cell.tactic ? cell.tactic.replace(/-/g, ' ') : 'unknown'}
+                </div>
+                <div className="text-lg font-semibold">
+                  {cell.mapped_rules > 0 ? `${cell.coverage.toFixed(0)}%` : '—'}
+                </div>
+              </div>
+            ))}
+          </div>
+          {sigmaCoverage.unmapped_techniques.length > 0 && (
+            <p className="text-sm text-gray-500 dark:text-gray-400 mt-3">
+              No Sigma rules mapped for: {sigmaCoverage.unmapped_techniques.join(', ')}
+            </p>
+          )}
+        </div>
+      )}
     </div>
   );
 }
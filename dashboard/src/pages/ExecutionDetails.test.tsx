@@ -144,6 +144,35 @@ describe('ExecutionDetails Page', () => {
     expect(screen.getByText('0%')).toBeInTheDocument();
   });
 
+  it('renders the progress and ETA of a running execution', async () => {
+    const mockExecution = {
+      id: 'exec-running',
+      scenario_id: 'running-scenario',
+      status: 'running',
+      started_at: '2024-01-15T12:00:00Z',
+      safe_mode: true,
+      progress: {
+        total: 12,
+        completed: 5,
+        failed: 1,
+        skipped: 0,
+        percent: 41.7,
+        current_phase: 'Discovery',
+        remaining_seconds: 95,
+        eta: '2024-01-15T12:06:35Z',
+      },
+    };
+
+    vi.mocked(executionApi.get).mockResolvedValue({ data: mockExecution } as never);
+    vi.mocked(executionApi.getResults).mockResolvedValue({ data: [] } as never);
+
+    renderWithRouter('exec-running');
+
+    expect(await screen.findByText('5 / 12 steps - Discovery')).toBeInTheDocument();
+    expect(screen.getByText('About 1m 35s left')).toBeInTheDocument();
+    expect(screen.getByRole('progressbar')).toHaveAttribute('aria-valuenow', '41.7');
+  });
+
   it('renders empty results state', async () => {
     const mockExecution = {
       id: 'exec-pending',
@@ -212,6 +241,50 @@ describe('ExecutionDetails Page', () => {
     expect(await screen.findByText('Execution Failed')).toBeInTheDocument();
   });
 
+  it('renders result killed for exceeding its resource limits', async () => {
+    const mockExecution = {
+      id: 'exec-limit',
+      scenario_id: 'limit-scenario',
+      status: 'completed',
+      started_at: '2024-01-15T10:00:00Z',
+      safe_mode: true,
+      score: { overall: 0, blocked: 0, detected: 0, successful: 0, total: 2 },
+    };
+
+    const mockResults = [
+      {
+        id: 'result-memory',
+        execution_id: 'exec-limit',
+        technique_id: 'T1059',
+        agent_paw: 'agent-1',
+        status: 'limit_exceeded',
+        output: 'resource limit exceeded: memory',
+        detected: false,
+        start_time: '2024-01-15T10:00:00Z',
+        end_time: '2024-01-15T10:01:00Z',
+      },
+      {
+        id: 'result-time',
+        execution_id: 'exec-limit',
+        technique_id: 'T1082',
+        agent_paw: 'agent-1',
+        status: 'timeout',
+        output: 'resource limit exceeded: time',
+        detected: false,
+        start_time: '2024-01-15T10:00:00Z',
+        end_time: '2024-01-15T10:05:00Z',
+      },
+    ];
+
+    vi.mocked(executionApi.get).mockResolvedValue({ data: mockExecution } as never);
+    vi.mocked(executionApi.getResults).mockResolvedValue({ data: mockResults } as never);
+
+    renderWithRouter('exec-limit');
+
+    expect(await screen.findByText('Limit Exceeded')).toBeInTheDocument();
+    expect(screen.getByText('Timed Out')).toBeInTheDocument();
+  });
+
   it('renders result with detected status', async () => {
     const mockExecution = {
       id: 'exec-detected',
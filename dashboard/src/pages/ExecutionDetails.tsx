@@ -1,6 +1,7 @@
 import { useParams, useNavigate } from 'react-router-dom';
 import { useQuery } from '@tanstack/react-query';
-import { ArrowLeftIcon, CheckCircleIcon, XCircleIcon, ExclamationTriangleIcon, ClockIcon } from '@heroicons/react/24/outline';
+import { ArrowLeftIcon, CheckCircleIcon, XCircleIcon, ExclamationTriangleIcon, ClockIcon, ArrowDownTrayIcon } from '@heroicons/react/24/outline';
+import toast from 'react-hot-toast';
 import { executionApi } from '../lib/api';
 import { Execution, ExecutionResult } from '../types';
 import { LoadingState } from '../components/LoadingState';
@@ -12,8 +13,10 @@ import { formatDistanceToNow, format } from 'date-fns';
  * Status meanings:
  * - success/successful: technique executed successfully (attack worked - bad for security)
  * - failed: technique execution failed (technical error - neutral)
+ * - timeout / limit_exceeded: the agent killed the command for exceeding its time or resource limits (neutral)
  * - blocked: technique was blocked by security controls (good for security)
  * - detected: technique was detected by security tools (good for security)
+ * - skipped_insufficient_privilege: not run, the agent lacks the elevation the technique requires
  */
 function getResultStatusInfo(status: string): { badgeClass: string; Icon: React.ElementType } {
   switch (status) {
@@ -22,6 +25,8 @@ function getResultStatusInfo(status: string): { badgeClass: string; Icon: React.
       // Attack succeeded = security vulnerability = danger (red)
       return { badgeClass: 'badge-danger', Icon: ExclamationTriangleIcon };
     case 'failed':
+    case 'timeout':
+    case 'limit_exceeded':
       // Technique execution failed (technical error) = neutral warning
       return { badgeClass: 'badge-warning', Icon: XCircleIcon };
     case 'detected':
@@ -31,6 +36,7 @@ function getResultStatusInfo(status: string): { badgeClass: string; Icon: React.
       // Attack blocked = security working = success (green)
       return { badgeClass: 'badge-success', Icon: CheckCircleIcon };
     case 'pending':
+    case 'queued':
     case 'running':
       return { badgeClass: 'badge-warning', Icon: ClockIcon };
     default:
@@ -48,12 +54,20 @@ function getStatusLabel(status: string): string {
       return 'Attack Succeeded';
     case 'failed':
       return 'Execution Failed';
+    case 'timeout':
+      return 'Timed Out';
+    case 'limit_exceeded':
+      return 'Limit Exceeded';
+    case 'skipped_insufficient_privilege':
+      return 'Skipped (needs elevation)';
     case 'detected':
       return 'Detected';
     case 'blocked':
       return 'Blocked';
     case 'pending':
       return 'Pending';
+    case 'queued':
+      return 'Queued (agent offline)';
     case 'running':
       return 'Running';
     default:
@@ -61,6 +75,21 @@ function getStatusLabel(status: string): string {
   }
 }
 
+/**
+ * Formats an estimated duration in seconds, e.g. "1h 5m" or "2m 30s".
+ */
+function formatRemaining(seconds: number): string {
+  const hours = Math.floor(seconds / 3600);
+  const minutes = Math.floor((seconds % 3600) / 60);
+  if (hours > 0) {
+    return `${hours}h ${minutes}m`;
+  }
+  if (minutes > 0) {
+    return `${minutes}m ${Math.round(seconds % 60)}s`;
+  }
+  return `${Math.round(seconds)}s`;
+}
+
 /**
  * Execution Details page component.
  * Displays detailed results of a scenario execution.
@@ -106,12 +135,32 @@ export default function ExecutionDetails() {
     );
   }
 
+  const handleExport = async () => {
+    try {
+      const response = await executionApi.export(execution.id, 'full');
+      const blob = new Blob([JSON.stringify(response.data, null, 2)], {
+        type: 'application/json',
+      });
+      const url = URL.createObjectURL(blob);
+      const a = document.createElement('a');
+      a.href = url;
+      a.download = `autostrike-execution-${execution.id}.json`;
+      document.body.appendChild(a);
+      a.click();
+      a.remove();
+      URL.revokeObjectURL(url);
+    } catch {
+      toast.error('Failed to export execution');
+    }
+  };
+
   const getExecutionStatusBadge = (status: string) => {
     switch (status) {
       case 'completed':
         return 'badge-success';
       case 'running':
       case 'pending':
+      case 'interrupted':
         return 'badge-warning';
       case 'cancelled':
       case 'failed':
@@ -138,9 +187,15 @@ export default function ExecutionDetails() {
             <h1 className="text-3xl font-bold text-gray-900 dark:text-gray-100">Execution Details</h1>
             <p className="text-gray-500 dark:text-gray-400 font-mono text-sm mt-1">{execution.id}</p>
           </div>
-          <span className={`badge ${getExecutionStatusBadge(execution.status)}`}>
-            {execution.status}
-          </span>
+          <div className="flex items-center gap-3">
+            <button onClick={handleExport} className="btn-secondary flex items-center">
+              <ArrowDownTrayIcon className="h-5 w-5 mr-2" />
+              Export
+            </button>
+            <span className={`badge ${getExecutionStatusBadge(execution.status)}`}>
+              {execution.status}
+            </span>
+          </div>
         </div>
       </div>
 
@@ -174,6 +229,37 @@ export default function ExecutionDetails() {
           </div>
         </div>
 
+        {/* Progress of an active execution */}
+        {(execution.status === 'running' || execution.status === 'pending') && execution.progress && (
+          <div className="mt-6 pt-6 border-t border-gray-200 dark:border-gray-700">
+            <div className="flex justify-between text-sm text-gray-500 dark:text-gray-400 mb-2">
+              <span>
+                {execution.progress.completed} / {execution.progress.total} steps
+                {execution.progress.current_phase && ` - ${execution.progress.current_phase}`}
+              </span>
+              {execution.progress.remaining_seconds !== undefined && (
+                <span>About {formatRemaining(execution.progress.remaining_seconds)} left</span>
+              )}
+            </div>
+            <div
+              className="h-2 bg-gray-200 dark:bg-gray-700 rounded-full overflow-hidden"
+              role="progressbar"
+              aria-valuenow={execution.progress.percent}
+              aria-valuemin={0}
+              aria-valuemax={100}
+            >
+              <div className="h-full bg-primary-600" style={{ width: `${execution.progress.percent}%` }} />
+            </div>
+          </div>
+        )}
+
+        {/* Why a failed execution failed */}
+        {execution.status === 'failed' && execution.failure_reason && (
+          <p className="mt-6 pt-6 border-t border-gray-200 dark:border-gray-700 text-sm text-red-600 dark:text-red-400">
+            {execution.failure_reason}
+          </p>
+        )}
+
         {/* Score Breakdown */}
         {execution.score && (
           <div className="mt-6 pt-6 border-t border-gray-200 dark:border-gray-700">
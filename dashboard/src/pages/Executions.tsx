@@ -23,6 +23,8 @@ function getStatusBadgeClass(status: string): string {
       return 'badge-warning';
     case 'pending':
       return 'badge-warning';
+    case 'interrupted':
+      return 'badge-warning';
     case 'cancelled':
       return 'badge-danger';
     default:
@@ -34,7 +36,7 @@ function getStatusBadgeClass(status: string): string {
  * Check if an execution can be stopped
  */
 function canStopExecution(status: ExecutionStatus): boolean {
-  return status === 'running' || status === 'pending';
+  return status === 'running' || status === 'pending' || status === 'interrupted';
 }
 
 /**
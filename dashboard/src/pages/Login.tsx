@@ -1,5 +1,5 @@
 import { useState, useEffect, FormEvent } from 'react';
-import { useNavigate, useLocation } from 'react-router-dom';
+import { Link, useNavigate, useLocation } from 'react-router-dom';
 import { useAuth } from '../contexts/AuthContext';
 
 interface LocationState {
@@ -136,6 +136,11 @@ export default function Login() {
         </form>
 
         <div className="mt-6 text-center text-xs text-gray-500">
+          <p>
+            <Link to="/reset-password" className="text-primary-600 hover:text-primary-700 dark:text-primary-400">
+              Forgot your password?
+            </Link>
+          </p>
           <p>Contact your administrator for access credentials</p>
         </div>
       </div>
@@ -0,0 +1,56 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import { render, screen } from '@testing-library/react';
+import { MemoryRouter, Route, Routes } from 'react-router-dom';
+import OpenLink from './OpenLink';
+import { notificationApi } from '../lib/api';
+
+vi.mock('../lib/api', () => ({
+  notificationApi: {
+    resolveLink: vi.fn(),
+  },
+}));
+
+function renderLink(token: string) {
+  return render(
+    <MemoryRouter initialEntries={[`/links/${token}`]}>
+      <Routes>
+        <Route path="/links/:token" element={<OpenLink />} />
+        <Route path="/executions/:id" element={<div>Execution Page</div>} />
+      </Routes>
+    </MemoryRouter>
+  );
+}
+
+describe('OpenLink Page', () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+  });
+
+  it('navigates to the resolved page', async () => {
+    vi.mocked(notificationApi.resolveLink).mockResolvedValue({
+      data: { kind: 'execution', id: 'exec-1', path: '/executions/exec-1' },
+    } as never);
+
+    renderLink('signed-token');
+
+    expect(await screen.findByText('Execution Page')).toBeInTheDocument();
+    expect(notificationApi.resolveLink).toHaveBeenCalledWith('signed-token');
+  });
+
+  it('shows an expired link message', async () => {
+    vi.mocked(notificationApi.resolveLink).mockRejectedValue({ response: { status: 410 } });
+
+    renderLink('old-token');
+
+    expect(await screen.findByText('This link has expired.')).toBeInTheDocument();
+    expect(screen.getByText('Go to Dashboard')).toBeInTheDocument();
+  });
+
+  it('shows a message for links sent to another user', async () => {
+    vi.mocked(notificationApi.resolveLink).mockRejectedValue({ response: { status: 403 } });
+
+    renderLink('someone-else');
+
+    expect(await screen.findByText('This link was sent to another user.')).toBeInTheDocument();
+  });
+});
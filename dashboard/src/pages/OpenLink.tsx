@@ -0,0 +1,57 @@
+import { useEffect, useState } from 'react';
+import { Link, useNavigate, useParams } from 'react-router-dom';
+import { notificationApi } from '../lib/api';
+import { LoadingState } from '../components/LoadingState';
+
+/**
+ * Returns the message shown when a notification link cannot be opened.
+ */
+function linkErrorMessage(status?: number): string {
+  switch (status) {
+    case 410:
+      return 'This link has expired.';
+    case 403:
+      return 'This link was sent to another user.';
+    default:
+      return 'This link is invalid.';
+  }
+}
+
+/**
+ * Opens a signed notification link.
+ * The link is resolved for the logged-in user, then replaced by the page it targets.
+ */
+export default function OpenLink() {
+  const { token } = useParams<{ token: string }>();
+  const navigate = useNavigate();
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!token) return;
+    let cancelled = false;
+    notificationApi
+      .resolveLink(token)
+      .then((res) => {
+        if (!cancelled) navigate(res.data.path, { replace: true });
+      })
+      .catch((err: unknown) => {
+        if (!cancelled) setError(linkErrorMessage((err as { response?: { status: number } })?.response?.status));
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [token, navigate]);
+
+  if (error) {
+    return (
+      <div className="card text-center py-12">
+        <p className="text-gray-700 dark:text-gray-300">{error}</p>
+        <Link to="/dashboard" className="mt-4 inline-block text-primary-600 hover:text-primary-500">
+          Go to Dashboard
+        </Link>
+      </div>
+    );
+  }
+
+  return <LoadingState message="Opening link..." />;
+}
@@ -0,0 +1,79 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import { render, screen, fireEvent, waitFor } from '@testing-library/react';
+import { MemoryRouter } from 'react-router-dom';
+import ResetPassword from './ResetPassword';
+import { authApi } from '../lib/api';
+
+vi.mock('../lib/api', () => ({
+  authApi: {
+    forgotPassword: vi.fn(),
+    resetPassword: vi.fn(),
+  },
+}));
+
+function renderResetPassword(path = '/reset-password') {
+  return render(
+    <MemoryRouter initialEntries={[path]}>
+      <ResetPassword />
+    </MemoryRouter>
+  );
+}
+
+describe('ResetPassword Page', () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+  });
+
+  it('requests a reset link without a token', async () => {
+    vi.mocked(authApi.forgotPassword).mockResolvedValue({ data: {} } as never);
+    renderResetPassword();
+
+    expect(screen.getByText('Reset your password')).toBeInTheDocument();
+    fireEvent.change(screen.getByLabelText('Email address'), { target: { value: 'user@example.com' } });
+    fireEvent.click(screen.getByRole('button', { name: 'Send reset link' }));
+
+    await waitFor(() => {
+      expect(authApi.forgotPassword).toHaveBeenCalledWith('user@example.com');
+      expect(screen.getByText(/a password reset link has been sent/)).toBeInTheDocument();
+    });
+  });
+
+  it('resets the password with the token of the link', async () => {
+    vi.mocked(authApi.resetPassword).mockResolvedValue({ data: {} } as never);
+    renderResetPassword('/reset-password?token=abc123');
+
+    expect(screen.getByText('Choose a new password')).toBeInTheDocument();
+    fireEvent.change(screen.getByLabelText('New password'), { target: { value: 'newpassword' } });
+    fireEvent.change(screen.getByLabelText('Confirm password'), { target: { value: 'newpassword' } });
+    fireEvent.click(screen.getByRole('button', { name: 'Reset password' }));
+
+    await waitFor(() => {
+      expect(authApi.resetPassword).toHaveBeenCalledWith('abc123', 'newpassword');
+      expect(screen.getByText(/Your password has been reset/)).toBeInTheDocument();
+    });
+  });
+
+  it('rejects mismatched passwords without calling the API', () => {
+    renderResetPassword('/reset-password?token=abc123');
+
+    fireEvent.change(screen.getByLabelText('New password'), { target: { value: 'newpassword' } });
+    fireEvent.change(screen.getByLabelText('Confirm password'), { target: { value: 'otherpassword' } });
+    fireEvent.click(screen.getByRole('button', { name: 'Reset password' }));
+
+    expect(screen.getByText('The passwords do not match.')).toBeInTheDocument();
+    expect(authApi.resetPassword).not.toHaveBeenCalled();
+  });
+
+  it('shows an expired link', async () => {
+    vi.mocked(authApi.resetPassword).mockRejectedValue({ response: { status: 400 } });
+    renderResetPassword('/reset-password?token=expired');
+
+    fireEvent.change(screen.getByLabelText('New password'), { target: { value: 'newpassword' } });
+    fireEvent.change(screen.getByLabelText('Confirm password'), { target: { value: 'newpassword' } });
+    fireEvent.click(screen.getByRole('button', { name: 'Reset password' }));
+
+    await waitFor(() => {
+      expect(screen.getByText(/invalid or has expired/)).toBeInTheDocument();
+    });
+  });
+});
@@ -0,0 +1,173 @@
+import { useState, FormEvent } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import { authApi } from '../lib/api';
+
+const inputClassName =
+  'mt-1 appearance-none relative block w-full px-3 py-2 border border-gray-300 dark:border-gray-700 bg-white dark:bg-gray-800 text-gray-900 dark:text-white placeholder-gray-400 dark:placeholder-gray-500 rounded-md focus:outline-none focus:ring-primary-500 focus:border-primary-500 focus:z-10 sm:text-sm';
+
+const buttonClassName =
+  'group relative w-full flex justify-center py-2 px-4 border border-transparent text-sm font-medium rounded-md text-white bg-primary-600 hover:bg-primary-700 focus:outline-none focus:ring-2 focus:ring-offset-2 focus:ring-primary-500 disabled:opacity-50 disabled:cursor-not-allowed';
+
+/**
+ * Returns the message shown when a password reset request fails.
+ */
+function resetErrorMessage(status?: number): string {
+  switch (status) {
+    case 400:
+      return 'This reset link is invalid or has expired. Request a new one.';
+    case 429:
+      return 'Too many attempts, try again later.';
+    case 503:
+      return 'Password reset by email is not enabled. Contact your administrator.';
+    default:
+      return 'The password could not be reset, try again later.';
+  }
+}
+
+/**
+ * Self-service password reset.
+ * Without a token, asks for the email address to send a reset link to; with
+ * the token of that link, asks for the new password.
+ */
+export default function ResetPassword() {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get('token');
+
+  const [email, setEmail] = useState('');
+  const [password, setPassword] = useState('');
+  const [confirmation, setConfirmation] = useState('');
+  const [error, setError] = useState('');
+  const [message, setMessage] = useState('');
+  const [isLoading, setIsLoading] = useState(false);
+
+  const handleRequest = async (e: FormEvent) => {
+    e.preventDefault();
+    setError('');
+    setIsLoading(true);
+
+    try {
+      await authApi.forgotPassword(email);
+      setMessage('If an account uses this address, a password reset link has been sent to it.');
+    } catch (err: unknown) {
+      setError(resetErrorMessage((err as { response?: { status: number } })?.response?.status));
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  const handleReset = async (e: FormEvent) => {
+    e.preventDefault();
+    setError('');
+    if (password.length < 8) {
+      setError('The password must have at least 8 characters.');
+      return;
+    }
+    if (password !== confirmation) {
+      setError('The passwords do not match.');
+      return;
+    }
+    setIsLoading(true);
+
+    try {
+      await authApi.resetPassword(token ?? '', password);
+      setMessage('Your password has been reset. You can now sign in with it.');
+    } catch (err: unknown) {
+      setError(resetErrorMessage((err as { response?: { status: number } })?.response?.status));
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-gray-100 dark:bg-gray-900 py-12 px-4 sm:px-6 lg:px-8">
+      <div className="max-w-md w-full space-y-8">
+        <div>
+          <h1 className="text-center text-4xl font-bold text-primary-600 dark:text-primary-400">
+            AutoStrike
+          </h1>
+          <h2 className="mt-6 text-center text-2xl font-bold text-gray-900 dark:text-white">
+            {token ? 'Choose a new password' : 'Reset your password'}
+          </h2>
+        </div>
+
+        {error && (
+          <div className="bg-red-50 dark:bg-red-900/50 border border-red-200 dark:border-red-500 text-red-700 dark:text-red-200 px-4 py-3 rounded">
+            {error}
+          </div>
+        )}
+
+        {message ? (
+          <div className="bg-green-50 dark:bg-green-900/50 border border-green-200 dark:border-green-500 text-green-700 dark:text-green-200 px-4 py-3 rounded">
+            {message}
+          </div>
+        ) : token ? (
+          <form className="mt-8 space-y-6" onSubmit={handleReset}>
+            <div className="space-y-4">
+              <div>
+                <label htmlFor="password" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+                  New password
+                </label>
+                <input
+                  id="password"
+                  name="password"
+                  type="password"
+                  autoComplete="new-password"
+                  required
+                  value={password}
+                  onChange={(e) => setPassword(e.target.value)}
+                  className={inputClassName}
+                />
+              </div>
+              <div>
+                <label htmlFor="confirmation" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+                  Confirm password
+                </label>
+                <input
+                  id="confirmation"
+                  name="confirmation"
+                  type="password"
+                  autoComplete="new-password"
+                  required
+                  value={confirmation}
+                  onChange={(e) => setConfirmation(e.target.value)}
+                  className={inputClassName}
+                />
+              </div>
+            </div>
+            <button type="submit" disabled={isLoading} className={buttonClassName}>
+              {isLoading ? 'Resetting...' : 'Reset password'}
+            </button>
+          </form>
+        ) : (
+          <form className="mt-8 space-y-6" onSubmit={handleRequest}>
+            <div>
+              <label htmlFor="email" className="block text-sm font-medium text-gray-700 dark:text-gray-300">
+                Email address
+              </label>
+              <input
+                id="email"
+                name="email"
+                type="email"
+                autoComplete="email"
+                required
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                className={inputClassName}
+                placeholder="Enter the email address of your account"
+              />
+            </div>
+            <button type="submit" disabled={isLoading} className={buttonClassName}>
+              {isLoading ? 'Sending...' : 'Send reset link'}
+            </button>
+          </form>
+        )}
+
+        <div className="text-center text-sm">
+          <Link to="/login" className="text-primary-600 hover:text-primary-700 dark:text-primary-400">
+            Back to sign in
+          </Link>
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -20,6 +20,8 @@ import {
   ScheduleRun,
   CreateScheduleRequest,
   ScheduleFrequency,
+  MisfirePolicy,
+  ChainCondition,
 } from '../lib/api';
 import { Scenario } from '../types';
 import { LoadingState } from '../components/LoadingState';
@@ -32,6 +34,8 @@ function getRunStatusColor(status: string): string {
       return 'bg-green-500';
     case 'failed':
       return 'bg-red-500';
+    case 'missed':
+      return 'bg-gray-400';
     default:
       return 'bg-yellow-500';
   }
@@ -95,6 +99,13 @@ const frequencyLabels: Record<ScheduleFrequency, string> = {
   weekly: 'Weekly',
   monthly: 'Monthly',
   cron: 'Custom (Cron)',
+  chained: 'After another schedule',
+};
+
+const chainConditionLabels: Record<ChainCondition, string> = {
+  success: 'completes',
+  score_below: 'scores below',
+  score_at_least: 'scores at least',
 };
 
 const statusColors: Record<string, string> = {
@@ -247,11 +258,24 @@ export default function Scheduler() {
                         {schedule.frequency === 'cron' && schedule.cron_expr && (
                           <span className="text-gray-400 dark:text-gray-500 ml-1">({schedule.cron_expr})</span>
                         )}
+                        {schedule.timezone && (
+                          <span className="text-gray-400 dark:text-gray-500 ml-1">{schedule.timezone}</span>
+                        )}
+                        {schedule.depends_on && (
+                          <span className="block text-xs text-gray-400 dark:text-gray-500">
+                            when {schedules?.find((s) => s.id === schedule.depends_on)?.name || 'a deleted schedule'}{' '}
+                            {chainConditionLabels[schedule.chain_condition || 'success']}
+                            {schedule.chain_condition && schedule.chain_condition !== 'success' && ` ${schedule.chain_threshold ?? 0}`}
+                          </span>
+                        )}
                       </p>
                     </div>
                     <div>
                       <span className="text-gray-500 dark:text-gray-400">Next Run:</span>
-                      <p className="font-medium">
+                      <p
+                        className="font-medium"
+                        title={schedule.next_runs?.map((run) => formatDate(run)).join('\n')}
+                      >
                         {schedule.status === 'active'
                           ? formatRelativeTime(schedule.next_run_at)
                           : 'Paused'}
@@ -327,6 +351,7 @@ export default function Scheduler() {
       {showCreateModal && (
         <ScheduleFormModal
           scenarios={scenarios || []}
+          schedules={schedules || []}
           onClose={() => setShowCreateModal(false)}
         />
       )}
@@ -336,6 +361,7 @@ export default function Scheduler() {
         <ScheduleFormModal
           schedule={scheduleToEdit}
           scenarios={scenarios || []}
+          schedules={schedules || []}
           onClose={() => setScheduleToEdit(null)}
           onUpdate={(data) => updateMutation.mutate({ id: scheduleToEdit.id, data })}
           isUpdating={updateMutation.isPending}
@@ -434,6 +460,7 @@ function ScheduleRunsHistory({ scheduleId }: ScheduleRunsHistoryProps) {
 interface ScheduleFormModalProps {
   readonly schedule?: Schedule;
   readonly scenarios: Scenario[];
+  readonly schedules: Schedule[];
   readonly onClose: () => void;
   readonly onUpdate?: (data: CreateScheduleRequest) => void;
   readonly isUpdating?: boolean;
@@ -442,6 +469,7 @@ interface ScheduleFormModalProps {
 function ScheduleFormModal({
   schedule,
   scenarios,
+  schedules,
   onClose,
   onUpdate,
   isUpdating,
@@ -456,6 +484,11 @@ function ScheduleFormModal({
     agent_paw: schedule?.agent_paw || '',
     frequency: schedule?.frequency || 'daily',
     cron_expr: schedule?.cron_expr || '',
+    timezone: schedule?.timezone || Intl.DateTimeFormat().resolvedOptions().timeZone,
+    misfire_policy: schedule?.misfire_policy || 'run_once_immediately',
+    depends_on: schedule?.depends_on || '',
+    chain_condition: schedule?.chain_condition || 'success',
+    chain_threshold: schedule?.chain_threshold ?? 50,
     safe_mode: schedule?.safe_mode ?? true,
     start_at: '',
   });
@@ -479,6 +512,11 @@ function ScheduleFormModal({
     if (data.frequency !== 'cron') {
       delete data.cron_expr;
     }
+    if (data.frequency !== 'chained') {
+      delete data.depends_on;
+      delete data.chain_condition;
+      delete data.chain_threshold;
+    }
 
     if (isEditMode && onUpdate) {
       onUpdate(data);
@@ -584,11 +622,109 @@ function ScheduleFormModal({
                 required={formData.frequency === 'cron'}
               />
               <p className="text-xs text-gray-500 dark:text-gray-400 mt-1">
-                Format: minute hour day-of-month month day-of-week
+                Format: [second] minute hour day-of-month month day-of-week, or @daily
               </p>
             </div>
           )}
 
+          {formData.frequency === 'chained' && (
+            <div className="grid grid-cols-2 gap-3">
+              <div className="col-span-2">
+                <label htmlFor="schedule-depends-on" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                  Run After *
+                </label>
+                <select
+                  id="schedule-depends-on"
+                  value={formData.depends_on}
+                  onChange={(e) => setFormData({ ...formData, depends_on: e.target.value })}
+                  className="input"
+                  required
+                >
+                  <option value="">Select a schedule</option>
+                  {schedules
+                    .filter((s) => s.id !== schedule?.id)
+                    .map((s) => (
+                      <option key={s.id} value={s.id}>
+                        {s.name}
+                      </option>
+                    ))}
+                </select>
+              </div>
+              <div>
+                <label htmlFor="schedule-chain-condition" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                  When Its Execution
+                </label>
+                <select
+                  id="schedule-chain-condition"
+                  value={formData.chain_condition}
+                  onChange={(e) =>
+                    setFormData({ ...formData, chain_condition: e.target.value as ChainCondition })
+                  }
+                  className="input"
+                >
+                  {Object.entries(chainConditionLabels).map(([value, label]) => (
+                    <option key={value} value={value}>
+                      {label}
+                    </option>
+                  ))}
+                </select>
+              </div>
+              {formData.chain_condition !== 'success' && (
+                <div>
+                  <label htmlFor="schedule-chain-threshold" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                    Score
+                  </label>
+                  <input
+                    id="schedule-chain-threshold"
+                    type="number"
+                    min={0}
+                    max={100}
+                    value={formData.chain_threshold}
+                    onChange={(e) => setFormData({ ...formData, chain_threshold: Number(e.target.value) })}
+                    className="input"
+                  />
+                </div>
+              )}
+            </div>
+          )}
+
+          {formData.frequency !== 'once' && formData.frequency !== 'hourly' && formData.frequency !== 'chained' && (
+            <div>
+              <label htmlFor="schedule-timezone" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                Timezone
+              </label>
+              <input
+                id="schedule-timezone"
+                type="text"
+                value={formData.timezone}
+                onChange={(e) => setFormData({ ...formData, timezone: e.target.value })}
+                className="input"
+                placeholder="Europe/Paris"
+              />
+            </div>
+          )}
+
+          <div>
+            <label htmlFor="schedule-misfire-policy" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+              Missed Runs
+            </label>
+            <select
+              id="schedule-misfire-policy"
+              value={formData.misfire_policy}
+              onChange={(e) =>
+                setFormData({ ...formData, misfire_policy: e.target.value as MisfirePolicy })
+              }
+              className="input"
+            >
+              <option value="run_once_immediately">Run once at startup</option>
+              <option value="run_all_missed">Run every missed run at startup</option>
+              <option value="skip">Skip</option>
+            </select>
+            <p className="text-xs text-gray-500 dark:text-gray-400 mt-1">
+              Runs missed while the server was down
+            </p>
+          </div>
+
           {!isEditMode && (
             <div>
               <label htmlFor="schedule-start-at" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
@@ -285,26 +285,22 @@ describe('Notification Settings', () => {
     });
   });
 
-  it('shows notification channel options when enabled', async () => {
+  it('shows notification channel matrix when enabled', async () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
     renderSettings();
 
     await waitFor(() => {
-      expect(screen.getByText('Notification Channel')).toBeInTheDocument();
+      expect(screen.getByRole('columnheader', { name: 'Email' })).toBeInTheDocument();
+      expect(screen.getByRole('columnheader', { name: 'Webhook' })).toBeInTheDocument();
     });
   });
 
@@ -312,15 +308,10 @@ describe('Notification Settings', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -335,15 +326,10 @@ describe('Notification Settings', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -362,15 +348,10 @@ describe('Notification Settings', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -388,15 +369,10 @@ describe('Notification Settings', () => {
       data: {
         id: 'settings-123',
         user_id: 'user-123',
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
@@ -424,15 +400,10 @@ describe('Notification Settings', () => {
       data: {
         id: 'settings-456',
         user_id: 'user-123',
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: '',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
@@ -600,27 +571,23 @@ describe('Settings Webhook Configuration', () => {
     localStorageMock.clear();
   });
 
-  it('shows webhook channel option when notifications enabled', async () => {
+  it('shows webhook channels checked when notifications enabled', async () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'webhook',
+        preferences: { execution_completed: ['webhook'], execution_failed: ['webhook'], score_alert: ['webhook'], agent_offline: ['webhook'] },
         enabled: true,
         email_address: '',
         webhook_url: 'https://example.com/webhook',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
     renderSettings();
 
     await waitFor(() => {
-      expect(screen.getByText('Notification Channel')).toBeInTheDocument();
+      expect(screen.getByLabelText('Execution fails by Webhook')).toBeChecked();
+      expect(screen.getByLabelText('Execution fails by Email')).not.toBeChecked();
     });
   });
 });
@@ -635,15 +602,10 @@ describe('Settings Score Alert Threshold', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -665,15 +627,10 @@ describe('Settings Notification Toggle', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -688,15 +645,10 @@ describe('Settings Notification Toggle', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_started: ['email'], execution_completed: ['email'], execution_failed: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: true,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: false,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -910,23 +862,18 @@ describe('Toggle Component Interactions', () => {
 
     // Now the expanded notification options should appear
     await waitFor(() => {
-      expect(screen.getByText('Notification Channel')).toBeInTheDocument();
+      expect(screen.getByText('Notify me when:')).toBeInTheDocument();
     });
   });
 
-  it('toggles notification type toggles when clicked', async () => {
+  it('toggles notification checkboxes when clicked', async () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -936,48 +883,34 @@ describe('Toggle Component Interactions', () => {
       expect(screen.getByText('Execution starts')).toBeInTheDocument();
     });
 
-    // Find and click the "Execution starts" toggle
-    const startSection = screen.getByText('Execution starts').closest('.flex');
-    const startToggle = startSection!.querySelector('button')!;
-    fireEvent.click(startToggle);
-
-    // Find and click the "Execution completes" toggle
-    const completeSection = screen.getByText('Execution completes').closest('.flex');
-    const completeToggle = completeSection!.querySelector('button')!;
-    fireEvent.click(completeToggle);
+    const startEmail = screen.getByLabelText('Execution starts by Email');
+    const completeEmail = screen.getByLabelText('Execution completes by Email');
+    const failWebhook = screen.getByLabelText('Execution fails by Webhook');
+    expect(startEmail).not.toBeChecked();
+    expect(completeEmail).toBeChecked();
+    expect(failWebhook).not.toBeChecked();
 
-    // Find and click the "Execution fails" toggle
-    const failSection = screen.getByText('Execution fails').closest('.flex');
-    const failToggle = failSection!.querySelector('button')!;
-    fireEvent.click(failToggle);
+    fireEvent.click(startEmail);
+    fireEvent.click(completeEmail);
+    fireEvent.click(failWebhook);
 
-    // Find and click the "Agent goes offline" toggle
-    const offlineSection = screen.getByText('Agent goes offline').closest('.flex');
-    const offlineToggle = offlineSection!.querySelector('button')!;
-    fireEvent.click(offlineToggle);
-
-    // Toggles should have visually changed their state
     await waitFor(() => {
-      // "Execution starts" was false, now should be true (bg-primary-600)
-      expect(startToggle.className).toContain('bg-primary-600');
-      // "Execution completes" was true, now should be false (bg-gray-200)
-      expect(completeToggle.className).toContain('bg-gray-200');
+      expect(startEmail).toBeChecked();
+      expect(completeEmail).not.toBeChecked();
+      expect(failWebhook).toBeChecked();
+      // Other channels of the same event are left untouched
+      expect(screen.getByLabelText('Execution fails by Email')).toBeChecked();
     });
   });
 
-  it('toggles score alert and hides threshold input when disabled', async () => {
+  it('hides threshold input when score alerts have no channel', async () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -987,58 +920,39 @@ describe('Toggle Component Interactions', () => {
       expect(screen.getByText('Security score below threshold')).toBeInTheDocument();
     });
 
-    // The score threshold input should be visible when notify_on_score_alert is true
-    const thresholdInput = screen.getByDisplayValue('70');
-    expect(thresholdInput).toBeInTheDocument();
+    // The score threshold input is visible while score alerts use a channel
+    expect(screen.getByLabelText('Score alert threshold')).toHaveValue(70);
 
-    // Find and click the score alert toggle to disable it
-    const scoreSection = screen.getByText('Security score below threshold').closest('.flex');
-    const scoreToggle = scoreSection!.querySelector('button')!;
-    fireEvent.click(scoreToggle);
+    fireEvent.click(screen.getByLabelText('Security score below threshold by Email'));
 
-    // After disabling, the threshold input should disappear
     await waitFor(() => {
-      expect(screen.queryByDisplayValue('70')).not.toBeInTheDocument();
+      expect(screen.queryByLabelText('Score alert threshold')).not.toBeInTheDocument();
     });
   });
 });
 
-describe('Channel Switching', () => {
+describe('Channel Selection', () => {
   beforeEach(() => {
     vi.clearAllMocks();
     localStorageMock.clear();
   });
 
-  it('switches from email to webhook channel and shows webhook URL input', async () => {
+  it('shows both email and webhook inputs', async () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
     renderSettings();
 
     await waitFor(() => {
-      expect(screen.getByText('Email Address')).toBeInTheDocument();
-    });
-
-    // Switch to webhook
-    const channelSelect = screen.getByLabelText('Notification Channel');
-    fireEvent.change(channelSelect, { target: { value: 'webhook' } });
-
-    // Email address should disappear, webhook URL should appear
-    await waitFor(() => {
-      expect(screen.queryByText('Email Address')).not.toBeInTheDocument();
-      expect(screen.getByText('Webhook URL')).toBeInTheDocument();
+      expect(screen.getByLabelText('Email Address')).toHaveValue('test@example.com');
+      expect(screen.getByLabelText('Webhook URL')).toHaveValue('');
     });
   });
 
@@ -1046,42 +960,74 @@ describe('Channel Switching', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'webhook',
+        preferences: { execution_completed: ['webhook'], execution_failed: ['webhook'], score_alert: ['webhook'], agent_offline: ['webhook'] },
         enabled: true,
         email_address: '',
         webhook_url: 'https://hooks.example.com/notify',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
     renderSettings();
 
     await waitFor(() => {
-      expect(screen.getByText('Webhook URL')).toBeInTheDocument();
       const webhookInput = screen.getByLabelText('Webhook URL');
       expect(webhookInput).toHaveValue('https://hooks.example.com/notify');
     });
   });
 
+  it('shows Teams webhook URL and channel with existing Teams settings', async () => {
+    const { notificationApi } = await import('../lib/api');
+    vi.mocked(notificationApi.getSettings).mockResolvedValue({
+      data: {
+        preferences: { execution_failed: ['teams'], score_alert: ['teams'] },
+        enabled: true,
+        teams_webhook_url: 'https://contoso.webhook.office.com/webhookb2/abc',
+        score_alert_threshold: 70,
+      },
+    } as never);
+
+    renderSettings();
+
+    await waitFor(() => {
+      expect(screen.getByLabelText('Teams Webhook URL')).toHaveValue('https://contoso.webhook.office.com/webhookb2/abc');
+    });
+    expect(screen.getByLabelText('Execution fails by Teams')).toBeChecked();
+    expect(screen.getByLabelText('Execution completes by Teams')).not.toBeChecked();
+  });
+
+  it('updates the webhook signing secret on input change', async () => {
+    const { notificationApi } = await import('../lib/api');
+    vi.mocked(notificationApi.getSettings).mockResolvedValue({
+      data: {
+        preferences: { execution_failed: ['webhook'] },
+        enabled: true,
+        webhook_url: 'https://hooks.example.com/notify',
+        webhook_secret: '0123456789abcdef',
+        score_alert_threshold: 70,
+      },
+    } as never);
+
+    renderSettings();
+
+    await waitFor(() => {
+      expect(screen.getByLabelText('Webhook Signing Secret')).toHaveValue('0123456789abcdef');
+    });
+
+    const secretInput = screen.getByLabelText('Webhook Signing Secret');
+    fireEvent.change(secretInput, { target: { value: 'another-long-secret' } });
+    expect(secretInput).toHaveValue('another-long-secret');
+  });
+
   it('updates webhook URL on input change', async () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'webhook',
+        preferences: { execution_completed: ['webhook'], execution_failed: ['webhook'], score_alert: ['webhook'], agent_offline: ['webhook'] },
         enabled: true,
         email_address: '',
         webhook_url: '',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -1101,15 +1047,10 @@ describe('Channel Switching', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: '',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -1125,36 +1066,35 @@ describe('Channel Switching', () => {
     expect(emailInput).toHaveValue('new@example.com');
   });
 
-  it('switches from webhook back to email channel', async () => {
+  it('selects webhook delivery for failures alongside email', async () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'webhook',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
-        email_address: '',
-        webhook_url: 'https://hooks.example.com/notify',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
+        email_address: 'test@example.com',
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
+    vi.mocked(notificationApi.updateSettings).mockResolvedValue({ data: {} } as never);
 
     renderSettings();
 
     await waitFor(() => {
-      expect(screen.getByText('Webhook URL')).toBeInTheDocument();
+      expect(screen.getByLabelText('Execution fails by Webhook')).toBeInTheDocument();
     });
 
-    // Switch to email
-    const channelSelect = screen.getByLabelText('Notification Channel');
-    fireEvent.change(channelSelect, { target: { value: 'email' } });
+    fireEvent.click(screen.getByLabelText('Execution fails by Webhook'));
+    fireEvent.change(screen.getByLabelText('Webhook URL'), { target: { value: 'https://hooks.example.com/notify' } });
+    fireEvent.click(screen.getByText('Save Notification Settings'));
 
     await waitFor(() => {
-      expect(screen.queryByText('Webhook URL')).not.toBeInTheDocument();
-      expect(screen.getByText('Email Address')).toBeInTheDocument();
+      expect(notificationApi.updateSettings).toHaveBeenCalledWith(
+        expect.objectContaining({
+          webhook_url: 'https://hooks.example.com/notify',
+          preferences: expect.objectContaining({ execution_failed: ['email', 'webhook'] }),
+        })
+      );
     });
   });
 });
@@ -1169,15 +1109,10 @@ describe('Number Input Validation', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -1197,15 +1132,10 @@ describe('Number Input Validation', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
 
@@ -1273,15 +1203,10 @@ describe('SMTP Warning and Display', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
     vi.mocked(notificationApi.getSMTPConfig).mockRejectedValue({ response: { status: 404 } });
@@ -1299,15 +1224,10 @@ describe('SMTP Warning and Display', () => {
     const { notificationApi } = await import('../lib/api');
     vi.mocked(notificationApi.getSettings).mockResolvedValue({
       data: {
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
       },
     } as never);
     vi.mocked(notificationApi.getSMTPConfig).mockResolvedValue({
@@ -1411,15 +1331,10 @@ describe('Create Notification Settings (New Settings)', () => {
       data: {
         id: 'existing-settings-id',
         user_id: 'user-1',
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
@@ -1438,7 +1353,7 @@ describe('Create Notification Settings (New Settings)', () => {
       expect(notificationApi.updateSettings).toHaveBeenCalledWith(
         'existing-settings-id',
         expect.objectContaining({
-          channel: 'email',
+          preferences: expect.objectContaining({ execution_failed: ['email'] }),
           enabled: true,
           email_address: 'test@example.com',
         })
@@ -1460,16 +1375,11 @@ describe('Notification Settings Data Mapping', () => {
       data: {
         id: 'ns-1',
         user_id: 'u-1',
-        channel: 'webhook',
+        preferences: { execution_started: ['webhook'], execution_failed: ['webhook'] },
         enabled: true,
         email_address: '',
         webhook_url: 'https://hooks.slack.com/test',
-        notify_on_start: true,
-        notify_on_complete: false,
-        notify_on_failure: true,
-        notify_on_score_alert: false,
         score_alert_threshold: 50,
-        notify_on_agent_offline: false,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
@@ -1478,28 +1388,17 @@ describe('Notification Settings Data Mapping', () => {
     renderSettings();
 
     await waitFor(() => {
-      // Webhook channel should be selected
-      const channelSelect = screen.getByLabelText('Notification Channel');
-      expect(channelSelect).toHaveValue('webhook');
-
       // Webhook URL should be populated
       const webhookInput = screen.getByLabelText('Webhook URL');
       expect(webhookInput).toHaveValue('https://hooks.slack.com/test');
     });
 
-    // Check toggle states: notify_on_start is true, notify_on_complete is false
-    const startSection = screen.getByText('Execution starts').closest('.flex');
-    const startToggle = startSection!.querySelector('button')!;
-    expect(startToggle.className).toContain('bg-primary-600');
-
-    const completeSection = screen.getByText('Execution completes').closest('.flex');
-    const completeToggle = completeSection!.querySelector('button')!;
-    expect(completeToggle.className).toContain('bg-gray-200');
-
-    // notify_on_agent_offline is false
-    const offlineSection = screen.getByText('Agent goes offline').closest('.flex');
-    const offlineToggle = offlineSection!.querySelector('button')!;
-    expect(offlineToggle.className).toContain('bg-gray-200');
+    // Check the matrix: starts and failures by webhook, nothing else
+    expect(screen.getByLabelText('Execution starts by Webhook')).toBeChecked();
+    expect(screen.getByLabelText('Execution fails by Webhook')).toBeChecked();
+    expect(screen.getByLabelText('Execution starts by Email')).not.toBeChecked();
+    expect(screen.getByLabelText('Execution completes by Webhook')).not.toBeChecked();
+    expect(screen.getByLabelText('Agent goes offline by Webhook')).not.toBeChecked();
   });
 
   it('handles null email_address and webhook_url in fetched data', async () => {
@@ -1508,16 +1407,11 @@ describe('Notification Settings Data Mapping', () => {
       data: {
         id: 'ns-2',
         user_id: 'u-2',
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: null,
         webhook_url: null,
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
@@ -1662,15 +1556,10 @@ describe('Pending/Loading States for Mutations', () => {
       data: {
         id: 'settings-pending',
         user_id: 'user-1',
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
@@ -1731,15 +1620,10 @@ describe('Pending/Loading States for Mutations', () => {
       data: {
         id: 'settings-disable',
         user_id: 'user-1',
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
@@ -1813,15 +1697,10 @@ describe('Toggle Disabled State', () => {
       data: {
         id: 'settings-1',
         user_id: 'user-1',
-        channel: 'email',
+        preferences: { execution_completed: ['email'], execution_failed: ['email'], score_alert: ['email'], agent_offline: ['email'] },
         enabled: true,
         email_address: 'test@example.com',
-        notify_on_start: false,
-        notify_on_complete: true,
-        notify_on_failure: true,
-        notify_on_score_alert: true,
         score_alert_threshold: 70,
-        notify_on_agent_offline: true,
         created_at: '2026-01-01T00:00:00Z',
         updated_at: '2026-01-01T00:00:00Z',
       },
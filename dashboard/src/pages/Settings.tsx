@@ -5,6 +5,7 @@ import {
   notificationApi,
   NotificationSettingsRequest,
   NotificationChannel,
+  NotificationType,
 } from '../lib/api';
 import { BellIcon, EnvelopeIcon, ExclamationTriangleIcon } from '@heroicons/react/24/outline';
 
@@ -35,18 +36,42 @@ const defaultSettings: LocalSettings = {
 };
 
 const defaultNotificationSettings: NotificationSettingsRequest = {
-  channel: 'email',
   enabled: false,
   email_address: '',
   webhook_url: '',
-  notify_on_start: false,
-  notify_on_complete: true,
-  notify_on_failure: true,
-  notify_on_score_alert: true,
+  teams_webhook_url: '',
+  webhook_secret: '',
+  pagerduty_routing_key: '',
+  opsgenie_api_key: '',
+  preferences: {
+    execution_completed: ['email'],
+    execution_failed: ['email'],
+    score_alert: ['email'],
+    agent_offline: ['email'],
+  },
   score_alert_threshold: 70,
-  notify_on_agent_offline: true,
 };
 
+// Rows and columns of the notification preference matrix
+const NOTIFICATION_EVENTS: { type: NotificationType; label: string }[] = [
+  { type: 'execution_started', label: 'Execution starts' },
+  { type: 'execution_completed', label: 'Execution completes' },
+  { type: 'execution_failed', label: 'Execution fails' },
+  { type: 'agent_offline', label: 'Agent goes offline' },
+  { type: 'score_alert', label: 'Security score below threshold' },
+  { type: 'security_alert', label: 'Security alert (admins)' },
+  { type: 'detection_regression', label: 'Detection regression' },
+  { type: 'critical_undetected', label: 'Critical technique undetected' },
+];
+
+const NOTIFICATION_CHANNELS: { channel: NotificationChannel; label: string }[] = [
+  { channel: 'email', label: 'Email' },
+  { channel: 'webhook', label: 'Webhook' },
+  { channel: 'teams', label: 'Teams' },
+  { channel: 'pagerduty', label: 'PagerDuty' },
+  { channel: 'opsgenie', label: 'Opsgenie' },
+];
+
 // Toggle component defined outside of Settings to avoid recreation on render
 function Toggle({
   enabled,
@@ -129,16 +154,15 @@ export default function Settings() {
     if (notificationSettingsData) {
       setHasExistingNotifSettings(true);
       setNotifSettings({
-        channel: notificationSettingsData.channel,
         enabled: notificationSettingsData.enabled,
         email_address: notificationSettingsData.email_address || '',
         webhook_url: notificationSettingsData.webhook_url || '',
-        notify_on_start: notificationSettingsData.notify_on_start,
-        notify_on_complete: notificationSettingsData.notify_on_complete,
-        notify_on_failure: notificationSettingsData.notify_on_failure,
-        notify_on_score_alert: notificationSettingsData.notify_on_score_alert,
+        teams_webhook_url: notificationSettingsData.teams_webhook_url || '',
+        webhook_secret: notificationSettingsData.webhook_secret || '',
+        pagerduty_routing_key: notificationSettingsData.pagerduty_routing_key || '',
+        opsgenie_api_key: notificationSettingsData.opsgenie_api_key || '',
+        preferences: notificationSettingsData.preferences || {},
         score_alert_threshold: notificationSettingsData.score_alert_threshold,
-        notify_on_agent_offline: notificationSettingsData.notify_on_agent_offline,
       });
     }
   }, [notificationSettingsData]);
@@ -201,6 +225,22 @@ export default function Settings() {
     setNotifSettings(prev => ({ ...prev, [key]: value }));
   };
 
+  const hasChannel = (type: NotificationType, channel: NotificationChannel) =>
+    notifSettings.preferences[type]?.includes(channel) ?? false;
+
+  const usesChannel = (channel: NotificationChannel) =>
+    NOTIFICATION_EVENTS.some(({ type }) => hasChannel(type, channel));
+
+  const toggleChannel = (type: NotificationType, channel: NotificationChannel) => {
+    setNotifSettings(prev => {
+      const channels = prev.preferences[type] ?? [];
+      const next = channels.includes(channel)
+        ? channels.filter(c => c !== channel)
+        : [...channels, channel];
+      return { ...prev, preferences: { ...prev.preferences, [type]: next } };
+    });
+  };
+
   return (
     <div>
       <h1 className="text-3xl font-bold mb-8">Settings</h1>
@@ -233,142 +273,164 @@ export default function Settings() {
 
               {notifSettings.enabled && (
                 <>
-                  {/* Channel Selection */}
+                  {/* Email Settings */}
                   <div>
-                    <label htmlFor="notif-channel" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
-                      Notification Channel
+                    <label htmlFor="notif-email" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                      Email Address
                     </label>
-                    <select
-                      id="notif-channel"
+                    <input
+                      id="notif-email"
+                      type="email"
                       className="input"
-                      value={notifSettings.channel}
-                      onChange={(e) =>
-                        updateNotifSetting('channel', e.target.value as NotificationChannel)
-                      }
-                    >
-                      <option value="email">Email</option>
-                      <option value="webhook">Webhook</option>
-                    </select>
+                      placeholder="your@email.com"
+                      value={notifSettings.email_address || ''}
+                      onChange={(e) => updateNotifSetting('email_address', e.target.value)}
+                    />
+                    {usesChannel('email') && !smtpConfig && (
+                      <p className="mt-1 text-sm text-amber-600 dark:text-amber-400 flex items-center gap-1">
+                        <ExclamationTriangleIcon className="h-4 w-4" />
+                        SMTP not configured on server. Email notifications may not work.
+                      </p>
+                    )}
                   </div>
 
-                  {/* Email Settings */}
-                  {notifSettings.channel === 'email' && (
+                  {/* Webhook Settings */}
+                  <div>
+                    <label htmlFor="notif-webhook" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                      Webhook URL
+                    </label>
+                    <input
+                      id="notif-webhook"
+                      type="url"
+                      className="input"
+                      placeholder="https://your-webhook-url.com/notify"
+                      value={notifSettings.webhook_url || ''}
+                      onChange={(e) => updateNotifSetting('webhook_url', e.target.value)}
+                    />
+                  </div>
+
+                  <div>
+                    <label htmlFor="notif-webhook-secret" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                      Webhook Signing Secret
+                    </label>
+                    <input
+                      id="notif-webhook-secret"
+                      type="password"
+                      className="input"
+                      autoComplete="off"
+                      placeholder="At least 16 characters, leave empty for unsigned webhooks"
+                      value={notifSettings.webhook_secret || ''}
+                      onChange={(e) => updateNotifSetting('webhook_secret', e.target.value)}
+                    />
+                    <p className="mt-1 text-sm text-gray-500 dark:text-gray-400">
+                      Requests carry an X-AutoStrike-Signature HMAC-SHA256 header computed with this secret.
+                    </p>
+                  </div>
+
+                  {/* Microsoft Teams Settings */}
+                  <div>
+                    <label htmlFor="notif-teams" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                      Teams Webhook URL
+                    </label>
+                    <input
+                      id="notif-teams"
+                      type="url"
+                      className="input"
+                      placeholder="https://your-tenant.webhook.office.com/webhookb2/..."
+                      value={notifSettings.teams_webhook_url || ''}
+                      onChange={(e) => updateNotifSetting('teams_webhook_url', e.target.value)}
+                    />
+                  </div>
+
+                  {/* Incident channels */}
+                  <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
                     <div>
-                      <label htmlFor="notif-email" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
-                        Email Address
+                      <label htmlFor="notif-pagerduty" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                        PagerDuty Routing Key
                       </label>
                       <input
-                        id="notif-email"
-                        type="email"
+                        id="notif-pagerduty"
+                        type="password"
                         className="input"
-                        placeholder="your@email.com"
-                        value={notifSettings.email_address || ''}
-                        onChange={(e) => updateNotifSetting('email_address', e.target.value)}
+                        placeholder="Events API v2 integration key"
+                        value={notifSettings.pagerduty_routing_key || ''}
+                        onChange={(e) => updateNotifSetting('pagerduty_routing_key', e.target.value)}
                       />
-                      {!smtpConfig && (
-                        <p className="mt-1 text-sm text-amber-600 dark:text-amber-400 flex items-center gap-1">
-                          <ExclamationTriangleIcon className="h-4 w-4" />
-                          SMTP not configured on server. Email notifications may not work.
-                        </p>
-                      )}
                     </div>
-                  )}
-
-                  {/* Webhook Settings */}
-                  {notifSettings.channel === 'webhook' && (
                     <div>
-                      <label htmlFor="notif-webhook" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
-                        Webhook URL
+                      <label htmlFor="notif-opsgenie" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1">
+                        Opsgenie API Key
                       </label>
                       <input
-                        id="notif-webhook"
-                        type="url"
+                        id="notif-opsgenie"
+                        type="password"
                         className="input"
-                        placeholder="https://your-webhook-url.com/notify"
-                        value={notifSettings.webhook_url || ''}
-                        onChange={(e) => updateNotifSetting('webhook_url', e.target.value)}
+                        placeholder="API integration key"
+                        value={notifSettings.opsgenie_api_key || ''}
+                        onChange={(e) => updateNotifSetting('opsgenie_api_key', e.target.value)}
                       />
                     </div>
-                  )}
+                  </div>
 
-                  {/* Notification Types */}
+                  {/* Event x channel matrix */}
                   <div className="border-t border-gray-200 dark:border-gray-700 pt-4 mt-4">
                     <p className="font-medium mb-3">Notify me when:</p>
-                    <div className="space-y-3">
-                      <div className="flex items-center justify-between">
-                        <span className="text-sm text-gray-700 dark:text-gray-300">Execution starts</span>
-                        <Toggle
-                          enabled={notifSettings.notify_on_start}
-                          onChange={() =>
-                            updateNotifSetting('notify_on_start', !notifSettings.notify_on_start)
-                          }
-                        />
-                      </div>
-                      <div className="flex items-center justify-between">
-                        <span className="text-sm text-gray-700 dark:text-gray-300">Execution completes</span>
-                        <Toggle
-                          enabled={notifSettings.notify_on_complete}
-                          onChange={() =>
-                            updateNotifSetting('notify_on_complete', !notifSettings.notify_on_complete)
-                          }
-                        />
-                      </div>
-                      <div className="flex items-center justify-between">
-                        <span className="text-sm text-gray-700 dark:text-gray-300">Execution fails</span>
-                        <Toggle
-                          enabled={notifSettings.notify_on_failure}
-                          onChange={() =>
-                            updateNotifSetting('notify_on_failure', !notifSettings.notify_on_failure)
-                          }
-                        />
-                      </div>
-                      <div className="flex items-center justify-between">
-                        <span className="text-sm text-gray-700 dark:text-gray-300">Agent goes offline</span>
-                        <Toggle
-                          enabled={notifSettings.notify_on_agent_offline}
-                          onChange={() =>
-                            updateNotifSetting(
-                              'notify_on_agent_offline',
-                              !notifSettings.notify_on_agent_offline
-                            )
-                          }
-                        />
-                      </div>
-                      <div className="flex items-center justify-between">
-                        <div className="flex-1">
-                          <span className="text-sm text-gray-700 dark:text-gray-300">
-                            Security score below threshold
-                          </span>
-                          {notifSettings.notify_on_score_alert && (
-                            <div className="mt-1">
-                              <input
-                                type="number"
-                                className="input w-24"
-                                min="0"
-                                max="100"
-                                value={notifSettings.score_alert_threshold}
-                                onChange={(e) =>
-                                  updateNotifSetting(
-                                    'score_alert_threshold',
-                                    Number.parseInt(e.target.value, 10) || 0
-                                  )
-                                }
-                              />
-                              <span className="text-sm text-gray-500 dark:text-gray-400 ml-2">%</span>
-                            </div>
-                          )}
-                        </div>
-                        <Toggle
-                          enabled={notifSettings.notify_on_score_alert}
-                          onChange={() =>
-                            updateNotifSetting(
-                              'notify_on_score_alert',
-                              !notifSettings.notify_on_score_alert
-                            )
-                          }
-                        />
-                      </div>
-                    </div>
+                    <table className="w-full">
+                      <thead>
+                        <tr>
+                          <th className="text-left text-xs font-medium text-gray-500 dark:text-gray-400 uppercase tracking-wider pb-2">
+                            Event
+                          </th>
+                          {NOTIFICATION_CHANNELS.map(({ channel, label }) => (
+                            <th
+                              key={channel}
+                              className="w-24 text-center text-xs font-medium text-gray-500 dark:text-gray-400 uppercase tracking-wider pb-2"
+                            >
+                              {label}
+                            </th>
+                          ))}
+                        </tr>
+                      </thead>
+                      <tbody className="divide-y divide-gray-200 dark:divide-gray-700">
+                        {NOTIFICATION_EVENTS.map(({ type, label }) => (
+                          <tr key={type}>
+                            <td className="py-2">
+                              <span className="text-sm text-gray-700 dark:text-gray-300">{label}</span>
+                              {type === 'score_alert' && notifSettings.preferences.score_alert?.length ? (
+                                <div className="mt-1">
+                                  <input
+                                    type="number"
+                                    className="input w-24"
+                                    min="0"
+                                    max="100"
+                                    aria-label="Score alert threshold"
+                                    value={notifSettings.score_alert_threshold}
+                                    onChange={(e) =>
+                                      updateNotifSetting(
+                                        'score_alert_threshold',
+                                        Number.parseInt(e.target.value, 10) || 0
+                                      )
+                                    }
+                                  />
+                                  <span className="text-sm text-gray-500 dark:text-gray-400 ml-2">%</span>
+                                </div>
+                              ) : null}
+                            </td>
+                            {NOTIFICATION_CHANNELS.map(({ channel, label: channelLabel }) => (
+                              <td key={channel} className="py-2 text-center">
+                                <input
+                                  type="checkbox"
+                                  checked={hasChannel(type, channel)}
+                                  onChange={() => toggleChannel(type, channel)}
+                                  className="rounded text-primary-600 focus:ring-primary-500"
+                                  aria-label={`${label} by ${channelLabel}`}
+                                />
+                              </td>
+                            ))}
+                          </tr>
+                        ))}
+                      </tbody>
+                    </table>
                   </div>
 
                   <div className="flex justify-end pt-4">
@@ -2,7 +2,8 @@ import { describe, it, expect, vi, beforeEach } from 'vitest';
 import { render, screen, fireEvent, waitFor } from '@testing-library/react';
 import { QueryClient, QueryClientProvider } from '@tanstack/react-query';
 import Techniques from './Techniques';
-import { api, techniqueApi } from '../lib/api';
+import { api, adminApi, techniqueApi } from '../lib/api';
+import { useAuth } from '../contexts/AuthContext';
 import toast from 'react-hot-toast';
 
 // Mock the API
@@ -10,11 +11,20 @@ vi.mock('../lib/api', () => ({
   api: {
     get: vi.fn(),
   },
+  adminApi: {
+    syncMitreTechniques: vi.fn(),
+    getTechniqueSyncJob: vi.fn(),
+  },
   techniqueApi: {
     import: vi.fn(),
   },
 }));
 
+// Mock the auth context
+vi.mock('../contexts/AuthContext', () => ({
+  useAuth: vi.fn(),
+}));
+
 // Mock react-hot-toast
 vi.mock('react-hot-toast', () => ({
   default: {
@@ -42,6 +52,10 @@ function renderWithClient(ui: React.ReactElement) {
 describe('Techniques Page', () => {
   beforeEach(() => {
     vi.clearAllMocks();
+    vi.mocked(useAuth).mockReturnValue({
+      user: { id: 'admin-1', username: 'admin', role: 'admin' },
+      authEnabled: true,
+    } as never);
   });
 
   it('renders loading state', () => {
@@ -554,4 +568,73 @@ describe('Techniques Import Modal', () => {
     fireEvent.click(screen.getByText('Import Techniques'));
     expect(screen.getByText(/Upload a JSON file/)).toBeInTheDocument();
   });
+
+  it('syncs techniques from MITRE content for admins', async () => {
+    vi.mocked(api.get).mockResolvedValue({ data: [] } as never);
+    vi.mocked(adminApi.syncMitreTechniques).mockResolvedValue({
+      data: { id: 'sync-1', status: 'running' },
+    } as never);
+    vi.mocked(adminApi.getTechniqueSyncJob).mockResolvedValue({
+      data: { id: 'sync-1', status: 'completed', added: 2, updated: 1, deprecated: 0, drafts: 1, failed: 0 },
+    } as never);
+
+    renderWithClient(<Techniques />);
+
+    fireEvent.click(await screen.findByText('Sync from MITRE'));
+
+    await waitFor(() => {
+      expect(adminApi.getTechniqueSyncJob).toHaveBeenCalledWith('sync-1');
+    });
+    await waitFor(() => {
+      expect(toast.success).toHaveBeenCalledWith('MITRE sync completed: 2 added, 1 updated, 0 deprecated, 1 drafts');
+    });
+  });
+
+  it('reports a MITRE sync of unchanged upstream content', async () => {
+    vi.mocked(api.get).mockResolvedValue({ data: [] } as never);
+    vi.mocked(adminApi.syncMitreTechniques).mockResolvedValue({
+      data: { id: 'sync-1', status: 'running' },
+    } as never);
+    vi.mocked(adminApi.getTechniqueSyncJob).mockResolvedValue({
+      data: { id: 'sync-1', status: 'completed', unchanged: true, added: 0, updated: 0, deprecated: 0, drafts: 0, failed: 0 },
+    } as never);
+
+    renderWithClient(<Techniques />);
+
+    fireEvent.click(await screen.findByText('Sync from MITRE'));
+
+    await waitFor(() => {
+      expect(toast.success).toHaveBeenCalledWith(
+        'MITRE sync completed: ATT&CK and Atomic Red Team are unchanged since the last sync'
+      );
+    });
+  });
+
+  it('reports a failed MITRE sync', async () => {
+    vi.mocked(api.get).mockResolvedValue({ data: [] } as never);
+    vi.mocked(adminApi.syncMitreTechniques).mockRejectedValue({
+      response: { data: { error: 'a technique sync is already running' } },
+    });
+
+    renderWithClient(<Techniques />);
+
+    fireEvent.click(await screen.findByText('Sync from MITRE'));
+
+    await waitFor(() => {
+      expect(toast.error).toHaveBeenCalledWith('a technique sync is already running');
+    });
+  });
+
+  it('hides the MITRE sync from non-admins', async () => {
+    vi.mocked(useAuth).mockReturnValue({
+      user: { id: 'viewer-1', username: 'viewer', role: 'viewer' },
+      authEnabled: true,
+    } as never);
+    vi.mocked(api.get).mockResolvedValue({ data: [] } as never);
+
+    renderWithClient(<Techniques />);
+
+    await screen.findByText('Import Techniques');
+    expect(screen.queryByText('Sync from MITRE')).not.toBeInTheDocument();
+  });
 });
@@ -1,12 +1,14 @@
-import { useState, useRef } from 'react';
+import { useState, useRef, useEffect } from 'react';
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
 import {
   ShieldExclamationIcon,
   ArrowUpTrayIcon,
+  ArrowPathIcon,
   CheckCircleIcon,
   ExclamationTriangleIcon,
 } from '@heroicons/react/24/outline';
-import { api, techniqueApi, Technique as TechniqueType } from '../lib/api';
+import { api, adminApi, techniqueApi, Technique as TechniqueType, TechniqueSyncJob } from '../lib/api';
+import { useAuth } from '../contexts/AuthContext';
 import { getTacticBadgeColor, formatTacticName } from '../lib/tacticColors';
 import { Technique } from '../types';
 import { LoadingState } from '../components/LoadingState';
@@ -64,6 +66,48 @@ export default function Techniques() {
     queryFn: () => api.get('/techniques').then(res => res.data),
   });
 
+  const { user, authEnabled } = useAuth();
+  const canSync = !authEnabled || user?.role === 'admin';
+  const [syncJobId, setSyncJobId] = useState<string | null>(null);
+
+  const { data: syncJob } = useQuery<TechniqueSyncJob>({
+    queryKey: ['technique-sync', syncJobId],
+    queryFn: () => adminApi.getTechniqueSyncJob(syncJobId!).then(res => res.data),
+    enabled: !!syncJobId,
+    refetchInterval: ({ state }) => (state.data?.status === 'running' ? 2000 : false),
+  });
+
+  useEffect(() => {
+    if (!syncJob || syncJob.status === 'running') return;
+    setSyncJobId(null);
+    queryClient.invalidateQueries({ queryKey: ['techniques'] });
+    if (syncJob.status === 'failed') {
+      toast.error(syncJob.error || 'MITRE sync failed');
+      return;
+    }
+    if (syncJob.unchanged) {
+      toast.success('MITRE sync completed: ATT&CK and Atomic Red Team are unchanged since the last sync');
+      return;
+    }
+    const summary = `${syncJob.added} added, ${syncJob.updated} updated, ${syncJob.deprecated} deprecated, ${syncJob.drafts} drafts`;
+    if (syncJob.failed > 0) {
+      toast.error(`MITRE sync finished with ${syncJob.failed} failure(s): ${summary}`);
+    } else {
+      toast.success(`MITRE sync completed: ${summary}`);
+    }
+  }, [syncJob, queryClient]);
+
+  const syncMutation = useMutation({
+    mutationFn: () => adminApi.syncMitreTechniques(),
+    onSuccess: (response) => {
+      setSyncJobId(response.data.id);
+      toast.success('MITRE sync started');
+    },
+    onError: (error: { response?: { data?: { error?: string } } }) => {
+      toast.error(error.response?.data?.error || 'Failed to start MITRE sync');
+    },
+  });
+
   const importMutation = useMutation({
     mutationFn: (techniques: TechniqueType[]) => techniqueApi.import(techniques),
     onSuccess: (response) => {
@@ -135,10 +179,22 @@ export default function Techniques() {
     <div>
       <div className="flex justify-between items-center mb-8">
         <h1 className="text-3xl font-bold text-gray-900 dark:text-gray-100">Techniques</h1>
-        <button onClick={handleImportClick} className="btn-primary flex items-center gap-2">
-          <ArrowUpTrayIcon className="h-5 w-5" />
-          Import Techniques
-        </button>
+        <div className="flex items-center gap-3">
+          {canSync && (
+            <button
+              onClick={() => syncMutation.mutate()}
+              disabled={syncMutation.isPending || !!syncJobId}
+              className="btn-secondary flex items-center gap-2"
+            >
+              <ArrowPathIcon className={`h-5 w-5${syncJobId ? ' animate-spin' : ''}`} />
+              {syncJobId ? 'Syncing...' : 'Sync from MITRE'}
+            </button>
+          )}
+          <button onClick={handleImportClick} className="btn-primary flex items-center gap-2">
+            <ArrowUpTrayIcon className="h-5 w-5" />
+            Import Techniques
+          </button>
+        </div>
       </div>
 
       {/* Hidden file input for import */}
@@ -28,10 +28,28 @@ export interface Agent {
   last_seen: string;
   /** ISO timestamp of agent registration */
   created_at?: string;
+  /** Agent build version, reported on registration and heartbeat */
+  version?: string;
+  /** Operating system version */
+  os_version?: string;
+  /** CPU architecture (x86_64, aarch64, etc.) */
+  architecture?: string;
+  /** Whether the agent runs as root or an administrator */
+  elevated?: boolean;
+  /** Directory domain the host is joined to */
+  domain?: string;
+  /** Security products detected on the host */
+  security_products?: string[];
+  /** Script interpreters found on the host, name to version */
+  interpreters?: Record<string, string>;
+  /** Source address of the agent's last connection */
+  ip_address?: string;
+  /** Networks (CIDR) the agent is expected to connect from, any when empty */
+  allowed_networks?: string[];
 }
 
 /**
- * MITRE ATT&CK tactic types.
+ * MITRE ATT&CK tactic types, the ATT&CK for ICS ones last.
  */
 export type TacticType =
   | 'reconnaissance'
@@ -47,7 +65,10 @@ export type TacticType =
   | 'collection'
   | 'command_and_control'
   | 'exfiltration'
-  | 'impact';
+  | 'impact'
+  | 'evasion'
+  | 'inhibit_response_function'
+  | 'impair_process_control';
 
 /**
  * Represents a MITRE ATT&CK technique.
@@ -59,8 +80,12 @@ export interface Technique {
   name: string;
   /** Detailed description of the technique */
   description: string;
-  /** MITRE tactic this technique belongs to */
+  /** Primary MITRE tactic of this technique */
   tactic: TacticType;
+  /** Every MITRE tactic this technique belongs to, the primary one first */
+  tactics?: TacticType[];
+  /** Organization custom fields (owner team, risk rating, related ticket...) */
+  metadata?: Record<string, string>;
   /** Supported operating system platforms */
   platforms: string[];
   /** Whether the technique is safe for production testing */
@@ -69,6 +94,14 @@ export interface Technique {
   executors?: TechniqueExecutor[];
   /** Detection indicators for this technique */
   detection?: DetectionIndicator[];
+  /** Parent technique ID of a sub-technique (T1059 for T1059.001) */
+  parent_id?: string;
+  /** ATT&CK domain the technique belongs to */
+  domain?: 'enterprise-attack' | 'ics-attack' | 'mobile-attack';
+  /** Severity, from the catalog or estimated from the tactics */
+  severity?: 'low' | 'medium' | 'high' | 'critical';
+  /** CVSS-like impact, from 0.1 to 10 */
+  impact?: number;
 }
 
 /**
@@ -83,6 +116,84 @@ export interface TechniqueExecutor {
   cleanup?: string;
   /** Execution timeout in seconds */
   timeout: number;
+  /** Facts to extract from the output, referenced by later phases as #{fact.<name>} */
+  parsers?: FactParser[];
+  /** Only runs on agents running as root or an administrator */
+  elevation_required?: boolean;
+  /** Name of a payload the agent downloads before the command, available as #{payload.path} */
+  payload?: string;
+  /** Files the agent uploads as result artifacts once the command has run */
+  collect?: string[];
+  /** Command and cleanup replacing the executor's on a platform (linux, darwin, windows) */
+  variants?: Record<string, CommandVariant>;
+}
+
+/**
+ * Platform-specific command of a technique executor.
+ */
+export interface CommandVariant {
+  /** Command run on the platform */
+  command: string;
+  /** Cleanup run on the platform */
+  cleanup?: string;
+}
+
+/**
+ * A file stored on the server that technique commands download.
+ */
+export interface Payload {
+  /** Unique payload ID */
+  id: string;
+  /** Name executors reference the payload with */
+  name: string;
+  /** Payload description */
+  description?: string;
+  /** Hex SHA-256 of the content */
+  sha256: string;
+  /** Size in bytes */
+  size: number;
+  /** ID of the user who uploaded it */
+  uploaded_by?: string;
+  /** Upload timestamp */
+  created_at: string;
+}
+
+/**
+ * A file an agent collected while running a technique.
+ */
+export interface Artifact {
+  /** Unique artifact ID */
+  id: string;
+  /** Result the file was collected for */
+  result_id: string;
+  /** Execution of the result */
+  execution_id: string;
+  /** Agent that uploaded it */
+  agent_paw: string;
+  /** File name */
+  name: string;
+  /** Path the agent read the file from */
+  path?: string;
+  /** Content type detected from the content */
+  content_type?: string;
+  /** Hex SHA-256 of the content */
+  sha256: string;
+  /** Size in bytes */
+  size: number;
+  /** Upload timestamp */
+  created_at: string;
+}
+
+/**
+ * Extracts a fact from a technique's output, with a regex or a JSON path.
+ */
+export interface FactParser {
+  /** Fact name */
+  fact: string;
+  /** Regex whose first capture group (or whole match) is the value */
+  regex?: string;
+  /** JSON path of the value, e.g. $.addresses[0].ip */
+  json_path?: string;
 }
 
 /**
@@ -98,7 +209,7 @@ export interface DetectionIndicator {
 /**
  * Execution status enumeration.
  */
-export type ExecutionStatus = 'pending' | 'running' | 'completed' | 'failed' | 'cancelled';
+export type ExecutionStatus = 'pending' | 'running' | 'completed' | 'failed' | 'cancelled' | 'interrupted';
 
 /**
  * Security score breakdown from an execution.
@@ -116,6 +227,25 @@ export interface ExecutionScore {
   total: number;
 }
 
+/**
+ * Progress of an execution in steps, a technique run on an agent.
+ */
+export interface ExecutionProgress {
+  total: number;
+  /** Finished steps, failed and skipped ones included */
+  completed: number;
+  failed: number;
+  skipped: number;
+  /** Completed out of total, 0 to 100 */
+  percent: number;
+  /** Earliest phase with unfinished steps */
+  current_phase?: string;
+  /** Estimated seconds left, from the median durations of past runs */
+  remaining_seconds?: number;
+  /** ISO timestamp of the estimated completion */
+  eta?: string;
+}
+
 /**
  * Represents a scenario execution.
  */
@@ -126,6 +256,8 @@ export interface Execution {
   scenario_id: string;
   /** Current execution status */
   status: ExecutionStatus;
+  /** Steps done, current phase and ETA */
+  progress?: ExecutionProgress;
   /** ISO timestamp when execution started */
   started_at: string;
   /** ISO timestamp when execution completed */
@@ -134,6 +266,26 @@ export interface Execution {
   safe_mode: boolean;
   /** Security score results */
   score?: ExecutionScore;
+  /** Scoring profile the execution is scored with, unset for the fixed formula */
+  scoring_profile_id?: string;
+  /** Version of the scoring profile pinned when the execution started */
+  scoring_profile_version?: number;
+  /** Why a failed execution failed, e.g. stuck running */
+  failure_reason?: string;
+  /** Counts of the results by status, set on listings */
+  result_summary?: ExecutionResultSummary;
+  /** Results, set when listed with include=results */
+  results?: ExecutionResult[];
+}
+
+/**
+ * Counts of the results of an execution, retries included.
+ */
+export interface ExecutionResultSummary {
+  /** Results of the execution */
+  total: number;
+  /** Results by status */
+  by_status: Record<string, number>;
 }
 
 /**
@@ -144,6 +296,22 @@ export interface ScenarioPhase {
   name: string;
   /** List of technique IDs to execute in this phase */
   techniques: string[];
+  /** Conditions on earlier results, all required for the phase to run on an agent */
+  run_if?: PhaseCondition[];
+  /** Executor types tried first, in order, e.g. psh then cmd */
+  executors?: string[];
+}
+
+/**
+ * Condition of a conditional phase on the result of an earlier technique.
+ */
+export interface PhaseCondition {
+  /** Technique of an earlier phase */
+  technique: string;
+  /** Restricts the condition to the technique's results in this phase */
+  phase?: string;
+  /** Required result status (defaults to success) */
+  status?: string;
 }
 
 /**
@@ -160,6 +328,10 @@ export interface Scenario {
   phases: ScenarioPhase[];
   /** Tags for categorization */
   tags: string[];
+  /** Built-in template: read-only, clone it to customize */
+  is_template?: boolean;
+  /** Scenario this one was cloned from */
+  cloned_from?: string;
 }
 
 /**
@@ -174,10 +346,31 @@ export interface ExecutionResult {
   technique_id: string;
   /** Agent that executed the technique */
   agent_paw: string;
+  /** Run of the technique on the agent within the execution, from 1 */
+  attempt?: number;
+  /** Scenario phase the technique was planned in */
+  phase?: string;
+  /** Executor type the technique was planned with */
+  executor?: string;
   /** Result status */
-  status: 'blocked' | 'detected' | 'successful' | 'failed' | 'skipped';
-  /** Command output */
+  status:
+    | 'blocked'
+    | 'detected'
+    | 'successful'
+    | 'failed'
+    | 'skipped'
+    | 'skipped_insufficient_privilege'
+    | 'timeout'
+    | 'limit_exceeded'
+    | 'queued';
+  /** Command output, only its beginning when output_ref is set */
   output: string;
+  /** Object storage key of the full output, when it was too large for the database */
+  output_ref?: string;
+  /** Size in bytes of the full output, when output only holds its beginning */
+  output_size?: number;
+  /** Whether the output was cut to the server maximum output size */
+  output_truncated?: boolean;
   /** Whether the technique was detected */
   detected: boolean;
   /** ISO timestamp when execution started */
@@ -186,6 +379,28 @@ export interface ExecutionResult {
   end_time: string;
 }
 
+/**
+ * Fact extracted from a technique's output during an execution.
+ */
+export interface Fact {
+  /** Unique fact identifier */
+  id: string;
+  /** Parent execution ID */
+  execution_id: string;
+  /** Agent whose output held the fact */
+  agent_paw: string;
+  /** Result the fact was extracted from */
+  result_id: string;
+  /** Technique whose parser extracted the fact */
+  technique_id: string;
+  /** Fact name */
+  name: string;
+  /** Fact value */
+  value: string;
+  /** ISO timestamp when the fact was recorded */
+  created_at: string;
+}
+
 /**
  * Coverage statistics by tactic.
  */
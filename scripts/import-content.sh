@@ -0,0 +1,78 @@
+#!/bin/bash
+set -e
+
+# AutoStrike Content Importer
+# Converts Prelude Operator, Stratus Red Team, CTID emulation plan, Caldera,
+# ATT&CK STIX or Atomic Red Team content into techniques and scenarios
+
+QUERY=""
+while [[ "$1" == --* ]]; do
+    case "$1" in
+        --incremental) QUERY="$QUERY&incremental=true" ;;
+        --deprecate-removed) QUERY="$QUERY&deprecate_removed=true" ;;
+        --platform) QUERY="$QUERY&platform=$2"; shift ;;
+        --domain) QUERY="$QUERY&domain=$2"; shift ;;
+        *) echo "Unknown option: $1"; exit 1 ;;
+    esac
+    shift
+done
+
+FORMAT="$1"
+FILE="$2"
+SERVER_URL="${AUTOSTRIKE_URL:-https://localhost:8443}"
+
+if [ -z "$FORMAT" ] || [ -z "$FILE" ]; then
+    echo "Usage: $0 [--incremental] [--deprecate-removed] [--platform <windows|linux|macos>]... [--domain <enterprise-attack|ics-attack|mobile-attack>]... <prelude|stratus|ctid|caldera|stix|atomic> <file|directory>"
+    echo ""
+    echo "  prelude  YAML stream of Operator TTPs and chains (documents separated by ---)"
+    echo "  stratus  YAML or JSON list of Stratus Red Team techniques"
+    echo "  ctid     MITRE CTID adversary emulation plan YAML (e.g. FIN6.yaml, menuPass.yaml)"
+    echo "  caldera  Caldera abilities and adversaries (e.g. a stockpile data/ directory)"
+    echo "  stix     ATT&CK STIX 2.1 bundle of a domain (e.g. ics-attack.json of attack-stix-data)"
+    echo "  atomic   Atomic Red Team index (atomics/Indexes/index.yaml)"
+    echo ""
+    echo "The YAML files of a directory are sent as one stream (documents separated by ---)."
+    echo ""
+    echo "  --incremental        only write the techniques and scenarios that changed"
+    echo "  --deprecate-removed  deprecate the techniques of the format the content no longer has"
+    echo "  --platform           only import the techniques of this ATT&CK platform matrix (repeatable)"
+    echo "  --domain             only import the techniques of this ATT&CK domain (repeatable)"
+    echo ""
+    echo "Environment: AUTOSTRIKE_URL (default $SERVER_URL), AUTOSTRIKE_TOKEN (access token)"
+    exit 1
+fi
+
+if [ -d "$FILE" ]; then
+    BUNDLE=$(mktemp)
+    trap 'rm -f "$BUNDLE"' EXIT
+    find "$FILE" -type f \( -name '*.yml' -o -name '*.yaml' \) | sort | while read -r f; do
+        echo "---" >> "$BUNDLE"
+        cat "$f" >> "$BUNDLE"
+        echo "" >> "$BUNDLE"
+    done
+    if [ ! -s "$BUNDLE" ]; then
+        echo "No YAML files found in: $FILE"
+        exit 1
+    fi
+    DATA="$BUNDLE"
+elif [ -f "$FILE" ]; then
+    DATA="$FILE"
+else
+    echo "File not found: $FILE"
+    exit 1
+fi
+
+echo "=== AutoStrike Content Importer ==="
+echo ""
+echo "Importing $FORMAT content from $FILE..."
+
+AUTH_HEADER=()
+if [ -n "$AUTOSTRIKE_TOKEN" ]; then
+    AUTH_HEADER=(-H "Authorization: Bearer $AUTOSTRIKE_TOKEN")
+fi
+
+curl -sSk -X POST "$SERVER_URL/api/v1/content/import/$FORMAT?${QUERY#&}" \
+    "${AUTH_HEADER[@]}" \
+    -H "Content-Type: text/plain" \
+    --data-binary "@$DATA"
+echo ""
@@ -0,0 +1,580 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/persistence/sqlite"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Config is the configuration of the server, read by loadConfig from
+// config.yaml and the environment. A setting is read from the variable named
+// after its key, uppercased with underscores (smtp.host from SMTP_HOST), or
+// from the variables of its env tag; the environment overrides config.yaml.
+// Squashed groups add no key prefix. Settings left at zero use the default of
+// their service, except those defaultConfig sets.
+type Config struct {
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	Checks    ChecksConfig    `mapstructure:"config"`
+	Content   ContentConfig   `mapstructure:"content"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	Login     LoginConfig     `mapstructure:"login"`
+	Agent     AgentConfig     `mapstructure:"agent"`
+	Secrets   SecretsConfig   `mapstructure:"secrets"`
+	Vault     VaultConfig     `mapstructure:"vault"`
+	AWS       AWSConfig       `mapstructure:"aws"`
+	S3        S3Config        `mapstructure:"s3"`
+	Storage   StorageConfig   `mapstructure:",squash"`
+	Execution ExecutionConfig `mapstructure:"execution"`
+	Catalog   CatalogConfig   `mapstructure:",squash"`
+	Notify    NotifyConfig    `mapstructure:",squash"`
+	Stream    StreamConfig    `mapstructure:"stream"`
+	Detection DetectionConfig `mapstructure:",squash"`
+	Ticketing TicketingConfig `mapstructure:",squash"`
+	Activity  ActivityConfig  `mapstructure:"activity"`
+
+	// Invalid lists the settings whose value did not parse, or was negative,
+	// and were left at their default
+	Invalid []string `mapstructure:"-"`
+}
+
+// ServerConfig holds the listen address and the access settings of the REST server
+type ServerConfig struct {
+	Address              string        `mapstructure:"address"`
+	DashboardPath        string        `mapstructure:"dashboard_path" env:"DASHBOARD_PATH"`
+	DashboardURL         string        `mapstructure:"dashboard_url" env:"DASHBOARD_URL"`
+	TrustedProxies       []string      `mapstructure:"trusted_proxies" env:"TRUSTED_PROXIES"`
+	APIAllowedNetworks   []string      `mapstructure:"api_allowed_networks" env:"API_ALLOWED_NETWORKS"`
+	ShutdownDrainTimeout time.Duration `mapstructure:"shutdown_drain_timeout" env:"SHUTDOWN_DRAIN_TIMEOUT"`
+}
+
+// DatabaseConfig holds the connection pool and the pragmas of the database
+type DatabaseConfig struct {
+	Path            string        `mapstructure:"path"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	BusyTimeout     time.Duration `mapstructure:"busy_timeout"`
+	JournalMode     string        `mapstructure:"journal_mode"`
+	Synchronous     string        `mapstructure:"synchronous"`
+}
+
+// SecurityConfig holds the TLS termination of the server
+type SecurityConfig struct {
+	TLS TLSConfig `mapstructure:"tls"`
+}
+
+// TLSConfig holds the certificate files, or the ACME account, TLS is terminated with
+type TLSConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	ACME     struct {
+		Domains      []string `mapstructure:"domains"`
+		Email        string   `mapstructure:"email"`
+		CacheDir     string   `mapstructure:"cache_dir"`
+		DirectoryURL string   `mapstructure:"directory_url"`
+	} `mapstructure:"acme"`
+}
+
+// ChecksConfig holds how the configuration checks run at startup
+type ChecksConfig struct {
+	FailFast bool `mapstructure:"fail_fast"`
+}
+
+// ContentConfig holds how the content of ./configs is applied
+type ContentConfig struct {
+	AutoApply bool `mapstructure:"auto_apply"`
+	Watch     bool `mapstructure:"watch"`
+}
+
+// AuthConfig holds the authentication of users. Enabled is nil when
+// ENABLE_AUTH is not set: authentication is then enabled with a JWT secret.
+type AuthConfig struct {
+	Enabled              *bool         `mapstructure:"enabled" env:"ENABLE_AUTH"`
+	JWTSecret            string        `mapstructure:"jwt_secret" env:"JWT_SECRET"`
+	DefaultAdminPassword string        `mapstructure:"default_admin_password" env:"DEFAULT_ADMIN_PASSWORD"`
+	PasswordResetTTL     time.Duration `mapstructure:"password_reset_ttl" env:"PASSWORD_RESET_TTL"`
+	DeepLinkTTL          time.Duration `mapstructure:"deep_link_ttl" env:"DEEP_LINK_TTL"`
+}
+
+// LoginConfig holds the failed login limits
+type LoginConfig struct {
+	MaxFailures   int           `mapstructure:"max_failures"`
+	MaxIPFailures int           `mapstructure:"max_ip_failures"`
+	FailureWindow time.Duration `mapstructure:"failure_window"`
+	Lockout       time.Duration `mapstructure:"lockout"`
+	MaxLockout    time.Duration `mapstructure:"max_lockout"`
+}
+
+// AgentConfig holds the authentication, check-ins, availability and updates of agents
+type AgentConfig struct {
+	Secret          string        `mapstructure:"secret"`
+	AllowedNetworks []string      `mapstructure:"allowed_networks"`
+	BeaconInterval  int           `mapstructure:"beacon_interval"`
+	BeaconJitter    int           `mapstructure:"beacon_jitter"`
+	StaleTimeout    time.Duration `mapstructure:"stale_timeout"`
+	OfflineGrace    time.Duration `mapstructure:"offline_grace"`
+	FlapWindow      time.Duration `mapstructure:"flap_window"`
+	FlapThreshold   int           `mapstructure:"flap_threshold"`
+	UpdatePublicKey string        `mapstructure:"update_public_key"`
+	ReleaseMaxSize  int64         `mapstructure:"release_max_size"`
+}
+
+// SecretsConfig holds the master key of the secrets store
+type SecretsConfig struct {
+	MasterKey   string `mapstructure:"master_key"`
+	KMSKeyID    string `mapstructure:"kms_key_id"`
+	KMSEndpoint string `mapstructure:"kms_endpoint" env:"KMS_ENDPOINT"`
+}
+
+// VaultConfig holds the Vault server the runtime secrets are read from.
+// Secrets maps environment variables to their Vault secret.
+type VaultConfig struct {
+	Address         string            `mapstructure:"address" env:"VAULT_ADDRESS,VAULT_ADDR"`
+	Namespace       string            `mapstructure:"namespace"`
+	Token           string            `mapstructure:"token"`
+	RoleID          string            `mapstructure:"role_id"`
+	SecretID        string            `mapstructure:"secret_id"`
+	AuthMount       string            `mapstructure:"auth_mount"`
+	Secrets         map[string]string `mapstructure:"secrets"`
+	RefreshInterval time.Duration     `mapstructure:"refresh_interval"`
+}
+
+// AWSConfig holds the region and credentials shared by KMS and the S3 buckets
+type AWSConfig struct {
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+}
+
+// S3Config holds the endpoint shared by the S3 buckets
+type S3Config struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	PathStyle bool   `mapstructure:"path_style"`
+}
+
+// StorageConfig holds the limits and the stores of payloads, result outputs,
+// artifacts and evidence, and the execution retention
+type StorageConfig struct {
+	Payload struct {
+		URLTTL      time.Duration `mapstructure:"url_ttl"`
+		MaxSize     int64         `mapstructure:"max_size"`
+		ScanCommand string        `mapstructure:"scan_command"`
+	} `mapstructure:"payload"`
+	Artifact struct {
+		MaxSize     int64         `mapstructure:"max_size"`
+		ResultQuota int64         `mapstructure:"result_quota"`
+		Retention   time.Duration `mapstructure:"retention"`
+	} `mapstructure:"artifact"`
+	Evidence struct {
+		MaxSize     int64    `mapstructure:"max_size"`
+		ResultQuota int64    `mapstructure:"result_quota"`
+		Types       []string `mapstructure:"types"`
+	} `mapstructure:"evidence"`
+	Output struct {
+		MaxSize       int `mapstructure:"max_size"`
+		BlobThreshold int `mapstructure:"blob_threshold"`
+		PreviewSize   int `mapstructure:"preview_size"`
+	} `mapstructure:"output"`
+	BlobStore struct {
+		Dir      string `mapstructure:"dir"`
+		S3Bucket string `mapstructure:"s3_bucket"`
+		S3Prefix string `mapstructure:"s3_prefix"`
+	} `mapstructure:"blob_store"`
+	Retention struct {
+		Outputs         time.Duration `mapstructure:"outputs" env:"RESULT_OUTPUT_RETENTION"`
+		Executions      time.Duration `mapstructure:"executions" env:"EXECUTION_RETENTION"`
+		RunHour         int           `mapstructure:"run_hour"`
+		ArchiveDir      string        `mapstructure:"archive_dir"`
+		ArchiveS3Bucket string        `mapstructure:"archive_s3_bucket"`
+		ArchiveS3Prefix string        `mapstructure:"archive_s3_prefix"`
+	} `mapstructure:"retention"`
+}
+
+// ExecutionConfig holds the queues, limits and recovery of executions
+type ExecutionConfig struct {
+	StaleTimeout     time.Duration `mapstructure:"stale_timeout"`
+	ResumeOnStart    bool          `mapstructure:"resume_on_start" env:"RESUME_INTERRUPTED_EXECUTIONS"`
+	ResumeAgentGrace time.Duration `mapstructure:"resume_agent_grace" env:"RESUME_AGENT_GRACE"`
+	TaskQueueTTL     time.Duration `mapstructure:"task_queue_ttl" env:"TASK_QUEUE_TTL"`
+	ResultQueueSize  int           `mapstructure:"result_queue_size" env:"RESULT_QUEUE_SIZE"`
+	ResultQueueBatch int           `mapstructure:"result_queue_batch" env:"RESULT_QUEUE_BATCH"`
+	JobWorkers       int           `mapstructure:"job_workers" env:"JOB_WORKERS"`
+	Quota            struct {
+		PerUser      int           `mapstructure:"per_user"`
+		PerAPIKey    int           `mapstructure:"per_api_key"`
+		PerWorkspace int           `mapstructure:"per_workspace"`
+		Total        int           `mapstructure:"total"`
+		Window       time.Duration `mapstructure:"window"`
+	} `mapstructure:"quota"`
+	EmergencyStop struct {
+		DBCheckInterval time.Duration `mapstructure:"db_check_interval" env:"EMERGENCY_STOP_DB_CHECK_INTERVAL"`
+		DBFailures      int           `mapstructure:"db_failures" env:"EMERGENCY_STOP_DB_FAILURES"`
+	} `mapstructure:"emergency_stop"`
+}
+
+// CatalogConfig holds the trash, the sync and the bundles of the technique
+// and scenario catalog, and the sharing of its caches
+type CatalogConfig struct {
+	TrashRetention time.Duration `mapstructure:"trash_retention" env:"TRASH_RETENTION"`
+	MITRESync      struct {
+		STIXURL    string   `mapstructure:"stix_url"`
+		AtomicsURL string   `mapstructure:"atomics_url"`
+		Domains    []string `mapstructure:"domains"`
+	} `mapstructure:"mitre_sync"`
+	BundleSigningKey string `mapstructure:"bundle_signing_key" env:"BUNDLE_SIGNING_KEY"`
+	Cache            struct {
+		RedisURL     string `mapstructure:"redis_url"`
+		RedisChannel string `mapstructure:"redis_channel"`
+	} `mapstructure:"catalog_cache"`
+}
+
+// NotifyConfig holds the email, incident alerting, webhook and syslog
+// deliveries of notifications and events
+type NotifyConfig struct {
+	SMTP struct {
+		Host     string `mapstructure:"host"`
+		Port     int    `mapstructure:"port"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+		From     string `mapstructure:"from"`
+		UseTLS   bool   `mapstructure:"use_tls"`
+	} `mapstructure:"smtp"`
+	Notifications struct {
+		Retention          time.Duration `mapstructure:"retention" env:"NOTIFICATION_RETENTION"`
+		WebhookVerbosity   string        `mapstructure:"webhook_verbosity" env:"WEBHOOK_VERBOSITY"`
+		PagerDutyEventsURL string        `mapstructure:"pagerduty_events_url" env:"PAGERDUTY_EVENTS_URL"`
+		OpsgenieAPIURL     string        `mapstructure:"opsgenie_api_url" env:"OPSGENIE_API_URL"`
+	} `mapstructure:"notifications"`
+	EventWebhook struct {
+		URL    string   `mapstructure:"url"`
+		Events []string `mapstructure:"events"`
+		Secret string   `mapstructure:"secret"`
+	} `mapstructure:"event_webhook"`
+	Syslog struct {
+		Addr     string `mapstructure:"addr"`
+		Protocol string `mapstructure:"protocol"`
+		Format   string `mapstructure:"format"`
+	} `mapstructure:"syslog"`
+}
+
+// StreamConfig holds the Kafka or NATS event stream
+type StreamConfig struct {
+	Driver        string        `mapstructure:"driver"`
+	TopicPrefix   string        `mapstructure:"topic_prefix"`
+	Events        []string      `mapstructure:"events"`
+	BatchSize     int           `mapstructure:"batch_size"`
+	BufferSize    int           `mapstructure:"buffer_size"`
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	Kafka         struct {
+		RESTURL  string `mapstructure:"rest_url"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"kafka"`
+	NATS struct {
+		URL      string `mapstructure:"url"`
+		Token    string `mapstructure:"token"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"nats"`
+}
+
+// DetectionConfig holds the SIEM and EDR connectors detections are verified with
+type DetectionConfig struct {
+	Splunk struct {
+		URL    string `mapstructure:"url"`
+		Token  string `mapstructure:"token"`
+		Search string `mapstructure:"search"`
+	} `mapstructure:"splunk"`
+	Elastic struct {
+		URL         string `mapstructure:"url"`
+		APIKey      string `mapstructure:"api_key"`
+		AlertsIndex string `mapstructure:"alerts_index"`
+	} `mapstructure:"elastic"`
+	Sentinel struct {
+		WorkspaceID  string `mapstructure:"workspace_id"`
+		TenantID     string `mapstructure:"tenant_id"`
+		ClientID     string `mapstructure:"client_id"`
+		ClientSecret string `mapstructure:"client_secret"`
+	} `mapstructure:"sentinel"`
+	CrowdStrike struct {
+		URL          string `mapstructure:"url"`
+		ClientID     string `mapstructure:"client_id"`
+		ClientSecret string `mapstructure:"client_secret"`
+	} `mapstructure:"crowdstrike"`
+	Defender struct {
+		TenantID     string `mapstructure:"tenant_id"`
+		ClientID     string `mapstructure:"client_id"`
+		ClientSecret string `mapstructure:"client_secret"`
+	} `mapstructure:"defender"`
+	SentinelOne struct {
+		URL      string `mapstructure:"url"`
+		APIToken string `mapstructure:"api_token"`
+	} `mapstructure:"sentinelone"`
+	SIEM struct {
+		QueryDelay  time.Duration `mapstructure:"query_delay"`
+		QueryWindow time.Duration `mapstructure:"query_window"`
+	} `mapstructure:"siem"`
+}
+
+// TicketingConfig holds the Jira or ServiceNow tracker issues are opened in.
+// Ticket.Delay is nil when TICKET_DELAY is not set.
+type TicketingConfig struct {
+	Jira struct {
+		URL               string `mapstructure:"url"`
+		Email             string `mapstructure:"email"`
+		APIToken          string `mapstructure:"api_token"`
+		Project           string `mapstructure:"project"`
+		IssueType         string `mapstructure:"issue_type"`
+		ResolveTransition string `mapstructure:"resolve_transition"`
+	} `mapstructure:"jira"`
+	ServiceNow struct {
+		URL             string `mapstructure:"url"`
+		Username        string `mapstructure:"username"`
+		Password        string `mapstructure:"password"`
+		AssignmentGroup string `mapstructure:"assignment_group"`
+		CloseCode       string `mapstructure:"close_code"`
+	} `mapstructure:"servicenow"`
+	Ticket struct {
+		Delay                   *time.Duration `mapstructure:"delay"`
+		SyncInterval            time.Duration  `mapstructure:"sync_interval"`
+		Labels                  []string       `mapstructure:"labels"`
+		SummaryTemplateFile     string         `mapstructure:"summary_template_file"`
+		DescriptionTemplateFile string         `mapstructure:"description_template_file"`
+	} `mapstructure:"ticket"`
+}
+
+// ActivityConfig holds the operator activity anomaly detection
+type ActivityConfig struct {
+	BusinessHours       string        `mapstructure:"business_hours"`
+	Timezone            string        `mapstructure:"timezone"`
+	MassDeleteThreshold int           `mapstructure:"mass_delete_threshold"`
+	MassDeleteWindow    time.Duration `mapstructure:"mass_delete_window"`
+	CountryHeader       string        `mapstructure:"country_header" env:"GEOIP_COUNTRY_HEADER"`
+}
+
+// defaultConfig returns the defaults of the settings whose zero value has a
+// meaning, e.g. TASK_QUEUE_TTL=0 disables the task queue
+func defaultConfig() *Config {
+	config := &Config{}
+	config.Server.Address = ":8443"
+	config.Server.DashboardPath = "../dashboard/dist"
+	config.Server.ShutdownDrainTimeout = 30 * time.Second
+	config.Database = DatabaseConfig{
+		Path:         "./data/autostrike.db",
+		MaxOpenConns: sqlite.DefaultMaxOpenConns,
+		MaxIdleConns: sqlite.DefaultMaxIdleConns,
+		BusyTimeout:  sqlite.DefaultBusyTimeout,
+		JournalMode:  sqlite.DefaultJournalMode,
+		Synchronous:  sqlite.DefaultSynchronous,
+	}
+	config.Security.TLS.ACME.CacheDir = "./data/acme"
+	config.Content = ContentConfig{AutoApply: true, Watch: true}
+
+	lockout := application.DefaultLoginLockoutConfig()
+	config.Login.MaxFailures = lockout.MaxUserFailures
+	config.Login.MaxIPFailures = lockout.MaxIPFailures
+
+	availability := application.DefaultAgentAvailabilityConfig()
+	config.Agent.BeaconInterval = 30
+	config.Agent.StaleTimeout = 2 * time.Minute
+	config.Agent.OfflineGrace = availability.OfflineGrace
+	config.Agent.FlapWindow = availability.FlapWindow
+	config.Agent.FlapThreshold = availability.FlapThreshold
+
+	config.Storage.Retention.RunHour = application.DefaultRetentionConfig().RunHour
+	config.Execution.StaleTimeout = application.DefaultStaleExecutionTimeout
+	config.Execution.TaskQueueTTL = application.DefaultTaskQueueTTL
+	config.Execution.ResultQueueSize = application.DefaultResultQueueSize
+	config.Execution.ResultQueueBatch = application.DefaultResultQueueBatch
+	config.Execution.EmergencyStop.DBCheckInterval = application.DefaultEmergencyStopCheckInterval
+	config.Catalog.TrashRetention = application.DefaultTrashRetention
+	config.Notify.SMTP.Port = 587
+	config.Notify.Notifications.Retention = application.DefaultNotificationRetention
+	config.Detection.SIEM.QueryDelay = application.DefaultDetectionConfig().Delay
+	config.Activity.MassDeleteThreshold = application.DefaultActivityMonitorConfig().MassDeletionThreshold
+	return config
+}
+
+// configSetting is a setting of Config: its key, the environment variables
+// bound to it and the field holding its value
+type configSetting struct {
+	key   string
+	envs  []string
+	field reflect.Value
+}
+
+// env returns the first environment variable the setting is read from
+func (s configSetting) env() string {
+	if len(s.envs) > 0 {
+		return s.envs[0]
+	}
+	return strings.ToUpper(strings.ReplaceAll(s.key, ".", "_"))
+}
+
+// settings lists the settings of the config, by walking its fields
+func (c *Config) settings() []configSetting {
+	return structSettings(reflect.ValueOf(c).Elem(), "")
+}
+
+func structSettings(v reflect.Value, prefix string) []configSetting {
+	var settings []configSetting
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, option, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		key := prefix
+		if name != "" && prefix != "" {
+			key = prefix + "." + name
+		} else if name != "" {
+			key = name
+		}
+		if field.Type.Kind() == reflect.Struct && (name != "" || option == "squash") {
+			settings = append(settings, structSettings(v.Field(i), key)...)
+			continue
+		}
+		var envs []string
+		if env := field.Tag.Get("env"); env != "" {
+			envs = strings.Split(env, ",")
+		}
+		settings = append(settings, configSetting{key: key, envs: envs, field: v.Field(i)})
+	}
+	return settings
+}
+
+// loadConfig reads config.yaml, from ./config, ./configs or the working
+// directory, and the environment. Lists are comma-separated in the
+// environment, and maps JSON objects. A value that does not parse as its
+// setting, or is negative, is ignored: the setting keeps its default and is
+// listed in Config.Invalid, as validate-config reports. The config is returned
+// with the error of a config file that could not be read.
+func loadConfig() (*Config, error) {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("./config")
+	viper.AddConfigPath("./configs")
+	viper.AddConfigPath(".")
+
+	// Nested keys are read from the environment with underscores, e.g. DATABASE_PATH
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	config := defaultConfig()
+	settings := config.settings()
+	for _, setting := range settings {
+		_ = viper.BindEnv(append([]string{setting.key}, setting.envs...)...)
+	}
+
+	err := viper.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		err = nil
+	}
+
+	for _, setting := range settings {
+		if !viper.IsSet(setting.key) {
+			continue
+		}
+		switch setting.field.Interface().(type) {
+		case map[string]string:
+			setting.field.Set(reflect.ValueOf(viper.GetStringMapString(setting.key)))
+			continue
+		case []string:
+			setting.field.Set(reflect.ValueOf(splitList(viper.GetStringSlice(setting.key))))
+			continue
+		}
+		value := reflect.New(setting.field.Type())
+		if decodeErr := viper.UnmarshalKey(setting.key, value.Interface()); decodeErr != nil || negative(value.Elem()) {
+			config.Invalid = append(config.Invalid,
+				fmt.Sprintf("%s=%q (%s)", setting.env(), viper.GetString(setting.key), setting.key))
+			continue
+		}
+		setting.field.Set(value.Elem())
+	}
+	return config, err
+}
+
+// splitList splits the comma-separated entries of a list, skipping empty ones
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// negative reports a negative number or duration, no setting taking one
+func negative(v reflect.Value) bool {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int64:
+		return v.Int() < 0
+	}
+	return false
+}
+
+// setEnv sets the setting read from the environment variable name, reporting
+// false when no setting is
+func (c *Config) setEnv(name, value string) bool {
+	found := false
+	for _, setting := range c.settings() {
+		if setting.env() != name && !slices.Contains(setting.envs, name) {
+			continue
+		}
+		if setting.field.Kind() != reflect.String {
+			// Vault holds secrets, not numbers or durations
+			continue
+		}
+		setting.field.SetString(value)
+		found = true
+	}
+	return found
+}
+
+// resolveSecretRefs replaces the settings set to secret://<name> with the
+// value of their secret. The values stay in the config: neither the
+// environment, inherited by the commands the server runs, nor viper ever hold
+// them. The server does not start with a reference it cannot resolve.
+func resolveSecretRefs(config *Config, secretService *application.SecretService, logger *zap.Logger) {
+	ctx := context.Background()
+	for _, setting := range config.settings() {
+		if setting.field.Kind() != reflect.String {
+			continue
+		}
+		value := setting.field.String()
+		if _, ok := entity.SecretRef(value); !ok {
+			continue
+		}
+		if secretService == nil {
+			logger.Fatal("Secret reference without secrets store, set SECRETS_MASTER_KEY or SECRETS_KMS_KEY_ID",
+				zap.String("setting", setting.key))
+		}
+		resolved, err := secretService.Resolve(ctx, value)
+		if err != nil {
+			logger.Fatal("Failed to resolve secret reference", zap.String("setting", setting.key), zap.Error(err))
+		}
+		setting.field.SetString(resolved)
+		logger.Info("Secret reference resolved", zap.String("setting", setting.key))
+	}
+}
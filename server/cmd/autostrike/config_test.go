@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if config.Server.Address != ":8443" {
+		t.Errorf("Server.Address = %q, want :8443", config.Server.Address)
+	}
+	if config.Execution.TaskQueueTTL != application.DefaultTaskQueueTTL {
+		t.Errorf("Execution.TaskQueueTTL = %v, want %v", config.Execution.TaskQueueTTL, application.DefaultTaskQueueTTL)
+	}
+	if !config.Content.AutoApply {
+		t.Error("Content.AutoApply = false, want true")
+	}
+	if config.Auth.Enabled != nil {
+		t.Errorf("Auth.Enabled = %v, want nil", *config.Auth.Enabled)
+	}
+	if config.Ticketing.Ticket.Delay != nil {
+		t.Errorf("Ticketing.Ticket.Delay = %v, want nil", *config.Ticketing.Ticket.Delay)
+	}
+	if len(config.Invalid) != 0 {
+		t.Errorf("Invalid = %v, want none", config.Invalid)
+	}
+}
+
+func TestLoadConfig_Environment(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "2525")
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	t.Setenv("ENABLE_AUTH", "false")
+	t.Setenv("TASK_QUEUE_TTL", "0")
+	t.Setenv("EXECUTION_QUOTA_PER_USER", "10")
+	t.Setenv("RESULT_OUTPUT_RETENTION", "720h")
+	t.Setenv("TICKET_DELAY", "0s")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1, 10.0.0.2,")
+	t.Setenv("VAULT_ADDR", "https://vault.example.com")
+	t.Setenv("VAULT_SECRETS", `{"jwt_secret":"kv/autostrike#jwt"}`)
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if config.Notify.SMTP.Host != "smtp.example.com" || config.Notify.SMTP.Port != 2525 {
+		t.Errorf("SMTP = %s:%d, want smtp.example.com:2525", config.Notify.SMTP.Host, config.Notify.SMTP.Port)
+	}
+	if config.Auth.JWTSecret != "jwt-secret" {
+		t.Errorf("Auth.JWTSecret = %q, want jwt-secret", config.Auth.JWTSecret)
+	}
+	if config.Auth.Enabled == nil || *config.Auth.Enabled {
+		t.Error("Auth.Enabled should be false")
+	}
+	if config.Execution.TaskQueueTTL != 0 {
+		t.Errorf("Execution.TaskQueueTTL = %v, want 0", config.Execution.TaskQueueTTL)
+	}
+	if config.Execution.Quota.PerUser != 10 {
+		t.Errorf("Execution.Quota.PerUser = %d, want 10", config.Execution.Quota.PerUser)
+	}
+	if config.Storage.Retention.Outputs != 720*time.Hour {
+		t.Errorf("Storage.Retention.Outputs = %v, want 720h", config.Storage.Retention.Outputs)
+	}
+	if config.Ticketing.Ticket.Delay == nil || *config.Ticketing.Ticket.Delay != 0 {
+		t.Error("Ticketing.Ticket.Delay should be set to 0")
+	}
+	if len(config.Server.TrustedProxies) != 2 || config.Server.TrustedProxies[1] != "10.0.0.2" {
+		t.Errorf("Server.TrustedProxies = %q, want [10.0.0.1 10.0.0.2]", config.Server.TrustedProxies)
+	}
+	if config.Vault.Address != "https://vault.example.com" {
+		t.Errorf("Vault.Address = %q, want the VAULT_ADDR value", config.Vault.Address)
+	}
+	vault, _ := vaultConfig(config.Vault)
+	if vault.Secrets["JWT_SECRET"] != "kv/autostrike#jwt" {
+		t.Errorf("Vault secrets = %v, want JWT_SECRET mapped", vault.Secrets)
+	}
+}
+
+func TestLoadConfig_ConfigFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Cleanup(viper.Reset)
+	file := `
+server:
+  address: ":9443"
+execution:
+  quota:
+    per_user: 3
+payload:
+  max_size: 1024
+stream:
+  events: [execution.completed, result.recorded]
+`
+	if err := os.WriteFile("config.yaml", []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EXECUTION_QUOTA_PER_USER", "5")
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if config.Server.Address != ":9443" {
+		t.Errorf("Server.Address = %q, want :9443", config.Server.Address)
+	}
+	if config.Execution.Quota.PerUser != 5 {
+		t.Errorf("Execution.Quota.PerUser = %d, want the environment value 5", config.Execution.Quota.PerUser)
+	}
+	if config.Storage.Payload.MaxSize != 1024 {
+		t.Errorf("Storage.Payload.MaxSize = %d, want 1024", config.Storage.Payload.MaxSize)
+	}
+	if len(config.Stream.Events) != 2 {
+		t.Errorf("Stream.Events = %v, want 2 events", config.Stream.Events)
+	}
+}
+
+func TestLoadConfig_InvalidValueKeepsDefault(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("SMTP_PORT", "smtp")
+	t.Setenv("TRASH_RETENTION", "-1h")
+	t.Setenv("JOB_WORKERS", "4")
+
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if config.Notify.SMTP.Port != 587 {
+		t.Errorf("SMTP port = %d, want the default 587", config.Notify.SMTP.Port)
+	}
+	if config.Catalog.TrashRetention != application.DefaultTrashRetention {
+		t.Errorf("Catalog.TrashRetention = %v, want the default", config.Catalog.TrashRetention)
+	}
+	if config.Execution.JobWorkers != 4 {
+		t.Errorf("Execution.JobWorkers = %d, want 4", config.Execution.JobWorkers)
+	}
+	if len(config.Invalid) != 2 {
+		t.Errorf("Invalid = %v, want SMTP_PORT and TRASH_RETENTION", config.Invalid)
+	}
+}
+
+func TestConfig_SetEnv(t *testing.T) {
+	config := defaultConfig()
+
+	if !config.setEnv("SMTP_PASSWORD", "from-vault") {
+		t.Fatal("setEnv(SMTP_PASSWORD) = false, want true")
+	}
+	if config.Notify.SMTP.Password != "from-vault" {
+		t.Errorf("SMTP password = %q, want from-vault", config.Notify.SMTP.Password)
+	}
+	if !config.setEnv("VAULT_ADDR", "https://vault") || config.Vault.Address != "https://vault" {
+		t.Error("VAULT_ADDR should set the Vault address")
+	}
+	if config.setEnv("OTEL_EXPORTER_OTLP_HEADERS", "x") {
+		t.Error("setEnv of a variable no setting is read from should return false")
+	}
+}
@@ -2,34 +2,43 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"log"
 	"os"
 	"os/signal"
-	"strconv"
 	"syscall"
 	"time"
 
 	"autostrike/internal/application"
-	"autostrike/internal/domain/entity"
 	"autostrike/internal/domain/repository"
 	"autostrike/internal/domain/service"
 	"autostrike/internal/infrastructure/api/rest"
+	"autostrike/internal/infrastructure/cache"
+	"autostrike/internal/infrastructure/content"
 	"autostrike/internal/infrastructure/persistence/sqlite"
+	"autostrike/internal/infrastructure/secrets"
+	"autostrike/internal/infrastructure/telemetry"
 	"autostrike/internal/infrastructure/websocket"
 
 	"github.com/joho/godotenv"
-	"github.com/spf13/viper"
 	"go.uber.org/zap"
 
 	// Register SQLite3 driver for database/sql
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// Version is the build of the server, set by the Makefile with
+// -ldflags "-X main.Version=..."
+var Version = "dev"
+
 func main() {
 	// Load .env file (optional - won't fail if not found)
 	_ = godotenv.Load()
 
+	// autostrike validate-config checks the configuration and exits
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(validateConfig(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Initialize logger
 	logger, err := zap.NewProduction()
 	if err != nil {
@@ -40,12 +49,25 @@ func main() {
 	}()
 
 	// Load configuration
-	if err := loadConfig(); err != nil {
+	config, err := loadConfig()
+	if err != nil {
 		logger.Fatal("Failed to load config", zap.Error(err))
 	}
+	for _, setting := range config.Invalid {
+		logger.Warn("Invalid configuration value ignored, using the default", zap.String("setting", setting))
+	}
+	// Read the runtime secrets mapped in Vault before the services are created
+	vault := initVault(config, logger)
+	reportStartupConfig(config, logger)
+
+	// Initialize tracing (exports spans when OTEL_EXPORTER_OTLP_ENDPOINT is set)
+	shutdownTracing, err := telemetry.InitTracing(context.Background(), logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", zap.Error(err))
+	}
 
 	// Initialize database
-	db, err := sql.Open("sqlite3", viper.GetString("database.path"))
+	db, err := sqlite.Open(databaseConfig(config.Database))
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
@@ -57,13 +79,53 @@ func main() {
 	}
 
 	// Initialize repositories
+	// The repositories caching prepared statements close them before the database
 	agentRepo := sqlite.NewAgentRepository(db)
-	scenarioRepo := sqlite.NewScenarioRepository(db)
-	techniqueRepo := sqlite.NewTechniqueRepository(db)
+	defer agentRepo.Close()
+	scenarioStore := sqlite.NewScenarioRepository(db)
+	defer scenarioStore.Close()
+	scenarioRepo := cache.NewScenarioCache(scenarioStore)
+	techniqueRepo := cache.NewTechniqueCache(sqlite.NewTechniqueRepository(db))
 	resultRepo := sqlite.NewResultRepository(db)
+	defer resultRepo.Close()
 	userRepo := sqlite.NewUserRepository(db)
-	notificationRepo := sqlite.NewNotificationRepository(db)
+	var notificationRepo repository.NotificationRepository = sqlite.NewNotificationRepository(db)
 	scheduleRepo := sqlite.NewScheduleRepository(db)
+	activityRepo := sqlite.NewActivityRepository(db)
+	scoreHistoryRepo := sqlite.NewScoreHistoryRepository(db)
+	agentSelectorRepo := sqlite.NewAgentSelectorRepository(db)
+	webhookDeliveryRepo := sqlite.NewWebhookDeliveryRepository(db)
+	factRepo := sqlite.NewFactRepository(db)
+	payloadRepo := sqlite.NewPayloadRepository(db)
+	artifactRepo := sqlite.NewArtifactRepository(db)
+	adhocTaskRepo := sqlite.NewAdHocTaskRepository(db)
+	agentReleaseRepo := sqlite.NewAgentReleaseRepository(db)
+	beaconRepo := sqlite.NewBeaconOverrideRepository(db)
+	taskQueueRepo := sqlite.NewTaskQueueRepository(db)
+	trashRepo := cache.NewCatalogTrash(sqlite.NewTrashRepository(db), techniqueRepo, scenarioRepo)
+	retentionRepo := sqlite.NewRetentionRepository(db)
+	scoringProfileRepo := sqlite.NewScoringProfileRepository(db)
+	ticketRepo := sqlite.NewTicketRepository(db)
+	smtpConfigRepo := sqlite.NewSMTPConfigRepository(db)
+	annotationRepo := sqlite.NewResultAnnotationRepository(db)
+	evidenceRepo := sqlite.NewEvidenceRepository(db)
+	reviewRepo := sqlite.NewExecutionReviewRepository(db)
+	apiKeyRepo := sqlite.NewAPIKeyRepository(db)
+
+	// Secrets store: resolve the secret:// references of the configuration and
+	// encrypt the credentials of notification settings
+	secretService := initSecretService(config, sqlite.NewSecretRepository(db), logger)
+	resolveSecretRefs(config, secretService, logger)
+	if secretService != nil {
+		notificationRepo = secrets.NewNotificationRepository(notificationRepo, secretService)
+	}
+
+	// Share the invalidations of the technique and scenario caches with the
+	// other instances when CATALOG_CACHE_REDIS_URL is set
+	catalogInvalidator := initCatalogInvalidation(config.Catalog, techniqueRepo, scenarioRepo, logger)
+	if catalogInvalidator != nil {
+		catalogInvalidator.Start()
+	}
 
 	// Initialize domain services
 	validator := service.NewTechniqueValidator()
@@ -81,53 +143,117 @@ func main() {
 		orchestrator,
 		calculator,
 	)
+	executionService.SetFactRepository(factRepo)
+	executionService.SetDurationHistory(resultRepo)
+	executionService.SetExecutionSummaries(resultRepo)
+	executionService.SetSubmissionLog(resultRepo)
+	executionService.SetResultBatch(resultRepo)
+	executionService.SetSnapshotRepository(sqlite.NewExecutionSnapshotRepository(db), Version)
+	scoringProfileService := application.NewScoringProfileService(scoringProfileRepo, techniqueRepo, calculator)
+	executionService.SetScoringProfileService(scoringProfileService)
+	payloadService := initPayloadService(config, payloadRepo, techniqueRepo, resultRepo, logger)
+	executionService.SetPayloadService(payloadService)
+	initTaskQueue(config.Execution, executionService, taskQueueRepo, logger)
+	resultQueue := initResultQueue(config.Execution, executionService, logger)
+	initStaleExecutionTimeout(config.Execution, executionService, logger)
+	initExecutionQuota(config.Execution, executionService, logger)
+	artifactService := initArtifactService(config, artifactRepo, resultRepo, logger)
+	retentionService := initRetentionService(config, retentionRepo, resultRepo, logger)
+	evidenceService := initEvidenceService(config, evidenceRepo, resultRepo)
+	initBlobStore(config, executionService, artifactService, evidenceService, retentionService, logger)
+	annotationService := application.NewResultAnnotationService(annotationRepo, resultRepo, userRepo)
+	executionService.SetResultAnnotations(annotationService)
+	reviewService := application.NewExecutionReviewService(reviewRepo, resultRepo, userRepo, annotationService, executionService)
+	executionService.SetExecutionReviews(reviewService)
+	annotationService.SetExecutionReviews(reviewService)
+	evidenceService.SetExecutionReviews(reviewService)
+	adhocTaskService := application.NewAdHocTaskService(adhocTaskRepo, agentRepo, logger)
 	techniqueService := application.NewTechniqueService(techniqueRepo)
 	analyticsService := application.NewAnalyticsService(resultRepo)
+	analyticsService.SetTechniqueRepository(techniqueRepo)
+	analyticsService.SetAgentRepository(agentRepo)
+	readinessService := application.NewReadinessService(scenarioRepo, techniqueRepo, agentRepo)
+	scoreBackfillService := application.NewScoreBackfillService(resultRepo, scoreHistoryRepo, calculator, logger)
+	scoreBackfillService.SetScoringProfileService(scoringProfileService)
+	agentSelectorService := application.NewAgentSelectorService(agentSelectorRepo, agentRepo)
+	beaconService := initBeaconService(config.Agent, beaconRepo, agentSelectorRepo, agentRepo, logger)
+	agentService.SetBeaconService(beaconService)
+	agentStaleTimeout := initAgentAvailability(config.Agent, agentService, sqlite.NewAgentAvailabilityRepository(db), logger)
+	agentService.SetBeaconHistory(sqlite.NewAgentBeaconRepository(db))
+	agentService.SetAgentResults(resultRepo)
+	contentImportService := application.NewContentImportService(techniqueService, scenarioService,
+		content.NewPreludeConverter(), content.NewStratusConverter(), content.NewCTIDConverter(),
+		content.NewCalderaConverter(), content.NewSTIXConverter(), content.NewAtomicConverter())
+	jobService := initJobService(config.Execution, sqlite.NewJobRepository(db), logger)
+	// Deleted scenarios and techniques stay in the trash for TRASH_RETENTION, 0 keeping them until restored
+	trashService := application.NewTrashService(trashRepo, config.Catalog.TrashRetention, logger)
+	trashService.SetTechniqueCache(techniqueRepo)
+
+	// Initialize notification service with the SMTP configuration
+	notificationService := initNotificationService(config, notificationRepo, smtpConfigRepo, userRepo, secretService, logger)
+	notificationService.SetWebhookResults(resultRepo, techniqueRepo, webhookVerbosity(config.Notify, logger))
+	webhookDeliveryService := application.NewWebhookDeliveryService(
+		webhookDeliveryRepo, notificationRepo, application.DefaultWebhookDeliveryConfig(), logger,
+	)
+	notificationService.SetWebhookDeliveryService(webhookDeliveryService)
+	notificationService.SetIncidentAlerting(scheduleRepo,
+		config.Notify.Notifications.PagerDutyEventsURL, config.Notify.Notifications.OpsgenieAPIURL)
+	deepLinks := initDeepLinks(config.Auth, logger)
+	if deepLinks != nil {
+		notificationService.SetDeepLinks(deepLinks)
+	}
 
-	// Initialize notification service with SMTP config from environment
-	notificationService := initNotificationService(notificationRepo, userRepo, logger)
+	// Initialize the event bus: notifications plus an optional event stream
+	eventBus, eventWebhook := initEventBus(config.Notify, notificationService, logger)
+	executionService.SetEventBus(eventBus)
+	agentService.SetEventBus(eventBus)
+
+	// Stream results and status events to Kafka or NATS when stream.driver is set
+	streamSink := initStreamSink(config.Stream, logger)
+	if streamSink != nil {
+		eventBus.AddSink(streamSink)
+	}
+
+	// Initialize SIEM/EDR detection verification
+	detectionService := initDetectionService(config.Detection, resultRepo, agentRepo, techniqueRepo, calculator, logger)
+	detectionService.SetScoringProfileService(scoringProfileService)
 
 	// Initialize schedule service
 	scheduleService := application.NewScheduleService(scheduleRepo, executionService, logger)
-
-	// Initialize auth service (JWT secret from environment)
-	jwtSecret := os.Getenv("JWT_SECRET")
-	var authService *application.AuthService
-	if jwtSecret != "" {
-		authService = application.NewAuthService(userRepo, jwtSecret)
-		// Ensure default admin user exists
-		result, err := authService.EnsureDefaultAdmin(context.Background())
-		if err != nil {
-			logger.Warn("Failed to create default admin user", zap.Error(err))
-		} else if result.Created {
-			if result.GeneratedPassword != "" {
-				// Print password to stderr only - never to structured logs
-				logger.Info("=======================================================")
-				logger.Info("Default admin user created with auto-generated password")
-				logger.Info("Username: admin")
-				// Password printed to stderr to avoid exposure in log aggregation systems
-				_, _ = os.Stderr.WriteString("Default admin password: " + result.GeneratedPassword + "\n")
-				logger.Info("Password printed to stderr (not logged)")
-				logger.Info("IMPORTANT: Change this password immediately after first login!")
-				logger.Info("Or set DEFAULT_ADMIN_PASSWORD env var before first startup.")
-				logger.Info("=======================================================")
-			} else {
-				logger.Info("Default admin user created with password from DEFAULT_ADMIN_PASSWORD env var")
-			}
-		} else {
-			logger.Debug("Default admin user already exists, skipping creation")
-		}
+	scheduleService.SetAgentSelectorService(agentSelectorService)
+	scheduleService.SetEventBus(eventBus)
+	eventBus.AddSink(scheduleService)
+
+	// Raise detection regressions when a completed execution does worse than the previous run of its scenario
+	analyticsService.SetEventBus(eventBus)
+	eventBus.AddSink(analyticsService)
+
+	// Open tracker issues for the techniques that ran undetected, when Jira or ServiceNow is configured
+	ticketService := initTicketService(config, ticketRepo, resultRepo, techniqueRepo, agentRepo, scenarioRepo, detectionService, logger)
+	if ticketService != nil {
+		eventBus.AddSink(ticketService)
 	}
 
-	// Auto-import techniques from configs directory at startup
-	autoImportTechniques(techniqueService, logger)
+	// Initialize auth service, when a JWT secret is set
+	authService := initAuthService(config.Auth, userRepo, logger)
 
-	// Auto-import scenarios from configs directory at startup
-	autoImportScenarios(scenarioService, logger)
+	// Plan the techniques and scenarios of the configs directory at startup,
+	// and apply the plan unless CONTENT_AUTO_APPLY=false
+	contentPlanService := application.NewContentPlanService(techniqueRepo, scenarioRepo, trashRepo, logger)
+	contentReloadService := application.NewContentReloadService(contentPlanService, "./configs", logger)
+	autoApplyContent(config.Content, contentPlanService, contentReloadService, logger)
 
 	// Initialize WebSocket hub
 	hub := websocket.NewHub(logger)
 
+	// Kill switch for all activity, tripped by admins or when the database stops answering
+	emergencyStop := initEmergencyStop(config.Execution, db, executionService, scheduleService, adhocTaskService, hub, logger)
+
+	// Push execution, result, agent and unread notification events to the dashboards
+	dashboardEvents := websocket.NewDashboardEvents(hub, logger)
+	eventBus.AddSink(dashboardEvents)
+	notificationService.SetUnreadCountListener(dashboardEvents.UnreadCountChanged)
+
 	// Set callback to mark agents offline when they disconnect
 	hub.SetOnAgentDisconnect(func(paw string) {
 		ctx := context.Background()
@@ -140,39 +266,134 @@ func main() {
 
 	go hub.Run()
 
-	// Start background job to clean up stale agents (every 60 seconds)
-	go func() {
-		ticker := time.NewTicker(60 * time.Second)
-		defer ticker.Stop()
-		for range ticker.C {
-			ctx := context.Background()
-			if err := agentService.CheckStaleAgents(ctx, 2*time.Minute); err != nil {
-				logger.Warn("Failed to check stale agents", zap.Error(err))
+	// Check stale agents and expire queued tasks and stuck executions, and purge
+	// the data kept past its retention
+	startMaintenance(agentService, executionService, trashService, notificationService, agentStaleTimeout, logger)
+
+	// Apply the secrets rotated in Vault: the SMTP password and the event
+	// webhook secret at once, the others on the next restart
+	if vault != nil {
+		vault.OnChange(func(name, value string) {
+			setVaultSecret(config, name, value)
+			switch {
+			case name == "SMTP_PASSWORD" && notificationService.SetSMTPPassword(value):
+			case name == "EVENT_WEBHOOK_SECRET" && eventWebhook != nil:
+				eventWebhook.SetSecret(value)
+			default:
+				logger.Warn("Vault secret changed, applied on the next restart", zap.String("env", name))
 			}
+		})
+		vault.Start()
+	}
+
+	// Repeated failed logins lock out the username and the source IP, alerting admins
+	activityMonitor := initActivityMonitor(config.Activity, activityRepo, userRepo, eventBus, logger)
+	if authService != nil {
+		authService.SetLoginLockout(loginLockoutConfig(config.Login, logger))
+		authService.OnLockout(activityMonitor.RecordLoginLockout)
+
+		// Self-service password reset, emailing tokens through the SMTP configuration
+		resetTTL := application.DefaultPasswordResetTTL
+		if config.Auth.PasswordResetTTL > 0 {
+			resetTTL = config.Auth.PasswordResetTTL
 		}
-	}()
+		authService.SetPasswordReset(sqlite.NewPasswordResetRepository(db), notificationService, resetTTL)
+		authService.OnPasswordReset(activityMonitor.RecordPasswordResetRequest, activityMonitor.RecordPasswordReset)
+	}
 
 	// Initialize HTTP server
 	services := &rest.Services{
-		Agent:        agentService,
-		Scenario:     scenarioService,
-		Execution:    executionService,
-		Technique:    techniqueService,
-		Auth:         authService,
-		Analytics:    analyticsService,
-		Notification: notificationService,
-		Schedule:     scheduleService,
+		Agent:           agentService,
+		Scenario:        scenarioService,
+		Execution:       executionService,
+		Technique:       techniqueService,
+		Auth:            authService,
+		Analytics:       analyticsService,
+		Readiness:       readinessService,
+		Notification:    notificationService,
+		Schedule:        scheduleService,
+		Detection:       detectionService,
+		Activity:        activityMonitor,
+		ScoreBackfill:   scoreBackfillService,
+		AgentSelector:   agentSelectorService,
+		WebhookDelivery: webhookDeliveryService,
+		DeepLinks:       deepLinks,
+		ContentImport:   contentImportService,
+		TechniqueSync:   initTechniqueSyncService(config.Catalog, contentImportService, jobService, logger),
+		Payload:         payloadService,
+		Artifact:        artifactService,
+		AdHocTask:       adhocTaskService,
+		AgentUpdate:     initAgentUpdateService(config.Agent, agentReleaseRepo, logger),
+		Beacon:          beaconService,
+		Health:          initHealthService(db, hub, scheduleService, notificationService, resultQueue, catalogInvalidator),
+		Trash:           trashService,
+		Retention:       retentionService,
+		ConfigBundle:    initConfigBundleService(config.Catalog, techniqueRepo, scenarioRepo, agentSelectorRepo, scheduleRepo, beaconRepo, logger),
+		Search:          application.NewSearchService(sqlite.NewSearchRepository(db)),
+		Jobs:            jobService,
+		ScoringProfile:  scoringProfileService,
+		Ticket:          ticketService,
+		EmergencyStop:   emergencyStop,
+		Annotation:      annotationService,
+		Evidence:        evidenceService,
+		Review:          reviewService,
+		APIKey:          application.NewAPIKeyService(apiKeyRepo, userRepo),
+		ContentPlan:     contentPlanService,
+		ContentReload:   contentReloadService,
+		Secrets:         secretService,
+		ResultQueue:     resultQueue,
+	}
+	server := rest.NewServerWithConfig(services, hub, logger, serverConfig(config))
+
+	// Recover the executions left in flight by the previous run
+	recoverExecutions(config.Execution, executionService, logger)
+
+	// Start writing the results agents report
+	if resultQueue != nil {
+		resultQueue.Start()
 	}
-	server := rest.NewServer(services, hub, logger)
 
 	// Start the scheduler
 	scheduleService.Start()
 
-	// Start server
+	// Start retrying failed webhook deliveries
+	webhookDeliveryService.Start()
+
+	// Start sending the held emails as digests
+	notificationService.StartDigests(application.DefaultDigestCheckInterval)
+
+	// Start purging expired result artifacts
+	artifactService.Start()
+
+	// Start the nightly execution retention job
+	retentionService.Start()
+
+	// Start the background job workers
+	jobService.Start()
+
+	// Start syncing ticket statuses with the tracker
+	if ticketService != nil {
+		ticketService.Start()
+	}
+
+	// Start publishing streamed events
+	if streamSink != nil {
+		streamSink.Start()
+	}
+
+	// Start reloading the technique and scenario files changed at runtime
+	contentWatcher := startContentWatcher(config.Content, contentReloadService, logger)
+
+	// Start server, terminating TLS when security.tls.enabled is set
+	serverTLS, certReloader := initTLS(config.Security.TLS, logger)
 	go func() {
-		addr := viper.GetString("server.address")
-		logger.Info("Starting AutoStrike server", zap.String("address", addr))
-		if err := server.Run(addr); err != nil {
+		addr := config.Server.Address
+		logger.Info("Starting AutoStrike server", zap.String("address", addr), zap.Bool("tls", serverTLS != nil))
+		run := server.Run
+		if serverTLS != nil {
+			run = func(addr string) error { return server.RunTLS(addr, serverTLS) }
+		}
+		if err := run(addr); err != nil {
 			logger.Fatal("Server failed", zap.Error(err))
 		}
 	}()
@@ -184,144 +405,76 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
-	// Stop the scheduler
+	// Stop the scheduler and the database watch
 	scheduleService.Stop()
+	emergencyStop.Stop()
 
-	// Close server resources (rate limiters, token blacklist)
-	server.Close()
-}
-
-func autoImportTechniques(service *application.TechniqueService, logger *zap.Logger) {
-	// Import techniques from all YAML files in configs/techniques
-	paths := []string{
-		"./configs/techniques/reconnaissance.yaml",
-		"./configs/techniques/initial-access.yaml",
-		"./configs/techniques/discovery.yaml",
-		"./configs/techniques/execution.yaml",
-		"./configs/techniques/persistence.yaml",
-		"./configs/techniques/privilege-escalation.yaml",
-		"./configs/techniques/defense-evasion.yaml",
-		"./configs/techniques/credential-access.yaml",
-		"./configs/techniques/collection.yaml",
-		"./configs/techniques/lateral-movement.yaml",
-		"./configs/techniques/command-and-control.yaml",
-		"./configs/techniques/exfiltration.yaml",
-		"./configs/techniques/impact.yaml",
+	// Stop accepting requests, then let agents finish in-flight executions
+	httpCtx, httpCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := server.Shutdown(httpCtx); err != nil {
+		logger.Warn("Failed to stop HTTP server gracefully", zap.Error(err))
 	}
+	httpCancel()
+	drainExecutions(config, executionService, hub, logger)
 
-	imported := 0
-	for _, path := range paths {
-		if _, err := os.Stat(path); err == nil {
-			if err := service.ImportTechniques(context.Background(), path); err != nil {
-				logger.Warn("Failed to import techniques", zap.String("path", path), zap.Error(err))
-			} else {
-				imported++
-				logger.Info("Imported techniques", zap.String("path", path))
-			}
-		}
+	// Write the results still queued
+	if resultQueue != nil {
+		resultQueue.Stop()
 	}
 
-	if imported > 0 {
-		logger.Info("Auto-imported technique files", zap.Int("count", imported))
-	}
-}
+	// Stop webhook delivery retries and digests
+	webhookDeliveryService.Stop()
+	notificationService.StopDigests()
 
-func autoImportScenarios(service *application.ScenarioService, logger *zap.Logger) {
-	// Import scenarios from all YAML files in configs/scenarios
-	paths := []string{
-		"./configs/scenarios/default.yaml",
-	}
+	// Cancel pending detection verifications
+	detectionService.Stop()
 
-	imported := 0
-	for _, path := range paths {
-		if _, err := os.Stat(path); err == nil {
-			if err := service.ImportScenarios(context.Background(), path); err != nil {
-				logger.Warn("Failed to import scenarios", zap.String("path", path), zap.Error(err))
-			} else {
-				imported++
-				logger.Info("Imported scenarios", zap.String("path", path))
-			}
-		}
-	}
+	// Stop purging artifacts
+	artifactService.Stop()
 
-	if imported > 0 {
-		logger.Info("Auto-imported scenario files", zap.Int("count", imported))
-	}
-}
-
-func loadConfig() error {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("./config")
-	viper.AddConfigPath("./configs")
-	viper.AddConfigPath(".")
+	// Cancel the running background jobs
+	jobService.Stop()
 
-	// Defaults
-	viper.SetDefault("server.address", ":8443")
-	viper.SetDefault("database.path", "./data/autostrike.db")
-	viper.SetDefault("agent.beacon_interval", 30)
+	// Stop the execution retention job
+	retentionService.Stop()
 
-	viper.AutomaticEnv()
+	// Stop the ticket sync
+	if ticketService != nil {
+		ticketService.Stop()
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
-		}
+	// Publish the queued events and disconnect from the broker
+	if streamSink != nil {
+		streamSink.Stop()
 	}
-	return nil
-}
 
-// initNotificationService initializes the notification service with SMTP config from environment
-func initNotificationService(
-	notificationRepo repository.NotificationRepository,
-	userRepo repository.UserRepository,
-	logger *zap.Logger,
-) *application.NotificationService {
-	// Get SMTP config from environment variables
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPortStr := os.Getenv("SMTP_PORT")
-	smtpUsername := os.Getenv("SMTP_USERNAME")
-	smtpPassword := os.Getenv("SMTP_PASSWORD")
-	smtpFrom := os.Getenv("SMTP_FROM")
-	smtpUseTLS := os.Getenv("SMTP_USE_TLS") == "true"
-	dashboardURL := os.Getenv("DASHBOARD_URL")
-
-	if dashboardURL == "" {
-		dashboardURL = "https://localhost:8443"
+	// Stop sharing the catalog cache invalidations
+	if catalogInvalidator != nil {
+		catalogInvalidator.Stop()
 	}
 
-	var smtpConfig *entity.SMTPConfig
-	if smtpHost != "" {
-		smtpPort := 587 // Default SMTP port
-		if smtpPortStr != "" {
-			if p, err := strconv.Atoi(smtpPortStr); err == nil {
-				smtpPort = p
-			}
-		}
+	// Stop watching the content directory
+	if contentWatcher != nil {
+		contentWatcher.Stop()
+	}
 
-		smtpConfig = &entity.SMTPConfig{
-			Host:     smtpHost,
-			Port:     smtpPort,
-			Username: smtpUsername,
-			Password: smtpPassword,
-			From:     smtpFrom,
-			UseTLS:   smtpUseTLS,
-		}
+	// Stop watching the certificate files
+	if certReloader != nil {
+		certReloader.Stop()
+	}
 
-		if smtpConfig.IsValid() {
-			logger.Info("SMTP configuration loaded",
-				zap.String("host", smtpHost),
-				zap.Int("port", smtpPort),
-				zap.String("from", smtpFrom),
-				zap.Bool("use_tls", smtpUseTLS),
-			)
-		} else {
-			logger.Warn("SMTP configuration incomplete - email notifications disabled")
-			smtpConfig = nil
-		}
-	} else {
-		logger.Info("SMTP not configured - email notifications disabled")
+	// Stop renewing the Vault token and leases
+	if vault != nil {
+		vault.Stop()
 	}
 
-	return application.NewNotificationService(notificationRepo, userRepo, smtpConfig, dashboardURL, logger)
+	// Close server resources (rate limiters, token blacklist)
+	server.Close()
+
+	// Flush pending spans
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("Failed to flush traces", zap.Error(err))
+	}
 }
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/secrets"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// checkedEnvVars are the environment variables whose value is parsed, an
+// invalid value being ignored by the server
+var checkedEnvVars = []application.EnvVar{
+	{Name: "ENABLE_AUTH", Kind: application.EnvBool},
+	{Name: "CONTENT_AUTO_APPLY", Kind: application.EnvBool},
+	{Name: "CONTENT_WATCH", Kind: application.EnvBool},
+	{Name: "CONFIG_FAIL_FAST", Kind: application.EnvBool},
+	{Name: "RESUME_INTERRUPTED_EXECUTIONS", Kind: application.EnvBool},
+	{Name: "S3_PATH_STYLE", Kind: application.EnvBool},
+	{Name: "SECURITY_TLS_ENABLED", Kind: application.EnvBool},
+	{Name: "SMTP_USE_TLS", Kind: application.EnvBool},
+	{Name: "ACTIVITY_MASS_DELETE_WINDOW", Kind: application.EnvDuration},
+	{Name: "ARTIFACT_RETENTION", Kind: application.EnvDuration},
+	{Name: "DATABASE_BUSY_TIMEOUT", Kind: application.EnvDuration},
+	{Name: "DATABASE_CONN_MAX_LIFETIME", Kind: application.EnvDuration},
+	{Name: "DEEP_LINK_TTL", Kind: application.EnvDuration},
+	{Name: "EMERGENCY_STOP_DB_CHECK_INTERVAL", Kind: application.EnvDuration},
+	{Name: "EXECUTION_QUOTA_WINDOW", Kind: application.EnvDuration},
+	{Name: "LOGIN_FAILURE_WINDOW", Kind: application.EnvDuration},
+	{Name: "LOGIN_LOCKOUT", Kind: application.EnvDuration},
+	{Name: "LOGIN_MAX_LOCKOUT", Kind: application.EnvDuration},
+	{Name: "PASSWORD_RESET_TTL", Kind: application.EnvDuration},
+	{Name: "NOTIFICATION_RETENTION", Kind: application.EnvDuration},
+	{Name: "PAYLOAD_URL_TTL", Kind: application.EnvDuration},
+	{Name: "RESUME_AGENT_GRACE", Kind: application.EnvDuration},
+	{Name: "SHUTDOWN_DRAIN_TIMEOUT", Kind: application.EnvDuration},
+	{Name: "SIEM_QUERY_DELAY", Kind: application.EnvDuration},
+	{Name: "SIEM_QUERY_WINDOW", Kind: application.EnvDuration},
+	{Name: "STREAM_FLUSH_INTERVAL", Kind: application.EnvDuration},
+	{Name: "TASK_QUEUE_TTL", Kind: application.EnvDuration},
+	{Name: "TICKET_DELAY", Kind: application.EnvDuration},
+	{Name: "TICKET_SYNC_INTERVAL", Kind: application.EnvDuration},
+	{Name: "TRASH_RETENTION", Kind: application.EnvDuration},
+	{Name: "VAULT_REFRESH_INTERVAL", Kind: application.EnvDuration},
+	{Name: "ACTIVITY_MASS_DELETE_THRESHOLD", Kind: application.EnvInt},
+	{Name: "AGENT_BEACON_INTERVAL", Kind: application.EnvInt},
+	{Name: "AGENT_BEACON_JITTER", Kind: application.EnvInt},
+	{Name: "AGENT_RELEASE_MAX_SIZE", Kind: application.EnvInt},
+	{Name: "ARTIFACT_MAX_SIZE", Kind: application.EnvInt},
+	{Name: "ARTIFACT_RESULT_QUOTA", Kind: application.EnvInt},
+	{Name: "DATABASE_MAX_IDLE_CONNS", Kind: application.EnvInt},
+	{Name: "DATABASE_MAX_OPEN_CONNS", Kind: application.EnvInt},
+	{Name: "EMERGENCY_STOP_DB_FAILURES", Kind: application.EnvInt},
+	{Name: "EVIDENCE_MAX_SIZE", Kind: application.EnvInt},
+	{Name: "EVIDENCE_RESULT_QUOTA", Kind: application.EnvInt},
+	{Name: "EXECUTION_QUOTA_PER_USER", Kind: application.EnvInt},
+	{Name: "EXECUTION_QUOTA_TOTAL", Kind: application.EnvInt},
+	{Name: "LOGIN_MAX_FAILURES", Kind: application.EnvInt},
+	{Name: "LOGIN_MAX_IP_FAILURES", Kind: application.EnvInt},
+	{Name: "OUTPUT_BLOB_THRESHOLD", Kind: application.EnvInt},
+	{Name: "OUTPUT_MAX_SIZE", Kind: application.EnvInt},
+	{Name: "OUTPUT_PREVIEW_SIZE", Kind: application.EnvInt},
+	{Name: "PAYLOAD_MAX_SIZE", Kind: application.EnvInt},
+	{Name: "RESULT_QUEUE_BATCH", Kind: application.EnvInt},
+	{Name: "RESULT_QUEUE_SIZE", Kind: application.EnvInt},
+	{Name: "RETENTION_RUN_HOUR", Kind: application.EnvInt},
+	{Name: "STREAM_BATCH_SIZE", Kind: application.EnvInt},
+	{Name: "CATALOG_CACHE_REDIS_URL", Kind: application.EnvURL},
+	{Name: "CROWDSTRIKE_URL", Kind: application.EnvURL},
+	{Name: "DASHBOARD_URL", Kind: application.EnvURL},
+	{Name: "ELASTIC_URL", Kind: application.EnvURL},
+	{Name: "EVENT_WEBHOOK_URL", Kind: application.EnvURL},
+	{Name: "JIRA_URL", Kind: application.EnvURL},
+	{Name: "OPSGENIE_API_URL", Kind: application.EnvURL},
+	{Name: "PAGERDUTY_EVENTS_URL", Kind: application.EnvURL},
+	{Name: "S3_ENDPOINT", Kind: application.EnvURL},
+	{Name: "SENTINELONE_URL", Kind: application.EnvURL},
+	{Name: "SERVICENOW_URL", Kind: application.EnvURL},
+	{Name: "SPLUNK_URL", Kind: application.EnvURL},
+	{Name: "VAULT_ADDR", Kind: application.EnvURL},
+}
+
+// newConfigValidator registers the checks of the configuration loaded by
+// loadConfig, loadErr being the error it returned
+func newConfigValidator(config *Config, loadErr error) *application.ConfigValidator {
+	validator := application.NewConfigValidator()
+	validator.Add("config file", application.CheckConfigFile(viper.ConfigFileUsed(), loadErr))
+	validator.Add("environment", application.CheckEnvVars(os.Getenv, checkedEnvVars))
+	validator.Add("secret references", application.CheckSecretRefs(os.Environ(),
+		config.Secrets.MasterKey != "" || config.Secrets.KMSKeyID != ""))
+	validator.Add("vault", checkVault(config.Vault))
+	validator.Add("jwt secret", checkJWTSecret(config))
+	validator.Add("tls", checkTLS(config.Security.TLS))
+	validator.Add("network allowlists", checkNetworks(config))
+	validator.Add("database path", application.CheckDatabasePath(config.Database.Path))
+	validator.Add("smtp", application.CheckSMTP(os.Getenv("SMTP_HOST"), os.Getenv("SMTP_PORT"), os.Getenv("SMTP_FROM")))
+	validator.Add("content yaml", application.CheckYAMLFiles("./configs"))
+	return validator
+}
+
+// checkVault checks the Vault configuration, without connecting to Vault
+func checkVault(settings VaultConfig) application.ConfigCheckFunc {
+	return func() (string, error) {
+		config, ok := vaultConfig(settings)
+		if !ok {
+			return "not configured", nil
+		}
+		if _, err := secrets.NewVault(config, nil); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d secrets from %s", len(config.Secrets), config.Address), nil
+	}
+}
+
+// checkJWTSecret checks JWT_SECRET, unless validate-config runs before it is
+// read from Vault
+func checkJWTSecret(config *Config) application.ConfigCheckFunc {
+	secret := config.Auth.JWTSecret
+	if vault, ok := vaultConfig(config.Vault); ok && secret == "" && vault.Secrets["JWT_SECRET"] != "" {
+		ref := vault.Secrets["JWT_SECRET"]
+		return func() (string, error) {
+			return fmt.Sprintf("read from Vault (%s)", ref), nil
+		}
+	}
+	return application.CheckJWTSecret(secret, config.Auth.Enabled != nil && *config.Auth.Enabled)
+}
+
+// checkTLS checks the certificate files of the TLS termination, when enabled
+// without ACME
+func checkTLS(config TLSConfig) application.ConfigCheckFunc {
+	switch {
+	case !config.Enabled:
+		return func() (string, error) {
+			return "disabled, TLS must be terminated in front of the server", nil
+		}
+	case len(config.ACME.Domains) > 0:
+		return func() (string, error) {
+			return fmt.Sprintf("ACME certificates for %s", strings.Join(config.ACME.Domains, ", ")), nil
+		}
+	}
+	return application.CheckTLSCertificate(config.CertFile, config.KeyFile, time.Now())
+}
+
+// checkNetworks checks the network allowlists and trusted proxies, which the
+// server refuses to start with when invalid
+func checkNetworks(config *Config) application.ConfigCheckFunc {
+	return func() (string, error) {
+		var zones []string
+		for _, list := range []struct {
+			name     string
+			networks []string
+		}{
+			{"AGENT_ALLOWED_NETWORKS", config.Agent.AllowedNetworks},
+			{"API_ALLOWED_NETWORKS", config.Server.APIAllowedNetworks},
+			{"TRUSTED_PROXIES", config.Server.TrustedProxies},
+		} {
+			if _, err := entity.ParseNetworkList(list.networks); err != nil {
+				return "", fmt.Errorf("%s: %w", list.name, err)
+			}
+			if len(list.networks) > 0 {
+				zones = append(zones, fmt.Sprintf("%s: %d", list.name, len(list.networks)))
+			}
+		}
+		if len(zones) == 0 {
+			return "any source network", nil
+		}
+		return strings.Join(zones, ", "), nil
+	}
+}
+
+// validateConfig runs the validate-config command: it prints the report of
+// the configuration checks and returns the exit code, 1 when a check failed
+func validateConfig(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: autostrike validate-config [--fail-fast] [--json]")
+		fs.PrintDefaults()
+	}
+	failFast := fs.Bool("fail-fast", false, "Stop at the first failed check")
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return 2
+	}
+
+	report := newConfigValidator(loadConfig()).Run(*failFast)
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	} else {
+		printConfigReport(stdout, report)
+	}
+	if !report.Valid() {
+		return 1
+	}
+	return 0
+}
+
+// printConfigReport prints a check per line, followed by its details
+func printConfigReport(w io.Writer, report *application.ConfigReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tCHECK\tMESSAGE")
+	for _, check := range report.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", check.Status, check.Name, check.Message)
+		for _, detail := range check.Details {
+			fmt.Fprintf(tw, "\t\t  %s\n", detail)
+		}
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\n%s\n", report.Summary())
+}
+
+// reportStartupConfig logs the configuration checks that did not pass. With
+// config.fail_fast, the server does not start when a check failed.
+func reportStartupConfig(config *Config, logger *zap.Logger) {
+	failFast := config.Checks.FailFast
+	report := newConfigValidator(config, nil).Run(failFast)
+	for _, check := range report.Checks {
+		fields := []zap.Field{zap.String("check", check.Name), zap.String("message", check.Message)}
+		if len(check.Details) > 0 {
+			fields = append(fields, zap.Strings("details", check.Details))
+		}
+		switch check.Status {
+		case application.ConfigCheckWarn:
+			logger.Warn("Configuration check warning", fields...)
+		case application.ConfigCheckFail:
+			logger.Error("Configuration check failed", fields...)
+		}
+	}
+	if !report.Valid() && failFast {
+		logger.Fatal("Invalid configuration, run autostrike validate-config for the full report",
+			zap.String("report", report.Summary()))
+	}
+	logger.Info("Configuration checked", zap.String("report", report.Summary()))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+// initAgentUpdateService creates the agent release registry when
+// AGENT_UPDATE_PUBLIC_KEY, the base64 Ed25519 key releases are signed for, is
+// set. Agents must be configured with the same key to install updates.
+// Releases are limited to AGENT_RELEASE_MAX_SIZE bytes.
+func initAgentUpdateService(
+	config AgentConfig,
+	releaseRepo repository.AgentReleaseRepository,
+	logger *zap.Logger,
+) *application.AgentUpdateService {
+	if config.UpdatePublicKey == "" {
+		return nil
+	}
+	publicKey, err := application.ParseAgentUpdateKey(config.UpdatePublicKey)
+	if err != nil {
+		logger.Warn("Invalid AGENT_UPDATE_PUBLIC_KEY, agent updates are disabled", zap.Error(err))
+		return nil
+	}
+
+	updateService := application.NewAgentUpdateService(releaseRepo, publicKey, logger)
+	if config.ReleaseMaxSize > 0 {
+		updateService.SetMaxSize(config.ReleaseMaxSize)
+	}
+	logger.Info("Agent updates enabled")
+	return updateService
+}
+
+// initAgentAvailability records the agents going online and offline. An agent
+// is offline after AGENT_STALE_TIMEOUT (2m) without a heartbeat, or when it did
+// not reconnect within AGENT_OFFLINE_GRACE (30s) of disconnecting. An agent
+// going online or offline more than AGENT_FLAP_THRESHOLD (4) times within
+// AGENT_FLAP_WINDOW (10m) is not announced until it settles; 0 disables the
+// grace and the flap detection. Returns the stale timeout.
+func initAgentAvailability(
+	config AgentConfig,
+	agentService *application.AgentService,
+	repo repository.AgentAvailabilityRepository,
+	logger *zap.Logger,
+) time.Duration {
+	stale := config.StaleTimeout
+	if stale == 0 {
+		logger.Warn("Invalid AGENT_STALE_TIMEOUT, using the default", zap.Duration("value", stale))
+		stale = 2 * time.Minute
+	}
+	availability := application.DefaultAgentAvailabilityConfig()
+	availability.OfflineGrace = config.OfflineGrace
+	availability.FlapWindow = config.FlapWindow
+	availability.FlapThreshold = config.FlapThreshold
+	agentService.SetAvailability(repo, availability)
+	return stale
+}
+
+// initBeaconService creates the beacon service. Agents without an override
+// check in every agent.beacon_interval seconds, varied by agent.beacon_jitter
+// percent.
+func initBeaconService(
+	config AgentConfig,
+	beaconRepo repository.BeaconOverrideRepository,
+	selectorRepo repository.AgentSelectorRepository,
+	agentRepo repository.AgentRepository,
+	logger *zap.Logger,
+) *application.BeaconService {
+	defaults := entity.BeaconSettings{
+		Interval: config.BeaconInterval,
+		Jitter:   config.BeaconJitter,
+	}
+	if err := defaults.Validate(); err != nil {
+		logger.Warn("Invalid agent beacon settings, using a 30s interval without jitter", zap.Error(err))
+		defaults = entity.BeaconSettings{Interval: 30}
+	}
+	return application.NewBeaconService(beaconRepo, selectorRepo, agentRepo, defaults, logger)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+// initAuthService creates the authentication of users, and the default admin
+// user on first start. Returns nil without JWT secret.
+func initAuthService(config AuthConfig, userRepo repository.UserRepository, logger *zap.Logger) *application.AuthService {
+	if config.JWTSecret == "" {
+		return nil
+	}
+	authService := application.NewAuthService(userRepo, config.JWTSecret)
+
+	// Ensure default admin user exists
+	result, err := authService.EnsureDefaultAdmin(context.Background(), config.DefaultAdminPassword)
+	if err != nil {
+		logger.Warn("Failed to create default admin user", zap.Error(err))
+	} else if result.Created {
+		if result.GeneratedPassword != "" {
+			// Print password to stderr only - never to structured logs
+			logger.Info("=======================================================")
+			logger.Info("Default admin user created with auto-generated password")
+			logger.Info("Username: admin")
+			// Password printed to stderr to avoid exposure in log aggregation systems
+			_, _ = os.Stderr.WriteString("Default admin password: " + result.GeneratedPassword + "\n")
+			logger.Info("Password printed to stderr (not logged)")
+			logger.Info("IMPORTANT: Change this password immediately after first login!")
+			logger.Info("Or set DEFAULT_ADMIN_PASSWORD env var before first startup.")
+			logger.Info("=======================================================")
+		} else {
+			logger.Info("Default admin user created with password from DEFAULT_ADMIN_PASSWORD env var")
+		}
+	} else {
+		logger.Debug("Default admin user already exists, skipping creation")
+	}
+	return authService
+}
+
+// initDeepLinks enables signed notification links when authentication is
+// configured, valid for DEEP_LINK_TTL (24h by default)
+func initDeepLinks(config AuthConfig, logger *zap.Logger) *application.DeepLinkService {
+	if config.JWTSecret == "" {
+		return nil
+	}
+	ttl := 24 * time.Hour
+	if config.DeepLinkTTL > 0 {
+		ttl = config.DeepLinkTTL
+	}
+	logger.Info("Notification deep links enabled", zap.Duration("ttl", ttl))
+	return application.NewDeepLinkService(config.JWTSecret, ttl)
+}
+
+// loginLockoutConfig returns the failed login limits
+func loginLockoutConfig(config LoginConfig, logger *zap.Logger) application.LoginLockoutConfig {
+	lockout := application.DefaultLoginLockoutConfig()
+	lockout.MaxUserFailures = config.MaxFailures
+	lockout.MaxIPFailures = config.MaxIPFailures
+	if config.FailureWindow > 0 {
+		lockout.FailureWindow = config.FailureWindow
+	}
+	if config.Lockout > 0 {
+		lockout.BaseLockout = config.Lockout
+	}
+	if config.MaxLockout > 0 {
+		lockout.MaxLockout = config.MaxLockout
+	}
+
+	logger.Info("Login lockout enabled",
+		zap.Int("max_user_failures", lockout.MaxUserFailures),
+		zap.Int("max_ip_failures", lockout.MaxIPFailures),
+		zap.Duration("failure_window", lockout.FailureWindow),
+		zap.Duration("lockout", lockout.BaseLockout),
+		zap.Duration("max_lockout", lockout.MaxLockout),
+	)
+	return lockout
+}
+
+// initActivityMonitor configures operator activity anomaly detection
+func initActivityMonitor(
+	config ActivityConfig,
+	activityRepo repository.ActivityRepository,
+	userRepo repository.UserRepository,
+	eventBus *application.EventBus,
+	logger *zap.Logger,
+) *application.ActivityMonitor {
+	monitor := application.DefaultActivityMonitorConfig()
+
+	if config.BusinessHours != "" {
+		start, end, err := application.ParseBusinessHours(config.BusinessHours)
+		if err != nil {
+			logger.Warn("Invalid ACTIVITY_BUSINESS_HOURS, using default", zap.Error(err))
+		} else {
+			monitor.BusinessHoursStart, monitor.BusinessHoursEnd = start, end
+		}
+	}
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			logger.Warn("Invalid ACTIVITY_TIMEZONE, using local time", zap.Error(err))
+		} else {
+			monitor.Location = loc
+		}
+	}
+	monitor.MassDeletionThreshold = config.MassDeleteThreshold
+	if config.MassDeleteWindow > 0 {
+		monitor.MassDeletionWindow = config.MassDeleteWindow
+	}
+	monitor.CountryHeader = config.CountryHeader
+
+	logger.Info("Activity anomaly detection enabled",
+		zap.Int("business_hours_start", monitor.BusinessHoursStart),
+		zap.Int("business_hours_end", monitor.BusinessHoursEnd),
+		zap.String("timezone", monitor.Location.String()),
+		zap.Int("mass_delete_threshold", monitor.MassDeletionThreshold),
+		zap.String("country_header", monitor.CountryHeader),
+	)
+
+	return application.NewActivityMonitor(activityRepo, userRepo, eventBus, monitor, logger)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"autostrike/internal/application"
+
+	"go.uber.org/zap"
+)
+
+// startMaintenance starts the background jobs checking the stale agents, the
+// expired queued tasks and the stuck executions, and purging the result
+// submissions, trash, agent history and notifications kept past their retention
+func startMaintenance(
+	agentService *application.AgentService,
+	executionService *application.ExecutionService,
+	trashService *application.TrashService,
+	notificationService *application.NotificationService,
+	agentStaleTimeout time.Duration,
+	logger *zap.Logger,
+) {
+	// Start background job to clean up stale agents and the disconnected agents
+	// past their grace period (every 15 seconds)
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx := context.Background()
+			if err := agentService.CheckStaleAgents(ctx, agentStaleTimeout); err != nil {
+				logger.Warn("Failed to check stale agents", zap.Error(err))
+			}
+		}
+	}()
+
+	// Fail the tasks queued for agents that did not check in before they expired
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n := executionService.ExpireQueuedTasks(context.Background()); n > 0 {
+				logger.Info("Expired queued tasks", zap.Int("tasks", n))
+			}
+		}
+	}()
+
+	// Fail the executions stuck running, e.g. after their agent vanished
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := executionService.FailStaleExecutions(context.Background(), time.Now())
+			if err != nil {
+				logger.Warn("Failed to check stale executions", zap.Error(err))
+			}
+			if n > 0 {
+				logger.Warn("Failed stale executions", zap.Int("executions", n))
+			}
+		}
+	}()
+
+	// Delete the idempotency keys of the result submissions kept past their retention
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := executionService.PurgeSubmissions(context.Background(), time.Now()); err != nil {
+				logger.Warn("Failed to purge result submissions", zap.Error(err))
+			}
+		}
+	}()
+
+	// Permanently delete the scenarios and techniques kept in the trash past its retention
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, _, err := trashService.Purge(context.Background(), time.Now()); err != nil {
+				logger.Warn("Failed to purge the trash", zap.Error(err))
+			}
+		}
+	}()
+
+	// Delete the agent availability and check-in history kept past its retention
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := agentService.PurgeAvailability(context.Background(), time.Now()); err != nil {
+				logger.Warn("Failed to purge the agent availability history", zap.Error(err))
+			}
+			if _, err := agentService.PurgeBeacons(context.Background(), time.Now()); err != nil {
+				logger.Warn("Failed to purge the agent check-in history", zap.Error(err))
+			}
+		}
+	}()
+
+	// Delete the read notifications kept past their retention
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := notificationService.PurgeRead(context.Background(), time.Now()); err != nil {
+				logger.Warn("Failed to purge notifications", zap.Error(err))
+			}
+		}
+	}()
+}
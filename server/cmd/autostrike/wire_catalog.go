@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/infrastructure/cache"
+	"autostrike/internal/infrastructure/content"
+
+	"go.uber.org/zap"
+)
+
+// autoApplyContent plans the content of ./configs against the database and
+// logs each change. The changes are applied unless content.auto_apply is
+// false; nothing is ever deleted at startup.
+func autoApplyContent(
+	config ContentConfig,
+	service *application.ContentPlanService,
+	reload *application.ContentReloadService,
+	logger *zap.Logger,
+) {
+	source, err := application.LoadContentDir("./configs")
+	if err != nil {
+		logger.Warn("Failed to load content", zap.Error(err))
+		return
+	}
+
+	ctx := context.Background()
+	plan, err := service.Plan(ctx, source, false)
+	if err != nil {
+		logger.Warn("Failed to plan content", zap.Error(err))
+		return
+	}
+	for _, message := range plan.Errors {
+		logger.Warn("Invalid content skipped", zap.String("error", message))
+	}
+	if len(plan.Changes) == 0 {
+		logger.Info("Content up to date", zap.Int("unchanged", plan.Unchanged))
+		markContentLoaded(reload, logger)
+		return
+	}
+	for _, change := range plan.Changes {
+		logger.Info("Content change planned",
+			zap.String("kind", change.Kind),
+			zap.String("id", change.ID),
+			zap.String("action", string(change.Action)),
+			zap.Strings("fields", change.Fields),
+		)
+	}
+
+	if !config.AutoApply {
+		logger.Warn("Content changes not applied, review and apply them with autostrikectl content apply",
+			zap.String("plan", plan.Summary()))
+		return
+	}
+	result, err := service.Apply(ctx, source, false, plan.Checksum)
+	if err != nil {
+		logger.Warn("Failed to apply content", zap.Error(err))
+		return
+	}
+	for _, message := range result.Errors {
+		logger.Warn("Content change failed", zap.String("error", message))
+	}
+	logger.Info("Applied content", zap.Int("applied", result.Applied), zap.String("plan", plan.Summary()))
+	if len(result.Errors) == 0 {
+		markContentLoaded(reload, logger)
+	}
+}
+
+// markContentLoaded records the content files as loaded, so that runtime
+// reloads only apply the files changed since startup
+func markContentLoaded(reload *application.ContentReloadService, logger *zap.Logger) {
+	if err := reload.MarkLoaded(); err != nil {
+		logger.Warn("Failed to record the loaded content", zap.Error(err))
+	}
+}
+
+// startContentWatcher reloads the changed content files at runtime, unless
+// CONTENT_WATCH=false or the content is not applied automatically. Returns
+// nil when the watcher is not started.
+func startContentWatcher(config ContentConfig, reload *application.ContentReloadService, logger *zap.Logger) *content.Watcher {
+	if !config.Watch || !config.AutoApply {
+		return nil
+	}
+	watcher := content.NewWatcher(reload, content.DefaultReloadDelay, logger)
+	if err := watcher.Start(); err != nil {
+		logger.Warn("Failed to watch the content directory", zap.String("dir", reload.Dir()), zap.Error(err))
+		return nil
+	}
+	logger.Info("Watching content for changes", zap.String("dir", reload.Dir()))
+	return watcher
+}
+
+// initTechniqueSyncService creates the technique catalog sync, merging the
+// ATT&CK STIX bundles of MITRE_SYNC_DOMAINS (comma-separated, enterprise-attack
+// by default) with the Atomic Red Team tests. MITRE_SYNC_STIX_URL, where
+// {domain} stands for each domain, and MITRE_SYNC_ATOMICS_URL point the sync
+// at a mirror instead of GitHub.
+func initTechniqueSyncService(
+	config CatalogConfig,
+	imports *application.ContentImportService,
+	jobs *application.JobService,
+	logger *zap.Logger,
+) *application.TechniqueSyncService {
+	syncConfig := application.TechniqueSyncConfig{
+		STIXURL:    config.MITRESync.STIXURL,
+		AtomicsURL: config.MITRESync.AtomicsURL,
+	}
+	for _, domain := range config.MITRESync.Domains {
+		if !entity.IsValidDomain(domain) {
+			logger.Warn("Unknown MITRE_SYNC_DOMAINS domain, ignored", zap.String("domain", domain))
+			continue
+		}
+		syncConfig.Domains = append(syncConfig.Domains, domain)
+	}
+	service := application.NewTechniqueSyncService(imports, content.NewHTTPFetcher(content.MaxFetchSize), syncConfig, logger)
+	service.SetJobService(jobs)
+	logger.Info("Technique sync configured", zap.Int("sources", len(service.Sources())))
+	return service
+}
+
+// initConfigBundleService creates the configuration bundle service. With
+// BUNDLE_SIGNING_KEY set, exported bundles are signed with it and only
+// bundles signed with it are imported.
+func initConfigBundleService(
+	config CatalogConfig,
+	techniqueRepo repository.TechniqueRepository,
+	scenarioRepo repository.ScenarioRepository,
+	selectorRepo repository.AgentSelectorRepository,
+	scheduleRepo repository.ScheduleRepository,
+	beaconRepo repository.BeaconOverrideRepository,
+	logger *zap.Logger,
+) *application.ConfigBundleService {
+	bundleService := application.NewConfigBundleService(techniqueRepo, scenarioRepo, selectorRepo, scheduleRepo, beaconRepo, logger)
+	if config.BundleSigningKey != "" {
+		bundleService.SetSigningKey([]byte(config.BundleSigningKey))
+	} else {
+		logger.Info("BUNDLE_SIGNING_KEY not set, configuration bundles are not signed")
+	}
+	return bundleService
+}
+
+// initCatalogInvalidation shares the invalidations of the technique and
+// scenario caches with the other instances of the deployment through the Redis
+// channel of CATALOG_CACHE_REDIS_URL, or returns nil when it is not set: the
+// caches then see the writes of this instance only
+func initCatalogInvalidation(
+	config CatalogConfig,
+	techniques *cache.TechniqueCache,
+	scenarios *cache.ScenarioCache,
+	logger *zap.Logger,
+) *cache.RedisInvalidator {
+	if config.Cache.RedisURL == "" {
+		return nil
+	}
+	invalidator, err := cache.NewRedisInvalidator(cache.RedisConfig{
+		URL:     config.Cache.RedisURL,
+		Channel: config.Cache.RedisChannel,
+	}, logger)
+	if err != nil {
+		logger.Warn("Invalid CATALOG_CACHE_REDIS_URL, catalog caches are not shared", zap.Error(err))
+		return nil
+	}
+	invalidator.Register(cache.CatalogTechniques, techniques)
+	invalidator.Register(cache.CatalogScenarios, scenarios)
+	techniques.SetNotifier(invalidator)
+	scenarios.SetNotifier(invalidator)
+	return invalidator
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/infrastructure/persistence/sqlite"
+	"autostrike/internal/infrastructure/websocket"
+
+	"go.uber.org/zap"
+)
+
+// initTaskQueue queues the tasks dispatched to offline agents until they check
+// in, for TASK_QUEUE_TTL (24h by default). TASK_QUEUE_TTL=0 disables the queue:
+// executions then require online agents and fail the tasks they cannot send.
+func initTaskQueue(
+	config ExecutionConfig,
+	executionService *application.ExecutionService,
+	queueRepo repository.TaskQueueRepository,
+	logger *zap.Logger,
+) {
+	if config.TaskQueueTTL == 0 {
+		logger.Info("Task queue disabled, tasks for offline agents fail")
+		return
+	}
+	executionService.SetTaskQueue(queueRepo, config.TaskQueueTTL)
+}
+
+// initResultQueue writes the results agents report through a queue of
+// RESULT_QUEUE_SIZE results (5000 by default), stored RESULT_QUEUE_BATCH
+// (500) at a time by a single writer. Agents resend the results refused
+// while it is full. RESULT_QUEUE_SIZE=0 disables the queue: results are then
+// written as they arrive.
+func initResultQueue(config ExecutionConfig, executionService *application.ExecutionService, logger *zap.Logger) *application.ResultQueue {
+	if config.ResultQueueSize == 0 {
+		logger.Info("Result queue disabled, results are written as they arrive")
+		return nil
+	}
+	return application.NewResultQueue(executionService, application.ResultQueueConfig{
+		Size:      config.ResultQueueSize,
+		BatchSize: config.ResultQueueBatch,
+	}, logger)
+}
+
+// initStaleExecutionTimeout fails the running executions without activity for
+// EXECUTION_STALE_TIMEOUT (6h by default). EXECUTION_STALE_TIMEOUT=0 disables
+// the watchdog.
+func initStaleExecutionTimeout(config ExecutionConfig, executionService *application.ExecutionService, logger *zap.Logger) {
+	if config.StaleTimeout == 0 {
+		logger.Info("Stale execution watchdog disabled")
+	}
+	executionService.SetStaleExecutionTimeout(config.StaleTimeout)
+}
+
+// initExecutionQuota limits the executions started per EXECUTION_QUOTA_WINDOW
+// (1h by default): by a user to EXECUTION_QUOTA_PER_USER, with an API key to
+// EXECUTION_QUOTA_PER_API_KEY, by the users of a workspace to
+// EXECUTION_QUOTA_PER_WORKSPACE, and on the server, by users and schedules, to
+// EXECUTION_QUOTA_TOTAL. Each is unlimited when unset.
+func initExecutionQuota(config ExecutionConfig, executionService *application.ExecutionService, logger *zap.Logger) {
+	quota := application.ExecutionQuotaConfig{
+		PerUser:      config.Quota.PerUser,
+		PerAPIKey:    config.Quota.PerAPIKey,
+		PerWorkspace: config.Quota.PerWorkspace,
+		Total:        config.Quota.Total,
+		Window:       config.Quota.Window,
+	}
+	if quota.PerUser == 0 && quota.PerAPIKey == 0 && quota.PerWorkspace == 0 && quota.Total == 0 {
+		return
+	}
+	if quota.Window == 0 {
+		quota.Window = application.DefaultExecutionQuotaWindow
+	}
+	executionService.SetExecutionQuota(application.NewExecutionQuota(quota))
+	logger.Info("Execution quota enabled",
+		zap.Int("per_user", quota.PerUser), zap.Int("per_api_key", quota.PerAPIKey),
+		zap.Int("per_workspace", quota.PerWorkspace), zap.Int("total", quota.Total),
+		zap.Duration("window", quota.Window))
+}
+
+// initJobService creates the background job workers, JOB_WORKERS (2 by
+// default) running at the same time
+func initJobService(config ExecutionConfig, repo repository.JobRepository, logger *zap.Logger) *application.JobService {
+	workers := application.DefaultJobWorkers
+	if config.JobWorkers > 0 {
+		workers = config.JobWorkers
+	}
+	return application.NewJobService(repo, workers, logger)
+}
+
+// recoverExecutions interrupts the executions left pending or running by a
+// crash and, when RESUME_INTERRUPTED_EXECUTIONS is true, resumes the interrupted
+// executions. Tasks whose agent has not reconnected within RESUME_AGENT_GRACE
+// (10m by default) are failed so their executions complete.
+func recoverExecutions(config ExecutionConfig, executionService *application.ExecutionService, logger *zap.Logger) {
+	stuck, resumed, err := executionService.RecoverExecutions(context.Background(), config.ResumeOnStart)
+	if err != nil {
+		logger.Error("Failed to recover executions", zap.Error(err))
+	}
+	if stuck > 0 {
+		logger.Warn("Interrupted executions left running by the previous run", zap.Int("count", stuck))
+	}
+	if resumed == 0 {
+		return
+	}
+
+	grace := 10 * time.Minute
+	if config.ResumeAgentGrace > 0 {
+		grace = config.ResumeAgentGrace
+	}
+	logger.Info("Resumed interrupted executions, waiting for their agents",
+		zap.Int("count", resumed), zap.Duration("grace", grace))
+
+	time.AfterFunc(grace, func() {
+		if n := executionService.ExpireResumedTasks(context.Background()); n > 0 {
+			logger.Warn("Failed resumed tasks whose agent did not reconnect", zap.Int("tasks", n))
+		}
+	})
+}
+
+// drainExecutions waits up to SHUTDOWN_DRAIN_TIMEOUT (30s by default) for running
+// executions to finish, marks the remaining ones as interrupted and tells the
+// connected agents the server is going away
+func drainExecutions(config *Config, executionService *application.ExecutionService, hub *websocket.Hub, logger *zap.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownDrainTimeout)
+	defer cancel()
+	if err := executionService.DrainExecutions(ctx, time.Second); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		logger.Warn("Failed to drain executions", zap.Error(err))
+	}
+
+	interrupted, err := executionService.InterruptRunningExecutions(context.Background())
+	if err != nil {
+		logger.Error("Failed to interrupt running executions", zap.Error(err))
+	}
+	if len(interrupted) > 0 {
+		logger.Info("Interrupted in-flight executions", zap.Int("count", len(interrupted)))
+	}
+
+	message, _ := json.Marshal(map[string]interface{}{
+		"type": "server_shutdown",
+		"payload": map[string]interface{}{
+			"interrupted_executions": len(interrupted),
+			"resumable":              config.Execution.ResumeOnStart,
+		},
+	})
+	if hub.NotifyAgents(message) > 0 {
+		// Let the write pumps flush the notification before exiting
+		time.Sleep(time.Second)
+	}
+}
+
+// initEmergencyStop creates the emergency stop, engaged again when it was not
+// re-armed before the last shutdown. The database is checked every
+// EMERGENCY_STOP_DB_CHECK_INTERVAL (10s by default, 0 disables the watch) and
+// the stop trips after EMERGENCY_STOP_DB_FAILURES (3) consecutive failures.
+func initEmergencyStop(
+	config ExecutionConfig,
+	db *sql.DB,
+	executionService *application.ExecutionService,
+	scheduleService *application.ScheduleService,
+	adhocTaskService *application.AdHocTaskService,
+	hub *websocket.Hub,
+	logger *zap.Logger,
+) *application.EmergencyStopService {
+	stop := application.NewEmergencyStopService(
+		sqlite.NewEmergencyStopRepository(db), executionService, scheduleService, hub, logger)
+	if err := stop.Load(context.Background()); err != nil {
+		logger.Error("Failed to load the emergency stop", zap.Error(err))
+	}
+	if engaged := stop.Status(); engaged != nil {
+		logger.Warn("Emergency stop engaged, re-arm it to resume activity",
+			zap.String("reason", engaged.Reason), zap.Time("tripped_at", engaged.TrippedAt))
+	}
+	executionService.SetEmergencyStop(stop)
+	adhocTaskService.SetEmergencyStop(stop)
+
+	stop.WatchDatabase(func(ctx context.Context) error {
+		return sqlite.Ping(ctx, db)
+	}, config.EmergencyStop.DBCheckInterval, config.EmergencyStop.DBFailures)
+	return stop
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/domain/service"
+	"autostrike/internal/infrastructure/edr"
+	"autostrike/internal/infrastructure/siem"
+	"autostrike/internal/infrastructure/ticketing"
+
+	"go.uber.org/zap"
+)
+
+// initDetectionService initializes SIEM/EDR detection verification with the configured connectors
+func initDetectionService(
+	config DetectionConfig,
+	resultRepo repository.ResultRepository,
+	agentRepo repository.AgentRepository,
+	techniqueRepo repository.TechniqueRepository,
+	calculator *service.ScoreCalculator,
+	logger *zap.Logger,
+) *application.DetectionService {
+	var connectors []application.SIEMConnector
+
+	if config.Splunk.URL != "" {
+		connectors = append(connectors, siem.NewSplunkConnector(siem.SplunkConfig{
+			URL:    config.Splunk.URL,
+			Token:  config.Splunk.Token,
+			Search: config.Splunk.Search,
+		}))
+	}
+	if config.Elastic.URL != "" {
+		connectors = append(connectors, siem.NewElasticConnector(siem.ElasticConfig{
+			URL:    config.Elastic.URL,
+			APIKey: config.Elastic.APIKey,
+			Index:  config.Elastic.AlertsIndex,
+		}))
+	}
+	if config.Sentinel.WorkspaceID != "" {
+		connectors = append(connectors, siem.NewSentinelConnector(siem.SentinelConfig{
+			WorkspaceID:  config.Sentinel.WorkspaceID,
+			TenantID:     config.Sentinel.TenantID,
+			ClientID:     config.Sentinel.ClientID,
+			ClientSecret: config.Sentinel.ClientSecret,
+		}))
+	}
+
+	var edrConnectors []application.EDRConnector
+
+	if config.CrowdStrike.ClientID != "" {
+		edrConnectors = append(edrConnectors, edr.NewCrowdStrikeConnector(edr.CrowdStrikeConfig{
+			URL:          config.CrowdStrike.URL,
+			ClientID:     config.CrowdStrike.ClientID,
+			ClientSecret: config.CrowdStrike.ClientSecret,
+		}))
+	}
+	if config.Defender.TenantID != "" {
+		edrConnectors = append(edrConnectors, edr.NewDefenderConnector(edr.DefenderConfig{
+			TenantID:     config.Defender.TenantID,
+			ClientID:     config.Defender.ClientID,
+			ClientSecret: config.Defender.ClientSecret,
+		}))
+	}
+	if config.SentinelOne.URL != "" {
+		edrConnectors = append(edrConnectors, edr.NewSentinelOneConnector(edr.SentinelOneConfig{
+			URL:      config.SentinelOne.URL,
+			APIToken: config.SentinelOne.APIToken,
+		}))
+	}
+
+	detectionConfig := application.DefaultDetectionConfig()
+	detectionConfig.Delay = config.SIEM.QueryDelay
+	if config.SIEM.QueryWindow > 0 {
+		detectionConfig.WindowPost = config.SIEM.QueryWindow
+	}
+
+	detectionService := application.NewDetectionService(
+		resultRepo, agentRepo, techniqueRepo, calculator, connectors, edrConnectors, detectionConfig, logger,
+	)
+	if detectionService.Enabled() {
+		logger.Info("Detection verification enabled",
+			zap.Strings("siem_connectors", detectionService.Connectors()),
+			zap.Strings("edr_connectors", detectionService.EDRConnectors()),
+			zap.Duration("delay", detectionConfig.Delay),
+			zap.Duration("window", detectionConfig.WindowPost),
+		)
+	} else {
+		logger.Info("No SIEM or EDR configured - detection relies on manual input")
+	}
+
+	return detectionService
+}
+
+// initTicketService opens Jira or ServiceNow issues for undetected techniques,
+// with the configured tracker. It returns nil without tracker.
+func initTicketService(
+	config *Config,
+	ticketRepo repository.TicketRepository,
+	resultRepo repository.ResultRepository,
+	techniqueRepo repository.TechniqueRepository,
+	agentRepo repository.AgentRepository,
+	scenarioRepo repository.ScenarioRepository,
+	detectionService *application.DetectionService,
+	logger *zap.Logger,
+) *application.TicketService {
+	settings := config.Ticketing
+	var connector application.TicketConnector
+	if jira := settings.Jira; jira.URL != "" {
+		connector = ticketing.NewJiraConnector(ticketing.JiraConfig{
+			URL:               jira.URL,
+			Email:             jira.Email,
+			APIToken:          jira.APIToken,
+			Project:           jira.Project,
+			IssueType:         jira.IssueType,
+			ResolveTransition: jira.ResolveTransition,
+		})
+	} else if serviceNow := settings.ServiceNow; serviceNow.URL != "" {
+		connector = ticketing.NewServiceNowConnector(ticketing.ServiceNowConfig{
+			URL:             serviceNow.URL,
+			Username:        serviceNow.Username,
+			Password:        serviceNow.Password,
+			AssignmentGroup: serviceNow.AssignmentGroup,
+			CloseCode:       serviceNow.CloseCode,
+		})
+	}
+	if connector == nil {
+		return nil
+	}
+
+	ticketConfig := application.DefaultTicketConfig()
+	ticketConfig.DashboardURL = config.Server.DashboardURL
+	// Leave detection correlation time to mark results detected before opening tickets
+	if detectionService.Enabled() {
+		ticketConfig.Delay = detectionService.VerificationDelay() + 3*time.Minute
+	}
+	if settings.Ticket.Delay != nil {
+		ticketConfig.Delay = *settings.Ticket.Delay
+	}
+	if settings.Ticket.SyncInterval > 0 {
+		ticketConfig.SyncInterval = settings.Ticket.SyncInterval
+	}
+	if len(settings.Ticket.Labels) > 0 {
+		ticketConfig.Labels = settings.Ticket.Labels
+	}
+
+	ticketService := application.NewTicketService(
+		ticketRepo, resultRepo, techniqueRepo, agentRepo, scenarioRepo, connector, ticketConfig, logger,
+	)
+	var summary, description string
+	if path := settings.Ticket.SummaryTemplateFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warn("Failed to read TICKET_SUMMARY_TEMPLATE_FILE, using default", zap.Error(err))
+		}
+		summary = string(data)
+	}
+	if path := settings.Ticket.DescriptionTemplateFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Warn("Failed to read TICKET_DESCRIPTION_TEMPLATE_FILE, using default", zap.Error(err))
+		}
+		description = string(data)
+	}
+	if err := ticketService.SetTemplates(summary, description); err != nil {
+		logger.Warn("Invalid ticket template, using default", zap.Error(err))
+	}
+
+	logger.Info("Ticketing enabled",
+		zap.String("provider", connector.Name()),
+		zap.Duration("delay", ticketConfig.Delay),
+		zap.Duration("sync_interval", ticketConfig.SyncInterval),
+	)
+	return ticketService
+}
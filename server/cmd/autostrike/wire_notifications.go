@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/infrastructure/siem"
+	"autostrike/internal/infrastructure/stream"
+
+	"go.uber.org/zap"
+)
+
+// initNotificationService initializes the notification service with the SMTP configuration,
+// overridden by the one set through the API when the secrets store seals its password.
+func initNotificationService(
+	config *Config,
+	notificationRepo repository.NotificationRepository,
+	smtpConfigRepo repository.SMTPConfigRepository,
+	userRepo repository.UserRepository,
+	secretService *application.SecretService,
+	logger *zap.Logger,
+) *application.NotificationService {
+	settings := config.Notify.SMTP
+	dashboardURL := config.Server.DashboardURL
+	if dashboardURL == "" {
+		dashboardURL = "https://localhost:8443"
+	}
+
+	var smtpConfig *entity.SMTPConfig
+	if settings.Host != "" {
+		smtpConfig = &entity.SMTPConfig{
+			Host:     settings.Host,
+			Port:     settings.Port,
+			Username: settings.Username,
+			Password: settings.Password,
+			From:     settings.From,
+			UseTLS:   settings.UseTLS,
+			Source:   entity.SMTPConfigFromEnv,
+		}
+
+		if smtpConfig.IsValid() {
+			logger.Info("SMTP configuration loaded",
+				zap.String("host", settings.Host),
+				zap.Int("port", settings.Port),
+				zap.String("from", settings.From),
+				zap.Bool("use_tls", settings.UseTLS),
+			)
+		} else {
+			logger.Warn("SMTP configuration incomplete - email notifications disabled")
+			smtpConfig = nil
+		}
+	} else {
+		logger.Info("SMTP not configured - email notifications disabled")
+	}
+
+	notificationService := application.NewNotificationService(notificationRepo, userRepo, smtpConfig, dashboardURL, logger)
+	if secretService != nil {
+		notificationService.SetSMTPConfigStore(smtpConfigRepo, secretService)
+		if err := notificationService.LoadSMTPConfig(context.Background()); err != nil {
+			logger.Error("Failed to load the stored SMTP configuration, using the environment", zap.Error(err))
+		} else if stored := notificationService.GetSMTPConfig(); stored != nil && stored.Source == entity.SMTPConfigFromAPI {
+			logger.Info("Stored SMTP configuration loaded", zap.String("host", stored.Host), zap.Int("port", stored.Port))
+		}
+	} else {
+		logger.Info("Secrets store not configured, SMTP is only configured by the environment")
+	}
+	// Read notifications are purged after NOTIFICATION_RETENTION, 0 keeps them
+	notificationService.SetRetention(config.Notify.Notifications.Retention)
+	return notificationService
+}
+
+// webhookVerbosity returns the detail of the results sent to webhooks,
+// minimal by default
+func webhookVerbosity(config NotifyConfig, logger *zap.Logger) application.ResultVerbosity {
+	verbosity, err := application.ParseResultVerbosity(config.Notifications.WebhookVerbosity)
+	if err != nil {
+		logger.Warn("Invalid WEBHOOK_VERBOSITY, using minimal", zap.Error(err))
+		return application.VerbosityMinimal
+	}
+	return verbosity
+}
+
+// initEventBus creates the event bus with the notification sink, streams
+// events to EVENT_WEBHOOK_URL and exports results to SYSLOG_ADDR when they are
+// set. Returns the EVENT_WEBHOOK_URL sink too, nil when not set.
+func initEventBus(config NotifyConfig, notificationService *application.NotificationService, logger *zap.Logger) (*application.EventBus, *application.WebhookEventSink) {
+	eventBus := application.NewEventBus(logger)
+	eventBus.AddSink(notificationService)
+
+	var eventWebhook *application.WebhookEventSink
+	if url := config.EventWebhook.URL; url != "" {
+		types, err := application.ParseEventTypes(strings.Join(config.EventWebhook.Events, ","))
+		if err != nil {
+			logger.Warn("Invalid EVENT_WEBHOOK_EVENTS, streaming every event", zap.Error(err))
+			types = nil
+		}
+		eventWebhook = application.NewWebhookEventSink(url, config.EventWebhook.Secret, types, logger)
+		eventBus.AddSink(eventWebhook)
+	}
+
+	if addr := config.Syslog.Addr; addr != "" {
+		exporter, err := siem.NewSyslogExporter(siem.SyslogConfig{
+			Address:  addr,
+			Protocol: config.Syslog.Protocol,
+			Format:   config.Syslog.Format,
+		}, logger)
+		if err != nil {
+			logger.Warn("Invalid syslog configuration, results are not exported", zap.Error(err))
+		} else {
+			eventBus.AddSink(exporter)
+		}
+	}
+
+	logger.Info("Event bus initialized", zap.Strings("sinks", eventBus.Sinks()))
+	return eventBus, eventWebhook
+}
+
+// initStreamSink creates the Kafka or NATS event stream from the stream.*
+// configuration (config.yaml or STREAM_* variables), or returns nil when
+// stream.driver is not set
+func initStreamSink(config StreamConfig, logger *zap.Logger) *stream.Sink {
+	if config.Driver == "" {
+		return nil
+	}
+
+	events, err := application.ParseEventTypes(strings.Join(config.Events, ","))
+	if err != nil {
+		logger.Warn("Invalid stream.events, streaming every event", zap.Error(err))
+		events = nil
+	}
+	streamConfig := stream.Config{
+		Driver:        config.Driver,
+		TopicPrefix:   config.TopicPrefix,
+		Events:        events,
+		BatchSize:     config.BatchSize,
+		BufferSize:    config.BufferSize,
+		FlushInterval: config.FlushInterval,
+		Kafka: stream.KafkaConfig{
+			RESTURL:  config.Kafka.RESTURL,
+			Username: config.Kafka.Username,
+			Password: config.Kafka.Password,
+		},
+		NATS: stream.NATSConfig{
+			URL:      config.NATS.URL,
+			Token:    config.NATS.Token,
+			Username: config.NATS.Username,
+			Password: config.NATS.Password,
+		},
+	}
+
+	publisher, err := stream.NewPublisher(streamConfig)
+	if err != nil {
+		logger.Warn("Invalid stream configuration, events are not streamed", zap.Error(err))
+		return nil
+	}
+	return stream.NewSink(publisher, streamConfig, logger)
+}
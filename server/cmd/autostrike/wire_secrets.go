@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/infrastructure/integration"
+	"autostrike/internal/infrastructure/secrets"
+
+	"go.uber.org/zap"
+)
+
+// initSecretService enables the secrets store with the master key of AWS KMS
+// when secrets.kms_key_id is set, or else of secrets.master_key. Returns nil
+// when neither is set.
+func initSecretService(config *Config, repo repository.SecretRepository, logger *zap.Logger) *application.SecretService {
+	var key application.MasterKey
+	if keyID := config.Secrets.KMSKeyID; keyID != "" {
+		kmsKey, err := secrets.NewKMSKey(secrets.KMSConfig{
+			KeyID:    keyID,
+			Region:   config.AWS.Region,
+			Endpoint: config.Secrets.KMSEndpoint,
+			Credentials: integration.AWSCredentials{
+				AccessKeyID:     config.AWS.AccessKeyID,
+				SecretAccessKey: config.AWS.SecretAccessKey,
+				SessionToken:    config.AWS.SessionToken,
+			},
+		})
+		if err != nil {
+			logger.Fatal("Invalid secrets KMS configuration", zap.Error(err))
+		}
+		key = kmsKey
+	} else if passphrase := config.Secrets.MasterKey; passphrase != "" {
+		localKey, err := application.NewLocalMasterKey(passphrase)
+		if err != nil {
+			logger.Fatal("Invalid SECRETS_MASTER_KEY", zap.Error(err))
+		}
+		key = localKey
+	} else {
+		logger.Info("SECRETS_MASTER_KEY and SECRETS_KMS_KEY_ID not set, the secrets store is disabled")
+		return nil
+	}
+
+	logger.Info("Secrets store enabled", zap.String("master_key", key.ID()))
+	return application.NewSecretService(repo, key)
+}
+
+// vaultConfig converts the vault.* configuration (config.yaml or VAULT_*
+// variables, VAULT_SECRETS being a JSON object), reporting false when no
+// Vault address is set
+func vaultConfig(config VaultConfig) (secrets.VaultConfig, bool) {
+	// Viper lowercases the keys of config.yaml, which name environment variables
+	mapped := make(map[string]string)
+	for name, ref := range config.Secrets {
+		mapped[strings.ToUpper(name)] = ref
+	}
+	return secrets.VaultConfig{
+		Address:         config.Address,
+		Namespace:       config.Namespace,
+		Token:           config.Token,
+		RoleID:          config.RoleID,
+		SecretID:        config.SecretID,
+		AuthMount:       config.AuthMount,
+		Secrets:         mapped,
+		RefreshInterval: config.RefreshInterval,
+	}, config.Address != ""
+}
+
+// initVault sets the settings mapped by vault.secrets to their value in
+// Vault. Returns nil when Vault is not configured; the server does not start
+// when a secret cannot be read.
+func initVault(config *Config, logger *zap.Logger) *secrets.Vault {
+	vaultSettings, ok := vaultConfig(config.Vault)
+	if !ok {
+		return nil
+	}
+	vault, err := secrets.NewVault(vaultSettings, logger)
+	if err != nil {
+		logger.Fatal("Invalid Vault configuration", zap.Error(err))
+	}
+	values, err := vault.Load(context.Background())
+	if err != nil {
+		logger.Fatal("Failed to read secrets from Vault", zap.Error(err))
+	}
+	for name, value := range values {
+		setVaultSecret(config, name, value)
+	}
+	logger.Info("Secrets read from Vault", zap.String("address", vaultSettings.Address), zap.Int("secrets", len(values)))
+	return vault
+}
+
+// setVaultSecret sets the setting read from the environment variable name,
+// or else the variable itself, for the libraries reading the environment
+func setVaultSecret(config *Config, name, value string) {
+	if !config.setEnv(name, value) {
+		_ = os.Setenv(name, value)
+	}
+}
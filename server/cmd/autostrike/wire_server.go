@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/api/rest"
+	"autostrike/internal/infrastructure/cache"
+	"autostrike/internal/infrastructure/persistence/sqlite"
+	"autostrike/internal/infrastructure/websocket"
+
+	"go.uber.org/zap"
+)
+
+// serverConfig returns the configuration of the REST server. Authentication
+// is enabled when a JWT secret is set, unless ENABLE_AUTH says otherwise.
+func serverConfig(config *Config) *rest.ServerConfig {
+	enableAuth := config.Auth.JWTSecret != ""
+	if config.Auth.Enabled != nil {
+		enableAuth = *config.Auth.Enabled
+	}
+	return &rest.ServerConfig{
+		JWTSecret:            config.Auth.JWTSecret,
+		AgentSecret:          config.Agent.Secret,
+		EnableAuth:           enableAuth,
+		DashboardPath:        config.Server.DashboardPath,
+		AgentAllowedNetworks: config.Agent.AllowedNetworks,
+		APIAllowedNetworks:   config.Server.APIAllowedNetworks,
+		TrustedProxies:       config.Server.TrustedProxies,
+	}
+}
+
+// databaseConfig returns the connection pool and the pragmas of the database
+func databaseConfig(config DatabaseConfig) sqlite.Config {
+	return sqlite.Config{
+		Path:            config.Path,
+		MaxOpenConns:    config.MaxOpenConns,
+		MaxIdleConns:    config.MaxIdleConns,
+		ConnMaxLifetime: config.ConnMaxLifetime,
+		BusyTimeout:     config.BusyTimeout,
+		JournalMode:     config.JournalMode,
+		Synchronous:     config.Synchronous,
+	}
+}
+
+// tlsConfig converts the security.tls.* configuration (config.yaml or
+// SECURITY_TLS_* variables, SECURITY_TLS_ACME_DOMAINS being comma-separated)
+func tlsConfig(config TLSConfig) rest.TLSConfig {
+	return rest.TLSConfig{
+		CertFile:         config.CertFile,
+		KeyFile:          config.KeyFile,
+		ACMEDomains:      config.ACME.Domains,
+		ACMEEmail:        config.ACME.Email,
+		ACMECacheDir:     config.ACME.CacheDir,
+		ACMEDirectoryURL: config.ACME.DirectoryURL,
+	}
+}
+
+// initTLS creates the TLS configuration of the server, or returns nil when TLS
+// is terminated in front of it. Certificate files are reloaded when they
+// change and on SIGHUP.
+func initTLS(config TLSConfig, logger *zap.Logger) (*tls.Config, *rest.CertReloader) {
+	if !config.Enabled {
+		return nil, nil
+	}
+	serverTLS, reloader, err := rest.NewTLSConfig(tlsConfig(config), logger)
+	if err != nil {
+		logger.Fatal("Invalid TLS configuration", zap.Error(err))
+	}
+	if reloader == nil {
+		logger.Info("TLS certificates obtained with ACME", zap.Strings("domains", config.ACME.Domains))
+		return serverTLS, nil
+	}
+
+	if err := reloader.Start(); err != nil {
+		logger.Warn("Failed to watch the TLS certificate files, reload them with SIGHUP", zap.Error(err))
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := reloader.Reload(); err != nil {
+				logger.Error("Failed to reload TLS certificate, keeping the current one", zap.Error(err))
+			}
+		}
+	}()
+	return serverTLS, reloader
+}
+
+// initHealthService registers the component checks of the /healthz and /readyz probes
+func initHealthService(
+	db *sql.DB,
+	hub *websocket.Hub,
+	scheduleService *application.ScheduleService,
+	notificationService *application.NotificationService,
+	resultQueue *application.ResultQueue,
+	catalogInvalidator *cache.RedisInvalidator,
+) *application.HealthService {
+	health := application.NewHealthService()
+	health.AddLivenessCheck("scheduler", scheduleService.CheckHealth)
+	health.AddLivenessCheck("websocket_hub", hub.Ping)
+	health.AddReadinessCheck("database", func(ctx context.Context) error {
+		return sqlite.Ping(ctx, db)
+	}, true)
+	if notificationService.GetSMTPConfig() != nil {
+		health.AddReadinessCheck("smtp", notificationService.CheckSMTP, false)
+	}
+	if resultQueue != nil {
+		health.AddReadinessCheck("result_queue", resultQueue.CheckHealth, false)
+	}
+	if catalogInvalidator != nil {
+		health.AddReadinessCheck("catalog_cache", catalogInvalidator.CheckHealth, false)
+	}
+	return health
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/infrastructure/scan"
+	"autostrike/internal/infrastructure/storage"
+
+	"go.uber.org/zap"
+)
+
+// initPayloadService creates the payload store. Download URLs are signed with
+// JWT_SECRET, or AGENT_SECRET, or else a key generated at startup, and are valid
+// for PAYLOAD_URL_TTL (1h by default). Uploads are limited to PAYLOAD_MAX_SIZE
+// bytes and scanned by PAYLOAD_SCAN_COMMAND when set.
+func initPayloadService(
+	config *Config,
+	payloadRepo repository.PayloadRepository,
+	techniqueRepo repository.TechniqueRepository,
+	resultRepo repository.ResultRepository,
+	logger *zap.Logger,
+) *application.PayloadService {
+	secret := config.Auth.JWTSecret
+	if secret == "" {
+		secret = config.Agent.Secret
+	}
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			logger.Fatal("Failed to generate payload signing key", zap.Error(err))
+		}
+		secret = hex.EncodeToString(key)
+	}
+
+	settings := config.Storage.Payload
+	ttl := time.Hour
+	if settings.URLTTL > 0 {
+		ttl = settings.URLTTL
+	}
+
+	payloadService := application.NewPayloadService(payloadRepo, techniqueRepo, resultRepo, secret, ttl)
+	if settings.MaxSize > 0 {
+		payloadService.SetMaxSize(settings.MaxSize)
+	}
+	if settings.ScanCommand != "" {
+		scanner, err := scan.NewCommandScanner(settings.ScanCommand, 0)
+		if err != nil {
+			logger.Warn("Invalid PAYLOAD_SCAN_COMMAND, payloads are not scanned", zap.Error(err))
+		} else {
+			payloadService.SetScanner(scanner)
+			logger.Info("Payload malware scan enabled")
+		}
+	}
+	return payloadService
+}
+
+// initArtifactService creates the store of result artifacts, limited to
+// ARTIFACT_MAX_SIZE bytes per file and ARTIFACT_RESULT_QUOTA bytes per result,
+// and kept for ARTIFACT_RETENTION
+func initArtifactService(
+	config *Config,
+	artifactRepo repository.ArtifactRepository,
+	resultRepo repository.ResultRepository,
+	logger *zap.Logger,
+) *application.ArtifactService {
+	settings := config.Storage.Artifact
+	artifactConfig := application.DefaultArtifactConfig()
+	if settings.MaxSize > 0 {
+		artifactConfig.MaxSize = settings.MaxSize
+	}
+	if settings.ResultQuota > 0 {
+		artifactConfig.ResultQuota = settings.ResultQuota
+	}
+	if settings.Retention > 0 {
+		artifactConfig.Retention = settings.Retention
+	}
+	return application.NewArtifactService(artifactRepo, resultRepo, artifactConfig, logger)
+}
+
+// initEvidenceService creates the store of result evidence, limited to
+// EVIDENCE_MAX_SIZE bytes per file and EVIDENCE_RESULT_QUOTA bytes per result.
+// EVIDENCE_TYPES replaces the accepted content types, comma-separated.
+func initEvidenceService(
+	config *Config,
+	evidenceRepo repository.EvidenceRepository,
+	resultRepo repository.ResultRepository,
+) *application.EvidenceService {
+	settings := config.Storage.Evidence
+	evidenceConfig := application.DefaultEvidenceConfig()
+	if settings.MaxSize > 0 {
+		evidenceConfig.MaxSize = settings.MaxSize
+	}
+	if settings.ResultQuota > 0 {
+		evidenceConfig.ResultQuota = settings.ResultQuota
+	}
+	if len(settings.Types) > 0 {
+		evidenceConfig.AllowedTypes = settings.Types
+	}
+	return application.NewEvidenceService(evidenceRepo, resultRepo, evidenceConfig)
+}
+
+// initRetentionService creates the execution retention job: result outputs are
+// cleared after RESULT_OUTPUT_RETENTION and executions deleted after
+// EXECUTION_RETENTION, both unset by default, every night at
+// RETENTION_RUN_HOUR. Removed rows are archived to RETENTION_ARCHIVE_DIR or to
+// the RETENTION_ARCHIVE_S3_BUCKET bucket when set.
+func initRetentionService(
+	config *Config,
+	retentionRepo repository.RetentionRepository,
+	resultRepo repository.ResultRepository,
+	logger *zap.Logger,
+) *application.RetentionService {
+	settings := config.Storage.Retention
+	retentionConfig := application.DefaultRetentionConfig()
+	retentionConfig.OutputRetention = settings.Outputs
+	retentionConfig.ExecutionRetention = settings.Executions
+	if settings.RunHour < 24 {
+		retentionConfig.RunHour = settings.RunHour
+	} else {
+		logger.Warn("Invalid RETENTION_RUN_HOUR, using 3", zap.Int("value", settings.RunHour))
+	}
+	retentionService := application.NewRetentionService(retentionRepo, resultRepo, retentionConfig, logger)
+
+	// A misconfigured archive must not let the job delete rows unarchived
+	var store application.ArchiveStore
+	var err error
+	if settings.ArchiveDir != "" {
+		store, err = storage.NewDiskStore(settings.ArchiveDir)
+	} else if settings.ArchiveS3Bucket != "" {
+		store, err = storage.NewS3Store(s3Config(config, settings.ArchiveS3Bucket, settings.ArchiveS3Prefix))
+	}
+	if err != nil {
+		logger.Fatal("Invalid retention archive", zap.Error(err))
+	}
+	if store != nil {
+		retentionService.SetArchiveStore(store)
+	}
+
+	if retentionService.Enabled() {
+		logger.Info("Execution retention enabled",
+			zap.Duration("output_retention", retentionConfig.OutputRetention),
+			zap.Duration("execution_retention", retentionConfig.ExecutionRetention),
+			zap.Bool("archive", store != nil),
+		)
+	}
+	return retentionService
+}
+
+// s3Config returns the configuration of an S3 bucket, with the endpoint and
+// credentials shared by every bucket: S3_ENDPOINT, AWS_REGION, the AWS_* keys
+// and S3_PATH_STYLE
+func s3Config(config *Config, bucket, prefix string) storage.S3Config {
+	return storage.S3Config{
+		Endpoint:        config.S3.Endpoint,
+		Region:          config.AWS.Region,
+		Bucket:          bucket,
+		Prefix:          prefix,
+		AccessKeyID:     config.AWS.AccessKeyID,
+		SecretAccessKey: config.AWS.SecretAccessKey,
+		SessionToken:    config.AWS.SessionToken,
+		PathStyle:       config.S3.PathStyle,
+	}
+}
+
+// initBlobStore moves result outputs larger than OUTPUT_BLOB_THRESHOLD bytes
+// and the content of new artifacts and evidence out of the database, to BLOB_STORE_DIR or
+// to the BLOB_STORE_S3_BUCKET bucket. Results keep the first
+// OUTPUT_PREVIEW_SIZE bytes of their output. Outputs are cut to OUTPUT_MAX_SIZE
+// bytes when received, with or without a blob store.
+func initBlobStore(
+	config *Config,
+	executionService *application.ExecutionService,
+	artifactService *application.ArtifactService,
+	evidenceService *application.EvidenceService,
+	retentionService *application.RetentionService,
+	logger *zap.Logger,
+) {
+	output := config.Storage.Output
+	if output.MaxSize > 0 {
+		executionService.SetOutputMaxSize(output.MaxSize)
+	}
+
+	settings := config.Storage.BlobStore
+	var store application.BlobStore
+	var err error
+	if settings.Dir != "" {
+		store, err = storage.NewDiskStore(settings.Dir)
+	} else if settings.S3Bucket != "" {
+		store, err = storage.NewS3Store(s3Config(config, settings.S3Bucket, settings.S3Prefix))
+	}
+	if err != nil {
+		// Stored outputs and artifacts could not be read back from a misconfigured store
+		logger.Fatal("Invalid blob store", zap.Error(err))
+	}
+	if store == nil {
+		return
+	}
+
+	executionService.SetOutputStore(store, output.BlobThreshold, output.PreviewSize)
+	artifactService.SetBlobStore(store)
+	evidenceService.SetBlobStore(store)
+	retentionService.SetBlobStore(store)
+	logger.Info("Blob store enabled for large outputs, artifacts and evidence", zap.String("location", store.Location()))
+}
@@ -0,0 +1,20 @@
+// Command autostrikectl is the command-line client of the AutoStrike server.
+// It authenticates with an API key, from --api-key or AUTOSTRIKE_API_KEY,
+// created under /api/v1/auth/api-keys.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"autostrike/internal/cli"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
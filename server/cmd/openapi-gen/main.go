@@ -0,0 +1,20 @@
+// Command openapi-gen generates the OpenAPI annotations of the REST handlers
+// from their godoc @Summary, @Param, @Success and @Failure lines. It runs
+// through go generate in the handlers package.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"autostrike/internal/infrastructure/api/openapi"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "Directory of the handler package")
+	flag.Parse()
+
+	if err := openapi.WriteAnnotations(*dir); err != nil {
+		log.Fatalf("openapi-gen: %v", err)
+	}
+}
@@ -0,0 +1,388 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// ActivityMonitorConfig configures which operator activity is considered unusual
+type ActivityMonitorConfig struct {
+	BusinessHoursStart    int            // First business hour, inclusive (0-23)
+	BusinessHoursEnd      int            // Last business hour, exclusive (1-24)
+	BusinessDays          []time.Weekday // Days on which executions are expected
+	Location              *time.Location // Timezone of business hours
+	MassDeletionThreshold int            // Scenario deletions by one user that trigger an alert
+	MassDeletionWindow    time.Duration  // Window in which deletions are counted
+	LoginHistoryDepth     int            // Past logins compared against to spot a new IP or country
+	CountryHeader         string         // Request header carrying the client country (set by a proxy/CDN)
+}
+
+// DefaultActivityMonitorConfig returns sensible defaults: 08:00-19:00 Monday to
+// Friday, and an alert after 5 scenario deletions within 10 minutes
+func DefaultActivityMonitorConfig() ActivityMonitorConfig {
+	return ActivityMonitorConfig{
+		BusinessHoursStart: 8,
+		BusinessHoursEnd:   19,
+		BusinessDays: []time.Weekday{
+			time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday,
+		},
+		Location:              time.Local,
+		MassDeletionThreshold: 5,
+		MassDeletionWindow:    10 * time.Minute,
+		LoginHistoryDepth:     100,
+	}
+}
+
+// ParseBusinessHours parses a "start-end" hour range such as "8-19"
+func ParseBusinessHours(value string) (int, int, error) {
+	parts := strings.SplitN(value, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid business hours %q: expected start-end", value)
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid business hours start: %w", err)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid business hours end: %w", err)
+	}
+	if start < 0 || end > 24 || start >= end {
+		return 0, 0, fmt.Errorf("invalid business hours %q: need 0 <= start < end <= 24", value)
+	}
+	return start, end, nil
+}
+
+// ActivityMonitor detects unusual operator activity, records it as an audit
+// anomaly and alerts admins. The platform drives attacks on the fleet, which
+// makes a compromised operator account especially dangerous.
+type ActivityMonitor struct {
+	repo     repository.ActivityRepository
+	userRepo repository.UserRepository
+	events   *EventBus
+	config   ActivityMonitorConfig
+	logger   *zap.Logger
+
+	mu           sync.Mutex
+	deletions    map[string][]time.Time // Recent scenario deletions per user
+	agentSources map[string]string      // Last unexpected source address alerted per agent
+}
+
+// NewActivityMonitor creates a new activity monitor. Anomalies are recorded and
+// published on events as security alerts; events may be nil, in which case
+// they are only recorded.
+func NewActivityMonitor(
+	repo repository.ActivityRepository,
+	userRepo repository.UserRepository,
+	events *EventBus,
+	config ActivityMonitorConfig,
+	logger *zap.Logger,
+) *ActivityMonitor {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	return &ActivityMonitor{
+		repo:         repo,
+		userRepo:     userRepo,
+		events:       events,
+		config:       config,
+		logger:       logger,
+		deletions:    make(map[string][]time.Time),
+		agentSources: make(map[string]string),
+	}
+}
+
+// CountryHeader returns the request header holding the client country, empty when not configured
+func (m *ActivityMonitor) CountryHeader() string {
+	return m.config.CountryHeader
+}
+
+// RecordLogin stores a successful login and raises an anomaly when it comes from
+// an IP or country never seen for the user. The first login sets the baseline.
+func (m *ActivityMonitor) RecordLogin(ctx context.Context, username, ipAddress, country, userAgent string) {
+	user, err := m.userRepo.FindByUsername(ctx, username)
+	if err != nil || user == nil {
+		m.logger.Warn("Activity monitor could not resolve user", zap.String("username", username), zap.Error(err))
+		return
+	}
+
+	history, err := m.repo.FindLoginsByUser(ctx, user.ID, m.config.LoginHistoryDepth)
+	if err != nil {
+		m.logger.Warn("Failed to load login history", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	record := &entity.LoginRecord{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		IPAddress: ipAddress,
+		Country:   strings.ToUpper(strings.TrimSpace(country)),
+		UserAgent: userAgent,
+		CreatedAt: time.Now(),
+	}
+	if err := m.repo.CreateLogin(ctx, record); err != nil {
+		m.logger.Warn("Failed to record login", zap.String("user_id", user.ID), zap.Error(err))
+	}
+
+	if len(history) == 0 {
+		return
+	}
+
+	knownIPs := make(map[string]bool)
+	knownCountries := make(map[string]bool)
+	for _, login := range history {
+		knownIPs[login.IPAddress] = true
+		if login.Country != "" {
+			knownCountries[login.Country] = true
+		}
+	}
+
+	data := map[string]any{"ip_address": record.IPAddress, "user_agent": record.UserAgent}
+	if record.Country != "" {
+		data["country"] = record.Country
+	}
+
+	// A new country is the stronger signal and already implies a new IP
+	switch {
+	case record.Country != "" && len(knownCountries) > 0 && !knownCountries[record.Country]:
+		m.raise(ctx, &entity.ActivityAnomaly{
+			Type:     entity.AnomalyNewCountry,
+			Severity: entity.SeverityHigh,
+			UserID:   user.ID,
+			Username: user.Username,
+			Message:  fmt.Sprintf("User %s logged in from a new country (%s, %s)", user.Username, record.Country, record.IPAddress),
+			Data:     data,
+		})
+	case !knownIPs[record.IPAddress]:
+		m.raise(ctx, &entity.ActivityAnomaly{
+			Type:     entity.AnomalyNewIP,
+			Severity: entity.SeverityMedium,
+			UserID:   user.ID,
+			Username: user.Username,
+			Message:  fmt.Sprintf("User %s logged in from a new IP address (%s)", user.Username, record.IPAddress),
+			Data:     data,
+		})
+	}
+}
+
+// RecordLoginLockout raises an anomaly when repeated failed logins lock out a
+// username or a source IP
+func (m *ActivityMonitor) RecordLoginLockout(ctx context.Context, throttle *entity.LoginThrottle) {
+	if throttle == nil || throttle.LockedUntil == nil {
+		return
+	}
+
+	anomaly := &entity.ActivityAnomaly{
+		Type:     entity.AnomalyLoginLockout,
+		Severity: entity.SeverityHigh,
+		Data: map[string]any{
+			"scope":        string(throttle.Scope),
+			"key":          throttle.Key,
+			"lockouts":     throttle.Lockouts,
+			"locked_until": *throttle.LockedUntil,
+		},
+	}
+	until := throttle.LockedUntil.In(m.config.Location).Format("15:04 MST")
+	if throttle.Scope == entity.LoginThrottleIP {
+		anomaly.Message = fmt.Sprintf("Logins from %s locked out until %s after repeated failures", throttle.Key, until)
+	} else {
+		anomaly.Message = fmt.Sprintf("Logins of user %s locked out until %s after repeated failures", throttle.Key, until)
+		if user, err := m.userRepo.FindByUsername(ctx, throttle.Key); err == nil && user != nil {
+			anomaly.UserID, anomaly.Username = user.ID, user.Username
+		}
+	}
+	m.raise(ctx, anomaly)
+}
+
+// RecordPasswordResetRequest logs a password reset emailed to a user. The
+// token issued, with its source IP, is kept by the password reset repository.
+func (m *ActivityMonitor) RecordPasswordResetRequest(ctx context.Context, user *entity.User, ip string) {
+	m.logger.Info("Password reset requested",
+		zap.String("user_id", user.ID), zap.String("username", user.Username), zap.String("ip_address", ip))
+}
+
+// RecordPasswordReset raises an anomaly when a user resets their password
+// with an emailed token, which a mailbox compromise would also allow
+func (m *ActivityMonitor) RecordPasswordReset(ctx context.Context, user *entity.User, ip string) {
+	m.raise(ctx, &entity.ActivityAnomaly{
+		Type:     entity.AnomalyPasswordReset,
+		Severity: entity.SeverityMedium,
+		UserID:   user.ID,
+		Username: user.Username,
+		Message:  fmt.Sprintf("User %s reset their password by email from %s", user.Username, ip),
+		Data:     map[string]any{"ip_address": ip},
+	})
+}
+
+// RecordExecutionStarted raises an anomaly when an execution is launched outside business hours
+func (m *ActivityMonitor) RecordExecutionStarted(ctx context.Context, userID string, execution *entity.Execution) {
+	if m.IsBusinessHours(execution.StartedAt) {
+		return
+	}
+
+	local := execution.StartedAt.In(m.config.Location)
+	m.raise(ctx, &entity.ActivityAnomaly{
+		Type:     entity.AnomalyOffHoursExecution,
+		Severity: entity.SeverityMedium,
+		UserID:   userID,
+		Username: m.username(ctx, userID),
+		Message: fmt.Sprintf("Execution %s was launched outside business hours (%s)",
+			execution.ID, local.Format("Mon 15:04 MST")),
+		Data: map[string]any{
+			"execution_id": execution.ID,
+			"scenario_id":  execution.ScenarioID,
+			"safe_mode":    execution.SafeMode,
+			"started_at":   execution.StartedAt,
+		},
+	})
+}
+
+// RecordScenarioDeleted counts scenario deletions per user and raises an anomaly
+// once the threshold is reached within the window
+func (m *ActivityMonitor) RecordScenarioDeleted(ctx context.Context, userID, scenarioID string) {
+	if m.config.MassDeletionThreshold <= 0 {
+		return
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-m.config.MassDeletionWindow)
+
+	m.mu.Lock()
+	recent := m.deletions[userID][:0]
+	for _, at := range m.deletions[userID] {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	count := len(recent)
+	if count >= m.config.MassDeletionThreshold {
+		// Reset so a sustained deletion spree raises one alert per threshold reached
+		delete(m.deletions, userID)
+	} else {
+		m.deletions[userID] = recent
+	}
+	m.mu.Unlock()
+
+	if count < m.config.MassDeletionThreshold {
+		return
+	}
+
+	username := m.username(ctx, userID)
+	m.raise(ctx, &entity.ActivityAnomaly{
+		Type:     entity.AnomalyMassDeletion,
+		Severity: entity.SeverityHigh,
+		UserID:   userID,
+		Username: username,
+		Message: fmt.Sprintf("User %s deleted %d scenarios within %s",
+			firstNonEmptyString(username, userID), count, m.config.MassDeletionWindow),
+		Data: map[string]any{
+			"deleted_count":    count,
+			"window":           m.config.MassDeletionWindow.String(),
+			"last_scenario_id": scenarioID,
+		},
+	})
+}
+
+// RecordAgentSource raises an anomaly when an agent connects or beacons from
+// an address outside its allowed networks, once per address until the agent
+// is back in them
+func (m *ActivityMonitor) RecordAgentSource(ctx context.Context, agent *entity.Agent, ip string) {
+	if agent == nil || ip == "" {
+		return
+	}
+
+	m.mu.Lock()
+	if agent.IsExpectedSource(ip) {
+		delete(m.agentSources, agent.Paw)
+		m.mu.Unlock()
+		return
+	}
+	alerted := m.agentSources[agent.Paw] == ip
+	m.agentSources[agent.Paw] = ip
+	m.mu.Unlock()
+	if alerted {
+		return
+	}
+
+	m.raise(ctx, &entity.ActivityAnomaly{
+		Type:     entity.AnomalyAgentUnexpectedNetwork,
+		Severity: entity.SeverityHigh,
+		Message: fmt.Sprintf("Agent %s (%s) connected from %s, outside its allowed networks",
+			agent.Paw, agent.Hostname, ip),
+		Data: map[string]any{
+			"paw":              agent.Paw,
+			"hostname":         agent.Hostname,
+			"ip_address":       ip,
+			"allowed_networks": agent.AllowedNetworks,
+		},
+	})
+}
+
+// IsBusinessHours reports whether t falls on a business day within business hours
+func (m *ActivityMonitor) IsBusinessHours(t time.Time) bool {
+	local := t.In(m.config.Location)
+
+	workday := false
+	for _, day := range m.config.BusinessDays {
+		if local.Weekday() == day {
+			workday = true
+			break
+		}
+	}
+	if !workday {
+		return false
+	}
+
+	hour := local.Hour()
+	return hour >= m.config.BusinessHoursStart && hour < m.config.BusinessHoursEnd
+}
+
+// ListAnomalies returns the most recent activity anomalies
+func (m *ActivityMonitor) ListAnomalies(ctx context.Context, limit int) ([]*entity.ActivityAnomaly, error) {
+	return m.repo.FindAnomalies(ctx, limit)
+}
+
+// raise records an anomaly and alerts admins
+func (m *ActivityMonitor) raise(ctx context.Context, anomaly *entity.ActivityAnomaly) {
+	anomaly.ID = uuid.New().String()
+	anomaly.CreatedAt = time.Now()
+
+	m.logger.Warn("Unusual operator activity detected",
+		zap.String("type", string(anomaly.Type)),
+		zap.String("severity", string(anomaly.Severity)),
+		zap.String("user_id", anomaly.UserID),
+		zap.String("message", anomaly.Message),
+	)
+
+	if err := m.repo.CreateAnomaly(ctx, anomaly); err != nil {
+		m.logger.Error("Failed to record activity anomaly", zap.Error(err))
+	}
+
+	m.events.Publish(ctx, &entity.Event{Type: entity.EventSecurityAlert, Anomaly: anomaly})
+}
+
+// username resolves a user ID for display, empty when unknown
+func (m *ActivityMonitor) username(ctx context.Context, userID string) string {
+	if userID == "" {
+		return ""
+	}
+	user, err := m.userRepo.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.Username
+}
@@ -0,0 +1,430 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+type mockActivityRepo struct {
+	mu        sync.Mutex
+	logins    []*entity.LoginRecord
+	anomalies []*entity.ActivityAnomaly
+	err       error
+}
+
+func newMockActivityRepo() *mockActivityRepo {
+	return &mockActivityRepo{}
+}
+
+func (m *mockActivityRepo) CreateLogin(ctx context.Context, record *entity.LoginRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logins = append(m.logins, record)
+	return nil
+}
+
+func (m *mockActivityRepo) FindLoginsByUser(ctx context.Context, userID string, limit int) ([]*entity.LoginRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	var result []*entity.LoginRecord
+	for i := len(m.logins) - 1; i >= 0 && len(result) < limit; i-- {
+		if m.logins[i].UserID == userID {
+			result = append(result, m.logins[i])
+		}
+	}
+	return result, nil
+}
+
+func (m *mockActivityRepo) CreateAnomaly(ctx context.Context, anomaly *entity.ActivityAnomaly) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.anomalies = append(m.anomalies, anomaly)
+	return nil
+}
+
+func (m *mockActivityRepo) FindAnomalies(ctx context.Context, limit int) ([]*entity.ActivityAnomaly, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	if len(m.anomalies) < limit {
+		limit = len(m.anomalies)
+	}
+	return m.anomalies[:limit], nil
+}
+
+func setupActivityMonitor(t *testing.T) (*ActivityMonitor, *mockActivityRepo, *mockNotificationRepo) {
+	t.Helper()
+	userRepo := newMockUserRepo()
+	userRepo.users["admin-1"] = &entity.User{ID: "admin-1", Username: "admin", Role: entity.RoleAdmin, IsActive: true}
+	userRepo.users["op-1"] = &entity.User{ID: "op-1", Username: "alice", Role: entity.RoleOperator, IsActive: true}
+
+	notificationRepo := newMockNotificationRepo()
+	events := NewEventBus(nil)
+	events.AddSink(NewNotificationService(notificationRepo, userRepo, nil, "https://localhost:8443", nil))
+
+	config := DefaultActivityMonitorConfig()
+	config.Location = time.UTC
+	config.MassDeletionThreshold = 3
+
+	activityRepo := newMockActivityRepo()
+	return NewActivityMonitor(activityRepo, userRepo, events, config, nil), activityRepo, notificationRepo
+}
+
+func TestActivityMonitor_RecordLogin_FirstLoginIsBaseline(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+
+	monitor.RecordLogin(context.Background(), "alice", "10.0.0.1", "fr", "curl")
+
+	if len(repo.logins) != 1 {
+		t.Fatalf("Expected login to be recorded, got %d", len(repo.logins))
+	}
+	if repo.logins[0].Country != "FR" {
+		t.Errorf("Expected country to be normalized, got %q", repo.logins[0].Country)
+	}
+	if len(repo.anomalies) != 0 {
+		t.Errorf("First login should not raise an anomaly, got %d", len(repo.anomalies))
+	}
+}
+
+func TestActivityMonitor_RecordLogin_NewIP(t *testing.T) {
+	monitor, repo, notificationRepo := setupActivityMonitor(t)
+	ctx := context.Background()
+
+	monitor.RecordLogin(ctx, "alice", "10.0.0.1", "", "curl")
+	monitor.RecordLogin(ctx, "alice", "10.0.0.1", "", "curl")
+	if len(repo.anomalies) != 0 {
+		t.Fatalf("Known IP should not raise an anomaly, got %d", len(repo.anomalies))
+	}
+
+	monitor.RecordLogin(ctx, "alice", "203.0.113.7", "", "curl")
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected 1 anomaly, got %d", len(repo.anomalies))
+	}
+	anomaly := repo.anomalies[0]
+	if anomaly.Type != entity.AnomalyNewIP || anomaly.Severity != entity.SeverityMedium || anomaly.UserID != "op-1" {
+		t.Errorf("Unexpected anomaly: %+v", anomaly)
+	}
+
+	// Only the admin is notified
+	if len(notificationRepo.notifications) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(notificationRepo.notifications))
+	}
+	for _, n := range notificationRepo.notifications {
+		if n.UserID != "admin-1" || n.Type != entity.NotificationSecurityAlert {
+			t.Errorf("Unexpected notification: %+v", n)
+		}
+	}
+}
+
+func TestActivityMonitor_RecordLogin_NewCountry(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	ctx := context.Background()
+
+	monitor.RecordLogin(ctx, "alice", "10.0.0.1", "FR", "")
+	monitor.RecordLogin(ctx, "alice", "198.51.100.4", "KP", "")
+
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected a single anomaly for new country, got %d", len(repo.anomalies))
+	}
+	if repo.anomalies[0].Type != entity.AnomalyNewCountry || repo.anomalies[0].Severity != entity.SeverityHigh {
+		t.Errorf("Unexpected anomaly: %+v", repo.anomalies[0])
+	}
+	if repo.anomalies[0].Data["country"] != "KP" {
+		t.Errorf("Expected country in data, got %v", repo.anomalies[0].Data)
+	}
+}
+
+func TestActivityMonitor_RecordLogin_Errors(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	ctx := context.Background()
+
+	monitor.RecordLogin(ctx, "ghost", "10.0.0.1", "", "")
+	if len(repo.logins) != 0 {
+		t.Error("Unknown user should not be recorded")
+	}
+
+	repo.err = errors.New("db down")
+	monitor.RecordLogin(ctx, "alice", "10.0.0.1", "", "")
+	if len(repo.logins) != 0 {
+		t.Error("Login should not be recorded when history cannot be loaded")
+	}
+}
+
+func TestActivityMonitor_RecordExecutionStarted(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	ctx := context.Background()
+
+	// Wednesday 10:00 UTC is within business hours
+	monitor.RecordExecutionStarted(ctx, "op-1", &entity.Execution{
+		ID: "exec-1", StartedAt: time.Date(2026, 1, 7, 10, 0, 0, 0, time.UTC),
+	})
+	if len(repo.anomalies) != 0 {
+		t.Fatalf("Business hours execution should not raise an anomaly")
+	}
+
+	// Saturday 03:00 UTC is not
+	monitor.RecordExecutionStarted(ctx, "op-1", &entity.Execution{
+		ID: "exec-2", ScenarioID: "sc-1", StartedAt: time.Date(2026, 1, 10, 3, 0, 0, 0, time.UTC),
+	})
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected 1 anomaly, got %d", len(repo.anomalies))
+	}
+	anomaly := repo.anomalies[0]
+	if anomaly.Type != entity.AnomalyOffHoursExecution || anomaly.Username != "alice" {
+		t.Errorf("Unexpected anomaly: %+v", anomaly)
+	}
+	if anomaly.Data["execution_id"] != "exec-2" {
+		t.Errorf("Expected execution_id in data, got %v", anomaly.Data)
+	}
+}
+
+func TestActivityMonitor_IsBusinessHours(t *testing.T) {
+	monitor, _, _ := setupActivityMonitor(t)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{"weekday start", time.Date(2026, 1, 7, 8, 0, 0, 0, time.UTC), true},
+		{"weekday before start", time.Date(2026, 1, 7, 7, 59, 0, 0, time.UTC), false},
+		{"weekday end is exclusive", time.Date(2026, 1, 7, 19, 0, 0, 0, time.UTC), false},
+		{"sunday", time.Date(2026, 1, 11, 12, 0, 0, 0, time.UTC), false},
+		{"other timezone converted", time.Date(2026, 1, 7, 10, 0, 0, 0, time.FixedZone("UTC+5", 5*3600)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monitor.IsBusinessHours(tt.at); got != tt.want {
+				t.Errorf("IsBusinessHours(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivityMonitor_RecordScenarioDeleted(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	ctx := context.Background()
+
+	monitor.RecordScenarioDeleted(ctx, "op-1", "sc-1")
+	monitor.RecordScenarioDeleted(ctx, "op-1", "sc-2")
+	monitor.RecordScenarioDeleted(ctx, "admin-1", "sc-3")
+	if len(repo.anomalies) != 0 {
+		t.Fatalf("Below threshold should not raise an anomaly")
+	}
+
+	monitor.RecordScenarioDeleted(ctx, "op-1", "sc-4")
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected 1 anomaly, got %d", len(repo.anomalies))
+	}
+	anomaly := repo.anomalies[0]
+	if anomaly.Type != entity.AnomalyMassDeletion || anomaly.Severity != entity.SeverityHigh {
+		t.Errorf("Unexpected anomaly: %+v", anomaly)
+	}
+	if anomaly.Data["deleted_count"] != 3 || anomaly.Data["last_scenario_id"] != "sc-4" {
+		t.Errorf("Unexpected data: %v", anomaly.Data)
+	}
+
+	// Counter resets after an alert
+	monitor.RecordScenarioDeleted(ctx, "op-1", "sc-5")
+	if len(repo.anomalies) != 1 {
+		t.Errorf("Expected counter reset after alert, got %d anomalies", len(repo.anomalies))
+	}
+}
+
+func TestActivityMonitor_RecordScenarioDeleted_WindowExpires(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	monitor.config.MassDeletionWindow = time.Millisecond
+	ctx := context.Background()
+
+	monitor.RecordScenarioDeleted(ctx, "op-1", "sc-1")
+	monitor.RecordScenarioDeleted(ctx, "op-1", "sc-2")
+	time.Sleep(5 * time.Millisecond)
+	monitor.RecordScenarioDeleted(ctx, "op-1", "sc-3")
+
+	if len(repo.anomalies) != 0 {
+		t.Errorf("Deletions outside the window should not count, got %d anomalies", len(repo.anomalies))
+	}
+}
+
+func TestActivityMonitor_RecordScenarioDeleted_Disabled(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	monitor.config.MassDeletionThreshold = 0
+
+	for i := 0; i < 10; i++ {
+		monitor.RecordScenarioDeleted(context.Background(), "op-1", "sc")
+	}
+	if len(repo.anomalies) != 0 {
+		t.Errorf("Disabled threshold should not raise anomalies, got %d", len(repo.anomalies))
+	}
+}
+
+func TestActivityMonitor_WithoutNotifier(t *testing.T) {
+	userRepo := newMockUserRepo()
+	repo := newMockActivityRepo()
+	monitor := NewActivityMonitor(repo, userRepo, nil, ActivityMonitorConfig{MassDeletionThreshold: 1}, nil)
+
+	monitor.RecordScenarioDeleted(context.Background(), "unknown", "sc-1")
+
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected anomaly to be recorded without notifier, got %d", len(repo.anomalies))
+	}
+	if repo.anomalies[0].Username != "" {
+		t.Errorf("Expected empty username for unknown user, got %q", repo.anomalies[0].Username)
+	}
+
+	anomalies, err := monitor.ListAnomalies(context.Background(), 10)
+	if err != nil || len(anomalies) != 1 {
+		t.Errorf("ListAnomalies = %d, %v", len(anomalies), err)
+	}
+}
+
+func TestNotifySecurityAlert_DeliversToEnabledChannel(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userRepo.users["admin-1"] = &entity.User{ID: "admin-1", Username: "admin", Role: entity.RoleAdmin, IsActive: true}
+	userRepo.users["admin-2"] = &entity.User{ID: "admin-2", Username: "old", Role: entity.RoleAdmin, IsActive: false}
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["s1"] = &entity.NotificationSettings{
+		ID: "s1", UserID: "admin-1", Enabled: true,
+		Preferences: entity.NotificationPreferences{entity.NotificationSecurityAlert: {entity.ChannelEmail}},
+	}
+	svc := NewNotificationService(notificationRepo, userRepo, nil, "", nil)
+
+	err := svc.NotifySecurityAlert(context.Background(), &entity.ActivityAnomaly{
+		ID: "a1", Type: entity.AnomalyMassDeletion, Severity: entity.SeverityHigh, Message: "deleted", CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("NotifySecurityAlert failed: %v", err)
+	}
+	if len(notificationRepo.notifications) != 1 {
+		t.Errorf("Expected only the active admin to be notified, got %d", len(notificationRepo.notifications))
+	}
+}
+
+func TestNotifySecurityAlert_UserRepoError(t *testing.T) {
+	userRepo := newMockUserRepo()
+	userRepo.findErr = errors.New("db down")
+	svc := NewNotificationService(newMockNotificationRepo(), userRepo, nil, "", nil)
+
+	if err := svc.NotifySecurityAlert(context.Background(), &entity.ActivityAnomaly{}); err == nil {
+		t.Error("Expected error when users cannot be loaded")
+	}
+}
+
+func TestParseBusinessHours(t *testing.T) {
+	tests := []struct {
+		value     string
+		start     int
+		end       int
+		expectErr bool
+	}{
+		{"8-19", 8, 19, false},
+		{" 0 - 24 ", 0, 24, false},
+		{"19-8", 0, 0, true},
+		{"8", 0, 0, true},
+		{"a-19", 0, 0, true},
+		{"8-b", 0, 0, true},
+		{"8-25", 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, err := ParseBusinessHours(tt.value)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseBusinessHours(%q) error = %v, expectErr %v", tt.value, err, tt.expectErr)
+			}
+			if start != tt.start || end != tt.end {
+				t.Errorf("ParseBusinessHours(%q) = %d-%d, want %d-%d", tt.value, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestActivityMonitor_RecordAgentSource(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	ctx := context.Background()
+	agent := &entity.Agent{Paw: "paw1", Hostname: "ws-01", AllowedNetworks: []string{"10.0.0.0/8"}}
+
+	monitor.RecordAgentSource(ctx, agent, "10.1.2.3")
+	if len(repo.anomalies) != 0 {
+		t.Fatalf("Expected no anomaly from an allowed network")
+	}
+
+	monitor.RecordAgentSource(ctx, agent, "203.0.113.7")
+	monitor.RecordAgentSource(ctx, agent, "203.0.113.7")
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected 1 anomaly per unexpected address, got %d", len(repo.anomalies))
+	}
+	anomaly := repo.anomalies[0]
+	if anomaly.Type != entity.AnomalyAgentUnexpectedNetwork || anomaly.Severity != entity.SeverityHigh {
+		t.Errorf("Unexpected anomaly: %+v", anomaly)
+	}
+	if anomaly.Data["paw"] != "paw1" || anomaly.Data["ip_address"] != "203.0.113.7" {
+		t.Errorf("Unexpected data: %v", anomaly.Data)
+	}
+
+	// Back in its networks, the agent is alerted on again when it leaves them
+	monitor.RecordAgentSource(ctx, agent, "10.1.2.3")
+	monitor.RecordAgentSource(ctx, agent, "203.0.113.7")
+	if len(repo.anomalies) != 2 {
+		t.Errorf("Expected a new anomaly after returning to the allowed networks, got %d", len(repo.anomalies))
+	}
+
+	// Agents without allowed networks are never alerted on
+	monitor.RecordAgentSource(ctx, &entity.Agent{Paw: "paw2"}, "203.0.113.7")
+	if len(repo.anomalies) != 2 {
+		t.Errorf("Expected no anomaly for an agent without allowed networks")
+	}
+}
+
+func TestActivityMonitor_RecordLoginLockout(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	ctx := context.Background()
+	until := time.Now().Add(time.Minute)
+
+	monitor.RecordLoginLockout(ctx, &entity.LoginThrottle{Scope: entity.LoginThrottleIP, Key: "203.0.113.7", Lockouts: 1, LockedUntil: &until})
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected 1 anomaly, got %d", len(repo.anomalies))
+	}
+	anomaly := repo.anomalies[0]
+	if anomaly.Type != entity.AnomalyLoginLockout || anomaly.Severity != entity.SeverityHigh {
+		t.Errorf("Unexpected anomaly: %+v", anomaly)
+	}
+	if anomaly.Data["scope"] != "ip" || anomaly.Data["key"] != "203.0.113.7" || anomaly.Data["lockouts"] != 1 {
+		t.Errorf("Unexpected data: %v", anomaly.Data)
+	}
+
+	// Throttles without a lockout are not alerted on
+	monitor.RecordLoginLockout(ctx, &entity.LoginThrottle{Scope: entity.LoginThrottleUser, Key: "alice", Failures: 2})
+	if len(repo.anomalies) != 1 {
+		t.Errorf("Expected no anomaly without a lockout")
+	}
+}
+
+func TestActivityMonitor_RecordPasswordReset(t *testing.T) {
+	monitor, repo, _ := setupActivityMonitor(t)
+	ctx := context.Background()
+	user := &entity.User{ID: "user-1", Username: "alice"}
+
+	monitor.RecordPasswordResetRequest(ctx, user, "10.0.0.1")
+	if len(repo.anomalies) != 0 {
+		t.Fatalf("Expected no anomaly for a reset request")
+	}
+
+	monitor.RecordPasswordReset(ctx, user, "10.0.0.1")
+	if len(repo.anomalies) != 1 {
+		t.Fatalf("Expected 1 anomaly, got %d", len(repo.anomalies))
+	}
+	anomaly := repo.anomalies[0]
+	if anomaly.Type != entity.AnomalyPasswordReset || anomaly.UserID != "user-1" || anomaly.Data["ip_address"] != "10.0.0.1" {
+		t.Errorf("Unexpected anomaly: %+v", anomaly)
+	}
+}
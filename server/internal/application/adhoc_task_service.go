@@ -0,0 +1,196 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// adhocTaskPrefix starts the ID of ad-hoc tasks, so their results are told
+// apart from scenario results without a lookup
+const adhocTaskPrefix = "adhoc-"
+
+// AdHocTechniqueID is the technique ID sent with ad-hoc tasks
+const AdHocTechniqueID = "adhoc"
+
+// Ad-hoc task limits, in seconds
+const (
+	DefaultAdHocTimeout = 60
+	MaxAdHocTimeout     = 3600
+)
+
+// Ad-hoc task errors
+var (
+	ErrAdHocTaskNotFound = errors.New("ad-hoc task not found")
+	ErrInvalidAdHocTask  = errors.New("invalid ad-hoc task")
+	ErrAdHocAgentUnknown = errors.New("agent not found")
+)
+
+// AdHocTaskRequest is a one-off command to run on an agent
+type AdHocTaskRequest struct {
+	Command  string `json:"command" binding:"required"`
+	Executor string `json:"executor,omitempty"` // The agent's first executor by default
+	Timeout  int    `json:"timeout,omitempty"`  // Seconds, DefaultAdHocTimeout by default
+}
+
+// AdHocTaskService runs one-off commands on agents outside any scenario and
+// keeps every command and its outcome as an audit record
+type AdHocTaskService struct {
+	repo      repository.AdHocTaskRepository
+	agentRepo repository.AgentRepository
+	logger    *zap.Logger
+
+	emergencyStop *EmergencyStopService
+}
+
+// NewAdHocTaskService creates a new ad-hoc task service
+func NewAdHocTaskService(
+	repo repository.AdHocTaskRepository,
+	agentRepo repository.AgentRepository,
+	logger *zap.Logger,
+) *AdHocTaskService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &AdHocTaskService{repo: repo, agentRepo: agentRepo, logger: logger}
+}
+
+// SetEmergencyStop refuses new ad-hoc tasks while the emergency stop is engaged
+func (s *AdHocTaskService) SetEmergencyStop(stop *EmergencyStopService) {
+	s.emergencyStop = stop
+}
+
+// IsAdHocTaskID reports whether a task ID belongs to an ad-hoc task
+func IsAdHocTaskID(id string) bool {
+	return strings.HasPrefix(id, adhocTaskPrefix)
+}
+
+// Create checks a command against the agent and records it as running. The
+// caller sends it to the agent, and calls Fail when it cannot.
+func (s *AdHocTaskService) Create(
+	ctx context.Context,
+	paw string,
+	req AdHocTaskRequest,
+	userID string,
+) (*entity.AdHocTask, error) {
+	if err := s.emergencyStop.Check(); err != nil {
+		return nil, err
+	}
+	command := strings.TrimSpace(req.Command)
+	if command == "" {
+		return nil, fmt.Errorf("%w: a command is required", ErrInvalidAdHocTask)
+	}
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = DefaultAdHocTimeout
+	}
+	if timeout < 0 || timeout > MaxAdHocTimeout {
+		return nil, fmt.Errorf("%w: timeout must be between 1 and %d seconds", ErrInvalidAdHocTask, MaxAdHocTimeout)
+	}
+
+	agent, err := s.agentRepo.FindByPaw(ctx, paw)
+	if err != nil || agent == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAdHocAgentUnknown, paw)
+	}
+	executor := req.Executor
+	if executor == "" && len(agent.Executors) > 0 {
+		executor = agent.Executors[0]
+	}
+	if !agent.SupportsExecutor(executor) {
+		return nil, fmt.Errorf("%w: agent %s does not support executor %q", ErrInvalidAdHocTask, paw, executor)
+	}
+
+	task := &entity.AdHocTask{
+		ID:          adhocTaskPrefix + uuid.New().String(),
+		AgentPaw:    paw,
+		Command:     command,
+		Executor:    executor,
+		Timeout:     timeout,
+		Status:      entity.StatusRunning,
+		RequestedBy: userID,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.Create(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to record ad-hoc task: %w", err)
+	}
+
+	s.logger.Info("Ad-hoc task dispatched",
+		zap.String("task_id", task.ID),
+		zap.String("paw", paw),
+		zap.String("executor", executor),
+		zap.String("command", command),
+		zap.String("user_id", userID),
+	)
+	return task, nil
+}
+
+// Fail records that a task could not be sent to its agent
+func (s *AdHocTaskService) Fail(ctx context.Context, id, reason string) (*entity.AdHocTask, error) {
+	return s.Complete(ctx, id, "", entity.StatusFailed, reason, -1)
+}
+
+// Complete records the outcome of a task. A non-empty agentPaw must be the
+// agent the task was sent to. A task already completed is returned unchanged
+// with entity.ErrResultTransition.
+func (s *AdHocTaskService) Complete(
+	ctx context.Context,
+	id, agentPaw string,
+	status entity.ResultStatus,
+	output string,
+	exitCode int,
+) (*entity.AdHocTask, error) {
+	task, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if agentPaw != "" && task.AgentPaw != agentPaw {
+		return nil, fmt.Errorf("%w: task %s was not sent to agent %s", ErrInvalidAdHocTask, id, agentPaw)
+	}
+	if !task.Status.CanTransitionTo(status) {
+		return task, entity.ErrResultTransition
+	}
+
+	now := time.Now()
+	task.Status = status
+	task.Output = output
+	task.ExitCode = exitCode
+	task.CompletedAt = &now
+	if err := s.repo.Update(ctx, task); err != nil {
+		return nil, fmt.Errorf("failed to record ad-hoc task result: %w", err)
+	}
+
+	s.logger.Info("Ad-hoc task completed",
+		zap.String("task_id", task.ID),
+		zap.String("paw", task.AgentPaw),
+		zap.String("status", string(status)),
+		zap.Int("exit_code", exitCode),
+		zap.String("user_id", task.RequestedBy),
+	)
+	return task, nil
+}
+
+// Get returns an ad-hoc task by ID
+func (s *AdHocTaskService) Get(ctx context.Context, id string) (*entity.AdHocTask, error) {
+	task, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAdHocTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
+
+// ListByAgent returns the latest ad-hoc tasks of an agent, newest first
+func (s *AdHocTaskService) ListByAgent(ctx context.Context, paw string, limit int) ([]*entity.AdHocTask, error) {
+	return s.repo.FindByAgent(ctx, paw, limit)
+}
@@ -0,0 +1,151 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockAdHocTaskRepo implements repository.AdHocTaskRepository for tests
+type mockAdHocTaskRepo struct {
+	tasks map[string]*entity.AdHocTask
+	err   error
+}
+
+func newMockAdHocTaskRepo() *mockAdHocTaskRepo {
+	return &mockAdHocTaskRepo{tasks: make(map[string]*entity.AdHocTask)}
+}
+
+func (m *mockAdHocTaskRepo) Create(ctx context.Context, task *entity.AdHocTask) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.tasks[task.ID] = task
+	return nil
+}
+
+func (m *mockAdHocTaskRepo) Update(ctx context.Context, task *entity.AdHocTask) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.tasks[task.ID] = task
+	return nil
+}
+
+func (m *mockAdHocTaskRepo) FindByID(ctx context.Context, id string) (*entity.AdHocTask, error) {
+	if task, ok := m.tasks[id]; ok {
+		copied := *task
+		return &copied, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAdHocTaskRepo) FindByAgent(ctx context.Context, paw string, limit int) ([]*entity.AdHocTask, error) {
+	var tasks []*entity.AdHocTask
+	for _, task := range m.tasks {
+		if task.AgentPaw == paw && len(tasks) < limit {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func newTestAdHocTaskService() (*AdHocTaskService, *mockAdHocTaskRepo) {
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{Paw: "paw1", Executors: []string{"sh", "bash"}}
+	repo := newMockAdHocTaskRepo()
+	return NewAdHocTaskService(repo, agentRepo, nil), repo
+}
+
+func TestAdHocTaskService_Create(t *testing.T) {
+	svc, repo := newTestAdHocTaskService()
+	ctx := context.Background()
+
+	task, err := svc.Create(ctx, "paw1", AdHocTaskRequest{Command: "  whoami  "}, "admin-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !IsAdHocTaskID(task.ID) || task.Command != "whoami" || task.Executor != "sh" ||
+		task.Timeout != DefaultAdHocTimeout || task.Status != entity.StatusRunning || task.RequestedBy != "admin-1" {
+		t.Errorf("Unexpected task: %+v", task)
+	}
+	if _, ok := repo.tasks[task.ID]; !ok {
+		t.Error("Expected the task to be recorded")
+	}
+
+	tests := []struct {
+		name    string
+		paw     string
+		req     AdHocTaskRequest
+		wantErr error
+	}{
+		{"no command", "paw1", AdHocTaskRequest{Command: " "}, ErrInvalidAdHocTask},
+		{"negative timeout", "paw1", AdHocTaskRequest{Command: "id", Timeout: -1}, ErrInvalidAdHocTask},
+		{"long timeout", "paw1", AdHocTaskRequest{Command: "id", Timeout: MaxAdHocTimeout + 1}, ErrInvalidAdHocTask},
+		{"unsupported executor", "paw1", AdHocTaskRequest{Command: "id", Executor: "powershell"}, ErrInvalidAdHocTask},
+		{"unknown agent", "missing", AdHocTaskRequest{Command: "id"}, ErrAdHocAgentUnknown},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Create(ctx, tt.paw, tt.req, "admin-1"); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	repo.err = errors.New("db error")
+	if _, err := svc.Create(ctx, "paw1", AdHocTaskRequest{Command: "id", Executor: "bash", Timeout: 10}, "admin-1"); err == nil {
+		t.Error("Expected an error when the task cannot be recorded")
+	}
+}
+
+func TestAdHocTaskService_Complete(t *testing.T) {
+	svc, _ := newTestAdHocTaskService()
+	ctx := context.Background()
+	task, _ := svc.Create(ctx, "paw1", AdHocTaskRequest{Command: "whoami"}, "admin-1")
+
+	if _, err := svc.Complete(ctx, task.ID, "paw2", entity.StatusSuccess, "root", 0); !errors.Is(err, ErrInvalidAdHocTask) {
+		t.Errorf("Expected ErrInvalidAdHocTask for another agent, got %v", err)
+	}
+	if _, err := svc.Complete(ctx, "adhoc-missing", "paw1", entity.StatusSuccess, "", 0); !errors.Is(err, ErrAdHocTaskNotFound) {
+		t.Errorf("Expected ErrAdHocTaskNotFound, got %v", err)
+	}
+
+	completed, err := svc.Complete(ctx, task.ID, "paw1", entity.StatusSuccess, "root", 0)
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if completed.Status != entity.StatusSuccess || completed.Output != "root" || completed.CompletedAt == nil {
+		t.Errorf("Unexpected task: %+v", completed)
+	}
+
+	// A resent result does not overwrite the recorded one
+	if again, err := svc.Complete(ctx, task.ID, "paw1", entity.StatusFailed, "", 1); !errors.Is(err, entity.ErrResultTransition) || again.Status != entity.StatusSuccess {
+		t.Errorf("Expected ErrResultTransition and the recorded task, got %+v (err %v)", again, err)
+	}
+}
+
+func TestAdHocTaskService_Fail(t *testing.T) {
+	svc, _ := newTestAdHocTaskService()
+	ctx := context.Background()
+	task, _ := svc.Create(ctx, "paw1", AdHocTaskRequest{Command: "whoami"}, "admin-1")
+
+	failed, err := svc.Fail(ctx, task.ID, "agent disconnected or unavailable")
+	if err != nil || failed.Status != entity.StatusFailed || !strings.Contains(failed.Output, "disconnected") {
+		t.Errorf("Unexpected task: %+v (err %v)", failed, err)
+	}
+
+	tasks, err := svc.ListByAgent(ctx, "paw1", 10)
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("Expected 1 task, got %d (err %v)", len(tasks), err)
+	}
+}
+
+func TestIsAdHocTaskID(t *testing.T) {
+	if !IsAdHocTaskID("adhoc-"+time.Now().String()) || IsAdHocTaskID("result-1") {
+		t.Error("Unexpected ad-hoc task ID detection")
+	}
+}
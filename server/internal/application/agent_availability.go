@@ -0,0 +1,218 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+// AgentAvailabilityRetention is how long the availability history of agents is kept
+const AgentAvailabilityRetention = 90 * 24 * time.Hour
+
+// AgentAvailabilityConfig tunes when agents going offline and back online are
+// announced on the event bus
+type AgentAvailabilityConfig struct {
+	// OfflineGrace is how long a disconnected agent has to reconnect before it
+	// is marked offline, 0 to mark it offline at once
+	OfflineGrace time.Duration
+	// An agent going online or offline more than FlapThreshold times within
+	// FlapWindow is flapping: its next transitions are recorded but not
+	// announced until it stays in one state for FlapWindow. 0 disables it.
+	FlapWindow    time.Duration
+	FlapThreshold int
+}
+
+// DefaultAgentAvailabilityConfig returns the default grace and flap detection
+func DefaultAgentAvailabilityConfig() AgentAvailabilityConfig {
+	return AgentAvailabilityConfig{
+		OfflineGrace:  30 * time.Second,
+		FlapWindow:    10 * time.Minute,
+		FlapThreshold: 4,
+	}
+}
+
+// SetAvailability records the agents going online and offline in repo, which
+// may be nil, with the grace period and flap detection of config
+func (s *AgentService) SetAvailability(repo repository.AgentAvailabilityRepository, config AgentAvailabilityConfig) {
+	s.availabilityRepo = repo
+	s.availability = config
+}
+
+// GetAvailability returns the availability of an agent from since to now
+func (s *AgentService) GetAvailability(ctx context.Context, paw string, since, now time.Time) (*entity.AgentAvailability, error) {
+	agent, err := s.repo.FindByPaw(ctx, paw)
+	if err != nil || agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	availability := &entity.AgentAvailability{
+		AgentPaw: paw,
+		Since:    since,
+		Until:    now,
+		Events:   []*entity.AgentAvailabilityEvent{},
+	}
+	if s.availabilityRepo != nil {
+		events, err := s.availabilityRepo.FindByAgent(ctx, paw, since)
+		if err != nil {
+			return nil, err
+		}
+		if events != nil {
+			availability.Events = events
+		}
+	}
+	availability.Transitions = len(availability.Events)
+	availability.UptimePercent = entity.AgentUptime(agent.Status, availability.Events, since, now)
+
+	s.availabilityMu.Lock()
+	_, availability.Flapping = s.held[paw]
+	s.availabilityMu.Unlock()
+	return availability, nil
+}
+
+// PurgeAvailability deletes the availability history older than
+// AgentAvailabilityRetention
+func (s *AgentService) PurgeAvailability(ctx context.Context, now time.Time) (int64, error) {
+	if s.availabilityRepo == nil {
+		return 0, nil
+	}
+	return s.availabilityRepo.DeleteBefore(ctx, now.Add(-AgentAvailabilityRetention))
+}
+
+// announceTransition records an agent going online or offline and publishes
+// it, unless the agent is flapping
+func (s *AgentService) announceTransition(ctx context.Context, agent *entity.Agent, reason string, now time.Time) {
+	flapping := s.countTransition(agent, now)
+
+	if s.availabilityRepo != nil {
+		// The history is best effort, the status change itself is already saved
+		_ = s.availabilityRepo.Create(ctx, &entity.AgentAvailabilityEvent{
+			ID:         uuid.New().String(),
+			AgentPaw:   agent.Paw,
+			Status:     agent.Status,
+			Reason:     reason,
+			Suppressed: flapping,
+			OccurredAt: now,
+		})
+	}
+	if !flapping {
+		s.events.Publish(ctx, &entity.Event{Type: availabilityEventType(agent.Status), Agent: agent})
+	}
+}
+
+// countTransition counts a transition of an agent within the flap window,
+// returning whether the agent is flapping. The status last announced for a
+// flapping agent is held until it settles.
+func (s *AgentService) countTransition(agent *entity.Agent, now time.Time) bool {
+	config := s.availability
+	if config.FlapThreshold <= 0 || config.FlapWindow <= 0 {
+		return false
+	}
+
+	s.availabilityMu.Lock()
+	defer s.availabilityMu.Unlock()
+	if s.transitions == nil {
+		s.transitions = make(map[string][]time.Time)
+		s.held = make(map[string]entity.AgentStatus)
+	}
+
+	recent := s.transitions[agent.Paw][:0]
+	for _, at := range s.transitions[agent.Paw] {
+		if now.Sub(at) < config.FlapWindow {
+			recent = append(recent, at)
+		}
+	}
+	recent = append(recent, now)
+	s.transitions[agent.Paw] = recent
+
+	if _, held := s.held[agent.Paw]; held {
+		return true
+	}
+	if len(recent) <= config.FlapThreshold {
+		return false
+	}
+	// The status announced last is the one the agent just left
+	if agent.Status == entity.AgentOnline {
+		s.held[agent.Paw] = entity.AgentOffline
+	} else {
+		s.held[agent.Paw] = entity.AgentOnline
+	}
+	return true
+}
+
+// releaseSettledAgents announces the status of the flapping agents that did
+// not change for the flap window, when it differs from the status announced
+// last
+func (s *AgentService) releaseSettledAgents(ctx context.Context, now time.Time) {
+	settled := make(map[string]entity.AgentStatus)
+	s.availabilityMu.Lock()
+	for paw, announced := range s.held {
+		recent := s.transitions[paw]
+		if len(recent) == 0 || now.Sub(recent[len(recent)-1]) >= s.availability.FlapWindow {
+			settled[paw] = announced
+			delete(s.held, paw)
+			delete(s.transitions, paw)
+		}
+	}
+	s.availabilityMu.Unlock()
+
+	for paw, announced := range settled {
+		agent, err := s.repo.FindByPaw(ctx, paw)
+		if err != nil || agent == nil {
+			continue // Deleted while flapping
+		}
+		if agent.Status != announced {
+			s.events.Publish(ctx, &entity.Event{Type: availabilityEventType(agent.Status), Agent: agent})
+		}
+	}
+}
+
+// disconnected records that the connection of an agent closed, returning
+// false when the agent should be marked offline at once
+func (s *AgentService) disconnected(paw string, now time.Time) bool {
+	if s.availability.OfflineGrace <= 0 {
+		return false
+	}
+	s.availabilityMu.Lock()
+	defer s.availabilityMu.Unlock()
+	if s.disconnectedAt == nil {
+		s.disconnectedAt = make(map[string]time.Time)
+	}
+	if _, ok := s.disconnectedAt[paw]; !ok {
+		s.disconnectedAt[paw] = now
+	}
+	return true
+}
+
+// reconnected forgets the disconnection of an agent that checked in again
+func (s *AgentService) reconnected(paw string) {
+	s.availabilityMu.Lock()
+	delete(s.disconnectedAt, paw)
+	s.availabilityMu.Unlock()
+}
+
+// expiredDisconnections returns the agents whose grace period to reconnect
+// is over, forgetting their disconnection
+func (s *AgentService) expiredDisconnections(now time.Time) []string {
+	s.availabilityMu.Lock()
+	defer s.availabilityMu.Unlock()
+	var paws []string
+	for paw, at := range s.disconnectedAt {
+		if now.Sub(at) >= s.availability.OfflineGrace {
+			paws = append(paws, paw)
+			delete(s.disconnectedAt, paw)
+		}
+	}
+	return paws
+}
+
+// availabilityEventType returns the bus event of an agent reaching a status
+func availabilityEventType(status entity.AgentStatus) entity.EventType {
+	if status == entity.AgentOnline {
+		return entity.EventAgentOnline
+	}
+	return entity.EventAgentOffline
+}
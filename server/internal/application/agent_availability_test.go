@@ -0,0 +1,137 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockAgentAvailabilityRepo implements repository.AgentAvailabilityRepository for tests
+type mockAgentAvailabilityRepo struct {
+	events []*entity.AgentAvailabilityEvent
+}
+
+func (m *mockAgentAvailabilityRepo) Create(ctx context.Context, event *entity.AgentAvailabilityEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockAgentAvailabilityRepo) FindByAgent(ctx context.Context, paw string, since time.Time) ([]*entity.AgentAvailabilityEvent, error) {
+	var events []*entity.AgentAvailabilityEvent
+	for _, event := range m.events {
+		if event.AgentPaw == paw && !event.OccurredAt.Before(since) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *mockAgentAvailabilityRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newAvailabilityTestService(config AgentAvailabilityConfig) (*AgentService, *mockAgentRepo, *mockAgentAvailabilityRepo, *recordingSink) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Status: entity.AgentOnline, LastSeen: time.Now()}
+	history := &mockAgentAvailabilityRepo{}
+	svc := NewAgentService(repo)
+	svc.SetAvailability(history, config)
+	bus := NewEventBus(nil)
+	sink := &recordingSink{name: "test"}
+	bus.AddSink(sink)
+	svc.SetEventBus(bus)
+	return svc, repo, history, sink
+}
+
+func TestAgentService_OfflineGrace(t *testing.T) {
+	svc, repo, history, sink := newAvailabilityTestService(AgentAvailabilityConfig{OfflineGrace: time.Minute})
+	ctx := context.Background()
+
+	// A reconnection within the grace period goes unnoticed
+	if err := svc.MarkAgentOffline(ctx, "paw1"); err != nil {
+		t.Fatalf("MarkAgentOffline failed: %v", err)
+	}
+	if repo.agents["paw1"].Status != entity.AgentOnline {
+		t.Error("Expected the agent kept online during its grace period")
+	}
+	if err := svc.RegisterAgent(ctx, &entity.Agent{Paw: "paw1"}); err != nil {
+		t.Fatalf("RegisterAgent failed: %v", err)
+	}
+	_ = svc.CheckStaleAgents(ctx, time.Hour)
+	if len(sink.events) != 0 || len(history.events) != 0 {
+		t.Fatalf("Expected no transition, got %v", sink.types())
+	}
+
+	// An agent that does not reconnect is offline once the grace period is over
+	_ = svc.MarkAgentOffline(ctx, "paw1")
+	svc.disconnectedAt["paw1"] = time.Now().Add(-2 * time.Minute)
+	if err := svc.CheckStaleAgents(ctx, time.Hour); err != nil {
+		t.Fatalf("CheckStaleAgents failed: %v", err)
+	}
+	if repo.agents["paw1"].Status != entity.AgentOffline {
+		t.Error("Expected the agent offline after its grace period")
+	}
+	if len(sink.events) != 1 || sink.events[0].Type != entity.EventAgentOffline {
+		t.Errorf("Expected an offline event, got %v", sink.types())
+	}
+	if len(history.events) != 1 || history.events[0].Reason != entity.AvailabilityDisconnected {
+		t.Errorf("Expected the disconnection recorded, got %+v", history.events)
+	}
+}
+
+func TestAgentService_FlapSuppression(t *testing.T) {
+	svc, repo, history, sink := newAvailabilityTestService(AgentAvailabilityConfig{FlapWindow: 10 * time.Minute, FlapThreshold: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_ = svc.MarkAgentOffline(ctx, "paw1")
+		_ = svc.RegisterAgent(ctx, &entity.Agent{Paw: "paw1"})
+	}
+	_ = svc.MarkAgentOffline(ctx, "paw1")
+
+	if len(history.events) != 7 {
+		t.Fatalf("Expected every transition recorded, got %d", len(history.events))
+	}
+	if history.events[1].Suppressed || !history.events[2].Suppressed {
+		t.Errorf("Expected the transitions past the threshold suppressed, got %+v", history.events)
+	}
+	if got := sink.types(); len(got) != 2 || got[0] != entity.EventAgentOffline || got[1] != entity.EventAgentOnline {
+		t.Fatalf("Expected only the transitions within the threshold announced, got %v", got)
+	}
+
+	now := time.Now()
+	availability, err := svc.GetAvailability(ctx, "paw1", now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatalf("GetAvailability failed: %v", err)
+	}
+	if !availability.Flapping || availability.Transitions != 7 {
+		t.Errorf("Expected a flapping agent with 7 transitions, got %+v", availability)
+	}
+
+	// Once settled, the status differing from the one announced last is announced
+	_ = svc.CheckStaleAgents(ctx, time.Hour)
+	if len(sink.events) != 2 {
+		t.Fatalf("Expected nothing announced while flapping, got %v", sink.types())
+	}
+	svc.transitions["paw1"] = []time.Time{now.Add(-11 * time.Minute)}
+	_ = svc.CheckStaleAgents(ctx, time.Hour)
+	if got := sink.types(); len(got) != 3 || got[2] != entity.EventAgentOffline {
+		t.Errorf("Expected the settled offline status announced, got %v", got)
+	}
+	if repo.agents["paw1"].Status != entity.AgentOffline {
+		t.Error("Expected the agent offline")
+	}
+	if availability, _ := svc.GetAvailability(ctx, "paw1", now.Add(-time.Hour), now); availability.Flapping {
+		t.Error("Expected the settled agent no longer flapping")
+	}
+}
+
+func TestAgentService_GetAvailability_NotFound(t *testing.T) {
+	svc, _, _, _ := newAvailabilityTestService(AgentAvailabilityConfig{})
+	now := time.Now()
+	if _, err := svc.GetAvailability(context.Background(), "missing", now.Add(-time.Hour), now); err != ErrAgentNotFound {
+		t.Errorf("Expected ErrAgentNotFound, got %v", err)
+	}
+}
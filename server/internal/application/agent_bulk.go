@@ -0,0 +1,263 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// Bulk agent operation errors
+var (
+	ErrInvalidAgentBulk = errors.New("invalid bulk agent operation")
+	ErrAgentBulkStale   = errors.New("the agents matching the filter changed since the preview, preview again")
+)
+
+// AgentBulkAction is what a bulk operation does to the agents it matches
+type AgentBulkAction string
+
+const (
+	AgentBulkDelete     AgentBulkAction = "delete"     // Delete the matching agents that are not online
+	AgentBulkDeregister AgentBulkAction = "deregister" // Delete the matching agents whatever their status
+	AgentBulkTag        AgentBulkAction = "tag"        // Set tags, an empty value removing the tag
+	AgentBulkBeacon     AgentBulkAction = "beacon"     // Override the beacon of each matching agent
+)
+
+// AgentBulkOperation is a bulk operation on the agents matching a filter
+type AgentBulkOperation struct {
+	Action AgentBulkAction        `json:"action"`
+	Filter entity.AgentFilter     `json:"filter"`
+	Tags   map[string]string      `json:"tags,omitempty"`   // For tag
+	Beacon *entity.BeaconSettings `json:"beacon,omitempty"` // For beacon
+}
+
+// Validate checks the action, its parameters and the filter
+func (o *AgentBulkOperation) Validate() error {
+	switch o.Action {
+	case AgentBulkDelete, AgentBulkDeregister:
+	case AgentBulkTag:
+		if len(o.Tags) == 0 {
+			return errors.New("tags are required")
+		}
+		for key := range o.Tags {
+			if key == "" {
+				return errors.New("tag name is required")
+			}
+		}
+	case AgentBulkBeacon:
+		if o.Beacon == nil {
+			return errors.New("beacon is required")
+		}
+		if err := o.Beacon.Validate(); err != nil {
+			return err
+		}
+	default:
+		return errors.New("invalid action: " + string(o.Action))
+	}
+	return o.Filter.Validate()
+}
+
+// AgentBulkTarget is an agent a bulk operation matched
+type AgentBulkTarget struct {
+	Paw      string             `json:"paw"`
+	Hostname string             `json:"hostname"`
+	Platform string             `json:"platform"`
+	Status   entity.AgentStatus `json:"status"`
+	LastSeen time.Time          `json:"last_seen"`
+	Reason   string             `json:"reason,omitempty"` // Why a skipped agent is left alone
+}
+
+// AgentBulkPreview lists the agents a bulk operation would change and those
+// it matched but would leave alone. Its checksum identifies the operation and
+// the agents it changes, so that an apply can check nothing changed since.
+type AgentBulkPreview struct {
+	Action   AgentBulkAction   `json:"action"`
+	Agents   []AgentBulkTarget `json:"agents"`
+	Skipped  []AgentBulkTarget `json:"skipped"`
+	Checksum string            `json:"checksum"`
+}
+
+// AgentBulkResult summarizes an applied bulk operation
+type AgentBulkResult struct {
+	Preview *AgentBulkPreview `json:"preview"`
+	Applied int               `json:"applied"`
+	Errors  []string          `json:"errors,omitempty"`
+}
+
+// SetAgentResults skips the agents with execution results when deleting or
+// deregistering, the results referencing them
+func (s *AgentService) SetAgentResults(repo repository.AgentResultRepository) {
+	s.resultRepo = repo
+}
+
+// PreviewBulk lists the agents a bulk operation would change at now, without
+// changing anything
+func (s *AgentService) PreviewBulk(ctx context.Context, op *AgentBulkOperation, now time.Time) (*AgentBulkPreview, error) {
+	if err := op.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentBulk, err)
+	}
+	if op.Action == AgentBulkBeacon && s.beacons == nil {
+		return nil, fmt.Errorf("%w: beacon settings are not available", ErrInvalidAgentBulk)
+	}
+
+	agents, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Paw < agents[j].Paw })
+
+	var matched []*entity.Agent
+	for _, agent := range agents {
+		if op.Filter.Matches(agent, now) {
+			matched = append(matched, agent)
+		}
+	}
+	withResults, err := s.pawsWithResults(ctx, op, matched)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &AgentBulkPreview{Action: op.Action, Agents: []AgentBulkTarget{}, Skipped: []AgentBulkTarget{}}
+	for _, agent := range matched {
+		target := AgentBulkTarget{
+			Paw:      agent.Paw,
+			Hostname: agent.Hostname,
+			Platform: agent.Platform,
+			Status:   agent.Status,
+			LastSeen: agent.LastSeen,
+		}
+		if withResults[agent.Paw] {
+			target.Reason = "agent has execution results, which reference it"
+		} else {
+			target.Reason = bulkSkipReason(op, agent)
+		}
+		if target.Reason != "" {
+			preview.Skipped = append(preview.Skipped, target)
+			continue
+		}
+		preview.Agents = append(preview.Agents, target)
+	}
+
+	preview.Checksum, err = bulkChecksum(op, preview.Agents)
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// ApplyBulk previews a bulk operation again and applies it to the agents it
+// changes. Nothing is applied when the checksum of the reviewed preview no
+// longer matches. An agent that cannot be changed is reported and the others
+// are still changed.
+func (s *AgentService) ApplyBulk(ctx context.Context, op *AgentBulkOperation, checksum, userID string, now time.Time) (*AgentBulkResult, error) {
+	if checksum == "" {
+		return nil, fmt.Errorf("%w: the checksum of the reviewed preview is required", ErrInvalidAgentBulk)
+	}
+	preview, err := s.PreviewBulk(ctx, op, now)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != preview.Checksum {
+		return nil, ErrAgentBulkStale
+	}
+
+	result := &AgentBulkResult{Preview: preview}
+	for _, target := range preview.Agents {
+		if err := s.applyBulk(ctx, op, target.Paw, userID); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("agent %s: %v", target.Paw, err))
+			continue
+		}
+		result.Applied++
+	}
+	return result, nil
+}
+
+// applyBulk applies a bulk operation to an agent
+func (s *AgentService) applyBulk(ctx context.Context, op *AgentBulkOperation, paw, userID string) error {
+	switch op.Action {
+	case AgentBulkDelete, AgentBulkDeregister:
+		return s.repo.Delete(ctx, paw)
+	case AgentBulkTag:
+		agent, err := s.repo.FindByPaw(ctx, paw)
+		if err != nil {
+			return err
+		}
+		agent.Metadata = taggedMetadata(agent.Metadata, op.Tags)
+		return s.repo.Update(ctx, agent)
+	default:
+		_, err := s.beacons.SetAgent(ctx, paw, *op.Beacon, userID)
+		return err
+	}
+}
+
+// pawsWithResults finds which of the agents a delete or deregister matched
+// have execution results
+func (s *AgentService) pawsWithResults(ctx context.Context, op *AgentBulkOperation, agents []*entity.Agent) (map[string]bool, error) {
+	if s.resultRepo == nil || len(agents) == 0 || (op.Action != AgentBulkDelete && op.Action != AgentBulkDeregister) {
+		return nil, nil
+	}
+	paws := make([]string, len(agents))
+	for i, agent := range agents {
+		paws[i] = agent.Paw
+	}
+	return s.resultRepo.FindPawsWithResults(ctx, paws)
+}
+
+// bulkSkipReason returns why a matching agent is left alone, "" to change it
+func bulkSkipReason(op *AgentBulkOperation, agent *entity.Agent) string {
+	switch op.Action {
+	case AgentBulkDelete:
+		if agent.Status == entity.AgentOnline {
+			return "agent is online, deregister it instead"
+		}
+	case AgentBulkTag:
+		if maps.Equal(taggedMetadata(agent.Metadata, op.Tags), agent.Metadata) {
+			return "agent already has the tags"
+		}
+	}
+	return ""
+}
+
+// taggedMetadata returns a copy of metadata with tags set, empty tag values
+// removing the tag
+func taggedMetadata(metadata, tags map[string]string) map[string]string {
+	tagged := maps.Clone(metadata)
+	if tagged == nil {
+		tagged = make(map[string]string)
+	}
+	for key, value := range tags {
+		if value == "" {
+			delete(tagged, key)
+		} else {
+			tagged[key] = value
+		}
+	}
+	return tagged
+}
+
+// bulkChecksum identifies a bulk operation on agents: the hex SHA-256 of the
+// operation and of the paws of the agents it changes. Their last seen time is
+// left out, online agents checking in all the time.
+func bulkChecksum(op *AgentBulkOperation, agents []AgentBulkTarget) (string, error) {
+	paws := make([]string, len(agents))
+	for i, agent := range agents {
+		paws[i] = agent.Paw
+	}
+	hash := sha256.New()
+	for _, v := range []any{op, paws} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
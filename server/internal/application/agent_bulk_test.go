@@ -0,0 +1,213 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/persistence/sqlite"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newBulkTestService() (*AgentService, *mockAgentRepo, *BeaconService) {
+	beacons, repo, _ := newTestBeaconService()
+	now := time.Now()
+	repo.agents["lab-1"] = &entity.Agent{Paw: "lab-1", Platform: "linux", Status: entity.AgentOffline, LastSeen: now.Add(-96 * time.Hour)}
+	repo.agents["lab-2"] = &entity.Agent{Paw: "lab-2", Platform: "linux", Status: entity.AgentOnline, LastSeen: now.Add(-96 * time.Hour)}
+	repo.agents["lab-3"] = &entity.Agent{Paw: "lab-3", Platform: "linux", Status: entity.AgentOnline, LastSeen: now}
+	repo.agents["win-1"] = &entity.Agent{Paw: "win-1", Platform: "windows", Status: entity.AgentOffline, LastSeen: now.Add(-96 * time.Hour),
+		Metadata: map[string]string{"env": "lab"}}
+	svc := NewAgentService(repo)
+	svc.SetBeaconService(beacons)
+	return svc, repo, beacons
+}
+
+func TestAgentService_BulkDelete(t *testing.T) {
+	svc, repo, _ := newBulkTestService()
+	ctx := context.Background()
+	op := &AgentBulkOperation{Action: AgentBulkDelete, Filter: entity.AgentFilter{Platforms: []string{"linux"}, NotSeenFor: "72h"}}
+
+	preview, err := svc.PreviewBulk(ctx, op, time.Now())
+	if err != nil {
+		t.Fatalf("PreviewBulk failed: %v", err)
+	}
+	if len(preview.Agents) != 1 || preview.Agents[0].Paw != "lab-1" {
+		t.Fatalf("Expected only the offline stale agent deleted, got %+v", preview.Agents)
+	}
+	if len(preview.Skipped) != 1 || preview.Skipped[0].Paw != "lab-2" || preview.Skipped[0].Reason == "" {
+		t.Errorf("Expected the online stale agent skipped, got %+v", preview.Skipped)
+	}
+	if len(repo.agents) != 4 {
+		t.Error("Expected the preview to change nothing")
+	}
+
+	if _, err := svc.ApplyBulk(ctx, op, "", "user-1", time.Now()); !errors.Is(err, ErrInvalidAgentBulk) {
+		t.Errorf("Expected the checksum required, got %v", err)
+	}
+	result, err := svc.ApplyBulk(ctx, op, preview.Checksum, "user-1", time.Now())
+	if err != nil {
+		t.Fatalf("ApplyBulk failed: %v", err)
+	}
+	if result.Applied != 1 || repo.agents["lab-1"] != nil || repo.agents["lab-2"] == nil {
+		t.Errorf("Expected only lab-1 deleted, got %+v", result)
+	}
+
+	// The fleet changed since the preview
+	if _, err := svc.ApplyBulk(ctx, op, preview.Checksum, "user-1", time.Now()); !errors.Is(err, ErrAgentBulkStale) {
+		t.Errorf("Expected ErrAgentBulkStale, got %v", err)
+	}
+}
+
+func TestAgentService_BulkDeregister(t *testing.T) {
+	svc, repo, _ := newBulkTestService()
+	ctx := context.Background()
+	op := &AgentBulkOperation{Action: AgentBulkDeregister, Filter: entity.AgentFilter{NotSeenFor: "72h"}}
+
+	preview, _ := svc.PreviewBulk(ctx, op, time.Now())
+	result, err := svc.ApplyBulk(ctx, op, preview.Checksum, "user-1", time.Now())
+	if err != nil {
+		t.Fatalf("ApplyBulk failed: %v", err)
+	}
+	if result.Applied != 3 || len(repo.agents) != 1 || repo.agents["lab-3"] == nil {
+		t.Errorf("Expected every stale agent deregistered, got %+v", result)
+	}
+}
+
+// openSQLiteTestDB opens a database file as the server does, enforcing the
+// foreign keys
+func openSQLiteTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(sqlite.DefaultConfig(filepath.Join(t.TempDir(), "autostrike.db")))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if err := sqlite.InitSchema(db); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+	return db
+}
+
+func TestAgentService_BulkDeregister_AgentWithResults(t *testing.T) {
+	db := openSQLiteTestDB(t)
+	for _, query := range []string{
+		`INSERT INTO agents (paw, hostname, username, platform, executors, status, last_seen, created_at)
+			VALUES ('lab-1', 'lab-1', 'root', 'linux', '["sh"]', 'offline', datetime('now'), datetime('now')),
+			('lab-2', 'lab-2', 'root', 'linux', '["sh"]', 'offline', datetime('now'), datetime('now'))`,
+		`INSERT INTO scenarios (id, name, description, phases, tags, created_at, updated_at)
+			VALUES ('s1', 'Discovery', '', '[]', '', datetime('now'), datetime('now'))`,
+		`INSERT INTO techniques (id, name, description, tactic, platforms, executors, detection, is_safe, created_at)
+			VALUES ('T1082', 'System Information Discovery', '', 'discovery', '["linux"]', '["sh"]', '', 1, datetime('now'))`,
+		`INSERT INTO executions (id, scenario_id, status, started_at, safe_mode) VALUES ('e1', 's1', 'completed', datetime('now'), 1)`,
+		`INSERT INTO execution_results (id, execution_id, technique_id, agent_paw, status, started_at)
+			VALUES ('r1', 'e1', 'T1082', 'lab-1', 'success', datetime('now'))`,
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Failed to seed the database: %v", err)
+		}
+	}
+
+	agentRepo := sqlite.NewAgentRepository(db)
+	svc := NewAgentService(agentRepo)
+	svc.SetAgentResults(sqlite.NewResultRepository(db))
+	ctx := context.Background()
+
+	for _, action := range []AgentBulkAction{AgentBulkDelete, AgentBulkDeregister} {
+		op := &AgentBulkOperation{Action: action, Filter: entity.AgentFilter{Platforms: []string{"linux"}}}
+		preview, err := svc.PreviewBulk(ctx, op, time.Now())
+		if err != nil {
+			t.Fatalf("PreviewBulk failed: %v", err)
+		}
+		if len(preview.Skipped) != 1 || preview.Skipped[0].Paw != "lab-1" || preview.Skipped[0].Reason == "" {
+			t.Errorf("Expected the agent with results skipped on %s, got %+v", action, preview.Skipped)
+		}
+		result, err := svc.ApplyBulk(ctx, op, preview.Checksum, "user-1", time.Now())
+		if err != nil {
+			t.Fatalf("ApplyBulk failed: %v", err)
+		}
+		if len(result.Errors) != 0 {
+			t.Errorf("Expected no error on %s, got %v", action, result.Errors)
+		}
+	}
+
+	if _, err := agentRepo.FindByPaw(ctx, "lab-1"); err != nil {
+		t.Errorf("Expected the agent with results kept, got %v", err)
+	}
+	if _, err := agentRepo.FindByPaw(ctx, "lab-2"); err == nil {
+		t.Error("Expected the agent without results deleted")
+	}
+}
+
+func TestAgentService_BulkTag(t *testing.T) {
+	svc, repo, _ := newBulkTestService()
+	ctx := context.Background()
+	op := &AgentBulkOperation{
+		Action: AgentBulkTag,
+		Filter: entity.AgentFilter{Paws: []string{"lab-1", "win-1"}},
+		Tags:   map[string]string{"env": "lab"},
+	}
+
+	preview, _ := svc.PreviewBulk(ctx, op, time.Now())
+	if len(preview.Agents) != 1 || len(preview.Skipped) != 1 || preview.Skipped[0].Paw != "win-1" {
+		t.Fatalf("Expected the agent already tagged skipped, got %+v", preview)
+	}
+	if _, err := svc.ApplyBulk(ctx, op, preview.Checksum, "user-1", time.Now()); err != nil {
+		t.Fatalf("ApplyBulk failed: %v", err)
+	}
+	if repo.agents["lab-1"].Metadata["env"] != "lab" {
+		t.Errorf("Expected lab-1 tagged, got %v", repo.agents["lab-1"].Metadata)
+	}
+
+	// An empty value removes the tag
+	op.Tags = map[string]string{"env": ""}
+	preview, _ = svc.PreviewBulk(ctx, op, time.Now())
+	_, _ = svc.ApplyBulk(ctx, op, preview.Checksum, "user-1", time.Now())
+	if _, ok := repo.agents["win-1"].Metadata["env"]; ok || len(repo.agents["lab-1"].Metadata) != 0 {
+		t.Errorf("Expected the tag removed, got %v and %v", repo.agents["lab-1"].Metadata, repo.agents["win-1"].Metadata)
+	}
+}
+
+func TestAgentService_BulkBeacon(t *testing.T) {
+	svc, _, beacons := newBulkTestService()
+	ctx := context.Background()
+	op := &AgentBulkOperation{
+		Action: AgentBulkBeacon,
+		Filter: entity.AgentFilter{Platforms: []string{"windows"}},
+		Beacon: &entity.BeaconSettings{Interval: 300, Jitter: 10},
+	}
+
+	preview, err := svc.PreviewBulk(ctx, op, time.Now())
+	if err != nil {
+		t.Fatalf("PreviewBulk failed: %v", err)
+	}
+	if _, err := svc.ApplyBulk(ctx, op, preview.Checksum, "user-1", time.Now()); err != nil {
+		t.Fatalf("ApplyBulk failed: %v", err)
+	}
+	if beacon, _ := beacons.ResolveAgent(ctx, "win-1"); beacon.Source != BeaconSourceAgent || beacon.Interval != 300 {
+		t.Errorf("Expected the agent beacon overridden, got %+v", beacon)
+	}
+}
+
+func TestAgentService_PreviewBulk_Invalid(t *testing.T) {
+	svc, _, _ := newBulkTestService()
+	tests := []struct {
+		name string
+		op   AgentBulkOperation
+	}{
+		{"unknown action", AgentBulkOperation{Action: "reboot", Filter: entity.AgentFilter{Platforms: []string{"linux"}}}},
+		{"no filter", AgentBulkOperation{Action: AgentBulkDeregister}},
+		{"no tags", AgentBulkOperation{Action: AgentBulkTag, Filter: entity.AgentFilter{Platforms: []string{"linux"}}}},
+		{"invalid beacon", AgentBulkOperation{Action: AgentBulkBeacon, Filter: entity.AgentFilter{Platforms: []string{"linux"}},
+			Beacon: &entity.BeaconSettings{Interval: 0}}},
+	}
+	for _, tt := range tests {
+		if _, err := svc.PreviewBulk(context.Background(), &tt.op, time.Now()); !errors.Is(err, ErrInvalidAgentBulk) {
+			t.Errorf("%s: expected ErrInvalidAgentBulk, got %v", tt.name, err)
+		}
+	}
+}
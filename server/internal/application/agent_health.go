@@ -0,0 +1,78 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// AgentBeaconRetention is how long the check-in history of agents is kept
+const AgentBeaconRetention = 30 * 24 * time.Hour
+
+// SetBeaconHistory records the check-ins of agents in repo, which may be nil.
+// Check-ins are only recorded with a beacon service to tell their expected beacon.
+func (s *AgentService) SetBeaconHistory(repo repository.AgentBeaconRepository) {
+	s.beaconRepo = repo
+}
+
+// CheckIn records a heartbeat of an agent, sent at sentAt by the agent clock
+// when known, then updates its last seen timestamp. The check-in is measured
+// against the previous one and the beacon the agent is told to keep.
+func (s *AgentService) CheckIn(ctx context.Context, paw string, sentAt *time.Time) error {
+	if s.beaconRepo != nil && s.beacons != nil {
+		s.recordBeacon(ctx, paw, sentAt, time.Now())
+	}
+	return s.Heartbeat(ctx, paw)
+}
+
+// recordBeacon stores a check-in of an agent received at receivedAt
+func (s *AgentService) recordBeacon(ctx context.Context, paw string, sentAt *time.Time, receivedAt time.Time) {
+	agent, err := s.repo.FindByPaw(ctx, paw)
+	if err != nil || agent == nil {
+		return
+	}
+	beacon, err := s.beacons.Resolve(ctx, agent)
+	if err != nil {
+		return
+	}
+
+	var previous *time.Time
+	if last := agent.LastSeen; !last.IsZero() && last.Before(receivedAt) {
+		previous = &last
+	}
+	// The history is best effort, the heartbeat itself must go through
+	_ = s.beaconRepo.Create(ctx, entity.NewAgentBeacon(paw, beacon.BeaconSettings, previous, sentAt, receivedAt))
+}
+
+// GetHealth rates how reliably an agent checked in from since to now, from
+// its check-ins and availability history
+func (s *AgentService) GetHealth(ctx context.Context, paw string, since, now time.Time) (*entity.AgentHealth, error) {
+	agent, err := s.repo.FindByPaw(ctx, paw)
+	if err != nil || agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	var beacons []*entity.AgentBeacon
+	if s.beaconRepo != nil {
+		if beacons, err = s.beaconRepo.FindByAgent(ctx, paw, since); err != nil {
+			return nil, err
+		}
+	}
+	var events []*entity.AgentAvailabilityEvent
+	if s.availabilityRepo != nil {
+		if events, err = s.availabilityRepo.FindByAgent(ctx, paw, since); err != nil {
+			return nil, err
+		}
+	}
+	return entity.ComputeAgentHealth(paw, agent.Status, beacons, events, since, now), nil
+}
+
+// PurgeBeacons deletes the check-in history older than AgentBeaconRetention
+func (s *AgentService) PurgeBeacons(ctx context.Context, now time.Time) (int64, error) {
+	if s.beaconRepo == nil {
+		return 0, nil
+	}
+	return s.beaconRepo.DeleteBefore(ctx, now.Add(-AgentBeaconRetention))
+}
@@ -0,0 +1,94 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockAgentBeaconRepo implements repository.AgentBeaconRepository for tests
+type mockAgentBeaconRepo struct {
+	beacons []*entity.AgentBeacon
+}
+
+func (m *mockAgentBeaconRepo) Create(ctx context.Context, beacon *entity.AgentBeacon) error {
+	m.beacons = append(m.beacons, beacon)
+	return nil
+}
+
+func (m *mockAgentBeaconRepo) FindByAgent(ctx context.Context, paw string, since time.Time) ([]*entity.AgentBeacon, error) {
+	var beacons []*entity.AgentBeacon
+	for _, beacon := range m.beacons {
+		if beacon.AgentPaw == paw && !beacon.ReceivedAt.Before(since) {
+			beacons = append(beacons, beacon)
+		}
+	}
+	return beacons, nil
+}
+
+func (m *mockAgentBeaconRepo) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestAgentService_CheckIn(t *testing.T) {
+	beacons, repo, _ := newTestBeaconService()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Status: entity.AgentOnline, LastSeen: time.Now().Add(-95 * time.Second)}
+	history := &mockAgentBeaconRepo{}
+	svc := NewAgentService(repo)
+	svc.SetBeaconService(beacons)
+	svc.SetBeaconHistory(history)
+	ctx := context.Background()
+
+	sentAt := time.Now().Add(-250 * time.Millisecond)
+	if err := svc.CheckIn(ctx, "paw1", &sentAt); err != nil {
+		t.Fatalf("CheckIn failed: %v", err)
+	}
+	if len(history.beacons) != 1 {
+		t.Fatalf("Expected the check-in recorded, got %d", len(history.beacons))
+	}
+	beacon := history.beacons[0]
+	if beacon.Interval != 30 || beacon.Missed != 2 || beacon.OnTime() {
+		t.Errorf("Expected a late check-in against the default beacon with 2 missed, got %+v", beacon)
+	}
+	if beacon.Latency == nil || *beacon.Latency < 250*time.Millisecond {
+		t.Errorf("Expected the latency measured from the agent clock, got %v", beacon.Latency)
+	}
+	if time.Since(repo.agents["paw1"].LastSeen) > time.Second {
+		t.Error("Expected the last seen timestamp updated")
+	}
+
+	_ = svc.CheckIn(ctx, "paw1", nil)
+	if len(history.beacons) != 2 || !history.beacons[1].OnTime() || history.beacons[1].Latency != nil {
+		t.Errorf("Expected an on-time check-in without latency, got %+v", history.beacons[1])
+	}
+
+	health, err := svc.GetHealth(ctx, "paw1", time.Now().Add(-time.Hour), time.Now())
+	if err != nil {
+		t.Fatalf("GetHealth failed: %v", err)
+	}
+	if health.Beacons != 2 || health.MissedBeacons != 2 || health.AdherencePercent != 50 || health.Health != entity.AgentDegraded {
+		t.Errorf("Expected a degraded agent with half its check-ins late, got %+v", health)
+	}
+}
+
+func TestAgentService_CheckIn_WithoutHistory(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Status: entity.AgentOnline}
+	svc := NewAgentService(repo)
+	if err := svc.CheckIn(context.Background(), "paw1", nil); err != nil {
+		t.Fatalf("CheckIn failed: %v", err)
+	}
+
+	health, err := svc.GetHealth(context.Background(), "paw1", time.Now().Add(-time.Hour), time.Now())
+	if err != nil {
+		t.Fatalf("GetHealth failed: %v", err)
+	}
+	if health.Health != entity.AgentHealthUnknown || health.UptimePercent != 100 {
+		t.Errorf("Expected an unknown health without check-ins, got %+v", health)
+	}
+	if _, err := svc.GetHealth(context.Background(), "missing", time.Now().Add(-time.Hour), time.Now()); err != ErrAgentNotFound {
+		t.Errorf("Expected ErrAgentNotFound, got %v", err)
+	}
+}
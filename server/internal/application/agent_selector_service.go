@@ -0,0 +1,171 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+// Agent selector errors
+var (
+	ErrAgentSelectorNotFound  = errors.New("agent selector not found")
+	ErrInvalidAgentSelector   = errors.New("invalid agent selector")
+	ErrAgentSelectorNameTaken = errors.New("an agent selector with this name already exists")
+	ErrNoMatchingAgents       = errors.New("no online agent matches the selector")
+)
+
+// AgentSelectorService manages saved agent selectors and resolves them to agents
+type AgentSelectorService struct {
+	selectorRepo repository.AgentSelectorRepository
+	agentRepo    repository.AgentRepository
+}
+
+// NewAgentSelectorService creates a new agent selector service
+func NewAgentSelectorService(
+	selectorRepo repository.AgentSelectorRepository,
+	agentRepo repository.AgentRepository,
+) *AgentSelectorService {
+	return &AgentSelectorService{selectorRepo: selectorRepo, agentRepo: agentRepo}
+}
+
+// List returns all saved agent selectors
+func (s *AgentSelectorService) List(ctx context.Context) ([]*entity.AgentSelector, error) {
+	return s.selectorRepo.FindAll(ctx)
+}
+
+// Get returns a saved agent selector
+func (s *AgentSelectorService) Get(ctx context.Context, id string) (*entity.AgentSelector, error) {
+	selector, err := s.selectorRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAgentSelectorNotFound
+		}
+		return nil, err
+	}
+	if selector == nil {
+		return nil, ErrAgentSelectorNotFound
+	}
+	return selector, nil
+}
+
+// Create saves a new agent selector
+func (s *AgentSelectorService) Create(ctx context.Context, selector *entity.AgentSelector, userID string) (*entity.AgentSelector, error) {
+	if err := s.validate(ctx, selector, ""); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	selector.ID = uuid.New().String()
+	selector.CreatedBy = userID
+	selector.CreatedAt = now
+	selector.UpdatedAt = now
+
+	if err := s.selectorRepo.Create(ctx, selector); err != nil {
+		return nil, fmt.Errorf("failed to create agent selector: %w", err)
+	}
+	return selector, nil
+}
+
+// Update replaces the name, description and criteria of a saved agent selector
+func (s *AgentSelectorService) Update(ctx context.Context, id string, update *entity.AgentSelector) (*entity.AgentSelector, error) {
+	selector, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, update, id); err != nil {
+		return nil, err
+	}
+
+	selector.Name = update.Name
+	selector.Description = update.Description
+	selector.Platforms = update.Platforms
+	selector.Statuses = update.Statuses
+	selector.Executors = update.Executors
+	selector.Tags = update.Tags
+	selector.HostnamePattern = update.HostnamePattern
+	selector.Architectures = update.Architectures
+	selector.Elevated = update.Elevated
+	selector.Domains = update.Domains
+	selector.SecurityProducts = update.SecurityProducts
+	selector.Interpreters = update.Interpreters
+	selector.UpdatedAt = time.Now()
+
+	if err := s.selectorRepo.Update(ctx, selector); err != nil {
+		return nil, fmt.Errorf("failed to update agent selector: %w", err)
+	}
+	return selector, nil
+}
+
+// Delete removes a saved agent selector
+func (s *AgentSelectorService) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.selectorRepo.Delete(ctx, id)
+}
+
+// MatchingAgents returns every registered agent the selector currently matches
+func (s *AgentSelectorService) MatchingAgents(ctx context.Context, id string) ([]*entity.Agent, error) {
+	selector, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	agents, err := s.agentRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agents: %w", err)
+	}
+
+	matches := make([]*entity.Agent, 0, len(agents))
+	for _, agent := range agents {
+		if selector.Matches(agent) {
+			matches = append(matches, agent)
+		}
+	}
+	return matches, nil
+}
+
+// ResolveAgentPaws returns the paws of the online agents the selector matches,
+// for launching an execution. Offline matches are skipped.
+func (s *AgentSelectorService) ResolveAgentPaws(ctx context.Context, id string) ([]string, error) {
+	agents, err := s.MatchingAgents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var paws []string
+	for _, agent := range agents {
+		if agent.Status == entity.AgentOnline {
+			paws = append(paws, agent.Paw)
+		}
+	}
+	if len(paws) == 0 {
+		return nil, ErrNoMatchingAgents
+	}
+	return paws, nil
+}
+
+// validate checks the selector criteria and that its name is not used by another selector
+func (s *AgentSelectorService) validate(ctx context.Context, selector *entity.AgentSelector, id string) error {
+	if err := selector.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAgentSelector, err)
+	}
+
+	existing, err := s.selectorRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.Name == selector.Name && other.ID != id {
+			return ErrAgentSelectorNameTaken
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/persistence/sqlite"
+
+	"go.uber.org/zap"
+)
+
+// mockAgentSelectorRepo implements repository.AgentSelectorRepository for tests
+type mockAgentSelectorRepo struct {
+	selectors map[string]*entity.AgentSelector
+	err       error
+}
+
+func newMockAgentSelectorRepo() *mockAgentSelectorRepo {
+	return &mockAgentSelectorRepo{selectors: make(map[string]*entity.AgentSelector)}
+}
+
+func (m *mockAgentSelectorRepo) Create(ctx context.Context, selector *entity.AgentSelector) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.selectors[selector.ID] = selector
+	return nil
+}
+
+func (m *mockAgentSelectorRepo) Update(ctx context.Context, selector *entity.AgentSelector) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.selectors[selector.ID] = selector
+	return nil
+}
+
+func (m *mockAgentSelectorRepo) Delete(ctx context.Context, id string) error {
+	delete(m.selectors, id)
+	return nil
+}
+
+func (m *mockAgentSelectorRepo) FindByID(ctx context.Context, id string) (*entity.AgentSelector, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	selector, ok := m.selectors[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return selector, nil
+}
+
+func (m *mockAgentSelectorRepo) FindAll(ctx context.Context) ([]*entity.AgentSelector, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var selectors []*entity.AgentSelector
+	for _, selector := range m.selectors {
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+func setupAgentSelectorService() (*AgentSelectorService, *mockAgentSelectorRepo, *mockAgentRepo) {
+	selectorRepo := newMockAgentSelectorRepo()
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["web-1"] = &entity.Agent{Paw: "web-1", Hostname: "web-01", Platform: "linux",
+		Status: entity.AgentOnline, Metadata: map[string]string{"env": "prod"}}
+	agentRepo.agents["web-2"] = &entity.Agent{Paw: "web-2", Hostname: "web-02", Platform: "linux",
+		Status: entity.AgentOffline, Metadata: map[string]string{"env": "prod"}}
+	agentRepo.agents["win-1"] = &entity.Agent{Paw: "win-1", Hostname: "desk-01", Platform: "windows",
+		Status: entity.AgentOnline, Metadata: map[string]string{"env": "prod"}}
+	return NewAgentSelectorService(selectorRepo, agentRepo), selectorRepo, agentRepo
+}
+
+func TestAgentSelectorService_CreateAndResolve(t *testing.T) {
+	svc, _, _ := setupAgentSelectorService()
+	ctx := context.Background()
+
+	selector, err := svc.Create(ctx, &entity.AgentSelector{
+		Name:            "Linux prod web",
+		Platforms:       []string{"linux"},
+		Tags:            map[string]string{"env": "prod"},
+		HostnamePattern: "web-*",
+	}, "user-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if selector.ID == "" || selector.CreatedBy != "user-1" || selector.CreatedAt.IsZero() {
+		t.Errorf("Expected ID, creator and timestamps to be set, got %+v", selector)
+	}
+
+	agents, err := svc.MatchingAgents(ctx, selector.ID)
+	if err != nil {
+		t.Fatalf("MatchingAgents failed: %v", err)
+	}
+	var paws []string
+	for _, agent := range agents {
+		paws = append(paws, agent.Paw)
+	}
+	sort.Strings(paws)
+	if len(paws) != 2 || paws[0] != "web-1" || paws[1] != "web-2" {
+		t.Errorf("Expected web-1 and web-2 to match, got %v", paws)
+	}
+
+	// Launches only target the online matches
+	resolved, err := svc.ResolveAgentPaws(ctx, selector.ID)
+	if err != nil {
+		t.Fatalf("ResolveAgentPaws failed: %v", err)
+	}
+	if len(resolved) != 1 || resolved[0] != "web-1" {
+		t.Errorf("Expected only web-1, got %v", resolved)
+	}
+}
+
+func TestAgentSelectorService_ResolveTagsFromDatabase(t *testing.T) {
+	db := openSQLiteTestDB(t)
+	agentRepo := sqlite.NewAgentRepository(db)
+	agents := NewAgentService(agentRepo)
+	svc := NewAgentSelectorService(sqlite.NewAgentSelectorRepository(db), agentRepo)
+	ctx := context.Background()
+
+	for _, paw := range []string{"web-1", "web-2"} {
+		if err := agents.RegisterAgent(ctx, &entity.Agent{Paw: paw, Hostname: paw, Platform: "linux", Executors: []string{"sh"}}); err != nil {
+			t.Fatalf("RegisterAgent failed: %v", err)
+		}
+	}
+	op := &AgentBulkOperation{Action: AgentBulkTag, Tags: map[string]string{"env": "prod"}, Filter: entity.AgentFilter{Paws: []string{"web-1"}}}
+	bulk, err := agents.PreviewBulk(ctx, op, time.Now())
+	if err != nil {
+		t.Fatalf("PreviewBulk failed: %v", err)
+	}
+	if _, err := agents.ApplyBulk(ctx, op, bulk.Checksum, "user-1", time.Now()); err != nil {
+		t.Fatalf("ApplyBulk failed: %v", err)
+	}
+	// The tags outlive the agent registering again
+	if err := agents.RegisterAgent(ctx, &entity.Agent{Paw: "web-1", Hostname: "web-1", Platform: "linux", Executors: []string{"sh"}}); err != nil {
+		t.Fatalf("RegisterAgent failed: %v", err)
+	}
+
+	selector, err := svc.Create(ctx, &entity.AgentSelector{Name: "Prod", Tags: map[string]string{"env": "prod"}}, "user-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	paws, err := svc.ResolveAgentPaws(ctx, selector.ID)
+	if err != nil {
+		t.Fatalf("ResolveAgentPaws failed: %v", err)
+	}
+	if len(paws) != 1 || paws[0] != "web-1" {
+		t.Errorf("Expected only the tagged agent, got %v", paws)
+	}
+}
+
+func TestAgentSelectorService_ResolveNoOnlineMatch(t *testing.T) {
+	svc, _, _ := setupAgentSelectorService()
+	ctx := context.Background()
+
+	selector, err := svc.Create(ctx, &entity.AgentSelector{Name: "macOS", Platforms: []string{"darwin"}}, "user-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if _, err := svc.ResolveAgentPaws(ctx, selector.ID); !errors.Is(err, ErrNoMatchingAgents) {
+		t.Errorf("Expected ErrNoMatchingAgents, got %v", err)
+	}
+}
+
+func TestAgentSelectorService_Validation(t *testing.T) {
+	svc, _, _ := setupAgentSelectorService()
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, &entity.AgentSelector{Name: "bad", HostnamePattern: "web-["}, "u"); !errors.Is(err, ErrInvalidAgentSelector) {
+		t.Errorf("Expected ErrInvalidAgentSelector, got %v", err)
+	}
+
+	first, err := svc.Create(ctx, &entity.AgentSelector{Name: "Linux"}, "u")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := svc.Create(ctx, &entity.AgentSelector{Name: "Linux"}, "u"); !errors.Is(err, ErrAgentSelectorNameTaken) {
+		t.Errorf("Expected ErrAgentSelectorNameTaken, got %v", err)
+	}
+
+	// Keeping its own name on update is allowed
+	if _, err := svc.Update(ctx, first.ID, &entity.AgentSelector{Name: "Linux", Platforms: []string{"linux"}}); err != nil {
+		t.Errorf("Update keeping the name failed: %v", err)
+	}
+}
+
+func TestAgentSelectorService_UpdateAndDelete(t *testing.T) {
+	svc, repo, _ := setupAgentSelectorService()
+	ctx := context.Background()
+
+	selector, _ := svc.Create(ctx, &entity.AgentSelector{Name: "Linux", Platforms: []string{"linux"}}, "user-1")
+	createdAt := selector.CreatedAt
+
+	updated, err := svc.Update(ctx, selector.ID, &entity.AgentSelector{Name: "Windows", Platforms: []string{"windows"}})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Name != "Windows" || updated.Platforms[0] != "windows" || !updated.CreatedAt.Equal(createdAt) || updated.CreatedBy != "user-1" {
+		t.Errorf("Unexpected updated selector: %+v", updated)
+	}
+
+	if err := svc.Delete(ctx, selector.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(repo.selectors) != 0 {
+		t.Error("Expected selector to be deleted")
+	}
+	if err := svc.Delete(ctx, selector.ID); !errors.Is(err, ErrAgentSelectorNotFound) {
+		t.Errorf("Expected ErrAgentSelectorNotFound, got %v", err)
+	}
+	if _, err := svc.Update(ctx, "missing", &entity.AgentSelector{Name: "x"}); !errors.Is(err, ErrAgentSelectorNotFound) {
+		t.Errorf("Expected ErrAgentSelectorNotFound, got %v", err)
+	}
+}
+
+func TestAgentSelectorService_RepositoryErrors(t *testing.T) {
+	svc, repo, agentRepo := setupAgentSelectorService()
+	ctx := context.Background()
+
+	selector, _ := svc.Create(ctx, &entity.AgentSelector{Name: "Linux"}, "user-1")
+	agentRepo.findErr = errors.New("db error")
+	if _, err := svc.MatchingAgents(ctx, selector.ID); err == nil {
+		t.Error("Expected agent repository error")
+	}
+
+	repo.err = errors.New("db error")
+	if _, err := svc.Get(ctx, selector.ID); err == nil || errors.Is(err, ErrAgentSelectorNotFound) {
+		t.Errorf("Expected repository error, got %v", err)
+	}
+	if _, err := svc.Create(ctx, &entity.AgentSelector{Name: "Other"}, "u"); err == nil {
+		t.Error("Expected repository error on create")
+	}
+}
+
+func TestScheduleService_RunNow_WithAgentSelector(t *testing.T) {
+	repo := newMockScheduleRepo()
+	svc := NewScheduleService(repo, buildTestExecutionService(), zap.NewNop())
+
+	selectorRepo := newMockAgentSelectorRepo()
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["agent-1"] = &entity.Agent{Paw: "agent-1", Platform: "linux", Status: entity.AgentOnline}
+	selectors := NewAgentSelectorService(selectorRepo, agentRepo)
+	svc.SetAgentSelectorService(selectors)
+
+	linux, _ := selectors.Create(context.Background(), &entity.AgentSelector{Name: "Linux", Platforms: []string{"linux"}}, "u")
+	windows, _ := selectors.Create(context.Background(), &entity.AgentSelector{Name: "Windows", Platforms: []string{"windows"}}, "u")
+
+	repo.schedules["sched-1"] = &entity.Schedule{
+		ID: "sched-1", Name: "Selector Schedule", ScenarioID: "scenario-1",
+		AgentSelectorID: linux.ID, Frequency: entity.FrequencyDaily, Status: entity.ScheduleStatusActive,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+
+	run, err := svc.RunNow(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	if run.Status != "started" {
+		t.Errorf("Run status = %q, want started (error: %s)", run.Status, run.Error)
+	}
+
+	// The selector is resolved at run time, so a selector without online agents fails the run
+	repo.schedules["sched-1"].AgentSelectorID = windows.ID
+	run, err = svc.RunNow(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	if run.Status != "failed" || run.Error == "" {
+		t.Errorf("Expected a failed run, got %+v", run)
+	}
+}
+
+func TestScheduleService_Create_UnknownAgentSelector(t *testing.T) {
+	svc := NewScheduleService(newMockScheduleRepo(), nil, zap.NewNop())
+	svc.SetAgentSelectorService(NewAgentSelectorService(newMockAgentSelectorRepo(), newMockAgentRepo()))
+
+	_, err := svc.Create(context.Background(), &CreateScheduleRequest{
+		Name: "s", ScenarioID: "scenario-1", AgentSelectorID: "missing", Frequency: entity.FrequencyDaily,
+	}, "user-1")
+	if !errors.Is(err, ErrAgentSelectorNotFound) {
+		t.Errorf("Expected ErrAgentSelectorNotFound, got %v", err)
+	}
+}
@@ -2,16 +2,35 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"autostrike/internal/domain/entity"
 	"autostrike/internal/domain/repository"
 )
 
+var (
+	ErrAgentNotFound        = errors.New("agent not found")
+	ErrInvalidAgentNetworks = errors.New("invalid agent networks")
+)
+
 // AgentService handles agent-related business logic
 type AgentService struct {
-	repo repository.AgentRepository
+	repo    repository.AgentRepository
+	events  *EventBus
+	beacons *BeaconService
+
+	availabilityRepo repository.AgentAvailabilityRepository
+	availability     AgentAvailabilityConfig
+	availabilityMu   sync.Mutex
+	disconnectedAt   map[string]time.Time          // Agents disconnected within their grace period, by paw
+	transitions      map[string][]time.Time        // Transitions within the flap window, by paw
+	held             map[string]entity.AgentStatus // Status announced last for flapping agents, by paw
+
+	beaconRepo repository.AgentBeaconRepository
+	resultRepo repository.AgentResultRepository
 }
 
 // NewAgentService creates a new agent service
@@ -19,29 +38,56 @@ func NewAgentService(repo repository.AgentRepository) *AgentService {
 	return &AgentService{repo: repo}
 }
 
+// SetEventBus publishes an event when an agent comes online or goes offline
+func (s *AgentService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// SetBeaconService gives agents with a long beacon interval more time to
+// check in before they are marked offline
+func (s *AgentService) SetBeaconService(beacons *BeaconService) {
+	s.beacons = beacons
+}
+
 // RegisterAgent registers a new agent or updates existing one
 func (s *AgentService) RegisterAgent(ctx context.Context, agent *entity.Agent) error {
+	s.reconnected(agent.Paw)
+	now := time.Now()
 	existing, err := s.repo.FindByPaw(ctx, agent.Paw)
 	if err == nil && existing != nil {
 		// Update existing agent
+		wasOnline := existing.Status == entity.AgentOnline
 		existing.Status = entity.AgentOnline
-		existing.LastSeen = time.Now()
+		existing.LastSeen = now
 		existing.Platform = agent.Platform
 		existing.Executors = agent.Executors
 		existing.Hostname = agent.Hostname
 		existing.Username = agent.Username
-		return s.repo.Update(ctx, existing)
+		existing.Version = agent.Version
+		existing.AgentInventory = agent.AgentInventory
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		if !wasOnline {
+			s.announceTransition(ctx, existing, entity.AvailabilityRegistered, now)
+		}
+		return nil
 	}
 
 	// Create new agent
 	agent.Status = entity.AgentOnline
-	agent.LastSeen = time.Now()
-	agent.CreatedAt = time.Now()
-	return s.repo.Create(ctx, agent)
+	agent.LastSeen = now
+	agent.CreatedAt = now
+	if err := s.repo.Create(ctx, agent); err != nil {
+		return err
+	}
+	s.announceTransition(ctx, agent, entity.AvailabilityRegistered, now)
+	return nil
 }
 
 // Heartbeat updates agent's last seen timestamp
 func (s *AgentService) Heartbeat(ctx context.Context, paw string) error {
+	s.reconnected(paw)
 	return s.repo.UpdateLastSeen(ctx, paw)
 }
 
@@ -60,15 +106,65 @@ func (s *AgentService) GetOnlineAgents(ctx context.Context) ([]*entity.Agent, er
 	return s.repo.FindByStatus(ctx, entity.AgentOnline)
 }
 
-// MarkAgentOffline marks an agent as offline
+// MarkAgentOffline marks an agent as offline once its connection closed. With
+// an offline grace period, the agent stays online until CheckStaleAgents finds
+// it did not reconnect in time.
 func (s *AgentService) MarkAgentOffline(ctx context.Context, paw string) error {
 	agent, err := s.repo.FindByPaw(ctx, paw)
 	if err != nil {
 		return fmt.Errorf("agent not found: %w", err)
 	}
 
+	now := time.Now()
+	wasOnline := agent.Status == entity.AgentOnline
+	if wasOnline && s.disconnected(paw, now) {
+		return nil
+	}
 	agent.Status = entity.AgentOffline
-	return s.repo.Update(ctx, agent)
+	if err := s.repo.Update(ctx, agent); err != nil {
+		return err
+	}
+
+	if wasOnline {
+		s.announceTransition(ctx, agent, entity.AvailabilityDisconnected, now)
+	}
+	return nil
+}
+
+// SetAllowedNetworks pins the networks an agent is expected to connect from,
+// in CIDR notation; an empty list expects any network
+func (s *AgentService) SetAllowedNetworks(ctx context.Context, paw string, networks []string) (*entity.Agent, error) {
+	parsed, err := entity.ParseNetworkList(networks)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentNetworks, err)
+	}
+	agent, err := s.repo.FindByPaw(ctx, paw)
+	if err != nil || agent == nil {
+		return nil, ErrAgentNotFound
+	}
+
+	agent.AllowedNetworks = parsed.Strings()
+	if err := s.repo.Update(ctx, agent); err != nil {
+		return nil, err
+	}
+	return agent, nil
+}
+
+// RecordSource keeps the address an agent connects from, returning the agent
+func (s *AgentService) RecordSource(ctx context.Context, paw, ip string) (*entity.Agent, error) {
+	agent, err := s.repo.FindByPaw(ctx, paw)
+	if err != nil || agent == nil {
+		return nil, ErrAgentNotFound
+	}
+	if ip == "" || agent.IPAddress == ip {
+		return agent, nil
+	}
+
+	agent.IPAddress = ip
+	if err := s.repo.Update(ctx, agent); err != nil {
+		return nil, err
+	}
+	return agent, nil
 }
 
 // DeleteAgent removes an agent
@@ -76,34 +172,68 @@ func (s *AgentService) DeleteAgent(ctx context.Context, paw string) error {
 	return s.repo.Delete(ctx, paw)
 }
 
-// CheckStaleAgents marks agents as offline if they haven't been seen recently
+// CheckStaleAgents marks agents as offline if they haven't been seen recently,
+// or did not reconnect within the offline grace period, then announces the
+// status of the flapping agents that settled
 func (s *AgentService) CheckStaleAgents(ctx context.Context, timeout time.Duration) error {
+	now := time.Now()
+	for _, paw := range s.expiredDisconnections(now) {
+		agent, err := s.repo.FindByPaw(ctx, paw)
+		if err != nil || agent == nil || agent.Status != entity.AgentOnline {
+			continue
+		}
+		agent.Status = entity.AgentOffline
+		if err := s.repo.Update(ctx, agent); err != nil {
+			return err
+		}
+		s.announceTransition(ctx, agent, entity.AvailabilityDisconnected, now)
+	}
+
 	agents, err := s.repo.FindByStatus(ctx, entity.AgentOnline)
 	if err != nil {
 		return err
 	}
 
-	cutoff := time.Now().Add(-timeout)
+	var timeouts map[string]time.Duration
+	if s.beacons != nil {
+		if timeouts, err = s.beacons.StaleTimeouts(ctx, agents, timeout); err != nil {
+			return err
+		}
+	}
+
 	for _, agent := range agents {
-		if agent.LastSeen.Before(cutoff) {
+		agentTimeout := timeout
+		if t, ok := timeouts[agent.Paw]; ok {
+			agentTimeout = t
+		}
+		if agent.LastSeen.Before(now.Add(-agentTimeout)) {
 			agent.Status = entity.AgentOffline
 			if err := s.repo.Update(ctx, agent); err != nil {
 				return err
 			}
+			s.announceTransition(ctx, agent, entity.AvailabilityHeartbeatTimeout, now)
 		}
 	}
 
+	s.releaseSettledAgents(ctx, now)
 	return nil
 }
 
 // RegisterOrUpdate registers a new agent or updates an existing one (WebSocket handler)
-func (s *AgentService) RegisterOrUpdate(ctx context.Context, paw, hostname, username, platform string, executors []string) error {
+func (s *AgentService) RegisterOrUpdate(
+	ctx context.Context,
+	paw, hostname, username, platform, version string,
+	executors []string,
+	inventory entity.AgentInventory,
+) error {
 	agent := &entity.Agent{
-		Paw:       paw,
-		Hostname:  hostname,
-		Username:  username,
-		Platform:  platform,
-		Executors: executors,
+		Paw:            paw,
+		Hostname:       hostname,
+		Username:       username,
+		Platform:       platform,
+		Version:        version,
+		Executors:      executors,
+		AgentInventory: inventory,
 	}
 	return s.RegisterAgent(ctx, agent)
 }
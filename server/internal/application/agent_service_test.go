@@ -398,7 +398,7 @@ func TestRegisterOrUpdate_NewAgent(t *testing.T) {
 	service := NewAgentService(repo)
 	ctx := context.Background()
 
-	err := service.RegisterOrUpdate(ctx, "ws-agent-1", "test-host", "testuser", "linux", []string{"sh", "bash"})
+	err := service.RegisterOrUpdate(ctx, "ws-agent-1", "test-host", "testuser", "linux", "", []string{"sh", "bash"}, entity.AgentInventory{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -445,7 +445,8 @@ func TestRegisterOrUpdate_ExistingAgent(t *testing.T) {
 	service := NewAgentService(repo)
 	ctx := context.Background()
 
-	err := service.RegisterOrUpdate(ctx, "ws-existing", "new-host", "newuser", "linux", []string{"sh"})
+	inventory := entity.AgentInventory{OSVersion: "22.04", Architecture: "aarch64", Elevated: true}
+	err := service.RegisterOrUpdate(ctx, "ws-existing", "new-host", "newuser", "linux", "1.2.0", []string{"sh"}, inventory)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -464,6 +465,14 @@ func TestRegisterOrUpdate_ExistingAgent(t *testing.T) {
 		t.Errorf("Expected platform 'linux', got '%s'", agent.Platform)
 	}
 
+	if agent.Version != "1.2.0" {
+		t.Errorf("Expected version '1.2.0', got '%s'", agent.Version)
+	}
+
+	if agent.Architecture != "aarch64" || !agent.Elevated || agent.OSVersion != "22.04" {
+		t.Errorf("Expected the reported inventory, got %+v", agent.AgentInventory)
+	}
+
 	if agent.Status != entity.AgentOnline {
 		t.Errorf("Expected status Online, got %v", agent.Status)
 	}
@@ -474,7 +483,7 @@ func TestRegisterOrUpdate_EmptyExecutors(t *testing.T) {
 	service := NewAgentService(repo)
 	ctx := context.Background()
 
-	err := service.RegisterOrUpdate(ctx, "empty-exec-agent", "host", "user", "linux", []string{})
+	err := service.RegisterOrUpdate(ctx, "empty-exec-agent", "host", "user", "linux", "", []string{}, entity.AgentInventory{})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -492,7 +501,7 @@ func TestRegisterOrUpdate_CreateError(t *testing.T) {
 	service := NewAgentService(repo)
 	ctx := context.Background()
 
-	err := service.RegisterOrUpdate(ctx, "error-agent", "host", "user", "linux", []string{"sh"})
+	err := service.RegisterOrUpdate(ctx, "error-agent", "host", "user", "linux", "", []string{"sh"}, entity.AgentInventory{})
 	if err == nil {
 		t.Fatal("Expected error")
 	}
@@ -547,3 +556,46 @@ func TestUpdateHeartbeat_NonExistentAgent(t *testing.T) {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 }
+
+func TestSetAllowedNetworks(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1"}
+	service := NewAgentService(repo)
+	ctx := context.Background()
+
+	agent, err := service.SetAllowedNetworks(ctx, "paw1", []string{"10.1.0.0/16", "192.168.1.10"})
+	if err != nil {
+		t.Fatalf("SetAllowedNetworks failed: %v", err)
+	}
+	if len(agent.AllowedNetworks) != 2 || agent.AllowedNetworks[1] != "192.168.1.10/32" {
+		t.Errorf("Expected normalized networks, got %v", agent.AllowedNetworks)
+	}
+
+	if _, err := service.SetAllowedNetworks(ctx, "paw1", []string{"10.0.0.0/40"}); !errors.Is(err, ErrInvalidAgentNetworks) {
+		t.Errorf("Expected ErrInvalidAgentNetworks, got %v", err)
+	}
+	if _, err := service.SetAllowedNetworks(ctx, "unknown", nil); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("Expected ErrAgentNotFound, got %v", err)
+	}
+}
+
+func TestRecordSource(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1"}
+	service := NewAgentService(repo)
+	ctx := context.Background()
+
+	agent, err := service.RecordSource(ctx, "paw1", "10.1.2.3")
+	if err != nil || agent.IPAddress != "10.1.2.3" {
+		t.Fatalf("Expected the source address kept, got %+v (%v)", agent, err)
+	}
+
+	// Unchanged addresses are not written again
+	repo.updateErr = errors.New("update error")
+	if _, err := service.RecordSource(ctx, "paw1", "10.1.2.3"); err != nil {
+		t.Errorf("Expected no update for an unchanged address, got %v", err)
+	}
+	if _, err := service.RecordSource(ctx, "unknown", "10.1.2.3"); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("Expected ErrAgentNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package application
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxAgentReleaseSize caps the size of an agent binary, below the API body limit
+const DefaultMaxAgentReleaseSize = 8 << 20
+
+// agentUpdateOfferInterval is how long an agent is left to download and
+// install a release before it is offered again
+const agentUpdateOfferInterval = time.Hour
+
+// Agent release errors
+var (
+	ErrAgentReleaseNotFound  = errors.New("agent release not found")
+	ErrInvalidAgentRelease   = errors.New("invalid agent release")
+	ErrAgentReleaseExists    = errors.New("a release of this version already exists for the platform")
+	ErrAgentReleaseTooLarge  = errors.New("agent release is too large")
+	ErrAgentReleaseSignature = errors.New("agent release signature does not verify")
+)
+
+// ParseAgentUpdateKey decodes the base64 Ed25519 public key agent releases are verified with
+func ParseAgentUpdateKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("public key is not valid base64: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// agentUpdateOffer records the release last offered to an agent
+type agentUpdateOffer struct {
+	version string
+	at      time.Time
+}
+
+// AgentUpdateService stores signed agent binaries and decides which agents are
+// told to update. Releases are signed offline with Ed25519 over their version,
+// checksum and size; the server only accepts those verifying with the
+// configured public key, and agents verify them again with the same key before
+// installing.
+type AgentUpdateService struct {
+	repo      repository.AgentReleaseRepository
+	publicKey ed25519.PublicKey
+	maxSize   int64
+	logger    *zap.Logger
+
+	mu     sync.Mutex
+	offers map[string]agentUpdateOffer // By agent paw
+}
+
+// NewAgentUpdateService creates an agent update service verifying releases with publicKey
+func NewAgentUpdateService(
+	repo repository.AgentReleaseRepository,
+	publicKey ed25519.PublicKey,
+	logger *zap.Logger,
+) *AgentUpdateService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &AgentUpdateService{
+		repo:      repo,
+		publicKey: publicKey,
+		maxSize:   DefaultMaxAgentReleaseSize,
+		logger:    logger,
+		offers:    make(map[string]agentUpdateOffer),
+	}
+}
+
+// SetMaxSize sets the largest agent binary accepted, in bytes
+func (s *AgentUpdateService) SetMaxSize(size int64) {
+	s.maxSize = size
+}
+
+// MaxSize returns the largest agent binary accepted, in bytes
+func (s *AgentUpdateService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// List returns all releases, newest upload first
+func (s *AgentUpdateService) List(ctx context.Context) ([]*entity.AgentRelease, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// Upload verifies and stores an agent binary. The signature is the base64
+// Ed25519 signature of entity.AgentReleaseMessage for the version and binary.
+func (s *AgentUpdateService) Upload(
+	ctx context.Context,
+	version, platform, signature string,
+	rolloutPercent int,
+	content []byte,
+	userID string,
+) (*entity.AgentRelease, error) {
+	release := &entity.AgentRelease{
+		Version:        version,
+		Platform:       platform,
+		Signature:      signature,
+		RolloutPercent: rolloutPercent,
+	}
+	if err := release.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentRelease, err)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidAgentRelease)
+	}
+	if int64(len(content)) > s.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", ErrAgentReleaseTooLarge, len(content), s.maxSize)
+	}
+
+	sum := sha256.Sum256(content)
+	release.SHA256 = hex.EncodeToString(sum[:])
+	release.Size = int64(len(content))
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	message := entity.AgentReleaseMessage(release.Version, release.SHA256, release.Size)
+	if err != nil || !ed25519.Verify(s.publicKey, message, sig) {
+		return nil, ErrAgentReleaseSignature
+	}
+
+	if _, err := s.repo.FindByVersion(ctx, version, platform); err == nil {
+		return nil, ErrAgentReleaseExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	release.ID = uuid.New().String()
+	release.UploadedBy = userID
+	release.CreatedAt = time.Now()
+	if err := s.repo.Create(ctx, release, content); err != nil {
+		return nil, fmt.Errorf("failed to store agent release: %w", err)
+	}
+
+	s.logger.Info("Agent release uploaded",
+		zap.String("version", release.Version),
+		zap.String("platform", release.Platform),
+		zap.Int("rollout_percent", release.RolloutPercent),
+		zap.String("uploaded_by", userID),
+	)
+	return release, nil
+}
+
+// SetRollout sets the share of the platform's agents offered a release
+func (s *AgentUpdateService) SetRollout(ctx context.Context, id string, percent int) (*entity.AgentRelease, error) {
+	release, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	release.RolloutPercent = percent
+	if err := release.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAgentRelease, err)
+	}
+	if err := s.repo.UpdateRollout(ctx, id, percent); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Agent release rollout changed",
+		zap.String("version", release.Version),
+		zap.String("platform", release.Platform),
+		zap.Int("rollout_percent", percent),
+	)
+	return release, nil
+}
+
+// Delete removes a release
+func (s *AgentUpdateService) Delete(ctx context.Context, id string) error {
+	if _, err := s.get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *AgentUpdateService) get(ctx context.Context, id string) (*entity.AgentRelease, error) {
+	release, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAgentReleaseNotFound
+		}
+		return nil, err
+	}
+	return release, nil
+}
+
+// UpdateFor returns the release an agent should update to, with its binary,
+// or nil when the agent is up to date, outside the rollout of every newer
+// release, or was offered the same release recently
+func (s *AgentUpdateService) UpdateFor(
+	ctx context.Context,
+	paw, platform, version string,
+) (*entity.AgentRelease, []byte, error) {
+	releases, err := s.repo.FindByPlatform(ctx, platform)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var target *entity.AgentRelease
+	for _, release := range releases {
+		if entity.CompareAgentVersions(release.Version, version) <= 0 || !inRollout(paw, release.RolloutPercent) {
+			continue
+		}
+		if target == nil || entity.CompareAgentVersions(release.Version, target.Version) > 0 {
+			target = release
+		}
+	}
+	if target == nil || !s.markOffered(paw, target.Version) {
+		return nil, nil, nil
+	}
+
+	content, err := s.repo.Content(ctx, target.ID)
+	if err != nil {
+		s.forgetOffer(paw)
+		return nil, nil, fmt.Errorf("failed to load agent release: %w", err)
+	}
+
+	s.logger.Info("Offering agent update",
+		zap.String("paw", paw),
+		zap.String("from", version),
+		zap.String("to", target.Version),
+	)
+	return target, content, nil
+}
+
+// ReportStatus records the outcome of an update reported by an agent. A
+// failed update is offered again after the offer interval, not at once, so a
+// release an agent cannot verify is not resent on every heartbeat.
+func (s *AgentUpdateService) ReportStatus(paw, version, status, message string) {
+	fields := []zap.Field{zap.String("paw", paw), zap.String("version", version), zap.String("status", status)}
+	if status == "failed" {
+		s.logger.Warn("Agent update failed", append(fields, zap.String("error", message))...)
+		return
+	}
+	s.logger.Info("Agent update status", fields...)
+}
+
+// markOffered records that a release is offered to an agent, and returns
+// false when it already was within the offer interval
+func (s *AgentUpdateService) markOffered(paw, version string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offer, ok := s.offers[paw]; ok && offer.version == version && time.Since(offer.at) < agentUpdateOfferInterval {
+		return false
+	}
+	s.offers[paw] = agentUpdateOffer{version: version, at: time.Now()}
+	return true
+}
+
+func (s *AgentUpdateService) forgetOffer(paw string) {
+	s.mu.Lock()
+	delete(s.offers, paw)
+	s.mu.Unlock()
+}
+
+// inRollout reports whether an agent falls in the first percent of the
+// rollout. Agents keep their bucket across releases, so raising the
+// percentage only adds agents.
+func inRollout(paw string, percent int) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(paw))
+	return int(h.Sum32()%100) < percent
+}
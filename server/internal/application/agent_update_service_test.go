@@ -0,0 +1,229 @@
+package application
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockAgentReleaseRepo implements repository.AgentReleaseRepository for tests
+type mockAgentReleaseRepo struct {
+	releases map[string]*entity.AgentRelease
+	contents map[string][]byte
+}
+
+func newMockAgentReleaseRepo() *mockAgentReleaseRepo {
+	return &mockAgentReleaseRepo{releases: make(map[string]*entity.AgentRelease), contents: make(map[string][]byte)}
+}
+
+func (m *mockAgentReleaseRepo) Create(ctx context.Context, release *entity.AgentRelease, content []byte) error {
+	m.releases[release.ID] = release
+	m.contents[release.ID] = content
+	return nil
+}
+
+func (m *mockAgentReleaseRepo) UpdateRollout(ctx context.Context, id string, percent int) error {
+	m.releases[id].RolloutPercent = percent
+	return nil
+}
+
+func (m *mockAgentReleaseRepo) Delete(ctx context.Context, id string) error {
+	delete(m.releases, id)
+	delete(m.contents, id)
+	return nil
+}
+
+func (m *mockAgentReleaseRepo) FindByID(ctx context.Context, id string) (*entity.AgentRelease, error) {
+	if release, ok := m.releases[id]; ok {
+		copied := *release
+		return &copied, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAgentReleaseRepo) FindByVersion(ctx context.Context, version, platform string) (*entity.AgentRelease, error) {
+	for _, release := range m.releases {
+		if release.Version == version && release.Platform == platform {
+			return release, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAgentReleaseRepo) FindAll(ctx context.Context) ([]*entity.AgentRelease, error) {
+	var releases []*entity.AgentRelease
+	for _, release := range m.releases {
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+func (m *mockAgentReleaseRepo) FindByPlatform(ctx context.Context, platform string) ([]*entity.AgentRelease, error) {
+	var releases []*entity.AgentRelease
+	for _, release := range m.releases {
+		if release.Platform == platform {
+			releases = append(releases, release)
+		}
+	}
+	return releases, nil
+}
+
+func (m *mockAgentReleaseRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	return m.contents[id], nil
+}
+
+func newTestAgentUpdateService(t *testing.T) (*AgentUpdateService, ed25519.PrivateKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return NewAgentUpdateService(newMockAgentReleaseRepo(), publicKey, nil), privateKey
+}
+
+func signRelease(key ed25519.PrivateKey, version string, content []byte) string {
+	sum := sha256.Sum256(content)
+	message := entity.AgentReleaseMessage(version, hex.EncodeToString(sum[:]), int64(len(content)))
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, message))
+}
+
+func TestParseAgentUpdateKey(t *testing.T) {
+	publicKey, _, _ := ed25519.GenerateKey(nil)
+	if key, err := ParseAgentUpdateKey(base64.StdEncoding.EncodeToString(publicKey)); err != nil || !key.Equal(publicKey) {
+		t.Errorf("Expected the key to parse, got %v", err)
+	}
+	if _, err := ParseAgentUpdateKey("not base64!"); err == nil {
+		t.Error("Expected an error for invalid base64")
+	}
+	if _, err := ParseAgentUpdateKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
+
+func TestAgentUpdateService_Upload(t *testing.T) {
+	svc, key := newTestAgentUpdateService(t)
+	svc.SetMaxSize(16)
+	ctx := context.Background()
+	content := []byte("agent-binary")
+
+	release, err := svc.Upload(ctx, "1.2.0", "linux", signRelease(key, "1.2.0", content), 25, content, "user-1")
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if release.ID == "" || release.Size != int64(len(content)) || len(release.SHA256) != 64 ||
+		release.RolloutPercent != 25 || release.UploadedBy != "user-1" {
+		t.Errorf("Unexpected release: %+v", release)
+	}
+
+	_, otherKey := newTestAgentUpdateService(t)
+	tests := []struct {
+		name      string
+		version   string
+		platform  string
+		signature string
+		content   []byte
+		want      error
+	}{
+		{"invalid version", "latest", "linux", signRelease(key, "latest", content), content, ErrInvalidAgentRelease},
+		{"invalid platform", "1.3.0", "plan9", signRelease(key, "1.3.0", content), content, ErrInvalidAgentRelease},
+		{"empty", "1.3.0", "linux", signRelease(key, "1.3.0", nil), nil, ErrInvalidAgentRelease},
+		{"too large", "1.3.0", "linux", "", []byte("a binary over sixteen bytes"), ErrAgentReleaseTooLarge},
+		{"unsigned", "1.3.0", "linux", "", content, ErrAgentReleaseSignature},
+		{"other key", "1.3.0", "linux", signRelease(otherKey, "1.3.0", content), content, ErrAgentReleaseSignature},
+		{"tampered", "1.3.0", "linux", signRelease(key, "1.3.0", content), []byte("agent-binarY"), ErrAgentReleaseSignature},
+		{"other version", "1.3.0", "linux", signRelease(key, "1.2.0", content), content, ErrAgentReleaseSignature},
+		{"binary only", "1.3.0", "linux", base64.StdEncoding.EncodeToString(ed25519.Sign(key, content)), content, ErrAgentReleaseSignature},
+		{"duplicate", "1.2.0", "linux", signRelease(key, "1.2.0", content), content, ErrAgentReleaseExists},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Upload(ctx, tt.version, tt.platform, tt.signature, 0, tt.content, ""); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestAgentUpdateService_SetRolloutAndDelete(t *testing.T) {
+	svc, key := newTestAgentUpdateService(t)
+	ctx := context.Background()
+	release, _ := svc.Upload(ctx, "1.2.0", "linux", signRelease(key, "1.2.0", []byte("x")), 0, []byte("x"), "")
+
+	updated, err := svc.SetRollout(ctx, release.ID, 50)
+	if err != nil || updated.RolloutPercent != 50 {
+		t.Fatalf("Expected rollout 50, got %+v (err %v)", updated, err)
+	}
+	if _, err := svc.SetRollout(ctx, release.ID, 101); !errors.Is(err, ErrInvalidAgentRelease) {
+		t.Errorf("Expected ErrInvalidAgentRelease, got %v", err)
+	}
+	if _, err := svc.SetRollout(ctx, "missing", 10); !errors.Is(err, ErrAgentReleaseNotFound) {
+		t.Errorf("Expected ErrAgentReleaseNotFound, got %v", err)
+	}
+
+	if err := svc.Delete(ctx, release.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := svc.Delete(ctx, release.ID); !errors.Is(err, ErrAgentReleaseNotFound) {
+		t.Errorf("Expected ErrAgentReleaseNotFound, got %v", err)
+	}
+}
+
+func TestAgentUpdateService_UpdateFor(t *testing.T) {
+	svc, key := newTestAgentUpdateService(t)
+	ctx := context.Background()
+	upload := func(version string, rollout int) *entity.AgentRelease {
+		content := []byte("agent " + version)
+		release, err := svc.Upload(ctx, version, "linux", signRelease(key, version, content), rollout, content, "")
+		if err != nil {
+			t.Fatalf("Upload %s failed: %v", version, err)
+		}
+		return release
+	}
+	upload("1.1.0", 100)
+	staged := upload("1.2.0", 0)
+
+	release, content, err := svc.UpdateFor(ctx, "paw1", "linux", "1.0.0")
+	if err != nil || release == nil || release.Version != "1.1.0" || string(content) != "agent 1.1.0" {
+		t.Fatalf("Expected release 1.1.0, got %+v (err %v)", release, err)
+	}
+	if release, _, _ := svc.UpdateFor(ctx, "paw1", "linux", "1.0.0"); release != nil {
+		t.Errorf("Expected no second offer within the interval, got %s", release.Version)
+	}
+	if release, _, _ := svc.UpdateFor(ctx, "paw2", "linux", "1.1.0"); release != nil {
+		t.Errorf("Expected no update for an up-to-date agent, got %s", release.Version)
+	}
+	if release, _, _ := svc.UpdateFor(ctx, "paw3", "windows", ""); release != nil {
+		t.Errorf("Expected no update for another platform, got %s", release.Version)
+	}
+
+	// A failed update waits for the offer interval too
+	svc.ReportStatus("paw1", "1.1.0", "failed", "signature mismatch")
+	if release, _, _ := svc.UpdateFor(ctx, "paw1", "linux", "1.0.0"); release != nil {
+		t.Errorf("Expected no offer right after a failure, got %s", release.Version)
+	}
+
+	// Raising the rollout only adds agents: the share offered 1.2.0 grows with it
+	_, _ = svc.SetRollout(ctx, staged.ID, 30)
+	offered := 0
+	for i := 0; i < 1000; i++ {
+		release, _, _ := svc.UpdateFor(ctx, fmt.Sprintf("agent-%d", i), "linux", "1.1.0")
+		if release != nil {
+			offered++
+		}
+	}
+	if offered < 200 || offered > 400 {
+		t.Errorf("Expected about 30%% of agents offered 1.2.0, got %d of 1000", offered)
+	}
+	for i := 0; i < 1000; i++ {
+		paw := fmt.Sprintf("agent-%d", i)
+		if inRollout(paw, 30) && !inRollout(paw, 60) {
+			t.Fatalf("Agent %s left the rollout when it was raised", paw)
+		}
+	}
+}
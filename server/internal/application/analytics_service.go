@@ -10,7 +10,10 @@ import (
 
 // AnalyticsService provides analytics and reporting functionality
 type AnalyticsService struct {
-	resultRepo repository.ResultRepository
+	resultRepo    repository.ResultRepository
+	techniqueRepo repository.TechniqueRepository
+	agentRepo     repository.AgentRepository
+	events        *EventBus
 }
 
 // NewAnalyticsService creates a new analytics service
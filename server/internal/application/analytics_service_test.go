@@ -74,6 +74,16 @@ func (m *mockResultRepoForAnalytics) FindCompletedExecutionsByDateRange(ctx cont
 	return results, nil
 }
 
+func (m *mockResultRepoForAnalytics) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	var results []*entity.Execution
+	for _, e := range m.executions {
+		if e.Status == status {
+			results = append(results, e)
+		}
+	}
+	return results, nil
+}
+
 func (m *mockResultRepoForAnalytics) CreateResult(ctx context.Context, result *entity.ExecutionResult) error {
 	return nil
 }
@@ -0,0 +1,141 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+// API key errors
+var (
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrInvalidAPIKey  = errors.New("invalid API key")
+	ErrAPIKeyLimit    = errors.New("too many API keys")
+)
+
+const (
+	// maxAPIKeysPerUser bounds the keys a user holds at once
+	maxAPIKeysPerUser = 20
+	// maxAPIKeyName is the longest key name accepted
+	maxAPIKeyName = 100
+	// apiKeyPrefixLength is the number of characters of a key kept to recognize it
+	apiKeyPrefixLength = 12
+	// apiKeyTouchInterval throttles the updates of the last use of a key
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService manages the API keys users create for scripts and the CLI,
+// and authenticates the requests made with them
+type APIKeyService struct {
+	repo     repository.APIKeyRepository
+	userRepo repository.UserRepository
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo repository.APIKeyRepository, userRepo repository.UserRepository) *APIKeyService {
+	return &APIKeyService{repo: repo, userRepo: userRepo}
+}
+
+// Create creates an API key for a user, expiring after ttl unless ttl is zero.
+// The key itself is returned once and never stored.
+func (s *APIKeyService) Create(ctx context.Context, userID, name string, ttl time.Duration) (*entity.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyName {
+		return nil, "", fmt.Errorf("%w: a name of 1 to %d characters is required", ErrInvalidAPIKey, maxAPIKeyName)
+	}
+	if ttl < 0 {
+		return nil, "", fmt.Errorf("%w: the expiry must be positive", ErrInvalidAPIKey)
+	}
+	existing, err := s.repo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list API keys: %w", err)
+	}
+	if len(existing) >= maxAPIKeysPerUser {
+		return nil, "", fmt.Errorf("%w: a user holds at most %d keys", ErrAPIKeyLimit, maxAPIKeysPerUser)
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plaintext := entity.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := &entity.APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plaintext[:apiKeyPrefixLength],
+		KeyHash:   hashAPIKey(plaintext),
+		CreatedAt: time.Now(),
+	}
+	if ttl > 0 {
+		expiresAt := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to save API key: %w", err)
+	}
+	return key, plaintext, nil
+}
+
+// List returns the API keys of a user, newest first
+func (s *APIKeyService) List(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	return s.repo.FindByUser(ctx, userID)
+}
+
+// Revoke deletes an API key. Users revoke their own keys; administrators any key.
+func (s *APIKeyService) Revoke(ctx context.Context, id, userID string, admin bool) error {
+	key, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && key.UserID != userID && !admin) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Authenticate returns an API key and the user it acts for. The key must be
+// known, not expired, and belong to an active user.
+func (s *APIKeyService) Authenticate(ctx context.Context, plaintext string) (*entity.APIKey, *entity.User, error) {
+	if !strings.HasPrefix(plaintext, entity.APIKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	key, err := s.repo.FindByHash(ctx, hashAPIKey(plaintext))
+	if err != nil {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if key.Expired(now) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+	user, err := s.userRepo.FindByID(ctx, key.UserID)
+	if err != nil || user == nil || !user.IsActive {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// The last use is informative: a failed update does not deny the request
+		_ = s.repo.Touch(ctx, key.ID, now)
+	}
+	return key, user, nil
+}
+
+// hashAPIKey returns the hex SHA-256 of an API key. Keys are random enough
+// for a fast hash, which keeps their lookup by hash possible.
+func hashAPIKey(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,158 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+type mockAPIKeyRepo struct {
+	keys    map[string]*entity.APIKey
+	touched int
+}
+
+func newMockAPIKeyRepo() *mockAPIKeyRepo {
+	return &mockAPIKeyRepo{keys: make(map[string]*entity.APIKey)}
+}
+
+func (m *mockAPIKeyRepo) Create(ctx context.Context, key *entity.APIKey) error {
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *mockAPIKeyRepo) FindByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	if key, ok := m.keys[id]; ok {
+		return key, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAPIKeyRepo) FindByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == hash {
+			return key, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAPIKeyRepo) FindByUser(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+	for _, key := range m.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepo) Delete(ctx context.Context, id string) error {
+	delete(m.keys, id)
+	return nil
+}
+
+func (m *mockAPIKeyRepo) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	m.touched++
+	m.keys[id].LastUsedAt = &usedAt
+	return nil
+}
+
+func setupAPIKeyTest() (*APIKeyService, *mockAPIKeyRepo, *mockUserRepo) {
+	userRepo := newMockUserRepo()
+	userRepo.users["operator"] = &entity.User{ID: "operator", Role: entity.RoleOperator, IsActive: true}
+	repo := newMockAPIKeyRepo()
+	return NewAPIKeyService(repo, userRepo), repo, userRepo
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	svc, repo, userRepo := setupAPIKeyTest()
+	ctx := context.Background()
+
+	key, secret, err := svc.Create(ctx, "operator", " ci pipeline ", 0)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.HasPrefix(secret, entity.APIKeyPrefix) || key.Name != "ci pipeline" || key.ExpiresAt != nil ||
+		!strings.HasPrefix(secret, key.Prefix) || key.KeyHash == "" || strings.Contains(key.KeyHash, secret) {
+		t.Errorf("Unexpected key: %+v (%s)", key, secret)
+	}
+
+	authenticated, user, err := svc.Authenticate(ctx, secret)
+	if err != nil || user.ID != "operator" || authenticated.ID != key.ID {
+		t.Fatalf("Expected the key of the operator, got %+v %+v (%v)", authenticated, user, err)
+	}
+	// The last use is recorded at most once a minute
+	if _, _, err := svc.Authenticate(ctx, secret); err != nil || repo.touched != 1 {
+		t.Errorf("Expected one last use update, got %d (%v)", repo.touched, err)
+	}
+
+	for _, candidate := range []string{"", "ask_unknown", strings.TrimPrefix(secret, entity.APIKeyPrefix)} {
+		if _, _, err := svc.Authenticate(ctx, candidate); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Expected ErrInvalidAPIKey for %q, got %v", candidate, err)
+		}
+	}
+
+	// Keys of a deactivated user stop working
+	userRepo.users["operator"].IsActive = false
+	if _, _, err := svc.Authenticate(ctx, secret); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for an inactive user, got %v", err)
+	}
+}
+
+func TestAPIKeyService_Expiry(t *testing.T) {
+	svc, repo, _ := setupAPIKeyTest()
+	ctx := context.Background()
+
+	key, secret, err := svc.Create(ctx, "operator", "temporary", time.Hour)
+	if err != nil || key.ExpiresAt == nil {
+		t.Fatalf("Expected an expiring key, got %+v (%v)", key, err)
+	}
+	expired := time.Now().Add(-time.Second)
+	repo.keys[key.ID].ExpiresAt = &expired
+	if _, _, err := svc.Authenticate(ctx, secret); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for an expired key, got %v", err)
+	}
+
+	if _, _, err := svc.Create(ctx, "operator", "negative", -time.Hour); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for a negative expiry, got %v", err)
+	}
+	for _, name := range []string{" ", strings.Repeat("k", maxAPIKeyName+1)} {
+		if _, _, err := svc.Create(ctx, "operator", name, 0); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Expected ErrInvalidAPIKey for name %q, got %v", name, err)
+		}
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	svc, repo, _ := setupAPIKeyTest()
+	ctx := context.Background()
+
+	key, secret, _ := svc.Create(ctx, "operator", "laptop", 0)
+	if err := svc.Revoke(ctx, key.ID, "someone-else", false); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound for another user, got %v", err)
+	}
+	if err := svc.Revoke(ctx, key.ID, "admin", true); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, _, err := svc.Authenticate(ctx, secret); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Expected a revoked key rejected, got %v", err)
+	}
+	if err := svc.Revoke(ctx, key.ID, "operator", false); !errors.Is(err, ErrAPIKeyNotFound) {
+		t.Errorf("Expected ErrAPIKeyNotFound, got %v", err)
+	}
+
+	for i := 0; i < maxAPIKeysPerUser; i++ {
+		repo.keys[string(rune('a'+i))] = &entity.APIKey{ID: string(rune('a' + i)), UserID: "operator"}
+	}
+	if _, _, err := svc.Create(ctx, "operator", "one too many", 0); !errors.Is(err, ErrAPIKeyLimit) {
+		t.Errorf("Expected ErrAPIKeyLimit, got %v", err)
+	}
+	if keys, err := svc.List(ctx, "operator"); err != nil || len(keys) != maxAPIKeysPerUser {
+		t.Errorf("Expected %d keys, got %d (%v)", maxAPIKeysPerUser, len(keys), err)
+	}
+}
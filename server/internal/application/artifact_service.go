@@ -0,0 +1,264 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Artifact errors
+var (
+	ErrArtifactNotFound      = errors.New("artifact not found")
+	ErrInvalidArtifact       = errors.New("invalid artifact")
+	ErrArtifactTooLarge      = errors.New("artifact is too large")
+	ErrArtifactQuotaExceeded = errors.New("artifact quota of the result exceeded")
+)
+
+// ArtifactConfig controls the size and lifetime of result artifacts
+type ArtifactConfig struct {
+	MaxSize       int64         // Largest artifact accepted, in bytes
+	ResultQuota   int64         // Bytes stored per result, all artifacts together
+	Retention     time.Duration // Artifacts older than this are purged
+	PurgeInterval time.Duration // How often expired artifacts are purged
+}
+
+// DefaultArtifactConfig returns the default artifact settings: 256 KB per file,
+// 1 MB per result, kept 30 days
+func DefaultArtifactConfig() ArtifactConfig {
+	return ArtifactConfig{
+		MaxSize:       256 << 10,
+		ResultQuota:   1 << 20,
+		Retention:     30 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}
+}
+
+// ArtifactService stores the files agents collect while running techniques,
+// so analysts can check what a simulated exfiltration actually gathered
+type ArtifactService struct {
+	repo       repository.ArtifactRepository
+	resultRepo repository.ResultRepository
+	config     ArtifactConfig
+	blobs      BlobStore
+	logger     *zap.Logger
+	stopChan   chan struct{}
+	wg         sync.WaitGroup
+	running    bool
+	mu         sync.Mutex
+}
+
+// NewArtifactService creates a new artifact service
+func NewArtifactService(
+	repo repository.ArtifactRepository,
+	resultRepo repository.ResultRepository,
+	config ArtifactConfig,
+	logger *zap.Logger,
+) *ArtifactService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ArtifactService{
+		repo:       repo,
+		resultRepo: resultRepo,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// SetBlobStore keeps the content of new artifacts in store instead of the
+// database. Artifacts stored before keep their content in the database.
+func (s *ArtifactService) SetBlobStore(store BlobStore) {
+	s.blobs = store
+}
+
+// Store records a file an agent collected for one of its results. The name is
+// the last element of the path the agent read the file from.
+func (s *ArtifactService) Store(
+	ctx context.Context,
+	agentPaw, resultID, path string,
+	content []byte,
+) (*entity.Artifact, error) {
+	name := artifactName(path)
+	if name == "" {
+		return nil, fmt.Errorf("%w: a file path is required", ErrInvalidArtifact)
+	}
+	if int64(len(content)) > s.config.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", ErrArtifactTooLarge, len(content), s.config.MaxSize)
+	}
+
+	result, err := s.resultRepo.FindResultByID(ctx, resultID)
+	if err != nil || result == nil {
+		return nil, fmt.Errorf("%w: result %s not found", ErrInvalidArtifact, resultID)
+	}
+	if result.AgentPaw != agentPaw {
+		return nil, fmt.Errorf("%w: result %s belongs to another agent", ErrInvalidArtifact, resultID)
+	}
+
+	stored, err := s.repo.TotalSizeByResult(ctx, resultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check artifact quota: %w", err)
+	}
+	if stored+int64(len(content)) > s.config.ResultQuota {
+		return nil, fmt.Errorf("%w: %d of %d bytes used", ErrArtifactQuotaExceeded, stored, s.config.ResultQuota)
+	}
+
+	sum := sha256.Sum256(content)
+	artifact := &entity.Artifact{
+		ID:          uuid.New().String(),
+		ResultID:    resultID,
+		ExecutionID: result.ExecutionID,
+		AgentPaw:    agentPaw,
+		Name:        name,
+		Path:        path,
+		ContentType: http.DetectContentType(content),
+		SHA256:      hex.EncodeToString(sum[:]),
+		Size:        int64(len(content)),
+		CreatedAt:   time.Now(),
+	}
+
+	dbContent := content
+	if s.blobs != nil {
+		artifact.StorageKey = artifactBlobKey(artifact)
+		if err := s.blobs.Put(ctx, artifact.StorageKey, content); err != nil {
+			return nil, fmt.Errorf("failed to store artifact: %w", err)
+		}
+		dbContent = nil
+	}
+	if err := s.repo.Create(ctx, artifact, dbContent); err != nil {
+		if artifact.StorageKey != "" {
+			_ = s.blobs.Delete(ctx, artifact.StorageKey)
+		}
+		return nil, fmt.Errorf("failed to store artifact: %w", err)
+	}
+	return artifact, nil
+}
+
+// artifactBlobKey returns the blob key of the content of an artifact
+func artifactBlobKey(artifact *entity.Artifact) string {
+	return fmt.Sprintf("artifacts/%s/%s", artifact.ExecutionID, artifact.ID)
+}
+
+// artifactName returns the file name of an agent path, Windows or POSIX
+func artifactName(path string) string {
+	path = strings.TrimRight(strings.TrimSpace(path), `/\`)
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		path = path[i+1:]
+	}
+	if path == "." || path == ".." {
+		return ""
+	}
+	return path
+}
+
+// ListByResult returns the artifacts of a result, oldest first
+func (s *ArtifactService) ListByResult(ctx context.Context, resultID string) ([]*entity.Artifact, error) {
+	return s.repo.FindByResult(ctx, resultID)
+}
+
+// Get returns an artifact of a result with its content
+func (s *ArtifactService) Get(ctx context.Context, resultID, id string) (*entity.Artifact, []byte, error) {
+	artifact, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrArtifactNotFound
+		}
+		return nil, nil, err
+	}
+	if artifact.ResultID != resultID {
+		return nil, nil, ErrArtifactNotFound
+	}
+
+	var content []byte
+	switch {
+	case artifact.StorageKey == "":
+		content, err = s.repo.Content(ctx, id)
+	case s.blobs == nil:
+		err = errors.New("artifact is kept in object storage, which is not configured")
+	default:
+		content, err = s.blobs.Get(ctx, artifact.StorageKey)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load artifact: %w", err)
+	}
+	return artifact, content, nil
+}
+
+// Purge deletes the artifacts older than the retention period, with their
+// stored content, and returns how many were deleted
+func (s *ArtifactService) Purge(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-s.config.Retention)
+	if s.blobs != nil {
+		keys, err := s.repo.StorageKeysCreatedBefore(ctx, before)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list expired artifact content: %w", err)
+		}
+		for _, key := range keys {
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				s.logger.Warn("Failed to delete artifact content", zap.String("key", key), zap.Error(err))
+			}
+		}
+	}
+	return s.repo.DeleteCreatedBefore(ctx, before)
+}
+
+// Start starts the background purge of expired artifacts
+func (s *ArtifactService) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.runPurge()
+}
+
+// Stop stops the background purge
+func (s *ArtifactService) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stopChan)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *ArtifactService) runPurge() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if purged, err := s.Purge(context.Background()); err != nil {
+				s.logger.Warn("Failed to purge expired artifacts", zap.Error(err))
+			} else if purged > 0 {
+				s.logger.Info("Purged expired artifacts", zap.Int64("count", purged))
+			}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockArtifactRepo implements repository.ArtifactRepository for tests
+type mockArtifactRepo struct {
+	artifacts map[string]*entity.Artifact
+	contents  map[string][]byte
+	err       error
+}
+
+func newMockArtifactRepo() *mockArtifactRepo {
+	return &mockArtifactRepo{artifacts: make(map[string]*entity.Artifact), contents: make(map[string][]byte)}
+}
+
+func (m *mockArtifactRepo) Create(ctx context.Context, artifact *entity.Artifact, content []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.artifacts[artifact.ID] = artifact
+	m.contents[artifact.ID] = content
+	return nil
+}
+
+func (m *mockArtifactRepo) FindByID(ctx context.Context, id string) (*entity.Artifact, error) {
+	if artifact, ok := m.artifacts[id]; ok {
+		return artifact, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockArtifactRepo) FindByResult(ctx context.Context, resultID string) ([]*entity.Artifact, error) {
+	var artifacts []*entity.Artifact
+	for _, artifact := range m.artifacts {
+		if artifact.ResultID == resultID {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	return artifacts, nil
+}
+
+func (m *mockArtifactRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	return m.contents[id], nil
+}
+
+func (m *mockArtifactRepo) TotalSizeByResult(ctx context.Context, resultID string) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	var total int64
+	for _, artifact := range m.artifacts {
+		if artifact.ResultID == resultID {
+			total += artifact.Size
+		}
+	}
+	return total, nil
+}
+
+func (m *mockArtifactRepo) StorageKeysCreatedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	var keys []string
+	for _, artifact := range m.artifacts {
+		if artifact.CreatedAt.Before(before) && artifact.StorageKey != "" {
+			keys = append(keys, artifact.StorageKey)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockArtifactRepo) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for id, artifact := range m.artifacts {
+		if artifact.CreatedAt.Before(before) {
+			delete(m.artifacts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newTestArtifactService() (*ArtifactService, *mockArtifactRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "result-1", ExecutionID: "exec-1", AgentPaw: "paw1"},
+	}
+	repo := newMockArtifactRepo()
+	config := DefaultArtifactConfig()
+	config.MaxSize = 8
+	config.ResultQuota = 12
+	return NewArtifactService(repo, resultRepo, config, nil), repo
+}
+
+func TestArtifactService_Store(t *testing.T) {
+	svc, _ := newTestArtifactService()
+	ctx := context.Background()
+
+	artifact, err := svc.Store(ctx, "paw1", "result-1", "/tmp/loot/passwd", []byte("root:x:0"))
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if artifact.Name != "passwd" || artifact.Path != "/tmp/loot/passwd" || artifact.ExecutionID != "exec-1" ||
+		artifact.Size != 8 || artifact.SHA256 == "" || !strings.HasPrefix(artifact.ContentType, "text/plain") {
+		t.Errorf("Unexpected artifact: %+v", artifact)
+	}
+
+	tests := []struct {
+		name    string
+		paw     string
+		result  string
+		path    string
+		content string
+		wantErr error
+	}{
+		{"no path", "paw1", "result-1", "", "x", ErrInvalidArtifact},
+		{"dot path", "paw1", "result-1", "/tmp/..", "x", ErrInvalidArtifact},
+		{"too large", "paw1", "result-1", "big.bin", "123456789", ErrArtifactTooLarge},
+		{"unknown result", "paw1", "missing", "a.txt", "x", ErrInvalidArtifact},
+		{"other agent", "paw2", "result-1", "a.txt", "x", ErrInvalidArtifact},
+		{"quota", "paw1", "result-1", "b.txt", "12345", ErrArtifactQuotaExceeded},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Store(ctx, tt.paw, tt.result, tt.path, []byte(tt.content)); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	// The quota still leaves room for a small file
+	if _, err := svc.Store(ctx, "paw1", "result-1", `C:\Users\Public\shot.png`, []byte("1234")); err != nil {
+		t.Errorf("Expected the file to fit the quota, got %v", err)
+	}
+}
+
+func TestArtifactName(t *testing.T) {
+	tests := map[string]string{
+		"/etc/passwd":           "passwd",
+		`C:\Temp\loot.zip`:      "loot.zip",
+		"relative/dir/file.txt": "file.txt",
+		"file.txt":              "file.txt",
+		"/tmp/dir/":             "dir",
+		"..":                    "",
+		"  ":                    "",
+	}
+	for path, want := range tests {
+		if got := artifactName(path); got != want {
+			t.Errorf("artifactName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestArtifactService_Get(t *testing.T) {
+	svc, _ := newTestArtifactService()
+	ctx := context.Background()
+	stored, _ := svc.Store(ctx, "paw1", "result-1", "/tmp/out.txt", []byte("output"))
+
+	artifact, content, err := svc.Get(ctx, "result-1", stored.ID)
+	if err != nil || artifact.ID != stored.ID || string(content) != "output" {
+		t.Errorf("Unexpected artifact %+v %q (err %v)", artifact, content, err)
+	}
+	if _, _, err := svc.Get(ctx, "result-2", stored.ID); !errors.Is(err, ErrArtifactNotFound) {
+		t.Errorf("Expected ErrArtifactNotFound for another result, got %v", err)
+	}
+	if _, _, err := svc.Get(ctx, "result-1", "missing"); !errors.Is(err, ErrArtifactNotFound) {
+		t.Errorf("Expected ErrArtifactNotFound, got %v", err)
+	}
+
+	artifacts, err := svc.ListByResult(ctx, "result-1")
+	if err != nil || len(artifacts) != 1 {
+		t.Errorf("Expected 1 artifact, got %d (err %v)", len(artifacts), err)
+	}
+}
+
+func TestArtifactService_Purge(t *testing.T) {
+	svc, repo := newTestArtifactService()
+	ctx := context.Background()
+	_ = repo.Create(ctx, &entity.Artifact{ID: "old", ResultID: "result-1", CreatedAt: time.Now().Add(-31 * 24 * time.Hour)}, nil)
+	_ = repo.Create(ctx, &entity.Artifact{ID: "new", ResultID: "result-1", CreatedAt: time.Now()}, nil)
+
+	purged, err := svc.Purge(ctx)
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected 1 artifact purged, got %d (err %v)", purged, err)
+	}
+	if _, ok := repo.artifacts["new"]; !ok {
+		t.Error("Expected the recent artifact to be kept")
+	}
+}
+
+func TestArtifactService_BlobStore(t *testing.T) {
+	svc, repo := newTestArtifactService()
+	store := newMockBlobStore()
+	svc.SetBlobStore(store)
+	ctx := context.Background()
+
+	stored, err := svc.Store(ctx, "paw1", "result-1", "/tmp/out.txt", []byte("output"))
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+	if stored.StorageKey != "artifacts/exec-1/"+stored.ID || string(store.objects[stored.StorageKey]) != "output" {
+		t.Errorf("Expected the content in the blob store, got key %q", stored.StorageKey)
+	}
+	if repo.contents[stored.ID] != nil {
+		t.Error("Expected no content in the database")
+	}
+	if _, content, err := svc.Get(ctx, "result-1", stored.ID); err != nil || string(content) != "output" {
+		t.Errorf("Get() = %q, %v", content, err)
+	}
+
+	stored.CreatedAt = time.Now().Add(-31 * 24 * time.Hour)
+	if purged, err := svc.Purge(ctx); err != nil || purged != 1 {
+		t.Fatalf("Expected 1 artifact purged, got %d (err %v)", purged, err)
+	}
+	if len(store.objects) != 0 {
+		t.Error("Expected the stored content deleted with the artifact")
+	}
+
+	store.err = errors.New("bucket not found")
+	if _, err := svc.Store(ctx, "paw1", "result-1", "/tmp/out.txt", []byte("output")); err == nil || len(repo.artifacts) != 0 {
+		t.Errorf("Expected nothing recorded when the content cannot be stored, got %v", err)
+	}
+}
+
+func TestArtifactService_StartStop(t *testing.T) {
+	svc, _ := newTestArtifactService()
+	svc.config.PurgeInterval = time.Millisecond
+
+	svc.Start()
+	svc.Start()
+	time.Sleep(5 * time.Millisecond)
+	svc.Stop()
+	svc.Stop()
+}
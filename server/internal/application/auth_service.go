@@ -6,7 +6,9 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"errors"
-	"os"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"autostrike/internal/domain/entity"
@@ -29,8 +31,50 @@ var (
 	ErrCannotDeactivateSelf = errors.New("cannot deactivate your own account")
 	ErrLastAdmin           = errors.New("cannot deactivate the last admin user")
 	ErrInvalidRole         = errors.New("invalid role")
+	ErrLoginLocked         = errors.New("too many failed logins")
+	ErrInvalidProfile      = errors.New("invalid profile")
+	ErrInvalidWorkspace    = errors.New("invalid workspace")
 )
 
+// lockoutMemory is how long lockouts are remembered, without failed logins,
+// to double the next one
+const lockoutMemory = 24 * time.Hour
+
+// LoginLockedError is returned while failed logins lock out the username or
+// the source IP of a login
+type LoginLockedError struct {
+	Until time.Time
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed logins, try again after %s", e.Until.Format(time.RFC3339))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LoginLockoutConfig sets when failed logins lock out a username or a source IP
+type LoginLockoutConfig struct {
+	MaxUserFailures int           // Failures of a username before it is locked out, 0 to disable
+	MaxIPFailures   int           // Failures from a source IP, across usernames, before it is locked out, 0 to disable
+	FailureWindow   time.Duration // Failures older than this are forgotten
+	BaseLockout     time.Duration // First lockout, doubled by each following one
+	MaxLockout      time.Duration // Longest lockout
+}
+
+// DefaultLoginLockoutConfig locks out a username after 5 failures and a source
+// IP after 20 within 15 minutes, for 1 minute then doubling up to an hour
+func DefaultLoginLockoutConfig() LoginLockoutConfig {
+	return LoginLockoutConfig{
+		MaxUserFailures: 5,
+		MaxIPFailures:   20,
+		FailureWindow:   15 * time.Minute,
+		BaseLockout:     time.Minute,
+		MaxLockout:      time.Hour,
+	}
+}
+
 // TokenResponse represents the response containing JWT tokens
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -46,6 +90,11 @@ type AuthService struct {
 	accessTokenTTL   time.Duration
 	refreshTokenTTL  time.Duration
 	bcryptCost       int
+	lockout          LoginLockoutConfig
+	onLockout        func(ctx context.Context, throttle *entity.LoginThrottle)
+	passwordReset    *passwordReset
+	onResetRequested func(ctx context.Context, user *entity.User, ip string)
+	onPasswordReset  func(ctx context.Context, user *entity.User, ip string)
 }
 
 // NewAuthService creates a new auth service
@@ -56,14 +105,39 @@ func NewAuthService(userRepo repository.UserRepository, jwtSecret string) *AuthS
 		accessTokenTTL:   15 * time.Minute,
 		refreshTokenTTL:  7 * 24 * time.Hour, // 7 days
 		bcryptCost:       12,
+		lockout:          DefaultLoginLockoutConfig(),
 	}
 }
 
+// SetLoginLockout sets when failed logins lock out a username or a source IP
+func (s *AuthService) SetLoginLockout(config LoginLockoutConfig) {
+	s.lockout = config
+}
+
+// OnLockout sets the function called when failed logins lock out a username
+// or a source IP
+func (s *AuthService) OnLockout(fn func(ctx context.Context, throttle *entity.LoginThrottle)) {
+	s.onLockout = fn
+}
+
 // Login authenticates a user and returns JWT tokens
 func (s *AuthService) Login(ctx context.Context, username, password string) (*TokenResponse, error) {
+	return s.LoginFrom(ctx, username, password, "")
+}
+
+// LoginFrom authenticates a user logging in from a source IP and returns JWT
+// tokens. Failed logins are counted per username and per source IP; once
+// locked out, logins fail with a LoginLockedError, even with the right password.
+func (s *AuthService) LoginFrom(ctx context.Context, username, password, ip string) (*TokenResponse, error) {
+	now := time.Now()
+	if err := s.checkLockout(ctx, username, ip, now); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
+			s.recordLoginFailure(ctx, username, ip, now)
 			return nil, ErrInvalidCredentials
 		}
 		return nil, err
@@ -75,15 +149,114 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*To
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		s.recordLoginFailure(ctx, username, ip, now)
 		return nil, ErrInvalidCredentials
 	}
 
-	// Update last login timestamp
+	// Update last login timestamp, and forget the failures of the username
 	_ = s.userRepo.UpdateLastLogin(ctx, user.ID)
+	_ = s.userRepo.DeleteLoginThrottle(ctx, entity.LoginThrottleUser, throttleKey(username))
 
 	return s.generateTokens(user)
 }
 
+// throttleKey normalizes a username, so that its case does not escape lockouts
+func throttleKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// loginThrottle is a scope failed logins are counted against, with its key and failure limit
+type loginThrottle struct {
+	scope entity.LoginThrottleScope
+	key   string
+	max   int
+}
+
+// loginThrottles returns the enabled scopes of a login
+func (s *AuthService) loginThrottles(username, ip string) []loginThrottle {
+	var throttles []loginThrottle
+	if key := throttleKey(username); s.lockout.MaxUserFailures > 0 && key != "" {
+		throttles = append(throttles, loginThrottle{entity.LoginThrottleUser, key, s.lockout.MaxUserFailures})
+	}
+	if s.lockout.MaxIPFailures > 0 && ip != "" {
+		throttles = append(throttles, loginThrottle{entity.LoginThrottleIP, ip, s.lockout.MaxIPFailures})
+	}
+	return throttles
+}
+
+// checkLockout fails with a LoginLockedError when the username or the source IP is locked out
+func (s *AuthService) checkLockout(ctx context.Context, username, ip string, now time.Time) error {
+	for _, t := range s.loginThrottles(username, ip) {
+		throttle, err := s.userRepo.FindLoginThrottle(ctx, t.scope, t.key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if throttle.IsLocked(now) {
+			return &LoginLockedError{Until: *throttle.LockedUntil}
+		}
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login against the username and the
+// source IP, locking them out once they reach their limit. The repository
+// counts and locks atomically: concurrent failures cannot go past the limit.
+func (s *AuthService) recordLoginFailure(ctx context.Context, username, ip string, now time.Time) {
+	for _, t := range s.loginThrottles(username, ip) {
+		throttle, err := s.userRepo.AddLoginFailure(ctx, t.scope, t.key, now, now.Add(-s.lockout.FailureWindow), now.Add(-lockoutMemory))
+		if err != nil || throttle.Failures < t.max {
+			continue
+		}
+		until := now.Add(s.lockoutDuration(throttle.Lockouts))
+		throttle, err = s.userRepo.LockLogin(ctx, t.scope, t.key, t.max, until)
+		if err != nil {
+			// Locked out by a concurrent failure
+			continue
+		}
+		if s.onLockout != nil {
+			s.onLockout(ctx, throttle)
+		}
+	}
+}
+
+// lockoutDuration returns the duration of a lockout after previous ones,
+// doubling from the base lockout up to the longest one
+func (s *AuthService) lockoutDuration(previous int) time.Duration {
+	duration := s.lockout.BaseLockout
+	for i := 0; i < previous && duration < s.lockout.MaxLockout; i++ {
+		duration *= 2
+	}
+	if s.lockout.MaxLockout > 0 && duration > s.lockout.MaxLockout {
+		duration = s.lockout.MaxLockout
+	}
+	return duration
+}
+
+// ListLockouts returns the usernames and source IPs currently locked out
+func (s *AuthService) ListLockouts(ctx context.Context) ([]*entity.LoginThrottle, error) {
+	return s.userRepo.FindLockedLoginThrottles(ctx, time.Now())
+}
+
+// UnlockUser lifts the lockout of a user and forgets their failed logins
+func (s *AuthService) UnlockUser(ctx context.Context, id string) error {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return s.userRepo.DeleteLoginThrottle(ctx, entity.LoginThrottleUser, throttleKey(user.Username))
+}
+
+// UnlockIP lifts the lockout of a source IP and forgets its failed logins
+func (s *AuthService) UnlockIP(ctx context.Context, ip string) error {
+	return s.userRepo.DeleteLoginThrottle(ctx, entity.LoginThrottleIP, ip)
+}
+
 // Refresh generates new tokens from a valid refresh token
 func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenResponse, error) {
 	claims, err := s.validateToken(refreshToken)
@@ -174,9 +347,9 @@ type DefaultAdminResult struct {
 }
 
 // EnsureDefaultAdmin creates a default admin user if no users exist
-// Password is sourced from DEFAULT_ADMIN_PASSWORD env var, or a secure random password is generated
+// Password is the configured one (DEFAULT_ADMIN_PASSWORD), or a secure random password is generated
 // Returns information about whether admin was created and the generated password (if any)
-func (s *AuthService) EnsureDefaultAdmin(ctx context.Context) (*DefaultAdminResult, error) {
+func (s *AuthService) EnsureDefaultAdmin(ctx context.Context, password string) (*DefaultAdminResult, error) {
 	users, err := s.userRepo.FindAll(ctx)
 	if err != nil {
 		return nil, err
@@ -187,8 +360,7 @@ func (s *AuthService) EnsureDefaultAdmin(ctx context.Context) (*DefaultAdminResu
 		return &DefaultAdminResult{Created: false}, nil
 	}
 
-	// Get password from environment variable or generate a secure random one
-	password := os.Getenv("DEFAULT_ADMIN_PASSWORD")
+	// Use the configured password or generate a secure random one
 	generatedPassword := ""
 	if password == "" {
 		// Generate a secure random password (24 bytes = 32 chars base64)
@@ -218,11 +390,12 @@ func (s *AuthService) generateTokens(user *entity.User) (*TokenResponse, error)
 
 	// Access token
 	accessClaims := jwt.MapClaims{
-		"sub":  user.ID,
-		"role": string(user.Role),
-		"type": "access",
-		"iat":  now.Unix(),
-		"exp":  now.Add(s.accessTokenTTL).Unix(),
+		"sub":       user.ID,
+		"role":      string(user.Role),
+		"workspace": user.Workspace,
+		"type":      "access",
+		"iat":       now.Unix(),
+		"exp":       now.Add(s.accessTokenTTL).Unix(),
 	}
 	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
 	accessTokenString, err := accessToken.SignedString([]byte(s.jwtSecret))
@@ -375,6 +548,33 @@ func (s *AuthService) UpdateUserRole(ctx context.Context, id string, role entity
 	return user, nil
 }
 
+// workspacePattern matches workspace names: lowercase letters, digits, dots,
+// dashes and underscores
+var workspacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// SetUserWorkspace moves a user to a workspace, or out of any workspace when
+// workspace is empty. Execution quotas are shared by the users of a workspace.
+func (s *AuthService) SetUserWorkspace(ctx context.Context, id, workspace string) (*entity.User, error) {
+	if workspace != "" && !workspacePattern.MatchString(workspace) {
+		return nil, ErrInvalidWorkspace
+	}
+
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user.Workspace = workspace
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // DeactivateUser deactivates a user account (soft delete)
 func (s *AuthService) DeactivateUser(ctx context.Context, id, currentUserID string) error {
 	// Cannot deactivate yourself
@@ -435,6 +635,78 @@ func (s *AuthService) ResetPassword(ctx context.Context, id, newPassword string)
 	return s.userRepo.Update(ctx, user)
 }
 
+// ProfileUpdate is a change of a user to their own profile. Nil fields are
+// kept; changing the email or the password takes the current password.
+type ProfileUpdate struct {
+	DisplayName     *string
+	Email           *string
+	Timezone        *string
+	Preferences     *entity.UserPreferences
+	CurrentPassword string
+	NewPassword     string
+}
+
+// UpdateProfile applies a change of a user to their own profile. Their role
+// and username stay under the admin user management.
+func (s *AuthService) UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (*entity.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	emailChanged := update.Email != nil && strings.TrimSpace(*update.Email) != user.Email
+	if emailChanged || update.NewPassword != "" {
+		if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(update.CurrentPassword)) != nil {
+			return nil, ErrInvalidCredentials
+		}
+	}
+
+	if update.DisplayName != nil {
+		user.DisplayName = strings.TrimSpace(*update.DisplayName)
+		if len(user.DisplayName) > 100 {
+			return nil, fmt.Errorf("%w: the display name has at most 100 characters", ErrInvalidProfile)
+		}
+	}
+	if emailChanged {
+		email := strings.TrimSpace(*update.Email)
+		if email == "" {
+			return nil, fmt.Errorf("%w: the email is required", ErrInvalidProfile)
+		}
+		if existing, err := s.userRepo.FindByEmail(ctx, email); err == nil && existing != nil && existing.ID != userID {
+			return nil, ErrUserAlreadyExists
+		}
+		user.Email = email
+	}
+	if update.Timezone != nil {
+		if err := entity.ValidateTimezone(*update.Timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidProfile, *update.Timezone)
+		}
+		user.Timezone = *update.Timezone
+	}
+	if update.Preferences != nil {
+		if err := update.Preferences.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidProfile, err.Error())
+		}
+		user.Preferences = *update.Preferences
+	}
+	if update.NewPassword != "" {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(update.NewPassword), s.bcryptCost)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = string(hashedPassword)
+	}
+
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 // ChangePassword allows a user to change their own password
 func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
 	user, err := s.userRepo.FindByID(ctx, userID)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -21,6 +22,7 @@ type mockUserRepo struct {
 	createErr      error
 	updateErr      error
 	deactivateErr  error
+	throttles      map[string]*entity.LoginThrottle
 }
 
 func newMockUserRepo() *mockUserRepo {
@@ -169,6 +171,61 @@ func (m *mockUserRepo) DeactivateAdminIfNotLast(ctx context.Context, id string)
 	return nil
 }
 
+func (m *mockUserRepo) FindLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) (*entity.LoginThrottle, error) {
+	throttle, ok := m.throttles[string(scope)+":"+key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *throttle
+	return &copied, nil
+}
+
+func (m *mockUserRepo) AddLoginFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, at, failuresSince, lockoutsSince time.Time) (*entity.LoginThrottle, error) {
+	if m.throttles == nil {
+		m.throttles = make(map[string]*entity.LoginThrottle)
+	}
+	throttle, ok := m.throttles[string(scope)+":"+key]
+	if !ok {
+		throttle = &entity.LoginThrottle{Scope: scope, Key: key}
+		m.throttles[string(scope)+":"+key] = throttle
+	}
+	if throttle.LastFailureAt.Before(failuresSince) {
+		throttle.Failures = 0
+	}
+	if throttle.LastFailureAt.Before(lockoutsSince) {
+		throttle.Lockouts = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = at
+	copied := *throttle
+	return &copied, nil
+}
+
+func (m *mockUserRepo) LockLogin(ctx context.Context, scope entity.LoginThrottleScope, key string, failures int, until time.Time) (*entity.LoginThrottle, error) {
+	throttle, ok := m.throttles[string(scope)+":"+key]
+	if !ok || throttle.Failures < failures {
+		return nil, sql.ErrNoRows
+	}
+	throttle.Failures, throttle.Lockouts, throttle.LockedUntil = 0, throttle.Lockouts+1, &until
+	copied := *throttle
+	return &copied, nil
+}
+
+func (m *mockUserRepo) DeleteLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) error {
+	delete(m.throttles, string(scope)+":"+key)
+	return nil
+}
+
+func (m *mockUserRepo) FindLockedLoginThrottles(ctx context.Context, at time.Time) ([]*entity.LoginThrottle, error) {
+	var throttles []*entity.LoginThrottle
+	for _, throttle := range m.throttles {
+		if throttle.IsLocked(at) {
+			throttles = append(throttles, throttle)
+		}
+	}
+	return throttles, nil
+}
+
 func TestNewAuthService(t *testing.T) {
 	repo := newMockUserRepo()
 	service := NewAuthService(repo, "test-secret")
@@ -268,6 +325,141 @@ func TestAuthService_Login_UserNotFound(t *testing.T) {
 	}
 }
 
+func newLockoutTestService(t *testing.T) (*AuthService, *mockUserRepo) {
+	t.Helper()
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+	service.SetLoginLockout(LoginLockoutConfig{
+		MaxUserFailures: 3,
+		MaxIPFailures:   5,
+		FailureWindow:   time.Minute,
+		BaseLockout:     time.Minute,
+		MaxLockout:      3 * time.Minute,
+	})
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), 4)
+	repo.users["user-1"] = &entity.User{
+		ID:           "user-1",
+		Username:     "testuser",
+		PasswordHash: string(hashedPassword),
+		IsActive:     true,
+	}
+	return service, repo
+}
+
+func TestAuthService_LoginFrom_LocksOutUser(t *testing.T) {
+	service, _ := newLockoutTestService(t)
+	ctx := context.Background()
+
+	var lockouts []*entity.LoginThrottle
+	service.OnLockout(func(ctx context.Context, throttle *entity.LoginThrottle) {
+		lockouts = append(lockouts, throttle)
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.LoginFrom(ctx, "TestUser", "wrong", "10.0.0.1"); err != ErrInvalidCredentials {
+			t.Fatalf("attempt %d: expected ErrInvalidCredentials, got %v", i, err)
+		}
+	}
+
+	// Locked out even with the right password
+	_, err := service.LoginFrom(ctx, "testuser", "password123", "10.0.0.2")
+	var locked *LoginLockedError
+	if !errors.As(err, &locked) || !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("expected LoginLockedError, got %v", err)
+	}
+	if wait := time.Until(locked.Until); wait <= 0 || wait > time.Minute {
+		t.Errorf("lockout = %v, want up to 1m", wait)
+	}
+
+	if len(lockouts) != 1 || lockouts[0].Scope != entity.LoginThrottleUser || lockouts[0].Key != "testuser" {
+		t.Errorf("expected one lockout of testuser, got %+v", lockouts)
+	}
+}
+
+func TestAuthService_LoginFrom_LockoutDoubles(t *testing.T) {
+	service, repo := newLockoutTestService(t)
+	ctx := context.Background()
+
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}
+	for _, want := range expected {
+		for i := 0; i < 3; i++ {
+			_, _ = service.LoginFrom(ctx, "testuser", "wrong", "")
+		}
+		throttle := repo.throttles["user:testuser"]
+		if throttle == nil || throttle.LockedUntil == nil {
+			t.Fatal("expected testuser to be locked out")
+		}
+		if got := throttle.LockedUntil.Sub(throttle.LastFailureAt); got != want {
+			t.Errorf("lockout = %v, want %v", got, want)
+		}
+		// Let the lockout expire
+		expired := time.Now().Add(-time.Second)
+		throttle.LockedUntil = &expired
+	}
+}
+
+func TestAuthService_LoginFrom_LocksOutIP(t *testing.T) {
+	service, _ := newLockoutTestService(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, _ = service.LoginFrom(ctx, "user"+string(rune('a'+i)), "wrong", "10.0.0.1")
+	}
+
+	_, err := service.LoginFrom(ctx, "testuser", "password123", "10.0.0.1")
+	if !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("expected ErrLoginLocked from the locked out IP, got %v", err)
+	}
+	if _, err := service.LoginFrom(ctx, "testuser", "password123", "10.0.0.2"); err != nil {
+		t.Errorf("expected login from another IP to succeed, got %v", err)
+	}
+}
+
+func TestAuthService_LoginFrom_SuccessForgetsFailures(t *testing.T) {
+	service, repo := newLockoutTestService(t)
+	ctx := context.Background()
+
+	_, _ = service.LoginFrom(ctx, "testuser", "wrong", "")
+	_, _ = service.LoginFrom(ctx, "testuser", "wrong", "")
+	if _, err := service.LoginFrom(ctx, "testuser", "password123", ""); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, ok := repo.throttles["user:testuser"]; ok {
+		t.Error("expected the failures of testuser to be forgotten")
+	}
+}
+
+func TestAuthService_UnlockUserAndIP(t *testing.T) {
+	service, _ := newLockoutTestService(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, _ = service.LoginFrom(ctx, "testuser", "wrong", "10.0.0.1")
+	}
+	lockouts, err := service.ListLockouts(ctx)
+	if err != nil {
+		t.Fatalf("ListLockouts failed: %v", err)
+	}
+	if len(lockouts) != 1 {
+		t.Fatalf("expected 1 lockout, got %d", len(lockouts))
+	}
+
+	if err := service.UnlockUser(ctx, "user-1"); err != nil {
+		t.Fatalf("UnlockUser failed: %v", err)
+	}
+	if _, err := service.LoginFrom(ctx, "testuser", "password123", "10.0.0.2"); err != nil {
+		t.Errorf("expected login after unlock to succeed, got %v", err)
+	}
+
+	if err := service.UnlockUser(ctx, "missing"); err != ErrUserNotFound {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+	if err := service.UnlockIP(ctx, "10.0.0.1"); err != nil {
+		t.Errorf("UnlockIP failed: %v", err)
+	}
+}
+
 func TestAuthService_Refresh_Success(t *testing.T) {
 	repo := newMockUserRepo()
 	service := NewAuthService(repo, "test-secret")
@@ -437,7 +629,7 @@ func TestAuthService_EnsureDefaultAdmin_CreatesAdmin(t *testing.T) {
 	service := NewAuthService(repo, "test-secret")
 
 	ctx := context.Background()
-	result, err := service.EnsureDefaultAdmin(ctx)
+	result, err := service.EnsureDefaultAdmin(ctx, "")
 
 	if err != nil {
 		t.Fatalf("EnsureDefaultAdmin failed: %v", err)
@@ -484,7 +676,7 @@ func TestAuthService_EnsureDefaultAdmin_SkipsIfUsersExist(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	result, err := service.EnsureDefaultAdmin(ctx)
+	result, err := service.EnsureDefaultAdmin(ctx, "")
 
 	if err != nil {
 		t.Fatalf("EnsureDefaultAdmin failed: %v", err)
@@ -802,6 +994,37 @@ func TestAuthService_UpdateUserRole_Success(t *testing.T) {
 	}
 }
 
+func TestAuthService_SetUserWorkspace(t *testing.T) {
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+	repo.users["user-1"] = &entity.User{ID: "user-1", Username: "user1", Role: entity.RoleOperator, IsActive: true}
+	ctx := context.Background()
+
+	for _, invalid := range []string{"Red Team", "-red", strings.Repeat("a", 65)} {
+		if _, err := service.SetUserWorkspace(ctx, "user-1", invalid); err != ErrInvalidWorkspace {
+			t.Errorf("SetUserWorkspace(%q) error = %v, want ErrInvalidWorkspace", invalid, err)
+		}
+	}
+	if _, err := service.SetUserWorkspace(ctx, "nonexistent", "red"); err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	user, err := service.SetUserWorkspace(ctx, "user-1", "red-team")
+	if err != nil || user.Workspace != "red-team" {
+		t.Fatalf("SetUserWorkspace() = %+v, %v", user, err)
+	}
+
+	// Access tokens carry the workspace, for the execution quotas
+	tokens, err := service.generateTokens(user)
+	if err != nil {
+		t.Fatalf("generateTokens failed: %v", err)
+	}
+	claims, err := service.ValidateToken(tokens.AccessToken)
+	if err != nil || claims["workspace"] != "red-team" {
+		t.Errorf("Expected the workspace claim, got %v (%v)", claims, err)
+	}
+}
+
 func TestAuthService_UpdateUserRole_NotFound(t *testing.T) {
 	repo := newMockUserRepo()
 	service := NewAuthService(repo, "test-secret")
@@ -1022,6 +1245,134 @@ func TestAuthService_ChangePassword_NotFound(t *testing.T) {
 	}
 }
 
+func TestAuthService_UpdateProfile_Success(t *testing.T) {
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+
+	ctx := context.Background()
+	user, _ := service.CreateUser(ctx, "testuser", "test@test.com", "password123", entity.RoleViewer)
+
+	name := "  Test User  "
+	timezone := "Europe/Paris"
+	updated, err := service.UpdateProfile(ctx, user.ID, ProfileUpdate{
+		DisplayName: &name,
+		Timezone:    &timezone,
+		Preferences: &entity.UserPreferences{LandingPage: "/executions", AnalyticsPeriod: 7, Theme: "dark"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+
+	if updated.DisplayName != "Test User" {
+		t.Errorf("DisplayName = %q, want %q", updated.DisplayName, "Test User")
+	}
+	if updated.Timezone != "Europe/Paris" {
+		t.Errorf("Timezone = %q, want Europe/Paris", updated.Timezone)
+	}
+	if repo.users[user.ID].Preferences.LandingPage != "/executions" {
+		t.Errorf("Preferences were not saved: %+v", repo.users[user.ID].Preferences)
+	}
+	if updated.Email != "test@test.com" {
+		t.Errorf("Email should be kept, got %q", updated.Email)
+	}
+}
+
+func TestAuthService_UpdateProfile_PasswordNeedsCurrentPassword(t *testing.T) {
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+
+	ctx := context.Background()
+	user, _ := service.CreateUser(ctx, "testuser", "test@test.com", "password123", entity.RoleViewer)
+
+	_, err := service.UpdateProfile(ctx, user.ID, ProfileUpdate{CurrentPassword: "wrongpassword", NewPassword: "newpassword456"})
+	if err != ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+	}
+
+	_, err = service.UpdateProfile(ctx, user.ID, ProfileUpdate{CurrentPassword: "password123", NewPassword: "newpassword456"})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(repo.users[user.ID].PasswordHash), []byte("newpassword456")) != nil {
+		t.Error("New password hash verification failed")
+	}
+}
+
+func TestAuthService_UpdateProfile_EmailNeedsCurrentPassword(t *testing.T) {
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+
+	ctx := context.Background()
+	user, _ := service.CreateUser(ctx, "testuser", "test@test.com", "password123", entity.RoleViewer)
+
+	email := "new@test.com"
+	if _, err := service.UpdateProfile(ctx, user.ID, ProfileUpdate{Email: &email}); err != ErrInvalidCredentials {
+		t.Fatalf("Expected ErrInvalidCredentials, got %v", err)
+	}
+
+	// The unchanged email does not take the password
+	same := "test@test.com"
+	if _, err := service.UpdateProfile(ctx, user.ID, ProfileUpdate{Email: &same}); err != nil {
+		t.Fatalf("UpdateProfile with the same email failed: %v", err)
+	}
+
+	updated, err := service.UpdateProfile(ctx, user.ID, ProfileUpdate{Email: &email, CurrentPassword: "password123"})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if updated.Email != "new@test.com" {
+		t.Errorf("Email = %q, want new@test.com", updated.Email)
+	}
+}
+
+func TestAuthService_UpdateProfile_DuplicateEmail(t *testing.T) {
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+
+	ctx := context.Background()
+	user, _ := service.CreateUser(ctx, "testuser", "test@test.com", "password123", entity.RoleViewer)
+	_, _ = service.CreateUser(ctx, "other", "other@test.com", "password123", entity.RoleViewer)
+
+	email := "other@test.com"
+	_, err := service.UpdateProfile(ctx, user.ID, ProfileUpdate{Email: &email, CurrentPassword: "password123"})
+	if err != ErrUserAlreadyExists {
+		t.Errorf("Expected ErrUserAlreadyExists, got %v", err)
+	}
+}
+
+func TestAuthService_UpdateProfile_Invalid(t *testing.T) {
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+
+	ctx := context.Background()
+	user, _ := service.CreateUser(ctx, "testuser", "test@test.com", "password123", entity.RoleViewer)
+
+	longName := strings.Repeat("a", 101)
+	timezone := "Mars/Olympus_Mons"
+	updates := map[string]ProfileUpdate{
+		"display name": {DisplayName: &longName},
+		"timezone":     {Timezone: &timezone},
+		"landing page": {Preferences: &entity.UserPreferences{LandingPage: "/nowhere"}},
+		"period":       {Preferences: &entity.UserPreferences{AnalyticsPeriod: 12}},
+		"theme":        {Preferences: &entity.UserPreferences{Theme: "pink"}},
+	}
+	for name, update := range updates {
+		if _, err := service.UpdateProfile(ctx, user.ID, update); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("%s: expected ErrInvalidProfile, got %v", name, err)
+		}
+	}
+}
+
+func TestAuthService_UpdateProfile_NotFound(t *testing.T) {
+	repo := newMockUserRepo()
+	service := NewAuthService(repo, "test-secret")
+
+	_, err := service.UpdateProfile(context.Background(), "nonexistent", ProfileUpdate{})
+	if err != ErrUserNotFound {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
 func TestAuthService_ValidateToken_ExpiredToken(t *testing.T) {
 	repo := newMockUserRepo()
 	service := NewAuthService(repo, "test-secret")
@@ -1236,14 +1587,12 @@ func TestAuthService_Refresh_MissingSubClaim(t *testing.T) {
 	}
 }
 
-func TestAuthService_EnsureDefaultAdmin_WithEnvPassword(t *testing.T) {
+func TestAuthService_EnsureDefaultAdmin_WithConfiguredPassword(t *testing.T) {
 	repo := newMockUserRepo()
 	service := NewAuthService(repo, "test-secret")
 
-	t.Setenv("DEFAULT_ADMIN_PASSWORD", "env-password-123")
-
 	ctx := context.Background()
-	result, err := service.EnsureDefaultAdmin(ctx)
+	result, err := service.EnsureDefaultAdmin(ctx, "env-password-123")
 
 	if err != nil {
 		t.Fatalf("EnsureDefaultAdmin failed: %v", err)
@@ -1251,9 +1600,9 @@ func TestAuthService_EnsureDefaultAdmin_WithEnvPassword(t *testing.T) {
 	if !result.Created {
 		t.Error("Expected Created to be true")
 	}
-	// When password comes from env var, GeneratedPassword should be empty
+	// When the password is configured, GeneratedPassword should be empty
 	if result.GeneratedPassword != "" {
-		t.Error("Expected GeneratedPassword to be empty when using env var")
+		t.Error("Expected GeneratedPassword to be empty when using the configured password")
 	}
 }
 
@@ -1263,7 +1612,7 @@ func TestAuthService_EnsureDefaultAdmin_FindAllError(t *testing.T) {
 	service := NewAuthService(repo, "test-secret")
 
 	ctx := context.Background()
-	_, err := service.EnsureDefaultAdmin(ctx)
+	_, err := service.EnsureDefaultAdmin(ctx, "")
 
 	if err == nil {
 		t.Error("Expected error from EnsureDefaultAdmin")
@@ -1276,7 +1625,7 @@ func TestAuthService_EnsureDefaultAdmin_CreateUserError(t *testing.T) {
 	service := NewAuthService(repo, "test-secret")
 
 	ctx := context.Background()
-	_, err := service.EnsureDefaultAdmin(ctx)
+	_, err := service.EnsureDefaultAdmin(ctx, "")
 
 	if err == nil {
 		t.Error("Expected error from EnsureDefaultAdmin when CreateUser fails")
@@ -0,0 +1,256 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+// Beacon errors
+var (
+	ErrInvalidBeacon          = errors.New("invalid beacon settings")
+	ErrBeaconAgentUnknown     = errors.New("agent not found")
+	ErrBeaconOverrideNotFound = errors.New("beacon override not found")
+)
+
+// Beacon sources, from the most to the least specific
+const (
+	BeaconSourceAgent    = "agent"
+	BeaconSourceSelector = "selector"
+	BeaconSourceDefault  = "default"
+)
+
+// ResolvedBeacon is the beacon an agent is told to use, with where it comes from
+type ResolvedBeacon struct {
+	entity.BeaconSettings
+	Source     string `json:"source"`                // "agent", "selector" or "default"
+	SelectorID string `json:"selector_id,omitempty"` // Set when the source is a selector
+}
+
+// BeaconService manages the check-in interval and jitter of agents. An agent
+// uses its own override, else the override of the first matching agent
+// selector by name, else the server default. Settings are pushed to agents
+// when they register and on the first check-in after they change.
+type BeaconService struct {
+	repo         repository.BeaconOverrideRepository
+	selectorRepo repository.AgentSelectorRepository
+	agentRepo    repository.AgentRepository
+	defaults     entity.BeaconSettings
+	logger       *zap.Logger
+
+	mu     sync.Mutex
+	pushed map[string]entity.BeaconSettings // By agent paw
+}
+
+// NewBeaconService creates a beacon service with the server default settings
+func NewBeaconService(
+	repo repository.BeaconOverrideRepository,
+	selectorRepo repository.AgentSelectorRepository,
+	agentRepo repository.AgentRepository,
+	defaults entity.BeaconSettings,
+	logger *zap.Logger,
+) *BeaconService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &BeaconService{
+		repo:         repo,
+		selectorRepo: selectorRepo,
+		agentRepo:    agentRepo,
+		defaults:     defaults,
+		logger:       logger,
+		pushed:       make(map[string]entity.BeaconSettings),
+	}
+}
+
+// Defaults returns the settings of agents without an override
+func (s *BeaconService) Defaults() entity.BeaconSettings {
+	return s.defaults
+}
+
+// List returns every agent and selector override
+func (s *BeaconService) List(ctx context.Context) ([]*entity.BeaconOverride, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// SetAgent sets the beacon of a registered agent
+func (s *BeaconService) SetAgent(ctx context.Context, paw string, settings entity.BeaconSettings, userID string) (*entity.BeaconOverride, error) {
+	if _, err := s.agentRepo.FindByPaw(ctx, paw); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBeaconAgentUnknown
+		}
+		return nil, err
+	}
+	return s.set(ctx, entity.BeaconScopeAgent, paw, settings, userID)
+}
+
+// SetSelector sets the beacon of the agents matching a saved selector
+func (s *BeaconService) SetSelector(ctx context.Context, selectorID string, settings entity.BeaconSettings, userID string) (*entity.BeaconOverride, error) {
+	if _, err := s.selectorRepo.FindByID(ctx, selectorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAgentSelectorNotFound
+		}
+		return nil, err
+	}
+	return s.set(ctx, entity.BeaconScopeSelector, selectorID, settings, userID)
+}
+
+func (s *BeaconService) set(
+	ctx context.Context,
+	scope entity.BeaconScope,
+	target string,
+	settings entity.BeaconSettings,
+	userID string,
+) (*entity.BeaconOverride, error) {
+	override := &entity.BeaconOverride{
+		Scope:          scope,
+		Target:         target,
+		BeaconSettings: settings,
+		UpdatedBy:      userID,
+		UpdatedAt:      time.Now(),
+	}
+	if err := override.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBeacon, err)
+	}
+	if err := s.repo.Upsert(ctx, override); err != nil {
+		return nil, fmt.Errorf("failed to save beacon override: %w", err)
+	}
+
+	s.logger.Info("Beacon override set",
+		zap.String("scope", string(scope)),
+		zap.String("target", target),
+		zap.Int("interval", settings.Interval),
+		zap.Int("jitter", settings.Jitter),
+		zap.String("updated_by", userID),
+	)
+	return override, nil
+}
+
+// Clear removes an override; the agents it applied to fall back to the next source
+func (s *BeaconService) Clear(ctx context.Context, scope entity.BeaconScope, target string) error {
+	if _, err := s.repo.Find(ctx, scope, target); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBeaconOverrideNotFound
+		}
+		return err
+	}
+	return s.repo.Delete(ctx, scope, target)
+}
+
+// Resolve returns the beacon an agent should use
+func (s *BeaconService) Resolve(ctx context.Context, agent *entity.Agent) (*ResolvedBeacon, error) {
+	resolved, err := s.ResolveAll(ctx, []*entity.Agent{agent})
+	if err != nil {
+		return nil, err
+	}
+	beacon := resolved[agent.Paw]
+	return &beacon, nil
+}
+
+// ResolveAgent returns the beacon a registered agent should use
+func (s *BeaconService) ResolveAgent(ctx context.Context, paw string) (*ResolvedBeacon, error) {
+	agent, err := s.agentRepo.FindByPaw(ctx, paw)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBeaconAgentUnknown
+		}
+		return nil, err
+	}
+	return s.Resolve(ctx, agent)
+}
+
+// ResolveAll returns the beacon of each agent by paw, loading the overrides once
+func (s *BeaconService) ResolveAll(ctx context.Context, agents []*entity.Agent) (map[string]ResolvedBeacon, error) {
+	overrides, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load beacon overrides: %w", err)
+	}
+
+	byAgent := make(map[string]entity.BeaconSettings)
+	bySelector := make(map[string]entity.BeaconSettings)
+	for _, override := range overrides {
+		if override.Scope == entity.BeaconScopeAgent {
+			byAgent[override.Target] = override.BeaconSettings
+		} else {
+			bySelector[override.Target] = override.BeaconSettings
+		}
+	}
+
+	var selectors []*entity.AgentSelector
+	if len(bySelector) > 0 {
+		all, err := s.selectorRepo.FindAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load agent selectors: %w", err)
+		}
+		for _, selector := range all {
+			if _, ok := bySelector[selector.ID]; ok {
+				selectors = append(selectors, selector)
+			}
+		}
+		sort.Slice(selectors, func(i, j int) bool { return selectors[i].Name < selectors[j].Name })
+	}
+
+	resolved := make(map[string]ResolvedBeacon, len(agents))
+	for _, agent := range agents {
+		resolved[agent.Paw] = s.resolve(agent, byAgent, bySelector, selectors)
+	}
+	return resolved, nil
+}
+
+func (s *BeaconService) resolve(
+	agent *entity.Agent,
+	byAgent, bySelector map[string]entity.BeaconSettings,
+	selectors []*entity.AgentSelector,
+) ResolvedBeacon {
+	if settings, ok := byAgent[agent.Paw]; ok {
+		return ResolvedBeacon{BeaconSettings: settings, Source: BeaconSourceAgent}
+	}
+	for _, selector := range selectors {
+		if selector.Matches(agent) {
+			return ResolvedBeacon{BeaconSettings: bySelector[selector.ID], Source: BeaconSourceSelector, SelectorID: selector.ID}
+		}
+	}
+	return ResolvedBeacon{BeaconSettings: s.defaults, Source: BeaconSourceDefault}
+}
+
+// CheckIn returns the settings to push to an agent checking in, or nil when
+// it already has them. Registering agents are always sent their settings.
+func (s *BeaconService) CheckIn(ctx context.Context, agent *entity.Agent, registering bool) (*entity.BeaconSettings, error) {
+	beacon, err := s.Resolve(ctx, agent)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if pushed, ok := s.pushed[agent.Paw]; ok && !registering && pushed == beacon.BeaconSettings {
+		return nil, nil
+	}
+	s.pushed[agent.Paw] = beacon.BeaconSettings
+	return &beacon.BeaconSettings, nil
+}
+
+// StaleTimeouts returns how long each agent can go without checking in before
+// it is considered offline: the given timeout, or twice its longest beacon
+// delay when that is longer
+func (s *BeaconService) StaleTimeouts(ctx context.Context, agents []*entity.Agent, timeout time.Duration) (map[string]time.Duration, error) {
+	resolved, err := s.ResolveAll(ctx, agents)
+	if err != nil {
+		return nil, err
+	}
+
+	timeouts := make(map[string]time.Duration, len(resolved))
+	for paw, beacon := range resolved {
+		timeouts[paw] = max(timeout, 2*beacon.MaxDelay())
+	}
+	return timeouts, nil
+}
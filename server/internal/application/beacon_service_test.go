@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockBeaconOverrideRepo implements repository.BeaconOverrideRepository for tests
+type mockBeaconOverrideRepo struct {
+	overrides map[string]*entity.BeaconOverride
+}
+
+func newMockBeaconOverrideRepo() *mockBeaconOverrideRepo {
+	return &mockBeaconOverrideRepo{overrides: make(map[string]*entity.BeaconOverride)}
+}
+
+func (m *mockBeaconOverrideRepo) Upsert(ctx context.Context, override *entity.BeaconOverride) error {
+	m.overrides[string(override.Scope)+"/"+override.Target] = override
+	return nil
+}
+
+func (m *mockBeaconOverrideRepo) Delete(ctx context.Context, scope entity.BeaconScope, target string) error {
+	delete(m.overrides, string(scope)+"/"+target)
+	return nil
+}
+
+func (m *mockBeaconOverrideRepo) Find(ctx context.Context, scope entity.BeaconScope, target string) (*entity.BeaconOverride, error) {
+	if override, ok := m.overrides[string(scope)+"/"+target]; ok {
+		return override, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockBeaconOverrideRepo) FindAll(ctx context.Context) ([]*entity.BeaconOverride, error) {
+	var overrides []*entity.BeaconOverride
+	for _, override := range m.overrides {
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+func newTestBeaconService() (*BeaconService, *mockAgentRepo, *mockAgentSelectorRepo) {
+	agentRepo := newMockAgentRepo()
+	selectorRepo := newMockAgentSelectorRepo()
+	svc := NewBeaconService(newMockBeaconOverrideRepo(), selectorRepo, agentRepo, entity.BeaconSettings{Interval: 30}, nil)
+	return svc, agentRepo, selectorRepo
+}
+
+func TestBeaconService_Resolve(t *testing.T) {
+	svc, agentRepo, selectorRepo := newTestBeaconService()
+	ctx := context.Background()
+	win := &entity.Agent{Paw: "win", Platform: "windows"}
+	linux := &entity.Agent{Paw: "linux", Platform: "linux"}
+	agentRepo.agents["win"] = win
+	agentRepo.agents["linux"] = linux
+	selectorRepo.selectors["sel-win"] = &entity.AgentSelector{ID: "sel-win", Name: "b windows", Platforms: []string{"windows"}}
+	selectorRepo.selectors["sel-all"] = &entity.AgentSelector{ID: "sel-all", Name: "a everything"}
+
+	if beacon, _ := svc.Resolve(ctx, win); beacon.Source != BeaconSourceDefault || beacon.Interval != 30 {
+		t.Errorf("Expected the default beacon, got %+v", beacon)
+	}
+
+	if _, err := svc.SetSelector(ctx, "sel-win", entity.BeaconSettings{Interval: 300, Jitter: 20}, "user-1"); err != nil {
+		t.Fatalf("SetSelector failed: %v", err)
+	}
+	if beacon, _ := svc.Resolve(ctx, win); beacon.Source != BeaconSourceSelector || beacon.SelectorID != "sel-win" || beacon.Interval != 300 {
+		t.Errorf("Expected the selector beacon, got %+v", beacon)
+	}
+	if beacon, _ := svc.Resolve(ctx, linux); beacon.Source != BeaconSourceDefault {
+		t.Errorf("Expected the default beacon for an agent outside the selector, got %+v", beacon)
+	}
+
+	// Selectors apply in name order
+	_, _ = svc.SetSelector(ctx, "sel-all", entity.BeaconSettings{Interval: 600}, "user-1")
+	if beacon, _ := svc.Resolve(ctx, win); beacon.SelectorID != "sel-all" || beacon.Interval != 600 {
+		t.Errorf("Expected the first selector by name, got %+v", beacon)
+	}
+
+	if _, err := svc.SetAgent(ctx, "win", entity.BeaconSettings{Interval: 10, Jitter: 50}, "user-1"); err != nil {
+		t.Fatalf("SetAgent failed: %v", err)
+	}
+	if beacon, _ := svc.Resolve(ctx, win); beacon.Source != BeaconSourceAgent || beacon.Interval != 10 || beacon.Jitter != 50 {
+		t.Errorf("Expected the agent beacon, got %+v", beacon)
+	}
+
+	if err := svc.Clear(ctx, entity.BeaconScopeAgent, "win"); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if beacon, _ := svc.Resolve(ctx, win); beacon.Source != BeaconSourceSelector {
+		t.Errorf("Expected the selector beacon after clearing the agent override, got %+v", beacon)
+	}
+	if err := svc.Clear(ctx, entity.BeaconScopeAgent, "win"); !errors.Is(err, ErrBeaconOverrideNotFound) {
+		t.Errorf("Expected ErrBeaconOverrideNotFound, got %v", err)
+	}
+}
+
+func TestBeaconService_SetErrors(t *testing.T) {
+	svc, agentRepo, _ := newTestBeaconService()
+	ctx := context.Background()
+	agentRepo.agents["paw1"] = &entity.Agent{Paw: "paw1"}
+
+	if _, err := svc.SetAgent(ctx, "paw1", entity.BeaconSettings{Interval: 1}, ""); !errors.Is(err, ErrInvalidBeacon) {
+		t.Errorf("Expected ErrInvalidBeacon, got %v", err)
+	}
+	if _, err := svc.SetAgent(ctx, "missing", entity.BeaconSettings{Interval: 60}, ""); err == nil {
+		t.Error("Expected an error for an unknown agent")
+	}
+	if _, err := svc.SetSelector(ctx, "missing", entity.BeaconSettings{Interval: 60}, ""); !errors.Is(err, ErrAgentSelectorNotFound) {
+		t.Errorf("Expected ErrAgentSelectorNotFound, got %v", err)
+	}
+}
+
+func TestBeaconService_CheckIn(t *testing.T) {
+	svc, agentRepo, _ := newTestBeaconService()
+	ctx := context.Background()
+	agent := &entity.Agent{Paw: "paw1"}
+	agentRepo.agents["paw1"] = agent
+
+	if settings, _ := svc.CheckIn(ctx, agent, true); settings == nil || settings.Interval != 30 {
+		t.Fatalf("Expected the settings on registration, got %+v", settings)
+	}
+	if settings, _ := svc.CheckIn(ctx, agent, false); settings != nil {
+		t.Errorf("Expected nothing to push for unchanged settings, got %+v", settings)
+	}
+
+	_, _ = svc.SetAgent(ctx, "paw1", entity.BeaconSettings{Interval: 120, Jitter: 10}, "")
+	if settings, _ := svc.CheckIn(ctx, agent, false); settings == nil || settings.Interval != 120 || settings.Jitter != 10 {
+		t.Errorf("Expected the changed settings, got %+v", settings)
+	}
+	if settings, _ := svc.CheckIn(ctx, agent, true); settings == nil {
+		t.Error("Expected the settings again when the agent registers again")
+	}
+}
+
+func TestCheckStaleAgents_BeaconInterval(t *testing.T) {
+	repo := newMockAgentRepo()
+	lastSeen := time.Now().Add(-5 * time.Minute)
+	repo.agents["slow"] = &entity.Agent{Paw: "slow", Status: entity.AgentOnline, LastSeen: lastSeen}
+	repo.agents["fast"] = &entity.Agent{Paw: "fast", Status: entity.AgentOnline, LastSeen: lastSeen}
+
+	beacons := NewBeaconService(newMockBeaconOverrideRepo(), newMockAgentSelectorRepo(), repo, entity.BeaconSettings{Interval: 30}, nil)
+	if _, err := beacons.SetAgent(context.Background(), "slow", entity.BeaconSettings{Interval: 600}, ""); err != nil {
+		t.Fatalf("SetAgent failed: %v", err)
+	}
+	service := NewAgentService(repo)
+	service.SetBeaconService(beacons)
+
+	if err := service.CheckStaleAgents(context.Background(), 2*time.Minute); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if repo.agents["slow"].Status != entity.AgentOnline {
+		t.Error("Expected the agent beaconing every 10 minutes to stay online")
+	}
+	if repo.agents["fast"].Status != entity.AgentOffline {
+		t.Error("Expected the agent on the default beacon to be offline")
+	}
+}
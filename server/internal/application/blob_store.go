@@ -0,0 +1,13 @@
+package application
+
+import "context"
+
+// BlobStore keeps large data out of the database: the full outputs of results
+// and the content of artifacts, on disk or in object storage. Get returns an
+// error matching fs.ErrNotExist for a missing key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	Location() string
+}
@@ -0,0 +1,393 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// ConfigBundleFormat identifies configuration bundles
+	ConfigBundleFormat = "autostrike-config-bundle"
+	// ConfigBundleVersion is the version of the bundles written by this server
+	ConfigBundleVersion = 1
+)
+
+// Configuration bundle errors
+var (
+	ErrInvalidConfigBundle   = errors.New("invalid configuration bundle")
+	ErrConfigBundleSignature = errors.New("configuration bundle signature invalid or missing")
+)
+
+// ConfigBundleContent is the configuration carried by a bundle. Scenarios,
+// schedules and selectors keep their IDs, so they are updated in place when
+// the bundle is imported again.
+type ConfigBundleContent struct {
+	Techniques      []*entity.Technique      `json:"techniques"`
+	Scenarios       []*entity.Scenario       `json:"scenarios"`
+	AgentSelectors  []*entity.AgentSelector  `json:"agent_selectors"`
+	Schedules       []*entity.Schedule       `json:"schedules"`
+	BeaconOverrides []*entity.BeaconOverride `json:"beacon_overrides"`
+}
+
+// ConfigBundle is the whole configuration of a server, to promote it from
+// staging to production. The signature is the hex HMAC-SHA256 of the content
+// bytes under the shared signing key.
+type ConfigBundle struct {
+	Format     string          `json:"format"`
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Content    json.RawMessage `json:"content"`
+	Signature  string          `json:"signature,omitempty"`
+}
+
+// ConfigBundleCount counts the items of a kind created and updated by an import
+type ConfigBundleCount struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// ConfigBundleImportResult summarizes a bundle import
+type ConfigBundleImportResult struct {
+	Verified        bool              `json:"verified"` // The signature was checked
+	DryRun          bool              `json:"dry_run"`
+	Techniques      ConfigBundleCount `json:"techniques"`
+	Scenarios       ConfigBundleCount `json:"scenarios"`
+	AgentSelectors  ConfigBundleCount `json:"agent_selectors"`
+	Schedules       ConfigBundleCount `json:"schedules"`
+	BeaconOverrides ConfigBundleCount `json:"beacon_overrides"`
+	Errors          []string          `json:"errors,omitempty"`
+}
+
+// ConfigBundleService exports and imports the configuration of the server:
+// techniques, scenarios, agent selectors, schedules and beacon overrides.
+// Built-in templates, executions and agents stay on each server.
+type ConfigBundleService struct {
+	techniqueRepo repository.TechniqueRepository
+	scenarioRepo  repository.ScenarioRepository
+	selectorRepo  repository.AgentSelectorRepository
+	scheduleRepo  repository.ScheduleRepository
+	beaconRepo    repository.BeaconOverrideRepository
+	signingKey    []byte
+	logger        *zap.Logger
+}
+
+// NewConfigBundleService creates a configuration bundle service
+func NewConfigBundleService(
+	techniqueRepo repository.TechniqueRepository,
+	scenarioRepo repository.ScenarioRepository,
+	selectorRepo repository.AgentSelectorRepository,
+	scheduleRepo repository.ScheduleRepository,
+	beaconRepo repository.BeaconOverrideRepository,
+	logger *zap.Logger,
+) *ConfigBundleService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ConfigBundleService{
+		techniqueRepo: techniqueRepo,
+		scenarioRepo:  scenarioRepo,
+		selectorRepo:  selectorRepo,
+		scheduleRepo:  scheduleRepo,
+		beaconRepo:    beaconRepo,
+		logger:        logger,
+	}
+}
+
+// SetSigningKey signs exported bundles with key, and refuses to import
+// bundles not signed with it
+func (s *ConfigBundleService) SetSigningKey(key []byte) {
+	s.signingKey = key
+}
+
+// Export packages the configuration of the server into a bundle
+func (s *ConfigBundleService) Export(ctx context.Context) (*ConfigBundle, error) {
+	content := ConfigBundleContent{}
+	var err error
+
+	if content.Techniques, err = s.techniqueRepo.FindAll(ctx); err != nil {
+		return nil, fmt.Errorf("failed to export techniques: %w", err)
+	}
+	scenarios, err := s.scenarioRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export scenarios: %w", err)
+	}
+	for _, scenario := range scenarios {
+		if !scenario.IsTemplate {
+			content.Scenarios = append(content.Scenarios, scenario)
+		}
+	}
+	if content.AgentSelectors, err = s.selectorRepo.FindAll(ctx); err != nil {
+		return nil, fmt.Errorf("failed to export agent selectors: %w", err)
+	}
+	if content.Schedules, err = s.scheduleRepo.FindAll(ctx); err != nil {
+		return nil, fmt.Errorf("failed to export schedules: %w", err)
+	}
+	if content.BeaconOverrides, err = s.beaconRepo.FindAll(ctx); err != nil {
+		return nil, fmt.Errorf("failed to export beacon overrides: %w", err)
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	bundle := &ConfigBundle{
+		Format:     ConfigBundleFormat,
+		Version:    ConfigBundleVersion,
+		ExportedAt: time.Now(),
+		Content:    data,
+	}
+	if len(s.signingKey) > 0 {
+		bundle.Signature = s.sign(data)
+	}
+	return bundle, nil
+}
+
+// Import creates or updates the configuration of a bundle, by ID. Items that
+// fail are reported and skipped. A dry run only counts what would change.
+func (s *ConfigBundleService) Import(ctx context.Context, bundle *ConfigBundle, userID string, dryRun bool) (*ConfigBundleImportResult, error) {
+	if bundle.Format != ConfigBundleFormat {
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidConfigBundle, bundle.Format)
+	}
+	if bundle.Version < 1 || bundle.Version > ConfigBundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidConfigBundle, bundle.Version)
+	}
+	result := &ConfigBundleImportResult{DryRun: dryRun}
+	if len(s.signingKey) > 0 {
+		if !hmac.Equal([]byte(bundle.Signature), []byte(s.sign(bundle.Content))) {
+			return nil, ErrConfigBundleSignature
+		}
+		result.Verified = true
+	}
+
+	var content ConfigBundleContent
+	if err := json.Unmarshal(bundle.Content, &content); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfigBundle, err)
+	}
+
+	// Referenced items first: scenarios use techniques, schedules use scenarios and selectors
+	for _, technique := range content.Techniques {
+		s.importTechnique(ctx, technique, dryRun, result)
+	}
+	for _, scenario := range content.Scenarios {
+		s.importScenario(ctx, scenario, dryRun, result)
+	}
+	for _, selector := range content.AgentSelectors {
+		s.importSelector(ctx, selector, userID, dryRun, result)
+	}
+	for _, schedule := range content.Schedules {
+		s.importSchedule(ctx, schedule, userID, dryRun, result)
+	}
+	for _, override := range content.BeaconOverrides {
+		s.importBeaconOverride(ctx, override, userID, dryRun, result)
+	}
+
+	if !dryRun {
+		s.logger.Info("Imported configuration bundle",
+			zap.String("user_id", userID),
+			zap.Bool("verified", result.Verified),
+			zap.Int("errors", len(result.Errors)),
+		)
+	}
+	return result, nil
+}
+
+// sign returns the hex HMAC-SHA256 of data under the signing key
+func (s *ConfigBundleService) sign(data []byte) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// fail records an item that could not be imported
+func (r *ConfigBundleImportResult) fail(count *ConfigBundleCount, kind, id string, err error) {
+	count.Failed++
+	r.Errors = append(r.Errors, fmt.Sprintf("%s %s: %v", kind, id, err))
+}
+
+func (s *ConfigBundleService) importTechnique(ctx context.Context, technique *entity.Technique, dryRun bool, result *ConfigBundleImportResult) {
+	count := &result.Techniques
+	technique.DeletedAt = nil
+	if err := validateTechnique(technique); err != nil {
+		result.fail(count, "technique", technique.ID, err)
+		return
+	}
+
+	existing, err := s.techniqueRepo.FindByID(ctx, technique.ID)
+	exists := err == nil && existing != nil
+	if !dryRun {
+		if exists {
+			err = s.techniqueRepo.Update(ctx, technique)
+		} else {
+			err = s.techniqueRepo.Create(ctx, technique)
+		}
+		if err != nil {
+			result.fail(count, "technique", technique.ID, err)
+			return
+		}
+	}
+	if exists {
+		count.Updated++
+	} else {
+		count.Created++
+	}
+}
+
+func (s *ConfigBundleService) importScenario(ctx context.Context, scenario *entity.Scenario, dryRun bool, result *ConfigBundleImportResult) {
+	count := &result.Scenarios
+	if scenario.ID == "" || scenario.Name == "" {
+		result.fail(count, "scenario", scenario.ID, errors.New("id and name are required"))
+		return
+	}
+	scenario.DeletedAt = nil
+
+	existing, err := s.scenarioRepo.FindByID(ctx, scenario.ID)
+	exists := err == nil && existing != nil
+	if exists && existing.IsTemplate {
+		result.fail(count, "scenario", scenario.ID, ErrScenarioTemplate)
+		return
+	}
+	if !dryRun {
+		if exists {
+			err = s.scenarioRepo.Update(ctx, scenario)
+		} else {
+			scenario.IsTemplate = false
+			scenario.CreatedAt = time.Now()
+			scenario.UpdatedAt = scenario.CreatedAt
+			err = s.scenarioRepo.Create(ctx, scenario)
+		}
+		if err != nil {
+			result.fail(count, "scenario", scenario.ID, err)
+			return
+		}
+	}
+	if exists {
+		count.Updated++
+	} else {
+		count.Created++
+	}
+}
+
+func (s *ConfigBundleService) importSelector(ctx context.Context, selector *entity.AgentSelector, userID string, dryRun bool, result *ConfigBundleImportResult) {
+	count := &result.AgentSelectors
+	if err := selector.Validate(); err != nil {
+		result.fail(count, "agent selector", selector.ID, err)
+		return
+	}
+
+	existing, err := s.selectorRepo.FindByID(ctx, selector.ID)
+	exists := err == nil && existing != nil
+	if !dryRun {
+		selector.UpdatedAt = time.Now()
+		if exists {
+			selector.CreatedBy = existing.CreatedBy
+			selector.CreatedAt = existing.CreatedAt
+			err = s.selectorRepo.Update(ctx, selector)
+		} else {
+			selector.CreatedBy = userID
+			selector.CreatedAt = selector.UpdatedAt
+			err = s.selectorRepo.Create(ctx, selector)
+		}
+		if err != nil {
+			result.fail(count, "agent selector", selector.ID, err)
+			return
+		}
+	}
+	if exists {
+		count.Updated++
+	} else {
+		count.Created++
+	}
+}
+
+// importSchedule imports a schedule definition. Its run history stays on the
+// source server: the next run is computed from now.
+func (s *ConfigBundleService) importSchedule(ctx context.Context, schedule *entity.Schedule, userID string, dryRun bool, result *ConfigBundleImportResult) {
+	count := &result.Schedules
+	if schedule.ID == "" || schedule.Name == "" || schedule.ScenarioID == "" {
+		result.fail(count, "schedule", schedule.ID, errors.New("id, name and scenario_id are required"))
+		return
+	}
+	if schedule.Frequency == entity.FrequencyCron {
+		if err := entity.ValidateCronExpr(schedule.CronExpr); err != nil {
+			result.fail(count, "schedule", schedule.ID, err)
+			return
+		}
+	}
+	if err := entity.ValidateTimezone(schedule.Timezone); err != nil {
+		result.fail(count, "schedule", schedule.ID, err)
+		return
+	}
+	if _, err := s.scenarioRepo.FindByID(ctx, schedule.ScenarioID); err != nil {
+		result.fail(count, "schedule", schedule.ID, fmt.Errorf("scenario %s not found", schedule.ScenarioID))
+		return
+	}
+
+	existing, err := s.scheduleRepo.FindByID(ctx, schedule.ID)
+	exists := err == nil && existing != nil
+	if !dryRun {
+		now := time.Now()
+		// A one-time schedule that already ran on the source does not run again
+		if schedule.Frequency != entity.FrequencyOnce {
+			schedule.LastRunAt = nil
+		}
+		schedule.LastRunID = ""
+		schedule.NextRuns = nil
+		schedule.NextRunAt = schedule.CalculateNextRun(now)
+		schedule.UpdatedAt = now
+		if exists {
+			schedule.CreatedBy = existing.CreatedBy
+			schedule.CreatedAt = existing.CreatedAt
+			err = s.scheduleRepo.Update(ctx, schedule)
+		} else {
+			schedule.CreatedBy = userID
+			schedule.CreatedAt = now
+			err = s.scheduleRepo.Create(ctx, schedule)
+		}
+		if err != nil {
+			result.fail(count, "schedule", schedule.ID, err)
+			return
+		}
+	}
+	if exists {
+		count.Updated++
+	} else {
+		count.Created++
+	}
+}
+
+func (s *ConfigBundleService) importBeaconOverride(ctx context.Context, override *entity.BeaconOverride, userID string, dryRun bool, result *ConfigBundleImportResult) {
+	count := &result.BeaconOverrides
+	id := string(override.Scope) + "/" + override.Target
+	if err := override.Validate(); err != nil {
+		result.fail(count, "beacon override", id, err)
+		return
+	}
+
+	existing, err := s.beaconRepo.Find(ctx, override.Scope, override.Target)
+	exists := err == nil && existing != nil
+	if !dryRun {
+		override.UpdatedBy = userID
+		override.UpdatedAt = time.Now()
+		if err := s.beaconRepo.Upsert(ctx, override); err != nil {
+			result.fail(count, "beacon override", id, err)
+			return
+		}
+	}
+	if exists {
+		count.Updated++
+	} else {
+		count.Created++
+	}
+}
@@ -0,0 +1,183 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// bundleFixture holds the repositories of a server for bundle tests
+type bundleFixture struct {
+	techniques *mockTechniqueRepo
+	scenarios  *mockScenarioRepo
+	selectors  *mockAgentSelectorRepo
+	schedules  *mockScheduleRepo
+	beacons    *mockBeaconOverrideRepo
+	service    *ConfigBundleService
+}
+
+func newBundleFixture(key string) *bundleFixture {
+	f := &bundleFixture{
+		techniques: newMockTechniqueRepo(),
+		scenarios:  newMockScenarioRepo(),
+		selectors:  newMockAgentSelectorRepo(),
+		schedules:  newMockScheduleRepo(),
+		beacons:    newMockBeaconOverrideRepo(),
+	}
+	f.service = NewConfigBundleService(f.techniques, f.scenarios, f.selectors, f.schedules, f.beacons, nil)
+	if key != "" {
+		f.service.SetSigningKey([]byte(key))
+	}
+	return f
+}
+
+// newStagingFixture returns a server holding one item of each kind, and a built-in template
+func newStagingFixture(key string) *bundleFixture {
+	f := newBundleFixture(key)
+	lastRun := time.Now().Add(-time.Hour)
+	f.techniques.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Command Interpreter"}
+	f.scenarios.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "Discovery"}
+	f.scenarios.scenarios["tpl"] = &entity.Scenario{ID: "tpl", Name: "Template", IsTemplate: true}
+	f.selectors.selectors["sel1"] = &entity.AgentSelector{ID: "sel1", Name: "Linux servers", CreatedBy: "staging-admin"}
+	f.schedules.schedules["sch1"] = &entity.Schedule{
+		ID: "sch1", Name: "Daily", ScenarioID: "s1", Frequency: entity.FrequencyDaily,
+		Status: entity.ScheduleStatusActive, CreatedBy: "staging-admin", LastRunAt: &lastRun, LastRunID: "exec-1",
+	}
+	f.beacons.overrides["selector/sel1"] = &entity.BeaconOverride{
+		Scope: entity.BeaconScopeSelector, Target: "sel1", BeaconSettings: entity.BeaconSettings{Interval: 60, Jitter: 10},
+	}
+	return f
+}
+
+func TestConfigBundleService_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	bundle, err := newStagingFixture("secret").service.Export(ctx)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if bundle.Format != ConfigBundleFormat || bundle.Version != ConfigBundleVersion || bundle.Signature == "" {
+		t.Fatalf("Expected a signed bundle, got %+v", bundle)
+	}
+
+	// Through JSON, as it travels between servers
+	data, _ := json.Marshal(bundle)
+	var received ConfigBundle
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatalf("Failed to decode bundle: %v", err)
+	}
+
+	production := newBundleFixture("secret")
+	production.scenarios.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "Old discovery"}
+	result, err := production.service.Import(ctx, &received, "prod-admin", false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !result.Verified || len(result.Errors) != 0 {
+		t.Fatalf("Expected a verified import without errors, got %+v", result)
+	}
+	if result.Techniques.Created != 1 || result.Scenarios.Updated != 1 || result.Scenarios.Created != 0 ||
+		result.AgentSelectors.Created != 1 || result.Schedules.Created != 1 || result.BeaconOverrides.Created != 1 {
+		t.Errorf("Unexpected counts %+v", result)
+	}
+
+	if production.scenarios.scenarios["s1"].Name != "Discovery" {
+		t.Error("Expected the existing scenario updated")
+	}
+	if _, ok := production.scenarios.scenarios["tpl"]; ok {
+		t.Error("Expected built-in templates left out of the bundle")
+	}
+	schedule := production.schedules.schedules["sch1"]
+	if schedule.CreatedBy != "prod-admin" || schedule.LastRunAt != nil || schedule.LastRunID != "" || schedule.NextRunAt == nil {
+		t.Errorf("Expected the schedule owned by the importer with a fresh next run, got %+v", schedule)
+	}
+	if production.beacons.overrides["selector/sel1"].Interval != 60 {
+		t.Error("Expected the beacon override imported")
+	}
+}
+
+func TestConfigBundleService_Signature(t *testing.T) {
+	ctx := context.Background()
+	bundle, _ := newStagingFixture("secret").service.Export(ctx)
+
+	if _, err := newBundleFixture("other").service.Import(ctx, bundle, "admin", false); !errors.Is(err, ErrConfigBundleSignature) {
+		t.Errorf("Expected ErrConfigBundleSignature for another key, got %v", err)
+	}
+
+	tampered := *bundle
+	tampered.Content = []byte(`{"techniques":[{"id":"T9999","name":"Injected"}]}`)
+	if _, err := newBundleFixture("secret").service.Import(ctx, &tampered, "admin", false); !errors.Is(err, ErrConfigBundleSignature) {
+		t.Errorf("Expected ErrConfigBundleSignature for altered content, got %v", err)
+	}
+
+	unsigned, _ := newStagingFixture("").service.Export(ctx)
+	if unsigned.Signature != "" {
+		t.Error("Expected an unsigned bundle without a key")
+	}
+	if _, err := newBundleFixture("secret").service.Import(ctx, unsigned, "admin", false); !errors.Is(err, ErrConfigBundleSignature) {
+		t.Errorf("Expected unsigned bundles refused when a key is set, got %v", err)
+	}
+	result, err := newBundleFixture("").service.Import(ctx, bundle, "admin", false)
+	if err != nil || result.Verified {
+		t.Errorf("Expected an unverified import without a key, got %+v (%v)", result, err)
+	}
+}
+
+func TestConfigBundleService_DryRun(t *testing.T) {
+	ctx := context.Background()
+	bundle, _ := newStagingFixture("").service.Export(ctx)
+
+	production := newBundleFixture("")
+	result, err := production.service.Import(ctx, bundle, "admin", true)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !result.DryRun || result.Techniques.Created != 1 || result.Scenarios.Created != 1 {
+		t.Errorf("Expected the changes counted, got %+v", result)
+	}
+	if len(production.techniques.techniques) != 0 || len(production.scenarios.scenarios) != 0 || len(production.beacons.overrides) != 0 {
+		t.Error("Expected nothing written on a dry run")
+	}
+}
+
+func TestConfigBundleService_InvalidBundle(t *testing.T) {
+	ctx := context.Background()
+	service := newBundleFixture("").service
+
+	for _, bundle := range []*ConfigBundle{
+		{Format: "other", Version: 1, Content: []byte(`{}`)},
+		{Format: ConfigBundleFormat, Version: ConfigBundleVersion + 1, Content: []byte(`{}`)},
+		{Format: ConfigBundleFormat, Version: 1, Content: []byte(`[]`)},
+	} {
+		if _, err := service.Import(ctx, bundle, "admin", false); !errors.Is(err, ErrInvalidConfigBundle) {
+			t.Errorf("Expected ErrInvalidConfigBundle for %+v, got %v", bundle, err)
+		}
+	}
+}
+
+func TestConfigBundleService_ItemErrors(t *testing.T) {
+	ctx := context.Background()
+	content, _ := json.Marshal(ConfigBundleContent{
+		Techniques: []*entity.Technique{{ID: "T1059", Name: "Command Interpreter", ParentID: "T1000"}},
+		Schedules:  []*entity.Schedule{{ID: "sch1", Name: "Orphan", ScenarioID: "missing", Frequency: entity.FrequencyDaily}},
+		BeaconOverrides: []*entity.BeaconOverride{
+			{Scope: entity.BeaconScopeAgent, Target: "paw-1", BeaconSettings: entity.BeaconSettings{Interval: 0}},
+		},
+	})
+	bundle := &ConfigBundle{Format: ConfigBundleFormat, Version: ConfigBundleVersion, Content: content}
+
+	production := newBundleFixture("")
+	result, err := production.service.Import(ctx, bundle, "admin", false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Techniques.Failed != 1 || result.Schedules.Failed != 1 || result.BeaconOverrides.Failed != 1 || len(result.Errors) != 3 {
+		t.Errorf("Expected each invalid item reported, got %+v", result)
+	}
+	if len(production.schedules.schedules) != 0 {
+		t.Error("Expected the schedule of a missing scenario skipped")
+	}
+}
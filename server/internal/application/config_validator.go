@@ -0,0 +1,437 @@
+package application
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigCheckStatus is the outcome of a configuration check
+type ConfigCheckStatus string
+
+// Configuration check outcomes
+const (
+	ConfigCheckOK      ConfigCheckStatus = "ok"
+	ConfigCheckWarn    ConfigCheckStatus = "warn"    // The server runs, with a feature disabled or a default used
+	ConfigCheckFail    ConfigCheckStatus = "fail"    // The server would not run as configured
+	ConfigCheckSkipped ConfigCheckStatus = "skipped" // Not run after a failure with fail-fast
+)
+
+// MinJWTSecretLength is the length under which a JWT secret is too weak
+const MinJWTSecretLength = 32
+
+// minJWTSecretChars is the number of distinct characters under which a JWT secret is too weak
+const minJWTSecretChars = 10
+
+// minCertificateValidity is the validity left under which a TLS certificate is reported
+const minCertificateValidity = 14 * 24 * time.Hour
+
+// weakJWTSecrets are placeholders of the example environment files and common defaults
+var weakJWTSecrets = []string{"your-secure-jwt-secret-here", "changeme", "change-me", "secret", "password", "autostrike"}
+
+// ConfigWarning is returned by a check for a problem the server runs with
+type ConfigWarning struct {
+	Message string
+	Details []string
+}
+
+func (w *ConfigWarning) Error() string {
+	return w.Message
+}
+
+// ConfigFailure is returned by a check for a problem listing details, e.g. one per file
+type ConfigFailure struct {
+	Message string
+	Details []string
+}
+
+func (f *ConfigFailure) Error() string {
+	return f.Message
+}
+
+// ConfigCheckFunc checks part of the configuration, returning a description
+// of it when valid, a *ConfigWarning or an error otherwise
+type ConfigCheckFunc func() (string, error)
+
+// ConfigCheckResult is the outcome of a configuration check
+type ConfigCheckResult struct {
+	Name    string            `json:"name"`
+	Status  ConfigCheckStatus `json:"status"`
+	Message string            `json:"message,omitempty"`
+	Details []string          `json:"details,omitempty"`
+}
+
+// ConfigReport is the outcome of every configuration check
+type ConfigReport struct {
+	Checks   []ConfigCheckResult `json:"checks"`
+	OK       int                 `json:"ok"`
+	Warnings int                 `json:"warnings"`
+	Failed   int                 `json:"failed"`
+	Skipped  int                 `json:"skipped"`
+}
+
+// Valid reports whether no check failed
+func (r *ConfigReport) Valid() bool {
+	return r.Failed == 0
+}
+
+// Summary counts the checks by outcome, e.g. "5 ok, 1 warning, 0 failed"
+func (r *ConfigReport) Summary() string {
+	summary := fmt.Sprintf("%d ok, %d warning, %d failed", r.OK, r.Warnings, r.Failed)
+	if r.Skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", r.Skipped)
+	}
+	return summary
+}
+
+// configCheck is a registered configuration check
+type configCheck struct {
+	name  string
+	check ConfigCheckFunc
+}
+
+// ConfigValidator runs the configuration checks of the validate-config
+// command and of the startup sanity report
+type ConfigValidator struct {
+	checks []configCheck
+}
+
+// NewConfigValidator creates a configuration validator without checks
+func NewConfigValidator() *ConfigValidator {
+	return &ConfigValidator{}
+}
+
+// Add registers a check, run in the order checks are added
+func (v *ConfigValidator) Add(name string, check ConfigCheckFunc) {
+	v.checks = append(v.checks, configCheck{name: name, check: check})
+}
+
+// Run runs the checks. With failFast, the checks after the first failure are skipped.
+func (v *ConfigValidator) Run(failFast bool) *ConfigReport {
+	report := &ConfigReport{Checks: make([]ConfigCheckResult, 0, len(v.checks))}
+	for _, c := range v.checks {
+		result := ConfigCheckResult{Name: c.name}
+		if failFast && report.Failed > 0 {
+			result.Status = ConfigCheckSkipped
+			report.Skipped++
+			report.Checks = append(report.Checks, result)
+			continue
+		}
+
+		message, err := c.check()
+		var warning *ConfigWarning
+		var failure *ConfigFailure
+		switch {
+		case err == nil:
+			result.Status, result.Message = ConfigCheckOK, message
+			report.OK++
+		case errors.As(err, &warning):
+			result.Status, result.Message, result.Details = ConfigCheckWarn, warning.Message, warning.Details
+			report.Warnings++
+		case errors.As(err, &failure):
+			result.Status, result.Message, result.Details = ConfigCheckFail, failure.Message, failure.Details
+			report.Failed++
+		default:
+			result.Status, result.Message = ConfigCheckFail, err.Error()
+			report.Failed++
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// CheckConfigFile checks the configuration file read at startup, loadErr
+// being the error reading it and file the one used, if any
+func CheckConfigFile(file string, loadErr error) ConfigCheckFunc {
+	return func() (string, error) {
+		if loadErr != nil {
+			return "", loadErr
+		}
+		if file == "" {
+			return "no config file found, using defaults and the environment", nil
+		}
+		return file, nil
+	}
+}
+
+// EnvVarKind is the type of value an environment variable holds
+type EnvVarKind string
+
+// Environment variable value types
+const (
+	EnvDuration EnvVarKind = "duration"
+	EnvInt      EnvVarKind = "int"
+	EnvBool     EnvVarKind = "bool"
+	EnvURL      EnvVarKind = "url"
+)
+
+// EnvVar is an environment variable whose value is parsed
+type EnvVar struct {
+	Name string
+	Kind EnvVarKind
+}
+
+// CheckEnvVars checks that the set variables parse as their kind. The server
+// ignores an invalid value, so that an invalid value is a warning.
+func CheckEnvVars(lookup func(string) string, vars []EnvVar) ConfigCheckFunc {
+	return func() (string, error) {
+		set := 0
+		var invalid []string
+		for _, v := range vars {
+			value := lookup(v.Name)
+			if value == "" {
+				continue
+			}
+			set++
+			if err := parseEnvValue(v.Kind, value); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s=%q: %v", v.Name, value, err))
+			}
+		}
+		if len(invalid) > 0 {
+			return "", &ConfigWarning{
+				Message: fmt.Sprintf("%d invalid values ignored, the defaults are used", len(invalid)),
+				Details: invalid,
+			}
+		}
+		return fmt.Sprintf("%d variables set", set), nil
+	}
+}
+
+// parseEnvValue parses the value of an environment variable of a kind
+func parseEnvValue(kind EnvVarKind, value string) error {
+	switch kind {
+	case EnvDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("not a duration, e.g. 30s or 24h")
+		}
+		if d < 0 {
+			return fmt.Errorf("negative duration")
+		}
+	case EnvInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("not an integer")
+		}
+		if n < 0 {
+			return fmt.Errorf("negative number")
+		}
+	case EnvBool:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("not true nor false")
+		}
+	case EnvURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("not an absolute URL")
+		}
+	}
+	return nil
+}
+
+// CheckJWTSecret checks the strength of the JWT secret. Without secret,
+// authentication is disabled unless required by authRequired.
+func CheckJWTSecret(secret string, authRequired bool) ConfigCheckFunc {
+	return func() (string, error) {
+		if secret == "" {
+			if authRequired {
+				return "", errors.New("JWT_SECRET is not set while ENABLE_AUTH is true")
+			}
+			return "", &ConfigWarning{Message: "JWT_SECRET is not set, authentication is disabled"}
+		}
+		if name, ok := entity.SecretRef(secret); ok {
+			return fmt.Sprintf("read from the secrets store (%s)", name), nil
+		}
+		for _, weak := range weakJWTSecrets {
+			if strings.EqualFold(secret, weak) {
+				return "", errors.New("JWT_SECRET is a placeholder, generate one with: openssl rand -base64 32")
+			}
+		}
+		if len(secret) < MinJWTSecretLength {
+			return "", fmt.Errorf("JWT_SECRET has %d characters, at least %d are required", len(secret), MinJWTSecretLength)
+		}
+		distinct := make(map[rune]bool)
+		for _, r := range secret {
+			distinct[r] = true
+		}
+		if len(distinct) < minJWTSecretChars {
+			return "", fmt.Errorf("JWT_SECRET has only %d distinct characters, generate one with: openssl rand -base64 32", len(distinct))
+		}
+		return fmt.Sprintf("%d characters", len(secret)), nil
+	}
+}
+
+// CheckSecretRefs checks that the environment variables referring to the
+// secrets store, set to secret://<name>, can be resolved with a master key
+func CheckSecretRefs(environ []string, masterKey bool) ConfigCheckFunc {
+	return func() (string, error) {
+		var refs []string
+		for _, env := range environ {
+			name, value, _ := strings.Cut(env, "=")
+			if secret, ok := entity.SecretRef(value); ok {
+				refs = append(refs, name+" -> "+secret)
+			}
+		}
+		sort.Strings(refs)
+		if len(refs) > 0 && !masterKey {
+			return "", &ConfigFailure{
+				Message: "secret references without SECRETS_MASTER_KEY nor SECRETS_KMS_KEY_ID",
+				Details: refs,
+			}
+		}
+		return fmt.Sprintf("%d references", len(refs)), nil
+	}
+}
+
+// CheckDatabasePath checks that the SQLite database, or the directory it is
+// created in, is writable
+func CheckDatabasePath(path string) ConfigCheckFunc {
+	return func() (string, error) {
+		if path == "" {
+			return "", errors.New("database path is empty")
+		}
+		if path == ":memory:" || strings.HasPrefix(path, "file:") {
+			return "", &ConfigWarning{Message: fmt.Sprintf("%s is not a file path, not checked", path)}
+		}
+
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && info.IsDir():
+			return "", fmt.Errorf("%s is a directory", path)
+		case err == nil:
+			f, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				return "", fmt.Errorf("%s is not writable: %w", path, err)
+			}
+			f.Close()
+			return path, nil
+		case !errors.Is(err, os.ErrNotExist):
+			return "", err
+		}
+
+		// The database is created on startup, in an existing directory
+		dir := filepath.Dir(path)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", fmt.Errorf("directory %s of the database does not exist", dir)
+		}
+		f, err := os.CreateTemp(dir, ".autostrike-check-*")
+		if err != nil {
+			return "", fmt.Errorf("directory %s of the database is not writable: %w", dir, err)
+		}
+		f.Close()
+		os.Remove(f.Name())
+		return "", &ConfigWarning{Message: fmt.Sprintf("%s does not exist yet, it is created on startup", path)}
+	}
+}
+
+// CheckSMTP checks the SMTP settings of the environment. A port that does not
+// parse makes the server use 587.
+func CheckSMTP(host, port, from string) ConfigCheckFunc {
+	return func() (string, error) {
+		if host == "" {
+			if port != "" || from != "" {
+				return "", &ConfigWarning{Message: "SMTP_PORT or SMTP_FROM set without SMTP_HOST, email notifications are disabled"}
+			}
+			return "not configured, email notifications are disabled", nil
+		}
+
+		config := &entity.SMTPConfig{Host: host, Port: 587, From: from}
+		if port != "" {
+			p, err := strconv.Atoi(port)
+			if err != nil {
+				return "", &ConfigWarning{Message: fmt.Sprintf("SMTP_PORT %q is not a number, 587 is used", port)}
+			}
+			config.Port = p
+		}
+		if err := config.Validate(); err != nil {
+			return "", fmt.Errorf("SMTP settings are invalid, email notifications are disabled: %w", err)
+		}
+		return fmt.Sprintf("%s:%d from %s", config.Host, config.Port, config.From), nil
+	}
+}
+
+// CheckTLSCertificate checks that the certificate and key files the server
+// terminates TLS with load, and that the certificate is not about to expire
+func CheckTLSCertificate(certFile, keyFile string, now time.Time) ConfigCheckFunc {
+	return func() (string, error) {
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to load %s and %s: %w", certFile, keyFile, err)
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", certFile, err)
+		}
+		expiry := cert.NotAfter.Format("2006-01-02")
+		switch {
+		case now.After(cert.NotAfter):
+			return "", fmt.Errorf("certificate %s expired on %s", certFile, expiry)
+		case cert.NotAfter.Sub(now) < minCertificateValidity:
+			return "", &ConfigWarning{Message: fmt.Sprintf("certificate %s expires on %s", certFile, expiry)}
+		}
+		return fmt.Sprintf("%s, expires on %s", cert.Subject.CommonName, expiry), nil
+	}
+}
+
+// CheckYAMLFiles checks the syntax of the YAML files under dir, and that the
+// files of its techniques and scenarios subdirectories hold lists of them
+func CheckYAMLFiles(dir string) ConfigCheckFunc {
+	return func() (string, error) {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return "", &ConfigWarning{Message: fmt.Sprintf("%s does not exist, no files checked", dir)}
+		}
+
+		checked := 0
+		var invalid []string
+		err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !isContentFile(d.Name()) {
+				return nil
+			}
+			checked++
+			file, _ := filepath.Rel(dir, path)
+			if err := checkYAMLFile(path, file); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%s: %v", file, err))
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(invalid) > 0 {
+			return "", &ConfigFailure{Message: fmt.Sprintf("%d of %d files are invalid", len(invalid), checked), Details: invalid}
+		}
+		return fmt.Sprintf("%d files in %s", checked, dir), nil
+	}
+}
+
+// checkYAMLFile parses a YAML file, as a content file when it is in a
+// techniques or scenarios subdirectory
+func checkYAMLFile(path, file string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	for _, sub := range contentDirs {
+		if filepath.Dir(file) == sub {
+			_, err := parseContentFile(file, data)
+			return err
+		}
+	}
+	var node yaml.Node
+	return yaml.Unmarshal(data, &node)
+}
@@ -0,0 +1,202 @@
+package application
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidator_Run(t *testing.T) {
+	v := NewConfigValidator()
+	v.Add("ok", func() (string, error) { return "fine", nil })
+	v.Add("warn", func() (string, error) { return "", &ConfigWarning{Message: "degraded", Details: []string{"a"}} })
+	v.Add("fail", func() (string, error) { return "", errors.New("broken") })
+	v.Add("after", func() (string, error) { return "fine", nil })
+
+	report := v.Run(false)
+	if report.Valid() || report.OK != 2 || report.Warnings != 1 || report.Failed != 1 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.Checks[1].Status != ConfigCheckWarn || report.Checks[1].Details[0] != "a" ||
+		report.Checks[2].Status != ConfigCheckFail || report.Checks[2].Message != "broken" {
+		t.Errorf("Unexpected checks: %+v", report.Checks)
+	}
+	if report.Summary() != "2 ok, 1 warning, 1 failed" {
+		t.Errorf("Unexpected summary: %s", report.Summary())
+	}
+
+	// Fail-fast skips the checks after the first failure
+	report = v.Run(true)
+	if report.Checks[3].Status != ConfigCheckSkipped || report.Skipped != 1 || report.Summary() != "1 ok, 1 warning, 1 failed, 1 skipped" {
+		t.Errorf("Expected the last check skipped, got %+v", report)
+	}
+}
+
+func checkStatus(t *testing.T, check ConfigCheckFunc) (ConfigCheckStatus, string) {
+	t.Helper()
+	v := NewConfigValidator()
+	v.Add("check", check)
+	result := v.Run(false).Checks[0]
+	return result.Status, result.Message
+}
+
+func TestCheckEnvVars(t *testing.T) {
+	env := map[string]string{"TTL": "24h", "SIZE": "-1", "FLAG": "yes", "URL": "https://example.com"}
+	vars := []EnvVar{{"TTL", EnvDuration}, {"SIZE", EnvInt}, {"FLAG", EnvBool}, {"URL", EnvURL}, {"UNSET", EnvDuration}}
+	lookup := func(name string) string { return env[name] }
+
+	if status, message := checkStatus(t, CheckEnvVars(lookup, vars)); status != ConfigCheckWarn || !strings.HasPrefix(message, "2 invalid") {
+		t.Errorf("Expected SIZE and FLAG invalid, got %s: %s", status, message)
+	}
+	env["SIZE"], env["FLAG"] = "10", "true"
+	if status, message := checkStatus(t, CheckEnvVars(lookup, vars)); status != ConfigCheckOK || message != "4 variables set" {
+		t.Errorf("Expected the variables valid, got %s: %s", status, message)
+	}
+}
+
+func TestCheckJWTSecret(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		required bool
+		want     ConfigCheckStatus
+	}{
+		{"unset", "", false, ConfigCheckWarn},
+		{"unset with auth required", "", true, ConfigCheckFail},
+		{"placeholder", "your-secure-jwt-secret-here", false, ConfigCheckFail},
+		{"short", "s3cr3t-but-short", false, ConfigCheckFail},
+		{"repetitive", strings.Repeat("ab", 20), false, ConfigCheckFail},
+		{"strong", "q3Vx9+JmZ2kLp0s/8dR4tYwE1uHc6nBf", false, ConfigCheckOK},
+		{"secret reference", "secret://jwt-secret", true, ConfigCheckOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, message := checkStatus(t, CheckJWTSecret(tt.secret, tt.required)); status != tt.want {
+				t.Errorf("Expected %s, got %s: %s", tt.want, status, message)
+			}
+		})
+	}
+}
+
+func TestCheckDatabasePath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "autostrike.db")
+
+	if status, _ := checkStatus(t, CheckDatabasePath(path)); status != ConfigCheckWarn {
+		t.Errorf("Expected a warning for a database to create, got %s", status)
+	}
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := checkStatus(t, CheckDatabasePath(path)); status != ConfigCheckOK {
+		t.Errorf("Expected the database writable, got %s", status)
+	}
+	if status, _ := checkStatus(t, CheckDatabasePath(filepath.Join(dir, "missing", "autostrike.db"))); status != ConfigCheckFail {
+		t.Errorf("Expected a missing directory to fail, got %s", status)
+	}
+	if status, _ := checkStatus(t, CheckDatabasePath(dir)); status != ConfigCheckFail {
+		t.Errorf("Expected a directory to fail, got %s", status)
+	}
+}
+
+func TestCheckSMTP(t *testing.T) {
+	tests := []struct {
+		name             string
+		host, port, from string
+		want             ConfigCheckStatus
+	}{
+		{"not configured", "", "", "", ConfigCheckOK},
+		{"without host", "", "25", "", ConfigCheckWarn},
+		{"valid", "smtp.example.com", "25", "autostrike@example.com", ConfigCheckOK},
+		{"default port", "smtp.example.com", "", "autostrike@example.com", ConfigCheckOK},
+		{"port not a number", "smtp.example.com", "smtp", "autostrike@example.com", ConfigCheckWarn},
+		{"port out of range", "smtp.example.com", "70000", "autostrike@example.com", ConfigCheckFail},
+		{"invalid from", "smtp.example.com", "25", "autostrike", ConfigCheckFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, message := checkStatus(t, CheckSMTP(tt.host, tt.port, tt.from)); status != tt.want {
+				t.Errorf("Expected %s, got %s: %s", tt.want, status, message)
+			}
+		})
+	}
+}
+
+func TestCheckYAMLFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeContentFile(t, dir, "config.yaml", "server:\n  address: \":8443\"\n")
+	writeContentFile(t, dir, "techniques/discovery.yaml", "- id: T1083\n  name: File and Directory Discovery\n")
+	writeContentFile(t, dir, "scenarios/default.yml", "- id: custom\n  name: Custom\n")
+	writeContentFile(t, dir, "notes.txt", "- [")
+
+	if status, message := checkStatus(t, CheckYAMLFiles(dir)); status != ConfigCheckOK || !strings.HasPrefix(message, "3 files") {
+		t.Fatalf("Expected 3 valid files, got %s: %s", status, message)
+	}
+
+	// A content file must hold a list, other files only need to parse
+	writeContentFile(t, dir, "scenarios/default.yml", "id: custom\n")
+	writeContentFile(t, dir, "other.yaml", "key: [")
+	v := NewConfigValidator()
+	v.Add("yaml", CheckYAMLFiles(dir))
+	result := v.Run(false).Checks[0]
+	if result.Status != ConfigCheckFail || result.Message != "2 of 4 files are invalid" || len(result.Details) != 2 {
+		t.Errorf("Expected 2 invalid files, got %+v", result)
+	}
+
+	if status, _ := checkStatus(t, CheckYAMLFiles(filepath.Join(dir, "missing"))); status != ConfigCheckWarn {
+		t.Errorf("Expected a warning for a missing directory, got %s", status)
+	}
+}
+
+func TestCheckSecretRefs(t *testing.T) {
+	environ := []string{"SPLUNK_TOKEN=secret://splunk-token", "SPLUNK_URL=https://splunk:8089"}
+	if status, message := checkStatus(t, CheckSecretRefs(environ, true)); status != ConfigCheckOK || message != "1 references" {
+		t.Errorf("Expected the reference resolvable, got %s: %s", status, message)
+	}
+	if status, _ := checkStatus(t, CheckSecretRefs(environ, false)); status != ConfigCheckFail {
+		t.Errorf("Expected a reference without master key to fail, got %s", status)
+	}
+}
+
+func TestCheckTLSCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	notAfter := time.Now().Add(90 * 24 * time.Hour)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "autostrike.example.com"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: notAfter}
+	der, _ := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want ConfigCheckStatus
+	}{
+		{"valid", time.Now(), ConfigCheckOK},
+		{"expiring", notAfter.Add(-24 * time.Hour), ConfigCheckWarn},
+		{"expired", notAfter.Add(time.Hour), ConfigCheckFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, message := checkStatus(t, CheckTLSCertificate(certFile, keyFile, tt.now)); status != tt.want {
+				t.Errorf("Expected %s, got %s: %s", tt.want, status, message)
+			}
+		})
+	}
+	if status, _ := checkStatus(t, CheckTLSCertificate(certFile, filepath.Join(dir, "missing.key"), time.Now())); status != ConfigCheckFail {
+		t.Errorf("Expected a missing key to fail, got %s", status)
+	}
+}
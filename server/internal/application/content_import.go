@@ -0,0 +1,407 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+)
+
+// ErrUnknownContentFormat is returned when no converter handles the requested format
+var ErrUnknownContentFormat = errors.New("unknown content format")
+
+// ContentBundle is attack content converted to AutoStrike techniques and scenarios.
+// Scenarios only reference techniques of the bundle or of the catalog.
+type ContentBundle struct {
+	Techniques []*entity.Technique
+	Scenarios  []*entity.Scenario
+	Version    string // Release of the upstream content, when the format has one
+}
+
+// ContentConverter converts an open attack-content format (Prelude Operator,
+// Stratus Red Team, ...) into AutoStrike content
+type ContentConverter interface {
+	Format() string
+	Convert(data []byte) (*ContentBundle, error)
+}
+
+// ContentImportOptions change how an import writes the converted content
+type ContentImportOptions struct {
+	// Incremental only writes the techniques and scenarios that differ from the
+	// stored ones, scenarios being matched by name
+	Incremental bool
+	// DeprecateRemoved deprecates the techniques imported from the same format
+	// that the content no longer has, instead of leaving them active
+	DeprecateRemoved bool
+	// Platforms limits the import to the techniques of these ATT&CK platform
+	// matrices ("linux", "macos" or "darwin", "windows")
+	Platforms []string
+	// Domains limits the import to the techniques of these ATT&CK domains
+	// (enterprise-attack, ics-attack, mobile-attack); techniques the content
+	// gives no domain belong to enterprise-attack
+	Domains []string
+	// OwnTechniquesOnly leaves the stored techniques not imported from the same
+	// format, such as the curated catalog, as they are
+	OwnTechniquesOnly bool
+}
+
+// ContentImportResult summarizes a content import
+type ContentImportResult struct {
+	Format     string             `json:"format"`
+	Techniques []string           `json:"techniques"` // IDs of the created or updated techniques
+	Added      []string           `json:"added"`
+	Updated    []string           `json:"updated"`
+	Deprecated []string           `json:"deprecated"`     // Removed upstream, with deprecate_removed
+	Unchanged  int                `json:"unchanged"`      // Techniques and scenarios left as stored, when incremental
+	Kept       []string           `json:"kept,omitempty"` // Techniques of another source, with own_techniques_only
+	Scenarios  []*entity.Scenario `json:"scenarios"`      // Created or updated
+	Failed     int                `json:"failed"`
+	Errors     []string           `json:"errors,omitempty"`
+}
+
+// ContentImportService imports third-party attack content through converters
+type ContentImportService struct {
+	techniques *TechniqueService
+	scenarios  *ScenarioService
+	converters map[string]ContentConverter
+}
+
+// NewContentImportService creates a content import service with the given converters
+func NewContentImportService(techniques *TechniqueService, scenarios *ScenarioService, converters ...ContentConverter) *ContentImportService {
+	s := &ContentImportService{
+		techniques: techniques,
+		scenarios:  scenarios,
+		converters: make(map[string]ContentConverter, len(converters)),
+	}
+	for _, c := range converters {
+		s.converters[c.Format()] = c
+	}
+	return s
+}
+
+// Formats returns the supported formats, sorted
+func (s *ContentImportService) Formats() []string {
+	formats := make([]string, 0, len(s.converters))
+	for format := range s.converters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Convert converts data without storing it
+func (s *ContentImportService) Convert(format string, data []byte) (*ContentBundle, error) {
+	converter, ok := s.converters[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownContentFormat, format)
+	}
+
+	bundle, err := converter.Convert(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s content: %w", format, err)
+	}
+	return bundle, nil
+}
+
+// Import converts data and stores its techniques, then its scenarios. Existing
+// techniques are updated and keep their lifecycle status and metadata, and are
+// tagged with the format in their import_source field. Items that cannot be
+// stored are reported in the result without stopping the import.
+func (s *ContentImportService) Import(ctx context.Context, format string, data []byte, opts ContentImportOptions) (*ContentImportResult, error) {
+	bundle, err := s.Convert(format, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.ImportBundle(ctx, format, bundle, opts)
+}
+
+// ImportBundle stores converted content like Import, tagging its techniques
+// with format
+func (s *ContentImportService) ImportBundle(ctx context.Context, format string, bundle *ContentBundle, opts ContentImportOptions) (*ContentImportResult, error) {
+	for _, domain := range opts.Domains {
+		if !entity.IsValidDomain(domain) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTechniqueDomain, domain)
+		}
+	}
+	converted := bundleTechniqueIDs(bundle)
+	if len(opts.Domains) > 0 {
+		filterBundleDomains(bundle, opts.Domains)
+	}
+	if len(opts.Platforms) > 0 {
+		filterBundlePlatforms(bundle, opts.Platforms)
+	}
+
+	result := &ContentImportResult{
+		Format:     format,
+		Techniques: make([]string, 0, len(bundle.Techniques)),
+		Added:      []string{},
+		Updated:    []string{},
+		Deprecated: []string{},
+		Scenarios:  make([]*entity.Scenario, 0, len(bundle.Scenarios)),
+	}
+
+	for _, technique := range bundle.Techniques {
+		action, err := s.saveTechnique(ctx, format, technique, opts)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("technique %s: %s", technique.ID, err.Error()))
+			continue
+		}
+		switch action {
+		case ContentCreate:
+			result.Added = append(result.Added, technique.ID)
+		case ContentUpdate:
+			result.Updated = append(result.Updated, technique.ID)
+		case contentKept:
+			result.Kept = append(result.Kept, technique.ID)
+			continue
+		default:
+			result.Unchanged++
+			continue
+		}
+		result.Techniques = append(result.Techniques, technique.ID)
+	}
+
+	if opts.DeprecateRemoved {
+		s.deprecateRemoved(ctx, format, converted, result)
+	}
+
+	var stored map[string]*entity.Scenario
+	if opts.Incremental {
+		var err error
+		if stored, err = s.scenariosByName(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for _, scenario := range bundle.Scenarios {
+		saved, err := s.saveScenario(ctx, scenario, stored)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("scenario %s: %s", scenario.Name, err.Error()))
+			continue
+		}
+		if !saved {
+			result.Unchanged++
+			continue
+		}
+		result.Scenarios = append(result.Scenarios, scenario)
+	}
+
+	return result, nil
+}
+
+// contentKept is the action of a stored technique of another source, left as is
+const contentKept ContentAction = "kept"
+
+// saveTechnique creates a technique, or updates it while keeping its status and
+// metadata. The metadata fields of the converted technique, such as its ATT&CK
+// release, are set over the stored ones, and a technique converted without
+// executors, from content describing techniques rather than procedures, keeps
+// the stored executors and platforms. Incremental imports leave a technique
+// that did not change as is and return no action.
+func (s *ContentImportService) saveTechnique(ctx context.Context, format string, technique *entity.Technique, opts ContentImportOptions) (ContentAction, error) {
+	fields := technique.Metadata
+	technique.Metadata = nil
+
+	existing, err := s.techniques.GetTechnique(ctx, technique.ID)
+	if err != nil || existing == nil {
+		technique.Metadata = entity.Metadata{entity.ImportSourceMetadataKey: format}
+		maps.Copy(technique.Metadata, fields)
+		return ContentCreate, s.techniques.CreateTechnique(ctx, technique)
+	}
+
+	if opts.OwnTechniquesOnly && existing.Metadata[entity.ImportSourceMetadataKey] != format {
+		return contentKept, nil
+	}
+	if len(technique.Executors) == 0 {
+		technique.Executors, technique.Platforms = existing.Executors, existing.Platforms
+	}
+
+	if opts.Incremental {
+		if err := checkContentTechnique(technique); err != nil {
+			return "", err
+		}
+		if len(techniqueChanges(technique, existing)) == 0 && existing.Metadata[entity.ImportSourceMetadataKey] == format &&
+			hasMetadata(existing.Metadata, fields) {
+			return "", nil
+		}
+	}
+
+	technique.Status = existing.Status
+	technique.Metadata = make(entity.Metadata, len(existing.Metadata)+len(fields)+1)
+	maps.Copy(technique.Metadata, existing.Metadata)
+	maps.Copy(technique.Metadata, fields)
+	technique.Metadata[entity.ImportSourceMetadataKey] = format
+	return ContentUpdate, s.techniques.UpdateTechnique(ctx, technique)
+}
+
+// hasMetadata reports whether metadata has every field of fields, with the same value
+func hasMetadata(metadata, fields entity.Metadata) bool {
+	for field, value := range fields {
+		if metadata[field] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// deprecateRemoved deprecates the techniques imported from format that are not
+// among the converted ones. Techniques already deprecated, or drafts that
+// cannot be, are left as is.
+func (s *ContentImportService) deprecateRemoved(ctx context.Context, format string, imported map[string]bool, result *ContentImportResult) {
+	techniques, err := s.techniques.GetAllTechniques(ctx)
+	if err != nil {
+		result.Failed++
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to load techniques: %s", err.Error()))
+		return
+	}
+
+	for _, technique := range techniques {
+		if imported[technique.ID] || technique.Metadata[entity.ImportSourceMetadataKey] != format ||
+			!technique.LifecycleStatus().CanTransitionTo(entity.TechniqueDeprecated) {
+			continue
+		}
+		if _, err := s.techniques.SetTechniqueStatus(ctx, technique.ID, entity.TechniqueDeprecated); err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("technique %s: %s", technique.ID, err.Error()))
+			continue
+		}
+		result.Deprecated = append(result.Deprecated, technique.ID)
+	}
+	sort.Strings(result.Deprecated)
+}
+
+// bundleTechniqueIDs returns the IDs of the techniques of a bundle
+func bundleTechniqueIDs(bundle *ContentBundle) map[string]bool {
+	ids := make(map[string]bool, len(bundle.Techniques))
+	for _, technique := range bundle.Techniques {
+		ids[technique.ID] = true
+	}
+	return ids
+}
+
+// platformAliases maps the ATT&CK matrix names to the agent platforms
+var platformAliases = map[string]string{
+	"macos": "darwin",
+}
+
+// filterBundlePlatforms keeps the techniques of a bundle running on one of
+// platforms, limited to those platforms. Scenarios lose the techniques left
+// out.
+func filterBundlePlatforms(bundle *ContentBundle, platforms []string) {
+	wanted := make(map[string]bool, len(platforms))
+	for _, platform := range platforms {
+		platform = strings.ToLower(strings.TrimSpace(platform))
+		if alias, ok := platformAliases[platform]; ok {
+			platform = alias
+		}
+		wanted[platform] = true
+	}
+
+	dropped := make(map[string]bool)
+	techniques := bundle.Techniques[:0]
+	for _, technique := range bundle.Techniques {
+		var matched []string
+		for _, platform := range technique.Platforms {
+			if wanted[strings.ToLower(platform)] {
+				matched = append(matched, platform)
+			}
+		}
+		if len(matched) == 0 {
+			dropped[technique.ID] = true
+			continue
+		}
+		technique.Platforms = matched
+		for i := range technique.Executors {
+			executor := &technique.Executors[i]
+			for platform := range executor.Variants {
+				if !slices.Contains(matched, platform) {
+					delete(executor.Variants, platform)
+				}
+			}
+			if len(executor.Variants) == 0 {
+				executor.Variants = nil
+			}
+		}
+		techniques = append(techniques, technique)
+	}
+	bundle.Techniques = techniques
+	dropBundleTechniques(bundle, dropped)
+}
+
+// filterBundleDomains keeps the techniques of a bundle of one of domains, a
+// technique without a domain belonging to enterprise-attack
+func filterBundleDomains(bundle *ContentBundle, domains []string) {
+	dropped := make(map[string]bool)
+	techniques := bundle.Techniques[:0]
+	for _, technique := range bundle.Techniques {
+		domain := technique.Domain
+		if domain == "" {
+			domain = entity.DomainEnterprise
+		}
+		if !slices.Contains(domains, domain) {
+			dropped[technique.ID] = true
+			continue
+		}
+		techniques = append(techniques, technique)
+	}
+	bundle.Techniques = techniques
+	dropBundleTechniques(bundle, dropped)
+}
+
+// dropBundleTechniques removes the dropped techniques from the scenarios of a
+// bundle, and the phases and scenarios left empty; their catalog techniques stay
+func dropBundleTechniques(bundle *ContentBundle, dropped map[string]bool) {
+	scenarios := bundle.Scenarios[:0]
+	for _, scenario := range bundle.Scenarios {
+		phases := scenario.Phases[:0]
+		for _, phase := range scenario.Phases {
+			phase.Techniques = slices.DeleteFunc(phase.Techniques, func(id string) bool { return dropped[id] })
+			if len(phase.Techniques) > 0 {
+				phase.Order = len(phases) + 1
+				phases = append(phases, phase)
+			}
+		}
+		scenario.Phases = phases
+		if len(phases) > 0 {
+			scenarios = append(scenarios, scenario)
+		}
+	}
+	bundle.Scenarios = scenarios
+}
+
+// scenariosByName returns the stored scenarios by name, the first one for a
+// name used twice
+func (s *ContentImportService) scenariosByName(ctx context.Context) (map[string]*entity.Scenario, error) {
+	scenarios, err := s.scenarios.GetAllScenarios(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scenarios: %w", err)
+	}
+	byName := make(map[string]*entity.Scenario, len(scenarios))
+	for _, scenario := range scenarios {
+		if _, ok := byName[scenario.Name]; !ok && !scenario.IsTemplate {
+			byName[scenario.Name] = scenario
+		}
+	}
+	return byName, nil
+}
+
+// saveScenario creates a scenario, or with the stored scenarios of an
+// incremental import updates the one of the same name when it changed. It
+// reports whether the scenario was written.
+func (s *ContentImportService) saveScenario(ctx context.Context, scenario *entity.Scenario, stored map[string]*entity.Scenario) (bool, error) {
+	current, ok := stored[scenario.Name]
+	if !ok {
+		return true, s.scenarios.CreateScenario(ctx, scenario)
+	}
+	if len(scenarioChanges(scenario, current)) == 0 {
+		return false, nil
+	}
+	scenario.ID, scenario.CreatedAt = current.ID, current.CreatedAt
+	return true, s.scenarios.UpdateScenario(ctx, scenario)
+}
@@ -0,0 +1,220 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+type stubContentConverter struct {
+	format string // "stub" when empty
+	bundle *ContentBundle
+	err    error
+}
+
+func (c *stubContentConverter) Format() string {
+	if c.format != "" {
+		return c.format
+	}
+	return "stub"
+}
+
+func (c *stubContentConverter) Convert(data []byte) (*ContentBundle, error) {
+	return c.bundle, c.err
+}
+
+func TestContentImportService_Import(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "Old", Status: entity.TechniqueDeprecated}
+	scenarioRepo := newMockScenarioRepo()
+
+	converter := &stubContentConverter{bundle: &ContentBundle{
+		Techniques: []*entity.Technique{
+			{ID: "T1082", Name: "System Information Discovery", Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "uname -a"}}},
+			{ID: "T1057", Name: "Process Discovery", Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "ps"}}},
+		},
+		Scenarios: []*entity.Scenario{
+			{Name: "Imported", Phases: []entity.Phase{{Name: "Discovery", Techniques: []string{"T1057"}, Order: 1}}},
+			{Name: "Broken", Phases: []entity.Phase{{Name: "Discovery", Techniques: []string{"T9999"}, Order: 1}}},
+		},
+	}}
+	svc := NewContentImportService(
+		NewTechniqueService(techRepo),
+		NewScenarioService(scenarioRepo, techRepo, service.NewTechniqueValidator()),
+		converter,
+	)
+
+	result, err := svc.Import(context.Background(), "stub", []byte("data"), ContentImportOptions{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if len(result.Techniques) != 2 || len(result.Scenarios) != 1 || result.Failed != 1 || len(result.Errors) != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(result.Added) != 1 || result.Added[0] != "T1057" || len(result.Updated) != 1 || result.Updated[0] != "T1082" {
+		t.Errorf("Unexpected added and updated techniques: %v %v", result.Added, result.Updated)
+	}
+	updated := techRepo.techniques["T1082"]
+	if updated.Name != "System Information Discovery" || updated.Status != entity.TechniqueDeprecated {
+		t.Errorf("Expected the technique updated with its status kept, got %+v", updated)
+	}
+	if updated.Metadata[entity.ImportSourceMetadataKey] != "stub" {
+		t.Errorf("Expected the import source recorded, got %v", updated.Metadata)
+	}
+	if len(scenarioRepo.scenarios) != 1 {
+		t.Errorf("Expected 1 stored scenario, got %d", len(scenarioRepo.scenarios))
+	}
+}
+
+func TestContentImportService_Incremental(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1003"] = &entity.Technique{ID: "T1003", Name: "Dumped", Metadata: entity.Metadata{entity.ImportSourceMetadataKey: "stub"}}
+	techRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Manual", Metadata: entity.Metadata{"owner": "red-team"}}
+	scenarioRepo := newMockScenarioRepo()
+	bundle := func(command string) *ContentBundle {
+		return &ContentBundle{
+			Techniques: []*entity.Technique{
+				{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery, Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: command}}},
+				{ID: "T1057", Name: "Process Discovery", Tactic: entity.TacticDiscovery, Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "ps"}}},
+			},
+			Scenarios: []*entity.Scenario{
+				{Name: "Imported", Phases: []entity.Phase{{Name: "Discovery", Techniques: []string{"T1057", "T1082"}, Order: 1}}},
+			},
+		}
+	}
+	importBundle := func(b *ContentBundle, opts ContentImportOptions) *ContentImportResult {
+		t.Helper()
+		svc := NewContentImportService(
+			NewTechniqueService(techRepo),
+			NewScenarioService(scenarioRepo, techRepo, service.NewTechniqueValidator()),
+			&stubContentConverter{bundle: b},
+		)
+		result, err := svc.Import(context.Background(), "stub", []byte("data"), opts)
+		if err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+		return result
+	}
+	opts := ContentImportOptions{Incremental: true, DeprecateRemoved: true}
+
+	first := importBundle(bundle("uname -a"), opts)
+	if len(first.Added) != 2 || len(first.Deprecated) != 1 || first.Deprecated[0] != "T1003" || len(first.Scenarios) != 1 {
+		t.Errorf("Unexpected first import: %+v", first)
+	}
+	if techRepo.techniques["T1003"].Status != entity.TechniqueDeprecated || techRepo.techniques["T1059"].Status != "" {
+		t.Error("Only the technique imported from the format should be deprecated")
+	}
+
+	// Nothing changed upstream: nothing is written
+	second := importBundle(bundle("uname -a"), opts)
+	if len(second.Techniques) != 0 || len(second.Deprecated) != 0 || len(second.Scenarios) != 0 || second.Unchanged != 3 {
+		t.Errorf("Expected an unchanged import, got %+v", second)
+	}
+	if len(scenarioRepo.scenarios) != 1 {
+		t.Errorf("Expected the scenario not duplicated, got %d", len(scenarioRepo.scenarios))
+	}
+
+	third := importBundle(bundle("uname -srm"), opts)
+	if len(third.Updated) != 1 || third.Updated[0] != "T1082" || third.Unchanged != 2 {
+		t.Errorf("Expected only the changed technique updated, got %+v", third)
+	}
+	if techRepo.techniques["T1082"].Executors[0].Command != "uname -srm" {
+		t.Error("Expected the changed command stored")
+	}
+}
+
+func TestContentImportService_Platforms(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "Stored", Platforms: []string{"windows"}, Metadata: entity.Metadata{entity.ImportSourceMetadataKey: "stub"}}
+	techRepo.techniques["T1016"] = &entity.Technique{ID: "T1016", Name: "Catalog", Platforms: []string{"linux"}}
+	scenarioRepo := newMockScenarioRepo()
+	converter := &stubContentConverter{bundle: &ContentBundle{
+		Techniques: []*entity.Technique{
+			{ID: "T1082", Name: "System Information Discovery", Platforms: []string{"windows"}, Executors: []entity.Executor{{Type: "psh", Command: "systeminfo"}}},
+			{ID: "T1057", Name: "Process Discovery", Platforms: []string{"linux", "darwin"}, Executors: []entity.Executor{{
+				Type: "sh", Command: "ps aux", Variants: map[string]entity.CommandVariant{"linux": {Command: "ps -ef"}},
+			}}},
+		},
+		Scenarios: []*entity.Scenario{{Name: "Imported", Phases: []entity.Phase{
+			{Name: "Windows", Techniques: []string{"T1082"}, Order: 1},
+			{Name: "Unix", Techniques: []string{"T1057", "T1016"}, Order: 2},
+		}}},
+	}}
+	svc := NewContentImportService(
+		NewTechniqueService(techRepo),
+		NewScenarioService(scenarioRepo, techRepo, service.NewTechniqueValidator()),
+		converter,
+	)
+
+	result, err := svc.Import(context.Background(), "stub", []byte("data"), ContentImportOptions{DeprecateRemoved: true, Platforms: []string{"macOS"}})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	// The windows technique is still upstream: it is left out, not deprecated
+	if len(result.Added) != 1 || result.Added[0] != "T1057" || len(result.Deprecated) != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	processes := techRepo.techniques["T1057"]
+	if len(processes.Platforms) != 1 || processes.Platforms[0] != "darwin" || processes.Executors[0].Variants != nil {
+		t.Errorf("Expected the technique limited to darwin, got %+v", processes)
+	}
+	if len(result.Scenarios) != 1 {
+		t.Fatalf("Expected the scenario imported, got %+v", result)
+	}
+	phases := result.Scenarios[0].Phases
+	if len(phases) != 1 || phases[0].Name != "Unix" || phases[0].Order != 1 || len(phases[0].Techniques) != 2 {
+		t.Errorf("Expected the phases of the left out techniques dropped, got %+v", phases)
+	}
+}
+
+func TestContentImportService_Domains(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	converter := &stubContentConverter{bundle: &ContentBundle{
+		Techniques: []*entity.Technique{
+			{ID: "T1082", Name: "System Information Discovery", Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "uname -a"}}},
+			{ID: "T0800", Name: "Activate Firmware Update Mode", Domain: entity.DomainICS},
+			{ID: "T1404", Name: "Exploitation for Privilege Escalation", Domain: entity.DomainMobile},
+		},
+		Scenarios: []*entity.Scenario{{Name: "ICS", Phases: []entity.Phase{{Name: "Inhibit", Techniques: []string{"T0800"}, Order: 1}}}},
+	}}
+	svc := NewContentImportService(
+		NewTechniqueService(techRepo),
+		NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator()),
+		converter,
+	)
+
+	result, err := svc.Import(context.Background(), "stub", []byte("data"), ContentImportOptions{Domains: []string{entity.DomainEnterprise, entity.DomainMobile}})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !slices.Equal(result.Added, []string{"T1082", "T1404"}) || len(result.Scenarios) != 0 {
+		t.Errorf("Expected the enterprise and mobile techniques only, got %+v", result)
+	}
+	if techRepo.techniques["T1404"].Domain != entity.DomainMobile {
+		t.Errorf("Expected the domain stored, got %+v", techRepo.techniques["T1404"])
+	}
+
+	if _, err := svc.Import(context.Background(), "stub", []byte("data"), ContentImportOptions{Domains: []string{"pre-attack"}}); !errors.Is(err, ErrInvalidTechniqueDomain) {
+		t.Errorf("Expected ErrInvalidTechniqueDomain, got %v", err)
+	}
+}
+
+func TestContentImportService_Errors(t *testing.T) {
+	svc := NewContentImportService(nil, nil, &stubContentConverter{err: errors.New("bad file")})
+
+	if _, err := svc.Import(context.Background(), "unknown", nil, ContentImportOptions{}); !errors.Is(err, ErrUnknownContentFormat) {
+		t.Errorf("Expected ErrUnknownContentFormat, got %v", err)
+	}
+	if _, err := svc.Import(context.Background(), "stub", nil, ContentImportOptions{}); err == nil {
+		t.Error("Expected the conversion error")
+	}
+	if formats := svc.Formats(); len(formats) != 1 || formats[0] != "stub" {
+		t.Errorf("Unexpected formats: %v", formats)
+	}
+}
@@ -0,0 +1,529 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrContentPlanStale is returned when the content changed since the plan was reviewed
+var ErrContentPlanStale = errors.New("content changed since the plan was made, plan again")
+
+// ContentAction is what applying a plan does to a technique or scenario
+type ContentAction string
+
+const (
+	ContentCreate  ContentAction = "create"
+	ContentUpdate  ContentAction = "update"
+	ContentRestore ContentAction = "restore" // Taken out of the trash and updated
+	ContentDelete  ContentAction = "delete"  // Moved to the trash, only when pruning
+)
+
+// Kinds of content items in a plan
+const (
+	ContentTechnique = "technique"
+	ContentScenario  = "scenario"
+)
+
+// ContentSource is the content of a directory of technique and scenario YAML
+// files, the desired state of the catalog
+type ContentSource struct {
+	Techniques []*entity.Technique `json:"techniques"`
+	Scenarios  []*entity.Scenario  `json:"scenarios"`
+}
+
+// ContentChange is a change of a plan
+type ContentChange struct {
+	Kind   string        `json:"kind"`
+	ID     string        `json:"id"`
+	Name   string        `json:"name"`
+	Action ContentAction `json:"action"`
+	Fields []string      `json:"fields,omitempty"` // Fields an update changes
+}
+
+// ContentPlan lists the changes that make the database match a content source.
+// Its checksum identifies the changes, so that an apply can check nothing
+// changed since the plan was reviewed.
+type ContentPlan struct {
+	Changes   []ContentChange `json:"changes"`
+	Unchanged int             `json:"unchanged"`
+	Prune     bool            `json:"prune"`
+	Errors    []string        `json:"errors,omitempty"` // Invalid items, left out of the plan
+	Checksum  string          `json:"checksum"`
+}
+
+// ContentApplyResult summarizes an applied plan
+type ContentApplyResult struct {
+	Plan    *ContentPlan `json:"plan"`
+	Applied int          `json:"applied"`
+	Errors  []string     `json:"errors,omitempty"`
+}
+
+// ContentPlanService diffs technique and scenario YAML against the database
+// and applies the resulting plan, instead of upserting the files blindly
+type ContentPlanService struct {
+	techniqueRepo repository.TechniqueRepository
+	scenarioRepo  repository.ScenarioRepository
+	trashRepo     repository.TrashRepository
+	logger        *zap.Logger
+}
+
+// NewContentPlanService creates a content plan service
+func NewContentPlanService(
+	techniqueRepo repository.TechniqueRepository,
+	scenarioRepo repository.ScenarioRepository,
+	trashRepo repository.TrashRepository,
+	logger *zap.Logger,
+) *ContentPlanService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ContentPlanService{
+		techniqueRepo: techniqueRepo,
+		scenarioRepo:  scenarioRepo,
+		trashRepo:     trashRepo,
+		logger:        logger,
+	}
+}
+
+// contentDirs are the subdirectories of a content directory, techniques first
+// since scenarios use them
+var contentDirs = []string{"techniques", "scenarios"}
+
+// LoadContentDir reads the technique files of dir/techniques and the scenario
+// files of dir/scenarios. A missing subdirectory has no content; an ID defined
+// twice is an error.
+func LoadContentDir(dir string) (*ContentSource, error) {
+	files, err := contentFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &ContentSource{}
+	defined := make(map[string]string)
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content, err := parseContentFile(file, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, technique := range content.Techniques {
+			if err := defineContent(defined, ContentTechnique, technique.ID, path); err != nil {
+				return nil, err
+			}
+		}
+		for _, scenario := range content.Scenarios {
+			if err := defineContent(defined, ContentScenario, scenario.ID, path); err != nil {
+				return nil, err
+			}
+		}
+		source.Techniques = append(source.Techniques, content.Techniques...)
+		source.Scenarios = append(source.Scenarios, content.Scenarios...)
+	}
+	return source, nil
+}
+
+// defineContent records the file an item is defined in, failing when another file defines it
+func defineContent(defined map[string]string, kind, id, path string) error {
+	if id == "" {
+		return nil
+	}
+	if other, ok := defined[kind+" "+id]; ok {
+		return fmt.Errorf("%s %s is also defined in %s", kind, id, other)
+	}
+	defined[kind+" "+id] = path
+	return nil
+}
+
+// contentFiles lists the YAML files of a content directory, relative to it,
+// in name order within each subdirectory
+func contentFiles(dir string) ([]string, error) {
+	var files []string
+	for _, sub := range contentDirs {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isContentFile(entry.Name()) {
+				files = append(files, filepath.Join(sub, entry.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// isContentFile reports whether a file name is a YAML file
+func isContentFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// parseContentFile parses a file of a content directory: a list of techniques
+// under techniques/, of scenarios under scenarios/
+func parseContentFile(file string, data []byte) (*ContentSource, error) {
+	source := &ContentSource{}
+	if filepath.Dir(file) == "techniques" {
+		return source, yaml.Unmarshal(data, &source.Techniques)
+	}
+	return source, yaml.Unmarshal(data, &source.Scenarios)
+}
+
+// Plan diffs the source against the database. With prune, the techniques and
+// scenarios the source does not define are deleted.
+func (s *ContentPlanService) Plan(ctx context.Context, source *ContentSource, prune bool) (*ContentPlan, error) {
+	plan := &ContentPlan{Changes: []ContentChange{}, Prune: prune}
+
+	techniques, err := s.techniqueRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load techniques: %w", err)
+	}
+	scenarios, err := s.scenarioRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scenarios: %w", err)
+	}
+	trashedTechniques, trashedScenarios, err := s.trashedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTechniques := make(map[string]*entity.Technique, len(techniques))
+	for _, technique := range techniques {
+		currentTechniques[technique.ID] = technique
+	}
+	wanted := make(map[string]bool)
+	for _, technique := range source.Techniques {
+		// Content files list sub-techniques by ID only
+		setTechniqueParent(technique)
+		if err := checkContentTechnique(technique); err != nil {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("technique %s: %v", technique.ID, err))
+			continue
+		}
+		wanted[technique.ID] = true
+		change := ContentChange{Kind: ContentTechnique, ID: technique.ID, Name: technique.Name}
+		switch current, ok := currentTechniques[technique.ID]; {
+		case ok:
+			if change.Fields = techniqueChanges(technique, current); len(change.Fields) == 0 {
+				plan.Unchanged++
+				continue
+			}
+			change.Action = ContentUpdate
+		case trashedTechniques[technique.ID]:
+			change.Action = ContentRestore
+		default:
+			change.Action = ContentCreate
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	var deletes []ContentChange
+	if prune {
+		for _, technique := range techniques {
+			if !wanted[technique.ID] {
+				deletes = append(deletes, ContentChange{Kind: ContentTechnique, ID: technique.ID, Name: technique.Name, Action: ContentDelete})
+			}
+		}
+	}
+
+	currentScenarios := make(map[string]*entity.Scenario, len(scenarios))
+	for _, scenario := range scenarios {
+		currentScenarios[scenario.ID] = scenario
+	}
+	wanted = make(map[string]bool)
+	for _, scenario := range source.Scenarios {
+		if scenario.ID == "" || scenario.Name == "" {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("scenario %s: id and name are required", scenario.ID))
+			continue
+		}
+		wanted[scenario.ID] = true
+		change := ContentChange{Kind: ContentScenario, ID: scenario.ID, Name: scenario.Name}
+		switch current, ok := currentScenarios[scenario.ID]; {
+		case ok:
+			if change.Fields = scenarioChanges(scenario, current); len(change.Fields) == 0 {
+				plan.Unchanged++
+				continue
+			}
+			change.Action = ContentUpdate
+		case trashedScenarios[scenario.ID]:
+			change.Action = ContentRestore
+		default:
+			change.Action = ContentCreate
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	if prune {
+		// Scenarios go first, before the techniques they use
+		var scenarioDeletes []ContentChange
+		for _, scenario := range scenarios {
+			if !wanted[scenario.ID] {
+				scenarioDeletes = append(scenarioDeletes, ContentChange{Kind: ContentScenario, ID: scenario.ID, Name: scenario.Name, Action: ContentDelete})
+			}
+		}
+		deletes = append(scenarioDeletes, deletes...)
+	}
+	// By kind then ID, so that the same state always makes the same plan
+	sort.SliceStable(deletes, func(i, j int) bool {
+		if deletes[i].Kind != deletes[j].Kind {
+			return deletes[i].Kind == ContentScenario
+		}
+		return deletes[i].ID < deletes[j].ID
+	})
+	plan.Changes = append(plan.Changes, deletes...)
+
+	plan.Checksum, err = contentChecksum(source, plan)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Apply plans the source again and applies the changes. A non-empty checksum
+// must match the new plan, otherwise ErrContentPlanStale is returned and
+// nothing is applied. Changes that fail are reported and skipped.
+func (s *ContentPlanService) Apply(ctx context.Context, source *ContentSource, prune bool, checksum string) (*ContentApplyResult, error) {
+	plan, err := s.Plan(ctx, source, prune)
+	if err != nil {
+		return nil, err
+	}
+	if checksum != "" && checksum != plan.Checksum {
+		return nil, ErrContentPlanStale
+	}
+
+	techniques := make(map[string]*entity.Technique, len(source.Techniques))
+	for _, technique := range source.Techniques {
+		techniques[technique.ID] = technique
+	}
+	scenarios := make(map[string]*entity.Scenario, len(source.Scenarios))
+	for _, scenario := range source.Scenarios {
+		scenarios[scenario.ID] = scenario
+	}
+
+	result := &ContentApplyResult{Plan: plan}
+	for _, change := range plan.Changes {
+		var err error
+		if change.Kind == ContentTechnique {
+			err = s.applyTechnique(ctx, change, techniques[change.ID])
+		} else {
+			err = s.applyScenario(ctx, change, scenarios[change.ID])
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s %s: %v", change.Action, change.Kind, change.ID, err))
+			continue
+		}
+		result.Applied++
+		s.logger.Info("Applied content change",
+			zap.String("kind", change.Kind),
+			zap.String("id", change.ID),
+			zap.String("action", string(change.Action)),
+			zap.Strings("fields", change.Fields),
+		)
+	}
+	return result, nil
+}
+
+func (s *ContentPlanService) applyTechnique(ctx context.Context, change ContentChange, technique *entity.Technique) error {
+	switch change.Action {
+	case ContentCreate:
+		return s.techniqueRepo.Create(ctx, technique)
+	case ContentRestore:
+		if _, err := s.trashRepo.RestoreTechnique(ctx, change.ID); err != nil {
+			return err
+		}
+		return s.techniqueRepo.Update(ctx, technique)
+	case ContentUpdate:
+		return s.techniqueRepo.Update(ctx, technique)
+	default:
+		return s.techniqueRepo.Delete(ctx, change.ID)
+	}
+}
+
+func (s *ContentPlanService) applyScenario(ctx context.Context, change ContentChange, scenario *entity.Scenario) error {
+	switch change.Action {
+	case ContentCreate:
+		scenario.CreatedAt = time.Now()
+		scenario.UpdatedAt = scenario.CreatedAt
+		return s.scenarioRepo.Create(ctx, scenario)
+	case ContentRestore:
+		if _, err := s.trashRepo.RestoreScenario(ctx, change.ID); err != nil {
+			return err
+		}
+		return s.scenarioRepo.Update(ctx, scenario)
+	case ContentUpdate:
+		return s.scenarioRepo.Update(ctx, scenario)
+	default:
+		return s.scenarioRepo.Delete(ctx, change.ID)
+	}
+}
+
+// trashedIDs returns the IDs of the techniques and scenarios in the trash
+func (s *ContentPlanService) trashedIDs(ctx context.Context) (map[string]bool, map[string]bool, error) {
+	techniques, scenarios := make(map[string]bool), make(map[string]bool)
+	if s.trashRepo == nil {
+		return techniques, scenarios, nil
+	}
+	deletedTechniques, err := s.trashRepo.FindDeletedTechniques(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load the trash: %w", err)
+	}
+	for _, technique := range deletedTechniques {
+		techniques[technique.ID] = true
+	}
+	deletedScenarios, err := s.trashRepo.FindDeletedScenarios(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load the trash: %w", err)
+	}
+	for _, scenario := range deletedScenarios {
+		scenarios[scenario.ID] = true
+	}
+	return techniques, scenarios, nil
+}
+
+// checkContentTechnique validates a technique of a content source and fills
+// in what the repository derives: the tactics, the severity and the impact
+func checkContentTechnique(technique *entity.Technique) error {
+	if technique.ID == "" || technique.Name == "" {
+		return errors.New("id and name are required")
+	}
+	if err := validateTechnique(technique); err != nil {
+		return err
+	}
+	technique.NormalizeTactics()
+	technique.AssessRisk()
+	return nil
+}
+
+// contentField is a stored field of a technique, as desired and as stored
+type contentField struct {
+	name             string
+	desired, current any
+}
+
+// techniqueChanges returns the stored fields of current that differ in
+// desired. An empty status, domain, parent and no metadata keep the stored
+// ones, and so the severity and impact overridden in the stored metadata.
+func techniqueChanges(desired, current *entity.Technique) []string {
+	fields := []contentField{
+		{"name", desired.Name, current.Name},
+		{"description", desired.Description, current.Description},
+		{"tactic", desired.Tactic, current.Tactic},
+		{"tactics", desired.Tactics, current.Tactics},
+		{"platforms", desired.Platforms, current.Platforms},
+		{"executors", desired.Executors, current.Executors},
+		{"detection", desired.Detection, current.Detection},
+		{"sigma_rules", desired.SigmaRules, current.SigmaRules},
+		{"is_safe", desired.IsSafe, current.IsSafe},
+	}
+	if desired.ParentID != "" {
+		fields = append(fields, contentField{"parent_id", desired.ParentID, current.ParentID})
+	}
+	if desired.Status != "" {
+		fields = append(fields, contentField{"status", desired.Status, current.Status})
+	}
+	if desired.Domain != "" {
+		fields = append(fields, contentField{"domain", desired.Domain, current.Domain})
+	}
+	if desired.Metadata != nil {
+		fields = append(fields, contentField{"metadata", desired.Metadata, current.Metadata})
+	}
+	if desired.Metadata != nil || !current.HasRiskOverride() {
+		// Compared with the severity and impact current is read with
+		assessed := *current
+		assessed.AssessRisk()
+		fields = append(fields,
+			contentField{"severity", desired.Severity, assessed.Severity},
+			contentField{"impact", desired.Impact, assessed.Impact},
+		)
+	}
+
+	var changed []string
+	for _, field := range fields {
+		if !sameContent(field.desired, field.current) {
+			changed = append(changed, field.name)
+		}
+	}
+	return changed
+}
+
+// scenarioChanges returns the stored fields of current that differ in desired
+func scenarioChanges(desired, current *entity.Scenario) []string {
+	var changed []string
+	if desired.Name != current.Name {
+		changed = append(changed, "name")
+	}
+	if desired.Description != current.Description {
+		changed = append(changed, "description")
+	}
+	if !sameContent(desired.Phases, current.Phases) {
+		changed = append(changed, "phases")
+	}
+	if !sameContent(desired.Tags, current.Tags) {
+		changed = append(changed, "tags")
+	}
+	return changed
+}
+
+// sameContent reports whether two values encode to the same JSON, an empty
+// list or map being the same as none
+func sameContent(a, b any) bool {
+	return contentJSON(a) == contentJSON(b)
+}
+
+func contentJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	switch encoded := string(data); encoded {
+	case "null", "[]", "{}":
+		return ""
+	default:
+		return encoded
+	}
+}
+
+// contentChecksum identifies a plan of a source: the hex SHA-256 of the
+// source and of the changes
+func contentChecksum(source *ContentSource, plan *ContentPlan) (string, error) {
+	hash := sha256.New()
+	for _, v := range []any{source, plan.Changes, plan.Prune} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Summary counts the changes of a plan by action, e.g. "2 to create, 1 to update"
+func (p *ContentPlan) Summary() string {
+	counts := make(map[ContentAction]int)
+	for _, change := range p.Changes {
+		counts[change.Action]++
+	}
+	var parts []string
+	for _, action := range []ContentAction{ContentCreate, ContentUpdate, ContentRestore, ContentDelete} {
+		parts = append(parts, fmt.Sprintf("%d to %s", counts[action], action))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,185 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+func newContentPlanFixture() (*ContentPlanService, *mockTechniqueRepo, *mockScenarioRepo, *mockTrashRepo) {
+	techniques := newMockTechniqueRepo()
+	techniques.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery,
+		Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux"}, IsSafe: true, Status: entity.TechniqueActive,
+		Metadata: entity.Metadata{"owner": "soc"}}
+	techniques.techniques["T1016"] = &entity.Technique{ID: "T1016", Name: "Network Discovery", Tactic: entity.TacticDiscovery,
+		Tactics: []entity.TacticType{entity.TacticDiscovery}}
+	techniques.techniques["T1000"] = &entity.Technique{ID: "T1000", Name: "Removed", Tactic: entity.TacticDiscovery}
+	scenarios := newMockScenarioRepo()
+	scenarios.scenarios["discovery"] = &entity.Scenario{ID: "discovery", Name: "Discovery",
+		Phases: []entity.Phase{{Name: "Recon", Techniques: []string{"T1082"}, Order: 1}}, Tags: []string{}}
+	scenarios.scenarios["custom"] = &entity.Scenario{ID: "custom", Name: "Created through the API"}
+	trash := newMockTrashRepo()
+	trash.techniques["T1057"] = &entity.Technique{ID: "T1057"}
+
+	return NewContentPlanService(techniques, scenarios, trash, nil), techniques, scenarios, trash
+}
+
+// contentSource is the desired state: T1082 unchanged, T1016 renamed, T1057
+// out of the trash, T1083 new, the discovery scenario with a new phase
+func contentSource() *ContentSource {
+	return &ContentSource{
+		Techniques: []*entity.Technique{
+			{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery, Platforms: []string{"linux"}, IsSafe: true},
+			{ID: "T1016", Name: "System Network Configuration Discovery", Tactic: entity.TacticDiscovery},
+			{ID: "T1057", Name: "Process Discovery", Tactic: entity.TacticDiscovery},
+			{ID: "T1083", Name: "File and Directory Discovery", Tactic: entity.TacticDiscovery},
+			{ID: "", Name: "No ID"},
+		},
+		Scenarios: []*entity.Scenario{
+			{ID: "discovery", Name: "Discovery", Phases: []entity.Phase{
+				{Name: "Recon", Techniques: []string{"T1082"}, Order: 1},
+				{Name: "Files", Techniques: []string{"T1083"}, Order: 2},
+			}},
+		},
+	}
+}
+
+func TestContentPlanService_Plan(t *testing.T) {
+	svc, techniques, _, _ := newContentPlanFixture()
+	ctx := context.Background()
+
+	plan, err := svc.Plan(ctx, contentSource(), false)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	got := make(map[string]ContentChange)
+	for _, change := range plan.Changes {
+		got[change.ID] = change
+	}
+	if len(plan.Changes) != 4 || plan.Unchanged != 1 || len(plan.Errors) != 1 || plan.Checksum == "" {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	if got["T1016"].Action != ContentUpdate || strings.Join(got["T1016"].Fields, ",") != "name" ||
+		got["T1057"].Action != ContentRestore || got["T1083"].Action != ContentCreate ||
+		got["discovery"].Action != ContentUpdate || strings.Join(got["discovery"].Fields, ",") != "phases" {
+		t.Errorf("Unexpected changes: %+v", plan.Changes)
+	}
+	if plan.Summary() != "1 to create, 2 to update, 1 to restore, 0 to delete" {
+		t.Errorf("Unexpected summary: %s", plan.Summary())
+	}
+	if techniques.techniques["T1016"].Name != "Network Discovery" {
+		t.Error("Expected nothing written by a plan")
+	}
+
+	// Pruning deletes the scenarios first, then the techniques
+	plan, err = svc.Plan(ctx, contentSource(), true)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	deletes := plan.Changes[len(plan.Changes)-2:]
+	if deletes[0].ID != "custom" || deletes[0].Action != ContentDelete || deletes[1].ID != "T1000" || deletes[1].Action != ContentDelete {
+		t.Errorf("Unexpected deletes: %+v", deletes)
+	}
+}
+
+func TestContentPlanService_Apply(t *testing.T) {
+	svc, techniques, scenarios, trash := newContentPlanFixture()
+	ctx := context.Background()
+
+	plan, err := svc.Plan(ctx, contentSource(), true)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+
+	// The database changed since the plan was reviewed
+	scenarios.scenarios["other"] = &entity.Scenario{ID: "other", Name: "Created meanwhile"}
+	if _, err := svc.Apply(ctx, contentSource(), true, plan.Checksum); !errors.Is(err, ErrContentPlanStale) {
+		t.Fatalf("Expected ErrContentPlanStale, got %v", err)
+	}
+	if _, ok := techniques.techniques["T1083"]; ok {
+		t.Fatal("Expected nothing applied from a stale plan")
+	}
+
+	plan, _ = svc.Plan(ctx, contentSource(), true)
+	result, err := svc.Apply(ctx, contentSource(), true, plan.Checksum)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if result.Applied != 7 || len(result.Errors) != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if techniques.techniques["T1016"].Name != "System Network Configuration Discovery" || techniques.techniques["T1083"] == nil ||
+		techniques.techniques["T1057"] == nil || len(trash.techniques) != 0 || techniques.techniques["T1000"] != nil {
+		t.Errorf("Unexpected techniques: %v", techniques.techniques)
+	}
+	if len(scenarios.scenarios["discovery"].Phases) != 2 || scenarios.scenarios["custom"] != nil || scenarios.scenarios["other"] != nil {
+		t.Errorf("Unexpected scenarios: %v", scenarios.scenarios)
+	}
+
+	// Applied content plans no change
+	plan, err = svc.Plan(ctx, contentSource(), true)
+	if err != nil || len(plan.Changes) != 0 || plan.Unchanged != 5 {
+		t.Errorf("Expected no change, got %+v (%v)", plan, err)
+	}
+}
+
+func TestContentPlanService_KeepsStoredFields(t *testing.T) {
+	svc, techniques, _, _ := newContentPlanFixture()
+
+	// The file sets no status nor metadata, and T1082 is unchanged
+	source := &ContentSource{Techniques: []*entity.Technique{contentSource().Techniques[0]}}
+	plan, err := svc.Plan(context.Background(), source, false)
+	if err != nil || len(plan.Changes) != 0 {
+		t.Fatalf("Expected no change, got %+v (%v)", plan, err)
+	}
+
+	source.Techniques[0].Status = entity.TechniqueDeprecated
+	plan, _ = svc.Plan(context.Background(), source, false)
+	if len(plan.Changes) != 1 || strings.Join(plan.Changes[0].Fields, ",") != "status" {
+		t.Errorf("Expected the status changed, got %+v", plan.Changes)
+	}
+
+	techniques.err = errors.New("db down")
+	if _, err := svc.Plan(context.Background(), source, false); err == nil {
+		t.Error("Expected the repository error")
+	}
+}
+
+func TestLoadContentDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("techniques/discovery.yaml", "- id: T1082\n  name: System Information Discovery\n  tactic: discovery\n")
+	write("techniques/README.md", "not content")
+	write("scenarios/default.yml", "- id: discovery\n  name: Discovery\n  phases:\n    - name: Recon\n      order: 1\n      techniques: [T1082]\n")
+
+	source, err := LoadContentDir(dir)
+	if err != nil {
+		t.Fatalf("LoadContentDir failed: %v", err)
+	}
+	if len(source.Techniques) != 1 || len(source.Scenarios) != 1 || source.Scenarios[0].Phases[0].Techniques[0] != "T1082" {
+		t.Errorf("Unexpected content: %+v", source)
+	}
+
+	write("techniques/more.yaml", "- id: T1082\n  name: Duplicate\n")
+	if _, err := LoadContentDir(dir); err == nil || !strings.Contains(err.Error(), "also defined") {
+		t.Errorf("Expected the duplicate reported, got %v", err)
+	}
+
+	if source, err := LoadContentDir(filepath.Join(dir, "missing")); err != nil || len(source.Techniques) != 0 {
+		t.Errorf("Expected no content for a missing directory, got %+v (%v)", source, err)
+	}
+}
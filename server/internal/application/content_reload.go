@@ -0,0 +1,165 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// ContentFileStatus is the outcome of reloading a content file
+type ContentFileStatus string
+
+const (
+	ContentFileApplied   ContentFileStatus = "applied"   // Changes applied
+	ContentFileUnchanged ContentFileStatus = "unchanged" // Already matching the database
+	ContentFileFailed    ContentFileStatus = "failed"    // Not parsed, or some changes failed
+	ContentFileRemoved   ContentFileStatus = "removed"   // Deleted, its items are kept
+)
+
+// ContentFileResult is the outcome of reloading a content file
+type ContentFileResult struct {
+	File    string            `json:"file"` // Relative to the content directory
+	Status  ContentFileStatus `json:"status"`
+	Applied int               `json:"applied"`
+	Changes []ContentChange   `json:"changes,omitempty"`
+	Errors  []string          `json:"errors,omitempty"`
+}
+
+// ContentReloadService reloads the techniques and scenarios of a content
+// directory at runtime, file by file. It remembers the content of each file
+// it loaded, so that only the files changed since are applied again. Nothing
+// is deleted: the items of a removed file are kept.
+type ContentReloadService struct {
+	plans  *ContentPlanService
+	dir    string
+	mu     sync.Mutex
+	loaded map[string]string // File to the checksum of its last loaded content
+	logger *zap.Logger
+}
+
+// NewContentReloadService creates a reload service for a content directory
+func NewContentReloadService(plans *ContentPlanService, dir string, logger *zap.Logger) *ContentReloadService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ContentReloadService{
+		plans:  plans,
+		dir:    dir,
+		loaded: make(map[string]string),
+		logger: logger,
+	}
+}
+
+// Dir returns the content directory
+func (s *ContentReloadService) Dir() string {
+	return s.dir
+}
+
+// MarkLoaded records the current content of every file as loaded, once the
+// whole directory was applied
+func (s *ContentReloadService) MarkLoaded() error {
+	files, err := contentFiles(s.dir)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(s.dir, file))
+		if err != nil {
+			return err
+		}
+		s.loaded[file] = contentFileChecksum(data)
+	}
+	return nil
+}
+
+// Reload applies the files changed since they were last loaded, or every
+// file with all, technique files first. A file that fails is tried again on
+// the next reload. The result lists the files reloaded and the removed ones.
+func (s *ContentReloadService) Reload(ctx context.Context, all bool) ([]ContentFileResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := contentFiles(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	results := []ContentFileResult{}
+	present := make(map[string]bool, len(files))
+	for _, file := range files {
+		present[file] = true
+		data, err := os.ReadFile(filepath.Join(s.dir, file))
+		if err != nil {
+			results = append(results, ContentFileResult{File: file, Status: ContentFileFailed, Errors: []string{err.Error()}})
+			continue
+		}
+		checksum := contentFileChecksum(data)
+		if !all && s.loaded[file] == checksum {
+			continue
+		}
+
+		result := s.reloadFile(ctx, file, data)
+		if result.Status != ContentFileFailed {
+			s.loaded[file] = checksum
+		}
+		results = append(results, result)
+	}
+
+	for file := range s.loaded {
+		if !present[file] {
+			delete(s.loaded, file)
+			results = append(results, ContentFileResult{File: file, Status: ContentFileRemoved})
+		}
+	}
+
+	for _, result := range results {
+		s.logger.Info("Reloaded content file",
+			zap.String("file", result.File),
+			zap.String("status", string(result.Status)),
+			zap.Int("applied", result.Applied),
+			zap.Strings("errors", result.Errors),
+		)
+	}
+	return results, nil
+}
+
+// reloadFile plans and applies the content of a file
+func (s *ContentReloadService) reloadFile(ctx context.Context, file string, data []byte) ContentFileResult {
+	result := ContentFileResult{File: file, Status: ContentFileUnchanged}
+	source, err := parseContentFile(file, data)
+	if err != nil {
+		result.Status = ContentFileFailed
+		result.Errors = []string{fmt.Sprintf("invalid YAML: %v", err)}
+		return result
+	}
+
+	applied, err := s.plans.Apply(ctx, source, false, "")
+	if err != nil {
+		result.Status = ContentFileFailed
+		result.Errors = []string{err.Error()}
+		return result
+	}
+	result.Changes = applied.Plan.Changes
+	result.Applied = applied.Applied
+	result.Errors = append(applied.Plan.Errors, applied.Errors...)
+	switch {
+	case len(result.Errors) > 0:
+		result.Status = ContentFileFailed
+	case result.Applied > 0:
+		result.Status = ContentFileApplied
+	}
+	return result
+}
+
+// contentFileChecksum identifies the content of a file
+func contentFileChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,87 @@
+package application
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeContentFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContentReloadService_Reload(t *testing.T) {
+	plans, techniques, scenarios, _ := newContentPlanFixture()
+	dir := t.TempDir()
+	svc := NewContentReloadService(plans, dir, nil)
+	ctx := context.Background()
+
+	writeContentFile(t, dir, "techniques/discovery.yaml", "- id: T1083\n  name: File and Directory Discovery\n  tactic: discovery\n")
+	writeContentFile(t, dir, "scenarios/default.yaml", "- id: custom\n  name: Created through the API\n")
+
+	results, err := svc.Reload(ctx, false)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if len(results) != 2 || results[0].File != filepath.Join("techniques", "discovery.yaml") || results[0].Status != ContentFileApplied ||
+		results[0].Applied != 1 || results[1].Status != ContentFileUnchanged {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if techniques.techniques["T1083"] == nil {
+		t.Error("Expected the technique created")
+	}
+
+	// Only the changed files are reloaded
+	if results, _ := svc.Reload(ctx, false); len(results) != 0 {
+		t.Errorf("Expected nothing reloaded, got %+v", results)
+	}
+	writeContentFile(t, dir, "scenarios/default.yaml", "- id: custom\n  name: Renamed\n")
+	results, _ = svc.Reload(ctx, false)
+	if len(results) != 1 || results[0].Status != ContentFileApplied || results[0].Changes[0].Fields[0] != "name" ||
+		scenarios.scenarios["custom"].Name != "Renamed" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	// A file that fails is tried again, a removed file keeps its items
+	writeContentFile(t, dir, "techniques/broken.yml", "- id: [")
+	for i := 0; i < 2; i++ {
+		results, _ = svc.Reload(ctx, false)
+		if len(results) != 1 || results[0].Status != ContentFileFailed || len(results[0].Errors) != 1 {
+			t.Errorf("Expected the broken file failed, got %+v", results)
+		}
+	}
+	if err := os.Remove(filepath.Join(dir, "scenarios", "default.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	results, _ = svc.Reload(ctx, false)
+	if len(results) != 2 || results[1].Status != ContentFileRemoved || scenarios.scenarios["custom"] == nil {
+		t.Errorf("Expected the file removed and its scenario kept, got %+v", results)
+	}
+
+	// All reloads every file
+	if results, _ := svc.Reload(ctx, true); len(results) != 2 || results[0].Status != ContentFileFailed || results[1].Status != ContentFileUnchanged {
+		t.Errorf("Expected every file reloaded, got %+v", results)
+	}
+}
+
+func TestContentReloadService_MarkLoaded(t *testing.T) {
+	plans, _, _, _ := newContentPlanFixture()
+	dir := t.TempDir()
+	svc := NewContentReloadService(plans, dir, nil)
+	writeContentFile(t, dir, "techniques/discovery.yaml", "- id: T1083\n  name: File and Directory Discovery\n  tactic: discovery\n")
+
+	if err := svc.MarkLoaded(); err != nil {
+		t.Fatalf("MarkLoaded failed: %v", err)
+	}
+	if results, err := svc.Reload(context.Background(), false); err != nil || len(results) != 0 {
+		t.Errorf("Expected the loaded files skipped, got %+v (%v)", results, err)
+	}
+}
@@ -0,0 +1,115 @@
+package application
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DeepLinkKind is the kind of page a notification link opens
+type DeepLinkKind string
+
+const (
+	DeepLinkExecution DeepLinkKind = "execution"
+	DeepLinkAgent     DeepLinkKind = "agent"
+)
+
+// deepLinkTokenType distinguishes link tokens from access and refresh tokens,
+// so a leaked email link can never be used as an API credential
+const deepLinkTokenType = "link"
+
+var (
+	ErrInvalidDeepLink   = errors.New("invalid link")
+	ErrDeepLinkExpired   = errors.New("link expired")
+	ErrDeepLinkForbidden = errors.New("link was issued to another user")
+)
+
+// DeepLinkTarget is the page a resolved link opens
+type DeepLinkTarget struct {
+	Kind           DeepLinkKind `json:"kind"`
+	ID             string       `json:"id"`
+	NotificationID string       `json:"notification_id,omitempty"`
+	Path           string       `json:"path"` // Dashboard route to navigate to
+}
+
+// DeepLinkService signs and resolves the short-lived links sent in
+// notifications. A link carries its recipient and target; the dashboard
+// resolves it after login, so it opens the right page instead of the root.
+type DeepLinkService struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewDeepLinkService creates a deep link service signing with secret, whose
+// links expire after ttl
+func NewDeepLinkService(secret string, ttl time.Duration) *DeepLinkService {
+	return &DeepLinkService{secret: []byte(secret), ttl: ttl}
+}
+
+// Token signs a link token opening the target for the given user. The
+// dashboard serves it at /links/<token>.
+func (s *DeepLinkService) Token(userID, notificationID string, kind DeepLinkKind, id string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":  userID,
+		"type": deepLinkTokenType,
+		"kind": string(kind),
+		"tid":  id,
+		"nid":  notificationID,
+		"iat":  now.Unix(),
+		"exp":  now.Add(s.ttl).Unix(),
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// Resolve checks a link token for the authenticated user and returns its target
+func (s *DeepLinkService) Resolve(tokenString, userID string) (*DeepLinkTarget, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return s.secret, nil
+	})
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrDeepLinkExpired
+		}
+		return nil, ErrInvalidDeepLink
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidDeepLink
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != deepLinkTokenType {
+		return nil, ErrInvalidDeepLink
+	}
+	if sub, _ := claims["sub"].(string); sub != userID {
+		return nil, ErrDeepLinkForbidden
+	}
+
+	kind, _ := claims["kind"].(string)
+	id, _ := claims["tid"].(string)
+	path, ok := deepLinkPath(DeepLinkKind(kind), id)
+	if !ok {
+		return nil, ErrInvalidDeepLink
+	}
+	notificationID, _ := claims["nid"].(string)
+
+	return &DeepLinkTarget{Kind: DeepLinkKind(kind), ID: id, NotificationID: notificationID, Path: path}, nil
+}
+
+// deepLinkPath returns the dashboard route of a link target
+func deepLinkPath(kind DeepLinkKind, id string) (string, bool) {
+	switch kind {
+	case DeepLinkExecution:
+		if id == "" {
+			return "", false
+		}
+		return "/executions/" + id, true
+	case DeepLinkAgent:
+		return "/agents", true
+	}
+	return "", false
+}
@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestDeepLinkService_RoundTrip(t *testing.T) {
+	links := NewDeepLinkService("link-secret", time.Hour)
+
+	token, err := links.Token("u1", "n1", DeepLinkExecution, "exec-1")
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+
+	target, err := links.Resolve(token, "u1")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if target.Kind != DeepLinkExecution || target.ID != "exec-1" || target.NotificationID != "n1" || target.Path != "/executions/exec-1" {
+		t.Errorf("Unexpected target: %+v", target)
+	}
+
+	if _, err := links.Resolve(token, "u2"); !errors.Is(err, ErrDeepLinkForbidden) {
+		t.Errorf("Expected ErrDeepLinkForbidden for another user, got %v", err)
+	}
+}
+
+func TestDeepLinkService_RejectsBadTokens(t *testing.T) {
+	links := NewDeepLinkService("link-secret", time.Hour)
+
+	expired, _ := NewDeepLinkService("link-secret", -time.Minute).Token("u1", "n1", DeepLinkAgent, "paw-1")
+	if _, err := links.Resolve(expired, "u1"); !errors.Is(err, ErrDeepLinkExpired) {
+		t.Errorf("Expected ErrDeepLinkExpired, got %v", err)
+	}
+
+	forged, _ := NewDeepLinkService("other-secret", time.Hour).Token("u1", "n1", DeepLinkAgent, "paw-1")
+	if _, err := links.Resolve(forged, "u1"); !errors.Is(err, ErrInvalidDeepLink) {
+		t.Errorf("Expected ErrInvalidDeepLink for a forged token, got %v", err)
+	}
+
+	// An access token signed with the same secret is not a link
+	access, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "u1", "type": "access", "exp": time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("link-secret"))
+	if _, err := links.Resolve(access, "u1"); !errors.Is(err, ErrInvalidDeepLink) {
+		t.Errorf("Expected ErrInvalidDeepLink for an access token, got %v", err)
+	}
+
+	unknownKind, _ := links.Token("u1", "n1", "report", "r1")
+	if _, err := links.Resolve(unknownKind, "u1"); !errors.Is(err, ErrInvalidDeepLink) {
+		t.Errorf("Expected ErrInvalidDeepLink for an unknown kind, got %v", err)
+	}
+}
+
+func TestNotificationService_DeliversSignedLinks(t *testing.T) {
+	received := make(chan WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true,
+		WebhookURL:  server.URL,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelWebhook}},
+	}
+	links := NewDeepLinkService("link-secret", time.Hour)
+	svc := NewNotificationService(notificationRepo, nil, nil, "https://autostrike.local/", nil)
+	svc.SetDeepLinks(links)
+
+	execution := &entity.Execution{ID: "exec-1"}
+	if err := svc.NotifyExecutionFailed(context.Background(), execution, "Discovery", "agent lost"); err != nil {
+		t.Fatalf("NotifyExecutionFailed failed: %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		link, _ := payload.Data["Link"].(string)
+		token, found := strings.CutPrefix(link, "https://autostrike.local/links/")
+		if !found {
+			t.Fatalf("Expected a deep link, got %q", link)
+		}
+		target, err := links.Resolve(token, "u1")
+		if err != nil || target.Path != "/executions/exec-1" {
+			t.Errorf("Expected the link to open the execution, got %+v, %v", target, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for webhook")
+	}
+
+	// Stored notifications are not tied to a recipient's link
+	for _, n := range notificationRepo.notifications {
+		if _, ok := n.Data["Link"]; ok {
+			t.Errorf("Expected stored notification data without link, got %v", n.Data)
+		}
+	}
+}
+
+func TestNotificationService_LinkWithoutDeepLinks(t *testing.T) {
+	svc := NewNotificationService(newMockNotificationRepo(), nil, nil, "https://autostrike.local", nil)
+
+	tests := []struct {
+		notification *entity.Notification
+		expected     string
+	}{
+		{&entity.Notification{Type: entity.NotificationScoreAlert, Data: map[string]any{"ExecutionID": "exec-1"}}, "https://autostrike.local/executions/exec-1"},
+		{&entity.Notification{Type: entity.NotificationAgentOffline, Data: map[string]any{"Paw": "paw-1"}}, "https://autostrike.local/agents"},
+		{&entity.Notification{Type: entity.NotificationSecurityAlert, Data: map[string]any{}}, "https://autostrike.local"},
+	}
+
+	for _, tt := range tests {
+		linked := svc.withLink("u1", tt.notification)
+		if linked.Data["Link"] != tt.expected {
+			t.Errorf("%s: expected %q, got %v", tt.notification.Type, tt.expected, linked.Data["Link"])
+		}
+	}
+}
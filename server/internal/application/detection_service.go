@@ -0,0 +1,519 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/domain/service"
+
+	"go.uber.org/zap"
+)
+
+// ErrExecutionNotFound is returned when an execution does not exist
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// DetectionQuery describes the alerts to look for after a technique ran
+type DetectionQuery struct {
+	TechniqueIDs []string  // Technique ID and its parent, e.g. ["T1059.001", "T1059"]
+	Hostname     string    // Host the technique ran on
+	Start        time.Time // Beginning of the correlation window
+	End          time.Time // End of the correlation window
+}
+
+// SIEMAlert is a normalized alert returned by a SIEM connector
+type SIEMAlert struct {
+	ID        string    `json:"id"`
+	RuleName  string    `json:"rule_name"`
+	Hostname  string    `json:"hostname"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SIEMConnector queries a SIEM for alerts matching a technique execution
+type SIEMConnector interface {
+	Name() string
+	SearchAlerts(ctx context.Context, query DetectionQuery) ([]SIEMAlert, error)
+}
+
+// PreventionQuery describes the EDR prevention events to look for on a host
+type PreventionQuery struct {
+	Hostname string
+	Start    time.Time
+	End      time.Time
+}
+
+// PreventionEvent is a normalized EDR prevention (block, kill, quarantine) event
+type PreventionEvent struct {
+	ID                string    `json:"id"`
+	Hostname          string    `json:"hostname"`
+	Timestamp         time.Time `json:"timestamp"`
+	Name              string    `json:"name"`   // Detection or rule name
+	Action            string    `json:"action"` // e.g. "process killed", "blocked"
+	ProcessName       string    `json:"process_name"`
+	CommandLine       string    `json:"command_line"`
+	ParentProcessName string    `json:"parent_process_name"`
+	TechniqueIDs      []string  `json:"technique_ids,omitempty"`
+}
+
+// EDRConnector queries an EDR for prevention events on a host
+type EDRConnector interface {
+	Name() string
+	SearchPreventions(ctx context.Context, query PreventionQuery) ([]PreventionEvent, error)
+}
+
+// DetectionConfig controls the SIEM and EDR correlation windows
+type DetectionConfig struct {
+	Delay            time.Duration // Wait before querying, to absorb SIEM/EDR ingestion lag
+	WindowPre        time.Duration // Look-back before the result started (clock skew)
+	WindowPost       time.Duration // SIEM look-ahead after the result completed
+	PreventionWindow time.Duration // EDR look-ahead after the result completed
+}
+
+// DefaultDetectionConfig returns the default correlation settings
+func DefaultDetectionConfig() DetectionConfig {
+	return DetectionConfig{
+		Delay:            2 * time.Minute,
+		WindowPre:        1 * time.Minute,
+		WindowPost:       10 * time.Minute,
+		PreventionWindow: 30 * time.Second,
+	}
+}
+
+// VerificationDelay is how long after a result completes it is verified:
+// once its SIEM and EDR windows have closed and their ingestion lag has passed,
+// so that late alerts are not missed
+func (c DetectionConfig) VerificationDelay() time.Duration {
+	return max(c.WindowPost, c.PreventionWindow) + c.Delay
+}
+
+// agentProcessName is the agent binary; processes it spawns are attributed to AutoStrike
+const agentProcessName = "autostrike-agent"
+
+// DetectionService classifies executed techniques from external telemetry:
+// EDR prevention events mark results blocked, SIEM alerts mark them detected.
+type DetectionService struct {
+	resultRepo    repository.ResultRepository
+	agentRepo     repository.AgentRepository
+	techniqueRepo repository.TechniqueRepository
+	calculator    *service.ScoreCalculator
+	connectors    []SIEMConnector
+	edrConnectors []EDRConnector
+	config        DetectionConfig
+	logger        *zap.Logger
+
+	scoringProfiles *ScoringProfileService
+
+	mu      sync.Mutex
+	timers  map[string]*time.Timer
+	stopped bool
+}
+
+// NewDetectionService creates a new detection verification service
+func NewDetectionService(
+	resultRepo repository.ResultRepository,
+	agentRepo repository.AgentRepository,
+	techniqueRepo repository.TechniqueRepository,
+	calculator *service.ScoreCalculator,
+	connectors []SIEMConnector,
+	edrConnectors []EDRConnector,
+	config DetectionConfig,
+	logger *zap.Logger,
+) *DetectionService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &DetectionService{
+		resultRepo:    resultRepo,
+		agentRepo:     agentRepo,
+		techniqueRepo: techniqueRepo,
+		calculator:    calculator,
+		connectors:    connectors,
+		edrConnectors: edrConnectors,
+		config:        config,
+		logger:        logger,
+		timers:        make(map[string]*time.Timer),
+	}
+}
+
+// SetScoringProfileService rescores executions with the scoring profile they are pinned to
+func (s *DetectionService) SetScoringProfileService(profiles *ScoringProfileService) {
+	s.scoringProfiles = profiles
+}
+
+// Enabled returns true if at least one SIEM or EDR connector is configured
+func (s *DetectionService) Enabled() bool {
+	return len(s.connectors) > 0 || len(s.edrConnectors) > 0
+}
+
+// Connectors returns the names of the configured SIEM connectors
+func (s *DetectionService) Connectors() []string {
+	names := make([]string, 0, len(s.connectors))
+	for _, c := range s.connectors {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// EDRConnectors returns the names of the configured EDR connectors
+func (s *DetectionService) EDRConnectors() []string {
+	names := make([]string, 0, len(s.edrConnectors))
+	for _, c := range s.edrConnectors {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// VerificationDelay is how long after a result completes it is verified
+func (s *DetectionService) VerificationDelay() time.Duration {
+	return s.config.VerificationDelay()
+}
+
+// ScheduleVerification verifies a result, which just completed, once its
+// correlation windows have closed and the configured delay has elapsed
+func (s *DetectionService) ScheduleVerification(resultID string) {
+	if !s.Enabled() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	if t, ok := s.timers[resultID]; ok {
+		t.Stop()
+	}
+
+	s.timers[resultID] = time.AfterFunc(s.config.VerificationDelay(), func() {
+		s.mu.Lock()
+		delete(s.timers, resultID)
+		s.mu.Unlock()
+
+		if _, err := s.VerifyResult(context.Background(), resultID); err != nil {
+			s.logger.Warn("Detection verification failed",
+				zap.String("result_id", resultID),
+				zap.Error(err),
+			)
+		}
+	})
+}
+
+// Stop cancels all pending verifications
+func (s *DetectionService) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for id, t := range s.timers {
+		t.Stop()
+		delete(s.timers, id)
+	}
+}
+
+// VerifyResult correlates a result with EDR prevention events and SIEM alerts.
+// Prevented techniques become blocked; otherwise executed (success) results are
+// marked detected or not detected from SIEM alerts.
+func (s *DetectionService) VerifyResult(ctx context.Context, resultID string) (*entity.ExecutionResult, error) {
+	result, err := s.resultRepo.FindResultByID(ctx, resultID)
+	if err != nil {
+		return nil, fmt.Errorf("result not found: %w", err)
+	}
+
+	changed, err := s.verify(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		if err := s.rescoreIfCompleted(ctx, result.ExecutionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// VerifyExecution verifies every executed result of an execution and rescores it
+func (s *DetectionService) VerifyExecution(ctx context.Context, executionID string) ([]*entity.ExecutionResult, error) {
+	if _, err := s.resultRepo.FindExecutionByID(ctx, executionID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
+
+	anyChanged := false
+	for _, result := range results {
+		changed, err := s.verify(ctx, result)
+		if err != nil {
+			return nil, err
+		}
+		anyChanged = anyChanged || changed
+	}
+
+	if anyChanged {
+		if err := s.rescoreIfCompleted(ctx, executionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// verify checks a single result and persists it when its detection changed,
+// which it reports
+func (s *DetectionService) verify(ctx context.Context, result *entity.ExecutionResult) (bool, error) {
+	if !s.Enabled() {
+		return false, nil
+	}
+
+	eligible := result.Status == entity.StatusSuccess ||
+		(result.Status == entity.StatusFailed && len(s.edrConnectors) > 0)
+	if !eligible {
+		return false, nil
+	}
+
+	agent, _ := s.agentRepo.FindByPaw(ctx, result.AgentPaw)
+	hostname := result.AgentPaw
+	if agent != nil && agent.Hostname != "" {
+		hostname = agent.Hostname
+	}
+
+	if len(s.edrConnectors) > 0 {
+		blocked, err := s.checkPrevention(ctx, result, agent, hostname)
+		if err != nil {
+			return false, err
+		}
+		if blocked {
+			return true, s.resultRepo.UpdateResult(ctx, result)
+		}
+	}
+
+	// A non-zero exit without a matching prevention event is a technical failure
+	if result.Status != entity.StatusSuccess || len(s.connectors) == 0 {
+		return false, nil
+	}
+
+	return s.checkAlerts(ctx, result, hostname)
+}
+
+// checkPrevention marks the result blocked if an EDR prevented it
+func (s *DetectionService) checkPrevention(
+	ctx context.Context,
+	result *entity.ExecutionResult,
+	agent *entity.Agent,
+	hostname string,
+) (bool, error) {
+	end := time.Now()
+	if result.CompletedAt != nil {
+		end = result.CompletedAt.Add(s.config.PreventionWindow)
+	}
+	query := PreventionQuery{
+		Hostname: hostname,
+		Start:    result.StartedAt.Add(-s.config.WindowPre),
+		End:      end,
+	}
+
+	techniqueIDs := techniqueIDsForQuery(result.TechniqueID)
+	hints := s.commandHints(ctx, result, agent)
+
+	queried := 0
+	for _, connector := range s.edrConnectors {
+		events, err := connector.SearchPreventions(ctx, query)
+		if err != nil {
+			s.logger.Warn("EDR query failed",
+				zap.String("connector", connector.Name()),
+				zap.String("result_id", result.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		queried++
+
+		for _, event := range events {
+			if !preventionMatches(event, techniqueIDs, hints) {
+				continue
+			}
+
+			result.Status = entity.StatusBlocked
+			result.Detected = true
+			result.DetectedBy = fmt.Sprintf("%s: %s", connector.Name(), firstNonEmptyString(event.Name, event.Action))
+
+			s.logger.Info("Technique blocked by EDR",
+				zap.String("result_id", result.ID),
+				zap.String("technique_id", result.TechniqueID),
+				zap.String("detected_by", result.DetectedBy),
+				zap.String("event_id", event.ID),
+			)
+			return true, nil
+		}
+	}
+
+	// EDR-only setups have no other source to classify the result
+	if queried == 0 && len(s.connectors) == 0 {
+		return false, fmt.Errorf("all EDR queries failed for result %s", result.ID)
+	}
+	return false, nil
+}
+
+// checkAlerts marks the result detected or not detected from SIEM alerts
+func (s *DetectionService) checkAlerts(ctx context.Context, result *entity.ExecutionResult, hostname string) (bool, error) {
+	end := time.Now()
+	if result.CompletedAt != nil {
+		end = result.CompletedAt.Add(s.config.WindowPost)
+	}
+	query := DetectionQuery{
+		TechniqueIDs: techniqueIDsForQuery(result.TechniqueID),
+		Hostname:     hostname,
+		Start:        result.StartedAt.Add(-s.config.WindowPre),
+		End:          end,
+	}
+
+	var matchedBy []string
+	queried := 0
+	for _, connector := range s.connectors {
+		alerts, err := connector.SearchAlerts(ctx, query)
+		if err != nil {
+			// One failing SIEM should not hide detections from the others
+			s.logger.Warn("SIEM query failed",
+				zap.String("connector", connector.Name()),
+				zap.String("result_id", result.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		queried++
+		if len(alerts) > 0 {
+			matchedBy = append(matchedBy, fmt.Sprintf("%s: %s", connector.Name(), alerts[0].RuleName))
+		}
+	}
+
+	// Without a single successful query we cannot claim the technique went unnoticed
+	if queried == 0 {
+		return false, fmt.Errorf("all SIEM queries failed for result %s", result.ID)
+	}
+
+	if len(matchedBy) == 0 {
+		if !result.Detected {
+			return false, nil
+		}
+		result.Detected = false
+		return true, s.resultRepo.UpdateResult(ctx, result)
+	}
+
+	result.Status = entity.StatusDetected
+	result.Detected = true
+	result.DetectedBy = strings.Join(matchedBy, "; ")
+
+	s.logger.Info("Technique detected by SIEM",
+		zap.String("result_id", result.ID),
+		zap.String("technique_id", result.TechniqueID),
+		zap.String("detected_by", result.DetectedBy),
+	)
+
+	return true, s.resultRepo.UpdateResult(ctx, result)
+}
+
+// commandHints returns command fragments the agent ran for the technique of a
+// result, used to attribute EDR process events to this result
+func (s *DetectionService) commandHints(ctx context.Context, result *entity.ExecutionResult, agent *entity.Agent) []string {
+	if s.techniqueRepo == nil || agent == nil {
+		return nil
+	}
+	technique, err := s.techniqueRepo.FindByID(ctx, result.TechniqueID)
+	if err != nil || technique == nil {
+		return nil
+	}
+	executor := resultExecutor(technique, result, agent)
+	if executor == nil {
+		return nil
+	}
+
+	var hints []string
+	for _, line := range strings.Split(executor.Command, "\n") {
+		line = strings.TrimSpace(line)
+		// Very short lines (e.g. "whoami") are too generic to attribute on their own
+		if len(line) < 8 {
+			continue
+		}
+		if len(line) > 80 {
+			line = line[:80]
+		}
+		hints = append(hints, strings.ToLower(line))
+	}
+	return hints
+}
+
+// preventionMatches correlates an EDR event with a result by technique tag,
+// command line, or the agent being the parent process
+func preventionMatches(event PreventionEvent, techniqueIDs, hints []string) bool {
+	for _, eventTechnique := range event.TechniqueIDs {
+		for _, id := range techniqueIDs {
+			if strings.EqualFold(eventTechnique, id) {
+				return true
+			}
+		}
+	}
+
+	cmdline := strings.ToLower(event.CommandLine)
+	for _, hint := range hints {
+		if cmdline != "" && strings.Contains(cmdline, hint) {
+			return true
+		}
+	}
+
+	return strings.Contains(strings.ToLower(event.ParentProcessName), agentProcessName)
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// rescoreIfCompleted recalculates the score of an already completed execution
+func (s *DetectionService) rescoreIfCompleted(ctx context.Context, executionID string) error {
+	if s.calculator == nil {
+		return nil
+	}
+
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("execution not found: %w", err)
+	}
+	// Running executions are scored on completion
+	if execution.Status != entity.ExecutionCompleted {
+		return nil
+	}
+
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to get results: %w", err)
+	}
+
+	score, err := scoreExecution(ctx, s.calculator, s.scoringProfiles, execution, results)
+	if err != nil {
+		return err
+	}
+	execution.Score = score
+	return s.resultRepo.UpdateExecution(ctx, execution)
+}
+
+// techniqueIDsForQuery returns the technique ID and, for sub-techniques, its parent
+func techniqueIDsForQuery(techniqueID string) []string {
+	ids := []string{techniqueID}
+	if i := strings.Index(techniqueID, "."); i > 0 {
+		ids = append(ids, techniqueID[:i])
+	}
+	return ids
+}
@@ -0,0 +1,444 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+type mockSIEMConnector struct {
+	name    string
+	alerts  []SIEMAlert
+	err     error
+	queries []DetectionQuery
+}
+
+func (m *mockSIEMConnector) Name() string { return m.name }
+
+func (m *mockSIEMConnector) SearchAlerts(ctx context.Context, query DetectionQuery) ([]SIEMAlert, error) {
+	m.queries = append(m.queries, query)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.alerts, nil
+}
+
+type mockEDRConnector struct {
+	name    string
+	events  []PreventionEvent
+	err     error
+	queries []PreventionQuery
+}
+
+func (m *mockEDRConnector) Name() string { return m.name }
+
+func (m *mockEDRConnector) SearchPreventions(ctx context.Context, query PreventionQuery) ([]PreventionEvent, error) {
+	m.queries = append(m.queries, query)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.events, nil
+}
+
+func setupDetectionTest(connectors ...SIEMConnector) (*DetectionService, *mockResultRepo, *mockAgentRepo) {
+	resultRepo := newMockResultRepo()
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw-1"] = &entity.Agent{Paw: "paw-1", Hostname: "WS-01"}
+
+	completed := time.Now()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionCompleted}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1059.001", AgentPaw: "paw-1",
+			Status: entity.StatusSuccess, StartedAt: completed.Add(-time.Minute), CompletedAt: &completed},
+		{ID: "r2", ExecutionID: "exec-1", TechniqueID: "T1082", AgentPaw: "paw-1", Status: entity.StatusBlocked},
+	}
+
+	svc := NewDetectionService(resultRepo, agentRepo, nil, service.NewScoreCalculator(), connectors, nil, DefaultDetectionConfig(), nil)
+	return svc, resultRepo, agentRepo
+}
+
+func TestDetectionService_Enabled(t *testing.T) {
+	svc, _, _ := setupDetectionTest()
+	if svc.Enabled() {
+		t.Error("Expected service without connectors to be disabled")
+	}
+
+	svc, _, _ = setupDetectionTest(&mockSIEMConnector{name: "splunk"})
+	if !svc.Enabled() {
+		t.Error("Expected service with connectors to be enabled")
+	}
+	if names := svc.Connectors(); len(names) != 1 || names[0] != "splunk" {
+		t.Errorf("Unexpected connector names: %v", names)
+	}
+}
+
+func TestDetectionService_VerifyResultDetected(t *testing.T) {
+	connector := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1", RuleName: "Suspicious PowerShell"}}}
+	svc, resultRepo, _ := setupDetectionTest(connector)
+
+	result, err := svc.VerifyResult(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+
+	if result.Status != entity.StatusDetected || !result.Detected {
+		t.Errorf("Expected result to be detected, got status=%s detected=%v", result.Status, result.Detected)
+	}
+	if result.DetectedBy != "splunk: Suspicious PowerShell" {
+		t.Errorf("Unexpected DetectedBy: %q", result.DetectedBy)
+	}
+
+	// Query correlates on hostname and includes the parent technique
+	q := connector.queries[0]
+	if q.Hostname != "WS-01" {
+		t.Errorf("Expected hostname WS-01, got %s", q.Hostname)
+	}
+	if len(q.TechniqueIDs) != 2 || q.TechniqueIDs[1] != "T1059" {
+		t.Errorf("Expected parent technique in query, got %v", q.TechniqueIDs)
+	}
+	if !q.Start.Before(result.StartedAt) || !q.End.After(*result.CompletedAt) {
+		t.Errorf("Query window does not cover the result: %v - %v", q.Start, q.End)
+	}
+
+	// Completed execution is rescored: 1 blocked + 1 detected = 75
+	score := resultRepo.executions["exec-1"].Score
+	if score == nil || score.Detected != 1 || score.Overall != 75 {
+		t.Errorf("Expected rescored execution, got %+v", score)
+	}
+}
+
+func TestDetectionService_VerifyResultNotDetected(t *testing.T) {
+	svc, resultRepo, _ := setupDetectionTest(&mockSIEMConnector{name: "elastic"})
+
+	result, err := svc.VerifyResult(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+
+	if result.Status != entity.StatusSuccess || result.Detected {
+		t.Errorf("Expected result to stay undetected, got status=%s detected=%v", result.Status, result.Detected)
+	}
+	if resultRepo.executions["exec-1"].Score != nil {
+		t.Error("Expected no rescore when nothing changed")
+	}
+}
+
+func TestDetectionService_SkipsNonExecutedResults(t *testing.T) {
+	connector := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1"}}}
+	svc, _, _ := setupDetectionTest(connector)
+
+	result, err := svc.VerifyResult(context.Background(), "r2")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if result.Status != entity.StatusBlocked {
+		t.Errorf("Expected blocked result to be untouched, got %s", result.Status)
+	}
+	if len(connector.queries) != 0 {
+		t.Error("Expected no SIEM query for blocked result")
+	}
+}
+
+func TestDetectionService_ConnectorErrorDoesNotHideOthers(t *testing.T) {
+	failing := &mockSIEMConnector{name: "sentinel", err: errors.New("unauthorized")}
+	working := &mockSIEMConnector{name: "elastic", alerts: []SIEMAlert{{ID: "a1", RuleName: "Rule"}}}
+	svc, _, _ := setupDetectionTest(failing, working)
+
+	result, err := svc.VerifyResult(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if result.Status != entity.StatusDetected || !strings.HasPrefix(result.DetectedBy, "elastic") {
+		t.Errorf("Expected detection from elastic, got status=%s by=%q", result.Status, result.DetectedBy)
+	}
+}
+
+func TestDetectionService_VerifyResultNotFound(t *testing.T) {
+	svc, _, _ := setupDetectionTest(&mockSIEMConnector{name: "splunk"})
+
+	if _, err := svc.VerifyResult(context.Background(), "missing"); err == nil {
+		t.Error("Expected error for missing result")
+	}
+}
+
+func TestDetectionService_VerifyExecution(t *testing.T) {
+	connector := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1", RuleName: "Rule"}}}
+	svc, resultRepo, _ := setupDetectionTest(connector)
+
+	results, err := svc.VerifyExecution(context.Background(), "exec-1")
+	if err != nil {
+		t.Fatalf("VerifyExecution failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if len(connector.queries) != 1 {
+		t.Errorf("Expected only the executed result to be queried, got %d queries", len(connector.queries))
+	}
+	if resultRepo.executions["exec-1"].Score == nil {
+		t.Error("Expected execution to be rescored")
+	}
+
+	if _, err := svc.VerifyExecution(context.Background(), "missing"); err == nil {
+		t.Error("Expected error for missing execution")
+	}
+}
+
+func TestDetectionService_RunningExecutionNotRescored(t *testing.T) {
+	connector := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1"}}}
+	svc, resultRepo, _ := setupDetectionTest(connector)
+	resultRepo.executions["exec-1"].Status = entity.ExecutionRunning
+
+	if _, err := svc.VerifyResult(context.Background(), "r1"); err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if resultRepo.executions["exec-1"].Score != nil {
+		t.Error("Expected running execution to be scored on completion, not now")
+	}
+}
+
+// notifyingResultRepo reports result updates made from timer goroutines
+type notifyingResultRepo struct {
+	*mockResultRepo
+	updated chan entity.ResultStatus
+}
+
+func (m *notifyingResultRepo) UpdateResult(ctx context.Context, r *entity.ExecutionResult) error {
+	m.updated <- r.Status
+	return nil
+}
+
+func TestDetectionService_ScheduleVerification(t *testing.T) {
+	connector := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1"}}}
+	resultRepo := &notifyingResultRepo{mockResultRepo: newMockResultRepo(), updated: make(chan entity.ResultStatus, 1)}
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionRunning}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1082", AgentPaw: "paw-1", Status: entity.StatusSuccess},
+	}
+
+	config := DetectionConfig{Delay: 10 * time.Millisecond}
+	svc := NewDetectionService(resultRepo, newMockAgentRepo(), nil, service.NewScoreCalculator(), []SIEMConnector{connector}, nil, config, nil)
+	defer svc.Stop()
+
+	svc.ScheduleVerification("r1")
+
+	select {
+	case status := <-resultRepo.updated:
+		if status != entity.StatusDetected {
+			t.Errorf("Expected scheduled verification to mark result detected, got %s", status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for scheduled verification")
+	}
+}
+
+func TestDetectionService_NotDetectedLeavesResultUnwritten(t *testing.T) {
+	resultRepo := &notifyingResultRepo{mockResultRepo: newMockResultRepo(), updated: make(chan entity.ResultStatus, 1)}
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionRunning}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1082", AgentPaw: "paw-1", Status: entity.StatusSuccess},
+		{ID: "r2", ExecutionID: "exec-1", TechniqueID: "T1082", AgentPaw: "paw-1", Status: entity.StatusSuccess, Detected: true},
+	}
+	svc := NewDetectionService(resultRepo, newMockAgentRepo(), nil, service.NewScoreCalculator(),
+		[]SIEMConnector{&mockSIEMConnector{name: "elastic"}}, nil, DefaultDetectionConfig(), nil)
+	ctx := context.Background()
+
+	if _, err := svc.VerifyResult(ctx, "r1"); err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	select {
+	case <-resultRepo.updated:
+		t.Error("Expected an undetected result left unwritten")
+	default:
+	}
+
+	// A result no longer detected is written
+	if _, err := svc.VerifyResult(ctx, "r2"); err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	select {
+	case <-resultRepo.updated:
+	default:
+		t.Error("Expected the result no longer detected written")
+	}
+}
+
+func TestDetectionConfig_VerificationDelay(t *testing.T) {
+	// Verified once the SIEM window closed and its ingestion lag passed
+	if got := DefaultDetectionConfig().VerificationDelay(); got != 12*time.Minute {
+		t.Errorf("VerificationDelay() = %v, want 12m", got)
+	}
+	config := DetectionConfig{Delay: time.Minute, WindowPost: 10 * time.Second, PreventionWindow: 30 * time.Second}
+	if got := config.VerificationDelay(); got != 90*time.Second {
+		t.Errorf("VerificationDelay() = %v, want 1m30s", got)
+	}
+}
+
+func TestDetectionService_StopCancelsPending(t *testing.T) {
+	connector := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1"}}}
+	svc, _, _ := setupDetectionTest(connector)
+	svc.config.Delay = 50 * time.Millisecond
+
+	svc.ScheduleVerification("r1")
+	svc.Stop()
+	svc.ScheduleVerification("r1") // ignored after stop
+	time.Sleep(100 * time.Millisecond)
+
+	if len(connector.queries) != 0 {
+		t.Errorf("Expected no queries after Stop, got %d", len(connector.queries))
+	}
+}
+
+func TestTechniqueIDsForQuery(t *testing.T) {
+	if ids := techniqueIDsForQuery("T1082"); len(ids) != 1 {
+		t.Errorf("Expected single ID for parent technique, got %v", ids)
+	}
+	if ids := techniqueIDsForQuery("T1059.001"); len(ids) != 2 || ids[1] != "T1059" {
+		t.Errorf("Expected parent appended, got %v", ids)
+	}
+}
+
+func TestDetectionService_AllConnectorsFail(t *testing.T) {
+	svc, _, _ := setupDetectionTest(&mockSIEMConnector{name: "splunk", err: errors.New("timeout")})
+
+	if _, err := svc.VerifyResult(context.Background(), "r1"); err == nil {
+		t.Error("Expected error when no SIEM could be queried")
+	}
+}
+
+func setupPreventionTest(siem []SIEMConnector, edrs ...EDRConnector) (*DetectionService, *mockResultRepo) {
+	resultRepo := newMockResultRepo()
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw-1"] = &entity.Agent{Paw: "paw-1", Hostname: "WS-01", Platform: "windows", Executors: []string{"psh"}}
+
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1003.001"] = &entity.Technique{
+		ID:        "T1003.001",
+		Platforms: []string{"windows"},
+		Executors: []entity.Executor{{Type: "psh", Command: "rundll32.exe C:\\windows\\System32\\comsvcs.dll, MiniDump"}},
+	}
+
+	completed := time.Now()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionCompleted}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1003.001", AgentPaw: "paw-1",
+			Status: entity.StatusSuccess, StartedAt: completed.Add(-time.Minute), CompletedAt: &completed},
+		{ID: "r2", ExecutionID: "exec-1", TechniqueID: "T1003.001", AgentPaw: "paw-1",
+			Status: entity.StatusFailed, StartedAt: completed.Add(-time.Minute), CompletedAt: &completed},
+	}
+
+	svc := NewDetectionService(resultRepo, agentRepo, techniqueRepo, service.NewScoreCalculator(), siem, edrs, DefaultDetectionConfig(), nil)
+	return svc, resultRepo
+}
+
+func TestDetectionService_BlockedByTechniqueTag(t *testing.T) {
+	edr := &mockEDRConnector{name: "crowdstrike", events: []PreventionEvent{
+		{ID: "e1", Name: "CredentialDumping", TechniqueIDs: []string{"T1003"}},
+	}}
+	siem := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1"}}}
+	svc, _ := setupPreventionTest([]SIEMConnector{siem}, edr)
+
+	result, err := svc.VerifyResult(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if result.Status != entity.StatusBlocked || !result.Detected {
+		t.Errorf("Expected blocked result, got status=%s detected=%v", result.Status, result.Detected)
+	}
+	if result.DetectedBy != "crowdstrike: CredentialDumping" {
+		t.Errorf("Unexpected DetectedBy: %q", result.DetectedBy)
+	}
+	if len(siem.queries) != 0 {
+		t.Error("Expected SIEM not to be queried once EDR blocked the technique")
+	}
+	if q := edr.queries[0]; q.Hostname != "WS-01" || !q.End.After(*result.CompletedAt) {
+		t.Errorf("Unexpected prevention query: %+v", q)
+	}
+}
+
+func TestDetectionService_BlockedByCommandLine(t *testing.T) {
+	edr := &mockEDRConnector{name: "defender", events: []PreventionEvent{
+		{ID: "e1", Action: "AsrLsassCredentialTheftBlocked", CommandLine: "RUNDLL32.EXE C:\\Windows\\System32\\comsvcs.dll, MiniDump 624 out.dmp full"},
+	}}
+	svc, _ := setupPreventionTest(nil, edr)
+
+	result, err := svc.VerifyResult(context.Background(), "r2")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if result.Status != entity.StatusBlocked {
+		t.Errorf("Expected failed result to be reclassified as blocked, got %s", result.Status)
+	}
+	if result.DetectedBy != "defender: AsrLsassCredentialTheftBlocked" {
+		t.Errorf("Unexpected DetectedBy: %q", result.DetectedBy)
+	}
+}
+
+func TestDetectionService_BlockedByAgentParent(t *testing.T) {
+	edr := &mockEDRConnector{name: "sentinelone", events: []PreventionEvent{
+		{ID: "e1", Name: "Malicious PowerShell", ParentProcessName: "C:\\AutoStrike\\autostrike-agent.exe"},
+	}}
+	svc, _ := setupPreventionTest(nil, edr)
+
+	result, err := svc.VerifyResult(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if result.Status != entity.StatusBlocked {
+		t.Errorf("Expected blocked result, got %s", result.Status)
+	}
+}
+
+func TestDetectionService_UnrelatedPreventionIgnored(t *testing.T) {
+	edr := &mockEDRConnector{name: "crowdstrike", events: []PreventionEvent{
+		{ID: "e1", Name: "Other", CommandLine: "notepad.exe", ParentProcessName: "explorer.exe", TechniqueIDs: []string{"T1486"}},
+	}}
+	siem := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1", RuleName: "LSASS Access"}}}
+	svc, _ := setupPreventionTest([]SIEMConnector{siem}, edr)
+
+	failed, err := svc.VerifyResult(context.Background(), "r2")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if failed.Status != entity.StatusFailed {
+		t.Errorf("Expected failed result without prevention to stay failed, got %s", failed.Status)
+	}
+
+	// Successful result falls through to SIEM correlation
+	success, err := svc.VerifyResult(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if success.Status != entity.StatusDetected {
+		t.Errorf("Expected SIEM detection, got %s", success.Status)
+	}
+}
+
+func TestDetectionService_FailedIgnoredWithoutEDR(t *testing.T) {
+	siem := &mockSIEMConnector{name: "splunk", alerts: []SIEMAlert{{ID: "a1"}}}
+	svc, _ := setupPreventionTest([]SIEMConnector{siem})
+
+	result, err := svc.VerifyResult(context.Background(), "r2")
+	if err != nil {
+		t.Fatalf("VerifyResult failed: %v", err)
+	}
+	if result.Status != entity.StatusFailed || len(siem.queries) != 0 {
+		t.Errorf("Expected failed result to be left alone without EDR, got %s", result.Status)
+	}
+}
+
+func TestDetectionService_AllEDRQueriesFail(t *testing.T) {
+	svc, _ := setupPreventionTest(nil, &mockEDRConnector{name: "crowdstrike", err: errors.New("forbidden")})
+
+	if _, err := svc.VerifyResult(context.Background(), "r1"); err == nil {
+		t.Error("Expected error when no EDR could be queried")
+	}
+	if !svc.Enabled() || len(svc.EDRConnectors()) != 1 {
+		t.Error("Expected EDR-only service to be enabled")
+	}
+}
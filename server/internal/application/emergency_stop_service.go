@@ -0,0 +1,294 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Database watchdog defaults
+const (
+	DefaultEmergencyStopCheckInterval = 10 * time.Second
+	DefaultEmergencyStopFailures      = 3 // Consecutive failed checks tripping the stop
+)
+
+// emergencyStopTimeout bounds enforcing a trip, which may run against a failing database
+const emergencyStopTimeout = 30 * time.Second
+
+// Emergency stop errors
+var (
+	ErrEmergencyStopEngaged    = errors.New("emergency stop engaged: re-arm it to resume activity")
+	ErrEmergencyStopNotEngaged = errors.New("emergency stop is not engaged")
+)
+
+// AgentNotifier queues a message for every connected agent and returns the
+// number of agents reached
+type AgentNotifier interface {
+	NotifyAgents(message []byte) int
+}
+
+// EmergencyStopService is the kill switch for all activity. Tripping it tells
+// every agent to kill its running commands, cancels the active executions and
+// pauses the active schedules; until an administrator re-arms it, no
+// execution or ad-hoc task starts. Schedules stay paused after re-arming.
+// The stop is kept in memory first, so it holds while the database is
+// unreachable, and enforced on the database once it answers again.
+type EmergencyStopService struct {
+	repo       repository.EmergencyStopRepository
+	executions *ExecutionService
+	schedules  *ScheduleService
+	agents     AgentNotifier
+	logger     *zap.Logger
+
+	engaged atomic.Bool
+	mu      sync.Mutex
+	current *entity.EmergencyStop
+	settled bool // The current trip was enforced and saved
+
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewEmergencyStopService creates the emergency stop, re-armed
+func NewEmergencyStopService(
+	repo repository.EmergencyStopRepository,
+	executions *ExecutionService,
+	schedules *ScheduleService,
+	agents AgentNotifier,
+	logger *zap.Logger,
+) *EmergencyStopService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &EmergencyStopService{
+		repo:       repo,
+		executions: executions,
+		schedules:  schedules,
+		agents:     agents,
+		logger:     logger,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Load restores a trip that was not re-armed before the server stopped
+func (s *EmergencyStopService) Load(ctx context.Context) error {
+	stop, err := s.repo.FindEngaged(ctx)
+	if err != nil || stop == nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current, s.settled = stop, true
+	s.engaged.Store(true)
+	return nil
+}
+
+// Check returns ErrEmergencyStopEngaged while the stop is engaged. A nil
+// service never stops anything.
+func (s *EmergencyStopService) Check() error {
+	if s != nil && s.engaged.Load() {
+		return ErrEmergencyStopEngaged
+	}
+	return nil
+}
+
+// Status returns the engaged trip, or nil when the stop is armed
+func (s *EmergencyStopService) Status() *entity.EmergencyStop {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == nil {
+		return nil
+	}
+	stop := *s.current
+	return &stop
+}
+
+// History returns the latest trips, newest first
+func (s *EmergencyStopService) History(ctx context.Context, limit int) ([]*entity.EmergencyStop, error) {
+	return s.repo.FindRecent(ctx, limit)
+}
+
+// Trip engages the emergency stop for a user. Tripping an engaged stop
+// enforces it again, catching what started in between.
+func (s *EmergencyStopService) Trip(ctx context.Context, reason, userID string) (*entity.EmergencyStop, error) {
+	if reason == "" {
+		reason = "manual emergency stop"
+	}
+	return s.trip(ctx, reason, userID, false)
+}
+
+func (s *EmergencyStopService) trip(ctx context.Context, reason, userID string, automatic bool) (*entity.EmergencyStop, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Refuse new activity before anything else
+	s.engaged.Store(true)
+	if s.current == nil {
+		s.current = &entity.EmergencyStop{
+			ID:        uuid.New().String(),
+			Reason:    reason,
+			Automatic: automatic,
+			TrippedBy: userID,
+			TrippedAt: time.Now(),
+		}
+		s.logger.Warn("Emergency stop tripped",
+			zap.String("reason", reason), zap.String("user_id", userID), zap.Bool("automatic", automatic))
+	}
+
+	// Agents are told first: it needs no database
+	message, _ := json.Marshal(map[string]any{
+		"type":    "cancel",
+		"payload": map[string]any{"all": true, "reason": s.current.Reason},
+	})
+	if s.agents != nil {
+		s.current.AgentsNotified = s.agents.NotifyAgents(message)
+	}
+
+	err := s.enforce(ctx)
+	stop := *s.current
+	return &stop, err
+}
+
+// enforce cancels the active executions, pauses the active schedules and
+// saves the trip; the caller holds s.mu
+func (s *EmergencyStopService) enforce(ctx context.Context) error {
+	var errs []error
+	if s.executions != nil {
+		cancelled, err := s.executions.CancelActiveExecutions(ctx)
+		s.current.CancelledExecutions = appendMissing(s.current.CancelledExecutions, cancelled)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel executions: %w", err))
+		}
+	}
+	if s.schedules != nil {
+		paused, err := s.schedules.PauseAll(ctx)
+		s.current.PausedSchedules = appendMissing(s.current.PausedSchedules, paused)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to pause schedules: %w", err))
+		}
+	}
+	if err := s.repo.Save(ctx, s.current); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save emergency stop: %w", err))
+	}
+
+	s.settled = len(errs) == 0
+	if err := errors.Join(errs...); err != nil {
+		s.logger.Error("Emergency stop not fully enforced, retrying when the database answers", zap.Error(err))
+		return err
+	}
+	s.logger.Warn("Emergency stop enforced",
+		zap.Int("cancelled_executions", len(s.current.CancelledExecutions)),
+		zap.Int("paused_schedules", len(s.current.PausedSchedules)),
+		zap.Int("agents_notified", s.current.AgentsNotified))
+	return nil
+}
+
+// Rearm releases the emergency stop: executions and ad-hoc tasks can start
+// again. Paused schedules are left for the operators to resume.
+func (s *EmergencyStopService) Rearm(ctx context.Context, userID string) (*entity.EmergencyStop, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.current == nil {
+		return nil, ErrEmergencyStopNotEngaged
+	}
+	now := time.Now()
+	rearmed := *s.current
+	rearmed.RearmedBy = userID
+	rearmed.RearmedAt = &now
+	if err := s.repo.Save(ctx, &rearmed); err != nil {
+		return nil, fmt.Errorf("failed to save emergency stop: %w", err)
+	}
+
+	s.current, s.settled = nil, false
+	s.engaged.Store(false)
+	s.logger.Warn("Emergency stop re-armed", zap.String("user_id", userID), zap.String("stop_id", rearmed.ID))
+	return &rearmed, nil
+}
+
+// WatchDatabase checks the database every interval and trips the stop after
+// failures consecutive failed checks, since results and cancellations can no
+// longer be recorded. Once the database answers again, an engaged trip that
+// could not be enforced is enforced. A zero interval disables the watch.
+func (s *EmergencyStopService) WatchDatabase(ping HealthCheckFunc, interval time.Duration, failures int) {
+	if interval <= 0 {
+		return
+	}
+	if failures <= 0 {
+		failures = DefaultEmergencyStopFailures
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		failed := 0
+		for {
+			select {
+			case <-ticker.C:
+				failed = s.checkDatabase(ping, interval, failed, failures)
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// checkDatabase runs one watchdog check and returns the consecutive failures
+func (s *EmergencyStopService) checkDatabase(ping HealthCheckFunc, timeout time.Duration, failed, failures int) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	err := ping(ctx)
+	cancel()
+
+	if err != nil {
+		failed++
+		s.logger.Warn("Database check failed", zap.Int("consecutive_failures", failed), zap.Error(err))
+		if failed == failures && s.Check() == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), emergencyStopTimeout)
+			defer cancel()
+			_, _ = s.trip(ctx, "database unreachable: "+err.Error(), "", true)
+		}
+		return failed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil && !s.settled {
+		ctx, cancel := context.WithTimeout(context.Background(), emergencyStopTimeout)
+		defer cancel()
+		_ = s.enforce(ctx)
+	}
+	return 0
+}
+
+// Stop stops the database watch. Safe to call multiple times.
+func (s *EmergencyStopService) Stop() {
+	s.closeOnce.Do(func() {
+		close(s.stopCh)
+	})
+	s.wg.Wait()
+}
+
+// appendMissing appends the values not in list yet
+func appendMissing(list, values []string) []string {
+	for _, value := range values {
+		if !slices.Contains(list, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
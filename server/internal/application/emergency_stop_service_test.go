@@ -0,0 +1,281 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockEmergencyStopRepo implements repository.EmergencyStopRepository for tests
+type mockEmergencyStopRepo struct {
+	stops map[string]*entity.EmergencyStop
+	err   error
+}
+
+func newMockEmergencyStopRepo() *mockEmergencyStopRepo {
+	return &mockEmergencyStopRepo{stops: make(map[string]*entity.EmergencyStop)}
+}
+
+func (m *mockEmergencyStopRepo) Save(ctx context.Context, stop *entity.EmergencyStop) error {
+	if m.err != nil {
+		return m.err
+	}
+	saved := *stop
+	m.stops[stop.ID] = &saved
+	return nil
+}
+
+func (m *mockEmergencyStopRepo) FindEngaged(ctx context.Context) (*entity.EmergencyStop, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, stop := range m.stops {
+		if stop.Engaged() {
+			return stop, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockEmergencyStopRepo) FindRecent(ctx context.Context, limit int) ([]*entity.EmergencyStop, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var stops []*entity.EmergencyStop
+	for _, stop := range m.stops {
+		stops = append(stops, stop)
+	}
+	return stops, nil
+}
+
+// mockAgentNotifier records the messages sent to the agents
+type mockAgentNotifier struct {
+	messages [][]byte
+	agents   int
+}
+
+func (m *mockAgentNotifier) NotifyAgents(message []byte) int {
+	m.messages = append(m.messages, message)
+	return m.agents
+}
+
+func newTestEmergencyStop(t *testing.T) (*EmergencyStopService, *ExecutionService, *mockResultRepo, *mockScheduleRepo, *mockEmergencyStopRepo, *mockAgentNotifier) {
+	t.Helper()
+	resultRepo := newMockResultRepo()
+	executions := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	scheduleRepo := newMockScheduleRepo()
+	schedules := NewScheduleService(scheduleRepo, executions, nil)
+	repo := newMockEmergencyStopRepo()
+	agents := &mockAgentNotifier{agents: 2}
+	stop := NewEmergencyStopService(repo, executions, schedules, agents, nil)
+	executions.SetEmergencyStop(stop)
+	return stop, executions, resultRepo, scheduleRepo, repo, agents
+}
+
+func TestEmergencyStopService_Trip(t *testing.T) {
+	stop, executions, resultRepo, scheduleRepo, repo, agents := newTestEmergencyStop(t)
+	ctx := context.Background()
+
+	started, err := executions.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("StartExecution failed: %v", err)
+	}
+	resultRepo.executions["done"] = &entity.Execution{ID: "done", Status: entity.ExecutionCompleted}
+	scheduleRepo.schedules["active"] = &entity.Schedule{ID: "active", Status: entity.ScheduleStatusActive}
+	scheduleRepo.schedules["disabled"] = &entity.Schedule{ID: "disabled", Status: entity.ScheduleStatusDisabled}
+
+	trip, err := stop.Trip(ctx, "", "admin-1")
+	if err != nil {
+		t.Fatalf("Trip failed: %v", err)
+	}
+	if trip.Reason != "manual emergency stop" || trip.TrippedBy != "admin-1" || trip.Automatic || !trip.Engaged() {
+		t.Errorf("Unexpected trip: %+v", trip)
+	}
+	if len(trip.CancelledExecutions) != 1 || trip.CancelledExecutions[0] != started.Execution.ID {
+		t.Errorf("Expected the running execution to be cancelled, got %v", trip.CancelledExecutions)
+	}
+	if resultRepo.executions[started.Execution.ID].Status != entity.ExecutionCancelled {
+		t.Errorf("Expected the execution to be cancelled, got %s", resultRepo.executions[started.Execution.ID].Status)
+	}
+	if len(trip.PausedSchedules) != 1 || trip.PausedSchedules[0] != "active" {
+		t.Errorf("Expected the active schedule to be paused, got %v", trip.PausedSchedules)
+	}
+	if scheduleRepo.schedules["active"].Status != entity.ScheduleStatusPaused ||
+		scheduleRepo.schedules["disabled"].Status != entity.ScheduleStatusDisabled {
+		t.Error("Expected only the active schedule to be paused")
+	}
+
+	if trip.AgentsNotified != 2 || len(agents.messages) != 1 {
+		t.Fatalf("Expected the agents to be notified once, got %d messages", len(agents.messages))
+	}
+	var message struct {
+		Type    string         `json:"type"`
+		Payload map[string]any `json:"payload"`
+	}
+	if err := json.Unmarshal(agents.messages[0], &message); err != nil {
+		t.Fatalf("Invalid agent message: %v", err)
+	}
+	if message.Type != "cancel" || message.Payload["all"] != true {
+		t.Errorf("Unexpected agent message: %s", agents.messages[0])
+	}
+	if _, ok := repo.stops[trip.ID]; !ok {
+		t.Error("Expected the trip to be saved")
+	}
+
+	// Nothing starts while engaged
+	if _, err := executions.StartExecution(ctx, "s1", []string{"paw1"}, false); !errors.Is(err, ErrEmergencyStopEngaged) {
+		t.Errorf("Expected ErrEmergencyStopEngaged, got %v", err)
+	}
+	if status := stop.Status(); status == nil || status.ID != trip.ID {
+		t.Errorf("Expected the engaged trip, got %+v", status)
+	}
+
+	// Tripping again keeps the trip and enforces it again
+	again, err := stop.Trip(ctx, "second", "admin-2")
+	if err != nil {
+		t.Fatalf("Trip failed: %v", err)
+	}
+	if again.ID != trip.ID || again.Reason != "manual emergency stop" || len(agents.messages) != 2 {
+		t.Errorf("Expected the same trip enforced again, got %+v", again)
+	}
+}
+
+func TestEmergencyStopService_Rearm(t *testing.T) {
+	stop, executions, _, _, repo, _ := newTestEmergencyStop(t)
+	ctx := context.Background()
+
+	if _, err := stop.Rearm(ctx, "admin-1"); !errors.Is(err, ErrEmergencyStopNotEngaged) {
+		t.Errorf("Expected ErrEmergencyStopNotEngaged, got %v", err)
+	}
+
+	trip, err := stop.Trip(ctx, "drill", "admin-1")
+	if err != nil {
+		t.Fatalf("Trip failed: %v", err)
+	}
+	rearmed, err := stop.Rearm(ctx, "admin-2")
+	if err != nil {
+		t.Fatalf("Rearm failed: %v", err)
+	}
+	if rearmed.ID != trip.ID || rearmed.RearmedBy != "admin-2" || rearmed.Engaged() {
+		t.Errorf("Unexpected re-armed trip: %+v", rearmed)
+	}
+	if repo.stops[trip.ID].Engaged() {
+		t.Error("Expected the re-armed trip to be saved")
+	}
+	if stop.Status() != nil || stop.Check() != nil {
+		t.Error("Expected the stop to be re-armed")
+	}
+	if _, err := executions.StartExecution(ctx, "s1", []string{"paw1"}, false); err != nil {
+		t.Errorf("Expected executions to start again, got %v", err)
+	}
+}
+
+func TestEmergencyStopService_Load(t *testing.T) {
+	repo := newMockEmergencyStopRepo()
+	repo.stops["old"] = &entity.EmergencyStop{ID: "old", Reason: "before restart", TrippedAt: time.Now()}
+
+	stop := NewEmergencyStopService(repo, nil, nil, nil, nil)
+	if err := stop.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !errors.Is(stop.Check(), ErrEmergencyStopEngaged) {
+		t.Error("Expected the restored trip to stay engaged")
+	}
+	if status := stop.Status(); status == nil || status.ID != "old" {
+		t.Errorf("Expected the restored trip, got %+v", status)
+	}
+}
+
+func TestEmergencyStopService_NilCheck(t *testing.T) {
+	var stop *EmergencyStopService
+	if err := stop.Check(); err != nil {
+		t.Errorf("Expected a nil stop to allow everything, got %v", err)
+	}
+}
+
+func TestEmergencyStopService_BlocksAdHocTasks(t *testing.T) {
+	svc, _ := newTestAdHocTaskService()
+	stop := NewEmergencyStopService(newMockEmergencyStopRepo(), nil, nil, nil, nil)
+	svc.SetEmergencyStop(stop)
+	ctx := context.Background()
+
+	if _, err := stop.Trip(ctx, "drill", "admin-1"); err != nil {
+		t.Fatalf("Trip failed: %v", err)
+	}
+	if _, err := svc.Create(ctx, "paw1", AdHocTaskRequest{Command: "whoami"}, "admin-1"); !errors.Is(err, ErrEmergencyStopEngaged) {
+		t.Errorf("Expected ErrEmergencyStopEngaged, got %v", err)
+	}
+}
+
+func TestEmergencyStopService_DatabaseWatchdog(t *testing.T) {
+	stop, _, _, scheduleRepo, repo, agents := newTestEmergencyStop(t)
+	scheduleRepo.schedules["active"] = &entity.Schedule{ID: "active", Status: entity.ScheduleStatusActive}
+
+	down := errors.New("database is locked")
+	failing := func(ctx context.Context) error { return down }
+	healthy := func(ctx context.Context) error { return nil }
+
+	// The database answers again before the threshold
+	failed := stop.checkDatabase(failing, time.Second, 0, 3)
+	failed = stop.checkDatabase(healthy, time.Second, failed, 3)
+	if failed != 0 || stop.Check() != nil {
+		t.Fatal("Expected a single failure not to trip the stop")
+	}
+
+	// The database is gone: the trip cannot be saved yet
+	repo.err = down
+	scheduleRepo.findErr = down
+	for i := 0; i < 3; i++ {
+		failed = stop.checkDatabase(failing, time.Second, failed, 3)
+	}
+	if failed != 3 || !errors.Is(stop.Check(), ErrEmergencyStopEngaged) {
+		t.Fatalf("Expected the stop to trip after 3 failures, got %d failures", failed)
+	}
+	status := stop.Status()
+	if status == nil || !status.Automatic || status.TrippedBy != "" || len(agents.messages) != 1 {
+		t.Fatalf("Expected an automatic trip notifying the agents, got %+v", status)
+	}
+	if len(repo.stops) != 0 {
+		t.Error("Expected the trip not to be saved while the database is down")
+	}
+
+	// Further failures do not trip again
+	stop.checkDatabase(failing, time.Second, failed, 3)
+	if len(agents.messages) != 1 {
+		t.Errorf("Expected a single trip, got %d agent messages", len(agents.messages))
+	}
+
+	// Once the database answers, the trip is enforced and saved
+	repo.err = nil
+	scheduleRepo.findErr = nil
+	if failed := stop.checkDatabase(healthy, time.Second, failed, 3); failed != 0 {
+		t.Errorf("Expected the failures to reset, got %d", failed)
+	}
+	saved, ok := repo.stops[status.ID]
+	if !ok || !saved.Automatic || len(saved.PausedSchedules) != 1 {
+		t.Errorf("Expected the trip to be enforced and saved, got %+v", saved)
+	}
+	if scheduleRepo.schedules["active"].Status != entity.ScheduleStatusPaused {
+		t.Error("Expected the schedule to be paused once the database answers")
+	}
+	if !errors.Is(stop.Check(), ErrEmergencyStopEngaged) {
+		t.Error("Expected the stop to stay engaged until re-armed")
+	}
+}
+
+func TestEmergencyStopService_WatchDatabaseStop(t *testing.T) {
+	stop := NewEmergencyStopService(newMockEmergencyStopRepo(), nil, nil, nil, nil)
+	stop.WatchDatabase(func(ctx context.Context) error { return nil }, time.Millisecond, 3)
+	time.Sleep(5 * time.Millisecond)
+	stop.Stop()
+	stop.Stop()
+
+	// A zero interval disables the watch
+	disabled := NewEmergencyStopService(newMockEmergencyStopRepo(), nil, nil, nil, nil)
+	disabled.WatchDatabase(nil, 0, 3)
+	disabled.Stop()
+}
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// EventSink receives the events published on the bus. Handle is called
+// synchronously by the publisher, so sinks doing network I/O must not block.
+type EventSink interface {
+	Name() string
+	Handle(ctx context.Context, event *entity.Event) error
+}
+
+// EventBus fans platform events out to sinks, so services publish what
+// happened without knowing who listens (notifications, event streams)
+type EventBus struct {
+	mu     sync.RWMutex
+	sinks  []EventSink
+	logger *zap.Logger
+}
+
+// NewEventBus creates an event bus without sinks
+func NewEventBus(logger *zap.Logger) *EventBus {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &EventBus{logger: logger}
+}
+
+// AddSink registers a sink for every event published afterwards
+func (b *EventBus) AddSink(sink EventSink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, sink)
+}
+
+// Sinks returns the names of the registered sinks
+func (b *EventBus) Sinks() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	names := make([]string, 0, len(b.sinks))
+	for _, sink := range b.sinks {
+		names = append(names, sink.Name())
+	}
+	return names
+}
+
+// Publish hands an event to every sink. Sink failures are logged and never
+// reach the publisher. A nil bus discards events, so services can publish
+// unconditionally.
+func (b *EventBus) Publish(ctx context.Context, event *entity.Event) {
+	if b == nil || event == nil {
+		return
+	}
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	sinks := b.sinks
+	b.mu.RUnlock()
+
+	for _, sink := range sinks {
+		b.dispatch(ctx, sink, event)
+	}
+}
+
+// dispatch calls a sink, recovering from panics so one sink cannot break the others
+func (b *EventBus) dispatch(ctx context.Context, sink EventSink, event *entity.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("Event sink panicked",
+				zap.String("sink", sink.Name()),
+				zap.String("event", string(event.Type)),
+				zap.Any("panic", r),
+			)
+		}
+	}()
+
+	if err := sink.Handle(ctx, event); err != nil {
+		b.logger.Warn("Event sink failed",
+			zap.String("sink", sink.Name()),
+			zap.String("event", string(event.Type)),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,230 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// recordingSink keeps the events it receives
+type recordingSink struct {
+	mu     sync.Mutex
+	name   string
+	events []*entity.Event
+	err    error
+	panics bool
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Handle(_ context.Context, event *entity.Event) error {
+	if s.panics {
+		panic("sink exploded")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return s.err
+}
+
+func (s *recordingSink) types() []entity.EventType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	types := make([]entity.EventType, 0, len(s.events))
+	for _, e := range s.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestEventBus_PublishReachesEverySink(t *testing.T) {
+	bus := NewEventBus(nil)
+	failing := &recordingSink{name: "failing", err: errors.New("broker down")}
+	panicking := &recordingSink{name: "panicking", panics: true}
+	last := &recordingSink{name: "last"}
+	bus.AddSink(failing)
+	bus.AddSink(panicking)
+	bus.AddSink(last)
+
+	bus.Publish(context.Background(), &entity.Event{Type: entity.EventAgentOffline})
+
+	if len(failing.events) != 1 || len(last.events) != 1 {
+		t.Fatalf("Expected every sink to receive the event, got %d and %d", len(failing.events), len(last.events))
+	}
+	event := last.events[0]
+	if event.ID == "" || event.OccurredAt.IsZero() {
+		t.Errorf("Expected ID and time to be set, got %+v", event)
+	}
+	if names := bus.Sinks(); len(names) != 3 || names[0] != "failing" {
+		t.Errorf("Unexpected sinks: %v", names)
+	}
+}
+
+func TestEventBus_NilDiscardsEvents(t *testing.T) {
+	var bus *EventBus
+	bus.Publish(context.Background(), &entity.Event{Type: entity.EventAgentOffline})
+}
+
+func TestExecutionService_PublishesLifecycleEvents(t *testing.T) {
+	svc := buildTestExecutionService()
+	sink := &recordingSink{name: "test"}
+	bus := NewEventBus(nil)
+	bus.AddSink(sink)
+	svc.SetEventBus(bus)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "scenario-1", []string{"agent-1"}, true)
+	if err != nil {
+		t.Fatalf("StartExecution failed: %v", err)
+	}
+	for _, task := range started.Tasks {
+		if err := svc.UpdateResultByID(ctx, task.ResultID, entity.StatusSuccess, "ok", 0, "agent-1"); err != nil {
+			t.Fatalf("UpdateResultByID failed: %v", err)
+		}
+	}
+
+	got := sink.types()
+	want := []entity.EventType{entity.EventExecutionStarted, entity.EventResultCompleted, entity.EventExecutionCompleted}
+	if len(got) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected events %v, got %v", want, got)
+			break
+		}
+	}
+	if sink.events[0].ScenarioName != "Test Scenario" || sink.events[0].Execution.ID != started.Execution.ID {
+		t.Errorf("Unexpected started event: %+v", sink.events[0])
+	}
+}
+
+func TestExecutionService_CancelPublishesEvent(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	sink := &recordingSink{name: "test"}
+	bus := NewEventBus(nil)
+	bus.AddSink(sink)
+	svc := &ExecutionService{resultRepo: resultRepo, events: bus}
+
+	if err := svc.CancelExecution(context.Background(), "e1"); err != nil {
+		t.Fatalf("CancelExecution failed: %v", err)
+	}
+
+	if got := sink.types(); len(got) != 1 || got[0] != entity.EventExecutionCancelled {
+		t.Errorf("Expected a cancelled event, got %v", got)
+	}
+}
+
+func TestAgentService_PublishesAgentOfflineOnce(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["a1"] = &entity.Agent{Paw: "a1", Status: entity.AgentOnline, LastSeen: time.Now()}
+	repo.agents["a2"] = &entity.Agent{Paw: "a2", Status: entity.AgentOnline, LastSeen: time.Now().Add(-time.Hour)}
+	sink := &recordingSink{name: "test"}
+	bus := NewEventBus(nil)
+	bus.AddSink(sink)
+	svc := NewAgentService(repo)
+	svc.SetEventBus(bus)
+	ctx := context.Background()
+
+	if err := svc.MarkAgentOffline(ctx, "a1"); err != nil {
+		t.Fatalf("MarkAgentOffline failed: %v", err)
+	}
+	if err := svc.MarkAgentOffline(ctx, "a1"); err != nil {
+		t.Fatalf("MarkAgentOffline failed: %v", err)
+	}
+	if err := svc.CheckStaleAgents(ctx, time.Minute); err != nil {
+		t.Fatalf("CheckStaleAgents failed: %v", err)
+	}
+
+	if len(sink.events) != 2 || sink.events[0].Agent.Paw != "a1" || sink.events[1].Agent.Paw != "a2" {
+		t.Errorf("Expected one offline event per agent, got %+v", sink.events)
+	}
+}
+
+func TestAgentService_PublishesAgentOnlineOnce(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["a1"] = &entity.Agent{Paw: "a1", Status: entity.AgentOffline}
+	sink := &recordingSink{name: "test"}
+	bus := NewEventBus(nil)
+	bus.AddSink(sink)
+	svc := NewAgentService(repo)
+	svc.SetEventBus(bus)
+	ctx := context.Background()
+
+	for _, paw := range []string{"a1", "a1", "a2"} {
+		if err := svc.RegisterAgent(ctx, &entity.Agent{Paw: paw, Hostname: "ws-" + paw}); err != nil {
+			t.Fatalf("RegisterAgent failed: %v", err)
+		}
+	}
+
+	if len(sink.events) != 2 || sink.events[0].Agent.Paw != "a1" || sink.events[1].Agent.Paw != "a2" {
+		t.Fatalf("Expected one online event per agent coming online, got %+v", sink.events)
+	}
+	if sink.events[0].Type != entity.EventAgentOnline || sink.events[0].Agent.Status != entity.AgentOnline {
+		t.Errorf("Unexpected event %+v", sink.events[0])
+	}
+}
+
+func TestScheduleService_RunNow_PublishesScheduleFailed(t *testing.T) {
+	repo := newMockScheduleRepo()
+	repo.schedules["sched-1"] = &entity.Schedule{
+		ID: "sched-1", Name: "Nightly", ScenarioID: "missing",
+		Frequency: entity.FrequencyDaily, Status: entity.ScheduleStatusActive,
+	}
+	sink := &recordingSink{name: "test"}
+	bus := NewEventBus(nil)
+	bus.AddSink(sink)
+	svc := NewScheduleService(repo, buildFailingExecutionService(), zap.NewNop())
+	svc.SetEventBus(bus)
+
+	run, err := svc.RunNow(context.Background(), "sched-1")
+	if err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	if run.Status != "failed" {
+		t.Fatalf("Expected a failed run, got %q", run.Status)
+	}
+
+	if len(sink.events) != 1 || sink.events[0].Type != entity.EventScheduleFailed ||
+		sink.events[0].Schedule.ID != "sched-1" || sink.events[0].Error == "" {
+		t.Errorf("Expected a schedule failed event, got %+v", sink.events)
+	}
+}
+
+func TestNotificationService_HandlesBusEvents(t *testing.T) {
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true,
+		Preferences: entity.NotificationPreferences{
+			entity.NotificationExecutionStarted: {entity.ChannelEmail},
+			entity.NotificationAgentOffline:     {entity.ChannelEmail},
+		},
+	}
+	bus := NewEventBus(nil)
+	bus.AddSink(NewNotificationService(notificationRepo, nil, nil, "", nil))
+	ctx := context.Background()
+
+	bus.Publish(ctx, &entity.Event{
+		Type:         entity.EventExecutionStarted,
+		Execution:    &entity.Execution{ID: "e1", StartedAt: time.Now()},
+		ScenarioName: "Discovery",
+	})
+	bus.Publish(ctx, &entity.Event{Type: entity.EventAgentOffline, Agent: &entity.Agent{Paw: "a1", Hostname: "ws-01"}})
+	bus.Publish(ctx, &entity.Event{Type: entity.EventResultCompleted, Result: &entity.ExecutionResult{ID: "r1"}})
+
+	types := map[entity.NotificationType]int{}
+	for _, n := range notificationRepo.notifications {
+		types[n.Type]++
+	}
+	if len(notificationRepo.notifications) != 2 ||
+		types[entity.NotificationExecutionStarted] != 1 || types[entity.NotificationAgentOffline] != 1 {
+		t.Errorf("Expected started and offline notifications, got %v", types)
+	}
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// ParseEventTypes parses a comma-separated list of event types. An empty value
+// returns no types, which sinks read as every event.
+func ParseEventTypes(value string) ([]entity.EventType, error) {
+	var types []entity.EventType
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		eventType := entity.EventType(part)
+		if !entity.IsValidEventType(eventType) {
+			return nil, fmt.Errorf("unknown event type %q", part)
+		}
+		types = append(types, eventType)
+	}
+	return types, nil
+}
+
+// WebhookEventSink streams bus events as JSON to a single endpoint, so
+// deployments can feed them into their own pipelines. Requests carry the same
+// headers as notification webhooks and are sent once, without retries.
+type WebhookEventSink struct {
+	url       string
+	mu        sync.RWMutex
+	secret    string
+	types     map[entity.EventType]bool // Empty streams every event
+	client    *http.Client
+	logger    *zap.Logger
+	semaphore chan struct{} // Bounds concurrent posts
+}
+
+// NewWebhookEventSink creates a sink posting the given event types, or every
+// event when types is empty, to url. Requests are signed when secret is set.
+func NewWebhookEventSink(url, secret string, types []entity.EventType, logger *zap.Logger) *WebhookEventSink {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	filter := make(map[entity.EventType]bool, len(types))
+	for _, t := range types {
+		filter[t] = true
+	}
+	return &WebhookEventSink{
+		url:       url,
+		secret:    secret,
+		types:     filter,
+		client:    &http.Client{Timeout: webhookTimeout},
+		logger:    logger,
+		semaphore: make(chan struct{}, 10),
+	}
+}
+
+// Name identifies the sink in logs
+func (s *WebhookEventSink) Name() string {
+	return "webhook-stream"
+}
+
+// SetSecret replaces the secret requests are signed with, e.g. rotated in Vault
+func (s *WebhookEventSink) SetSecret(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secret = secret
+}
+
+// Handle posts the event in the background
+func (s *WebhookEventSink) Handle(_ context.Context, event *entity.Event) error {
+	if len(s.types) > 0 && !s.types[event.Type] {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	go func() {
+		s.semaphore <- struct{}{}
+		defer func() { <-s.semaphore }()
+		s.mu.RLock()
+		secret := s.secret
+		s.mu.RUnlock()
+		headers := webhookHeaders(secret, string(event.Type), event.ID, body, time.Now())
+		if _, err := postWebhook(context.Background(), s.client, s.url, body, headers); err != nil {
+			s.logger.Error("Failed to stream event",
+				zap.String("event_id", event.ID),
+				zap.String("event", string(event.Type)),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
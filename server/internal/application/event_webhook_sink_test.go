@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestParseEventTypes(t *testing.T) {
+	types, err := ParseEventTypes(" execution.completed, agent.offline ,")
+	if err != nil {
+		t.Fatalf("ParseEventTypes failed: %v", err)
+	}
+	if len(types) != 2 || types[0] != entity.EventExecutionCompleted || types[1] != entity.EventAgentOffline {
+		t.Errorf("Unexpected types: %v", types)
+	}
+
+	if types, err := ParseEventTypes(""); err != nil || types != nil {
+		t.Errorf("Expected no types for an empty value, got %v, %v", types, err)
+	}
+	if _, err := ParseEventTypes("execution.completed,execution_failed"); err == nil {
+		t.Error("Expected an error for an unknown event type")
+	}
+}
+
+func TestWebhookEventSink_StreamsSignedEvents(t *testing.T) {
+	type request struct {
+		headers http.Header
+		body    []byte
+	}
+	received := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{headers: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookEventSink(server.URL, "stream-secret", []entity.EventType{entity.EventExecutionCompleted}, nil)
+	bus := NewEventBus(nil)
+	bus.AddSink(sink)
+	ctx := context.Background()
+
+	bus.Publish(ctx, &entity.Event{Type: entity.EventAgentOffline, Agent: &entity.Agent{Paw: "a1"}})
+	bus.Publish(ctx, &entity.Event{
+		Type:         entity.EventExecutionCompleted,
+		Execution:    &entity.Execution{ID: "e1", Status: entity.ExecutionCompleted},
+		ScenarioName: "Discovery",
+	})
+
+	select {
+	case req := <-received:
+		var event entity.Event
+		if err := json.Unmarshal(req.body, &event); err != nil {
+			t.Fatalf("Invalid event body: %v", err)
+		}
+		if event.Type != entity.EventExecutionCompleted || event.Execution == nil || event.Execution.ID != "e1" {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+		if req.headers.Get(WebhookEventHeader) != "execution.completed" || req.headers.Get(WebhookDeliveryHeader) != event.ID {
+			t.Errorf("Unexpected headers: %v", req.headers)
+		}
+		ts, _ := strconv.ParseInt(req.headers.Get(WebhookTimestampHeader), 10, 64)
+		if req.headers.Get(WebhookSignatureHeader) != SignWebhookPayload("stream-secret", ts, req.body) {
+			t.Error("Expected a valid signature")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the streamed event")
+	}
+
+	select {
+	case req := <-received:
+		t.Errorf("Expected filtered events to be dropped, got %s", req.body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
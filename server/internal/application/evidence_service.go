@@ -0,0 +1,230 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+// Evidence errors
+var (
+	ErrEvidenceNotFound      = errors.New("evidence not found")
+	ErrInvalidEvidence       = errors.New("invalid evidence")
+	ErrEvidenceTooLarge      = errors.New("evidence is too large")
+	ErrEvidenceQuotaExceeded = errors.New("evidence quota of the result exceeded")
+	ErrEvidenceType          = errors.New("evidence type not allowed")
+)
+
+// Content types of packet captures, which content sniffing does not detect
+const (
+	ContentTypePcap   = "application/vnd.tcpdump.pcap"
+	ContentTypePcapng = "application/x-pcapng"
+)
+
+// maxEvidenceDescription is the longest evidence description accepted, in bytes
+const maxEvidenceDescription = 1000
+
+// EvidenceConfig controls the size and type of evidence files
+type EvidenceConfig struct {
+	MaxSize      int64    // Largest file accepted, in bytes
+	ResultQuota  int64    // Bytes stored per result, all files together
+	AllowedTypes []string // Content types accepted, detected from the content
+}
+
+// DefaultEvidenceConfig returns the default evidence settings: 5 MB per file,
+// 50 MB per result, screenshots, packet captures, PDF, text and archives
+func DefaultEvidenceConfig() EvidenceConfig {
+	return EvidenceConfig{
+		MaxSize:     5 << 20,
+		ResultQuota: 50 << 20,
+		AllowedTypes: []string{
+			"image/png", "image/jpeg", "image/gif", "image/webp",
+			ContentTypePcap, ContentTypePcapng,
+			"application/pdf", "text/plain", "application/zip", "application/x-gzip",
+		},
+	}
+}
+
+// EvidenceService stores the files operators attach to results as proof of
+// their outcome, so purple-team reviews have them next to each technique
+type EvidenceService struct {
+	repo       repository.EvidenceRepository
+	resultRepo repository.ResultRepository
+	config     EvidenceConfig
+	blobs      BlobStore
+	reviews    *ExecutionReviewService
+}
+
+// NewEvidenceService creates a new evidence service
+func NewEvidenceService(
+	repo repository.EvidenceRepository,
+	resultRepo repository.ResultRepository,
+	config EvidenceConfig,
+) *EvidenceService {
+	return &EvidenceService{repo: repo, resultRepo: resultRepo, config: config}
+}
+
+// SetBlobStore keeps the content of new evidence in store instead of the
+// database. Evidence stored before keeps its content in the database.
+func (s *EvidenceService) SetBlobStore(store BlobStore) {
+	s.blobs = store
+}
+
+// SetExecutionReviews refuses evidence for executions whose review was signed off
+func (s *EvidenceService) SetExecutionReviews(reviews *ExecutionReviewService) {
+	s.reviews = reviews
+}
+
+// MaxSize returns the largest evidence file accepted, in bytes
+func (s *EvidenceService) MaxSize() int64 {
+	return s.config.MaxSize
+}
+
+// Attach stores a file for a result. When checksum is set, it must be the
+// SHA-256 of the content, so truncated uploads are refused.
+func (s *EvidenceService) Attach(
+	ctx context.Context,
+	resultID, fileName, description, checksum string,
+	content []byte,
+	uploadedBy string,
+) (*entity.Evidence, error) {
+	name := artifactName(fileName)
+	if name == "" {
+		return nil, fmt.Errorf("%w: a file name is required", ErrInvalidEvidence)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidEvidence)
+	}
+	if int64(len(content)) > s.config.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", ErrEvidenceTooLarge, len(content), s.config.MaxSize)
+	}
+	description = strings.TrimSpace(description)
+	if len(description) > maxEvidenceDescription {
+		return nil, fmt.Errorf("%w: the description is longer than %d characters", ErrInvalidEvidence, maxEvidenceDescription)
+	}
+
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	if checksum != "" && !strings.EqualFold(strings.TrimSpace(checksum), digest) {
+		return nil, fmt.Errorf("%w: the SHA-256 of the content is %s", ErrInvalidEvidence, digest)
+	}
+	contentType := evidenceContentType(content)
+	if !s.allowedType(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrEvidenceType, contentType)
+	}
+
+	result, err := s.resultRepo.FindResultByID(ctx, resultID)
+	if err != nil || result == nil {
+		return nil, ErrResultNotFound
+	}
+	if s.reviews != nil {
+		if err := s.reviews.checkLocked(ctx, result.ExecutionID); err != nil {
+			return nil, err
+		}
+	}
+	stored, err := s.repo.TotalSizeByResult(ctx, resultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check evidence quota: %w", err)
+	}
+	if stored+int64(len(content)) > s.config.ResultQuota {
+		return nil, fmt.Errorf("%w: %d of %d bytes used", ErrEvidenceQuotaExceeded, stored, s.config.ResultQuota)
+	}
+
+	evidence := &entity.Evidence{
+		ID:          uuid.New().String(),
+		ResultID:    resultID,
+		ExecutionID: result.ExecutionID,
+		Name:        name,
+		Description: description,
+		ContentType: contentType,
+		SHA256:      digest,
+		Size:        int64(len(content)),
+		UploadedBy:  uploadedBy,
+		CreatedAt:   time.Now(),
+	}
+
+	dbContent := content
+	if s.blobs != nil {
+		evidence.StorageKey = fmt.Sprintf("evidence/%s/%s", evidence.ExecutionID, evidence.ID)
+		if err := s.blobs.Put(ctx, evidence.StorageKey, content); err != nil {
+			return nil, fmt.Errorf("failed to store evidence: %w", err)
+		}
+		dbContent = nil
+	}
+	if err := s.repo.Create(ctx, evidence, dbContent); err != nil {
+		if evidence.StorageKey != "" {
+			_ = s.blobs.Delete(ctx, evidence.StorageKey)
+		}
+		return nil, fmt.Errorf("failed to store evidence: %w", err)
+	}
+	return evidence, nil
+}
+
+// allowedType reports whether evidence of a content type is accepted
+func (s *EvidenceService) allowedType(contentType string) bool {
+	for _, allowed := range s.config.AllowedTypes {
+		if strings.EqualFold(strings.TrimSpace(allowed), contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// evidenceContentType detects the content type of a file, without parameters.
+// Packet captures are recognized by their magic number.
+func evidenceContentType(content []byte) string {
+	if len(content) >= 4 {
+		switch string(content[:4]) {
+		case "\xd4\xc3\xb2\xa1", "\xa1\xb2\xc3\xd4", "\x4d\x3c\xb2\xa1", "\xa1\xb2\x3c\x4d":
+			return ContentTypePcap
+		case "\x0a\x0d\x0d\x0a":
+			return ContentTypePcapng
+		}
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	return contentType
+}
+
+// ListByResult returns the evidence of a result, oldest first
+func (s *EvidenceService) ListByResult(ctx context.Context, resultID string) ([]*entity.Evidence, error) {
+	return s.repo.FindByResult(ctx, resultID)
+}
+
+// Get returns evidence of a result with its content
+func (s *EvidenceService) Get(ctx context.Context, resultID, id string) (*entity.Evidence, []byte, error) {
+	evidence, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrEvidenceNotFound
+		}
+		return nil, nil, err
+	}
+	if evidence.ResultID != resultID {
+		return nil, nil, ErrEvidenceNotFound
+	}
+
+	var content []byte
+	switch {
+	case evidence.StorageKey == "":
+		content, err = s.repo.Content(ctx, id)
+	case s.blobs == nil:
+		err = errors.New("evidence is kept in object storage, which is not configured")
+	default:
+		content, err = s.blobs.Get(ctx, evidence.StorageKey)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load evidence: %w", err)
+	}
+	return evidence, content, nil
+}
@@ -0,0 +1,178 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockEvidenceRepo implements repository.EvidenceRepository for tests
+type mockEvidenceRepo struct {
+	files    map[string]*entity.Evidence
+	contents map[string][]byte
+	err      error
+}
+
+func newMockEvidenceRepo() *mockEvidenceRepo {
+	return &mockEvidenceRepo{files: make(map[string]*entity.Evidence), contents: make(map[string][]byte)}
+}
+
+func (m *mockEvidenceRepo) Create(ctx context.Context, evidence *entity.Evidence, content []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.files[evidence.ID] = evidence
+	m.contents[evidence.ID] = content
+	return nil
+}
+
+func (m *mockEvidenceRepo) FindByID(ctx context.Context, id string) (*entity.Evidence, error) {
+	if evidence, ok := m.files[id]; ok {
+		return evidence, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockEvidenceRepo) FindByResult(ctx context.Context, resultID string) ([]*entity.Evidence, error) {
+	var files []*entity.Evidence
+	for _, evidence := range m.files {
+		if evidence.ResultID == resultID {
+			files = append(files, evidence)
+		}
+	}
+	return files, nil
+}
+
+func (m *mockEvidenceRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	return m.contents[id], nil
+}
+
+func (m *mockEvidenceRepo) TotalSizeByResult(ctx context.Context, resultID string) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	var total int64
+	for _, evidence := range m.files {
+		if evidence.ResultID == resultID {
+			total += evidence.Size
+		}
+	}
+	return total, nil
+}
+
+// pcapHeader is the start of a little-endian packet capture
+const pcapHeader = "\xd4\xc3\xb2\xa1\x02\x00\x04\x00"
+
+func newTestEvidenceService() (*EvidenceService, *mockEvidenceRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{{ID: "result-1", ExecutionID: "exec-1"}}
+	repo := newMockEvidenceRepo()
+	config := DefaultEvidenceConfig()
+	config.MaxSize = 16
+	config.ResultQuota = 20
+	return NewEvidenceService(repo, resultRepo, config), repo
+}
+
+func TestEvidenceService_Attach(t *testing.T) {
+	svc, _ := newTestEvidenceService()
+	ctx := context.Background()
+
+	sum := sha256.Sum256([]byte(pcapHeader))
+	evidence, err := svc.Attach(ctx, "result-1", "captures/beacon.pcap", " C2 traffic ", hex.EncodeToString(sum[:]), []byte(pcapHeader), "operator")
+	if err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if evidence.Name != "beacon.pcap" || evidence.Description != "C2 traffic" || evidence.ContentType != ContentTypePcap ||
+		evidence.ExecutionID != "exec-1" || evidence.SHA256 != hex.EncodeToString(sum[:]) || evidence.UploadedBy != "operator" {
+		t.Errorf("Unexpected evidence: %+v", evidence)
+	}
+
+	tests := []struct {
+		name     string
+		result   string
+		file     string
+		checksum string
+		content  string
+		wantErr  error
+	}{
+		{"no name", "result-1", "", "", "text", ErrInvalidEvidence},
+		{"empty", "result-1", "a.txt", "", "", ErrInvalidEvidence},
+		{"too large", "result-1", "a.txt", "", "0123456789abcdefg", ErrEvidenceTooLarge},
+		{"checksum mismatch", "result-1", "a.txt", "00", "text", ErrInvalidEvidence},
+		{"type", "result-1", "run.exe", "", "MZ\x90\x00\x03\x00\x00\x00", ErrEvidenceType},
+		{"unknown result", "missing", "a.txt", "", "text", ErrResultNotFound},
+		{"quota", "result-1", "a.txt", "", "0123456789abcdef", ErrEvidenceQuotaExceeded},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Attach(ctx, tt.result, tt.file, "", tt.checksum, []byte(tt.content), "operator"); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestEvidenceContentType(t *testing.T) {
+	tests := map[string]string{
+		pcapHeader:                         ContentTypePcap,
+		"\x0a\x0d\x0d\x0a\x1c\x00\x00\x00": ContentTypePcapng,
+		"\x89PNG\r\n\x1a\n":                "image/png",
+		"EDR alert at 10:42":               "text/plain",
+	}
+	for content, want := range tests {
+		if got := evidenceContentType([]byte(content)); got != want {
+			t.Errorf("evidenceContentType(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestEvidenceService_Get(t *testing.T) {
+	svc, repo := newTestEvidenceService()
+	ctx := context.Background()
+	evidence, _ := svc.Attach(ctx, "result-1", "note.txt", "", "", []byte("blocked"), "operator")
+
+	got, content, err := svc.Get(ctx, "result-1", evidence.ID)
+	if err != nil || got.ID != evidence.ID || string(content) != "blocked" {
+		t.Errorf("Unexpected evidence: %+v %q (%v)", got, content, err)
+	}
+	if _, _, err := svc.Get(ctx, "result-2", evidence.ID); !errors.Is(err, ErrEvidenceNotFound) {
+		t.Errorf("Expected ErrEvidenceNotFound for another result, got %v", err)
+	}
+	if _, _, err := svc.Get(ctx, "result-1", "missing"); !errors.Is(err, ErrEvidenceNotFound) {
+		t.Errorf("Expected ErrEvidenceNotFound, got %v", err)
+	}
+	if files, _ := svc.ListByResult(ctx, "result-1"); len(files) != 1 {
+		t.Errorf("Expected 1 file, got %d", len(files))
+	}
+
+	repo.err = errors.New("db down")
+	if _, err := svc.Attach(ctx, "result-1", "b.txt", "", "", []byte("x"), "operator"); err == nil {
+		t.Error("Expected the repository error")
+	}
+}
+
+func TestEvidenceService_BlobStore(t *testing.T) {
+	svc, repo := newTestEvidenceService()
+	store := newMockBlobStore()
+	svc.SetBlobStore(store)
+	ctx := context.Background()
+
+	evidence, err := svc.Attach(ctx, "result-1", "shot.png", "", "", []byte("\x89PNG\r\n\x1a\n"), "operator")
+	if err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if evidence.StorageKey != "evidence/exec-1/"+evidence.ID || repo.contents[evidence.ID] != nil || store.objects[evidence.StorageKey] == nil {
+		t.Errorf("Expected the content in the blob store, got key %q", evidence.StorageKey)
+	}
+	if _, content, err := svc.Get(ctx, "result-1", evidence.ID); err != nil || string(content) != "\x89PNG\r\n\x1a\n" {
+		t.Errorf("Expected the content read from the blob store, got %q (%v)", content, err)
+	}
+
+	store.err = errors.New("bucket gone")
+	if _, err := svc.Attach(ctx, "result-1", "x.txt", "", "", []byte("x"), "operator"); err == nil {
+		t.Error("Expected the blob store error")
+	}
+}
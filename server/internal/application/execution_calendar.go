@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCalendarRange is returned when the calendar range is inverted or too long
+var ErrInvalidCalendarRange = errors.New("invalid calendar range: end must not be before start and the range cannot exceed 366 days")
+
+// maxCalendarDays bounds the calendar to one (leap) year of cells
+const maxCalendarDays = 366
+
+// CalendarDay is one cell of the execution calendar heatmap
+type CalendarDay struct {
+	Date           string   `json:"date"` // YYYY-MM-DD
+	ExecutionCount int      `json:"execution_count"`
+	CompletedCount int      `json:"completed_count"`
+	AverageScore   *float64 `json:"average_score"` // Null when no execution of the day was scored
+}
+
+// ExecutionCalendar is the number of executions started per day with their average score
+type ExecutionCalendar struct {
+	StartDate       string        `json:"start_date"`
+	EndDate         string        `json:"end_date"`
+	ScenarioIDs     []string      `json:"scenario_ids,omitempty"`
+	TotalExecutions int           `json:"total_executions"`
+	ActiveDays      int           `json:"active_days"`    // Days with at least one execution
+	MaxExecutions   int           `json:"max_executions"` // Busiest day, to scale the heatmap colors
+	Days            []CalendarDay `json:"days"`
+}
+
+// GetExecutionCalendar returns one entry per day between start and end (inclusive, in the
+// location of start), optionally restricted to the given scenarios
+func (s *AnalyticsService) GetExecutionCalendar(
+	ctx context.Context,
+	start, end time.Time,
+	scenarioIDs []string,
+) (*ExecutionCalendar, error) {
+	first := startOfDay(start)
+	last := startOfDay(end.In(start.Location()))
+	if last.Before(first) || last.Sub(first) >= maxCalendarDays*24*time.Hour {
+		return nil, ErrInvalidCalendarRange
+	}
+
+	executions, err := s.resultRepo.FindExecutionsByDateRange(ctx, first, last.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		return nil, err
+	}
+
+	scenarios := make(map[string]bool, len(scenarioIDs))
+	for _, id := range scenarioIDs {
+		scenarios[id] = true
+	}
+
+	type dayTotals struct {
+		count, completed, scored int
+		scoreSum                 float64
+	}
+	totals := make(map[string]*dayTotals)
+	calendar := &ExecutionCalendar{
+		StartDate:   first.Format("2006-01-02"),
+		EndDate:     last.Format("2006-01-02"),
+		ScenarioIDs: scenarioIDs,
+		Days:        make([]CalendarDay, 0, int(last.Sub(first).Hours()/24)+1),
+	}
+
+	for _, exec := range executions {
+		if len(scenarios) > 0 && !scenarios[exec.ScenarioID] {
+			continue
+		}
+		key := exec.StartedAt.In(start.Location()).Format("2006-01-02")
+		day, ok := totals[key]
+		if !ok {
+			day = &dayTotals{}
+			totals[key] = day
+		}
+		day.count++
+		calendar.TotalExecutions++
+		if exec.CompletedAt != nil {
+			day.completed++
+		}
+		if exec.Score != nil {
+			day.scored++
+			day.scoreSum += exec.Score.Overall
+		}
+	}
+
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		cell := CalendarDay{Date: key}
+		if day, ok := totals[key]; ok {
+			cell.ExecutionCount = day.count
+			cell.CompletedCount = day.completed
+			if day.scored > 0 {
+				avg := day.scoreSum / float64(day.scored)
+				cell.AverageScore = &avg
+			}
+			calendar.ActiveDays++
+			calendar.MaxExecutions = max(calendar.MaxExecutions, day.count)
+		}
+		calendar.Days = append(calendar.Days, cell)
+	}
+
+	return calendar, nil
+}
+
+// startOfDay truncates a time to midnight in its own location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
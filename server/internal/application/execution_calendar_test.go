@@ -0,0 +1,103 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestGetExecutionCalendar(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC) }
+	running := &entity.Execution{ID: "e4", ScenarioID: "s1", Status: entity.ExecutionRunning, StartedAt: day(3, 23)}
+	repo := &mockResultRepoForAnalytics{executions: []*entity.Execution{
+		createTestExecution("e1", "s1", 80, 4, 0, 1, 5, day(1, 9), entity.ExecutionCompleted),
+		createTestExecution("e2", "s1", 40, 2, 0, 3, 5, day(1, 15), entity.ExecutionCompleted),
+		createTestExecution("e3", "s2", 100, 5, 0, 0, 5, day(3, 0), entity.ExecutionCompleted),
+		running,
+		createTestExecution("e5", "s1", 10, 0, 0, 5, 5, day(5, 0), entity.ExecutionCompleted),
+	}}
+	svc := NewAnalyticsService(repo)
+
+	calendar, err := svc.GetExecutionCalendar(context.Background(), day(1, 12), day(4, 8), nil)
+	if err != nil {
+		t.Fatalf("GetExecutionCalendar failed: %v", err)
+	}
+
+	if calendar.StartDate != "2024-03-01" || calendar.EndDate != "2024-03-04" {
+		t.Errorf("Range = %s..%s, want 2024-03-01..2024-03-04", calendar.StartDate, calendar.EndDate)
+	}
+	if len(calendar.Days) != 4 {
+		t.Fatalf("Expected 4 days, got %d", len(calendar.Days))
+	}
+	if calendar.TotalExecutions != 4 || calendar.ActiveDays != 2 || calendar.MaxExecutions != 2 {
+		t.Errorf("Unexpected totals: %+v", calendar)
+	}
+
+	first := calendar.Days[0]
+	if first.ExecutionCount != 2 || first.CompletedCount != 2 || first.AverageScore == nil || *first.AverageScore != 60 {
+		t.Errorf("Unexpected first day: %+v", first)
+	}
+	if empty := calendar.Days[1]; empty.ExecutionCount != 0 || empty.AverageScore != nil {
+		t.Errorf("Expected an empty second day, got %+v", empty)
+	}
+	third := calendar.Days[2]
+	if third.ExecutionCount != 2 || third.CompletedCount != 1 || third.AverageScore == nil || *third.AverageScore != 100 {
+		t.Errorf("Unscored executions should not lower the average, got %+v", third)
+	}
+}
+
+func TestGetExecutionCalendar_ScenarioFilter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	repo := &mockResultRepoForAnalytics{executions: []*entity.Execution{
+		createTestExecution("e1", "s1", 80, 4, 0, 1, 5, now, entity.ExecutionCompleted),
+		createTestExecution("e2", "s2", 40, 2, 0, 3, 5, now, entity.ExecutionCompleted),
+		createTestExecution("e3", "s3", 20, 1, 0, 4, 5, now, entity.ExecutionCompleted),
+	}}
+	svc := NewAnalyticsService(repo)
+
+	calendar, err := svc.GetExecutionCalendar(context.Background(), now, now, []string{"s1", "s3"})
+	if err != nil {
+		t.Fatalf("GetExecutionCalendar failed: %v", err)
+	}
+
+	if calendar.TotalExecutions != 2 {
+		t.Errorf("Expected 2 executions, got %d", calendar.TotalExecutions)
+	}
+	if avg := calendar.Days[0].AverageScore; avg == nil || *avg != 50 {
+		t.Errorf("Expected average 50, got %v", avg)
+	}
+}
+
+func TestGetExecutionCalendar_InvalidRange(t *testing.T) {
+	svc := NewAnalyticsService(&mockResultRepoForAnalytics{})
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := svc.GetExecutionCalendar(context.Background(), start, start.AddDate(0, 0, -1), nil); !errors.Is(err, ErrInvalidCalendarRange) {
+		t.Errorf("Expected ErrInvalidCalendarRange for inverted range, got %v", err)
+	}
+	if _, err := svc.GetExecutionCalendar(context.Background(), start, start.AddDate(0, 0, maxCalendarDays), nil); !errors.Is(err, ErrInvalidCalendarRange) {
+		t.Errorf("Expected ErrInvalidCalendarRange for a range over %d days, got %v", maxCalendarDays, err)
+	}
+
+	calendar, err := svc.GetExecutionCalendar(context.Background(), start, start.AddDate(0, 0, maxCalendarDays-1), nil)
+	if err != nil {
+		t.Fatalf("Expected a full year to be accepted, got %v", err)
+	}
+	if len(calendar.Days) != maxCalendarDays {
+		t.Errorf("Expected %d days, got %d", maxCalendarDays, len(calendar.Days))
+	}
+}
+
+func TestGetExecutionCalendar_RepositoryError(t *testing.T) {
+	repo := newMockResultRepo()
+	repo.err = errors.New("db error")
+	svc := NewAnalyticsService(repo)
+
+	now := time.Now()
+	if _, err := svc.GetExecutionCalendar(context.Background(), now, now, nil); err == nil {
+		t.Error("Expected repository error")
+	}
+}
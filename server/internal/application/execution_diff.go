@@ -0,0 +1,205 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+// ErrExecutionsNotComparable is returned when comparing runs of different scenarios
+var ErrExecutionsNotComparable = errors.New("executions are runs of different scenarios")
+
+// CompareExecutions returns the differences between a baseline execution and a
+// later run of the same scenario: techniques whose status changed on an agent
+// of both runs, the score delta overall and per tactic, and the agents added to
+// or removed from the run
+func (s *AnalyticsService) CompareExecutions(ctx context.Context, baselineID, executionID string) (*entity.ExecutionDiff, error) {
+	baseline, err := s.resultRepo.FindExecutionByID(ctx, baselineID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+	if baseline.ScenarioID != execution.ScenarioID {
+		return nil, ErrExecutionsNotComparable
+	}
+
+	before, err := s.resultRepo.FindResultsByExecution(ctx, baseline.ID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+	if err != nil {
+		return nil, err
+	}
+	techniques, err := s.techniquesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	calculator := service.NewScoreCalculator()
+	diff := &entity.ExecutionDiff{
+		ScenarioID:          execution.ScenarioID,
+		BaselineExecutionID: baseline.ID,
+		ExecutionID:         execution.ID,
+		BaselineScore:       overallScore(calculator, baseline, before),
+		Score:               overallScore(calculator, execution, after),
+		Tactics:             tacticDeltas(calculator.CalculateScoreByTactic(before, techniques), calculator.CalculateScoreByTactic(after, techniques)),
+		Techniques:          []entity.TechniqueStatusChange{},
+	}
+	diff.ScoreDelta = diff.Score - diff.BaselineScore
+
+	beforeAgents, afterAgents := resultAgents(before), resultAgents(after)
+	diff.AddedAgents = missingAgents(afterAgents, beforeAgents)
+	diff.RemovedAgents = missingAgents(beforeAgents, afterAgents)
+
+	previous, current := latestStatuses(before), latestStatuses(after)
+	keys := make(map[defenseKey]bool)
+	for key := range previous {
+		keys[key] = true
+	}
+	for key := range current {
+		keys[key] = true
+	}
+	for key := range keys {
+		// Agents of a single run are reported as added or removed, not per technique
+		if !beforeAgents[key.agentPaw] || !afterAgents[key.agentPaw] {
+			continue
+		}
+		change := statusChange(previous[key], current[key])
+		if change == "" {
+			continue
+		}
+		diff.Techniques = append(diff.Techniques, entity.TechniqueStatusChange{
+			TechniqueID:    key.techniqueID,
+			AgentPaw:       key.agentPaw,
+			BaselineStatus: previous[key],
+			Status:         current[key],
+			Change:         change,
+		})
+	}
+	sort.Slice(diff.Techniques, func(i, j int) bool {
+		if diff.Techniques[i].TechniqueID != diff.Techniques[j].TechniqueID {
+			return diff.Techniques[i].TechniqueID < diff.Techniques[j].TechniqueID
+		}
+		return diff.Techniques[i].AgentPaw < diff.Techniques[j].AgentPaw
+	})
+	for i := range diff.Techniques {
+		if technique, ok := techniques[diff.Techniques[i].TechniqueID]; ok {
+			diff.Techniques[i].TechniqueName = technique.Name
+		}
+	}
+	return diff, nil
+}
+
+// overallScore returns the stored score of an execution, or the score of its
+// results while it is not scored yet
+func overallScore(calculator *service.ScoreCalculator, execution *entity.Execution, results []*entity.ExecutionResult) float64 {
+	if execution.Score != nil {
+		return execution.Score.Overall
+	}
+	return calculator.CalculateScore(results).Overall
+}
+
+// tacticDeltas returns the score change of every tactic tested by either run, sorted by tactic
+func tacticDeltas(before, after map[entity.TacticType]*entity.SecurityScore) []entity.TacticScoreDelta {
+	tactics := make(map[entity.TacticType]bool)
+	for tactic, score := range before {
+		if score.Total > 0 {
+			tactics[tactic] = true
+		}
+	}
+	for tactic, score := range after {
+		if score.Total > 0 {
+			tactics[tactic] = true
+		}
+	}
+
+	deltas := make([]entity.TacticScoreDelta, 0, len(tactics))
+	for tactic := range tactics {
+		delta := entity.TacticScoreDelta{Tactic: string(tactic)}
+		if score, ok := before[tactic]; ok && score.Total > 0 {
+			delta.BaselineScore = &score.Overall
+		}
+		if score, ok := after[tactic]; ok && score.Total > 0 {
+			delta.Score = &score.Overall
+		}
+		if delta.BaselineScore != nil && delta.Score != nil {
+			d := *delta.Score - *delta.BaselineScore
+			delta.Delta = &d
+		}
+		deltas = append(deltas, delta)
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Tactic < deltas[j].Tactic })
+	return deltas
+}
+
+// latestStatuses returns the status of the latest result of each technique on
+// each agent. Successful results the SIEM detected count as detected.
+func latestStatuses(results []*entity.ExecutionResult) map[defenseKey]entity.ResultStatus {
+	latest := make(map[defenseKey]*entity.ExecutionResult)
+	for _, result := range results {
+		key := defenseKey{techniqueID: result.TechniqueID, agentPaw: result.AgentPaw}
+		if existing, ok := latest[key]; ok && existing.StartedAt.After(result.StartedAt) {
+			continue
+		}
+		latest[key] = result
+	}
+
+	statuses := make(map[defenseKey]entity.ResultStatus, len(latest))
+	for key, result := range latest {
+		status := result.Status
+		if status == entity.StatusSuccess && result.Detected {
+			status = entity.StatusDetected
+		}
+		statuses[key] = status
+	}
+	return statuses
+}
+
+// statusChange classifies the change of a technique status between two runs, "" when unchanged
+func statusChange(before, after entity.ResultStatus) string {
+	switch {
+	case before == after:
+		return ""
+	case before == "":
+		return entity.ChangeAdded
+	case after == "":
+		return entity.ChangeRemoved
+	}
+	previous, current := defenseLevel(before), defenseLevel(after)
+	if previous < 0 || current < 0 {
+		return entity.ChangeChanged
+	}
+	if current > previous {
+		return entity.ChangeImproved
+	}
+	return entity.ChangeRegressed
+}
+
+// resultAgents returns the agents that ran at least one result
+func resultAgents(results []*entity.ExecutionResult) map[string]bool {
+	agents := make(map[string]bool)
+	for _, result := range results {
+		agents[result.AgentPaw] = true
+	}
+	return agents
+}
+
+// missingAgents returns the agents of from missing from other, sorted
+func missingAgents(from, other map[string]bool) []string {
+	missing := []string{}
+	for paw := range from {
+		if !other[paw] {
+			missing = append(missing, paw)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,90 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestCompareExecutions(t *testing.T) {
+	repo := newMockResultRepo()
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Command and Scripting Interpreter", Tactic: entity.TacticExecution}
+	techniqueRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery}
+	techniqueRepo.techniques["T1003"] = &entity.Technique{ID: "T1003", Name: "OS Credential Dumping", Tactic: entity.TacticCredentialAccess}
+	svc := NewAnalyticsService(repo)
+	svc.SetTechniqueRepository(techniqueRepo)
+
+	base := time.Now().Add(-48 * time.Hour)
+	result := func(id, technique, paw string, status entity.ResultStatus) *entity.ExecutionResult {
+		return &entity.ExecutionResult{ID: id, TechniqueID: technique, AgentPaw: paw, Status: status}
+	}
+	before := regressionFixture(repo, "before", base,
+		result("b1", "T1059", "agent-1", entity.StatusSuccess),
+		result("b2", "T1082", "agent-1", entity.StatusDetected),
+		result("b3", "T1003", "agent-1", entity.StatusBlocked),
+		result("b4", "T1059", "agent-2", entity.StatusSuccess),
+	)
+	before.Score = &entity.SecurityScore{Overall: 50}
+	regressionFixture(repo, "after", base.Add(24*time.Hour),
+		result("a1", "T1059", "agent-1", entity.StatusBlocked),                                                                     // Improved
+		&entity.ExecutionResult{ID: "a2", TechniqueID: "T1082", AgentPaw: "agent-1", Status: entity.StatusSuccess, Detected: true}, // Unchanged
+		result("a3", "T1003", "agent-1", entity.StatusSuccess),                                                                     // Regressed
+		result("a4", "T1087", "agent-1", entity.StatusFailed),                                                                      // Added
+		result("a5", "T1059", "agent-3", entity.StatusBlocked),                                                                     // New agent
+	)
+
+	diff, err := svc.CompareExecutions(context.Background(), "before", "after")
+	if err != nil {
+		t.Fatalf("CompareExecutions failed: %v", err)
+	}
+
+	if diff.BaselineScore != 50 || diff.ScoreDelta != diff.Score-50 {
+		t.Errorf("unexpected scores %v -> %v (%v)", diff.BaselineScore, diff.Score, diff.ScoreDelta)
+	}
+	if len(diff.AddedAgents) != 1 || diff.AddedAgents[0] != "agent-3" ||
+		len(diff.RemovedAgents) != 1 || diff.RemovedAgents[0] != "agent-2" {
+		t.Errorf("unexpected agents +%v -%v", diff.AddedAgents, diff.RemovedAgents)
+	}
+
+	want := map[string]string{"T1003": entity.ChangeRegressed, "T1059": entity.ChangeImproved, "T1087": entity.ChangeAdded}
+	if len(diff.Techniques) != len(want) {
+		t.Fatalf("techniques = %+v, want %v", diff.Techniques, want)
+	}
+	for _, change := range diff.Techniques {
+		if change.AgentPaw != "agent-1" || want[change.TechniqueID] != change.Change {
+			t.Errorf("unexpected change %+v", change)
+		}
+	}
+	if diff.Techniques[0].TechniqueID != "T1003" || diff.Techniques[0].TechniqueName != "OS Credential Dumping" {
+		t.Errorf("expected sorted and named techniques, got %+v", diff.Techniques[0])
+	}
+
+	tactics := make(map[string]entity.TacticScoreDelta)
+	for _, tactic := range diff.Tactics {
+		tactics[tactic.Tactic] = tactic
+	}
+	if execution := tactics["execution"]; execution.Delta == nil || *execution.Delta != 100 {
+		t.Errorf("expected execution to improve by 100, got %+v", execution)
+	}
+	if credential := tactics["credential-access"]; credential.Delta == nil || *credential.Delta != -100 {
+		t.Errorf("expected credential-access to drop by 100, got %+v", credential)
+	}
+}
+
+func TestCompareExecutions_Errors(t *testing.T) {
+	repo := newMockResultRepo()
+	svc := NewAnalyticsService(repo)
+	regressionFixture(repo, "e1", time.Now())
+	repo.executions["other"] = &entity.Execution{ID: "other", ScenarioID: "scenario-2"}
+
+	if _, err := svc.CompareExecutions(context.Background(), "e1", "missing"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("expected ErrExecutionNotFound, got %v", err)
+	}
+	if _, err := svc.CompareExecutions(context.Background(), "e1", "other"); !errors.Is(err, ErrExecutionsNotComparable) {
+		t.Errorf("expected ErrExecutionsNotComparable, got %v", err)
+	}
+}
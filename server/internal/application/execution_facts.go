@@ -0,0 +1,101 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+// GetExecutionFacts retrieves the facts extracted during an execution
+func (s *ExecutionService) GetExecutionFacts(ctx context.Context, executionID string) ([]*entity.Fact, error) {
+	if _, err := s.resultRepo.FindExecutionByID(ctx, executionID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+	if s.factRepo == nil {
+		return []*entity.Fact{}, nil
+	}
+	return s.factRepo.FindByExecution(ctx, executionID)
+}
+
+// recordFacts runs the parsers of the executor that produced a result on its
+// full output and stores the facts found. Recording is best-effort: a fact that
+// cannot be stored shows up as missing in the techniques referencing it.
+func (s *ExecutionService) recordFacts(ctx context.Context, result *entity.ExecutionResult, output string) {
+	if s.factRepo == nil || output == "" {
+		return
+	}
+	technique, err := s.techniqueRepo.FindByID(ctx, result.TechniqueID)
+	if err != nil || technique == nil {
+		return
+	}
+	agent, err := s.agentRepo.FindByPaw(ctx, result.AgentPaw)
+	if err != nil || agent == nil {
+		return
+	}
+	executor := resultExecutor(technique, result, agent)
+	if executor == nil || len(executor.Parsers) == 0 {
+		return
+	}
+
+	extracted := service.ExtractFacts(executor.Parsers, output)
+	for _, parser := range executor.Parsers {
+		value, ok := extracted[parser.Fact]
+		if !ok {
+			continue
+		}
+		delete(extracted, parser.Fact)
+
+		fact := &entity.Fact{
+			ID:          uuid.New().String(),
+			ExecutionID: result.ExecutionID,
+			AgentPaw:    result.AgentPaw,
+			ResultID:    result.ID,
+			TechniqueID: result.TechniqueID,
+			Name:        parser.Fact,
+			Value:       value,
+			CreatedAt:   time.Now(),
+		}
+		_ = s.factRepo.Create(ctx, fact)
+	}
+}
+
+// agentFacts returns the facts available to the techniques run by an agent in
+// an execution, by name. Facts of the agent take precedence over facts found on
+// other agents, and later facts over earlier ones.
+func (s *ExecutionService) agentFacts(ctx context.Context, executionID, paw string) map[string]string {
+	facts := make(map[string]string)
+	if s.factRepo == nil {
+		return facts
+	}
+	stored, err := s.factRepo.FindByExecution(ctx, executionID)
+	if err != nil {
+		return facts
+	}
+	for _, own := range []bool{false, true} {
+		for _, fact := range stored {
+			if (fact.AgentPaw == paw) == own {
+				facts[fact.Name] = fact.Value
+			}
+		}
+	}
+	return facts
+}
+
+// substituteTaskFacts substitutes facts into the command and cleanup of a task.
+// Returns the names of the referenced facts without a value.
+func substituteTaskFacts(command, cleanup string, facts map[string]string) (string, string, []string) {
+	command, missing := service.SubstituteFacts(command, facts)
+	cleanup, missingCleanup := service.SubstituteFacts(cleanup, facts)
+	for _, name := range missingCleanup {
+		if !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	return command, cleanup, missing
+}
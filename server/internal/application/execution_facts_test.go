@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+// mockFactRepo implements repository.FactRepository for tests
+type mockFactRepo struct {
+	facts []*entity.Fact
+	err   error
+}
+
+func (m *mockFactRepo) Create(ctx context.Context, fact *entity.Fact) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.facts = append(m.facts, fact)
+	return nil
+}
+
+func (m *mockFactRepo) FindByExecution(ctx context.Context, executionID string) ([]*entity.Fact, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var facts []*entity.Fact
+	for _, f := range m.facts {
+		if f.ExecutionID == executionID {
+			facts = append(facts, f)
+		}
+	}
+	return facts, nil
+}
+
+func newFactTestService(resultRepo *mockResultRepo, factRepo *mockFactRepo, phases []entity.Phase) *ExecutionService {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "Facts", Phases: phases}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{
+		ID:        "T1082",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{
+			Type:    "sh",
+			Command: "hostname",
+			Parsers: []entity.FactParser{{Fact: "hostname", Regex: `^(\S+)`}},
+		}},
+	}
+	techRepo.techniques["T1021"] = &entity.Technique{
+		ID:        "T1021",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "ssh #{fact.hostname}", Cleanup: "rm /tmp/#{fact.hostname}"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{
+		Paw:       "paw1",
+		Status:    entity.AgentOnline,
+		Platform:  "linux",
+		Executors: []string{"sh"},
+		LastSeen:  time.Now(),
+	}
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	svc := NewExecutionService(resultRepo, scenarioRepo, techRepo, agentRepo, orchestrator, service.NewScoreCalculator())
+	svc.SetFactRepository(factRepo)
+	return svc
+}
+
+var factTestPhases = []entity.Phase{
+	{Name: "Discovery", Techniques: []string{"T1082"}},
+	{Name: "Lateral Movement", Techniques: []string{"T1021"}},
+}
+
+func TestFacts_SubstitutedIntoLaterPhase(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	factRepo := &mockFactRepo{}
+	svc := newFactTestService(resultRepo, factRepo, factTestPhases)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(started.Tasks) != 1 || started.Tasks[0].TechniqueID != "T1082" {
+		t.Fatalf("Expected only the discovery task, got %+v", started.Tasks)
+	}
+
+	if err := svc.UpdateResultByID(ctx, started.Tasks[0].ResultID, entity.StatusSuccess, "web-01\n", 0, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	facts, err := svc.GetExecutionFacts(ctx, started.Execution.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(facts) != 1 || facts[0].Name != "hostname" || facts[0].Value != "web-01" || facts[0].TechniqueID != "T1082" {
+		t.Fatalf("Unexpected facts: %+v", facts)
+	}
+
+	tasks := svc.TakeReadyTasks(ctx, "paw1")
+	if len(tasks) != 1 || tasks[0].Command != "ssh web-01" || tasks[0].Cleanup != "rm /tmp/web-01" {
+		t.Fatalf("Expected the fact to be substituted, got %+v", tasks)
+	}
+}
+
+func TestFacts_ParsedWithPhaseExecutor(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	factRepo := &mockFactRepo{}
+	phases := []entity.Phase{
+		{Name: "Discovery", Techniques: []string{"T1082"}, Executors: []string{"bash"}},
+		{Name: "Lateral Movement", Techniques: []string{"T1021"}},
+	}
+	svc := newFactTestService(resultRepo, factRepo, phases)
+	techniques := svc.techniqueRepo.(*mockTechniqueRepo)
+	techniques.techniques["T1082"].Executors = append(techniques.techniques["T1082"].Executors, entity.Executor{
+		Type:    "bash",
+		Command: "hostname -f",
+		Parsers: []entity.FactParser{{Fact: "hostname", Regex: `^[^.]+\.(\S+)`}},
+	})
+	svc.agentRepo.(*mockAgentRepo).agents["paw1"].Executors = []string{"sh", "bash"}
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(started.Tasks) != 1 || started.Tasks[0].Executor != "bash" || started.Tasks[0].Command != "hostname -f" {
+		t.Fatalf("Expected the task to run with the phase executor, got %+v", started.Tasks)
+	}
+	if err := svc.UpdateResultByID(ctx, started.Tasks[0].ResultID, entity.StatusSuccess, "web-01.corp.local\n", 0, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The facts come from the parsers of the executor that ran
+	facts, err := svc.GetExecutionFacts(ctx, started.Execution.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(facts) != 1 || facts[0].Value != "corp.local" {
+		t.Fatalf("Expected the fact of the bash parser, got %+v", facts)
+	}
+}
+
+func TestFacts_MissingFactSkipsTechnique(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// A blocked technique yields no fact
+	if err := svc.UpdateResultByID(ctx, started.Tasks[0].ResultID, entity.StatusBlocked, "web-01", 1, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if tasks := svc.TakeReadyTasks(ctx, "paw1"); len(tasks) != 0 {
+		t.Errorf("Expected no ready task, got %d", len(tasks))
+	}
+	results := resultRepo.results[started.Execution.ID]
+	if len(results) != 2 || results[1].Status != entity.StatusSkipped || results[1].Output != "missing facts: hostname" {
+		t.Fatalf("Expected a skipped lateral movement result, got %+v", results)
+	}
+	if exec := resultRepo.executions[started.Execution.ID]; exec.Status != entity.ExecutionCompleted {
+		t.Errorf("Expected execution to be completed, got %v", exec.Status)
+	}
+}
+
+func TestFacts_FirstPhaseWithoutFacts(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases[1:])
+
+	started, err := svc.StartExecution(context.Background(), "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(started.Tasks) != 0 {
+		t.Errorf("Expected no task, got %+v", started.Tasks)
+	}
+	if exec := resultRepo.executions[started.Execution.ID]; exec.Status != entity.ExecutionCompleted {
+		t.Errorf("Expected execution to be completed, got %v", exec.Status)
+	}
+}
+
+func TestFacts_RecordingFailureDoesNotFailUpdate(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	factRepo := &mockFactRepo{err: errors.New("db error")}
+	svc := newFactTestService(resultRepo, factRepo, factTestPhases)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.UpdateResultByID(ctx, started.Tasks[0].ResultID, entity.StatusSuccess, "web-01", 0, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	results := resultRepo.results[started.Execution.ID]
+	if len(results) != 2 || results[1].Output != "missing facts: hostname" {
+		t.Errorf("Expected the lateral movement to be skipped, got %+v", results)
+	}
+}
+
+func TestGetExecutionFacts_NotFound(t *testing.T) {
+	svc := newFactTestService(newMockResultRepo(), &mockFactRepo{}, factTestPhases)
+
+	if _, err := svc.GetExecutionFacts(context.Background(), "missing"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Expected ErrExecutionNotFound, got %v", err)
+	}
+}
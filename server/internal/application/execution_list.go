@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// SetExecutionSummaries lists executions with the counts of their results,
+// and their results when asked, in a fixed number of queries rather than one
+// per execution
+func (s *ExecutionService) SetExecutionSummaries(repo repository.ExecutionSummaryRepository) {
+	s.summaries = repo
+}
+
+// ListExecutions returns the most recent executions with the counts of their
+// results by status. With includeResults, each execution also carries its
+// results, outputs cut to the preview size.
+func (s *ExecutionService) ListExecutions(ctx context.Context, limit int, includeResults bool) ([]*entity.Execution, error) {
+	if s.summaries == nil {
+		return s.listExecutionsByOne(ctx, limit, includeResults)
+	}
+
+	executions, err := s.summaries.FindRecentExecutionsWithSummary(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if !includeResults || len(executions) == 0 {
+		return executions, nil
+	}
+
+	ids := make([]string, len(executions))
+	for i, execution := range executions {
+		ids[i] = execution.ID
+	}
+	results, err := s.summaries.FindResultsByExecutions(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
+	byExecution := make(map[string][]*entity.ExecutionResult, len(executions))
+	for _, result := range results {
+		byExecution[result.ExecutionID] = append(byExecution[result.ExecutionID], result)
+	}
+	for _, execution := range executions {
+		execution.Results = s.resultValues(byExecution[execution.ID])
+	}
+	return executions, nil
+}
+
+// listExecutionsByOne lists the executions with a query for the results of
+// each, when no summary repository is set
+func (s *ExecutionService) listExecutionsByOne(ctx context.Context, limit int, includeResults bool) ([]*entity.Execution, error) {
+	executions, err := s.resultRepo.FindRecentExecutions(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, execution := range executions {
+		results, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get results: %w", err)
+		}
+		execution.ResultSummary = summarizeResults(results)
+		if includeResults {
+			execution.Results = s.resultValues(results)
+		}
+	}
+	return executions, nil
+}
+
+// summarizeResults counts results by status
+func summarizeResults(results []*entity.ExecutionResult) *entity.ExecutionResultSummary {
+	summary := &entity.ExecutionResultSummary{ByStatus: make(map[entity.ResultStatus]int)}
+	for _, result := range results {
+		summary.ByStatus[result.Status]++
+		summary.Total++
+	}
+	return summary
+}
+
+// resultValues returns the previews of results, as an execution holds them
+func (s *ExecutionService) resultValues(results []*entity.ExecutionResult) []entity.ExecutionResult {
+	values := make([]entity.ExecutionResult, 0, len(results))
+	for _, result := range s.ResultPreviews(results) {
+		values = append(values, *result)
+	}
+	return values
+}
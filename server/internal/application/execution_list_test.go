@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+// countingSummaryRepo serves summaries from a mock result repository,
+// counting its queries
+type countingSummaryRepo struct {
+	results *mockResultRepo
+	queries int
+}
+
+func (r *countingSummaryRepo) FindRecentExecutionsWithSummary(ctx context.Context, limit int) ([]*entity.Execution, error) {
+	r.queries++
+	executions, err := r.results.FindRecentExecutions(ctx, limit)
+	for _, execution := range executions {
+		execution.ResultSummary = summarizeResults(r.results.results[execution.ID])
+	}
+	return executions, err
+}
+
+func (r *countingSummaryRepo) FindResultsByExecutions(ctx context.Context, executionIDs []string) ([]*entity.ExecutionResult, error) {
+	r.queries++
+	var results []*entity.ExecutionResult
+	for _, id := range executionIDs {
+		results = append(results, r.results.results[id]...)
+	}
+	return results, nil
+}
+
+func newListTestService() (*ExecutionService, *mockResultRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1"}
+	resultRepo.executions["e2"] = &entity.Execution{ID: "e2"}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", Status: entity.StatusSuccess, Output: strings.Repeat("x", 100)},
+		{ID: "r2", ExecutionID: "e1", Status: entity.StatusBlocked},
+	}
+	svc := &ExecutionService{resultRepo: resultRepo, outputPreviewSize: 10}
+	return svc, resultRepo
+}
+
+func TestListExecutions(t *testing.T) {
+	svc, resultRepo := newListTestService()
+	summaries := &countingSummaryRepo{results: resultRepo}
+	svc.SetExecutionSummaries(summaries)
+
+	executions, err := svc.ListExecutions(context.Background(), 10, false)
+	if err != nil || len(executions) != 2 {
+		t.Fatalf("Expected 2 executions, got %v, %v", executions, err)
+	}
+	if summaries.queries != 1 {
+		t.Errorf("Expected a single query, got %d", summaries.queries)
+	}
+	for _, execution := range executions {
+		if execution.Results != nil {
+			t.Errorf("Expected %s listed without results", execution.ID)
+		}
+	}
+
+	summaries.queries = 0
+	executions, err = svc.ListExecutions(context.Background(), 10, true)
+	if err != nil {
+		t.Fatalf("ListExecutions failed: %v", err)
+	}
+	if summaries.queries != 2 {
+		t.Errorf("Expected 2 queries for any number of executions, got %d", summaries.queries)
+	}
+	for _, execution := range executions {
+		if execution.ID != "e1" {
+			if len(execution.Results) != 0 {
+				t.Errorf("Expected %s without results, got %d", execution.ID, len(execution.Results))
+			}
+			continue
+		}
+		if execution.ResultSummary.Total != 2 || execution.ResultSummary.ByStatus[entity.StatusBlocked] != 1 {
+			t.Errorf("Unexpected summary: %+v", execution.ResultSummary)
+		}
+		if len(execution.Results) != 2 || len(execution.Results[0].Output) != 10 || execution.Results[0].OutputSize != 100 {
+			t.Errorf("Expected the results with their output previews, got %+v", execution.Results)
+		}
+	}
+	if len(resultRepo.results["e1"][0].Output) != 100 {
+		t.Error("Expected the stored result left whole")
+	}
+}
+
+func TestListExecutions_WithoutSummaryRepository(t *testing.T) {
+	svc, _ := newListTestService()
+
+	executions, err := svc.ListExecutions(context.Background(), 10, true)
+	if err != nil || len(executions) != 2 {
+		t.Fatalf("Expected 2 executions, got %v, %v", executions, err)
+	}
+	for _, execution := range executions {
+		if execution.ResultSummary == nil {
+			t.Fatalf("Expected a summary for %s", execution.ID)
+		}
+		if execution.ID == "e1" && (execution.ResultSummary.Total != 2 || len(execution.Results) != 2) {
+			t.Errorf("Expected the 2 results of e1, got %+v", execution)
+		}
+	}
+}
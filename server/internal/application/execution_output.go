@@ -0,0 +1,179 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"autostrike/internal/domain/entity"
+)
+
+// Output size defaults
+const (
+	DefaultOutputBlobThreshold = 64 << 10 // Outputs larger than this are stored apart
+	DefaultOutputPreviewSize   = 4 << 10  // Bytes of an output kept in results stored apart and in result lists
+	DefaultOutputMaxSize       = 10 << 20 // Outputs are truncated to this size when received
+	DefaultOutputPageSize      = 64 << 10 // Bytes of output returned per page
+	MaxOutputPageSize          = 1 << 20
+)
+
+// Output errors
+var (
+	ErrResultNotFound     = errors.New("result not found")
+	ErrInvalidOutputRange = errors.New("invalid output range")
+)
+
+// SetOutputStore keeps the outputs larger than threshold bytes in store; the
+// result only holds the first previewSize bytes and a reference to the blob.
+// Zero sizes use the defaults.
+func (s *ExecutionService) SetOutputStore(store BlobStore, threshold, previewSize int) {
+	if threshold <= 0 {
+		threshold = DefaultOutputBlobThreshold
+	}
+	if previewSize <= 0 || previewSize > threshold {
+		previewSize = min(DefaultOutputPreviewSize, threshold)
+	}
+	s.outputStore = store
+	s.outputThreshold = threshold
+	s.outputPreviewSize = previewSize
+}
+
+// SetOutputMaxSize sets the largest output kept for a result, in bytes; the
+// rest of a larger output is dropped when it is received
+func (s *ExecutionService) SetOutputMaxSize(size int) {
+	if size > 0 {
+		s.outputMaxSize = size
+	}
+}
+
+// setResultOutput sets the output of a result, truncated to the maximum size,
+// moving it to the output store when it is too large. An output that cannot
+// be stored stays in the result. Blob keys derive from the result ID, so a
+// later output overwrites the blob.
+func (s *ExecutionService) setResultOutput(ctx context.Context, result *entity.ExecutionResult, output string) {
+	result.OutputTruncated = false
+	if s.outputMaxSize > 0 && len(output) > s.outputMaxSize {
+		output = outputPreview(output, s.outputMaxSize)
+		result.OutputTruncated = true
+	}
+
+	result.Output, result.OutputRef, result.OutputSize = output, "", 0
+	if s.outputStore == nil || len(output) <= s.outputThreshold {
+		return
+	}
+
+	key := resultOutputKey(result)
+	if err := s.outputStore.Put(ctx, key, []byte(output)); err != nil {
+		return
+	}
+	result.Output = outputPreview(output, s.outputPreviewSize)
+	result.OutputRef = key
+	result.OutputSize = int64(len(output))
+}
+
+// ResultOutput returns the full output of a result, loading it from the
+// output store when the result only holds a preview
+func (s *ExecutionService) ResultOutput(ctx context.Context, result *entity.ExecutionResult) (string, error) {
+	if result.OutputRef == "" {
+		return result.Output, nil
+	}
+	if s.outputStore == nil {
+		return "", fmt.Errorf("output of result %s is kept in object storage, which is not configured", result.ID)
+	}
+	data, err := s.outputStore.Get(ctx, result.OutputRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to load output of result %s: %w", result.ID, err)
+	}
+	return string(data), nil
+}
+
+// ResultOutputPage is a range of bytes of the output of a result
+type ResultOutputPage struct {
+	ResultID   string `json:"result_id"`
+	Offset     int    `json:"offset"`
+	Size       int    `json:"size"` // Bytes of the whole output
+	Output     string `json:"output"`
+	NextOffset *int   `json:"next_offset,omitempty"` // Offset of the following page, unset on the last one
+	Truncated  bool   `json:"truncated"`             // The agent sent more than the maximum output size
+}
+
+// GetResultOutput returns up to limit bytes of the output of a result from
+// offset. Pages end on a character boundary, so the next page starts at
+// NextOffset rather than offset+limit.
+func (s *ExecutionService) GetResultOutput(ctx context.Context, resultID string, offset, limit int) (*ResultOutputPage, error) {
+	if limit <= 0 {
+		limit = DefaultOutputPageSize
+	}
+	if offset < 0 || limit > MaxOutputPageSize {
+		return nil, fmt.Errorf("%w: offset must be positive and limit at most %d", ErrInvalidOutputRange, MaxOutputPageSize)
+	}
+
+	result, err := s.resultRepo.FindResultByID(ctx, resultID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrResultNotFound
+		}
+		return nil, err
+	}
+	output, err := s.ResultOutput(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	if offset > len(output) {
+		return nil, fmt.Errorf("%w: offset %d is past the %d bytes of output", ErrInvalidOutputRange, offset, len(output))
+	}
+
+	page := outputPreview(output[offset:], limit)
+	if page == "" && offset < len(output) {
+		// A page too small for the next character still returns it whole
+		_, size := utf8.DecodeRuneInString(output[offset:])
+		page = output[offset : offset+size]
+	}
+	out := &ResultOutputPage{
+		ResultID:  result.ID,
+		Offset:    offset,
+		Size:      len(output),
+		Output:    page,
+		Truncated: result.OutputTruncated,
+	}
+	if end := offset + len(page); end < len(output) {
+		out.NextOffset = &end
+	}
+	return out, nil
+}
+
+// ResultPreviews returns the results with their outputs cut to the preview
+// size, for listings; OutputSize tells the size of the full output of a
+// shortened result, read with GetResultOutput
+func (s *ExecutionService) ResultPreviews(results []*entity.ExecutionResult) []*entity.ExecutionResult {
+	previews := make([]*entity.ExecutionResult, len(results))
+	for i, result := range results {
+		previews[i] = result
+		if len(result.Output) <= s.outputPreviewSize {
+			continue
+		}
+		copied := *result
+		copied.Output = outputPreview(result.Output, s.outputPreviewSize)
+		copied.OutputSize = int64(len(result.Output))
+		previews[i] = &copied
+	}
+	return previews
+}
+
+// resultOutputKey returns the blob key of the full output of a result
+func resultOutputKey(result *entity.ExecutionResult) string {
+	return fmt.Sprintf("outputs/%s/%s", result.ExecutionID, result.ID)
+}
+
+// outputPreview returns the first size bytes of an output, cut on a rune boundary
+func outputPreview(output string, size int) string {
+	if len(output) <= size {
+		return output
+	}
+	for size > 0 && !utf8.RuneStart(output[size]) {
+		size--
+	}
+	return output[:size]
+}
@@ -0,0 +1,212 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockBlobStore keeps objects in memory
+type mockBlobStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func newMockBlobStore() *mockBlobStore {
+	return &mockBlobStore{objects: make(map[string][]byte)}
+}
+
+func (m *mockBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.objects[key] = data
+	return nil
+}
+
+func (m *mockBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+	}
+	return data, nil
+}
+
+func (m *mockBlobStore) Delete(ctx context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *mockBlobStore) Location() string { return "memory" }
+
+func TestOutputPreview(t *testing.T) {
+	if got := outputPreview("short", 10); got != "short" {
+		t.Errorf("Expected a short output unchanged, got %q", got)
+	}
+	if got := outputPreview("abcdef", 3); got != "abc" {
+		t.Errorf("Expected 3 bytes, got %q", got)
+	}
+	// "é" is 2 bytes: cutting inside it keeps the preview valid UTF-8
+	if got := outputPreview("aé", 2); got != "a" {
+		t.Errorf("Expected the cut on a rune boundary, got %q", got)
+	}
+}
+
+func TestExecutionService_OffloadsLargeOutput(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	factRepo := &mockFactRepo{}
+	svc := newFactTestService(resultRepo, factRepo, factTestPhases)
+	store := newMockBlobStore()
+	svc.SetOutputStore(store, 64, 16)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("StartExecution failed: %v", err)
+	}
+	resultID := started.Tasks[0].ResultID
+	output := "web-01\n" + strings.Repeat("x", 200)
+	if err := svc.UpdateResultByID(ctx, resultID, entity.StatusSuccess, output, 0, "paw1"); err != nil {
+		t.Fatalf("UpdateResultByID failed: %v", err)
+	}
+
+	result, _ := resultRepo.FindResultByID(ctx, resultID)
+	if result.Output != output[:16] || result.OutputSize != int64(len(output)) {
+		t.Errorf("Expected a 16 byte preview of %d bytes, got %q (%d)", len(output), result.Output, result.OutputSize)
+	}
+	if result.OutputRef != "outputs/"+started.Execution.ID+"/"+resultID || string(store.objects[result.OutputRef]) != output {
+		t.Errorf("Expected the full output stored, got ref %q", result.OutputRef)
+	}
+	full, err := svc.ResultOutput(ctx, result)
+	if err != nil || full != output {
+		t.Errorf("ResultOutput() = %d bytes, %v", len(full), err)
+	}
+
+	// Facts are parsed from the full output
+	facts, _ := svc.GetExecutionFacts(ctx, started.Execution.ID)
+	if len(facts) != 1 || facts[0].Value != "web-01" {
+		t.Errorf("Unexpected facts: %+v", facts)
+	}
+}
+
+func TestExecutionService_OutputStoreFailureKeepsOutput(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	store := newMockBlobStore()
+	store.err = errors.New("bucket not found")
+	svc.SetOutputStore(store, 8, 0)
+	ctx := context.Background()
+
+	started, _ := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	resultID := started.Tasks[0].ResultID
+	if err := svc.UpdateResultByID(ctx, resultID, entity.StatusSuccess, "a long output", 0, "paw1"); err != nil {
+		t.Fatalf("UpdateResultByID failed: %v", err)
+	}
+	result, _ := resultRepo.FindResultByID(ctx, resultID)
+	if result.Output != "a long output" || result.OutputRef != "" {
+		t.Errorf("Expected the output kept in the result, got %+v", result)
+	}
+
+	// Small outputs never reach the store
+	store.err = nil
+	if full, err := svc.ResultOutput(ctx, result); err != nil || full != "a long output" {
+		t.Errorf("ResultOutput() = %q, %v", full, err)
+	}
+	if _, err := svc.ResultOutput(ctx, &entity.ExecutionResult{ID: "r", OutputRef: "outputs/missing"}); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist for a missing blob, got %v", err)
+	}
+}
+
+func TestExecutionService_TruncatesOutputToMaxSize(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	svc.SetOutputMaxSize(10)
+	ctx := context.Background()
+
+	started, _ := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	resultID := started.Tasks[0].ResultID
+	if err := svc.UpdateResultByID(ctx, resultID, entity.StatusSuccess, strings.Repeat("y", 50), 0, "paw1"); err != nil {
+		t.Fatalf("UpdateResultByID failed: %v", err)
+	}
+	result, _ := resultRepo.FindResultByID(ctx, resultID)
+	if result.Output != strings.Repeat("y", 10) || !result.OutputTruncated {
+		t.Errorf("Expected the output truncated to 10 bytes, got %q (truncated %v)", result.Output, result.OutputTruncated)
+	}
+
+	// Non-positive sizes keep the limit
+	svc.SetOutputMaxSize(0)
+	if svc.outputMaxSize != 10 {
+		t.Errorf("Expected the max size unchanged, got %d", svc.outputMaxSize)
+	}
+}
+
+func TestExecutionService_GetResultOutput(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	store := newMockBlobStore()
+	svc.SetOutputStore(store, 8, 4)
+	ctx := context.Background()
+
+	started, _ := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	resultID := started.Tasks[0].ResultID
+	output := "abcdé" + strings.Repeat("z", 10) // "é" spans bytes 4 and 5
+	if err := svc.UpdateResultByID(ctx, resultID, entity.StatusSuccess, output, 0, "paw1"); err != nil {
+		t.Fatalf("UpdateResultByID failed: %v", err)
+	}
+
+	page, err := svc.GetResultOutput(ctx, resultID, 0, 5)
+	if err != nil {
+		t.Fatalf("GetResultOutput failed: %v", err)
+	}
+	if page.Output != "abcd" || page.Size != len(output) || page.NextOffset == nil || *page.NextOffset != 4 {
+		t.Errorf("Expected the first page cut before the rune, got %+v", page)
+	}
+
+	// A page smaller than the next rune returns the rune
+	page, _ = svc.GetResultOutput(ctx, resultID, 4, 1)
+	if page.Output != "é" || *page.NextOffset != 6 {
+		t.Errorf("Expected the whole rune, got %+v", page)
+	}
+
+	// The last page has no next offset; the default limit reads everything
+	page, _ = svc.GetResultOutput(ctx, resultID, 6, 0)
+	if page.Output != strings.Repeat("z", 10) || page.NextOffset != nil {
+		t.Errorf("Expected the last page, got %+v", page)
+	}
+
+	for _, bad := range [][2]int{{-1, 10}, {0, MaxOutputPageSize + 1}, {len(output) + 1, 10}} {
+		if _, err := svc.GetResultOutput(ctx, resultID, bad[0], bad[1]); !errors.Is(err, ErrInvalidOutputRange) {
+			t.Errorf("GetResultOutput(%d, %d) = %v, want ErrInvalidOutputRange", bad[0], bad[1], err)
+		}
+	}
+
+	resultRepo.err = sql.ErrNoRows
+	if _, err := svc.GetResultOutput(ctx, "missing", 0, 0); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound, got %v", err)
+	}
+}
+
+func TestExecutionService_ResultPreviews(t *testing.T) {
+	svc := newFactTestService(newMockResultRepo(), &mockFactRepo{}, factTestPhases)
+	svc.SetOutputStore(newMockBlobStore(), 16, 4)
+
+	long := &entity.ExecutionResult{ID: "r1", Output: "0123456789"}
+	short := &entity.ExecutionResult{ID: "r2", Output: "ok"}
+	previews := svc.ResultPreviews([]*entity.ExecutionResult{long, short})
+
+	if previews[0].Output != "0123" || previews[0].OutputSize != 10 {
+		t.Errorf("Expected a 4 byte preview of 10 bytes, got %+v", previews[0])
+	}
+	if long.Output != "0123456789" {
+		t.Error("Expected the listed result unchanged")
+	}
+	if previews[1] != short {
+		t.Error("Expected a short result returned as is")
+	}
+}
@@ -0,0 +1,201 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+// advanceDeferredPhases decides the deferred phases an agent has reached in an
+// execution, i.e. whose earlier phases only hold final results for the agent.
+// A phase whose run_if conditions hold is planned with the facts known so far:
+// its results are created pending and its tasks are queued for TakeReadyTasks.
+// Otherwise its techniques are recorded as skipped, as are the techniques
+// referencing a missing fact. Decisions are derived from the stored results,
+// so they survive a restart.
+func (s *ExecutionService) advanceDeferredPhases(ctx context.Context, executionID, paw string) error {
+	s.phaseMu.Lock()
+	defer s.phaseMu.Unlock()
+
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("execution not found: %w", err)
+	}
+	if execution.Status != entity.ExecutionRunning || s.scenarioRepo == nil {
+		return nil
+	}
+	scenario, err := s.scenarioRepo.FindByID(ctx, execution.ScenarioID)
+	if err != nil || scenario == nil || !s.hasDeferredPhase(ctx, scenario) {
+		return nil
+	}
+
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to get results: %w", err)
+	}
+	var agentResults []*entity.ExecutionResult
+	byPhase := make(map[string][]*entity.ExecutionResult)
+	for _, r := range results {
+		if r.AgentPaw == paw {
+			agentResults = append(agentResults, r)
+			byPhase[r.Phase] = append(byPhase[r.Phase], r)
+		}
+	}
+
+	for _, phase := range scenario.Phases {
+		phaseResults := byPhase[phase.Name]
+		if len(phaseResults) == 0 && s.orchestrator.DefersPhase(ctx, phase) {
+			created, err := s.decidePhase(ctx, execution, phase, paw, agentResults)
+			if err != nil {
+				return err
+			}
+			phaseResults = created
+			agentResults = append(agentResults, created...)
+		}
+
+		// Later phases wait for this one to finish on the agent
+		for _, r := range phaseResults {
+			if !r.Status.IsTerminal() {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// decidePhase evaluates a deferred phase on an agent and records the outcome as results
+func (s *ExecutionService) decidePhase(
+	ctx context.Context,
+	execution *entity.Execution,
+	phase entity.Phase,
+	paw string,
+	agentResults []*entity.ExecutionResult,
+) ([]*entity.ExecutionResult, error) {
+	status := entity.StatusSkipped
+	reason := "run_if conditions not met"
+	var planned []service.PlannedTask
+
+	agent, err := s.agentRepo.FindByPaw(ctx, paw)
+	if s.orchestrator.PhaseConditionsMet(phase, agentResults) {
+		status = entity.StatusPending
+		if err == nil && agent != nil {
+			planned = s.orchestrator.PlanPhase(ctx, phase, agent, execution.SafeMode)
+		}
+	} else {
+		for _, techID := range phase.Techniques {
+			planned = append(planned, service.PlannedTask{TechniqueID: techID, AgentPaw: paw, Phase: phase.Name})
+		}
+	}
+	facts := s.agentFacts(ctx, execution.ID, paw)
+
+	attempts := make(map[string]int)
+	for _, r := range agentResults {
+		if r.Attempt > attempts[r.TechniqueID] {
+			attempts[r.TechniqueID] = r.Attempt
+		}
+	}
+
+	var created []*entity.ExecutionResult
+	var tasks []TaskDispatchInfo
+	for _, task := range planned {
+		attempts[task.TechniqueID]++
+		now := time.Now()
+		result := &entity.ExecutionResult{
+			ID:          uuid.New().String(),
+			ExecutionID: execution.ID,
+			TechniqueID: task.TechniqueID,
+			AgentPaw:    paw,
+			Attempt:     attempts[task.TechniqueID],
+			Phase:       phase.Name,
+			Executor:    task.Executor,
+			Status:      status,
+			StartedAt:   now,
+		}
+		var payload *TaskPayload
+		if status != entity.StatusPending {
+			result.Output = reason
+		} else if !skipPlannedTask(result, task) {
+			var missing []string
+			var err error
+			task.Command, task.Cleanup, missing = substituteTaskFacts(task.Command, task.Cleanup, facts)
+			if len(missing) > 0 {
+				result.Status = entity.StatusSkipped
+				result.Output = "missing facts: " + strings.Join(missing, ", ")
+			} else if payload, err = s.linkTaskPayload(ctx, task.Payload, result.ID, paw); err != nil {
+				failUnlinkedPayload(result, task.Payload, err)
+			}
+		}
+		if result.Status == entity.StatusSkipped {
+			result.CompletedAt = &now
+		}
+
+		id := result.ID
+		if err := s.resultRepo.CreateResult(ctx, result); err != nil {
+			return nil, fmt.Errorf("failed to create result: %w", err)
+		}
+		created = append(created, result)
+		if result.Status != entity.StatusPending || result.ID != id {
+			// Skipped, failed, or already planned by a concurrent decision
+			continue
+		}
+
+		tasks = append(tasks, TaskDispatchInfo{
+			ResultID:    result.ID,
+			AgentPaw:    paw,
+			TechniqueID: task.TechniqueID,
+			Command:     task.Command,
+			Executor:    task.Executor,
+			Timeout:     task.Timeout,
+			Cleanup:     task.Cleanup,
+			Limits:      task.Limits,
+			Payload:     payload,
+			Collect:     task.Collect,
+		})
+	}
+
+	if len(tasks) > 0 {
+		s.readyMu.Lock()
+		if s.ready == nil {
+			s.ready = make(map[string][]TaskDispatchInfo)
+		}
+		s.ready[paw] = append(s.ready[paw], tasks...)
+		s.readyMu.Unlock()
+	}
+	return created, nil
+}
+
+// TakeReadyTasks returns the tasks of deferred phases planned for an agent
+// since the last call. Tasks whose result is no longer pending, e.g. because
+// the execution was cancelled, are dropped.
+func (s *ExecutionService) TakeReadyTasks(ctx context.Context, paw string) []TaskDispatchInfo {
+	s.readyMu.Lock()
+	waiting := s.ready[paw]
+	delete(s.ready, paw)
+	s.readyMu.Unlock()
+
+	tasks := make([]TaskDispatchInfo, 0, len(waiting))
+	for _, task := range waiting {
+		result, err := s.resultRepo.FindResultByID(ctx, task.ResultID)
+		if err != nil || result.Status != entity.StatusPending {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// hasDeferredPhase reports whether a scenario has a phase planned during the execution
+func (s *ExecutionService) hasDeferredPhase(ctx context.Context, scenario *entity.Scenario) bool {
+	for _, phase := range scenario.Phases {
+		if s.orchestrator.DefersPhase(ctx, phase) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+func newConditionalTestService(resultRepo *mockResultRepo) *ExecutionService {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:   "s1",
+		Name: "Conditional",
+		Phases: []entity.Phase{
+			{Name: "Credential Access", Techniques: []string{"T1003"}},
+			{
+				Name:       "Lateral Movement",
+				Techniques: []string{"T1021"},
+				RunIf:      []entity.PhaseCondition{{Technique: "T1003", Status: entity.StatusSuccess}},
+			},
+		},
+	}
+	techRepo := newMockTechniqueRepo()
+	for id, command := range map[string]string{"T1003": "dump", "T1021": "ssh"} {
+		techRepo.techniques[id] = &entity.Technique{
+			ID:        id,
+			Platforms: []string{"linux"},
+			Executors: []entity.Executor{{Type: "sh", Command: command}},
+		}
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{
+		Paw:       "paw1",
+		Status:    entity.AgentOnline,
+		Platform:  "linux",
+		Executors: []string{"sh"},
+		LastSeen:  time.Now(),
+	}
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	return NewExecutionService(resultRepo, scenarioRepo, techRepo, agentRepo, orchestrator, service.NewScoreCalculator())
+}
+
+func TestConditionalPhase_RunsWhenConditionHolds(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newConditionalTestService(resultRepo)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(started.Tasks) != 1 || started.Tasks[0].TechniqueID != "T1003" {
+		t.Fatalf("Expected only the credential access task, got %+v", started.Tasks)
+	}
+
+	if err := svc.UpdateResultByID(ctx, started.Tasks[0].ResultID, entity.StatusSuccess, "", 0, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if exec := resultRepo.executions[started.Execution.ID]; exec.Status != entity.ExecutionRunning {
+		t.Errorf("Expected execution to keep running, got %v", exec.Status)
+	}
+
+	tasks := svc.TakeReadyTasks(ctx, "paw1")
+	if len(tasks) != 1 || tasks[0].TechniqueID != "T1021" || tasks[0].Command != "ssh" {
+		t.Fatalf("Expected the lateral movement task, got %+v", tasks)
+	}
+	if again := svc.TakeReadyTasks(ctx, "paw1"); len(again) != 0 {
+		t.Errorf("Expected ready tasks to be taken once, got %d", len(again))
+	}
+
+	if err := svc.UpdateResultByID(ctx, tasks[0].ResultID, entity.StatusBlocked, "", 1, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if exec := resultRepo.executions[started.Execution.ID]; exec.Status != entity.ExecutionCompleted {
+		t.Errorf("Expected execution to be completed, got %v", exec.Status)
+	}
+}
+
+func TestConditionalPhase_SkippedWhenConditionFails(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newConditionalTestService(resultRepo)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.UpdateResultByID(ctx, started.Tasks[0].ResultID, entity.StatusBlocked, "", 1, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if tasks := svc.TakeReadyTasks(ctx, "paw1"); len(tasks) != 0 {
+		t.Errorf("Expected no ready task, got %d", len(tasks))
+	}
+	results := resultRepo.results[started.Execution.ID]
+	if len(results) != 2 || results[1].Phase != "Lateral Movement" || results[1].Status != entity.StatusSkipped {
+		t.Fatalf("Expected a skipped lateral movement result, got %+v", results)
+	}
+	if exec := resultRepo.executions[started.Execution.ID]; exec.Status != entity.ExecutionCompleted {
+		t.Errorf("Expected execution to be completed, got %v", exec.Status)
+	}
+}
+
+func TestTakeReadyTasks_DropsCancelledTasks(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newConditionalTestService(resultRepo)
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.UpdateResultByID(ctx, started.Tasks[0].ResultID, entity.StatusSuccess, "", 0, "paw1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := svc.CancelExecution(ctx, started.Execution.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if tasks := svc.TakeReadyTasks(ctx, "paw1"); len(tasks) != 0 {
+		t.Errorf("Expected cancelled tasks to be dropped, got %d", len(tasks))
+	}
+}
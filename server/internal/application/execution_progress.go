@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// progressHistoryRuns is the number of past runs of a technique whose median
+// duration estimates its next run
+const progressHistoryRuns = 20
+
+// fillProgress computes the progress of an execution with the orchestrator,
+// the median durations of past runs giving the ETA of an active execution
+func (s *ExecutionService) fillProgress(ctx context.Context, execution *entity.Execution) error {
+	if s.orchestrator == nil {
+		return nil
+	}
+	results, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get results: %w", err)
+	}
+
+	var scenario *entity.Scenario
+	if s.scenarioRepo != nil {
+		// A scenario that cannot be loaded only leaves its deferred phases uncounted
+		scenario, _ = s.scenarioRepo.FindByID(ctx, execution.ScenarioID)
+	}
+
+	var history map[string][]time.Duration
+	if s.durations != nil && !isFinishedExecution(execution.Status) {
+		history, err = s.durations.FindRecentDurations(ctx, unfinishedTechniques(results, scenario), progressHistoryRuns)
+		if err != nil {
+			return fmt.Errorf("failed to get technique durations: %w", err)
+		}
+	}
+
+	execution.Progress = s.orchestrator.Progress(ctx, execution, scenario, results, history, time.Now())
+	return nil
+}
+
+// executionWithProgress loads an execution with its progress
+func (s *ExecutionService) executionWithProgress(ctx context.Context, id string) (*entity.Execution, error) {
+	execution, err := s.resultRepo.FindExecutionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fillProgress(ctx, execution); err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+// isFinishedExecution reports whether an execution has no step left to run
+func isFinishedExecution(status entity.ExecutionStatus) bool {
+	return status == entity.ExecutionCompleted || status == entity.ExecutionFailed || status == entity.ExecutionCancelled
+}
+
+// unfinishedTechniques returns the techniques of the unfinished results and of
+// the phases of the scenario, whose deferred phases may not have results yet
+func unfinishedTechniques(results []*entity.ExecutionResult, scenario *entity.Scenario) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, r := range results {
+		if !r.Status.IsTerminal() {
+			add(r.TechniqueID)
+		}
+	}
+	if scenario != nil {
+		for _, phase := range scenario.Phases {
+			for _, techID := range phase.Techniques {
+				add(techID)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+// stubDurationRepo serves fixed technique durations
+type stubDurationRepo struct {
+	durations map[string][]time.Duration
+}
+
+func (r *stubDurationRepo) FindRecentDurations(ctx context.Context, techniqueIDs []string, limit int) (map[string][]time.Duration, error) {
+	return r.durations, nil
+}
+
+func newProgressFixture() (*ExecutionService, *mockResultRepo) {
+	resultRepo := newMockResultRepo()
+	techRepo := newMockTechniqueRepo()
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{ID: "s1", Phases: []entity.Phase{{Name: "Discovery", Techniques: []string{"T1082", "T1083"}}}}
+	orchestrator := service.NewAttackOrchestrator(newMockAgentRepo(), techRepo, service.NewTechniqueValidator(), nil)
+
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", ScenarioID: "s1", Status: entity.ExecutionRunning, AgentPaws: []string{"a1"}}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", TechniqueID: "T1082", AgentPaw: "a1", Phase: "Discovery", Status: entity.StatusRunning},
+		{ID: "r2", ExecutionID: "e1", TechniqueID: "T1083", AgentPaw: "a1", Phase: "Discovery", Status: entity.StatusPending},
+	}
+
+	svc := NewExecutionService(resultRepo, scenarioRepo, techRepo, newMockAgentRepo(), orchestrator, service.NewScoreCalculator())
+	svc.SetDurationHistory(&stubDurationRepo{durations: map[string][]time.Duration{
+		"T1082": {10 * time.Second},
+		"T1083": {20 * time.Second},
+	}})
+	return svc, resultRepo
+}
+
+func TestGetExecution_Progress(t *testing.T) {
+	svc, _ := newProgressFixture()
+
+	execution, err := svc.GetExecution(context.Background(), "e1")
+	if err != nil {
+		t.Fatalf("GetExecution failed: %v", err)
+	}
+	progress := execution.Progress
+	if progress.Total != 2 || progress.Completed != 0 || progress.CurrentPhase != "Discovery" {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+	if progress.RemainingSeconds == nil || *progress.RemainingSeconds != 30 || progress.ETA == nil {
+		t.Errorf("Expected the median durations of both techniques left, got %+v", progress)
+	}
+}
+
+func TestUpdateResultByID_PublishesProgress(t *testing.T) {
+	svc, _ := newProgressFixture()
+	bus := NewEventBus(nil)
+	sink := &recordingSink{name: "test"}
+	bus.AddSink(sink)
+	svc.SetEventBus(bus)
+
+	if err := svc.UpdateResultByID(context.Background(), "r1", entity.StatusSuccess, "", 0, "a1"); err != nil {
+		t.Fatalf("UpdateResultByID failed: %v", err)
+	}
+
+	if len(sink.events) != 1 || sink.events[0].Type != entity.EventResultCompleted {
+		t.Fatalf("Expected a result event, got %v", sink.types())
+	}
+	execution := sink.events[0].Execution
+	if execution == nil || execution.Progress.Completed != 1 || execution.Progress.Percent != 50 || *execution.Progress.RemainingSeconds != 20 {
+		t.Errorf("Expected the result event to carry the progress, got %+v", execution)
+	}
+}
@@ -0,0 +1,141 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// DefaultTaskQueueTTL is how long a task waits for its offline agent by default
+const DefaultTaskQueueTTL = 24 * time.Hour
+
+// ErrTaskQueueDisabled is returned when a task is queued without a task queue
+var ErrTaskQueueDisabled = errors.New("task queue is not enabled")
+
+// SetTaskQueue queues the tasks dispatched to offline agents for up to ttl,
+// instead of failing them. Executions may then target offline agents.
+func (s *ExecutionService) SetTaskQueue(repo repository.TaskQueueRepository, ttl time.Duration) {
+	s.taskQueue = repo
+	s.taskQueueTTL = ttl
+}
+
+// QueueTask queues a task its agent could not be sent, to be delivered by
+// TakeQueuedTasks when the agent checks in. Its result becomes queued.
+func (s *ExecutionService) QueueTask(ctx context.Context, task TaskDispatchInfo) error {
+	if s.taskQueue == nil {
+		return ErrTaskQueueDisabled
+	}
+
+	result, err := s.resultRepo.FindResultByID(ctx, task.ResultID)
+	if err != nil {
+		return fmt.Errorf("result not found: %w", err)
+	}
+	if apply, err := checkResultTransition(result, entity.StatusQueued); !apply {
+		return err
+	}
+
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("failed to encode task: %w", err)
+	}
+	now := time.Now()
+	err = s.taskQueue.Enqueue(ctx, &entity.QueuedTask{
+		ResultID:    task.ResultID,
+		ExecutionID: result.ExecutionID,
+		AgentPaw:    task.AgentPaw,
+		Task:        encoded,
+		QueuedAt:    now,
+		ExpiresAt:   now.Add(s.taskQueueTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue task: %w", err)
+	}
+
+	result.Status = entity.StatusQueued
+	return s.resultRepo.UpdateResult(ctx, result)
+}
+
+// TakeQueuedTasks returns the tasks queued for an agent, oldest first, and
+// removes them from the queue. Their results are pending again. Expired tasks
+// are failed instead, and a task taken by a concurrent check-in is skipped.
+func (s *ExecutionService) TakeQueuedTasks(ctx context.Context, paw string) []TaskDispatchInfo {
+	if s.taskQueue == nil {
+		return nil
+	}
+	queued, err := s.taskQueue.FindByAgent(ctx, paw)
+	if err != nil || len(queued) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	tasks := make([]TaskDispatchInfo, 0, len(queued))
+	for _, q := range queued {
+		if taken, err := s.taskQueue.Delete(ctx, q.ResultID); err != nil || !taken {
+			continue
+		}
+		if q.IsExpired(now) {
+			s.failQueuedTask(ctx, q)
+			continue
+		}
+
+		var task TaskDispatchInfo
+		if err := json.Unmarshal(q.Task, &task); err != nil {
+			_ = s.UpdateResultByID(ctx, q.ResultID, entity.StatusFailed, "queued task is unreadable", -1, "")
+			continue
+		}
+		// The download link of a payload may have expired while the task waited
+		if task.Payload != nil {
+			payload, err := s.linkTaskPayload(ctx, task.Payload.Name, task.ResultID, paw)
+			if err != nil {
+				_ = s.UpdateResultByID(ctx, q.ResultID, entity.StatusFailed, fmt.Sprintf("payload %s unavailable: %v", task.Payload.Name, err), -1, "")
+				continue
+			}
+			task.Payload = payload
+		}
+
+		result, err := s.resultRepo.FindResultByID(ctx, q.ResultID)
+		if err != nil || result.Status != entity.StatusQueued {
+			continue
+		}
+		result.Status = entity.StatusPending
+		if err := s.resultRepo.UpdateResult(ctx, result); err != nil {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// ExpireQueuedTasks fails the queued tasks whose agent did not check in before
+// they expired, so their executions complete. Returns the number of tasks failed.
+func (s *ExecutionService) ExpireQueuedTasks(ctx context.Context) int {
+	if s.taskQueue == nil {
+		return 0
+	}
+	queued, err := s.taskQueue.FindExpired(ctx, time.Now())
+	if err != nil {
+		return 0
+	}
+
+	expired := 0
+	for _, q := range queued {
+		if taken, err := s.taskQueue.Delete(ctx, q.ResultID); err != nil || !taken {
+			continue
+		}
+		if s.failQueuedTask(ctx, q) {
+			expired++
+		}
+	}
+	return expired
+}
+
+// failQueuedTask fails the result of an expired queued task
+func (s *ExecutionService) failQueuedTask(ctx context.Context, q *entity.QueuedTask) bool {
+	reason := fmt.Sprintf("agent did not check in within %s of the task being queued", q.ExpiresAt.Sub(q.QueuedAt).Round(time.Second))
+	return s.UpdateResultByID(ctx, q.ResultID, entity.StatusFailed, reason, -1, "") == nil
+}
@@ -0,0 +1,174 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockTaskQueueRepo implements repository.TaskQueueRepository for tests
+type mockTaskQueueRepo struct {
+	tasks map[string]*entity.QueuedTask
+}
+
+func newMockTaskQueueRepo() *mockTaskQueueRepo {
+	return &mockTaskQueueRepo{tasks: make(map[string]*entity.QueuedTask)}
+}
+
+func (m *mockTaskQueueRepo) Enqueue(ctx context.Context, task *entity.QueuedTask) error {
+	m.tasks[task.ResultID] = task
+	return nil
+}
+
+func (m *mockTaskQueueRepo) Delete(ctx context.Context, resultID string) (bool, error) {
+	_, ok := m.tasks[resultID]
+	delete(m.tasks, resultID)
+	return ok, nil
+}
+
+func (m *mockTaskQueueRepo) DeleteByExecution(ctx context.Context, executionID string) error {
+	for id, task := range m.tasks {
+		if task.ExecutionID == executionID {
+			delete(m.tasks, id)
+		}
+	}
+	return nil
+}
+
+func (m *mockTaskQueueRepo) FindByAgent(ctx context.Context, paw string) ([]*entity.QueuedTask, error) {
+	var tasks []*entity.QueuedTask
+	for _, task := range m.tasks {
+		if task.AgentPaw == paw {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *mockTaskQueueRepo) FindExpired(ctx context.Context, now time.Time) ([]*entity.QueuedTask, error) {
+	var tasks []*entity.QueuedTask
+	for _, task := range m.tasks {
+		if task.IsExpired(now) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func newQueueTestService(t *testing.T) (*ExecutionService, *mockResultRepo, *mockTaskQueueRepo) {
+	t.Helper()
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", AgentPaw: "paw1", TechniqueID: "T1059", Status: entity.StatusPending},
+		{ID: "r2", ExecutionID: "e1", AgentPaw: "paw1", TechniqueID: "T1082", Status: entity.StatusPending},
+	}
+	queue := newMockTaskQueueRepo()
+	svc := newResumeTestService(resultRepo)
+	svc.SetTaskQueue(queue, time.Hour)
+	return svc, resultRepo, queue
+}
+
+func TestQueueTask_Disabled(t *testing.T) {
+	svc := newResumeTestService(newMockResultRepo())
+	if err := svc.QueueTask(context.Background(), TaskDispatchInfo{ResultID: "r1"}); !errors.Is(err, ErrTaskQueueDisabled) {
+		t.Errorf("Expected ErrTaskQueueDisabled, got %v", err)
+	}
+	if tasks := svc.TakeQueuedTasks(context.Background(), "paw1"); tasks != nil {
+		t.Errorf("Expected no tasks, got %v", tasks)
+	}
+}
+
+func TestQueueTask_DeliveredOnCheckIn(t *testing.T) {
+	svc, resultRepo, queue := newQueueTestService(t)
+	ctx := context.Background()
+
+	task := TaskDispatchInfo{ResultID: "r1", AgentPaw: "paw1", TechniqueID: "T1059", Command: "whoami", Executor: "sh", Timeout: 30}
+	if err := svc.QueueTask(ctx, task); err != nil {
+		t.Fatalf("QueueTask failed: %v", err)
+	}
+	result := resultRepo.results["e1"][0]
+	if result.Status != entity.StatusQueued || queue.tasks["r1"] == nil || queue.tasks["r1"].ExecutionID != "e1" {
+		t.Fatalf("Expected r1 to be queued, got %s", result.Status)
+	}
+
+	// A queued result keeps its execution running
+	_ = svc.UpdateResultByID(ctx, "r2", entity.StatusSuccess, "", 0, "")
+	if resultRepo.executions["e1"].Status != entity.ExecutionRunning {
+		t.Error("Expected the execution to wait for the queued task")
+	}
+
+	tasks := svc.TakeQueuedTasks(ctx, "paw1")
+	if len(tasks) != 1 || tasks[0].Command != "whoami" || tasks[0].Timeout != 30 {
+		t.Fatalf("Expected the queued task, got %+v", tasks)
+	}
+	if result.Status != entity.StatusPending || len(queue.tasks) != 0 {
+		t.Errorf("Expected r1 to be pending and dequeued, got %s", result.Status)
+	}
+	if tasks := svc.TakeQueuedTasks(ctx, "paw1"); len(tasks) != 0 {
+		t.Errorf("Expected a task to be delivered once, got %d", len(tasks))
+	}
+
+	// A completed result cannot be queued again
+	_ = svc.UpdateResultByID(ctx, "r1", entity.StatusSuccess, "", 0, "")
+	if err := svc.QueueTask(ctx, task); !errors.Is(err, entity.ErrResultTransition) {
+		t.Errorf("Expected ErrResultTransition, got %v", err)
+	}
+}
+
+func TestExpireQueuedTasks(t *testing.T) {
+	svc, resultRepo, queue := newQueueTestService(t)
+	ctx := context.Background()
+
+	_ = svc.QueueTask(ctx, TaskDispatchInfo{ResultID: "r1", AgentPaw: "paw1"})
+	_ = svc.QueueTask(ctx, TaskDispatchInfo{ResultID: "r2", AgentPaw: "paw1"})
+	queue.tasks["r1"].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if n := svc.ExpireQueuedTasks(ctx); n != 1 {
+		t.Fatalf("Expected 1 expired task, got %d", n)
+	}
+	r1, r2 := resultRepo.results["e1"][0], resultRepo.results["e1"][1]
+	if r1.Status != entity.StatusFailed || !strings.Contains(r1.Output, "did not check in") {
+		t.Errorf("Expected r1 to fail, got %s (%s)", r1.Status, r1.Output)
+	}
+	if r2.Status != entity.StatusQueued || queue.tasks["r2"] == nil {
+		t.Errorf("Expected r2 to stay queued, got %s", r2.Status)
+	}
+
+	// Expired tasks are failed rather than delivered
+	queue.tasks["r2"].ExpiresAt = time.Now()
+	if tasks := svc.TakeQueuedTasks(ctx, "paw1"); len(tasks) != 0 {
+		t.Errorf("Expected no delivery of an expired task, got %d", len(tasks))
+	}
+	if r2.Status != entity.StatusFailed || resultRepo.executions["e1"].Status != entity.ExecutionCompleted {
+		t.Errorf("Expected r2 to fail and the execution to complete, got %s and %s", r2.Status, resultRepo.executions["e1"].Status)
+	}
+}
+
+func TestCancelExecution_DropsQueuedTasks(t *testing.T) {
+	svc, resultRepo, queue := newQueueTestService(t)
+	ctx := context.Background()
+
+	_ = svc.QueueTask(ctx, TaskDispatchInfo{ResultID: "r1", AgentPaw: "paw1"})
+	if err := svc.CancelExecution(ctx, "e1"); err != nil {
+		t.Fatalf("CancelExecution failed: %v", err)
+	}
+	if len(queue.tasks) != 0 || resultRepo.results["e1"][0].Status != entity.StatusSkipped {
+		t.Errorf("Expected the queued task to be dropped and skipped, got %s", resultRepo.results["e1"][0].Status)
+	}
+}
+
+func TestStartExecution_OfflineAgentWithTaskQueue(t *testing.T) {
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{Paw: "paw1", Status: entity.AgentOffline}
+	svc := &ExecutionService{agentRepo: agentRepo}
+	svc.SetTaskQueue(newMockTaskQueueRepo(), time.Hour)
+
+	if _, err := svc.loadAndValidateAgents(context.Background(), []string{"paw1"}); err != nil {
+		t.Errorf("Expected an offline agent to be accepted with a task queue, got %v", err)
+	}
+}
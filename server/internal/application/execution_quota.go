@@ -0,0 +1,183 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultExecutionQuotaWindow is the period execution quotas are counted over
+const DefaultExecutionQuotaWindow = time.Hour
+
+// Execution quota scopes
+const (
+	QuotaScopeUser      = "user"
+	QuotaScopeAPIKey    = "api_key"
+	QuotaScopeWorkspace = "workspace"
+	QuotaScopeServer    = "server"
+)
+
+// ErrExecutionQuotaExceeded is matched by every QuotaExceededError
+var ErrExecutionQuotaExceeded = errors.New("execution quota exceeded")
+
+// QuotaExceededError reports the quota an execution launch went over and when
+// a new execution can start
+type QuotaExceededError struct {
+	Scope   string // One of the QuotaScope constants
+	Limit   int
+	Window  time.Duration
+	ResetAt time.Time // The oldest counted execution leaves the window
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("execution quota exceeded: %d executions per %s per %s, retry after %s",
+		e.Limit, e.Window, e.Scope, e.ResetAt.UTC().Format(time.RFC3339))
+}
+
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrExecutionQuotaExceeded
+}
+
+// ExecutionLauncher identifies who starts an execution. Schedules launch
+// executions with an empty launcher.
+type ExecutionLauncher struct {
+	UserID    string
+	APIKeyID  string // Set when the user authenticated with an API key
+	Workspace string // Workspace of the user, if any
+}
+
+// ExecutionQuotaConfig holds the execution limits per window. A zero limit
+// disables its scope; a zero window uses DefaultExecutionQuotaWindow.
+type ExecutionQuotaConfig struct {
+	PerUser      int
+	PerAPIKey    int
+	PerWorkspace int
+	Total        int // Executions started on the server, by users and schedules
+	Window       time.Duration
+}
+
+// QuotaReservation is an execution counted by ExecutionQuota.Reserve, which
+// ExecutionQuota.Release gives back
+type QuotaReservation struct {
+	id      uint64
+	windows []string // Keys of the windows the execution is counted in
+}
+
+// quotaEntry is an execution counted in a window
+type quotaEntry struct {
+	id uint64
+	at time.Time
+}
+
+// ExecutionQuota limits the executions started per user, API key, workspace
+// and on the whole server within a sliding window, so a script or a hurried
+// operator cannot flood production agents. Counts are kept in memory and start
+// over when the server restarts.
+type ExecutionQuota struct {
+	config ExecutionQuotaConfig
+
+	mu      sync.Mutex
+	nextID  uint64
+	windows map[string][]quotaEntry // Executions within the window, by scope and key
+}
+
+// NewExecutionQuota creates an execution quota
+func NewExecutionQuota(config ExecutionQuotaConfig) *ExecutionQuota {
+	if config.Window <= 0 {
+		config.Window = DefaultExecutionQuotaWindow
+	}
+	return &ExecutionQuota{config: config, windows: make(map[string][]quotaEntry)}
+}
+
+// quotaWindow is a window an execution is counted in
+type quotaWindow struct {
+	scope string
+	key   string
+	limit int
+}
+
+// Reserve counts an execution started by launcher at now, or returns a
+// *QuotaExceededError when a quota is used up. The execution counts in every
+// scope it belongs to, or in none.
+func (q *ExecutionQuota) Reserve(launcher ExecutionLauncher, now time.Time) (*QuotaReservation, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var windows []quotaWindow
+	if q.config.Total > 0 {
+		windows = append(windows, quotaWindow{QuotaScopeServer, "", q.config.Total})
+	}
+	if q.config.PerWorkspace > 0 && launcher.Workspace != "" {
+		windows = append(windows, quotaWindow{QuotaScopeWorkspace, launcher.Workspace, q.config.PerWorkspace})
+	}
+	if q.config.PerUser > 0 && launcher.UserID != "" {
+		windows = append(windows, quotaWindow{QuotaScopeUser, launcher.UserID, q.config.PerUser})
+	}
+	if q.config.PerAPIKey > 0 && launcher.APIKeyID != "" {
+		windows = append(windows, quotaWindow{QuotaScopeAPIKey, launcher.APIKeyID, q.config.PerAPIKey})
+	}
+
+	for _, w := range windows {
+		k := w.scope + ":" + w.key
+		entries := q.prune(q.windows[k], now)
+		q.windows[k] = entries
+		if len(entries) >= w.limit {
+			return nil, q.exceeded(w.scope, w.limit, entries)
+		}
+	}
+
+	q.nextID++
+	reservation := &QuotaReservation{id: q.nextID}
+	for _, w := range windows {
+		k := w.scope + ":" + w.key
+		q.windows[k] = append(q.windows[k], quotaEntry{id: reservation.id, at: now})
+		reservation.windows = append(reservation.windows, k)
+	}
+	return reservation, nil
+}
+
+// Release gives back a reservation, for an execution that could not be
+// created. Concurrent launches keep their own reservations.
+func (q *ExecutionQuota) Release(reservation *QuotaReservation) {
+	if reservation == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, k := range reservation.windows {
+		entries := q.windows[k]
+		for i, entry := range entries {
+			if entry.id == reservation.id {
+				q.windows[k] = append(entries[:i:i], entries[i+1:]...)
+				break
+			}
+		}
+		if len(q.windows[k]) == 0 {
+			delete(q.windows, k)
+		}
+	}
+}
+
+// prune drops the executions that left the window
+func (q *ExecutionQuota) prune(entries []quotaEntry, now time.Time) []quotaEntry {
+	cutoff := now.Add(-q.config.Window)
+	kept := entries[:0:0]
+	for _, entry := range entries {
+		if entry.at.After(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+func (q *ExecutionQuota) exceeded(scope string, limit int, entries []quotaEntry) error {
+	oldest := entries[0].at
+	for _, entry := range entries[1:] {
+		if entry.at.Before(oldest) {
+			oldest = entry.at
+		}
+	}
+	return &QuotaExceededError{Scope: scope, Limit: limit, Window: q.config.Window, ResetAt: oldest.Add(q.config.Window)}
+}
@@ -0,0 +1,141 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecutionQuota_PerUser(t *testing.T) {
+	quota := NewExecutionQuota(ExecutionQuotaConfig{PerUser: 2, Window: time.Hour})
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	user1 := ExecutionLauncher{UserID: "user-1"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := quota.Reserve(user1, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Reserve %d failed: %v", i, err)
+		}
+	}
+	_, err := quota.Reserve(user1, start.Add(10*time.Minute))
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) || !errors.Is(err, ErrExecutionQuotaExceeded) {
+		t.Fatalf("Expected a QuotaExceededError, got %v", err)
+	}
+	if quotaErr.Scope != QuotaScopeUser || quotaErr.Limit != 2 || !quotaErr.ResetAt.Equal(start.Add(time.Hour)) {
+		t.Errorf("Unexpected error %+v", quotaErr)
+	}
+
+	// Other users and launches without a user are not limited
+	if _, err := quota.Reserve(ExecutionLauncher{UserID: "user-2"}, start); err != nil {
+		t.Errorf("Expected another user allowed, got %v", err)
+	}
+	if _, err := quota.Reserve(ExecutionLauncher{}, start); err != nil {
+		t.Errorf("Expected a schedule allowed, got %v", err)
+	}
+
+	// The oldest start leaves the window after an hour
+	if _, err := quota.Reserve(user1, start.Add(time.Hour)); err != nil {
+		t.Errorf("Expected a slot after the window, got %v", err)
+	}
+}
+
+func TestExecutionQuota_APIKeyAndWorkspace(t *testing.T) {
+	quota := NewExecutionQuota(ExecutionQuotaConfig{PerAPIKey: 1, PerWorkspace: 2})
+	now := time.Now()
+	ci := ExecutionLauncher{UserID: "user-1", APIKeyID: "key-1", Workspace: "red"}
+
+	if _, err := quota.Reserve(ci, now); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	var quotaErr *QuotaExceededError
+	if _, err := quota.Reserve(ci, now); !errors.As(err, &quotaErr) || quotaErr.Scope != QuotaScopeAPIKey {
+		t.Fatalf("Expected the API key quota exceeded, got %v", err)
+	}
+
+	// The same user without the key shares the workspace quota only
+	if _, err := quota.Reserve(ExecutionLauncher{UserID: "user-1", Workspace: "red"}, now); err != nil {
+		t.Fatalf("Expected the user allowed without the key, got %v", err)
+	}
+	if _, err := quota.Reserve(ExecutionLauncher{UserID: "user-2", Workspace: "red"}, now); !errors.As(err, &quotaErr) || quotaErr.Scope != QuotaScopeWorkspace {
+		t.Fatalf("Expected the workspace quota exceeded, got %v", err)
+	}
+	if _, err := quota.Reserve(ExecutionLauncher{UserID: "user-2", Workspace: "blue"}, now); err != nil {
+		t.Errorf("Expected another workspace allowed, got %v", err)
+	}
+}
+
+func TestExecutionQuota_Server(t *testing.T) {
+	quota := NewExecutionQuota(ExecutionQuotaConfig{Total: 2})
+	if quota.config.Window != DefaultExecutionQuotaWindow {
+		t.Errorf("Expected the default window, got %s", quota.config.Window)
+	}
+	now := time.Now()
+
+	_, _ = quota.Reserve(ExecutionLauncher{UserID: "user-1"}, now)
+	reservation, _ := quota.Reserve(ExecutionLauncher{}, now)
+	var quotaErr *QuotaExceededError
+	if _, err := quota.Reserve(ExecutionLauncher{UserID: "user-2"}, now); !errors.As(err, &quotaErr) || quotaErr.Scope != QuotaScopeServer {
+		t.Fatalf("Expected the server quota exceeded, got %v", err)
+	}
+
+	quota.Release(reservation)
+	if _, err := quota.Reserve(ExecutionLauncher{UserID: "user-2"}, now); err != nil {
+		t.Errorf("Expected a released slot reused, got %v", err)
+	}
+}
+
+func TestExecutionQuota_ReleaseExactReservation(t *testing.T) {
+	quota := NewExecutionQuota(ExecutionQuotaConfig{PerUser: 2, Window: time.Hour})
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	user := ExecutionLauncher{UserID: "user-1"}
+
+	// A launch fails after a later one was counted: releasing it must not
+	// give back the later start, which would move the reset time forward
+	failed, _ := quota.Reserve(user, start)
+	if _, err := quota.Reserve(user, start.Add(30*time.Minute)); err != nil {
+		t.Fatalf("Reserve failed: %v", err)
+	}
+	quota.Release(failed)
+	quota.Release(failed) // Releasing twice is harmless
+
+	entries := quota.windows[QuotaScopeUser+":user-1"]
+	if len(entries) != 1 || !entries[0].at.Equal(start.Add(30*time.Minute)) {
+		t.Fatalf("Expected the later reservation kept, got %v", entries)
+	}
+	if _, err := quota.Reserve(user, start.Add(31*time.Minute)); err != nil {
+		t.Fatalf("Expected the released slot reused, got %v", err)
+	}
+	var quotaErr *QuotaExceededError
+	if _, err := quota.Reserve(user, start.Add(32*time.Minute)); !errors.As(err, &quotaErr) ||
+		!quotaErr.ResetAt.Equal(start.Add(90*time.Minute)) {
+		t.Errorf("Expected a reset at the oldest kept start, got %v", err)
+	}
+}
+
+func TestExecutionService_StartExecutionQuota(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	svc.SetExecutionQuota(NewExecutionQuota(ExecutionQuotaConfig{PerUser: 1, Window: time.Hour}))
+	ctx := context.Background()
+
+	started, err := svc.StartExecutionWithProfile(ctx, "s1", []string{"paw1"}, false, "", ExecutionLauncher{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("StartExecutionWithProfile failed: %v", err)
+	}
+	if started.Execution.StartedBy != "user-1" {
+		t.Errorf("Expected StartedBy user-1, got %q", started.Execution.StartedBy)
+	}
+	if _, err := svc.StartExecutionWithProfile(ctx, "s1", []string{"paw1"}, false, "", ExecutionLauncher{UserID: "user-1"}); !errors.Is(err, ErrExecutionQuotaExceeded) {
+		t.Errorf("Expected ErrExecutionQuotaExceeded, got %v", err)
+	}
+
+	// A launch that fails to be stored gives its slot back
+	resultRepo.err = errors.New("db error")
+	if _, err := svc.StartExecutionWithProfile(ctx, "s1", []string{"paw1"}, false, "", ExecutionLauncher{UserID: "user-2"}); err == nil {
+		t.Fatal("Expected the launch to fail")
+	}
+	if entries := svc.quota.windows[QuotaScopeUser+":user-2"]; len(entries) != 0 {
+		t.Errorf("Expected the reservation released, got %v", entries)
+	}
+}
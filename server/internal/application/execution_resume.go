@@ -0,0 +1,218 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+// resumedResult is an unanswered result of a resumed execution, waiting for its agent
+type resumedResult struct {
+	result   *entity.ExecutionResult
+	safeMode bool
+}
+
+// DrainExecutions waits until no execution is running, checking every interval,
+// or until ctx is done. Used on shutdown to let agents report in-flight tasks.
+func (s *ExecutionService) DrainExecutions(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		running, err := s.resultRepo.FindExecutionsByStatus(ctx, entity.ExecutionRunning)
+		if err != nil {
+			return fmt.Errorf("failed to find running executions: %w", err)
+		}
+		if len(running) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// InterruptRunningExecutions marks the pending and running executions as
+// interrupted, once the server stops dispatching. Their unanswered results keep
+// their status: they are the state from which the execution can be resumed.
+func (s *ExecutionService) InterruptRunningExecutions(ctx context.Context) ([]*entity.Execution, error) {
+	var interrupted []*entity.Execution
+	for _, status := range []entity.ExecutionStatus{entity.ExecutionPending, entity.ExecutionRunning} {
+		executions, err := s.resultRepo.FindExecutionsByStatus(ctx, status)
+		if err != nil {
+			return interrupted, fmt.Errorf("failed to find %s executions: %w", status, err)
+		}
+
+		for _, execution := range executions {
+			now := time.Now()
+			execution.Status = entity.ExecutionInterrupted
+			execution.CompletedAt = &now
+			if err := s.resultRepo.UpdateExecution(ctx, execution); err != nil {
+				return interrupted, fmt.Errorf("failed to interrupt execution %s: %w", execution.ID, err)
+			}
+			interrupted = append(interrupted, execution)
+
+			s.events.Publish(ctx, &entity.Event{
+				Type:         entity.EventExecutionInterrupted,
+				Execution:    execution,
+				ScenarioName: s.scenarioName(ctx, execution.ScenarioID),
+			})
+		}
+	}
+	return interrupted, nil
+}
+
+// ResumeInterruptedExecutions puts interrupted executions back to running and
+// queues their unanswered results for their agents, to be re-dispatched by
+// TakeResumedTasks when each agent reconnects. An interrupted execution whose
+// results were all answered is completed. Returns the number of executions resumed.
+func (s *ExecutionService) ResumeInterruptedExecutions(ctx context.Context) (int, error) {
+	executions, err := s.resultRepo.FindExecutionsByStatus(ctx, entity.ExecutionInterrupted)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find interrupted executions: %w", err)
+	}
+
+	for i, execution := range executions {
+		results, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+		if err != nil {
+			return i, fmt.Errorf("failed to get results of execution %s: %w", execution.ID, err)
+		}
+
+		execution.Status = entity.ExecutionRunning
+		execution.CompletedAt = nil
+		if err := s.resultRepo.UpdateExecution(ctx, execution); err != nil {
+			return i, fmt.Errorf("failed to resume execution %s: %w", execution.ID, err)
+		}
+
+		s.resumeMu.Lock()
+		if s.resumed == nil {
+			s.resumed = make(map[string][]resumedResult)
+		}
+		paws := make(map[string]bool)
+		for _, result := range results {
+			paws[result.AgentPaw] = true
+			// Queued tasks are persisted, and delivered from the task queue
+			if !result.Status.IsTerminal() && result.Status != entity.StatusQueued {
+				s.resumed[result.AgentPaw] = append(s.resumed[result.AgentPaw], resumedResult{result: result, safeMode: execution.SafeMode})
+			}
+		}
+		s.resumeMu.Unlock()
+
+		// Decide the deferred phases that were reached before the interruption
+		for paw := range paws {
+			if err := s.advanceDeferredPhases(ctx, execution.ID, paw); err != nil {
+				return i, err
+			}
+		}
+
+		if err := s.checkAndCompleteExecution(ctx, execution.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(executions), nil
+}
+
+// RecoverExecutions handles the executions left pending or running by a server
+// that stopped without draining them, e.g. after a crash. Called on startup,
+// before agents reconnect: the executions are interrupted, then resumed along
+// with the ones interrupted by a graceful shutdown when resume is set and the
+// emergency stop is not engaged. Returns the number of stuck executions and of
+// resumed executions.
+func (s *ExecutionService) RecoverExecutions(ctx context.Context, resume bool) (int, int, error) {
+	stuck, err := s.InterruptRunningExecutions(ctx)
+	if err != nil {
+		return len(stuck), 0, err
+	}
+	if !resume || s.emergencyStop.Check() != nil {
+		return len(stuck), 0, nil
+	}
+	resumed, err := s.ResumeInterruptedExecutions(ctx)
+	return len(stuck), resumed, err
+}
+
+// ExpireResumedTasks fails the tasks of resumed executions whose agent did not
+// reconnect, so their executions complete and get scored. Returns the number
+// of tasks failed.
+func (s *ExecutionService) ExpireResumedTasks(ctx context.Context) int {
+	s.resumeMu.Lock()
+	waiting := s.resumed
+	s.resumed = nil
+	s.resumeMu.Unlock()
+
+	expired := 0
+	for _, results := range waiting {
+		for _, w := range results {
+			if err := s.UpdateResultByID(ctx, w.result.ID, entity.StatusFailed, "agent did not reconnect after server restart", -1, ""); err == nil {
+				expired++
+			}
+		}
+	}
+	return expired
+}
+
+// TakeResumedTasks returns the unanswered tasks of resumed executions waiting
+// for an agent, planned again for the agent's current platform and executors.
+// Each task is returned once. Results whose technique can no longer run on the
+// agent are failed.
+func (s *ExecutionService) TakeResumedTasks(ctx context.Context, paw string) []TaskDispatchInfo {
+	s.resumeMu.Lock()
+	waiting := s.resumed[paw]
+	delete(s.resumed, paw)
+	s.resumeMu.Unlock()
+	if len(waiting) == 0 {
+		return nil
+	}
+
+	agent, err := s.agentRepo.FindByPaw(ctx, paw)
+
+	tasks := make([]TaskDispatchInfo, 0, len(waiting))
+	for _, w := range waiting {
+		var task *service.PlannedTask
+		if err == nil && agent != nil {
+			// The executor the task was planned with is kept when the agent still has it
+			task = s.orchestrator.ReplanTask(ctx, w.result.TechniqueID, agent, w.safeMode, resultExecutors(w.result))
+		}
+		if task == nil {
+			_ = s.UpdateResultByID(ctx, w.result.ID, entity.StatusFailed, "technique cannot be resumed on this agent", -1, "")
+			continue
+		}
+		if task.SkipStatus != "" {
+			_ = s.UpdateResultByID(ctx, w.result.ID, task.SkipStatus, task.SkipReason, -1, "")
+			continue
+		}
+
+		var missing []string
+		facts := s.agentFacts(ctx, w.result.ExecutionID, paw)
+		task.Command, task.Cleanup, missing = substituteTaskFacts(task.Command, task.Cleanup, facts)
+		if len(missing) > 0 {
+			_ = s.UpdateResultByID(ctx, w.result.ID, entity.StatusFailed, "missing facts: "+strings.Join(missing, ", "), -1, "")
+			continue
+		}
+		payload, linkErr := s.linkTaskPayload(ctx, task.Payload, w.result.ID, paw)
+		if linkErr != nil {
+			_ = s.UpdateResultByID(ctx, w.result.ID, entity.StatusFailed, fmt.Sprintf("payload %s unavailable: %v", task.Payload, linkErr), -1, "")
+			continue
+		}
+
+		tasks = append(tasks, TaskDispatchInfo{
+			ResultID:    w.result.ID,
+			AgentPaw:    paw,
+			TechniqueID: w.result.TechniqueID,
+			Command:     task.Command,
+			Executor:    task.Executor,
+			Timeout:     task.Timeout,
+			Cleanup:     task.Cleanup,
+			Limits:      task.Limits,
+			Payload:     payload,
+			Collect:     task.Collect,
+		})
+	}
+	return tasks
+}
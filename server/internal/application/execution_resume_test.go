@@ -0,0 +1,248 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+func newResumeTestService(resultRepo *mockResultRepo) *ExecutionService {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{
+		ID:        "T1059",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "echo test"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{
+		Paw:       "paw1",
+		Status:    entity.AgentOnline,
+		Platform:  "linux",
+		Executors: []string{"sh"},
+		LastSeen:  time.Now(),
+	}
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	return NewExecutionService(resultRepo, newMockScenarioRepo(), techRepo, agentRepo, orchestrator, service.NewScoreCalculator())
+}
+
+func TestDrainExecutions_ReturnsWhenNoneRunning(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionCompleted}
+	svc := newResumeTestService(resultRepo)
+
+	if err := svc.DrainExecutions(context.Background(), time.Millisecond); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestDrainExecutions_StopsAtDeadline(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	svc := newResumeTestService(resultRepo)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := svc.DrainExecutions(ctx, 5*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestInterruptRunningExecutions(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	resultRepo.executions["e2"] = &entity.Execution{ID: "e2", Status: entity.ExecutionPending}
+	resultRepo.executions["e3"] = &entity.Execution{ID: "e3", Status: entity.ExecutionCompleted}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", Status: entity.StatusRunning},
+	}
+	svc := newResumeTestService(resultRepo)
+
+	interrupted, err := svc.InterruptRunningExecutions(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(interrupted) != 2 {
+		t.Fatalf("Expected 2 interrupted executions, got %d", len(interrupted))
+	}
+	for _, id := range []string{"e1", "e2"} {
+		exec := resultRepo.executions[id]
+		if exec.Status != entity.ExecutionInterrupted || exec.CompletedAt == nil {
+			t.Errorf("Expected %s to be interrupted, got %v", id, exec.Status)
+		}
+	}
+	if resultRepo.executions["e3"].Status != entity.ExecutionCompleted {
+		t.Error("Expected completed execution to be left alone")
+	}
+	if resultRepo.results["e1"][0].Status != entity.StatusRunning {
+		t.Error("Expected unanswered result to keep its status")
+	}
+}
+
+func TestInterruptRunningExecutions_RepoError(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.err = errors.New("db error")
+	svc := newResumeTestService(resultRepo)
+
+	if _, err := svc.InterruptRunningExecutions(context.Background()); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestResumeInterruptedExecutions(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	completedAt := time.Now()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionInterrupted, CompletedAt: &completedAt}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", TechniqueID: "T1059", AgentPaw: "paw1", Status: entity.StatusSuccess},
+		{ID: "r2", ExecutionID: "e1", TechniqueID: "T1059", AgentPaw: "paw1", Status: entity.StatusRunning},
+	}
+	svc := newResumeTestService(resultRepo)
+
+	n, err := svc.ResumeInterruptedExecutions(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 resumed execution, got %d", n)
+	}
+	exec := resultRepo.executions["e1"]
+	if exec.Status != entity.ExecutionRunning || exec.CompletedAt != nil {
+		t.Errorf("Expected execution to be running again, got %v", exec.Status)
+	}
+
+	tasks := svc.TakeResumedTasks(context.Background(), "paw1")
+	if len(tasks) != 1 {
+		t.Fatalf("Expected 1 resumed task, got %d", len(tasks))
+	}
+	if tasks[0].ResultID != "r2" || tasks[0].Command != "echo test" || tasks[0].Executor != "sh" {
+		t.Errorf("Unexpected resumed task: %+v", tasks[0])
+	}
+	if again := svc.TakeResumedTasks(context.Background(), "paw1"); len(again) != 0 {
+		t.Errorf("Expected resumed tasks to be taken once, got %d", len(again))
+	}
+}
+
+func TestResumeInterruptedExecutions_CompletesAnswered(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionInterrupted}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", TechniqueID: "T1059", AgentPaw: "paw1", Status: entity.StatusSuccess},
+	}
+	svc := newResumeTestService(resultRepo)
+
+	if _, err := svc.ResumeInterruptedExecutions(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resultRepo.executions["e1"].Status != entity.ExecutionCompleted {
+		t.Errorf("Expected execution to be completed, got %v", resultRepo.executions["e1"].Status)
+	}
+	if tasks := svc.TakeResumedTasks(context.Background(), "paw1"); len(tasks) != 0 {
+		t.Errorf("Expected no resumed task, got %d", len(tasks))
+	}
+}
+
+func TestTakeResumedTasks_FailsUnplannableResult(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionInterrupted}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", TechniqueID: "T9999", AgentPaw: "paw1", Status: entity.StatusPending},
+	}
+	svc := newResumeTestService(resultRepo)
+
+	if _, err := svc.ResumeInterruptedExecutions(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tasks := svc.TakeResumedTasks(context.Background(), "paw1"); len(tasks) != 0 {
+		t.Errorf("Expected no resumed task, got %d", len(tasks))
+	}
+	if resultRepo.results["e1"][0].Status != entity.StatusFailed {
+		t.Errorf("Expected result to be failed, got %v", resultRepo.results["e1"][0].Status)
+	}
+	if resultRepo.executions["e1"].Status != entity.ExecutionCompleted {
+		t.Errorf("Expected execution to be completed, got %v", resultRepo.executions["e1"].Status)
+	}
+}
+
+func TestCancelExecution_Interrupted(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionInterrupted}
+	svc := newResumeTestService(resultRepo)
+
+	if err := svc.CancelExecution(context.Background(), "e1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resultRepo.executions["e1"].Status != entity.ExecutionCancelled {
+		t.Errorf("Expected execution to be cancelled, got %v", resultRepo.executions["e1"].Status)
+	}
+}
+
+func TestRecoverExecutions(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", TechniqueID: "T1059", AgentPaw: "paw1", Status: entity.StatusRunning},
+	}
+	resultRepo.executions["e2"] = &entity.Execution{ID: "e2", Status: entity.ExecutionInterrupted}
+	resultRepo.results["e2"] = []*entity.ExecutionResult{
+		{ID: "r2", ExecutionID: "e2", TechniqueID: "T1059", AgentPaw: "paw1", Status: entity.StatusPending},
+	}
+	svc := newResumeTestService(resultRepo)
+
+	stuck, resumed, err := svc.RecoverExecutions(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stuck != 1 || resumed != 2 {
+		t.Errorf("Expected 1 stuck and 2 resumed executions, got %d and %d", stuck, resumed)
+	}
+	if tasks := svc.TakeResumedTasks(context.Background(), "paw1"); len(tasks) != 2 {
+		t.Errorf("Expected 2 resumed tasks, got %d", len(tasks))
+	}
+}
+
+func TestRecoverExecutions_WithoutResume(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	svc := newResumeTestService(resultRepo)
+
+	stuck, resumed, err := svc.RecoverExecutions(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stuck != 1 || resumed != 0 {
+		t.Errorf("Expected 1 stuck and no resumed execution, got %d and %d", stuck, resumed)
+	}
+	if resultRepo.executions["e1"].Status != entity.ExecutionInterrupted {
+		t.Errorf("Expected execution to be interrupted, got %v", resultRepo.executions["e1"].Status)
+	}
+}
+
+func TestExpireResumedTasks(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionInterrupted}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", TechniqueID: "T1059", AgentPaw: "paw2", Status: entity.StatusRunning},
+	}
+	svc := newResumeTestService(resultRepo)
+
+	if _, err := svc.ResumeInterruptedExecutions(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n := svc.ExpireResumedTasks(context.Background()); n != 1 {
+		t.Errorf("Expected 1 expired task, got %d", n)
+	}
+	if resultRepo.results["e1"][0].Status != entity.StatusFailed {
+		t.Errorf("Expected result to be failed, got %v", resultRepo.results["e1"][0].Status)
+	}
+	exec := resultRepo.executions["e1"]
+	if exec.Status != entity.ExecutionCompleted || exec.Score == nil {
+		t.Errorf("Expected execution to be completed and scored, got %v", exec.Status)
+	}
+	if n := svc.ExpireResumedTasks(context.Background()); n != 0 {
+		t.Errorf("Expected nothing left to expire, got %d", n)
+	}
+}
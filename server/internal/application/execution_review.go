@@ -0,0 +1,286 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// Execution review errors
+var (
+	ErrReviewNotFound   = errors.New("review not found")
+	ErrInvalidReview    = errors.New("invalid review")
+	ErrReviewTransition = errors.New("invalid review transition")
+	ErrReviewLocked     = errors.New("the execution review is signed off")
+	ErrNotReviewer      = errors.New("only the assigned reviewer can do this")
+)
+
+// maxReviewNotes is the longest sign-off note accepted, in bytes
+const maxReviewNotes = 4000
+
+// ReviewSummary is an execution review with the progress of the dispositions
+type ReviewSummary struct {
+	*entity.ExecutionReview
+	Results       int `json:"results"`       // Results of the execution
+	Dispositioned int `json:"dispositioned"` // Results with a triage verdict
+}
+
+// ExecutionReviewService runs the purple-team review of executions: a review
+// is requested for a finished execution, the reviewer gives every result a
+// disposition through its annotation, then signs off, which freezes the
+// export of the execution and locks its annotations and evidence
+type ExecutionReviewService struct {
+	repo        repository.ExecutionReviewRepository
+	resultRepo  repository.ResultRepository
+	userRepo    repository.UserRepository
+	annotations *ResultAnnotationService
+	executions  *ExecutionService
+}
+
+// NewExecutionReviewService creates a new execution review service
+func NewExecutionReviewService(
+	repo repository.ExecutionReviewRepository,
+	resultRepo repository.ResultRepository,
+	userRepo repository.UserRepository,
+	annotations *ResultAnnotationService,
+	executions *ExecutionService,
+) *ExecutionReviewService {
+	return &ExecutionReviewService{
+		repo:        repo,
+		resultRepo:  resultRepo,
+		userRepo:    userRepo,
+		annotations: annotations,
+		executions:  executions,
+	}
+}
+
+// Get returns the review of an execution with its progress
+func (s *ExecutionReviewService) Get(ctx context.Context, executionID string) (*ReviewSummary, error) {
+	review, err := s.find(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(ctx, review)
+}
+
+// List returns up to limit reviews, newest request first, filtered by status
+// and reviewer when set
+func (s *ExecutionReviewService) List(ctx context.Context, status entity.ReviewStatus, reviewerID string, limit int) ([]*entity.ExecutionReview, error) {
+	if status != "" && !entity.IsValidReviewStatus(status) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidReview, status)
+	}
+	return s.repo.FindAll(ctx, status, reviewerID, limit)
+}
+
+// Request submits a finished execution for review, assigned to reviewerID
+// when set
+func (s *ExecutionReviewService) Request(ctx context.Context, executionID, reviewerID, requestedBy string) (*ReviewSummary, error) {
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil || execution == nil {
+		return nil, ErrExecutionNotFound
+	}
+	switch execution.Status {
+	case entity.ExecutionCompleted, entity.ExecutionFailed, entity.ExecutionCancelled:
+	default:
+		return nil, fmt.Errorf("%w: the execution is %s", ErrInvalidReview, execution.Status)
+	}
+
+	existing, err := s.repo.Get(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Locked() {
+		return nil, ErrReviewLocked
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: a review is already %s", ErrReviewTransition, existing.Status)
+	}
+	if err := s.checkReviewer(ctx, reviewerID); err != nil {
+		return nil, err
+	}
+
+	review := &entity.ExecutionReview{
+		ExecutionID: executionID,
+		Status:      entity.ReviewNeeded,
+		ReviewerID:  reviewerID,
+		RequestedBy: requestedBy,
+		RequestedAt: time.Now(),
+	}
+	if err := s.repo.Save(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+	return s.summary(ctx, review)
+}
+
+// Assign hands a review not signed off yet to another reviewer
+func (s *ExecutionReviewService) Assign(ctx context.Context, executionID, reviewerID string) (*ReviewSummary, error) {
+	review, err := s.find(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Locked() {
+		return nil, ErrReviewLocked
+	}
+	if reviewerID == "" {
+		return nil, fmt.Errorf("%w: a reviewer is required", ErrInvalidReview)
+	}
+	if err := s.checkReviewer(ctx, reviewerID); err != nil {
+		return nil, err
+	}
+
+	review.ReviewerID = reviewerID
+	if err := s.repo.Save(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+	return s.summary(ctx, review)
+}
+
+// Start moves a review in review. A review without reviewer is assigned to
+// the user starting it; otherwise only its reviewer can start it.
+func (s *ExecutionReviewService) Start(ctx context.Context, executionID, userID string) (*ReviewSummary, error) {
+	review, err := s.find(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.CanTransitionTo(entity.ReviewInReview) {
+		return nil, fmt.Errorf("%w: the review is %s", ErrReviewTransition, review.Status)
+	}
+	if review.ReviewerID != "" && review.ReviewerID != userID {
+		return nil, ErrNotReviewer
+	}
+
+	now := time.Now()
+	review.Status = entity.ReviewInReview
+	review.ReviewerID = userID
+	review.StartedAt = &now
+	if err := s.repo.Save(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+	return s.summary(ctx, review)
+}
+
+// SignOff approves a review once every result has a disposition. The export
+// of the execution is frozen in the review snapshot, and the annotations and
+// evidence of the execution can no longer change.
+func (s *ExecutionReviewService) SignOff(ctx context.Context, executionID, userID, notes string) (*ReviewSummary, error) {
+	notes = strings.TrimSpace(notes)
+	if len(notes) > maxReviewNotes {
+		return nil, fmt.Errorf("%w: the notes are longer than %d characters", ErrInvalidReview, maxReviewNotes)
+	}
+	review, err := s.find(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Status.CanTransitionTo(entity.ReviewSignedOff) {
+		return nil, fmt.Errorf("%w: the review is %s", ErrReviewTransition, review.Status)
+	}
+	if review.ReviewerID != userID {
+		return nil, ErrNotReviewer
+	}
+	summary, err := s.summary(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Dispositioned < summary.Results {
+		return nil, fmt.Errorf("%w: %d of %d results have no disposition", ErrInvalidReview,
+			summary.Results-summary.Dispositioned, summary.Results)
+	}
+
+	now := time.Now()
+	signed := *review
+	signed.Status = entity.ReviewSignedOff
+	signed.SignedOffBy = userID
+	signed.SignedOffAt = &now
+	signed.Notes = notes
+
+	export, err := s.executions.ExportExecution(ctx, executionID, VerbosityFull)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export execution: %w", err)
+	}
+	export.Review = &signed
+	if signed.Snapshot, err = json.Marshal(export); err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	if err := s.repo.Save(ctx, &signed); err != nil {
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+	summary.ExecutionReview = &signed
+	return summary, nil
+}
+
+// Snapshot returns the export of an execution frozen when its review was signed off
+func (s *ExecutionReviewService) Snapshot(ctx context.Context, executionID string) (json.RawMessage, error) {
+	review, err := s.find(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Locked() || len(review.Snapshot) == 0 {
+		return nil, fmt.Errorf("%w: the review is %s", ErrReviewNotFound, review.Status)
+	}
+	return review.Snapshot, nil
+}
+
+// checkLocked returns ErrReviewLocked when the review of an execution was signed off
+func (s *ExecutionReviewService) checkLocked(ctx context.Context, executionID string) error {
+	review, err := s.repo.Get(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to load review: %w", err)
+	}
+	if review.Locked() {
+		return ErrReviewLocked
+	}
+	return nil
+}
+
+// find loads the review of an execution, returning ErrReviewNotFound when there is none
+func (s *ExecutionReviewService) find(ctx context.Context, executionID string) (*entity.ExecutionReview, error) {
+	review, err := s.repo.Get(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+// checkReviewer checks that a reviewer, when set, is an active user allowed to triage results
+func (s *ExecutionReviewService) checkReviewer(ctx context.Context, reviewerID string) error {
+	if reviewerID == "" {
+		return nil
+	}
+	user, err := s.userRepo.FindByID(ctx, reviewerID)
+	if err != nil || user == nil || !user.IsActive {
+		return fmt.Errorf("%w: reviewer %s is not an active user", ErrInvalidReview, reviewerID)
+	}
+	if !entity.HasPermission(user.Role, entity.PermissionExecutionsTriage) {
+		return fmt.Errorf("%w: reviewer %s cannot triage results", ErrInvalidReview, reviewerID)
+	}
+	return nil
+}
+
+// summary counts the results of the reviewed execution and those with a disposition
+func (s *ExecutionReviewService) summary(ctx context.Context, review *entity.ExecutionReview) (*ReviewSummary, error) {
+	results, err := s.resultRepo.FindResultsByExecution(ctx, review.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := s.annotations.ByExecution(ctx, review.ExecutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ReviewSummary{ExecutionReview: review, Results: len(results)}
+	for _, result := range results {
+		if annotation := annotations[result.ID]; annotation != nil && annotation.Triage != entity.TriageNone {
+			summary.Dispositioned++
+		}
+	}
+	return summary, nil
+}
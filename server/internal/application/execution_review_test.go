@@ -0,0 +1,225 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+type mockExecutionReviewRepo struct {
+	reviews map[string]*entity.ExecutionReview
+	err     error
+}
+
+func newMockExecutionReviewRepo() *mockExecutionReviewRepo {
+	return &mockExecutionReviewRepo{reviews: make(map[string]*entity.ExecutionReview)}
+}
+
+func (m *mockExecutionReviewRepo) Get(ctx context.Context, executionID string) (*entity.ExecutionReview, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	review, ok := m.reviews[executionID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *review
+	return &copied, nil
+}
+
+func (m *mockExecutionReviewRepo) Save(ctx context.Context, review *entity.ExecutionReview) error {
+	if m.err != nil {
+		return m.err
+	}
+	copied := *review
+	m.reviews[review.ExecutionID] = &copied
+	return nil
+}
+
+func (m *mockExecutionReviewRepo) FindAll(ctx context.Context, status entity.ReviewStatus, reviewerID string, limit int) ([]*entity.ExecutionReview, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var reviews []*entity.ExecutionReview
+	for _, review := range m.reviews {
+		if (status == "" || review.Status == status) && (reviewerID == "" || review.ReviewerID == reviewerID) {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func setupReviewTest() (*ExecutionReviewService, *ResultAnnotationService, *mockExecutionReviewRepo) {
+	executions, resultRepo := setupExportTest()
+	resultRepo.executions["exec-1"].Status = entity.ExecutionCompleted
+	resultRepo.executions["exec-2"] = &entity.Execution{ID: "exec-2", Status: entity.ExecutionRunning}
+
+	userRepo := newMockUserRepo()
+	userRepo.users["analyst"] = &entity.User{ID: "analyst", Role: entity.RoleAnalyst, IsActive: true}
+	userRepo.users["lead"] = &entity.User{ID: "lead", Role: entity.RoleRSSI, IsActive: true}
+	userRepo.users["viewer"] = &entity.User{ID: "viewer", Role: entity.RoleViewer, IsActive: true}
+
+	annotations := NewResultAnnotationService(newMockResultAnnotationRepo(), resultRepo, userRepo)
+	executions.SetResultAnnotations(annotations)
+
+	repo := newMockExecutionReviewRepo()
+	reviews := NewExecutionReviewService(repo, resultRepo, userRepo, annotations, executions)
+	executions.SetExecutionReviews(reviews)
+	annotations.SetExecutionReviews(reviews)
+	return reviews, annotations, repo
+}
+
+func TestExecutionReviewService_Lifecycle(t *testing.T) {
+	svc, annotations, repo := setupReviewTest()
+	ctx := context.Background()
+
+	review, err := svc.Request(ctx, "exec-1", "", "operator")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if review.Status != entity.ReviewNeeded || review.RequestedBy != "operator" || review.Results != 3 || review.Dispositioned != 0 {
+		t.Errorf("Unexpected review: %+v", review)
+	}
+
+	if export, err := svc.executions.ExportExecution(ctx, "exec-1", VerbosityMinimal); err != nil ||
+		export.Review == nil || export.Review.Status != entity.ReviewNeeded {
+		t.Errorf("Expected the review in the export, got %+v (%v)", export, err)
+	}
+
+	review, err = svc.Start(ctx, "exec-1", "analyst")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if review.Status != entity.ReviewInReview || review.ReviewerID != "analyst" || review.StartedAt == nil {
+		t.Errorf("Expected the review started by its reviewer, got %+v", review)
+	}
+
+	// Every result needs a disposition, and only the reviewer signs off
+	if _, err := svc.SignOff(ctx, "exec-1", "analyst", ""); !errors.Is(err, ErrInvalidReview) {
+		t.Errorf("Expected ErrInvalidReview without dispositions, got %v", err)
+	}
+	for _, id := range []string{"r1", "r2", "r3"} {
+		if _, err := annotations.Triage(ctx, id, entity.TriageExpectedBlock, "", "analyst"); err != nil {
+			t.Fatalf("Triage failed: %v", err)
+		}
+	}
+	if _, err := svc.SignOff(ctx, "exec-1", "lead", ""); !errors.Is(err, ErrNotReviewer) {
+		t.Errorf("Expected ErrNotReviewer, got %v", err)
+	}
+
+	review, err = svc.SignOff(ctx, "exec-1", "analyst", "  All blocks confirmed with the SOC  ")
+	if err != nil {
+		t.Fatalf("SignOff failed: %v", err)
+	}
+	if review.Status != entity.ReviewSignedOff || review.SignedOffBy != "analyst" || review.SignedOffAt == nil ||
+		review.Notes != "All blocks confirmed with the SOC" || review.Dispositioned != 3 {
+		t.Errorf("Unexpected signed-off review: %+v", review)
+	}
+	if repo.reviews["exec-1"].Status != entity.ReviewSignedOff {
+		t.Errorf("Expected the review saved, got %+v", repo.reviews["exec-1"])
+	}
+
+	// The snapshot holds the export with the annotations and the review
+	raw, err := svc.Snapshot(ctx, "exec-1")
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	var snapshot ExecutionExport
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		t.Fatalf("Invalid snapshot: %v", err)
+	}
+	if snapshot.Review == nil || snapshot.Review.Status != entity.ReviewSignedOff || len(snapshot.Results) != 3 ||
+		snapshot.Results[0].Annotation == nil || snapshot.Results[0].Annotation.Triage != entity.TriageExpectedBlock {
+		t.Errorf("Unexpected snapshot: %+v", snapshot)
+	}
+
+	// The execution is locked
+	if _, err := annotations.Triage(ctx, "r1", entity.TriageNeedsTuning, "", "analyst"); !errors.Is(err, ErrReviewLocked) {
+		t.Errorf("Expected ErrReviewLocked on triage, got %v", err)
+	}
+	if _, err := annotations.AddComment(ctx, "r1", "analyst", "late note"); !errors.Is(err, ErrReviewLocked) {
+		t.Errorf("Expected ErrReviewLocked on comment, got %v", err)
+	}
+	if _, err := svc.Assign(ctx, "exec-1", "lead"); !errors.Is(err, ErrReviewLocked) {
+		t.Errorf("Expected ErrReviewLocked on assign, got %v", err)
+	}
+	if _, err := svc.Request(ctx, "exec-1", "", "operator"); !errors.Is(err, ErrReviewLocked) {
+		t.Errorf("Expected ErrReviewLocked on a new request, got %v", err)
+	}
+	if _, err := svc.Start(ctx, "exec-1", "analyst"); !errors.Is(err, ErrReviewTransition) {
+		t.Errorf("Expected ErrReviewTransition, got %v", err)
+	}
+}
+
+func TestExecutionReviewService_Errors(t *testing.T) {
+	svc, _, repo := setupReviewTest()
+	ctx := context.Background()
+
+	if _, err := svc.Request(ctx, "missing", "", "operator"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Expected ErrExecutionNotFound, got %v", err)
+	}
+	if _, err := svc.Request(ctx, "exec-2", "", "operator"); !errors.Is(err, ErrInvalidReview) {
+		t.Errorf("Expected ErrInvalidReview for a running execution, got %v", err)
+	}
+	for _, reviewer := range []string{"viewer", "missing"} {
+		if _, err := svc.Request(ctx, "exec-1", reviewer, "operator"); !errors.Is(err, ErrInvalidReview) {
+			t.Errorf("Expected ErrInvalidReview for reviewer %s, got %v", reviewer, err)
+		}
+	}
+	if _, err := svc.Get(ctx, "exec-1"); !errors.Is(err, ErrReviewNotFound) {
+		t.Errorf("Expected ErrReviewNotFound, got %v", err)
+	}
+
+	if _, err := svc.Request(ctx, "exec-1", "lead", "operator"); err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	if _, err := svc.Request(ctx, "exec-1", "", "operator"); !errors.Is(err, ErrReviewTransition) {
+		t.Errorf("Expected ErrReviewTransition for a second request, got %v", err)
+	}
+	if _, err := svc.Start(ctx, "exec-1", "analyst"); !errors.Is(err, ErrNotReviewer) {
+		t.Errorf("Expected ErrNotReviewer, got %v", err)
+	}
+	if _, err := svc.SignOff(ctx, "exec-1", "lead", ""); !errors.Is(err, ErrReviewTransition) {
+		t.Errorf("Expected ErrReviewTransition before the review started, got %v", err)
+	}
+	if _, err := svc.Snapshot(ctx, "exec-1"); !errors.Is(err, ErrReviewNotFound) {
+		t.Errorf("Expected ErrReviewNotFound before sign-off, got %v", err)
+	}
+	if _, err := svc.Assign(ctx, "exec-1", ""); !errors.Is(err, ErrInvalidReview) {
+		t.Errorf("Expected ErrInvalidReview without reviewer, got %v", err)
+	}
+
+	review, err := svc.Assign(ctx, "exec-1", "analyst")
+	if err != nil || review.ReviewerID != "analyst" {
+		t.Fatalf("Expected the review handed over, got %+v (%v)", review, err)
+	}
+	if _, err := svc.Start(ctx, "exec-1", "analyst"); err != nil {
+		t.Errorf("Start failed: %v", err)
+	}
+
+	if _, err := svc.List(ctx, "closed", "", 10); !errors.Is(err, ErrInvalidReview) {
+		t.Errorf("Expected ErrInvalidReview for an unknown status, got %v", err)
+	}
+	if reviews, err := svc.List(ctx, entity.ReviewInReview, "analyst", 10); err != nil || len(reviews) != 1 {
+		t.Errorf("Expected the review listed, got %d (%v)", len(reviews), err)
+	}
+
+	repo.err = errors.New("db down")
+	if _, err := svc.Get(ctx, "exec-1"); err == nil {
+		t.Error("Expected the repository error")
+	}
+}
+
+func TestEvidenceService_AttachLocked(t *testing.T) {
+	svc, _ := newTestEvidenceService()
+	repo := newMockExecutionReviewRepo()
+	repo.reviews["exec-1"] = &entity.ExecutionReview{ExecutionID: "exec-1", Status: entity.ReviewSignedOff}
+	svc.SetExecutionReviews(NewExecutionReviewService(repo, nil, nil, nil, nil))
+
+	if _, err := svc.Attach(context.Background(), "result-1", "notes.txt", "", "", []byte("text"), "operator"); !errors.Is(err, ErrReviewLocked) {
+		t.Errorf("Expected ErrReviewLocked, got %v", err)
+	}
+}
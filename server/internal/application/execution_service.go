@@ -2,7 +2,9 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"autostrike/internal/domain/entity"
@@ -10,6 +12,7 @@ import (
 	"autostrike/internal/domain/service"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // ExecutionService handles execution-related business logic
@@ -20,6 +23,40 @@ type ExecutionService struct {
 	agentRepo     repository.AgentRepository
 	orchestrator  *service.AttackOrchestrator
 	calculator    *service.ScoreCalculator
+	events        *EventBus
+
+	resumeMu sync.Mutex
+	resumed  map[string][]resumedResult // Unanswered results of resumed executions, by agent paw
+
+	phaseMu sync.Mutex // Serializes deferred phase decisions
+	readyMu sync.Mutex
+	ready   map[string][]TaskDispatchInfo // Tasks of deferred phases to dispatch, by agent paw
+
+	factRepo  repository.FactRepository
+	payloads  *PayloadService
+	durations repository.ResultDurationRepository
+	summaries repository.ExecutionSummaryRepository
+
+	submissions repository.ResultSubmissionRepository
+	resultBatch repository.ResultBatchRepository
+
+	snapshotRepo  repository.ExecutionSnapshotRepository
+	serverVersion string
+
+	taskQueue    repository.TaskQueueRepository
+	taskQueueTTL time.Duration
+	staleTimeout time.Duration // Inactivity after which a running execution is failed
+
+	scoringProfiles *ScoringProfileService
+	quota           *ExecutionQuota
+	emergencyStop   *EmergencyStopService
+	annotations     *ResultAnnotationService
+	reviews         *ExecutionReviewService
+
+	outputStore       BlobStore
+	outputThreshold   int
+	outputPreviewSize int
+	outputMaxSize     int
 }
 
 // NewExecutionService creates a new execution service
@@ -38,9 +75,61 @@ func NewExecutionService(
 		agentRepo:     agentRepo,
 		orchestrator:  orchestrator,
 		calculator:    calculator,
+
+		outputPreviewSize: DefaultOutputPreviewSize,
+		outputMaxSize:     DefaultOutputMaxSize,
 	}
 }
 
+// SetEventBus publishes execution lifecycle and result events on the bus
+func (s *ExecutionService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// SetFactRepository stores the facts extracted from technique output, so later
+// techniques can reference them
+func (s *ExecutionService) SetFactRepository(repo repository.FactRepository) {
+	s.factRepo = repo
+}
+
+// SetDurationHistory estimates the time left to active executions from the
+// durations of past technique runs
+func (s *ExecutionService) SetDurationHistory(repo repository.ResultDurationRepository) {
+	s.durations = repo
+}
+
+// SetPayloadService attaches download links to the tasks of techniques that
+// deliver a payload
+func (s *ExecutionService) SetPayloadService(payloads *PayloadService) {
+	s.payloads = payloads
+}
+
+// SetScoringProfileService pins new executions to a scoring profile version and
+// scores them with it
+func (s *ExecutionService) SetScoringProfileService(profiles *ScoringProfileService) {
+	s.scoringProfiles = profiles
+}
+
+// SetExecutionQuota limits the executions started per user and per server
+func (s *ExecutionService) SetExecutionQuota(quota *ExecutionQuota) {
+	s.quota = quota
+}
+
+// SetEmergencyStop refuses new executions while the emergency stop is engaged
+func (s *ExecutionService) SetEmergencyStop(stop *EmergencyStopService) {
+	s.emergencyStop = stop
+}
+
+// SetResultAnnotations includes the analyst triage and comments of results in exports
+func (s *ExecutionService) SetResultAnnotations(annotations *ResultAnnotationService) {
+	s.annotations = annotations
+}
+
+// SetExecutionReviews includes the purple-team review of executions in exports
+func (s *ExecutionService) SetExecutionReviews(reviews *ExecutionReviewService) {
+	s.reviews = reviews
+}
+
 // TaskDispatchInfo contains information needed to dispatch a task to an agent
 type TaskDispatchInfo struct {
 	ResultID    string
@@ -50,6 +139,9 @@ type TaskDispatchInfo struct {
 	Executor    string
 	Timeout     int
 	Cleanup     string
+	Limits      *entity.ResourceLimits
+	Payload     *TaskPayload
+	Collect     []string
 }
 
 // ExecutionWithTasks contains the execution and tasks to dispatch
@@ -58,24 +150,61 @@ type ExecutionWithTasks struct {
 	Tasks     []TaskDispatchInfo
 }
 
-// StartExecution starts a new scenario execution
+// StartExecution starts a new scenario execution scored with the default scoring profile
 func (s *ExecutionService) StartExecution(
 	ctx context.Context,
 	scenarioID string,
 	agentPaws []string,
 	safeMode bool,
 ) (*ExecutionWithTasks, error) {
+	return s.StartExecutionWithProfile(ctx, scenarioID, agentPaws, safeMode, "", ExecutionLauncher{})
+}
+
+// StartExecutionWithProfile starts a new scenario execution for a launcher
+// scored with the current version of a scoring profile, the default one when
+// scoringProfileID is empty. It returns a *QuotaExceededError when the user,
+// their API key, their workspace or the server started too many executions
+// recently, and ErrEmergencyStopEngaged until the emergency stop is re-armed.
+func (s *ExecutionService) StartExecutionWithProfile(
+	ctx context.Context,
+	scenarioID string,
+	agentPaws []string,
+	safeMode bool,
+	scoringProfileID string,
+	launcher ExecutionLauncher,
+) (_ *ExecutionWithTasks, err error) {
+	ctx, span := startSpan(ctx, "ExecutionService.StartExecution",
+		attribute.String("scenario.id", scenarioID),
+		attribute.Int("agent.count", len(agentPaws)),
+		attribute.Bool("execution.safe_mode", safeMode),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err := s.emergencyStop.Check(); err != nil {
+		return nil, err
+	}
+
 	scenario, err := s.scenarioRepo.FindByID(ctx, scenarioID)
 	if err != nil {
 		return nil, fmt.Errorf("scenario not found: %w", err)
 	}
 
-	agentMap, agents, err := s.loadAndValidateAgents(ctx, agentPaws)
+	agents, err := s.loadAndValidateAgents(ctx, agentPaws)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.resolveScoringProfile(ctx, scoringProfileID)
 	if err != nil {
 		return nil, err
 	}
 
-	plan, err := s.orchestrator.PlanExecution(ctx, scenario, agents, safeMode)
+	planCtx, planSpan := startSpan(ctx, "AttackOrchestrator.PlanExecution")
+	plan, err := s.orchestrator.PlanExecution(planCtx, scenario, agents, safeMode)
+	if err == nil {
+		planSpan.SetAttributes(attribute.Int("task.count", len(plan.Tasks)))
+	}
+	endSpan(planSpan, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to plan execution: %w", err)
 	}
@@ -86,32 +215,88 @@ func (s *ExecutionService) StartExecution(
 		AgentPaws:  agentPaws,
 		Status:     entity.ExecutionRunning,
 		StartedAt:  time.Now(),
+		StartedBy:  launcher.UserID,
 		SafeMode:   safeMode,
 	}
+	if profile != nil {
+		execution.ScoringProfileID = profile.ID
+		execution.ScoringProfileVersion = profile.Version
+	}
 
+	var reservation *QuotaReservation
+	if s.quota != nil {
+		if reservation, err = s.quota.Reserve(launcher, execution.StartedAt); err != nil {
+			return nil, err
+		}
+	}
 	if err := s.resultRepo.CreateExecution(ctx, execution); err != nil {
+		if s.quota != nil {
+			s.quota.Release(reservation)
+		}
 		return nil, fmt.Errorf("failed to create execution: %w", err)
 	}
+	if err := s.captureSnapshot(ctx, execution, scenario, agents); err != nil {
+		return nil, err
+	}
 
-	tasks, err := s.createTasksForExecution(ctx, execution.ID, plan.Tasks, agentMap)
+	tasks, err := s.createTasksForExecution(ctx, execution.ID, plan.Tasks)
 	if err != nil {
 		return nil, err
 	}
 
+	// Decide the deferred phases an agent starts with, e.g. a first phase
+	// referencing facts that no technique can have extracted yet
+	for _, paw := range agentPaws {
+		if err := s.advanceDeferredPhases(ctx, execution.ID, paw); err != nil {
+			return nil, fmt.Errorf("failed to plan deferred phases: %w", err)
+		}
+		tasks = append(tasks, s.TakeReadyTasks(ctx, paw)...)
+	}
+
+	span.SetAttributes(attribute.String("execution.id", execution.ID))
+	if s.events != nil {
+		_ = s.fillProgress(ctx, execution) // The progress is informative, it never fails the start
+	}
+	s.events.Publish(ctx, &entity.Event{
+		Type:         entity.EventExecutionStarted,
+		Execution:    execution,
+		ScenarioName: scenario.Name,
+	})
+
+	// Every planned technique may already be skipped
+	if len(tasks) == 0 {
+		if err := s.CompleteExecution(ctx, execution.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	return &ExecutionWithTasks{
 		Execution: execution,
 		Tasks:     tasks,
 	}, nil
 }
 
-// loadAndValidateAgents loads agents and validates they exist and are online
+// resolveScoringProfile returns the scoring profile version a new execution is
+// pinned to, nil for the built-in formula
+func (s *ExecutionService) resolveScoringProfile(ctx context.Context, id string) (*entity.ScoringProfile, error) {
+	if s.scoringProfiles == nil {
+		if id != "" {
+			return nil, ErrScoringProfileNotFound
+		}
+		return nil, nil
+	}
+	return s.scoringProfiles.Resolve(ctx, id)
+}
+
+// loadAndValidateAgents loads agents and validates they exist and are online.
+// Offline agents are accepted when their tasks can be queued.
 func (s *ExecutionService) loadAndValidateAgents(
 	ctx context.Context,
 	agentPaws []string,
-) (map[string]*entity.Agent, []*entity.Agent, error) {
+) ([]*entity.Agent, error) {
 	agents, err := s.agentRepo.FindByPaws(ctx, agentPaws)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load agents: %w", err)
+		return nil, fmt.Errorf("failed to load agents: %w", err)
 	}
 
 	agentMap := make(map[string]*entity.Agent, len(agents))
@@ -122,14 +307,14 @@ func (s *ExecutionService) loadAndValidateAgents(
 	for _, paw := range agentPaws {
 		agent, found := agentMap[paw]
 		if !found {
-			return nil, nil, fmt.Errorf("agent %s not found", paw)
+			return nil, fmt.Errorf("agent %s not found", paw)
 		}
-		if agent.Status != entity.AgentOnline {
-			return nil, nil, fmt.Errorf("agent %s is not online", paw)
+		if agent.Status != entity.AgentOnline && s.taskQueue == nil {
+			return nil, fmt.Errorf("agent %s is not online", paw)
 		}
 	}
 
-	return agentMap, agents, nil
+	return agents, nil
 }
 
 // createTasksForExecution creates task results and dispatch info for each planned task
@@ -137,70 +322,118 @@ func (s *ExecutionService) createTasksForExecution(
 	ctx context.Context,
 	executionID string,
 	planTasks []service.PlannedTask,
-	agentMap map[string]*entity.Agent,
 ) ([]TaskDispatchInfo, error) {
 	tasks := make([]TaskDispatchInfo, 0, len(planTasks))
+	attempts := make(map[string]int)
 
 	for _, task := range planTasks {
+		// A technique planned twice for an agent (e.g. in two phases) gets one result per run
+		key := task.TechniqueID + "|" + task.AgentPaw
+		attempts[key]++
+
 		result := &entity.ExecutionResult{
 			ID:          uuid.New().String(),
 			ExecutionID: executionID,
 			TechniqueID: task.TechniqueID,
 			AgentPaw:    task.AgentPaw,
+			Attempt:     attempts[key],
+			Phase:       task.Phase,
+			Executor:    task.Executor,
 			Status:      entity.StatusPending,
 			StartedAt:   time.Now(),
 		}
+		var payload *TaskPayload
+		if !skipPlannedTask(result, task) {
+			var err error
+			if payload, err = s.linkTaskPayload(ctx, task.Payload, result.ID, task.AgentPaw); err != nil {
+				failUnlinkedPayload(result, task.Payload, err)
+			}
+		}
 
 		if err := s.resultRepo.CreateResult(ctx, result); err != nil {
 			return nil, fmt.Errorf("failed to create result: %w", err)
 		}
-
-		executor := s.determineExecutor(ctx, task.TechniqueID, agentMap[task.AgentPaw])
+		if result.Status.IsTerminal() {
+			continue
+		}
 
 		tasks = append(tasks, TaskDispatchInfo{
 			ResultID:    result.ID,
 			AgentPaw:    task.AgentPaw,
 			TechniqueID: task.TechniqueID,
 			Command:     task.Command,
-			Executor:    executor,
+			Executor:    task.Executor,
 			Timeout:     task.Timeout,
 			Cleanup:     task.Cleanup,
+			Limits:      task.Limits,
+			Payload:     payload,
+			Collect:     task.Collect,
 		})
 	}
 
 	return tasks, nil
 }
 
-// determineExecutor finds the appropriate executor for a technique on an agent
-func (s *ExecutionService) determineExecutor(ctx context.Context, techniqueID string, agent *entity.Agent) string {
-	if agent == nil {
-		return "sh"
+// linkTaskPayload returns the download link of the payload a task needs, or nil
+// when it needs none
+func (s *ExecutionService) linkTaskPayload(ctx context.Context, name, resultID, paw string) (*TaskPayload, error) {
+	if name == "" {
+		return nil, nil
 	}
-
-	technique, err := s.techniqueRepo.FindByID(ctx, techniqueID)
-	if err != nil || technique == nil {
-		// Return platform-appropriate fallback when technique lookup fails
-		return defaultExecutorForPlatform(agent.Platform)
+	if s.payloads == nil {
+		return nil, errors.New("payloads are not enabled")
 	}
+	return s.payloads.Link(ctx, name, resultID, paw)
+}
 
-	if exec := technique.GetExecutorForPlatform(agent.Platform, agent.Executors); exec != nil {
-		return exec.Type
+// skipPlannedTask records a result about to be created as skipped when its
+// task was planned skipped, e.g. on an agent lacking elevation. Reports whether
+// it was.
+func skipPlannedTask(result *entity.ExecutionResult, task service.PlannedTask) bool {
+	if task.SkipStatus == "" {
+		return false
 	}
+	now := time.Now()
+	result.Status = task.SkipStatus
+	result.Output = task.SkipReason
+	result.CompletedAt = &now
+	return true
+}
 
-	// No compatible executor found - return platform-appropriate fallback
-	return defaultExecutorForPlatform(agent.Platform)
+// failUnlinkedPayload fails a result about to be created whose payload cannot be
+// linked, e.g. because it was deleted
+func failUnlinkedPayload(result *entity.ExecutionResult, name string, err error) {
+	now := time.Now()
+	result.Status = entity.StatusFailed
+	result.Output = fmt.Sprintf("payload %s unavailable: %v", name, err)
+	result.CompletedAt = &now
 }
 
-// defaultExecutorForPlatform returns the default shell executor for a platform
-func defaultExecutorForPlatform(platform string) string {
-	switch platform {
-	case "windows":
-		return "cmd"
-	case "darwin":
-		return "bash"
-	default:
-		return "sh"
+// resultExecutor returns the executor of a technique a result was planned
+// with, with its command for the platform of the agent. Results planned before
+// executors were recorded get the first executor compatible with the agent.
+func resultExecutor(technique *entity.Technique, result *entity.ExecutionResult, agent *entity.Agent) *entity.Executor {
+	if result.Executor != "" {
+		if executor := technique.ExecutorOfType(result.Executor); executor != nil {
+			if agent != nil {
+				return executor.ForPlatform(agent.Platform)
+			}
+			return executor
+		}
 	}
+	if agent == nil {
+		return nil
+	}
+	return technique.GetExecutorForPlatform(agent.Platform, agent.Executors)
+}
+
+// resultExecutors returns the executor a result was planned with as the
+// preference of a task planned again for it
+func resultExecutors(result *entity.ExecutionResult) []string {
+	if result.Executor == "" {
+		return nil
+	}
+	return []string{result.Executor}
 }
 
 // UpdateResult updates an execution result
@@ -215,13 +448,21 @@ func (s *ExecutionService) UpdateResult(
 	if err != nil {
 		return err
 	}
+	if apply, err := checkResultTransition(result, status); !apply {
+		return err
+	}
 
 	now := time.Now()
 	result.Status = status
-	result.Output = output
+	s.setResultOutput(ctx, result, output)
 	result.Detected = detected
 	result.CompletedAt = &now
-	return s.resultRepo.UpdateResult(ctx, result)
+	if err := s.resultRepo.UpdateResult(ctx, result); err != nil {
+		return err
+	}
+
+	s.publishResult(ctx, result)
+	return nil
 }
 
 // UpdateResultByID updates a result by its ID with exit code
@@ -233,31 +474,101 @@ func (s *ExecutionService) UpdateResultByID(
 	output string,
 	exitCode int,
 	agentPaw string,
-) error {
+) (err error) {
+	ctx, span := startSpan(ctx, "ExecutionService.UpdateResultByID",
+		attribute.String("result.id", resultID),
+		attribute.String("result.status", string(status)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	result, apply, err := s.reportResult(ctx, resultID, status, output, exitCode, agentPaw)
+	if !apply {
+		return err
+	}
+	executionID := result.ExecutionID
+
+	if err := s.resultRepo.UpdateResult(ctx, result); err != nil {
+		return err
+	}
+	s.resultReported(ctx, result, output)
+
+	// Plan the deferred phases the agent can now reach, before completion is checked
+	if status.IsTerminal() {
+		if err := s.advanceDeferredPhases(ctx, executionID, result.AgentPaw); err != nil {
+			return fmt.Errorf("failed to plan deferred phases: %w", err)
+		}
+	}
+
+	// Check if all results are completed and auto-complete execution
+	return s.checkAndCompleteExecution(ctx, executionID)
+}
+
+// reportResult loads a result an agent reports and applies the report to it,
+// without storing it. It returns false for a result that must not be updated,
+// with an error unless the report repeats the final status of the result.
+func (s *ExecutionService) reportResult(
+	ctx context.Context,
+	resultID string,
+	status entity.ResultStatus,
+	output string,
+	exitCode int,
+	agentPaw string,
+) (*entity.ExecutionResult, bool, error) {
 	result, err := s.resultRepo.FindResultByID(ctx, resultID)
 	if err != nil {
-		return fmt.Errorf("result not found: %w", err)
+		return nil, false, fmt.Errorf("result not found: %w", err)
 	}
 
 	// Validate that the result belongs to the requesting agent
 	if agentPaw != "" && result.AgentPaw != agentPaw {
-		return fmt.Errorf("agent %s is not authorized to update result %s (belongs to %s)", agentPaw, resultID, result.AgentPaw)
+		return nil, false, fmt.Errorf("agent %s is not authorized to update result %s (belongs to %s)", agentPaw, resultID, result.AgentPaw)
+	}
+	if apply, err := checkResultTransition(result, status); !apply {
+		return nil, false, err
 	}
-
-	executionID := result.ExecutionID
 
 	now := time.Now()
 	result.Status = status
-	result.Output = output
+	s.setResultOutput(ctx, result, output)
 	result.ExitCode = exitCode
 	result.CompletedAt = &now
+	return result, true, nil
+}
 
-	if err := s.resultRepo.UpdateResult(ctx, result); err != nil {
-		return err
+// resultReported publishes a reported result once stored and records the
+// facts of its output
+func (s *ExecutionService) resultReported(ctx context.Context, result *entity.ExecutionResult, output string) {
+	s.publishResult(ctx, result)
+	if result.Status == entity.StatusSuccess || result.Status == entity.StatusDetected {
+		s.recordFacts(ctx, result, output)
 	}
+}
 
-	// Check if all results are completed and auto-complete execution
-	return s.checkAndCompleteExecution(ctx, executionID)
+// checkResultTransition reports whether a result should move to status.
+// Repeating the final status a result already has is a retried submission:
+// it is not applied again and is not an error. Any other move that is not
+// forward (pending, running, then final) wraps entity.ErrResultTransition.
+func checkResultTransition(result *entity.ExecutionResult, status entity.ResultStatus) (bool, error) {
+	if result.Status == status && status.IsTerminal() {
+		return false, nil
+	}
+	if !result.Status.CanTransitionTo(status) {
+		return false, fmt.Errorf("result %s is %s, cannot become %s: %w", result.ID, result.Status, status, entity.ErrResultTransition)
+	}
+	return true, nil
+}
+
+// publishResult publishes a result that reached a final status, with its
+// execution carrying the progress made
+func (s *ExecutionService) publishResult(ctx context.Context, result *entity.ExecutionResult) {
+	if !result.Status.IsTerminal() || s.events == nil {
+		return
+	}
+	event := &entity.Event{Type: entity.EventResultCompleted, Result: result}
+	if execution, err := s.executionWithProgress(ctx, result.ExecutionID); err == nil {
+		event.Execution = execution
+	}
+	s.events.Publish(ctx, event)
 }
 
 // checkAndCompleteExecution checks if all results are done and completes the execution
@@ -267,10 +578,10 @@ func (s *ExecutionService) checkAndCompleteExecution(ctx context.Context, execut
 		return nil // Don't fail the result update if we can't check
 	}
 
-	// Check if all results are completed (not pending or running)
+	// Check if all results are completed (not pending, queued or running)
 	allDone := true
 	for _, r := range results {
-		if r.Status == entity.StatusPending || r.Status == entity.StatusRunning {
+		if !r.Status.IsTerminal() {
 			allDone = false
 			break
 		}
@@ -302,17 +613,84 @@ func (s *ExecutionService) CompleteExecution(ctx context.Context, executionID st
 
 	// Calculate score
 	now := time.Now()
-	score := s.calculator.CalculateScore(results)
+	score, err := scoreExecution(ctx, s.calculator, s.scoringProfiles, execution, results)
+	if err != nil {
+		return err
+	}
 	execution.Score = score
 	execution.Status = entity.ExecutionCompleted
 	execution.CompletedAt = &now
 
-	return s.resultRepo.UpdateExecution(ctx, execution)
+	if err := s.resultRepo.UpdateExecution(ctx, execution); err != nil {
+		return err
+	}
+
+	// Sink failures are logged by the bus and never fail the completion itself
+	if s.events != nil {
+		_ = s.fillProgress(ctx, execution)
+		s.events.Publish(ctx, &entity.Event{
+			Type:         entity.EventExecutionCompleted,
+			Execution:    execution,
+			ScenarioName: s.scenarioName(ctx, execution.ScenarioID),
+		})
+	}
+	return nil
+}
+
+// scenarioName returns the scenario name, or its ID if it cannot be loaded
+func (s *ExecutionService) scenarioName(ctx context.Context, scenarioID string) string {
+	if s.scenarioRepo == nil {
+		return scenarioID
+	}
+	scenario, err := s.scenarioRepo.FindByID(ctx, scenarioID)
+	if err != nil || scenario == nil {
+		return scenarioID
+	}
+	return scenario.Name
+}
+
+// ExportExecution returns an execution with its results, enriched with technique
+// metadata when verbosity is full and with the analyst triage of each result
+func (s *ExecutionService) ExportExecution(ctx context.Context, executionID string, verbosity ResultVerbosity) (*ExecutionExport, error) {
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	enriched := enrichResults(ctx, s.techniqueRepo, results, verbosity)
+	if s.annotations != nil {
+		annotations, err := s.annotations.ByExecution(ctx, executionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load annotations: %w", err)
+		}
+		for i := range enriched {
+			enriched[i].Annotation = annotations[enriched[i].ID]
+		}
+	}
+
+	export := &ExecutionExport{
+		Execution:    execution,
+		ScenarioName: s.scenarioName(ctx, execution.ScenarioID),
+		Verbosity:    verbosity,
+		ExportedAt:   time.Now(),
+		Results:      enriched,
+	}
+	if s.reviews != nil {
+		if export.Review, err = s.reviews.repo.Get(ctx, executionID); err != nil {
+			return nil, fmt.Errorf("failed to load review: %w", err)
+		}
+	}
+	return export, nil
 }
 
-// GetExecution retrieves an execution by ID
+// GetExecution retrieves an execution by ID with its progress
 func (s *ExecutionService) GetExecution(ctx context.Context, id string) (*entity.Execution, error) {
-	return s.resultRepo.FindExecutionByID(ctx, id)
+	return s.executionWithProgress(ctx, id)
 }
 
 // GetExecutionResults retrieves results for an execution
@@ -325,6 +703,32 @@ func (s *ExecutionService) GetRecentExecutions(ctx context.Context, limit int) (
 	return s.resultRepo.FindRecentExecutions(ctx, limit)
 }
 
+// CancelActiveExecutions cancels every running, pending or interrupted
+// execution and returns their IDs. It goes on past an execution that fails to
+// cancel, returning the first error.
+func (s *ExecutionService) CancelActiveExecutions(ctx context.Context) ([]string, error) {
+	var cancelled []string
+	var firstErr error
+	for _, status := range []entity.ExecutionStatus{
+		entity.ExecutionRunning, entity.ExecutionPending, entity.ExecutionInterrupted,
+	} {
+		executions, err := s.resultRepo.FindExecutionsByStatus(ctx, status)
+		if err != nil {
+			return cancelled, fmt.Errorf("failed to list %s executions: %w", status, err)
+		}
+		for _, execution := range executions {
+			if err := s.CancelExecution(ctx, execution.ID); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			cancelled = append(cancelled, execution.ID)
+		}
+	}
+	return cancelled, firstErr
+}
+
 // CancelExecution stops a running execution
 func (s *ExecutionService) CancelExecution(ctx context.Context, executionID string) error {
 	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
@@ -332,20 +736,26 @@ func (s *ExecutionService) CancelExecution(ctx context.Context, executionID stri
 		return fmt.Errorf("execution not found: %w", err)
 	}
 
-	// Only running or pending executions can be cancelled
-	if execution.Status != entity.ExecutionRunning && execution.Status != entity.ExecutionPending {
+	// Only running, pending or interrupted executions can be cancelled
+	if execution.Status != entity.ExecutionRunning && execution.Status != entity.ExecutionPending &&
+		execution.Status != entity.ExecutionInterrupted {
 		return fmt.Errorf("execution cannot be cancelled: status is %s", execution.Status)
 	}
 
-	// Update all pending results to skipped
+	// Update all pending results to skipped, dropping the tasks queued for offline agents
 	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
 	if err != nil {
 		return fmt.Errorf("failed to get results: %w", err)
 	}
+	if s.taskQueue != nil {
+		if err := s.taskQueue.DeleteByExecution(ctx, executionID); err != nil {
+			return fmt.Errorf("failed to drop queued tasks: %w", err)
+		}
+	}
 
 	now := time.Now()
 	for _, result := range results {
-		if result.Status == entity.StatusPending || result.Status == entity.StatusRunning {
+		if !result.Status.IsTerminal() {
 			result.Status = entity.StatusSkipped
 			result.CompletedAt = &now
 			if err := s.resultRepo.UpdateResult(ctx, result); err != nil {
@@ -358,5 +768,17 @@ func (s *ExecutionService) CancelExecution(ctx context.Context, executionID stri
 	execution.Status = entity.ExecutionCancelled
 	execution.CompletedAt = &now
 
-	return s.resultRepo.UpdateExecution(ctx, execution)
+	if err := s.resultRepo.UpdateExecution(ctx, execution); err != nil {
+		return err
+	}
+
+	if s.events != nil {
+		_ = s.fillProgress(ctx, execution)
+		s.events.Publish(ctx, &entity.Event{
+			Type:         entity.EventExecutionCancelled,
+			Execution:    execution,
+			ScenarioName: s.scenarioName(ctx, execution.ScenarioID),
+		})
+	}
+	return nil
 }
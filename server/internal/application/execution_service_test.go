@@ -167,6 +167,19 @@ func (m *mockResultRepo) FindCompletedExecutionsByDateRange(ctx context.Context,
 	return result, nil
 }
 
+func (m *mockResultRepo) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var result []*entity.Execution
+	for _, e := range m.executions {
+		if e.Status == status {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
 func TestNewExecutionService(t *testing.T) {
 	resultRepo := newMockResultRepo()
 	scenarioRepo := newMockScenarioRepo()
@@ -399,6 +412,57 @@ func TestStartExecutionSuccess(t *testing.T) {
 	if len(result.Tasks) == 0 {
 		t.Error("Expected tasks to be returned")
 	}
+	if results := resultRepo.results[result.Execution.ID]; len(results) == 0 || results[0].Phase != "Phase1" {
+		t.Error("Expected results to record their scenario phase")
+	}
+}
+
+func TestStartExecution_InsufficientPrivilege(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:     "s1",
+		Name:   "Elevated",
+		Phases: []entity.Phase{{Name: "Credential Access", Techniques: []string{"T1003"}}},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1003"] = &entity.Technique{
+		ID:        "T1003",
+		Platforms: []string{"windows"},
+		Executors: []entity.Executor{{Type: "psh", Command: "dump", ElevationRequired: true}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["user"] = &entity.Agent{Paw: "user", Status: entity.AgentOnline, Platform: "windows", Executors: []string{"psh"}}
+	agentRepo.agents["admin"] = &entity.Agent{Paw: "admin", Status: entity.AgentOnline, Platform: "windows", Executors: []string{"psh"},
+		AgentInventory: entity.AgentInventory{Elevated: true}}
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	svc := NewExecutionService(resultRepo, scenarioRepo, techRepo, agentRepo, orchestrator, service.NewScoreCalculator())
+
+	started, err := svc.StartExecution(context.Background(), "s1", []string{"user", "admin"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(started.Tasks) != 1 || started.Tasks[0].AgentPaw != "admin" {
+		t.Fatalf("Expected only the elevated agent to get the task, got %+v", started.Tasks)
+	}
+	var skipped *entity.ExecutionResult
+	for _, r := range resultRepo.results[started.Execution.ID] {
+		if r.AgentPaw == "user" {
+			skipped = r
+		}
+	}
+	if skipped == nil || skipped.Status != entity.StatusSkippedInsufficientPrivilege || skipped.CompletedAt == nil {
+		t.Errorf("Expected the non-elevated agent's result to be skipped for privilege, got %+v", skipped)
+	}
+
+	// An execution with only non-elevated agents completes right away
+	started, err = svc.StartExecution(context.Background(), "s1", []string{"user"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(started.Tasks) != 0 || resultRepo.executions[started.Execution.ID].Status != entity.ExecutionCompleted {
+		t.Errorf("Expected the execution to complete without tasks, got %+v", started)
+	}
 }
 
 func TestUpdateResultRepoError(t *testing.T) {
@@ -704,7 +768,7 @@ func TestUpdateResultByID_AutoCompletes(t *testing.T) {
 	}
 	// Only one result that we're about to complete
 	resultRepo.results["e1"] = []*entity.ExecutionResult{
-		{ID: "r1", ExecutionID: "e1", Status: entity.StatusSuccess}, // Already marked success in mock after update
+		{ID: "r1", ExecutionID: "e1", Status: entity.StatusRunning},
 	}
 	calculator := service.NewScoreCalculator()
 
@@ -776,6 +840,58 @@ func TestUpdateResultByID_AgentPawValidation(t *testing.T) {
 	}
 }
 
+func TestUpdateResultByID_RetriedAndOutOfOrder(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", AgentPaw: "agent-1", Status: entity.StatusPending},
+	}
+	svc := &ExecutionService{resultRepo: resultRepo, calculator: service.NewScoreCalculator()}
+	ctx := context.Background()
+
+	if err := svc.UpdateResultByID(ctx, "r1", entity.StatusSuccess, "done", 0, "agent-1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A retried submission is accepted but not applied again
+	if err := svc.UpdateResultByID(ctx, "r1", entity.StatusSuccess, "retry", 0, "agent-1"); err != nil {
+		t.Errorf("Expected retried submission to succeed, got %v", err)
+	}
+
+	for _, status := range []entity.ResultStatus{entity.StatusRunning, entity.StatusFailed} {
+		err := svc.UpdateResultByID(ctx, "r1", status, "late", 1, "agent-1")
+		if !errors.Is(err, entity.ErrResultTransition) {
+			t.Errorf("Expected ErrResultTransition for %s, got %v", status, err)
+		}
+	}
+
+	result := resultRepo.results["e1"][0]
+	if result.Status != entity.StatusSuccess || result.Output != "done" {
+		t.Errorf("Expected the first submission to be kept, got %s %q", result.Status, result.Output)
+	}
+}
+
+func TestCreateTasksForExecution_NumbersAttempts(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := &ExecutionService{resultRepo: resultRepo, techniqueRepo: newMockTechniqueRepo()}
+
+	planTasks := []service.PlannedTask{
+		{TechniqueID: "T1082", AgentPaw: "agent-1"},
+		{TechniqueID: "T1082", AgentPaw: "agent-2"},
+		{TechniqueID: "T1082", AgentPaw: "agent-1"},
+	}
+	if _, err := svc.createTasksForExecution(context.Background(), "e1", planTasks); err != nil {
+		t.Fatalf("createTasksForExecution failed: %v", err)
+	}
+
+	expected := []int{1, 1, 2}
+	for i, r := range resultRepo.results["e1"] {
+		if r.Attempt != expected[i] {
+			t.Errorf("Expected task %d to be attempt %d, got %d", i, expected[i], r.Attempt)
+		}
+	}
+}
+
 func TestCheckAndCompleteExecution_FindResultsError(t *testing.T) {
 	resultRepo := newMockResultRepo()
 	resultRepo.findResultsErr = errors.New("db error")
@@ -821,58 +937,28 @@ func TestStartExecutionFindByPawsError(t *testing.T) {
 	}
 }
 
-func TestDefaultExecutorForPlatform(t *testing.T) {
-	tests := []struct {
-		platform string
-		expected string
-	}{
-		{"windows", "cmd"},
-		{"darwin", "bash"},
-		{"linux", "sh"},
-		{"freebsd", "sh"},
-		{"", "sh"},
+func TestResultExecutor(t *testing.T) {
+	technique := &entity.Technique{
+		ID:        "T1059",
+		Platforms: []string{"windows"},
+		Executors: []entity.Executor{{Type: "cmd", Command: "whoami"}, {Type: "psh", Command: "Get-LocalUser"}},
 	}
+	agent := &entity.Agent{Platform: "windows", Executors: []string{"cmd", "psh"}}
 
-	for _, tt := range tests {
-		result := defaultExecutorForPlatform(tt.platform)
-		if result != tt.expected {
-			t.Errorf("defaultExecutorForPlatform(%s) = %s, want %s", tt.platform, result, tt.expected)
-		}
+	if executor := resultExecutor(technique, &entity.ExecutionResult{Executor: "psh"}, agent); executor == nil || executor.Type != "psh" {
+		t.Errorf("Expected the executor the result was planned with, got %+v", executor)
 	}
-}
-
-func TestDetermineExecutor_TechniqueNotFound(t *testing.T) {
-	techRepo := newMockTechniqueRepo()
-	techRepo.err = errors.New("technique not found")
-
-	svc := &ExecutionService{techniqueRepo: techRepo}
-
-	// Windows agent should get "cmd" when technique lookup fails
-	windowsAgent := &entity.Agent{Platform: "windows", Executors: []string{"cmd", "powershell"}}
-	result := svc.determineExecutor(context.Background(), "T9999", windowsAgent)
-	if result != "cmd" {
-		t.Errorf("Expected 'cmd' for Windows agent, got '%s'", result)
+	// Results planned before executors were recorded get the first compatible one
+	if executor := resultExecutor(technique, &entity.ExecutionResult{}, agent); executor == nil || executor.Type != "cmd" {
+		t.Errorf("Expected the first compatible executor, got %+v", executor)
 	}
-
-	// Linux agent should get "sh" when technique lookup fails
-	linuxAgent := &entity.Agent{Platform: "linux", Executors: []string{"sh", "bash"}}
-	result = svc.determineExecutor(context.Background(), "T9999", linuxAgent)
-	if result != "sh" {
-		t.Errorf("Expected 'sh' for Linux agent, got '%s'", result)
+	if executor := resultExecutor(technique, &entity.ExecutionResult{}, nil); executor != nil {
+		t.Errorf("Expected no executor without the agent, got %+v", executor)
 	}
-
-	// macOS agent should get "bash" when technique lookup fails
-	darwinAgent := &entity.Agent{Platform: "darwin", Executors: []string{"bash", "zsh"}}
-	result = svc.determineExecutor(context.Background(), "T9999", darwinAgent)
-	if result != "bash" {
-		t.Errorf("Expected 'bash' for macOS agent, got '%s'", result)
+	if got := resultExecutors(&entity.ExecutionResult{Executor: "psh"}); len(got) != 1 || got[0] != "psh" {
+		t.Errorf("Expected [psh], got %v", got)
 	}
-}
-
-func TestDetermineExecutor_NilAgent(t *testing.T) {
-	svc := &ExecutionService{}
-	result := svc.determineExecutor(context.Background(), "T1059", nil)
-	if result != "sh" {
-		t.Errorf("Expected 'sh' for nil agent, got '%s'", result)
+	if got := resultExecutors(&entity.ExecutionResult{}); got != nil {
+		t.Errorf("Expected no preference, got %v", got)
 	}
 }
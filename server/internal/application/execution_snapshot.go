@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// ErrSnapshotNotFound is returned for an execution started without a snapshot,
+// e.g. before snapshots were enabled
+var ErrSnapshotNotFound = errors.New("execution snapshot not found")
+
+// SetSnapshotRepository captures the environment of every new execution: the
+// server settings and build, the scenario and its techniques as they are at
+// launch, and the inventory of the agents
+func (s *ExecutionService) SetSnapshotRepository(repo repository.ExecutionSnapshotRepository, serverVersion string) {
+	s.snapshotRepo = repo
+	s.serverVersion = serverVersion
+}
+
+// captureSnapshot stores the environment an execution is launched in
+func (s *ExecutionService) captureSnapshot(
+	ctx context.Context,
+	execution *entity.Execution,
+	scenario *entity.Scenario,
+	agents []*entity.Agent,
+) error {
+	if s.snapshotRepo == nil {
+		return nil
+	}
+
+	snapshot := &entity.ExecutionSnapshot{
+		ExecutionID: execution.ID,
+		CapturedAt:  execution.StartedAt,
+		Server: entity.ServerSnapshot{
+			Version:               s.serverVersion,
+			SafeMode:              execution.SafeMode,
+			ScoringProfileID:      execution.ScoringProfileID,
+			ScoringProfileVersion: execution.ScoringProfileVersion,
+			OutputMaxSize:         s.outputMaxSize,
+		},
+		Scenario: entity.ScenarioSnapshot{
+			Fingerprint: entity.ScenarioFingerprint(scenario),
+			Scenario:    scenario,
+		},
+		Techniques: []entity.TechniqueSnapshot{},
+		Agents:     agents,
+	}
+	if s.taskQueue != nil {
+		snapshot.Server.TaskQueueTTL = s.taskQueueTTL.String()
+	}
+
+	seen := make(map[string]bool)
+	for _, phase := range scenario.Phases {
+		for _, id := range phase.Techniques {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			// A missing technique is skipped by the execution as well
+			technique, err := s.techniqueRepo.FindByID(ctx, id)
+			if err != nil {
+				continue
+			}
+			snapshot.Techniques = append(snapshot.Techniques, entity.TechniqueSnapshot{
+				Fingerprint: entity.TechniqueFingerprint(technique),
+				Technique:   technique,
+			})
+		}
+	}
+
+	if err := s.snapshotRepo.Save(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to save execution snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetExecutionSnapshot returns the environment an execution was launched in,
+// flagging the scenario and techniques modified or deleted since
+func (s *ExecutionService) GetExecutionSnapshot(ctx context.Context, executionID string) (*entity.ExecutionSnapshot, error) {
+	if _, err := s.resultRepo.FindExecutionByID(ctx, executionID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+	if s.snapshotRepo == nil {
+		return nil, ErrSnapshotNotFound
+	}
+	snapshot, err := s.snapshotRepo.Get(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, ErrSnapshotNotFound
+	}
+
+	if snapshot.Scenario.Scenario != nil {
+		current, err := s.scenarioRepo.FindByID(ctx, snapshot.Scenario.Scenario.ID)
+		snapshot.Scenario.Modified = err != nil || entity.ScenarioFingerprint(current) != snapshot.Scenario.Fingerprint
+	}
+	for i := range snapshot.Techniques {
+		technique := &snapshot.Techniques[i]
+		if technique.Technique == nil {
+			continue
+		}
+		current, err := s.techniqueRepo.FindByID(ctx, technique.Technique.ID)
+		technique.Modified = err != nil || entity.TechniqueFingerprint(current) != technique.Fingerprint
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockExecutionSnapshotRepo implements repository.ExecutionSnapshotRepository
+// for tests, storing snapshots encoded like the database does
+type mockExecutionSnapshotRepo struct {
+	snapshots map[string][]byte
+	err       error
+}
+
+func (m *mockExecutionSnapshotRepo) Save(ctx context.Context, snapshot *entity.ExecutionSnapshot) error {
+	if m.err != nil {
+		return m.err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	m.snapshots[snapshot.ExecutionID] = data
+	return nil
+}
+
+func (m *mockExecutionSnapshotRepo) Get(ctx context.Context, executionID string) (*entity.ExecutionSnapshot, error) {
+	data, ok := m.snapshots[executionID]
+	if !ok {
+		return nil, nil
+	}
+	snapshot := &entity.ExecutionSnapshot{}
+	return snapshot, json.Unmarshal(data, snapshot)
+}
+
+func TestExecutionSnapshot_CapturedAtLaunch(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	repo := &mockExecutionSnapshotRepo{snapshots: make(map[string][]byte)}
+	svc.SetSnapshotRepository(repo, "v1.2.3")
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, true)
+	if err != nil {
+		t.Fatalf("StartExecution failed: %v", err)
+	}
+
+	snapshot, err := svc.GetExecutionSnapshot(ctx, started.Execution.ID)
+	if err != nil {
+		t.Fatalf("GetExecutionSnapshot failed: %v", err)
+	}
+	if snapshot.Server.Version != "v1.2.3" || !snapshot.Server.SafeMode || snapshot.Server.OutputMaxSize != DefaultOutputMaxSize {
+		t.Errorf("Unexpected server snapshot: %+v", snapshot.Server)
+	}
+	if snapshot.Scenario.Scenario == nil || snapshot.Scenario.Scenario.ID != "s1" || snapshot.Scenario.Modified {
+		t.Errorf("Unexpected scenario snapshot: %+v", snapshot.Scenario)
+	}
+	// Techniques of deferred phases are captured too
+	if len(snapshot.Techniques) != 2 || snapshot.Techniques[0].Technique.ID != "T1082" || snapshot.Techniques[1].Technique.ID != "T1021" {
+		t.Fatalf("Unexpected technique snapshots: %+v", snapshot.Techniques)
+	}
+	if len(snapshot.Agents) != 1 || snapshot.Agents[0].Paw != "paw1" || snapshot.Agents[0].Platform != "linux" {
+		t.Errorf("Unexpected agent snapshots: %+v", snapshot.Agents)
+	}
+}
+
+func TestExecutionSnapshot_FlagsLaterEdits(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	svc.SetSnapshotRepository(&mockExecutionSnapshotRepo{snapshots: make(map[string][]byte)}, "dev")
+	ctx := context.Background()
+
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("StartExecution failed: %v", err)
+	}
+
+	// Saving the scenario unchanged keeps its fingerprint
+	scenarios := svc.scenarioRepo.(*mockScenarioRepo)
+	scenarios.scenarios["s1"].UpdatedAt = time.Now().Add(time.Hour)
+	techniques := svc.techniqueRepo.(*mockTechniqueRepo)
+	techniques.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "Edited", Platforms: []string{"linux"}}
+	delete(techniques.techniques, "T1021")
+
+	snapshot, err := svc.GetExecutionSnapshot(ctx, started.Execution.ID)
+	if err != nil {
+		t.Fatalf("GetExecutionSnapshot failed: %v", err)
+	}
+	if snapshot.Scenario.Modified {
+		t.Error("The scenario should not be flagged modified")
+	}
+	if !snapshot.Techniques[0].Modified || !snapshot.Techniques[1].Modified {
+		t.Errorf("The edited and deleted techniques should be flagged modified: %+v", snapshot.Techniques)
+	}
+	if snapshot.Techniques[0].Technique.Name == "Edited" {
+		t.Error("The snapshot should keep the technique as it was run")
+	}
+}
+
+func TestExecutionSnapshot_Errors(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	svc := newFactTestService(resultRepo, &mockFactRepo{}, factTestPhases)
+	ctx := context.Background()
+
+	if _, err := svc.GetExecutionSnapshot(ctx, "missing"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Expected ErrExecutionNotFound, got %v", err)
+	}
+
+	// Executions started without snapshots have none
+	started, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("StartExecution failed: %v", err)
+	}
+	if _, err := svc.GetExecutionSnapshot(ctx, started.Execution.ID); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Errorf("Expected ErrSnapshotNotFound, got %v", err)
+	}
+
+	svc.SetSnapshotRepository(&mockExecutionSnapshotRepo{snapshots: make(map[string][]byte), err: errors.New("disk full")}, "dev")
+	if _, err := svc.StartExecution(ctx, "s1", []string{"paw1"}, false); err == nil {
+		t.Error("StartExecution should fail when the snapshot cannot be saved")
+	}
+}
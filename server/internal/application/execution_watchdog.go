@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// DefaultStaleExecutionTimeout is how long a running execution may go without
+// activity by default before the watchdog fails it
+const DefaultStaleExecutionTimeout = 6 * time.Hour
+
+// SetStaleExecutionTimeout fails the running executions without activity for
+// timeout, e.g. because their agent vanished or the orchestrator lost track of
+// them. A zero timeout disables the watchdog.
+func (s *ExecutionService) SetStaleExecutionTimeout(timeout time.Duration) {
+	s.staleTimeout = timeout
+}
+
+// FailStaleExecutions fails the running executions whose last activity, their
+// start or the latest update of one of their results, is older than the stale
+// timeout. Executions waiting for queued tasks are left to the task queue,
+// which fails the tasks once expired. Returns the number of executions failed.
+func (s *ExecutionService) FailStaleExecutions(ctx context.Context, now time.Time) (int, error) {
+	if s.staleTimeout <= 0 {
+		return 0, nil
+	}
+	running, err := s.resultRepo.FindExecutionsByStatus(ctx, entity.ExecutionRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find running executions: %w", err)
+	}
+
+	failed := 0
+	for _, execution := range running {
+		results, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+		if err != nil {
+			return failed, fmt.Errorf("failed to get results of execution %s: %w", execution.ID, err)
+		}
+		last, waiting := lastExecutionActivity(execution, results)
+		if waiting || now.Sub(last) < s.staleTimeout {
+			continue
+		}
+
+		reason := fmt.Sprintf("no activity for %s, the execution is considered stuck", s.staleTimeout)
+		if err := s.failStaleExecution(ctx, execution, results, reason, now); err != nil {
+			return failed, err
+		}
+		failed++
+	}
+	return failed, nil
+}
+
+// failStaleExecution fails an execution with its unfinished results, and drops
+// the tasks still waiting to be sent to its agents
+func (s *ExecutionService) failStaleExecution(
+	ctx context.Context,
+	execution *entity.Execution,
+	results []*entity.ExecutionResult,
+	reason string,
+	now time.Time,
+) error {
+	if s.taskQueue != nil {
+		if err := s.taskQueue.DeleteByExecution(ctx, execution.ID); err != nil {
+			return fmt.Errorf("failed to drop queued tasks of execution %s: %w", execution.ID, err)
+		}
+	}
+	s.dropWaitingTasks(execution.ID, results)
+
+	for _, result := range results {
+		if result.Status.IsTerminal() {
+			continue
+		}
+		result.Status = entity.StatusFailed
+		s.setResultOutput(ctx, result, reason)
+		result.ExitCode = -1
+		result.CompletedAt = &now
+		if err := s.resultRepo.UpdateResult(ctx, result); err != nil {
+			return fmt.Errorf("failed to update result %s: %w", result.ID, err)
+		}
+	}
+
+	execution.Status = entity.ExecutionFailed
+	execution.CompletedAt = &now
+	execution.FailureReason = reason
+	if err := s.resultRepo.UpdateExecution(ctx, execution); err != nil {
+		return fmt.Errorf("failed to fail execution %s: %w", execution.ID, err)
+	}
+
+	// Subscribed users are notified by the notification sink
+	if s.events != nil {
+		_ = s.fillProgress(ctx, execution)
+		s.events.Publish(ctx, &entity.Event{
+			Type:         entity.EventExecutionFailed,
+			Execution:    execution,
+			ScenarioName: s.scenarioName(ctx, execution.ScenarioID),
+			Error:        reason,
+		})
+	}
+	return nil
+}
+
+// dropWaitingTasks forgets the deferred phase and resumed tasks of an
+// execution, not sent to their agents yet
+func (s *ExecutionService) dropWaitingTasks(executionID string, results []*entity.ExecutionResult) {
+	ids := make(map[string]bool, len(results))
+	for _, r := range results {
+		ids[r.ID] = true
+	}
+
+	s.readyMu.Lock()
+	for paw, tasks := range s.ready {
+		kept := tasks[:0]
+		for _, task := range tasks {
+			if !ids[task.ResultID] {
+				kept = append(kept, task)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.ready, paw)
+		} else {
+			s.ready[paw] = kept
+		}
+	}
+	s.readyMu.Unlock()
+
+	s.resumeMu.Lock()
+	for paw, waiting := range s.resumed {
+		kept := waiting[:0]
+		for _, w := range waiting {
+			if w.result.ExecutionID != executionID {
+				kept = append(kept, w)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.resumed, paw)
+		} else {
+			s.resumed[paw] = kept
+		}
+	}
+	s.resumeMu.Unlock()
+}
+
+// lastExecutionActivity returns when an execution last made progress, and
+// whether one of its results is queued for an offline agent
+func lastExecutionActivity(execution *entity.Execution, results []*entity.ExecutionResult) (time.Time, bool) {
+	last := execution.StartedAt
+	waiting := false
+	for _, r := range results {
+		if r.Status == entity.StatusQueued {
+			waiting = true
+		}
+		if r.StartedAt.After(last) {
+			last = r.StartedAt
+		}
+		if r.CompletedAt != nil && r.CompletedAt.After(last) {
+			last = *r.CompletedAt
+		}
+	}
+	return last, waiting
+}
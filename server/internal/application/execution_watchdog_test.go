@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestFailStaleExecutions(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	now := time.Now()
+	old := now.Add(-3 * time.Hour)
+	recent := now.Add(-time.Minute)
+
+	resultRepo.executions["stale"] = &entity.Execution{ID: "stale", Status: entity.ExecutionRunning, StartedAt: old}
+	resultRepo.results["stale"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "stale", AgentPaw: "paw1", Status: entity.StatusSuccess, StartedAt: old, CompletedAt: &old},
+		{ID: "r2", ExecutionID: "stale", AgentPaw: "paw1", Status: entity.StatusRunning, StartedAt: old},
+	}
+	resultRepo.executions["active"] = &entity.Execution{ID: "active", Status: entity.ExecutionRunning, StartedAt: old}
+	resultRepo.results["active"] = []*entity.ExecutionResult{
+		{ID: "r3", ExecutionID: "active", AgentPaw: "paw1", Status: entity.StatusSuccess, StartedAt: old, CompletedAt: &recent},
+		{ID: "r4", ExecutionID: "active", AgentPaw: "paw1", Status: entity.StatusRunning, StartedAt: old},
+	}
+	resultRepo.executions["waiting"] = &entity.Execution{ID: "waiting", Status: entity.ExecutionRunning, StartedAt: old}
+	resultRepo.results["waiting"] = []*entity.ExecutionResult{
+		{ID: "r5", ExecutionID: "waiting", AgentPaw: "paw2", Status: entity.StatusQueued, StartedAt: old},
+	}
+
+	svc := newResumeTestService(resultRepo)
+	queue := newMockTaskQueueRepo()
+	svc.SetTaskQueue(queue, time.Hour)
+	queue.tasks["r2"] = &entity.QueuedTask{ResultID: "r2", ExecutionID: "stale", AgentPaw: "paw1"}
+	svc.ready = map[string][]TaskDispatchInfo{"paw1": {{ResultID: "r2"}, {ResultID: "r4"}}}
+	bus := NewEventBus(nil)
+	sink := &recordingSink{name: "test"}
+	bus.AddSink(sink)
+	svc.SetEventBus(bus)
+	ctx := context.Background()
+
+	if n, err := svc.FailStaleExecutions(ctx, now); err != nil || n != 0 {
+		t.Fatalf("Expected the watchdog disabled by default, got %d, %v", n, err)
+	}
+
+	svc.SetStaleExecutionTimeout(time.Hour)
+	n, err := svc.FailStaleExecutions(ctx, now)
+	if err != nil {
+		t.Fatalf("FailStaleExecutions failed: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("Expected 1 stale execution, got %d", n)
+	}
+
+	stale := resultRepo.executions["stale"]
+	if stale.Status != entity.ExecutionFailed || stale.CompletedAt == nil || stale.FailureReason == "" {
+		t.Errorf("Expected the stale execution failed with a reason, got %+v", stale)
+	}
+	if r := resultRepo.results["stale"][1]; r.Status != entity.StatusFailed || r.Output != stale.FailureReason {
+		t.Errorf("Expected the unfinished result failed with the reason, got %+v", r)
+	}
+	if resultRepo.executions["active"].Status != entity.ExecutionRunning || resultRepo.executions["waiting"].Status != entity.ExecutionRunning {
+		t.Error("Expected the executions with recent activity or queued tasks kept running")
+	}
+	if len(queue.tasks) != 0 {
+		t.Errorf("Expected the queued tasks of the stale execution dropped, got %v", queue.tasks)
+	}
+	if tasks := svc.ready["paw1"]; len(tasks) != 1 || tasks[0].ResultID != "r4" {
+		t.Errorf("Expected only the ready tasks of the stale execution dropped, got %+v", tasks)
+	}
+
+	if len(sink.events) != 1 || sink.events[0].Type != entity.EventExecutionFailed || sink.events[0].Error != stale.FailureReason {
+		t.Errorf("Expected an execution failed event with the reason, got %v", sink.types())
+	}
+}
@@ -0,0 +1,157 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Health statuses of a component and of a report
+const (
+	HealthOK          = "ok"
+	HealthDegraded    = "degraded"    // An optional component is failing
+	HealthUnavailable = "unavailable" // A required component is failing
+	HealthError       = "error"       // Status of a failing component
+)
+
+// defaultHealthCheckTimeout bounds each component check
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// HealthCheckFunc checks a component, returning an error when it is unhealthy
+type HealthCheckFunc func(ctx context.Context) error
+
+// ComponentHealth is the outcome of a component check
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	Required  bool   `json:"required"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport is the component-level health of the server
+type HealthReport struct {
+	Status     string                      `json:"status"`
+	Components map[string]*ComponentHealth `json:"components"`
+	CheckedAt  time.Time                   `json:"checked_at"`
+}
+
+// Healthy reports whether every required component is healthy
+func (r *HealthReport) Healthy() bool {
+	return r.Status != HealthUnavailable
+}
+
+// healthCheck is a registered component check
+type healthCheck struct {
+	name     string
+	check    HealthCheckFunc
+	required bool
+	liveness bool
+}
+
+// HealthService runs component checks for the liveness and readiness probes.
+// Liveness checks cover in-process loops (a failure means the process should
+// be restarted); readiness checks add the dependencies needed to serve traffic.
+type HealthService struct {
+	checks  []healthCheck
+	timeout time.Duration
+}
+
+// NewHealthService creates a health service without checks
+func NewHealthService() *HealthService {
+	return &HealthService{timeout: defaultHealthCheckTimeout}
+}
+
+// SetTimeout sets the time limit of each component check
+func (s *HealthService) SetTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
+// AddLivenessCheck registers a required check run by both probes
+func (s *HealthService) AddLivenessCheck(name string, check HealthCheckFunc) {
+	s.checks = append(s.checks, healthCheck{name: name, check: check, required: true, liveness: true})
+}
+
+// AddReadinessCheck registers a check run by the readiness probe. A failing
+// optional check degrades the report without making the server unavailable.
+func (s *HealthService) AddReadinessCheck(name string, check HealthCheckFunc, required bool) {
+	s.checks = append(s.checks, healthCheck{name: name, check: check, required: required})
+}
+
+// Liveness runs the liveness checks
+func (s *HealthService) Liveness(ctx context.Context) *HealthReport {
+	var checks []healthCheck
+	for _, c := range s.checks {
+		if c.liveness {
+			checks = append(checks, c)
+		}
+	}
+	return s.run(ctx, checks)
+}
+
+// Readiness runs every check
+func (s *HealthService) Readiness(ctx context.Context) *HealthReport {
+	return s.run(ctx, s.checks)
+}
+
+// run runs checks concurrently, each within the check timeout
+func (s *HealthService) run(ctx context.Context, checks []healthCheck) *HealthReport {
+	report := &HealthReport{
+		Status:     HealthOK,
+		Components: make(map[string]*ComponentHealth, len(checks)),
+		CheckedAt:  time.Now(),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c healthCheck) {
+			defer wg.Done()
+			component := s.runCheck(ctx, c)
+			mu.Lock()
+			report.Components[c.name] = component
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if component.Status == HealthOK {
+			continue
+		}
+		if component.Required {
+			report.Status = HealthUnavailable
+		} else if report.Status == HealthOK {
+			report.Status = HealthDegraded
+		}
+	}
+	return report
+}
+
+// runCheck runs a check, reporting a timeout when it does not return in time
+func (s *HealthService) runCheck(ctx context.Context, c healthCheck) *ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- c.check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	component := &ComponentHealth{
+		Status:    HealthOK,
+		Required:  c.required,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		component.Status = HealthError
+		component.Error = err.Error()
+	}
+	return component
+}
@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func healthyCheck(context.Context) error { return nil }
+
+func failingCheck(context.Context) error { return errors.New("connection refused") }
+
+func TestHealthService_Readiness_OK(t *testing.T) {
+	svc := NewHealthService()
+	svc.AddLivenessCheck("scheduler", healthyCheck)
+	svc.AddReadinessCheck("database", healthyCheck, true)
+
+	report := svc.Readiness(context.Background())
+	if report.Status != HealthOK || !report.Healthy() {
+		t.Errorf("expected ok, got %s", report.Status)
+	}
+	if len(report.Components) != 2 || report.Components["database"].Status != HealthOK {
+		t.Errorf("unexpected components: %+v", report.Components)
+	}
+}
+
+func TestHealthService_Readiness_OptionalFailureDegrades(t *testing.T) {
+	svc := NewHealthService()
+	svc.AddReadinessCheck("database", healthyCheck, true)
+	svc.AddReadinessCheck("smtp", failingCheck, false)
+
+	report := svc.Readiness(context.Background())
+	if report.Status != HealthDegraded || !report.Healthy() {
+		t.Errorf("expected degraded and healthy, got %s", report.Status)
+	}
+	smtp := report.Components["smtp"]
+	if smtp.Status != HealthError || smtp.Error != "connection refused" || smtp.Required {
+		t.Errorf("unexpected smtp component: %+v", smtp)
+	}
+}
+
+func TestHealthService_Readiness_RequiredFailureUnavailable(t *testing.T) {
+	svc := NewHealthService()
+	svc.AddReadinessCheck("database", failingCheck, true)
+	svc.AddReadinessCheck("smtp", failingCheck, false)
+
+	report := svc.Readiness(context.Background())
+	if report.Status != HealthUnavailable || report.Healthy() {
+		t.Errorf("expected unavailable, got %s", report.Status)
+	}
+}
+
+func TestHealthService_Liveness_SkipsReadinessChecks(t *testing.T) {
+	svc := NewHealthService()
+	svc.AddLivenessCheck("websocket_hub", healthyCheck)
+	svc.AddReadinessCheck("database", failingCheck, true)
+
+	report := svc.Liveness(context.Background())
+	if report.Status != HealthOK {
+		t.Errorf("expected ok, got %s", report.Status)
+	}
+	if _, ok := report.Components["database"]; ok {
+		t.Error("liveness should not run readiness checks")
+	}
+}
+
+func TestHealthService_CheckTimeout(t *testing.T) {
+	svc := NewHealthService()
+	svc.SetTimeout(20 * time.Millisecond)
+	svc.AddLivenessCheck("websocket_hub", func(context.Context) error {
+		time.Sleep(time.Second) // Ignores its context
+		return nil
+	})
+
+	start := time.Now()
+	report := svc.Liveness(context.Background())
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("a stuck check should not block the probe")
+	}
+	if report.Status != HealthUnavailable || report.Components["websocket_hub"].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("expected a timed-out component, got %+v", report.Components["websocket_hub"])
+	}
+}
@@ -0,0 +1,251 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrJobNotFound is returned when the requested job does not exist or belongs to another user
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobQueueFull is returned when too many jobs are waiting for a worker
+	ErrJobQueueFull = errors.New("too many jobs waiting, try again later")
+)
+
+const (
+	// DefaultJobWorkers is how many jobs run at the same time
+	DefaultJobWorkers = 2
+	// jobQueueSize bounds the jobs waiting for a worker
+	jobQueueSize = 100
+	// maxJobList bounds the jobs listed at once
+	maxJobList = 100
+	// jobInterruptedError is the error of the jobs a server stop left unfinished
+	jobInterruptedError = "interrupted by a server restart"
+)
+
+// JobFunc does the work of a background job and returns its result, stored as
+// JSON. It should stop when ctx is cancelled.
+type JobFunc func(ctx context.Context, progress *JobProgress) (any, error)
+
+// JobProgress reports the progress of a running job
+type JobProgress struct {
+	service *JobService
+	job     *entity.Job
+}
+
+// Report records that progress of total items are processed, total being zero
+// when unknown, and the current step
+func (p *JobProgress) Report(progress, total int, message string) {
+	p.job.Progress, p.job.Total, p.job.Message = progress, total, message
+	p.service.save(p.job)
+}
+
+// queuedJob is a job waiting for a worker
+type queuedJob struct {
+	job *entity.Job
+	fn  JobFunc
+}
+
+// JobService runs long-running work (imports, exports, retention runs,
+// catalog syncs) on a pool of workers, outside the HTTP requests that start
+// it. Jobs are stored with their progress and result, so they are followed
+// with GET /jobs/:id; a stop fails the unfinished ones on the next start.
+type JobService struct {
+	repo    repository.JobRepository
+	workers int
+	logger  *zap.Logger
+	queue   chan queuedJob
+
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewJobService creates a job service running workers jobs at a time
+func NewJobService(repo repository.JobRepository, workers int, logger *zap.Logger) *JobService {
+	if workers <= 0 {
+		workers = DefaultJobWorkers
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &JobService{
+		repo:    repo,
+		workers: workers,
+		logger:  logger,
+		queue:   make(chan queuedJob, jobQueueSize),
+	}
+}
+
+// Submit stores a pending job and queues it for a worker
+func (s *JobService) Submit(ctx context.Context, jobType entity.JobType, userID string, fn JobFunc) (*entity.Job, error) {
+	job := &entity.Job{
+		ID:        uuid.New().String(),
+		Type:      jobType,
+		Status:    entity.JobPending,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
+	}
+	snapshot := *job
+
+	select {
+	case s.queue <- queuedJob{job: job, fn: fn}:
+	default:
+		s.finish(job, nil, ErrJobQueueFull)
+		return nil, ErrJobQueueFull
+	}
+
+	s.logger.Info("Job submitted", zap.String("job_id", job.ID), zap.String("type", string(jobType)), zap.String("user_id", userID))
+	return &snapshot, nil
+}
+
+// Get returns a job. Users get their own jobs; administrators any job.
+func (s *JobService) Get(ctx context.Context, id, userID string, admin bool) (*entity.Job, error) {
+	job, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && job.CreatedBy != userID && !admin) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// List returns the latest jobs of a type, every type when empty. Users list
+// their own jobs; administrators every job.
+func (s *JobService) List(ctx context.Context, jobType entity.JobType, userID string, admin bool, limit int) ([]*entity.Job, error) {
+	if limit <= 0 || limit > maxJobList {
+		limit = maxJobList
+	}
+	createdBy := userID
+	if admin {
+		createdBy = ""
+	}
+	jobs, err := s.repo.FindRecent(ctx, jobType, createdBy, limit)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []*entity.Job{}
+	}
+	return jobs, nil
+}
+
+// Start fails the jobs a previous stop left unfinished and starts the workers
+func (s *JobService) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	if n, err := s.repo.FailUnfinished(ctx, jobInterruptedError, time.Now()); err != nil {
+		s.logger.Error("Failed to fail interrupted jobs", zap.Error(err))
+	} else if n > 0 {
+		s.logger.Warn("Failed jobs interrupted by a server restart", zap.Int64("count", n))
+	}
+
+	for i := 0; i < s.workers; i++ {
+		s.wg.Add(1)
+		go s.work(ctx)
+	}
+}
+
+// Stop cancels the running jobs and waits for the workers. Queued jobs stay
+// pending and are failed on the next start.
+func (s *JobService) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.cancel()
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// work runs queued jobs until ctx is cancelled
+func (s *JobService) work(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case queued := <-s.queue:
+			s.run(ctx, queued.job, queued.fn)
+		}
+	}
+}
+
+// run does the work of a job and records its outcome
+func (s *JobService) run(ctx context.Context, job *entity.Job, fn JobFunc) {
+	now := time.Now()
+	job.Status = entity.JobRunning
+	job.StartedAt = &now
+	s.save(job)
+
+	result, err := s.call(ctx, job, fn)
+	s.finish(job, result, err)
+}
+
+// call runs fn, turning a panic into an error so that it only fails its job
+func (s *JobService) call(ctx context.Context, job *entity.Job, fn JobFunc) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return fn(ctx, &JobProgress{service: s, job: job})
+}
+
+// finish records the result or the error of a job
+func (s *JobService) finish(job *entity.Job, result any, err error) {
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Status = entity.JobCompleted
+	if err == nil && result != nil {
+		job.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		job.Status = entity.JobFailed
+		job.Error = err.Error()
+	}
+	s.save(job)
+
+	fields := []zap.Field{zap.String("job_id", job.ID), zap.String("type", string(job.Type))}
+	if err != nil {
+		s.logger.Warn("Job failed", append(fields, zap.Error(err))...)
+		return
+	}
+	s.logger.Info("Job completed", fields...)
+}
+
+// save stores the state of a job. It outlives the request that started the
+// job, so it does not use its context.
+func (s *JobService) save(job *entity.Job) {
+	if err := s.repo.Update(context.Background(), job); err != nil {
+		s.logger.Error("Failed to save job", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,207 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockJobRepo implements repository.JobRepository for tests, storing copies
+// of the jobs so that workers and tests do not share them
+type mockJobRepo struct {
+	mu   sync.Mutex
+	jobs map[string]entity.Job
+}
+
+func newMockJobRepo() *mockJobRepo {
+	return &mockJobRepo{jobs: make(map[string]entity.Job)}
+}
+
+func (m *mockJobRepo) Create(ctx context.Context, job *entity.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockJobRepo) Update(ctx context.Context, job *entity.Job) error {
+	return m.Create(ctx, job)
+}
+
+func (m *mockJobRepo) FindByID(ctx context.Context, id string) (*entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &job, nil
+}
+
+func (m *mockJobRepo) FindRecent(ctx context.Context, jobType entity.JobType, createdBy string, limit int) ([]*entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []*entity.Job
+	for _, job := range m.jobs {
+		if (jobType == "" || job.Type == jobType) && (createdBy == "" || job.CreatedBy == createdBy) {
+			job := job
+			jobs = append(jobs, &job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+func (m *mockJobRepo) FailUnfinished(ctx context.Context, reason string, at time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, job := range m.jobs {
+		if !job.Finished() {
+			job.Status, job.Error, job.CompletedAt = entity.JobFailed, reason, &at
+			m.jobs[id] = job
+			n++
+		}
+	}
+	return n, nil
+}
+
+func waitForJob(t *testing.T, svc *JobService, id string) *entity.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.Get(context.Background(), id, "", true)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if job.Finished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("job did not finish in time")
+	return nil
+}
+
+func TestJobService_RunsJobs(t *testing.T) {
+	svc := NewJobService(newMockJobRepo(), 2, nil)
+	svc.Start()
+	defer svc.Stop()
+	ctx := context.Background()
+
+	job, err := svc.Submit(ctx, entity.JobContentImport, "user-1", func(ctx context.Context, progress *JobProgress) (any, error) {
+		progress.Report(1, 2, "Halfway")
+		return map[string]int{"imported": 2}, nil
+	})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.Status != entity.JobPending || job.CreatedBy != "user-1" || job.Type != entity.JobContentImport {
+		t.Errorf("Unexpected submitted job: %+v", job)
+	}
+
+	done := waitForJob(t, svc, job.ID)
+	if done.Status != entity.JobCompleted || done.StartedAt == nil || done.CompletedAt == nil {
+		t.Fatalf("Expected a completed job, got %+v", done)
+	}
+	if done.Progress != 1 || done.Total != 2 || done.Message != "Halfway" {
+		t.Errorf("Expected the reported progress kept, got %+v", done)
+	}
+	var result map[string]int
+	if err := json.Unmarshal(done.Result, &result); err != nil || result["imported"] != 2 {
+		t.Errorf("Unexpected result %s: %v", done.Result, err)
+	}
+}
+
+func TestJobService_FailedJobs(t *testing.T) {
+	svc := NewJobService(newMockJobRepo(), 1, nil)
+	svc.Start()
+	defer svc.Stop()
+	ctx := context.Background()
+
+	failed, _ := svc.Submit(ctx, entity.JobRetention, "user-1", func(ctx context.Context, progress *JobProgress) (any, error) {
+		return nil, errors.New("disk full")
+	})
+	panicked, _ := svc.Submit(ctx, entity.JobRetention, "user-1", func(ctx context.Context, progress *JobProgress) (any, error) {
+		panic("boom")
+	})
+
+	if job := waitForJob(t, svc, failed.ID); job.Status != entity.JobFailed || job.Error != "disk full" {
+		t.Errorf("Expected the job failed with its error, got %+v", job)
+	}
+	if job := waitForJob(t, svc, panicked.ID); job.Status != entity.JobFailed || job.Error != "job panicked: boom" {
+		t.Errorf("Expected the panic to fail its job, got %+v", job)
+	}
+}
+
+func TestJobService_Ownership(t *testing.T) {
+	svc := NewJobService(newMockJobRepo(), 1, nil)
+	ctx := context.Background()
+	noop := func(ctx context.Context, progress *JobProgress) (any, error) { return nil, nil }
+
+	mine, _ := svc.Submit(ctx, entity.JobContentImport, "user-1", noop)
+	_, _ = svc.Submit(ctx, entity.JobExecutionExport, "user-2", noop)
+
+	if _, err := svc.Get(ctx, mine.ID, "user-2", false); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected another user's job to be hidden, got %v", err)
+	}
+	if _, err := svc.Get(ctx, mine.ID, "user-2", true); err != nil {
+		t.Errorf("Expected admins to get any job, got %v", err)
+	}
+	if _, err := svc.Get(ctx, "missing", "user-1", true); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+
+	if jobs, _ := svc.List(ctx, "", "user-1", false, 0); len(jobs) != 1 || jobs[0].ID != mine.ID {
+		t.Errorf("Expected the user's own jobs, got %+v", jobs)
+	}
+	if jobs, _ := svc.List(ctx, "", "user-1", true, 0); len(jobs) != 2 {
+		t.Errorf("Expected every job for admins, got %d", len(jobs))
+	}
+	if jobs, _ := svc.List(ctx, entity.JobExecutionExport, "user-1", true, 0); len(jobs) != 1 {
+		t.Errorf("Expected the jobs of the type, got %d", len(jobs))
+	}
+}
+
+func TestJobService_FailsInterruptedJobs(t *testing.T) {
+	repo := newMockJobRepo()
+	repo.jobs["old"] = entity.Job{ID: "old", Type: entity.JobRetention, Status: entity.JobRunning, CreatedAt: time.Now()}
+
+	svc := NewJobService(repo, 1, nil)
+	svc.Start()
+	defer svc.Stop()
+
+	job, _ := svc.Get(context.Background(), "old", "", true)
+	if job.Status != entity.JobFailed || job.Error != jobInterruptedError {
+		t.Errorf("Expected the interrupted job failed, got %+v", job)
+	}
+}
+
+func TestJobService_StopCancelsRunningJobs(t *testing.T) {
+	svc := NewJobService(newMockJobRepo(), 1, nil)
+	svc.Start()
+	started := make(chan struct{})
+
+	job, _ := svc.Submit(context.Background(), entity.JobTechniqueSync, "user-1", func(ctx context.Context, progress *JobProgress) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	<-started
+	svc.Stop()
+
+	done, _ := svc.Get(context.Background(), job.ID, "", true)
+	if done.Status != entity.JobFailed || done.Error != context.Canceled.Error() {
+		t.Errorf("Expected the running job cancelled, got %+v", done)
+	}
+}
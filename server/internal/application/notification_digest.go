@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// DefaultDigestCheckInterval is how often the due digests are looked for
+const DefaultDigestCheckInterval = time.Minute
+
+// holdEmail holds the email of a stored notification for the next digest when
+// the recipient is in quiet hours or batches emails. Critical notifications
+// are never held, nor emails whose hold could not be recorded.
+func (s *NotificationService) holdEmail(setting *entity.NotificationSettings, notification *entity.Notification) bool {
+	if entity.IsCriticalNotification(notification.Type) {
+		return false
+	}
+	if setting.Digest == entity.DigestOff && !setting.QuietHours.Contains(time.Now()) {
+		return false
+	}
+	if err := s.notificationRepo.MarkDigestPending(context.Background(), notification.ID); err != nil {
+		s.logger.Warn("Failed to hold email for the digest, sending it now",
+			zap.String("notification_id", notification.ID), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// SendDigests emails the held notifications of the users whose digest is
+// due: once their quiet hours are over and, when they batch emails, a digest
+// period after the oldest held notification. A digest that fails to send is
+// retried on the next check.
+func (s *NotificationService) SendDigests(ctx context.Context, now time.Time) {
+	settings, err := s.notificationRepo.FindAllEnabledSettings(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load notification settings for digests", zap.Error(err))
+		return
+	}
+
+	for _, setting := range settings {
+		if setting.EmailAddress == "" || setting.QuietHours.Contains(now) {
+			continue
+		}
+		held, err := s.notificationRepo.FindDigestPending(ctx, setting.UserID)
+		if err != nil {
+			s.logger.Warn("Failed to load held notifications", zap.String("user_id", setting.UserID), zap.Error(err))
+			continue
+		}
+		if len(held) == 0 {
+			continue
+		}
+		if period := setting.Digest.Period(); period > 0 && now.Sub(held[0].CreatedAt) < period {
+			continue
+		}
+
+		if err := s.sendDigest(setting, held); err != nil {
+			s.logger.Error("Failed to send digest", zap.String("user_id", setting.UserID), zap.Error(err))
+			continue
+		}
+		ids := make([]string, len(held))
+		for i, notification := range held {
+			ids[i] = notification.ID
+		}
+		if err := s.notificationRepo.MarkDigestSent(ctx, ids, now); err != nil {
+			s.logger.Error("Failed to record sent digest", zap.String("user_id", setting.UserID), zap.Error(err))
+		}
+	}
+}
+
+// sendDigest emails held notifications as one message, oldest first
+func (s *NotificationService) sendDigest(setting *entity.NotificationSettings, held []*entity.Notification) error {
+	lines := make([]string, len(held))
+	for i, notification := range held {
+		lines[i] = fmt.Sprintf("- %s: %s\n  %s\n  %s",
+			notification.CreatedAt.Format(time.RFC1123), notification.Title, notification.Message,
+			s.notificationLink(setting.UserID, notification))
+	}
+
+	data := map[string]any{
+		"Count":         len(held),
+		"Since":         held[0].CreatedAt.Format(time.RFC1123),
+		"Notifications": strings.Join(lines, "\n\n"),
+		"Link":          strings.TrimRight(s.dashboardURL, "/"),
+	}
+	return s.sendTemplate(setting.EmailAddress, entity.DigestEmailTemplate(), data)
+}
+
+// StartDigests starts sending the due digests every interval
+func (s *NotificationService) StartDigests(interval time.Duration) {
+	s.digestMu.Lock()
+	defer s.digestMu.Unlock()
+	if s.digestStop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	s.digestStop = stop
+
+	s.digestWG.Add(1)
+	go func() {
+		defer s.digestWG.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				s.SendDigests(context.Background(), now)
+			}
+		}
+	}()
+}
+
+// StopDigests stops sending digests. Held emails are sent after a restart.
+func (s *NotificationService) StopDigests() {
+	s.digestMu.Lock()
+	stop := s.digestStop
+	s.digestStop = nil
+	s.digestMu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		s.digestWG.Wait()
+	}
+}
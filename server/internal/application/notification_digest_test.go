@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// newDigestTestService returns a notification service emailing through a
+// fake SMTP server accepting one message
+func newDigestTestService(t *testing.T, setting *entity.NotificationSettings) (*NotificationService, *mockNotificationRepo, <-chan string) {
+	t.Helper()
+	addr, dataCh := fakeSMTPServer(t)
+	host, port := "", 0
+	_, _ = fmt.Sscanf(strings.Replace(addr, ":", " ", 1), "%s %d", &host, &port)
+
+	repo := newMockNotificationRepo()
+	repo.settings[setting.ID] = setting
+	smtpConfig := &entity.SMTPConfig{Host: host, Port: port, From: "noreply@autostrike.test"}
+	svc := NewNotificationService(repo, &mockUserRepoForNotification{}, smtpConfig, "https://autostrike.test", nil)
+	return svc, repo, dataCh
+}
+
+func TestNotificationService_DigestBatchesEmails(t *testing.T) {
+	setting := &entity.NotificationSettings{
+		ID: "s1", UserID: "user-1", Enabled: true, EmailAddress: "user@example.com",
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
+		Digest:      entity.DigestHourly,
+	}
+	svc, repo, dataCh := newDigestTestService(t, setting)
+	ctx := context.Background()
+
+	for _, id := range []string{"exec-1", "exec-2"} {
+		if err := svc.NotifyExecutionFailed(ctx, &entity.Execution{ID: id}, "Discovery", "agent lost"); err != nil {
+			t.Fatalf("NotifyExecutionFailed failed: %v", err)
+		}
+	}
+	held, _ := repo.FindDigestPending(ctx, "user-1")
+	if len(held) != 2 {
+		t.Fatalf("Expected both emails to be held, got %d", len(held))
+	}
+
+	// Not due before a digest period
+	svc.SendDigests(ctx, time.Now())
+	select {
+	case <-dataCh:
+		t.Fatal("Expected no email before the digest is due")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	now := time.Now().Add(time.Hour)
+	svc.SendDigests(ctx, now)
+	select {
+	case data := <-dataCh:
+		if !strings.Contains(data, "Subject: AutoStrike: 2 new notification(s)") {
+			t.Errorf("Unexpected digest subject: %s", data)
+		}
+		if !strings.Contains(data, "/executions/exec-1") || !strings.Contains(data, "/executions/exec-2") {
+			t.Errorf("Expected a link to each execution: %s", data)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the digest")
+	}
+
+	for _, notification := range held {
+		if notification.DigestPending || notification.SentAt == nil || !notification.SentAt.Equal(now) {
+			t.Errorf("Expected the notification to be sent in the digest, got %+v", notification)
+		}
+	}
+}
+
+func TestNotificationService_QuietHoursHoldEmails(t *testing.T) {
+	now := time.Now().UTC()
+	setting := &entity.NotificationSettings{
+		ID: "s1", UserID: "user-1", Enabled: true, EmailAddress: "user@example.com",
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
+		QuietHours: &entity.QuietHours{
+			Start: now.Add(-2 * time.Hour).Format("15:04"),
+			End:   now.Add(2 * time.Hour).Format("15:04"),
+		},
+	}
+	svc, repo, dataCh := newDigestTestService(t, setting)
+	ctx := context.Background()
+
+	if err := svc.NotifyExecutionFailed(ctx, &entity.Execution{ID: "exec-1"}, "Discovery", "agent lost"); err != nil {
+		t.Fatalf("NotifyExecutionFailed failed: %v", err)
+	}
+	if held, _ := repo.FindDigestPending(ctx, "user-1"); len(held) != 1 {
+		t.Fatalf("Expected the email to be held during quiet hours, got %d", len(held))
+	}
+
+	svc.SendDigests(ctx, now)
+	select {
+	case <-dataCh:
+		t.Fatal("Expected no email during quiet hours")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Without a digest, held emails go out as soon as quiet hours end
+	svc.SendDigests(ctx, now.Add(3*time.Hour))
+	select {
+	case data := <-dataCh:
+		if !strings.Contains(data, "Execution Failed: Discovery") {
+			t.Errorf("Expected the held notification in the digest: %s", data)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the digest")
+	}
+	if held, _ := repo.FindDigestPending(ctx, "user-1"); len(held) != 0 {
+		t.Errorf("Expected no held email, got %d", len(held))
+	}
+}
+
+func TestNotificationService_HoldEmail(t *testing.T) {
+	repo := newMockNotificationRepo()
+	svc := NewNotificationService(repo, &mockUserRepoForNotification{}, nil, "", nil)
+	batching := &entity.NotificationSettings{UserID: "user-1", Digest: entity.DigestDaily}
+	immediate := &entity.NotificationSettings{UserID: "user-1"}
+
+	for _, n := range []*entity.Notification{
+		{ID: "critical", Type: entity.NotificationCriticalUndetected},
+		{ID: "security", Type: entity.NotificationSecurityAlert},
+		{ID: "completed", Type: entity.NotificationExecutionCompleted},
+	} {
+		repo.notifications[n.ID] = n
+	}
+
+	if svc.holdEmail(batching, repo.notifications["critical"]) || svc.holdEmail(batching, repo.notifications["security"]) {
+		t.Error("Expected critical notifications to be emailed at once")
+	}
+	if svc.holdEmail(immediate, repo.notifications["completed"]) {
+		t.Error("Expected emails to be sent at once without digest or quiet hours")
+	}
+	if !svc.holdEmail(batching, repo.notifications["completed"]) || !repo.notifications["completed"].DigestPending {
+		t.Error("Expected the email to be held for the digest")
+	}
+}
+
+func TestNotificationService_StartStopDigests(t *testing.T) {
+	svc := NewNotificationService(newMockNotificationRepo(), &mockUserRepoForNotification{}, nil, "", nil)
+	svc.StartDigests(time.Millisecond)
+	svc.StartDigests(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	svc.StopDigests()
+	svc.StopDigests()
+}
@@ -0,0 +1,348 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Default endpoints of the incident channels
+const (
+	DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	DefaultOpsgenieAlertsURL  = "https://api.opsgenie.com/v2/alerts"
+)
+
+// PagerDutyEvent is the body of a PagerDuty Events API v2 trigger
+type PagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     PagerDutyPayload `json:"payload"`
+	Links       []PagerDutyLink  `json:"links,omitempty"`
+	Client      string           `json:"client,omitempty"`
+	ClientURL   string           `json:"client_url,omitempty"`
+}
+
+// PagerDutyPayload describes the incident of a PagerDuty event
+type PagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"` // critical, error, warning or info
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// PagerDutyLink is a link attached to a PagerDuty incident
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text"`
+}
+
+// OpsgenieAlert is the body of an Opsgenie alert creation
+type OpsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"` // P1 (critical) to P5
+	Source      string            `json:"source"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// incidentSeverities maps notification types to PagerDuty severities and
+// Opsgenie priorities; other types page as warnings
+var incidentSeverities = map[entity.NotificationType]struct{ pagerDuty, opsgenie string }{
+	entity.NotificationCriticalUndetected: {"critical", "P1"},
+	entity.NotificationScoreAlert:         {"error", "P2"},
+	entity.NotificationSecurityAlert:      {"error", "P2"},
+}
+
+// SetIncidentAlerting applies the alerting settings of schedules to the
+// incidents of their executions, and overrides the channel endpoints when set,
+// e.g. for the Opsgenie EU region
+func (s *NotificationService) SetIncidentAlerting(scheduleRepo repository.ScheduleRepository, pagerDutyURL, opsgenieURL string) {
+	s.scheduleRepo = scheduleRepo
+	if pagerDutyURL != "" {
+		s.pagerDutyURL = pagerDutyURL
+	}
+	if opsgenieURL != "" {
+		s.opsgenieURL = opsgenieURL
+	}
+}
+
+// shouldSendPagerDuty checks if a PagerDuty incident should be triggered for a notification type
+func shouldSendPagerDuty(setting *entity.NotificationSettings, notificationType entity.NotificationType) bool {
+	return setting.Preferences.Has(notificationType, entity.ChannelPagerDuty) && setting.PagerDutyRoutingKey != ""
+}
+
+// shouldSendOpsgenie checks if an Opsgenie alert should be created for a notification type
+func shouldSendOpsgenie(setting *entity.NotificationSettings, notificationType entity.NotificationType) bool {
+	return setting.Preferences.Has(notificationType, entity.ChannelOpsgenie) && setting.OpsgenieAPIKey != ""
+}
+
+// pages reports whether a setting triggers incidents for a notification type
+func pages(setting *entity.NotificationSettings, notificationType entity.NotificationType) bool {
+	return shouldSendPagerDuty(setting, notificationType) || shouldSendOpsgenie(setting, notificationType)
+}
+
+// incidentDedupKey groups the incidents of a notification type for an
+// execution, so a redelivery updates the open incident instead of paging again
+func incidentDedupKey(notification *entity.Notification) string {
+	if executionID, _ := notification.Data["ExecutionID"].(string); executionID != "" {
+		return "autostrike-" + string(notification.Type) + "-" + executionID
+	}
+	return "autostrike-" + string(notification.Type) + "-" + notification.ID
+}
+
+// incidentDetails flattens the notification data shown on incidents, without the links
+func incidentDetails(notification *entity.Notification) map[string]string {
+	details := make(map[string]string, len(notification.Data))
+	for key, value := range notification.Data {
+		if key == "Link" || key == "DashboardURL" || value == nil {
+			continue
+		}
+		details[key] = fmt.Sprint(value)
+	}
+	return details
+}
+
+// buildPagerDutyEvent renders a notification as a PagerDuty trigger
+func buildPagerDutyEvent(routingKey string, notification *entity.Notification) *PagerDutyEvent {
+	severity := "warning"
+	if s, ok := incidentSeverities[notification.Type]; ok {
+		severity = s.pagerDuty
+	}
+	details := make(map[string]any)
+	for key, value := range incidentDetails(notification) {
+		details[key] = value
+	}
+	details["message"] = notification.Message
+	scenarioName, _ := notification.Data["ScenarioName"].(string)
+
+	event := &PagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: "trigger",
+		DedupKey:    incidentDedupKey(notification),
+		Payload: PagerDutyPayload{
+			Summary:       notification.Title,
+			Source:        "autostrike",
+			Severity:      severity,
+			Component:     scenarioName,
+			Group:         "breach-and-attack-simulation",
+			Class:         string(notification.Type),
+			CustomDetails: details,
+		},
+		Client: "AutoStrike",
+	}
+	if link, _ := notification.Data["Link"].(string); link != "" {
+		event.ClientURL = link
+		event.Links = []PagerDutyLink{{Href: link, Text: "View in AutoStrike"}}
+	}
+	return event
+}
+
+// buildOpsgenieAlert renders a notification as an Opsgenie alert
+func buildOpsgenieAlert(notification *entity.Notification) *OpsgenieAlert {
+	priority := "P3"
+	if s, ok := incidentSeverities[notification.Type]; ok {
+		priority = s.opsgenie
+	}
+	description := notification.Message
+	if undetected, _ := notification.Data["Undetected"].(string); undetected != "" {
+		description += "\n\n" + undetected
+	}
+	if link, _ := notification.Data["Link"].(string); link != "" {
+		description += "\n\n" + link
+	}
+
+	details := incidentDetails(notification)
+	delete(details, "Undetected")
+	return &OpsgenieAlert{
+		Message:     truncateRunes(notification.Title, 130), // Opsgenie rejects longer messages
+		Alias:       incidentDedupKey(notification),
+		Description: description,
+		Priority:    priority,
+		Source:      "AutoStrike",
+		Tags:        []string{"autostrike", string(notification.Type)},
+		Details:     details,
+	}
+}
+
+// truncateRunes shortens a string to at most n runes
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// deliverIncidents triggers the PagerDuty and Opsgenie incidents the setting
+// selected for a linked notification, through the delivery queue when one is
+// configured so failed pages are retried
+func (s *NotificationService) deliverIncidents(setting *entity.NotificationSettings, notification *entity.Notification) {
+	if shouldSendPagerDuty(setting, notification.Type) {
+		event := buildPagerDutyEvent(setting.PagerDutyRoutingKey, notification)
+		if s.deliveries != nil {
+			s.enqueueWebhook(setting.UserID, entity.ChannelPagerDuty, notification.Type, s.pagerDutyURL, event)
+		} else {
+			s.sendIncidentAsync(entity.ChannelPagerDuty, s.pagerDutyURL, nil, notification.Type, event)
+		}
+	}
+	if shouldSendOpsgenie(setting, notification.Type) {
+		alert := buildOpsgenieAlert(notification)
+		if s.deliveries != nil {
+			s.enqueueWebhook(setting.UserID, entity.ChannelOpsgenie, notification.Type, s.opsgenieURL, alert)
+		} else {
+			s.sendIncidentAsync(entity.ChannelOpsgenie, s.opsgenieURL, opsgenieHeaders(setting.OpsgenieAPIKey), notification.Type, alert)
+		}
+	}
+}
+
+// opsgenieHeaders authenticates a request with an Opsgenie API integration key
+func opsgenieHeaders(apiKey string) map[string]string {
+	return map[string]string{"Authorization": "GenieKey " + apiKey}
+}
+
+func (s *NotificationService) sendIncidentAsync(
+	channel entity.NotificationChannel,
+	url string,
+	headers map[string]string,
+	event entity.NotificationType,
+	payload any,
+) {
+	go func() {
+		s.webhookSemaphore <- struct{}{}
+		defer func() { <-s.webhookSemaphore }()
+		body, err := json.Marshal(payload)
+		if err == nil {
+			_, err = postWebhook(context.Background(), s.webhookClient, url, body, headers)
+		}
+		if err != nil {
+			s.logger.Error("Failed to trigger incident",
+				zap.String("channel", string(channel)),
+				zap.String("event", string(event)),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// scheduleAlerting returns the alerting settings of the schedule that started
+// an execution, nil for executions started by hand or schedules without any
+func (s *NotificationService) scheduleAlerting(ctx context.Context, executionID string) *entity.ScheduleAlerting {
+	if s.scheduleRepo == nil {
+		return nil
+	}
+	run, err := s.scheduleRepo.FindRunByExecutionID(ctx, executionID)
+	if err != nil || run == nil {
+		return nil
+	}
+	schedule, err := s.scheduleRepo.FindByID(ctx, run.ScheduleID)
+	if err != nil || schedule == nil {
+		return nil
+	}
+	return schedule.Alerting
+}
+
+// incidentChannels selects the channels of a notification: the incident
+// channels when it pages, the other channels when it notifies
+func incidentChannels(notify, page bool) func(entity.NotificationChannel) bool {
+	return func(channel entity.NotificationChannel) bool {
+		if entity.IsIncidentChannel(channel) {
+			return page
+		}
+		return notify
+	}
+}
+
+// undetectedTechniques lists the results of an execution that ran without
+// being blocked or detected, for techniques of a paging severity
+func (s *NotificationService) undetectedTechniques(
+	ctx context.Context,
+	executionID string,
+	alerting *entity.ScheduleAlerting,
+) ([]string, []string) {
+	if s.resultRepo == nil || s.techniqueRepo == nil {
+		return nil, nil
+	}
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		s.logger.Warn("Failed to load results for critical alerts", zap.String("execution_id", executionID), zap.Error(err))
+		return nil, nil
+	}
+
+	var lines []string
+	severities := make(map[string]bool)
+	for _, result := range results {
+		if result.Status != entity.StatusSuccess || result.Detected {
+			continue
+		}
+		technique, err := s.techniqueRepo.FindByID(ctx, result.TechniqueID)
+		if err != nil || technique == nil || !alerting.PagesOnSeverity(technique.EffectiveSeverity()) {
+			continue
+		}
+		severities[technique.EffectiveSeverity()] = true
+		lines = append(lines, fmt.Sprintf("- %s (%s) on %s [%s]", technique.ID, technique.Name, result.AgentPaw, technique.EffectiveSeverity()))
+	}
+
+	names := make([]string, 0, len(severities))
+	for severity := range severities {
+		names = append(names, severity)
+	}
+	sort.Strings(names)
+	return lines, names
+}
+
+// processCriticalUndetected alerts on the critical-severity techniques of an
+// execution that ran undetected. Incident channels are skipped when the
+// schedule of the execution disables paging.
+func (s *NotificationService) processCriticalUndetected(
+	ctx context.Context,
+	setting *entity.NotificationSettings,
+	data map[string]any,
+	undetected, severities []string,
+	alerting *entity.ScheduleAlerting,
+) {
+	if len(undetected) == 0 || !setting.Preferences.Wants(entity.NotificationCriticalUndetected) {
+		return
+	}
+
+	alertData := make(map[string]any, len(data)+3)
+	for k, v := range data {
+		alertData[k] = v
+	}
+	alertData["UndetectedCount"] = len(undetected)
+	alertData["Undetected"] = strings.Join(undetected, "\n")
+	alertData["Severities"] = strings.Join(severities, "/")
+
+	scenarioName, _ := data["ScenarioName"].(string)
+	notification := &entity.Notification{
+		ID:     uuid.New().String(),
+		UserID: setting.UserID,
+		Type:   entity.NotificationCriticalUndetected,
+		Title:  fmt.Sprintf("Undetected %s Techniques: %s", alertData["Severities"], scenarioName),
+		Message: fmt.Sprintf("%d %s technique(s) of '%s' ran without being blocked or detected",
+			len(undetected), alertData["Severities"], scenarioName),
+		Data:      alertData,
+		CreatedAt: time.Now(),
+	}
+
+	if s.notificationRepo.CreateNotification(ctx, notification) != nil {
+		return
+	}
+	s.publishUnreadCount(ctx, notification.UserID)
+
+	s.deliverTo(setting, notification, nil, incidentChannels(true, alerting == nil || !alerting.Disabled))
+}
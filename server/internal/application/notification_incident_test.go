@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// incidentRequest is a page received by the test incident endpoints
+type incidentRequest struct {
+	path, auth string
+	body       map[string]any
+}
+
+func newIncidentServer(t *testing.T) (*httptest.Server, chan incidentRequest) {
+	t.Helper()
+	received := make(chan incidentRequest, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received <- incidentRequest{path: r.URL.Path, auth: r.Header.Get("Authorization"), body: body}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func waitIncident(t *testing.T, received chan incidentRequest) incidentRequest {
+	t.Helper()
+	select {
+	case req := <-received:
+		return req
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for an incident")
+	}
+	return incidentRequest{}
+}
+
+func expectNoIncident(t *testing.T, received chan incidentRequest) {
+	t.Helper()
+	select {
+	case req := <-received:
+		t.Errorf("Unexpected incident: %+v", req)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBuildIncidentPayloads(t *testing.T) {
+	notification := &entity.Notification{
+		ID:    "n1",
+		Type:  entity.NotificationCriticalUndetected,
+		Title: "Undetected critical Techniques: Ransomware chain",
+		Data: map[string]any{
+			"ScenarioName": "Ransomware chain", "ExecutionID": "exec-1", "Undetected": "- T1486 (Data Encrypted) on paw1",
+			"DashboardURL": "https://autostrike.local", "Link": "https://autostrike.local/executions/exec-1",
+		},
+	}
+
+	event := buildPagerDutyEvent("routing-key", notification)
+	if event.RoutingKey != "routing-key" || event.EventAction != "trigger" || event.Payload.Severity != "critical" {
+		t.Errorf("Unexpected PagerDuty event: %+v", event)
+	}
+	if event.DedupKey != "autostrike-critical_undetected-exec-1" || event.Payload.Component != "Ransomware chain" {
+		t.Errorf("Unexpected PagerDuty dedup key or component: %+v", event)
+	}
+	if len(event.Links) != 1 || event.Links[0].Href != "https://autostrike.local/executions/exec-1" {
+		t.Errorf("Expected a link to the execution, got %+v", event.Links)
+	}
+	if _, ok := event.Payload.CustomDetails["DashboardURL"]; ok {
+		t.Error("Did not expect links in the custom details")
+	}
+
+	alert := buildOpsgenieAlert(notification)
+	if alert.Priority != "P1" || alert.Alias != event.DedupKey || !strings.Contains(alert.Description, "T1486") {
+		t.Errorf("Unexpected Opsgenie alert: %+v", alert)
+	}
+
+	notification.Type = entity.NotificationAgentOffline
+	if buildPagerDutyEvent("k", notification).Payload.Severity != "warning" || buildOpsgenieAlert(notification).Priority != "P3" {
+		t.Error("Expected other notification types to page as warnings")
+	}
+}
+
+func TestNotificationService_PagesCriticalUndetected(t *testing.T) {
+	server, received := newIncidentServer(t)
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true,
+		PagerDutyRoutingKey: "routing-key", OpsgenieAPIKey: "genie-key",
+		Preferences: entity.NotificationPreferences{
+			entity.NotificationCriticalUndetected: {entity.ChannelPagerDuty, entity.ChannelOpsgenie},
+		},
+	}
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1486", AgentPaw: "paw1", Status: entity.StatusSuccess},
+		{ID: "r2", ExecutionID: "exec-1", TechniqueID: "T1082", AgentPaw: "paw1", Status: entity.StatusSuccess},
+		{ID: "r3", ExecutionID: "exec-1", TechniqueID: "T1490", AgentPaw: "paw1", Status: entity.StatusSuccess, Detected: true},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1486"] = &entity.Technique{ID: "T1486", Name: "Data Encrypted for Impact",
+		Metadata: map[string]string{entity.SeverityMetadataKey: "Critical"}}
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery",
+		Metadata: map[string]string{entity.SeverityMetadataKey: "low"}}
+	techRepo.techniques["T1490"] = &entity.Technique{ID: "T1490", Name: "Inhibit System Recovery",
+		Metadata: map[string]string{entity.SeverityMetadataKey: "critical"}}
+
+	svc := NewNotificationService(notificationRepo, nil, nil, "https://autostrike.local", nil)
+	svc.SetWebhookResults(resultRepo, techRepo, VerbosityMinimal)
+	svc.SetIncidentAlerting(nil, server.URL+"/v2/enqueue", server.URL+"/v2/alerts")
+
+	execution := &entity.Execution{ID: "exec-1", Score: &entity.SecurityScore{Overall: 90}}
+	if err := svc.NotifyExecutionCompleted(context.Background(), execution, "Ransomware chain"); err != nil {
+		t.Fatalf("NotifyExecutionCompleted() error = %v", err)
+	}
+
+	pages := map[string]incidentRequest{}
+	for i := 0; i < 2; i++ {
+		req := waitIncident(t, received)
+		pages[req.path] = req
+	}
+	pagerDuty, opsgenie := pages["/v2/enqueue"], pages["/v2/alerts"]
+	if pagerDuty.body["routing_key"] != "routing-key" || pagerDuty.body["payload"].(map[string]any)["severity"] != "critical" {
+		t.Errorf("Unexpected PagerDuty event: %+v", pagerDuty.body)
+	}
+	if opsgenie.auth != "GenieKey genie-key" || opsgenie.body["priority"] != "P1" {
+		t.Errorf("Unexpected Opsgenie alert: %q %+v", opsgenie.auth, opsgenie.body)
+	}
+	description, _ := opsgenie.body["description"].(string)
+	if !strings.Contains(description, "T1486") || strings.Contains(description, "T1082") || strings.Contains(description, "T1490") {
+		t.Errorf("Expected only the undetected critical technique, got %q", description)
+	}
+	expectNoIncident(t, received)
+}
+
+func TestNotificationService_ScheduleAlertingPaging(t *testing.T) {
+	server, received := newIncidentServer(t)
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true, ScoreAlertThreshold: 50,
+		PagerDutyRoutingKey: "routing-key",
+		Preferences: entity.NotificationPreferences{
+			entity.NotificationScoreAlert: {entity.ChannelPagerDuty},
+		},
+	}
+	scheduleRepo := newMockScheduleRepo()
+	scheduleRepo.schedules["sched-1"] = &entity.Schedule{ID: "sched-1", Alerting: &entity.ScheduleAlerting{ScoreThreshold: 80}}
+	scheduleRepo.runs["sched-1"] = []*entity.ScheduleRun{{ID: "run-1", ScheduleID: "sched-1", ExecutionID: "exec-1"}}
+
+	svc := NewNotificationService(notificationRepo, nil, nil, "", nil)
+	svc.SetIncidentAlerting(scheduleRepo, server.URL, "")
+	ctx := context.Background()
+
+	// Above the user's threshold, below the schedule's
+	_ = svc.NotifyExecutionCompleted(ctx, &entity.Execution{ID: "exec-1", Score: &entity.SecurityScore{Overall: 70}}, "Nightly")
+	req := waitIncident(t, received)
+	if req.body["dedup_key"] != "autostrike-score_alert-exec-1" {
+		t.Errorf("Unexpected PagerDuty event: %+v", req.body)
+	}
+
+	// Executions started by hand keep the user's threshold
+	_ = svc.NotifyExecutionCompleted(ctx, &entity.Execution{ID: "exec-2", Score: &entity.SecurityScore{Overall: 70}}, "Nightly")
+	expectNoIncident(t, received)
+
+	// Paging disabled on the schedule
+	scheduleRepo.schedules["sched-1"].Alerting = &entity.ScheduleAlerting{Disabled: true}
+	_ = svc.NotifyExecutionCompleted(ctx, &entity.Execution{ID: "exec-1", Score: &entity.SecurityScore{Overall: 10}}, "Nightly")
+	expectNoIncident(t, received)
+}
@@ -0,0 +1,61 @@
+package application
+
+import (
+	"strings"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// SetDeepLinks makes notification links signed per recipient, so they open the
+// related page after login instead of a plain dashboard URL
+func (s *NotificationService) SetDeepLinks(links *DeepLinkService) {
+	s.links = links
+}
+
+// withLink returns a copy of a notification whose data carries the "Link" the
+// recipient should open: a signed deep link when configured, else the page URL
+func (s *NotificationService) withLink(userID string, notification *entity.Notification) *entity.Notification {
+	data := make(map[string]any, len(notification.Data)+1)
+	for k, v := range notification.Data {
+		data[k] = v
+	}
+	data["Link"] = s.notificationLink(userID, notification)
+
+	linked := *notification
+	linked.Data = data
+	return &linked
+}
+
+// notificationLink builds the link of a notification for a recipient
+func (s *NotificationService) notificationLink(userID string, notification *entity.Notification) string {
+	kind, id := notificationTarget(notification)
+	dashboardURL := strings.TrimRight(s.dashboardURL, "/")
+	if kind == "" {
+		return dashboardURL
+	}
+
+	if s.links != nil {
+		token, err := s.links.Token(userID, notification.ID, kind, id)
+		if err == nil {
+			return dashboardURL + "/links/" + token
+		}
+		s.logger.Warn("Failed to sign notification link", zap.String("notification_id", notification.ID), zap.Error(err))
+	}
+
+	path, _ := deepLinkPath(kind, id)
+	return dashboardURL + path
+}
+
+// notificationTarget returns the page a notification is about, if any
+func notificationTarget(notification *entity.Notification) (DeepLinkKind, string) {
+	if executionID, _ := notification.Data["ExecutionID"].(string); executionID != "" {
+		return DeepLinkExecution, executionID
+	}
+	if notification.Type == entity.NotificationAgentOffline {
+		paw, _ := notification.Data["Paw"].(string)
+		return DeepLinkAgent, paw
+	}
+	return "", ""
+}
@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultNotificationRetention is how long read notifications are kept
+const DefaultNotificationRetention = 90 * 24 * time.Hour
+
+// MaxNotificationBulkDelete is the most notifications deleted by ID at once
+const MaxNotificationBulkDelete = 500
+
+// ErrNotificationNotFound is returned for a notification that does not exist
+// or belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found or not owned by user")
+
+// SetRetention sets the age after which read notifications are purged. Zero
+// keeps them until deleted.
+func (s *NotificationService) SetRetention(retention time.Duration) {
+	s.retention = retention
+}
+
+// Retention returns the age after which read notifications are purged
+func (s *NotificationService) Retention() time.Duration {
+	return s.retention
+}
+
+// DeleteForUser deletes a notification only if owned by the user
+func (s *NotificationService) DeleteForUser(ctx context.Context, id string, userID string) error {
+	deleted, err := s.notificationRepo.DeleteNotifications(ctx, userID, []string{id})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotificationNotFound
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
+}
+
+// DeleteManyForUser deletes the given notifications of a user, and all their
+// read notifications when read is set. Notifications of other users are
+// ignored. Returns the number deleted.
+func (s *NotificationService) DeleteManyForUser(ctx context.Context, userID string, ids []string, read bool) (int64, error) {
+	deleted, err := s.notificationRepo.DeleteNotifications(ctx, userID, ids)
+	if err != nil {
+		return 0, err
+	}
+	if read {
+		n, err := s.notificationRepo.DeleteReadNotifications(ctx, userID)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	if deleted > 0 {
+		s.publishUnreadCount(ctx, userID)
+	}
+	return deleted, nil
+}
+
+// PurgeRead deletes the read notifications older than the retention. Unread
+// notifications and those still held for a digest are kept. Returns the
+// number deleted.
+func (s *NotificationService) PurgeRead(ctx context.Context, now time.Time) (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	purged, err := s.notificationRepo.PurgeReadNotifications(ctx, now.Add(-s.retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge notifications: %w", err)
+	}
+	if purged > 0 {
+		s.logger.Info("Purged read notifications", zap.Int64("notifications", purged))
+	}
+	return purged, nil
+}
@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestNotificationService_DeleteForUser(t *testing.T) {
+	repo := newMockNotificationRepo()
+	svc := NewNotificationService(repo, &mockUserRepoForNotification{}, nil, "", nil)
+	var published []int
+	svc.SetUnreadCountListener(func(userID string, unread int) { published = append(published, unread) })
+	repo.notifications["mine"] = &entity.Notification{ID: "mine", UserID: "user-1"}
+	repo.notifications["theirs"] = &entity.Notification{ID: "theirs", UserID: "user-2"}
+	ctx := context.Background()
+
+	if err := svc.DeleteForUser(ctx, "theirs", "user-1"); !errors.Is(err, ErrNotificationNotFound) {
+		t.Errorf("Expected ErrNotificationNotFound, got %v", err)
+	}
+	if err := svc.DeleteForUser(ctx, "mine", "user-1"); err != nil {
+		t.Fatalf("DeleteForUser failed: %v", err)
+	}
+	if _, ok := repo.notifications["mine"]; ok {
+		t.Error("Expected the notification to be deleted")
+	}
+	if len(published) != 1 || published[0] != 0 {
+		t.Errorf("Expected the unread count to be pushed once, got %v", published)
+	}
+}
+
+func TestNotificationService_DeleteManyForUser(t *testing.T) {
+	repo := newMockNotificationRepo()
+	svc := NewNotificationService(repo, &mockUserRepoForNotification{}, nil, "", nil)
+	for _, n := range []*entity.Notification{
+		{ID: "a", UserID: "user-1"},
+		{ID: "b", UserID: "user-1", Read: true},
+		{ID: "c", UserID: "user-1"},
+		{ID: "d", UserID: "user-2", Read: true},
+	} {
+		repo.notifications[n.ID] = n
+	}
+
+	deleted, err := svc.DeleteManyForUser(context.Background(), "user-1", []string{"a", "d"}, true)
+	if err != nil || deleted != 2 {
+		t.Fatalf("Expected 2 notifications deleted, got %d (%v)", deleted, err)
+	}
+	if len(repo.notifications) != 2 || repo.notifications["c"] == nil || repo.notifications["d"] == nil {
+		t.Errorf("Unexpected remaining notifications: %v", repo.notifications)
+	}
+}
+
+func TestNotificationService_PurgeRead(t *testing.T) {
+	repo := newMockNotificationRepo()
+	svc := NewNotificationService(repo, &mockUserRepoForNotification{}, nil, "", nil)
+	now := time.Now()
+	old := now.Add(-DefaultNotificationRetention - time.Hour)
+	for _, n := range []*entity.Notification{
+		{ID: "old-read", Read: true, CreatedAt: old},
+		{ID: "old-unread", CreatedAt: old},
+		{ID: "old-held", Read: true, DigestPending: true, CreatedAt: old},
+		{ID: "recent-read", Read: true, CreatedAt: now},
+	} {
+		repo.notifications[n.ID] = n
+	}
+
+	purged, err := svc.PurgeRead(context.Background(), now)
+	if err != nil || purged != 1 {
+		t.Fatalf("Expected 1 notification purged, got %d (%v)", purged, err)
+	}
+	if _, ok := repo.notifications["old-read"]; ok {
+		t.Error("Expected the old read notification to be purged")
+	}
+
+	// A zero retention keeps everything
+	svc.SetRetention(0)
+	if purged, _ := svc.PurgeRead(context.Background(), now.Add(365*24*time.Hour)); purged != 0 || svc.Retention() != 0 {
+		t.Errorf("Expected nothing purged without retention, got %d", purged)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
 	"net/smtp"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -21,11 +24,31 @@ import (
 type NotificationService struct {
 	notificationRepo repository.NotificationRepository
 	userRepo         repository.UserRepository
-	smtpConfig       *entity.SMTPConfig
+	smtpMu           sync.RWMutex
+	smtpConfig       *entity.SMTPConfig              // Live configuration, swapped when set through the API
+	envSMTPConfig    *entity.SMTPConfig              // Configuration from the environment, restored when the stored one is deleted
+	smtpStore        repository.SMTPConfigRepository // Optional, keeps the configuration set through the API
+	secrets          *SecretService                  // Seals the stored SMTP password
 	dashboardURL     string
 	templates        map[entity.NotificationType]entity.EmailTemplate
 	logger           *zap.Logger
 	emailSemaphore   chan struct{} // Bounds concurrent email goroutines
+	webhookSemaphore chan struct{} // Bounds concurrent webhook goroutines
+	webhookClient    *http.Client
+	resultRepo       repository.ResultRepository // Optional, adds results to completion webhooks
+	techniqueRepo    repository.TechniqueRepository
+	webhookVerbosity ResultVerbosity
+	deliveries       *WebhookDeliveryService       // Optional, queues webhooks for signing and retries
+	links            *DeepLinkService              // Optional, signs per-recipient deep links
+	scheduleRepo     repository.ScheduleRepository // Optional, applies the alerting settings of schedules
+	pagerDutyURL     string
+	opsgenieURL      string
+	unreadListener   func(userID string, unread int)
+	retention        time.Duration // Age after which read notifications are purged, 0 keeps them
+
+	digestMu   sync.Mutex
+	digestStop chan struct{} // Closed to stop the digest worker, nil when not running
+	digestWG   sync.WaitGroup
 }
 
 // NewNotificationService creates a new notification service
@@ -43,30 +66,50 @@ func NewNotificationService(
 		notificationRepo: notificationRepo,
 		userRepo:         userRepo,
 		smtpConfig:       smtpConfig,
+		envSMTPConfig:    smtpConfig,
 		dashboardURL:     dashboardURL,
 		templates:        entity.DefaultEmailTemplates(),
 		logger:           logger,
 		emailSemaphore:   make(chan struct{}, 10), // Max 10 concurrent email sends
+		webhookSemaphore: make(chan struct{}, 10),
+		webhookClient:    &http.Client{Timeout: webhookTimeout},
+		webhookVerbosity: VerbosityMinimal,
+		pagerDutyURL:     DefaultPagerDutyEventsURL,
+		opsgenieURL:      DefaultOpsgenieAlertsURL,
+		retention:        DefaultNotificationRetention,
 	}
 }
 
 // SetSMTPConfig updates the SMTP configuration
 func (s *NotificationService) SetSMTPConfig(config *entity.SMTPConfig) {
+	s.smtpMu.Lock()
+	defer s.smtpMu.Unlock()
 	s.smtpConfig = config
 }
 
+// smtp returns the live SMTP configuration
+func (s *NotificationService) smtp() *entity.SMTPConfig {
+	s.smtpMu.RLock()
+	defer s.smtpMu.RUnlock()
+	return s.smtpConfig
+}
+
 // GetSMTPConfig returns the current SMTP configuration (without password)
 func (s *NotificationService) GetSMTPConfig() *entity.SMTPConfig {
-	if s.smtpConfig == nil {
+	config := s.smtp()
+	if config == nil {
 		return nil
 	}
 	// Return a copy without the password
 	return &entity.SMTPConfig{
-		Host:     s.smtpConfig.Host,
-		Port:     s.smtpConfig.Port,
-		Username: s.smtpConfig.Username,
-		From:     s.smtpConfig.From,
-		UseTLS:   s.smtpConfig.UseTLS,
+		Host:      config.Host,
+		Port:      config.Port,
+		Username:  config.Username,
+		From:      config.From,
+		UseTLS:    config.UseTLS,
+		Source:    config.Source,
+		UpdatedBy: config.UpdatedBy,
+		UpdatedAt: config.UpdatedAt,
 	}
 }
 
@@ -111,6 +154,25 @@ func (s *NotificationService) GetUnreadCount(ctx context.Context, userID string)
 	return len(notifications), nil
 }
 
+// SetUnreadCountListener sets the function told the unread count of a user
+// whenever a notification is created or read, to push it to their dashboards
+func (s *NotificationService) SetUnreadCountListener(listener func(userID string, unread int)) {
+	s.unreadListener = listener
+}
+
+// publishUnreadCount tells the unread count listener the count of a user
+func (s *NotificationService) publishUnreadCount(ctx context.Context, userID string) {
+	if s.unreadListener == nil {
+		return
+	}
+	unread, err := s.GetUnreadCount(ctx, userID)
+	if err != nil {
+		s.logger.Warn("Failed to count unread notifications", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+	s.unreadListener(userID, unread)
+}
+
 // MarkAsRead marks a notification as read
 func (s *NotificationService) MarkAsRead(ctx context.Context, id string) error {
 	return s.notificationRepo.MarkAsRead(ctx, id)
@@ -127,12 +189,20 @@ func (s *NotificationService) MarkAsReadForUser(ctx context.Context, id string,
 		return fmt.Errorf("notification not found or not owned by user")
 	}
 
-	return s.notificationRepo.MarkAsRead(ctx, id)
+	if err := s.notificationRepo.MarkAsRead(ctx, id); err != nil {
+		return err
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
 }
 
 // MarkAllAsRead marks all notifications as read for a user
 func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID string) error {
-	return s.notificationRepo.MarkAllAsRead(ctx, userID)
+	if err := s.notificationRepo.MarkAllAsRead(ctx, userID); err != nil {
+		return err
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
 }
 
 // sendEmailAsync sends email asynchronously with semaphore control
@@ -150,9 +220,9 @@ func (s *NotificationService) sendEmailAsync(to string, notificationType entity.
 	}()
 }
 
-// shouldSendEmail checks if email should be sent for a setting
-func shouldSendEmail(setting *entity.NotificationSettings) bool {
-	return setting.Channel == entity.ChannelEmail && setting.EmailAddress != ""
+// shouldSendEmail checks if email should be sent for a notification type
+func shouldSendEmail(setting *entity.NotificationSettings, notificationType entity.NotificationType) bool {
+	return setting.Preferences.Has(notificationType, entity.ChannelEmail) && setting.EmailAddress != ""
 }
 
 // NotifyExecutionStarted sends notifications for execution start
@@ -171,7 +241,7 @@ func (s *NotificationService) NotifyExecutionStarted(ctx context.Context, execut
 	}
 
 	for _, setting := range settings {
-		if !setting.NotifyOnStart {
+		if !setting.Preferences.Wants(entity.NotificationExecutionStarted) {
 			continue
 		}
 
@@ -188,10 +258,9 @@ func (s *NotificationService) NotifyExecutionStarted(ctx context.Context, execut
 		if s.notificationRepo.CreateNotification(ctx, notification) != nil {
 			continue // Don't fail on individual notification errors
 		}
+		s.publishUnreadCount(ctx, notification.UserID)
 
-		if shouldSendEmail(setting) {
-			s.sendEmailAsync(setting.EmailAddress, entity.NotificationExecutionStarted, data)
-		}
+		s.deliver(setting, notification, nil)
 	}
 
 	return nil
@@ -222,9 +291,34 @@ func buildExecutionCompletedData(execution *entity.Execution, scenarioName, dash
 	return data, score
 }
 
-// processScoreAlert handles score alert notification if threshold exceeded
-func (s *NotificationService) processScoreAlert(ctx context.Context, setting *entity.NotificationSettings, data map[string]any, score float64) {
-	if !setting.NotifyOnScoreAlert || score >= setting.ScoreAlertThreshold {
+// processScoreAlert handles score alert notification if threshold exceeded.
+// The alerting settings of the execution's schedule decide when the incident
+// channels page, with their own threshold or not at all.
+func (s *NotificationService) processScoreAlert(
+	ctx context.Context,
+	setting *entity.NotificationSettings,
+	data map[string]any,
+	score float64,
+	alerting *entity.ScheduleAlerting,
+) {
+	if !setting.Preferences.Wants(entity.NotificationScoreAlert) {
+		return
+	}
+
+	threshold := setting.ScoreAlertThreshold
+	notify := score < threshold
+	page := notify
+	if alerting != nil {
+		pageThreshold := setting.ScoreAlertThreshold
+		if alerting.ScoreThreshold > 0 {
+			pageThreshold = alerting.ScoreThreshold
+		}
+		page = !alerting.Disabled && score < pageThreshold && pages(setting, entity.NotificationScoreAlert)
+		if !notify {
+			threshold = pageThreshold
+		}
+	}
+	if !notify && !page {
 		return
 	}
 
@@ -232,14 +326,14 @@ func (s *NotificationService) processScoreAlert(ctx context.Context, setting *en
 	for k, v := range data {
 		alertData[k] = v
 	}
-	alertData["Threshold"] = fmt.Sprintf("%.1f", setting.ScoreAlertThreshold)
+	alertData["Threshold"] = fmt.Sprintf("%.1f", threshold)
 
 	alertNotification := &entity.Notification{
 		ID:        uuid.New().String(),
 		UserID:    setting.UserID,
 		Type:      entity.NotificationScoreAlert,
 		Title:     fmt.Sprintf("Low Score Alert: %.1f%%", score),
-		Message:   fmt.Sprintf("Security score %.1f%% is below threshold %.1f%%", score, setting.ScoreAlertThreshold),
+		Message:   fmt.Sprintf("Security score %.1f%% is below threshold %.1f%%", score, threshold),
 		Data:      alertData,
 		CreatedAt: time.Now(),
 	}
@@ -247,10 +341,9 @@ func (s *NotificationService) processScoreAlert(ctx context.Context, setting *en
 	if s.notificationRepo.CreateNotification(ctx, alertNotification) != nil {
 		return
 	}
+	s.publishUnreadCount(ctx, alertNotification.UserID)
 
-	if shouldSendEmail(setting) {
-		s.sendEmailAsync(setting.EmailAddress, entity.NotificationScoreAlert, alertData)
-	}
+	s.deliverTo(setting, alertNotification, nil, incidentChannels(notify, page))
 }
 
 // NotifyExecutionCompleted sends notifications for execution completion
@@ -261,9 +354,22 @@ func (s *NotificationService) NotifyExecutionCompleted(ctx context.Context, exec
 	}
 
 	data, score := buildExecutionCompletedData(execution, scenarioName, s.dashboardURL)
+	results := s.webhookResults(ctx, execution.ID, settings)
+	alerting := s.scheduleAlerting(ctx, execution.ID)
+	var undetected, severities []string
+	for _, setting := range settings {
+		if setting.Preferences.Wants(entity.NotificationCriticalUndetected) {
+			undetected, severities = s.undetectedTechniques(ctx, execution.ID, alerting)
+			break
+		}
+	}
 
 	for _, setting := range settings {
-		if !setting.NotifyOnComplete {
+		// Score and critical technique alerts are subscribed to independently of completions
+		s.processScoreAlert(ctx, setting, data, score, alerting)
+		s.processCriticalUndetected(ctx, setting, data, undetected, severities, alerting)
+
+		if !setting.Preferences.Wants(entity.NotificationExecutionCompleted) {
 			continue
 		}
 
@@ -280,12 +386,9 @@ func (s *NotificationService) NotifyExecutionCompleted(ctx context.Context, exec
 		if s.notificationRepo.CreateNotification(ctx, notification) != nil {
 			continue
 		}
+		s.publishUnreadCount(ctx, notification.UserID)
 
-		if shouldSendEmail(setting) {
-			s.sendEmailAsync(setting.EmailAddress, entity.NotificationExecutionCompleted, data)
-		}
-
-		s.processScoreAlert(ctx, setting, data, score)
+		s.deliver(setting, notification, results)
 	}
 
 	return nil
@@ -306,7 +409,7 @@ func (s *NotificationService) NotifyExecutionFailed(ctx context.Context, executi
 	}
 
 	for _, setting := range settings {
-		if !setting.NotifyOnFailure {
+		if !setting.Preferences.Wants(entity.NotificationExecutionFailed) {
 			continue
 		}
 
@@ -323,10 +426,9 @@ func (s *NotificationService) NotifyExecutionFailed(ctx context.Context, executi
 		if s.notificationRepo.CreateNotification(ctx, notification) != nil {
 			continue
 		}
+		s.publishUnreadCount(ctx, notification.UserID)
 
-		if shouldSendEmail(setting) {
-			s.sendEmailAsync(setting.EmailAddress, entity.NotificationExecutionFailed, data)
-		}
+		s.deliver(setting, notification, nil)
 	}
 
 	return nil
@@ -348,7 +450,7 @@ func (s *NotificationService) NotifyAgentOffline(ctx context.Context, agent *ent
 	}
 
 	for _, setting := range settings {
-		if !setting.NotifyOnAgentOffline {
+		if !setting.Preferences.Wants(entity.NotificationAgentOffline) {
 			continue
 		}
 
@@ -365,10 +467,111 @@ func (s *NotificationService) NotifyAgentOffline(ctx context.Context, agent *ent
 		if s.notificationRepo.CreateNotification(ctx, notification) != nil {
 			continue
 		}
+		s.publishUnreadCount(ctx, notification.UserID)
+
+		s.deliver(setting, notification, nil)
+	}
+
+	return nil
+}
+
+// NotifySecurityAlert notifies every active admin of an activity anomaly. Admins
+// always get the in-app notification; delivery follows their security alert channels.
+func (s *NotificationService) NotifySecurityAlert(ctx context.Context, anomaly *entity.ActivityAnomaly) error {
+	users, err := s.userRepo.FindActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"AnomalyID":    anomaly.ID,
+		"AlertType":    string(anomaly.Type),
+		"Severity":     string(anomaly.Severity),
+		"Username":     anomaly.Username,
+		"Message":      anomaly.Message,
+		"DetectedAt":   anomaly.CreatedAt.Format(time.RFC1123),
+		"DashboardURL": s.dashboardURL,
+	}
+
+	for _, user := range users {
+		if !user.IsAdmin() {
+			continue
+		}
+
+		notification := &entity.Notification{
+			ID:        uuid.New().String(),
+			UserID:    user.ID,
+			Type:      entity.NotificationSecurityAlert,
+			Title:     fmt.Sprintf("Security Alert: %s", anomaly.Type),
+			Message:   anomaly.Message,
+			Data:      data,
+			CreatedAt: time.Now(),
+		}
+
+		if s.notificationRepo.CreateNotification(ctx, notification) != nil {
+			continue
+		}
+		s.publishUnreadCount(ctx, notification.UserID)
+
+		setting, err := s.notificationRepo.FindSettingsByUserID(ctx, user.ID)
+		if err != nil || setting == nil || !setting.Enabled {
+			continue
+		}
+		s.deliver(setting, notification, nil)
+	}
+
+	return nil
+}
+
+// NotifyDetectionRegression sends notifications when the defenses handled
+// techniques of an execution worse than on the previous run of its scenario
+func (s *NotificationService) NotifyDetectionRegression(ctx context.Context, execution *entity.Execution, scenarioName string, regressions []*entity.DetectionRegression) error {
+	if len(regressions) == 0 {
+		return nil
+	}
+	settings, err := s.notificationRepo.FindAllEnabledSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	lines := make([]string, len(regressions))
+	for i, regression := range regressions {
+		technique := regression.TechniqueID
+		if regression.TechniqueName != "" {
+			technique += " (" + regression.TechniqueName + ")"
+		}
+		lines[i] = fmt.Sprintf("- %s on %s: %s -> %s", technique, regression.AgentPaw, regression.PreviousStatus, regression.Status)
+	}
+	data := map[string]any{
+		"ScenarioName":        scenarioName,
+		"ExecutionID":         execution.ID,
+		"PreviousExecutionID": regressions[0].PreviousExecutionID,
+		"RegressionCount":     len(regressions),
+		"Regressions":         strings.Join(lines, "\n"),
+		"DashboardURL":        s.dashboardURL,
+	}
+
+	for _, setting := range settings {
+		if !setting.Preferences.Wants(entity.NotificationDetectionRegression) {
+			continue
+		}
 
-		if shouldSendEmail(setting) {
-			s.sendEmailAsync(setting.EmailAddress, entity.NotificationAgentOffline, data)
+		notification := &entity.Notification{
+			ID:        uuid.New().String(),
+			UserID:    setting.UserID,
+			Type:      entity.NotificationDetectionRegression,
+			Title:     fmt.Sprintf("Detection Regression: %s", scenarioName),
+			Message:   fmt.Sprintf("%d technique(s) of '%s' were handled worse by the defenses than on the previous run", len(regressions), scenarioName),
+			Data:      data,
+			CreatedAt: time.Now(),
+		}
+
+		if s.notificationRepo.CreateNotification(ctx, notification) != nil {
+			continue
 		}
+		s.publishUnreadCount(ctx, notification.UserID)
+
+		s.deliver(setting, notification, nil)
 	}
 
 	return nil
@@ -402,9 +605,9 @@ func buildEmailMessage(from, to, subject, body string) string {
 }
 
 // sendEmailTLS sends email over TLS connection
-func (s *NotificationService) sendEmailTLS(addr, to string, auth smtp.Auth, msg string) error {
+func (s *NotificationService) sendEmailTLS(config *entity.SMTPConfig, addr, to string, auth smtp.Auth, msg string) error {
 	tlsConfig := &tls.Config{
-		ServerName: s.smtpConfig.Host,
+		ServerName: config.Host,
 	}
 
 	conn, err := tls.Dial("tcp", addr, tlsConfig)
@@ -413,7 +616,7 @@ func (s *NotificationService) sendEmailTLS(addr, to string, auth smtp.Auth, msg
 	}
 	defer conn.Close() //nolint:errcheck
 
-	client, err := smtp.NewClient(conn, s.smtpConfig.Host)
+	client, err := smtp.NewClient(conn, config.Host)
 	if err != nil {
 		return fmt.Errorf("failed to create SMTP client: %w", err)
 	}
@@ -425,7 +628,7 @@ func (s *NotificationService) sendEmailTLS(addr, to string, auth smtp.Auth, msg
 		}
 	}
 
-	if err := client.Mail(s.smtpConfig.From); err != nil {
+	if err := client.Mail(config.From); err != nil {
 		return fmt.Errorf("SMTP MAIL command failed: %w", err)
 	}
 
@@ -451,7 +654,7 @@ func (s *NotificationService) sendEmailTLS(addr, to string, auth smtp.Auth, msg
 
 // sendEmail sends an email using the configured SMTP server
 func (s *NotificationService) sendEmail(to string, notificationType entity.NotificationType, data map[string]any) error {
-	if s.smtpConfig == nil || !s.smtpConfig.IsValid() {
+	if config := s.smtp(); config == nil || !config.IsValid() {
 		return fmt.Errorf("SMTP not configured")
 	}
 
@@ -459,6 +662,15 @@ func (s *NotificationService) sendEmail(to string, notificationType entity.Notif
 	if !ok {
 		return fmt.Errorf("template not found for notification type: %s", notificationType)
 	}
+	return s.sendTemplate(to, tmpl, data)
+}
+
+// sendTemplate renders an email template and sends it
+func (s *NotificationService) sendTemplate(to string, tmpl entity.EmailTemplate, data map[string]any) error {
+	config := s.smtp()
+	if config == nil || !config.IsValid() {
+		return fmt.Errorf("SMTP not configured")
+	}
 
 	subject, err := renderEmailTemplate(tmpl.Subject, data)
 	if err != nil {
@@ -470,24 +682,39 @@ func (s *NotificationService) sendEmail(to string, notificationType entity.Notif
 		return fmt.Errorf("failed to render body: %w", err)
 	}
 
-	msg := buildEmailMessage(s.smtpConfig.From, to, subject, body)
-	addr := fmt.Sprintf("%s:%d", s.smtpConfig.Host, s.smtpConfig.Port)
+	msg := buildEmailMessage(config.From, to, subject, body)
+	addr := fmt.Sprintf("%s:%d", config.Host, config.Port)
 
 	var auth smtp.Auth
-	if s.smtpConfig.Username != "" && s.smtpConfig.Password != "" {
-		auth = smtp.PlainAuth("", s.smtpConfig.Username, s.smtpConfig.Password, s.smtpConfig.Host)
+	if config.Username != "" && config.Password != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
 	}
 
-	if s.smtpConfig.UseTLS {
-		return s.sendEmailTLS(addr, to, auth, msg)
+	if config.UseTLS {
+		return s.sendEmailTLS(config, addr, to, auth, msg)
 	}
 
-	return smtp.SendMail(addr, auth, s.smtpConfig.From, []string{to}, []byte(msg))
+	return smtp.SendMail(addr, auth, config.From, []string{to}, []byte(msg))
+}
+
+// CheckSMTP verifies the SMTP server accepts connections, without sending mail
+func (s *NotificationService) CheckSMTP(ctx context.Context) error {
+	config := s.smtp()
+	if config == nil || !config.IsValid() {
+		return fmt.Errorf("SMTP not configured")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", config.Host, config.Port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	return conn.Close()
 }
 
 // TestSMTPConnection tests the SMTP connection
 func (s *NotificationService) TestSMTPConnection(ctx context.Context, to string) error {
-	if s.smtpConfig == nil || !s.smtpConfig.IsValid() {
+	if config := s.smtp(); config == nil || !config.IsValid() {
 		return fmt.Errorf("SMTP not configured")
 	}
 
@@ -497,6 +724,7 @@ func (s *NotificationService) TestSMTPConnection(ctx context.Context, to string)
 		"StartedAt":    time.Now().Format(time.RFC1123),
 		"SafeMode":     true,
 		"DashboardURL": s.dashboardURL,
+		"Link":         strings.TrimRight(s.dashboardURL, "/") + "/executions/test-123",
 	}
 
 	return s.sendEmail(to, entity.NotificationExecutionStarted, data)
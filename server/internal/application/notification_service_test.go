@@ -3,8 +3,11 @@ package application
 import (
 	"bufio"
 	"context"
+	"database/sql"
 	"fmt"
 	"net"
+	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -123,6 +126,67 @@ func (m *mockNotificationRepo) MarkAllAsRead(ctx context.Context, userID string)
 	return nil
 }
 
+func (m *mockNotificationRepo) MarkDigestPending(ctx context.Context, id string) error {
+	if n, ok := m.notifications[id]; ok {
+		n.DigestPending = true
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) FindDigestPending(ctx context.Context, userID string) ([]*entity.Notification, error) {
+	var result []*entity.Notification
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.DigestPending {
+			result = append(result, n)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (m *mockNotificationRepo) MarkDigestSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	for _, id := range ids {
+		if n, ok := m.notifications[id]; ok {
+			n.DigestPending = false
+			n.SentAt = &sentAt
+		}
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		if n, ok := m.notifications[id]; ok && n.UserID == userID {
+			delete(m.notifications, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockNotificationRepo) DeleteReadNotifications(ctx context.Context, userID string) (int64, error) {
+	var deleted int64
+	for id, n := range m.notifications {
+		if n.UserID == userID && n.Read {
+			delete(m.notifications, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockNotificationRepo) PurgeReadNotifications(ctx context.Context, createdBefore time.Time) (int64, error) {
+	var deleted int64
+	for id, n := range m.notifications {
+		if n.Read && !n.DigestPending && n.CreatedAt.Before(createdBefore) {
+			delete(m.notifications, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
 // mockUserRepoForNotification implements repository.UserRepository for notification tests
 type mockUserRepoForNotification struct{}
 
@@ -178,6 +242,26 @@ func (m *mockUserRepoForNotification) DeactivateAdminIfNotLast(ctx context.Conte
 	return nil
 }
 
+func (m *mockUserRepoForNotification) FindLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockUserRepoForNotification) AddLoginFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, at, failuresSince, lockoutsSince time.Time) (*entity.LoginThrottle, error) {
+	return &entity.LoginThrottle{Scope: scope, Key: key, Failures: 1, LastFailureAt: at}, nil
+}
+
+func (m *mockUserRepoForNotification) LockLogin(ctx context.Context, scope entity.LoginThrottleScope, key string, failures int, until time.Time) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockUserRepoForNotification) DeleteLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) error {
+	return nil
+}
+
+func (m *mockUserRepoForNotification) FindLockedLoginThrottles(ctx context.Context, at time.Time) ([]*entity.LoginThrottle, error) {
+	return nil, nil
+}
+
 func TestNewNotificationService(t *testing.T) {
 	repo := newMockNotificationRepo()
 	userRepo := &mockUserRepoForNotification{}
@@ -194,13 +278,10 @@ func TestNotificationService_CreateSettings(t *testing.T) {
 	service := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	settings := &entity.NotificationSettings{
-		UserID:           "user-1",
-		Channel:          entity.ChannelEmail,
-		Enabled:          true,
-		EmailAddress:     "test@example.com",
-		NotifyOnStart:    true,
-		NotifyOnComplete: true,
-		NotifyOnFailure:  true,
+		UserID:       "user-1",
+		Enabled:      true,
+		EmailAddress: "test@example.com",
+		Preferences:  entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}, entity.NotificationExecutionCompleted: {entity.ChannelEmail}, entity.NotificationExecutionFailed: {entity.ChannelEmail}},
 	}
 
 	err := service.CreateSettings(context.Background(), settings)
@@ -225,7 +306,6 @@ func TestNotificationService_UpdateSettings(t *testing.T) {
 	settings := &entity.NotificationSettings{
 		ID:           "settings-1",
 		UserID:       "user-1",
-		Channel:      entity.ChannelEmail,
 		Enabled:      true,
 		EmailAddress: "test@example.com",
 	}
@@ -250,7 +330,6 @@ func TestNotificationService_GetSettingsByUserID(t *testing.T) {
 	settings := &entity.NotificationSettings{
 		ID:           "settings-1",
 		UserID:       "user-1",
-		Channel:      entity.ChannelEmail,
 		Enabled:      true,
 		EmailAddress: "test@example.com",
 	}
@@ -388,6 +467,43 @@ func TestNotificationService_MarkAllAsRead(t *testing.T) {
 	}
 }
 
+func TestNotificationService_UnreadCountListener(t *testing.T) {
+	repo := newMockNotificationRepo()
+	service := NewNotificationService(repo, &mockUserRepoForNotification{}, nil, "https://localhost:8443", nil)
+	repo.settings["settings-1"] = &entity.NotificationSettings{
+		ID: "settings-1", UserID: "user-1", Enabled: true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}},
+	}
+	var counts []int
+	service.SetUnreadCountListener(func(userID string, unread int) {
+		if userID != "user-1" {
+			t.Errorf("unexpected user %q", userID)
+		}
+		counts = append(counts, unread)
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := service.NotifyExecutionStarted(ctx, &entity.Execution{ID: "exec-1", StartedAt: time.Now()}, "Discovery"); err != nil {
+			t.Fatalf("NotifyExecutionStarted failed: %v", err)
+		}
+	}
+	var id string
+	for id = range repo.notifications {
+		break
+	}
+	if err := service.MarkAsReadForUser(ctx, id, "user-1"); err != nil {
+		t.Fatalf("MarkAsReadForUser failed: %v", err)
+	}
+	if err := service.MarkAllAsRead(ctx, "user-1"); err != nil {
+		t.Fatalf("MarkAllAsRead failed: %v", err)
+	}
+
+	if want := []int{1, 2, 1, 0}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
+
 func TestNotificationService_GetSMTPConfig(t *testing.T) {
 	repo := newMockNotificationRepo()
 	userRepo := &mockUserRepoForNotification{}
@@ -449,11 +565,10 @@ func TestNotificationService_NotifyExecutionStarted(t *testing.T) {
 
 	// Add enabled settings with NotifyOnStart
 	settings := &entity.NotificationSettings{
-		ID:            "settings-1",
-		UserID:        "user-1",
-		Channel:       entity.ChannelEmail,
-		Enabled:       true,
-		NotifyOnStart: true,
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -481,11 +596,10 @@ func TestNotificationService_NotifyExecutionCompleted(t *testing.T) {
 
 	// Add enabled settings with NotifyOnComplete
 	settings := &entity.NotificationSettings{
-		ID:               "settings-1",
-		UserID:           "user-1",
-		Channel:          entity.ChannelEmail,
-		Enabled:          true,
-		NotifyOnComplete: true,
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -520,11 +634,9 @@ func TestNotificationService_NotifyExecutionCompleted_WithScoreAlert(t *testing.
 	settings := &entity.NotificationSettings{
 		ID:                  "settings-1",
 		UserID:              "user-1",
-		Channel:             entity.ChannelEmail,
 		Enabled:             true,
-		NotifyOnComplete:    true,
-		NotifyOnScoreAlert:  true,
 		ScoreAlertThreshold: 70.0,
+		Preferences:         entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}, entity.NotificationScoreAlert: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -558,11 +670,10 @@ func TestNotificationService_NotifyExecutionFailed(t *testing.T) {
 
 	// Add enabled settings with NotifyOnFailure
 	settings := &entity.NotificationSettings{
-		ID:              "settings-1",
-		UserID:          "user-1",
-		Channel:         entity.ChannelEmail,
-		Enabled:         true,
-		NotifyOnFailure: true,
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -588,11 +699,10 @@ func TestNotificationService_NotifyAgentOffline(t *testing.T) {
 
 	// Add enabled settings with NotifyOnAgentOffline
 	settings := &entity.NotificationSettings{
-		ID:                   "settings-1",
-		UserID:               "user-1",
-		Channel:              entity.ChannelEmail,
-		Enabled:              true,
-		NotifyOnAgentOffline: true,
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationAgentOffline: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -613,6 +723,54 @@ func TestNotificationService_NotifyAgentOffline(t *testing.T) {
 	}
 }
 
+func TestNotificationService_NotifyDetectionRegression(t *testing.T) {
+	repo := newMockNotificationRepo()
+	service := NewNotificationService(repo, &mockUserRepoForNotification{}, nil, "https://localhost:8443", nil)
+	repo.settings["settings-1"] = &entity.NotificationSettings{
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationDetectionRegression: {entity.ChannelEmail}},
+	}
+	repo.settings["settings-2"] = &entity.NotificationSettings{
+		ID:          "settings-2",
+		UserID:      "user-2",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}},
+	}
+
+	execution := &entity.Execution{ID: "exec-2", ScenarioID: "scenario-1"}
+	regressions := []*entity.DetectionRegression{{
+		TechniqueID: "T1059", TechniqueName: "Command and Scripting Interpreter", AgentPaw: "agent-1",
+		PreviousStatus: entity.StatusBlocked, Status: entity.StatusSuccess, PreviousExecutionID: "exec-1",
+	}}
+	event := &entity.Event{Type: entity.EventDetectionRegression, Execution: execution, ScenarioName: "Discovery", Regressions: regressions}
+	if err := service.Handle(context.Background(), event); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	if len(repo.notifications) != 1 {
+		t.Fatalf("len(notifications) = %d, want 1", len(repo.notifications))
+	}
+	for _, notification := range repo.notifications {
+		if notification.UserID != "user-1" || notification.Type != entity.NotificationDetectionRegression {
+			t.Errorf("notification = %+v", notification)
+		}
+		want := "- T1059 (Command and Scripting Interpreter) on agent-1: blocked -> success"
+		if notification.Data["Regressions"] != want || notification.Data["PreviousExecutionID"] != "exec-1" {
+			t.Errorf("data = %v", notification.Data)
+		}
+	}
+
+	// Nothing to notify without regression
+	if err := service.NotifyDetectionRegression(context.Background(), execution, "Discovery", nil); err != nil {
+		t.Fatalf("NotifyDetectionRegression failed: %v", err)
+	}
+	if len(repo.notifications) != 1 {
+		t.Errorf("len(notifications) = %d, want 1", len(repo.notifications))
+	}
+}
+
 func TestNotificationService_TestSMTPConnection_NotConfigured(t *testing.T) {
 	repo := newMockNotificationRepo()
 	userRepo := &mockUserRepoForNotification{}
@@ -699,11 +857,10 @@ func TestNotificationService_NotifyExecutionCompleted_NilScore(t *testing.T) {
 	service := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	settings := &entity.NotificationSettings{
-		ID:               "settings-1",
-		UserID:           "user-1",
-		Channel:          entity.ChannelEmail,
-		Enabled:          true,
-		NotifyOnComplete: true,
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -730,11 +887,9 @@ func TestNotificationService_NotifyExecutionStarted_NotifyDisabled(t *testing.T)
 
 	// Settings with NotifyOnStart = false
 	settings := &entity.NotificationSettings{
-		ID:            "settings-1",
-		UserID:        "user-1",
-		Channel:       entity.ChannelEmail,
-		Enabled:       true,
-		NotifyOnStart: false,
+		ID:      "settings-1",
+		UserID:  "user-1",
+		Enabled: true,
 	}
 	repo.settings[settings.ID] = settings
 
@@ -760,11 +915,9 @@ func TestNotificationService_NotifyExecutionCompleted_NotifyDisabled(t *testing.
 	service := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	settings := &entity.NotificationSettings{
-		ID:               "settings-1",
-		UserID:           "user-1",
-		Channel:          entity.ChannelEmail,
-		Enabled:          true,
-		NotifyOnComplete: false,
+		ID:      "settings-1",
+		UserID:  "user-1",
+		Enabled: true,
 	}
 	repo.settings[settings.ID] = settings
 
@@ -789,11 +942,9 @@ func TestNotificationService_NotifyExecutionFailed_NotifyDisabled(t *testing.T)
 	service := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	settings := &entity.NotificationSettings{
-		ID:              "settings-1",
-		UserID:          "user-1",
-		Channel:         entity.ChannelEmail,
-		Enabled:         true,
-		NotifyOnFailure: false,
+		ID:      "settings-1",
+		UserID:  "user-1",
+		Enabled: true,
 	}
 	repo.settings[settings.ID] = settings
 
@@ -818,11 +969,9 @@ func TestNotificationService_NotifyAgentOffline_NotifyDisabled(t *testing.T) {
 	service := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	settings := &entity.NotificationSettings{
-		ID:                   "settings-1",
-		UserID:               "user-1",
-		Channel:              entity.ChannelEmail,
-		Enabled:              true,
-		NotifyOnAgentOffline: false,
+		ID:      "settings-1",
+		UserID:  "user-1",
+		Enabled: true,
 	}
 	repo.settings[settings.ID] = settings
 
@@ -851,11 +1000,9 @@ func TestNotificationService_NotifyExecutionCompleted_ScoreAboveThreshold(t *tes
 	settings := &entity.NotificationSettings{
 		ID:                  "settings-1",
 		UserID:              "user-1",
-		Channel:             entity.ChannelEmail,
 		Enabled:             true,
-		NotifyOnComplete:    true,
-		NotifyOnScoreAlert:  true,
 		ScoreAlertThreshold: 70.0,
+		Preferences:         entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}, entity.NotificationScoreAlert: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -931,24 +1078,32 @@ func TestShouldSendEmail(t *testing.T) {
 		{
 			name: "email channel with address",
 			settings: &entity.NotificationSettings{
-				Channel:      entity.ChannelEmail,
 				EmailAddress: "test@example.com",
+				Preferences:  entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
 			},
 			want: true,
 		},
 		{
 			name: "email channel without address",
 			settings: &entity.NotificationSettings{
-				Channel:      entity.ChannelEmail,
 				EmailAddress: "",
+				Preferences:  entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
 			},
 			want: false,
 		},
 		{
 			name: "webhook channel",
 			settings: &entity.NotificationSettings{
-				Channel:      entity.ChannelWebhook,
 				EmailAddress: "test@example.com",
+				Preferences:  entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelWebhook}},
+			},
+			want: false,
+		},
+		{
+			name: "email channel for another type",
+			settings: &entity.NotificationSettings{
+				EmailAddress: "test@example.com",
+				Preferences:  entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}},
 			},
 			want: false,
 		},
@@ -956,7 +1111,7 @@ func TestShouldSendEmail(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := shouldSendEmail(tt.settings)
+			got := shouldSendEmail(tt.settings, entity.NotificationExecutionFailed)
 			if got != tt.want {
 				t.Errorf("shouldSendEmail() = %v, want %v", got, tt.want)
 			}
@@ -1123,11 +1278,10 @@ func TestNotificationService_NotifyExecutionStarted_DisabledSettings(t *testing.
 
 	// Add disabled settings
 	settings := &entity.NotificationSettings{
-		ID:            "settings-1",
-		UserID:        "user-1",
-		Channel:       entity.ChannelEmail,
-		Enabled:       false, // Disabled
-		NotifyOnStart: true,
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     false, // Disabled
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -1155,11 +1309,9 @@ func TestNotificationService_NotifyExecutionCompleted_ScoreAlertDisabled(t *test
 	settings := &entity.NotificationSettings{
 		ID:                  "settings-1",
 		UserID:              "user-1",
-		Channel:             entity.ChannelEmail,
 		Enabled:             true,
-		NotifyOnComplete:    true,
-		NotifyOnScoreAlert:  false, // Disabled
 		ScoreAlertThreshold: 70.0,
+		Preferences:         entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -1264,11 +1416,9 @@ func TestNotificationService_NotifyExecutionCompleted_ScoreExactlyAtThreshold(t
 	settings := &entity.NotificationSettings{
 		ID:                  "settings-1",
 		UserID:              "user-1",
-		Channel:             entity.ChannelEmail,
 		Enabled:             true,
-		NotifyOnComplete:    true,
-		NotifyOnScoreAlert:  true,
 		ScoreAlertThreshold: 70.0,
+		Preferences:         entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}, entity.NotificationScoreAlert: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -1298,18 +1448,16 @@ func TestNotificationService_NotifyExecutionCompleted_MultipleSettings(t *testin
 
 	// Add multiple enabled settings
 	repo.settings["settings-1"] = &entity.NotificationSettings{
-		ID:               "settings-1",
-		UserID:           "user-1",
-		Channel:          entity.ChannelEmail,
-		Enabled:          true,
-		NotifyOnComplete: true,
+		ID:          "settings-1",
+		UserID:      "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}},
 	}
 	repo.settings["settings-2"] = &entity.NotificationSettings{
-		ID:               "settings-2",
-		UserID:           "user-2",
-		Channel:          entity.ChannelEmail,
-		Enabled:          true,
-		NotifyOnComplete: true,
+		ID:          "settings-2",
+		UserID:      "user-2",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}},
 	}
 
 	execution := &entity.Execution{
@@ -1335,12 +1483,11 @@ func TestNotificationService_NotifyExecutionStarted_WithEmailChannel(t *testing.
 
 	// Settings with email channel but no email address (shouldn't send email)
 	settings := &entity.NotificationSettings{
-		ID:            "settings-1",
-		UserID:        "user-1",
-		Channel:       entity.ChannelEmail,
-		Enabled:       true,
-		EmailAddress:  "", // Empty
-		NotifyOnStart: true,
+		ID:           "settings-1",
+		UserID:       "user-1",
+		Enabled:      true,
+		EmailAddress: "", // Empty
+		Preferences:  entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -1801,8 +1948,9 @@ func TestNotificationService_NotifyExecutionStarted_CreateNotificationError(t *t
 	svc := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	repo.settings["s1"] = &entity.NotificationSettings{
-		ID: "s1", UserID: "user-1", Channel: entity.ChannelEmail,
-		Enabled: true, NotifyOnStart: true,
+		ID: "s1", UserID: "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}},
 	}
 
 	execution := &entity.Execution{ID: "exec-1", StartedAt: time.Now(), SafeMode: true}
@@ -1837,8 +1985,9 @@ func TestNotificationService_NotifyExecutionCompleted_CreateNotificationError(t
 	svc := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	repo.settings["s1"] = &entity.NotificationSettings{
-		ID: "s1", UserID: "user-1", Channel: entity.ChannelEmail,
-		Enabled: true, NotifyOnComplete: true,
+		ID: "s1", UserID: "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelEmail}},
 	}
 
 	execution := &entity.Execution{
@@ -1874,8 +2023,9 @@ func TestNotificationService_NotifyExecutionFailed_CreateNotificationError(t *te
 	svc := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	repo.settings["s1"] = &entity.NotificationSettings{
-		ID: "s1", UserID: "user-1", Channel: entity.ChannelEmail,
-		Enabled: true, NotifyOnFailure: true,
+		ID: "s1", UserID: "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
 	}
 
 	execution := &entity.Execution{ID: "exec-1", StartedAt: time.Now()}
@@ -1908,8 +2058,9 @@ func TestNotificationService_NotifyAgentOffline_CreateNotificationError(t *testi
 	svc := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	repo.settings["s1"] = &entity.NotificationSettings{
-		ID: "s1", UserID: "user-1", Channel: entity.ChannelEmail,
-		Enabled: true, NotifyOnAgentOffline: true,
+		ID: "s1", UserID: "user-1",
+		Enabled:     true,
+		Preferences: entity.NotificationPreferences{entity.NotificationAgentOffline: {entity.ChannelEmail}},
 	}
 
 	agent := &entity.Agent{Paw: "p1", Hostname: "h1", Platform: "linux", LastSeen: time.Now()}
@@ -1929,8 +2080,9 @@ func TestNotificationService_ProcessScoreAlert_CreateNotificationError(t *testin
 	svc := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
 
 	setting := &entity.NotificationSettings{
-		ID: "s1", UserID: "user-1", Channel: entity.ChannelEmail,
-		Enabled: true, NotifyOnScoreAlert: true, ScoreAlertThreshold: 70.0,
+		ID: "s1", UserID: "user-1",
+		Enabled: true, ScoreAlertThreshold: 70.0,
+		Preferences: entity.NotificationPreferences{entity.NotificationScoreAlert: {entity.ChannelEmail}},
 	}
 
 	data := map[string]any{
@@ -1939,7 +2091,7 @@ func TestNotificationService_ProcessScoreAlert_CreateNotificationError(t *testin
 	}
 
 	// Call processScoreAlert directly - score 50 < threshold 70 -> should try to create
-	svc.processScoreAlert(context.Background(), setting, data, 50.0)
+	svc.processScoreAlert(context.Background(), setting, data, 50.0, nil)
 
 	// CreateNotification fails but processScoreAlert just returns
 	if len(repo.notifications) != 0 {
@@ -1977,12 +2129,11 @@ func TestNotificationService_NotifyExecutionStarted_WithEmailSend(t *testing.T)
 	svc := NewNotificationService(repo, userRepo, smtpConfig, "https://localhost:8443", nil)
 
 	settings := &entity.NotificationSettings{
-		ID:            "settings-1",
-		UserID:        "user-1",
-		Channel:       entity.ChannelEmail,
-		Enabled:       true,
-		EmailAddress:  "user@test.com",
-		NotifyOnStart: true,
+		ID:           "settings-1",
+		UserID:       "user-1",
+		Enabled:      true,
+		EmailAddress: "user@test.com",
+		Preferences:  entity.NotificationPreferences{entity.NotificationExecutionStarted: {entity.ChannelEmail}},
 	}
 	repo.settings[settings.ID] = settings
 
@@ -2010,3 +2161,28 @@ func TestNotificationService_NotifyExecutionStarted_WithEmailSend(t *testing.T)
 		t.Error("Timed out waiting for email to be sent")
 	}
 }
+
+func TestNotificationService_CheckSMTP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	repo := newMockNotificationRepo()
+	userRepo := &mockUserRepoForNotification{}
+	svc := NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
+	if err := svc.CheckSMTP(context.Background()); err == nil {
+		t.Error("CheckSMTP should fail when SMTP not configured")
+	}
+
+	svc.SetSMTPConfig(&entity.SMTPConfig{Host: "127.0.0.1", Port: port, From: "noreply@autostrike.test"})
+	if err := svc.CheckSMTP(context.Background()); err != nil {
+		t.Errorf("CheckSMTP failed: %v", err)
+	}
+
+	ln.Close()
+	if err := svc.CheckSMTP(context.Background()); err == nil {
+		t.Error("CheckSMTP should fail when the server is down")
+	}
+}
@@ -0,0 +1,45 @@
+package application
+
+import (
+	"context"
+
+	"autostrike/internal/domain/entity"
+)
+
+// Name identifies the notification service as an event sink
+func (s *NotificationService) Name() string {
+	return "notifications"
+}
+
+// Handle turns bus events into user notifications, delivered on the email,
+// webhook and Teams channels of each user's preferences. Events without a
+// notification type are ignored.
+func (s *NotificationService) Handle(ctx context.Context, event *entity.Event) error {
+	switch event.Type {
+	case entity.EventExecutionStarted:
+		if event.Execution != nil {
+			return s.NotifyExecutionStarted(ctx, event.Execution, event.ScenarioName)
+		}
+	case entity.EventExecutionCompleted:
+		if event.Execution != nil {
+			return s.NotifyExecutionCompleted(ctx, event.Execution, event.ScenarioName)
+		}
+	case entity.EventExecutionFailed:
+		if event.Execution != nil {
+			return s.NotifyExecutionFailed(ctx, event.Execution, event.ScenarioName, event.Error)
+		}
+	case entity.EventAgentOffline:
+		if event.Agent != nil {
+			return s.NotifyAgentOffline(ctx, event.Agent)
+		}
+	case entity.EventSecurityAlert:
+		if event.Anomaly != nil {
+			return s.NotifySecurityAlert(ctx, event.Anomaly)
+		}
+	case entity.EventDetectionRegression:
+		if event.Execution != nil {
+			return s.NotifyDetectionRegression(ctx, event.Execution, event.ScenarioName, event.Regressions)
+		}
+	}
+	return nil
+}
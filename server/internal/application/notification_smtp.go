@@ -0,0 +1,140 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+// ErrSMTPConfigStoreDisabled is returned when the SMTP configuration cannot be
+// set through the API because no secrets store encrypts its password
+var ErrSMTPConfigStoreDisabled = errors.New("SMTP configuration through the API needs the secrets store (SECRETS_MASTER_KEY or SECRETS_KMS_KEY_ID)")
+
+// ErrInvalidSMTPConfig is returned for an SMTP configuration that cannot be used
+var ErrInvalidSMTPConfig = errors.New("invalid SMTP configuration")
+
+// SetSMTPConfigStore keeps the SMTP configuration set through the API in the
+// repository, with its password sealed by the secrets store
+func (s *NotificationService) SetSMTPConfigStore(store repository.SMTPConfigRepository, secrets *SecretService) {
+	s.smtpStore = store
+	s.secrets = secrets
+}
+
+// LoadSMTPConfig switches to the stored SMTP configuration, if any, over the
+// one from the environment
+func (s *NotificationService) LoadSMTPConfig(ctx context.Context) error {
+	if s.smtpStore == nil || s.secrets == nil {
+		return nil
+	}
+	config, err := s.smtpStore.Get(ctx)
+	if err != nil || config == nil {
+		return err
+	}
+	if config.Password != "" && !isSealed(config.Password) {
+		// Encrypted with the former SMTP_CONFIG_KEY, which is no longer read
+		return errors.New("the stored SMTP password is not sealed by the secrets store, save the SMTP configuration again")
+	}
+	if config.Password, err = s.secrets.Open(ctx, config.Password); err != nil {
+		return fmt.Errorf("failed to decrypt the stored SMTP password: %w", err)
+	}
+	s.SetSMTPConfig(config)
+	return nil
+}
+
+// SetSMTPPassword replaces the password of the SMTP configuration from the
+// environment, e.g. rotated in Vault. A configuration set through the API
+// keeps its own password. Reports whether the password was replaced.
+func (s *NotificationService) SetSMTPPassword(password string) bool {
+	s.smtpMu.Lock()
+	defer s.smtpMu.Unlock()
+	if s.smtpConfig == nil || s.smtpConfig.Source != entity.SMTPConfigFromEnv {
+		return false
+	}
+	config := *s.smtpConfig
+	config.Password = password
+	s.smtpConfig = &config
+	return true
+}
+
+// SaveSMTPConfig stores the SMTP configuration and switches to it at once. An
+// empty password keeps the current one. Returns the configuration without
+// its password.
+func (s *NotificationService) SaveSMTPConfig(ctx context.Context, config *entity.SMTPConfig, updatedBy string) (*entity.SMTPConfig, error) {
+	if s.smtpStore == nil || s.secrets == nil {
+		return nil, ErrSMTPConfigStoreDisabled
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSMTPConfig, err.Error())
+	}
+
+	saved := *config
+	if saved.Password == "" {
+		if current := s.smtp(); current != nil {
+			saved.Password = current.Password
+		}
+	}
+	now := time.Now()
+	saved.Source = entity.SMTPConfigFromAPI
+	saved.UpdatedBy = updatedBy
+	saved.UpdatedAt = &now
+
+	stored := saved
+	encrypted, err := s.secrets.Seal(ctx, saved.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt the SMTP password: %w", err)
+	}
+	stored.Password = encrypted
+	if err := s.smtpStore.Save(ctx, &stored); err != nil {
+		return nil, err
+	}
+
+	s.SetSMTPConfig(&saved)
+	s.logger.Info("SMTP configuration updated",
+		zap.String("host", saved.Host), zap.Int("port", saved.Port), zap.String("updated_by", updatedBy))
+	return s.GetSMTPConfig(), nil
+}
+
+// DeleteSMTPConfig deletes the stored SMTP configuration and switches back to
+// the one from the environment, if any
+func (s *NotificationService) DeleteSMTPConfig(ctx context.Context) error {
+	if s.smtpStore == nil {
+		return ErrSMTPConfigStoreDisabled
+	}
+	if err := s.smtpStore.Delete(ctx); err != nil {
+		return err
+	}
+	s.SetSMTPConfig(s.envSMTPConfig)
+	return nil
+}
+
+// SendPasswordReset emails a password reset link to a user. The email is sent
+// in the background, so that the response time does not tell whether an
+// account was found; only a missing SMTP configuration fails at once.
+func (s *NotificationService) SendPasswordReset(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error {
+	if config := s.smtp(); config == nil || !config.IsValid() {
+		s.logger.Warn("Password reset email not sent: SMTP not configured", zap.String("user_id", user.ID))
+		return fmt.Errorf("SMTP not configured")
+	}
+
+	data := map[string]any{
+		"Username":  user.Username,
+		"Link":      strings.TrimRight(s.dashboardURL, "/") + "/reset-password?token=" + url.QueryEscape(token),
+		"ExpiresAt": expiresAt.Format(time.RFC1123),
+	}
+	go func() {
+		s.emailSemaphore <- struct{}{}
+		defer func() { <-s.emailSemaphore }()
+		if err := s.sendTemplate(user.Email, entity.PasswordResetEmailTemplate(), data); err != nil {
+			s.logger.Error("Failed to send password reset email", zap.String("user_id", user.ID), zap.Error(err))
+		}
+	}()
+	return nil
+}
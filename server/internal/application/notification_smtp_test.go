@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockSMTPConfigRepo implements repository.SMTPConfigRepository for tests
+type mockSMTPConfigRepo struct {
+	config *entity.SMTPConfig
+	err    error
+}
+
+func (m *mockSMTPConfigRepo) Get(ctx context.Context) (*entity.SMTPConfig, error) {
+	if m.config == nil {
+		return nil, m.err
+	}
+	config := *m.config
+	return &config, m.err
+}
+
+func (m *mockSMTPConfigRepo) Save(ctx context.Context, config *entity.SMTPConfig) error {
+	if m.err != nil {
+		return m.err
+	}
+	saved := *config
+	m.config = &saved
+	return nil
+}
+
+func (m *mockSMTPConfigRepo) Delete(ctx context.Context) error {
+	m.config = nil
+	return m.err
+}
+
+func newSMTPStoreTestService(t *testing.T, env *entity.SMTPConfig) (*NotificationService, *mockSMTPConfigRepo) {
+	t.Helper()
+	secrets := newTestSecretService(t, "master-key", newMockSecretRepo())
+	store := &mockSMTPConfigRepo{}
+	svc := NewNotificationService(newMockNotificationRepo(), &mockUserRepoForNotification{}, env, "https://autostrike.test", nil)
+	svc.SetSMTPConfigStore(store, secrets)
+	return svc, store
+}
+
+func TestNotificationService_SaveSMTPConfig(t *testing.T) {
+	env := &entity.SMTPConfig{Host: "env.example.com", Port: 587, Password: "env-password", From: "env@example.com", Source: entity.SMTPConfigFromEnv}
+	svc, store := newSMTPStoreTestService(t, env)
+	ctx := context.Background()
+
+	if _, err := svc.SaveSMTPConfig(ctx, &entity.SMTPConfig{Host: "smtp.example.com", Port: 0, From: "a@example.com"}, "admin-1"); !errors.Is(err, ErrInvalidSMTPConfig) {
+		t.Errorf("Expected ErrInvalidSMTPConfig, got %v", err)
+	}
+
+	config, err := svc.SaveSMTPConfig(ctx, &entity.SMTPConfig{
+		Host: "smtp.example.com", Port: 465, Username: "mailer", Password: "api-password", From: "noreply@example.com", UseTLS: true,
+	}, "admin-1")
+	if err != nil {
+		t.Fatalf("SaveSMTPConfig failed: %v", err)
+	}
+	if config.Host != "smtp.example.com" || config.Source != entity.SMTPConfigFromAPI || config.UpdatedBy != "admin-1" || config.Password != "" {
+		t.Errorf("Unexpected configuration: %+v", config)
+	}
+	if !isSealed(store.config.Password) || strings.Contains(store.config.Password, "api-password") {
+		t.Errorf("Expected the stored password to be sealed, got %q", store.config.Password)
+	}
+	if svc.smtp().Password != "api-password" {
+		t.Error("Expected the new configuration to be used at once")
+	}
+
+	// An empty password keeps the current one
+	if _, err := svc.SaveSMTPConfig(ctx, &entity.SMTPConfig{Host: "smtp2.example.com", Port: 587, From: "noreply@example.com"}, "admin-2"); err != nil {
+		t.Fatalf("SaveSMTPConfig failed: %v", err)
+	}
+	if svc.smtp().Password != "api-password" || svc.smtp().Host != "smtp2.example.com" {
+		t.Errorf("Expected the password to be kept, got %+v", svc.smtp())
+	}
+
+	// The stored configuration is used after a restart
+	restarted, _ := newSMTPStoreTestService(t, env)
+	restarted.smtpStore = store
+	if err := restarted.LoadSMTPConfig(ctx); err != nil {
+		t.Fatalf("LoadSMTPConfig failed: %v", err)
+	}
+	if restarted.smtp().Host != "smtp2.example.com" || restarted.smtp().Password != "api-password" {
+		t.Errorf("Expected the stored configuration, got %+v", restarted.smtp())
+	}
+
+	if err := restarted.DeleteSMTPConfig(ctx); err != nil {
+		t.Fatalf("DeleteSMTPConfig failed: %v", err)
+	}
+	if store.config != nil || restarted.GetSMTPConfig().Host != "env.example.com" {
+		t.Errorf("Expected the environment configuration back, got %+v", restarted.GetSMTPConfig())
+	}
+}
+
+func TestNotificationService_LoadSMTPConfig_WrongKey(t *testing.T) {
+	svc, store := newSMTPStoreTestService(t, nil)
+	ctx := context.Background()
+	if _, err := svc.SaveSMTPConfig(ctx, &entity.SMTPConfig{Host: "smtp.example.com", Port: 587, Password: "pw", From: "a@example.com"}, "admin-1"); err != nil {
+		t.Fatalf("SaveSMTPConfig failed: %v", err)
+	}
+
+	other := newTestSecretService(t, "rotated-master-key", newMockSecretRepo())
+	restarted := NewNotificationService(newMockNotificationRepo(), &mockUserRepoForNotification{}, nil, "", nil)
+	restarted.SetSMTPConfigStore(store, other)
+	if err := restarted.LoadSMTPConfig(ctx); err == nil {
+		t.Error("Expected a password sealed under another master key to fail")
+	}
+	if restarted.GetSMTPConfig() != nil {
+		t.Error("Expected the configuration not to be used")
+	}
+}
+
+func TestNotificationService_LoadSMTPConfig_UnsealedPassword(t *testing.T) {
+	svc, store := newSMTPStoreTestService(t, nil)
+	// Encrypted with the former SMTP_CONFIG_KEY
+	store.config = &entity.SMTPConfig{Host: "smtp.example.com", Port: 587, Password: "bm90LXNlYWxlZA==", From: "a@example.com", Source: entity.SMTPConfigFromAPI}
+	if err := svc.LoadSMTPConfig(context.Background()); err == nil || !strings.Contains(err.Error(), "save the SMTP configuration again") {
+		t.Errorf("Expected an unsealed password to be refused, got %v", err)
+	}
+	if svc.GetSMTPConfig() != nil {
+		t.Error("Expected the configuration not to be used")
+	}
+}
+
+func TestNotificationService_SetSMTPPassword(t *testing.T) {
+	env := &entity.SMTPConfig{Host: "env.example.com", Port: 587, Password: "env-password", From: "env@example.com", Source: entity.SMTPConfigFromEnv}
+	svc, _ := newSMTPStoreTestService(t, env)
+	if !svc.SetSMTPPassword("rotated") || svc.smtp().Password != "rotated" || env.Password != "env-password" {
+		t.Errorf("Expected the environment password replaced, got %q", svc.smtp().Password)
+	}
+
+	if _, err := svc.SaveSMTPConfig(context.Background(), &entity.SMTPConfig{Host: "smtp.example.com", Port: 587, Password: "api-password", From: "a@example.com"}, "admin-1"); err != nil {
+		t.Fatalf("SaveSMTPConfig failed: %v", err)
+	}
+	if svc.SetSMTPPassword("rotated-again") || svc.smtp().Password != "api-password" {
+		t.Error("Expected the password set through the API kept")
+	}
+}
+
+func TestNotificationService_SMTPConfigStoreDisabled(t *testing.T) {
+	svc := NewNotificationService(newMockNotificationRepo(), &mockUserRepoForNotification{}, nil, "", nil)
+	ctx := context.Background()
+	if err := svc.LoadSMTPConfig(ctx); err != nil {
+		t.Errorf("Expected no error without a store, got %v", err)
+	}
+	if _, err := svc.SaveSMTPConfig(ctx, &entity.SMTPConfig{Host: "h", Port: 25, From: "a@example.com"}, "admin-1"); !errors.Is(err, ErrSMTPConfigStoreDisabled) {
+		t.Errorf("Expected ErrSMTPConfigStoreDisabled, got %v", err)
+	}
+	if err := svc.DeleteSMTPConfig(ctx); !errors.Is(err, ErrSMTPConfigStoreDisabled) {
+		t.Errorf("Expected ErrSMTPConfigStoreDisabled, got %v", err)
+	}
+}
+
+func TestNotificationService_TestSMTPConnection_StoredConfig(t *testing.T) {
+	svc, _ := newSMTPStoreTestService(t, &entity.SMTPConfig{Host: "127.0.0.1", Port: 1, From: "env@example.com"})
+	addr, dataCh := fakeSMTPServer(t)
+	host, port := "", 0
+	_, _ = fmt.Sscanf(strings.Replace(addr, ":", " ", 1), "%s %d", &host, &port)
+
+	if _, err := svc.SaveSMTPConfig(context.Background(), &entity.SMTPConfig{Host: host, Port: port, From: "api@example.com"}, "admin-1"); err != nil {
+		t.Fatalf("SaveSMTPConfig failed: %v", err)
+	}
+	if err := svc.TestSMTPConnection(context.Background(), "admin@example.com"); err != nil {
+		t.Fatalf("TestSMTPConnection failed: %v", err)
+	}
+	select {
+	case data := <-dataCh:
+		if !strings.Contains(data, "From: api@example.com") {
+			t.Errorf("Expected the stored configuration to send the test email: %s", data)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the test email")
+	}
+}
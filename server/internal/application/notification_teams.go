@@ -0,0 +1,204 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// adaptiveCardContentType identifies adaptive card attachments in Teams messages
+const adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+
+// TeamsMessage is the body posted to Microsoft Teams incoming webhooks
+type TeamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []TeamsAttachment `json:"attachments"`
+}
+
+// TeamsAttachment wraps an adaptive card in a Teams message
+type TeamsAttachment struct {
+	ContentType string       `json:"contentType"`
+	Content     AdaptiveCard `json:"content"`
+}
+
+// AdaptiveCard is the subset of the adaptive card schema used by notifications
+type AdaptiveCard struct {
+	Schema  string               `json:"$schema"`
+	Type    string               `json:"type"`
+	Version string               `json:"version"`
+	Body    []AdaptiveCardBlock  `json:"body"`
+	Actions []AdaptiveCardAction `json:"actions,omitempty"`
+}
+
+// AdaptiveCardBlock is a TextBlock or a FactSet element of a card body
+type AdaptiveCardBlock struct {
+	Type   string             `json:"type"`
+	Text   string             `json:"text,omitempty"`
+	Size   string             `json:"size,omitempty"`
+	Weight string             `json:"weight,omitempty"`
+	Color  string             `json:"color,omitempty"`
+	Wrap   bool               `json:"wrap,omitempty"`
+	Facts  []AdaptiveCardFact `json:"facts,omitempty"`
+}
+
+// AdaptiveCardFact is a title/value pair of a FactSet
+type AdaptiveCardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// AdaptiveCardAction is an Action.OpenUrl button
+type AdaptiveCardAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// teamsFact maps a notification data key to a card fact
+type teamsFact struct {
+	title, key, suffix string
+}
+
+// teamsCardFacts lists the facts shown on the card of each notification type
+var teamsCardFacts = map[entity.NotificationType][]teamsFact{
+	entity.NotificationExecutionStarted: {
+		{title: "Scenario", key: "ScenarioName"},
+		{title: "Started", key: "StartedAt"},
+		{title: "Safe mode", key: "SafeMode"},
+	},
+	entity.NotificationExecutionCompleted: {
+		{title: "Scenario", key: "ScenarioName"},
+		{title: "Score", key: "Score", suffix: "%"},
+		{title: "Blocked", key: "Blocked"},
+		{title: "Detected", key: "Detected"},
+		{title: "Successful", key: "Successful"},
+		{title: "Total", key: "Total"},
+	},
+	entity.NotificationExecutionFailed: {
+		{title: "Scenario", key: "ScenarioName"},
+		{title: "Error", key: "Error"},
+	},
+	entity.NotificationScoreAlert: {
+		{title: "Scenario", key: "ScenarioName"},
+		{title: "Score", key: "Score", suffix: "%"},
+		{title: "Threshold", key: "Threshold", suffix: "%"},
+	},
+	entity.NotificationAgentOffline: {
+		{title: "Hostname", key: "Hostname"},
+		{title: "Paw", key: "Paw"},
+		{title: "Platform", key: "Platform"},
+		{title: "Last seen", key: "LastSeen"},
+	},
+	entity.NotificationSecurityAlert: {
+		{title: "Type", key: "AlertType"},
+		{title: "Severity", key: "Severity"},
+		{title: "User", key: "Username"},
+		{title: "Detected", key: "DetectedAt"},
+	},
+	entity.NotificationDetectionRegression: {
+		{title: "Scenario", key: "ScenarioName"},
+		{title: "Regressions", key: "RegressionCount"},
+		{title: "Previous execution", key: "PreviousExecutionID"},
+	},
+	entity.NotificationCriticalUndetected: {
+		{title: "Scenario", key: "ScenarioName"},
+		{title: "Undetected", key: "UndetectedCount"},
+		{title: "Severities", key: "Severities"},
+		{title: "Score", key: "Score", suffix: "%"},
+	},
+}
+
+// teamsTitleColors highlights the card title by notification type
+var teamsTitleColors = map[entity.NotificationType]string{
+	entity.NotificationExecutionStarted:    "accent",
+	entity.NotificationExecutionCompleted:  "good",
+	entity.NotificationExecutionFailed:     "attention",
+	entity.NotificationScoreAlert:          "warning",
+	entity.NotificationAgentOffline:        "warning",
+	entity.NotificationSecurityAlert:       "attention",
+	entity.NotificationDetectionRegression: "warning",
+	entity.NotificationCriticalUndetected:  "attention",
+}
+
+// shouldSendTeams checks if a Teams card should be sent for a notification type
+func shouldSendTeams(setting *entity.NotificationSettings, notificationType entity.NotificationType) bool {
+	return setting.Preferences.Has(notificationType, entity.ChannelTeams) && setting.TeamsWebhookURL != ""
+}
+
+// buildTeamsMessage renders a notification as an adaptive card with a link back to the dashboard
+func buildTeamsMessage(notification *entity.Notification) *TeamsMessage {
+	body := []AdaptiveCardBlock{
+		{Type: "TextBlock", Text: notification.Title, Size: "Medium", Weight: "Bolder",
+			Color: teamsTitleColors[notification.Type], Wrap: true},
+		{Type: "TextBlock", Text: notification.Message, Wrap: true},
+	}
+
+	var facts []AdaptiveCardFact
+	for _, fact := range teamsCardFacts[notification.Type] {
+		if value, ok := notification.Data[fact.key]; ok && value != nil {
+			facts = append(facts, AdaptiveCardFact{Title: fact.title, Value: fmt.Sprint(value) + fact.suffix})
+		}
+	}
+	if len(facts) > 0 {
+		body = append(body, AdaptiveCardBlock{Type: "FactSet", Facts: facts})
+	}
+
+	card := AdaptiveCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+	}
+	if title, link := teamsDeepLink(notification); link != "" {
+		card.Actions = []AdaptiveCardAction{{Type: "Action.OpenUrl", Title: title, URL: link}}
+	}
+
+	return &TeamsMessage{
+		Type:        "message",
+		Attachments: []TeamsAttachment{{ContentType: adaptiveCardContentType, Content: card}},
+	}
+}
+
+// teamsDeepLink returns the dashboard page a card links to, or an empty link
+// when the dashboard URL is not configured
+func teamsDeepLink(notification *entity.Notification) (string, string) {
+	dashboardURL, _ := notification.Data["DashboardURL"].(string)
+	if dashboardURL == "" {
+		return "", ""
+	}
+	dashboardURL = strings.TrimRight(dashboardURL, "/")
+
+	// Delivered notifications carry the recipient's link, signed when deep links are enabled
+	link, _ := notification.Data["Link"].(string)
+	if executionID, _ := notification.Data["ExecutionID"].(string); executionID != "" {
+		if link == "" {
+			link = dashboardURL + "/executions/" + executionID
+		}
+		return "View execution", link
+	}
+	if notification.Type == entity.NotificationAgentOffline {
+		if link == "" {
+			link = dashboardURL + "/agents"
+		}
+		return "View agents", link
+	}
+	return "Open dashboard", dashboardURL
+}
+
+func (s *NotificationService) sendTeamsAsync(url string, notification *entity.Notification) {
+	message := buildTeamsMessage(notification)
+	go func() {
+		s.webhookSemaphore <- struct{}{}
+		defer func() { <-s.webhookSemaphore }()
+		if err := s.postJSON(context.Background(), url, message); err != nil {
+			s.logger.Error("Failed to send Teams notification",
+				zap.String("event", string(notification.Type)),
+				zap.Error(err),
+			)
+		}
+	}()
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestBuildTeamsMessage_ExecutionCompleted(t *testing.T) {
+	execution := &entity.Execution{ID: "exec-1", Score: &entity.SecurityScore{Overall: 75, Blocked: 3, Total: 4}}
+	data, _ := buildExecutionCompletedData(execution, "Ransomware chain", "https://autostrike.local/")
+
+	message := buildTeamsMessage(&entity.Notification{
+		Type:    entity.NotificationExecutionCompleted,
+		Title:   "Execution Completed: 75.0%",
+		Message: "Attack simulation completed",
+		Data:    data,
+	})
+
+	if message.Type != "message" || len(message.Attachments) != 1 {
+		t.Fatalf("Unexpected message envelope: %+v", message)
+	}
+	attachment := message.Attachments[0]
+	if attachment.ContentType != adaptiveCardContentType || attachment.Content.Type != "AdaptiveCard" {
+		t.Errorf("Expected an adaptive card attachment, got %+v", attachment)
+	}
+
+	card := attachment.Content
+	if card.Body[0].Text != "Execution Completed: 75.0%" || card.Body[0].Color != "good" {
+		t.Errorf("Unexpected title block: %+v", card.Body[0])
+	}
+	facts := card.Body[2].Facts
+	if len(facts) != 6 || facts[0].Value != "Ransomware chain" || facts[1].Value != "75.0%" || facts[2].Value != "3" {
+		t.Errorf("Unexpected facts: %+v", facts)
+	}
+	if len(card.Actions) != 1 || card.Actions[0].URL != "https://autostrike.local/executions/exec-1" {
+		t.Errorf("Expected a link to the execution page, got %+v", card.Actions)
+	}
+}
+
+func TestBuildTeamsMessage_DeepLinks(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification *entity.Notification
+		expectedURL  string
+	}{
+		{
+			name: "agent offline links to agents",
+			notification: &entity.Notification{Type: entity.NotificationAgentOffline,
+				Data: map[string]any{"Hostname": "ws-01", "DashboardURL": "https://autostrike.local"}},
+			expectedURL: "https://autostrike.local/agents",
+		},
+		{
+			name: "security alert links to dashboard",
+			notification: &entity.Notification{Type: entity.NotificationSecurityAlert,
+				Data: map[string]any{"Severity": "high", "DashboardURL": "https://autostrike.local"}},
+			expectedURL: "https://autostrike.local",
+		},
+		{
+			name: "signed link of the recipient",
+			notification: &entity.Notification{Type: entity.NotificationExecutionCompleted,
+				Data: map[string]any{"ExecutionID": "exec-1", "DashboardURL": "https://autostrike.local",
+					"Link": "https://autostrike.local/links/signed-token"}},
+			expectedURL: "https://autostrike.local/links/signed-token",
+		},
+		{
+			name: "no dashboard URL",
+			notification: &entity.Notification{Type: entity.NotificationExecutionFailed,
+				Data: map[string]any{"ExecutionID": "exec-1", "DashboardURL": ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := buildTeamsMessage(tt.notification).Attachments[0].Content
+			if tt.expectedURL == "" {
+				if len(card.Actions) != 0 {
+					t.Errorf("Expected no action, got %+v", card.Actions)
+				}
+				return
+			}
+			if len(card.Actions) != 1 || card.Actions[0].URL != tt.expectedURL {
+				t.Errorf("Expected link %s, got %+v", tt.expectedURL, card.Actions)
+			}
+		})
+	}
+}
+
+func TestShouldSendTeams(t *testing.T) {
+	setting := &entity.NotificationSettings{
+		TeamsWebhookURL: "https://example.webhook.office.com/hook",
+		Preferences:     entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelTeams}},
+	}
+
+	if !shouldSendTeams(setting, entity.NotificationExecutionFailed) {
+		t.Error("Expected Teams delivery for failures")
+	}
+	if shouldSendTeams(setting, entity.NotificationExecutionStarted) {
+		t.Error("Did not expect Teams delivery for starts")
+	}
+	setting.TeamsWebhookURL = ""
+	if shouldSendTeams(setting, entity.NotificationExecutionFailed) {
+		t.Error("Did not expect Teams delivery without a webhook URL")
+	}
+}
+
+func TestNotificationService_DeliversTeamsCards(t *testing.T) {
+	received := make(chan TeamsMessage, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message TeamsMessage
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		received <- message
+	}))
+	defer server.Close()
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true,
+		TeamsWebhookURL: server.URL,
+		Preferences:     entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelTeams}},
+	}
+	svc := NewNotificationService(notificationRepo, nil, nil, "https://autostrike.local", nil)
+	execution := &entity.Execution{ID: "exec-1", StartedAt: time.Now()}
+
+	_ = svc.NotifyExecutionStarted(context.Background(), execution, "Scenario")
+	_ = svc.NotifyExecutionFailed(context.Background(), execution, "Scenario", "agent lost")
+
+	select {
+	case message := <-received:
+		card := message.Attachments[0].Content
+		if card.Body[0].Text != "Execution Failed: Scenario" || card.Actions[0].URL != "https://autostrike.local/executions/exec-1" {
+			t.Errorf("Unexpected card: %+v", card)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for Teams card")
+	}
+
+	select {
+	case message := <-received:
+		t.Errorf("Unexpected second card: %+v", message)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
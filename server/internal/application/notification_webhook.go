@@ -0,0 +1,206 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+// webhookTimeout bounds a single webhook delivery
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload is the JSON body posted to webhook notification channels
+type WebhookPayload struct {
+	Event     entity.NotificationType `json:"event"`
+	Timestamp time.Time               `json:"timestamp"`
+	Title     string                  `json:"title"`
+	Message   string                  `json:"message"`
+	Data      map[string]any          `json:"data,omitempty"`
+	Results   []EnrichedResult        `json:"results,omitempty"`
+}
+
+// SetWebhookResults includes execution results in completion webhooks, enriched
+// with technique metadata when verbosity is full
+func (s *NotificationService) SetWebhookResults(
+	resultRepo repository.ResultRepository,
+	techniqueRepo repository.TechniqueRepository,
+	verbosity ResultVerbosity,
+) {
+	s.resultRepo = resultRepo
+	s.techniqueRepo = techniqueRepo
+	s.webhookVerbosity = verbosity
+}
+
+// SetWebhookDeliveryService routes webhook and Teams notifications through the
+// persisted delivery queue, which signs and retries them
+func (s *NotificationService) SetWebhookDeliveryService(deliveries *WebhookDeliveryService) {
+	s.deliveries = deliveries
+}
+
+// shouldSendWebhook checks if a webhook should be sent for a notification type
+func shouldSendWebhook(setting *entity.NotificationSettings, notificationType entity.NotificationType) bool {
+	return setting.Preferences.Has(notificationType, entity.ChannelWebhook) && setting.WebhookURL != ""
+}
+
+// deliver sends a stored notification on every channel the setting selected for its type,
+// with the recipient's link. Webhooks go through the delivery queue when one is configured.
+func (s *NotificationService) deliver(setting *entity.NotificationSettings, notification *entity.Notification, results []EnrichedResult) {
+	s.deliverTo(setting, notification, results, func(entity.NotificationChannel) bool { return true })
+}
+
+// deliverTo sends a stored notification on the selected channels accepted by include
+func (s *NotificationService) deliverTo(
+	setting *entity.NotificationSettings,
+	notification *entity.Notification,
+	results []EnrichedResult,
+	include func(entity.NotificationChannel) bool,
+) {
+	notification = s.withLink(setting.UserID, notification)
+	if include(entity.ChannelEmail) && shouldSendEmail(setting, notification.Type) && !s.holdEmail(setting, notification) {
+		s.sendEmailAsync(setting.EmailAddress, notification.Type, notification.Data)
+	}
+	if include(entity.ChannelWebhook) && shouldSendWebhook(setting, notification.Type) {
+		payload := &WebhookPayload{
+			Event:     notification.Type,
+			Timestamp: notification.CreatedAt,
+			Title:     notification.Title,
+			Message:   notification.Message,
+			Data:      notification.Data,
+			Results:   results,
+		}
+		if s.deliveries != nil {
+			s.enqueueWebhook(setting.UserID, entity.ChannelWebhook, notification.Type, setting.WebhookURL, payload)
+		} else {
+			s.sendWebhookAsync(setting.WebhookURL, setting.WebhookSecret, payload)
+		}
+	}
+	if include(entity.ChannelTeams) && shouldSendTeams(setting, notification.Type) {
+		if s.deliveries != nil {
+			s.enqueueWebhook(setting.UserID, entity.ChannelTeams, notification.Type, setting.TeamsWebhookURL, buildTeamsMessage(notification))
+		} else {
+			s.sendTeamsAsync(setting.TeamsWebhookURL, notification)
+		}
+	}
+	if include(entity.ChannelPagerDuty) || include(entity.ChannelOpsgenie) {
+		s.deliverIncidents(setting, notification)
+	}
+}
+
+// enqueueWebhook queues a webhook delivery, logging when it cannot be persisted
+func (s *NotificationService) enqueueWebhook(
+	userID string,
+	channel entity.NotificationChannel,
+	event entity.NotificationType,
+	url string,
+	payload any,
+) {
+	if _, err := s.deliveries.Enqueue(context.Background(), userID, channel, event, url, payload); err != nil {
+		s.logger.Error("Failed to queue webhook",
+			zap.String("channel", string(channel)),
+			zap.String("event", string(event)),
+			zap.Error(err),
+		)
+	}
+}
+
+// webhookResults loads the results of an execution for webhook payloads.
+// Returns nil when results are not configured or no webhook will be sent.
+func (s *NotificationService) webhookResults(
+	ctx context.Context,
+	executionID string,
+	settings []*entity.NotificationSettings,
+) []EnrichedResult {
+	if s.resultRepo == nil {
+		return nil
+	}
+
+	needed := false
+	for _, setting := range settings {
+		if shouldSendWebhook(setting, entity.NotificationExecutionCompleted) {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		s.logger.Warn("Failed to load results for webhook", zap.String("execution_id", executionID), zap.Error(err))
+		return nil
+	}
+	return enrichResults(ctx, s.techniqueRepo, results, s.webhookVerbosity)
+}
+
+func (s *NotificationService) sendWebhookAsync(url, secret string, payload *WebhookPayload) {
+	go func() {
+		s.webhookSemaphore <- struct{}{}
+		defer func() { <-s.webhookSemaphore }()
+		if err := s.sendWebhook(context.Background(), url, secret, payload); err != nil {
+			s.logger.Error("Failed to send webhook",
+				zap.String("url", url),
+				zap.String("event", string(payload.Event)),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// sendWebhook posts a payload as JSON, signed when a secret is set, and expects a 2xx response
+func (s *NotificationService) sendWebhook(ctx context.Context, url, secret string, payload *WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	_, err = postWebhook(ctx, s.webhookClient, url, body, webhookHeaders(secret, string(payload.Event), "", body, time.Now()))
+	return err
+}
+
+// postJSON posts any JSON body to a webhook endpoint and expects a 2xx response
+func (s *NotificationService) postJSON(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	_, err = postWebhook(ctx, s.webhookClient, url, body, nil)
+	return err
+}
+
+// postWebhook posts a JSON body with extra headers and returns the response
+// status, with an error unless it is 2xx
+func postWebhook(ctx context.Context, client *http.Client, url string, body []byte, headers map[string]string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AutoStrike-Webhook/1.0")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
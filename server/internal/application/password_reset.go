@@ -0,0 +1,186 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password reset errors
+var (
+	ErrPasswordResetDisabled = errors.New("password reset by email is not enabled")
+	ErrInvalidResetToken     = errors.New("invalid or expired password reset token")
+)
+
+const (
+	// DefaultPasswordResetTTL is how long a password reset link stays valid
+	DefaultPasswordResetTTL = 30 * time.Minute
+	// maxPasswordResetsPerHour bounds the reset emails sent to an account
+	maxPasswordResetsPerHour = 3
+	// passwordResetTokenRetention is how long expired tokens are kept before
+	// being deleted by the next request
+	passwordResetTokenRetention = 24 * time.Hour
+)
+
+// PasswordResetMailer emails a password reset token to a user
+type PasswordResetMailer interface {
+	SendPasswordReset(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error
+}
+
+// passwordReset holds the self-service password reset settings of the auth service
+type passwordReset struct {
+	repo   repository.PasswordResetRepository
+	mailer PasswordResetMailer
+	ttl    time.Duration
+}
+
+// SetPasswordReset enables the self-service password reset: tokens valid for
+// ttl are stored in repo and emailed by mailer
+func (s *AuthService) SetPasswordReset(repo repository.PasswordResetRepository, mailer PasswordResetMailer, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultPasswordResetTTL
+	}
+	s.passwordReset = &passwordReset{repo: repo, mailer: mailer, ttl: ttl}
+}
+
+// PasswordResetEnabled reports whether users can reset their password by email
+func (s *AuthService) PasswordResetEnabled() bool {
+	return s.passwordReset != nil
+}
+
+// OnPasswordReset sets the functions called, for the audit trail, when a user
+// requests a password reset and when they reset their password with a token.
+// Either may be nil.
+func (s *AuthService) OnPasswordReset(requested, reset func(ctx context.Context, user *entity.User, ip string)) {
+	s.onResetRequested = requested
+	s.onPasswordReset = reset
+}
+
+// RequestPasswordReset emails a single-use reset token to the active user with
+// an email address. An unknown address, an inactive user or an account past
+// its reset emails of the hour is silently ignored, so that the caller cannot
+// tell which addresses have an account.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email, ip string) error {
+	reset := s.passwordReset
+	if reset == nil {
+		return ErrPasswordResetDisabled
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return nil
+	}
+	user, err := s.userRepo.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	now := time.Now()
+	count, err := reset.repo.CountByUserSince(ctx, user.ID, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if count >= maxPasswordResetsPerHour {
+		return nil
+	}
+	// Expired tokens are useless; their cleanup rides on the requests
+	_, _ = reset.repo.DeleteExpired(ctx, now.Add(-passwordResetTokenRetention))
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	plaintext := base64.RawURLEncoding.EncodeToString(secret)
+
+	token := &entity.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: hashAPIKey(plaintext),
+		RequestIP: ip,
+		CreatedAt: now,
+		ExpiresAt: now.Add(reset.ttl),
+	}
+	if err := reset.repo.Create(ctx, token); err != nil {
+		return fmt.Errorf("failed to store password reset token: %w", err)
+	}
+	if s.onResetRequested != nil {
+		s.onResetRequested(ctx, user, ip)
+	}
+
+	return reset.mailer.SendPasswordReset(ctx, user, plaintext, token.ExpiresAt)
+}
+
+// ResetPasswordWithToken sets the password of the user a reset token was
+// emailed to. The token is spent, along with the other tokens of the user,
+// and the lockout of their username after failed logins is lifted.
+func (s *AuthService) ResetPasswordWithToken(ctx context.Context, plaintext, newPassword, ip string) error {
+	reset := s.passwordReset
+	if reset == nil {
+		return ErrPasswordResetDisabled
+	}
+
+	token, err := reset.repo.FindByHash(ctx, hashAPIKey(strings.TrimSpace(plaintext)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	now := time.Now()
+	if !token.Usable(now) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !user.IsActive {
+		return ErrInvalidResetToken
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), s.bcryptCost)
+	if err != nil {
+		return err
+	}
+
+	if err := reset.repo.MarkUsed(ctx, token.ID, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user.PasswordHash = string(hashedPassword)
+	user.UpdatedAt = now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	_ = reset.repo.InvalidateByUser(ctx, user.ID, now)
+	_ = s.userRepo.DeleteLoginThrottle(ctx, entity.LoginThrottleUser, throttleKey(user.Username))
+	if s.onPasswordReset != nil {
+		s.onPasswordReset(ctx, user, ip)
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mockPasswordResetRepo implements repository.PasswordResetRepository for testing
+type mockPasswordResetRepo struct {
+	tokens map[string]*entity.PasswordResetToken
+}
+
+func newMockPasswordResetRepo() *mockPasswordResetRepo {
+	return &mockPasswordResetRepo{tokens: make(map[string]*entity.PasswordResetToken)}
+}
+
+func (m *mockPasswordResetRepo) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	m.tokens[token.ID] = token
+	return nil
+}
+
+func (m *mockPasswordResetRepo) FindByHash(ctx context.Context, hash string) (*entity.PasswordResetToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockPasswordResetRepo) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	token, ok := m.tokens[id]
+	if !ok || token.UsedAt != nil {
+		return sql.ErrNoRows
+	}
+	token.UsedAt = &usedAt
+	return nil
+}
+
+func (m *mockPasswordResetRepo) InvalidateByUser(ctx context.Context, userID string, at time.Time) error {
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.UsedAt == nil {
+			token.UsedAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *mockPasswordResetRepo) CountByUserSince(ctx context.Context, userID string, since time.Time) (int, error) {
+	count := 0
+	for _, token := range m.tokens {
+		if token.UserID == userID && !token.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockPasswordResetRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	for id, token := range m.tokens {
+		if token.ExpiresAt.Before(before) {
+			delete(m.tokens, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// mockResetMailer records the password reset tokens it is asked to send
+type mockResetMailer struct {
+	mu     sync.Mutex
+	tokens []string
+	err    error
+}
+
+func (m *mockResetMailer) SendPasswordReset(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = append(m.tokens, token)
+	return m.err
+}
+
+func setupPasswordReset(t *testing.T) (*AuthService, *mockUserRepo, *mockPasswordResetRepo, *mockResetMailer) {
+	t.Helper()
+	repo := newMockUserRepo()
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("oldpassword"), 4)
+	repo.users["user-1"] = &entity.User{
+		ID:           "user-1",
+		Username:     "testuser",
+		Email:        "test@example.com",
+		PasswordHash: string(hashedPassword),
+		IsActive:     true,
+	}
+
+	service := NewAuthService(repo, "test-secret")
+	service.bcryptCost = 4
+	resets := newMockPasswordResetRepo()
+	mailer := &mockResetMailer{}
+	service.SetPasswordReset(resets, mailer, 0)
+	return service, repo, resets, mailer
+}
+
+func TestAuthService_PasswordReset_Disabled(t *testing.T) {
+	service := NewAuthService(newMockUserRepo(), "test-secret")
+	ctx := context.Background()
+
+	if err := service.RequestPasswordReset(ctx, "test@example.com", ""); err != ErrPasswordResetDisabled {
+		t.Errorf("Expected ErrPasswordResetDisabled, got %v", err)
+	}
+	if err := service.ResetPasswordWithToken(ctx, "token", "newpassword", ""); err != ErrPasswordResetDisabled {
+		t.Errorf("Expected ErrPasswordResetDisabled, got %v", err)
+	}
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	service, repo, resets, mailer := setupPasswordReset(t)
+	ctx := context.Background()
+
+	var requested, reset []string
+	service.OnPasswordReset(
+		func(ctx context.Context, user *entity.User, ip string) { requested = append(requested, user.ID+"@"+ip) },
+		func(ctx context.Context, user *entity.User, ip string) { reset = append(reset, user.ID+"@"+ip) },
+	)
+
+	if err := service.RequestPasswordReset(ctx, " test@example.com ", "10.0.0.1"); err != nil {
+		t.Fatalf("RequestPasswordReset failed: %v", err)
+	}
+	if len(mailer.tokens) != 1 || len(resets.tokens) != 1 {
+		t.Fatalf("Expected 1 token emailed and stored, got %d and %d", len(mailer.tokens), len(resets.tokens))
+	}
+	for _, token := range resets.tokens {
+		if token.TokenHash == mailer.tokens[0] || token.RequestIP != "10.0.0.1" {
+			t.Errorf("Unexpected stored token: %+v", token)
+		}
+		if ttl := token.ExpiresAt.Sub(token.CreatedAt); ttl != DefaultPasswordResetTTL {
+			t.Errorf("TTL = %v, want %v", ttl, DefaultPasswordResetTTL)
+		}
+	}
+	if len(requested) != 1 || requested[0] != "user-1@10.0.0.1" {
+		t.Errorf("Expected the request to be audited, got %v", requested)
+	}
+
+	// A locked out username is unlocked by the reset
+	_, _ = repo.AddLoginFailure(ctx, entity.LoginThrottleUser, "testuser", time.Now(), time.Time{}, time.Time{})
+
+	if err := service.ResetPasswordWithToken(ctx, mailer.tokens[0], "newpassword", "10.0.0.2"); err != nil {
+		t.Fatalf("ResetPasswordWithToken failed: %v", err)
+	}
+	if _, err := service.Login(ctx, "testuser", "newpassword"); err != nil {
+		t.Errorf("Expected login with the new password, got %v", err)
+	}
+	if _, ok := repo.throttles["user:testuser"]; ok {
+		t.Error("Expected the failed logins of testuser to be forgotten")
+	}
+	if len(reset) != 1 || reset[0] != "user-1@10.0.0.2" {
+		t.Errorf("Expected the reset to be audited, got %v", reset)
+	}
+
+	// Single use
+	if err := service.ResetPasswordWithToken(ctx, mailer.tokens[0], "otherpassword", ""); err != ErrInvalidResetToken {
+		t.Errorf("Expected ErrInvalidResetToken on reuse, got %v", err)
+	}
+}
+
+func TestAuthService_PasswordReset_InvalidTokens(t *testing.T) {
+	service, _, resets, mailer := setupPasswordReset(t)
+	ctx := context.Background()
+
+	if err := service.ResetPasswordWithToken(ctx, "unknown", "newpassword", ""); err != ErrInvalidResetToken {
+		t.Errorf("Expected ErrInvalidResetToken for an unknown token, got %v", err)
+	}
+
+	_ = service.RequestPasswordReset(ctx, "test@example.com", "")
+	_ = service.RequestPasswordReset(ctx, "test@example.com", "")
+	for _, token := range resets.tokens {
+		token.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	if err := service.ResetPasswordWithToken(ctx, mailer.tokens[0], "newpassword", ""); err != ErrInvalidResetToken {
+		t.Errorf("Expected ErrInvalidResetToken for an expired token, got %v", err)
+	}
+}
+
+func TestAuthService_PasswordReset_OtherTokensSpent(t *testing.T) {
+	service, _, _, mailer := setupPasswordReset(t)
+	ctx := context.Background()
+
+	_ = service.RequestPasswordReset(ctx, "test@example.com", "")
+	_ = service.RequestPasswordReset(ctx, "test@example.com", "")
+	if err := service.ResetPasswordWithToken(ctx, mailer.tokens[1], "newpassword", ""); err != nil {
+		t.Fatalf("ResetPasswordWithToken failed: %v", err)
+	}
+	if err := service.ResetPasswordWithToken(ctx, mailer.tokens[0], "otherpassword", ""); err != ErrInvalidResetToken {
+		t.Errorf("Expected the older token to be spent, got %v", err)
+	}
+}
+
+func TestAuthService_PasswordReset_SilentlyIgnored(t *testing.T) {
+	service, repo, _, mailer := setupPasswordReset(t)
+	ctx := context.Background()
+
+	for _, email := range []string{"", "unknown@example.com"} {
+		if err := service.RequestPasswordReset(ctx, email, ""); err != nil {
+			t.Errorf("Expected %q to be ignored, got %v", email, err)
+		}
+	}
+
+	// At most a few emails per hour
+	for i := 0; i < maxPasswordResetsPerHour+2; i++ {
+		if err := service.RequestPasswordReset(ctx, "test@example.com", ""); err != nil {
+			t.Fatalf("RequestPasswordReset failed: %v", err)
+		}
+	}
+	if len(mailer.tokens) != maxPasswordResetsPerHour {
+		t.Errorf("Expected %d emails, got %d", maxPasswordResetsPerHour, len(mailer.tokens))
+	}
+
+	repo.users["user-1"].IsActive = false
+	repo.users["user-2"] = &entity.User{ID: "user-2", Username: "inactive", Email: "inactive@example.com"}
+	if err := service.RequestPasswordReset(ctx, "inactive@example.com", ""); err != nil || len(mailer.tokens) != maxPasswordResetsPerHour {
+		t.Errorf("Expected inactive users to be ignored, got %v", err)
+	}
+}
+
+func TestAuthService_PasswordReset_MailerError(t *testing.T) {
+	service, _, _, mailer := setupPasswordReset(t)
+	mailer.err = errors.New("SMTP not configured")
+
+	if err := service.RequestPasswordReset(context.Background(), "test@example.com", ""); err == nil {
+		t.Error("Expected the mailer error")
+	}
+}
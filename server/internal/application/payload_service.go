@@ -0,0 +1,265 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// DefaultMaxPayloadSize caps the size of an uploaded payload, below the API body limit
+const DefaultMaxPayloadSize = 8 << 20
+
+// payloadTokenType distinguishes payload download tokens from access and link tokens
+const payloadTokenType = "payload"
+
+// payloadDownloadPath prefixes the download URL of a payload token. Agents
+// resolve it against their server URL.
+const payloadDownloadPath = "/payloads/"
+
+// Payload errors
+var (
+	ErrPayloadNotFound    = errors.New("payload not found")
+	ErrInvalidPayload     = errors.New("invalid payload")
+	ErrPayloadTooLarge    = errors.New("payload is too large")
+	ErrPayloadNameTaken   = errors.New("a payload with this name already exists")
+	ErrPayloadInUse       = errors.New("payload is used by techniques")
+	ErrPayloadRejected    = errors.New("payload rejected by the malware scan")
+	ErrInvalidPayloadLink = errors.New("invalid payload link")
+	ErrPayloadLinkExpired = errors.New("payload link expired")
+	ErrPayloadLinkDenied  = errors.New("payload link is not for this agent or task")
+)
+
+// PayloadScanner checks an uploaded payload before it is stored, e.g. with an
+// antivirus. It returns an error when the payload must be rejected.
+type PayloadScanner interface {
+	Scan(ctx context.Context, name string, content []byte) error
+}
+
+// TaskPayload is the payload sent with a task: the agent downloads it from URL,
+// a path scoped to the task's result and agent, with its agent credentials, and
+// checks its SHA-256
+type TaskPayload struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	URL    string `json:"url"`
+}
+
+// PayloadService stores the payloads techniques deliver to agents and signs
+// the short-lived URLs agents download them from
+type PayloadService struct {
+	repo          repository.PayloadRepository
+	techniqueRepo repository.TechniqueRepository
+	resultRepo    repository.ResultRepository
+	scanner       PayloadScanner
+	secret        []byte
+	ttl           time.Duration
+	maxSize       int64
+}
+
+// NewPayloadService creates a payload service signing download URLs with
+// secret, valid for ttl. Downloads are checked against the results of resultRepo.
+func NewPayloadService(
+	repo repository.PayloadRepository,
+	techniqueRepo repository.TechniqueRepository,
+	resultRepo repository.ResultRepository,
+	secret string,
+	ttl time.Duration,
+) *PayloadService {
+	return &PayloadService{
+		repo:          repo,
+		techniqueRepo: techniqueRepo,
+		resultRepo:    resultRepo,
+		secret:        []byte(secret),
+		ttl:           ttl,
+		maxSize:       DefaultMaxPayloadSize,
+	}
+}
+
+// SetScanner sets the malware scan run on every upload
+func (s *PayloadService) SetScanner(scanner PayloadScanner) {
+	s.scanner = scanner
+}
+
+// SetMaxSize sets the largest payload accepted, in bytes
+func (s *PayloadService) SetMaxSize(size int64) {
+	s.maxSize = size
+}
+
+// MaxSize returns the largest payload accepted, in bytes
+func (s *PayloadService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// List returns all payloads
+func (s *PayloadService) List(ctx context.Context) ([]*entity.Payload, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// Get returns a payload by name
+func (s *PayloadService) Get(ctx context.Context, name string) (*entity.Payload, error) {
+	payload, err := s.repo.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPayloadNotFound
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Upload checks, scans and stores a payload
+func (s *PayloadService) Upload(
+	ctx context.Context,
+	name, description string,
+	content []byte,
+	userID string,
+) (*entity.Payload, error) {
+	payload := &entity.Payload{Name: name, Description: description}
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: empty file", ErrInvalidPayload)
+	}
+	if int64(len(content)) > s.maxSize {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", ErrPayloadTooLarge, len(content), s.maxSize)
+	}
+	if _, err := s.Get(ctx, name); err == nil {
+		return nil, ErrPayloadNameTaken
+	} else if !errors.Is(err, ErrPayloadNotFound) {
+		return nil, err
+	}
+
+	if s.scanner != nil {
+		if err := s.scanner.Scan(ctx, name, content); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrPayloadRejected, err)
+		}
+	}
+
+	sum := sha256.Sum256(content)
+	payload.ID = uuid.New().String()
+	payload.SHA256 = hex.EncodeToString(sum[:])
+	payload.Size = int64(len(content))
+	payload.UploadedBy = userID
+	payload.CreatedAt = time.Now()
+
+	if err := s.repo.Create(ctx, payload, content); err != nil {
+		return nil, fmt.Errorf("failed to store payload: %w", err)
+	}
+	return payload, nil
+}
+
+// Delete removes a payload no technique references
+func (s *PayloadService) Delete(ctx context.Context, name string) error {
+	payload, err := s.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	techniques, err := s.techniqueRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load techniques: %w", err)
+	}
+	var users []string
+	for _, technique := range techniques {
+		for _, executor := range technique.Executors {
+			if executor.Payload == name {
+				users = append(users, technique.ID)
+				break
+			}
+		}
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("%w: %v", ErrPayloadInUse, users)
+	}
+
+	return s.repo.Delete(ctx, payload.ID)
+}
+
+// Link returns the payload of a task, with a download URL only the task's
+// agent can use, until the URL expires
+func (s *PayloadService) Link(ctx context.Context, name, resultID, agentPaw string) (*TaskPayload, error) {
+	payload, err := s.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"type": payloadTokenType,
+		"pid":  payload.ID,
+		"rid":  resultID,
+		"paw":  agentPaw,
+		"iat":  now.Unix(),
+		"exp":  now.Add(s.ttl).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign payload link: %w", err)
+	}
+
+	return &TaskPayload{
+		Name:   payload.Name,
+		SHA256: payload.SHA256,
+		Size:   payload.Size,
+		URL:    payloadDownloadPath + token,
+	}, nil
+}
+
+// Download checks a payload token and returns the payload and its content. The
+// token must have been issued to agentPaw, for a task of the agent still
+// pending or running: a leaked URL is of no use to other agents, nor once the
+// task has finished.
+func (s *PayloadService) Download(ctx context.Context, tokenString, agentPaw string) (*entity.Payload, []byte, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return s.secret, nil
+	})
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, nil, ErrPayloadLinkExpired
+		}
+		return nil, nil, ErrInvalidPayloadLink
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, nil, ErrInvalidPayloadLink
+	}
+	if tokenType, _ := claims["type"].(string); tokenType != payloadTokenType {
+		return nil, nil, ErrInvalidPayloadLink
+	}
+	if paw, _ := claims["paw"].(string); agentPaw == "" || paw != agentPaw {
+		return nil, nil, ErrPayloadLinkDenied
+	}
+	resultID, _ := claims["rid"].(string)
+	result, err := s.resultRepo.FindResultByID(ctx, resultID)
+	if err != nil || result == nil || result.AgentPaw != agentPaw || result.Status.IsTerminal() {
+		return nil, nil, ErrPayloadLinkDenied
+	}
+	payloadID, _ := claims["pid"].(string)
+
+	payload, err := s.repo.FindByID(ctx, payloadID)
+	if err != nil {
+		// Deleted since the task was dispatched
+		return nil, nil, ErrPayloadNotFound
+	}
+	content, err := s.repo.Content(ctx, payload.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load payload: %w", err)
+	}
+	return payload, content, nil
+}
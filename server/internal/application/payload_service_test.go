@@ -0,0 +1,306 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockPayloadRepo implements repository.PayloadRepository for tests
+type mockPayloadRepo struct {
+	payloads map[string]*entity.Payload
+	contents map[string][]byte
+	err      error
+}
+
+func newMockPayloadRepo() *mockPayloadRepo {
+	return &mockPayloadRepo{payloads: make(map[string]*entity.Payload), contents: make(map[string][]byte)}
+}
+
+func (m *mockPayloadRepo) Create(ctx context.Context, payload *entity.Payload, content []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.payloads[payload.ID] = payload
+	m.contents[payload.ID] = content
+	return nil
+}
+
+func (m *mockPayloadRepo) Delete(ctx context.Context, id string) error {
+	delete(m.payloads, id)
+	delete(m.contents, id)
+	return nil
+}
+
+func (m *mockPayloadRepo) FindByID(ctx context.Context, id string) (*entity.Payload, error) {
+	if payload, ok := m.payloads[id]; ok {
+		return payload, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockPayloadRepo) FindByName(ctx context.Context, name string) (*entity.Payload, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, payload := range m.payloads {
+		if payload.Name == name {
+			return payload, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockPayloadRepo) FindAll(ctx context.Context) ([]*entity.Payload, error) {
+	var payloads []*entity.Payload
+	for _, payload := range m.payloads {
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+func (m *mockPayloadRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	if content, ok := m.contents[id]; ok {
+		return content, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// scannerFunc adapts a function to PayloadScanner
+type scannerFunc func(ctx context.Context, name string, content []byte) error
+
+func (f scannerFunc) Scan(ctx context.Context, name string, content []byte) error {
+	return f(ctx, name, content)
+}
+
+func TestPayloadService_Upload(t *testing.T) {
+	svc := NewPayloadService(newMockPayloadRepo(), newMockTechniqueRepo(), newMockResultRepo(), "secret", time.Hour)
+	ctx := context.Background()
+
+	payload, err := svc.Upload(ctx, "tool.sh", "Test tool", []byte("echo tool"), "user-1")
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	sum := sha256.Sum256([]byte("echo tool"))
+	if payload.ID == "" || payload.SHA256 != hex.EncodeToString(sum[:]) || payload.Size != 9 || payload.UploadedBy != "user-1" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+
+	found, err := svc.Get(ctx, "tool.sh")
+	if err != nil || found.ID != payload.ID {
+		t.Errorf("Expected the uploaded payload, got %+v (err %v)", found, err)
+	}
+	if _, err := svc.Get(ctx, "missing"); !errors.Is(err, ErrPayloadNotFound) {
+		t.Errorf("Expected ErrPayloadNotFound, got %v", err)
+	}
+}
+
+func TestPayloadService_Upload_Errors(t *testing.T) {
+	repo := newMockPayloadRepo()
+	svc := NewPayloadService(repo, newMockTechniqueRepo(), newMockResultRepo(), "secret", time.Hour)
+	svc.SetMaxSize(16)
+	svc.SetScanner(scannerFunc(func(ctx context.Context, name string, content []byte) error {
+		if strings.Contains(string(content), "EICAR") {
+			return errors.New("Eicar-Signature FOUND")
+		}
+		return nil
+	}))
+	ctx := context.Background()
+	_, _ = svc.Upload(ctx, "taken.sh", "", []byte("x"), "")
+
+	tests := []struct {
+		name    string
+		payload string
+		content string
+		want    error
+	}{
+		{"invalid name", "../etc/passwd", "x", ErrInvalidPayload},
+		{"empty file", "empty.sh", "", ErrInvalidPayload},
+		{"too large", "large.bin", strings.Repeat("x", 17), ErrPayloadTooLarge},
+		{"name taken", "taken.sh", "x", ErrPayloadNameTaken},
+		{"rejected", "eicar.com", "EICAR", ErrPayloadRejected},
+	}
+	for _, tt := range tests {
+		if _, err := svc.Upload(ctx, tt.payload, "", []byte(tt.content), ""); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	repo.err = errors.New("db down")
+	if _, err := svc.Upload(ctx, "other.sh", "", []byte("x"), ""); err == nil || errors.Is(err, ErrPayloadNameTaken) {
+		t.Errorf("Expected the repository error, got %v", err)
+	}
+}
+
+func TestPayloadService_Delete(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1105"] = &entity.Technique{
+		ID:        "T1105",
+		Executors: []entity.Executor{{Type: "sh", Command: "curl -so /tmp/tool #{payload.url}", Payload: "tool.sh"}},
+	}
+	svc := NewPayloadService(newMockPayloadRepo(), techRepo, newMockResultRepo(), "secret", time.Hour)
+	ctx := context.Background()
+	_, _ = svc.Upload(ctx, "tool.sh", "", []byte("x"), "")
+	_, _ = svc.Upload(ctx, "unused.sh", "", []byte("x"), "")
+
+	if err := svc.Delete(ctx, "tool.sh"); !errors.Is(err, ErrPayloadInUse) || !strings.Contains(err.Error(), "T1105") {
+		t.Errorf("Expected ErrPayloadInUse naming T1105, got %v", err)
+	}
+	if err := svc.Delete(ctx, "unused.sh"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := svc.Delete(ctx, "unused.sh"); !errors.Is(err, ErrPayloadNotFound) {
+		t.Errorf("Expected ErrPayloadNotFound, got %v", err)
+	}
+}
+
+func TestPayloadService_LinkAndDownload(t *testing.T) {
+	repo := newMockPayloadRepo()
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "result-1", ExecutionID: "exec-1", AgentPaw: "paw1", Status: entity.StatusRunning},
+		{ID: "result-2", ExecutionID: "exec-1", AgentPaw: "paw1", Status: entity.StatusSuccess},
+		{ID: "result-3", ExecutionID: "exec-1", AgentPaw: "paw2", Status: entity.StatusPending},
+	}
+	svc := NewPayloadService(repo, newMockTechniqueRepo(), resultRepo, "secret", time.Hour)
+	ctx := context.Background()
+	uploaded, _ := svc.Upload(ctx, "tool.sh", "", []byte("echo tool"), "")
+
+	link, err := svc.Link(ctx, "tool.sh", "result-1", "paw1")
+	if err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	if link.Name != "tool.sh" || link.SHA256 != uploaded.SHA256 || link.Size != 9 || !strings.HasPrefix(link.URL, "/payloads/") {
+		t.Errorf("Unexpected link: %+v", link)
+	}
+	if _, err := svc.Link(ctx, "missing", "result-1", "paw1"); !errors.Is(err, ErrPayloadNotFound) {
+		t.Errorf("Expected ErrPayloadNotFound, got %v", err)
+	}
+
+	token := strings.TrimPrefix(link.URL, "/payloads/")
+	payload, content, err := svc.Download(ctx, token, "paw1")
+	if err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if payload.Name != "tool.sh" || string(content) != "echo tool" {
+		t.Errorf("Unexpected download: %+v %q", payload, content)
+	}
+
+	sign := func(claims jwt.MapClaims, secret string) string {
+		s, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		return s
+	}
+	signedFor := func(resultID, paw string) string {
+		return sign(jwt.MapClaims{"type": "payload", "pid": uploaded.ID, "rid": resultID, "paw": paw, "exp": time.Now().Add(time.Hour).Unix()}, "secret")
+	}
+	tests := []struct {
+		name  string
+		token string
+		paw   string
+		want  error
+	}{
+		{"garbage", "not-a-token", "paw1", ErrInvalidPayloadLink},
+		{"other secret", sign(jwt.MapClaims{"type": "payload", "pid": uploaded.ID, "exp": time.Now().Add(time.Hour).Unix()}, "other"), "paw1", ErrInvalidPayloadLink},
+		{"access token", sign(jwt.MapClaims{"type": "access", "pid": uploaded.ID, "exp": time.Now().Add(time.Hour).Unix()}, "secret"), "paw1", ErrInvalidPayloadLink},
+		{"other agent", token, "paw2", ErrPayloadLinkDenied},
+		{"no agent", token, "", ErrPayloadLinkDenied},
+		{"result of another agent", signedFor("result-3", "paw1"), "paw1", ErrPayloadLinkDenied},
+		{"finished task", signedFor("result-2", "paw1"), "paw1", ErrPayloadLinkDenied},
+		{"unknown result", signedFor("result-9", "paw1"), "paw1", ErrPayloadLinkDenied},
+		{"expired", sign(jwt.MapClaims{"type": "payload", "pid": uploaded.ID, "exp": time.Now().Add(-time.Minute).Unix()}, "secret"), "paw1", ErrPayloadLinkExpired},
+	}
+	for _, tt := range tests {
+		if _, _, err := svc.Download(ctx, tt.token, tt.paw); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	_ = repo.Delete(ctx, uploaded.ID)
+	if _, _, err := svc.Download(ctx, token, "paw1"); !errors.Is(err, ErrPayloadNotFound) {
+		t.Errorf("Expected ErrPayloadNotFound for a deleted payload, got %v", err)
+	}
+}
+
+func newPayloadTestExecutionService(resultRepo *mockResultRepo, payloads *PayloadService) *ExecutionService {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "Payloads", Phases: []entity.Phase{
+		{Name: "Ingress", Techniques: []string{"T1105"}},
+	}}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1105"] = &entity.Technique{
+		ID:        "T1105",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "curl -so /tmp/tool #{payload.url}", Payload: "tool.sh"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{
+		Paw:       "paw1",
+		Status:    entity.AgentOnline,
+		Platform:  "linux",
+		Executors: []string{"sh"},
+		LastSeen:  time.Now(),
+	}
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	svc := NewExecutionService(resultRepo, scenarioRepo, techRepo, agentRepo, orchestrator, service.NewScoreCalculator())
+	if payloads != nil {
+		svc.SetPayloadService(payloads)
+	}
+	return svc
+}
+
+func TestExecutionService_DispatchesTaskPayload(t *testing.T) {
+	payloads := NewPayloadService(newMockPayloadRepo(), newMockTechniqueRepo(), newMockResultRepo(), "secret", time.Hour)
+	_, _ = payloads.Upload(context.Background(), "tool.sh", "", []byte("echo tool"), "")
+	svc := newPayloadTestExecutionService(newMockResultRepo(), payloads)
+
+	started, err := svc.StartExecution(context.Background(), "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(started.Tasks) != 1 || started.Tasks[0].Payload == nil {
+		t.Fatalf("Expected a task with a payload, got %+v", started.Tasks)
+	}
+	task := started.Tasks[0]
+
+	token := strings.TrimPrefix(task.Payload.URL, "/payloads/")
+	claims := jwt.MapClaims{}
+	_, _ = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return []byte("secret"), nil })
+	if claims["rid"] != task.ResultID || claims["paw"] != "paw1" {
+		t.Errorf("Expected the link to be scoped to the task, got %v", claims)
+	}
+}
+
+func TestExecutionService_MissingPayloadFailsTask(t *testing.T) {
+	for name, payloads := range map[string]*PayloadService{
+		"not uploaded": NewPayloadService(newMockPayloadRepo(), newMockTechniqueRepo(), newMockResultRepo(), "secret", time.Hour),
+		"disabled":     nil,
+	} {
+		resultRepo := newMockResultRepo()
+		svc := newPayloadTestExecutionService(resultRepo, payloads)
+
+		started, err := svc.StartExecution(context.Background(), "s1", []string{"paw1"}, false)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if len(started.Tasks) != 0 {
+			t.Errorf("%s: expected no task, got %+v", name, started.Tasks)
+		}
+		results := resultRepo.results[started.Execution.ID]
+		if len(results) != 1 || results[0].Status != entity.StatusFailed || !strings.Contains(results[0].Output, "payload tool.sh unavailable") {
+			t.Errorf("%s: expected a failed result, got %+v", name, results)
+		}
+		if exec := resultRepo.executions[started.Execution.ID]; exec.Status != entity.ExecutionCompleted {
+			t.Errorf("%s: expected execution to be completed, got %v", name, exec.Status)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// ErrScenarioNotFound is returned when the requested scenario does not exist
+var ErrScenarioNotFound = errors.New("scenario not found")
+
+// ReadinessService evaluates whether a scenario can run against the current fleet
+type ReadinessService struct {
+	scenarioRepo  repository.ScenarioRepository
+	techniqueRepo repository.TechniqueRepository
+	agentRepo     repository.AgentRepository
+}
+
+// NewReadinessService creates a new readiness service
+func NewReadinessService(
+	scenarioRepo repository.ScenarioRepository,
+	techniqueRepo repository.TechniqueRepository,
+	agentRepo repository.AgentRepository,
+) *ReadinessService {
+	return &ReadinessService{
+		scenarioRepo:  scenarioRepo,
+		techniqueRepo: techniqueRepo,
+		agentRepo:     agentRepo,
+	}
+}
+
+// TechniqueReadiness describes whether a single technique can be executed
+type TechniqueReadiness struct {
+	TechniqueID      string   `json:"technique_id"`
+	Name             string   `json:"name,omitempty"`
+	Platforms        []string `json:"platforms"`
+	Known            bool     `json:"known"`
+	IsSafe           bool     `json:"is_safe"`
+	Ready            bool     `json:"ready"`
+	CompatibleAgents []string `json:"compatible_agents"`
+	// Agents able to run the technique only if elevated, on which it is skipped
+	UnprivilegedAgents []string `json:"unprivileged_agents,omitempty"`
+}
+
+// ScenarioReadiness summarizes how much of a scenario the fleet can execute
+type ScenarioReadiness struct {
+	ScenarioID        string               `json:"scenario_id"`
+	ScenarioName      string               `json:"scenario_name"`
+	Score             float64              `json:"score"` // Percentage of techniques with a compatible online agent
+	TotalTechniques   int                  `json:"total_techniques"`
+	ReadyTechniques   int                  `json:"ready_techniques"`
+	OnlineAgents      int                  `json:"online_agents"`
+	MissingPlatforms  []string             `json:"missing_platforms"`
+	UnsafeTechniques  []string             `json:"unsafe_techniques"`  // Require approval, skipped in safe mode
+	UnknownTechniques []string             `json:"unknown_techniques"` // Referenced but absent from the catalog
+	Techniques        []TechniqueReadiness `json:"techniques"`
+}
+
+// GetReadiness computes the readiness of a scenario. When agentPaws is empty the
+// whole online fleet is considered, otherwise only the selected agents.
+func (s *ReadinessService) GetReadiness(ctx context.Context, scenarioID string, agentPaws []string) (*ScenarioReadiness, error) {
+	scenario, err := s.scenarioRepo.FindByID(ctx, scenarioID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrScenarioNotFound, err)
+	}
+
+	var agents []*entity.Agent
+	if len(agentPaws) > 0 {
+		agents, err = s.agentRepo.FindByPaws(ctx, agentPaws)
+	} else {
+		agents, err = s.agentRepo.FindByStatus(ctx, entity.AgentOnline)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agents: %w", err)
+	}
+
+	techniques, err := s.techniqueRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load techniques: %w", err)
+	}
+
+	return computeReadiness(scenario, techniques, agents), nil
+}
+
+// computeReadiness applies the same compatibility rules as the orchestrator
+func computeReadiness(scenario *entity.Scenario, techniques []*entity.Technique, agents []*entity.Agent) *ScenarioReadiness {
+	catalog := make(map[string]*entity.Technique, len(techniques))
+	for _, t := range techniques {
+		catalog[t.ID] = t
+	}
+
+	online := make([]*entity.Agent, 0, len(agents))
+	fleetPlatforms := make(map[string]bool)
+	for _, agent := range agents {
+		if agent.Status != entity.AgentOnline {
+			continue
+		}
+		online = append(online, agent)
+		fleetPlatforms[agent.Platform] = true
+	}
+	sort.Slice(online, func(i, j int) bool { return online[i].Paw < online[j].Paw })
+
+	readiness := &ScenarioReadiness{
+		ScenarioID:        scenario.ID,
+		ScenarioName:      scenario.Name,
+		OnlineAgents:      len(online),
+		MissingPlatforms:  []string{},
+		UnsafeTechniques:  []string{},
+		UnknownTechniques: []string{},
+		Techniques:        []TechniqueReadiness{},
+	}
+
+	missing := make(map[string]bool)
+	for _, id := range scenario.GetAllTechniques() {
+		tr := TechniqueReadiness{TechniqueID: id, Platforms: []string{}, CompatibleAgents: []string{}}
+
+		technique, ok := catalog[id]
+		if !ok {
+			readiness.UnknownTechniques = append(readiness.UnknownTechniques, id)
+			readiness.Techniques = append(readiness.Techniques, tr)
+			continue
+		}
+
+		tr.Known = true
+		tr.Name = technique.Name
+		tr.IsSafe = technique.IsSafe
+		if technique.Platforms != nil {
+			tr.Platforms = technique.Platforms
+		}
+		if !technique.IsSafe {
+			readiness.UnsafeTechniques = append(readiness.UnsafeTechniques, id)
+		}
+
+		for _, agent := range online {
+			executor, needsElevation := technique.ExecutorForAgent(agent, nil)
+			switch {
+			case executor != nil:
+				tr.CompatibleAgents = append(tr.CompatibleAgents, agent.Paw)
+			case needsElevation:
+				tr.UnprivilegedAgents = append(tr.UnprivilegedAgents, agent.Paw)
+			}
+		}
+		tr.Ready = len(tr.CompatibleAgents) > 0
+
+		if tr.Ready {
+			readiness.ReadyTechniques++
+		} else {
+			for _, p := range technique.Platforms {
+				if !fleetPlatforms[p] {
+					missing[p] = true
+				}
+			}
+		}
+		readiness.Techniques = append(readiness.Techniques, tr)
+	}
+
+	for p := range missing {
+		readiness.MissingPlatforms = append(readiness.MissingPlatforms, p)
+	}
+	sort.Strings(readiness.MissingPlatforms)
+
+	readiness.TotalTechniques = len(readiness.Techniques)
+	if readiness.TotalTechniques > 0 {
+		score := float64(readiness.ReadyTechniques) / float64(readiness.TotalTechniques) * 100
+		readiness.Score = math.Round(score*10) / 10
+	}
+
+	return readiness
+}
@@ -0,0 +1,133 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+func setupReadinessTest() (*ReadinessService, *mockAgentRepo) {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:   "s1",
+		Name: "Discovery and credentials",
+		Phases: []entity.Phase{
+			{Name: "discovery", Techniques: []string{"T1082", "T1083"}},
+			{Name: "credentials", Techniques: []string{"T1003.001", "T9999", "T1082"}},
+		},
+	}
+
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery", IsSafe: true,
+		Platforms: []string{"windows", "linux"},
+		Executors: []entity.Executor{{Type: "cmd"}, {Type: "sh"}}}
+	techRepo.techniques["T1083"] = &entity.Technique{ID: "T1083", Name: "File and Directory Discovery", IsSafe: true,
+		Platforms: []string{"darwin"},
+		Executors: []entity.Executor{{Type: "bash"}}}
+	techRepo.techniques["T1003.001"] = &entity.Technique{ID: "T1003.001", Name: "LSASS Memory", IsSafe: false,
+		Platforms: []string{"windows"},
+		Executors: []entity.Executor{{Type: "psh"}}}
+
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["win-1"] = &entity.Agent{Paw: "win-1", Platform: "windows", Executors: []string{"cmd"}, Status: entity.AgentOnline}
+	agentRepo.agents["lin-1"] = &entity.Agent{Paw: "lin-1", Platform: "linux", Executors: []string{"sh"}, Status: entity.AgentOnline}
+	agentRepo.agents["mac-1"] = &entity.Agent{Paw: "mac-1", Platform: "darwin", Executors: []string{"bash"}, Status: entity.AgentOffline}
+
+	return NewReadinessService(scenarioRepo, techRepo, agentRepo), agentRepo
+}
+
+func TestReadinessService_GetReadiness(t *testing.T) {
+	svc, _ := setupReadinessTest()
+
+	r, err := svc.GetReadiness(context.Background(), "s1", nil)
+	if err != nil {
+		t.Fatalf("GetReadiness failed: %v", err)
+	}
+
+	// T1082 is ready; T1083 needs an online macOS agent; T1003.001 needs psh; T9999 is unknown
+	if r.TotalTechniques != 4 || r.ReadyTechniques != 1 || r.Score != 25 {
+		t.Errorf("Unexpected counts: total=%d ready=%d score=%v", r.TotalTechniques, r.ReadyTechniques, r.Score)
+	}
+	if r.OnlineAgents != 2 {
+		t.Errorf("Expected 2 online agents, got %d", r.OnlineAgents)
+	}
+	if len(r.MissingPlatforms) != 1 || r.MissingPlatforms[0] != "darwin" {
+		t.Errorf("Expected darwin missing, got %v", r.MissingPlatforms)
+	}
+	if len(r.UnsafeTechniques) != 1 || r.UnsafeTechniques[0] != "T1003.001" {
+		t.Errorf("Expected T1003.001 unsafe, got %v", r.UnsafeTechniques)
+	}
+	if len(r.UnknownTechniques) != 1 || r.UnknownTechniques[0] != "T9999" {
+		t.Errorf("Expected T9999 unknown, got %v", r.UnknownTechniques)
+	}
+
+	first := r.Techniques[0]
+	if first.TechniqueID != "T1082" || !first.Ready || len(first.CompatibleAgents) != 2 || first.CompatibleAgents[0] != "lin-1" {
+		t.Errorf("Unexpected readiness for T1082: %+v", first)
+	}
+}
+
+func TestReadinessService_UnprivilegedAgents(t *testing.T) {
+	svc, agentRepo := setupReadinessTest()
+	agentRepo.agents["win-1"].Executors = []string{"cmd", "psh"}
+	technique, _ := svc.techniqueRepo.FindByID(context.Background(), "T1003.001")
+	technique.Executors[0].ElevationRequired = true
+
+	r, err := svc.GetReadiness(context.Background(), "s1", nil)
+	if err != nil {
+		t.Fatalf("GetReadiness failed: %v", err)
+	}
+	lsass := r.Techniques[2]
+	if lsass.TechniqueID != "T1003.001" || lsass.Ready || len(lsass.UnprivilegedAgents) != 1 || lsass.UnprivilegedAgents[0] != "win-1" {
+		t.Errorf("Expected T1003.001 to need an elevated win-1, got %+v", lsass)
+	}
+
+	agentRepo.agents["win-1"].Elevated = true
+	if r, _ = svc.GetReadiness(context.Background(), "s1", nil); !r.Techniques[2].Ready {
+		t.Errorf("Expected T1003.001 ready on the elevated agent, got %+v", r.Techniques[2])
+	}
+}
+
+func TestReadinessService_SelectedAgents(t *testing.T) {
+	svc, agentRepo := setupReadinessTest()
+	agentRepo.agents["mac-1"].Status = entity.AgentOnline
+
+	r, err := svc.GetReadiness(context.Background(), "s1", []string{"mac-1"})
+	if err != nil {
+		t.Fatalf("GetReadiness failed: %v", err)
+	}
+	if r.OnlineAgents != 1 || r.ReadyTechniques != 1 || r.Techniques[1].TechniqueID != "T1083" || !r.Techniques[1].Ready {
+		t.Errorf("Expected only T1083 ready on the selected agent, got %+v", r)
+	}
+	if len(r.MissingPlatforms) != 2 || r.MissingPlatforms[0] != "linux" || r.MissingPlatforms[1] != "windows" {
+		t.Errorf("Expected linux and windows missing, got %v", r.MissingPlatforms)
+	}
+}
+
+func TestReadinessService_NoAgents(t *testing.T) {
+	svc, agentRepo := setupReadinessTest()
+	agentRepo.agents = map[string]*entity.Agent{}
+
+	r, err := svc.GetReadiness(context.Background(), "s1", nil)
+	if err != nil {
+		t.Fatalf("GetReadiness failed: %v", err)
+	}
+	if r.Score != 0 || r.ReadyTechniques != 0 || len(r.MissingPlatforms) != 3 {
+		t.Errorf("Expected nothing ready, got score=%v missing=%v", r.Score, r.MissingPlatforms)
+	}
+}
+
+func TestReadinessService_Errors(t *testing.T) {
+	svc, agentRepo := setupReadinessTest()
+
+	if _, err := svc.GetReadiness(context.Background(), "missing", nil); !errors.Is(err, ErrScenarioNotFound) {
+		t.Errorf("Expected ErrScenarioNotFound, got %v", err)
+	}
+
+	agentRepo.findErr = errors.New("db down")
+	if _, err := svc.GetReadiness(context.Background(), "s1", nil); err == nil || errors.Is(err, ErrScenarioNotFound) {
+		t.Errorf("Expected agent load error, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+)
+
+// Result annotation errors
+var (
+	ErrInvalidAnnotation    = errors.New("invalid annotation")
+	ErrCommentNotFound      = errors.New("comment not found")
+	ErrCommentNotDeleteable = errors.New("only the author or an administrator can delete a comment")
+)
+
+// ResultAnnotationService records the manual triage of results: the analyst
+// verdict, the teammate assigned to follow it up and the comments around it
+type ResultAnnotationService struct {
+	repo       repository.ResultAnnotationRepository
+	resultRepo repository.ResultRepository
+	userRepo   repository.UserRepository
+	reviews    *ExecutionReviewService
+}
+
+// NewResultAnnotationService creates a new result annotation service
+func NewResultAnnotationService(
+	repo repository.ResultAnnotationRepository,
+	resultRepo repository.ResultRepository,
+	userRepo repository.UserRepository,
+) *ResultAnnotationService {
+	return &ResultAnnotationService{repo: repo, resultRepo: resultRepo, userRepo: userRepo}
+}
+
+// SetExecutionReviews refuses changes to the results of executions whose
+// review was signed off
+func (s *ResultAnnotationService) SetExecutionReviews(reviews *ExecutionReviewService) {
+	s.reviews = reviews
+}
+
+// checkLocked returns ErrReviewLocked when the review of an execution was signed off
+func (s *ResultAnnotationService) checkLocked(ctx context.Context, executionID string) error {
+	if s.reviews == nil {
+		return nil
+	}
+	return s.reviews.checkLocked(ctx, executionID)
+}
+
+// Get returns the annotation of a result. A result never annotated returns an
+// empty annotation.
+func (s *ResultAnnotationService) Get(ctx context.Context, resultID string) (*entity.ResultAnnotation, error) {
+	result, err := s.findResult(ctx, resultID)
+	if err != nil {
+		return nil, err
+	}
+	return s.annotation(ctx, result)
+}
+
+// Triage sets the verdict and assignee of a result. An empty verdict or
+// assignee clears it; the assignee must be an active user.
+func (s *ResultAnnotationService) Triage(
+	ctx context.Context,
+	resultID string,
+	triage entity.ResultTriage,
+	assigneeID, updatedBy string,
+) (*entity.ResultAnnotation, error) {
+	if !entity.IsValidResultTriage(triage) {
+		return nil, fmt.Errorf("%w: unknown triage %q", ErrInvalidAnnotation, triage)
+	}
+	if assigneeID != "" {
+		user, err := s.userRepo.FindByID(ctx, assigneeID)
+		if err != nil || user == nil || !user.IsActive {
+			return nil, fmt.Errorf("%w: assignee %s is not an active user", ErrInvalidAnnotation, assigneeID)
+		}
+	}
+
+	result, err := s.findResult(ctx, resultID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLocked(ctx, result.ExecutionID); err != nil {
+		return nil, err
+	}
+	annotation, err := s.annotation(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	annotation.Triage = triage
+	annotation.AssigneeID = assigneeID
+	annotation.UpdatedBy = updatedBy
+	annotation.UpdatedAt = &now
+	if err := s.repo.Save(ctx, annotation); err != nil {
+		return nil, fmt.Errorf("failed to save annotation: %w", err)
+	}
+	return annotation, nil
+}
+
+// AddComment adds a comment to a result
+func (s *ResultAnnotationService) AddComment(ctx context.Context, resultID, authorID, body string) (*entity.ResultComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: the comment is empty", ErrInvalidAnnotation)
+	}
+	if len(body) > entity.MaxResultCommentLength {
+		return nil, fmt.Errorf("%w: the comment is longer than %d characters", ErrInvalidAnnotation, entity.MaxResultCommentLength)
+	}
+
+	result, err := s.findResult(ctx, resultID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLocked(ctx, result.ExecutionID); err != nil {
+		return nil, err
+	}
+	comment := &entity.ResultComment{
+		ID:          uuid.New().String(),
+		ResultID:    result.ID,
+		ExecutionID: result.ExecutionID,
+		AuthorID:    authorID,
+		Body:        body,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.AddComment(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to save comment: %w", err)
+	}
+	return comment, nil
+}
+
+// DeleteComment deletes a comment of a result. Only its author or an
+// administrator can delete it.
+func (s *ResultAnnotationService) DeleteComment(ctx context.Context, resultID, commentID, userID string, admin bool) error {
+	comment, err := s.repo.FindComment(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	if comment.ResultID != resultID {
+		return ErrCommentNotFound
+	}
+	if comment.AuthorID != userID && !admin {
+		return ErrCommentNotDeleteable
+	}
+	if err := s.checkLocked(ctx, comment.ExecutionID); err != nil {
+		return err
+	}
+	return s.repo.DeleteComment(ctx, commentID)
+}
+
+// ByExecution returns the annotations of the results of an execution, by result ID
+func (s *ResultAnnotationService) ByExecution(ctx context.Context, executionID string) (map[string]*entity.ResultAnnotation, error) {
+	annotations, err := s.repo.FindByExecution(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	byResult := make(map[string]*entity.ResultAnnotation, len(annotations))
+	for _, annotation := range annotations {
+		byResult[annotation.ResultID] = annotation
+	}
+	return byResult, nil
+}
+
+// findResult loads a result, returning ErrResultNotFound when it does not exist
+func (s *ResultAnnotationService) findResult(ctx context.Context, resultID string) (*entity.ExecutionResult, error) {
+	result, err := s.resultRepo.FindResultByID(ctx, resultID)
+	if err != nil || result == nil {
+		return nil, ErrResultNotFound
+	}
+	return result, nil
+}
+
+// annotation returns the stored annotation of a result, or an empty one
+func (s *ResultAnnotationService) annotation(ctx context.Context, result *entity.ExecutionResult) (*entity.ResultAnnotation, error) {
+	annotation, err := s.repo.Get(ctx, result.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load annotation: %w", err)
+	}
+	if annotation == nil {
+		annotation = &entity.ResultAnnotation{ResultID: result.ID, ExecutionID: result.ExecutionID, Comments: []entity.ResultComment{}}
+	}
+	return annotation, nil
+}
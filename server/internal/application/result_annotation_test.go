@@ -0,0 +1,228 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+type mockResultAnnotationRepo struct {
+	annotations map[string]*entity.ResultAnnotation
+	comments    []*entity.ResultComment
+	err         error
+}
+
+func newMockResultAnnotationRepo() *mockResultAnnotationRepo {
+	return &mockResultAnnotationRepo{annotations: make(map[string]*entity.ResultAnnotation)}
+}
+
+func (m *mockResultAnnotationRepo) Get(ctx context.Context, resultID string) (*entity.ResultAnnotation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	stored, ok := m.annotations[resultID]
+	comments := m.commentsOf(resultID)
+	if !ok && len(comments) == 0 {
+		return nil, nil
+	}
+	annotation := &entity.ResultAnnotation{ResultID: resultID}
+	if ok {
+		copied := *stored
+		annotation = &copied
+	}
+	annotation.Comments = comments
+	return annotation, nil
+}
+
+func (m *mockResultAnnotationRepo) FindByExecution(ctx context.Context, executionID string) ([]*entity.ResultAnnotation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var annotations []*entity.ResultAnnotation
+	for _, annotation := range m.annotations {
+		if annotation.ExecutionID == executionID {
+			found, _ := m.Get(ctx, annotation.ResultID)
+			annotations = append(annotations, found)
+		}
+	}
+	return annotations, nil
+}
+
+func (m *mockResultAnnotationRepo) Save(ctx context.Context, annotation *entity.ResultAnnotation) error {
+	if m.err != nil {
+		return m.err
+	}
+	copied := *annotation
+	m.annotations[annotation.ResultID] = &copied
+	return nil
+}
+
+func (m *mockResultAnnotationRepo) AddComment(ctx context.Context, comment *entity.ResultComment) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.comments = append(m.comments, comment)
+	return nil
+}
+
+func (m *mockResultAnnotationRepo) FindComment(ctx context.Context, id string) (*entity.ResultComment, error) {
+	for _, comment := range m.comments {
+		if comment.ID == id {
+			return comment, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockResultAnnotationRepo) DeleteComment(ctx context.Context, id string) error {
+	for i, comment := range m.comments {
+		if comment.ID == id {
+			m.comments = append(m.comments[:i], m.comments[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockResultAnnotationRepo) commentsOf(resultID string) []entity.ResultComment {
+	comments := []entity.ResultComment{}
+	for _, comment := range m.comments {
+		if comment.ResultID == resultID {
+			comments = append(comments, *comment)
+		}
+	}
+	return comments
+}
+
+func setupAnnotationTest() (*ResultAnnotationService, *mockResultAnnotationRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1059"}}
+	userRepo := newMockUserRepo()
+	userRepo.users["analyst"] = &entity.User{ID: "analyst", IsActive: true}
+	userRepo.users["former"] = &entity.User{ID: "former"}
+
+	repo := newMockResultAnnotationRepo()
+	return NewResultAnnotationService(repo, resultRepo, userRepo), repo
+}
+
+func TestResultAnnotationService_Triage(t *testing.T) {
+	svc, repo := setupAnnotationTest()
+	ctx := context.Background()
+
+	empty, err := svc.Get(ctx, "r1")
+	if err != nil || empty.ExecutionID != "exec-1" || empty.Triage != entity.TriageNone || empty.Comments == nil {
+		t.Fatalf("Expected an empty annotation, got %+v (%v)", empty, err)
+	}
+
+	annotation, err := svc.Triage(ctx, "r1", entity.TriageNeedsTuning, "analyst", "lead")
+	if err != nil {
+		t.Fatalf("Triage failed: %v", err)
+	}
+	if annotation.AssigneeID != "analyst" || annotation.UpdatedBy != "lead" || annotation.UpdatedAt == nil {
+		t.Errorf("Unexpected annotation: %+v", annotation)
+	}
+	if stored := repo.annotations["r1"]; stored == nil || stored.Triage != entity.TriageNeedsTuning || stored.ExecutionID != "exec-1" {
+		t.Errorf("Expected the annotation saved, got %+v", stored)
+	}
+
+	// Clearing the verdict and the assignee
+	if annotation, err := svc.Triage(ctx, "r1", entity.TriageNone, "", "lead"); err != nil || annotation.Triage != "" || annotation.AssigneeID != "" {
+		t.Errorf("Expected the triage cleared, got %+v (%v)", annotation, err)
+	}
+}
+
+func TestResultAnnotationService_TriageErrors(t *testing.T) {
+	svc, repo := setupAnnotationTest()
+	ctx := context.Background()
+
+	if _, err := svc.Triage(ctx, "r1", "false_positive", "", "lead"); !errors.Is(err, ErrInvalidAnnotation) {
+		t.Errorf("Expected ErrInvalidAnnotation for an unknown verdict, got %v", err)
+	}
+	for _, assignee := range []string{"former", "missing"} {
+		if _, err := svc.Triage(ctx, "r1", entity.TriageTruePositive, assignee, "lead"); !errors.Is(err, ErrInvalidAnnotation) {
+			t.Errorf("Expected ErrInvalidAnnotation for assignee %s, got %v", assignee, err)
+		}
+	}
+	if _, err := svc.Triage(ctx, "missing", entity.TriageTruePositive, "", "lead"); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound, got %v", err)
+	}
+	if _, err := svc.Get(ctx, "missing"); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound, got %v", err)
+	}
+
+	repo.err = errors.New("db down")
+	if _, err := svc.Triage(ctx, "r1", entity.TriageTruePositive, "", "lead"); err == nil {
+		t.Error("Expected the repository error")
+	}
+}
+
+func TestResultAnnotationService_Comments(t *testing.T) {
+	svc, repo := setupAnnotationTest()
+	ctx := context.Background()
+
+	comment, err := svc.AddComment(ctx, "r1", "analyst", "  EDR alert fired 4 minutes late  ")
+	if err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	if comment.Body != "EDR alert fired 4 minutes late" || comment.ExecutionID != "exec-1" || comment.AuthorID != "analyst" {
+		t.Errorf("Unexpected comment: %+v", comment)
+	}
+	if annotation, _ := svc.Get(ctx, "r1"); len(annotation.Comments) != 1 {
+		t.Errorf("Expected the comment on the annotation, got %+v", annotation)
+	}
+
+	for _, body := range []string{" ", strings.Repeat("x", entity.MaxResultCommentLength+1)} {
+		if _, err := svc.AddComment(ctx, "r1", "analyst", body); !errors.Is(err, ErrInvalidAnnotation) {
+			t.Errorf("Expected ErrInvalidAnnotation, got %v", err)
+		}
+	}
+	if _, err := svc.AddComment(ctx, "missing", "analyst", "note"); !errors.Is(err, ErrResultNotFound) {
+		t.Errorf("Expected ErrResultNotFound, got %v", err)
+	}
+
+	// Only the author or an administrator deletes a comment
+	if err := svc.DeleteComment(ctx, "r1", comment.ID, "other", false); !errors.Is(err, ErrCommentNotDeleteable) {
+		t.Errorf("Expected ErrCommentNotDeleteable, got %v", err)
+	}
+	if err := svc.DeleteComment(ctx, "r2", comment.ID, "analyst", false); !errors.Is(err, ErrCommentNotFound) {
+		t.Errorf("Expected ErrCommentNotFound for another result, got %v", err)
+	}
+	if err := svc.DeleteComment(ctx, "r1", comment.ID, "admin", true); err != nil {
+		t.Errorf("DeleteComment failed: %v", err)
+	}
+	if len(repo.comments) != 0 {
+		t.Errorf("Expected the comment deleted, got %d", len(repo.comments))
+	}
+	if err := svc.DeleteComment(ctx, "r1", comment.ID, "analyst", false); !errors.Is(err, ErrCommentNotFound) {
+		t.Errorf("Expected ErrCommentNotFound, got %v", err)
+	}
+}
+
+func TestExecutionService_ExportExecutionAnnotations(t *testing.T) {
+	svc, resultRepo := setupExportTest()
+	annotations, repo := setupAnnotationTest()
+	annotations.resultRepo = resultRepo
+	svc.SetResultAnnotations(annotations)
+	ctx := context.Background()
+
+	if _, err := annotations.Triage(ctx, "r2", entity.TriageTruePositive, "", "lead"); err != nil {
+		t.Fatalf("Triage failed: %v", err)
+	}
+	export, err := svc.ExportExecution(ctx, "exec-1", VerbosityMinimal)
+	if err != nil {
+		t.Fatalf("ExportExecution failed: %v", err)
+	}
+	if export.Results[0].Annotation != nil || export.Results[1].Annotation == nil ||
+		export.Results[1].Annotation.Triage != entity.TriageTruePositive {
+		t.Errorf("Expected the annotation of r2 only, got %+v / %+v", export.Results[0].Annotation, export.Results[1].Annotation)
+	}
+
+	repo.err = errors.New("db down")
+	if _, err := svc.ExportExecution(ctx, "exec-1", VerbosityMinimal); err == nil {
+		t.Error("Expected the annotation error")
+	}
+}
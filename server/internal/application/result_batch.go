@@ -0,0 +1,220 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MaxResultBatch is the largest number of results an agent submits at once
+const MaxResultBatch = 100
+
+// ErrInvalidResultBatch is returned for an empty or oversized batch of results
+var ErrInvalidResultBatch = errors.New("invalid result batch")
+
+// ResultSubmission is a result an agent reports within a batch
+type ResultSubmission struct {
+	AgentPaw     string // Agent reporting the result, set by SubmitResults
+	SubmissionID string // Idempotency key, the same across the retries of the agent
+	ResultID     string
+	Status       entity.ResultStatus
+	Output       string
+	ExitCode     int
+}
+
+// ResultSubmissionOutcome is what became of a result of a batch
+type ResultSubmissionOutcome struct {
+	Duplicate bool  // A retry of a submission already applied, not applied again
+	Err       error // Why the result could not be applied
+}
+
+// SetResultBatch stores the results of a batch in a single transaction of
+// repo, which may be nil to store them one by one
+func (s *ExecutionService) SetResultBatch(repo repository.ResultBatchRepository) {
+	s.resultBatch = repo
+}
+
+// SubmitResults applies a batch of results an agent reported and returns the
+// outcome of each, in order: a result that cannot be applied does not fail
+// the others. Idempotency keys are claimed and results stored in one
+// transaction each, then deferred phases are planned and executions completed
+// once per execution. An error is returned for an invalid batch, or when the
+// keys cannot be claimed, nothing being applied.
+func (s *ExecutionService) SubmitResults(ctx context.Context, agentPaw string, batch []ResultSubmission) (outcomes []ResultSubmissionOutcome, err error) {
+	ctx, span := startSpan(ctx, "ExecutionService.SubmitResults",
+		attribute.String("agent.paw", agentPaw),
+		attribute.Int("results.count", len(batch)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if len(batch) == 0 || len(batch) > MaxResultBatch {
+		return nil, fmt.Errorf("%w: a batch has 1 to %d results", ErrInvalidResultBatch, MaxResultBatch)
+	}
+
+	submissions := make([]ResultSubmission, len(batch))
+	for i, sub := range batch {
+		sub.AgentPaw = agentPaw
+		submissions[i] = sub
+	}
+	return s.applyResults(ctx, submissions)
+}
+
+// executionAgent is an execution an agent reported results of
+type executionAgent struct {
+	executionID string
+	agentPaw    string
+}
+
+// applyResults applies results reported by one or more agents, as
+// SubmitResults does for the results of an agent
+func (s *ExecutionService) applyResults(ctx context.Context, batch []ResultSubmission) ([]ResultSubmissionOutcome, error) {
+	outcomes := make([]ResultSubmissionOutcome, len(batch))
+	claimed, err := s.claimBatch(ctx, batch, outcomes)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*entity.ExecutionResult
+	var indexes []int // Submission of each result to store
+	for i, sub := range batch {
+		if outcomes[i].Duplicate {
+			continue
+		}
+		result, apply, err := s.reportResult(ctx, sub.ResultID, sub.Status, sub.Output, sub.ExitCode, sub.AgentPaw)
+		if !apply {
+			outcomes[i].Err = err
+			continue
+		}
+		results = append(results, result)
+		indexes = append(indexes, i)
+	}
+
+	errs := s.storeResults(ctx, results)
+	stored := make([]bool, len(batch))
+	var executions []executionAgent
+	byExecution := make(map[executionAgent][]int) // Submissions stored, by execution and agent
+	terminal := make(map[executionAgent]bool)     // With a final result stored
+	for j, result := range results {
+		i := indexes[j]
+		if outcomes[i].Err = errs[j]; errs[j] != nil {
+			continue
+		}
+		stored[i] = true
+		s.resultReported(ctx, result, batch[i].Output)
+		key := executionAgent{executionID: result.ExecutionID, agentPaw: batch[i].AgentPaw}
+		if _, ok := byExecution[key]; !ok {
+			executions = append(executions, key)
+		}
+		byExecution[key] = append(byExecution[key], i)
+		terminal[key] = terminal[key] || result.Status.IsTerminal()
+	}
+
+	// Plan the deferred phases the agents can now reach, then check completion,
+	// once per execution and agent
+	for _, key := range executions {
+		var err error
+		if terminal[key] {
+			if err = s.advanceDeferredPhases(ctx, key.executionID, key.agentPaw); err != nil {
+				err = fmt.Errorf("failed to plan deferred phases: %w", err)
+			}
+		}
+		if err == nil {
+			err = s.checkAndCompleteExecution(ctx, key.executionID)
+		}
+		if err != nil {
+			for _, i := range byExecution[key] {
+				outcomes[i].Err = err
+			}
+		}
+	}
+
+	// The retries of the results that could not be stored are applied
+	for i, sub := range batch {
+		if claimed[i] && !stored[i] && outcomes[i].Err != nil && !errors.Is(outcomes[i].Err, entity.ErrResultTransition) {
+			_ = s.submissions.ReleaseSubmission(ctx, sub.AgentPaw, sub.SubmissionID)
+		}
+	}
+	return outcomes, nil
+}
+
+// claimBatch claims the idempotency keys of a batch, one transaction per
+// agent, marking the duplicates in outcomes, including a key repeated within
+// the batch. It reports the submissions whose key it claimed.
+func (s *ExecutionService) claimBatch(ctx context.Context, batch []ResultSubmission, outcomes []ResultSubmissionOutcome) ([]bool, error) {
+	claimed := make([]bool, len(batch))
+	if s.submissions == nil {
+		return claimed, nil
+	}
+
+	var agents []string
+	keys := make(map[string]map[string]string) // Submission ID to result ID, by agent
+	for i, sub := range batch {
+		if sub.SubmissionID == "" {
+			continue
+		}
+		agentKeys, ok := keys[sub.AgentPaw]
+		if !ok {
+			agentKeys = make(map[string]string)
+			keys[sub.AgentPaw] = agentKeys
+			agents = append(agents, sub.AgentPaw)
+		}
+		if _, ok := agentKeys[sub.SubmissionID]; ok {
+			outcomes[i].Duplicate = true
+			continue
+		}
+		agentKeys[sub.SubmissionID] = sub.ResultID
+	}
+
+	receivedAt := time.Now()
+	claims := make(map[string]map[string]bool, len(agents))
+	for _, paw := range agents {
+		agentClaims, err := s.submissions.ClaimSubmissions(ctx, paw, keys[paw], receivedAt)
+		if err != nil {
+			// Forget the keys claimed for the other agents, nothing being applied
+			for _, claimedPaw := range agents {
+				for submissionID := range claims[claimedPaw] {
+					_ = s.submissions.ReleaseSubmission(ctx, claimedPaw, submissionID)
+				}
+			}
+			return nil, err
+		}
+		claims[paw] = agentClaims
+	}
+	for i, sub := range batch {
+		if sub.SubmissionID != "" && !outcomes[i].Duplicate {
+			claimed[i] = claims[sub.AgentPaw][sub.SubmissionID]
+			outcomes[i].Duplicate = !claimed[i]
+		}
+	}
+	return claimed, nil
+}
+
+// storeResults stores reported results, in a single transaction when
+// possible, returning the error of each
+func (s *ExecutionService) storeResults(ctx context.Context, results []*entity.ExecutionResult) []error {
+	errs := make([]error, len(results))
+	if len(results) == 0 {
+		return errs
+	}
+	if s.resultBatch == nil {
+		for i, result := range results {
+			errs[i] = s.resultRepo.UpdateResult(ctx, result)
+		}
+		return errs
+	}
+
+	batchErrs, err := s.resultBatch.UpdateResults(ctx, results)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	return batchErrs
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockResultBatchRepo records the batches of results stored
+type mockResultBatchRepo struct {
+	batches [][]*entity.ExecutionResult
+	err     error
+}
+
+func (m *mockResultBatchRepo) UpdateResults(ctx context.Context, results []*entity.ExecutionResult) ([]error, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.batches = append(m.batches, results)
+	return make([]error, len(results)), nil
+}
+
+func TestSubmitResults(t *testing.T) {
+	svc, resultRepo, _ := newSubmissionTestService()
+	batchRepo := &mockResultBatchRepo{}
+	svc.SetResultBatch(batchRepo)
+	ctx := context.Background()
+
+	outcomes, err := svc.SubmitResults(ctx, "paw1", []ResultSubmission{
+		{SubmissionID: "sub-1", ResultID: "r1", Status: entity.StatusSuccess, Output: "ok"},
+		{SubmissionID: "sub-1", ResultID: "r1", Status: entity.StatusSuccess, Output: "ok"}, // Retried within the batch
+		{SubmissionID: "sub-2", ResultID: "missing", Status: entity.StatusSuccess},
+		{SubmissionID: "sub-3", ResultID: "r2", Status: entity.StatusFailed, ExitCode: 1},
+	})
+	if err != nil {
+		t.Fatalf("SubmitResults failed: %v", err)
+	}
+	if outcomes[0].Duplicate || outcomes[0].Err != nil {
+		t.Errorf("Expected the first result applied, got %+v", outcomes[0])
+	}
+	if !outcomes[1].Duplicate {
+		t.Errorf("Expected the retry within the batch to be a duplicate, got %+v", outcomes[1])
+	}
+	if outcomes[2].Err == nil {
+		t.Error("Expected an error for an unknown result, the others still applied")
+	}
+	if outcomes[3].Duplicate || outcomes[3].Err != nil {
+		t.Errorf("Expected the last result applied, got %+v", outcomes[3])
+	}
+
+	// Stored in a single batch, then the execution completed once
+	if len(batchRepo.batches) != 1 || len(batchRepo.batches[0]) != 2 {
+		t.Fatalf("Expected one batch of 2 results, got %v", batchRepo.batches)
+	}
+	if execution := resultRepo.executions["e1"]; execution.Status != entity.ExecutionCompleted {
+		t.Errorf("Expected the execution completed, got %s", execution.Status)
+	}
+
+	// The key of the unknown result is released, the others are duplicates
+	outcomes, err = svc.SubmitResults(ctx, "paw1", []ResultSubmission{
+		{SubmissionID: "sub-1", ResultID: "r1", Status: entity.StatusFailed},
+		{SubmissionID: "sub-2", ResultID: "missing", Status: entity.StatusSuccess},
+	})
+	if err != nil {
+		t.Fatalf("SubmitResults failed: %v", err)
+	}
+	if !outcomes[0].Duplicate || outcomes[1].Duplicate || outcomes[1].Err == nil {
+		t.Errorf("Unexpected outcomes of the retry: %+v", outcomes)
+	}
+}
+
+func TestSubmitResults_StoreFailure(t *testing.T) {
+	svc, resultRepo, submissions := newSubmissionTestService()
+	svc.resultRepo = &unstoredResultRepo{mockResultRepo: resultRepo}
+	svc.SetResultBatch(&mockResultBatchRepo{err: errors.New("database is locked")})
+
+	outcomes, err := svc.SubmitResults(context.Background(), "paw1", []ResultSubmission{
+		{SubmissionID: "sub-1", ResultID: "r1", Status: entity.StatusSuccess},
+		{SubmissionID: "sub-2", ResultID: "r2", Status: entity.StatusSuccess},
+	})
+	if err != nil {
+		t.Fatalf("SubmitResults failed: %v", err)
+	}
+	for i, outcome := range outcomes {
+		if outcome.Err == nil || outcome.Duplicate {
+			t.Errorf("Expected result %d to fail with the batch, got %+v", i, outcome)
+		}
+	}
+	if len(submissions.keys) != 0 {
+		t.Errorf("Expected the keys of the failed batch released, got %v", submissions.keys)
+	}
+}
+
+func TestSubmitResults_InvalidBatch(t *testing.T) {
+	svc, _, _ := newSubmissionTestService()
+
+	if _, err := svc.SubmitResults(context.Background(), "paw1", nil); !errors.Is(err, ErrInvalidResultBatch) {
+		t.Errorf("Expected ErrInvalidResultBatch for an empty batch, got %v", err)
+	}
+	batch := make([]ResultSubmission, MaxResultBatch+1)
+	if _, err := svc.SubmitResults(context.Background(), "paw1", batch); !errors.Is(err, ErrInvalidResultBatch) {
+		t.Errorf("Expected ErrInvalidResultBatch for an oversized batch, got %v", err)
+	}
+}
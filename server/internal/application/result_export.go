@@ -0,0 +1,115 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// ResultVerbosity controls how much context is attached to exported and pushed results
+type ResultVerbosity string
+
+const (
+	// VerbosityMinimal sends results as stored
+	VerbosityMinimal ResultVerbosity = "minimal"
+	// VerbosityFull adds denormalized technique metadata to every result
+	VerbosityFull ResultVerbosity = "full"
+)
+
+// ParseResultVerbosity parses a verbosity flag, defaulting to minimal when empty
+func ParseResultVerbosity(value string) (ResultVerbosity, error) {
+	switch ResultVerbosity(strings.ToLower(strings.TrimSpace(value))) {
+	case "", VerbosityMinimal:
+		return VerbosityMinimal, nil
+	case VerbosityFull:
+		return VerbosityFull, nil
+	default:
+		return "", fmt.Errorf("invalid verbosity %q: must be %s or %s", value, VerbosityMinimal, VerbosityFull)
+	}
+}
+
+// TechniqueContext is the technique metadata embedded in enriched results
+type TechniqueContext struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Tactic    entity.TacticType `json:"tactic"`
+	Platforms []string          `json:"platforms"`
+	Severity  string            `json:"severity,omitempty"`
+	Impact    float64           `json:"impact,omitempty"`
+	URL       string            `json:"url"`
+}
+
+// EnrichedResult is an execution result with optional technique context and
+// analyst annotation
+type EnrichedResult struct {
+	*entity.ExecutionResult
+	Technique  *TechniqueContext        `json:"technique,omitempty"`
+	Annotation *entity.ResultAnnotation `json:"annotation,omitempty"`
+}
+
+// ExecutionExport is the self-contained export of an execution and its results
+type ExecutionExport struct {
+	Execution    *entity.Execution `json:"execution"`
+	ScenarioName string            `json:"scenario_name,omitempty"`
+	Verbosity    ResultVerbosity   `json:"verbosity"`
+	ExportedAt   time.Time         `json:"exported_at"`
+	Results      []EnrichedResult  `json:"results"`
+
+	// Purple-team review of the execution, when one was requested
+	Review *entity.ExecutionReview `json:"review,omitempty"`
+}
+
+// AttackURL returns the MITRE ATT&CK page of a technique or sub-technique
+func AttackURL(techniqueID string) string {
+	return "https://attack.mitre.org/techniques/" + strings.ReplaceAll(techniqueID, ".", "/") + "/"
+}
+
+// enrichResults attaches technique metadata when verbosity is full. Each technique
+// is looked up once; unknown techniques still get their ATT&CK URL.
+func enrichResults(
+	ctx context.Context,
+	techniqueRepo repository.TechniqueRepository,
+	results []*entity.ExecutionResult,
+	verbosity ResultVerbosity,
+) []EnrichedResult {
+	enriched := make([]EnrichedResult, 0, len(results))
+	contexts := make(map[string]*TechniqueContext)
+
+	for _, result := range results {
+		item := EnrichedResult{ExecutionResult: result}
+		if verbosity == VerbosityFull {
+			tc, ok := contexts[result.TechniqueID]
+			if !ok {
+				tc = techniqueContext(ctx, techniqueRepo, result.TechniqueID)
+				contexts[result.TechniqueID] = tc
+			}
+			item.Technique = tc
+		}
+		enriched = append(enriched, item)
+	}
+
+	return enriched
+}
+
+func techniqueContext(ctx context.Context, techniqueRepo repository.TechniqueRepository, id string) *TechniqueContext {
+	tc := &TechniqueContext{ID: id, Platforms: []string{}, URL: AttackURL(id)}
+	if techniqueRepo == nil {
+		return tc
+	}
+	technique, err := techniqueRepo.FindByID(ctx, id)
+	if err != nil || technique == nil {
+		return tc
+	}
+	tc.Name = technique.Name
+	tc.Tactic = technique.Tactic
+	tc.Severity = technique.EffectiveSeverity()
+	tc.Impact = technique.Impact
+	if technique.Platforms != nil {
+		tc.Platforms = technique.Platforms
+	}
+	return tc
+}
@@ -0,0 +1,201 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+func setupExportTest() (*ExecutionService, *mockResultRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", ScenarioID: "s1", Status: entity.ExecutionRunning}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1059.001", Status: entity.StatusBlocked},
+		{ID: "r2", ExecutionID: "exec-1", TechniqueID: "T1059.001", Status: entity.StatusSuccess},
+		{ID: "r3", ExecutionID: "exec-1", TechniqueID: "T9999", Status: entity.StatusDetected},
+	}
+
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "PowerShell chain"}
+
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059.001"] = &entity.Technique{ID: "T1059.001", Name: "PowerShell",
+		Tactic: entity.TacticExecution, Platforms: []string{"windows"}}
+
+	svc := NewExecutionService(resultRepo, scenarioRepo, techRepo, newMockAgentRepo(), nil, service.NewScoreCalculator())
+	return svc, resultRepo
+}
+
+func TestParseResultVerbosity(t *testing.T) {
+	tests := map[string]ResultVerbosity{"": VerbosityMinimal, "minimal": VerbosityMinimal, " FULL ": VerbosityFull}
+	for input, want := range tests {
+		got, err := ParseResultVerbosity(input)
+		if err != nil || got != want {
+			t.Errorf("ParseResultVerbosity(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseResultVerbosity("debug"); err == nil {
+		t.Error("Expected error for unknown verbosity")
+	}
+}
+
+func TestAttackURL(t *testing.T) {
+	if got := AttackURL("T1059.001"); got != "https://attack.mitre.org/techniques/T1059/001/" {
+		t.Errorf("Unexpected sub-technique URL: %s", got)
+	}
+	if got := AttackURL("T1082"); got != "https://attack.mitre.org/techniques/T1082/" {
+		t.Errorf("Unexpected technique URL: %s", got)
+	}
+}
+
+func TestExecutionService_ExportExecution(t *testing.T) {
+	svc, _ := setupExportTest()
+
+	minimal, err := svc.ExportExecution(context.Background(), "exec-1", VerbosityMinimal)
+	if err != nil {
+		t.Fatalf("ExportExecution failed: %v", err)
+	}
+	if minimal.ScenarioName != "PowerShell chain" || len(minimal.Results) != 3 || minimal.Results[0].Technique != nil {
+		t.Errorf("Unexpected minimal export: %+v", minimal)
+	}
+
+	full, err := svc.ExportExecution(context.Background(), "exec-1", VerbosityFull)
+	if err != nil {
+		t.Fatalf("ExportExecution failed: %v", err)
+	}
+	tc := full.Results[0].Technique
+	if tc == nil || tc.Name != "PowerShell" || tc.Tactic != entity.TacticExecution || tc.URL != AttackURL("T1059.001") {
+		t.Errorf("Unexpected technique context: %+v", tc)
+	}
+	if full.Results[1].Technique != tc {
+		t.Error("Expected technique context to be shared between results of the same technique")
+	}
+	if unknown := full.Results[2].Technique; unknown == nil || unknown.Name != "" || unknown.URL == "" {
+		t.Errorf("Expected unknown technique to keep its ATT&CK URL, got %+v", unknown)
+	}
+
+	// Result fields stay at the top level of each JSON object
+	data, _ := json.Marshal(full.Results[0])
+	var decoded map[string]any
+	_ = json.Unmarshal(data, &decoded)
+	if decoded["technique_id"] != "T1059.001" || decoded["technique"] == nil {
+		t.Errorf("Unexpected JSON shape: %s", data)
+	}
+}
+
+func TestExecutionService_ExportExecutionNotFound(t *testing.T) {
+	svc, _ := setupExportTest()
+
+	if _, err := svc.ExportExecution(context.Background(), "missing", VerbosityFull); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Expected ErrExecutionNotFound, got %v", err)
+	}
+}
+
+func TestExecutionService_CompleteExecutionSendsWebhook(t *testing.T) {
+	received := make(chan WebhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	svc, resultRepo := setupExportTest()
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true,
+		WebhookURL:  server.URL,
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionCompleted: {entity.ChannelWebhook}},
+	}
+	notifier := NewNotificationService(notificationRepo, nil, nil, "", nil)
+	notifier.SetWebhookResults(resultRepo, svc.techniqueRepo, VerbosityFull)
+	events := NewEventBus(nil)
+	events.AddSink(notifier)
+	svc.SetEventBus(events)
+
+	if err := svc.CompleteExecution(context.Background(), "exec-1"); err != nil {
+		t.Fatalf("CompleteExecution failed: %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload.Event != entity.NotificationExecutionCompleted || payload.Data["ScenarioName"] != "PowerShell chain" {
+			t.Errorf("Unexpected payload: %+v", payload)
+		}
+		if len(payload.Results) != 3 || payload.Results[0].Technique == nil || payload.Results[0].Technique.Name != "PowerShell" {
+			t.Errorf("Expected enriched results in webhook, got %+v", payload.Results)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for webhook")
+	}
+}
+
+func TestNotificationService_DeliversPerEventChannels(t *testing.T) {
+	received := make(chan WebhookPayload, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true,
+		WebhookURL:          server.URL,
+		ScoreAlertThreshold: 80,
+		Preferences: entity.NotificationPreferences{
+			entity.NotificationExecutionFailed: {entity.ChannelWebhook},
+			entity.NotificationScoreAlert:      {entity.ChannelWebhook},
+		},
+	}
+	svc := NewNotificationService(notificationRepo, nil, nil, "", nil)
+	execution := &entity.Execution{ID: "exec-1", StartedAt: time.Now(), Score: &entity.SecurityScore{Overall: 40}}
+
+	// Starts and completions are not subscribed; the low score alert is, on its own
+	_ = svc.NotifyExecutionStarted(context.Background(), execution, "Scenario")
+	_ = svc.NotifyExecutionCompleted(context.Background(), execution, "Scenario")
+	_ = svc.NotifyExecutionFailed(context.Background(), execution, "Scenario", "agent lost")
+
+	events := make(map[entity.NotificationType]bool)
+	for range 2 {
+		select {
+		case payload := <-received:
+			events[payload.Event] = true
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for webhooks")
+		}
+	}
+	if !events[entity.NotificationScoreAlert] || !events[entity.NotificationExecutionFailed] {
+		t.Errorf("Expected score alert and failure webhooks, got %v", events)
+	}
+
+	select {
+	case payload := <-received:
+		t.Errorf("Unexpected webhook for %s", payload.Event)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if len(notificationRepo.notifications) != 2 {
+		t.Errorf("Expected 2 stored notifications, got %d", len(notificationRepo.notifications))
+	}
+}
+
+func TestNotificationService_SendWebhookErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	svc := NewNotificationService(newMockNotificationRepo(), nil, nil, "", nil)
+	err := svc.sendWebhook(context.Background(), server.URL, "", &WebhookPayload{Event: entity.NotificationExecutionStarted})
+	if err == nil {
+		t.Error("Expected error on non-2xx webhook response")
+	}
+}
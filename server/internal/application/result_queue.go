@@ -0,0 +1,267 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Defaults of the result queue configuration
+const (
+	DefaultResultQueueSize  = 5000
+	DefaultResultQueueBatch = 500
+)
+
+// resultQueueRetryAfter is how long agents wait before resending results the
+// full queue refused
+const resultQueueRetryAfter = 5 * time.Second
+
+// ErrResultQueueFull is returned when the result queue cannot take a batch
+// until the writer catches up; the agent resends it later
+var ErrResultQueueFull = errors.New("result queue is full")
+
+// ResultQueueConfig configures the result queue
+type ResultQueueConfig struct {
+	Size      int // Results waiting to be written; batches are refused past it
+	BatchSize int // Results written per transaction
+}
+
+// ResultQueueStats is the state of the result queue, and its activity since
+// the server started
+type ResultQueueStats struct {
+	Depth         int        `json:"depth"`    // Results waiting to be written
+	Capacity      int        `json:"capacity"` // Results the queue holds
+	HighWater     int        `json:"high_water"`
+	Enqueued      int64      `json:"enqueued"`
+	Written       int64      `json:"written"`
+	Rejected      int64      `json:"rejected"` // Results refused while the queue was full
+	Flushes       int64      `json:"flushes"`
+	LastFlushAt   *time.Time `json:"last_flush_at,omitempty"`
+	LastFlushSize int        `json:"last_flush_size"`
+	LastFlushMS   int64      `json:"last_flush_ms"`
+	RetryAfterSec int        `json:"retry_after_seconds"` // Delay agents wait after a refusal
+}
+
+// resultJob is a batch of results waiting for the writer
+type resultJob struct {
+	ctx      context.Context
+	batch    []ResultSubmission
+	outcomes []ResultSubmissionOutcome
+	err      error
+	done     chan struct{}
+}
+
+// ResultQueue serializes the results agents report through a single writer,
+// which stores the batches queued meanwhile in one transaction. A burst of
+// agents reporting at once then waits in memory rather than contending for
+// the database, and is refused with ErrResultQueueFull past the queue size.
+type ResultQueue struct {
+	executions *ExecutionService
+	config     ResultQueueConfig
+	logger     *zap.Logger
+	wake       chan struct{}
+
+	mu       sync.Mutex
+	pending  []*resultJob
+	stats    ResultQueueStats
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+}
+
+// NewResultQueue creates a result queue writing with executions; Start runs
+// its writer, results being applied directly until then
+func NewResultQueue(executions *ExecutionService, config ResultQueueConfig, logger *zap.Logger) *ResultQueue {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if config.Size <= 0 {
+		config.Size = DefaultResultQueueSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultResultQueueBatch
+	}
+	return &ResultQueue{
+		executions: executions,
+		config:     config,
+		logger:     logger,
+		wake:       make(chan struct{}, 1),
+		stats: ResultQueueStats{
+			Capacity:      config.Size,
+			RetryAfterSec: int(resultQueueRetryAfter / time.Second),
+		},
+	}
+}
+
+// RetryAfter returns how long agents wait before resending refused results
+func (q *ResultQueue) RetryAfter() time.Duration {
+	return resultQueueRetryAfter
+}
+
+// SubmitResults queues a batch of results an agent reported and waits until
+// the writer applied it, returning the outcome of each as
+// ExecutionService.SubmitResults does. ErrResultQueueFull is returned, nothing
+// being applied, when the queue cannot take the batch.
+func (q *ResultQueue) SubmitResults(ctx context.Context, agentPaw string, batch []ResultSubmission) ([]ResultSubmissionOutcome, error) {
+	if len(batch) == 0 || len(batch) > MaxResultBatch {
+		return q.executions.SubmitResults(ctx, agentPaw, batch)
+	}
+
+	job := &resultJob{ctx: ctx, batch: make([]ResultSubmission, len(batch)), done: make(chan struct{})}
+	for i, sub := range batch {
+		sub.AgentPaw = agentPaw
+		job.batch[i] = sub
+	}
+
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return q.executions.SubmitResults(ctx, agentPaw, batch)
+	}
+	if q.stats.Depth+len(batch) > q.config.Size {
+		q.stats.Rejected += int64(len(batch))
+		q.mu.Unlock()
+		return nil, ErrResultQueueFull
+	}
+	q.pending = append(q.pending, job)
+	q.stats.Depth += len(batch)
+	q.stats.Enqueued += int64(len(batch))
+	if q.stats.Depth > q.stats.HighWater {
+		q.stats.HighWater = q.stats.Depth
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	// Queued results are written even when the agent goes away meanwhile
+	<-job.done
+	return job.outcomes, job.err
+}
+
+// Stats returns the state of the queue
+func (q *ResultQueue) Stats() ResultQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := q.stats
+	if stats.LastFlushAt != nil {
+		at := *stats.LastFlushAt
+		stats.LastFlushAt = &at
+	}
+	return stats
+}
+
+// CheckHealth fails while the queue is at least 90% full, agents being
+// refused or about to be
+func (q *ResultQueue) CheckHealth(ctx context.Context) error {
+	stats := q.Stats()
+	if stats.Depth*10 >= stats.Capacity*9 {
+		return ErrResultQueueFull
+	}
+	return nil
+}
+
+// Start runs the writer
+func (q *ResultQueue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running {
+		return
+	}
+	q.running = true
+	q.stopChan = make(chan struct{})
+	q.wg.Add(1)
+	go q.run()
+	q.logger.Info("Result queue started",
+		zap.Int("size", q.config.Size),
+		zap.Int("batch_size", q.config.BatchSize),
+	)
+}
+
+// Stop writes the queued results and stops the writer; later results are
+// applied directly
+func (q *ResultQueue) Stop() {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return
+	}
+	q.running = false
+	close(q.stopChan)
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+// run writes the queued batches until stopped, then writes what is left
+func (q *ResultQueue) run() {
+	defer q.wg.Done()
+	for {
+		for q.flush() {
+		}
+		select {
+		case <-q.wake:
+		case <-q.stopChan:
+			for q.flush() {
+			}
+			return
+		}
+	}
+}
+
+// flush writes the oldest queued batches, up to the batch size of results
+// unless a single batch is larger, reporting whether any was written
+func (q *ResultQueue) flush() bool {
+	q.mu.Lock()
+	var jobs []*resultJob
+	size := 0
+	for len(q.pending) > 0 && (len(jobs) == 0 || size+len(q.pending[0].batch) <= q.config.BatchSize) {
+		jobs = append(jobs, q.pending[0])
+		size += len(q.pending[0].batch)
+		q.pending[0] = nil
+		q.pending = q.pending[1:]
+	}
+	q.mu.Unlock()
+	if len(jobs) == 0 {
+		return false
+	}
+
+	var batch []ResultSubmission
+	for _, job := range jobs {
+		batch = append(batch, job.batch...)
+	}
+	start := time.Now()
+	// The write outlives the requests of the agents, which wait for it
+	outcomes, err := q.executions.applyResults(context.WithoutCancel(jobs[0].ctx), batch)
+	elapsed := time.Since(start)
+	if err != nil {
+		q.logger.Error("Failed to write queued results", zap.Int("results", size), zap.Error(err))
+	}
+
+	offset := 0
+	for _, job := range jobs {
+		if err != nil {
+			job.err = err
+		} else {
+			job.outcomes = outcomes[offset : offset+len(job.batch)]
+		}
+		offset += len(job.batch)
+		close(job.done)
+	}
+
+	q.mu.Lock()
+	q.stats.Depth -= size
+	q.stats.Flushes++
+	if err == nil {
+		q.stats.Written += int64(size)
+	}
+	q.stats.LastFlushAt = &start
+	q.stats.LastFlushSize = size
+	q.stats.LastFlushMS = elapsed.Milliseconds()
+	q.mu.Unlock()
+	return true
+}
@@ -0,0 +1,109 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// waitForDepth waits until depth results are queued
+func waitForDepth(t *testing.T, q *ResultQueue, depth int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for q.Stats().Depth != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d results queued, got %d", depth, q.Stats().Depth)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestResultQueue_BatchesAgents(t *testing.T) {
+	svc, resultRepo, _ := newSubmissionTestService()
+	resultRepo.results["e1"][1].AgentPaw = "paw2"
+	batchRepo := &mockResultBatchRepo{}
+	svc.SetResultBatch(batchRepo)
+
+	// Queue the batches of two agents without a writer, then write them at once
+	q := NewResultQueue(svc, ResultQueueConfig{Size: 10}, nil)
+	q.running = true
+	type submitted struct {
+		outcomes []ResultSubmissionOutcome
+		err      error
+	}
+	done := make(chan submitted, 2)
+	submit := func(paw string, sub ResultSubmission) {
+		outcomes, err := q.SubmitResults(context.Background(), paw, []ResultSubmission{sub})
+		done <- submitted{outcomes, err}
+	}
+	go submit("paw1", ResultSubmission{SubmissionID: "sub-1", ResultID: "r1", Status: entity.StatusSuccess})
+	waitForDepth(t, q, 1)
+	go submit("paw2", ResultSubmission{SubmissionID: "sub-1", ResultID: "r2", Status: entity.StatusFailed, ExitCode: 1})
+	waitForDepth(t, q, 2)
+
+	if !q.flush() {
+		t.Fatal("Expected the queued batches written")
+	}
+	for i := 0; i < 2; i++ {
+		res := <-done
+		if res.err != nil || len(res.outcomes) != 1 || res.outcomes[0].Duplicate || res.outcomes[0].Err != nil {
+			t.Errorf("Expected the result applied, got %+v, %v", res.outcomes, res.err)
+		}
+	}
+	if len(batchRepo.batches) != 1 || len(batchRepo.batches[0]) != 2 {
+		t.Fatalf("Expected one batch of 2 results, got %v", batchRepo.batches)
+	}
+	if execution := resultRepo.executions["e1"]; execution.Status != entity.ExecutionCompleted {
+		t.Errorf("Expected the execution completed, got %s", execution.Status)
+	}
+
+	stats := q.Stats()
+	if stats.Depth != 0 || stats.HighWater != 2 || stats.Enqueued != 2 || stats.Written != 2 || stats.Flushes != 1 || stats.LastFlushAt == nil {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestResultQueue_Backpressure(t *testing.T) {
+	svc, _, _ := newSubmissionTestService()
+	q := NewResultQueue(svc, ResultQueueConfig{Size: 1}, nil)
+	q.running = true
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := q.SubmitResults(context.Background(), "paw1", []ResultSubmission{{SubmissionID: "sub-1", ResultID: "r1", Status: entity.StatusSuccess}})
+		done <- err
+	}()
+	waitForDepth(t, q, 1)
+
+	// The full queue refuses the batch, nothing being claimed
+	_, err := q.SubmitResults(context.Background(), "paw1", []ResultSubmission{{SubmissionID: "sub-2", ResultID: "r2", Status: entity.StatusSuccess}})
+	if !errors.Is(err, ErrResultQueueFull) {
+		t.Fatalf("Expected ErrResultQueueFull, got %v", err)
+	}
+	if err := q.CheckHealth(context.Background()); err == nil {
+		t.Error("Expected the full queue reported unhealthy")
+	}
+	if stats := q.Stats(); stats.Rejected != 1 {
+		t.Errorf("Expected 1 result rejected, got %d", stats.Rejected)
+	}
+
+	q.flush()
+	if err := <-done; err != nil {
+		t.Errorf("Expected the queued batch written, got %v", err)
+	}
+	if err := q.CheckHealth(context.Background()); err != nil {
+		t.Errorf("Expected the drained queue healthy, got %v", err)
+	}
+
+	// The resent batch goes through once the writer runs
+	q.running = false
+	q.Start()
+	defer q.Stop()
+	outcomes, err := q.SubmitResults(context.Background(), "paw1", []ResultSubmission{{SubmissionID: "sub-2", ResultID: "r2", Status: entity.StatusSuccess}})
+	if err != nil || outcomes[0].Err != nil || outcomes[0].Duplicate {
+		t.Fatalf("Expected the resent batch applied, got %+v, %v", outcomes, err)
+	}
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// ResultSubmissionRetention is how long the idempotency keys of result
+// submissions are kept, well past the time an agent keeps retrying
+const ResultSubmissionRetention = 7 * 24 * time.Hour
+
+// SetSubmissionLog drops the retried result submissions of agents, by the
+// idempotency keys recorded in repo, which may be nil
+func (s *ExecutionService) SetSubmissionLog(repo repository.ResultSubmissionRepository) {
+	s.submissions = repo
+}
+
+// SubmitResult applies a result an agent submitted under the idempotency key
+// submissionID, reporting a duplicate when the agent already submitted it.
+// Submissions without a key are applied as UpdateResultByID does. A
+// submission whose result could not be stored releases its key, so that the
+// retry of the agent is applied.
+func (s *ExecutionService) SubmitResult(
+	ctx context.Context,
+	submissionID string,
+	resultID string,
+	status entity.ResultStatus,
+	output string,
+	exitCode int,
+	agentPaw string,
+) (duplicate bool, err error) {
+	if submissionID == "" || s.submissions == nil {
+		return false, s.UpdateResultByID(ctx, resultID, status, output, exitCode, agentPaw)
+	}
+
+	claimed, err := s.submissions.ClaimSubmission(ctx, agentPaw, submissionID, resultID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		return true, nil
+	}
+
+	err = s.UpdateResultByID(ctx, resultID, status, output, exitCode, agentPaw)
+	if err != nil && !errors.Is(err, entity.ErrResultTransition) && !s.resultStored(ctx, resultID, status) {
+		_ = s.submissions.ReleaseSubmission(ctx, agentPaw, submissionID)
+	}
+	return false, err
+}
+
+// resultStored reports whether a result has status, i.e. whether a submission
+// that failed past the update of its result was still stored
+func (s *ExecutionService) resultStored(ctx context.Context, resultID string, status entity.ResultStatus) bool {
+	result, err := s.resultRepo.FindResultByID(ctx, resultID)
+	return err == nil && result.Status == status
+}
+
+// PurgeSubmissions deletes the idempotency keys older than ResultSubmissionRetention
+func (s *ExecutionService) PurgeSubmissions(ctx context.Context, now time.Time) (int64, error) {
+	if s.submissions == nil {
+		return 0, nil
+	}
+	return s.submissions.DeleteSubmissionsBefore(ctx, now.Add(-ResultSubmissionRetention))
+}
@@ -0,0 +1,156 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+type mockSubmissionRepo struct {
+	keys map[string]time.Time
+}
+
+func newMockSubmissionRepo() *mockSubmissionRepo {
+	return &mockSubmissionRepo{keys: make(map[string]time.Time)}
+}
+
+func (m *mockSubmissionRepo) ClaimSubmission(ctx context.Context, paw, submissionID, resultID string, receivedAt time.Time) (bool, error) {
+	if _, ok := m.keys[paw+"/"+submissionID]; ok {
+		return false, nil
+	}
+	m.keys[paw+"/"+submissionID] = receivedAt
+	return true, nil
+}
+
+func (m *mockSubmissionRepo) ClaimSubmissions(ctx context.Context, paw string, submissions map[string]string, receivedAt time.Time) (map[string]bool, error) {
+	claimed := make(map[string]bool)
+	for submissionID, resultID := range submissions {
+		claimed[submissionID], _ = m.ClaimSubmission(ctx, paw, submissionID, resultID, receivedAt)
+	}
+	return claimed, nil
+}
+
+func (m *mockSubmissionRepo) ReleaseSubmission(ctx context.Context, paw, submissionID string) error {
+	delete(m.keys, paw+"/"+submissionID)
+	return nil
+}
+
+func (m *mockSubmissionRepo) DeleteSubmissionsBefore(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	for key, receivedAt := range m.keys {
+		if receivedAt.Before(before) {
+			delete(m.keys, key)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func newSubmissionTestService() (*ExecutionService, *mockResultRepo, *mockSubmissionRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionRunning}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", AgentPaw: "paw1", Status: entity.StatusRunning},
+		{ID: "r2", ExecutionID: "e1", AgentPaw: "paw1", Status: entity.StatusRunning},
+	}
+	submissions := newMockSubmissionRepo()
+	svc := &ExecutionService{resultRepo: resultRepo, calculator: service.NewScoreCalculator()}
+	svc.SetSubmissionLog(submissions)
+	return svc, resultRepo, submissions
+}
+
+func TestSubmitResult_DropsRetries(t *testing.T) {
+	svc, resultRepo, _ := newSubmissionTestService()
+	ctx := context.Background()
+
+	duplicate, err := svc.SubmitResult(ctx, "sub-1", "r1", entity.StatusSuccess, "ok", 0, "paw1")
+	if err != nil || duplicate {
+		t.Fatalf("Expected the first submission applied, got %v, %v", duplicate, err)
+	}
+	// The retry is dropped, even with another status
+	duplicate, err = svc.SubmitResult(ctx, "sub-1", "r1", entity.StatusFailed, "retry", 1, "paw1")
+	if err != nil || !duplicate {
+		t.Fatalf("Expected the retry reported as a duplicate, got %v, %v", duplicate, err)
+	}
+	if result := resultRepo.results["e1"][0]; result.Status != entity.StatusSuccess || result.Output != "ok" {
+		t.Errorf("Expected the first submission kept, got %s %q", result.Status, result.Output)
+	}
+}
+
+// unstoredResultRepo fails the updates of results, leaving them unchanged
+type unstoredResultRepo struct {
+	*mockResultRepo
+	fail bool
+}
+
+func (m *unstoredResultRepo) FindResultByID(ctx context.Context, id string) (*entity.ExecutionResult, error) {
+	result, err := m.mockResultRepo.FindResultByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *result
+	return &copied, nil
+}
+
+func (m *unstoredResultRepo) UpdateResult(ctx context.Context, result *entity.ExecutionResult) error {
+	if m.fail {
+		return errors.New("database is locked")
+	}
+	return m.mockResultRepo.UpdateResult(ctx, result)
+}
+
+func TestSubmitResult_ReleasesFailedSubmissions(t *testing.T) {
+	svc, resultRepo, submissions := newSubmissionTestService()
+	repo := &unstoredResultRepo{mockResultRepo: resultRepo, fail: true}
+	svc.resultRepo = repo
+	ctx := context.Background()
+
+	if _, err := svc.SubmitResult(ctx, "sub-1", "r1", entity.StatusSuccess, "ok", 0, "paw1"); err == nil {
+		t.Fatal("Expected the update error")
+	}
+	if len(submissions.keys) != 0 {
+		t.Error("Expected the key of a failed submission released")
+	}
+
+	// The retry goes through
+	repo.fail = false
+	duplicate, err := svc.SubmitResult(ctx, "sub-1", "r1", entity.StatusSuccess, "ok", 0, "paw1")
+	if err != nil || duplicate {
+		t.Fatalf("Expected the retry applied, got %v, %v", duplicate, err)
+	}
+	if len(submissions.keys) != 1 {
+		t.Error("Expected the key of the applied retry kept")
+	}
+}
+
+func TestSubmitResult_WithoutKey(t *testing.T) {
+	svc, _, submissions := newSubmissionTestService()
+
+	duplicate, err := svc.SubmitResult(context.Background(), "", "r2", entity.StatusSuccess, "ok", 0, "paw1")
+	if err != nil || duplicate {
+		t.Fatalf("Expected a submission without key applied, got %v, %v", duplicate, err)
+	}
+	if len(submissions.keys) != 0 {
+		t.Error("Expected no key recorded")
+	}
+}
+
+func TestPurgeSubmissions(t *testing.T) {
+	svc, _, submissions := newSubmissionTestService()
+	now := time.Now()
+	submissions.keys["paw1/old"] = now.Add(-ResultSubmissionRetention - time.Hour)
+	submissions.keys["paw1/new"] = now.Add(-time.Hour)
+
+	n, err := svc.PurgeSubmissions(context.Background(), now)
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 key purged, got %d, %v", n, err)
+	}
+
+	if n, err := (&ExecutionService{}).PurgeSubmissions(context.Background(), now); err != nil || n != 0 {
+		t.Errorf("Expected nothing purged without a submission log, got %d, %v", n, err)
+	}
+}
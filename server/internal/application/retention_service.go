@@ -0,0 +1,411 @@
+package application
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+// ErrRetentionInProgress is returned when the retention job is started while it runs
+var ErrRetentionInProgress = errors.New("retention job already running")
+
+// ArchiveStore keeps the archives of the execution data the retention job
+// removes, on disk or in object storage
+type ArchiveStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Location() string
+}
+
+// RetentionConfig controls how long execution data is kept. A zero retention keeps the data forever.
+type RetentionConfig struct {
+	OutputRetention    time.Duration // Raw result outputs are cleared after this, scores and statuses stay
+	ExecutionRetention time.Duration // Executions are deleted with their results after this
+	RunHour            int           // Hour of the day, in server time, the nightly job runs at
+	BatchSize          int           // Rows archived and removed at a time
+}
+
+// DefaultRetentionConfig returns the default retention settings: everything is
+// kept, and the job runs at 3 AM once a retention is set
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{RunHour: 3, BatchSize: 500}
+}
+
+// RetentionRun reports a run of the retention job
+type RetentionRun struct {
+	StartedAt         time.Time  `json:"started_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty"`
+	OutputsCleared    int64      `json:"outputs_cleared"`
+	ExecutionsDeleted int64      `json:"executions_deleted"`
+	ResultsDeleted    int64      `json:"results_deleted"`
+	Archives          []string   `json:"archives"`
+	Error             string     `json:"error,omitempty"`
+}
+
+// RetentionTotals counts the rows reclaimed since the server started
+type RetentionTotals struct {
+	Runs              int64 `json:"runs"`
+	OutputsCleared    int64 `json:"outputs_cleared"`
+	ExecutionsDeleted int64 `json:"executions_deleted"`
+	ResultsDeleted    int64 `json:"results_deleted"`
+	Archives          int64 `json:"archives"`
+}
+
+// RetentionStatus reports the retention policies and what the job reclaimed
+type RetentionStatus struct {
+	OutputRetentionDays    int             `json:"output_retention_days"`
+	ExecutionRetentionDays int             `json:"execution_retention_days"`
+	Archive                string          `json:"archive,omitempty"`
+	NextRunAt              *time.Time      `json:"next_run_at,omitempty"`
+	LastRun                *RetentionRun   `json:"last_run,omitempty"`
+	Totals                 RetentionTotals `json:"totals"`
+}
+
+// archivedExecution is an execution and its results in an archive
+type archivedExecution struct {
+	Execution *entity.Execution         `json:"execution"`
+	Results   []*entity.ExecutionResult `json:"results"`
+}
+
+// RetentionService keeps the execution tables from growing unbounded: a
+// nightly job clears the raw outputs of old results, then deletes old
+// executions. Removed rows are first archived to gzip-compressed JSON when an
+// archive store is set; a failed archive leaves the rows in place.
+type RetentionService struct {
+	repo       repository.RetentionRepository
+	resultRepo repository.ResultRepository
+	archive    ArchiveStore
+	blobs      BlobStore
+	config     RetentionConfig
+	logger     *zap.Logger
+
+	mu        sync.Mutex
+	inRun     bool
+	nextRunAt *time.Time
+	lastRun   *RetentionRun
+	totals    RetentionTotals
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+}
+
+// NewRetentionService creates a retention service
+func NewRetentionService(
+	repo repository.RetentionRepository,
+	resultRepo repository.ResultRepository,
+	config RetentionConfig,
+	logger *zap.Logger,
+) *RetentionService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultRetentionConfig().BatchSize
+	}
+	return &RetentionService{repo: repo, resultRepo: resultRepo, config: config, logger: logger}
+}
+
+// SetArchiveStore archives the removed rows to store
+func (s *RetentionService) SetArchiveStore(store ArchiveStore) {
+	s.archive = store
+}
+
+// SetBlobStore deletes the outputs and artifacts kept in object storage along
+// with the rows referencing them
+func (s *RetentionService) SetBlobStore(store BlobStore) {
+	s.blobs = store
+}
+
+// Enabled reports whether a retention is set
+func (s *RetentionService) Enabled() bool {
+	return s.config.OutputRetention > 0 || s.config.ExecutionRetention > 0
+}
+
+// Status returns the policies, the last run and the rows reclaimed since the server started
+func (s *RetentionService) Status() *RetentionStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &RetentionStatus{
+		OutputRetentionDays:    int(s.config.OutputRetention / (24 * time.Hour)),
+		ExecutionRetentionDays: int(s.config.ExecutionRetention / (24 * time.Hour)),
+		NextRunAt:              s.nextRunAt,
+		LastRun:                s.lastRun,
+		Totals:                 s.totals,
+	}
+	if s.archive != nil {
+		status.Archive = s.archive.Location()
+	}
+	return status
+}
+
+// Run applies the retention policies to the data older than their retention at now
+func (s *RetentionService) Run(ctx context.Context, now time.Time) (*RetentionRun, error) {
+	s.mu.Lock()
+	if s.inRun {
+		s.mu.Unlock()
+		return nil, ErrRetentionInProgress
+	}
+	s.inRun = true
+	s.mu.Unlock()
+
+	run := &RetentionRun{StartedAt: now, Archives: []string{}}
+	err := s.deleteExecutions(ctx, now, run)
+	if err == nil {
+		err = s.clearOutputs(ctx, now, run)
+	}
+	completedAt := time.Now()
+	run.CompletedAt = &completedAt
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	s.inRun = false
+	s.lastRun = run
+	s.totals.Runs++
+	s.totals.OutputsCleared += run.OutputsCleared
+	s.totals.ExecutionsDeleted += run.ExecutionsDeleted
+	s.totals.ResultsDeleted += run.ResultsDeleted
+	s.totals.Archives += int64(len(run.Archives))
+	s.mu.Unlock()
+
+	if run.OutputsCleared > 0 || run.ExecutionsDeleted > 0 {
+		s.logger.Info("Applied execution retention",
+			zap.Int64("outputs_cleared", run.OutputsCleared),
+			zap.Int64("executions_deleted", run.ExecutionsDeleted),
+			zap.Int64("results_deleted", run.ResultsDeleted),
+			zap.Int("archives", len(run.Archives)),
+		)
+	}
+	return run, err
+}
+
+// deleteExecutions deletes the executions completed before the execution retention, batch by batch
+func (s *RetentionService) deleteExecutions(ctx context.Context, now time.Time, run *RetentionRun) error {
+	if s.config.ExecutionRetention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-s.config.ExecutionRetention)
+
+	for batch := 1; ; batch++ {
+		executions, err := s.repo.FindExpiredExecutions(ctx, cutoff, s.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to find expired executions: %w", err)
+		}
+		if len(executions) == 0 {
+			return nil
+		}
+
+		if s.archive != nil {
+			records := make([]archivedExecution, len(executions))
+			for i, execution := range executions {
+				results, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+				if err != nil {
+					return fmt.Errorf("failed to load results of execution %s: %w", execution.ID, err)
+				}
+				records[i] = archivedExecution{Execution: execution, Results: s.withFullOutputs(ctx, results)}
+			}
+			if err := s.store(ctx, "executions", now, batch, records, run); err != nil {
+				return err
+			}
+		}
+
+		ids := make([]string, len(executions))
+		for i, execution := range executions {
+			ids[i] = execution.ID
+		}
+		var blobKeys []string
+		if s.blobs != nil {
+			if blobKeys, err = s.repo.FindBlobKeys(ctx, ids); err != nil {
+				return fmt.Errorf("failed to find stored outputs and artifacts: %w", err)
+			}
+		}
+		results, err := s.repo.DeleteExecutions(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to delete expired executions: %w", err)
+		}
+		s.deleteBlobs(ctx, blobKeys)
+		run.ExecutionsDeleted += int64(len(ids))
+		run.ResultsDeleted += results
+
+		if len(executions) < s.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+// clearOutputs clears the outputs of the results of executions completed
+// before the output retention, batch by batch
+func (s *RetentionService) clearOutputs(ctx context.Context, now time.Time, run *RetentionRun) error {
+	if s.config.OutputRetention <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-s.config.OutputRetention)
+
+	for batch := 1; ; batch++ {
+		results, err := s.repo.FindExpiredOutputs(ctx, cutoff, s.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to find expired outputs: %w", err)
+		}
+		if len(results) == 0 {
+			return nil
+		}
+
+		if s.archive != nil {
+			if err := s.store(ctx, "outputs", now, batch, s.withFullOutputs(ctx, results), run); err != nil {
+				return err
+			}
+		}
+
+		ids := make([]string, len(results))
+		var blobKeys []string
+		for i, result := range results {
+			ids[i] = result.ID
+			if result.OutputRef != "" {
+				blobKeys = append(blobKeys, result.OutputRef)
+			}
+		}
+		cleared, err := s.repo.ClearOutputs(ctx, ids)
+		if err != nil {
+			return fmt.Errorf("failed to clear expired outputs: %w", err)
+		}
+		run.OutputsCleared += cleared
+		s.deleteBlobs(ctx, blobKeys)
+
+		if len(results) < s.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+// withFullOutputs returns copies of results holding their full output instead
+// of the preview, so archives keep what the blob store is about to lose. An
+// output that cannot be loaded is archived as its preview.
+func (s *RetentionService) withFullOutputs(ctx context.Context, results []*entity.ExecutionResult) []*entity.ExecutionResult {
+	if s.blobs == nil {
+		return results
+	}
+	full := make([]*entity.ExecutionResult, len(results))
+	for i, result := range results {
+		full[i] = result
+		if result.OutputRef == "" {
+			continue
+		}
+		data, err := s.blobs.Get(ctx, result.OutputRef)
+		if err != nil {
+			s.logger.Warn("Failed to load stored output for the archive", zap.String("key", result.OutputRef), zap.Error(err))
+			continue
+		}
+		copied := *result
+		copied.Output = string(data)
+		full[i] = &copied
+	}
+	return full
+}
+
+// deleteBlobs deletes stored outputs and artifacts whose rows are gone; a
+// failure leaves an orphan object and is only logged
+func (s *RetentionService) deleteBlobs(ctx context.Context, keys []string) {
+	if s.blobs == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			s.logger.Warn("Failed to delete stored object", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// store archives records as gzip-compressed JSON, under a key naming their
+// kind, the run and the batch
+func (s *RetentionService) store(ctx context.Context, kind string, now time.Time, batch int, records any, run *RetentionRun) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(records); err != nil {
+		return fmt.Errorf("failed to encode %s archive: %w", kind, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s archive: %w", kind, err)
+	}
+
+	key := fmt.Sprintf("%s/%s-%s-%04d.json.gz", now.UTC().Format("2006/01"), kind, now.UTC().Format("20060102T150405Z"), batch)
+	if err := s.archive.Put(ctx, key, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", kind, err)
+	}
+	run.Archives = append(run.Archives, key)
+	return nil
+}
+
+// nextRetentionRun returns the next time after now the nightly job runs at hour
+func nextRetentionRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// Start starts the nightly retention job, when a retention is set
+func (s *RetentionService) Start() {
+	if !s.Enabled() {
+		return
+	}
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.runNightly()
+}
+
+// Stop stops the nightly retention job
+func (s *RetentionService) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stopChan)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *RetentionService) runNightly() {
+	defer s.wg.Done()
+
+	for {
+		next := nextRetentionRun(time.Now(), s.config.RunHour)
+		s.mu.Lock()
+		s.nextRunAt = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stopChan:
+			timer.Stop()
+			return
+		case <-timer.C:
+			if _, err := s.Run(context.Background(), time.Now()); err != nil {
+				s.logger.Warn("Failed to apply execution retention", zap.Error(err))
+			}
+		}
+	}
+}
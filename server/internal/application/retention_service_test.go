@@ -0,0 +1,268 @@
+package application
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockRetentionRepo implements repository.RetentionRepository over a mockResultRepo
+type mockRetentionRepo struct {
+	results *mockResultRepo
+}
+
+func (m *mockRetentionRepo) expired(completedBefore time.Time) []*entity.Execution {
+	var executions []*entity.Execution
+	for _, e := range m.results.executions {
+		if e.CompletedAt != nil && e.CompletedAt.Before(completedBefore) {
+			executions = append(executions, e)
+		}
+	}
+	sort.Slice(executions, func(i, j int) bool { return executions[i].CompletedAt.Before(*executions[j].CompletedAt) })
+	return executions
+}
+
+func (m *mockRetentionRepo) FindExpiredOutputs(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.ExecutionResult, error) {
+	var results []*entity.ExecutionResult
+	for _, e := range m.expired(completedBefore) {
+		for _, r := range m.results.results[e.ID] {
+			if r.Output != "" && len(results) < limit {
+				copied := *r
+				results = append(results, &copied)
+			}
+		}
+	}
+	return results, nil
+}
+
+func (m *mockRetentionRepo) ClearOutputs(ctx context.Context, resultIDs []string) (int64, error) {
+	var n int64
+	for _, results := range m.results.results {
+		for _, r := range results {
+			for _, id := range resultIDs {
+				if r.ID == id {
+					r.Output, r.OutputRef, r.OutputSize = "", "", 0
+					n++
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
+func (m *mockRetentionRepo) FindBlobKeys(ctx context.Context, executionIDs []string) ([]string, error) {
+	var keys []string
+	for _, id := range executionIDs {
+		for _, r := range m.results.results[id] {
+			if r.OutputRef != "" {
+				keys = append(keys, r.OutputRef)
+			}
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockRetentionRepo) FindExpiredExecutions(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.Execution, error) {
+	executions := m.expired(completedBefore)
+	if len(executions) > limit {
+		executions = executions[:limit]
+	}
+	return executions, nil
+}
+
+func (m *mockRetentionRepo) DeleteExecutions(ctx context.Context, executionIDs []string) (int64, error) {
+	var n int64
+	for _, id := range executionIDs {
+		n += int64(len(m.results.results[id]))
+		delete(m.results.results, id)
+		delete(m.results.executions, id)
+	}
+	return n, nil
+}
+
+// mockArchiveStore keeps archives in memory
+type mockArchiveStore struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (m *mockArchiveStore) Put(ctx context.Context, key string, data []byte) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.objects[key] = data
+	return nil
+}
+
+func (m *mockArchiveStore) Location() string { return "memory" }
+
+// newRetentionFixture returns a result repository holding an execution
+// completed days ago for each of days, with one result each
+func newRetentionFixture(now time.Time, days ...int) *mockResultRepo {
+	repo := newMockResultRepo()
+	for _, d := range days {
+		completedAt := now.AddDate(0, 0, -d)
+		id := "exec-" + completedAt.Format("20060102")
+		repo.executions[id] = &entity.Execution{ID: id, Status: entity.ExecutionCompleted, CompletedAt: &completedAt}
+		repo.results[id] = []*entity.ExecutionResult{{ID: "result-" + id, ExecutionID: id, Output: "uid=0(root)"}}
+	}
+	return repo
+}
+
+func TestRetentionService_Run(t *testing.T) {
+	now := time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)
+	results := newRetentionFixture(now, 1, 100, 800, 900)
+	archive := &mockArchiveStore{objects: make(map[string][]byte)}
+	service := NewRetentionService(&mockRetentionRepo{results: results}, results, RetentionConfig{
+		OutputRetention:    90 * 24 * time.Hour,
+		ExecutionRetention: 730 * 24 * time.Hour,
+		BatchSize:          1,
+	}, nil)
+	service.SetArchiveStore(archive)
+
+	run, err := service.Run(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if run.ExecutionsDeleted != 2 || run.ResultsDeleted != 2 {
+		t.Errorf("Expected the 2 executions past 2 years deleted, got %d (%d results)", run.ExecutionsDeleted, run.ResultsDeleted)
+	}
+	if run.OutputsCleared != 1 {
+		t.Errorf("Expected the output past 90 days cleared, got %d", run.OutputsCleared)
+	}
+	if len(results.executions) != 2 || results.results["exec-20260531"][0].Output == "" {
+		t.Error("Expected recent executions and outputs kept")
+	}
+
+	// One archive per batch, readable as gzipped JSON
+	if len(run.Archives) != 3 || len(archive.objects) != 3 {
+		t.Fatalf("Expected 3 archives, got %v", run.Archives)
+	}
+	key := "2026/06/executions-20260601T030000Z-0001.json.gz"
+	gz, err := gzip.NewReader(bytes.NewReader(archive.objects[key]))
+	if err != nil {
+		t.Fatalf("Expected archive %s, got %v", key, err)
+	}
+	var archived []archivedExecution
+	if err := json.NewDecoder(gz).Decode(&archived); err != nil {
+		t.Fatalf("Failed to decode archive: %v", err)
+	}
+	if len(archived) != 1 || archived[0].Execution.ID != "exec-"+now.AddDate(0, 0, -900).Format("20060102") || len(archived[0].Results) != 1 {
+		t.Errorf("Expected the oldest execution with its results archived, got %+v", archived)
+	}
+
+	status := service.Status()
+	if status.OutputRetentionDays != 90 || status.ExecutionRetentionDays != 730 || status.Archive != "memory" {
+		t.Errorf("Unexpected policies %+v", status)
+	}
+	if status.LastRun != run || status.Totals.Runs != 1 || status.Totals.ExecutionsDeleted != 2 || status.Totals.Archives != 3 {
+		t.Errorf("Unexpected totals %+v", status.Totals)
+	}
+}
+
+func TestRetentionService_BlobStore(t *testing.T) {
+	now := time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)
+	results := newRetentionFixture(now, 100, 800)
+	blobs := newMockBlobStore()
+	for _, id := range []string{"exec-20260221", "exec-20240323"} {
+		r := results.results[id][0]
+		r.Output, r.OutputRef = "uid=0", "outputs/"+id+"/"+r.ID
+		blobs.objects[r.OutputRef] = []byte("uid=0(root) and more")
+	}
+	archive := &mockArchiveStore{objects: make(map[string][]byte)}
+	service := NewRetentionService(&mockRetentionRepo{results: results}, results, RetentionConfig{
+		OutputRetention:    90 * 24 * time.Hour,
+		ExecutionRetention: 730 * 24 * time.Hour,
+	}, nil)
+	service.SetArchiveStore(archive)
+	service.SetBlobStore(blobs)
+
+	if _, err := service.Run(context.Background(), now); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(blobs.objects) != 0 {
+		t.Errorf("Expected the stored outputs deleted, got %v", blobs.objects)
+	}
+
+	// Archives keep the full output, not the preview
+	gz, err := gzip.NewReader(bytes.NewReader(archive.objects["2026/06/outputs-20260601T030000Z-0001.json.gz"]))
+	if err != nil {
+		t.Fatalf("Expected an outputs archive, got %v", err)
+	}
+	var archived []*entity.ExecutionResult
+	if err := json.NewDecoder(gz).Decode(&archived); err != nil {
+		t.Fatalf("Failed to decode archive: %v", err)
+	}
+	if len(archived) != 1 || archived[0].Output != "uid=0(root) and more" {
+		t.Errorf("Expected the full output archived, got %+v", archived)
+	}
+}
+
+func TestRetentionService_ArchiveFailureKeepsRows(t *testing.T) {
+	now := time.Now()
+	results := newRetentionFixture(now, 800)
+	service := NewRetentionService(&mockRetentionRepo{results: results}, results, RetentionConfig{
+		ExecutionRetention: 730 * 24 * time.Hour,
+	}, nil)
+	service.SetArchiveStore(&mockArchiveStore{err: errors.New("bucket not found")})
+
+	run, err := service.Run(context.Background(), now)
+	if err == nil || !strings.Contains(run.Error, "bucket not found") {
+		t.Fatalf("Expected the archive error, got %v", err)
+	}
+	if run.ExecutionsDeleted != 0 || len(results.executions) != 1 {
+		t.Error("Expected the execution kept when it could not be archived")
+	}
+}
+
+func TestRetentionService_Disabled(t *testing.T) {
+	now := time.Now()
+	results := newRetentionFixture(now, 5000)
+	service := NewRetentionService(&mockRetentionRepo{results: results}, results, DefaultRetentionConfig(), nil)
+
+	if service.Enabled() {
+		t.Error("Expected retention disabled by default")
+	}
+	run, err := service.Run(context.Background(), now)
+	if err != nil || run.ExecutionsDeleted != 0 || run.OutputsCleared != 0 {
+		t.Errorf("Expected nothing removed, got %+v (%v)", run, err)
+	}
+	service.Start() // No job without a retention
+	if service.Status().NextRunAt != nil {
+		t.Error("Expected no nightly run scheduled")
+	}
+	service.Stop()
+}
+
+func TestRetentionService_InProgress(t *testing.T) {
+	service := NewRetentionService(&mockRetentionRepo{results: newMockResultRepo()}, newMockResultRepo(), DefaultRetentionConfig(), nil)
+	service.inRun = true
+
+	if _, err := service.Run(context.Background(), time.Now()); !errors.Is(err, ErrRetentionInProgress) {
+		t.Errorf("Expected ErrRetentionInProgress, got %v", err)
+	}
+}
+
+func TestNextRetentionRun(t *testing.T) {
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2026, 6, 1, 1, 30, 0, 0, time.UTC), time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC), time.Date(2026, 6, 2, 3, 0, 0, 0, time.UTC)},
+		{time.Date(2026, 6, 30, 22, 0, 0, 0, time.UTC), time.Date(2026, 7, 1, 3, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := nextRetentionRun(tt.now, 3); !got.Equal(tt.want) {
+			t.Errorf("nextRetentionRun(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"autostrike/internal/domain/entity"
@@ -11,6 +13,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrScenarioTemplate is returned when modifying or deleting a built-in template
+var ErrScenarioTemplate = errors.New("built-in templates are read-only, clone them to customize")
+
 // ScenarioService handles scenario-related business logic
 type ScenarioService struct {
 	repo      repository.ScenarioRepository
@@ -49,6 +54,7 @@ func (s *ScenarioService) GetScenariosByTag(ctx context.Context, tag string) ([]
 // CreateScenario creates a new scenario
 func (s *ScenarioService) CreateScenario(ctx context.Context, scenario *entity.Scenario) error {
 	scenario.ID = uuid.New().String()
+	scenario.IsTemplate = false // Templates only come from the built-in library
 	scenario.CreatedAt = time.Now()
 	scenario.UpdatedAt = time.Now()
 
@@ -76,7 +82,16 @@ func (s *ScenarioService) UpdateScenario(ctx context.Context, scenario *entity.S
 		return err
 	}
 
-	result := s.validator.ValidateScenario(scenario, techniques)
+	// Retired techniques the scenario already used stay allowed so it remains editable
+	previous, err := s.repo.FindByID(ctx, scenario.ID)
+	if err != nil {
+		previous = nil
+	}
+	if previous != nil && previous.IsTemplate {
+		return ErrScenarioTemplate
+	}
+
+	result := s.validator.ValidateScenarioUpdate(scenario, previous, techniques)
 	if !result.IsValid {
 		return &ValidationError{Errors: result.Errors}
 	}
@@ -86,9 +101,83 @@ func (s *ScenarioService) UpdateScenario(ctx context.Context, scenario *entity.S
 
 // DeleteScenario deletes a scenario
 func (s *ScenarioService) DeleteScenario(ctx context.Context, id string) error {
+	if scenario, err := s.repo.FindByID(ctx, id); err == nil && scenario.IsTemplate {
+		return ErrScenarioTemplate
+	}
 	return s.repo.Delete(ctx, id)
 }
 
+// CloneScenario creates an editable copy of a scenario or template. The copy
+// is named name, or after the source when empty.
+func (s *ScenarioService) CloneScenario(ctx context.Context, id, name string) (*entity.Scenario, error) {
+	source, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := source.Clone()
+	clone.ID = uuid.New().String()
+	clone.Name = name
+	if clone.Name == "" {
+		clone.Name = fmt.Sprintf("%s (copy)", source.Name)
+	}
+	clone.CreatedAt = time.Now()
+	clone.UpdatedAt = clone.CreatedAt
+
+	// Techniques retired since the source was written stay allowed, as when editing it
+	techniques, err := s.techRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := s.validator.ValidateScenarioUpdate(clone, source, techniques)
+	if !result.IsValid {
+		return nil, &ValidationError{Errors: result.Errors}
+	}
+
+	if err := s.repo.Create(ctx, clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// ScenarioValidation is the outcome of a dry-run scenario validation
+type ScenarioValidation struct {
+	Valid    bool                      `json:"valid"`
+	Errors   []service.ValidationIssue `json:"errors"`
+	Warnings []service.ValidationIssue `json:"warnings"`
+}
+
+// ValidateScenarioDraft validates a scenario without saving it, against the
+// target platforms and safe mode of an execution. A scenario with an ID is
+// checked as an update of the stored scenario.
+func (s *ScenarioService) ValidateScenarioDraft(
+	ctx context.Context,
+	scenario *entity.Scenario,
+	check service.ScenarioCheck,
+) (*ScenarioValidation, error) {
+	techniques, err := s.techRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous *entity.Scenario
+	if scenario.ID != "" {
+		if previous, err = s.repo.FindByID(ctx, scenario.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := s.validator.ValidateScenarioDraft(scenario, previous, techniques, check)
+	validation := &ScenarioValidation{
+		Valid:    result.IsValid,
+		Errors:   make([]service.ValidationIssue, 0, len(result.ErrorDetails)),
+		Warnings: make([]service.ValidationIssue, 0, len(result.WarningDetails)),
+	}
+	validation.Errors = append(validation.Errors, result.ErrorDetails...)
+	validation.Warnings = append(validation.Warnings, result.WarningDetails...)
+	return validation, nil
+}
+
 // ImportScenarios imports scenarios from YAML file
 func (s *ScenarioService) ImportScenarios(ctx context.Context, path string) error {
 	return s.repo.ImportFromYAML(ctx, path)
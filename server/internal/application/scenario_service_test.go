@@ -286,6 +286,86 @@ func TestDeleteScenario(t *testing.T) {
 	}
 }
 
+func TestUpdateAndDeleteScenario_TemplateReadOnly(t *testing.T) {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["template-apt29"] = &entity.Scenario{ID: "template-apt29", Name: "APT29", IsTemplate: true}
+	svc := NewScenarioService(scenarioRepo, newMockTechniqueRepo(), service.NewTechniqueValidator())
+
+	updated := &entity.Scenario{ID: "template-apt29", Name: "Edited", Phases: []entity.Phase{{Name: "Phase 1"}}}
+	if err := svc.UpdateScenario(context.Background(), updated); !errors.Is(err, ErrScenarioTemplate) {
+		t.Errorf("Expected ErrScenarioTemplate on update, got %v", err)
+	}
+	if err := svc.DeleteScenario(context.Background(), "template-apt29"); !errors.Is(err, ErrScenarioTemplate) {
+		t.Errorf("Expected ErrScenarioTemplate on delete, got %v", err)
+	}
+	if scenarioRepo.scenarios["template-apt29"].Name != "APT29" {
+		t.Error("Template must not be modified")
+	}
+}
+
+func TestCreateScenario_CannotCreateTemplate(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082"}
+	svc := NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator())
+
+	scenario := &entity.Scenario{Name: "Mine", IsTemplate: true, Phases: []entity.Phase{{Name: "Phase 1", Techniques: []string{"T1082"}}}}
+	if err := svc.CreateScenario(context.Background(), scenario); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if scenario.IsTemplate {
+		t.Error("Created scenarios must not be templates")
+	}
+}
+
+func TestCloneScenario(t *testing.T) {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["template-apt29"] = &entity.Scenario{
+		ID:         "template-apt29",
+		Name:       "APT29",
+		IsTemplate: true,
+		Phases:     []entity.Phase{{Name: "Discovery", Techniques: []string{"T1082", "T1059"}}},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082"}
+	techRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Status: entity.TechniqueDeprecated}
+	svc := NewScenarioService(scenarioRepo, techRepo, service.NewTechniqueValidator())
+
+	clone, err := svc.CloneScenario(context.Background(), "template-apt29", "")
+	if err != nil {
+		t.Fatalf("Expected retired technique of the source to be kept, got %v", err)
+	}
+	if clone.ID == "" || clone.ID == "template-apt29" || clone.Name != "APT29 (copy)" {
+		t.Errorf("Unexpected clone: %+v", clone)
+	}
+	if clone.IsTemplate || clone.ClonedFrom != "template-apt29" {
+		t.Errorf("Expected an editable clone of the template, got %+v", clone)
+	}
+	if scenarioRepo.scenarios[clone.ID] != clone {
+		t.Error("Clone was not stored")
+	}
+
+	named, err := svc.CloneScenario(context.Background(), "template-apt29", "Team APT29")
+	if err != nil || named.Name != "Team APT29" {
+		t.Errorf("Expected clone named Team APT29, got %+v (err %v)", named, err)
+	}
+
+	clone.Phases[0].Name = "Edited"
+	if err := svc.UpdateScenario(context.Background(), clone); err != nil {
+		t.Errorf("Expected clone to be editable, got %v", err)
+	}
+	if scenarioRepo.scenarios["template-apt29"].Phases[0].Name != "Discovery" {
+		t.Error("Editing the clone changed the template")
+	}
+}
+
+func TestCloneScenario_NotFound(t *testing.T) {
+	svc := NewScenarioService(newMockScenarioRepo(), newMockTechniqueRepo(), service.NewTechniqueValidator())
+
+	if _, err := svc.CloneScenario(context.Background(), "missing", ""); err == nil {
+		t.Error("Expected error")
+	}
+}
+
 func TestValidationError_Error(t *testing.T) {
 	err := &ValidationError{Errors: []string{"error1", "error2"}}
 	if err.Error() != "error1" {
@@ -325,3 +405,149 @@ func TestImportScenarios_Error(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestUpdateScenario_KeepsRetiredTechnique(t *testing.T) {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:     "s1",
+		Name:   "Old",
+		Phases: []entity.Phase{{Name: "Phase 1", Techniques: []string{"T1059"}}},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Status: entity.TechniqueDeprecated}
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Status: entity.TechniqueBroken}
+	svc := NewScenarioService(scenarioRepo, techRepo, service.NewTechniqueValidator())
+
+	scenario := &entity.Scenario{
+		ID:     "s1",
+		Name:   "Renamed",
+		Phases: []entity.Phase{{Name: "Phase 1", Techniques: []string{"T1059"}}},
+	}
+	if err := svc.UpdateScenario(context.Background(), scenario); err != nil {
+		t.Fatalf("Expected retired technique already in use to be kept, got %v", err)
+	}
+
+	updated := &entity.Scenario{
+		ID:     "s1",
+		Name:   "Renamed",
+		Phases: []entity.Phase{{Name: "Phase 1", Techniques: []string{"T1059", "T1082"}}},
+	}
+	if err := svc.UpdateScenario(context.Background(), updated); err == nil {
+		t.Error("Expected error when adding a broken technique")
+	}
+}
+
+func TestValidateScenarioDraft(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{
+		ID:        "T1082",
+		Platforms: []string{"linux", "windows"},
+		Executors: []entity.Executor{{Type: "bash", Command: "uname -a"}},
+		IsSafe:    true,
+	}
+	svc := NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator())
+
+	scenario := &entity.Scenario{
+		Name:   "Draft",
+		Phases: []entity.Phase{{Name: "Discovery", Techniques: []string{"T1082", "T9999"}}},
+	}
+	validation, err := svc.ValidateScenarioDraft(context.Background(), scenario,
+		service.ScenarioCheck{Platforms: []string{"windows"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if validation.Valid {
+		t.Error("Expected the draft to be invalid")
+	}
+	codes := func(issues []service.ValidationIssue) []string {
+		var out []string
+		for _, issue := range issues {
+			out = append(out, issue.Code)
+		}
+		return out
+	}
+	if got := codes(validation.Errors); len(got) != 2 || got[0] != service.IssueUnknownTechnique || got[1] != service.IssueNothingToRun {
+		t.Errorf("Unexpected errors: %v", got)
+	}
+	if len(validation.Warnings) != 1 || validation.Warnings[0].Code != service.IssueNoExecutor ||
+		validation.Warnings[0].Phase != "Discovery" || validation.Warnings[0].Technique != "T1082" {
+		t.Errorf("Unexpected warnings: %+v", validation.Warnings)
+	}
+
+	if _, err := svc.ValidateScenarioDraft(context.Background(), &entity.Scenario{ID: "missing"},
+		service.ScenarioCheck{}); err == nil {
+		t.Error("Expected an error for an unknown scenario")
+	}
+}
+
+func TestGetLifecycleReport(t *testing.T) {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:   "s1",
+		Name: "Zeta",
+		Phases: []entity.Phase{
+			{Name: "Phase 1", Techniques: []string{"T1059", "T1082"}},
+			{Name: "Phase 2", Techniques: []string{"T1059"}},
+		},
+	}
+	scenarioRepo.scenarios["s2"] = &entity.Scenario{
+		ID:     "s2",
+		Name:   "Alpha",
+		Phases: []entity.Phase{{Name: "Phase 1", Techniques: []string{"T1003"}}},
+	}
+	scenarioRepo.scenarios["s3"] = &entity.Scenario{
+		ID:     "s3",
+		Name:   "Healthy",
+		Phases: []entity.Phase{{Name: "Phase 1", Techniques: []string{"T1082"}}},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Command", Status: entity.TechniqueDeprecated}
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "Discovery"}
+	techRepo.techniques["T1003"] = &entity.Technique{ID: "T1003", Name: "Dumping", Status: entity.TechniqueBroken}
+	svc := NewScenarioService(scenarioRepo, techRepo, service.NewTechniqueValidator())
+
+	report, err := svc.GetLifecycleReport(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("Expected 2 scenarios needing updates, got %d", len(report))
+	}
+	if report[0].ScenarioID != "s2" || report[1].ScenarioID != "s1" {
+		t.Errorf("Expected report sorted by scenario name, got %s then %s", report[0].ScenarioName, report[1].ScenarioName)
+	}
+	if len(report[1].Techniques) != 1 || report[1].Techniques[0].Status != entity.TechniqueDeprecated {
+		t.Errorf("Expected T1059 flagged once as deprecated, got %+v", report[1].Techniques)
+	}
+	if report[0].Techniques[0].Status != entity.TechniqueBroken {
+		t.Errorf("Expected T1003 flagged as broken, got %+v", report[0].Techniques)
+	}
+}
+
+func TestGetLifecycleReport_Errors(t *testing.T) {
+	scenarioRepo := newMockScenarioRepo()
+	techRepo := newMockTechniqueRepo()
+	techRepo.err = errors.New("db error")
+	svc := NewScenarioService(scenarioRepo, techRepo, service.NewTechniqueValidator())
+
+	if _, err := svc.GetLifecycleReport(context.Background()); err == nil {
+		t.Error("Expected error when techniques cannot be loaded")
+	}
+
+	scenarioRepo.err = errors.New("db error")
+	if _, err := svc.GetLifecycleReport(context.Background()); err == nil {
+		t.Error("Expected error when scenarios cannot be loaded")
+	}
+}
+
+func TestGetLifecycleReport_Empty(t *testing.T) {
+	svc := NewScenarioService(newMockScenarioRepo(), newMockTechniqueRepo(), service.NewTechniqueValidator())
+
+	report, err := svc.GetLifecycleReport(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if report == nil || len(report) != 0 {
+		t.Errorf("Expected empty non-nil report, got %v", report)
+	}
+}
@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// Name identifies the scheduler as an event sink
+func (s *ScheduleService) Name() string {
+	return "schedule-chains"
+}
+
+// Handle runs the chained schedules of the schedule that started a completed
+// execution. They start in the background: the bus calls sinks while the
+// execution is being completed.
+func (s *ScheduleService) Handle(ctx context.Context, event *entity.Event) error {
+	if event.Type != entity.EventExecutionCompleted || event.Execution == nil {
+		return nil
+	}
+	go s.runChainedSchedules(context.WithoutCancel(ctx), event.Execution)
+	return nil
+}
+
+// runChainedSchedules runs the active schedules depending on the schedule that
+// started an execution, when the execution meets their chain condition. Their
+// own executions trigger the schedules depending on them in turn.
+func (s *ScheduleService) runChainedSchedules(ctx context.Context, execution *entity.Execution) {
+	run, err := s.scheduleRepo.FindRunByExecutionID(ctx, execution.ID)
+	if err != nil {
+		s.logger.Error("Failed to find the schedule run of an execution", zap.String("execution_id", execution.ID), zap.Error(err))
+		return
+	}
+	if run == nil {
+		return // Not started by a schedule
+	}
+
+	dependents, err := s.scheduleRepo.FindDependents(ctx, run.ScheduleID)
+	if err != nil {
+		s.logger.Error("Failed to find chained schedules", zap.String("schedule_id", run.ScheduleID), zap.Error(err))
+		return
+	}
+	for _, schedule := range dependents {
+		if !schedule.TriggeredBy(execution) {
+			continue
+		}
+		s.logger.Info("Running chained schedule",
+			zap.String("schedule_id", schedule.ID),
+			zap.String("upstream_schedule_id", run.ScheduleID),
+			zap.String("upstream_execution_id", execution.ID),
+		)
+		s.runSchedule(ctx, schedule)
+	}
+}
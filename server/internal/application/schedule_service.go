@@ -17,14 +17,30 @@ import (
 // ErrScheduleNotFound is returned when a schedule is not found
 var ErrScheduleNotFound = errors.New("schedule not found")
 
+// schedulerInterval is how often the scheduler checks for due schedules
+const schedulerInterval = 10 * time.Second
+
+const (
+	// misfireGrace is how overdue a schedule may be at startup and still run
+	// as due; older runs were missed while the server was down
+	misfireGrace = time.Minute
+	// maxCatchUpRuns caps the missed runs a run_all_missed schedule runs at startup
+	maxCatchUpRuns = 10
+	// maxMisfiresLogged caps the missed runs logged per schedule at startup
+	maxMisfiresLogged = 100
+)
+
 // ScheduleService handles schedule-related business logic
 type ScheduleService struct {
 	scheduleRepo     repository.ScheduleRepository
 	executionService *ExecutionService
+	selectorService  *AgentSelectorService
+	events           *EventBus
 	logger           *zap.Logger
 	stopChan         chan struct{}
 	wg               sync.WaitGroup
 	running          bool
+	lastTick         time.Time
 	mu               sync.Mutex
 }
 
@@ -42,52 +58,171 @@ func NewScheduleService(
 	}
 }
 
+// SetAgentSelectorService enables schedules that target a saved agent selector
+func (s *ScheduleService) SetAgentSelectorService(selectorService *AgentSelectorService) {
+	s.selectorService = selectorService
+}
+
+// SetEventBus publishes an event when a schedule fails to start its execution
+func (s *ScheduleService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
 // CreateScheduleRequest represents the request to create a schedule
 type CreateScheduleRequest struct {
-	Name        string                   `json:"name" binding:"required"`
-	Description string                   `json:"description"`
-	ScenarioID  string                   `json:"scenario_id" binding:"required"`
-	AgentPaw    string                   `json:"agent_paw"`
-	Frequency   entity.ScheduleFrequency `json:"frequency" binding:"required"`
-	CronExpr    string                   `json:"cron_expr"`
-	SafeMode    bool                     `json:"safe_mode"`
-	StartAt     *time.Time               `json:"start_at"`
+	Name            string                   `json:"name" binding:"required"`
+	Description     string                   `json:"description"`
+	ScenarioID      string                   `json:"scenario_id" binding:"required"`
+	AgentPaw        string                   `json:"agent_paw"`
+	AgentSelectorID string                   `json:"agent_selector_id"`
+	Frequency       entity.ScheduleFrequency `json:"frequency" binding:"required"`
+	CronExpr        string                   `json:"cron_expr"`
+	Timezone        string                   `json:"timezone"`
+	MisfirePolicy   entity.MisfirePolicy     `json:"misfire_policy"`
+	DependsOn       string                   `json:"depends_on"`
+	ChainCondition  entity.ChainCondition    `json:"chain_condition"`
+	ChainThreshold  float64                  `json:"chain_threshold"`
+	Alerting        *entity.ScheduleAlerting `json:"alerting"`
+	SafeMode        bool                     `json:"safe_mode"`
+	StartAt         *time.Time               `json:"start_at"`
 }
 
 // ErrInvalidCronExpr is returned when a cron expression is invalid
 var ErrInvalidCronExpr = errors.New("invalid cron expression")
 
-// Create creates a new schedule
-func (s *ScheduleService) Create(ctx context.Context, req *CreateScheduleRequest, userID string) (*entity.Schedule, error) {
-	// Validate cron expression if frequency is cron
+// ErrInvalidTimezone is returned when a schedule timezone is not an IANA name
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// ErrInvalidMisfirePolicy is returned when a schedule misfire policy is unknown
+var ErrInvalidMisfirePolicy = errors.New("invalid misfire policy")
+
+// validateScheduleTiming validates the cron expression, timezone and misfire policy of a schedule
+func validateScheduleTiming(req *CreateScheduleRequest) error {
 	if req.Frequency == entity.FrequencyCron {
 		if req.CronExpr == "" {
-			return nil, fmt.Errorf("cron expression required for cron frequency: %w", ErrInvalidCronExpr)
+			return fmt.Errorf("cron expression required for cron frequency: %w", ErrInvalidCronExpr)
+		}
+		if err := entity.ValidateCronExpr(req.CronExpr); err != nil {
+			return fmt.Errorf("%w '%s': %v", ErrInvalidCronExpr, req.CronExpr, err)
+		}
+	}
+	if err := entity.ValidateTimezone(req.Timezone); err != nil {
+		return fmt.Errorf("%w '%s': use an IANA name such as Europe/Paris", ErrInvalidTimezone, req.Timezone)
+	}
+	if !req.MisfirePolicy.IsValid() {
+		return fmt.Errorf("%w '%s': use skip, run_once_immediately or run_all_missed", ErrInvalidMisfirePolicy, req.MisfirePolicy)
+	}
+	if req.Alerting != nil {
+		if err := req.Alerting.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidScheduleAlerting, err)
 		}
-		if entity.ValidateCronExpr(req.CronExpr) != nil {
-			return nil, fmt.Errorf("invalid cron expression '%s': %w", req.CronExpr, ErrInvalidCronExpr)
+	}
+	return nil
+}
+
+// ErrInvalidScheduleAlerting is returned when the incident alerting settings of a schedule are invalid
+var ErrInvalidScheduleAlerting = errors.New("invalid schedule alerting")
+
+// ErrInvalidScheduleChain is returned when a chained schedule has no valid upstream schedule or condition
+var ErrInvalidScheduleChain = errors.New("invalid schedule chain")
+
+// ErrScheduleCycle is returned when a schedule would depend on itself through its upstream schedules
+var ErrScheduleCycle = errors.New("schedule dependency cycle")
+
+// validateScheduleChain validates the upstream schedule and condition of a chained schedule
+func validateScheduleChain(req *CreateScheduleRequest) error {
+	if req.Frequency == entity.FrequencyChained && req.DependsOn == "" {
+		return fmt.Errorf("%w: chained schedules need depends_on", ErrInvalidScheduleChain)
+	}
+	if req.Frequency != entity.FrequencyChained && req.DependsOn != "" {
+		return fmt.Errorf("%w: depends_on requires the chained frequency", ErrInvalidScheduleChain)
+	}
+	if !req.ChainCondition.IsValid() {
+		return fmt.Errorf("%w: unknown condition '%s', use success, score_below or score_at_least", ErrInvalidScheduleChain, req.ChainCondition)
+	}
+	return nil
+}
+
+// checkDependency verifies that the upstream schedule of a chained schedule
+// exists and that following the upstream schedules never leads back to it
+func (s *ScheduleService) checkDependency(ctx context.Context, id, dependsOn string) error {
+	if dependsOn == "" {
+		return nil
+	}
+	if dependsOn == id {
+		return fmt.Errorf("%w: a schedule cannot depend on itself", ErrScheduleCycle)
+	}
+
+	seen := map[string]bool{id: true}
+	for current := dependsOn; current != ""; {
+		if seen[current] {
+			return fmt.Errorf("%w: schedule %s already depends on this schedule", ErrScheduleCycle, dependsOn)
+		}
+		seen[current] = true
+
+		upstream, err := s.scheduleRepo.FindByID(ctx, current)
+		if err != nil || upstream == nil {
+			if current == dependsOn {
+				return fmt.Errorf("%w: upstream schedule %s not found", ErrInvalidScheduleChain, dependsOn)
+			}
+			// A deleted schedule further up ends the chain
+			return nil
+		}
+		current = upstream.DependsOn
+	}
+	return nil
+}
+
+// withUpcomingRuns sets the upcoming run times of schedules returned by the API
+func withUpcomingRuns(schedules ...*entity.Schedule) {
+	for _, schedule := range schedules {
+		if schedule != nil {
+			schedule.NextRuns = schedule.UpcomingRuns(entity.UpcomingRunCount)
 		}
 	}
+}
+
+// Create creates a new schedule
+func (s *ScheduleService) Create(ctx context.Context, req *CreateScheduleRequest, userID string) (*entity.Schedule, error) {
+	if err := validateScheduleTiming(req); err != nil {
+		return nil, err
+	}
+	if err := validateScheduleChain(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkAgentSelector(ctx, req.AgentSelectorID); err != nil {
+		return nil, err
+	}
+	if err := s.checkDependency(ctx, "", req.DependsOn); err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 
 	schedule := &entity.Schedule{
-		ID:          uuid.New().String(),
-		Name:        req.Name,
-		Description: req.Description,
-		ScenarioID:  req.ScenarioID,
-		AgentPaw:    req.AgentPaw,
-		Frequency:   req.Frequency,
-		CronExpr:    req.CronExpr,
-		SafeMode:    req.SafeMode,
-		Status:      entity.ScheduleStatusActive,
-		CreatedBy:   userID,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	// Calculate next run time
-	if req.StartAt != nil && req.StartAt.After(now) {
+		ID:              uuid.New().String(),
+		Name:            req.Name,
+		Description:     req.Description,
+		ScenarioID:      req.ScenarioID,
+		AgentPaw:        req.AgentPaw,
+		AgentSelectorID: req.AgentSelectorID,
+		Frequency:       req.Frequency,
+		CronExpr:        req.CronExpr,
+		Timezone:        req.Timezone,
+		MisfirePolicy:   req.MisfirePolicy,
+		DependsOn:       req.DependsOn,
+		ChainCondition:  req.ChainCondition,
+		ChainThreshold:  req.ChainThreshold,
+		Alerting:        req.Alerting,
+		SafeMode:        req.SafeMode,
+		Status:          entity.ScheduleStatusActive,
+		CreatedBy:       userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	// Calculate next run time; chained schedules only run after their upstream schedule
+	if req.StartAt != nil && req.StartAt.After(now) && req.Frequency != entity.FrequencyChained {
 		schedule.NextRunAt = req.StartAt
 	} else {
 		nextRun := schedule.CalculateNextRun(now)
@@ -104,19 +239,23 @@ func (s *ScheduleService) Create(ctx context.Context, req *CreateScheduleRequest
 		zap.String("frequency", string(schedule.Frequency)),
 	)
 
+	withUpcomingRuns(schedule)
 	return schedule, nil
 }
 
 // Update updates an existing schedule
 func (s *ScheduleService) Update(ctx context.Context, id string, req *CreateScheduleRequest) (*entity.Schedule, error) {
-	// Validate cron expression if frequency is cron
-	if req.Frequency == entity.FrequencyCron {
-		if req.CronExpr == "" {
-			return nil, fmt.Errorf("cron expression required for cron frequency: %w", ErrInvalidCronExpr)
-		}
-		if entity.ValidateCronExpr(req.CronExpr) != nil {
-			return nil, fmt.Errorf("invalid cron expression '%s': %w", req.CronExpr, ErrInvalidCronExpr)
-		}
+	if err := validateScheduleTiming(req); err != nil {
+		return nil, err
+	}
+	if err := validateScheduleChain(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkAgentSelector(ctx, req.AgentSelectorID); err != nil {
+		return nil, err
+	}
+	if err := s.checkDependency(ctx, id, req.DependsOn); err != nil {
+		return nil, err
 	}
 
 	schedule, err := s.scheduleRepo.FindByID(ctx, id)
@@ -131,13 +270,20 @@ func (s *ScheduleService) Update(ctx context.Context, id string, req *CreateSche
 	schedule.Description = req.Description
 	schedule.ScenarioID = req.ScenarioID
 	schedule.AgentPaw = req.AgentPaw
+	schedule.AgentSelectorID = req.AgentSelectorID
 	schedule.Frequency = req.Frequency
 	schedule.CronExpr = req.CronExpr
+	schedule.Timezone = req.Timezone
+	schedule.MisfirePolicy = req.MisfirePolicy
+	schedule.DependsOn = req.DependsOn
+	schedule.ChainCondition = req.ChainCondition
+	schedule.ChainThreshold = req.ChainThreshold
+	schedule.Alerting = req.Alerting
 	schedule.SafeMode = req.SafeMode
 	schedule.UpdatedAt = time.Now()
 
 	// Recalculate next run time if frequency changed
-	if req.StartAt != nil && req.StartAt.After(time.Now()) {
+	if req.StartAt != nil && req.StartAt.After(time.Now()) && req.Frequency != entity.FrequencyChained {
 		schedule.NextRunAt = req.StartAt
 	} else {
 		nextRun := schedule.CalculateNextRun(time.Now())
@@ -148,6 +294,7 @@ func (s *ScheduleService) Update(ctx context.Context, id string, req *CreateSche
 		return nil, fmt.Errorf("failed to update schedule: %w", err)
 	}
 
+	withUpcomingRuns(schedule)
 	return schedule, nil
 }
 
@@ -156,14 +303,24 @@ func (s *ScheduleService) Delete(ctx context.Context, id string) error {
 	return s.scheduleRepo.Delete(ctx, id)
 }
 
-// GetByID retrieves a schedule by ID
+// GetByID retrieves a schedule by ID, with its upcoming runs
 func (s *ScheduleService) GetByID(ctx context.Context, id string) (*entity.Schedule, error) {
-	return s.scheduleRepo.FindByID(ctx, id)
+	schedule, err := s.scheduleRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	withUpcomingRuns(schedule)
+	return schedule, nil
 }
 
-// GetAll retrieves all schedules
+// GetAll retrieves all schedules, with their upcoming runs
 func (s *ScheduleService) GetAll(ctx context.Context) ([]*entity.Schedule, error) {
-	return s.scheduleRepo.FindAll(ctx)
+	schedules, err := s.scheduleRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	withUpcomingRuns(schedules...)
+	return schedules, nil
 }
 
 // GetByStatus retrieves schedules by status
@@ -191,6 +348,25 @@ func (s *ScheduleService) Pause(ctx context.Context, id string) (*entity.Schedul
 	return schedule, nil
 }
 
+// PauseAll pauses every active schedule and returns their IDs
+func (s *ScheduleService) PauseAll(ctx context.Context) ([]string, error) {
+	schedules, err := s.scheduleRepo.FindByStatus(ctx, entity.ScheduleStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	var paused []string
+	for _, schedule := range schedules {
+		schedule.Status = entity.ScheduleStatusPaused
+		schedule.UpdatedAt = time.Now()
+		if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+			return paused, fmt.Errorf("failed to pause schedule %s: %w", schedule.ID, err)
+		}
+		paused = append(paused, schedule.ID)
+	}
+	return paused, nil
+}
+
 // Resume resumes a paused schedule
 func (s *ScheduleService) Resume(ctx context.Context, id string) (*entity.Schedule, error) {
 	schedule, err := s.scheduleRepo.FindByID(ctx, id)
@@ -231,6 +407,7 @@ func (s *ScheduleService) Start() {
 		return
 	}
 	s.running = true
+	s.lastTick = time.Now()
 	s.stopChan = make(chan struct{})
 	s.mu.Unlock()
 
@@ -259,7 +436,11 @@ func (s *ScheduleService) Stop() {
 func (s *ScheduleService) runScheduler() {
 	defer s.wg.Done()
 
-	ticker := time.NewTicker(10 * time.Second) // Check every 10 seconds for better precision
+	// Schedules that fell due while the server was down follow their misfire
+	// policy before the first pass runs them as due
+	s.handleMisfires(time.Now())
+
+	ticker := time.NewTicker(schedulerInterval) // Check every 10 seconds for better precision
 	defer ticker.Stop()
 
 	for {
@@ -268,10 +449,28 @@ func (s *ScheduleService) runScheduler() {
 			return
 		case <-ticker.C:
 			s.checkAndRunDueSchedules()
+			s.mu.Lock()
+			s.lastTick = time.Now()
+			s.mu.Unlock()
 		}
 	}
 }
 
+// CheckHealth reports an error when the scheduler is stopped or its loop has
+// not completed a pass for three intervals (e.g. stuck on a due schedule)
+func (s *ScheduleService) CheckHealth(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return errors.New("scheduler is not running")
+	}
+	if since := time.Since(s.lastTick); since > 3*schedulerInterval {
+		return fmt.Errorf("scheduler loop stalled for %s", since.Round(time.Second))
+	}
+	return nil
+}
+
 // checkAndRunDueSchedules checks for due schedules and runs them
 func (s *ScheduleService) checkAndRunDueSchedules() {
 	ctx := context.Background()
@@ -288,6 +487,89 @@ func (s *ScheduleService) checkAndRunDueSchedules() {
 	}
 }
 
+// handleMisfires applies the misfire policy of the active schedules whose run
+// is more than misfireGrace overdue, logging each missed run as a schedule run
+func (s *ScheduleService) handleMisfires(now time.Time) {
+	ctx := context.Background()
+
+	schedules, err := s.scheduleRepo.FindActiveSchedulesDue(ctx, now.Add(-misfireGrace))
+	if err != nil {
+		s.logger.Error("Failed to find missed schedules", zap.Error(err))
+		return
+	}
+
+	for _, schedule := range schedules {
+		s.handleMisfire(ctx, schedule, now)
+	}
+}
+
+// handleMisfire logs the missed runs of a schedule, then skips them or runs
+// the schedule once or once per missed run
+func (s *ScheduleService) handleMisfire(ctx context.Context, schedule *entity.Schedule, now time.Time) {
+	missed := schedule.MissedRuns(now, maxMisfiresLogged)
+	policy := schedule.Misfire()
+
+	catchUp := 0
+	switch policy {
+	case entity.MisfireRunOnceImmediately:
+		catchUp = 1
+	case entity.MisfireRunAllMissed:
+		catchUp = min(len(missed), maxCatchUpRuns)
+	}
+
+	s.logger.Warn("Schedule missed runs while the server was down",
+		zap.String("schedule_id", schedule.ID),
+		zap.String("name", schedule.Name),
+		zap.Int("missed", len(missed)),
+		zap.String("misfire_policy", string(policy)),
+	)
+
+	for i, missedAt := range missed {
+		var action string
+		switch {
+		case policy == entity.MisfireRunOnceImmediately:
+			action = "caught up by a single run at startup"
+		case policy == entity.MisfireRunAllMissed && i >= len(missed)-catchUp:
+			action = "run at startup"
+		case policy == entity.MisfireRunAllMissed:
+			action = fmt.Sprintf("skipped, only the last %d missed runs are run", maxCatchUpRuns)
+		default:
+			action = "skipped"
+		}
+		completedAt := now
+		run := &entity.ScheduleRun{
+			ID:          uuid.New().String(),
+			ScheduleID:  schedule.ID,
+			StartedAt:   missedAt,
+			CompletedAt: &completedAt,
+			Status:      "missed",
+			Error:       "missed while the server was down, " + action,
+		}
+		if err := s.scheduleRepo.CreateRun(ctx, run); err != nil {
+			s.logger.Error("Failed to save missed schedule run", zap.Error(err))
+		}
+	}
+
+	if catchUp == 0 {
+		// A skipped one-time schedule has nothing left to run
+		if schedule.Frequency == entity.FrequencyOnce {
+			schedule.Status = entity.ScheduleStatusDisabled
+			schedule.NextRunAt = nil
+		} else {
+			schedule.NextRunAt = schedule.CalculateNextRun(now)
+		}
+		schedule.UpdatedAt = now
+		if err := s.scheduleRepo.Update(ctx, schedule); err != nil {
+			s.logger.Error("Failed to update schedule after skipping missed runs", zap.Error(err))
+		}
+		return
+	}
+
+	for i := 0; i < catchUp && schedule.Status == entity.ScheduleStatusActive; i++ {
+		s.runSchedule(ctx, schedule)
+	}
+}
+
 // runSchedule executes a single schedule
 func (s *ScheduleService) runSchedule(ctx context.Context, schedule *entity.Schedule) {
 	s.logger.Info("Running scheduled execution",
@@ -304,14 +586,8 @@ func (s *ScheduleService) runSchedule(ctx context.Context, schedule *entity.Sche
 		Status:     "running",
 	}
 
-	// Build agent paws list
-	var agentPaws []string
-	if schedule.AgentPaw != "" {
-		agentPaws = []string{schedule.AgentPaw}
-	}
-
 	// Start the execution
-	result, err := s.executionService.StartExecution(ctx, schedule.ScenarioID, agentPaws, schedule.SafeMode)
+	result, err := s.startScheduledExecution(ctx, schedule)
 	if err != nil {
 		s.logger.Error("Failed to start scheduled execution",
 			zap.String("schedule_id", schedule.ID),
@@ -321,6 +597,7 @@ func (s *ScheduleService) runSchedule(ctx context.Context, schedule *entity.Sche
 		run.Error = err.Error()
 		completedAt := time.Now()
 		run.CompletedAt = &completedAt
+		s.events.Publish(ctx, &entity.Event{Type: entity.EventScheduleFailed, Schedule: schedule, Error: err.Error()})
 	} else {
 		run.ExecutionID = result.Execution.ID
 		run.Status = "started"
@@ -350,6 +627,33 @@ func (s *ScheduleService) runSchedule(ctx context.Context, schedule *entity.Sche
 	}
 }
 
+// startScheduledExecution resolves the schedule targets and starts its execution.
+// Selectors are resolved at run time so edits apply to every schedule using them.
+func (s *ScheduleService) startScheduledExecution(ctx context.Context, schedule *entity.Schedule) (*ExecutionWithTasks, error) {
+	var agentPaws []string
+	switch {
+	case schedule.AgentSelectorID != "" && s.selectorService != nil:
+		paws, err := s.selectorService.ResolveAgentPaws(ctx, schedule.AgentSelectorID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve agent selector: %w", err)
+		}
+		agentPaws = paws
+	case schedule.AgentPaw != "":
+		agentPaws = []string{schedule.AgentPaw}
+	}
+
+	return s.executionService.StartExecution(ctx, schedule.ScenarioID, agentPaws, schedule.SafeMode)
+}
+
+// checkAgentSelector verifies that a referenced agent selector exists
+func (s *ScheduleService) checkAgentSelector(ctx context.Context, selectorID string) error {
+	if selectorID == "" || s.selectorService == nil {
+		return nil
+	}
+	_, err := s.selectorService.Get(ctx, selectorID)
+	return err
+}
+
 // RunNow manually triggers a schedule to run immediately
 func (s *ScheduleService) RunNow(ctx context.Context, id string) (*entity.ScheduleRun, error) {
 	schedule, err := s.scheduleRepo.FindByID(ctx, id)
@@ -368,19 +672,14 @@ func (s *ScheduleService) RunNow(ctx context.Context, id string) (*entity.Schedu
 		Status:     "running",
 	}
 
-	// Build agent paws list
-	var agentPaws []string
-	if schedule.AgentPaw != "" {
-		agentPaws = []string{schedule.AgentPaw}
-	}
-
 	// Start the execution
-	result, err := s.executionService.StartExecution(ctx, schedule.ScenarioID, agentPaws, schedule.SafeMode)
+	result, err := s.startScheduledExecution(ctx, schedule)
 	if err != nil {
 		run.Status = "failed"
 		run.Error = err.Error()
 		completedAt := time.Now()
 		run.CompletedAt = &completedAt
+		s.events.Publish(ctx, &entity.Event{Type: entity.EventScheduleFailed, Schedule: schedule, Error: err.Error()})
 	} else {
 		run.ExecutionID = result.Execution.ID
 		run.Status = "started"
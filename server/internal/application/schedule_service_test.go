@@ -178,6 +178,21 @@ func (m *mockScheduleRepo) FindByScenarioID(ctx context.Context, scenarioID stri
 	return result, nil
 }
 
+func (m *mockScheduleRepo) FindDependents(ctx context.Context, scheduleID string) ([]*entity.Schedule, error) {
+	if m.findErr != nil {
+		return nil, m.findErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*entity.Schedule
+	for _, s := range m.schedules {
+		if s.DependsOn == scheduleID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockScheduleRepo) CreateRun(ctx context.Context, run *entity.ScheduleRun) error {
 	if m.createErr != nil {
 		return m.createErr
@@ -208,6 +223,22 @@ func (m *mockScheduleRepo) FindRunsByScheduleID(ctx context.Context, scheduleID
 	return runs, nil
 }
 
+func (m *mockScheduleRepo) FindRunByExecutionID(ctx context.Context, executionID string) (*entity.ScheduleRun, error) {
+	if m.findErr != nil {
+		return nil, m.findErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, runs := range m.runs {
+		for _, run := range runs {
+			if run.ExecutionID == executionID {
+				return run, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
 // mockExecutionServiceForSchedule implements the execution service interface for schedule tests
 type mockExecutionServiceForSchedule struct {
 	startErr  error
@@ -1845,3 +1876,240 @@ func TestScheduleService_RunNow_UpdateError(t *testing.T) {
 		t.Fatal("RunNow should return a run")
 	}
 }
+
+func TestScheduleService_CheckHealth(t *testing.T) {
+	service := NewScheduleService(newMockScheduleRepo(), nil, zap.NewNop())
+
+	if err := service.CheckHealth(context.Background()); err == nil {
+		t.Error("expected an error before the scheduler starts")
+	}
+
+	service.Start()
+	defer service.Stop()
+	if err := service.CheckHealth(context.Background()); err != nil {
+		t.Errorf("expected a healthy scheduler, got %v", err)
+	}
+
+	service.mu.Lock()
+	service.lastTick = time.Now().Add(-time.Minute)
+	service.mu.Unlock()
+	if err := service.CheckHealth(context.Background()); err == nil {
+		t.Error("expected an error for a stalled scheduler loop")
+	}
+}
+
+// newMisfireTestService returns a scheduler with a daily schedule whose next
+// run was three days ago, as if the server had been down since
+func newMisfireTestService(policy entity.MisfirePolicy) (*ScheduleService, *mockScheduleRepo, *entity.Schedule) {
+	repo := newMockScheduleRepo()
+	svc := &ScheduleService{
+		scheduleRepo:     repo,
+		executionService: buildTestExecutionService(),
+		logger:           zap.NewNop(),
+		stopChan:         make(chan struct{}),
+	}
+	nextRun := time.Now().Add(-3*24*time.Hour + time.Hour)
+	schedule := &entity.Schedule{
+		ID:            "sched-1",
+		Name:          "Nightly",
+		ScenarioID:    "scenario-1",
+		AgentPaw:      "agent-1",
+		Frequency:     entity.FrequencyDaily,
+		Timezone:      "UTC",
+		MisfirePolicy: policy,
+		Status:        entity.ScheduleStatusActive,
+		NextRunAt:     &nextRun,
+	}
+	repo.schedules[schedule.ID] = schedule
+	return svc, repo, schedule
+}
+
+// countRuns counts the runs of a schedule by status
+func countRuns(runs []*entity.ScheduleRun) map[string]int {
+	counts := make(map[string]int)
+	for _, run := range runs {
+		counts[run.Status]++
+	}
+	return counts
+}
+
+func TestScheduleService_handleMisfires_Skip(t *testing.T) {
+	svc, repo, schedule := newMisfireTestService(entity.MisfireSkip)
+	now := time.Now()
+
+	svc.handleMisfires(now)
+
+	counts := countRuns(repo.runs["sched-1"])
+	if counts["missed"] != 3 || counts["started"] != 0 {
+		t.Errorf("Expected 3 missed runs and no execution, got %v", counts)
+	}
+	if schedule.NextRunAt == nil || !schedule.NextRunAt.After(now) {
+		t.Errorf("Expected the next run in the future, got %v", schedule.NextRunAt)
+	}
+}
+
+func TestScheduleService_handleMisfires_RunOnceImmediately(t *testing.T) {
+	// The default policy
+	svc, repo, schedule := newMisfireTestService("")
+
+	svc.handleMisfires(time.Now())
+
+	counts := countRuns(repo.runs["sched-1"])
+	if counts["missed"] != 3 || counts["started"] != 1 {
+		t.Errorf("Expected 3 missed runs caught up by 1 execution, got %v", counts)
+	}
+	if schedule.LastRunID == "" {
+		t.Error("Expected the catch-up execution to be recorded on the schedule")
+	}
+}
+
+func TestScheduleService_handleMisfires_RunAllMissed(t *testing.T) {
+	svc, repo, _ := newMisfireTestService(entity.MisfireRunAllMissed)
+
+	svc.handleMisfires(time.Now())
+
+	counts := countRuns(repo.runs["sched-1"])
+	if counts["missed"] != 3 || counts["started"] != 3 {
+		t.Errorf("Expected 3 missed runs each run once, got %v", counts)
+	}
+}
+
+func TestScheduleService_handleMisfires_SkipOnce(t *testing.T) {
+	svc, repo, schedule := newMisfireTestService(entity.MisfireSkip)
+	schedule.Frequency = entity.FrequencyOnce
+
+	svc.handleMisfires(time.Now())
+
+	if len(repo.runs["sched-1"]) != 1 || schedule.Status != entity.ScheduleStatusDisabled || schedule.NextRunAt != nil {
+		t.Errorf("Expected a skipped one-time schedule to be disabled, got %s", schedule.Status)
+	}
+}
+
+func TestScheduleService_handleMisfires_WithinGrace(t *testing.T) {
+	svc, repo, schedule := newMisfireTestService(entity.MisfireSkip)
+	justDue := time.Now().Add(-10 * time.Second)
+	schedule.NextRunAt = &justDue
+
+	svc.handleMisfires(time.Now())
+
+	// A schedule due moments ago is run by the scheduler as due
+	if len(repo.runs["sched-1"]) != 0 || !schedule.NextRunAt.Equal(justDue) {
+		t.Error("Expected a schedule due within the grace period to be left for the scheduler")
+	}
+}
+
+func TestScheduleService_Create_InvalidMisfirePolicy(t *testing.T) {
+	svc := NewScheduleService(newMockScheduleRepo(), nil, zap.NewNop())
+	req := &CreateScheduleRequest{
+		Name:          "Test",
+		ScenarioID:    "scenario-1",
+		Frequency:     entity.FrequencyDaily,
+		MisfirePolicy: "later",
+	}
+
+	if _, err := svc.Create(context.Background(), req, "user-1"); !errors.Is(err, ErrInvalidMisfirePolicy) {
+		t.Errorf("Expected ErrInvalidMisfirePolicy, got %v", err)
+	}
+}
+
+func TestScheduleService_Create_Chained(t *testing.T) {
+	repo := newMockScheduleRepo()
+	svc := NewScheduleService(repo, nil, zap.NewNop())
+	repo.schedules["recon"] = &entity.Schedule{ID: "recon", Frequency: entity.FrequencyDaily, Status: entity.ScheduleStatusActive}
+	ctx := context.Background()
+
+	schedule, err := svc.Create(ctx, &CreateScheduleRequest{
+		Name:           "Lateral movement",
+		ScenarioID:     "scenario-2",
+		Frequency:      entity.FrequencyChained,
+		DependsOn:      "recon",
+		ChainCondition: entity.ChainOnScoreBelow,
+		ChainThreshold: 50,
+	}, "user-1")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if schedule.NextRunAt != nil || len(schedule.NextRuns) != 0 {
+		t.Errorf("Expected a chained schedule to have no run time, got %v", schedule.NextRunAt)
+	}
+
+	invalid := []*CreateScheduleRequest{
+		{Name: "a", ScenarioID: "s", Frequency: entity.FrequencyChained},
+		{Name: "b", ScenarioID: "s", Frequency: entity.FrequencyDaily, DependsOn: "recon"},
+		{Name: "c", ScenarioID: "s", Frequency: entity.FrequencyChained, DependsOn: "missing"},
+		{Name: "d", ScenarioID: "s", Frequency: entity.FrequencyChained, DependsOn: "recon", ChainCondition: "detected"},
+	}
+	for _, req := range invalid {
+		if _, err := svc.Create(ctx, req, "user-1"); !errors.Is(err, ErrInvalidScheduleChain) {
+			t.Errorf("%s: expected ErrInvalidScheduleChain, got %v", req.Name, err)
+		}
+	}
+}
+
+func TestScheduleService_Update_ChainCycle(t *testing.T) {
+	repo := newMockScheduleRepo()
+	svc := NewScheduleService(repo, nil, zap.NewNop())
+	repo.schedules["a"] = &entity.Schedule{ID: "a", Frequency: entity.FrequencyDaily, Status: entity.ScheduleStatusActive}
+	repo.schedules["b"] = &entity.Schedule{ID: "b", Frequency: entity.FrequencyChained, DependsOn: "a", Status: entity.ScheduleStatusActive}
+	repo.schedules["c"] = &entity.Schedule{ID: "c", Frequency: entity.FrequencyChained, DependsOn: "b", Status: entity.ScheduleStatusActive}
+	ctx := context.Background()
+
+	// a -> b -> c -> a
+	req := &CreateScheduleRequest{Name: "a", ScenarioID: "s", Frequency: entity.FrequencyChained, DependsOn: "c"}
+	if _, err := svc.Update(ctx, "a", req); !errors.Is(err, ErrScheduleCycle) {
+		t.Errorf("Expected ErrScheduleCycle, got %v", err)
+	}
+	req.DependsOn = "a"
+	if _, err := svc.Update(ctx, "a", req); !errors.Is(err, ErrScheduleCycle) {
+		t.Errorf("Expected a self dependency to be a cycle, got %v", err)
+	}
+
+	// c may depend on a directly
+	req = &CreateScheduleRequest{Name: "c", ScenarioID: "s", Frequency: entity.FrequencyChained, DependsOn: "a"}
+	if _, err := svc.Update(ctx, "c", req); err != nil {
+		t.Errorf("Expected a new upstream without cycle to be accepted, got %v", err)
+	}
+}
+
+func TestScheduleService_runChainedSchedules(t *testing.T) {
+	repo := newMockScheduleRepo()
+	svc := &ScheduleService{
+		scheduleRepo:     repo,
+		executionService: buildTestExecutionService(),
+		logger:           zap.NewNop(),
+		stopChan:         make(chan struct{}),
+	}
+	repo.schedules["recon"] = &entity.Schedule{ID: "recon", Frequency: entity.FrequencyDaily, Status: entity.ScheduleStatusActive}
+	repo.runs["recon"] = []*entity.ScheduleRun{{ID: "run-1", ScheduleID: "recon", ExecutionID: "exec-1", Status: "started"}}
+	for id, condition := range map[string]entity.ChainCondition{"undetected": entity.ChainOnScoreBelow, "detected": entity.ChainOnScoreAtLeast} {
+		repo.schedules[id] = &entity.Schedule{
+			ID:             id,
+			ScenarioID:     "scenario-1",
+			AgentPaw:       "agent-1",
+			Frequency:      entity.FrequencyChained,
+			DependsOn:      "recon",
+			ChainCondition: condition,
+			ChainThreshold: 50,
+			Status:         entity.ScheduleStatusActive,
+		}
+	}
+
+	execution := &entity.Execution{ID: "exec-1", Status: entity.ExecutionCompleted, Score: &entity.SecurityScore{Overall: 20}}
+	svc.runChainedSchedules(context.Background(), execution)
+
+	if len(repo.runs["undetected"]) != 1 || repo.runs["undetected"][0].Status != "started" {
+		t.Errorf("Expected the score_below schedule to run, got %v", repo.runs["undetected"])
+	}
+	if len(repo.runs["detected"]) != 0 {
+		t.Error("Expected the score_at_least schedule not to run")
+	}
+	if repo.schedules["undetected"].NextRunAt != nil {
+		t.Error("Expected a chained schedule to keep no run time after running")
+	}
+
+	// Executions not started by a schedule trigger nothing
+	svc.runChainedSchedules(context.Background(), &entity.Execution{ID: "manual", Status: entity.ExecutionCompleted})
+	if len(repo.runs["undetected"]) != 1 {
+		t.Error("Expected a manual execution to trigger no chained schedule")
+	}
+}
@@ -0,0 +1,383 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/domain/service"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrRecomputeInProgress is returned when a recomputation is started while another one runs
+	ErrRecomputeInProgress = errors.New("a score recomputation is already running")
+	// ErrRecomputeJobNotFound is returned when the requested recompute job does not exist
+	ErrRecomputeJobNotFound = errors.New("recompute job not found")
+	// ErrInvalidRecomputeRange is returned when the recompute period ends before it starts
+	ErrInvalidRecomputeRange = errors.New("invalid recompute range: from must be before to")
+)
+
+const (
+	defaultRecomputeBatchSize = 100
+	maxRecomputeBatchSize     = 1000
+	// maxRecordedScoreChanges bounds the comparison kept on a job; counters stay exact
+	maxRecordedScoreChanges = 500
+)
+
+// RecomputeStatus is the state of a score recompute job
+type RecomputeStatus string
+
+const (
+	RecomputeRunning   RecomputeStatus = "running"
+	RecomputeCompleted RecomputeStatus = "completed"
+	RecomputeFailed    RecomputeStatus = "failed"
+)
+
+// RecomputeRequest selects the completed executions whose score is recomputed
+type RecomputeRequest struct {
+	From      time.Time // Zero means since the first execution
+	To        time.Time // Zero means now
+	BatchSize int       // Executions processed between progress reports
+	DryRun    bool      // Report the changes without writing them
+}
+
+// ScoreChange compares the score of an execution before and after recomputation
+type ScoreChange struct {
+	ExecutionID     string  `json:"execution_id"`
+	OriginalOverall float64 `json:"original_overall"` // Score before any recomputation
+	PreviousOverall float64 `json:"previous_overall"` // Score before this recomputation
+	NewOverall      float64 `json:"new_overall"`
+}
+
+// RecomputeJob tracks the progress of a score recomputation backfill
+type RecomputeJob struct {
+	ID          string          `json:"id"`
+	Status      RecomputeStatus `json:"status"`
+	DryRun      bool            `json:"dry_run"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	BatchSize   int             `json:"batch_size"`
+	Total       int             `json:"total"`     // Executions in the period
+	Processed   int             `json:"processed"` // Executions recomputed so far
+	Changed     int             `json:"changed"`   // Executions whose score changed
+	Failed      int             `json:"failed"`    // Executions that could not be recomputed
+	Changes     []ScoreChange   `json:"changes"`
+	Error       string          `json:"error,omitempty"`
+	StartedAt   time.Time       `json:"started_at"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// ScoreHistory is the original and current score of an execution with every recomputation in between
+type ScoreHistory struct {
+	ExecutionID    string                       `json:"execution_id"`
+	OriginalScore  *entity.SecurityScore        `json:"original_score"`
+	CurrentScore   *entity.SecurityScore        `json:"current_score"`
+	Recomputations []*entity.ScoreRecomputation `json:"recomputations"`
+}
+
+// ScoreBackfillService recomputes the scores of historical executions after the
+// scoring model or result adjudication changed, keeping the overwritten scores
+type ScoreBackfillService struct {
+	resultRepo  repository.ResultRepository
+	historyRepo repository.ScoreHistoryRepository
+	calculator  *service.ScoreCalculator
+	logger      *zap.Logger
+
+	scoringProfiles *ScoringProfileService
+
+	mu      sync.Mutex
+	jobs    map[string]*RecomputeJob
+	running string // ID of the running job, empty when idle
+}
+
+// NewScoreBackfillService creates a new score backfill service
+func NewScoreBackfillService(
+	resultRepo repository.ResultRepository,
+	historyRepo repository.ScoreHistoryRepository,
+	calculator *service.ScoreCalculator,
+	logger *zap.Logger,
+) *ScoreBackfillService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ScoreBackfillService{
+		resultRepo:  resultRepo,
+		historyRepo: historyRepo,
+		calculator:  calculator,
+		logger:      logger,
+		jobs:        make(map[string]*RecomputeJob),
+	}
+}
+
+// SetScoringProfileService recomputes executions with the scoring profile they are pinned to
+func (s *ScoreBackfillService) SetScoringProfileService(profiles *ScoringProfileService) {
+	s.scoringProfiles = profiles
+}
+
+// StartRecompute launches a recomputation in the background and returns its job.
+// Only one recomputation runs at a time.
+func (s *ScoreBackfillService) StartRecompute(req RecomputeRequest) (*RecomputeJob, error) {
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if !req.From.IsZero() && req.From.After(req.To) {
+		return nil, ErrInvalidRecomputeRange
+	}
+	if req.BatchSize <= 0 {
+		req.BatchSize = defaultRecomputeBatchSize
+	}
+	if req.BatchSize > maxRecomputeBatchSize {
+		req.BatchSize = maxRecomputeBatchSize
+	}
+
+	s.mu.Lock()
+	if s.running != "" {
+		s.mu.Unlock()
+		return nil, ErrRecomputeInProgress
+	}
+	job := &RecomputeJob{
+		ID:        uuid.New().String(),
+		Status:    RecomputeRunning,
+		DryRun:    req.DryRun,
+		From:      req.From,
+		To:        req.To,
+		BatchSize: req.BatchSize,
+		Changes:   []ScoreChange{},
+		StartedAt: time.Now(),
+	}
+	s.jobs[job.ID] = job
+	s.running = job.ID
+	snapshot := job.snapshot()
+	s.mu.Unlock()
+
+	// The job outlives the request that started it
+	go s.run(context.Background(), job)
+
+	return snapshot, nil
+}
+
+// GetJob returns the current state of a recompute job
+func (s *ScoreBackfillService) GetJob(id string) (*RecomputeJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrRecomputeJobNotFound
+	}
+	return job.snapshot(), nil
+}
+
+// ListJobs returns the recompute jobs started since the server booted, newest first
+func (s *ScoreBackfillService) ListJobs() []*RecomputeJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*RecomputeJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.After(jobs[j].StartedAt) })
+	return jobs
+}
+
+// GetScoreHistory returns the original and current score of an execution
+func (s *ScoreBackfillService) GetScoreHistory(ctx context.Context, executionID string) (*ScoreHistory, error) {
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+
+	recomputations, err := s.historyRepo.FindByExecution(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load score history: %w", err)
+	}
+	if recomputations == nil {
+		recomputations = []*entity.ScoreRecomputation{}
+	}
+
+	history := &ScoreHistory{
+		ExecutionID:    executionID,
+		OriginalScore:  execution.Score,
+		CurrentScore:   execution.Score,
+		Recomputations: recomputations,
+	}
+	if len(recomputations) > 0 {
+		original := recomputations[0].PreviousScore
+		history.OriginalScore = &original
+	}
+	return history, nil
+}
+
+// run processes the executions of a job in batches, reporting progress after each one
+func (s *ScoreBackfillService) run(ctx context.Context, job *RecomputeJob) {
+	err := s.recompute(ctx, job)
+
+	s.mu.Lock()
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Status = RecomputeCompleted
+	if err != nil {
+		job.Status = RecomputeFailed
+		job.Error = err.Error()
+	}
+	s.running = ""
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("Score recomputation failed", zap.String("job_id", job.ID), zap.Error(err))
+		return
+	}
+	s.logger.Info("Score recomputation completed",
+		zap.String("job_id", job.ID),
+		zap.Bool("dry_run", job.DryRun),
+		zap.Int("processed", job.Processed),
+		zap.Int("changed", job.Changed),
+		zap.Int("failed", job.Failed),
+	)
+}
+
+func (s *ScoreBackfillService) recompute(ctx context.Context, job *RecomputeJob) error {
+	executions, err := s.resultRepo.FindCompletedExecutionsByDateRange(ctx, job.From, job.To)
+	if err != nil {
+		return fmt.Errorf("failed to load executions: %w", err)
+	}
+	// Oldest first so progress follows the timeline
+	sort.Slice(executions, func(i, j int) bool { return executions[i].StartedAt.Before(executions[j].StartedAt) })
+
+	s.mu.Lock()
+	job.Total = len(executions)
+	s.mu.Unlock()
+
+	for start := 0; start < len(executions); start += job.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := min(start+job.BatchSize, len(executions))
+		for _, execution := range executions[start:end] {
+			change, err := s.recomputeExecution(ctx, job, execution)
+
+			s.mu.Lock()
+			job.Processed++
+			switch {
+			case err != nil:
+				job.Failed++
+			case change != nil:
+				job.Changed++
+				if len(job.Changes) < maxRecordedScoreChanges {
+					job.Changes = append(job.Changes, *change)
+				}
+			}
+			s.mu.Unlock()
+
+			if err != nil {
+				s.logger.Warn("Failed to recompute execution score",
+					zap.String("job_id", job.ID), zap.String("execution_id", execution.ID), zap.Error(err))
+			}
+		}
+
+		s.logger.Info("Score recomputation progress",
+			zap.String("job_id", job.ID),
+			zap.Int("processed", end),
+			zap.Int("total", len(executions)),
+		)
+	}
+
+	return nil
+}
+
+// recomputeExecution recalculates one execution's score, returning nil when it is unchanged.
+// The overwritten score is recorded before the execution is updated.
+func (s *ScoreBackfillService) recomputeExecution(
+	ctx context.Context,
+	job *RecomputeJob,
+	execution *entity.Execution,
+) (*ScoreChange, error) {
+	results, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results: %w", err)
+	}
+
+	previous := entity.SecurityScore{}
+	if execution.Score != nil {
+		previous = *execution.Score
+	}
+	score, err := scoreExecution(ctx, s.calculator, s.scoringProfiles, execution, results)
+	if err != nil {
+		return nil, err
+	}
+	if sameScore(previous, *score) {
+		return nil, nil
+	}
+
+	history, err := s.historyRepo.FindByExecution(ctx, execution.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load score history: %w", err)
+	}
+	change := &ScoreChange{
+		ExecutionID:     execution.ID,
+		OriginalOverall: previous.Overall,
+		PreviousOverall: previous.Overall,
+		NewOverall:      score.Overall,
+	}
+	if len(history) > 0 {
+		change.OriginalOverall = history[0].PreviousScore.Overall
+	}
+
+	if job.DryRun {
+		return change, nil
+	}
+
+	err = s.historyRepo.Create(ctx, &entity.ScoreRecomputation{
+		ID:            uuid.New().String(),
+		ExecutionID:   execution.ID,
+		JobID:         job.ID,
+		PreviousScore: previous,
+		NewScore:      *score,
+		RecomputedAt:  time.Now(),
+
+		PreviousScoringProfileID:      execution.ScoringProfileID,
+		PreviousScoringProfileVersion: execution.ScoringProfileVersion,
+		ScoringProfileID:              execution.ScoringProfileID,
+		ScoringProfileVersion:         execution.ScoringProfileVersion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to preserve previous score: %w", err)
+	}
+
+	execution.Score = score
+	if err := s.resultRepo.UpdateExecution(ctx, execution); err != nil {
+		return nil, fmt.Errorf("failed to update execution: %w", err)
+	}
+	return change, nil
+}
+
+// sameScore compares the persisted parts of two scores
+func sameScore(a, b entity.SecurityScore) bool {
+	return math.Abs(a.Overall-b.Overall) < 1e-9 &&
+		a.Blocked == b.Blocked &&
+		a.Detected == b.Detected &&
+		a.Successful == b.Successful &&
+		a.Total == b.Total
+}
+
+// snapshot copies the job so callers can read it without holding the lock
+func (j *RecomputeJob) snapshot() *RecomputeJob {
+	clone := *j
+	clone.Changes = append([]ScoreChange{}, j.Changes...)
+	if j.CompletedAt != nil {
+		completedAt := *j.CompletedAt
+		clone.CompletedAt = &completedAt
+	}
+	return &clone
+}
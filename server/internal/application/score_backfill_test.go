@@ -0,0 +1,293 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+type mockScoreHistoryRepo struct {
+	mu             sync.Mutex
+	recomputations []*entity.ScoreRecomputation
+	createErr      error
+	findErr        error
+}
+
+func (m *mockScoreHistoryRepo) Create(ctx context.Context, rec *entity.ScoreRecomputation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.createErr != nil {
+		return m.createErr
+	}
+	m.recomputations = append(m.recomputations, rec)
+	return nil
+}
+
+func (m *mockScoreHistoryRepo) FindByExecution(ctx context.Context, executionID string) ([]*entity.ScoreRecomputation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.findErr != nil {
+		return nil, m.findErr
+	}
+	var result []*entity.ScoreRecomputation
+	for _, rec := range m.recomputations {
+		if rec.ExecutionID == executionID {
+			result = append(result, rec)
+		}
+	}
+	return result, nil
+}
+
+// newBackfillFixture returns a result repo with two completed executions: exec-stale
+// scored 0 although one technique was blocked, and exec-fresh already up to date
+func newBackfillFixture() *mockResultRepo {
+	repo := newMockResultRepo()
+	now := time.Now()
+
+	repo.executions["exec-stale"] = &entity.Execution{
+		ID:        "exec-stale",
+		Status:    entity.ExecutionCompleted,
+		StartedAt: now.Add(-2 * time.Hour),
+		Score:     &entity.SecurityScore{Overall: 0, Successful: 1, Total: 2},
+	}
+	repo.results["exec-stale"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-stale", Status: entity.StatusBlocked},
+		{ID: "r2", ExecutionID: "exec-stale", Status: entity.StatusSuccess},
+	}
+
+	repo.executions["exec-fresh"] = &entity.Execution{
+		ID:        "exec-fresh",
+		Status:    entity.ExecutionCompleted,
+		StartedAt: now.Add(-time.Hour),
+		Score:     &entity.SecurityScore{Overall: 50, Detected: 1, Total: 1},
+	}
+	repo.results["exec-fresh"] = []*entity.ExecutionResult{
+		{ID: "r3", ExecutionID: "exec-fresh", Status: entity.StatusDetected},
+	}
+
+	return repo
+}
+
+func waitForRecompute(t *testing.T, svc *ScoreBackfillService, id string) *RecomputeJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		if job.Status != RecomputeRunning {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("recompute job did not finish in time")
+	return nil
+}
+
+func TestScoreBackfill_RecomputesChangedScores(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	historyRepo := &mockScoreHistoryRepo{}
+	svc := NewScoreBackfillService(resultRepo, historyRepo, service.NewScoreCalculator(), nil)
+
+	started, err := svc.StartRecompute(RecomputeRequest{BatchSize: 1})
+	if err != nil {
+		t.Fatalf("StartRecompute failed: %v", err)
+	}
+	if started.Status != RecomputeRunning || started.BatchSize != 1 {
+		t.Errorf("Unexpected started job: %+v", started)
+	}
+
+	job := waitForRecompute(t, svc, started.ID)
+	if job.Status != RecomputeCompleted {
+		t.Fatalf("Expected completed job, got %s (%s)", job.Status, job.Error)
+	}
+	if job.Total != 2 || job.Processed != 2 || job.Changed != 1 || job.Failed != 0 {
+		t.Errorf("Unexpected progress: total=%d processed=%d changed=%d failed=%d",
+			job.Total, job.Processed, job.Changed, job.Failed)
+	}
+	if job.CompletedAt == nil {
+		t.Error("Expected CompletedAt to be set")
+	}
+
+	if len(job.Changes) != 1 {
+		t.Fatalf("Expected 1 change, got %d", len(job.Changes))
+	}
+	change := job.Changes[0]
+	if change.ExecutionID != "exec-stale" || change.OriginalOverall != 0 || change.NewOverall != 50 {
+		t.Errorf("Unexpected change: %+v", change)
+	}
+
+	if got := resultRepo.executions["exec-stale"].Score; got.Overall != 50 || got.Blocked != 1 {
+		t.Errorf("Expected execution score to be updated, got %+v", got)
+	}
+	if len(historyRepo.recomputations) != 1 || historyRepo.recomputations[0].PreviousScore.Overall != 0 {
+		t.Errorf("Expected previous score to be preserved, got %+v", historyRepo.recomputations)
+	}
+	if historyRepo.recomputations[0].JobID != job.ID {
+		t.Errorf("Expected recomputation to reference job %s", job.ID)
+	}
+}
+
+func TestScoreBackfill_DryRun(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	historyRepo := &mockScoreHistoryRepo{}
+	svc := NewScoreBackfillService(resultRepo, historyRepo, service.NewScoreCalculator(), nil)
+
+	started, err := svc.StartRecompute(RecomputeRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("StartRecompute failed: %v", err)
+	}
+	job := waitForRecompute(t, svc, started.ID)
+
+	if job.Changed != 1 || len(job.Changes) != 1 {
+		t.Errorf("Expected the change to be reported, got %+v", job)
+	}
+	if resultRepo.executions["exec-stale"].Score.Overall != 0 {
+		t.Error("Dry run must not update the execution")
+	}
+	if len(historyRepo.recomputations) != 0 {
+		t.Error("Dry run must not record history")
+	}
+}
+
+func TestScoreBackfill_KeepsOriginalAcrossRecomputations(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	historyRepo := &mockScoreHistoryRepo{recomputations: []*entity.ScoreRecomputation{
+		{ID: "old", ExecutionID: "exec-stale", PreviousScore: entity.SecurityScore{Overall: 25}},
+	}}
+	svc := NewScoreBackfillService(resultRepo, historyRepo, service.NewScoreCalculator(), nil)
+
+	started, _ := svc.StartRecompute(RecomputeRequest{})
+	job := waitForRecompute(t, svc, started.ID)
+
+	if len(job.Changes) != 1 || job.Changes[0].OriginalOverall != 25 || job.Changes[0].PreviousOverall != 0 {
+		t.Errorf("Expected original score from history, got %+v", job.Changes)
+	}
+
+	history, err := svc.GetScoreHistory(context.Background(), "exec-stale")
+	if err != nil {
+		t.Fatalf("GetScoreHistory failed: %v", err)
+	}
+	if history.OriginalScore.Overall != 25 || history.CurrentScore.Overall != 50 || len(history.Recomputations) != 2 {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestScoreBackfill_CountsFailures(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	historyRepo := &mockScoreHistoryRepo{createErr: errors.New("disk full")}
+	svc := NewScoreBackfillService(resultRepo, historyRepo, service.NewScoreCalculator(), nil)
+
+	started, _ := svc.StartRecompute(RecomputeRequest{})
+	job := waitForRecompute(t, svc, started.ID)
+
+	if job.Status != RecomputeCompleted || job.Failed != 1 || job.Changed != 0 {
+		t.Errorf("Expected one failed execution, got %+v", job)
+	}
+	if resultRepo.executions["exec-stale"].Score.Overall != 0 {
+		t.Error("Score must not be overwritten when the previous one could not be preserved")
+	}
+}
+
+func TestScoreBackfill_LoadError(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	resultRepo.err = errors.New("database locked")
+	svc := NewScoreBackfillService(resultRepo, &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+
+	started, _ := svc.StartRecompute(RecomputeRequest{})
+	job := waitForRecompute(t, svc, started.ID)
+
+	if job.Status != RecomputeFailed || job.Error == "" {
+		t.Errorf("Expected failed job with error, got %+v", job)
+	}
+
+	// A failed job frees the slot for the next one
+	if _, err := svc.StartRecompute(RecomputeRequest{}); err != nil {
+		t.Errorf("Expected a new job to start, got %v", err)
+	}
+}
+
+func TestScoreBackfill_StartValidation(t *testing.T) {
+	svc := NewScoreBackfillService(newMockResultRepo(), &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+
+	now := time.Now()
+	if _, err := svc.StartRecompute(RecomputeRequest{From: now, To: now.Add(-time.Hour)}); !errors.Is(err, ErrInvalidRecomputeRange) {
+		t.Errorf("Expected ErrInvalidRecomputeRange, got %v", err)
+	}
+
+	job, err := svc.StartRecompute(RecomputeRequest{BatchSize: 5000})
+	if err != nil {
+		t.Fatalf("StartRecompute failed: %v", err)
+	}
+	if job.BatchSize != maxRecomputeBatchSize {
+		t.Errorf("Expected batch size capped at %d, got %d", maxRecomputeBatchSize, job.BatchSize)
+	}
+	waitForRecompute(t, svc, job.ID)
+}
+
+func TestScoreBackfill_SingleJobAtATime(t *testing.T) {
+	svc := NewScoreBackfillService(newMockResultRepo(), &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+
+	// Simulate a job in flight
+	svc.running = "busy"
+	if _, err := svc.StartRecompute(RecomputeRequest{}); !errors.Is(err, ErrRecomputeInProgress) {
+		t.Errorf("Expected ErrRecomputeInProgress, got %v", err)
+	}
+}
+
+func TestScoreBackfill_GetJobAndList(t *testing.T) {
+	svc := NewScoreBackfillService(newMockResultRepo(), &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+
+	if _, err := svc.GetJob("missing"); !errors.Is(err, ErrRecomputeJobNotFound) {
+		t.Errorf("Expected ErrRecomputeJobNotFound, got %v", err)
+	}
+
+	first, _ := svc.StartRecompute(RecomputeRequest{})
+	waitForRecompute(t, svc, first.ID)
+	second, _ := svc.StartRecompute(RecomputeRequest{})
+	waitForRecompute(t, svc, second.ID)
+
+	jobs := svc.ListJobs()
+	if len(jobs) != 2 {
+		t.Fatalf("Expected 2 jobs, got %d", len(jobs))
+	}
+	if jobs[0].ID != second.ID {
+		t.Error("Expected newest job first")
+	}
+}
+
+func TestScoreBackfill_GetScoreHistory_NotFound(t *testing.T) {
+	svc := NewScoreBackfillService(newMockResultRepo(), &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+
+	if _, err := svc.GetScoreHistory(context.Background(), "missing"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Expected ErrExecutionNotFound, got %v", err)
+	}
+}
+
+func TestScoreBackfill_GetScoreHistory_NoRecomputation(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	svc := NewScoreBackfillService(resultRepo, &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+
+	history, err := svc.GetScoreHistory(context.Background(), "exec-fresh")
+	if err != nil {
+		t.Fatalf("GetScoreHistory failed: %v", err)
+	}
+	if history.OriginalScore.Overall != 50 || history.Recomputations == nil || len(history.Recomputations) != 0 {
+		t.Errorf("Expected current score as original and empty history, got %+v", history)
+	}
+}
+
+func TestScoreBackfill_GetScoreHistory_Error(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	svc := NewScoreBackfillService(resultRepo, &mockScoreHistoryRepo{findErr: errors.New("boom")}, service.NewScoreCalculator(), nil)
+
+	if _, err := svc.GetScoreHistory(context.Background(), "exec-fresh"); err == nil {
+		t.Error("Expected error when history cannot be loaded")
+	}
+}
@@ -0,0 +1,272 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+// ScorePoint is the security score of a completed execution, overall and per tactic
+type ScorePoint struct {
+	ExecutionID string             `json:"execution_id"`
+	ScenarioID  string             `json:"scenario_id"`
+	StartedAt   time.Time          `json:"started_at"`
+	Overall     float64            `json:"overall"`
+	ByTactic    map[string]float64 `json:"by_tactic"`
+}
+
+// ScoreTimeline is the series of the scores of the completed executions of a
+// period, oldest first, for one scenario or all of them
+type ScoreTimeline struct {
+	ScenarioID string       `json:"scenario_id,omitempty"`
+	Days       int          `json:"days"`
+	Since      time.Time    `json:"since"`
+	Tactics    []string     `json:"tactics"` // Tactics scored in at least one point
+	Points     []ScorePoint `json:"points"`
+}
+
+// SetEventBus publishes the detection regressions found on completed executions
+func (s *AnalyticsService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// GetScoreTimeline returns the overall and per tactic scores of the executions
+// completed over the last days, of a scenario or of all of them when
+// scenarioID is empty. Tactic scores need the technique repository.
+func (s *AnalyticsService) GetScoreTimeline(ctx context.Context, scenarioID string, days int) (*ScoreTimeline, error) {
+	now := time.Now()
+	since := now.AddDate(0, 0, -days)
+	timeline := &ScoreTimeline{ScenarioID: scenarioID, Days: days, Since: since, Tactics: []string{}, Points: []ScorePoint{}}
+
+	var executions []*entity.Execution
+	if scenarioID != "" {
+		all, err := s.resultRepo.FindExecutionsByScenario(ctx, scenarioID)
+		if err != nil {
+			return nil, err
+		}
+		for _, execution := range all {
+			if execution.Status == entity.ExecutionCompleted && !execution.StartedAt.Before(since) {
+				executions = append(executions, execution)
+			}
+		}
+	} else {
+		completed, err := s.resultRepo.FindCompletedExecutionsByDateRange(ctx, since, now)
+		if err != nil {
+			return nil, err
+		}
+		executions = completed
+	}
+	sort.SliceStable(executions, func(i, j int) bool { return executions[i].StartedAt.Before(executions[j].StartedAt) })
+
+	techniques, err := s.techniquesByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	calculator := service.NewScoreCalculator()
+	tactics := make(map[string]bool)
+	for _, execution := range executions {
+		results, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+		if err != nil {
+			return nil, err
+		}
+		point := ScorePoint{
+			ExecutionID: execution.ID,
+			ScenarioID:  execution.ScenarioID,
+			StartedAt:   execution.StartedAt,
+			ByTactic:    make(map[string]float64),
+		}
+		if execution.Score != nil {
+			point.Overall = execution.Score.Overall
+		} else {
+			point.Overall = calculator.CalculateScore(results).Overall
+		}
+		for tactic, score := range calculator.CalculateScoreByTactic(results, techniques) {
+			if score.Total == 0 {
+				continue
+			}
+			point.ByTactic[string(tactic)] = score.Overall
+			tactics[string(tactic)] = true
+		}
+		timeline.Points = append(timeline.Points, point)
+	}
+
+	for tactic := range tactics {
+		timeline.Tactics = append(timeline.Tactics, tactic)
+	}
+	sort.Strings(timeline.Tactics)
+	return timeline, nil
+}
+
+// techniquesByID returns the techniques of the catalog by ID, or nil without technique repository
+func (s *AnalyticsService) techniquesByID(ctx context.Context) (map[string]*entity.Technique, error) {
+	if s.techniqueRepo == nil {
+		return nil, nil
+	}
+	all, err := s.techniqueRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	techniques := make(map[string]*entity.Technique, len(all))
+	for _, technique := range all {
+		techniques[technique.ID] = technique
+	}
+	return techniques, nil
+}
+
+// GetExecutionRegressions returns the detection regressions of an execution
+// against the previous completed run of its scenario
+func (s *AnalyticsService) GetExecutionRegressions(ctx context.Context, executionID string) ([]*entity.DetectionRegression, error) {
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+	return s.DetectRegressions(ctx, execution)
+}
+
+// DetectRegressions compares the results of an execution with those of the
+// previous completed execution of the same scenario, started before it. A
+// technique regressed on an agent when the defenses reacted less than before:
+// blocked, then detected or successful; detected, then successful. Results
+// that did not run to a defense outcome (errors, skips) are not compared.
+func (s *AnalyticsService) DetectRegressions(ctx context.Context, execution *entity.Execution) ([]*entity.DetectionRegression, error) {
+	regressions := []*entity.DetectionRegression{}
+
+	executions, err := s.resultRepo.FindExecutionsByScenario(ctx, execution.ScenarioID)
+	if err != nil {
+		return nil, err
+	}
+	var previous *entity.Execution
+	for _, candidate := range executions {
+		if candidate.ID == execution.ID || candidate.Status != entity.ExecutionCompleted ||
+			!candidate.StartedAt.Before(execution.StartedAt) {
+			continue
+		}
+		if previous == nil || candidate.StartedAt.After(previous.StartedAt) {
+			previous = candidate
+		}
+	}
+	if previous == nil {
+		return regressions, nil
+	}
+
+	before, err := s.resultRepo.FindResultsByExecution(ctx, previous.ID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.resultRepo.FindResultsByExecution(ctx, execution.ID)
+	if err != nil {
+		return nil, err
+	}
+	previousOutcomes := defenseOutcomes(before)
+	outcomes := defenseOutcomes(after)
+
+	for key, current := range outcomes {
+		earlier, ok := previousOutcomes[key]
+		if !ok || defenseLevel(current.status) >= defenseLevel(earlier.status) {
+			continue
+		}
+		regressions = append(regressions, &entity.DetectionRegression{
+			TechniqueID:         key.techniqueID,
+			AgentPaw:            key.agentPaw,
+			PreviousStatus:      earlier.status,
+			Status:              current.status,
+			PreviousExecutionID: previous.ID,
+			PreviousResultID:    earlier.resultID,
+			ResultID:            current.resultID,
+		})
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		if regressions[i].TechniqueID != regressions[j].TechniqueID {
+			return regressions[i].TechniqueID < regressions[j].TechniqueID
+		}
+		return regressions[i].AgentPaw < regressions[j].AgentPaw
+	})
+
+	if len(regressions) > 0 && s.techniqueRepo != nil {
+		for _, regression := range regressions {
+			if technique, err := s.techniqueRepo.FindByID(ctx, regression.TechniqueID); err == nil && technique != nil {
+				regression.TechniqueName = technique.Name
+			}
+		}
+	}
+	return regressions, nil
+}
+
+// defenseKey identifies a technique run on an agent across executions
+type defenseKey struct {
+	techniqueID, agentPaw string
+}
+
+// defenseOutcome is how the defenses reacted to the latest run of a technique on an agent
+type defenseOutcome struct {
+	status    entity.ResultStatus
+	resultID  string
+	startedAt time.Time
+}
+
+// defenseOutcomes returns the latest defense outcome of each technique on each
+// agent, among the results reaching one
+func defenseOutcomes(results []*entity.ExecutionResult) map[defenseKey]defenseOutcome {
+	outcomes := make(map[defenseKey]defenseOutcome)
+	for _, result := range results {
+		status := result.Status
+		if status == entity.StatusSuccess && result.Detected {
+			status = entity.StatusDetected
+		}
+		if defenseLevel(status) < 0 {
+			continue
+		}
+		key := defenseKey{techniqueID: result.TechniqueID, agentPaw: result.AgentPaw}
+		if existing, ok := outcomes[key]; ok && existing.startedAt.After(result.StartedAt) {
+			continue
+		}
+		outcomes[key] = defenseOutcome{status: status, resultID: result.ID, startedAt: result.StartedAt}
+	}
+	return outcomes
+}
+
+// defenseLevel ranks the reaction of the defenses to a result status, -1 when
+// the technique did not run to an outcome
+func defenseLevel(status entity.ResultStatus) int {
+	switch status {
+	case entity.StatusBlocked:
+		return 2
+	case entity.StatusDetected:
+		return 1
+	case entity.StatusSuccess:
+		return 0
+	}
+	return -1
+}
+
+// Name identifies the regression detection as an event sink
+func (s *AnalyticsService) Name() string {
+	return "detection-regressions"
+}
+
+// Handle looks for detection regressions in completed executions and
+// publishes them, so they are notified and streamed like other events
+func (s *AnalyticsService) Handle(ctx context.Context, event *entity.Event) error {
+	if event.Type != entity.EventExecutionCompleted || event.Execution == nil {
+		return nil
+	}
+	regressions, err := s.DetectRegressions(ctx, event.Execution)
+	if err != nil {
+		return err
+	}
+	if len(regressions) == 0 {
+		return nil
+	}
+	s.events.Publish(ctx, &entity.Event{
+		Type:         entity.EventDetectionRegression,
+		Execution:    event.Execution,
+		ScenarioName: event.ScenarioName,
+		Regressions:  regressions,
+	})
+	return nil
+}
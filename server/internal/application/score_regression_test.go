@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// regressionFixture stores a completed execution of scenario-1 with its results
+func regressionFixture(repo *mockResultRepo, id string, started time.Time, results ...*entity.ExecutionResult) *entity.Execution {
+	execution := &entity.Execution{ID: id, ScenarioID: "scenario-1", Status: entity.ExecutionCompleted, StartedAt: started}
+	repo.executions[id] = execution
+	for _, result := range results {
+		result.ExecutionID = id
+		if result.StartedAt.IsZero() {
+			result.StartedAt = started
+		}
+	}
+	repo.results[id] = results
+	return execution
+}
+
+func TestDetectRegressions(t *testing.T) {
+	repo := newMockResultRepo()
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Command and Scripting Interpreter"}
+	svc := NewAnalyticsService(repo)
+	svc.SetTechniqueRepository(techniqueRepo)
+
+	base := time.Now().Add(-72 * time.Hour)
+	result := func(id, technique, paw string, status entity.ResultStatus) *entity.ExecutionResult {
+		return &entity.ExecutionResult{ID: id, TechniqueID: technique, AgentPaw: paw, Status: status}
+	}
+	regressionFixture(repo, "oldest", base,
+		result("o1", "T1082", "agent-1", entity.StatusBlocked),
+	)
+	regressionFixture(repo, "previous", base.Add(24*time.Hour),
+		result("p1", "T1059", "agent-1", entity.StatusBlocked),
+		result("p2", "T1059", "agent-2", entity.StatusDetected),
+		result("p3", "T1082", "agent-1", entity.StatusSuccess),
+		result("p4", "T1003", "agent-1", entity.StatusBlocked),
+		result("p5", "T1018", "agent-1", entity.StatusDetected),
+	)
+	repo.executions["running"] = &entity.Execution{ID: "running", ScenarioID: "scenario-1", Status: entity.ExecutionRunning,
+		StartedAt: base.Add(36 * time.Hour)}
+	current := regressionFixture(repo, "current", base.Add(48*time.Hour),
+		result("c1", "T1059", "agent-1", entity.StatusSuccess),
+		result("c2", "T1059", "agent-2", entity.StatusBlocked), // Improved
+		result("c3", "T1082", "agent-1", entity.StatusSuccess), // Unchanged
+		result("c4", "T1003", "agent-1", entity.StatusFailed),  // No outcome
+		&entity.ExecutionResult{ID: "c5", TechniqueID: "T1018", AgentPaw: "agent-1", Status: entity.StatusSuccess, Detected: true},
+		result("c6", "T1087", "agent-1", entity.StatusSuccess), // Not run before
+	)
+
+	regressions, err := svc.DetectRegressions(context.Background(), current)
+	if err != nil {
+		t.Fatalf("DetectRegressions failed: %v", err)
+	}
+	if len(regressions) != 1 {
+		t.Fatalf("regressions = %+v, want only T1059 on agent-1", regressions)
+	}
+	got := regressions[0]
+	want := entity.DetectionRegression{
+		TechniqueID: "T1059", TechniqueName: "Command and Scripting Interpreter", AgentPaw: "agent-1",
+		PreviousStatus: entity.StatusBlocked, Status: entity.StatusSuccess,
+		PreviousExecutionID: "previous", PreviousResultID: "p1", ResultID: "c1",
+	}
+	if *got != want {
+		t.Errorf("regression = %+v, want %+v", *got, want)
+	}
+
+	// The first run of a scenario has nothing to regress from
+	first, err := svc.GetExecutionRegressions(context.Background(), "oldest")
+	if err != nil || first == nil || len(first) != 0 {
+		t.Errorf("first run regressions = %v, %v, want none", first, err)
+	}
+
+	if _, err := svc.GetExecutionRegressions(context.Background(), "missing"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("err = %v, want ErrExecutionNotFound", err)
+	}
+}
+
+func TestAnalyticsService_HandlePublishesRegressions(t *testing.T) {
+	repo := newMockResultRepo()
+	base := time.Now().Add(-time.Hour)
+	regressionFixture(repo, "previous", base,
+		&entity.ExecutionResult{ID: "p1", TechniqueID: "T1059", AgentPaw: "agent-1", Status: entity.StatusDetected})
+	current := regressionFixture(repo, "current", base.Add(time.Minute),
+		&entity.ExecutionResult{ID: "c1", TechniqueID: "T1059", AgentPaw: "agent-1", Status: entity.StatusSuccess})
+
+	svc := NewAnalyticsService(repo)
+	bus := NewEventBus(zap.NewNop())
+	sink := &recordingSink{name: "recorder"}
+	bus.AddSink(svc)
+	bus.AddSink(sink)
+	svc.SetEventBus(bus)
+
+	bus.Publish(context.Background(), &entity.Event{Type: entity.EventExecutionCompleted, Execution: current, ScenarioName: "Discovery"})
+
+	var regression *entity.Event
+	for _, event := range sink.events {
+		if event.Type == entity.EventDetectionRegression {
+			regression = event
+		}
+	}
+	if regression == nil {
+		t.Fatal("no detection regression event published")
+	}
+	if regression.Execution != current || regression.ScenarioName != "Discovery" || len(regression.Regressions) != 1 {
+		t.Errorf("event = %+v", regression)
+	}
+
+	// Without regression, nothing more is published
+	published := len(sink.events)
+	bus.Publish(context.Background(), &entity.Event{Type: entity.EventExecutionCompleted, Execution: repo.executions["previous"]})
+	if len(sink.events) != published+1 {
+		t.Errorf("events = %d, want only the completion", len(sink.events)-published)
+	}
+}
+
+func TestGetScoreTimeline(t *testing.T) {
+	repo := newMockResultRepo()
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Tactic: entity.TacticExecution}
+	techniqueRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Tactic: entity.TacticDiscovery}
+	svc := NewAnalyticsService(repo)
+	svc.SetTechniqueRepository(techniqueRepo)
+
+	base := time.Now().Add(-72 * time.Hour)
+	second := regressionFixture(repo, "second", base.Add(24*time.Hour),
+		&entity.ExecutionResult{ID: "s1", TechniqueID: "T1059", AgentPaw: "agent-1", Status: entity.StatusSuccess},
+		&entity.ExecutionResult{ID: "s2", TechniqueID: "T1082", AgentPaw: "agent-1", Status: entity.StatusBlocked},
+	)
+	second.Score = &entity.SecurityScore{Overall: 50}
+	regressionFixture(repo, "first", base,
+		&entity.ExecutionResult{ID: "f1", TechniqueID: "T1059", AgentPaw: "agent-1", Status: entity.StatusBlocked},
+	)
+	regressionFixture(repo, "expired", base.AddDate(0, 0, -30))
+	repo.executions["other"] = &entity.Execution{ID: "other", ScenarioID: "scenario-2", Status: entity.ExecutionCompleted,
+		StartedAt: base, Score: &entity.SecurityScore{Overall: 10}}
+
+	timeline, err := svc.GetScoreTimeline(context.Background(), "scenario-1", 7)
+	if err != nil {
+		t.Fatalf("GetScoreTimeline failed: %v", err)
+	}
+	if len(timeline.Points) != 2 || timeline.Points[0].ExecutionID != "first" || timeline.Points[1].ExecutionID != "second" {
+		t.Fatalf("points = %+v, want first then second", timeline.Points)
+	}
+	first, last := timeline.Points[0], timeline.Points[1]
+	if first.Overall != 100 || first.ByTactic["execution"] != 100 {
+		t.Errorf("first = %+v, want scored from its results", first)
+	}
+	if last.Overall != 50 || last.ByTactic["execution"] != 0 || last.ByTactic["discovery"] != 100 {
+		t.Errorf("last = %+v", last)
+	}
+	if len(timeline.Tactics) != 2 || timeline.Tactics[0] != "discovery" || timeline.Tactics[1] != "execution" {
+		t.Errorf("tactics = %v", timeline.Tactics)
+	}
+
+	all, err := svc.GetScoreTimeline(context.Background(), "", 7)
+	if err != nil {
+		t.Fatalf("GetScoreTimeline failed: %v", err)
+	}
+	if len(all.Points) != 3 {
+		t.Errorf("points = %d, want the executions of every scenario", len(all.Points))
+	}
+
+	repo.err = errors.New("db down")
+	if _, err := svc.GetScoreTimeline(context.Background(), "scenario-1", 7); err == nil {
+		t.Error("expected repository error")
+	}
+}
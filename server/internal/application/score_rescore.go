@@ -0,0 +1,206 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInvalidRescore is returned for a rescore of no executions or of too many
+	ErrInvalidRescore = errors.New("invalid rescore request")
+	// ErrExecutionNotCompleted is returned when rescoring an execution that did not complete
+	ErrExecutionNotCompleted = errors.New("execution is not completed")
+)
+
+// maxRescoreExecutions bounds the executions of a batch rescore, larger
+// periods go through the recompute backfill
+const maxRescoreExecutions = 100
+
+// RescoreResult is the outcome of rescoring one execution. ScoreVersion counts
+// the scores of the execution, the original being version 1.
+type RescoreResult struct {
+	ExecutionID           string                `json:"execution_id"`
+	PreviousScore         *entity.SecurityScore `json:"previous_score,omitempty"`
+	NewScore              *entity.SecurityScore `json:"new_score,omitempty"`
+	ScoringProfileID      string                `json:"scoring_profile_id,omitempty"`
+	ScoringProfileVersion int                   `json:"scoring_profile_version,omitempty"`
+	ScoreVersion          int                   `json:"score_version,omitempty"`
+	Changed               bool                  `json:"changed"` // A new score version was written
+	Error                 string                `json:"error,omitempty"`
+}
+
+// RescoreReport is the outcome of a rescore request
+type RescoreReport struct {
+	ID      string           `json:"id"` // Job ID of the score recomputations written
+	Changed int              `json:"changed"`
+	Failed  int              `json:"failed"`
+	Results []*RescoreResult `json:"results"`
+}
+
+// RescoreExecution recalculates the score of a completed execution from its
+// stored results with the current version of a scoring profile, the default
+// profile when profileID is empty. The execution is pinned to that version and
+// its previous score is kept in the score history, so the original stays auditable.
+func (s *ScoreBackfillService) RescoreExecution(ctx context.Context, executionID, profileID, userID string) (*RescoreResult, error) {
+	profile, err := s.prepareRescore(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.rescoreExecution(ctx, uuid.New().String(), executionID, profile, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("Execution rescored",
+		zap.String("execution_id", executionID),
+		zap.String("user_id", userID),
+		zap.Bool("changed", result.Changed),
+	)
+	return result, nil
+}
+
+// Rescore rescores a batch of completed executions like RescoreExecution. An
+// execution that cannot be rescored is reported in its result.
+func (s *ScoreBackfillService) Rescore(ctx context.Context, executionIDs []string, profileID, userID string) (*RescoreReport, error) {
+	if len(executionIDs) == 0 || len(executionIDs) > maxRescoreExecutions {
+		return nil, fmt.Errorf("%w: between 1 and %d executions", ErrInvalidRescore, maxRescoreExecutions)
+	}
+	profile, err := s.prepareRescore(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RescoreReport{ID: uuid.New().String(), Results: make([]*RescoreResult, 0, len(executionIDs))}
+	seen := make(map[string]bool, len(executionIDs))
+	for _, id := range executionIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result, err := s.rescoreExecution(ctx, report.ID, id, profile, userID)
+		if err != nil {
+			report.Failed++
+			report.Results = append(report.Results, &RescoreResult{ExecutionID: id, Error: err.Error()})
+			s.logger.Warn("Failed to rescore execution", zap.String("execution_id", id), zap.Error(err))
+			continue
+		}
+		if result.Changed {
+			report.Changed++
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	s.logger.Info("Executions rescored",
+		zap.String("rescore_id", report.ID),
+		zap.String("user_id", userID),
+		zap.Int("changed", report.Changed),
+		zap.Int("failed", report.Failed),
+	)
+	return report, nil
+}
+
+// prepareRescore resolves the scoring profile version executions are rescored
+// with, nil for the built-in formula. It refuses to rescore during a backfill,
+// which would recompute the same executions concurrently.
+func (s *ScoreBackfillService) prepareRescore(ctx context.Context, profileID string) (*entity.ScoringProfile, error) {
+	s.mu.Lock()
+	running := s.running != ""
+	s.mu.Unlock()
+	if running {
+		return nil, ErrRecomputeInProgress
+	}
+
+	if s.scoringProfiles == nil {
+		if profileID != "" {
+			return nil, ErrScoringProfileNotFound
+		}
+		return nil, nil
+	}
+	return s.scoringProfiles.Resolve(ctx, profileID)
+}
+
+// rescoreExecution scores one execution with a profile version, nil for the
+// built-in formula. Nothing is written when neither the score nor the profile
+// version changes.
+func (s *ScoreBackfillService) rescoreExecution(
+	ctx context.Context,
+	rescoreID, executionID string,
+	profile *entity.ScoringProfile,
+	userID string,
+) (*RescoreResult, error) {
+	execution, err := s.resultRepo.FindExecutionByID(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutionNotFound, err)
+	}
+	if execution.Status != entity.ExecutionCompleted {
+		return nil, ErrExecutionNotCompleted
+	}
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load results: %w", err)
+	}
+	history, err := s.historyRepo.FindByExecution(ctx, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load score history: %w", err)
+	}
+
+	previous := entity.SecurityScore{}
+	if execution.Score != nil {
+		previous = *execution.Score
+	}
+	rescored := *execution
+	rescored.ScoringProfileID, rescored.ScoringProfileVersion = "", 0
+	if profile != nil {
+		rescored.ScoringProfileID, rescored.ScoringProfileVersion = profile.ID, profile.Version
+	}
+	score, err := scoreExecution(ctx, s.calculator, s.scoringProfiles, &rescored, results)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RescoreResult{
+		ExecutionID:           executionID,
+		PreviousScore:         &previous,
+		NewScore:              score,
+		ScoringProfileID:      rescored.ScoringProfileID,
+		ScoringProfileVersion: rescored.ScoringProfileVersion,
+		ScoreVersion:          len(history) + 1,
+	}
+	if sameScore(previous, *score) &&
+		rescored.ScoringProfileID == execution.ScoringProfileID &&
+		rescored.ScoringProfileVersion == execution.ScoringProfileVersion {
+		return result, nil
+	}
+
+	err = s.historyRepo.Create(ctx, &entity.ScoreRecomputation{
+		ID:                            uuid.New().String(),
+		ExecutionID:                   executionID,
+		JobID:                         rescoreID,
+		PreviousScore:                 previous,
+		NewScore:                      *score,
+		PreviousScoringProfileID:      execution.ScoringProfileID,
+		PreviousScoringProfileVersion: execution.ScoringProfileVersion,
+		ScoringProfileID:              rescored.ScoringProfileID,
+		ScoringProfileVersion:         rescored.ScoringProfileVersion,
+		RecomputedBy:                  userID,
+		RecomputedAt:                  time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to preserve previous score: %w", err)
+	}
+
+	rescored.Score = score
+	if err := s.resultRepo.UpdateExecution(ctx, &rescored); err != nil {
+		return nil, fmt.Errorf("failed to update execution: %w", err)
+	}
+	result.Changed = true
+	result.ScoreVersion++
+	return result, nil
+}
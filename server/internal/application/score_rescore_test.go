@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+func TestScoreRescore_WithProfile(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	historyRepo := &mockScoreHistoryRepo{}
+	profiles, _, _ := setupScoringProfileService()
+	svc := NewScoreBackfillService(resultRepo, historyRepo, service.NewScoreCalculator(), nil)
+	svc.SetScoringProfileService(profiles)
+	ctx := context.Background()
+
+	// Detections no longer earn partial credit
+	profile, err := profiles.Create(ctx, &entity.ScoringProfile{Name: "Strict", BlockedCredit: 1}, "user-1")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	result, err := svc.RescoreExecution(ctx, "exec-fresh", profile.ID, "user-2")
+	if err != nil {
+		t.Fatalf("RescoreExecution failed: %v", err)
+	}
+	if !result.Changed || result.ScoreVersion != 2 || result.PreviousScore.Overall != 50 || result.NewScore.Overall != 0 {
+		t.Errorf("Unexpected rescore result: %+v", result)
+	}
+	if result.ScoringProfileID != profile.ID || result.ScoringProfileVersion != 1 {
+		t.Errorf("Expected the result to reference profile %s v1, got %+v", profile.ID, result)
+	}
+
+	execution := resultRepo.executions["exec-fresh"]
+	if execution.Score.Overall != 0 || execution.ScoringProfileID != profile.ID || execution.ScoringProfileVersion != 1 {
+		t.Errorf("Expected the execution to be rescored and pinned, got %+v", execution)
+	}
+	if len(historyRepo.recomputations) != 1 {
+		t.Fatalf("Expected 1 recomputation, got %d", len(historyRepo.recomputations))
+	}
+	rec := historyRepo.recomputations[0]
+	if rec.PreviousScore.Overall != 50 || rec.PreviousScoringProfileID != "" || rec.ScoringProfileID != profile.ID || rec.RecomputedBy != "user-2" {
+		t.Errorf("Expected the original score to be preserved, got %+v", rec)
+	}
+
+	// Rescoring with the same profile version writes nothing
+	again, err := svc.RescoreExecution(ctx, "exec-fresh", profile.ID, "user-2")
+	if err != nil {
+		t.Fatalf("RescoreExecution failed: %v", err)
+	}
+	if again.Changed || again.ScoreVersion != 2 || len(historyRepo.recomputations) != 1 {
+		t.Errorf("Expected an unchanged rescore, got %+v", again)
+	}
+}
+
+func TestScoreRescore_Batch(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	historyRepo := &mockScoreHistoryRepo{}
+	svc := NewScoreBackfillService(resultRepo, historyRepo, service.NewScoreCalculator(), nil)
+
+	report, err := svc.Rescore(context.Background(), []string{"exec-stale", "exec-fresh", "exec-stale", "missing"}, "", "user-1")
+	if err != nil {
+		t.Fatalf("Rescore failed: %v", err)
+	}
+	if report.ID == "" || report.Changed != 1 || report.Failed != 1 || len(report.Results) != 3 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.Results[2].ExecutionID != "missing" || report.Results[2].Error == "" {
+		t.Errorf("Expected the missing execution to be reported, got %+v", report.Results[2])
+	}
+	if len(historyRepo.recomputations) != 1 || historyRepo.recomputations[0].JobID != report.ID {
+		t.Errorf("Expected the recomputation to reference rescore %s, got %+v", report.ID, historyRepo.recomputations)
+	}
+	if got := resultRepo.executions["exec-stale"].Score.Overall; got != 50 {
+		t.Errorf("Expected exec-stale to be rescored to 50, got %v", got)
+	}
+}
+
+func TestScoreRescore_Errors(t *testing.T) {
+	resultRepo := newBackfillFixture()
+	resultRepo.executions["exec-running"] = &entity.Execution{ID: "exec-running", Status: entity.ExecutionRunning}
+	svc := NewScoreBackfillService(resultRepo, &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+	ctx := context.Background()
+
+	if _, err := svc.Rescore(ctx, nil, "", "user-1"); !errors.Is(err, ErrInvalidRescore) {
+		t.Errorf("Expected ErrInvalidRescore for no executions, got %v", err)
+	}
+	tooMany := make([]string, maxRescoreExecutions+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("exec-%d", i)
+	}
+	if _, err := svc.Rescore(ctx, tooMany, "", "user-1"); !errors.Is(err, ErrInvalidRescore) {
+		t.Errorf("Expected ErrInvalidRescore for too many executions, got %v", err)
+	}
+
+	if _, err := svc.RescoreExecution(ctx, "missing", "", "user-1"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Errorf("Expected ErrExecutionNotFound, got %v", err)
+	}
+	if _, err := svc.RescoreExecution(ctx, "exec-running", "", "user-1"); !errors.Is(err, ErrExecutionNotCompleted) {
+		t.Errorf("Expected ErrExecutionNotCompleted, got %v", err)
+	}
+	if _, err := svc.RescoreExecution(ctx, "exec-fresh", "strict", "user-1"); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Errorf("Expected ErrScoringProfileNotFound without profiles, got %v", err)
+	}
+
+	profiles, _, _ := setupScoringProfileService()
+	svc.SetScoringProfileService(profiles)
+	if _, err := svc.RescoreExecution(ctx, "exec-fresh", "unknown", "user-1"); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Errorf("Expected ErrScoringProfileNotFound, got %v", err)
+	}
+
+	svc.running = "job-1"
+	if _, err := svc.RescoreExecution(ctx, "exec-fresh", "", "user-1"); !errors.Is(err, ErrRecomputeInProgress) {
+		t.Errorf("Expected ErrRecomputeInProgress during a backfill, got %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/domain/service"
+
+	"github.com/google/uuid"
+)
+
+// Scoring profile errors
+var (
+	ErrScoringProfileNotFound = errors.New("scoring profile not found")
+	ErrInvalidScoringProfile  = errors.New("invalid scoring profile")
+)
+
+// ScoringProfileService manages the versioned scoring profiles and scores
+// executions with the profile version they are pinned to
+type ScoringProfileService struct {
+	profileRepo   repository.ScoringProfileRepository
+	techniqueRepo repository.TechniqueRepository
+	calculator    *service.ScoreCalculator
+}
+
+// NewScoringProfileService creates a new scoring profile service
+func NewScoringProfileService(
+	profileRepo repository.ScoringProfileRepository,
+	techniqueRepo repository.TechniqueRepository,
+	calculator *service.ScoreCalculator,
+) *ScoringProfileService {
+	return &ScoringProfileService{profileRepo: profileRepo, techniqueRepo: techniqueRepo, calculator: calculator}
+}
+
+// List returns the current version of every scoring profile
+func (s *ScoringProfileService) List(ctx context.Context) ([]*entity.ScoringProfile, error) {
+	return s.profileRepo.FindAll(ctx)
+}
+
+// Get returns the current version of a scoring profile
+func (s *ScoringProfileService) Get(ctx context.Context, id string) (*entity.ScoringProfile, error) {
+	profile, err := s.profileRepo.FindByID(ctx, id)
+	return foundScoringProfile(profile, err)
+}
+
+// GetVersion returns a version of a scoring profile, even of a deleted profile
+func (s *ScoringProfileService) GetVersion(ctx context.Context, id string, version int) (*entity.ScoringProfile, error) {
+	profile, err := s.profileRepo.FindVersion(ctx, id, version)
+	return foundScoringProfile(profile, err)
+}
+
+// Versions returns every version of a scoring profile, oldest first
+func (s *ScoringProfileService) Versions(ctx context.Context, id string) ([]*entity.ScoringProfile, error) {
+	versions, err := s.profileRepo.FindVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrScoringProfileNotFound
+	}
+	return versions, nil
+}
+
+// Create saves a new scoring profile as its version 1
+func (s *ScoringProfileService) Create(ctx context.Context, profile *entity.ScoringProfile, userID string) (*entity.ScoringProfile, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScoringProfile, err)
+	}
+
+	profile.ID = uuid.New().String()
+	profile.Version = 1
+	profile.IsDefault = false
+	profile.CreatedBy = userID
+	profile.CreatedAt = time.Now()
+
+	if err := s.profileRepo.Create(ctx, profile); err != nil {
+		return nil, fmt.Errorf("failed to create scoring profile: %w", err)
+	}
+	return profile, nil
+}
+
+// Update saves a new version of a scoring profile. Earlier versions are kept
+// unchanged for the executions scored with them.
+func (s *ScoringProfileService) Update(ctx context.Context, id string, update *entity.ScoringProfile, userID string) (*entity.ScoringProfile, error) {
+	current, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := update.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScoringProfile, err)
+	}
+
+	update.ID = id
+	update.Version = current.Version + 1
+	update.IsDefault = current.IsDefault
+	update.CreatedBy = userID
+	update.CreatedAt = time.Now()
+
+	if err := s.profileRepo.AddVersion(ctx, update); err != nil {
+		return nil, fmt.Errorf("failed to update scoring profile: %w", err)
+	}
+	return update, nil
+}
+
+// Delete removes a scoring profile from listings; executions pinned to one of
+// its versions keep being scored with it
+func (s *ScoringProfileService) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.profileRepo.Delete(ctx, id)
+}
+
+// SetDefault makes a scoring profile the one of executions that select none
+func (s *ScoringProfileService) SetDefault(ctx context.Context, id string) (*entity.ScoringProfile, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.SetDefault(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to set default scoring profile: %w", err)
+	}
+	return s.Get(ctx, id)
+}
+
+// Resolve returns the profile version a new execution is pinned to: the current
+// version of the selected profile, else of the default profile. It returns nil
+// when nothing is selected and no profile is the default, for the built-in formula.
+func (s *ScoringProfileService) Resolve(ctx context.Context, id string) (*entity.ScoringProfile, error) {
+	if id != "" {
+		return s.Get(ctx, id)
+	}
+	return s.profileRepo.FindDefault(ctx)
+}
+
+// Score calculates the score of an execution with the profile version it is
+// pinned to, or with the built-in formula when it is pinned to none
+func (s *ScoringProfileService) Score(
+	ctx context.Context,
+	execution *entity.Execution,
+	results []*entity.ExecutionResult,
+) (*entity.SecurityScore, error) {
+	if execution.ScoringProfileID == "" {
+		return s.calculator.CalculateScore(results), nil
+	}
+
+	profile, err := s.GetVersion(ctx, execution.ScoringProfileID, execution.ScoringProfileVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scoring profile %s version %d: %w",
+			execution.ScoringProfileID, execution.ScoringProfileVersion, err)
+	}
+
+	techniques := make(map[string]*entity.Technique)
+	if s.techniqueRepo != nil {
+		all, err := s.techniqueRepo.FindAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load techniques: %w", err)
+		}
+		for _, technique := range all {
+			techniques[technique.ID] = technique
+		}
+	}
+	return s.calculator.CalculateProfileScore(results, techniques, profile), nil
+}
+
+// foundScoringProfile maps a missing scoring profile to ErrScoringProfileNotFound
+func foundScoringProfile(profile *entity.ScoringProfile, err error) (*entity.ScoringProfile, error) {
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrScoringProfileNotFound
+		}
+		return nil, err
+	}
+	if profile == nil {
+		return nil, ErrScoringProfileNotFound
+	}
+	return profile, nil
+}
+
+// scoreExecution calculates the score of an execution with its pinned scoring
+// profile when profiles are configured, else with the built-in formula
+func scoreExecution(
+	ctx context.Context,
+	calculator *service.ScoreCalculator,
+	profiles *ScoringProfileService,
+	execution *entity.Execution,
+	results []*entity.ExecutionResult,
+) (*entity.SecurityScore, error) {
+	if profiles == nil {
+		return calculator.CalculateScore(results), nil
+	}
+	return profiles.Score(ctx, execution, results)
+}
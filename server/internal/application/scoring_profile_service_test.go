@@ -0,0 +1,294 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+// mockScoringProfileRepo implements repository.ScoringProfileRepository for tests
+type mockScoringProfileRepo struct {
+	versions  map[string][]*entity.ScoringProfile // By profile ID, oldest first
+	deleted   map[string]bool
+	defaultID string
+	err       error
+}
+
+func newMockScoringProfileRepo() *mockScoringProfileRepo {
+	return &mockScoringProfileRepo{
+		versions: make(map[string][]*entity.ScoringProfile),
+		deleted:  make(map[string]bool),
+	}
+}
+
+func (m *mockScoringProfileRepo) Create(ctx context.Context, profile *entity.ScoringProfile) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.versions[profile.ID] = []*entity.ScoringProfile{profile}
+	return nil
+}
+
+func (m *mockScoringProfileRepo) AddVersion(ctx context.Context, profile *entity.ScoringProfile) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.versions[profile.ID] = append(m.versions[profile.ID], profile)
+	return nil
+}
+
+func (m *mockScoringProfileRepo) Delete(ctx context.Context, id string) error {
+	m.deleted[id] = true
+	if m.defaultID == id {
+		m.defaultID = ""
+	}
+	return nil
+}
+
+func (m *mockScoringProfileRepo) FindByID(ctx context.Context, id string) (*entity.ScoringProfile, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	versions := m.versions[id]
+	if len(versions) == 0 || m.deleted[id] {
+		return nil, sql.ErrNoRows
+	}
+	profile := *versions[len(versions)-1]
+	profile.IsDefault = m.defaultID == id
+	return &profile, nil
+}
+
+func (m *mockScoringProfileRepo) FindVersion(ctx context.Context, id string, version int) (*entity.ScoringProfile, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, profile := range m.versions[id] {
+		if profile.Version == version {
+			return profile, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockScoringProfileRepo) FindVersions(ctx context.Context, id string) ([]*entity.ScoringProfile, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.versions[id], nil
+}
+
+func (m *mockScoringProfileRepo) FindAll(ctx context.Context) ([]*entity.ScoringProfile, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var profiles []*entity.ScoringProfile
+	for id := range m.versions {
+		if profile, err := m.FindByID(ctx, id); err == nil {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+func (m *mockScoringProfileRepo) FindDefault(ctx context.Context) (*entity.ScoringProfile, error) {
+	if m.defaultID == "" {
+		return nil, nil
+	}
+	return m.FindByID(ctx, m.defaultID)
+}
+
+func (m *mockScoringProfileRepo) SetDefault(ctx context.Context, id string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.defaultID = id
+	return nil
+}
+
+func setupScoringProfileService() (*ScoringProfileService, *mockScoringProfileRepo, *mockTechniqueRepo) {
+	profileRepo := newMockScoringProfileRepo()
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{ID: "T1059", Tactic: entity.TacticExecution}
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Tactic: entity.TacticDiscovery}
+	return NewScoringProfileService(profileRepo, techRepo, service.NewScoreCalculator()), profileRepo, techRepo
+}
+
+func TestScoringProfileService_CreateAndUpdate(t *testing.T) {
+	svc, _, _ := setupScoringProfileService()
+	ctx := context.Background()
+
+	created, err := svc.Create(ctx, &entity.ScoringProfile{Name: "Strict", BlockedCredit: 1}, "user-1")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.ID == "" || created.Version != 1 || created.CreatedBy != "user-1" {
+		t.Errorf("unexpected profile %+v", created)
+	}
+
+	updated, err := svc.Update(ctx, created.ID, &entity.ScoringProfile{Name: "Strict", BlockedCredit: 1, DetectedCredit: 0.25}, "user-2")
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.Version != 2 || updated.CreatedBy != "user-2" {
+		t.Errorf("expected version 2 by user-2, got %+v", updated)
+	}
+
+	first, err := svc.GetVersion(ctx, created.ID, 1)
+	if err != nil {
+		t.Fatalf("GetVersion() error = %v", err)
+	}
+	if first.DetectedCredit != 0 {
+		t.Error("expected version 1 to be kept unchanged")
+	}
+	versions, err := svc.Versions(ctx, created.ID)
+	if err != nil || len(versions) != 2 {
+		t.Errorf("expected 2 versions, got %d (%v)", len(versions), err)
+	}
+}
+
+func TestScoringProfileService_Invalid(t *testing.T) {
+	svc, _, _ := setupScoringProfileService()
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, &entity.ScoringProfile{Name: "Bad", BlockedCredit: 2}, "user-1")
+	if !errors.Is(err, ErrInvalidScoringProfile) {
+		t.Errorf("expected ErrInvalidScoringProfile, got %v", err)
+	}
+	if _, err := svc.Update(ctx, "missing", &entity.ScoringProfile{Name: "p"}, "user-1"); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Errorf("expected ErrScoringProfileNotFound, got %v", err)
+	}
+	if _, err := svc.Versions(ctx, "missing"); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Errorf("expected ErrScoringProfileNotFound, got %v", err)
+	}
+}
+
+func TestScoringProfileService_DeleteKeepsVersions(t *testing.T) {
+	svc, _, _ := setupScoringProfileService()
+	ctx := context.Background()
+	profile, _ := svc.Create(ctx, &entity.ScoringProfile{Name: "Old", BlockedCredit: 1}, "user-1")
+
+	if err := svc.Delete(ctx, profile.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := svc.Get(ctx, profile.ID); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Errorf("expected deleted profile to be hidden, got %v", err)
+	}
+	if _, err := svc.GetVersion(ctx, profile.ID, 1); err != nil {
+		t.Errorf("expected versions of a deleted profile to resolve, got %v", err)
+	}
+}
+
+func TestScoringProfileService_Resolve(t *testing.T) {
+	svc, _, _ := setupScoringProfileService()
+	ctx := context.Background()
+
+	profile, err := svc.Resolve(ctx, "")
+	if err != nil || profile != nil {
+		t.Fatalf("expected no profile without default, got %+v (%v)", profile, err)
+	}
+
+	created, _ := svc.Create(ctx, &entity.ScoringProfile{Name: "Default", BlockedCredit: 1}, "user-1")
+	if _, err := svc.SetDefault(ctx, created.ID); err != nil {
+		t.Fatalf("SetDefault() error = %v", err)
+	}
+	profile, err = svc.Resolve(ctx, "")
+	if err != nil || profile == nil || profile.ID != created.ID {
+		t.Errorf("expected the default profile, got %+v (%v)", profile, err)
+	}
+	if _, err := svc.Resolve(ctx, "missing"); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Errorf("expected ErrScoringProfileNotFound, got %v", err)
+	}
+}
+
+func TestScoringProfileService_ScoreUsesPinnedVersion(t *testing.T) {
+	svc, _, _ := setupScoringProfileService()
+	ctx := context.Background()
+	results := []*entity.ExecutionResult{
+		{TechniqueID: "T1059", Status: entity.StatusBlocked},
+		{TechniqueID: "T1082", Status: entity.StatusSuccess},
+	}
+
+	score, err := svc.Score(ctx, &entity.Execution{ID: "e1"}, results)
+	if err != nil || score.Overall != 50 {
+		t.Fatalf("expected the built-in score 50 without profile, got %+v (%v)", score, err)
+	}
+
+	profile, _ := svc.Create(ctx, &entity.ScoringProfile{Name: "Execution first", BlockedCredit: 1,
+		TacticWeights: map[string]float64{"execution": 3}}, "user-1")
+	_, _ = svc.Update(ctx, profile.ID, &entity.ScoringProfile{Name: "Execution first", BlockedCredit: 1}, "user-1")
+
+	execution := &entity.Execution{ID: "e1", ScoringProfileID: profile.ID, ScoringProfileVersion: 1}
+	score, err = svc.Score(ctx, execution, results)
+	if err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	if score.Overall != 75 {
+		t.Errorf("expected version 1 weights to score 75, got %v", score.Overall)
+	}
+
+	execution.ScoringProfileVersion = 9
+	if _, err := svc.Score(ctx, execution, results); err == nil {
+		t.Error("expected an error for a missing profile version")
+	}
+}
+
+func TestExecutionService_PinsScoringProfile(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:     "s1",
+		Name:   "Test",
+		Phases: []entity.Phase{{Name: "Phase1", Techniques: []string{"T1059"}}},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{
+		ID:        "T1059",
+		Tactic:    entity.TacticExecution,
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "echo test"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{
+		Paw: "paw1", Status: entity.AgentOnline, Platform: "linux", Executors: []string{"sh"}, LastSeen: time.Now(),
+	}
+	calculator := service.NewScoreCalculator()
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	svc := NewExecutionService(resultRepo, scenarioRepo, techRepo, agentRepo, orchestrator, calculator)
+	profiles := NewScoringProfileService(newMockScoringProfileRepo(), techRepo, calculator)
+	svc.SetScoringProfileService(profiles)
+	ctx := context.Background()
+
+	profile, _ := profiles.Create(ctx, &entity.ScoringProfile{Name: "Lenient", BlockedCredit: 1, DetectedCredit: 1}, "user-1")
+	_, _ = profiles.Update(ctx, profile.ID, &entity.ScoringProfile{Name: "Lenient", BlockedCredit: 1, DetectedCredit: 0.8}, "user-1")
+
+	started, err := svc.StartExecutionWithProfile(ctx, "s1", []string{"paw1"}, false, profile.ID, ExecutionLauncher{})
+	if err != nil {
+		t.Fatalf("StartExecutionWithProfile() error = %v", err)
+	}
+	execution := started.Execution
+	if execution.ScoringProfileID != profile.ID || execution.ScoringProfileVersion != 2 {
+		t.Fatalf("expected the execution pinned to version 2, got %s v%d",
+			execution.ScoringProfileID, execution.ScoringProfileVersion)
+	}
+
+	// A later version does not change the score of the pinned execution
+	_, _ = profiles.Update(ctx, profile.ID, &entity.ScoringProfile{Name: "Lenient", BlockedCredit: 1}, "user-1")
+	for _, result := range resultRepo.results[execution.ID] {
+		result.Status = entity.StatusDetected
+	}
+	if err := svc.CompleteExecution(ctx, execution.ID); err != nil {
+		t.Fatalf("CompleteExecution() error = %v", err)
+	}
+	if score := resultRepo.executions[execution.ID].Score; score == nil || score.Overall != 80 {
+		t.Errorf("expected version 2 to score 80, got %+v", score)
+	}
+
+	if _, err := svc.StartExecutionWithProfile(ctx, "s1", []string{"paw1"}, false, "missing", ExecutionLauncher{}); !errors.Is(err, ErrScoringProfileNotFound) {
+		t.Errorf("expected ErrScoringProfileNotFound, got %v", err)
+	}
+}
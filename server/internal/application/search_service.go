@@ -0,0 +1,77 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+const (
+	// DefaultSearchLimit is the number of hits returned when no limit is given
+	DefaultSearchLimit = 20
+
+	// MaxSearchLimit caps the number of hits of a search
+	MaxSearchLimit = 100
+
+	// maxSearchQueryLength caps the length of a search query, in bytes
+	maxSearchQueryLength = 256
+)
+
+var (
+	// ErrInvalidSearchQuery is returned for an empty or too long search query
+	ErrInvalidSearchQuery = errors.New("invalid search query")
+
+	// ErrInvalidSearchType is returned when searching an unknown type of item
+	ErrInvalidSearchType = errors.New("invalid search type")
+)
+
+// SearchService searches the techniques, scenarios and execution results by
+// their text: technique names, descriptions and commands, scenario names,
+// descriptions and tags, and result outputs
+type SearchService struct {
+	repo repository.SearchRepository
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(repo repository.SearchRepository) *SearchService {
+	return &SearchService{repo: repo}
+}
+
+// Search returns the items of types matching every term of query, best first.
+// No types searches every type. The limit defaults to DefaultSearchLimit and
+// is capped at MaxSearchLimit.
+func (s *SearchService) Search(ctx context.Context, query string, types []entity.SearchType, limit int) ([]*entity.SearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: empty", ErrInvalidSearchQuery)
+	}
+	if len(query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: longer than %d characters", ErrInvalidSearchQuery, maxSearchQueryLength)
+	}
+
+	if len(types) == 0 {
+		types = entity.SearchTypes()
+	}
+	for _, t := range types {
+		if !t.IsValid() {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSearchType, t)
+		}
+	}
+
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	hits, err := s.repo.Search(ctx, query, types, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	return hits, nil
+}
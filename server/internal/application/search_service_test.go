@@ -0,0 +1,60 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockSearchRepo implements repository.SearchRepository for tests
+type mockSearchRepo struct {
+	query string
+	types []entity.SearchType
+	limit int
+	hits  []*entity.SearchHit
+	err   error
+}
+
+func (m *mockSearchRepo) Search(ctx context.Context, query string, types []entity.SearchType, limit int) ([]*entity.SearchHit, error) {
+	m.query, m.types, m.limit = query, types, limit
+	return m.hits, m.err
+}
+
+func TestSearchService_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockSearchRepo{hits: []*entity.SearchHit{{Type: entity.SearchTechnique, ID: "T1033"}}}
+	service := NewSearchService(repo)
+
+	hits, err := service.Search(ctx, "  whoami ", nil, 0)
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("Search = %v, %v", hits, err)
+	}
+	if repo.query != "whoami" || repo.limit != DefaultSearchLimit || !reflect.DeepEqual(repo.types, entity.SearchTypes()) {
+		t.Errorf("repository searched %q %v limit %d", repo.query, repo.types, repo.limit)
+	}
+
+	if _, err := service.Search(ctx, "whoami", []entity.SearchType{entity.SearchResult}, 1000); err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if repo.limit != MaxSearchLimit || len(repo.types) != 1 {
+		t.Errorf("repository searched %v limit %d, want the results capped", repo.types, repo.limit)
+	}
+
+	for _, query := range []string{"", "   ", strings.Repeat("a", maxSearchQueryLength+1)} {
+		if _, err := service.Search(ctx, query, nil, 0); !errors.Is(err, ErrInvalidSearchQuery) {
+			t.Errorf("Search(%d bytes) error = %v, want ErrInvalidSearchQuery", len(query), err)
+		}
+	}
+	if _, err := service.Search(ctx, "whoami", []entity.SearchType{"agent"}, 0); !errors.Is(err, ErrInvalidSearchType) {
+		t.Errorf("error = %v, want ErrInvalidSearchType", err)
+	}
+
+	repo.err = errors.New("database locked")
+	if _, err := service.Search(ctx, "whoami", nil, 0); err == nil {
+		t.Error("expected the repository error")
+	}
+}
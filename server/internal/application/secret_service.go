@@ -0,0 +1,308 @@
+package application
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// Secrets store errors
+var (
+	ErrSecretNotFound = errors.New("secret not found")
+	ErrInvalidSecret  = errors.New("invalid secret")
+)
+
+const (
+	// maxSecretSize is the largest secret value accepted
+	maxSecretSize = 64 * 1024
+	// maxSecretDescription is the longest secret description accepted
+	maxSecretDescription = 200
+	// sealedPrefix starts a value sealed by SecretService.Seal
+	sealedPrefix = "enc:v1:"
+)
+
+// secretNamePattern matches the names of secrets, as used in secret:// references
+var secretNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,99}$`)
+
+// MasterKey wraps the data keys secrets are sealed with. It is kept out of
+// the database: derived from an environment variable, or held by a KMS.
+type MasterKey interface {
+	// ID identifies the key, so that values wrapped with another key are recognized
+	ID() string
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// LocalMasterKey is a master key derived from a passphrase, such as
+// SECRETS_MASTER_KEY, wrapping data keys with AES-256-GCM
+type LocalMasterKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewLocalMasterKey derives a master key from a passphrase
+func NewLocalMasterKey(passphrase string) (*LocalMasterKey, error) {
+	if passphrase == "" {
+		return nil, errors.New("master key is empty")
+	}
+	key := sha256.Sum256([]byte(passphrase))
+	aead, err := newAEAD(key[:])
+	if err != nil {
+		return nil, err
+	}
+	// The ID is a hash of the derived key, which does not reveal it
+	id := sha256.Sum256(key[:])
+	return &LocalMasterKey{id: "local:" + hex.EncodeToString(id[:6]), aead: aead}, nil
+}
+
+// ID identifies the key by a hash of it
+func (k *LocalMasterKey) ID() string {
+	return k.id
+}
+
+// WrapKey seals a data key
+func (k *LocalMasterKey) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	return sealAEAD(k.aead, dataKey)
+}
+
+// UnwrapKey opens a data key sealed by WrapKey
+func (k *LocalMasterKey) UnwrapKey(_ context.Context, wrapped []byte) ([]byte, error) {
+	return openAEAD(k.aead, wrapped)
+}
+
+// SecretService keeps integration credentials encrypted with envelope
+// encryption: each value is sealed with a random data key, wrapped with the
+// master key. Configuration values refer to them as secret://<name>.
+type SecretService struct {
+	repo repository.SecretRepository
+	key  MasterKey
+}
+
+// NewSecretService creates a secrets store sealing values under a master key
+func NewSecretService(repo repository.SecretRepository, key MasterKey) *SecretService {
+	return &SecretService{repo: repo, key: key}
+}
+
+// KeyID returns the ID of the master key new values are sealed under
+func (s *SecretService) KeyID() string {
+	return s.key.ID()
+}
+
+// Put stores the value of a secret, replacing the previous one
+func (s *SecretService) Put(ctx context.Context, name, description, value, userID string) (*entity.Secret, error) {
+	if !secretNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: a name of lowercase letters, digits, '.', '_' and '-' is required", ErrInvalidSecret)
+	}
+	if value == "" || len(value) > maxSecretSize {
+		return nil, fmt.Errorf("%w: a value of 1 to %d bytes is required", ErrInvalidSecret, maxSecretSize)
+	}
+	if len(description) > maxSecretDescription {
+		return nil, fmt.Errorf("%w: the description is limited to %d characters", ErrInvalidSecret, maxSecretDescription)
+	}
+
+	envelope, err := s.seal(ctx, []byte(value))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	secret := &entity.Secret{
+		Name:        name,
+		Description: description,
+		KeyID:       envelope.KeyID,
+		DataKey:     envelope.DataKey,
+		Ciphertext:  envelope.Data,
+		UpdatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if existing, err := s.repo.FindByName(ctx, name); err == nil {
+		secret.CreatedAt = existing.CreatedAt
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to find secret: %w", err)
+	}
+	if err := s.repo.Save(ctx, secret); err != nil {
+		return nil, fmt.Errorf("failed to save secret: %w", err)
+	}
+	return secret, nil
+}
+
+// List returns the secrets, without their value
+func (s *SecretService) List(ctx context.Context) ([]*entity.Secret, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// Delete deletes a secret
+func (s *SecretService) Delete(ctx context.Context, name string) error {
+	if _, err := s.find(ctx, name); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, name)
+}
+
+// Value decrypts the value of a secret
+func (s *SecretService) Value(ctx context.Context, name string) (string, error) {
+	secret, err := s.find(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	value, err := s.open(ctx, &secretEnvelope{KeyID: secret.KeyID, DataKey: secret.DataKey, Data: secret.Ciphertext})
+	if err != nil {
+		return "", fmt.Errorf("secret %s: %w", name, err)
+	}
+	return string(value), nil
+}
+
+// Resolve returns the value of the secret a secret://<name> configuration
+// value refers to; any other value is returned as is
+func (s *SecretService) Resolve(ctx context.Context, value string) (string, error) {
+	name, ok := entity.SecretRef(value)
+	if !ok {
+		return value, nil
+	}
+	return s.Value(ctx, name)
+}
+
+// Seal encrypts a value kept in another table, such as a webhook secret. An
+// empty value stays empty.
+func (s *SecretService) Seal(ctx context.Context, value string) (string, error) {
+	if value == "" || isSealed(value) {
+		return value, nil
+	}
+	envelope, err := s.seal(ctx, []byte(value))
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Open decrypts a value sealed by Seal. A value stored before the secrets
+// store was enabled is returned as is.
+func (s *SecretService) Open(ctx context.Context, value string) (string, error) {
+	if !isSealed(value) {
+		return value, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed value: %w", err)
+	}
+	var envelope secretEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return "", fmt.Errorf("invalid sealed value: %w", err)
+	}
+	plaintext, err := s.open(ctx, &envelope)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// isSealed reports whether a value was sealed by SecretService.Seal
+func isSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// find finds a secret by name
+func (s *SecretService) find(ctx context.Context, name string) (*entity.Secret, error) {
+	secret, err := s.repo.FindByName(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find secret: %w", err)
+	}
+	return secret, nil
+}
+
+// secretEnvelope is a value sealed with a data key, and the data key wrapped
+// with the master key
+type secretEnvelope struct {
+	KeyID   string `json:"kid"`
+	DataKey []byte `json:"key"`
+	Data    []byte `json:"data"`
+}
+
+// seal seals a value with a new data key
+func (s *SecretService) seal(ctx context.Context, plaintext []byte) (*secretEnvelope, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := sealAEAD(aead, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := s.key.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+	return &secretEnvelope{KeyID: s.key.ID(), DataKey: wrapped, Data: data}, nil
+}
+
+// open unwraps the data key of an envelope and opens its value
+func (s *SecretService) open(ctx context.Context, envelope *secretEnvelope) ([]byte, error) {
+	if envelope.KeyID != s.key.ID() {
+		return nil, fmt.Errorf("sealed with master key %s, the server uses %s", envelope.KeyID, s.key.ID())
+	}
+	dataKey, err := s.key.UnwrapKey(ctx, envelope.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return openAEAD(aead, envelope.Data)
+}
+
+// newAEAD creates an AES-256-GCM cipher
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealAEAD returns a random nonce followed by the sealed plaintext
+func sealAEAD(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// openAEAD opens a value sealed by sealAEAD
+func openAEAD(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	size := aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("sealed value too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
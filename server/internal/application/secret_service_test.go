@@ -0,0 +1,163 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+type mockSecretRepo struct {
+	secrets map[string]*entity.Secret
+}
+
+func newMockSecretRepo() *mockSecretRepo {
+	return &mockSecretRepo{secrets: make(map[string]*entity.Secret)}
+}
+
+func (m *mockSecretRepo) Save(ctx context.Context, secret *entity.Secret) error {
+	m.secrets[secret.Name] = secret
+	return nil
+}
+
+func (m *mockSecretRepo) FindByName(ctx context.Context, name string) (*entity.Secret, error) {
+	if secret, ok := m.secrets[name]; ok {
+		return secret, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockSecretRepo) FindAll(ctx context.Context) ([]*entity.Secret, error) {
+	var secrets []*entity.Secret
+	for _, secret := range m.secrets {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets, nil
+}
+
+func (m *mockSecretRepo) Delete(ctx context.Context, name string) error {
+	delete(m.secrets, name)
+	return nil
+}
+
+func newTestSecretService(t *testing.T, passphrase string, repo *mockSecretRepo) *SecretService {
+	t.Helper()
+	key, err := NewLocalMasterKey(passphrase)
+	if err != nil {
+		t.Fatalf("NewLocalMasterKey failed: %v", err)
+	}
+	return NewSecretService(repo, key)
+}
+
+func TestSecretService_PutValue(t *testing.T) {
+	repo := newMockSecretRepo()
+	svc := newTestSecretService(t, "master-key", repo)
+	ctx := context.Background()
+
+	secret, err := svc.Put(ctx, "splunk-token", "Splunk HEC", "token-value", "admin-1")
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if secret.KeyID != svc.KeyID() || !strings.HasPrefix(secret.KeyID, "local:") ||
+		strings.Contains(string(secret.Ciphertext), "token-value") || len(secret.DataKey) == 0 {
+		t.Fatalf("Expected the value sealed, got %+v", secret)
+	}
+	if value, err := svc.Value(ctx, "splunk-token"); err != nil || value != "token-value" {
+		t.Fatalf("Expected the value decrypted, got %q (%v)", value, err)
+	}
+
+	// Updating keeps the creation date and uses a new data key
+	created, dataKey := secret.CreatedAt, secret.DataKey
+	updated, err := svc.Put(ctx, "splunk-token", "", "rotated", "admin-2")
+	if err != nil || !updated.CreatedAt.Equal(created) || string(updated.DataKey) == string(dataKey) || updated.UpdatedBy != "admin-2" {
+		t.Fatalf("Unexpected update %+v (%v)", updated, err)
+	}
+	if value, _ := svc.Value(ctx, "splunk-token"); value != "rotated" {
+		t.Errorf("Expected the rotated value, got %q", value)
+	}
+
+	// Another master key cannot open the value
+	other := newTestSecretService(t, "other-key", repo)
+	if _, err := other.Value(ctx, "splunk-token"); err == nil || !strings.Contains(err.Error(), "master key") {
+		t.Errorf("Expected a master key mismatch, got %v", err)
+	}
+}
+
+func TestSecretService_Validation(t *testing.T) {
+	svc := newTestSecretService(t, "master-key", newMockSecretRepo())
+	ctx := context.Background()
+
+	for _, tc := range []struct{ name, value string }{
+		{"", "value"},
+		{"Upper", "value"},
+		{"with space", "value"},
+		{"-leading", "value"},
+		{"empty", ""},
+		{"too-large", strings.Repeat("x", maxSecretSize+1)},
+	} {
+		if _, err := svc.Put(ctx, tc.name, "", tc.value, ""); !errors.Is(err, ErrInvalidSecret) {
+			t.Errorf("Put(%q) expected ErrInvalidSecret, got %v", tc.name, err)
+		}
+	}
+	if _, err := svc.Value(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound, got %v", err)
+	}
+	if err := svc.Delete(ctx, "missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound, got %v", err)
+	}
+}
+
+func TestSecretService_Resolve(t *testing.T) {
+	svc := newTestSecretService(t, "master-key", newMockSecretRepo())
+	ctx := context.Background()
+	if _, err := svc.Put(ctx, "jira-token", "", "jira-value", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := svc.Resolve(ctx, "secret://jira-token"); err != nil || value != "jira-value" {
+		t.Errorf("Expected the reference resolved, got %q (%v)", value, err)
+	}
+	if value, err := svc.Resolve(ctx, "plain-value"); err != nil || value != "plain-value" {
+		t.Errorf("Expected a plain value as is, got %q (%v)", value, err)
+	}
+	if _, err := svc.Resolve(ctx, "secret://missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected ErrSecretNotFound, got %v", err)
+	}
+
+	if err := svc.Delete(ctx, "jira-token"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if secrets, _ := svc.List(ctx); len(secrets) != 0 {
+		t.Errorf("Expected the secret deleted, got %+v", secrets)
+	}
+}
+
+func TestSecretService_SealOpen(t *testing.T) {
+	svc := newTestSecretService(t, "master-key", newMockSecretRepo())
+	ctx := context.Background()
+
+	sealed, err := svc.Seal(ctx, "webhook-secret")
+	if err != nil || !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, "webhook-secret") {
+		t.Fatalf("Unexpected sealed value %q (%v)", sealed, err)
+	}
+	if again, _ := svc.Seal(ctx, sealed); again != sealed {
+		t.Error("Expected a sealed value not sealed twice")
+	}
+	if value, err := svc.Open(ctx, sealed); err != nil || value != "webhook-secret" {
+		t.Errorf("Expected the value opened, got %q (%v)", value, err)
+	}
+	if value, err := svc.Open(ctx, "legacy-plaintext"); err != nil || value != "legacy-plaintext" {
+		t.Errorf("Expected a plaintext value as is, got %q (%v)", value, err)
+	}
+	if empty, _ := svc.Seal(ctx, ""); empty != "" {
+		t.Errorf("Expected an empty value kept empty, got %q", empty)
+	}
+	if _, err := svc.Open(ctx, sealedPrefix+"not-base64!"); err == nil {
+		t.Error("Expected an invalid sealed value refused")
+	}
+}
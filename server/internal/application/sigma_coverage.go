@@ -0,0 +1,284 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// ErrTechniqueCatalogUnavailable is returned when a report needs the technique catalog but none is configured
+var ErrTechniqueCatalogUnavailable = errors.New("technique catalog not configured")
+
+// SetTechniqueRepository enables reports that need technique metadata, such as Sigma coverage
+func (s *AnalyticsService) SetTechniqueRepository(techniqueRepo repository.TechniqueRepository) {
+	s.techniqueRepo = techniqueRepo
+}
+
+// SigmaRuleCoverage reports how often a mapped Sigma rule fired
+type SigmaRuleCoverage struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	Fired int    `json:"fired"` // Results whose detection matched this rule
+}
+
+// TechniqueSigmaCoverage is the Sigma coverage of a single executed technique
+type TechniqueSigmaCoverage struct {
+	TechniqueID   string              `json:"technique_id"`
+	Name          string              `json:"name,omitempty"`
+	Tactic        entity.TacticType   `json:"tactic,omitempty"`
+	Executions    int                 `json:"executions"`     // Results recorded in the period
+	Detected      int                 `json:"detected"`       // Results detected by any source
+	SigmaDetected int                 `json:"sigma_detected"` // Results detected by a mapped Sigma rule
+	Coverage      float64             `json:"coverage"`       // Percentage of mapped rules that fired at least once
+	Rules         []SigmaRuleCoverage `json:"rules"`
+}
+
+// TacticSigmaCoverage aggregates Sigma coverage per tactic, one cell of the heatmap
+type TacticSigmaCoverage struct {
+	Tactic      entity.TacticType `json:"tactic"`
+	Techniques  int               `json:"techniques"`
+	MappedRules int               `json:"mapped_rules"`
+	FiredRules  int               `json:"fired_rules"`
+	Coverage    float64           `json:"coverage"`
+}
+
+// ParentSigmaCoverage rolls up the Sigma coverage of a technique and its
+// executed sub-techniques
+type ParentSigmaCoverage struct {
+	TechniqueID   string            `json:"technique_id"`
+	Name          string            `json:"name,omitempty"`
+	Tactic        entity.TacticType `json:"tactic,omitempty"`
+	SubTechniques []string          `json:"sub_techniques"` // Executed sub-techniques
+	Executions    int               `json:"executions"`
+	Detected      int               `json:"detected"`
+	SigmaDetected int               `json:"sigma_detected"`
+	MappedRules   int               `json:"mapped_rules"`
+	FiredRules    int               `json:"fired_rules"`
+	Coverage      float64           `json:"coverage"`
+}
+
+// SigmaCoverageReport shows which executed techniques had their Sigma detections fire
+type SigmaCoverageReport struct {
+	Period             string                   `json:"period"`
+	ExecutedTechniques int                      `json:"executed_techniques"`
+	MappedTechniques   int                      `json:"mapped_techniques"`
+	TotalRules         int                      `json:"total_rules"`
+	FiredRules         int                      `json:"fired_rules"`
+	Coverage           float64                  `json:"coverage"`
+	UnmappedTechniques []string                 `json:"unmapped_techniques"` // Executed but without any Sigma rule
+	ByTactic           []TacticSigmaCoverage    `json:"by_tactic"`
+	ByParent           []ParentSigmaCoverage    `json:"by_parent"` // Sub-techniques rolled up into their parent
+	Techniques         []TechniqueSigmaCoverage `json:"techniques"`
+}
+
+// GetSigmaCoverage reports, for the techniques executed over the last days, which
+// mapped Sigma rules fired. A rule fires when a result's detected_by mentions its
+// ID or title, which is how SIEM connectors record the matching alert rule.
+func (s *AnalyticsService) GetSigmaCoverage(ctx context.Context, days int) (*SigmaCoverageReport, error) {
+	if s.techniqueRepo == nil {
+		return nil, ErrTechniqueCatalogUnavailable
+	}
+
+	now := time.Now()
+	executions, err := s.resultRepo.FindExecutionsByDateRange(ctx, now.AddDate(0, 0, -days), now)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*entity.ExecutionResult
+	for _, exec := range executions {
+		execResults, err := s.resultRepo.FindResultsByExecution(ctx, exec.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load results for execution %s: %w", exec.ID, err)
+		}
+		results = append(results, execResults...)
+	}
+
+	techniques, err := s.techniqueRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load techniques: %w", err)
+	}
+
+	report := computeSigmaCoverage(techniques, results)
+	report.Period = fmt.Sprintf("%dd", days)
+	return report, nil
+}
+
+// computeSigmaCoverage builds the report from the catalog and the period's results
+func computeSigmaCoverage(techniques []*entity.Technique, results []*entity.ExecutionResult) *SigmaCoverageReport {
+	catalog := make(map[string]*entity.Technique, len(techniques))
+	for _, t := range techniques {
+		catalog[t.ID] = t
+	}
+
+	byTechnique := make(map[string]*TechniqueSigmaCoverage)
+	for _, result := range results {
+		tc, ok := byTechnique[result.TechniqueID]
+		if !ok {
+			tc = newTechniqueSigmaCoverage(result.TechniqueID, catalog[result.TechniqueID])
+			byTechnique[result.TechniqueID] = tc
+		}
+
+		tc.Executions++
+		if result.Detected {
+			tc.Detected++
+		}
+		if result.DetectedBy == "" {
+			continue
+		}
+
+		detectedBy := strings.ToLower(result.DetectedBy)
+		matched := false
+		for i := range tc.Rules {
+			if sigmaRuleMatches(tc.Rules[i], detectedBy) {
+				tc.Rules[i].Fired++
+				matched = true
+			}
+		}
+		if matched {
+			tc.SigmaDetected++
+		}
+	}
+
+	report := &SigmaCoverageReport{
+		UnmappedTechniques: []string{},
+		ByTactic:           []TacticSigmaCoverage{},
+		ByParent:           []ParentSigmaCoverage{},
+		Techniques:         make([]TechniqueSigmaCoverage, 0, len(byTechnique)),
+	}
+	tactics := make(map[entity.TacticType]*TacticSigmaCoverage)
+	parents := make(map[string]*ParentSigmaCoverage)
+
+	for _, tc := range byTechnique {
+		fired := 0
+		for _, rule := range tc.Rules {
+			if rule.Fired > 0 {
+				fired++
+			}
+		}
+		tc.Coverage = percentage(fired, len(tc.Rules))
+
+		report.ExecutedTechniques++
+		if len(tc.Rules) == 0 {
+			report.UnmappedTechniques = append(report.UnmappedTechniques, tc.TechniqueID)
+		} else {
+			report.MappedTechniques++
+		}
+		report.TotalRules += len(tc.Rules)
+		report.FiredRules += fired
+
+		// A technique spanning several tactics counts in each of their cells
+		for _, name := range sigmaCoverageTactics(tc, catalog) {
+			tactic, ok := tactics[name]
+			if !ok {
+				tactic = &TacticSigmaCoverage{Tactic: name}
+				tactics[name] = tactic
+			}
+			tactic.Techniques++
+			tactic.MappedRules += len(tc.Rules)
+			tactic.FiredRules += fired
+		}
+
+		parent := parentSigmaCoverage(parents, tc.TechniqueID, catalog)
+		if parent.TechniqueID != tc.TechniqueID {
+			parent.SubTechniques = append(parent.SubTechniques, tc.TechniqueID)
+		}
+		parent.Executions += tc.Executions
+		parent.Detected += tc.Detected
+		parent.SigmaDetected += tc.SigmaDetected
+		parent.MappedRules += len(tc.Rules)
+		parent.FiredRules += fired
+
+		report.Techniques = append(report.Techniques, *tc)
+	}
+
+	for _, tactic := range tactics {
+		tactic.Coverage = percentage(tactic.FiredRules, tactic.MappedRules)
+		report.ByTactic = append(report.ByTactic, *tactic)
+	}
+	for _, parent := range parents {
+		parent.Coverage = percentage(parent.FiredRules, parent.MappedRules)
+		sort.Strings(parent.SubTechniques)
+		report.ByParent = append(report.ByParent, *parent)
+	}
+	report.Coverage = percentage(report.FiredRules, report.TotalRules)
+
+	sort.Strings(report.UnmappedTechniques)
+	sort.Slice(report.ByTactic, func(i, j int) bool { return report.ByTactic[i].Tactic < report.ByTactic[j].Tactic })
+	sort.Slice(report.ByParent, func(i, j int) bool { return report.ByParent[i].TechniqueID < report.ByParent[j].TechniqueID })
+	sort.Slice(report.Techniques, func(i, j int) bool { return report.Techniques[i].TechniqueID < report.Techniques[j].TechniqueID })
+
+	return report
+}
+
+// sigmaCoverageTactics returns the heatmap cells an executed technique counts in
+func sigmaCoverageTactics(tc *TechniqueSigmaCoverage, catalog map[string]*entity.Technique) []entity.TacticType {
+	if technique := catalog[tc.TechniqueID]; technique != nil && len(technique.AllTactics()) > 0 {
+		return technique.AllTactics()
+	}
+	return []entity.TacticType{tc.Tactic}
+}
+
+// parentSigmaCoverage returns the roll-up an executed technique counts in: its
+// parent's for a sub-technique, its own otherwise
+func parentSigmaCoverage(parents map[string]*ParentSigmaCoverage, id string, catalog map[string]*entity.Technique) *ParentSigmaCoverage {
+	parentID := ""
+	if technique := catalog[id]; technique != nil {
+		parentID = technique.ParentID
+	}
+	if parentID == "" {
+		parentID = entity.ParentTechniqueID(id)
+	}
+	if parentID == "" {
+		parentID = id
+	}
+
+	parent, ok := parents[parentID]
+	if !ok {
+		parent = &ParentSigmaCoverage{TechniqueID: parentID, SubTechniques: []string{}}
+		if technique := catalog[parentID]; technique != nil {
+			parent.Name = technique.Name
+			parent.Tactic = technique.Tactic
+		} else if technique := catalog[id]; technique != nil {
+			parent.Tactic = technique.Tactic
+		}
+		parents[parentID] = parent
+	}
+	return parent
+}
+
+func newTechniqueSigmaCoverage(id string, technique *entity.Technique) *TechniqueSigmaCoverage {
+	tc := &TechniqueSigmaCoverage{TechniqueID: id, Rules: []SigmaRuleCoverage{}}
+	if technique == nil {
+		return tc
+	}
+	tc.Name = technique.Name
+	tc.Tactic = technique.Tactic
+	for _, rule := range technique.SigmaRules {
+		tc.Rules = append(tc.Rules, SigmaRuleCoverage{ID: rule.ID, Title: rule.Title})
+	}
+	return tc
+}
+
+// sigmaRuleMatches reports whether a lowercased detected_by value references the rule
+func sigmaRuleMatches(rule SigmaRuleCoverage, detectedBy string) bool {
+	if rule.ID != "" && strings.Contains(detectedBy, strings.ToLower(rule.ID)) {
+		return true
+	}
+	return rule.Title != "" && strings.Contains(detectedBy, strings.ToLower(rule.Title))
+}
+
+// percentage returns part/total as a percentage rounded to one decimal, 0 when total is 0
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*1000) / 10
+}
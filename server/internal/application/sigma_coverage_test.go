@@ -0,0 +1,198 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func setupSigmaCoverageTest() (*AnalyticsService, *mockResultRepo, *mockTechniqueRepo) {
+	resultRepo := newMockResultRepo()
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1059.001"] = &entity.Technique{
+		ID: "T1059.001", Name: "PowerShell", Tactic: entity.TacticExecution,
+		SigmaRules: []entity.SigmaRule{
+			{ID: "5b3c7ab6-0001", Title: "Suspicious PowerShell Invocation"},
+			{ID: "5b3c7ab6-0002", Title: "PowerShell Download Cradle"},
+		},
+	}
+	techniqueRepo.techniques["T1003.001"] = &entity.Technique{
+		ID: "T1003.001", Name: "LSASS Memory", Tactic: entity.TacticCredentialAccess,
+		SigmaRules: []entity.SigmaRule{{ID: "9a1b0c2d-0003"}},
+	}
+	techniqueRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery}
+
+	svc := NewAnalyticsService(resultRepo)
+	svc.SetTechniqueRepository(techniqueRepo)
+	return svc, resultRepo, techniqueRepo
+}
+
+func TestGetSigmaCoverage(t *testing.T) {
+	svc, resultRepo, _ := setupSigmaCoverageTest()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", StartedAt: time.Now().Add(-time.Hour)}
+	resultRepo.executions["exec-old"] = &entity.Execution{ID: "exec-old", StartedAt: time.Now().AddDate(0, 0, -60)}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", TechniqueID: "T1059.001", Status: entity.StatusDetected, Detected: true, DetectedBy: "splunk: suspicious powershell invocation"},
+		{ID: "r2", TechniqueID: "T1059.001", Status: entity.StatusSuccess},
+		{ID: "r3", TechniqueID: "T1003.001", Status: entity.StatusDetected, Detected: true, DetectedBy: "elastic: Credential Dumping [9A1B0C2D-0003]"},
+		{ID: "r4", TechniqueID: "T1082", Status: entity.StatusDetected, Detected: true, DetectedBy: "sentinel: Recon"},
+		{ID: "r5", TechniqueID: "T9999", Status: entity.StatusSuccess},
+	}
+	resultRepo.results["exec-old"] = []*entity.ExecutionResult{
+		{ID: "r6", TechniqueID: "T1059.001", Detected: true, DetectedBy: "splunk: PowerShell Download Cradle"},
+	}
+
+	report, err := svc.GetSigmaCoverage(context.Background(), 30)
+	if err != nil {
+		t.Fatalf("GetSigmaCoverage failed: %v", err)
+	}
+
+	if report.Period != "30d" {
+		t.Errorf("Period = %q, want 30d", report.Period)
+	}
+	if report.ExecutedTechniques != 4 || report.MappedTechniques != 2 {
+		t.Errorf("executed=%d mapped=%d, want 4 and 2", report.ExecutedTechniques, report.MappedTechniques)
+	}
+	if report.TotalRules != 3 || report.FiredRules != 2 {
+		t.Errorf("rules=%d fired=%d, want 3 and 2", report.TotalRules, report.FiredRules)
+	}
+	if report.Coverage != 66.7 {
+		t.Errorf("Coverage = %v, want 66.7", report.Coverage)
+	}
+	if len(report.UnmappedTechniques) != 2 || report.UnmappedTechniques[0] != "T1082" || report.UnmappedTechniques[1] != "T9999" {
+		t.Errorf("UnmappedTechniques = %v, want [T1082 T9999]", report.UnmappedTechniques)
+	}
+
+	powershell := report.Techniques[1]
+	if powershell.TechniqueID != "T1059.001" {
+		t.Fatalf("Techniques not sorted by ID: %+v", report.Techniques)
+	}
+	if powershell.Executions != 2 || powershell.Detected != 1 || powershell.SigmaDetected != 1 {
+		t.Errorf("PowerShell counts = %+v", powershell)
+	}
+	if powershell.Rules[0].Fired != 1 || powershell.Rules[1].Fired != 0 || powershell.Coverage != 50 {
+		t.Errorf("PowerShell rules = %+v coverage=%v", powershell.Rules, powershell.Coverage)
+	}
+
+	tactics := make(map[entity.TacticType]TacticSigmaCoverage)
+	for _, tc := range report.ByTactic {
+		tactics[tc.Tactic] = tc
+	}
+	if got := tactics[entity.TacticCredentialAccess]; got.Coverage != 100 || got.FiredRules != 1 {
+		t.Errorf("credential-access cell = %+v", got)
+	}
+	if got := tactics[entity.TacticDiscovery]; got.MappedRules != 0 || got.Coverage != 0 {
+		t.Errorf("discovery cell = %+v", got)
+	}
+	if _, ok := tactics[""]; !ok {
+		t.Error("Expected unknown techniques to be grouped under an empty tactic")
+	}
+}
+
+func TestGetSigmaCoverage_RollsUpSubTechniques(t *testing.T) {
+	svc, resultRepo, techniqueRepo := setupSigmaCoverageTest()
+	techniqueRepo.techniques["T1059"] = &entity.Technique{
+		ID: "T1059", Name: "Command and Scripting Interpreter", Tactic: entity.TacticExecution,
+		SigmaRules: []entity.SigmaRule{{ID: "parent-rule"}},
+	}
+	techniqueRepo.techniques["T1059.003"] = &entity.Technique{
+		ID: "T1059.003", ParentID: "T1059", Name: "Windows Command Shell", Tactic: entity.TacticExecution,
+	}
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", StartedAt: time.Now().Add(-time.Hour)}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", TechniqueID: "T1059.001", Detected: true, DetectedBy: "splunk: PowerShell Download Cradle"},
+		{ID: "r2", TechniqueID: "T1059.003", Detected: true, DetectedBy: "edr"},
+		{ID: "r3", TechniqueID: "T1059", DetectedBy: "parent-rule"},
+		{ID: "r4", TechniqueID: "T1082"},
+	}
+
+	report, err := svc.GetSigmaCoverage(context.Background(), 30)
+	if err != nil {
+		t.Fatalf("GetSigmaCoverage failed: %v", err)
+	}
+
+	if len(report.ByParent) != 2 {
+		t.Fatalf("Expected 2 roll-ups, got %+v", report.ByParent)
+	}
+	interpreter := report.ByParent[0]
+	if interpreter.TechniqueID != "T1059" || interpreter.Name != "Command and Scripting Interpreter" ||
+		len(interpreter.SubTechniques) != 2 || interpreter.SubTechniques[0] != "T1059.001" {
+		t.Errorf("Unexpected roll-up: %+v", interpreter)
+	}
+	if interpreter.Executions != 3 || interpreter.Detected != 2 || interpreter.SigmaDetected != 2 ||
+		interpreter.MappedRules != 3 || interpreter.FiredRules != 2 || interpreter.Coverage != 66.7 {
+		t.Errorf("Unexpected roll-up counts: %+v", interpreter)
+	}
+	if discovery := report.ByParent[1]; discovery.TechniqueID != "T1082" || len(discovery.SubTechniques) != 0 {
+		t.Errorf("Expected a technique without parent to roll up into itself, got %+v", discovery)
+	}
+}
+
+func TestGetSigmaCoverage_NoResults(t *testing.T) {
+	svc, _, _ := setupSigmaCoverageTest()
+
+	report, err := svc.GetSigmaCoverage(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("GetSigmaCoverage failed: %v", err)
+	}
+	if report.ExecutedTechniques != 0 || report.Coverage != 0 {
+		t.Errorf("Expected empty report, got %+v", report)
+	}
+	if report.Techniques == nil || report.ByTactic == nil || report.ByParent == nil || report.UnmappedTechniques == nil {
+		t.Error("Expected empty slices rather than nil")
+	}
+}
+
+func TestGetSigmaCoverage_NoTechniqueRepository(t *testing.T) {
+	svc := NewAnalyticsService(newMockResultRepo())
+
+	_, err := svc.GetSigmaCoverage(context.Background(), 30)
+	if !errors.Is(err, ErrTechniqueCatalogUnavailable) {
+		t.Errorf("Expected ErrTechniqueCatalogUnavailable, got %v", err)
+	}
+}
+
+func TestGetSigmaCoverage_RepositoryErrors(t *testing.T) {
+	svc, resultRepo, techniqueRepo := setupSigmaCoverageTest()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", StartedAt: time.Now()}
+
+	resultRepo.findResultsErr = errors.New("results unavailable")
+	if _, err := svc.GetSigmaCoverage(context.Background(), 30); err == nil {
+		t.Error("Expected error when results cannot be loaded")
+	}
+	resultRepo.findResultsErr = nil
+
+	techniqueRepo.err = errors.New("catalog unavailable")
+	if _, err := svc.GetSigmaCoverage(context.Background(), 30); err == nil {
+		t.Error("Expected error when techniques cannot be loaded")
+	}
+
+	resultRepo.err = errors.New("db down")
+	if _, err := svc.GetSigmaCoverage(context.Background(), 30); err == nil {
+		t.Error("Expected error when executions cannot be loaded")
+	}
+}
+
+func TestSigmaRuleMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       SigmaRuleCoverage
+		detectedBy string
+		want       bool
+	}{
+		{"by id", SigmaRuleCoverage{ID: "abc-123"}, "splunk: rule abc-123", true},
+		{"by title", SigmaRuleCoverage{ID: "x", Title: "Mimikatz Use"}, "elastic: mimikatz use", true},
+		{"no match", SigmaRuleCoverage{ID: "x", Title: "Other"}, "elastic: mimikatz use", false},
+		{"empty rule", SigmaRuleCoverage{}, "anything", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sigmaRuleMatches(tt.rule, tt.detectedBy); got != tt.want {
+				t.Errorf("sigmaRuleMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"autostrike/internal/domain/entity"
+)
+
+var (
+	// ErrTechniqueNotFound is returned when the requested technique does not exist
+	ErrTechniqueNotFound = errors.New("technique not found")
+	// ErrInvalidTechniqueStatus is returned for an unknown lifecycle state
+	ErrInvalidTechniqueStatus = errors.New("invalid technique status")
+	// ErrInvalidTechniqueTransition is returned when a lifecycle change is not allowed
+	ErrInvalidTechniqueTransition = errors.New("invalid technique status transition")
+)
+
+// validateTechniqueStatus accepts an empty status, which keeps or defaults to active
+func validateTechniqueStatus(status entity.TechniqueStatus) error {
+	if status != "" && !status.IsValid() {
+		return fmt.Errorf("%w: %s", ErrInvalidTechniqueStatus, status)
+	}
+	return nil
+}
+
+// SetTechniqueStatus moves a technique to another lifecycle state
+func (s *TechniqueService) SetTechniqueStatus(
+	ctx context.Context,
+	id string,
+	status entity.TechniqueStatus,
+) (*entity.Technique, error) {
+	if !status.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTechniqueStatus, status)
+	}
+
+	technique, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTechniqueNotFound, err)
+	}
+
+	current := technique.LifecycleStatus()
+	if !current.CanTransitionTo(status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTechniqueTransition, current, status)
+	}
+
+	technique.Status = status
+	if err := s.repo.Update(ctx, technique); err != nil {
+		return nil, fmt.Errorf("failed to update technique: %w", err)
+	}
+	return technique, nil
+}
+
+// FlaggedTechnique is a deprecated or broken technique still used by a scenario
+type FlaggedTechnique struct {
+	ID     string                 `json:"id"`
+	Name   string                 `json:"name"`
+	Status entity.TechniqueStatus `json:"status"`
+}
+
+// ScenarioLifecycleIssue lists the retired techniques a scenario still uses
+type ScenarioLifecycleIssue struct {
+	ScenarioID   string             `json:"scenario_id"`
+	ScenarioName string             `json:"scenario_name"`
+	Techniques   []FlaggedTechnique `json:"techniques"`
+}
+
+// GetLifecycleReport lists the scenarios that use deprecated or broken techniques
+// and need to be updated, sorted by scenario name
+func (s *ScenarioService) GetLifecycleReport(ctx context.Context) ([]ScenarioLifecycleIssue, error) {
+	scenarios, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scenarios: %w", err)
+	}
+	techniques, err := s.techRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load techniques: %w", err)
+	}
+
+	retired := make(map[string]*entity.Technique)
+	for _, t := range techniques {
+		if t.IsRetired() {
+			retired[t.ID] = t
+		}
+	}
+
+	issues := []ScenarioLifecycleIssue{}
+	for _, scenario := range scenarios {
+		var flagged []FlaggedTechnique
+		seen := make(map[string]bool)
+		for _, id := range scenario.GetAllTechniques() {
+			technique, ok := retired[id]
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			flagged = append(flagged, FlaggedTechnique{
+				ID:     technique.ID,
+				Name:   technique.Name,
+				Status: technique.LifecycleStatus(),
+			})
+		}
+		if len(flagged) > 0 {
+			issues = append(issues, ScenarioLifecycleIssue{
+				ScenarioID:   scenario.ID,
+				ScenarioName: scenario.Name,
+				Techniques:   flagged,
+			})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].ScenarioName < issues[j].ScenarioName })
+	return issues, nil
+}
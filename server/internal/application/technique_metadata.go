@@ -0,0 +1,85 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+)
+
+// Limits on the custom fields of a technique
+const (
+	maxMetadataFields     = 50
+	maxMetadataValueBytes = 1024
+)
+
+// ErrInvalidTechniqueMetadata is returned for a custom field with an invalid name or value
+var ErrInvalidTechniqueMetadata = errors.New("invalid technique metadata")
+
+// metadataFieldName matches a custom field name: lowercase, digits and underscores
+var metadataFieldName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// validateTechniqueMetadata checks the number, names and sizes of custom fields
+func validateTechniqueMetadata(metadata entity.Metadata) error {
+	if len(metadata) > maxMetadataFields {
+		return fmt.Errorf("%w: at most %d fields", ErrInvalidTechniqueMetadata, maxMetadataFields)
+	}
+	for field, value := range metadata {
+		if !metadataFieldName.MatchString(field) {
+			return fmt.Errorf("%w: field %q must be lowercase letters, digits and underscores", ErrInvalidTechniqueMetadata, field)
+		}
+		if len(value) > maxMetadataValueBytes {
+			return fmt.Errorf("%w: field %s is longer than %d bytes", ErrInvalidTechniqueMetadata, field, maxMetadataValueBytes)
+		}
+	}
+	return validateRiskOverrides(metadata)
+}
+
+// validateRiskOverrides checks the severity and impact overrides of custom fields
+func validateRiskOverrides(metadata entity.Metadata) error {
+	if severity, ok := metadata[entity.SeverityMetadataKey]; ok && !entity.IsValidSeverity(strings.ToLower(strings.TrimSpace(severity))) {
+		return fmt.Errorf("%w: severity must be low, medium, high or critical", ErrInvalidTechniqueMetadata)
+	}
+	if value, ok := metadata[entity.ImpactMetadataKey]; ok {
+		impact, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || impact <= 0 || impact > entity.MaxTechniqueImpact {
+			return fmt.Errorf("%w: impact must be a number from 0.1 to 10", ErrInvalidTechniqueMetadata)
+		}
+	}
+	return nil
+}
+
+// SetTechniqueMetadata replaces the custom fields of a technique. An empty map
+// removes them all. Catalog re-imports keep the fields set here. The severity
+// and impact fields override the assessed ones; removing an override estimates
+// them again until the technique is re-imported.
+func (s *TechniqueService) SetTechniqueMetadata(
+	ctx context.Context,
+	id string,
+	metadata entity.Metadata,
+) (*entity.Technique, error) {
+	if err := validateTechniqueMetadata(metadata); err != nil {
+		return nil, err
+	}
+
+	technique, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTechniqueNotFound, err)
+	}
+
+	if metadata == nil {
+		metadata = entity.Metadata{}
+	}
+	if technique.HasRiskOverride() {
+		technique.Severity, technique.Impact = "", 0
+	}
+	technique.Metadata = metadata
+	if err := s.repo.Update(ctx, technique); err != nil {
+		return nil, fmt.Errorf("failed to update technique: %w", err)
+	}
+	return technique, nil
+}
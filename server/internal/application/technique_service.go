@@ -2,11 +2,35 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
 
 	"autostrike/internal/domain/entity"
 	"autostrike/internal/domain/repository"
+	"autostrike/internal/domain/service"
 )
 
+// ErrInvalidResourceLimits is returned when an executor sets a negative resource limit
+var ErrInvalidResourceLimits = errors.New("invalid executor resource limits")
+
+// ErrInvalidFactParser is returned when an executor has an invalid fact parser
+var ErrInvalidFactParser = errors.New("invalid fact parser")
+
+// ErrInvalidCommandVariant is returned for a command variant of a platform the
+// technique does not run on, or without a command
+var ErrInvalidCommandVariant = errors.New("invalid command variant")
+
+// ErrInvalidTechniqueParent is returned when a technique's parent_id is not the technique its ID extends
+var ErrInvalidTechniqueParent = errors.New("invalid parent technique")
+
+// ErrInvalidTechniqueDomain is returned for a domain other than the ATT&CK ones
+var ErrInvalidTechniqueDomain = errors.New("invalid ATT&CK domain")
+
+// ErrInvalidTechniqueRisk is returned for an unknown severity or an impact out of the 0-10 scale
+var ErrInvalidTechniqueRisk = errors.New("invalid technique severity or impact")
+
 // TechniqueService handles technique-related business logic
 type TechniqueService struct {
 	repo repository.TechniqueRepository
@@ -44,16 +68,26 @@ func (s *TechniqueService) ImportTechniques(ctx context.Context, path string) er
 
 // CreateTechnique creates a new technique
 func (s *TechniqueService) CreateTechnique(ctx context.Context, technique *entity.Technique) error {
+	if err := validateTechnique(technique); err != nil {
+		return err
+	}
 	return s.repo.Create(ctx, technique)
 }
 
-// UpdateTechnique updates an existing technique
+// UpdateTechnique updates an existing technique. An empty parent keeps the
+// stored one.
 func (s *TechniqueService) UpdateTechnique(ctx context.Context, technique *entity.Technique) error {
+	if err := validateTechnique(technique); err != nil {
+		return err
+	}
 	return s.repo.Update(ctx, technique)
 }
 
-// DeleteTechnique deletes a technique
+// DeleteTechnique moves a technique to the trash
 func (s *TechniqueService) DeleteTechnique(ctx context.Context, id string) error {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return fmt.Errorf("%w: %v", ErrTechniqueNotFound, err)
+	}
 	return s.repo.Delete(ctx, id)
 }
 
@@ -71,3 +105,52 @@ func (s *TechniqueService) GetCoverage(ctx context.Context) (map[entity.TacticTy
 
 	return coverage, nil
 }
+
+// setTechniqueParent derives the parent of an ATT&CK sub-technique from its ID,
+// for the YAML content files listing sub-techniques by ID only. Imports take
+// the parent from their content, such as the subtechnique-of relationships of
+// ATT&CK STIX bundles.
+func setTechniqueParent(technique *entity.Technique) {
+	if technique.ParentID == "" {
+		technique.ParentID = entity.ParentTechniqueID(technique.ID)
+	}
+}
+
+// validateTechnique checks the fields the repository stores as-is
+func validateTechnique(technique *entity.Technique) error {
+	if err := validateTechniqueStatus(technique.Status); err != nil {
+		return err
+	}
+	if err := validateTechniqueMetadata(technique.Metadata); err != nil {
+		return err
+	}
+	if technique.Severity != "" && !entity.IsValidSeverity(strings.ToLower(technique.Severity)) {
+		return fmt.Errorf("%w: severity %q", ErrInvalidTechniqueRisk, technique.Severity)
+	}
+	if !entity.IsValidImpact(technique.Impact) {
+		return fmt.Errorf("%w: impact %v", ErrInvalidTechniqueRisk, technique.Impact)
+	}
+	if technique.Domain != "" && !entity.IsValidDomain(technique.Domain) {
+		return fmt.Errorf("%w: %q", ErrInvalidTechniqueDomain, technique.Domain)
+	}
+	// A sub-technique ID extends its parent's ("T1059.001" of "T1059")
+	if technique.ParentID != "" && !strings.HasPrefix(technique.ID, technique.ParentID+".") {
+		return fmt.Errorf("%w: %s is not a sub-technique of %s", ErrInvalidTechniqueParent, technique.ID, technique.ParentID)
+	}
+	for _, executor := range technique.Executors {
+		if !executor.Limits.IsValid() {
+			return fmt.Errorf("%w: %s executor", ErrInvalidResourceLimits, executor.Type)
+		}
+		for _, parser := range executor.Parsers {
+			if err := service.ValidateFactParser(parser); err != nil {
+				return fmt.Errorf("%w: %s executor: %v", ErrInvalidFactParser, executor.Type, err)
+			}
+		}
+		for platform, variant := range executor.Variants {
+			if !slices.Contains(technique.Platforms, platform) || strings.TrimSpace(variant.Command) == "" {
+				return fmt.Errorf("%w: %s executor on %s", ErrInvalidCommandVariant, executor.Type, platform)
+			}
+		}
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package application
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"autostrike/internal/domain/entity"
@@ -228,6 +230,15 @@ func TestDeleteTechnique(t *testing.T) {
 	}
 }
 
+func TestDeleteTechnique_NotFound(t *testing.T) {
+	service := NewTechniqueService(newMockTechniqueRepo())
+
+	err := service.DeleteTechnique(context.Background(), "T9999")
+	if !errors.Is(err, ErrTechniqueNotFound) {
+		t.Fatalf("Expected ErrTechniqueNotFound, got %v", err)
+	}
+}
+
 func TestGetCoverage(t *testing.T) {
 	repo := newMockTechniqueRepo()
 	repo.techniques["T1"] = &entity.Technique{ID: "T1", Tactic: entity.TacticExecution}
@@ -257,3 +268,255 @@ func TestGetCoverage_Error(t *testing.T) {
 		t.Fatal("Expected error")
 	}
 }
+
+func TestCreateTechnique_InvalidStatus(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	service := NewTechniqueService(repo)
+
+	err := service.CreateTechnique(context.Background(), &entity.Technique{ID: "T1059", Status: "retired"})
+	if !errors.Is(err, ErrInvalidTechniqueStatus) {
+		t.Errorf("Expected ErrInvalidTechniqueStatus, got %v", err)
+	}
+	if err := service.UpdateTechnique(context.Background(), &entity.Technique{ID: "T1059", Status: "retired"}); !errors.Is(err, ErrInvalidTechniqueStatus) {
+		t.Errorf("Expected ErrInvalidTechniqueStatus on update, got %v", err)
+	}
+}
+
+func TestSetTechniqueStatus(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059"}
+	service := NewTechniqueService(repo)
+
+	tech, err := service.SetTechniqueStatus(context.Background(), "T1059", entity.TechniqueDeprecated)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tech.Status != entity.TechniqueDeprecated || repo.techniques["T1059"].Status != entity.TechniqueDeprecated {
+		t.Errorf("Expected technique to be deprecated, got %s", tech.Status)
+	}
+
+	if _, err := service.SetTechniqueStatus(context.Background(), "T1059", entity.TechniqueActive); err != nil {
+		t.Errorf("Expected deprecated technique to be reactivated, got %v", err)
+	}
+}
+
+func TestSetTechniqueStatus_Errors(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059", Status: entity.TechniqueDraft}
+	service := NewTechniqueService(repo)
+
+	tests := []struct {
+		name   string
+		id     string
+		status entity.TechniqueStatus
+		want   error
+	}{
+		{"unknown status", "T1059", "retired", ErrInvalidTechniqueStatus},
+		{"missing technique", "T9999", entity.TechniqueActive, ErrTechniqueNotFound},
+		{"draft cannot be deprecated", "T1059", entity.TechniqueDeprecated, ErrInvalidTechniqueTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SetTechniqueStatus(context.Background(), tt.id, tt.status)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSetTechniqueMetadata(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059"}
+	service := NewTechniqueService(repo)
+
+	tech, err := service.SetTechniqueMetadata(context.Background(), "T1059", entity.Metadata{"owner_team": "red", "risk_rating": "high"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tech.Metadata["owner_team"] != "red" || repo.techniques["T1059"].Metadata["risk_rating"] != "high" {
+		t.Errorf("Expected the custom fields to be stored, got %v", tech.Metadata)
+	}
+
+	tech, err = service.SetTechniqueMetadata(context.Background(), "T1059", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tech.Metadata == nil || len(tech.Metadata) != 0 {
+		t.Errorf("Expected the custom fields to be removed, got %v", tech.Metadata)
+	}
+}
+
+func TestSetTechniqueMetadata_Errors(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059"}
+	service := NewTechniqueService(repo)
+
+	tooMany := entity.Metadata{}
+	for i := 0; i <= maxMetadataFields; i++ {
+		tooMany[fmt.Sprintf("field_%d", i)] = "x"
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		metadata entity.Metadata
+		want     error
+	}{
+		{"invalid field name", "T1059", entity.Metadata{"Owner Team": "red"}, ErrInvalidTechniqueMetadata},
+		{"value too long", "T1059", entity.Metadata{"notes": strings.Repeat("x", maxMetadataValueBytes+1)}, ErrInvalidTechniqueMetadata},
+		{"too many fields", "T1059", tooMany, ErrInvalidTechniqueMetadata},
+		{"unknown severity override", "T1059", entity.Metadata{"severity": "severe"}, ErrInvalidTechniqueMetadata},
+		{"impact override out of range", "T1059", entity.Metadata{"impact": "11"}, ErrInvalidTechniqueMetadata},
+		{"missing technique", "T9999", entity.Metadata{"owner_team": "red"}, ErrTechniqueNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SetTechniqueMetadata(context.Background(), tt.id, tt.metadata)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestSetTechniqueMetadata_RiskOverride(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1082"] = &entity.Technique{ID: "T1082", Severity: entity.TechniqueSeverityLow, Impact: 3}
+	service := NewTechniqueService(repo)
+	ctx := context.Background()
+
+	tech, err := service.SetTechniqueMetadata(ctx, "T1082", entity.Metadata{"severity": "critical", "impact": "9.5"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tech.EffectiveSeverity() != entity.TechniqueSeverityCritical {
+		t.Errorf("Expected the severity override, got %q", tech.EffectiveSeverity())
+	}
+
+	// Removing the override drops the overridden values, estimated again when saved
+	tech, err = service.SetTechniqueMetadata(ctx, "T1082", entity.Metadata{"owner_team": "red"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tech.Severity != "" || tech.Impact != 0 {
+		t.Errorf("Expected the overridden severity and impact to be dropped, got %q %v", tech.Severity, tech.Impact)
+	}
+}
+
+func TestCreateTechnique_InvalidRisk(t *testing.T) {
+	service := NewTechniqueService(newMockTechniqueRepo())
+
+	tests := []struct {
+		name      string
+		technique *entity.Technique
+	}{
+		{"unknown severity", &entity.Technique{ID: "T1059", Severity: "severe"}},
+		{"negative impact", &entity.Technique{ID: "T1059", Impact: -1}},
+		{"impact above 10", &entity.Technique{ID: "T1059", Impact: 10.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.CreateTechnique(context.Background(), tt.technique); !errors.Is(err, ErrInvalidTechniqueRisk) {
+				t.Errorf("Expected ErrInvalidTechniqueRisk, got %v", err)
+			}
+		})
+	}
+
+	if err := service.CreateTechnique(context.Background(), &entity.Technique{ID: "T1082", Severity: "High", Impact: 7.5}); err != nil {
+		t.Errorf("Expected a valid severity and impact to be accepted, got %v", err)
+	}
+}
+
+func TestCreateTechnique_InvalidResourceLimits(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	service := NewTechniqueService(repo)
+
+	technique := &entity.Technique{
+		ID:        "T1059",
+		Executors: []entity.Executor{{Type: "sh", Command: "id", Limits: &entity.ResourceLimits{MemoryMB: -1}}},
+	}
+	if err := service.CreateTechnique(context.Background(), technique); !errors.Is(err, ErrInvalidResourceLimits) {
+		t.Errorf("Expected ErrInvalidResourceLimits, got %v", err)
+	}
+	if _, ok := repo.techniques["T1059"]; ok {
+		t.Error("Technique with invalid limits must not be stored")
+	}
+}
+
+func TestCreateTechnique_InvalidFactParser(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	service := NewTechniqueService(repo)
+
+	technique := &entity.Technique{
+		ID: "T1082",
+		Executors: []entity.Executor{{
+			Type:    "sh",
+			Command: "hostname",
+			Parsers: []entity.FactParser{{Fact: "hostname", Regex: "("}},
+		}},
+	}
+	if err := service.CreateTechnique(context.Background(), technique); !errors.Is(err, ErrInvalidFactParser) {
+		t.Errorf("Expected ErrInvalidFactParser, got %v", err)
+	}
+	if _, ok := repo.techniques["T1082"]; ok {
+		t.Error("Technique with an invalid parser must not be stored")
+	}
+}
+
+func TestCreateTechnique_InvalidCommandVariant(t *testing.T) {
+	service := NewTechniqueService(newMockTechniqueRepo())
+
+	variants := map[string]map[string]entity.CommandVariant{
+		"platform not supported": {"windows": {Command: "ver"}},
+		"no command":             {"darwin": {Cleanup: "rm -f /tmp/x"}},
+	}
+	for name, variant := range variants {
+		technique := &entity.Technique{
+			ID:        "T1082",
+			Platforms: []string{"linux", "darwin"},
+			Executors: []entity.Executor{{Type: "sh", Command: "uname -a", Variants: variant}},
+		}
+		if err := service.CreateTechnique(context.Background(), technique); !errors.Is(err, ErrInvalidCommandVariant) {
+			t.Errorf("%s: expected ErrInvalidCommandVariant, got %v", name, err)
+		}
+	}
+
+	technique := &entity.Technique{
+		ID:        "T1082",
+		Platforms: []string{"linux", "darwin"},
+		Executors: []entity.Executor{{Type: "sh", Command: "uname -a", Variants: map[string]entity.CommandVariant{"darwin": {Command: "sw_vers"}}}},
+	}
+	if err := service.CreateTechnique(context.Background(), technique); err != nil {
+		t.Errorf("Expected a variant of a supported platform to be accepted, got %v", err)
+	}
+}
+
+func TestCreateTechnique_ParentNotDerived(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	service := NewTechniqueService(repo)
+
+	// Only the YAML content files have their parents derived from the IDs
+	technique := &entity.Technique{ID: "T1059.001"}
+	if err := service.CreateTechnique(context.Background(), technique); err != nil {
+		t.Fatalf("CreateTechnique failed: %v", err)
+	}
+	if technique.ParentID != "" {
+		t.Errorf("Expected no parent, got %q", technique.ParentID)
+	}
+}
+
+func TestCreateTechnique_InvalidParent(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	service := NewTechniqueService(repo)
+
+	technique := &entity.Technique{ID: "T1059.001", ParentID: "T1003"}
+	if err := service.CreateTechnique(context.Background(), technique); !errors.Is(err, ErrInvalidTechniqueParent) {
+		t.Errorf("Expected ErrInvalidTechniqueParent, got %v", err)
+	}
+	if err := service.UpdateTechnique(context.Background(), technique); !errors.Is(err, ErrInvalidTechniqueParent) {
+		t.Errorf("Expected ErrInvalidTechniqueParent on update, got %v", err)
+	}
+}
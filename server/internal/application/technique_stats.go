@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// unknownPlatform groups the results of agents that no longer exist
+const unknownPlatform = "unknown"
+
+// TechniqueFailure is the latest run of a technique that did not complete normally
+type TechniqueFailure struct {
+	ResultID    string              `json:"result_id"`
+	ExecutionID string              `json:"execution_id"`
+	AgentPaw    string              `json:"agent_paw"`
+	Status      entity.ResultStatus `json:"status"`
+	At          time.Time           `json:"at"`
+}
+
+// TechniqueRunStats aggregates the runs of a technique. Runs are the results
+// that reached a final status other than skipped; rates are fractions of them.
+// Flakiness is the fraction of consecutive runs on the same agent whose
+// outcome changed, high for techniques whose result is not reproducible.
+type TechniqueRunStats struct {
+	Runs                  int               `json:"runs"`
+	SuccessRate           float64           `json:"success_rate"` // Executed without detection
+	BlockedRate           float64           `json:"blocked_rate"`
+	DetectionRate         float64           `json:"detection_rate"`
+	ErrorRate             float64           `json:"error_rate"` // Failed, timed out or killed for its limits
+	MedianDurationSeconds *float64          `json:"median_duration_seconds"`
+	Flakiness             float64           `json:"flakiness"`
+	LastFailure           *TechniqueFailure `json:"last_failure,omitempty"`
+}
+
+// PlatformTechniqueStats are the statistics of a technique on the agents of a platform
+type PlatformTechniqueStats struct {
+	Platform string `json:"platform"`
+	TechniqueRunStats
+}
+
+// TechniqueStats are the historical statistics of a technique, overall and per agent platform
+type TechniqueStats struct {
+	TechniqueID string                   `json:"technique_id"`
+	Name        string                   `json:"name,omitempty"`
+	Days        int                      `json:"days"`
+	Since       time.Time                `json:"since"`
+	Overall     TechniqueRunStats        `json:"overall"`
+	ByPlatform  []PlatformTechniqueStats `json:"by_platform"`
+}
+
+// SetAgentRepository resolves the platform of the agents of results
+func (s *AnalyticsService) SetAgentRepository(agentRepo repository.AgentRepository) {
+	s.agentRepo = agentRepo
+}
+
+// GetTechniqueStats returns the statistics of the runs of a technique over the
+// last days, to spot unreliable techniques
+func (s *AnalyticsService) GetTechniqueStats(ctx context.Context, techniqueID string, days int) (*TechniqueStats, error) {
+	since := time.Now().AddDate(0, 0, -days)
+	stats := &TechniqueStats{TechniqueID: techniqueID, Days: days, Since: since, ByPlatform: []PlatformTechniqueStats{}}
+
+	results, err := s.resultRepo.FindResultsByTechnique(ctx, techniqueID)
+	if err != nil {
+		return nil, err
+	}
+	if s.techniqueRepo != nil {
+		if technique, err := s.techniqueRepo.FindByID(ctx, techniqueID); err == nil {
+			stats.Name = technique.Name
+		} else if len(results) == 0 {
+			return nil, ErrTechniqueNotFound
+		}
+	}
+
+	var runs []*entity.ExecutionResult
+	paws := make(map[string]bool)
+	for _, result := range results {
+		if result.IsComplete() && !result.Status.IsSkipped() && !result.StartedAt.Before(since) {
+			runs = append(runs, result)
+			paws[result.AgentPaw] = true
+		}
+	}
+
+	platforms := make(map[string]string, len(paws))
+	if s.agentRepo != nil && len(paws) > 0 {
+		list := make([]string, 0, len(paws))
+		for paw := range paws {
+			list = append(list, paw)
+		}
+		agents, err := s.agentRepo.FindByPaws(ctx, list)
+		if err != nil {
+			return nil, err
+		}
+		for _, agent := range agents {
+			platforms[agent.Paw] = agent.Platform
+		}
+	}
+
+	byPlatform := make(map[string][]*entity.ExecutionResult)
+	for _, run := range runs {
+		platform := platforms[run.AgentPaw]
+		if platform == "" {
+			platform = unknownPlatform
+		}
+		byPlatform[platform] = append(byPlatform[platform], run)
+	}
+
+	stats.Overall = computeTechniqueRunStats(runs)
+	for platform, platformRuns := range byPlatform {
+		stats.ByPlatform = append(stats.ByPlatform, PlatformTechniqueStats{
+			Platform:          platform,
+			TechniqueRunStats: computeTechniqueRunStats(platformRuns),
+		})
+	}
+	sort.Slice(stats.ByPlatform, func(i, j int) bool { return stats.ByPlatform[i].Platform < stats.ByPlatform[j].Platform })
+	return stats, nil
+}
+
+// runOutcome classifies a run for the flakiness: an error, or the reaction of the defenses
+func runOutcome(result *entity.ExecutionResult) string {
+	switch result.Status {
+	case entity.StatusFailed, entity.StatusTimeout, entity.StatusLimitExceeded:
+		return "error"
+	case entity.StatusSuccess:
+		if result.Detected {
+			return string(entity.StatusDetected)
+		}
+	}
+	return string(result.Status)
+}
+
+// computeTechniqueRunStats aggregates runs
+func computeTechniqueRunStats(runs []*entity.ExecutionResult) TechniqueRunStats {
+	stats := TechniqueRunStats{Runs: len(runs)}
+	if len(runs) == 0 {
+		return stats
+	}
+
+	sorted := make([]*entity.ExecutionResult, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartedAt.Before(sorted[j].StartedAt) })
+
+	var success, blocked, detected, errored, transitions, changes int
+	var durations []float64
+	lastOutcome := make(map[string]string)
+	for _, run := range sorted {
+		outcome := runOutcome(run)
+		switch outcome {
+		case "error":
+			errored++
+			stats.LastFailure = &TechniqueFailure{
+				ResultID: run.ID, ExecutionID: run.ExecutionID, AgentPaw: run.AgentPaw, Status: run.Status, At: run.StartedAt,
+			}
+		case string(entity.StatusSuccess):
+			success++
+		case string(entity.StatusBlocked):
+			blocked++
+		case string(entity.StatusDetected):
+			detected++
+		}
+		if run.CompletedAt != nil && !run.CompletedAt.Before(run.StartedAt) {
+			durations = append(durations, run.CompletedAt.Sub(run.StartedAt).Seconds())
+		}
+
+		if previous, ok := lastOutcome[run.AgentPaw]; ok {
+			transitions++
+			if previous != outcome {
+				changes++
+			}
+		}
+		lastOutcome[run.AgentPaw] = outcome
+	}
+
+	total := float64(len(runs))
+	stats.SuccessRate = float64(success) / total
+	stats.BlockedRate = float64(blocked) / total
+	stats.DetectionRate = float64(detected) / total
+	stats.ErrorRate = float64(errored) / total
+	if transitions > 0 {
+		stats.Flakiness = float64(changes) / float64(transitions)
+	}
+	if len(durations) > 0 {
+		median := medianOf(durations)
+		stats.MedianDurationSeconds = &median
+	}
+	return stats
+}
+
+// medianOf returns the median of values, which it sorts
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestGetTechniqueStats(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery"}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["win-1"] = &entity.Agent{Paw: "win-1", Platform: "windows"}
+	agentRepo.agents["lin-1"] = &entity.Agent{Paw: "lin-1", Platform: "linux"}
+
+	svc := NewAnalyticsService(resultRepo)
+	svc.SetTechniqueRepository(techniqueRepo)
+	svc.SetAgentRepository(agentRepo)
+
+	base := time.Now().Add(-48 * time.Hour)
+	run := func(id, paw string, status entity.ResultStatus, offset, seconds int) *entity.ExecutionResult {
+		started := base.Add(time.Duration(offset) * time.Hour)
+		completed := started.Add(time.Duration(seconds) * time.Second)
+		return &entity.ExecutionResult{ID: id, ExecutionID: "exec-" + id, TechniqueID: "T1082", AgentPaw: paw,
+			Status: status, StartedAt: started, CompletedAt: &completed}
+	}
+	resultRepo.results["exec"] = []*entity.ExecutionResult{
+		run("w1", "win-1", entity.StatusBlocked, 0, 2),
+		run("w2", "win-1", entity.StatusSuccess, 1, 4),
+		run("w3", "win-1", entity.StatusBlocked, 2, 6),
+		run("l1", "lin-1", entity.StatusDetected, 0, 1),
+		run("l2", "lin-1", entity.StatusTimeout, 3, 30),
+		run("g1", "gone", entity.StatusSuccess, 4, 3),
+		run("skipped", "lin-1", entity.StatusSkipped, 5, 0),
+		run("old", "lin-1", entity.StatusFailed, -24*100, 1),
+		{ID: "running", TechniqueID: "T1082", AgentPaw: "lin-1", Status: entity.StatusRunning, StartedAt: base},
+	}
+
+	stats, err := svc.GetTechniqueStats(context.Background(), "T1082", 30)
+	if err != nil {
+		t.Fatalf("GetTechniqueStats failed: %v", err)
+	}
+	if stats.Name != "System Information Discovery" || stats.Days != 30 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	overall := stats.Overall
+	if overall.Runs != 6 {
+		t.Fatalf("Runs = %d, want 6", overall.Runs)
+	}
+	if overall.SuccessRate != 2.0/6 || overall.BlockedRate != 2.0/6 || overall.DetectionRate != 1.0/6 || overall.ErrorRate != 1.0/6 {
+		t.Errorf("rates = %+v", overall)
+	}
+	if overall.MedianDurationSeconds == nil || *overall.MedianDurationSeconds != 3.5 {
+		t.Errorf("MedianDurationSeconds = %v, want 3.5", overall.MedianDurationSeconds)
+	}
+	// win-1: blocked -> success -> blocked (2 changes), lin-1: detected -> timeout (1 change)
+	if overall.Flakiness != 1 {
+		t.Errorf("Flakiness = %v, want 1", overall.Flakiness)
+	}
+	if overall.LastFailure == nil || overall.LastFailure.ResultID != "l2" || overall.LastFailure.Status != entity.StatusTimeout {
+		t.Errorf("LastFailure = %+v, want the timeout", overall.LastFailure)
+	}
+
+	if len(stats.ByPlatform) != 3 {
+		t.Fatalf("ByPlatform = %+v, want linux, unknown and windows", stats.ByPlatform)
+	}
+	linux, unknown, windows := stats.ByPlatform[0], stats.ByPlatform[1], stats.ByPlatform[2]
+	if linux.Platform != "linux" || linux.Runs != 2 || linux.ErrorRate != 0.5 {
+		t.Errorf("linux = %+v", linux)
+	}
+	if unknown.Platform != unknownPlatform || unknown.Runs != 1 || unknown.Flakiness != 0 {
+		t.Errorf("unknown = %+v", unknown)
+	}
+	if windows.Platform != "windows" || windows.Runs != 3 || windows.LastFailure != nil || math.Abs(windows.BlockedRate-2.0/3) > 1e-9 {
+		t.Errorf("windows = %+v", windows)
+	}
+}
+
+func TestGetTechniqueStats_NoRuns(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery"}
+	svc := NewAnalyticsService(resultRepo)
+	svc.SetTechniqueRepository(techniqueRepo)
+
+	stats, err := svc.GetTechniqueStats(context.Background(), "T1082", 30)
+	if err != nil {
+		t.Fatalf("GetTechniqueStats failed: %v", err)
+	}
+	if stats.Overall.Runs != 0 || stats.Overall.MedianDurationSeconds != nil || len(stats.ByPlatform) != 0 {
+		t.Errorf("stats = %+v, want no runs", stats)
+	}
+
+	if _, err := svc.GetTechniqueStats(context.Background(), "T9999", 30); !errors.Is(err, ErrTechniqueNotFound) {
+		t.Errorf("error = %v, want ErrTechniqueNotFound", err)
+	}
+
+	resultRepo.err = errors.New("database locked")
+	if _, err := svc.GetTechniqueStats(context.Background(), "T1082", 30); err == nil {
+		t.Error("expected the repository error")
+	}
+}
@@ -0,0 +1,390 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrTechniqueSyncInProgress is returned when a sync is started while another one runs
+	ErrTechniqueSyncInProgress = errors.New("a technique sync is already running")
+	// ErrTechniqueSyncJobNotFound is returned when the requested sync job does not exist
+	ErrTechniqueSyncJobNotFound = errors.New("technique sync job not found")
+)
+
+// TechniqueSyncStatus is the state of a technique sync job
+type TechniqueSyncStatus string
+
+const (
+	TechniqueSyncRunning   TechniqueSyncStatus = "running"
+	TechniqueSyncCompleted TechniqueSyncStatus = "completed"
+	TechniqueSyncFailed    TechniqueSyncStatus = "failed"
+)
+
+// Content formats a sync merges, whose converters the content import service has
+const (
+	STIXContentFormat   = "stix"
+	AtomicContentFormat = "atomic"
+)
+
+// MitreSyncFormat tags the techniques a sync imported, in their import_source field
+const MitreSyncFormat = "mitre"
+
+// Upstream content a sync downloads by default
+const (
+	// DefaultSTIXURL is the STIX bundle of an ATT&CK domain, {domain} standing for the domain
+	DefaultSTIXURL = "https://raw.githubusercontent.com/mitre-attack/attack-stix-data/master/{domain}/{domain}.json"
+	// DefaultAtomicsURL is the index of the Atomic Red Team tests
+	DefaultAtomicsURL = "https://raw.githubusercontent.com/redcanaryco/atomic-red-team/master/atomics/Indexes/index.yaml"
+)
+
+// ContentFetcher downloads the content of a sync source
+type ContentFetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
+
+// TechniqueSyncConfig locates the upstream content a sync merges. Zero fields
+// use the enterprise domain, DefaultSTIXURL and DefaultAtomicsURL.
+type TechniqueSyncConfig struct {
+	Domains    []string // ATT&CK domains: enterprise-attack, ics-attack, mobile-attack
+	STIXURL    string   // {domain} is replaced by each domain
+	AtomicsURL string
+}
+
+// TechniqueSyncSource is a document a sync downloads, in a content import format
+type TechniqueSyncSource struct {
+	Format string `json:"format"`
+	URL    string `json:"url"`
+}
+
+// TechniqueSyncRequest changes how a sync writes the upstream content
+type TechniqueSyncRequest struct {
+	DeprecateRemoved bool // Deprecate the techniques the synced content no longer has
+}
+
+// TechniqueSyncSourceResult is the outcome of downloading and converting one source
+type TechniqueSyncSourceResult struct {
+	TechniqueSyncSource
+	Version string `json:"version,omitempty"` // ATT&CK release of a STIX bundle, SHA-256 prefix of the atomics
+	Error   string `json:"error,omitempty"`   // The source could not be fetched or converted
+}
+
+// TechniqueSyncJob tracks a refresh of the technique catalog from ATT&CK and Atomic Red Team
+type TechniqueSyncJob struct {
+	ID               string                      `json:"id"`
+	JobID            string                      `json:"job_id,omitempty"` // Background job running the sync, with a job service
+	Status           TechniqueSyncStatus         `json:"status"`
+	DeprecateRemoved bool                        `json:"deprecate_removed"`
+	StartedBy        string                      `json:"started_by"`
+	Sources          []TechniqueSyncSourceResult `json:"sources"`             // Sources downloaded so far
+	Unchanged        bool                        `json:"unchanged,omitempty"` // Same versions as the last sync, nothing imported
+	Result           *ContentImportResult        `json:"result,omitempty"`    // Import of the merged content
+	Added            int                         `json:"added"`
+	Updated          int                         `json:"updated"`
+	Deprecated       int                         `json:"deprecated"`
+	Drafts           int                         `json:"drafts"` // Techniques without an atomic test, created as drafts
+	Failed           int                         `json:"failed"` // Techniques and scenarios that could not be imported
+	Error            string                      `json:"error,omitempty"`
+	StartedAt        time.Time                   `json:"started_at"`
+	CompletedAt      *time.Time                  `json:"completed_at,omitempty"`
+}
+
+// TechniqueSyncService refreshes the technique catalog in the background: it
+// downloads the ATT&CK STIX bundle of each configured domain and the Atomic
+// Red Team index, gives the ATT&CK techniques the executors of their atomic
+// tests, and imports the merged content incrementally.
+type TechniqueSyncService struct {
+	imports *ContentImportService
+	fetcher ContentFetcher
+	sources []TechniqueSyncSource
+	logger  *zap.Logger
+
+	jobService *JobService
+
+	mu       sync.Mutex
+	jobs     map[string]*TechniqueSyncJob
+	running  string            // ID of the running job, empty when idle
+	versions map[string]string // Source versions of the last completed sync, by URL
+}
+
+// NewTechniqueSyncService creates a technique sync service converting and
+// importing the content through the content import service, which needs the
+// stix and atomic converters
+func NewTechniqueSyncService(
+	imports *ContentImportService,
+	fetcher ContentFetcher,
+	config TechniqueSyncConfig,
+	logger *zap.Logger,
+) *TechniqueSyncService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	domains := config.Domains
+	if len(domains) == 0 {
+		domains = []string{entity.DomainEnterprise}
+	}
+	stixURL := config.STIXURL
+	if stixURL == "" {
+		stixURL = DefaultSTIXURL
+	}
+	atomicsURL := config.AtomicsURL
+	if atomicsURL == "" {
+		atomicsURL = DefaultAtomicsURL
+	}
+
+	sources := make([]TechniqueSyncSource, 0, len(domains)+1)
+	for _, domain := range domains {
+		sources = append(sources, TechniqueSyncSource{Format: STIXContentFormat, URL: strings.ReplaceAll(stixURL, "{domain}", domain)})
+	}
+	sources = append(sources, TechniqueSyncSource{Format: AtomicContentFormat, URL: atomicsURL})
+
+	return &TechniqueSyncService{
+		imports: imports,
+		fetcher: fetcher,
+		sources: sources,
+		logger:  logger,
+		jobs:    make(map[string]*TechniqueSyncJob),
+	}
+}
+
+// SetJobService runs the syncs as background jobs, followed with GET /jobs/:id as well
+func (s *TechniqueSyncService) SetJobService(jobs *JobService) {
+	s.jobService = jobs
+}
+
+// Sources returns the documents a sync downloads
+func (s *TechniqueSyncService) Sources() []TechniqueSyncSource {
+	return append([]TechniqueSyncSource{}, s.sources...)
+}
+
+// StartSync launches a sync in the background and returns its job. Only one
+// sync runs at a time.
+func (s *TechniqueSyncService) StartSync(req TechniqueSyncRequest, userID string) (*TechniqueSyncJob, error) {
+	s.mu.Lock()
+	if s.running != "" {
+		s.mu.Unlock()
+		return nil, ErrTechniqueSyncInProgress
+	}
+	job := &TechniqueSyncJob{
+		ID:               uuid.New().String(),
+		Status:           TechniqueSyncRunning,
+		DeprecateRemoved: req.DeprecateRemoved,
+		StartedBy:        userID,
+		Sources:          []TechniqueSyncSourceResult{},
+		StartedAt:        time.Now(),
+	}
+	s.jobs[job.ID] = job
+	s.running = job.ID
+	snapshot := job.snapshot()
+	s.mu.Unlock()
+
+	if s.jobService == nil {
+		// The job outlives the request that started it
+		go s.run(context.Background(), job, nil)
+		return snapshot, nil
+	}
+
+	queued, err := s.jobService.Submit(context.Background(), entity.JobTechniqueSync, userID,
+		func(ctx context.Context, progress *JobProgress) (any, error) {
+			s.run(ctx, job, progress)
+			done, _ := s.GetJob(job.ID)
+			if done.Status == TechniqueSyncFailed {
+				return nil, errors.New(done.Error)
+			}
+			return done, nil
+		})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		delete(s.jobs, job.ID)
+		s.running = ""
+		return nil, err
+	}
+	job.JobID = queued.ID
+	return job.snapshot(), nil
+}
+
+// GetJob returns the current state of a sync job
+func (s *TechniqueSyncService) GetJob(id string) (*TechniqueSyncJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrTechniqueSyncJobNotFound
+	}
+	return job.snapshot(), nil
+}
+
+// ListJobs returns the sync jobs started since the server booted, newest first
+func (s *TechniqueSyncService) ListJobs() []*TechniqueSyncJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*TechniqueSyncJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job.snapshot())
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.After(jobs[j].StartedAt) })
+	return jobs
+}
+
+// run downloads and converts the sources one after the other, reporting the
+// progress of its background job when it has one, then imports their merge.
+// The sync fails without importing anything when a source cannot be fetched or
+// converted: a partial catalog would deprecate the techniques of the missing
+// source. Sources with the versions of the last completed sync are not
+// imported again.
+func (s *TechniqueSyncService) run(ctx context.Context, job *TechniqueSyncJob, progress *JobProgress) {
+	steps := len(s.sources) + 1
+	var catalog []*entity.Technique
+	var atomics *ContentBundle
+	versions := make(map[string]string, len(s.sources))
+	failed := false
+	for i, source := range s.sources {
+		if progress != nil {
+			progress.Report(i, steps, "Downloading "+source.URL)
+		}
+		outcome := TechniqueSyncSourceResult{TechniqueSyncSource: source}
+		bundle, version, err := s.convertSource(ctx, source)
+		if err != nil {
+			outcome.Error = err.Error()
+			failed = true
+			s.logger.Warn("Failed to download technique sync source",
+				zap.String("job_id", job.ID), zap.String("url", source.URL), zap.Error(err))
+		} else if source.Format == AtomicContentFormat {
+			atomics = bundle
+		} else {
+			catalog = append(catalog, bundle.Techniques...)
+		}
+		outcome.Version = version
+		versions[source.URL] = version
+
+		s.mu.Lock()
+		job.Sources = append(job.Sources, outcome)
+		s.mu.Unlock()
+	}
+
+	var result *ContentImportResult
+	var drafts int
+	var err error
+	s.mu.Lock()
+	unchanged := !failed && maps.Equal(versions, s.versions)
+	s.mu.Unlock()
+	switch {
+	case failed:
+		err = errors.New("a source could not be downloaded, nothing was imported")
+	case unchanged:
+	default:
+		if progress != nil {
+			progress.Report(len(s.sources), steps, "Importing the merged catalog")
+		}
+		var merged *ContentBundle
+		merged, drafts = mergeAtomics(catalog, atomics)
+		opts := ContentImportOptions{Incremental: true, DeprecateRemoved: job.DeprecateRemoved, OwnTechniquesOnly: true}
+		result, err = s.imports.ImportBundle(ctx, MitreSyncFormat, merged, opts)
+	}
+	if progress != nil {
+		progress.Report(steps, steps, "")
+	}
+
+	s.mu.Lock()
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Status = TechniqueSyncCompleted
+	job.Unchanged = unchanged
+	if err != nil {
+		job.Status = TechniqueSyncFailed
+		job.Error = err.Error()
+	} else if result != nil {
+		job.Result = result
+		job.Added = len(result.Added)
+		job.Updated = len(result.Updated)
+		job.Deprecated = len(result.Deprecated)
+		job.Drafts = drafts
+		job.Failed = result.Failed
+		s.versions = versions
+	}
+	s.running = ""
+	s.mu.Unlock()
+
+	s.logger.Info("Technique sync finished",
+		zap.String("job_id", job.ID),
+		zap.String("status", string(job.Status)),
+		zap.Bool("unchanged", job.Unchanged),
+		zap.Int("added", job.Added),
+		zap.Int("updated", job.Updated),
+		zap.Int("deprecated", job.Deprecated),
+		zap.Int("failed", job.Failed),
+	)
+}
+
+// convertSource downloads and converts one source, and returns its version:
+// the ATT&CK release of a STIX bundle, the start of the SHA-256 of other content
+func (s *TechniqueSyncService) convertSource(ctx context.Context, source TechniqueSyncSource) (*ContentBundle, string, error) {
+	data, err := s.fetcher.Fetch(ctx, source.URL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", source.URL, err)
+	}
+	bundle, err := s.imports.Convert(source.Format, data)
+	if err != nil {
+		return nil, "", err
+	}
+	version := bundle.Version
+	if version == "" {
+		sum := sha256.Sum256(data)
+		version = "sha256:" + hex.EncodeToString(sum[:6])
+	}
+	return bundle, version, nil
+}
+
+// mergeAtomics gives the ATT&CK techniques of catalog the executors and
+// platforms of their atomic tests. Techniques without a test cannot run yet:
+// they are merged as drafts, for an operator to write executors for, and
+// counted. The atomics of techniques missing from the catalog, revoked or of
+// another domain, are left out.
+func mergeAtomics(catalog []*entity.Technique, atomics *ContentBundle) (*ContentBundle, int) {
+	tests := make(map[string]*entity.Technique, len(atomics.Techniques))
+	for _, technique := range atomics.Techniques {
+		tests[technique.ID] = technique
+	}
+
+	merged := &ContentBundle{Techniques: make([]*entity.Technique, 0, len(catalog))}
+	drafts := 0
+	for _, technique := range catalog {
+		if atomic, ok := tests[technique.ID]; ok {
+			technique.Executors = atomic.Executors
+			technique.Platforms = atomic.Platforms
+		} else {
+			technique.Status = entity.TechniqueDraft
+			drafts++
+		}
+		merged.Techniques = append(merged.Techniques, technique)
+	}
+	return merged, drafts
+}
+
+// snapshot returns a copy of the job that is safe to hand out while it runs
+func (j *TechniqueSyncJob) snapshot() *TechniqueSyncJob {
+	clone := *j
+	clone.Sources = append([]TechniqueSyncSourceResult{}, j.Sources...)
+	if j.CompletedAt != nil {
+		completedAt := *j.CompletedAt
+		clone.CompletedAt = &completedAt
+	}
+	return &clone
+}
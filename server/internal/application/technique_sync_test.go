@@ -0,0 +1,258 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+)
+
+// stubContentFetcher serves content by URL, blocking until release is closed when set
+type stubContentFetcher struct {
+	content map[string][]byte
+	release chan struct{}
+}
+
+func (f *stubContentFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	if f.release != nil {
+		<-f.release
+	}
+	data, ok := f.content[url]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return data, nil
+}
+
+// Sync sources of the fixture
+const (
+	syncSTIXURL    = "https://example.com/enterprise-attack.json"
+	syncAtomicsURL = "https://example.com/atomics.yaml"
+)
+
+// syncContent serves both sync sources
+func syncContent() map[string][]byte {
+	return map[string][]byte{syncSTIXURL: []byte("stix"), syncAtomicsURL: []byte("atomics")}
+}
+
+// newTechniqueSyncFixture stores a curated technique, T1003, and a technique of
+// a previous sync that ATT&CK no longer has, T1999. The ATT&CK bundle has
+// T1082, which has an atomic test, and T1057 and T1003, which have none.
+func newTechniqueSyncFixture(fetcher ContentFetcher) (*TechniqueSyncService, *mockTechniqueRepo) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1003"] = &entity.Technique{ID: "T1003", Name: "Curated", Tactic: entity.TacticCredentialAccess, Executors: []entity.Executor{{Type: "sh", Command: "cat /etc/shadow"}}}
+	techRepo.techniques["T1999"] = &entity.Technique{ID: "T1999", Name: "Removed upstream", Metadata: entity.Metadata{entity.ImportSourceMetadataKey: MitreSyncFormat}}
+	stix := &stubContentConverter{format: STIXContentFormat, bundle: &ContentBundle{
+		Version: "15.1",
+		Techniques: []*entity.Technique{
+			{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery, Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux", "windows"}, Metadata: entity.Metadata{entity.ATTACKVersionMetadataKey: "15.1"}},
+			{ID: "T1057", Name: "Process Discovery", Tactic: entity.TacticDiscovery, Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux"}, Metadata: entity.Metadata{entity.ATTACKVersionMetadataKey: "15.1"}},
+			{ID: "T1003", Name: "OS Credential Dumping", Tactic: entity.TacticCredentialAccess, Tactics: []entity.TacticType{entity.TacticCredentialAccess}, Platforms: []string{"linux"}},
+		},
+	}}
+	atomics := &stubContentConverter{format: AtomicContentFormat, bundle: &ContentBundle{
+		Techniques: []*entity.Technique{
+			{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery, Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "uname -a"}}},
+			{ID: "T1105", Name: "Ingress Tool Transfer", Tactic: entity.TacticCommandAndControl, Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "curl -O"}}},
+		},
+	}}
+	imports := NewContentImportService(
+		NewTechniqueService(techRepo),
+		NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator()),
+		stix, atomics,
+	)
+	config := TechniqueSyncConfig{STIXURL: "https://example.com/{domain}.json", AtomicsURL: syncAtomicsURL}
+	return NewTechniqueSyncService(imports, fetcher, config, nil), techRepo
+}
+
+func waitForTechniqueSync(t *testing.T, svc *TechniqueSyncService, id string) *TechniqueSyncJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		if job.Status != TechniqueSyncRunning {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("technique sync job did not finish in time")
+	return nil
+}
+
+func TestNewTechniqueSyncService_Sources(t *testing.T) {
+	svc := NewTechniqueSyncService(nil, nil, TechniqueSyncConfig{Domains: []string{entity.DomainEnterprise, entity.DomainICS}}, nil)
+
+	sources := svc.Sources()
+	if len(sources) != 3 {
+		t.Fatalf("Expected a STIX source per domain and the atomics, got %+v", sources)
+	}
+	if sources[1].Format != STIXContentFormat || !strings.HasSuffix(sources[1].URL, "/ics-attack/ics-attack.json") {
+		t.Errorf("Unexpected ICS source: %+v", sources[1])
+	}
+	if sources[2].Format != AtomicContentFormat || sources[2].URL != DefaultAtomicsURL {
+		t.Errorf("Unexpected atomics source: %+v", sources[2])
+	}
+}
+
+func TestTechniqueSync_MergesATTACKWithAtomics(t *testing.T) {
+	svc, techRepo := newTechniqueSyncFixture(&stubContentFetcher{content: syncContent()})
+
+	started, err := svc.StartSync(TechniqueSyncRequest{DeprecateRemoved: true}, "admin-1")
+	if err != nil {
+		t.Fatalf("StartSync failed: %v", err)
+	}
+	if started.Status != TechniqueSyncRunning || started.StartedBy != "admin-1" {
+		t.Errorf("Unexpected started job: %+v", started)
+	}
+
+	job := waitForTechniqueSync(t, svc, started.ID)
+	if job.Status != TechniqueSyncCompleted || job.CompletedAt == nil || job.Result == nil {
+		t.Fatalf("Expected a completed job, got %+v", job)
+	}
+	if len(job.Sources) != 2 || job.Sources[0].Version != "15.1" || !strings.HasPrefix(job.Sources[1].Version, "sha256:") {
+		t.Errorf("Expected the source versions recorded, got %+v", job.Sources)
+	}
+	if job.Added != 2 || job.Drafts != 2 || job.Deprecated != 1 || job.Failed != 0 {
+		t.Errorf("Unexpected counters: %+v", job)
+	}
+
+	discovery := techRepo.techniques["T1082"]
+	if discovery == nil || len(discovery.Executors) != 1 || discovery.Executors[0].Command != "uname -a" || discovery.Status == entity.TechniqueDraft {
+		t.Fatalf("Expected T1082 with the executor of its atomic test, got %+v", discovery)
+	}
+	if discovery.Metadata[entity.ImportSourceMetadataKey] != MitreSyncFormat || discovery.Metadata[entity.ATTACKVersionMetadataKey] != "15.1" {
+		t.Errorf("Unexpected T1082 metadata: %v", discovery.Metadata)
+	}
+	if techRepo.techniques["T1057"].Status != entity.TechniqueDraft {
+		t.Error("Expected the technique without an atomic test created as a draft")
+	}
+	if _, ok := techRepo.techniques["T1105"]; ok {
+		t.Error("Expected the atomics of a technique missing from ATT&CK left out")
+	}
+	if curated := techRepo.techniques["T1003"]; curated.Name != "Curated" || len(curated.Executors) != 1 || len(job.Result.Kept) != 1 {
+		t.Errorf("Expected the curated technique left as is, got %+v, kept %v", curated, job.Result.Kept)
+	}
+	if techRepo.techniques["T1999"].Status != entity.TechniqueDeprecated {
+		t.Error("Expected the technique removed upstream to be deprecated")
+	}
+}
+
+func TestTechniqueSync_SkipsUnchangedVersions(t *testing.T) {
+	svc, techRepo := newTechniqueSyncFixture(&stubContentFetcher{content: syncContent()})
+
+	first, err := svc.StartSync(TechniqueSyncRequest{}, "admin-1")
+	if err != nil {
+		t.Fatalf("StartSync failed: %v", err)
+	}
+	waitForTechniqueSync(t, svc, first.ID)
+	delete(techRepo.techniques, "T1082")
+
+	second, err := svc.StartSync(TechniqueSyncRequest{}, "admin-1")
+	if err != nil {
+		t.Fatalf("StartSync failed: %v", err)
+	}
+	job := waitForTechniqueSync(t, svc, second.ID)
+	if job.Status != TechniqueSyncCompleted || !job.Unchanged || job.Result != nil {
+		t.Errorf("Expected nothing imported for the same versions, got %+v", job)
+	}
+	if _, ok := techRepo.techniques["T1082"]; ok {
+		t.Error("Expected no import for the same versions")
+	}
+}
+
+func TestTechniqueSync_FailsWhenASourceFails(t *testing.T) {
+	content := syncContent()
+	delete(content, syncAtomicsURL)
+	svc, techRepo := newTechniqueSyncFixture(&stubContentFetcher{content: content})
+
+	started, err := svc.StartSync(TechniqueSyncRequest{DeprecateRemoved: true}, "admin-1")
+	if err != nil {
+		t.Fatalf("StartSync failed: %v", err)
+	}
+	job := waitForTechniqueSync(t, svc, started.ID)
+	if job.Status != TechniqueSyncFailed || job.Error == "" || len(job.Sources) != 2 || job.Sources[1].Error == "" {
+		t.Fatalf("Expected a failed job, got %+v", job)
+	}
+	if _, ok := techRepo.techniques["T1082"]; ok {
+		t.Error("Expected nothing imported when a source fails")
+	}
+	if techRepo.techniques["T1999"].Status == entity.TechniqueDeprecated {
+		t.Error("Expected no technique deprecated when a source fails")
+	}
+}
+
+func TestMergeAtomics_KeepsStoredExecutors(t *testing.T) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1057"] = &entity.Technique{ID: "T1057", Name: "Process Discovery", Tactic: entity.TacticDiscovery, Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "ps aux"}}, Metadata: entity.Metadata{entity.ImportSourceMetadataKey: MitreSyncFormat}}
+	imports := NewContentImportService(NewTechniqueService(techRepo), NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator()))
+
+	merged, drafts := mergeAtomics([]*entity.Technique{
+		{ID: "T1057", Name: "Process Discovery", Description: "Updated upstream", Tactic: entity.TacticDiscovery, Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux"}},
+	}, &ContentBundle{})
+	if drafts != 1 {
+		t.Errorf("Expected 1 draft, got %d", drafts)
+	}
+	result, err := imports.ImportBundle(context.Background(), MitreSyncFormat, merged, ContentImportOptions{Incremental: true, OwnTechniquesOnly: true})
+	if err != nil || len(result.Updated) != 1 {
+		t.Fatalf("Expected T1057 updated, got %+v, %v", result, err)
+	}
+	stored := techRepo.techniques["T1057"]
+	if stored.Description != "Updated upstream" || len(stored.Executors) != 1 || stored.Executors[0].Command != "ps aux" {
+		t.Errorf("Expected the stored executors kept, got %+v", stored)
+	}
+}
+
+func TestTechniqueSync_SingleJobAtATime(t *testing.T) {
+	fetcher := &stubContentFetcher{content: syncContent(), release: make(chan struct{})}
+	svc, _ := newTechniqueSyncFixture(fetcher)
+
+	started, err := svc.StartSync(TechniqueSyncRequest{}, "admin-1")
+	if err != nil {
+		t.Fatalf("StartSync failed: %v", err)
+	}
+	if _, err := svc.StartSync(TechniqueSyncRequest{}, "admin-1"); !errors.Is(err, ErrTechniqueSyncInProgress) {
+		t.Errorf("Expected ErrTechniqueSyncInProgress, got %v", err)
+	}
+	close(fetcher.release)
+	waitForTechniqueSync(t, svc, started.ID)
+
+	if jobs := svc.ListJobs(); len(jobs) != 1 || jobs[0].ID != started.ID {
+		t.Errorf("Expected the started job listed, got %+v", jobs)
+	}
+	if _, err := svc.GetJob("missing"); !errors.Is(err, ErrTechniqueSyncJobNotFound) {
+		t.Errorf("Expected ErrTechniqueSyncJobNotFound, got %v", err)
+	}
+}
+
+func TestTechniqueSync_RunsAsBackgroundJob(t *testing.T) {
+	fetcher := &stubContentFetcher{content: syncContent()}
+	svc, _ := newTechniqueSyncFixture(fetcher)
+	jobs := NewJobService(newMockJobRepo(), 1, nil)
+	jobs.Start()
+	defer jobs.Stop()
+	svc.SetJobService(jobs)
+
+	started, err := svc.StartSync(TechniqueSyncRequest{}, "admin-1")
+	if err != nil {
+		t.Fatalf("StartSync failed: %v", err)
+	}
+	if started.JobID == "" {
+		t.Fatal("Expected the sync linked to its background job")
+	}
+
+	job := waitForJob(t, jobs, started.JobID)
+	if job.Status != entity.JobCompleted || job.Type != entity.JobTechniqueSync || job.Progress != 3 || job.Total != 3 {
+		t.Errorf("Unexpected background job: %+v", job)
+	}
+	if sync := waitForTechniqueSync(t, svc, started.ID); sync.Status != TechniqueSyncCompleted || sync.Added != 2 {
+		t.Errorf("Unexpected sync: %+v", sync)
+	}
+}
@@ -0,0 +1,531 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// TicketRequest is the issue to open in a tracker for an undetected technique
+type TicketRequest struct {
+	Summary     string
+	Description string
+	TechniqueID string
+	Labels      []string
+}
+
+// TicketRef identifies an issue opened in a tracker
+type TicketRef struct {
+	Key string // Issue key or record ID, used for later calls
+	URL string // Link to the issue in the tracker UI
+}
+
+// TicketState is the status of an issue in a tracker
+type TicketState struct {
+	Status string // Status name as shown in the tracker
+	Closed bool   // Done, resolved or closed
+}
+
+// TicketConnector opens and follows issues in an external tracker
+type TicketConnector interface {
+	Name() string
+	CreateTicket(ctx context.Context, req TicketRequest) (*TicketRef, error)
+	GetTicket(ctx context.Context, key string) (*TicketState, error)
+	CommentTicket(ctx context.Context, key, comment string) error
+	ResolveTicket(ctx context.Context, key, comment string) error
+}
+
+// TicketConfig controls when tickets are opened and synced
+type TicketConfig struct {
+	Delay        time.Duration // Wait after completion before opening tickets, to let detection correlation finish
+	SyncInterval time.Duration // Interval of the status sync with the tracker
+	Labels       []string      // Labels added to every issue
+	DashboardURL string        // Links the issues to the execution in the dashboard when set
+}
+
+// DefaultTicketConfig returns the default ticketing settings
+func DefaultTicketConfig() TicketConfig {
+	return TicketConfig{SyncInterval: 15 * time.Minute, Labels: []string{"autostrike"}}
+}
+
+// DefaultTicketSummaryTemplate is the summary of the issue opened for an undetected technique
+const DefaultTicketSummaryTemplate = `[AutoStrike] {{.Technique.ID}} {{.Technique.Name}} not detected on {{.Host}}`
+
+// DefaultTicketDescriptionTemplate is the description of the issue opened for an undetected technique
+const DefaultTicketDescriptionTemplate = `The technique {{.Technique.ID}} ({{.Technique.Name}}, {{.Technique.Tactic}}) ran on {{.Host}} without being blocked or detected.
+
+Scenario: {{.ScenarioName}}
+Execution: {{.ExecutionID}}
+Host: {{.Host}} ({{.Platform}}, agent {{.AgentPaw}})
+Started: {{.StartedAt}}
+Completed: {{.CompletedAt}}
+{{if .Command}}
+Command ({{.Executor}}):
+{{.Command}}
+{{end}}{{if .Remediation}}
+Remediation suggestions:
+{{range .Remediation}}- {{.}}
+{{end}}{{end}}{{if .Link}}
+Result: {{.Link}}
+{{end}}`
+
+// TicketData is the data the ticket templates are rendered with
+type TicketData struct {
+	Technique    *entity.Technique
+	ScenarioName string
+	ExecutionID  string
+	ResultID     string
+	AgentPaw     string
+	Host         string
+	Platform     string
+	Executor     string
+	Command      string
+	StartedAt    string
+	CompletedAt  string
+	Remediation  []string
+	Link         string
+}
+
+// TicketService opens a tracker issue for every technique that ran undetected
+// in a completed execution, and keeps its status in sync both ways: a later run
+// blocking or detecting the technique resolves the issue in the tracker, and an
+// issue closed in the tracker is resolved in AutoStrike.
+type TicketService struct {
+	ticketRepo    repository.TicketRepository
+	resultRepo    repository.ResultRepository
+	techniqueRepo repository.TechniqueRepository
+	agentRepo     repository.AgentRepository
+	scenarioRepo  repository.ScenarioRepository
+	connector     TicketConnector
+	config        TicketConfig
+	logger        *zap.Logger
+
+	summary     *template.Template
+	description *template.Template
+
+	mu       sync.Mutex
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+}
+
+// NewTicketService creates a new ticket service
+func NewTicketService(
+	ticketRepo repository.TicketRepository,
+	resultRepo repository.ResultRepository,
+	techniqueRepo repository.TechniqueRepository,
+	agentRepo repository.AgentRepository,
+	scenarioRepo repository.ScenarioRepository,
+	connector TicketConnector,
+	config TicketConfig,
+	logger *zap.Logger,
+) *TicketService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = DefaultTicketConfig().SyncInterval
+	}
+	return &TicketService{
+		ticketRepo:    ticketRepo,
+		resultRepo:    resultRepo,
+		techniqueRepo: techniqueRepo,
+		agentRepo:     agentRepo,
+		scenarioRepo:  scenarioRepo,
+		connector:     connector,
+		config:        config,
+		logger:        logger,
+		summary:       template.Must(template.New("summary").Parse(DefaultTicketSummaryTemplate)),
+		description:   template.Must(template.New("description").Parse(DefaultTicketDescriptionTemplate)),
+	}
+}
+
+// SetTemplates replaces the summary and description templates; an empty
+// template keeps the default one
+func (s *TicketService) SetTemplates(summary, description string) error {
+	if summary != "" {
+		tmpl, err := template.New("summary").Parse(summary)
+		if err != nil {
+			return fmt.Errorf("invalid ticket summary template: %w", err)
+		}
+		s.summary = tmpl
+	}
+	if description != "" {
+		tmpl, err := template.New("description").Parse(description)
+		if err != nil {
+			return fmt.Errorf("invalid ticket description template: %w", err)
+		}
+		s.description = tmpl
+	}
+	return nil
+}
+
+// Provider returns the name of the tracker tickets are opened in
+func (s *TicketService) Provider() string {
+	return s.connector.Name()
+}
+
+// Name returns the sink name
+func (s *TicketService) Name() string {
+	return "tickets"
+}
+
+// Handle opens and resolves the tickets of completed executions. It runs in
+// the background, after the configured delay: the bus calls sinks while the
+// execution is being completed.
+func (s *TicketService) Handle(ctx context.Context, event *entity.Event) error {
+	if event.Type != entity.EventExecutionCompleted || event.Execution == nil {
+		return nil
+	}
+	execution, scenarioName := event.Execution, event.ScenarioName
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if s.config.Delay > 0 {
+			timer := time.NewTimer(s.config.Delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-s.stopped():
+				return
+			}
+		}
+		if err := s.ProcessExecution(context.WithoutCancel(ctx), execution.ID, scenarioName); err != nil {
+			s.logger.Error("Failed to process the tickets of an execution",
+				zap.String("execution_id", execution.ID), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// ProcessExecution opens a ticket for every technique that ran undetected on
+// an agent, or adds the failure to its open ticket, and resolves the open
+// tickets of the techniques the execution blocked or detected
+func (s *TicketService) ProcessExecution(ctx context.Context, executionID, scenarioName string) error {
+	results, err := s.resultRepo.FindResultsByExecution(ctx, executionID)
+	if err != nil {
+		return fmt.Errorf("failed to get results: %w", err)
+	}
+
+	var errs []string
+	for _, result := range latestResults(results) {
+		switch {
+		case result.Status == entity.StatusSuccess && !result.Detected:
+			err = s.openTicket(ctx, result, scenarioName)
+		case result.Status == entity.StatusBlocked || result.Status == entity.StatusDetected ||
+			(result.Status == entity.StatusSuccess && result.Detected):
+			err = s.resolveDefended(ctx, result)
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s on %s: %v", result.TechniqueID, result.AgentPaw, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d ticket(s) failed: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// openTicket opens the ticket of an undetected result, or comments the open one
+func (s *TicketService) openTicket(ctx context.Context, result *entity.ExecutionResult, scenarioName string) error {
+	ticket, err := s.ticketRepo.FindOpen(ctx, result.TechniqueID, result.AgentPaw)
+	if err != nil {
+		return err
+	}
+	if ticket != nil && ticket.ResultID == result.ID {
+		return nil
+	}
+
+	data := s.ticketData(ctx, result, scenarioName)
+	now := time.Now()
+	if ticket != nil {
+		comment := fmt.Sprintf("Still undetected in execution %s (%s).", result.ExecutionID, data.StartedAt)
+		if err := s.connector.CommentTicket(ctx, ticket.ExternalKey, comment); err != nil {
+			return fmt.Errorf("failed to comment %s: %w", ticket.ExternalKey, err)
+		}
+		ticket.ExecutionID = result.ExecutionID
+		ticket.ResultID = result.ID
+		ticket.Occurrences++
+		ticket.UpdatedAt = now
+		return s.ticketRepo.Update(ctx, ticket)
+	}
+
+	summary, err := renderTicketTemplate(s.summary, data)
+	if err != nil {
+		return err
+	}
+	description, err := renderTicketTemplate(s.description, data)
+	if err != nil {
+		return err
+	}
+	ref, err := s.connector.CreateTicket(ctx, TicketRequest{
+		Summary:     strings.TrimSpace(summary),
+		Description: description,
+		TechniqueID: result.TechniqueID,
+		Labels:      s.config.Labels,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create ticket: %w", err)
+	}
+
+	return s.ticketRepo.Create(ctx, &entity.Ticket{
+		ID:          uuid.New().String(),
+		Provider:    s.connector.Name(),
+		ExternalKey: ref.Key,
+		URL:         ref.URL,
+		TechniqueID: result.TechniqueID,
+		AgentPaw:    result.AgentPaw,
+		ExecutionID: result.ExecutionID,
+		ResultID:    result.ID,
+		Status:      entity.TicketOpen,
+		Occurrences: 1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+}
+
+// resolveDefended resolves the open ticket of a technique a result blocked or detected
+func (s *TicketService) resolveDefended(ctx context.Context, result *entity.ExecutionResult) error {
+	ticket, err := s.ticketRepo.FindOpen(ctx, result.TechniqueID, result.AgentPaw)
+	if err != nil || ticket == nil {
+		return err
+	}
+	return s.resolve(ctx, ticket, result)
+}
+
+// resolve resolves a ticket in the tracker and in AutoStrike after result defended the technique
+func (s *TicketService) resolve(ctx context.Context, ticket *entity.Ticket, result *entity.ExecutionResult) error {
+	outcome := "blocked"
+	if result.Status != entity.StatusBlocked {
+		outcome = "detected"
+	}
+	comment := fmt.Sprintf("%s was %s in execution %s, resolved by AutoStrike.", result.TechniqueID, outcome, result.ExecutionID)
+	if err := s.connector.ResolveTicket(ctx, ticket.ExternalKey, comment); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", ticket.ExternalKey, err)
+	}
+	ticket.ExecutionID = result.ExecutionID
+	ticket.ResultID = result.ID
+	ticket.Resolve(entity.TicketResolutionDefended, time.Now())
+	return s.ticketRepo.Update(ctx, ticket)
+}
+
+// Sync brings the open tickets in line with the tracker and the results: a
+// ticket whose result was since marked blocked or detected by the detection
+// correlation is resolved in the tracker, and a ticket closed in the tracker
+// is resolved here. It returns the number of tickets resolved.
+func (s *TicketService) Sync(ctx context.Context) (int, error) {
+	tickets, err := s.ticketRepo.FindAll(ctx, entity.TicketOpen, maxOpenTicketsSynced)
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	var errs []string
+	for _, ticket := range tickets {
+		done, err := s.syncTicket(ctx, ticket)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", ticket.ExternalKey, err))
+			continue
+		}
+		if done {
+			resolved++
+		}
+	}
+	if len(errs) > 0 {
+		return resolved, fmt.Errorf("%d ticket(s) failed to sync: %s", len(errs), strings.Join(errs, "; "))
+	}
+	return resolved, nil
+}
+
+// maxOpenTicketsSynced bounds the tickets a sync checks
+const maxOpenTicketsSynced = 500
+
+func (s *TicketService) syncTicket(ctx context.Context, ticket *entity.Ticket) (bool, error) {
+	if result, err := s.resultRepo.FindResultByID(ctx, ticket.ResultID); err == nil && result != nil &&
+		(result.Status == entity.StatusBlocked || result.Status == entity.StatusDetected || result.Detected) {
+		return true, s.resolve(ctx, ticket, result)
+	}
+
+	state, err := s.connector.GetTicket(ctx, ticket.ExternalKey)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if state.Closed {
+		ticket.ExternalStatus = state.Status
+		ticket.Resolve(entity.TicketResolutionClosed, now)
+		return true, s.ticketRepo.Update(ctx, ticket)
+	}
+	if state.Status != ticket.ExternalStatus {
+		ticket.ExternalStatus = state.Status
+		ticket.UpdatedAt = now
+		return false, s.ticketRepo.Update(ctx, ticket)
+	}
+	return false, nil
+}
+
+// List returns the most recent tickets, optionally filtered by status
+func (s *TicketService) List(ctx context.Context, status entity.TicketStatus, limit int) ([]*entity.Ticket, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.ticketRepo.FindAll(ctx, status, limit)
+}
+
+// Start starts the periodic status sync
+func (s *TicketService) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.runSync()
+}
+
+// Stop stops the periodic sync and the tickets waiting for their delay
+func (s *TicketService) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stopChan)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// stopped returns the channel closed when the service stops, nil when not started
+func (s *TicketService) stopped() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopChan
+}
+
+func (s *TicketService) runSync() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			if resolved, err := s.Sync(context.Background()); err != nil {
+				s.logger.Warn("Ticket sync failed", zap.Int("resolved", resolved), zap.Error(err))
+			}
+		}
+	}
+}
+
+// ticketData gathers the host, command, timestamps and remediation of an undetected result
+func (s *TicketService) ticketData(ctx context.Context, result *entity.ExecutionResult, scenarioName string) *TicketData {
+	data := &TicketData{
+		Technique:    &entity.Technique{ID: result.TechniqueID, Name: result.TechniqueID},
+		ScenarioName: scenarioName,
+		ExecutionID:  result.ExecutionID,
+		ResultID:     result.ID,
+		AgentPaw:     result.AgentPaw,
+		Host:         result.AgentPaw,
+		StartedAt:    result.StartedAt.UTC().Format(time.RFC3339),
+	}
+	if result.CompletedAt != nil {
+		data.CompletedAt = result.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	if s.config.DashboardURL != "" {
+		data.Link = strings.TrimRight(s.config.DashboardURL, "/") + "/executions/" + result.ExecutionID
+	}
+
+	agent, _ := s.agentRepo.FindByPaw(ctx, result.AgentPaw)
+	if agent != nil {
+		data.Host = firstNonEmptyString(agent.Hostname, agent.Paw)
+		data.Platform = agent.Platform
+	}
+	if scenarioName == "" {
+		if execution, err := s.resultRepo.FindExecutionByID(ctx, result.ExecutionID); err == nil && s.scenarioRepo != nil {
+			if scenario, err := s.scenarioRepo.FindByID(ctx, execution.ScenarioID); err == nil {
+				data.ScenarioName = scenario.Name
+			}
+		}
+	}
+
+	technique, err := s.techniqueRepo.FindByID(ctx, result.TechniqueID)
+	if err != nil || technique == nil {
+		return data
+	}
+	data.Technique = technique
+	data.Remediation = remediationSuggestions(technique)
+	if executor := resultExecutor(technique, result, agent); executor != nil {
+		data.Executor = executor.Type
+		data.Command = executor.Command
+	}
+	return data
+}
+
+// remediationSuggestions lists what to deploy to detect a technique: its
+// detection data sources, Sigma rules and references
+func remediationSuggestions(technique *entity.Technique) []string {
+	var suggestions []string
+	for _, detection := range technique.Detection {
+		suggestions = append(suggestions, fmt.Sprintf("Monitor %s for %s", detection.Source, detection.Indicator))
+	}
+	for _, rule := range technique.SigmaRules {
+		suggestions = append(suggestions, "Deploy the Sigma rule "+firstNonEmptyString(rule.Title, rule.ID)+" ("+rule.ID+")")
+	}
+	for _, reference := range technique.References {
+		suggestions = append(suggestions, "See "+reference)
+	}
+	return suggestions
+}
+
+// latestResults returns the latest result of each technique on each agent
+func latestResults(results []*entity.ExecutionResult) []*entity.ExecutionResult {
+	latest := make(map[defenseKey]*entity.ExecutionResult)
+	var order []defenseKey
+	for _, result := range results {
+		key := defenseKey{techniqueID: result.TechniqueID, agentPaw: result.AgentPaw}
+		existing, ok := latest[key]
+		if !ok {
+			order = append(order, key)
+		} else if existing.StartedAt.After(result.StartedAt) {
+			continue
+		}
+		latest[key] = result
+	}
+
+	selected := make([]*entity.ExecutionResult, 0, len(order))
+	for _, key := range order {
+		selected = append(selected, latest[key])
+	}
+	return selected
+}
+
+// renderTicketTemplate renders a ticket template with data
+func renderTicketTemplate(tmpl *template.Template, data *TicketData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render the %s template: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
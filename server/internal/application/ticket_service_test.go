@@ -0,0 +1,250 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockTicketRepo implements repository.TicketRepository for tests
+type mockTicketRepo struct {
+	tickets []*entity.Ticket
+}
+
+func (m *mockTicketRepo) Create(ctx context.Context, ticket *entity.Ticket) error {
+	m.tickets = append(m.tickets, ticket)
+	return nil
+}
+
+func (m *mockTicketRepo) Update(ctx context.Context, ticket *entity.Ticket) error {
+	return nil
+}
+
+func (m *mockTicketRepo) FindByID(ctx context.Context, id string) (*entity.Ticket, error) {
+	for _, ticket := range m.tickets {
+		if ticket.ID == id {
+			return ticket, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockTicketRepo) FindOpen(ctx context.Context, techniqueID, agentPaw string) (*entity.Ticket, error) {
+	for _, ticket := range m.tickets {
+		if ticket.Status == entity.TicketOpen && ticket.TechniqueID == techniqueID && ticket.AgentPaw == agentPaw {
+			return ticket, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockTicketRepo) FindAll(ctx context.Context, status entity.TicketStatus, limit int) ([]*entity.Ticket, error) {
+	var tickets []*entity.Ticket
+	for _, ticket := range m.tickets {
+		if status == "" || ticket.Status == status {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+// mockTicketConnector records the calls made to the tracker
+type mockTicketConnector struct {
+	created  []TicketRequest
+	comments map[string][]string
+	resolved map[string]string
+	states   map[string]*TicketState
+}
+
+func newMockTicketConnector() *mockTicketConnector {
+	return &mockTicketConnector{
+		comments: make(map[string][]string),
+		resolved: make(map[string]string),
+		states:   make(map[string]*TicketState),
+	}
+}
+
+func (m *mockTicketConnector) Name() string { return "mock" }
+
+func (m *mockTicketConnector) CreateTicket(ctx context.Context, req TicketRequest) (*TicketRef, error) {
+	m.created = append(m.created, req)
+	key := "SEC-" + string(rune('0'+len(m.created)))
+	return &TicketRef{Key: key, URL: "https://tracker/" + key}, nil
+}
+
+func (m *mockTicketConnector) GetTicket(ctx context.Context, key string) (*TicketState, error) {
+	if state, ok := m.states[key]; ok {
+		return state, nil
+	}
+	return &TicketState{Status: "To Do"}, nil
+}
+
+func (m *mockTicketConnector) CommentTicket(ctx context.Context, key, comment string) error {
+	m.comments[key] = append(m.comments[key], comment)
+	return nil
+}
+
+func (m *mockTicketConnector) ResolveTicket(ctx context.Context, key, comment string) error {
+	m.resolved[key] = comment
+	return nil
+}
+
+func setupTicketService() (*TicketService, *mockTicketRepo, *mockResultRepo, *mockTicketConnector) {
+	ticketRepo := &mockTicketRepo{}
+	resultRepo := newMockResultRepo()
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{
+		ID:         "T1059",
+		Name:       "Command and Scripting Interpreter",
+		Tactic:     entity.TacticExecution,
+		Platforms:  []string{"linux"},
+		Executors:  []entity.Executor{{Type: "sh", Command: "bash -c 'id'"}},
+		Detection:  []entity.Detection{{Source: "Process Creation", Indicator: "bash spawned by a service"}},
+		SigmaRules: []entity.SigmaRule{{ID: "rule-1", Title: "Suspicious Bash"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{Paw: "paw1", Hostname: "web-01", Platform: "linux", Executors: []string{"sh"}}
+	connector := newMockTicketConnector()
+	config := DefaultTicketConfig()
+	config.DashboardURL = "https://autostrike.local/"
+	svc := NewTicketService(ticketRepo, resultRepo, techRepo, agentRepo, newMockScenarioRepo(), connector, config, nil)
+	return svc, ticketRepo, resultRepo, connector
+}
+
+func ticketResult(id, executionID string, status entity.ResultStatus, detected bool) *entity.ExecutionResult {
+	completed := time.Date(2024, 5, 1, 10, 1, 0, 0, time.UTC)
+	return &entity.ExecutionResult{
+		ID: id, ExecutionID: executionID, TechniqueID: "T1059", AgentPaw: "paw1", Status: status, Detected: detected,
+		StartedAt: completed.Add(-time.Minute), CompletedAt: &completed,
+	}
+}
+
+func TestTicketService_OpensTemplatedTicket(t *testing.T) {
+	svc, ticketRepo, resultRepo, connector := setupTicketService()
+	ctx := context.Background()
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		ticketResult("r1", "e1", entity.StatusSuccess, false),
+		{ID: "r2", ExecutionID: "e1", TechniqueID: "T1082", AgentPaw: "paw1", Status: entity.StatusBlocked},
+	}
+
+	if err := svc.ProcessExecution(ctx, "e1", "Linux discovery"); err != nil {
+		t.Fatalf("ProcessExecution() error = %v", err)
+	}
+	if len(connector.created) != 1 || len(ticketRepo.tickets) != 1 {
+		t.Fatalf("expected one ticket, got %d created, %d stored", len(connector.created), len(ticketRepo.tickets))
+	}
+
+	req := connector.created[0]
+	if req.Summary != "[AutoStrike] T1059 Command and Scripting Interpreter not detected on web-01" {
+		t.Errorf("unexpected summary %q", req.Summary)
+	}
+	for _, want := range []string{
+		"Scenario: Linux discovery", "bash -c 'id'", "2024-05-01T10:00:00Z", "2024-05-01T10:01:00Z",
+		"Monitor Process Creation for bash spawned by a service", "Deploy the Sigma rule Suspicious Bash (rule-1)",
+		"https://autostrike.local/executions/e1",
+	} {
+		if !strings.Contains(req.Description, want) {
+			t.Errorf("expected the description to contain %q:\n%s", want, req.Description)
+		}
+	}
+	ticket := ticketRepo.tickets[0]
+	if ticket.ExternalKey != "SEC-1" || ticket.Provider != "mock" || ticket.Status != entity.TicketOpen || ticket.Occurrences != 1 {
+		t.Errorf("unexpected ticket %+v", ticket)
+	}
+}
+
+func TestTicketService_RepeatFailureCommentsOpenTicket(t *testing.T) {
+	svc, ticketRepo, resultRepo, connector := setupTicketService()
+	ctx := context.Background()
+	resultRepo.results["e1"] = []*entity.ExecutionResult{ticketResult("r1", "e1", entity.StatusSuccess, false)}
+	resultRepo.results["e2"] = []*entity.ExecutionResult{ticketResult("r2", "e2", entity.StatusSuccess, false)}
+
+	_ = svc.ProcessExecution(ctx, "e1", "")
+	_ = svc.ProcessExecution(ctx, "e1", "") // Processing an execution again changes nothing
+	if err := svc.ProcessExecution(ctx, "e2", ""); err != nil {
+		t.Fatalf("ProcessExecution() error = %v", err)
+	}
+
+	if len(connector.created) != 1 || len(connector.comments["SEC-1"]) != 1 {
+		t.Errorf("expected one ticket commented once, got %d created, comments %v", len(connector.created), connector.comments)
+	}
+	if ticket := ticketRepo.tickets[0]; ticket.Occurrences != 2 || ticket.ExecutionID != "e2" {
+		t.Errorf("expected the latest failure on the ticket, got %+v", ticket)
+	}
+}
+
+func TestTicketService_DefendedTechniqueResolvesTicket(t *testing.T) {
+	svc, ticketRepo, resultRepo, connector := setupTicketService()
+	ctx := context.Background()
+	resultRepo.results["e1"] = []*entity.ExecutionResult{ticketResult("r1", "e1", entity.StatusSuccess, false)}
+	resultRepo.results["e2"] = []*entity.ExecutionResult{ticketResult("r2", "e2", entity.StatusBlocked, false)}
+
+	_ = svc.ProcessExecution(ctx, "e1", "")
+	if err := svc.ProcessExecution(ctx, "e2", ""); err != nil {
+		t.Fatalf("ProcessExecution() error = %v", err)
+	}
+
+	ticket := ticketRepo.tickets[0]
+	if ticket.Status != entity.TicketResolved || ticket.Resolution != entity.TicketResolutionDefended || ticket.ResolvedAt == nil {
+		t.Errorf("expected the ticket resolved as defended, got %+v", ticket)
+	}
+	if !strings.Contains(connector.resolved["SEC-1"], "blocked in execution e2") {
+		t.Errorf("expected the ticket resolved in the tracker, got %v", connector.resolved)
+	}
+}
+
+func TestTicketService_Sync(t *testing.T) {
+	svc, ticketRepo, resultRepo, connector := setupTicketService()
+	ctx := context.Background()
+	resultRepo.results["e1"] = []*entity.ExecutionResult{ticketResult("r1", "e1", entity.StatusSuccess, false)}
+	resultRepo.results["e2"] = []*entity.ExecutionResult{
+		{ID: "r2", ExecutionID: "e2", TechniqueID: "T1082", AgentPaw: "paw1", Status: entity.StatusSuccess},
+	}
+	_ = svc.ProcessExecution(ctx, "e1", "")
+	_ = svc.ProcessExecution(ctx, "e2", "")
+
+	// The tracker moved SEC-1 along; SEC-2 was closed there
+	connector.states["SEC-1"] = &TicketState{Status: "In Progress"}
+	connector.states["SEC-2"] = &TicketState{Status: "Done", Closed: true}
+	resolved, err := svc.Sync(ctx)
+	if err != nil || resolved != 1 {
+		t.Fatalf("expected one ticket resolved, got %d (%v)", resolved, err)
+	}
+	if ticketRepo.tickets[0].ExternalStatus != "In Progress" || ticketRepo.tickets[0].Status != entity.TicketOpen {
+		t.Errorf("expected SEC-1 open with its tracker status, got %+v", ticketRepo.tickets[0])
+	}
+	if ticketRepo.tickets[1].Resolution != entity.TicketResolutionClosed {
+		t.Errorf("expected SEC-2 resolved as closed, got %+v", ticketRepo.tickets[1])
+	}
+
+	// Detection correlation later marked r1 detected
+	resultRepo.results["e1"][0].Detected = true
+	if resolved, err := svc.Sync(ctx); err != nil || resolved != 1 {
+		t.Fatalf("expected SEC-1 resolved, got %d (%v)", resolved, err)
+	}
+	if _, ok := connector.resolved["SEC-1"]; !ok || ticketRepo.tickets[0].Resolution != entity.TicketResolutionDefended {
+		t.Errorf("expected SEC-1 resolved in the tracker, got %+v", ticketRepo.tickets[0])
+	}
+}
+
+func TestTicketService_SetTemplates(t *testing.T) {
+	svc, _, resultRepo, connector := setupTicketService()
+	if err := svc.SetTemplates("{{.Technique.ID", ""); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+	if err := svc.SetTemplates("{{.Technique.ID}} on {{.Host}}", ""); err != nil {
+		t.Fatalf("SetTemplates() error = %v", err)
+	}
+
+	resultRepo.results["e1"] = []*entity.ExecutionResult{ticketResult("r1", "e1", entity.StatusSuccess, false)}
+	_ = svc.ProcessExecution(context.Background(), "e1", "")
+	if len(connector.created) != 1 || connector.created[0].Summary != "T1059 on web-01" {
+		t.Errorf("expected the custom summary, got %+v", connector.created)
+	}
+	if !strings.Contains(connector.created[0].Description, "Remediation suggestions") {
+		t.Error("expected the default description to be kept")
+	}
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans routes the spans of the test to an in-memory recorder
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestStartExecution_RecordsSpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:     "s1",
+		Phases: []entity.Phase{{Name: "Phase1", Techniques: []string{"T1059"}}},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{
+		ID:        "T1059",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "echo test"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{Paw: "paw1", Status: entity.AgentOnline, Platform: "linux", Executors: []string{"sh"}, LastSeen: time.Now()}
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	svc := NewExecutionService(newMockResultRepo(), scenarioRepo, techRepo, agentRepo, orchestrator, service.NewScoreCalculator())
+
+	result, err := svc.StartExecution(context.Background(), "s1", []string{"paw1"}, false)
+	if err != nil {
+		t.Fatalf("StartExecution() error = %v", err)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	start, plan := spans["ExecutionService.StartExecution"], spans["AttackOrchestrator.PlanExecution"]
+	if start == nil || plan == nil {
+		t.Fatalf("expected execution and planning spans, got %v", spans)
+	}
+	if plan.Parent().SpanID() != start.SpanContext().SpanID() {
+		t.Error("expected the planning span to be a child of the execution span")
+	}
+	found := false
+	for _, attr := range start.Attributes() {
+		if attr.Key == "execution.id" && attr.Value.AsString() == result.Execution.ID {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected the execution span to carry the execution ID")
+	}
+}
+
+func TestUpdateResultByID_RecordsErrorSpan(t *testing.T) {
+	recorder := recordSpans(t)
+
+	svc := NewExecutionService(newMockResultRepo(), nil, nil, nil, nil, nil)
+	if err := svc.UpdateResultByID(context.Background(), "missing", entity.StatusSuccess, "", 0, ""); err == nil {
+		t.Fatal("expected an error for an unknown result")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "ExecutionService.UpdateResultByID" {
+		t.Fatalf("expected an UpdateResultByID span, got %v", spans)
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Errorf("expected an error status, got %v", spans[0].Status().Code)
+	}
+}
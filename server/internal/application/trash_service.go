@@ -0,0 +1,110 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"go.uber.org/zap"
+)
+
+// DefaultTrashRetention is how long deleted scenarios and techniques stay in the trash by default
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// ErrNotInTrash is returned when restoring a scenario or technique that is not in the trash
+var ErrNotInTrash = errors.New("not in the trash")
+
+// TrashService lists, restores and purges the deleted scenarios and techniques.
+// Deleting only moves them to the trash, so past executions keep their scenario
+// and results their technique.
+type TrashService struct {
+	repo        repository.TrashRepository
+	retention   time.Duration
+	invalidator interface{ Invalidate() }
+	logger      *zap.Logger
+}
+
+// NewTrashService creates a trash service purging after retention; zero keeps deleted items forever
+func NewTrashService(repo repository.TrashRepository, retention time.Duration, logger *zap.Logger) *TrashService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &TrashService{repo: repo, retention: retention, logger: logger}
+}
+
+// SetTechniqueCache invalidates the technique cache when a technique is restored
+func (s *TrashService) SetTechniqueCache(cache interface{ Invalidate() }) {
+	s.invalidator = cache
+}
+
+// Retention returns how long deleted items stay in the trash, zero meaning forever
+func (s *TrashService) Retention() time.Duration {
+	return s.retention
+}
+
+// ListScenarios returns the scenarios in the trash
+func (s *TrashService) ListScenarios(ctx context.Context) ([]*entity.Scenario, error) {
+	return s.repo.FindDeletedScenarios(ctx)
+}
+
+// ListTechniques returns the techniques in the trash
+func (s *TrashService) ListTechniques(ctx context.Context) ([]*entity.Technique, error) {
+	return s.repo.FindDeletedTechniques(ctx)
+}
+
+// RestoreScenario takes a scenario out of the trash
+func (s *TrashService) RestoreScenario(ctx context.Context, id string) error {
+	restored, err := s.repo.RestoreScenario(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore scenario: %w", err)
+	}
+	if !restored {
+		return fmt.Errorf("scenario %s: %w", id, ErrNotInTrash)
+	}
+	return nil
+}
+
+// RestoreTechnique takes a technique out of the trash
+func (s *TrashService) RestoreTechnique(ctx context.Context, id string) error {
+	restored, err := s.repo.RestoreTechnique(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore technique: %w", err)
+	}
+	if !restored {
+		return fmt.Errorf("technique %s: %w", id, ErrNotInTrash)
+	}
+	if s.invalidator != nil {
+		s.invalidator.Invalidate()
+	}
+	return nil
+}
+
+// Purge permanently deletes the items kept in the trash for longer than the
+// retention, except those past executions still reference. Returns the number
+// of scenarios and techniques deleted.
+func (s *TrashService) Purge(ctx context.Context, now time.Time) (int64, int64, error) {
+	if s.retention <= 0 {
+		return 0, 0, nil
+	}
+	cutoff := now.Add(-s.retention)
+
+	scenarios, err := s.repo.PurgeScenarios(ctx, cutoff)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to purge scenarios: %w", err)
+	}
+	techniques, err := s.repo.PurgeTechniques(ctx, cutoff)
+	if err != nil {
+		return scenarios, 0, fmt.Errorf("failed to purge techniques: %w", err)
+	}
+	if scenarios > 0 || techniques > 0 {
+		s.logger.Info("Purged the trash",
+			zap.Int64("scenarios", scenarios),
+			zap.Int64("techniques", techniques),
+		)
+	}
+	return scenarios, techniques, nil
+}
@@ -0,0 +1,167 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockTrashRepo implements repository.TrashRepository for tests
+type mockTrashRepo struct {
+	scenarios  map[string]*entity.Scenario
+	techniques map[string]*entity.Technique
+	cutoffs    []time.Time
+	err        error
+}
+
+func newMockTrashRepo() *mockTrashRepo {
+	return &mockTrashRepo{
+		scenarios:  make(map[string]*entity.Scenario),
+		techniques: make(map[string]*entity.Technique),
+	}
+}
+
+func (m *mockTrashRepo) FindDeletedScenarios(ctx context.Context) ([]*entity.Scenario, error) {
+	var scenarios []*entity.Scenario
+	for _, s := range m.scenarios {
+		scenarios = append(scenarios, s)
+	}
+	return scenarios, m.err
+}
+
+func (m *mockTrashRepo) FindDeletedTechniques(ctx context.Context) ([]*entity.Technique, error) {
+	var techniques []*entity.Technique
+	for _, t := range m.techniques {
+		techniques = append(techniques, t)
+	}
+	return techniques, m.err
+}
+
+func (m *mockTrashRepo) RestoreScenario(ctx context.Context, id string) (bool, error) {
+	_, ok := m.scenarios[id]
+	delete(m.scenarios, id)
+	return ok, m.err
+}
+
+func (m *mockTrashRepo) RestoreTechnique(ctx context.Context, id string) (bool, error) {
+	_, ok := m.techniques[id]
+	delete(m.techniques, id)
+	return ok, m.err
+}
+
+func (m *mockTrashRepo) PurgeScenarios(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.cutoffs = append(m.cutoffs, deletedBefore)
+	var n int64
+	for id, s := range m.scenarios {
+		if s.DeletedAt.Before(deletedBefore) {
+			delete(m.scenarios, id)
+			n++
+		}
+	}
+	return n, m.err
+}
+
+func (m *mockTrashRepo) PurgeTechniques(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.cutoffs = append(m.cutoffs, deletedBefore)
+	var n int64
+	for id, t := range m.techniques {
+		if t.DeletedAt.Before(deletedBefore) {
+			delete(m.techniques, id)
+			n++
+		}
+	}
+	return n, m.err
+}
+
+// countingInvalidator counts the technique cache invalidations
+type countingInvalidator struct {
+	calls int
+}
+
+func (c *countingInvalidator) Invalidate() {
+	c.calls++
+}
+
+func TestTrashService_Restore(t *testing.T) {
+	deletedAt := time.Now()
+	repo := newMockTrashRepo()
+	repo.scenarios["s1"] = &entity.Scenario{ID: "s1", DeletedAt: &deletedAt}
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059", DeletedAt: &deletedAt}
+	cache := &countingInvalidator{}
+	service := NewTrashService(repo, DefaultTrashRetention, nil)
+	service.SetTechniqueCache(cache)
+	ctx := context.Background()
+
+	if err := service.RestoreScenario(ctx, "s1"); err != nil {
+		t.Fatalf("RestoreScenario failed: %v", err)
+	}
+	if err := service.RestoreScenario(ctx, "s1"); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected ErrNotInTrash, got %v", err)
+	}
+	if err := service.RestoreTechnique(ctx, "T1059"); err != nil {
+		t.Fatalf("RestoreTechnique failed: %v", err)
+	}
+	if cache.calls != 1 {
+		t.Errorf("Expected the technique cache invalidated once, got %d", cache.calls)
+	}
+	if err := service.RestoreTechnique(ctx, "T1059"); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected ErrNotInTrash, got %v", err)
+	}
+	if cache.calls != 1 {
+		t.Errorf("Expected no invalidation when nothing was restored, got %d", cache.calls)
+	}
+}
+
+func TestTrashService_RestoreError(t *testing.T) {
+	repo := newMockTrashRepo()
+	repo.err = errors.New("db error")
+	service := NewTrashService(repo, DefaultTrashRetention, nil)
+
+	err := service.RestoreScenario(context.Background(), "s1")
+	if err == nil || errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Expected a repository error, got %v", err)
+	}
+}
+
+func TestTrashService_Purge(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	recent := now.Add(-time.Hour)
+	repo := newMockTrashRepo()
+	repo.scenarios["old"] = &entity.Scenario{ID: "old", DeletedAt: &old}
+	repo.scenarios["recent"] = &entity.Scenario{ID: "recent", DeletedAt: &recent}
+	repo.techniques["T1"] = &entity.Technique{ID: "T1", DeletedAt: &old}
+	service := NewTrashService(repo, 24*time.Hour, nil)
+
+	scenarios, techniques, err := service.Purge(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if scenarios != 1 || techniques != 1 {
+		t.Errorf("Expected 1 scenario and 1 technique purged, got %d and %d", scenarios, techniques)
+	}
+	if _, ok := repo.scenarios["recent"]; !ok {
+		t.Error("Expected the recently deleted scenario kept")
+	}
+	if !repo.cutoffs[0].Equal(now.Add(-24 * time.Hour)) {
+		t.Errorf("Expected the cutoff one retention ago, got %v", repo.cutoffs[0])
+	}
+}
+
+func TestTrashService_PurgeDisabled(t *testing.T) {
+	old := time.Now().Add(-365 * 24 * time.Hour)
+	repo := newMockTrashRepo()
+	repo.scenarios["old"] = &entity.Scenario{ID: "old", DeletedAt: &old}
+	service := NewTrashService(repo, 0, nil)
+
+	scenarios, techniques, err := service.Purge(context.Background(), time.Now())
+	if err != nil || scenarios != 0 || techniques != 0 {
+		t.Errorf("Expected nothing purged, got %d, %d (%v)", scenarios, techniques, err)
+	}
+	if len(repo.cutoffs) != 0 {
+		t.Error("Expected the repository not asked to purge")
+	}
+}
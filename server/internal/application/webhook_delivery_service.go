@@ -0,0 +1,321 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Headers set on outgoing webhook requests
+const (
+	WebhookSignatureHeader = "X-AutoStrike-Signature" // "sha256=" + hex HMAC of "<timestamp>.<body>"
+	WebhookTimestampHeader = "X-AutoStrike-Timestamp" // Unix seconds of the attempt
+	WebhookDeliveryHeader  = "X-AutoStrike-Delivery"  // Delivery ID, stable across retries
+	WebhookEventHeader     = "X-AutoStrike-Event"
+)
+
+// ErrWebhookDeliveryNotFound is returned when a delivery does not exist or belongs to another user
+var ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+// WebhookDeliveryConfig controls retries of failed webhook deliveries
+type WebhookDeliveryConfig struct {
+	MaxAttempts  int           // Attempts before a delivery is marked failed
+	BaseDelay    time.Duration // Delay before the first retry, doubled after each failure
+	MaxDelay     time.Duration // Upper bound of the retry delay
+	PollInterval time.Duration // How often due retries are looked for
+}
+
+// DefaultWebhookDeliveryConfig returns the default retry settings: six attempts
+// spread over about a quarter of an hour
+func DefaultWebhookDeliveryConfig() WebhookDeliveryConfig {
+	return WebhookDeliveryConfig{
+		MaxAttempts:  6,
+		BaseDelay:    30 * time.Second,
+		MaxDelay:     time.Hour,
+		PollInterval: 15 * time.Second,
+	}
+}
+
+// SignWebhookPayload computes the signature header value of a webhook body, so
+// receivers can check it came from AutoStrike with the shared secret
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookHeaders builds the AutoStrike headers of a webhook request. The
+// signature is only added when a secret is configured.
+func webhookHeaders(secret, event, deliveryID string, body []byte, now time.Time) map[string]string {
+	headers := map[string]string{
+		WebhookEventHeader:     event,
+		WebhookTimestampHeader: strconv.FormatInt(now.Unix(), 10),
+	}
+	if deliveryID != "" {
+		headers[WebhookDeliveryHeader] = deliveryID
+	}
+	if secret != "" {
+		headers[WebhookSignatureHeader] = SignWebhookPayload(secret, now.Unix(), body)
+	}
+	return headers
+}
+
+// WebhookDeliveryService persists outgoing webhooks, retries failed ones with
+// exponential backoff and exposes the delivery log
+type WebhookDeliveryService struct {
+	repo             repository.WebhookDeliveryRepository
+	notificationRepo repository.NotificationRepository // Signing secrets, read at each attempt
+	client           *http.Client
+	config           WebhookDeliveryConfig
+	logger           *zap.Logger
+	semaphore        chan struct{} // Bounds concurrent first attempts
+	stopChan         chan struct{}
+	wg               sync.WaitGroup
+	running          bool
+	mu               sync.Mutex
+}
+
+// NewWebhookDeliveryService creates a new webhook delivery service
+func NewWebhookDeliveryService(
+	repo repository.WebhookDeliveryRepository,
+	notificationRepo repository.NotificationRepository,
+	config WebhookDeliveryConfig,
+	logger *zap.Logger,
+) *WebhookDeliveryService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &WebhookDeliveryService{
+		repo:             repo,
+		notificationRepo: notificationRepo,
+		client:           &http.Client{Timeout: webhookTimeout},
+		config:           config,
+		logger:           logger,
+		semaphore:        make(chan struct{}, 10),
+	}
+}
+
+// Enqueue persists a delivery and makes its first attempt in the background.
+// Failed attempts are picked up again by the retry loop.
+func (s *WebhookDeliveryService) Enqueue(
+	ctx context.Context,
+	userID string,
+	channel entity.NotificationChannel,
+	event entity.NotificationType,
+	url string,
+	payload any,
+) (*entity.WebhookDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	// The next attempt is set a retry delay ahead so the retry loop leaves the
+	// first attempt alone, and still picks it up if the server stops meanwhile
+	now := time.Now()
+	next := now.Add(s.config.BaseDelay)
+	delivery := &entity.WebhookDelivery{
+		ID:            uuid.New().String(),
+		UserID:        userID,
+		Channel:       channel,
+		Event:         event,
+		URL:           url,
+		Payload:       body,
+		Status:        entity.WebhookDeliveryPending,
+		NextAttemptAt: &next,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.repo.Create(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to queue webhook delivery: %w", err)
+	}
+
+	go func() {
+		s.semaphore <- struct{}{}
+		defer func() { <-s.semaphore }()
+		s.attempt(context.Background(), delivery)
+	}()
+
+	return delivery, nil
+}
+
+// attempt sends a delivery once and records the outcome
+func (s *WebhookDeliveryService) attempt(ctx context.Context, delivery *entity.WebhookDelivery) {
+	now := time.Now()
+	statusCode, err := s.send(ctx, delivery, now)
+	if err == nil {
+		delivery.RecordSuccess(statusCode, now)
+	} else {
+		var next *time.Time
+		if delivery.Attempts+1 < s.config.MaxAttempts {
+			at := now.Add(s.retryDelay(delivery.Attempts + 1))
+			next = &at
+		}
+		delivery.RecordFailure(statusCode, err.Error(), now, next)
+		s.logger.Warn("Webhook delivery failed",
+			zap.String("delivery_id", delivery.ID),
+			zap.String("event", string(delivery.Event)),
+			zap.Int("attempt", delivery.Attempts),
+			zap.Bool("will_retry", next != nil),
+			zap.Error(err),
+		)
+	}
+
+	if err := s.repo.Update(ctx, delivery); err != nil {
+		s.logger.Error("Failed to save webhook delivery", zap.String("delivery_id", delivery.ID), zap.Error(err))
+	}
+}
+
+// send posts the delivery payload, signed with the current secret of the
+// user's settings for the generic webhook channel. Opsgenie alerts carry the
+// current API key of the settings instead.
+func (s *WebhookDeliveryService) send(ctx context.Context, delivery *entity.WebhookDelivery, now time.Time) (int, error) {
+	var settings *entity.NotificationSettings
+	if (delivery.Channel == entity.ChannelWebhook || delivery.Channel == entity.ChannelOpsgenie) && s.notificationRepo != nil {
+		var err error
+		settings, err = s.notificationRepo.FindSettingsByUserID(ctx, delivery.UserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("failed to load notification settings: %w", err)
+		}
+	}
+
+	var secret string
+	if delivery.Channel == entity.ChannelWebhook && settings != nil {
+		secret = settings.WebhookSecret
+	}
+	headers := webhookHeaders(secret, string(delivery.Event), delivery.ID, delivery.Payload, now)
+	if delivery.Channel == entity.ChannelOpsgenie && settings != nil {
+		for name, value := range opsgenieHeaders(settings.OpsgenieAPIKey) {
+			headers[name] = value
+		}
+	}
+	return postWebhook(ctx, s.client, delivery.URL, delivery.Payload, headers)
+}
+
+// retryDelay returns the wait before the retry following the given attempt
+func (s *WebhookDeliveryService) retryDelay(attempt int) time.Duration {
+	delay := s.config.BaseDelay
+	for i := 1; i < attempt && delay < s.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.config.MaxDelay {
+		delay = s.config.MaxDelay
+	}
+	return delay
+}
+
+// ProcessDue attempts every pending delivery whose retry is due and returns
+// how many were attempted
+func (s *WebhookDeliveryService) ProcessDue(ctx context.Context) int {
+	deliveries, err := s.repo.FindDue(ctx, time.Now(), 50)
+	if err != nil {
+		s.logger.Error("Failed to find due webhook deliveries", zap.Error(err))
+		return 0
+	}
+
+	for _, delivery := range deliveries {
+		s.attempt(ctx, delivery)
+	}
+	return len(deliveries)
+}
+
+// Start starts the background retry loop
+func (s *WebhookDeliveryService) Start() {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.runRetries()
+}
+
+// Stop stops the background retry loop
+func (s *WebhookDeliveryService) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stopChan)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *WebhookDeliveryService) runRetries() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+			s.ProcessDue(context.Background())
+		}
+	}
+}
+
+// List returns the most recent deliveries of a user, optionally filtered by status
+func (s *WebhookDeliveryService) List(
+	ctx context.Context,
+	userID string,
+	status entity.WebhookDeliveryStatus,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	return s.repo.FindByUserID(ctx, userID, status, limit)
+}
+
+// Get returns a delivery of a user
+func (s *WebhookDeliveryService) Get(ctx context.Context, id, userID string) (*entity.WebhookDelivery, error) {
+	delivery, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	if delivery == nil || delivery.UserID != userID {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// Redeliver attempts a delivery again right away, whatever its status, and
+// returns it with the outcome of the attempt
+func (s *WebhookDeliveryService) Redeliver(ctx context.Context, id, userID string) (*entity.WebhookDelivery, error) {
+	delivery, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Status = entity.WebhookDeliveryPending
+	delivery.DeliveredAt = nil
+	s.attempt(ctx, delivery)
+	return delivery, nil
+}
@@ -0,0 +1,365 @@
+package application
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockWebhookDeliveryRepo implements repository.WebhookDeliveryRepository for tests.
+// Deliveries are stored as copies since attempts run in the background.
+type mockWebhookDeliveryRepo struct {
+	mu         sync.Mutex
+	deliveries map[string]entity.WebhookDelivery
+	err        error
+}
+
+func newMockWebhookDeliveryRepo() *mockWebhookDeliveryRepo {
+	return &mockWebhookDeliveryRepo{deliveries: make(map[string]entity.WebhookDelivery)}
+}
+
+func (m *mockWebhookDeliveryRepo) Create(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (m *mockWebhookDeliveryRepo) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (m *mockWebhookDeliveryRepo) FindByID(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &delivery, nil
+}
+
+func (m *mockWebhookDeliveryRepo) FindByUserID(ctx context.Context, userID string, status entity.WebhookDeliveryStatus, limit int) ([]*entity.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*entity.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.UserID == userID && (status == "" || delivery.Status == status) {
+			d := delivery
+			result = append(result, &d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookDeliveryRepo) FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*entity.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == entity.WebhookDeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			d := delivery
+			result = append(result, &d)
+		}
+	}
+	return result, nil
+}
+
+// get returns a copy of a stored delivery
+func (m *mockWebhookDeliveryRepo) get(id string) entity.WebhookDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deliveries[id]
+}
+
+// makeDue moves the next attempt of a delivery to the past
+func (m *mockWebhookDeliveryRepo) makeDue(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery := m.deliveries[id]
+	past := time.Now().Add(-time.Second)
+	delivery.NextAttemptAt = &past
+	m.deliveries[id] = delivery
+}
+
+// waitForAttempts waits until a delivery has been attempted the given number of times
+func waitForAttempts(t *testing.T, repo *mockWebhookDeliveryRepo, id string, attempts int) entity.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if delivery := repo.get(id); delivery.Attempts >= attempts {
+			return delivery
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d attempts of delivery %s", attempts, id)
+	return entity.WebhookDelivery{}
+}
+
+func testWebhookDeliveryConfig() WebhookDeliveryConfig {
+	return WebhookDeliveryConfig{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute, PollInterval: time.Hour}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"event":"execution_completed"}`)
+
+	signature := SignWebhookPayload("0123456789abcdef", 1700000000, body)
+	if len(signature) != len("sha256=")+64 || signature[:7] != "sha256=" {
+		t.Fatalf("Unexpected signature format: %s", signature)
+	}
+	if SignWebhookPayload("0123456789abcdef", 1700000000, body) != signature {
+		t.Error("Signature should be deterministic")
+	}
+	if SignWebhookPayload("another-secret-value", 1700000000, body) == signature {
+		t.Error("Signature should depend on the secret")
+	}
+	if SignWebhookPayload("0123456789abcdef", 1700000001, body) == signature {
+		t.Error("Signature should depend on the timestamp")
+	}
+}
+
+func TestWebhookDeliveryService_EnqueueSignsAndDelivers(t *testing.T) {
+	const secret = "0123456789abcdef"
+	var verified atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		verified.Store(r.Header.Get(WebhookSignatureHeader) == SignWebhookPayload(secret, timestamp, payload) &&
+			r.Header.Get(WebhookEventHeader) == "execution_completed" &&
+			r.Header.Get(WebhookDeliveryHeader) != "")
+	}))
+	defer server.Close()
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{ID: "set-1", UserID: "u1", WebhookSecret: secret}
+	repo := newMockWebhookDeliveryRepo()
+	svc := NewWebhookDeliveryService(repo, notificationRepo, testWebhookDeliveryConfig(), nil)
+
+	queued, err := svc.Enqueue(context.Background(), "u1", entity.ChannelWebhook, entity.NotificationExecutionCompleted,
+		server.URL, &WebhookPayload{Event: entity.NotificationExecutionCompleted})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	id := queued.ID
+
+	delivery := waitForAttempts(t, repo, id, 1)
+	if delivery.Status != entity.WebhookDeliveryDelivered || delivery.LastStatusCode != http.StatusOK || delivery.DeliveredAt == nil {
+		t.Errorf("Expected a delivered delivery, got %+v", delivery)
+	}
+	if !verified.Load() {
+		t.Error("Expected a valid signature and AutoStrike headers")
+	}
+}
+
+func TestWebhookDeliveryService_OpsgenieAPIKey(t *testing.T) {
+	var auth, signature atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth.Store(r.Header.Get("Authorization"))
+		signature.Store(r.Header.Get(WebhookSignatureHeader))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", WebhookSecret: "0123456789abcdef", OpsgenieAPIKey: "genie-key",
+	}
+	repo := newMockWebhookDeliveryRepo()
+	svc := NewWebhookDeliveryService(repo, notificationRepo, testWebhookDeliveryConfig(), nil)
+
+	queued, err := svc.Enqueue(context.Background(), "u1", entity.ChannelOpsgenie, entity.NotificationScoreAlert,
+		server.URL, &OpsgenieAlert{Message: "Low Score Alert"})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	if delivery := waitForAttempts(t, repo, queued.ID, 1); delivery.Status != entity.WebhookDeliveryDelivered {
+		t.Errorf("Expected a delivered alert, got %+v", delivery)
+	}
+	if auth.Load() != "GenieKey genie-key" || signature.Load() != "" {
+		t.Errorf("Expected the API key without a webhook signature, got %q %q", auth.Load(), signature.Load())
+	}
+}
+
+func TestWebhookDeliveryService_RetriesWithBackoffThenFails(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := newMockWebhookDeliveryRepo()
+	svc := NewWebhookDeliveryService(repo, nil, testWebhookDeliveryConfig(), nil)
+
+	queued, err := svc.Enqueue(context.Background(), "u1", entity.ChannelTeams, entity.NotificationExecutionFailed, server.URL, map[string]string{"type": "message"})
+	if err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	id := queued.ID
+
+	delivery := waitForAttempts(t, repo, id, 1)
+	if delivery.Status != entity.WebhookDeliveryPending || delivery.LastStatusCode != http.StatusServiceUnavailable || delivery.NextAttemptAt == nil {
+		t.Fatalf("Expected a pending retry, got %+v", delivery)
+	}
+	if wait := time.Until(*delivery.NextAttemptAt); wait < 50*time.Second || wait > time.Minute {
+		t.Errorf("Expected the first retry in about a minute, got %v", wait)
+	}
+
+	// Not due yet
+	if n := svc.ProcessDue(context.Background()); n != 0 {
+		t.Errorf("Expected no due delivery, got %d", n)
+	}
+
+	repo.makeDue(id)
+	if n := svc.ProcessDue(context.Background()); n != 1 {
+		t.Fatalf("Expected one due delivery, got %d", n)
+	}
+	delivery = repo.get(id)
+	if delivery.Attempts != 2 || delivery.Status != entity.WebhookDeliveryPending {
+		t.Fatalf("Expected a second pending attempt, got %+v", delivery)
+	}
+
+	repo.makeDue(id)
+	svc.ProcessDue(context.Background())
+	delivery = repo.get(id)
+	if delivery.Attempts != 3 || delivery.Status != entity.WebhookDeliveryFailed || delivery.NextAttemptAt != nil {
+		t.Errorf("Expected the delivery to fail after the last attempt, got %+v", delivery)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %d", calls.Load())
+	}
+}
+
+func TestWebhookDeliveryService_RetryDelay(t *testing.T) {
+	svc := NewWebhookDeliveryService(newMockWebhookDeliveryRepo(), nil, DefaultWebhookDeliveryConfig(), nil)
+
+	expected := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute}
+	for i, want := range expected {
+		if got := svc.retryDelay(i + 1); got != want {
+			t.Errorf("retryDelay(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+	if got := svc.retryDelay(20); got != time.Hour {
+		t.Errorf("Expected the delay to be capped at an hour, got %v", got)
+	}
+}
+
+func TestWebhookDeliveryService_GetAndRedeliver(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	repo := newMockWebhookDeliveryRepo()
+	svc := NewWebhookDeliveryService(repo, nil, testWebhookDeliveryConfig(), nil)
+	queued, _ := svc.Enqueue(context.Background(), "u1", entity.ChannelWebhook, entity.NotificationAgentOffline, server.URL, map[string]string{})
+	id := queued.ID
+	waitForAttempts(t, repo, id, 1)
+
+	if _, err := svc.Get(context.Background(), id, "someone-else"); !errors.Is(err, ErrWebhookDeliveryNotFound) {
+		t.Errorf("Expected ErrWebhookDeliveryNotFound for another user, got %v", err)
+	}
+	if _, err := svc.Redeliver(context.Background(), "missing", "u1"); !errors.Is(err, ErrWebhookDeliveryNotFound) {
+		t.Errorf("Expected ErrWebhookDeliveryNotFound, got %v", err)
+	}
+
+	fail.Store(false)
+	delivery, err := svc.Redeliver(context.Background(), id, "u1")
+	if err != nil {
+		t.Fatalf("Redeliver failed: %v", err)
+	}
+	if delivery.Status != entity.WebhookDeliveryDelivered || delivery.Attempts != 2 {
+		t.Errorf("Expected the redelivery to succeed, got %+v", delivery)
+	}
+
+	deliveries, _ := svc.List(context.Background(), "u1", entity.WebhookDeliveryDelivered, 10)
+	if len(deliveries) != 1 {
+		t.Errorf("Expected 1 delivered delivery, got %d", len(deliveries))
+	}
+}
+
+func TestWebhookDeliveryService_EnqueueRepositoryError(t *testing.T) {
+	repo := newMockWebhookDeliveryRepo()
+	repo.err = errors.New("db error")
+	svc := NewWebhookDeliveryService(repo, nil, testWebhookDeliveryConfig(), nil)
+
+	if _, err := svc.Enqueue(context.Background(), "u1", entity.ChannelWebhook, entity.NotificationAgentOffline, "http://localhost", map[string]string{}); err == nil {
+		t.Error("Expected an error when the delivery cannot be persisted")
+	}
+}
+
+func TestWebhookDeliveryService_StartStop(t *testing.T) {
+	svc := NewWebhookDeliveryService(newMockWebhookDeliveryRepo(), nil, testWebhookDeliveryConfig(), nil)
+	svc.Start()
+	svc.Start()
+	svc.Stop()
+	svc.Stop()
+}
+
+func TestNotificationService_QueuesWebhooks(t *testing.T) {
+	received := make(chan *http.Request, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r
+	}))
+	defer server.Close()
+
+	notificationRepo := newMockNotificationRepo()
+	notificationRepo.settings["set-1"] = &entity.NotificationSettings{
+		ID: "set-1", UserID: "u1", Enabled: true,
+		WebhookURL: server.URL, TeamsWebhookURL: server.URL, WebhookSecret: "0123456789abcdef",
+		Preferences: entity.NotificationPreferences{
+			entity.NotificationExecutionFailed: {entity.ChannelWebhook, entity.ChannelTeams},
+		},
+	}
+	repo := newMockWebhookDeliveryRepo()
+	svc := NewNotificationService(notificationRepo, nil, nil, "https://autostrike.local", nil)
+	svc.SetWebhookDeliveryService(NewWebhookDeliveryService(repo, notificationRepo, testWebhookDeliveryConfig(), nil))
+
+	execution := &entity.Execution{ID: "exec-1", StartedAt: time.Now()}
+	_ = svc.NotifyExecutionFailed(context.Background(), execution, "Scenario", "agent lost")
+
+	signed := 0
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-received:
+			if r.Header.Get(WebhookDeliveryHeader) == "" {
+				t.Error("Expected queued requests to carry a delivery ID")
+			}
+			if r.Header.Get(WebhookSignatureHeader) != "" {
+				signed++
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for webhook requests")
+		}
+	}
+	if signed != 1 {
+		t.Errorf("Expected only the generic webhook to be signed, got %d signed requests", signed)
+	}
+
+	deliveries, _ := repo.FindByUserID(context.Background(), "u1", "", 10)
+	if len(deliveries) != 2 {
+		t.Errorf("Expected webhook and Teams deliveries to be logged, got %d", len(deliveries))
+	}
+}
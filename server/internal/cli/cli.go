@@ -0,0 +1,200 @@
+// Package cli implements autostrikectl, the command-line client operators use
+// to script the AutoStrike server through its REST API.
+package cli
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Environment variables read for the global flags
+const (
+	EnvServer = "AUTOSTRIKE_SERVER"
+	EnvAPIKey = "AUTOSTRIKE_API_KEY"
+)
+
+// defaultServer is the server address when neither --server nor AUTOSTRIKE_SERVER is set
+const defaultServer = "https://localhost:8443"
+
+// usageError reports a command line the command cannot run; its usage is printed
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// app holds what the commands share: the global flags, the API client and
+// the outputs
+type app struct {
+	client *Client
+	stdin  io.Reader // Answers to confirmation prompts, secret values
+	stdout io.Writer
+	stderr io.Writer
+	json   bool          // Print raw JSON instead of tables
+	poll   time.Duration // Interval between two polls of a watched execution
+
+	server   string
+	apiKey   string
+	insecure bool
+	started  bool // A command started running: its errors are no longer usage errors
+}
+
+// Run runs autostrikectl with its command-line arguments and returns the exit
+// code: 0 on success, 1 when the command failed, 2 on a usage error
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	a := &app{stdin: os.Stdin, stdout: stdout, stderr: stderr, poll: 2 * time.Second}
+	return a.execute(ctx, args)
+}
+
+// execute runs a command line and returns its exit code
+func (a *app) execute(ctx context.Context, args []string) int {
+	a.started = false
+	root := a.newRootCommand()
+	root.SetArgs(args)
+	cmd, err := root.ExecuteContextC(ctx)
+	if err == nil {
+		return 0
+	}
+
+	// Cobra fails on unknown commands and flags, missing arguments and
+	// required flags before the command runs
+	var usage usageError
+	if errors.As(err, &usage) || !a.started {
+		fmt.Fprintf(a.stderr, "autostrikectl: %v\n\n%s", err, cmd.UsageString())
+		return 2
+	}
+	fmt.Fprintf(a.stderr, "autostrikectl: %v\n", err)
+	return 1
+}
+
+// newRootCommand builds the command tree of autostrikectl
+func (a *app) newRootCommand() *cobra.Command {
+	root := a.group("autostrikectl", "Command-line client of the AutoStrike server",
+		a.group("agents", "Manage agents",
+			a.listAgentsCommand(),
+		),
+		a.group("scenarios", "Manage scenarios",
+			a.listScenariosCommand(),
+			a.importScenariosCommand(),
+		),
+		a.group("content", "Plan and apply technique and scenario YAML (admin)",
+			a.planContentCommand(),
+			a.applyContentCommand(),
+			a.reloadContentCommand(),
+		),
+		a.group("secrets", "Manage the secrets store of integration credentials (admin)",
+			a.listSecretsCommand(),
+			a.setSecretCommand(),
+			a.deleteSecretCommand(),
+		),
+		a.group("executions", "Launch and follow executions",
+			a.listExecutionsCommand(),
+			a.startExecutionCommand(),
+			a.watchExecutionCommand(),
+			a.exportExecutionCommand(),
+		),
+		a.group("users", "Manage users (admin)",
+			a.listUsersCommand(),
+			a.createUserCommand(),
+			a.setUserRoleCommand(),
+			a.deactivateUserCommand(),
+			a.reactivateUserCommand(),
+		),
+	)
+	root.Long = "autostrikectl scripts the AutoStrike server through its REST API. It authenticates with an\n" +
+		"API key created under /api/v1/auth/api-keys."
+	root.SilenceErrors, root.SilenceUsage = true, true
+	root.SetOut(a.stdout)
+	root.SetErr(a.stderr)
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&a.server, "server", envOr(EnvServer, defaultServer), "Server URL ("+EnvServer+")")
+	flags.StringVar(&a.apiKey, "api-key", "", "API key ("+EnvAPIKey+")")
+	flags.BoolVar(&a.insecure, "insecure", false, "Skip the verification of the server certificate")
+	flags.BoolVar(&a.json, "json", false, "Print JSON instead of tables")
+	return root
+}
+
+// group creates a command grouping subcommands; run without one, it fails
+// with its usage
+func (a *app) group(use, short string, commands ...*cobra.Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return usageError(fmt.Sprintf("unknown command %q for %q", args[0], cmd.CommandPath()))
+			}
+			return usageError(cmd.CommandPath() + " requires a command")
+		},
+	}
+	cmd.AddCommand(commands...)
+	return cmd
+}
+
+// run adapts a command implementation to cobra, connecting to the server
+// first. Its errors are not usage errors.
+func (a *app) run(fn func(ctx context.Context, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := a.connect(); err != nil {
+			return err
+		}
+		a.started = true
+		return fn(cmd.Context(), args)
+	}
+}
+
+// connect creates the API client from the global flags, unless there is one
+func (a *app) connect() error {
+	if a.client != nil {
+		return nil
+	}
+	if a.apiKey == "" {
+		a.apiKey = os.Getenv(EnvAPIKey)
+	}
+	if a.apiKey == "" {
+		return usageError("an API key is required (--api-key or " + EnvAPIKey + ")")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if a.insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // Explicit opt-in for self-signed lab servers
+	}
+	a.client = NewClient(a.server, a.apiKey, &http.Client{Transport: transport, Timeout: time.Minute})
+	return nil
+}
+
+// printJSON prints a value as indented JSON
+func (a *app) printJSON(v any) error {
+	encoder := json.NewEncoder(a.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// table prints rows aligned in columns under a header
+func (a *app) table(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// envOr returns an environment variable, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// fakeServer serves the API routes the CLI calls and records the request bodies
+type fakeServer struct {
+	polls  int
+	bodies map[string]string
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-API-Key") != "ask_test" {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid API key"}`))
+		return
+	}
+	route := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/api/v1")
+	var body bytes.Buffer
+	_, _ = body.ReadFrom(r.Body)
+	f.bodies[route] = body.String()
+
+	w.Header().Set("Content-Type", "application/json")
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	switch route {
+	case "GET /agents":
+		_ = json.NewEncoder(w).Encode([]entity.Agent{{Paw: "paw-1", Hostname: "ws-01", Platform: "windows", Status: entity.AgentOnline, LastSeen: now}})
+	case "POST /executions":
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(entity.Execution{ID: "exec-1", AgentPaws: []string{"paw-1"}, Status: entity.ExecutionRunning})
+	case "GET /executions/exec-1":
+		// Running on the first poll, completed on the second
+		f.polls++
+		execution := entity.Execution{ID: "exec-1", Status: entity.ExecutionRunning}
+		if f.polls > 1 {
+			execution.Status = entity.ExecutionCompleted
+			execution.Score = &entity.SecurityScore{Overall: 50}
+		}
+		_ = json.NewEncoder(w).Encode(execution)
+	case "GET /executions/exec-1/results":
+		results := []entity.ExecutionResult{
+			{ID: "r1", TechniqueID: "T1059.001", AgentPaw: "paw-1", Status: entity.StatusBlocked, StartedAt: now},
+			{ID: "r2", TechniqueID: "T1082", AgentPaw: "paw-1", Status: entity.StatusRunning, StartedAt: now},
+		}
+		if f.polls > 1 {
+			results[1].Status, results[1].DetectedBy = entity.StatusDetected, "EDR"
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	case "GET /executions/exec-1/export":
+		_, _ = w.Write([]byte(`{"execution":{"id":"exec-1"},"verbosity":"` + r.URL.Query().Get("verbosity") + `"}`))
+	case "POST /scenarios/import":
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte(`{"imported":1,"failed":1,"errors":["scenario 2: unknown technique"],"scenarios":[{"id":"s1","name":"Discovery"}]}`))
+	case "POST /admin/content/plan":
+		_, _ = w.Write([]byte(`{"changes":[{"kind":"technique","id":"T1082","name":"System Information Discovery","action":"update","fields":["name"]}],"unchanged":3,"checksum":"c1"}`))
+	case "POST /admin/content/apply":
+		_, _ = w.Write([]byte(`{"plan":{"changes":[{"kind":"technique","id":"T1082","action":"update"}],"checksum":"c1"},"applied":1}`))
+	case "POST /admin/reload":
+		_, _ = w.Write([]byte(`{"files":[{"file":"techniques/discovery.yaml","status":"applied","applied":2},{"file":"scenarios/broken.yaml","status":"failed","applied":0,"errors":["invalid YAML"]}]}`))
+	case "GET /admin/secrets":
+		_ = json.NewEncoder(w).Encode([]entity.Secret{{Name: "splunk-token", Description: "Splunk", KeyID: "local:1a2b", UpdatedAt: now}})
+	case "PUT /admin/secrets/splunk-token":
+		_ = json.NewEncoder(w).Encode(entity.Secret{Name: "splunk-token", KeyID: "local:1a2b", UpdatedAt: now})
+	case "DELETE /admin/secrets/splunk-token":
+		w.WriteHeader(http.StatusNoContent)
+	case "POST /admin/users":
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"u1","username":"jdoe","email":"jdoe@example.com","role":"analyst","is_active":true}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"execution not found"}`))
+	}
+}
+
+func runCLI(t *testing.T, server *httptest.Server, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), append([]string{"--server", server.URL, "--api-key", "ask_test"}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func newFakeServer() (*fakeServer, *httptest.Server) {
+	fake := &fakeServer{bodies: make(map[string]string)}
+	return fake, httptest.NewServer(fake)
+}
+
+func TestRun_AgentsList(t *testing.T) {
+	_, server := newFakeServer()
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "agents", "list")
+	if code != 0 || !strings.Contains(stdout, "paw-1") || !strings.Contains(stdout, "HOSTNAME") {
+		t.Errorf("Unexpected output (%d): %s %s", code, stdout, stderr)
+	}
+
+	code, stdout, _ = runCLI(t, server, "--json", "agents", "list", "--all")
+	var agents []entity.Agent
+	if code != 0 || json.Unmarshal([]byte(stdout), &agents) != nil || len(agents) != 1 {
+		t.Errorf("Expected the agents as JSON, got %d: %s", code, stdout)
+	}
+}
+
+func TestRun_StartAndWatch(t *testing.T) {
+	fake, server := newFakeServer()
+	defer server.Close()
+	// The fake server completes the execution on the second poll
+	var stdout, stderr bytes.Buffer
+	a := &app{client: NewClient(server.URL, "ask_test", server.Client()), stdout: &stdout, stderr: &stderr, poll: time.Millisecond}
+
+	if code := a.execute(context.Background(), []string{"executions", "start", "--scenario", "s1", "--agents", "paw-1, ,paw-2", "--safe", "--watch"}); code != 0 {
+		t.Fatalf("executions start failed (%d): %s", code, stderr.String())
+	}
+
+	var request map[string]any
+	if err := json.Unmarshal([]byte(fake.bodies["POST /executions"]), &request); err != nil {
+		t.Fatalf("Invalid request: %v", err)
+	}
+	if request["scenario_id"] != "s1" || request["safe_mode"] != true || len(request["agent_paws"].([]any)) != 2 {
+		t.Errorf("Unexpected request: %v", request)
+	}
+
+	output := stdout.String()
+	if strings.Count(output, "T1059.001") != 1 || !strings.Contains(output, "detected by EDR") ||
+		!strings.Contains(output, "Execution exec-1 completed, score 50.0") {
+		t.Errorf("Unexpected watch output:\n%s", output)
+	}
+}
+
+func TestRun_Export(t *testing.T) {
+	_, server := newFakeServer()
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "report.json")
+	code, _, stderr := runCLI(t, server, "executions", "export", "exec-1", "--verbosity", "full", "--output", file)
+	if code != 0 {
+		t.Fatalf("Export failed (%d): %s", code, stderr)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || !strings.Contains(string(data), `"verbosity":"full"`) {
+		t.Errorf("Unexpected report: %s (%v)", data, err)
+	}
+
+	code, _, stderr = runCLI(t, server, "executions", "export", "missing")
+	if code != 1 || !strings.Contains(stderr, "execution not found") {
+		t.Errorf("Expected the API error, got %d: %s", code, stderr)
+	}
+}
+
+func TestRun_ImportScenarios(t *testing.T) {
+	fake, server := newFakeServer()
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "scenarios.json")
+	export := `{"version":"1.0","scenarios":[{"name":"Discovery","phases":[]}]}`
+	if err := os.WriteFile(file, []byte(export), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A partial import fails the command
+	code, stdout, stderr := runCLI(t, server, "scenarios", "import", file)
+	if code != 1 || !strings.Contains(stdout, "Imported Discovery (s1)") || !strings.Contains(stderr, "unknown technique") {
+		t.Errorf("Unexpected import (%d): %s %s", code, stdout, stderr)
+	}
+	if fake.bodies["POST /scenarios/import"] != export {
+		t.Errorf("Expected the file sent as is, got %s", fake.bodies["POST /scenarios/import"])
+	}
+}
+
+func TestRun_CreateUser(t *testing.T) {
+	fake, server := newFakeServer()
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "users", "create", "--username", "jdoe", "--email", "jdoe@example.com", "--role", "analyst")
+	if code != 0 || !strings.Contains(stdout, "Created user jdoe (u1)") || !strings.Contains(stdout, "Password: ") {
+		t.Fatalf("Unexpected output (%d): %s %s", code, stdout, stderr)
+	}
+	var request map[string]string
+	if err := json.Unmarshal([]byte(fake.bodies["POST /admin/users"]), &request); err != nil || len(request["password"]) < 16 {
+		t.Errorf("Expected a generated password, got %v (%v)", request, err)
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	_, server := newFakeServer()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"no command", nil, 2, "Commands:"},
+		{"unknown command", []string{"deploy"}, 2, "Commands:"},
+		{"group", []string{"executions"}, 2, "watch"},
+		{"missing argument", []string{"executions", "watch"}, 2, "autostrikectl executions watch <id>"},
+		{"missing flag", []string{"executions", "start"}, 2, `required flag(s) "scenario" not set`},
+		{"exclusive flags", []string{"executions", "start", "--scenario", "s1", "--agents", "paw-1", "--selector", "sel-1"}, 2, "--agents"},
+		{"unknown flag", []string{"agents", "list", "--offline"}, 2, "autostrikectl agents list [flags]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, server, tt.args...)
+			if code != tt.code || !strings.Contains(stderr, tt.want) {
+				t.Errorf("Expected %d with %q, got %d: %s", tt.code, tt.want, code, stderr)
+			}
+		})
+	}
+
+	// The API key is required, and checked by the server
+	t.Setenv(EnvAPIKey, "")
+	var stderr bytes.Buffer
+	if code := Run(context.Background(), []string{"--server", server.URL, "agents", "list"}, &bytes.Buffer{}, &stderr); code != 2 {
+		t.Errorf("Expected a usage error without API key, got %d: %s", code, stderr.String())
+	}
+	stderr.Reset()
+	if code := Run(context.Background(), []string{"--server", server.URL, "--api-key", "ask_wrong", "agents", "list"}, &bytes.Buffer{}, &stderr); code != 1 ||
+		!strings.Contains(stderr.String(), "401: invalid API key") {
+		t.Errorf("Expected the server to reject the key, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestContent_PlanAndApply(t *testing.T) {
+	fake, server := newFakeServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "techniques"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	technique := "- id: T1082\n  name: System Information Discovery\n  tactic: discovery\n"
+	if err := os.WriteFile(filepath.Join(dir, "techniques", "discovery.yaml"), []byte(technique), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runCLI(t, server, "content", "plan", dir, "--prune")
+	if code != 0 || !strings.Contains(stdout, "T1082") || !strings.Contains(stdout, "Plan: 0 to create, 1 to update, 0 to restore, 0 to delete, 3 unchanged.") {
+		t.Fatalf("Unexpected plan (%d): %s %s", code, stdout, stderr)
+	}
+	var request map[string]any
+	if err := json.Unmarshal([]byte(fake.bodies["POST /admin/content/plan"]), &request); err != nil ||
+		request["prune"] != true || len(request["techniques"].([]any)) != 1 {
+		t.Errorf("Unexpected plan request: %s", fake.bodies["POST /admin/content/plan"])
+	}
+
+	// Only "yes" applies the plan
+	var out, errOut bytes.Buffer
+	a := &app{client: NewClient(server.URL, "ask_test", server.Client()), stdin: strings.NewReader("y\n"), stdout: &out, stderr: &errOut}
+	if code := a.execute(context.Background(), []string{"content", "apply", dir}); code != 1 || fake.bodies["POST /admin/content/apply"] != "" {
+		t.Fatalf("Expected the apply cancelled, got %d: %s", code, errOut.String())
+	}
+
+	a.stdin = strings.NewReader("yes\n")
+	if code := a.execute(context.Background(), []string{"content", "apply", dir}); code != 0 {
+		t.Fatalf("content apply failed (%d): %s", code, errOut.String())
+	}
+	if !strings.Contains(fake.bodies["POST /admin/content/apply"], `"checksum":"c1"`) || !strings.Contains(out.String(), "Applied 1 of 1 changes.") {
+		t.Errorf("Unexpected apply: %s\n%s", fake.bodies["POST /admin/content/apply"], out.String())
+	}
+
+	if code, _, stderr := runCLI(t, server, "content", "plan", t.TempDir()); code != 1 || !strings.Contains(stderr, "has no techniques") {
+		t.Errorf("Expected an empty directory refused, got %d: %s", code, stderr)
+	}
+}
+
+func TestContent_Reload(t *testing.T) {
+	_, server := newFakeServer()
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "content", "reload", "--all")
+	if code != 1 || !strings.Contains(stdout, "techniques/discovery.yaml") || !strings.Contains(stdout, "invalid YAML") ||
+		!strings.Contains(stderr, "1 files failed to reload") {
+		t.Errorf("Unexpected reload (%d): %s %s", code, stdout, stderr)
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	fake, server := newFakeServer()
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "secrets", "list")
+	if code != 0 || !strings.Contains(stdout, "splunk-token") || !strings.Contains(stdout, "local:1a2b") {
+		t.Errorf("Unexpected list (%d): %s %s", code, stdout, stderr)
+	}
+
+	// The value is read from stdin
+	var out, errOut bytes.Buffer
+	a := &app{client: NewClient(server.URL, "ask_test", server.Client()), stdin: strings.NewReader("token-value\n"), stdout: &out, stderr: &errOut}
+	if code := a.execute(context.Background(), []string{"secrets", "set", "splunk-token", "--description", "Splunk"}); code != 0 {
+		t.Fatalf("secrets set failed (%d): %s", code, errOut.String())
+	}
+	if body := fake.bodies["PUT /admin/secrets/splunk-token"]; !strings.Contains(body, `"value":"token-value"`) || !strings.Contains(body, `"description":"Splunk"`) {
+		t.Errorf("Unexpected request body %s", body)
+	}
+	if !strings.Contains(out.String(), "secret://splunk-token") {
+		t.Errorf("Expected the reference printed, got %s", out.String())
+	}
+	a.stdin = strings.NewReader("")
+	if code := a.execute(context.Background(), []string{"secrets", "set", "splunk-token"}); code != 1 {
+		t.Errorf("Expected an empty value refused, got %d", code)
+	}
+
+	if code, stdout, _ := runCLI(t, server, "secrets", "delete", "splunk-token"); code != 0 || !strings.Contains(stdout, "Deleted secret splunk-token") {
+		t.Errorf("Unexpected delete (%d): %s", code, stdout)
+	}
+}
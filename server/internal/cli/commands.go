@@ -0,0 +1,509 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"github.com/spf13/cobra"
+)
+
+func (a *app) listAgentsCommand() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List online agents, or all agents",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			return listAgents(ctx, a, all)
+		}),
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Include offline agents")
+	return cmd
+}
+
+// listAgents prints the online agents, or all agents with --all
+func listAgents(ctx context.Context, a *app, all bool) error {
+	path := "/agents"
+	if all {
+		path += "?all=true"
+	}
+	var agents []entity.Agent
+	if err := a.client.Do(ctx, http.MethodGet, path, nil, &agents); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(agents)
+	}
+
+	rows := make([][]string, 0, len(agents))
+	for _, agent := range agents {
+		rows = append(rows, []string{agent.Paw, agent.Hostname, agent.Platform, string(agent.Status),
+			agent.LastSeen.Local().Format(time.DateTime)})
+	}
+	return a.table([]string{"PAW", "HOSTNAME", "PLATFORM", "STATUS", "LAST SEEN"}, rows)
+}
+
+func (a *app) listScenariosCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List scenarios",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			return listScenarios(ctx, a)
+		}),
+	}
+}
+
+// listScenarios prints the scenarios
+func listScenarios(ctx context.Context, a *app) error {
+	var scenarios []entity.Scenario
+	if err := a.client.Do(ctx, http.MethodGet, "/scenarios", nil, &scenarios); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(scenarios)
+	}
+
+	rows := make([][]string, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		techniques := 0
+		for _, phase := range scenario.Phases {
+			techniques += len(phase.Techniques)
+		}
+		rows = append(rows, []string{scenario.ID, scenario.Name, strconv.Itoa(len(scenario.Phases)),
+			strconv.Itoa(techniques), strings.Join(scenario.Tags, ",")})
+	}
+	return a.table([]string{"ID", "NAME", "PHASES", "TECHNIQUES", "TAGS"}, rows)
+}
+
+func (a *app) importScenariosCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import the scenarios of an export file",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return importScenarios(ctx, a, args[0])
+		}),
+	}
+}
+
+// importScenarios imports the scenarios of an export file, as written by the
+// scenario export of the dashboard or the API
+func importScenarios(ctx context.Context, a *app, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return fmt.Errorf("%s is not a JSON scenario export", file)
+	}
+
+	var response struct {
+		Imported  int               `json:"imported"`
+		Failed    int               `json:"failed"`
+		Errors    []string          `json:"errors"`
+		Scenarios []entity.Scenario `json:"scenarios"`
+	}
+	if err := a.client.Do(ctx, http.MethodPost, "/scenarios/import", data, &response); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(response)
+	}
+
+	for _, scenario := range response.Scenarios {
+		fmt.Fprintf(a.stdout, "Imported %s (%s)\n", scenario.Name, scenario.ID)
+	}
+	for _, message := range response.Errors {
+		fmt.Fprintf(a.stderr, "Not imported: %s\n", message)
+	}
+	if response.Failed > 0 {
+		return fmt.Errorf("%d of %d scenarios were not imported", response.Failed, response.Failed+response.Imported)
+	}
+	return nil
+}
+
+func (a *app) listExecutionsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List recent executions",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			return listExecutions(ctx, a)
+		}),
+	}
+}
+
+// listExecutions prints the recent executions
+func listExecutions(ctx context.Context, a *app) error {
+	var executions []entity.Execution
+	if err := a.client.Do(ctx, http.MethodGet, "/executions", nil, &executions); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(executions)
+	}
+
+	rows := make([][]string, 0, len(executions))
+	for _, execution := range executions {
+		rows = append(rows, []string{execution.ID, execution.ScenarioID, string(execution.Status),
+			fmt.Sprintf("%d/%d", execution.Progress.Completed, execution.Progress.Total), formatScore(execution.Score),
+			execution.StartedAt.Local().Format(time.DateTime)})
+	}
+	return a.table([]string{"ID", "SCENARIO", "STATUS", "PROGRESS", "SCORE", "STARTED"}, rows)
+}
+
+// startOptions are the flags of executions start
+type startOptions struct {
+	scenario string
+	agents   []string
+	selector string
+	profile  string
+	safe     bool
+	watch    bool
+}
+
+func (a *app) startExecutionCommand() *cobra.Command {
+	var opts startOptions
+	cmd := &cobra.Command{
+		Use:   "start --scenario <id> [--agents <paw,...> | --selector <id>] [--safe] [--watch]",
+		Short: "Start an execution",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			return startExecution(ctx, a, opts)
+		}),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&opts.scenario, "scenario", "", "Scenario ID")
+	flags.StringSliceVar(&opts.agents, "agents", nil, "Comma-separated agent paws")
+	flags.StringVar(&opts.selector, "selector", "", "Saved agent selector ID, instead of --agents")
+	flags.StringVar(&opts.profile, "scoring-profile", "", "Scoring profile ID, the default profile when empty")
+	flags.BoolVar(&opts.safe, "safe", false, "Run in safe mode, skipping unsafe techniques")
+	flags.BoolVar(&opts.watch, "watch", false, "Tail the results until the execution ends")
+	_ = cmd.MarkFlagRequired("scenario")
+	cmd.MarkFlagsMutuallyExclusive("agents", "selector")
+	return cmd
+}
+
+// startExecution starts an execution, then tails its results with --watch
+func startExecution(ctx context.Context, a *app, opts startOptions) error {
+	request := map[string]any{
+		"scenario_id":        opts.scenario,
+		"agent_paws":         compactList(opts.agents),
+		"agent_selector_id":  opts.selector,
+		"safe_mode":          opts.safe,
+		"scoring_profile_id": opts.profile,
+	}
+	var execution entity.Execution
+	if err := a.client.Do(ctx, http.MethodPost, "/executions", request, &execution); err != nil {
+		return err
+	}
+	if a.json && !opts.watch {
+		return a.printJSON(execution)
+	}
+	if !a.json {
+		fmt.Fprintf(a.stdout, "Started execution %s on %s\n", execution.ID, strings.Join(execution.AgentPaws, ", "))
+	}
+	if opts.watch {
+		return a.watch(ctx, execution.ID)
+	}
+	return nil
+}
+
+func (a *app) watchExecutionCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch <id>",
+		Short: "Tail the results of an execution until it ends",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return a.watch(ctx, args[0])
+		}),
+	}
+}
+
+// watch polls an execution and prints its results as they complete. It
+// returns an error when the execution ends other than completed, so scripts
+// can rely on the exit code.
+func (a *app) watch(ctx context.Context, id string) error {
+	printed := make(map[string]bool)
+	for {
+		// The execution is loaded before its results: once it has ended, the
+		// results loaded after it are final
+		var execution entity.Execution
+		if err := a.client.Do(ctx, http.MethodGet, "/executions/"+url.PathEscape(id), nil, &execution); err != nil {
+			return err
+		}
+		var results []entity.ExecutionResult
+		if err := a.client.Do(ctx, http.MethodGet, "/executions/"+url.PathEscape(id)+"/results", nil, &results); err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			if !result.Status.IsTerminal() || printed[result.ID] {
+				continue
+			}
+			printed[result.ID] = true
+			if err := a.printResult(result); err != nil {
+				return err
+			}
+		}
+
+		if execution.Status != entity.ExecutionPending && execution.Status != entity.ExecutionRunning {
+			if a.json {
+				if err := a.printJSON(execution); err != nil {
+					return err
+				}
+			} else {
+				fmt.Fprintf(a.stdout, "Execution %s %s, score %s\n", execution.ID, execution.Status, formatScore(execution.Score))
+			}
+			if execution.Status != entity.ExecutionCompleted {
+				return fmt.Errorf("execution %s", execution.Status)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(a.poll):
+		}
+	}
+}
+
+// printResult prints a completed result, as a JSON line with --json
+func (a *app) printResult(result entity.ExecutionResult) error {
+	if a.json {
+		line, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(a.stdout, string(line))
+		return err
+	}
+
+	completed := result.StartedAt
+	if result.CompletedAt != nil {
+		completed = *result.CompletedAt
+	}
+	line := fmt.Sprintf("%s  %-12s  %-16s  %s", completed.Local().Format(time.TimeOnly), result.TechniqueID, result.AgentPaw, result.Status)
+	if result.DetectedBy != "" {
+		line += " by " + result.DetectedBy
+	}
+	_, err := fmt.Fprintln(a.stdout, line)
+	return err
+}
+
+func (a *app) exportExecutionCommand() *cobra.Command {
+	var verbosity, output string
+	cmd := &cobra.Command{
+		Use:   "export <id> [--verbosity minimal|full] [--output <file>]",
+		Short: "Export the report of an execution as JSON",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return exportExecution(ctx, a, args[0], verbosity, output)
+		}),
+	}
+	cmd.Flags().StringVar(&verbosity, "verbosity", "minimal", "minimal, or full to include technique metadata")
+	cmd.Flags().StringVar(&output, "output", "", "File to write, stdout when empty")
+	return cmd
+}
+
+// exportExecution writes the JSON report of an execution to a file or stdout
+func exportExecution(ctx context.Context, a *app, id, verbosity, output string) error {
+	var report []byte
+	path := "/executions/" + url.PathEscape(id) + "/export?verbosity=" + url.QueryEscape(verbosity)
+	if err := a.client.Do(ctx, http.MethodGet, path, nil, &report); err != nil {
+		return err
+	}
+	if output == "" {
+		_, err := a.stdout.Write(report)
+		return err
+	}
+	if err := os.WriteFile(output, report, 0o600); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "Wrote %s (%d bytes)\n", output, len(report))
+	return nil
+}
+
+// user is a user as listed by the admin API
+type user struct {
+	ID          string `json:"id"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	IsActive    bool   `json:"is_active"`
+	LastLoginAt string `json:"last_login_at,omitempty"`
+}
+
+func (a *app) listUsersCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List users",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			return listUsers(ctx, a)
+		}),
+	}
+}
+
+// listUsers prints the users
+func listUsers(ctx context.Context, a *app) error {
+	var response struct {
+		Users []user `json:"users"`
+	}
+	if err := a.client.Do(ctx, http.MethodGet, "/admin/users", nil, &response); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(response.Users)
+	}
+
+	rows := make([][]string, 0, len(response.Users))
+	for _, u := range response.Users {
+		rows = append(rows, []string{u.ID, u.Username, u.Email, u.Role, strconv.FormatBool(u.IsActive), u.LastLoginAt})
+	}
+	return a.table([]string{"ID", "USERNAME", "EMAIL", "ROLE", "ACTIVE", "LAST LOGIN"}, rows)
+}
+
+func (a *app) createUserCommand() *cobra.Command {
+	var username, email, role, password string
+	cmd := &cobra.Command{
+		Use:   "create --username <name> --email <email> --role <role> [--password <password>]",
+		Short: "Create a user, with a generated password unless one is given",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			request := map[string]string{"username": username, "email": email, "role": role, "password": password}
+			return createUser(ctx, a, request)
+		}),
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&username, "username", "", "Username")
+	flags.StringVar(&email, "email", "", "Email address")
+	flags.StringVar(&role, "role", "", "Role: admin, rssi, operator, analyst or viewer")
+	flags.StringVar(&password, "password", "", "Password, generated when empty")
+	for _, name := range []string{"username", "email", "role"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+// createUser creates a user, with a generated password unless the request has one
+func createUser(ctx context.Context, a *app, request map[string]string) error {
+	generated := request["password"] == ""
+	if generated {
+		secret := make([]byte, 18)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		request["password"] = base64.RawURLEncoding.EncodeToString(secret)
+	}
+
+	var created user
+	if err := a.client.Do(ctx, http.MethodPost, "/admin/users", request, &created); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(created)
+	}
+	fmt.Fprintf(a.stdout, "Created user %s (%s) with role %s\n", created.Username, created.ID, created.Role)
+	if generated {
+		fmt.Fprintf(a.stdout, "Password: %s\n", request["password"])
+	}
+	return nil
+}
+
+func (a *app) setUserRoleCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-role <id> <role>",
+		Short: "Change the role of a user",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return setUserRole(ctx, a, args[0], args[1])
+		}),
+	}
+}
+
+// setUserRole changes the role of a user
+func setUserRole(ctx context.Context, a *app, id, role string) error {
+	var updated user
+	path := "/admin/users/" + url.PathEscape(id) + "/role"
+	if err := a.client.Do(ctx, http.MethodPut, path, map[string]string{"role": role}, &updated); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(updated)
+	}
+	fmt.Fprintf(a.stdout, "User %s is now %s\n", updated.Username, updated.Role)
+	return nil
+}
+
+func (a *app) deactivateUserCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "deactivate <id>",
+		Short: "Deactivate a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return deactivateUser(ctx, a, args[0])
+		}),
+	}
+}
+
+// deactivateUser deactivates a user
+func deactivateUser(ctx context.Context, a *app, id string) error {
+	if err := a.client.Do(ctx, http.MethodDelete, "/admin/users/"+url.PathEscape(id), nil, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Deactivated user %s\n", id)
+	return nil
+}
+
+func (a *app) reactivateUserCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reactivate <id>",
+		Short: "Reactivate a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return reactivateUser(ctx, a, args[0])
+		}),
+	}
+}
+
+// reactivateUser reactivates a user
+func reactivateUser(ctx context.Context, a *app, id string) error {
+	if err := a.client.Do(ctx, http.MethodPost, "/admin/users/"+url.PathEscape(id)+"/reactivate", nil, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Reactivated user %s\n", id)
+	return nil
+}
+
+// formatScore formats the overall score of an execution, or - before it is scored
+func formatScore(score *entity.SecurityScore) string {
+	if score == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(score.Overall, 'f', 1, 64)
+}
+
+// compactList trims the items of a list and drops the empty ones
+func compactList(list []string) []string {
+	var items []string
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"autostrike/internal/application"
+
+	"github.com/spf13/cobra"
+)
+
+// contentRequest is the content of a YAML directory sent to plan or apply
+type contentRequest struct {
+	*application.ContentSource
+	Prune    bool   `json:"prune"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+func (a *app) planContentCommand() *cobra.Command {
+	var prune bool
+	cmd := &cobra.Command{
+		Use:   "plan <dir> [--prune]",
+		Short: "Show what applying a directory of YAML would change",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return planContent(ctx, a, args[0], prune)
+		}),
+	}
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete the techniques and scenarios the directory does not define")
+	return cmd
+}
+
+// planContent prints what applying a directory of technique and scenario YAML would change
+func planContent(ctx context.Context, a *app, dir string, prune bool) error {
+	_, plan, err := a.plan(ctx, dir, prune)
+	if err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(plan)
+	}
+	return a.printPlan(plan)
+}
+
+func (a *app) applyContentCommand() *cobra.Command {
+	var prune, autoApprove bool
+	cmd := &cobra.Command{
+		Use:   "apply <dir> [--prune] [--auto-approve]",
+		Short: "Apply a directory of YAML after confirmation",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return applyContent(ctx, a, args[0], prune, autoApprove)
+		}),
+	}
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete the techniques and scenarios the directory does not define")
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Apply without asking for confirmation")
+	return cmd
+}
+
+// applyContent plans a directory, asks for confirmation unless autoApprove
+// and applies the reviewed plan
+func applyContent(ctx context.Context, a *app, dir string, prune, autoApprove bool) error {
+	request, plan, err := a.plan(ctx, dir, prune)
+	if err != nil {
+		return err
+	}
+	if !a.json {
+		if err := a.printPlan(plan); err != nil {
+			return err
+		}
+	}
+	if len(plan.Changes) == 0 {
+		if a.json {
+			return a.printJSON(application.ContentApplyResult{Plan: plan})
+		}
+		return nil
+	}
+	if !autoApprove {
+		fmt.Fprint(a.stderr, "\nApply these changes? Only 'yes' is accepted: ")
+		answer, _ := bufio.NewReader(a.stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			return fmt.Errorf("apply cancelled")
+		}
+	}
+
+	request.Checksum = plan.Checksum
+	var result application.ContentApplyResult
+	if err := a.client.Do(ctx, http.MethodPost, "/admin/content/apply", request, &result); err != nil {
+		return err
+	}
+	if a.json {
+		if err := a.printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(a.stdout, "\nApplied %d of %d changes.\n", result.Applied, len(result.Plan.Changes))
+	}
+	for _, message := range result.Errors {
+		fmt.Fprintf(a.stderr, "Failed: %s\n", message)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d changes failed", len(result.Errors))
+	}
+	return nil
+}
+
+func (a *app) reloadContentCommand() *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "reload [--all]",
+		Short: "Reload the changed files of the server configs/ directory",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			return reloadContent(ctx, a, all)
+		}),
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "Reload every file, changed or not")
+	return cmd
+}
+
+// reloadContent has the server reload the changed files of its configs/ directory
+func reloadContent(ctx context.Context, a *app, all bool) error {
+	path := "/admin/reload"
+	if all {
+		path += "?all=true"
+	}
+	var response struct {
+		Files []application.ContentFileResult `json:"files"`
+	}
+	if err := a.client.Do(ctx, http.MethodPost, path, nil, &response); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(response)
+	}
+	if len(response.Files) == 0 {
+		fmt.Fprintln(a.stdout, "No changed files.")
+		return nil
+	}
+
+	failed := 0
+	rows := make([][]string, 0, len(response.Files))
+	for _, file := range response.Files {
+		if file.Status == application.ContentFileFailed {
+			failed++
+		}
+		rows = append(rows, []string{file.File, string(file.Status), fmt.Sprint(file.Applied), strings.Join(file.Errors, "; ")})
+	}
+	if err := a.table([]string{"FILE", "STATUS", "APPLIED", "ERRORS"}, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d files failed to reload", failed)
+	}
+	return nil
+}
+
+// plan loads a content directory and has the server plan it
+func (a *app) plan(ctx context.Context, dir string, prune bool) (*contentRequest, *application.ContentPlan, error) {
+	source, err := application.LoadContentDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(source.Techniques) == 0 && len(source.Scenarios) == 0 {
+		return nil, nil, fmt.Errorf("%s has no techniques/ nor scenarios/ YAML files", dir)
+	}
+
+	request := &contentRequest{ContentSource: source, Prune: prune}
+	var plan application.ContentPlan
+	if err := a.client.Do(ctx, http.MethodPost, "/admin/content/plan", request, &plan); err != nil {
+		return nil, nil, err
+	}
+	return request, &plan, nil
+}
+
+// printPlan prints the changes of a plan and their count
+func (a *app) printPlan(plan *application.ContentPlan) error {
+	for _, message := range plan.Errors {
+		fmt.Fprintf(a.stderr, "Skipped: %s\n", message)
+	}
+	if len(plan.Changes) == 0 {
+		fmt.Fprintf(a.stdout, "No changes, %d items up to date.\n", plan.Unchanged)
+		return nil
+	}
+
+	rows := make([][]string, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		rows = append(rows, []string{string(change.Action), change.Kind, change.ID, change.Name, strings.Join(change.Fields, ", ")})
+	}
+	if err := a.table([]string{"ACTION", "KIND", "ID", "NAME", "CHANGED"}, rows); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "\nPlan: %s, %d unchanged.\n", plan.Summary(), plan.Unchanged)
+	return nil
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+
+	"github.com/spf13/cobra"
+)
+
+// maxSecretInput bounds the value read from stdin
+const maxSecretInput = 64 * 1024
+
+func (a *app) listSecretsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the secrets, without their value",
+		Args:  cobra.NoArgs,
+		RunE: a.run(func(ctx context.Context, _ []string) error {
+			return listSecrets(ctx, a)
+		}),
+	}
+}
+
+// listSecrets lists the secrets of the secrets store, without their value
+func listSecrets(ctx context.Context, a *app) error {
+	var secrets []entity.Secret
+	if err := a.client.Do(ctx, http.MethodGet, "/admin/secrets", nil, &secrets); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(secrets)
+	}
+
+	rows := make([][]string, 0, len(secrets))
+	for _, s := range secrets {
+		rows = append(rows, []string{s.Name, s.Description, s.KeyID, s.UpdatedAt.Format("2006-01-02 15:04")})
+	}
+	return a.table([]string{"NAME", "DESCRIPTION", "MASTER KEY", "UPDATED"}, rows)
+}
+
+func (a *app) setSecretCommand() *cobra.Command {
+	var description string
+	cmd := &cobra.Command{
+		Use:   "set <name> [--description <text>] < value",
+		Short: "Store a secret, its value read from stdin",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return setSecret(ctx, a, args[0], description)
+		}),
+	}
+	cmd.Flags().StringVar(&description, "description", "", "What the secret is for")
+	return cmd
+}
+
+// setSecret stores a secret read from stdin, so that its value stays out of
+// the shell history and the process list
+func setSecret(ctx context.Context, a *app, name, description string) error {
+	data, err := io.ReadAll(io.LimitReader(a.stdin, maxSecretInput+1))
+	if err != nil {
+		return fmt.Errorf("failed to read the secret value: %w", err)
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return fmt.Errorf("the secret value is read from stdin, e.g. autostrikectl secrets set %s < token.txt", name)
+	}
+
+	request := map[string]string{"value": value, "description": description}
+	var secret entity.Secret
+	if err := a.client.Do(ctx, http.MethodPut, "/admin/secrets/"+url.PathEscape(name), request, &secret); err != nil {
+		return err
+	}
+	if a.json {
+		return a.printJSON(secret)
+	}
+	fmt.Fprintf(a.stdout, "Stored secret %s, refer to it as %s%s\n", secret.Name, entity.SecretRefPrefix, secret.Name)
+	return nil
+}
+
+func (a *app) deleteSecretCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a secret",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.run(func(ctx context.Context, args []string) error {
+			return deleteSecret(ctx, a, args[0])
+		}),
+	}
+}
+
+// deleteSecret deletes a secret
+func deleteSecret(ctx context.Context, a *app, name string) error {
+	if err := a.client.Do(ctx, http.MethodDelete, "/admin/secrets/"+url.PathEscape(name), nil, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Deleted secret %s\n", name)
+	return nil
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+// AnomalyType identifies the kind of unusual operator activity
+type AnomalyType string
+
+const (
+	AnomalyNewIP             AnomalyType = "new_ip"              // Login from an IP not seen for the user
+	AnomalyNewCountry        AnomalyType = "new_country"         // Login from a country not seen for the user
+	AnomalyOffHoursExecution AnomalyType = "off_hours_execution" // Execution launched outside business hours
+	AnomalyMassDeletion      AnomalyType = "mass_deletion"       // Many scenarios deleted in a short window
+
+	AnomalyAgentUnexpectedNetwork AnomalyType = "agent_unexpected_network" // Agent connecting from outside its allowed networks
+	AnomalyLoginLockout           AnomalyType = "login_lockout"            // Username or source IP locked out after repeated failed logins
+	AnomalyPasswordReset          AnomalyType = "password_reset"           // Password reset with an emailed token
+)
+
+// AnomalySeverity ranks how suspicious an anomaly is
+type AnomalySeverity string
+
+const (
+	SeverityMedium AnomalySeverity = "medium"
+	SeverityHigh   AnomalySeverity = "high"
+)
+
+// LoginRecord is a successful login kept to build each user's baseline
+type LoginRecord struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	IPAddress string    `json:"ip_address"`
+	Country   string    `json:"country,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ActivityAnomaly is an audit record of unusual operator activity
+type ActivityAnomaly struct {
+	ID        string          `json:"id"`
+	Type      AnomalyType     `json:"type"`
+	Severity  AnomalySeverity `json:"severity"`
+	UserID    string          `json:"user_id,omitempty"`
+	Username  string          `json:"username,omitempty"`
+	Message   string          `json:"message"`
+	Data      map[string]any  `json:"data,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
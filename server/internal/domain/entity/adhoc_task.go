@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// AdHocTask is a one-off command an administrator ran on an agent outside any
+// scenario. It is kept as the audit record of who ran what, where and with
+// which outcome.
+type AdHocTask struct {
+	ID          string       `json:"id"`
+	AgentPaw    string       `json:"agent_paw"`
+	Command     string       `json:"command"`
+	Executor    string       `json:"executor"`
+	Timeout     int          `json:"timeout"` // Seconds
+	Status      ResultStatus `json:"status"`  // running, then success, failed or timeout
+	Output      string       `json:"output,omitempty"`
+	ExitCode    int          `json:"exit_code"`
+	RequestedBy string       `json:"requested_by"` // ID of the administrator
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -15,17 +17,29 @@ const (
 
 // Agent represents a deployed AutoStrike agent
 type Agent struct {
-	Paw       string            `json:"paw"`
-	Hostname  string            `json:"hostname"`
-	Platform  string            `json:"platform"` // "windows", "linux", "darwin"
-	Username  string            `json:"username"`
-	Executors []string          `json:"executors"` // ["psh", "cmd", "bash"]
-	Status    AgentStatus       `json:"status"`
-	LastSeen  time.Time         `json:"last_seen"`
-	IPAddress string            `json:"ip_address"`
-	OSVersion string            `json:"os_version"`
-	Metadata  map[string]string `json:"metadata,omitempty"`
-	CreatedAt time.Time         `json:"created_at"`
+	Paw             string            `json:"paw"`
+	Hostname        string            `json:"hostname"`
+	Platform        string            `json:"platform"` // "windows", "linux", "darwin"
+	Username        string            `json:"username"`
+	Executors       []string          `json:"executors"` // ["psh", "cmd", "bash"]
+	Status          AgentStatus       `json:"status"`
+	LastSeen        time.Time         `json:"last_seen"`
+	IPAddress       string            `json:"ip_address"`                 // Source address of the last connection
+	AllowedNetworks []string          `json:"allowed_networks,omitempty"` // Expected source networks, any when empty
+	Version         string            `json:"version,omitempty"`          // Agent build, reported at registration
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	AgentInventory
+}
+
+// AgentInventory describes the host an agent runs on, as reported at registration
+type AgentInventory struct {
+	OSVersion        string            `json:"os_version"`
+	Architecture     string            `json:"architecture,omitempty"`      // "x86_64", "aarch64"
+	Elevated         bool              `json:"elevated"`                    // Running as root or an administrator
+	Domain           string            `json:"domain,omitempty"`            // Directory domain, empty when not joined
+	SecurityProducts []string          `json:"security_products,omitempty"` // e.g. ["Microsoft Defender"]
+	Interpreters     map[string]string `json:"interpreters,omitempty"`      // Name to version, e.g. {"powershell": "5.1"}
 }
 
 // IsOnline returns true if the agent is considered online
@@ -33,6 +47,19 @@ func (a *Agent) IsOnline(timeout time.Duration) bool {
 	return time.Since(a.LastSeen) < timeout
 }
 
+// IsExpectedSource reports whether an address is in the agent's allowed
+// networks, any address being expected when none is set
+func (a *Agent) IsExpectedSource(ip string) bool {
+	if len(a.AllowedNetworks) == 0 {
+		return true
+	}
+	networks, err := ParseNetworkList(a.AllowedNetworks)
+	if err != nil {
+		return false
+	}
+	return networks.Contains(ip)
+}
+
 // IsCompatible checks if the agent can execute the given technique
 func (a *Agent) IsCompatible(technique *Technique) bool {
 	for _, platform := range technique.Platforms {
@@ -58,3 +85,92 @@ func (a *Agent) SupportsExecutor(executorType string) bool {
 	}
 	return false
 }
+
+// NormalizeArchitecture maps the usual aliases of a CPU architecture to the
+// names agents report, so "amd64" and "x86_64" are the same architecture
+func NormalizeArchitecture(arch string) string {
+	switch arch = strings.ToLower(arch); arch {
+	case "amd64", "x64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "386", "i386", "i686":
+		return "x86"
+	default:
+		return arch
+	}
+}
+
+// HasInterpreter checks if the agent has an interpreter, at minVersion or later
+// when minVersion is set
+func (a *Agent) HasInterpreter(name, minVersion string) bool {
+	for interpreter, version := range a.Interpreters {
+		if strings.EqualFold(interpreter, name) {
+			return minVersion == "" || VersionAtLeast(version, minVersion)
+		}
+	}
+	return false
+}
+
+// HasSecurityProduct checks if a security product was detected on the agent's host
+func (a *Agent) HasSecurityProduct(product string) bool {
+	for _, p := range a.SecurityProducts {
+		if strings.EqualFold(p, product) {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionAtLeast reports whether a dotted version is at least min, comparing
+// numeric components in order: "5.1.19041" is at least "5", "3.9" is not at
+// least "3.10". Missing components count as 0 and a version that does not
+// start with a number never matches.
+func VersionAtLeast(version, min string) bool {
+	have, ok := parseVersionComponents(version)
+	if !ok {
+		return false
+	}
+	want, ok := parseVersionComponents(min)
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(have) || i < len(want); i++ {
+		var h, w int
+		if i < len(have) {
+			h = have[i]
+		}
+		if i < len(want) {
+			w = want[i]
+		}
+		if h != w {
+			return h > w
+		}
+	}
+	return true
+}
+
+// parseVersionComponents parses the leading numeric components of a version,
+// ignoring any prefix "v" and suffix such as "-rc1"
+func parseVersionComponents(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	var components []int
+	for _, part := range strings.Split(version, ".") {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, err := strconv.Atoi(part[:end])
+		if err != nil {
+			return nil, false
+		}
+		components = append(components, n)
+		if end < len(part) {
+			break
+		}
+	}
+	return components, len(components) > 0
+}
@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// Reasons an agent went online or offline
+const (
+	AvailabilityRegistered       = "registered"        // The agent registered or reconnected
+	AvailabilityDisconnected     = "disconnected"      // Its connection closed and it did not reconnect in time
+	AvailabilityHeartbeatTimeout = "heartbeat_timeout" // It stopped sending heartbeats
+)
+
+// AgentAvailabilityEvent is an agent going online or offline
+type AgentAvailabilityEvent struct {
+	ID         string      `json:"id"`
+	AgentPaw   string      `json:"agent_paw"`
+	Status     AgentStatus `json:"status"` // online or offline
+	Reason     string      `json:"reason"`
+	Suppressed bool        `json:"suppressed"` // Not announced, the agent was flapping
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+// AgentAvailability is the availability of an agent over a period
+type AgentAvailability struct {
+	AgentPaw      string                    `json:"agent_paw"`
+	Since         time.Time                 `json:"since"`
+	Until         time.Time                 `json:"until"`
+	UptimePercent float64                   `json:"uptime_percent"`
+	Transitions   int                       `json:"transitions"`
+	Flapping      bool                      `json:"flapping"` // Its transitions are currently not announced
+	Events        []*AgentAvailabilityEvent `json:"events"`
+}
+
+// AgentUptime returns the share of the period from since to until an agent
+// was online, in percent, from its events in the period, oldest first. The
+// agent is taken to be in the opposite state of its first event before it,
+// or in status throughout the period without events.
+func AgentUptime(status AgentStatus, events []*AgentAvailabilityEvent, since, until time.Time) float64 {
+	period := until.Sub(since)
+	if period <= 0 {
+		return 0
+	}
+
+	online := status == AgentOnline
+	if len(events) > 0 {
+		online = events[0].Status != AgentOnline
+	}
+	var up time.Duration
+	from := since
+	for _, event := range events {
+		if online {
+			up += event.OccurredAt.Sub(from)
+		}
+		online = event.Status == AgentOnline
+		from = event.OccurredAt
+	}
+	if online {
+		up += until.Sub(from)
+	}
+	return math.Round(up.Seconds()/period.Seconds()*1000) / 10
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgentUptime(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(10 * time.Hour)
+	at := func(hours int, status AgentStatus) *AgentAvailabilityEvent {
+		return &AgentAvailabilityEvent{Status: status, OccurredAt: since.Add(time.Duration(hours) * time.Hour)}
+	}
+
+	tests := []struct {
+		name   string
+		status AgentStatus
+		events []*AgentAvailabilityEvent
+		want   float64
+	}{
+		{"online throughout", AgentOnline, nil, 100},
+		{"offline throughout", AgentOffline, nil, 0},
+		{"went offline", AgentOffline, []*AgentAvailabilityEvent{at(4, AgentOffline)}, 40},
+		{"came back", AgentOnline, []*AgentAvailabilityEvent{at(2, AgentOffline), at(5, AgentOnline)}, 70},
+		{"came online", AgentOnline, []*AgentAvailabilityEvent{at(7, AgentOnline)}, 30},
+	}
+	for _, tt := range tests {
+		if got := AgentUptime(tt.status, tt.events, since, until); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+	if got := AgentUptime(AgentOnline, nil, until, since); got != 0 {
+		t.Errorf("Expected an empty period to have no uptime, got %v", got)
+	}
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+// AgentFilter selects the agents of a bulk operation. An agent matches when it
+// satisfies every non-empty criterion; values inside a criterion are alternatives.
+type AgentFilter struct {
+	Paws            []string          `json:"paws,omitempty"`             // Any of
+	Platforms       []string          `json:"platforms,omitempty"`        // Any of
+	Statuses        []AgentStatus     `json:"statuses,omitempty"`         // Any of
+	Tags            map[string]string `json:"tags,omitempty"`             // All of, matched against agent metadata
+	HostnamePattern string            `json:"hostname_pattern,omitempty"` // Glob, e.g. "lab-*"
+	NotSeenFor      string            `json:"not_seen_for,omitempty"`     // Last seen longer ago than this duration, e.g. "72h"
+}
+
+// Validate checks that the filter has a criterion, so that no bulk operation
+// targets the whole fleet by mistake, and that its criteria are well-formed
+func (f *AgentFilter) Validate() error {
+	if len(f.Paws) == 0 && len(f.Platforms) == 0 && len(f.Statuses) == 0 &&
+		len(f.Tags) == 0 && f.HostnamePattern == "" && f.NotSeenFor == "" {
+		return errors.New("filter needs at least one criterion")
+	}
+	if f.NotSeenFor != "" {
+		if d, err := time.ParseDuration(f.NotSeenFor); err != nil || d <= 0 {
+			return errors.New("not_seen_for must be a positive duration, e.g. 72h")
+		}
+	}
+	return f.selector().Validate()
+}
+
+// Matches reports whether an agent satisfies every criterion of the filter at now
+func (f *AgentFilter) Matches(agent *Agent, now time.Time) bool {
+	if len(f.Paws) > 0 && !slices.Contains(f.Paws, agent.Paw) {
+		return false
+	}
+	if f.NotSeenFor != "" {
+		d, err := time.ParseDuration(f.NotSeenFor)
+		if err != nil || now.Sub(agent.LastSeen) <= d {
+			return false
+		}
+	}
+	return f.selector().Matches(agent)
+}
+
+// selector returns the criteria the filter shares with agent selectors
+func (f *AgentFilter) selector() *AgentSelector {
+	return &AgentSelector{
+		Name:            "filter",
+		Platforms:       f.Platforms,
+		Statuses:        f.Statuses,
+		Tags:            f.Tags,
+		HostnamePattern: f.HostnamePattern,
+	}
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgentFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  AgentFilter
+		wantErr bool
+	}{
+		{"no criterion", AgentFilter{}, true},
+		{"platform", AgentFilter{Platforms: []string{"linux"}}, false},
+		{"not seen for", AgentFilter{NotSeenFor: "72h"}, false},
+		{"invalid duration", AgentFilter{NotSeenFor: "3 days"}, true},
+		{"negative duration", AgentFilter{NotSeenFor: "-1h"}, true},
+		{"invalid status", AgentFilter{Statuses: []AgentStatus{"gone"}}, true},
+		{"invalid pattern", AgentFilter{HostnamePattern: "lab-["}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.filter.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestAgentFilter_Matches(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	agent := &Agent{
+		Paw:      "paw1",
+		Hostname: "lab-01",
+		Platform: "linux",
+		Status:   AgentOffline,
+		LastSeen: now.Add(-96 * time.Hour),
+		Metadata: map[string]string{"env": "lab"},
+	}
+
+	tests := []struct {
+		name   string
+		filter AgentFilter
+		want   bool
+	}{
+		{"paw", AgentFilter{Paws: []string{"paw2", "paw1"}}, true},
+		{"other paw", AgentFilter{Paws: []string{"paw2"}}, false},
+		{"stale", AgentFilter{NotSeenFor: "72h"}, true},
+		{"seen recently", AgentFilter{NotSeenFor: "120h"}, false},
+		{"criteria are combined", AgentFilter{Platforms: []string{"linux"}, HostnamePattern: "lab-*", Tags: map[string]string{"env": "lab"}}, true},
+		{"status mismatch", AgentFilter{Platforms: []string{"linux"}, Statuses: []AgentStatus{AgentOnline}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(agent, now); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
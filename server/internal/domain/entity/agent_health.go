@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Agent health ratings
+const (
+	AgentHealthy       = "healthy"
+	AgentDegraded      = "degraded"
+	AgentHealthUnknown = "unknown" // No check-in in the period
+)
+
+// Issues degrading the health of an agent, with their thresholds
+const (
+	HealthIssueLate     = "late_beacons" // Fewer than HealthyAdherence percent of check-ins on time
+	HealthIssueDowntime = "low_uptime"   // Online less than HealthyUptime percent of the period
+	HealthIssueLatency  = "slow_beacons" // 95th percentile latency above HealthyLatencyMs
+
+	HealthyAdherence = 90.0
+	HealthyUptime    = 95.0
+	HealthyLatencyMs = 5000.0
+)
+
+// AgentBeacon is a check-in of an agent, with how it kept to its beacon
+type AgentBeacon struct {
+	AgentPaw   string         `json:"agent_paw"`
+	ReceivedAt time.Time      `json:"received_at"`
+	Interval   int            `json:"interval"` // Beacon the agent was expected to keep
+	Jitter     int            `json:"jitter"`
+	Gap        *time.Duration `json:"gap,omitempty"`     // Since the previous check-in, unknown for the first one
+	Latency    *time.Duration `json:"latency,omitempty"` // From the agent sending it, when it sent its clock
+	Missed     int            `json:"missed"`            // Check-ins expected within the gap that never came
+}
+
+// NewAgentBeacon creates the check-in of an agent keeping to settings, from
+// its previous check-in and the time the agent sent it, both optional. A
+// latency below zero is a skewed agent clock and is dropped.
+func NewAgentBeacon(paw string, settings BeaconSettings, previous, sentAt *time.Time, receivedAt time.Time) *AgentBeacon {
+	beacon := &AgentBeacon{
+		AgentPaw:   paw,
+		ReceivedAt: receivedAt,
+		Interval:   settings.Interval,
+		Jitter:     settings.Jitter,
+	}
+	if previous != nil {
+		gap := receivedAt.Sub(*previous)
+		beacon.Gap = &gap
+		if gap > settings.MaxDelay() && settings.Interval > 0 {
+			beacon.Missed = max(0, int(math.Round(gap.Seconds()/float64(settings.Interval)))-1)
+		}
+	}
+	if sentAt != nil {
+		if latency := receivedAt.Sub(*sentAt); latency >= 0 {
+			beacon.Latency = &latency
+		}
+	}
+	return beacon
+}
+
+// OnTime reports whether the check-in came within the longest delay of its
+// beacon, false when its gap is unknown
+func (b *AgentBeacon) OnTime() bool {
+	settings := BeaconSettings{Interval: b.Interval, Jitter: b.Jitter}
+	return b.Gap != nil && *b.Gap <= settings.MaxDelay()
+}
+
+// AgentHealth is how reliably an agent checked in over a period
+type AgentHealth struct {
+	AgentPaw         string    `json:"agent_paw"`
+	Since            time.Time `json:"since"`
+	Until            time.Time `json:"until"`
+	Health           string    `json:"health"` // healthy, degraded or unknown
+	Issues           []string  `json:"issues"`
+	Beacons          int       `json:"beacons"`
+	MissedBeacons    int       `json:"missed_beacons"`
+	AdherencePercent float64   `json:"adherence_percent"` // Check-ins within the longest delay of their beacon
+	MedianGapSeconds *float64  `json:"median_gap_seconds"`
+	P95GapSeconds    *float64  `json:"p95_gap_seconds"`
+	MedianLatencyMs  *float64  `json:"median_latency_ms"`
+	P95LatencyMs     *float64  `json:"p95_latency_ms"`
+	UptimePercent    float64   `json:"uptime_percent"`
+}
+
+// ComputeAgentHealth rates an agent from its check-ins and availability
+// events in the period from since to until, both oldest first. Check-ins
+// whose gap is unknown count towards neither adherence nor gaps.
+func ComputeAgentHealth(
+	paw string,
+	status AgentStatus,
+	beacons []*AgentBeacon,
+	events []*AgentAvailabilityEvent,
+	since, until time.Time,
+) *AgentHealth {
+	health := &AgentHealth{
+		AgentPaw:      paw,
+		Since:         since,
+		Until:         until,
+		Health:        AgentHealthUnknown,
+		Issues:        []string{},
+		Beacons:       len(beacons),
+		UptimePercent: AgentUptime(status, events, since, until),
+	}
+
+	var gaps, latencies []float64
+	onTime := 0
+	for _, beacon := range beacons {
+		health.MissedBeacons += beacon.Missed
+		if beacon.Gap != nil {
+			gaps = append(gaps, beacon.Gap.Seconds())
+			if beacon.OnTime() {
+				onTime++
+			}
+		}
+		if beacon.Latency != nil {
+			latencies = append(latencies, float64(beacon.Latency.Milliseconds()))
+		}
+	}
+	if len(gaps) > 0 {
+		health.AdherencePercent = math.Round(float64(onTime)/float64(len(gaps))*1000) / 10
+		health.MedianGapSeconds, health.P95GapSeconds = percentile(gaps, 50), percentile(gaps, 95)
+	}
+	if len(latencies) > 0 {
+		health.MedianLatencyMs, health.P95LatencyMs = percentile(latencies, 50), percentile(latencies, 95)
+	}
+
+	if len(beacons) == 0 {
+		return health
+	}
+	if len(gaps) > 0 && health.AdherencePercent < HealthyAdherence {
+		health.Issues = append(health.Issues, HealthIssueLate)
+	}
+	if health.UptimePercent < HealthyUptime {
+		health.Issues = append(health.Issues, HealthIssueDowntime)
+	}
+	if health.P95LatencyMs != nil && *health.P95LatencyMs > HealthyLatencyMs {
+		health.Issues = append(health.Issues, HealthIssueLatency)
+	}
+	health.Health = AgentHealthy
+	if len(health.Issues) > 0 {
+		health.Health = AgentDegraded
+	}
+	return health
+}
+
+// percentile returns the nearest-rank percentile p of values, rounded to a
+// tenth, leaving them unsorted
+func percentile(values []float64, p float64) *float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := max(int(math.Ceil(p/100*float64(len(sorted))))-1, 0)
+	value := math.Round(sorted[rank]*10) / 10
+	return &value
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewAgentBeacon(t *testing.T) {
+	settings := BeaconSettings{Interval: 30, Jitter: 20}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(seconds int) *time.Time {
+		at := now.Add(-time.Duration(seconds) * time.Second)
+		return &at
+	}
+
+	tests := []struct {
+		name     string
+		previous *time.Time
+		onTime   bool
+		missed   int
+	}{
+		{"first check-in", nil, false, 0},
+		{"on time", ago(34), true, 0},
+		{"late", ago(40), false, 0},
+		{"two missed", ago(95), false, 2},
+	}
+	for _, tt := range tests {
+		beacon := NewAgentBeacon("paw1", settings, tt.previous, nil, now)
+		if beacon.OnTime() != tt.onTime || beacon.Missed != tt.missed {
+			t.Errorf("%s: expected on time %v and %d missed, got %v and %d", tt.name, tt.onTime, tt.missed, beacon.OnTime(), beacon.Missed)
+		}
+	}
+
+	if beacon := NewAgentBeacon("paw1", settings, nil, ago(2), now); beacon.Latency == nil || *beacon.Latency != 2*time.Second {
+		t.Errorf("Expected a 2s latency, got %v", beacon.Latency)
+	}
+	ahead := now.Add(time.Minute)
+	if beacon := NewAgentBeacon("paw1", settings, nil, &ahead, now); beacon.Latency != nil {
+		t.Errorf("Expected the latency of a skewed clock dropped, got %v", *beacon.Latency)
+	}
+}
+
+func TestComputeAgentHealth(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(10 * time.Hour)
+	settings := BeaconSettings{Interval: 60}
+	beacons := func(gaps ...int) []*AgentBeacon {
+		var list []*AgentBeacon
+		at := since
+		for i, gap := range gaps {
+			next := at.Add(time.Duration(gap) * time.Second)
+			var previous *time.Time
+			if i > 0 {
+				previous = &at
+			}
+			sent := next.Add(-100 * time.Millisecond)
+			list = append(list, NewAgentBeacon("paw1", settings, previous, &sent, next))
+			at = next
+		}
+		return list
+	}
+
+	health := ComputeAgentHealth("paw1", AgentOnline, nil, nil, since, until)
+	if health.Health != AgentHealthUnknown || health.UptimePercent != 100 {
+		t.Errorf("Expected an unknown health without check-ins, got %+v", health)
+	}
+
+	health = ComputeAgentHealth("paw1", AgentOnline, beacons(0, 60, 60, 60, 60), nil, since, until)
+	if health.Health != AgentHealthy || health.AdherencePercent != 100 || len(health.Issues) != 0 {
+		t.Errorf("Expected a healthy agent, got %+v", health)
+	}
+	if *health.MedianGapSeconds != 60 || *health.MedianLatencyMs != 100 {
+		t.Errorf("Expected 60s gaps and 100ms latency, got %v and %v", *health.MedianGapSeconds, *health.MedianLatencyMs)
+	}
+
+	health = ComputeAgentHealth("paw1", AgentOffline, beacons(0, 60, 180, 60, 300),
+		[]*AgentAvailabilityEvent{{Status: AgentOffline, OccurredAt: since.Add(5 * time.Hour)}}, since, until)
+	if health.Health != AgentDegraded || health.AdherencePercent != 50 || health.MissedBeacons != 6 {
+		t.Errorf("Expected a degraded agent with 6 missed beacons, got %+v", health)
+	}
+	if *health.P95GapSeconds != 300 {
+		t.Errorf("Expected a 300s 95th percentile gap, got %v", *health.P95GapSeconds)
+	}
+	if len(health.Issues) != 2 || health.Issues[0] != HealthIssueLate || health.Issues[1] != HealthIssueDowntime {
+		t.Errorf("Expected late beacons and low uptime, got %v", health.Issues)
+	}
+}
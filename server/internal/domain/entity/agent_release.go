@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AgentRelease is a signed agent binary for one platform. Agents of that
+// platform running an older version are told to update to it, a stable share
+// of them at a time while the rollout is staged.
+type AgentRelease struct {
+	ID             string    `json:"id"`
+	Version        string    `json:"version"`         // Semantic version, e.g. 1.4.0
+	Platform       string    `json:"platform"`        // "windows", "linux", "darwin"
+	SHA256         string    `json:"sha256"`          // Hex checksum of the binary
+	Signature      string    `json:"signature"`       // Base64 Ed25519 signature of AgentReleaseMessage
+	Size           int64     `json:"size"`            // Bytes
+	RolloutPercent int       `json:"rollout_percent"` // Share of the platform's agents offered the release, 0 pauses it
+	UploadedBy     string    `json:"uploaded_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AgentReleaseMessage returns the message a release is signed over: its
+// version, the lowercase hex SHA-256 and the size of the binary, one per line
+// after a fixed header. Signing the version along with the binary keeps an old
+// release from being offered again under a newer version.
+func AgentReleaseMessage(version, sha256 string, size int64) []byte {
+	return []byte(fmt.Sprintf("autostrike-agent-release\n%s\n%s\n%d", version, strings.ToLower(sha256), size))
+}
+
+// agentPlatforms are the platforms agents are built for
+var agentPlatforms = map[string]bool{"windows": true, "linux": true, "darwin": true}
+
+// Validate checks the version, platform and rollout of the release
+func (r *AgentRelease) Validate() error {
+	if _, err := ParseAgentVersion(r.Version); err != nil {
+		return err
+	}
+	if !agentPlatforms[r.Platform] {
+		return errors.New("platform must be windows, linux or darwin")
+	}
+	if r.RolloutPercent < 0 || r.RolloutPercent > 100 {
+		return errors.New("rollout_percent must be between 0 and 100")
+	}
+	return nil
+}
+
+// ParseAgentVersion parses a MAJOR.MINOR.PATCH version, with an optional "v"
+// prefix. Pre-release and build suffixes are not supported.
+func ParseAgentVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) != 3 {
+		return parsed, fmt.Errorf("version %q must be MAJOR.MINOR.PATCH", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return parsed, fmt.Errorf("version %q must be MAJOR.MINOR.PATCH", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// CompareAgentVersions returns -1, 0 or 1 as version a is older than, equal to
+// or newer than version b. A version that does not parse is older than any
+// valid version, so agents reporting none are offered updates.
+func CompareAgentVersions(a, b string) int {
+	va, errA := ParseAgentVersion(a)
+	vb, errB := ParseAgentVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
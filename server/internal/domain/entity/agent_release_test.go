@@ -0,0 +1,50 @@
+package entity
+
+import "testing"
+
+func TestAgentRelease_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		release AgentRelease
+		wantErr bool
+	}{
+		{"valid", AgentRelease{Version: "1.4.0", Platform: "linux", RolloutPercent: 25}, false},
+		{"v prefix", AgentRelease{Version: "v2.0.1", Platform: "windows", RolloutPercent: 100}, false},
+		{"short version", AgentRelease{Version: "1.4", Platform: "linux"}, true},
+		{"pre-release", AgentRelease{Version: "1.4.0-rc1", Platform: "linux"}, true},
+		{"unknown platform", AgentRelease{Version: "1.4.0", Platform: "freebsd"}, true},
+		{"negative rollout", AgentRelease{Version: "1.4.0", Platform: "darwin", RolloutPercent: -1}, true},
+		{"rollout over 100", AgentRelease{Version: "1.4.0", Platform: "darwin", RolloutPercent: 101}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.release.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompareAgentVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.4.0", "1.4.0", 0},
+		{"v1.4.0", "1.4.0", 0},
+		{"1.4.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.4.1", "1.4.0", 1},
+		{"", "0.1.0", -1},
+		{"0.1.0", "unknown", 1},
+		{"", "unknown", 0},
+	}
+
+	for _, tt := range tests {
+		if got := CompareAgentVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareAgentVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
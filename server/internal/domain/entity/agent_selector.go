@@ -0,0 +1,162 @@
+package entity
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"time"
+)
+
+// AgentSelector is a named, reusable agent targeting expression. An agent matches
+// when it satisfies every non-empty criterion; values inside a criterion are alternatives.
+type AgentSelector struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description,omitempty"`
+	Platforms       []string          `json:"platforms,omitempty"`        // Any of, e.g. ["windows", "linux"]
+	Statuses        []AgentStatus     `json:"statuses,omitempty"`         // Any of
+	Executors       []string          `json:"executors,omitempty"`        // Agent supports any of
+	Tags            map[string]string `json:"tags,omitempty"`             // All of, matched against agent metadata
+	HostnamePattern string            `json:"hostname_pattern,omitempty"` // Glob, e.g. "web-*"
+	// Host inventory criteria, matched against what the agent reported at registration
+	Architectures    []string          `json:"architectures,omitempty"`     // Any of, e.g. ["x86_64"]
+	Elevated         *bool             `json:"elevated,omitempty"`          // Running as root or an administrator, or not
+	Domains          []string          `json:"domains,omitempty"`           // Any of, case-insensitive
+	SecurityProducts []string          `json:"security_products,omitempty"` // Host runs any of
+	Interpreters     map[string]string `json:"interpreters,omitempty"`      // All of, name to minimum version ("" for any)
+	CreatedBy        string            `json:"created_by,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// Validate checks that the selector has a name and well-formed criteria
+func (s *AgentSelector) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is required")
+	}
+	for _, status := range s.Statuses {
+		switch status {
+		case AgentOnline, AgentOffline, AgentBusy, AgentUntrusted:
+		default:
+			return errors.New("invalid agent status: " + string(status))
+		}
+	}
+	if s.HostnamePattern != "" {
+		if _, err := path.Match(s.HostnamePattern, ""); err != nil {
+			return errors.New("invalid hostname pattern: " + s.HostnamePattern)
+		}
+	}
+	for name, minVersion := range s.Interpreters {
+		if name == "" {
+			return errors.New("interpreter name is required")
+		}
+		if _, ok := parseVersionComponents(minVersion); minVersion != "" && !ok {
+			return errors.New("invalid minimum version for " + name + ": " + minVersion)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether an agent satisfies every criterion of the selector
+func (s *AgentSelector) Matches(agent *Agent) bool {
+	if len(s.Platforms) > 0 && !s.matchesPlatform(agent) {
+		return false
+	}
+	if len(s.Statuses) > 0 && !s.matchesStatus(agent) {
+		return false
+	}
+	if len(s.Executors) > 0 && !s.matchesExecutor(agent) {
+		return false
+	}
+	for key, value := range s.Tags {
+		if agentValue, ok := agent.Metadata[key]; !ok || agentValue != value {
+			return false
+		}
+	}
+	if s.HostnamePattern != "" {
+		if matched, _ := path.Match(s.HostnamePattern, agent.Hostname); !matched {
+			return false
+		}
+	}
+	return s.matchesInventory(agent)
+}
+
+// matchesInventory checks the host inventory criteria of the selector
+func (s *AgentSelector) matchesInventory(agent *Agent) bool {
+	if len(s.Architectures) > 0 && !s.matchesArchitecture(agent) {
+		return false
+	}
+	if s.Elevated != nil && *s.Elevated != agent.Elevated {
+		return false
+	}
+	if len(s.Domains) > 0 && !containsFold(s.Domains, agent.Domain) {
+		return false
+	}
+	if len(s.SecurityProducts) > 0 && !s.matchesSecurityProduct(agent) {
+		return false
+	}
+	for name, minVersion := range s.Interpreters {
+		if !agent.HasInterpreter(name, minVersion) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *AgentSelector) matchesPlatform(agent *Agent) bool {
+	for _, platform := range s.Platforms {
+		if platform == agent.Platform {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AgentSelector) matchesStatus(agent *Agent) bool {
+	for _, status := range s.Statuses {
+		if status == agent.Status {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AgentSelector) matchesExecutor(agent *Agent) bool {
+	for _, executor := range s.Executors {
+		if agent.SupportsExecutor(executor) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AgentSelector) matchesArchitecture(agent *Agent) bool {
+	for _, arch := range s.Architectures {
+		if NormalizeArchitecture(arch) == NormalizeArchitecture(agent.Architecture) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AgentSelector) matchesSecurityProduct(agent *Agent) bool {
+	for _, product := range s.SecurityProducts {
+		if agent.HasSecurityProduct(product) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether a non-empty value is in the list, ignoring case
+func containsFold(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package entity
+
+import "testing"
+
+func TestAgentSelector_Matches(t *testing.T) {
+	agent := &Agent{
+		Paw:       "paw-1",
+		Hostname:  "web-01",
+		Platform:  "linux",
+		Executors: []string{"sh", "bash"},
+		Status:    AgentOnline,
+		Metadata:  map[string]string{"env": "prod", "team": "blue"},
+	}
+
+	tests := []struct {
+		name     string
+		selector AgentSelector
+		expected bool
+	}{
+		{"empty selector matches everything", AgentSelector{}, true},
+		{"platform alternatives", AgentSelector{Platforms: []string{"windows", "linux"}}, true},
+		{"platform mismatch", AgentSelector{Platforms: []string{"windows"}}, false},
+		{"status match", AgentSelector{Statuses: []AgentStatus{AgentOnline}}, true},
+		{"status mismatch", AgentSelector{Statuses: []AgentStatus{AgentOffline}}, false},
+		{"executor match", AgentSelector{Executors: []string{"psh", "bash"}}, true},
+		{"executor mismatch", AgentSelector{Executors: []string{"psh"}}, false},
+		{"all tags match", AgentSelector{Tags: map[string]string{"env": "prod", "team": "blue"}}, true},
+		{"tag value mismatch", AgentSelector{Tags: map[string]string{"env": "staging"}}, false},
+		{"missing tag", AgentSelector{Tags: map[string]string{"zone": "dmz"}}, false},
+		{"hostname glob", AgentSelector{HostnamePattern: "web-*"}, true},
+		{"hostname glob mismatch", AgentSelector{HostnamePattern: "db-*"}, false},
+		{"criteria are combined", AgentSelector{Platforms: []string{"linux"}, Tags: map[string]string{"env": "staging"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.selector.Matches(agent); got != tt.expected {
+				t.Errorf("Matches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAgentSelector_MatchesInventory(t *testing.T) {
+	elevated, standard := true, false
+	agent := &Agent{
+		Platform: "windows",
+		AgentInventory: AgentInventory{
+			Architecture:     "x86_64",
+			Elevated:         true,
+			Domain:           "CORP.EXAMPLE.COM",
+			SecurityProducts: []string{"Microsoft Defender"},
+			Interpreters:     map[string]string{"powershell": "5.1.19041"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		selector AgentSelector
+		expected bool
+	}{
+		{"architecture alias", AgentSelector{Architectures: []string{"amd64"}}, true},
+		{"architecture mismatch", AgentSelector{Architectures: []string{"arm64"}}, false},
+		{"elevated", AgentSelector{Elevated: &elevated}, true},
+		{"not elevated", AgentSelector{Elevated: &standard}, false},
+		{"domain ignores case", AgentSelector{Domains: []string{"corp.example.com"}}, true},
+		{"domain mismatch", AgentSelector{Domains: []string{"LAB"}}, false},
+		{"security product", AgentSelector{SecurityProducts: []string{"microsoft defender", "SentinelOne"}}, true},
+		{"security product mismatch", AgentSelector{SecurityProducts: []string{"SentinelOne"}}, false},
+		{"interpreter minimum version", AgentSelector{Interpreters: map[string]string{"powershell": "5"}}, true},
+		{"interpreter too old", AgentSelector{Interpreters: map[string]string{"powershell": "7"}}, false},
+		{"missing interpreter", AgentSelector{Interpreters: map[string]string{"python3": ""}}, false},
+		{"elevated windows with powershell 5+", AgentSelector{
+			Platforms:    []string{"windows"},
+			Elevated:     &elevated,
+			Interpreters: map[string]string{"powershell": "5"},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.selector.Matches(agent); got != tt.expected {
+				t.Errorf("Matches() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAgentSelector_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector AgentSelector
+		wantErr  bool
+	}{
+		{"valid", AgentSelector{Name: "Linux prod", Platforms: []string{"linux"}, HostnamePattern: "web-*"}, false},
+		{"missing name", AgentSelector{Platforms: []string{"linux"}}, true},
+		{"invalid status", AgentSelector{Name: "s", Statuses: []AgentStatus{"sleeping"}}, true},
+		{"invalid pattern", AgentSelector{Name: "s", HostnamePattern: "web-["}, true},
+		{"interpreter without version", AgentSelector{Name: "s", Interpreters: map[string]string{"python3": ""}}, false},
+		{"invalid interpreter version", AgentSelector{Name: "s", Interpreters: map[string]string{"powershell": "latest"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.selector.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -151,3 +151,93 @@ func TestAgentStatus_Constants(t *testing.T) {
 		t.Errorf("AgentUntrusted = %s, want untrusted", AgentUntrusted)
 	}
 }
+
+func TestAgent_HasInterpreter(t *testing.T) {
+	agent := &Agent{AgentInventory: AgentInventory{Interpreters: map[string]string{"powershell": "5.1.19041", "python3": "3.9.2"}}}
+
+	tests := []struct {
+		name       string
+		minVersion string
+		want       bool
+	}{
+		{"powershell", "", true},
+		{"PowerShell", "5", true},
+		{"powershell", "5.1", true},
+		{"powershell", "7", false},
+		{"python3", "3.10", false},
+		{"python3", "3.9", true},
+		{"perl", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.minVersion, func(t *testing.T) {
+			if got := agent.HasInterpreter(tt.name, tt.minVersion); got != tt.want {
+				t.Errorf("Agent.HasInterpreter(%s, %s) = %v, want %v", tt.name, tt.minVersion, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		min     string
+		want    bool
+	}{
+		{"5.1", "5", true},
+		{"5", "5.0.0", true},
+		{"v7.4.1", "7.4", true},
+		{"3.12.0-rc1", "3.12", true},
+		{"3.9", "3.10", false},
+		{"unknown", "1", false},
+		{"1.0", "latest", false},
+	}
+
+	for _, tt := range tests {
+		if got := VersionAtLeast(tt.version, tt.min); got != tt.want {
+			t.Errorf("VersionAtLeast(%s, %s) = %v, want %v", tt.version, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestAgent_IsExpectedSource(t *testing.T) {
+	agent := &Agent{Paw: "paw1"}
+	if !agent.IsExpectedSource("203.0.113.7") {
+		t.Error("Expected any source without allowed networks")
+	}
+
+	agent.AllowedNetworks = []string{"10.0.0.0/8", "192.168.1.10"}
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"10.20.30.40", true},
+		{"192.168.1.10", true},
+		{"::ffff:10.1.1.1", true},
+		{"192.168.1.11", false},
+		{"203.0.113.7", false},
+		{"not an ip", false},
+	}
+	for _, tt := range tests {
+		if got := agent.IsExpectedSource(tt.ip); got != tt.want {
+			t.Errorf("IsExpectedSource(%q) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestParseNetworkList(t *testing.T) {
+	networks, err := ParseNetworkList([]string{" 10.1.2.3/8 ", "", "2001:db8::1"})
+	if err != nil {
+		t.Fatalf("ParseNetworkList failed: %v", err)
+	}
+	got := networks.Strings()
+	if len(got) != 2 || got[0] != "10.0.0.0/8" || got[1] != "2001:db8::1/128" {
+		t.Errorf("Expected normalized networks, got %v", got)
+	}
+
+	for _, value := range []string{"10.0.0.0/33", "example.com"} {
+		if _, err := ParseNetworkList([]string{value}); err == nil {
+			t.Errorf("Expected %q rejected", value)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// APIKeyPrefix starts every API key, so keys are told apart from access tokens
+// and recognized by secret scanners
+const APIKeyPrefix = "ask_"
+
+// APIKey is a long-lived credential a user creates for scripts and the
+// autostrikectl CLI. It acts with the current role of its owner; only its
+// SHA-256 hash is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // First characters of the key, to recognize it
+	KeyHash    string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil for a key that never expires
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Expired reports whether the key can no longer be used at now
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Artifact is a file an agent collected while running a technique, such as
+// the archive a simulated exfiltration staged or a screenshot. The content is
+// stored apart and purged after the retention period.
+type Artifact struct {
+	ID          string    `json:"id"`
+	ResultID    string    `json:"result_id"`
+	ExecutionID string    `json:"execution_id"`
+	AgentPaw    string    `json:"agent_paw"`
+	Name        string    `json:"name"`         // File name on the agent, without directories
+	Path        string    `json:"path"`         // Path the agent collected the file from
+	ContentType string    `json:"content_type"` // Detected from the content
+	SHA256      string    `json:"sha256"`
+	Size        int64     `json:"size"` // Bytes
+	StorageKey  string    `json:"-"`    // Blob holding the content when it is not stored in the database
+	CreatedAt   time.Time `json:"created_at"`
+}
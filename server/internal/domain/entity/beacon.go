@@ -0,0 +1,66 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Beacon interval bounds, in seconds
+const (
+	MinBeaconInterval = 5
+	MaxBeaconInterval = 24 * 60 * 60
+	MaxBeaconJitter   = 90 // Percent
+)
+
+// BeaconScope is what a beacon override applies to
+type BeaconScope string
+
+const (
+	BeaconScopeAgent    BeaconScope = "agent"    // A single agent, by paw
+	BeaconScopeSelector BeaconScope = "selector" // The agents matching a saved agent selector
+)
+
+// BeaconSettings is how often an agent checks in with the server
+type BeaconSettings struct {
+	Interval int `json:"interval"` // Seconds between check-ins
+	Jitter   int `json:"jitter"`   // Percent each delay randomly varies by, up or down
+}
+
+// Validate checks the interval and jitter bounds
+func (b BeaconSettings) Validate() error {
+	if b.Interval < MinBeaconInterval || b.Interval > MaxBeaconInterval {
+		return fmt.Errorf("interval must be between %d and %d seconds", MinBeaconInterval, MaxBeaconInterval)
+	}
+	if b.Jitter < 0 || b.Jitter > MaxBeaconJitter {
+		return fmt.Errorf("jitter must be between 0 and %d percent", MaxBeaconJitter)
+	}
+	return nil
+}
+
+// MaxDelay returns the longest delay between two check-ins
+func (b BeaconSettings) MaxDelay() time.Duration {
+	return time.Duration(b.Interval) * time.Second * time.Duration(100+b.Jitter) / 100
+}
+
+// BeaconOverride sets the beacon of an agent, or of the agents matching a selector
+type BeaconOverride struct {
+	Scope  BeaconScope `json:"scope"`
+	Target string      `json:"target"` // Agent paw or selector ID
+	BeaconSettings
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the scope, target and settings of the override
+func (o *BeaconOverride) Validate() error {
+	switch o.Scope {
+	case BeaconScopeAgent, BeaconScopeSelector:
+	default:
+		return errors.New("invalid beacon scope: " + string(o.Scope))
+	}
+	if o.Target == "" {
+		return errors.New("target is required")
+	}
+	return o.BeaconSettings.Validate()
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBeaconSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings BeaconSettings
+		wantErr  bool
+	}{
+		{"valid", BeaconSettings{Interval: 60, Jitter: 20}, false},
+		{"no jitter", BeaconSettings{Interval: 5}, false},
+		{"interval too short", BeaconSettings{Interval: 1}, true},
+		{"interval too long", BeaconSettings{Interval: MaxBeaconInterval + 1}, true},
+		{"negative jitter", BeaconSettings{Interval: 60, Jitter: -1}, true},
+		{"jitter too large", BeaconSettings{Interval: 60, Jitter: 95}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBeaconSettings_MaxDelay(t *testing.T) {
+	if got := (BeaconSettings{Interval: 60, Jitter: 50}).MaxDelay(); got != 90*time.Second {
+		t.Errorf("MaxDelay() = %v, want 1m30s", got)
+	}
+}
+
+func TestBeaconOverride_Validate(t *testing.T) {
+	valid := BeaconSettings{Interval: 60}
+	tests := []struct {
+		name     string
+		override BeaconOverride
+		wantErr  bool
+	}{
+		{"agent", BeaconOverride{Scope: BeaconScopeAgent, Target: "paw1", BeaconSettings: valid}, false},
+		{"selector", BeaconOverride{Scope: BeaconScopeSelector, Target: "sel-1", BeaconSettings: valid}, false},
+		{"invalid scope", BeaconOverride{Scope: "group", Target: "g", BeaconSettings: valid}, true},
+		{"missing target", BeaconOverride{Scope: BeaconScopeAgent, BeaconSettings: valid}, true},
+		{"invalid settings", BeaconOverride{Scope: BeaconScopeAgent, Target: "paw1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.override.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// EmergencyStop is a trip of the emergency stop: running executions are
+// cancelled, schedules paused and agents told to kill their commands, and no
+// execution or ad-hoc task starts until an administrator re-arms it
+type EmergencyStop struct {
+	ID        string    `json:"id"`
+	Reason    string    `json:"reason"`
+	Automatic bool      `json:"automatic"`            // Tripped by the server, e.g. on losing the database
+	TrippedBy string    `json:"tripped_by,omitempty"` // User who tripped it, empty when automatic
+	TrippedAt time.Time `json:"tripped_at"`
+
+	CancelledExecutions []string `json:"cancelled_executions"`
+	PausedSchedules     []string `json:"paused_schedules"`
+	AgentsNotified      int      `json:"agents_notified"`
+
+	RearmedBy string     `json:"rearmed_by,omitempty"`
+	RearmedAt *time.Time `json:"rearmed_at,omitempty"`
+}
+
+// Engaged reports whether the stop has not been re-armed yet
+func (s *EmergencyStop) Engaged() bool {
+	return s.RearmedAt == nil
+}
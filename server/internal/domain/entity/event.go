@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// EventType identifies a platform event published on the event bus
+type EventType string
+
+const (
+	EventExecutionStarted     EventType = "execution.started"
+	EventExecutionCompleted   EventType = "execution.completed"
+	EventExecutionFailed      EventType = "execution.failed"
+	EventExecutionCancelled   EventType = "execution.cancelled"
+	EventExecutionInterrupted EventType = "execution.interrupted"
+	EventResultCompleted      EventType = "result.completed"
+	EventAgentOnline          EventType = "agent.online"
+	EventAgentOffline         EventType = "agent.offline"
+	EventScheduleFailed       EventType = "schedule.failed"
+	EventSecurityAlert        EventType = "security.alert"
+	EventDetectionRegression  EventType = "detection.regression"
+)
+
+// EventTypes returns every event type published on the bus
+func EventTypes() []EventType {
+	return []EventType{
+		EventExecutionStarted,
+		EventExecutionCompleted,
+		EventExecutionFailed,
+		EventExecutionCancelled,
+		EventExecutionInterrupted,
+		EventResultCompleted,
+		EventAgentOnline,
+		EventAgentOffline,
+		EventScheduleFailed,
+		EventSecurityAlert,
+		EventDetectionRegression,
+	}
+}
+
+// IsValidEventType checks if an event type is known
+func IsValidEventType(t EventType) bool {
+	for _, known := range EventTypes() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is something that happened on the platform. Only the fields relevant
+// to its type are set, so the event can be streamed as-is to external sinks.
+type Event struct {
+	ID           string           `json:"id"`
+	Type         EventType        `json:"type"`
+	OccurredAt   time.Time        `json:"occurred_at"`
+	Execution    *Execution       `json:"execution,omitempty"`
+	ScenarioName string           `json:"scenario_name,omitempty"`
+	Result       *ExecutionResult `json:"result,omitempty"`
+	Agent        *Agent           `json:"agent,omitempty"`
+	Schedule     *Schedule        `json:"schedule,omitempty"`
+	Anomaly      *ActivityAnomaly `json:"anomaly,omitempty"`
+	Error        string           `json:"error,omitempty"`
+
+	Regressions []*DetectionRegression `json:"regressions,omitempty"`
+}
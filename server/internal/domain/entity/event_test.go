@@ -0,0 +1,14 @@
+package entity
+
+import "testing"
+
+func TestIsValidEventType(t *testing.T) {
+	for _, eventType := range EventTypes() {
+		if !IsValidEventType(eventType) {
+			t.Errorf("Expected %q to be valid", eventType)
+		}
+	}
+	if IsValidEventType("execution_completed") {
+		t.Error("Expected notification type to be an invalid event type")
+	}
+}
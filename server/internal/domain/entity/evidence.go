@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// Evidence is a file an operator attached to a result as proof of its
+// outcome, such as a screenshot of the EDR console or a packet capture.
+// Unlike artifacts, evidence is kept as long as its execution.
+type Evidence struct {
+	ID          string    `json:"id"`
+	ResultID    string    `json:"result_id"`
+	ExecutionID string    `json:"execution_id"`
+	Name        string    `json:"name"`                  // File name as uploaded, without directories
+	Description string    `json:"description,omitempty"` // What the file shows
+	ContentType string    `json:"content_type"`          // Detected from the content
+	SHA256      string    `json:"sha256"`
+	Size        int64     `json:"size"` // Bytes
+	StorageKey  string    `json:"-"`    // Blob holding the content when it is not stored in the database
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ReviewStatus is the stage of the purple-team review of an execution
+type ReviewStatus string
+
+const (
+	ReviewNeeded    ReviewStatus = "needs_review" // Waiting for the reviewer
+	ReviewInReview  ReviewStatus = "in_review"    // The reviewer is going through the results
+	ReviewSignedOff ReviewStatus = "signed_off"   // Approved, the execution and its annotations are locked
+)
+
+// IsValidReviewStatus checks if a review status is known
+func IsValidReviewStatus(s ReviewStatus) bool {
+	return s == ReviewNeeded || s == ReviewInReview || s == ReviewSignedOff
+}
+
+// CanTransitionTo reports whether a review moves from s to next. Reviews only
+// move forward: needs_review, then in_review, then signed_off.
+func (s ReviewStatus) CanTransitionTo(next ReviewStatus) bool {
+	switch s {
+	case ReviewNeeded:
+		return next == ReviewInReview
+	case ReviewInReview:
+		return next == ReviewSignedOff
+	}
+	return false
+}
+
+// ExecutionReview is the purple-team review of a finished execution. The
+// reviewer gives every result a disposition (its annotation triage), then
+// signs the review off, which freezes the export of the execution in Snapshot.
+type ExecutionReview struct {
+	ExecutionID string          `json:"execution_id"`
+	Status      ReviewStatus    `json:"status"`
+	ReviewerID  string          `json:"reviewer_id,omitempty"`
+	RequestedBy string          `json:"requested_by,omitempty"`
+	RequestedAt time.Time       `json:"requested_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	SignedOffBy string          `json:"signed_off_by,omitempty"`
+	SignedOffAt *time.Time      `json:"signed_off_at,omitempty"`
+	Notes       string          `json:"notes,omitempty"`
+	Snapshot    json.RawMessage `json:"-"` // Export of the execution at sign-off
+}
+
+// Locked reports whether the execution was signed off and can no longer be annotated
+func (r *ExecutionReview) Locked() bool {
+	return r != nil && r.Status == ReviewSignedOff
+}
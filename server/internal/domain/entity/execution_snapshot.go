@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// ExecutionSnapshot is the environment an execution was launched in: the
+// server settings, the scenario and techniques as they were, and the inventory
+// of the agents. It keeps results interpretable after later edits.
+type ExecutionSnapshot struct {
+	ExecutionID string              `json:"execution_id"`
+	CapturedAt  time.Time           `json:"captured_at"`
+	Server      ServerSnapshot      `json:"server"`
+	Scenario    ScenarioSnapshot    `json:"scenario"`
+	Techniques  []TechniqueSnapshot `json:"techniques"` // Techniques of the scenario phases, in phase order
+	Agents      []*Agent            `json:"agents"`
+}
+
+// ServerSnapshot is the server configuration an execution ran with
+type ServerSnapshot struct {
+	Version               string `json:"version,omitempty"` // Server build
+	SafeMode              bool   `json:"safe_mode"`
+	ScoringProfileID      string `json:"scoring_profile_id,omitempty"`
+	ScoringProfileVersion int    `json:"scoring_profile_version,omitempty"`
+	TaskQueueTTL          string `json:"task_queue_ttl,omitempty"` // Empty when tasks are not queued for offline agents
+	OutputMaxSize         int    `json:"output_max_size"`          // Bytes kept of a result output
+}
+
+// ScenarioSnapshot is the scenario an execution was planned from. Modified is
+// not stored: it is set on read when the scenario changed or was deleted since.
+type ScenarioSnapshot struct {
+	Fingerprint string    `json:"fingerprint"`
+	Scenario    *Scenario `json:"scenario"`
+	Modified    bool      `json:"modified"`
+}
+
+// TechniqueSnapshot is a technique as an execution ran it. Modified is not
+// stored: it is set on read when the technique changed or was deleted since.
+type TechniqueSnapshot struct {
+	Fingerprint string     `json:"fingerprint"`
+	Technique   *Technique `json:"technique"`
+	Modified    bool       `json:"modified"`
+}
+
+// ScenarioFingerprint identifies the content of a scenario. Timestamps are
+// left out, so saving a scenario unchanged keeps its fingerprint.
+func ScenarioFingerprint(scenario *Scenario) string {
+	content := *scenario
+	content.CreatedAt, content.UpdatedAt, content.DeletedAt = time.Time{}, time.Time{}, nil
+	return fingerprint(content)
+}
+
+// TechniqueFingerprint identifies the content of a technique, whether it is in
+// the trash or not
+func TechniqueFingerprint(technique *Technique) string {
+	content := *technique
+	content.DeletedAt = nil
+	return fingerprint(content)
+}
+
+// fingerprint returns the hex SHA-256 of the JSON encoding of v
+func fingerprint(v any) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
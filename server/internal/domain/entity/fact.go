@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// Fact is a named value extracted from the output of a technique during an
+// execution, available to the commands of the techniques that follow
+type Fact struct {
+	ID          string    `json:"id"`
+	ExecutionID string    `json:"execution_id"`
+	AgentPaw    string    `json:"agent_paw"`    // Agent whose output held the fact
+	ResultID    string    `json:"result_id"`    // Result the fact was extracted from
+	TechniqueID string    `json:"technique_id"` // Technique whose parser extracted it
+	Name        string    `json:"name"`
+	Value       string    `json:"value"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobStatus is the state of a background job
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending" // Queued, waiting for a worker
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// JobType identifies the work a background job does
+type JobType string
+
+const (
+	JobContentImport   JobType = "content_import"
+	JobExecutionExport JobType = "execution_export"
+	JobRetention       JobType = "retention"
+	JobTechniqueSync   JobType = "technique_sync"
+)
+
+// Job is long-running work done in the background rather than in the HTTP
+// request that started it. Progress counts the items processed out of Total,
+// zero when the job does not know how many it has. Result holds the JSON
+// outcome of a completed job.
+type Job struct {
+	ID          string          `json:"id"`
+	Type        JobType         `json:"type"`
+	Status      JobStatus       `json:"status"`
+	Progress    int             `json:"progress"`
+	Total       int             `json:"total"`
+	Message     string          `json:"message,omitempty"` // Current step
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	CreatedBy   string          `json:"created_by"` // ID of the user who started the job
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	CompletedAt *time.Time      `json:"completed_at,omitempty"`
+}
+
+// Finished tells whether the job completed or failed
+func (j *Job) Finished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// NetworkList is a list of networks that source addresses are checked against
+type NetworkList []netip.Prefix
+
+// ParseNetworkList parses CIDR networks such as "10.0.0.0/8"; a bare address
+// is a single-host network. Empty entries are skipped.
+func ParseNetworkList(values []string) (NetworkList, error) {
+	var networks NetworkList
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			addr, err := netip.ParseAddr(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q", value)
+			}
+			networks = append(networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q", value)
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// Strings returns the networks in CIDR notation
+func (l NetworkList) Strings() []string {
+	values := make([]string, len(l))
+	for i, prefix := range l {
+		values[i] = prefix.String()
+	}
+	return values
+}
+
+// Contains reports whether an address falls in one of the networks. An
+// invalid address is in none.
+func (l NetworkList) Contains(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
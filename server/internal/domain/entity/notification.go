@@ -1,42 +1,232 @@
 package entity
 
-import "time"
+import (
+	"fmt"
+	"net/mail"
+	"time"
+)
 
 // NotificationType represents the type of notification
 type NotificationType string
 
 const (
-	NotificationExecutionStarted   NotificationType = "execution_started"
-	NotificationExecutionCompleted NotificationType = "execution_completed"
-	NotificationExecutionFailed    NotificationType = "execution_failed"
-	NotificationScoreAlert         NotificationType = "score_alert"
-	NotificationAgentOffline       NotificationType = "agent_offline"
+	NotificationExecutionStarted    NotificationType = "execution_started"
+	NotificationExecutionCompleted  NotificationType = "execution_completed"
+	NotificationExecutionFailed     NotificationType = "execution_failed"
+	NotificationScoreAlert          NotificationType = "score_alert"
+	NotificationAgentOffline        NotificationType = "agent_offline"
+	NotificationSecurityAlert       NotificationType = "security_alert"
+	NotificationDetectionRegression NotificationType = "detection_regression"
+	NotificationCriticalUndetected  NotificationType = "critical_undetected" // Critical-severity techniques ran without being blocked or detected
 )
 
 // NotificationChannel represents the delivery channel
 type NotificationChannel string
 
 const (
-	ChannelEmail   NotificationChannel = "email"
-	ChannelWebhook NotificationChannel = "webhook"
+	ChannelEmail     NotificationChannel = "email"
+	ChannelWebhook   NotificationChannel = "webhook"
+	ChannelTeams     NotificationChannel = "teams"     // Microsoft Teams incoming webhook, as adaptive cards
+	ChannelPagerDuty NotificationChannel = "pagerduty" // PagerDuty Events API v2 incident
+	ChannelOpsgenie  NotificationChannel = "opsgenie"  // Opsgenie alert
+)
+
+// NotificationPreferences maps each notification type to the channels it is
+// delivered on. Types missing from the map are not notified at all.
+type NotificationPreferences map[NotificationType][]NotificationChannel
+
+// NotificationTypes returns the notification types users can subscribe to
+func NotificationTypes() []NotificationType {
+	return []NotificationType{
+		NotificationExecutionStarted,
+		NotificationExecutionCompleted,
+		NotificationExecutionFailed,
+		NotificationScoreAlert,
+		NotificationAgentOffline,
+		NotificationSecurityAlert,
+		NotificationDetectionRegression,
+		NotificationCriticalUndetected,
+	}
+}
+
+// IsValidNotificationType checks if a notification type is known
+func IsValidNotificationType(t NotificationType) bool {
+	for _, known := range NotificationTypes() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidNotificationChannel checks if a delivery channel is known
+func IsValidNotificationChannel(c NotificationChannel) bool {
+	return c == ChannelEmail || c == ChannelWebhook || c == ChannelTeams || IsIncidentChannel(c)
+}
+
+// IsIncidentChannel reports whether a channel pages on-call responders; the
+// alerting settings of a schedule apply to these channels
+func IsIncidentChannel(c NotificationChannel) bool {
+	return c == ChannelPagerDuty || c == ChannelOpsgenie
+}
+
+// Wants reports whether a notification type is delivered on at least one channel
+func (p NotificationPreferences) Wants(t NotificationType) bool {
+	return len(p[t]) > 0
+}
+
+// Has reports whether a notification type is delivered on a channel
+func (p NotificationPreferences) Has(t NotificationType, c NotificationChannel) bool {
+	for _, channel := range p[t] {
+		if channel == c {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCriticalNotification reports whether a notification type is emailed at
+// once, ignoring quiet hours and the digest
+func IsCriticalNotification(t NotificationType) bool {
+	return t == NotificationSecurityAlert || t == NotificationCriticalUndetected
+}
+
+// NotificationDigest is how often held emails are batched into one
+type NotificationDigest string
+
+const (
+	DigestOff    NotificationDigest = ""
+	DigestHourly NotificationDigest = "hourly"
+	DigestDaily  NotificationDigest = "daily"
 )
 
+// IsValidNotificationDigest checks if a digest mode is known
+func IsValidNotificationDigest(d NotificationDigest) bool {
+	return d == DigestOff || d == DigestHourly || d == DigestDaily
+}
+
+// Period returns the time between two digests, zero when off
+func (d NotificationDigest) Period() time.Duration {
+	switch d {
+	case DigestHourly:
+		return time.Hour
+	case DigestDaily:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// QuietHours is a daily period during which non-critical emails are held,
+// then sent as one digest once it ends
+type QuietHours struct {
+	Start    string `json:"start"`              // HH:MM
+	End      string `json:"end"`                // HH:MM, earlier than start to span midnight
+	Timezone string `json:"timezone,omitempty"` // IANA name, UTC when empty
+}
+
+// Validate checks the times and the timezone of quiet hours
+func (q *QuietHours) Validate() error {
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("unknown quiet hours timezone: %s", q.Timezone)
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours start: %w", err)
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return fmt.Errorf("invalid quiet hours end: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("quiet hours must not start and end at the same time")
+	}
+	return nil
+}
+
+// Contains reports whether a time falls within the quiet hours. Nil or
+// invalid quiet hours contain nothing.
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return false
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	now := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	if start < end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// parseClock parses an HH:MM time of day into the time since midnight
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an HH:MM time", value)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// UsesChannel reports whether any notification type is delivered on a channel
+func (p NotificationPreferences) UsesChannel(c NotificationChannel) bool {
+	for t := range p {
+		if p.Has(t, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// LegacyNotificationPreferences converts the former single-channel settings
+// (one channel plus a flag per event) to a preference matrix. Security alerts
+// were always delivered on the channel, so they stay subscribed.
+func LegacyNotificationPreferences(channel NotificationChannel, onStart, onComplete, onFailure, onScoreAlert, onAgentOffline bool) NotificationPreferences {
+	prefs := NotificationPreferences{NotificationSecurityAlert: {channel}}
+	flags := map[NotificationType]bool{
+		NotificationExecutionStarted:   onStart,
+		NotificationExecutionCompleted: onComplete,
+		NotificationExecutionFailed:    onFailure,
+		NotificationScoreAlert:         onScoreAlert,
+		NotificationAgentOffline:       onAgentOffline,
+	}
+	for t, enabled := range flags {
+		if enabled {
+			prefs[t] = []NotificationChannel{channel}
+		}
+	}
+	return prefs
+}
+
 // NotificationSettings represents user notification preferences
 type NotificationSettings struct {
-	ID                   string              `json:"id"`
-	UserID               string              `json:"user_id"`
-	Channel              NotificationChannel `json:"channel"`
-	Enabled              bool                `json:"enabled"`
-	EmailAddress         string              `json:"email_address,omitempty"`
-	WebhookURL           string              `json:"webhook_url,omitempty"`
-	NotifyOnStart        bool                `json:"notify_on_start"`
-	NotifyOnComplete     bool                `json:"notify_on_complete"`
-	NotifyOnFailure      bool                `json:"notify_on_failure"`
-	NotifyOnScoreAlert   bool                `json:"notify_on_score_alert"`
-	ScoreAlertThreshold  float64             `json:"score_alert_threshold"` // Alert if score below this
-	NotifyOnAgentOffline bool                `json:"notify_on_agent_offline"`
-	CreatedAt            time.Time           `json:"created_at"`
-	UpdatedAt            time.Time           `json:"updated_at"`
+	ID                  string                  `json:"id"`
+	UserID              string                  `json:"user_id"`
+	Enabled             bool                    `json:"enabled"`
+	EmailAddress        string                  `json:"email_address,omitempty"`
+	WebhookURL          string                  `json:"webhook_url,omitempty"`
+	TeamsWebhookURL     string                  `json:"teams_webhook_url,omitempty"`
+	WebhookSecret       string                  `json:"webhook_secret,omitempty"`        // Shared secret signing webhook bodies, unsigned when empty
+	PagerDutyRoutingKey string                  `json:"pagerduty_routing_key,omitempty"` // Integration key of a PagerDuty Events API v2 service
+	OpsgenieAPIKey      string                  `json:"opsgenie_api_key,omitempty"`      // API key of an Opsgenie API integration
+	Preferences         NotificationPreferences `json:"preferences"`
+	ScoreAlertThreshold float64                 `json:"score_alert_threshold"` // Alert if score below this
+	QuietHours          *QuietHours             `json:"quiet_hours,omitempty"` // Non-critical emails are held meanwhile
+	Digest              NotificationDigest      `json:"digest,omitempty"`      // Non-critical emails are batched, sent one by one when off
+	CreatedAt           time.Time               `json:"created_at"`
+	UpdatedAt           time.Time               `json:"updated_at"`
 }
 
 // Notification represents a notification record
@@ -48,10 +238,20 @@ type Notification struct {
 	Message   string           `json:"message"`
 	Data      map[string]any   `json:"data,omitempty"`
 	Read      bool             `json:"read"`
-	SentAt    *time.Time       `json:"sent_at,omitempty"`
+	SentAt    *time.Time       `json:"sent_at,omitempty"` // When a digest carrying it was emailed
 	CreatedAt time.Time        `json:"created_at"`
+
+	DigestPending bool `json:"digest_pending,omitempty"` // The email is held for the next digest
 }
 
+// SMTPConfigSource tells where the SMTP configuration comes from
+type SMTPConfigSource string
+
+const (
+	SMTPConfigFromEnv SMTPConfigSource = "env" // SMTP_* environment variables
+	SMTPConfigFromAPI SMTPConfigSource = "api" // Set by an admin, stored encrypted
+)
+
 // SMTPConfig represents SMTP server configuration
 type SMTPConfig struct {
 	Host     string `json:"host"`
@@ -60,6 +260,10 @@ type SMTPConfig struct {
 	Password string `json:"-"` // Never expose password in JSON
 	From     string `json:"from"`
 	UseTLS   bool   `json:"use_tls"`
+
+	Source    SMTPConfigSource `json:"source,omitempty"`
+	UpdatedBy string           `json:"updated_by,omitempty"` // Admin who set it through the API
+	UpdatedAt *time.Time       `json:"updated_at,omitempty"`
 }
 
 // IsValid checks if the SMTP configuration is valid
@@ -67,12 +271,63 @@ func (c *SMTPConfig) IsValid() bool {
 	return c.Host != "" && c.Port > 0 && c.From != ""
 }
 
+// Validate checks an SMTP configuration set through the API
+func (c *SMTPConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("invalid from address: %s", c.From)
+	}
+	return nil
+}
+
 // EmailTemplate represents an email template
 type EmailTemplate struct {
 	Subject string
 	Body    string
 }
 
+// DigestEmailTemplate returns the template of the email batching held
+// notifications
+func DigestEmailTemplate() EmailTemplate {
+	return EmailTemplate{
+		Subject: "AutoStrike: {{.Count}} new notification(s)",
+		Body: `Hello,
+
+Here are the notifications of AutoStrike since {{.Since}}.
+
+{{.Notifications}}
+
+Open the dashboard at: {{.Link}}
+
+Best regards,
+AutoStrike Platform`,
+	}
+}
+
+// PasswordResetEmailTemplate returns the template of the email sending a
+// password reset link
+func PasswordResetEmailTemplate() EmailTemplate {
+	return EmailTemplate{
+		Subject: "AutoStrike: Password reset",
+		Body: `Hello {{.Username}},
+
+A password reset was requested for your AutoStrike account. Set a new password at:
+
+{{.Link}}
+
+This link can be used once and expires at {{.ExpiresAt}}. If you did not request it, ignore this
+email: your password is unchanged.
+
+Best regards,
+AutoStrike Platform`,
+	}
+}
+
 // DefaultEmailTemplates returns the default email templates
 func DefaultEmailTemplates() map[NotificationType]EmailTemplate {
 	return map[NotificationType]EmailTemplate{
@@ -87,7 +342,7 @@ Execution ID: {{.ExecutionID}}
 Started At: {{.StartedAt}}
 Safe Mode: {{.SafeMode}}
 
-You can monitor the progress at: {{.DashboardURL}}/executions/{{.ExecutionID}}
+You can monitor the progress at: {{.Link}}
 
 Best regards,
 AutoStrike Platform`,
@@ -109,7 +364,7 @@ Results:
 - Successful: {{.Successful}}
 - Total: {{.Total}}
 
-View full results at: {{.DashboardURL}}/executions/{{.ExecutionID}}
+View full results at: {{.Link}}
 
 Best regards,
 AutoStrike Platform`,
@@ -125,7 +380,7 @@ Execution ID: {{.ExecutionID}}
 Status: Failed
 Error: {{.Error}}
 
-Please check the dashboard for more details: {{.DashboardURL}}/executions/{{.ExecutionID}}
+Please check the dashboard for more details: {{.Link}}
 
 Best regards,
 AutoStrike Platform`,
@@ -143,7 +398,7 @@ Alert Threshold: {{.Threshold}}%
 
 This score is below your configured alert threshold. Please review your security controls.
 
-View details at: {{.DashboardURL}}/executions/{{.ExecutionID}}
+View details at: {{.Link}}
 
 Best regards,
 AutoStrike Platform`,
@@ -159,7 +414,58 @@ Paw: {{.Paw}}
 Platform: {{.Platform}}
 Last Seen: {{.LastSeen}}
 
-Please check the agent status at: {{.DashboardURL}}/agents
+Please check the agent status at: {{.Link}}
+
+Best regards,
+AutoStrike Platform`,
+		},
+		NotificationSecurityAlert: {
+			Subject: "AutoStrike: Security Alert - {{.AlertType}}",
+			Body: `Hello,
+
+Unusual activity has been detected on AutoStrike.
+
+Alert: {{.AlertType}}
+Severity: {{.Severity}}
+User: {{.Username}}
+Details: {{.Message}}
+Detected At: {{.DetectedAt}}
+
+If this activity was not expected, review the account and recent changes.
+
+Best regards,
+AutoStrike Platform`,
+		},
+		NotificationDetectionRegression: {
+			Subject: "AutoStrike: Detection Regression - {{.ScenarioName}}",
+			Body: `Hello,
+
+Defenses handled {{.RegressionCount}} technique(s) worse than on the previous run of a scenario.
+
+Scenario: {{.ScenarioName}}
+Execution ID: {{.ExecutionID}}
+Previous Execution ID: {{.PreviousExecutionID}}
+Regressions:
+{{.Regressions}}
+
+Please review the execution at: {{.Link}}
+
+Best regards,
+AutoStrike Platform`,
+		},
+		NotificationCriticalUndetected: {
+			Subject: "AutoStrike: Critical Techniques Undetected - {{.ScenarioName}}",
+			Body: `Hello,
+
+{{.UndetectedCount}} {{.Severities}} technique(s) ran without being blocked or detected.
+
+Scenario: {{.ScenarioName}}
+Execution ID: {{.ExecutionID}}
+Security Score: {{.Score}}%
+Undetected:
+{{.Undetected}}
+
+Please review the execution at: {{.Link}}
 
 Best regards,
 AutoStrike Platform`,
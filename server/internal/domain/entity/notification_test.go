@@ -2,6 +2,7 @@ package entity
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNotificationType_Constants(t *testing.T) {
@@ -34,6 +35,7 @@ func TestNotificationChannel_Constants(t *testing.T) {
 	}{
 		{"Email", ChannelEmail, "email"},
 		{"Webhook", ChannelWebhook, "webhook"},
+		{"Teams", ChannelTeams, "teams"},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +124,24 @@ func TestSMTPConfig_IsValid(t *testing.T) {
 	}
 }
 
+func TestSMTPConfig_Validate(t *testing.T) {
+	valid := SMTPConfig{Host: "smtp.example.com", Port: 587, From: "AutoStrike <noreply@example.com>"}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	for name, config := range map[string]SMTPConfig{
+		"missing host": {Port: 587, From: "noreply@example.com"},
+		"zero port":    {Host: "smtp.example.com", From: "noreply@example.com"},
+		"large port":   {Host: "smtp.example.com", Port: 70000, From: "noreply@example.com"},
+		"bad from":     {Host: "smtp.example.com", Port: 587, From: "not-an-address"},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
 func TestDefaultEmailTemplates(t *testing.T) {
 	templates := DefaultEmailTemplates()
 
@@ -132,6 +152,9 @@ func TestDefaultEmailTemplates(t *testing.T) {
 		NotificationExecutionFailed,
 		NotificationScoreAlert,
 		NotificationAgentOffline,
+		NotificationSecurityAlert,
+		NotificationDetectionRegression,
+		NotificationCriticalUndetected,
 	}
 
 	if len(templates) != len(expectedTypes) {
@@ -171,7 +194,7 @@ func TestDefaultEmailTemplates_ExecutionStarted(t *testing.T) {
 		"{{.ExecutionID}}",
 		"{{.StartedAt}}",
 		"{{.SafeMode}}",
-		"{{.DashboardURL}}",
+		"{{.Link}}",
 	}
 
 	for _, ph := range expectedPlaceholders {
@@ -198,7 +221,7 @@ func TestDefaultEmailTemplates_ExecutionCompleted(t *testing.T) {
 		"{{.Detected}}",
 		"{{.Successful}}",
 		"{{.Total}}",
-		"{{.DashboardURL}}",
+		"{{.Link}}",
 	}
 
 	for _, ph := range expectedPlaceholders {
@@ -216,7 +239,7 @@ func TestDefaultEmailTemplates_ExecutionFailed(t *testing.T) {
 		"{{.ScenarioName}}",
 		"{{.ExecutionID}}",
 		"{{.Error}}",
-		"{{.DashboardURL}}",
+		"{{.Link}}",
 	}
 
 	for _, ph := range expectedPlaceholders {
@@ -239,7 +262,7 @@ func TestDefaultEmailTemplates_ScoreAlert(t *testing.T) {
 		"{{.ExecutionID}}",
 		"{{.Score}}",
 		"{{.Threshold}}",
-		"{{.DashboardURL}}",
+		"{{.Link}}",
 	}
 
 	for _, ph := range expectedPlaceholders {
@@ -262,7 +285,7 @@ func TestDefaultEmailTemplates_AgentOffline(t *testing.T) {
 		"{{.Paw}}",
 		"{{.Platform}}",
 		"{{.LastSeen}}",
-		"{{.DashboardURL}}",
+		"{{.Link}}",
 	}
 
 	for _, ph := range expectedPlaceholders {
@@ -274,25 +297,23 @@ func TestDefaultEmailTemplates_AgentOffline(t *testing.T) {
 
 func TestNotificationSettings_Struct(t *testing.T) {
 	settings := &NotificationSettings{
-		ID:                   "settings-1",
-		UserID:               "user-1",
-		Channel:              ChannelEmail,
-		Enabled:              true,
-		EmailAddress:         "test@example.com",
-		WebhookURL:           "",
-		NotifyOnStart:        true,
-		NotifyOnComplete:     true,
-		NotifyOnFailure:      true,
-		NotifyOnScoreAlert:   true,
-		ScoreAlertThreshold:  70.0,
-		NotifyOnAgentOffline: true,
+		ID:           "settings-1",
+		UserID:       "user-1",
+		Enabled:      true,
+		EmailAddress: "test@example.com",
+		WebhookURL:   "",
+		Preferences: NotificationPreferences{
+			NotificationExecutionFailed:    {ChannelEmail, ChannelWebhook},
+			NotificationExecutionCompleted: {ChannelWebhook},
+		},
+		ScoreAlertThreshold: 70.0,
 	}
 
 	if settings.ID != "settings-1" {
 		t.Errorf("ID = %q, want %q", settings.ID, "settings-1")
 	}
-	if settings.Channel != ChannelEmail {
-		t.Errorf("Channel = %q, want %q", settings.Channel, ChannelEmail)
+	if !settings.Preferences.Has(NotificationExecutionFailed, ChannelEmail) {
+		t.Error("Failures should be delivered by email")
 	}
 	if !settings.Enabled {
 		t.Error("Enabled should be true")
@@ -302,6 +323,144 @@ func TestNotificationSettings_Struct(t *testing.T) {
 	}
 }
 
+func TestNotificationPreferences(t *testing.T) {
+	prefs := NotificationPreferences{
+		NotificationExecutionFailed:    {ChannelEmail},
+		NotificationExecutionCompleted: {ChannelWebhook},
+		NotificationExecutionStarted:   {},
+	}
+
+	if !prefs.Wants(NotificationExecutionFailed) || prefs.Wants(NotificationExecutionStarted) || prefs.Wants(NotificationAgentOffline) {
+		t.Error("Wants should only be true for types with a channel")
+	}
+	if !prefs.Has(NotificationExecutionCompleted, ChannelWebhook) || prefs.Has(NotificationExecutionCompleted, ChannelEmail) {
+		t.Error("Has should match the configured channels")
+	}
+	if !prefs.UsesChannel(ChannelEmail) || !prefs.UsesChannel(ChannelWebhook) {
+		t.Error("Both channels should be in use")
+	}
+	if (NotificationPreferences{}).UsesChannel(ChannelEmail) {
+		t.Error("Empty preferences should not use any channel")
+	}
+
+	var nilPrefs NotificationPreferences
+	if nilPrefs.Wants(NotificationExecutionFailed) {
+		t.Error("Nil preferences should not want anything")
+	}
+}
+
+func TestIsValidNotificationTypeAndChannel(t *testing.T) {
+	for _, nt := range NotificationTypes() {
+		if !IsValidNotificationType(nt) {
+			t.Errorf("%s should be valid", nt)
+		}
+	}
+	if IsValidNotificationType("execution_paused") {
+		t.Error("Unknown type should be invalid")
+	}
+	for _, channel := range []NotificationChannel{ChannelEmail, ChannelWebhook, ChannelTeams, ChannelPagerDuty, ChannelOpsgenie} {
+		if !IsValidNotificationChannel(channel) {
+			t.Errorf("%s should be a valid channel", channel)
+		}
+	}
+	if IsIncidentChannel(ChannelTeams) || !IsIncidentChannel(ChannelOpsgenie) {
+		t.Error("Only PagerDuty and Opsgenie should be incident channels")
+	}
+	if IsValidNotificationChannel("sms") {
+		t.Error("Unknown channel should be invalid")
+	}
+}
+
+func TestQuietHours_Contains(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 15, hour, minute, 0, 0, paris)
+	}
+
+	overnight := &QuietHours{Start: "22:00", End: "07:00", Timezone: "Europe/Paris"}
+	daytime := &QuietHours{Start: "12:00", End: "14:30"}
+	tests := []struct {
+		name       string
+		quietHours *QuietHours
+		at         time.Time
+		want       bool
+	}{
+		{"overnight before midnight", overnight, at(23, 0), true},
+		{"overnight after midnight", overnight, at(6, 59), true},
+		{"overnight end excluded", overnight, at(7, 0), false},
+		{"overnight daytime", overnight, at(15, 0), false},
+		{"overnight in another timezone", overnight, at(22, 30).UTC(), true},
+		{"daytime UTC", daytime, time.Date(2024, 1, 15, 14, 29, 0, 0, time.UTC), true},
+		{"daytime UTC outside", daytime, time.Date(2024, 1, 15, 14, 30, 0, 0, time.UTC), false},
+		{"nil", nil, at(23, 0), false},
+		{"invalid", &QuietHours{Start: "late", End: "07:00"}, at(23, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quietHours.Contains(tt.at); got != tt.want {
+				t.Errorf("Contains() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHours_Validate(t *testing.T) {
+	valid := []QuietHours{
+		{Start: "22:00", End: "07:00"},
+		{Start: "00:00", End: "23:59", Timezone: "America/New_York"},
+	}
+	for _, q := range valid {
+		if err := q.Validate(); err != nil {
+			t.Errorf("%+v should be valid, got %v", q, err)
+		}
+	}
+	invalid := []QuietHours{
+		{Start: "22:00", End: "22:00"},
+		{Start: "25:00", End: "07:00"},
+		{Start: "22:00", End: "7"},
+		{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"},
+	}
+	for _, q := range invalid {
+		if err := q.Validate(); err == nil {
+			t.Errorf("%+v should be invalid", q)
+		}
+	}
+}
+
+func TestNotificationDigest(t *testing.T) {
+	if DigestOff.Period() != 0 || DigestHourly.Period() != time.Hour || DigestDaily.Period() != 24*time.Hour {
+		t.Error("Unexpected digest periods")
+	}
+	if !IsValidNotificationDigest(DigestDaily) || IsValidNotificationDigest("weekly") {
+		t.Error("Unexpected digest validity")
+	}
+	if !IsCriticalNotification(NotificationSecurityAlert) || !IsCriticalNotification(NotificationCriticalUndetected) ||
+		IsCriticalNotification(NotificationExecutionCompleted) {
+		t.Error("Only security alerts and undetected critical techniques should be critical")
+	}
+}
+
+func TestLegacyNotificationPreferences(t *testing.T) {
+	prefs := LegacyNotificationPreferences(ChannelWebhook, false, true, true, false, true)
+
+	expected := map[NotificationType]bool{
+		NotificationExecutionStarted:   false,
+		NotificationExecutionCompleted: true,
+		NotificationExecutionFailed:    true,
+		NotificationScoreAlert:         false,
+		NotificationAgentOffline:       true,
+		NotificationSecurityAlert:      true,
+	}
+	for nt, want := range expected {
+		if got := prefs.Has(nt, ChannelWebhook); got != want {
+			t.Errorf("%s on webhook = %v, want %v", nt, got, want)
+		}
+		if prefs.Has(nt, ChannelEmail) {
+			t.Errorf("%s should not be delivered by email", nt)
+		}
+	}
+}
+
 func TestNotification_Struct(t *testing.T) {
 	notification := &Notification{
 		ID:      "notif-1",
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// payloadName matches a payload name: a file name without directories
+var payloadName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// Payload is a binary or script delivered to agents for the techniques that
+// need it. Executors reference it by name; the content is stored apart.
+type Payload struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"` // Referenced by executors as payload: <name>
+	Description string    `json:"description,omitempty"`
+	SHA256      string    `json:"sha256"` // Hex checksum of the content
+	Size        int64     `json:"size"`   // Bytes
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Validate checks that the payload name is a plain file name
+func (p *Payload) Validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if !payloadName.MatchString(p.Name) {
+		return errors.New("name must be a file name of letters, digits, dots, dashes and underscores")
+	}
+	return nil
+}
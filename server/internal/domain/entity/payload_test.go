@@ -0,0 +1,28 @@
+package entity
+
+import "testing"
+
+func TestPayload_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{"file name", "mimikatz.exe", false},
+		{"script", "enum_users-v2.ps1", false},
+		{"empty", "", true},
+		{"directory", "tools/nc.exe", true},
+		{"parent directory", "..", true},
+		{"hidden file", ".bashrc", true},
+		{"space", "my tool.exe", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Payload{Name: tt.payload}).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.payload, err, tt.wantErr)
+			}
+		})
+	}
+}
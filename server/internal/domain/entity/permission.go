@@ -28,9 +28,10 @@ const (
 	PermissionScenariosExport Permission = "scenarios:export"
 
 	// Execution permissions
-	PermissionExecutionsView  Permission = "executions:view"
-	PermissionExecutionsStart Permission = "executions:start"
-	PermissionExecutionsStop  Permission = "executions:stop"
+	PermissionExecutionsView   Permission = "executions:view"
+	PermissionExecutionsStart  Permission = "executions:start"
+	PermissionExecutionsStop   Permission = "executions:stop"
+	PermissionExecutionsTriage Permission = "executions:triage"
 
 	// Analytics permissions
 	PermissionAnalyticsView    Permission = "analytics:view"
@@ -71,7 +72,7 @@ var RolePermissions = map[UserRole][]Permission{
 		PermissionAgentsView, PermissionAgentsCreate, PermissionAgentsDelete,
 		PermissionTechniquesView, PermissionTechniquesImport,
 		PermissionScenariosView, PermissionScenariosCreate, PermissionScenariosEdit, PermissionScenariosDelete, PermissionScenariosImport, PermissionScenariosExport,
-		PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop,
+		PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop, PermissionExecutionsTriage,
 		PermissionAnalyticsView, PermissionAnalyticsCompare, PermissionAnalyticsExport,
 		PermissionSettingsView, PermissionSettingsEdit,
 		PermissionSchedulerView, PermissionSchedulerCreate, PermissionSchedulerEdit, PermissionSchedulerDelete,
@@ -82,7 +83,7 @@ var RolePermissions = map[UserRole][]Permission{
 		PermissionAgentsView,
 		PermissionTechniquesView,
 		PermissionScenariosView, PermissionScenariosExport,
-		PermissionExecutionsView,
+		PermissionExecutionsView, PermissionExecutionsTriage,
 		PermissionAnalyticsView, PermissionAnalyticsCompare, PermissionAnalyticsExport,
 		PermissionSettingsView,
 		PermissionSchedulerView,
@@ -92,7 +93,7 @@ var RolePermissions = map[UserRole][]Permission{
 		PermissionAgentsView, PermissionAgentsCreate, PermissionAgentsDelete,
 		PermissionTechniquesView, PermissionTechniquesImport,
 		PermissionScenariosView, PermissionScenariosCreate, PermissionScenariosEdit, PermissionScenariosDelete, PermissionScenariosImport, PermissionScenariosExport,
-		PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop,
+		PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop, PermissionExecutionsTriage,
 		PermissionAnalyticsView,
 		PermissionSettingsView,
 		PermissionSchedulerView, PermissionSchedulerCreate, PermissionSchedulerEdit, PermissionSchedulerDelete,
@@ -102,7 +103,7 @@ var RolePermissions = map[UserRole][]Permission{
 		PermissionAgentsView,
 		PermissionTechniquesView,
 		PermissionScenariosView, PermissionScenariosExport,
-		PermissionExecutionsView,
+		PermissionExecutionsView, PermissionExecutionsTriage,
 		PermissionAnalyticsView, PermissionAnalyticsCompare, PermissionAnalyticsExport,
 		PermissionSettingsView,
 		PermissionSchedulerView,
@@ -143,7 +144,7 @@ func GetPermissionCategories() []PermissionCategory {
 		{
 			Name:        "Executions",
 			Description: "Execution management permissions",
-			Permissions: []Permission{PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop},
+			Permissions: []Permission{PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop, PermissionExecutionsTriage},
 		},
 		{
 			Name:        "Analytics",
@@ -189,6 +190,7 @@ func GetPermissionInfo() []PermissionInfo {
 		{PermissionExecutionsView, "View Executions", "View execution history and results", "Executions"},
 		{PermissionExecutionsStart, "Start Executions", "Start new attack simulations", "Executions"},
 		{PermissionExecutionsStop, "Stop Executions", "Stop running executions", "Executions"},
+		{PermissionExecutionsTriage, "Triage Results", "Annotate, assign and comment results", "Executions"},
 		// Analytics
 		{PermissionAnalyticsView, "View Analytics", "View security analytics", "Analytics"},
 		{PermissionAnalyticsCompare, "Compare Analytics", "Compare scores across periods", "Analytics"},
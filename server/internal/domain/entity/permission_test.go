@@ -194,7 +194,7 @@ func TestPermissionConstants(t *testing.T) {
 		PermissionAgentsView, PermissionAgentsCreate, PermissionAgentsDelete,
 		PermissionTechniquesView, PermissionTechniquesImport,
 		PermissionScenariosView, PermissionScenariosCreate, PermissionScenariosEdit, PermissionScenariosDelete, PermissionScenariosImport, PermissionScenariosExport,
-		PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop,
+		PermissionExecutionsView, PermissionExecutionsStart, PermissionExecutionsStop, PermissionExecutionsTriage,
 		PermissionAnalyticsView, PermissionAnalyticsCompare, PermissionAnalyticsExport,
 		PermissionSettingsView, PermissionSettingsEdit,
 		PermissionSchedulerView, PermissionSchedulerCreate, PermissionSchedulerEdit, PermissionSchedulerDelete,
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// QueuedTask is a task dispatched while its agent was offline. It waits in the
+// agent's queue, and its result stays queued, until the agent checks in or the
+// task expires.
+type QueuedTask struct {
+	ResultID    string          `json:"result_id"`
+	ExecutionID string          `json:"execution_id"`
+	AgentPaw    string          `json:"agent_paw"`
+	Task        json.RawMessage `json:"task"` // Task as it is sent to the agent
+	QueuedAt    time.Time       `json:"queued_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+// IsExpired reports whether the task can no longer be delivered at t
+func (q *QueuedTask) IsExpired(t time.Time) bool {
+	return !t.Before(q.ExpiresAt)
+}
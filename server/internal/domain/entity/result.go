@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"errors"
 	"time"
 )
 
@@ -9,6 +10,7 @@ type ResultStatus string
 
 const (
 	StatusPending  ResultStatus = "pending"  // Waiting to execute
+	StatusQueued   ResultStatus = "queued"   // Waiting for its offline agent to check in
 	StatusRunning  ResultStatus = "running"  // Currently executing
 	StatusSuccess  ResultStatus = "success"  // Executed, not detected
 	StatusBlocked  ResultStatus = "blocked"  // Blocked by defense
@@ -16,23 +18,83 @@ const (
 	StatusFailed   ResultStatus = "failed"   // Technical error
 	StatusSkipped  ResultStatus = "skipped"  // Not executed
 	StatusTimeout  ResultStatus = "timeout"  // Execution timed out
+	// StatusLimitExceeded means the agent killed the command for exceeding its resource limits
+	StatusLimitExceeded ResultStatus = "limit_exceeded"
+	// StatusSkippedInsufficientPrivilege means the technique requires elevation the agent lacks
+	StatusSkippedInsufficientPrivilege ResultStatus = "skipped_insufficient_privilege"
 )
 
+// ErrResultTransition is returned when an update would move a result backwards,
+// e.g. a retried submission flipping a completed result back to running
+var ErrResultTransition = errors.New("out-of-order result update")
+
+// IsTerminal returns true for the final statuses of a result
+func (s ResultStatus) IsTerminal() bool {
+	return s != StatusPending && s != StatusQueued && s != StatusRunning
+}
+
+// IsKnown reports whether s is one of the result statuses
+func (s ResultStatus) IsKnown() bool {
+	switch s {
+	case StatusPending, StatusQueued, StatusRunning, StatusSuccess, StatusBlocked, StatusDetected,
+		StatusFailed, StatusSkipped, StatusSkippedInsufficientPrivilege, StatusTimeout, StatusLimitExceeded:
+		return true
+	}
+	return false
+}
+
+// IsSkipped reports whether s is a status of a technique that did not run,
+// left out of scores
+func (s ResultStatus) IsSkipped() bool {
+	return s == StatusSkipped || s == StatusSkippedInsufficientPrivilege
+}
+
+// Stage orders statuses for monotonic updates: pending or queued (0),
+// running (1), terminal (2)
+func (s ResultStatus) Stage() int {
+	switch s {
+	case StatusPending, StatusQueued:
+		return 0
+	case StatusRunning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// CanTransitionTo reports whether a result may move from s to next. Results only
+// move forward, pending to running to terminal, and a terminal result is final.
+// A pending task queued for an offline agent is pending again once delivered.
+func (s ResultStatus) CanTransitionTo(next ResultStatus) bool {
+	if s.Stage() == 0 && next.Stage() == 0 {
+		return s != next
+	}
+	return !s.IsTerminal() && next.Stage() > s.Stage()
+}
+
 // ExecutionResult represents the result of a single technique execution
 type ExecutionResult struct {
 	ID          string        `json:"id"`
 	ExecutionID string        `json:"execution_id"`
 	TechniqueID string        `json:"technique_id"`
 	AgentPaw    string        `json:"agent_paw"`
+	Attempt     int           `json:"attempt"`            // Occurrence of the technique on the agent, from 1
+	Phase       string        `json:"phase,omitempty"`    // Scenario phase the technique was planned in
+	Executor    string        `json:"executor,omitempty"` // Executor type the technique was planned with
 	Status      ResultStatus  `json:"status"`
-	Output      string        `json:"output,omitempty"` // Base64 encoded
-	Stderr      string        `json:"stderr,omitempty"` // Base64 encoded
+	Output      string        `json:"output,omitempty"`      // Base64 encoded
+	OutputRef   string        `json:"output_ref,omitempty"`  // Blob holding the full output when Output is only a preview
+	OutputSize  int64         `json:"output_size,omitempty"` // Bytes of the full output when Output is only a preview
+	Stderr      string        `json:"stderr,omitempty"`      // Base64 encoded
 	ExitCode    int           `json:"exit_code"`
 	Detected    bool          `json:"detected"`              // Was the technique detected?
 	DetectedBy  string        `json:"detected_by,omitempty"` // "Windows Defender", "CrowdStrike"
 	StartedAt   time.Time     `json:"started_at"`
 	CompletedAt *time.Time    `json:"completed_at,omitempty"`
 	Duration    time.Duration `json:"duration_ms"`
+
+	// The agent sent more than the maximum output size, whose end was dropped
+	OutputTruncated bool `json:"output_truncated,omitempty"`
 }
 
 // Execution represents a scenario execution session
@@ -48,6 +110,13 @@ type Execution struct {
 	CompletedAt *time.Time        `json:"completed_at,omitempty"`
 	StartedBy   string            `json:"started_by"`
 	SafeMode    bool              `json:"safe_mode"`
+	// Scoring profile version the execution is scored with, unset for the built-in formula
+	ScoringProfileID      string `json:"scoring_profile_id,omitempty"`
+	ScoringProfileVersion int    `json:"scoring_profile_version,omitempty"`
+	// Why a failed execution failed, e.g. stuck running
+	FailureReason string `json:"failure_reason,omitempty"`
+	// Counts of the results by status, set on listings
+	ResultSummary *ExecutionResultSummary `json:"result_summary,omitempty"`
 }
 
 // ExecutionStatus represents the status of an execution
@@ -59,14 +128,30 @@ const (
 	ExecutionCompleted ExecutionStatus = "completed"
 	ExecutionFailed    ExecutionStatus = "failed"
 	ExecutionCancelled ExecutionStatus = "cancelled"
+	// ExecutionInterrupted is an execution stopped by a server shutdown. Its
+	// unanswered results are kept so it can be resumed on restart.
+	ExecutionInterrupted ExecutionStatus = "interrupted"
 )
 
-// ExecutionProgress tracks execution progress
+// ExecutionProgress tracks execution progress in steps, a technique run on an
+// agent. Completed counts the finished steps, the failed and skipped ones
+// included. The ETA is an estimate from the median durations of past runs.
 type ExecutionProgress struct {
-	Total     int `json:"total"`
-	Completed int `json:"completed"`
-	Failed    int `json:"failed"`
-	Skipped   int `json:"skipped"`
+	Total            int        `json:"total"`
+	Completed        int        `json:"completed"`
+	Failed           int        `json:"failed"`
+	Skipped          int        `json:"skipped"`
+	Percent          float64    `json:"percent"`                     // Completed out of Total, 0 to 100
+	CurrentPhase     string     `json:"current_phase,omitempty"`     // Earliest phase with unfinished steps
+	RemainingSeconds *float64   `json:"remaining_seconds,omitempty"` // Unset when no duration is known
+	ETA              *time.Time `json:"eta,omitempty"`
+}
+
+// ExecutionResultSummary counts the results of an execution, retries
+// included, by status
+type ExecutionResultSummary struct {
+	Total    int                  `json:"total"`
+	ByStatus map[ResultStatus]int `json:"by_status"`
 }
 
 // SecurityScore represents the calculated security score
@@ -79,9 +164,84 @@ type SecurityScore struct {
 	Total      int                `json:"total"`      // Total techniques tested
 }
 
+// ScoreRecomputation records a score overwritten by a recomputation backfill
+// or a rescore. The earliest recomputation of an execution holds its original
+// score; each one is a new version of the score.
+type ScoreRecomputation struct {
+	ID            string        `json:"id"`
+	ExecutionID   string        `json:"execution_id"`
+	JobID         string        `json:"job_id"` // Backfill job or rescore request
+	PreviousScore SecurityScore `json:"previous_score"`
+	NewScore      SecurityScore `json:"new_score"`
+	// Scoring profile versions of the previous and new score, unset for the built-in formula
+	PreviousScoringProfileID      string    `json:"previous_scoring_profile_id,omitempty"`
+	PreviousScoringProfileVersion int       `json:"previous_scoring_profile_version,omitempty"`
+	ScoringProfileID              string    `json:"scoring_profile_id,omitempty"`
+	ScoringProfileVersion         int       `json:"scoring_profile_version,omitempty"`
+	RecomputedBy                  string    `json:"recomputed_by,omitempty"` // User who asked for a rescore, unset for backfills
+	RecomputedAt                  time.Time `json:"recomputed_at"`
+}
+
+// DetectionRegression is a technique the defenses of an agent handled worse
+// than on the previous run of the scenario, e.g. blocked before and now successful
+type DetectionRegression struct {
+	TechniqueID         string       `json:"technique_id"`
+	TechniqueName       string       `json:"technique_name,omitempty"`
+	AgentPaw            string       `json:"agent_paw"`
+	PreviousStatus      ResultStatus `json:"previous_status"`
+	Status              ResultStatus `json:"status"`
+	PreviousExecutionID string       `json:"previous_execution_id"`
+	PreviousResultID    string       `json:"previous_result_id"`
+	ResultID            string       `json:"result_id"`
+}
+
+// ExecutionDiff compares two runs of the same scenario, e.g. before and after a
+// change of security controls
+type ExecutionDiff struct {
+	ScenarioID          string                  `json:"scenario_id"`
+	BaselineExecutionID string                  `json:"baseline_execution_id"`
+	ExecutionID         string                  `json:"execution_id"`
+	BaselineScore       float64                 `json:"baseline_score"`
+	Score               float64                 `json:"score"`
+	ScoreDelta          float64                 `json:"score_delta"`
+	Tactics             []TacticScoreDelta      `json:"tactics"`    // Every tactic tested by either run
+	Techniques          []TechniqueStatusChange `json:"techniques"` // Techniques whose status changed on an agent of both runs
+	AddedAgents         []string                `json:"added_agents"`
+	RemovedAgents       []string                `json:"removed_agents"`
+}
+
+// TacticScoreDelta is the score change of a MITRE tactic between two runs. A
+// score is nil when its run did not test the tactic, and so is the delta.
+type TacticScoreDelta struct {
+	Tactic        string   `json:"tactic"`
+	BaselineScore *float64 `json:"baseline_score"`
+	Score         *float64 `json:"score"`
+	Delta         *float64 `json:"delta"`
+}
+
+// Status changes of a technique between two runs
+const (
+	ChangeImproved  = "improved"  // The defenses reacted more, e.g. detected then blocked
+	ChangeRegressed = "regressed" // The defenses reacted less, e.g. blocked then successful
+	ChangeChanged   = "changed"   // Another change, e.g. failed then blocked
+	ChangeAdded     = "added"     // Only run by the compared execution
+	ChangeRemoved   = "removed"   // Only run by the baseline
+)
+
+// TechniqueStatusChange is a technique whose latest result on an agent differs
+// between two runs. A status is empty when its run did not include the technique.
+type TechniqueStatusChange struct {
+	TechniqueID    string       `json:"technique_id"`
+	TechniqueName  string       `json:"technique_name,omitempty"`
+	AgentPaw       string       `json:"agent_paw"`
+	BaselineStatus ResultStatus `json:"baseline_status,omitempty"`
+	Status         ResultStatus `json:"status,omitempty"`
+	Change         string       `json:"change"`
+}
+
 // IsComplete returns true if the result has completed (success, failed, blocked, etc.)
 func (r *ExecutionResult) IsComplete() bool {
-	return r.Status != StatusPending && r.Status != StatusRunning
+	return r.Status.IsTerminal()
 }
 
 // IsSuccessful returns true if the technique executed without detection
@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// ResultTriage is the verdict an analyst gives a result after reviewing it
+type ResultTriage string
+
+const (
+	TriageNone          ResultTriage = ""               // Not triaged yet
+	TriageTruePositive  ResultTriage = "true_positive"  // The result reflects a real gap or a real detection
+	TriageExpectedBlock ResultTriage = "expected_block" // The technique was blocked as intended
+	TriageNeedsTuning   ResultTriage = "needs_tuning"   // A detection rule or the technique needs tuning
+)
+
+// IsValidResultTriage checks if a triage verdict is known. The empty verdict
+// clears the triage of a result.
+func IsValidResultTriage(t ResultTriage) bool {
+	switch t {
+	case TriageNone, TriageTruePositive, TriageExpectedBlock, TriageNeedsTuning:
+		return true
+	}
+	return false
+}
+
+// MaxResultCommentLength is the longest comment accepted, in bytes
+const MaxResultCommentLength = 4000
+
+// ResultAnnotation is the manual triage of an execution result: the analyst
+// verdict, the teammate following it up and the discussion around it
+type ResultAnnotation struct {
+	ResultID    string          `json:"result_id"`
+	ExecutionID string          `json:"execution_id"`
+	Triage      ResultTriage    `json:"triage,omitempty"`
+	AssigneeID  string          `json:"assignee_id,omitempty"`
+	UpdatedBy   string          `json:"updated_by,omitempty"`
+	UpdatedAt   *time.Time      `json:"updated_at,omitempty"` // Nil until the result is triaged or assigned
+	Comments    []ResultComment `json:"comments"`             // Oldest first
+}
+
+// ResultComment is a free-text comment left by an analyst on a result
+type ResultComment struct {
+	ID          string    `json:"id"`
+	ResultID    string    `json:"result_id"`
+	ExecutionID string    `json:"execution_id"`
+	AuthorID    string    `json:"author_id"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+}
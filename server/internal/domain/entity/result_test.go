@@ -10,6 +10,7 @@ func TestExecutionResult_IsComplete(t *testing.T) {
 		want   bool
 	}{
 		{StatusPending, false},
+		{StatusQueued, false},
 		{StatusRunning, false},
 		{StatusSuccess, true},
 		{StatusBlocked, true},
@@ -52,6 +53,56 @@ func TestExecutionResult_IsSuccessful(t *testing.T) {
 	}
 }
 
+func TestResultStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ResultStatus
+		want     bool
+	}{
+		{StatusPending, StatusRunning, true},
+		{StatusPending, StatusSuccess, true},
+		{StatusRunning, StatusFailed, true},
+		{StatusRunning, StatusRunning, false},
+		{StatusRunning, StatusPending, false},
+		{StatusPending, StatusQueued, true},
+		{StatusQueued, StatusPending, true},
+		{StatusQueued, StatusQueued, false},
+		{StatusQueued, StatusSuccess, true},
+		{StatusRunning, StatusQueued, false},
+		{StatusFailed, StatusQueued, false},
+		{StatusSuccess, StatusRunning, false},
+		{StatusSuccess, StatusSuccess, false},
+		{StatusSuccess, StatusFailed, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestResultStatus_IsKnown(t *testing.T) {
+	for _, s := range []ResultStatus{StatusPending, StatusSuccess, StatusDetected, StatusLimitExceeded} {
+		if !s.IsKnown() {
+			t.Errorf("%s should be known", s)
+		}
+	}
+	if ResultStatus("succeeded").IsKnown() {
+		t.Error("succeeded should not be known")
+	}
+}
+
+func TestResultStatus_IsSkipped(t *testing.T) {
+	for _, s := range []ResultStatus{StatusSkipped, StatusSkippedInsufficientPrivilege} {
+		if !s.IsSkipped() || !s.IsKnown() || !s.IsTerminal() {
+			t.Errorf("%s should be a known, final skipped status", s)
+		}
+	}
+	if StatusFailed.IsSkipped() {
+		t.Error("failed should not be skipped")
+	}
+}
+
 func TestResultStatus_Constants(t *testing.T) {
 	// Verify all status constants
 	statuses := map[ResultStatus]string{
@@ -108,3 +159,32 @@ func TestSecurityScore_Initialization(t *testing.T) {
 		t.Errorf("ByTactic length = %d, want 2", len(score.ByTactic))
 	}
 }
+
+func TestIsValidResultTriage(t *testing.T) {
+	for _, triage := range []ResultTriage{TriageNone, TriageTruePositive, TriageExpectedBlock, TriageNeedsTuning} {
+		if !IsValidResultTriage(triage) {
+			t.Errorf("Expected %q to be valid", triage)
+		}
+	}
+	if IsValidResultTriage("false_positive") {
+		t.Error("Expected an unknown verdict to be invalid")
+	}
+}
+
+func TestReviewStatus_CanTransitionTo(t *testing.T) {
+	allowed := map[ReviewStatus]ReviewStatus{ReviewNeeded: ReviewInReview, ReviewInReview: ReviewSignedOff}
+	for _, from := range []ReviewStatus{ReviewNeeded, ReviewInReview, ReviewSignedOff} {
+		if !IsValidReviewStatus(from) {
+			t.Errorf("Expected %q to be valid", from)
+		}
+		for _, to := range []ReviewStatus{ReviewNeeded, ReviewInReview, ReviewSignedOff} {
+			if got := from.CanTransitionTo(to); got != (allowed[from] == to) {
+				t.Errorf("%s -> %s = %v", from, to, got)
+			}
+		}
+	}
+	var review *ExecutionReview
+	if review.Locked() || (&ExecutionReview{Status: ReviewInReview}).Locked() || !(&ExecutionReview{Status: ReviewSignedOff}).Locked() {
+		t.Error("Expected only a signed-off review to be locked")
+	}
+}
@@ -6,22 +6,71 @@ import (
 
 // Scenario represents an attack scenario with multiple phases
 type Scenario struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Phases      []Phase   `json:"phases"`
-	Tags        []string  `json:"tags,omitempty"`
-	Author      string    `json:"author,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Phases      []Phase    `json:"phases"`
+	Tags        []string   `json:"tags,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	IsTemplate  bool       `json:"is_template" yaml:"is_template,omitempty"`           // Built-in template, read-only
+	ClonedFrom  string     `json:"cloned_from,omitempty" yaml:"cloned_from,omitempty"` // Scenario this one was cloned from
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" yaml:"-"` // Set while the scenario is in the trash
 }
 
 // Phase represents a phase in a scenario
 type Phase struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description,omitempty"`
-	Techniques  []string `json:"techniques"` // Technique IDs
-	Order       int      `json:"order"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Techniques  []string         `json:"techniques"` // Technique IDs
+	Order       int              `json:"order"`
+	RunIf       []PhaseCondition `json:"run_if,omitempty" yaml:"run_if,omitempty"` // All must hold for the phase to run
+	// Executor types tried first, in order, e.g. psh then cmd. Techniques with
+	// none of them the agent supports run with their first compatible executor.
+	Executors []string `json:"executors,omitempty" yaml:"executors,omitempty"`
+}
+
+// PhaseCondition makes a phase depend on the results of earlier phases. It
+// holds on an agent when one of the agent's results for the technique, within
+// the phase if set, has the status (success when empty).
+type PhaseCondition struct {
+	Technique string       `json:"technique"`
+	Phase     string       `json:"phase,omitempty"`
+	Status    ResultStatus `json:"status,omitempty"`
+}
+
+// IsConditional reports whether the phase runs only when its conditions hold
+func (p *Phase) IsConditional() bool {
+	return len(p.RunIf) > 0
+}
+
+// ExpectedStatus returns the result status the condition requires
+func (c PhaseCondition) ExpectedStatus() ResultStatus {
+	if c.Status == "" {
+		return StatusSuccess
+	}
+	return c.Status
+}
+
+// Clone returns an editable copy of the scenario, not sharing any slice with
+// it. The copy has no ID and records the scenario as its source.
+func (s *Scenario) Clone() *Scenario {
+	clone := &Scenario{
+		Name:        s.Name,
+		Description: s.Description,
+		Tags:        append([]string(nil), s.Tags...),
+		Author:      s.Author,
+		ClonedFrom:  s.ID,
+		Phases:      make([]Phase, len(s.Phases)),
+	}
+	for i, phase := range s.Phases {
+		phase.Techniques = append([]string(nil), phase.Techniques...)
+		phase.RunIf = append([]PhaseCondition(nil), phase.RunIf...)
+		phase.Executors = append([]string(nil), phase.Executors...)
+		clone.Phases[i] = phase
+	}
+	return clone
 }
 
 // GetAllTechniques returns all unique technique IDs from all phases
@@ -145,3 +145,52 @@ func TestPhase_Struct(t *testing.T) {
 		t.Errorf("Techniques length = %d, want 2", len(phase.Techniques))
 	}
 }
+
+func TestPhase_IsConditional(t *testing.T) {
+	phase := Phase{Name: "Lateral Movement", Techniques: []string{"T1021"}}
+	if phase.IsConditional() {
+		t.Error("Phase without run_if should not be conditional")
+	}
+
+	phase.RunIf = []PhaseCondition{{Technique: "T1003"}}
+	if !phase.IsConditional() {
+		t.Error("Phase with run_if should be conditional")
+	}
+}
+
+func TestPhaseCondition_ExpectedStatus(t *testing.T) {
+	if got := (PhaseCondition{Technique: "T1003"}).ExpectedStatus(); got != StatusSuccess {
+		t.Errorf("ExpectedStatus() = %s, want success", got)
+	}
+	if got := (PhaseCondition{Technique: "T1003", Status: StatusBlocked}).ExpectedStatus(); got != StatusBlocked {
+		t.Errorf("ExpectedStatus() = %s, want blocked", got)
+	}
+}
+
+func TestScenario_Clone(t *testing.T) {
+	source := &Scenario{
+		ID:         "template-apt29",
+		Name:       "APT29",
+		Tags:       []string{"apt"},
+		IsTemplate: true,
+		Phases: []Phase{{
+			Name:       "Lateral Movement",
+			Techniques: []string{"T1021"},
+			RunIf:      []PhaseCondition{{Technique: "T1003"}},
+		}},
+	}
+
+	clone := source.Clone()
+	if clone.ID != "" || clone.IsTemplate || clone.ClonedFrom != "template-apt29" || clone.Name != "APT29" {
+		t.Errorf("Unexpected clone: %+v", clone)
+	}
+
+	clone.Tags[0] = "edited"
+	clone.Phases[0].Name = "Edited"
+	clone.Phases[0].Techniques[0] = "T1082"
+	clone.Phases[0].RunIf[0].Technique = "T1082"
+	if source.Tags[0] != "apt" || source.Phases[0].Name != "Lateral Movement" ||
+		source.Phases[0].Techniques[0] != "T1021" || source.Phases[0].RunIf[0].Technique != "T1003" {
+		t.Errorf("Editing the clone changed the source: %+v", source)
+	}
+}
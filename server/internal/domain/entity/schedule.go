@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -25,27 +27,125 @@ const (
 	FrequencyWeekly  ScheduleFrequency = "weekly"
 	FrequencyMonthly ScheduleFrequency = "monthly"
 	FrequencyCron    ScheduleFrequency = "cron"
+	FrequencyChained ScheduleFrequency = "chained" // Runs when the schedule it depends on completes
 )
 
+// ChainCondition decides which completed executions of its upstream schedule
+// trigger a chained schedule
+type ChainCondition string
+
+const (
+	ChainOnSuccess      ChainCondition = "success"        // Every completed execution
+	ChainOnScoreBelow   ChainCondition = "score_below"    // Completed with an overall score below the threshold, e.g. undetected techniques
+	ChainOnScoreAtLeast ChainCondition = "score_at_least" // Completed with an overall score of at least the threshold
+)
+
+// IsValid reports whether the condition is known; empty means success
+func (c ChainCondition) IsValid() bool {
+	switch c {
+	case "", ChainOnSuccess, ChainOnScoreBelow, ChainOnScoreAtLeast:
+		return true
+	}
+	return false
+}
+
+// MisfirePolicy decides what happens to the runs a schedule missed while the
+// server was down
+type MisfirePolicy string
+
+const (
+	MisfireSkip               MisfirePolicy = "skip"                 // Log the missed runs and wait for the next one
+	MisfireRunOnceImmediately MisfirePolicy = "run_once_immediately" // Run once at startup for all the missed runs
+	MisfireRunAllMissed       MisfirePolicy = "run_all_missed"       // Run every missed run at startup
+)
+
+// IsValid reports whether the policy is known; empty means the default
+func (p MisfirePolicy) IsValid() bool {
+	switch p {
+	case "", MisfireSkip, MisfireRunOnceImmediately, MisfireRunAllMissed:
+		return true
+	}
+	return false
+}
+
+// DefaultAlertSeverity is the technique severity paging when undetected, for
+// schedules that set no severities
+const DefaultAlertSeverity = "critical"
+
+// ScheduleAlerting tunes, for the executions of a schedule, when incidents are
+// triggered on the PagerDuty and Opsgenie channels of users
+type ScheduleAlerting struct {
+	Disabled       bool     `json:"disabled,omitempty"`        // Never page for the executions of the schedule
+	ScoreThreshold float64  `json:"score_threshold,omitempty"` // Page below this overall score, 0 = each user's alert threshold
+	Severities     []string `json:"severities,omitempty"`      // Technique severities paging when undetected, empty = critical
+}
+
+// Validate checks the score threshold and the severities
+func (a *ScheduleAlerting) Validate() error {
+	if a.ScoreThreshold < 0 || a.ScoreThreshold > 100 {
+		return errors.New("alerting score threshold must be between 0 and 100")
+	}
+	for _, severity := range a.Severities {
+		if strings.TrimSpace(severity) == "" {
+			return errors.New("alerting severities must not be empty")
+		}
+	}
+	return nil
+}
+
+// PagesOnSeverity reports whether an undetected technique of a severity
+// triggers an incident. A nil alerting only pages on critical techniques.
+func (a *ScheduleAlerting) PagesOnSeverity(severity string) bool {
+	if severity == "" {
+		return false
+	}
+	if a == nil || len(a.Severities) == 0 {
+		return severity == DefaultAlertSeverity
+	}
+	for _, s := range a.Severities {
+		if strings.EqualFold(strings.TrimSpace(s), severity) {
+			return true
+		}
+	}
+	return false
+}
+
 // Schedule represents a scheduled execution
 type Schedule struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Description string            `json:"description,omitempty"`
-	ScenarioID  string            `json:"scenario_id"`
-	AgentPaw    string            `json:"agent_paw,omitempty"` // Empty = any available agent
-	Frequency   ScheduleFrequency `json:"frequency"`
-	CronExpr    string            `json:"cron_expr,omitempty"` // Only for cron frequency
-	SafeMode    bool              `json:"safe_mode"`
-	Status      ScheduleStatus    `json:"status"`
-	NextRunAt   *time.Time        `json:"next_run_at,omitempty"`
-	LastRunAt   *time.Time        `json:"last_run_at,omitempty"`
-	LastRunID   string            `json:"last_run_id,omitempty"` // Last execution ID
-	CreatedBy   string            `json:"created_by"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Description     string            `json:"description,omitempty"`
+	ScenarioID      string            `json:"scenario_id"`
+	AgentPaw        string            `json:"agent_paw,omitempty"`         // Empty = any available agent
+	AgentSelectorID string            `json:"agent_selector_id,omitempty"` // Saved selector resolved at each run, takes precedence over AgentPaw
+	Frequency       ScheduleFrequency `json:"frequency"`
+	CronExpr        string            `json:"cron_expr,omitempty"`       // Only for cron frequency
+	Timezone        string            `json:"timezone,omitempty"`        // IANA name, empty = server local time
+	MisfirePolicy   MisfirePolicy     `json:"misfire_policy,omitempty"`  // Runs missed while the server was down, empty = run_once_immediately
+	DependsOn       string            `json:"depends_on,omitempty"`      // Chained schedules: the upstream schedule whose executions trigger this one
+	ChainCondition  ChainCondition    `json:"chain_condition,omitempty"` // Chained schedules: empty = success
+	ChainThreshold  float64           `json:"chain_threshold,omitempty"` // Overall score compared by the score conditions
+	Alerting        *ScheduleAlerting `json:"alerting,omitempty"`        // Incident paging of the schedule's executions, nil = users' settings
+	SafeMode        bool              `json:"safe_mode"`
+	Status          ScheduleStatus    `json:"status"`
+	NextRunAt       *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt       *time.Time        `json:"last_run_at,omitempty"`
+	LastRunID       string            `json:"last_run_id,omitempty"` // Last execution ID
+	CreatedBy       string            `json:"created_by"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	NextRuns        []time.Time       `json:"next_runs,omitempty"` // Upcoming run times, computed on read
 }
 
+// UpcomingRunCount is the number of upcoming run times returned with a schedule
+const UpcomingRunCount = 5
+
+// cronParser accepts five-field expressions, with an optional leading
+// seconds field, and descriptors such as @daily
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
 // ScheduleRun represents a single run of a schedule
 type ScheduleRun struct {
 	ID          string    `json:"id"`
@@ -53,19 +153,19 @@ type ScheduleRun struct {
 	ExecutionID string    `json:"execution_id"`
 	StartedAt   time.Time `json:"started_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
-	Status      string    `json:"status"` // pending, running, completed, failed
-	Error       string    `json:"error,omitempty"`
+	Status      string     `json:"status"` // pending, running, completed, failed, missed
+	Error       string     `json:"error,omitempty"`
 }
 
-// CalculateNextRun calculates the next run time based on frequency
+// CalculateNextRun calculates the next run time based on frequency.
+// Daily, weekly, monthly and cron schedules follow the wall clock of the
+// schedule's timezone, across DST changes.
 func (s *Schedule) CalculateNextRun(from time.Time) *time.Time {
 	if s.Status != ScheduleStatusActive {
 		return nil
 	}
 
-	var next time.Time
-	switch s.Frequency {
-	case FrequencyOnce:
+	if s.Frequency == FrequencyOnce {
 		// One-time schedules don't have a next run after execution
 		if s.LastRunAt != nil {
 			return nil
@@ -75,32 +175,115 @@ func (s *Schedule) CalculateNextRun(from time.Time) *time.Time {
 		}
 		// If no start_at was provided, run immediately
 		return &from
+	}
+	return s.nextAfter(from)
+}
+
+// nextAfter returns the run of a recurring schedule following from
+func (s *Schedule) nextAfter(from time.Time) *time.Time {
+	local := from.In(s.Location())
+
+	var next time.Time
+	switch s.Frequency {
 	case FrequencyHourly:
 		next = from.Add(time.Hour)
 	case FrequencyDaily:
-		next = from.AddDate(0, 0, 1)
+		next = local.AddDate(0, 0, 1)
 	case FrequencyWeekly:
-		next = from.AddDate(0, 0, 7)
+		next = local.AddDate(0, 0, 7)
 	case FrequencyMonthly:
-		next = from.AddDate(0, 1, 0)
+		next = local.AddDate(0, 1, 0)
 	case FrequencyCron:
 		// Parse cron expression and calculate next run
 		if s.CronExpr == "" {
 			return nil
 		}
-		parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		schedule, err := parser.Parse(s.CronExpr)
+		schedule, err := cronParser.Parse(s.CronExpr)
 		if err != nil {
 			return nil
 		}
-		next = schedule.Next(from)
-		return &next
+		next = schedule.Next(local)
+		if next.IsZero() {
+			return nil
+		}
 	default:
 		return nil
 	}
 	return &next
 }
 
+// UpcomingRuns returns up to n upcoming run times, starting with the next
+// run. Paused and disabled schedules have none.
+func (s *Schedule) UpcomingRuns(n int) []time.Time {
+	runs := []time.Time{}
+	if s.Status != ScheduleStatusActive || s.NextRunAt == nil {
+		return runs
+	}
+
+	for next := s.NextRunAt; next != nil && len(runs) < n; next = s.nextAfter(*next) {
+		runs = append(runs, *next)
+		if s.Frequency == FrequencyOnce {
+			break
+		}
+	}
+	return runs
+}
+
+// MissedRuns returns up to limit run times of an active schedule that fell
+// before now, oldest first, starting with the next run
+func (s *Schedule) MissedRuns(now time.Time, limit int) []time.Time {
+	runs := []time.Time{}
+	if s.Status != ScheduleStatusActive || s.NextRunAt == nil {
+		return runs
+	}
+
+	for next := s.NextRunAt; next != nil && next.Before(now) && len(runs) < limit; next = s.nextAfter(*next) {
+		runs = append(runs, *next)
+		if s.Frequency == FrequencyOnce {
+			break
+		}
+	}
+	return runs
+}
+
+// Misfire returns the misfire policy of the schedule, run_once_immediately
+// when unset as the scheduler always ran overdue schedules once
+func (s *Schedule) Misfire() MisfirePolicy {
+	if s.MisfirePolicy == "" {
+		return MisfireRunOnceImmediately
+	}
+	return s.MisfirePolicy
+}
+
+// Location returns the timezone the schedule is evaluated in
+func (s *Schedule) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// TriggeredBy reports whether a completed execution of the upstream schedule
+// triggers this chained schedule
+func (s *Schedule) TriggeredBy(execution *Execution) bool {
+	if s.Status != ScheduleStatusActive || execution == nil || execution.Status != ExecutionCompleted {
+		return false
+	}
+	switch s.ChainCondition {
+	case "", ChainOnSuccess:
+		return true
+	case ChainOnScoreBelow:
+		return execution.Score != nil && execution.Score.Overall < s.ChainThreshold
+	case ChainOnScoreAtLeast:
+		return execution.Score != nil && execution.Score.Overall >= s.ChainThreshold
+	}
+	return false
+}
+
 // IsReadyToRun checks if the schedule should run now
 func (s *Schedule) IsReadyToRun(now time.Time) bool {
 	if s.Status != ScheduleStatusActive {
@@ -117,7 +300,19 @@ func ValidateCronExpr(cronExpr string) error {
 	if cronExpr == "" {
 		return nil
 	}
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	_, err := parser.Parse(cronExpr)
+	// The timezone is a field of the schedule, not part of the expression
+	if strings.HasPrefix(cronExpr, "TZ=") || strings.HasPrefix(cronExpr, "CRON_TZ=") {
+		return errors.New("set the schedule timezone instead of a TZ prefix")
+	}
+	_, err := cronParser.Parse(cronExpr)
+	return err
+}
+
+// ValidateTimezone validates an IANA timezone name, such as Europe/Paris
+func ValidateTimezone(timezone string) error {
+	if timezone == "" {
+		return nil
+	}
+	_, err := time.LoadLocation(timezone)
 	return err
 }
@@ -545,3 +545,164 @@ func TestCalculateNextRun_OnceNoStartAt(t *testing.T) {
 		t.Error("Should return the 'from' time")
 	}
 }
+
+func TestCalculateNextRun_Timezone(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+
+	// 09:00 in Paris is 07:00 UTC in summer and 08:00 UTC in winter
+	s := &Schedule{Frequency: FrequencyCron, CronExpr: "0 9 * * *", Timezone: "Europe/Paris", Status: ScheduleStatusActive}
+	next := s.CalculateNextRun(time.Date(2024, 10, 26, 12, 0, 0, 0, time.UTC))
+	if next == nil || !next.Equal(time.Date(2024, 10, 27, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 08:00 UTC the day DST ends, got %v", next)
+	}
+
+	// Daily schedules keep their wall clock time across the change
+	s = &Schedule{Frequency: FrequencyDaily, Timezone: "Europe/Paris", Status: ScheduleStatusActive}
+	next = s.CalculateNextRun(time.Date(2024, 10, 26, 9, 0, 0, 0, paris))
+	if next == nil || next.In(paris).Hour() != 9 || next.Sub(time.Date(2024, 10, 26, 9, 0, 0, 0, paris)) != 25*time.Hour {
+		t.Errorf("Expected 09:00 Paris time 25 hours later, got %v", next)
+	}
+}
+
+func TestCalculateNextRun_CronSeconds(t *testing.T) {
+	s := &Schedule{Frequency: FrequencyCron, CronExpr: "30 */15 * * * *", Timezone: "UTC", Status: ScheduleStatusActive}
+	next := s.CalculateNextRun(time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC))
+	if next == nil || !next.Equal(time.Date(2024, 6, 15, 12, 0, 30, 0, time.UTC)) {
+		t.Errorf("Expected 12:00:30, got %v", next)
+	}
+}
+
+func TestSchedule_UpcomingRuns(t *testing.T) {
+	first := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	s := &Schedule{Frequency: FrequencyCron, CronExpr: "@daily", Timezone: "UTC", Status: ScheduleStatusActive, NextRunAt: &first}
+
+	runs := s.UpcomingRuns(UpcomingRunCount)
+	if len(runs) != 5 || !runs[0].Equal(first) || !runs[4].Equal(first.AddDate(0, 0, 4)) {
+		t.Errorf("Expected five daily runs from %v, got %v", first, runs)
+	}
+
+	once := &Schedule{Frequency: FrequencyOnce, Status: ScheduleStatusActive, NextRunAt: &first}
+	if runs := once.UpcomingRuns(5); len(runs) != 1 {
+		t.Errorf("Expected a single run for a one-time schedule, got %v", runs)
+	}
+	s.Status = ScheduleStatusPaused
+	if runs := s.UpcomingRuns(5); len(runs) != 0 {
+		t.Errorf("Expected no runs for a paused schedule, got %v", runs)
+	}
+}
+
+func TestValidateCronExpr_Extended(t *testing.T) {
+	for _, expr := range []string{"0 */5 * * * *", "@hourly", "@every 90m"} {
+		if err := ValidateCronExpr(expr); err != nil {
+			t.Errorf("ValidateCronExpr(%q) = %v, want nil", expr, err)
+		}
+	}
+	if err := ValidateCronExpr("CRON_TZ=Europe/Paris 0 9 * * *"); err == nil {
+		t.Error("Expected the timezone prefix to be rejected")
+	}
+}
+
+func TestValidateTimezone(t *testing.T) {
+	for _, tz := range []string{"", "UTC", "America/New_York"} {
+		if err := ValidateTimezone(tz); err != nil {
+			t.Errorf("ValidateTimezone(%q) = %v, want nil", tz, err)
+		}
+	}
+	if err := ValidateTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Error("Expected an unknown timezone to be rejected")
+	}
+}
+
+func TestSchedule_MissedRuns(t *testing.T) {
+	next := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	s := &Schedule{Frequency: FrequencyHourly, Status: ScheduleStatusActive, NextRunAt: &next}
+
+	now := next.Add(3*time.Hour + 30*time.Minute)
+	if runs := s.MissedRuns(now, 10); len(runs) != 4 || !runs[0].Equal(next) || !runs[3].Equal(next.Add(3*time.Hour)) {
+		t.Errorf("Expected the 4 runs before now, got %v", runs)
+	}
+	if runs := s.MissedRuns(now, 2); len(runs) != 2 {
+		t.Errorf("Expected the runs to be capped, got %d", len(runs))
+	}
+	if runs := s.MissedRuns(next, 10); len(runs) != 0 {
+		t.Errorf("Expected no missed run when the next run is due now, got %v", runs)
+	}
+}
+
+func TestMisfirePolicy(t *testing.T) {
+	for _, policy := range []MisfirePolicy{"", MisfireSkip, MisfireRunOnceImmediately, MisfireRunAllMissed} {
+		if !policy.IsValid() {
+			t.Errorf("Expected %q to be valid", policy)
+		}
+	}
+	if MisfirePolicy("later").IsValid() {
+		t.Error("Expected an unknown policy to be invalid")
+	}
+	if (&Schedule{}).Misfire() != MisfireRunOnceImmediately {
+		t.Error("Expected schedules to run once after a misfire by default")
+	}
+}
+
+func TestSchedule_TriggeredBy(t *testing.T) {
+	completed := func(score float64) *Execution {
+		return &Execution{Status: ExecutionCompleted, Score: &SecurityScore{Overall: score}}
+	}
+	s := &Schedule{Frequency: FrequencyChained, Status: ScheduleStatusActive}
+
+	if !s.TriggeredBy(completed(90)) {
+		t.Error("Expected a completed execution to trigger by default")
+	}
+	if s.TriggeredBy(&Execution{Status: ExecutionFailed}) {
+		t.Error("Expected a failed execution not to trigger")
+	}
+
+	s.ChainCondition, s.ChainThreshold = ChainOnScoreBelow, 50
+	if !s.TriggeredBy(completed(30)) || s.TriggeredBy(completed(50)) || s.TriggeredBy(&Execution{Status: ExecutionCompleted}) {
+		t.Error("Expected score_below to trigger only below the threshold")
+	}
+
+	s.ChainCondition = ChainOnScoreAtLeast
+	if !s.TriggeredBy(completed(50)) || s.TriggeredBy(completed(30)) {
+		t.Error("Expected score_at_least to trigger from the threshold")
+	}
+
+	s.Status = ScheduleStatusPaused
+	if s.TriggeredBy(completed(80)) {
+		t.Error("Expected a paused schedule not to trigger")
+	}
+}
+
+func TestChainCondition_IsValid(t *testing.T) {
+	for _, c := range []ChainCondition{"", ChainOnSuccess, ChainOnScoreBelow, ChainOnScoreAtLeast} {
+		if !c.IsValid() {
+			t.Errorf("Expected %q to be valid", c)
+		}
+	}
+	if ChainCondition("detected").IsValid() {
+		t.Error("Expected an unknown condition to be invalid")
+	}
+}
+
+func TestScheduleAlerting(t *testing.T) {
+	var unset *ScheduleAlerting
+	if !unset.PagesOnSeverity("critical") || unset.PagesOnSeverity("high") || unset.PagesOnSeverity("") {
+		t.Error("Expected only critical techniques to page without alerting settings")
+	}
+
+	alerting := &ScheduleAlerting{ScoreThreshold: 60, Severities: []string{"High", "critical"}}
+	if err := alerting.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !alerting.PagesOnSeverity("high") || alerting.PagesOnSeverity("medium") {
+		t.Error("Expected the configured severities to page")
+	}
+
+	for _, invalid := range []*ScheduleAlerting{{ScoreThreshold: 120}, {ScoreThreshold: -1}, {Severities: []string{" "}}} {
+		if invalid.Validate() == nil {
+			t.Errorf("Expected %+v to be invalid", invalid)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Default credits of the built-in scoring formula: blocked techniques earn the
+// full weight, detected ones half of it, successful ones nothing
+const (
+	DefaultBlockedCredit  = 1.0
+	DefaultDetectedCredit = 0.5
+)
+
+// SeverityMetadataKey is the technique metadata field overriding its severity,
+// e.g. "low", "medium", "high" or "critical"
+const SeverityMetadataKey = "severity"
+
+// ScoringProfile is a version of a configurable scoring model. Each technique
+// result weighs its tactic weight times its severity weight (1 when unset),
+// times its undetected weight when the technique went undetected, and earns
+// the credit of its outcome; the score is the weighted credit earned out of the
+// total weight, as a percentage. Updating a profile creates a new version, so
+// executions scored with an older one stay comparable.
+type ScoringProfile struct {
+	ID                string             `json:"id"`
+	Version           int                `json:"version"`
+	Name              string             `json:"name"`
+	Description       string             `json:"description,omitempty"`
+	BlockedCredit     float64            `json:"blocked_credit"`               // Credit of blocked techniques, 0-1
+	DetectedCredit    float64            `json:"detected_credit"`              // Partial credit of detected-but-not-blocked techniques, 0-1
+	TacticWeights     map[string]float64 `json:"tactic_weights,omitempty"`     // Weight per MITRE tactic
+	SeverityWeights   map[string]float64 `json:"severity_weights,omitempty"`   // Weight per technique severity
+	UndetectedWeights map[string]float64 `json:"undetected_weights,omitempty"` // Extra weight per technique severity when undetected
+	IsDefault         bool               `json:"is_default"`                   // Used by executions that select no profile
+	CreatedBy         string             `json:"created_by,omitempty"`         // Author of this version
+	CreatedAt         time.Time          `json:"created_at"`                   // Creation of this version
+}
+
+// Validate checks that the profile has a name, credits between 0 and 1 and
+// non-negative weights
+func (p *ScoringProfile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	if p.BlockedCredit < 0 || p.BlockedCredit > 1 || p.DetectedCredit < 0 || p.DetectedCredit > 1 {
+		return errors.New("credits must be between 0 and 1")
+	}
+	for tactic, weight := range p.TacticWeights {
+		if weight < 0 {
+			return errors.New("invalid weight for tactic " + tactic)
+		}
+	}
+	for severity, weight := range p.SeverityWeights {
+		if weight < 0 {
+			return errors.New("invalid weight for severity " + severity)
+		}
+	}
+	for severity, weight := range p.UndetectedWeights {
+		if weight < 0 {
+			return errors.New("invalid undetected weight for severity " + severity)
+		}
+	}
+	return nil
+}
+
+// Weight returns the weight of a technique: the weight of its primary tactic
+// times the weight of its severity, 1 for each when the profile does not set it
+func (p *ScoringProfile) Weight(technique *Technique) float64 {
+	weight := 1.0
+	if technique == nil {
+		return weight
+	}
+	if w, ok := p.TacticWeights[string(technique.Tactic)]; ok {
+		weight *= w
+	}
+	if severity := technique.EffectiveSeverity(); severity != "" {
+		if w, ok := p.SeverityWeights[severity]; ok {
+			weight *= w
+		}
+	}
+	return weight
+}
+
+// UndetectedWeight returns the factor the weight of a technique is multiplied
+// by when it goes undetected: the undetected weight of its severity, 1 when the
+// profile does not set it. An undetected critical technique weighted 3 costs
+// the score three times what blocking it earns.
+func (p *ScoringProfile) UndetectedWeight(technique *Technique) float64 {
+	if technique == nil {
+		return 1
+	}
+	if w, ok := p.UndetectedWeights[technique.EffectiveSeverity()]; ok {
+		return w
+	}
+	return 1
+}
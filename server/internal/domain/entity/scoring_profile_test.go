@@ -0,0 +1,75 @@
+package entity
+
+import "testing"
+
+func TestScoringProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile ScoringProfile
+		wantErr bool
+	}{
+		{"valid", ScoringProfile{Name: "Default", BlockedCredit: 1, DetectedCredit: 0.5}, false},
+		{"missing name", ScoringProfile{Name: " ", BlockedCredit: 1}, true},
+		{"credit above 1", ScoringProfile{Name: "p", BlockedCredit: 1.5}, true},
+		{"negative credit", ScoringProfile{Name: "p", BlockedCredit: 1, DetectedCredit: -0.1}, true},
+		{"negative tactic weight", ScoringProfile{Name: "p", TacticWeights: map[string]float64{"execution": -1}}, true},
+		{"negative severity weight", ScoringProfile{Name: "p", SeverityWeights: map[string]float64{"high": -2}}, true},
+		{"negative undetected weight", ScoringProfile{Name: "p", UndetectedWeights: map[string]float64{"critical": -1}}, true},
+		{"zero weight", ScoringProfile{Name: "p", TacticWeights: map[string]float64{"discovery": 0}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.profile.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScoringProfile_Weight(t *testing.T) {
+	profile := &ScoringProfile{
+		TacticWeights:   map[string]float64{"execution": 2, "discovery": 0.5},
+		SeverityWeights: map[string]float64{"critical": 3},
+	}
+
+	tests := []struct {
+		name      string
+		technique *Technique
+		expected  float64
+	}{
+		{"unknown technique", nil, 1},
+		{"unweighted tactic", &Technique{Tactic: TacticPersistence}, 1},
+		{"tactic weight", &Technique{Tactic: TacticExecution}, 2},
+		{"tactic and severity weights", &Technique{Tactic: TacticExecution,
+			Metadata: map[string]string{"severity": "Critical"}}, 6},
+		{"unweighted severity", &Technique{Tactic: TacticDiscovery,
+			Metadata: map[string]string{"severity": "low"}}, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profile.Weight(tt.technique); got != tt.expected {
+				t.Errorf("Weight() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestScoringProfile_UndetectedWeight(t *testing.T) {
+	profile := &ScoringProfile{UndetectedWeights: map[string]float64{"critical": 3}}
+
+	if got := profile.UndetectedWeight(nil); got != 1 {
+		t.Errorf("UndetectedWeight(nil) = %v, want 1", got)
+	}
+	if got := profile.UndetectedWeight(&Technique{Severity: TechniqueSeverityCritical}); got != 3 {
+		t.Errorf("UndetectedWeight(critical) = %v, want 3", got)
+	}
+	if got := profile.UndetectedWeight(&Technique{Severity: TechniqueSeverityLow}); got != 1 {
+		t.Errorf("UndetectedWeight(low) = %v, want 1", got)
+	}
+	overridden := &Technique{Severity: TechniqueSeverityLow, Metadata: Metadata{SeverityMetadataKey: "Critical"}}
+	if got := profile.UndetectedWeight(overridden); got != 3 {
+		t.Errorf("UndetectedWeight(overridden) = %v, want 3", got)
+	}
+}
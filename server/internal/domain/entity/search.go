@@ -0,0 +1,49 @@
+package entity
+
+// SearchType is the kind of item a search hit refers to
+type SearchType string
+
+const (
+	SearchTechnique SearchType = "technique"
+	SearchScenario  SearchType = "scenario"
+	SearchResult    SearchType = "result"
+)
+
+// SearchTypes returns every searchable type
+func SearchTypes() []SearchType {
+	return []SearchType{SearchTechnique, SearchScenario, SearchResult}
+}
+
+// IsValid checks if the search type is known
+func (t SearchType) IsValid() bool {
+	for _, known := range SearchTypes() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Permission returns the permission needed to search items of the type
+func (t SearchType) Permission() Permission {
+	switch t {
+	case SearchTechnique:
+		return PermissionTechniquesView
+	case SearchScenario:
+		return PermissionScenariosView
+	default:
+		return PermissionExecutionsView
+	}
+}
+
+// SearchHit is an item matching a full-text search. Snippet is an excerpt of
+// the matching text with the matched terms between <mark> and </mark>; the
+// rest of it is not escaped.
+type SearchHit struct {
+	Type        SearchType `json:"type"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Snippet     string     `json:"snippet"`
+	Score       float64    `json:"score"`
+	ExecutionID string     `json:"execution_id,omitempty"` // Execution of a result
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// SecretRefPrefix starts a configuration value naming a stored secret, e.g.
+// SPLUNK_TOKEN=secret://splunk-token
+const SecretRefPrefix = "secret://"
+
+// Secret is an integration credential kept in the secrets store. Its value is
+// sealed with a data key of its own, itself wrapped with the master key, and
+// is never exposed through the API.
+type Secret struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	KeyID       string    `json:"key_id"` // Master key the data key is wrapped with
+	DataKey     []byte    `json:"-"`      // Wrapped data key
+	Ciphertext  []byte    `json:"-"`      // Value sealed with the data key
+	UpdatedBy   string    `json:"updated_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SecretRef returns the name of the secret a configuration value refers to
+func SecretRef(value string) (string, bool) {
+	if !strings.HasPrefix(value, SecretRefPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(value, SecretRefPrefix), true
+}
@@ -1,5 +1,12 @@
 package entity
 
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
 // TacticType represents a MITRE ATT&CK tactic
 type TacticType string
 
@@ -18,27 +25,241 @@ const (
 	TacticCommandAndControl   TacticType = "command-and-control"
 	TacticExfiltration        TacticType = "exfiltration"
 	TacticImpact              TacticType = "impact"
+
+	// Tactics of ATT&CK for ICS that enterprise and mobile do not have
+	TacticEvasion                 TacticType = "evasion"
+	TacticInhibitResponseFunction TacticType = "inhibit-response-function"
+	TacticImpairProcessControl    TacticType = "impair-process-control"
 )
 
 // Technique represents a MITRE ATT&CK technique
 type Technique struct {
-	ID          string      `json:"id" yaml:"id"`                   // "T1059.001"
-	Name        string      `json:"name" yaml:"name"`               // "PowerShell"
-	Tactic      TacticType  `json:"tactic" yaml:"tactic"`           // "execution"
-	Description string      `json:"description" yaml:"description"` // Detailed description
-	Platforms   []string    `json:"platforms" yaml:"platforms"`     // ["windows"]
-	Executors   []Executor  `json:"executors" yaml:"executors"`
-	Detection   []Detection `json:"detection,omitempty" yaml:"detection,omitempty"`
-	References  []string    `json:"references,omitempty" yaml:"references,omitempty"`
-	IsSafe      bool        `json:"is_safe" yaml:"is_safe"` // Safe for production
+	ID          string          `json:"id" yaml:"id"`                   // "T1059.001"
+	Name        string          `json:"name" yaml:"name"`               // "PowerShell"
+	Tactic      TacticType      `json:"tactic" yaml:"tactic"`           // "execution"
+	Description string          `json:"description" yaml:"description"` // Detailed description
+	Platforms   []string        `json:"platforms" yaml:"platforms"`     // ["windows"]
+	Executors   []Executor      `json:"executors" yaml:"executors"`
+	Detection   []Detection     `json:"detection,omitempty" yaml:"detection,omitempty"`
+	References  []string        `json:"references,omitempty" yaml:"references,omitempty"`
+	SigmaRules  []SigmaRule     `json:"sigma_rules,omitempty" yaml:"sigma_rules,omitempty"`
+	IsSafe      bool            `json:"is_safe" yaml:"is_safe"`                         // Safe for production
+	Status      TechniqueStatus `json:"status,omitempty" yaml:"status,omitempty"`       // Lifecycle state, empty means active
+	ParentID    string          `json:"parent_id,omitempty" yaml:"parent_id,omitempty"` // "T1059" for a sub-technique
+	Tactics     []TacticType    `json:"tactics,omitempty" yaml:"tactics,omitempty"`     // Every tactic, Tactic first
+	Metadata    Metadata        `json:"metadata,omitempty" yaml:"metadata,omitempty"`   // Organization fields: owner team, risk rating...
+	Severity    string          `json:"severity,omitempty" yaml:"severity,omitempty"`   // low, medium, high or critical
+	Impact      float64         `json:"impact,omitempty" yaml:"impact,omitempty"`       // CVSS-like, 0.1-10
+	Domain      string          `json:"domain,omitempty" yaml:"domain,omitempty"`       // ATT&CK domain, enterprise-attack when empty
+	DeletedAt   *time.Time      `json:"deleted_at,omitempty" yaml:"-"`                  // Set while the technique is in the trash
+}
+
+// Metadata holds the custom fields an organization adds to a technique
+type Metadata map[string]string
+
+// ImportSourceMetadataKey is the technique metadata field naming the content
+// format a technique was imported from ("caldera", "ctid", ...)
+const ImportSourceMetadataKey = "import_source"
+
+// ATTACKVersionMetadataKey is the technique metadata field holding the ATT&CK
+// release a technique was last imported from ("15.1")
+const ATTACKVersionMetadataKey = "attack_version"
+
+// ATT&CK domains, the matrices MITRE publishes a STIX bundle for
+const (
+	DomainEnterprise = "enterprise-attack"
+	DomainICS        = "ics-attack"
+	DomainMobile     = "mobile-attack"
+)
+
+// IsValidDomain reports whether domain is an ATT&CK domain
+func IsValidDomain(domain string) bool {
+	return domain == DomainEnterprise || domain == DomainICS || domain == DomainMobile
+}
+
+// MatchesMetadata reports whether the technique has every field of filter, with
+// the same value regardless of case
+func (t *Technique) MatchesMetadata(filter Metadata) bool {
+	for field, value := range filter {
+		current, ok := t.Metadata[field]
+		if !ok || !strings.EqualFold(current, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeTactics makes Tactic the first of Tactics: it defaults Tactic to the
+// first listed tactic, lists Tactic when missing and drops duplicates
+func (t *Technique) NormalizeTactics() {
+	if t.Tactic == "" && len(t.Tactics) > 0 {
+		t.Tactic = t.Tactics[0]
+	}
+	if t.Tactic == "" {
+		return
+	}
+	tactics := []TacticType{t.Tactic}
+	for _, tactic := range t.Tactics {
+		if tactic != "" && !slices.Contains(tactics, tactic) {
+			tactics = append(tactics, tactic)
+		}
+	}
+	t.Tactics = tactics
+}
+
+// AllTactics returns every tactic of the technique, Tactic alone when Tactics is unset
+func (t *Technique) AllTactics() []TacticType {
+	if len(t.Tactics) > 0 {
+		return t.Tactics
+	}
+	if t.Tactic == "" {
+		return nil
+	}
+	return []TacticType{t.Tactic}
+}
+
+// HasTactic reports whether the technique belongs to a tactic
+func (t *Technique) HasTactic(tactic TacticType) bool {
+	return t.Tactic == tactic || slices.Contains(t.Tactics, tactic)
+}
+
+// ParentTechniqueID returns the ATT&CK technique a sub-technique ID belongs to
+// ("T1059" for "T1059.001"), or "" when id is not a sub-technique ID
+func ParentTechniqueID(id string) string {
+	parent, sub, found := strings.Cut(id, ".")
+	if !found || !strings.HasPrefix(parent, "T") || !isDigits(parent[1:], 4) || !isDigits(sub, 3) {
+		return ""
+	}
+	return parent
+}
+
+// isDigits reports whether s is made of n decimal digits
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// TechniqueStatus is the lifecycle state of a technique
+type TechniqueStatus string
+
+const (
+	TechniqueDraft      TechniqueStatus = "draft"      // Being written, not yet validated
+	TechniqueActive     TechniqueStatus = "active"     // Usable in scenarios
+	TechniqueDeprecated TechniqueStatus = "deprecated" // Superseded, kept for existing scenarios only
+	TechniqueBroken     TechniqueStatus = "broken"     // Known not to work, kept for existing scenarios only
+)
+
+// techniqueTransitions lists the lifecycle states reachable from each state
+var techniqueTransitions = map[TechniqueStatus][]TechniqueStatus{
+	TechniqueDraft:      {TechniqueActive},
+	TechniqueActive:     {TechniqueDeprecated, TechniqueBroken},
+	TechniqueBroken:     {TechniqueActive, TechniqueDeprecated},
+	TechniqueDeprecated: {TechniqueActive},
+}
+
+// IsValid reports whether the status is a known lifecycle state
+func (s TechniqueStatus) IsValid() bool {
+	_, ok := techniqueTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a technique may move from s to next
+func (s TechniqueStatus) CanTransitionTo(next TechniqueStatus) bool {
+	for _, allowed := range techniqueTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// LifecycleStatus returns the technique status, active when unset
+func (t *Technique) LifecycleStatus() TechniqueStatus {
+	if t.Status == "" {
+		return TechniqueActive
+	}
+	return t.Status
+}
+
+// IsRetired reports whether the technique is deprecated or broken and must not be added to scenarios
+func (t *Technique) IsRetired() bool {
+	status := t.LifecycleStatus()
+	return status == TechniqueDeprecated || status == TechniqueBroken
 }
 
 // Executor defines how to execute the technique
 type Executor struct {
-	Type    string `json:"type" yaml:"type"`       // "psh", "cmd", "bash"
-	Command string `json:"command" yaml:"command"` // The command to execute
+	Type    string          `json:"type" yaml:"type"`       // "psh", "cmd", "bash"
+	Command string          `json:"command" yaml:"command"` // The command to execute
+	Cleanup string          `json:"cleanup,omitempty" yaml:"cleanup,omitempty"`
+	Timeout int             `json:"timeout" yaml:"timeout"` // Seconds
+	Limits  *ResourceLimits `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Parsers []FactParser    `json:"parsers,omitempty" yaml:"parsers,omitempty"` // Facts extracted from the output
+	Payload string          `json:"payload,omitempty" yaml:"payload,omitempty"` // Name of a payload delivered with the command
+	Collect []string        `json:"collect,omitempty" yaml:"collect,omitempty"` // Files the agent uploads as result artifacts
+	// The command needs root or administrator rights, it only runs on elevated agents
+	ElevationRequired bool `json:"elevation_required,omitempty" yaml:"elevation_required,omitempty"`
+	// Commands replacing Command and Cleanup on a platform ("linux", "darwin", "windows")
+	Variants map[string]CommandVariant `json:"variants,omitempty" yaml:"variants,omitempty"`
+}
+
+// CommandVariant is the command of an executor on one platform, such as the
+// darwin flavour of a sh command
+type CommandVariant struct {
+	Command string `json:"command" yaml:"command"`
 	Cleanup string `json:"cleanup,omitempty" yaml:"cleanup,omitempty"`
-	Timeout int    `json:"timeout" yaml:"timeout"` // Seconds
+}
+
+// ForPlatform returns the executor as it runs on the platform: a copy with the
+// command and cleanup of its variant for the platform, else the executor itself
+func (e *Executor) ForPlatform(platform string) *Executor {
+	variant, ok := e.Variants[platform]
+	if !ok {
+		return e
+	}
+	resolved := *e
+	resolved.Command, resolved.Cleanup = variant.Command, variant.Cleanup
+	resolved.Variants = nil
+	return &resolved
+}
+
+// Commands returns the commands and cleanups of the executor, those of its
+// variants included
+func (e *Executor) Commands() []string {
+	commands := []string{e.Command, e.Cleanup}
+	for _, platform := range slices.Sorted(maps.Keys(e.Variants)) {
+		commands = append(commands, e.Variants[platform].Command, e.Variants[platform].Cleanup)
+	}
+	return commands
+}
+
+// FactParser extracts a named fact from the output of an executor, with a
+// regular expression (its first capture group, or the whole match) or a JSON
+// path such as $.host.name or $.addresses[0]. Later techniques of the execution
+// reference the fact as #{fact.<name>} in their command.
+type FactParser struct {
+	Fact     string `json:"fact" yaml:"fact"`
+	Regex    string `json:"regex,omitempty" yaml:"regex,omitempty"`
+	JSONPath string `json:"json_path,omitempty" yaml:"json_path,omitempty"`
+}
+
+// ResourceLimits caps what the command may use on the agent host.
+// The agent enforces them with cgroups on Linux and job objects on Windows.
+type ResourceLimits struct {
+	CPUPercent int `json:"cpu_percent,omitempty" yaml:"cpu_percent,omitempty"` // Share of one core, throttled
+	MemoryMB   int `json:"memory_mb,omitempty" yaml:"memory_mb,omitempty"`     // The command is killed above it
+}
+
+// IsValid reports whether every limit is unset or positive
+func (l *ResourceLimits) IsValid() bool {
+	return l == nil || (l.CPUPercent >= 0 && l.MemoryMB >= 0)
 }
 
 // Detection describes expected detection indicators
@@ -47,8 +268,22 @@ type Detection struct {
 	Indicator string `json:"indicator" yaml:"indicator"` // Pattern description
 }
 
+// SigmaRule links a technique to a Sigma detection rule
+type SigmaRule struct {
+	ID    string `json:"id" yaml:"id"`                           // Sigma rule UUID
+	Title string `json:"title,omitempty" yaml:"title,omitempty"` // Rule title, as deployed in the SIEM
+}
+
 // GetExecutorForPlatform returns the first compatible executor for the given platform
 func (t *Technique) GetExecutorForPlatform(platform string, agentExecutors []string) *Executor {
+	return t.SelectExecutor(platform, agentExecutors, nil)
+}
+
+// SelectExecutor returns the executor a technique runs with on an agent of the
+// platform having agentExecutors: the first preferred type both support, else
+// the first compatible executor, with its command for the platform. Nil when
+// the agent cannot run the technique.
+func (t *Technique) SelectExecutor(platform string, agentExecutors, preferred []string) *Executor {
 	// Check if platform is supported
 	platformSupported := false
 	for _, p := range t.Platforms {
@@ -62,13 +297,52 @@ func (t *Technique) GetExecutorForPlatform(platform string, agentExecutors []str
 		return nil
 	}
 
+	for _, executorType := range preferred {
+		if executor := t.ExecutorOfType(executorType); executor != nil && slices.Contains(agentExecutors, executorType) {
+			return executor.ForPlatform(platform)
+		}
+	}
+
 	// Find compatible executor
 	for i := range t.Executors {
 		for _, agentExec := range agentExecutors {
 			if t.Executors[i].Type == agentExec {
-				return &t.Executors[i]
+				return t.Executors[i].ForPlatform(platform)
 			}
 		}
 	}
 	return nil
 }
+
+// ExecutorForAgent returns the executor a technique runs with on an agent like
+// SelectExecutor, leaving out the executors requiring elevation on agents that
+// are not elevated. needsElevation reports that the agent could only run the
+// technique elevated.
+func (t *Technique) ExecutorForAgent(agent *Agent, preferred []string) (executor *Executor, needsElevation bool) {
+	if agent.Elevated {
+		return t.SelectExecutor(agent.Platform, agent.Executors, preferred), false
+	}
+
+	unprivileged := *t
+	unprivileged.Executors = make([]Executor, 0, len(t.Executors))
+	for _, e := range t.Executors {
+		if !e.ElevationRequired {
+			unprivileged.Executors = append(unprivileged.Executors, e)
+		}
+	}
+	if selected := unprivileged.SelectExecutor(agent.Platform, agent.Executors, preferred); selected != nil {
+		return selected, false
+	}
+	return nil, t.SelectExecutor(agent.Platform, agent.Executors, preferred) != nil
+}
+
+// ExecutorOfType returns the first executor of the technique with the type, or
+// nil when it has none
+func (t *Technique) ExecutorOfType(executorType string) *Executor {
+	for i := range t.Executors {
+		if t.Executors[i].Type == executorType {
+			return &t.Executors[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package entity
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Technique severities, the CVSS v3 qualitative ratings
+const (
+	TechniqueSeverityLow      = "low"
+	TechniqueSeverityMedium   = "medium"
+	TechniqueSeverityHigh     = "high"
+	TechniqueSeverityCritical = "critical"
+)
+
+// ImpactMetadataKey is the technique metadata field overriding its impact, a
+// number from 0.1 to 10. Like the severity field, it survives catalog re-imports.
+const ImpactMetadataKey = "impact"
+
+// MaxTechniqueImpact is the impact of the most damaging techniques
+const MaxTechniqueImpact = 10.0
+
+// tacticImpact is the estimated impact of a technique of each tactic going
+// undetected, on the CVSS 0-10 scale: what an attacker gains from it
+var tacticImpact = map[TacticType]float64{
+	TacticReconnaissance:      2.0,
+	TacticResourceDevelopment: 2.0,
+	TacticDiscovery:           3.0,
+	TacticCollection:          5.0,
+	TacticExecution:           5.5,
+	TacticCommandAndControl:   6.0,
+	TacticPersistence:         6.5,
+	TacticDefenseEvasion:      6.5,
+	TacticInitialAccess:       7.0,
+	TacticLateralMovement:     7.5,
+	TacticPrivilegeEscalation: 7.5,
+	TacticCredentialAccess:    8.0,
+	TacticExfiltration:        8.0,
+	TacticImpact:              9.0,
+
+	TacticEvasion:                 6.5,
+	TacticInhibitResponseFunction: 8.5,
+	TacticImpairProcessControl:    9.0,
+}
+
+// Impact of a technique without a known tactic, and the impact added when it
+// is not safe to run in production
+const (
+	defaultTacticImpact = 5.0
+	unsafeImpact        = 1.0
+)
+
+// IsValidSeverity reports whether severity is one of the technique severities
+func IsValidSeverity(severity string) bool {
+	switch severity {
+	case TechniqueSeverityLow, TechniqueSeverityMedium, TechniqueSeverityHigh, TechniqueSeverityCritical:
+		return true
+	}
+	return false
+}
+
+// IsValidImpact reports whether impact is on the 0-10 scale, 0 meaning unset
+func IsValidImpact(impact float64) bool {
+	return impact >= 0 && impact <= MaxTechniqueImpact
+}
+
+// SeverityForImpact rates an impact like CVSS v3: critical from 9, high from
+// 7, medium from 4, low below
+func SeverityForImpact(impact float64) string {
+	switch {
+	case impact >= 9:
+		return TechniqueSeverityCritical
+	case impact >= 7:
+		return TechniqueSeverityHigh
+	case impact >= 4:
+		return TechniqueSeverityMedium
+	default:
+		return TechniqueSeverityLow
+	}
+}
+
+// EstimateImpact estimates the impact of a technique: the impact of its most
+// damaging tactic, raised when the technique is not safe to run in production,
+// rounded to one decimal
+func EstimateImpact(t *Technique) float64 {
+	impact := 0.0
+	for _, tactic := range t.AllTactics() {
+		if w, ok := tacticImpact[tactic]; ok && w > impact {
+			impact = w
+		}
+	}
+	if impact == 0 {
+		impact = defaultTacticImpact
+	}
+	if !t.IsSafe {
+		impact += unsafeImpact
+	}
+	return math.Round(math.Min(impact, MaxTechniqueImpact)*10) / 10
+}
+
+// AssessRisk fills in the severity and impact of a technique. The severity and
+// impact metadata fields override them; otherwise the values of the catalog
+// are kept and missing ones estimated, the impact with EstimateImpact and the
+// severity from the impact.
+func (t *Technique) AssessRisk() {
+	if severity := t.severityOverride(); severity != "" {
+		t.Severity = severity
+	}
+	if impact, ok := t.impactOverride(); ok {
+		t.Impact = impact
+	}
+	t.Severity = strings.ToLower(strings.TrimSpace(t.Severity))
+	if t.Impact <= 0 || t.Impact > MaxTechniqueImpact {
+		t.Impact = EstimateImpact(t)
+	}
+	if t.Severity == "" {
+		t.Severity = SeverityForImpact(t.Impact)
+	}
+}
+
+// EffectiveSeverity returns the severity of a technique: its severity metadata
+// override, else its assessed severity
+func (t *Technique) EffectiveSeverity() string {
+	if severity := t.severityOverride(); severity != "" {
+		return severity
+	}
+	return strings.ToLower(strings.TrimSpace(t.Severity))
+}
+
+// HasRiskOverride reports whether the metadata of a technique overrides its
+// severity or impact
+func (t *Technique) HasRiskOverride() bool {
+	_, ok := t.impactOverride()
+	return ok || t.severityOverride() != ""
+}
+
+// severityOverride returns the severity metadata field, lowercased, or "" when unset
+func (t *Technique) severityOverride() string {
+	return strings.ToLower(strings.TrimSpace(t.Metadata[SeverityMetadataKey]))
+}
+
+// impactOverride returns the impact metadata field, when set to a valid impact
+func (t *Technique) impactOverride() (float64, bool) {
+	value := strings.TrimSpace(t.Metadata[ImpactMetadataKey])
+	if value == "" {
+		return 0, false
+	}
+	impact, err := strconv.ParseFloat(value, 64)
+	if err != nil || impact <= 0 || impact > MaxTechniqueImpact {
+		return 0, false
+	}
+	return impact, true
+}
@@ -0,0 +1,90 @@
+package entity
+
+import "testing"
+
+func TestSeverityForImpact(t *testing.T) {
+	tests := []struct {
+		impact   float64
+		expected string
+	}{
+		{0.5, TechniqueSeverityLow},
+		{3.9, TechniqueSeverityLow},
+		{4, TechniqueSeverityMedium},
+		{7, TechniqueSeverityHigh},
+		{8.9, TechniqueSeverityHigh},
+		{9, TechniqueSeverityCritical},
+		{10, TechniqueSeverityCritical},
+	}
+
+	for _, tt := range tests {
+		if got := SeverityForImpact(tt.impact); got != tt.expected {
+			t.Errorf("SeverityForImpact(%v) = %q, want %q", tt.impact, got, tt.expected)
+		}
+	}
+}
+
+func TestEstimateImpact(t *testing.T) {
+	tests := []struct {
+		name      string
+		technique *Technique
+		expected  float64
+	}{
+		{"safe discovery", &Technique{Tactic: TacticDiscovery, IsSafe: true}, 3},
+		{"unsafe discovery", &Technique{Tactic: TacticDiscovery}, 4},
+		{"most damaging tactic", &Technique{Tactic: TacticExecution, Tactics: []TacticType{TacticExecution, TacticCredentialAccess}, IsSafe: true}, 8},
+		{"ics tactic", &Technique{Tactic: TacticImpairProcessControl, IsSafe: true}, 9},
+		{"unknown tactic", &Technique{Tactic: "custom", IsSafe: true}, 5},
+		{"capped", &Technique{Tactic: TacticImpact}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateImpact(tt.technique); got != tt.expected {
+				t.Errorf("EstimateImpact() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTechnique_AssessRisk(t *testing.T) {
+	tests := []struct {
+		name             string
+		technique        Technique
+		expectedSeverity string
+		expectedImpact   float64
+	}{
+		{"estimated", Technique{Tactic: TacticCredentialAccess}, TechniqueSeverityCritical, 9},
+		{"catalog values kept", Technique{Tactic: TacticDiscovery, IsSafe: true, Severity: "High", Impact: 7.2}, TechniqueSeverityHigh, 7.2},
+		{"catalog impact rated", Technique{Tactic: TacticDiscovery, Impact: 9.5}, TechniqueSeverityCritical, 9.5},
+		{"impact out of range estimated", Technique{Tactic: TacticDiscovery, IsSafe: true, Impact: 12}, TechniqueSeverityLow, 3},
+		{"metadata overrides", Technique{Tactic: TacticDiscovery, Severity: TechniqueSeverityLow, Impact: 2,
+			Metadata: Metadata{SeverityMetadataKey: " Critical ", ImpactMetadataKey: "9.8"}}, TechniqueSeverityCritical, 9.8},
+		{"invalid impact override ignored", Technique{Tactic: TacticDiscovery, IsSafe: true,
+			Metadata: Metadata{ImpactMetadataKey: "severe"}}, TechniqueSeverityLow, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			technique := tt.technique
+			technique.AssessRisk()
+			if technique.Severity != tt.expectedSeverity || technique.Impact != tt.expectedImpact {
+				t.Errorf("AssessRisk() = %q %v, want %q %v", technique.Severity, technique.Impact, tt.expectedSeverity, tt.expectedImpact)
+			}
+		})
+	}
+}
+
+func TestTechnique_HasRiskOverride(t *testing.T) {
+	if (&Technique{Severity: TechniqueSeverityHigh}).HasRiskOverride() {
+		t.Error("A catalog severity is not an override")
+	}
+	if !(&Technique{Metadata: Metadata{SeverityMetadataKey: "high"}}).HasRiskOverride() {
+		t.Error("Expected a severity override")
+	}
+	if !(&Technique{Metadata: Metadata{ImpactMetadataKey: "4.5"}}).HasRiskOverride() {
+		t.Error("Expected an impact override")
+	}
+	if (&Technique{Metadata: Metadata{ImpactMetadataKey: "0"}}).HasRiskOverride() {
+		t.Error("An invalid impact is not an override")
+	}
+}
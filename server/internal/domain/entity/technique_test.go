@@ -74,6 +74,107 @@ func TestTechnique_GetExecutorForPlatform(t *testing.T) {
 	}
 }
 
+func TestTechnique_SelectExecutor(t *testing.T) {
+	technique := &Technique{
+		ID:        "T1059",
+		Platforms: []string{"windows"},
+		Executors: []Executor{
+			{Type: "cmd", Command: "whoami"},
+			{Type: "psh", Command: "Get-LocalUser"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		platform       string
+		agentExecutors []string
+		preferred      []string
+		wantType       string
+	}{
+		{"no preference takes the first compatible", "windows", []string{"cmd", "psh"}, nil, "cmd"},
+		{"preferred executor", "windows", []string{"cmd", "psh"}, []string{"psh", "cmd"}, "psh"},
+		{"fallback when the agent lacks the preferred one", "windows", []string{"cmd"}, []string{"psh", "cmd"}, "cmd"},
+		{"fallback when the technique lacks the preferred ones", "windows", []string{"cmd", "psh"}, []string{"pwsh"}, "cmd"},
+		{"unsupported platform", "linux", []string{"cmd", "psh"}, []string{"psh"}, ""},
+		{"no compatible executor", "windows", []string{"sh"}, []string{"psh"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := technique.SelectExecutor(tt.platform, tt.agentExecutors, tt.preferred)
+			switch {
+			case tt.wantType == "" && got != nil:
+				t.Errorf("SelectExecutor() = %v, want nil", got)
+			case tt.wantType != "" && (got == nil || got.Type != tt.wantType):
+				t.Errorf("SelectExecutor() = %v, want executor of type %s", got, tt.wantType)
+			}
+		})
+	}
+
+	if technique.ExecutorOfType("psh").Command != "Get-LocalUser" || technique.ExecutorOfType("sh") != nil {
+		t.Error("ExecutorOfType should return the executor of the type, nil when missing")
+	}
+}
+
+func TestTechnique_ExecutorForAgent(t *testing.T) {
+	technique := &Technique{
+		ID:        "T1003",
+		Platforms: []string{"windows"},
+		Executors: []Executor{
+			{Type: "psh", Command: "dump", ElevationRequired: true},
+			{Type: "cmd", Command: "reg save"},
+		},
+	}
+	user := &Agent{Platform: "windows", Executors: []string{"psh", "cmd"}}
+	admin := &Agent{Platform: "windows", Executors: []string{"psh", "cmd"}, AgentInventory: AgentInventory{Elevated: true}}
+
+	if executor, needsElevation := technique.ExecutorForAgent(admin, nil); executor == nil || executor.Type != "psh" || needsElevation {
+		t.Errorf("Expected the elevated agent to run psh, got %+v %v", executor, needsElevation)
+	}
+	// The non-elevated agent falls back to the executor it can run
+	if executor, needsElevation := technique.ExecutorForAgent(user, []string{"psh"}); executor == nil || executor.Type != "cmd" || needsElevation {
+		t.Errorf("Expected the non-elevated agent to run cmd, got %+v %v", executor, needsElevation)
+	}
+	pshOnly := &Agent{Platform: "windows", Executors: []string{"psh"}}
+	if executor, needsElevation := technique.ExecutorForAgent(pshOnly, nil); executor != nil || !needsElevation {
+		t.Errorf("Expected the technique to need elevation, got %+v %v", executor, needsElevation)
+	}
+	linux := &Agent{Platform: "linux", Executors: []string{"sh"}}
+	if executor, needsElevation := technique.ExecutorForAgent(linux, nil); executor != nil || needsElevation {
+		t.Errorf("Expected an incompatible agent, got %+v %v", executor, needsElevation)
+	}
+}
+
+func TestTechnique_CommandVariants(t *testing.T) {
+	technique := &Technique{
+		ID:        "T1082",
+		Platforms: []string{"linux", "darwin"},
+		Executors: []Executor{{
+			Type:     "sh",
+			Command:  "uname -a",
+			Cleanup:  "rm -f /tmp/os",
+			Variants: map[string]CommandVariant{"darwin": {Command: "sw_vers"}},
+		}},
+	}
+
+	linux := technique.SelectExecutor("linux", []string{"sh"}, nil)
+	if linux == nil || linux.Command != "uname -a" || linux.Cleanup != "rm -f /tmp/os" {
+		t.Errorf("Expected the default command on linux, got %+v", linux)
+	}
+	darwin := technique.SelectExecutor("darwin", []string{"sh"}, []string{"sh"})
+	if darwin == nil || darwin.Command != "sw_vers" || darwin.Cleanup != "" || darwin.Variants != nil {
+		t.Errorf("Expected the darwin variant, got %+v", darwin)
+	}
+	if technique.Executors[0].Command != "uname -a" {
+		t.Error("Resolving a variant must not modify the technique")
+	}
+
+	commands := technique.Executors[0].Commands()
+	if len(commands) != 4 || commands[2] != "sw_vers" {
+		t.Errorf("Expected the variant among the commands, got %v", commands)
+	}
+}
+
 func TestTechnique_MultiPlatform(t *testing.T) {
 	technique := &Technique{
 		ID:        "T1082",
@@ -141,3 +242,125 @@ func TestTacticType_Constants(t *testing.T) {
 		}
 	}
 }
+
+func TestTechnique_LifecycleStatus(t *testing.T) {
+	tests := []struct {
+		status  TechniqueStatus
+		want    TechniqueStatus
+		retired bool
+	}{
+		{"", TechniqueActive, false},
+		{TechniqueDraft, TechniqueDraft, false},
+		{TechniqueActive, TechniqueActive, false},
+		{TechniqueDeprecated, TechniqueDeprecated, true},
+		{TechniqueBroken, TechniqueBroken, true},
+	}
+
+	for _, tt := range tests {
+		technique := &Technique{ID: "T1059", Status: tt.status}
+		if got := technique.LifecycleStatus(); got != tt.want {
+			t.Errorf("LifecycleStatus(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+		if got := technique.IsRetired(); got != tt.retired {
+			t.Errorf("IsRetired(%q) = %v, want %v", tt.status, got, tt.retired)
+		}
+	}
+}
+
+func TestTechniqueStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		from, to TechniqueStatus
+		allowed  bool
+	}{
+		{TechniqueDraft, TechniqueActive, true},
+		{TechniqueDraft, TechniqueDeprecated, false},
+		{TechniqueActive, TechniqueDeprecated, true},
+		{TechniqueActive, TechniqueBroken, true},
+		{TechniqueActive, TechniqueDraft, false},
+		{TechniqueBroken, TechniqueActive, true},
+		{TechniqueBroken, TechniqueDeprecated, true},
+		{TechniqueDeprecated, TechniqueActive, true},
+		{TechniqueDeprecated, TechniqueBroken, false},
+		{TechniqueActive, TechniqueActive, false},
+		{"unknown", TechniqueActive, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.allowed {
+			t.Errorf("%s -> %s = %v, want %v", tt.from, tt.to, got, tt.allowed)
+		}
+	}
+
+	for _, status := range []TechniqueStatus{TechniqueDraft, TechniqueActive, TechniqueDeprecated, TechniqueBroken} {
+		if !status.IsValid() {
+			t.Errorf("Expected %s to be valid", status)
+		}
+	}
+	if TechniqueStatus("retired").IsValid() || TechniqueStatus("").IsValid() {
+		t.Error("Expected unknown statuses to be invalid")
+	}
+}
+
+func TestResourceLimits_IsValid(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits *ResourceLimits
+		want   bool
+	}{
+		{"unset", nil, true},
+		{"empty", &ResourceLimits{}, true},
+		{"positive", &ResourceLimits{CPUPercent: 50, MemoryMB: 256}, true},
+		{"negative cpu", &ResourceLimits{CPUPercent: -1}, false},
+		{"negative memory", &ResourceLimits{MemoryMB: -256}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParentTechniqueID(t *testing.T) {
+	tests := map[string]string{
+		"T1059.001": "T1059",
+		"T1003.008": "T1003",
+		"T1059":     "",
+		"T1059.1":   "",
+		"T105.001":  "",
+		"X1059.001": "",
+		"":          "",
+	}
+
+	for id, want := range tests {
+		if got := ParentTechniqueID(id); got != want {
+			t.Errorf("ParentTechniqueID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestTechnique_NormalizeTactics(t *testing.T) {
+	technique := &Technique{Tactics: []TacticType{TacticPersistence, TacticPrivilegeEscalation, TacticPersistence}}
+	technique.NormalizeTactics()
+	if technique.Tactic != TacticPersistence || len(technique.Tactics) != 2 {
+		t.Errorf("Expected the first tactic as primary without duplicates, got %s %v", technique.Tactic, technique.Tactics)
+	}
+
+	technique = &Technique{Tactic: TacticInitialAccess, Tactics: []TacticType{TacticPersistence}}
+	technique.NormalizeTactics()
+	if len(technique.Tactics) != 2 || technique.Tactics[0] != TacticInitialAccess {
+		t.Errorf("Expected the primary tactic listed first, got %v", technique.Tactics)
+	}
+	if !technique.HasTactic(TacticPersistence) || technique.HasTactic(TacticImpact) {
+		t.Errorf("Unexpected HasTactic for %v", technique.Tactics)
+	}
+
+	if tactics := (&Technique{Tactic: TacticDiscovery}).AllTactics(); len(tactics) != 1 || tactics[0] != TacticDiscovery {
+		t.Errorf("Expected the single tactic, got %v", tactics)
+	}
+	if tactics := (&Technique{}).AllTactics(); tactics != nil {
+		t.Errorf("Expected no tactics, got %v", tactics)
+	}
+}
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// TicketStatus represents the state of a tracker issue opened for a technique
+type TicketStatus string
+
+const (
+	TicketOpen     TicketStatus = "open"     // The technique still goes undetected
+	TicketResolved TicketStatus = "resolved" // Closed in the tracker, or the technique is now defended
+)
+
+// IsValidTicketStatus checks if a ticket status is known
+func IsValidTicketStatus(s TicketStatus) bool {
+	return s == TicketOpen || s == TicketResolved
+}
+
+// Ticket resolutions
+const (
+	TicketResolutionDefended = "defended" // A later run blocked or detected the technique
+	TicketResolutionClosed   = "closed"   // The issue was closed in the tracker
+)
+
+// Ticket is an issue opened in an external tracker (Jira, ServiceNow) for a
+// technique that ran undetected on an agent. A single ticket stays open per
+// technique and agent; later failures are added to it.
+type Ticket struct {
+	ID             string       `json:"id"`
+	Provider       string       `json:"provider"`     // "jira", "servicenow"
+	ExternalKey    string       `json:"external_key"` // Issue key or record ID in the tracker
+	URL            string       `json:"url,omitempty"`
+	TechniqueID    string       `json:"technique_id"`
+	AgentPaw       string       `json:"agent_paw"`
+	ExecutionID    string       `json:"execution_id"` // Latest execution the technique went undetected in
+	ResultID       string       `json:"result_id"`
+	Status         TicketStatus `json:"status"`
+	ExternalStatus string       `json:"external_status,omitempty"` // Status name in the tracker at the last sync
+	Resolution     string       `json:"resolution,omitempty"`
+	Occurrences    int          `json:"occurrences"` // Executions the technique went undetected in
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	ResolvedAt     *time.Time   `json:"resolved_at,omitempty"`
+}
+
+// Resolve marks the ticket resolved
+func (t *Ticket) Resolve(resolution string, at time.Time) {
+	t.Status = TicketResolved
+	t.Resolution = resolution
+	t.ResolvedAt = &at
+	t.UpdatedAt = at
+}
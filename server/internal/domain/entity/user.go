@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -26,6 +27,84 @@ type User struct {
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+	Workspace    string     `json:"workspace,omitempty"` // Team the user works in, set by administrators
+
+	// Profile, set by the user themselves
+	DisplayName string          `json:"display_name,omitempty"`
+	Timezone    string          `json:"timezone,omitempty"` // IANA timezone dates are shown in, the browser's when empty
+	Preferences UserPreferences `json:"preferences"`
+}
+
+// Landing pages a user can open after login
+var landingPages = map[string]bool{
+	"/dashboard": true, "/agents": true, "/techniques": true, "/matrix": true,
+	"/scenarios": true, "/executions": true, "/scheduler": true, "/analytics": true, "/settings": true,
+}
+
+// UserPreferences are the dashboard defaults of a user
+type UserPreferences struct {
+	LandingPage     string `json:"landing_page,omitempty"`     // Page opened after login, /dashboard when empty
+	AnalyticsPeriod int    `json:"analytics_period,omitempty"` // Default analytics period in days: 7, 30 or 90
+	Theme           string `json:"theme,omitempty"`            // light, dark or system
+}
+
+// Validate checks the preferences are ones the dashboard offers
+func (p UserPreferences) Validate() error {
+	if p.LandingPage != "" && !landingPages[p.LandingPage] {
+		return fmt.Errorf("unknown landing page %q", p.LandingPage)
+	}
+	switch p.AnalyticsPeriod {
+	case 0, 7, 30, 90:
+	default:
+		return fmt.Errorf("analytics period must be 7, 30 or 90 days")
+	}
+	switch p.Theme {
+	case "", "light", "dark", "system":
+	default:
+		return fmt.Errorf("theme must be light, dark or system")
+	}
+	return nil
+}
+
+// LoginThrottleScope is what failed logins are counted against
+type LoginThrottleScope string
+
+const (
+	LoginThrottleUser LoginThrottleScope = "user" // Keyed by username, whether the user exists or not
+	LoginThrottleIP   LoginThrottleScope = "ip"   // Keyed by source IP address
+)
+
+// LoginThrottle counts the recent failed logins of a username or a source IP
+// and the lockouts they caused, each lockout lasting twice the previous one
+type LoginThrottle struct {
+	Scope         LoginThrottleScope `json:"scope"`
+	Key           string             `json:"key"`
+	Failures      int                `json:"failures"` // Failures since the last lockout
+	Lockouts      int                `json:"lockouts"` // Lockouts within a day of each other, doubling the next one
+	LockedUntil   *time.Time         `json:"locked_until,omitempty"`
+	LastFailureAt time.Time          `json:"last_failure_at"`
+}
+
+// IsLocked reports whether logins are refused at t
+func (t *LoginThrottle) IsLocked(at time.Time) bool {
+	return t.LockedUntil != nil && at.Before(*t.LockedUntil)
+}
+
+// PasswordResetToken is a single-use, short-lived token emailed to a user who
+// forgot their password. Only its SHA-256 hash is stored.
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	RequestIP string     `json:"request_ip,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// Usable reports whether the token can still reset a password at now
+func (t *PasswordResetToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
 }
 
 // IsAdmin returns true if the user has admin role
@@ -216,6 +216,29 @@ func TestUserRole_DisplayName(t *testing.T) {
 	}
 }
 
+func TestUserPreferences_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefs   UserPreferences
+		wantErr bool
+	}{
+		{"empty", UserPreferences{}, false},
+		{"all set", UserPreferences{LandingPage: "/analytics", AnalyticsPeriod: 90, Theme: "system"}, false},
+		{"unknown landing page", UserPreferences{LandingPage: "/admin/users"}, true},
+		{"unknown period", UserPreferences{AnalyticsPeriod: 14}, true},
+		{"unknown theme", UserPreferences{Theme: "blue"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prefs.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
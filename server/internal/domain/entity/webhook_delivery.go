@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookDeliveryStatus represents the state of a queued webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Waiting for its next attempt
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered" // Endpoint answered with a 2xx status
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // All attempts exhausted
+)
+
+// IsValidWebhookDeliveryStatus checks if a delivery status is known
+func IsValidWebhookDeliveryStatus(s WebhookDeliveryStatus) bool {
+	return s == WebhookDeliveryPending || s == WebhookDeliveryDelivered || s == WebhookDeliveryFailed
+}
+
+// WebhookDelivery is one outgoing webhook request, persisted so failed
+// deliveries can be retried and inspected in the delivery log
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	UserID         string                `json:"user_id"`
+	Channel        NotificationChannel   `json:"channel"` // webhook or teams
+	Event          NotificationType      `json:"event"`
+	URL            string                `json:"url"`
+	Payload        json.RawMessage       `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"` // HTTP status of the last attempt, 0 if none was received
+	LastError      string                `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// RecordSuccess marks the delivery as delivered after a successful attempt
+func (d *WebhookDelivery) RecordSuccess(statusCode int, at time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliveryDelivered
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	d.NextAttemptAt = nil
+	d.DeliveredAt = &at
+	d.UpdatedAt = at
+}
+
+// RecordFailure records a failed attempt. The delivery stays pending until
+// nextAttempt, or fails for good when nextAttempt is nil.
+func (d *WebhookDelivery) RecordFailure(statusCode int, errMsg string, at time.Time, nextAttempt *time.Time) {
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = errMsg
+	d.NextAttemptAt = nextAttempt
+	d.UpdatedAt = at
+	if nextAttempt == nil {
+		d.Status = WebhookDeliveryFailed
+	} else {
+		d.Status = WebhookDeliveryPending
+	}
+}
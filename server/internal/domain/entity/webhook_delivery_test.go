@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhookDelivery_RecordFailureThenSuccess(t *testing.T) {
+	d := &WebhookDelivery{Status: WebhookDeliveryPending}
+	now := time.Now()
+	next := now.Add(time.Minute)
+
+	d.RecordFailure(503, "webhook returned status 503", now, &next)
+	if d.Status != WebhookDeliveryPending || d.Attempts != 1 || d.LastStatusCode != 503 || d.NextAttemptAt == nil {
+		t.Errorf("Unexpected delivery after a retryable failure: %+v", d)
+	}
+
+	d.RecordSuccess(200, next)
+	if d.Status != WebhookDeliveryDelivered || d.Attempts != 2 || d.LastError != "" || d.NextAttemptAt != nil || d.DeliveredAt == nil {
+		t.Errorf("Unexpected delivery after success: %+v", d)
+	}
+}
+
+func TestWebhookDelivery_RecordFinalFailure(t *testing.T) {
+	d := &WebhookDelivery{Status: WebhookDeliveryPending, Attempts: 4}
+
+	d.RecordFailure(0, "connection refused", time.Now(), nil)
+	if d.Status != WebhookDeliveryFailed || d.Attempts != 5 || d.NextAttemptAt != nil {
+		t.Errorf("Expected a failed delivery, got %+v", d)
+	}
+}
+
+func TestIsValidWebhookDeliveryStatus(t *testing.T) {
+	for _, s := range []WebhookDeliveryStatus{WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryFailed} {
+		if !IsValidWebhookDeliveryStatus(s) {
+			t.Errorf("Expected %q to be valid", s)
+		}
+	}
+	if IsValidWebhookDeliveryStatus("retrying") {
+		t.Error("Expected unknown status to be invalid")
+	}
+}
@@ -43,6 +43,17 @@ type ScenarioRepository interface {
 	ImportFromYAML(ctx context.Context, path string) error
 }
 
+// TrashRepository defines the interface for the scenarios and techniques in
+// the trash, i.e. deleted but kept for the executions referencing them
+type TrashRepository interface {
+	FindDeletedScenarios(ctx context.Context) ([]*entity.Scenario, error)
+	FindDeletedTechniques(ctx context.Context) ([]*entity.Technique, error)
+	RestoreScenario(ctx context.Context, id string) (bool, error)
+	RestoreTechnique(ctx context.Context, id string) (bool, error)
+	PurgeScenarios(ctx context.Context, deletedBefore time.Time) (int64, error)
+	PurgeTechniques(ctx context.Context, deletedBefore time.Time) (int64, error)
+}
+
 // ResultRepository defines the interface for execution result persistence
 type ResultRepository interface {
 	CreateExecution(ctx context.Context, execution *entity.Execution) error
@@ -52,6 +63,7 @@ type ResultRepository interface {
 	FindRecentExecutions(ctx context.Context, limit int) ([]*entity.Execution, error)
 	FindExecutionsByDateRange(ctx context.Context, start, end time.Time) ([]*entity.Execution, error)
 	FindCompletedExecutionsByDateRange(ctx context.Context, start, end time.Time) ([]*entity.Execution, error)
+	FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error)
 
 	CreateResult(ctx context.Context, result *entity.ExecutionResult) error
 	UpdateResult(ctx context.Context, result *entity.ExecutionResult) error
@@ -60,6 +72,22 @@ type ResultRepository interface {
 	FindResultsByTechnique(ctx context.Context, techniqueID string) ([]*entity.ExecutionResult, error)
 }
 
+// RetentionRepository defines the interface for removing the execution data
+// kept past its retention. Only executions with a completion time expire.
+type RetentionRepository interface {
+	FindExpiredOutputs(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.ExecutionResult, error)
+	ClearOutputs(ctx context.Context, resultIDs []string) (int64, error)
+	FindBlobKeys(ctx context.Context, executionIDs []string) ([]string, error)
+	FindExpiredExecutions(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.Execution, error)
+	DeleteExecutions(ctx context.Context, executionIDs []string) (int64, error)
+}
+
+// SearchRepository defines the interface for the full-text search of
+// techniques, scenarios and results, returning the best hits first
+type SearchRepository interface {
+	Search(ctx context.Context, query string, types []entity.SearchType, limit int) ([]*entity.SearchHit, error)
+}
+
 // UserRepository defines the interface for user persistence
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
@@ -77,6 +105,29 @@ type UserRepository interface {
 	// DeactivateAdminIfNotLast atomically deactivates an admin user only if they are not the last active admin.
 	// Returns ErrLastAdmin if user is the last admin, ErrUserNotFound if user doesn't exist.
 	DeactivateAdminIfNotLast(ctx context.Context, id string) error
+
+	// Failed login tracking, FindLoginThrottle returning sql.ErrNoRows when there is none.
+	// AddLoginFailure counts a failure atomically, forgetting the failures last
+	// made before failuresSince and the lockouts before lockoutsSince. LockLogin
+	// locks out a throttle still counting at least failures, resetting them;
+	// it returns sql.ErrNoRows when a concurrent failure locked it first.
+	FindLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) (*entity.LoginThrottle, error)
+	AddLoginFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, at, failuresSince, lockoutsSince time.Time) (*entity.LoginThrottle, error)
+	LockLogin(ctx context.Context, scope entity.LoginThrottleScope, key string, failures int, until time.Time) (*entity.LoginThrottle, error)
+	DeleteLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) error
+	FindLockedLoginThrottles(ctx context.Context, at time.Time) ([]*entity.LoginThrottle, error)
+}
+
+// PasswordResetRepository defines the interface for password reset tokens.
+// FindByHash returns sql.ErrNoRows for an unknown token, and MarkUsed for a
+// token already used, so that each token resets a password once.
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *entity.PasswordResetToken) error
+	FindByHash(ctx context.Context, hash string) (*entity.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) error
+	InvalidateByUser(ctx context.Context, userID string, at time.Time) error // Marks the unused tokens of a user used
+	CountByUserSince(ctx context.Context, userID string, since time.Time) (int, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
 
 // NotificationRepository defines the interface for notification persistence
@@ -95,6 +146,31 @@ type NotificationRepository interface {
 	FindUnreadByUserID(ctx context.Context, userID string) ([]*entity.Notification, error)
 	MarkAsRead(ctx context.Context, id string) error
 	MarkAllAsRead(ctx context.Context, userID string) error
+	DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error)
+	DeleteReadNotifications(ctx context.Context, userID string) (int64, error)
+	PurgeReadNotifications(ctx context.Context, createdBefore time.Time) (int64, error)
+
+	// Digests
+	MarkDigestPending(ctx context.Context, id string) error
+	FindDigestPending(ctx context.Context, userID string) ([]*entity.Notification, error)
+	MarkDigestSent(ctx context.Context, ids []string, sentAt time.Time) error
+}
+
+// SMTPConfigRepository defines the interface for the SMTP configuration set
+// through the API. The password is stored as given, encrypted by the caller.
+type SMTPConfigRepository interface {
+	Get(ctx context.Context) (*entity.SMTPConfig, error) // nil when not set
+	Save(ctx context.Context, config *entity.SMTPConfig) error
+	Delete(ctx context.Context) error
+}
+
+// WebhookDeliveryRepository defines the interface for the webhook delivery queue and log
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *entity.WebhookDelivery) error
+	Update(ctx context.Context, delivery *entity.WebhookDelivery) error
+	FindByID(ctx context.Context, id string) (*entity.WebhookDelivery, error)
+	FindByUserID(ctx context.Context, userID string, status entity.WebhookDeliveryStatus, limit int) ([]*entity.WebhookDelivery, error)
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.WebhookDelivery, error)
 }
 
 // ScheduleRepository defines the interface for schedule persistence
@@ -107,9 +183,266 @@ type ScheduleRepository interface {
 	FindByStatus(ctx context.Context, status entity.ScheduleStatus) ([]*entity.Schedule, error)
 	FindActiveSchedulesDue(ctx context.Context, now time.Time) ([]*entity.Schedule, error)
 	FindByScenarioID(ctx context.Context, scenarioID string) ([]*entity.Schedule, error)
+	FindDependents(ctx context.Context, scheduleID string) ([]*entity.Schedule, error)
 
 	// Schedule runs
 	CreateRun(ctx context.Context, run *entity.ScheduleRun) error
 	UpdateRun(ctx context.Context, run *entity.ScheduleRun) error
 	FindRunsByScheduleID(ctx context.Context, scheduleID string, limit int) ([]*entity.ScheduleRun, error)
+	FindRunByExecutionID(ctx context.Context, executionID string) (*entity.ScheduleRun, error)
+}
+
+// ActivityRepository defines the interface for operator login history and activity anomalies
+type ActivityRepository interface {
+	CreateLogin(ctx context.Context, record *entity.LoginRecord) error
+	FindLoginsByUser(ctx context.Context, userID string, limit int) ([]*entity.LoginRecord, error)
+	CreateAnomaly(ctx context.Context, anomaly *entity.ActivityAnomaly) error
+	FindAnomalies(ctx context.Context, limit int) ([]*entity.ActivityAnomaly, error)
+}
+
+// ScoreHistoryRepository defines the interface for scores overwritten by recomputation backfills
+type ScoreHistoryRepository interface {
+	Create(ctx context.Context, recomputation *entity.ScoreRecomputation) error
+	FindByExecution(ctx context.Context, executionID string) ([]*entity.ScoreRecomputation, error)
+}
+
+// FactRepository defines the interface for the facts of executions
+type FactRepository interface {
+	Create(ctx context.Context, fact *entity.Fact) error
+	FindByExecution(ctx context.Context, executionID string) ([]*entity.Fact, error)
+}
+
+// AgentSelectorRepository defines the interface for saved agent selector persistence
+type AgentSelectorRepository interface {
+	Create(ctx context.Context, selector *entity.AgentSelector) error
+	Update(ctx context.Context, selector *entity.AgentSelector) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*entity.AgentSelector, error)
+	FindAll(ctx context.Context) ([]*entity.AgentSelector, error)
+}
+
+// ScoringProfileRepository defines the interface for versioned scoring profile
+// persistence. Versions are never modified nor removed: deleting a profile
+// hides it from listings, its versions still score the executions pinned to them.
+type ScoringProfileRepository interface {
+	Create(ctx context.Context, profile *entity.ScoringProfile) error     // Saves the profile as its version 1
+	AddVersion(ctx context.Context, profile *entity.ScoringProfile) error // Saves profile.Version as the current version
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*entity.ScoringProfile, error) // Current version
+	FindVersion(ctx context.Context, id string, version int) (*entity.ScoringProfile, error)
+	FindVersions(ctx context.Context, id string) ([]*entity.ScoringProfile, error) // Oldest first
+	FindAll(ctx context.Context) ([]*entity.ScoringProfile, error)                 // Current versions
+	FindDefault(ctx context.Context) (*entity.ScoringProfile, error)               // Nil without default profile
+	SetDefault(ctx context.Context, id string) error
+}
+
+// TicketRepository defines the interface for the tracker issues opened for undetected techniques
+type TicketRepository interface {
+	Create(ctx context.Context, ticket *entity.Ticket) error
+	Update(ctx context.Context, ticket *entity.Ticket) error
+	FindByID(ctx context.Context, id string) (*entity.Ticket, error)
+	FindOpen(ctx context.Context, techniqueID, agentPaw string) (*entity.Ticket, error) // Nil without open ticket
+	FindAll(ctx context.Context, status entity.TicketStatus, limit int) ([]*entity.Ticket, error)
+}
+
+// PayloadRepository defines the interface for payload persistence. Listing and
+// lookups return the payload metadata; Content loads the file itself.
+type PayloadRepository interface {
+	Create(ctx context.Context, payload *entity.Payload, content []byte) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*entity.Payload, error)
+	FindByName(ctx context.Context, name string) (*entity.Payload, error)
+	FindAll(ctx context.Context) ([]*entity.Payload, error)
+	Content(ctx context.Context, id string) ([]byte, error)
+}
+
+// ArtifactRepository defines the interface for the files agents collect with
+// results. Lookups return the artifact metadata; Content loads the file itself.
+type ArtifactRepository interface {
+	Create(ctx context.Context, artifact *entity.Artifact, content []byte) error
+	FindByID(ctx context.Context, id string) (*entity.Artifact, error)
+	FindByResult(ctx context.Context, resultID string) ([]*entity.Artifact, error)
+	Content(ctx context.Context, id string) ([]byte, error)
+	TotalSizeByResult(ctx context.Context, resultID string) (int64, error)
+	StorageKeysCreatedBefore(ctx context.Context, before time.Time) ([]string, error)
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// APIKeyRepository defines the interface for user API keys. FindByHash returns
+// sql.ErrNoRows for an unknown key.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	FindByID(ctx context.Context, id string) (*entity.APIKey, error)
+	FindByHash(ctx context.Context, hash string) (*entity.APIKey, error)
+	FindByUser(ctx context.Context, userID string) ([]*entity.APIKey, error) // Newest first
+	Delete(ctx context.Context, id string) error
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
+
+// SecretRepository defines the interface for the secrets store. Save inserts
+// or replaces a secret; FindByName returns sql.ErrNoRows for an unknown name.
+type SecretRepository interface {
+	Save(ctx context.Context, secret *entity.Secret) error
+	FindByName(ctx context.Context, name string) (*entity.Secret, error)
+	FindAll(ctx context.Context) ([]*entity.Secret, error) // By name
+	Delete(ctx context.Context, name string) error
+}
+
+// ExecutionReviewRepository defines the interface for the purple-team reviews
+// of executions, one per execution. Get returns nil for an execution never
+// submitted for review; lookups other than Get leave the snapshot out.
+type ExecutionReviewRepository interface {
+	Get(ctx context.Context, executionID string) (*entity.ExecutionReview, error)
+	Save(ctx context.Context, review *entity.ExecutionReview) error
+	FindAll(ctx context.Context, status entity.ReviewStatus, reviewerID string, limit int) ([]*entity.ExecutionReview, error) // Newest first, empty filters match all
+}
+
+// ExecutionSnapshotRepository defines the interface for the environment
+// snapshots of executions, one per execution. Get returns nil for an execution
+// started without a snapshot.
+type ExecutionSnapshotRepository interface {
+	Save(ctx context.Context, snapshot *entity.ExecutionSnapshot) error
+	Get(ctx context.Context, executionID string) (*entity.ExecutionSnapshot, error)
+}
+
+// EvidenceRepository defines the interface for the files operators attach to
+// results. Lookups return the evidence metadata; Content loads the file itself.
+type EvidenceRepository interface {
+	Create(ctx context.Context, evidence *entity.Evidence, content []byte) error
+	FindByID(ctx context.Context, id string) (*entity.Evidence, error)
+	FindByResult(ctx context.Context, resultID string) ([]*entity.Evidence, error)
+	Content(ctx context.Context, id string) ([]byte, error)
+	TotalSizeByResult(ctx context.Context, resultID string) (int64, error)
+}
+
+// ResultAnnotationRepository defines the interface for the analyst triage and
+// comments of results. Get returns nil for a result never annotated.
+type ResultAnnotationRepository interface {
+	Get(ctx context.Context, resultID string) (*entity.ResultAnnotation, error)
+	FindByExecution(ctx context.Context, executionID string) ([]*entity.ResultAnnotation, error)
+	Save(ctx context.Context, annotation *entity.ResultAnnotation) error // Saves the triage and assignee, not the comments
+	AddComment(ctx context.Context, comment *entity.ResultComment) error
+	FindComment(ctx context.Context, id string) (*entity.ResultComment, error)
+	DeleteComment(ctx context.Context, id string) error
+}
+
+// AdHocTaskRepository defines the interface for the audit trail of ad-hoc agent commands
+type AdHocTaskRepository interface {
+	Create(ctx context.Context, task *entity.AdHocTask) error
+	Update(ctx context.Context, task *entity.AdHocTask) error
+	FindByID(ctx context.Context, id string) (*entity.AdHocTask, error)
+	FindByAgent(ctx context.Context, paw string, limit int) ([]*entity.AdHocTask, error)
+}
+
+// AgentReleaseRepository defines the interface for signed agent binaries.
+// Lookups return the release metadata; Content loads the binary itself.
+type AgentReleaseRepository interface {
+	Create(ctx context.Context, release *entity.AgentRelease, content []byte) error
+	UpdateRollout(ctx context.Context, id string, percent int) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*entity.AgentRelease, error)
+	FindByVersion(ctx context.Context, version, platform string) (*entity.AgentRelease, error)
+	FindAll(ctx context.Context) ([]*entity.AgentRelease, error)
+	FindByPlatform(ctx context.Context, platform string) ([]*entity.AgentRelease, error)
+	Content(ctx context.Context, id string) ([]byte, error)
+}
+
+// BeaconOverrideRepository defines the interface for the beacon settings of
+// agents and agent selectors, one per scope and target
+type BeaconOverrideRepository interface {
+	Upsert(ctx context.Context, override *entity.BeaconOverride) error
+	Delete(ctx context.Context, scope entity.BeaconScope, target string) error
+	Find(ctx context.Context, scope entity.BeaconScope, target string) (*entity.BeaconOverride, error)
+	FindAll(ctx context.Context) ([]*entity.BeaconOverride, error)
+}
+
+// TaskQueueRepository defines the interface for the tasks waiting for offline
+// agents, one per result. Delete reports whether the task was still queued, so
+// concurrent check-ins deliver a task once.
+type TaskQueueRepository interface {
+	Enqueue(ctx context.Context, task *entity.QueuedTask) error
+	Delete(ctx context.Context, resultID string) (bool, error)
+	DeleteByExecution(ctx context.Context, executionID string) error
+	FindByAgent(ctx context.Context, paw string) ([]*entity.QueuedTask, error)
+	FindExpired(ctx context.Context, now time.Time) ([]*entity.QueuedTask, error)
+}
+
+// EmergencyStopRepository defines the interface for emergency stop trips.
+// Save inserts or replaces a trip; FindEngaged returns nil when every trip
+// was re-armed.
+type EmergencyStopRepository interface {
+	Save(ctx context.Context, stop *entity.EmergencyStop) error
+	FindEngaged(ctx context.Context) (*entity.EmergencyStop, error)
+	FindRecent(ctx context.Context, limit int) ([]*entity.EmergencyStop, error)
+}
+
+// JobRepository defines the interface for background jobs. FindRecent filters
+// by type and creator when they are set, newest first. FailUnfinished fails
+// the jobs left pending or running, e.g. by a server restart.
+type JobRepository interface {
+	Create(ctx context.Context, job *entity.Job) error
+	Update(ctx context.Context, job *entity.Job) error
+	FindByID(ctx context.Context, id string) (*entity.Job, error)
+	FindRecent(ctx context.Context, jobType entity.JobType, createdBy string, limit int) ([]*entity.Job, error)
+	FailUnfinished(ctx context.Context, reason string, at time.Time) (int64, error)
+}
+
+// ResultDurationRepository defines the interface for the durations of past
+// technique runs, used to estimate how long an execution has left
+type ResultDurationRepository interface {
+	FindRecentDurations(ctx context.Context, techniqueIDs []string, limit int) (map[string][]time.Duration, error)
+}
+
+// ExecutionSummaryRepository defines the interface for listing executions
+// without a query per execution. FindRecentExecutionsWithSummary joins the
+// recent executions with the counts of their results by status;
+// FindResultsByExecutions loads the results of several executions at once,
+// ordered by execution.
+type ExecutionSummaryRepository interface {
+	FindRecentExecutionsWithSummary(ctx context.Context, limit int) ([]*entity.Execution, error)
+	FindResultsByExecutions(ctx context.Context, executionIDs []string) ([]*entity.ExecutionResult, error)
+}
+
+// AgentResultRepository defines the interface for finding the agents that
+// have execution results, which keep them from being deleted
+type AgentResultRepository interface {
+	FindPawsWithResults(ctx context.Context, paws []string) (map[string]bool, error)
+}
+
+// ResultSubmissionRepository defines the interface for the idempotency keys of
+// the results agents submit. ClaimSubmission records the key of an agent,
+// reporting false when the agent already submitted it; ClaimSubmissions does
+// the same for a batch, by submission ID to result ID, reporting the keys
+// claimed. ReleaseSubmission forgets a key whose submission could not be processed.
+type ResultSubmissionRepository interface {
+	ClaimSubmission(ctx context.Context, paw, submissionID, resultID string, receivedAt time.Time) (bool, error)
+	ClaimSubmissions(ctx context.Context, paw string, submissions map[string]string, receivedAt time.Time) (map[string]bool, error)
+	ReleaseSubmission(ctx context.Context, paw, submissionID string) error
+	DeleteSubmissionsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ResultBatchRepository defines the interface for updating the reported
+// results of a batch in a single transaction. It returns the error of each
+// result, entity.ErrResultTransition for a result that would move backward,
+// or an error failing the whole batch.
+type ResultBatchRepository interface {
+	UpdateResults(ctx context.Context, results []*entity.ExecutionResult) ([]error, error)
+}
+
+// AgentAvailabilityRepository defines the interface for the availability
+// history of agents. FindByAgent returns the events of an agent since a time,
+// oldest first.
+type AgentAvailabilityRepository interface {
+	Create(ctx context.Context, event *entity.AgentAvailabilityEvent) error
+	FindByAgent(ctx context.Context, paw string, since time.Time) ([]*entity.AgentAvailabilityEvent, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AgentBeaconRepository defines the interface for the check-in history of
+// agents. FindByAgent returns the check-ins of an agent since a time, oldest
+// first.
+type AgentBeaconRepository interface {
+	Create(ctx context.Context, beacon *entity.AgentBeacon) error
+	FindByAgent(ctx context.Context, paw string, since time.Time) ([]*entity.AgentBeacon, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
 }
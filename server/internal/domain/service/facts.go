@@ -0,0 +1,188 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+)
+
+// factReference matches a fact reference in a command, e.g. #{fact.hostname}
+var factReference = regexp.MustCompile(`#\{fact\.([A-Za-z0-9_.-]+)\}`)
+
+// jsonPathSegment matches a JSON path segment: a key with optional indices, e.g. addresses[0]
+var jsonPathSegment = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+
+// ReferencesFacts reports whether a command references facts
+func ReferencesFacts(command string) bool {
+	return factReference.MatchString(command)
+}
+
+// ReferencedFacts returns the names of the facts a command references, once each
+func ReferencedFacts(command string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range factReference.FindAllStringSubmatch(command, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// SubstituteFacts replaces the fact references of a command with their values.
+// Returns the command and the names of the referenced facts without a value.
+func SubstituteFacts(command string, facts map[string]string) (string, []string) {
+	var missing []string
+	seen := make(map[string]bool)
+	substituted := factReference.ReplaceAllStringFunc(command, func(ref string) string {
+		name := factReference.FindStringSubmatch(ref)[1]
+		if value, ok := facts[name]; ok {
+			return value
+		}
+		if !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return ref
+	})
+	return substituted, missing
+}
+
+// ValidateFactParser checks that a parser names its fact and has exactly one
+// valid extractor
+func ValidateFactParser(parser entity.FactParser) error {
+	if parser.Fact == "" {
+		return fmt.Errorf("fact name is required")
+	}
+	if !factReference.MatchString("#{fact." + parser.Fact + "}") {
+		return fmt.Errorf("invalid fact name %q", parser.Fact)
+	}
+	switch {
+	case parser.Regex != "" && parser.JSONPath != "":
+		return fmt.Errorf("fact %s: set either regex or json_path", parser.Fact)
+	case parser.Regex != "":
+		if _, err := regexp.Compile(parser.Regex); err != nil {
+			return fmt.Errorf("fact %s: invalid regex: %w", parser.Fact, err)
+		}
+	case parser.JSONPath != "":
+		if _, err := parseJSONPath(parser.JSONPath); err != nil {
+			return fmt.Errorf("fact %s: %w", parser.Fact, err)
+		}
+	default:
+		return fmt.Errorf("fact %s: regex or json_path is required", parser.Fact)
+	}
+	return nil
+}
+
+// ExtractFacts runs the parsers on a command output and returns the facts found,
+// by name. Parsers that do not match, or are invalid, yield no fact.
+func ExtractFacts(parsers []entity.FactParser, output string) map[string]string {
+	facts := make(map[string]string)
+	for _, parser := range parsers {
+		if ValidateFactParser(parser) != nil {
+			continue
+		}
+		var value string
+		var ok bool
+		if parser.Regex != "" {
+			value, ok = extractRegex(parser.Regex, output)
+		} else {
+			value, ok = extractJSONPath(parser.JSONPath, output)
+		}
+		if ok {
+			facts[parser.Fact] = value
+		}
+	}
+	return facts
+}
+
+// extractRegex returns the first capture group of the first match, or the whole match
+func extractRegex(pattern, output string) (string, bool) {
+	match := regexp.MustCompile(pattern).FindStringSubmatch(output)
+	switch {
+	case match == nil:
+		return "", false
+	case len(match) > 1:
+		return strings.TrimSpace(match[1]), true
+	default:
+		return strings.TrimSpace(match[0]), true
+	}
+}
+
+// extractJSONPath returns the value at a JSON path of a JSON output. Strings
+// are returned as-is, other values as JSON.
+func extractJSONPath(path, output string) (string, bool) {
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return "", false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &value); err != nil {
+		return "", false
+	}
+	for _, step := range steps {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			next, ok := node[step.key]
+			if step.index >= 0 || !ok {
+				return "", false
+			}
+			value = next
+		case []interface{}:
+			if step.index < 0 || step.index >= len(node) {
+				return "", false
+			}
+			value = node[step.index]
+		default:
+			return "", false
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+// jsonPathStep is a key (index -1) or an array index of a JSON path
+type jsonPathStep struct {
+	key   string
+	index int
+}
+
+// parseJSONPath parses a dotted JSON path with array indices, e.g. $.hosts[0].name
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("invalid json_path %q", path)
+	}
+
+	var steps []jsonPathStep
+	for _, segment := range strings.Split(trimmed, ".") {
+		match := jsonPathSegment.FindStringSubmatch(segment)
+		if match == nil || (match[1] == "" && match[2] == "") {
+			return nil, fmt.Errorf("invalid json_path %q", path)
+		}
+		if match[1] != "" {
+			steps = append(steps, jsonPathStep{key: match[1], index: -1})
+		}
+		for _, idx := range strings.Split(strings.Trim(match[2], "[]"), "][") {
+			if idx == "" {
+				continue
+			}
+			n, _ := strconv.Atoi(idx)
+			steps = append(steps, jsonPathStep{index: n})
+		}
+	}
+	return steps, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestReferencedFacts(t *testing.T) {
+	got := ReferencedFacts("ssh #{fact.user}@#{fact.host} -p #{fact.port} #{fact.host}")
+	want := []string{"user", "host", "port"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReferencedFacts() = %v, want %v", got, want)
+	}
+	if ReferencesFacts("echo #{fact}") || !ReferencesFacts("echo #{fact.a.b}") {
+		t.Error("Unexpected ReferencesFacts result")
+	}
+}
+
+func TestSubstituteFacts(t *testing.T) {
+	command, missing := SubstituteFacts("ssh #{fact.user}@#{fact.host} #{fact.key} #{fact.key}",
+		map[string]string{"user": "root", "host": "10.0.0.5"})
+
+	if command != "ssh root@10.0.0.5 #{fact.key} #{fact.key}" {
+		t.Errorf("Unexpected command: %s", command)
+	}
+	if !reflect.DeepEqual(missing, []string{"key"}) {
+		t.Errorf("Expected key to be missing, got %v", missing)
+	}
+}
+
+func TestValidateFactParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		parser  entity.FactParser
+		wantErr bool
+	}{
+		{"regex", entity.FactParser{Fact: "hostname", Regex: `^(\S+)`}, false},
+		{"json path", entity.FactParser{Fact: "host.ip", JSONPath: "$.addresses[0].ip"}, false},
+		{"missing name", entity.FactParser{Regex: ".*"}, true},
+		{"invalid name", entity.FactParser{Fact: "host name", Regex: ".*"}, true},
+		{"no extractor", entity.FactParser{Fact: "hostname"}, true},
+		{"both extractors", entity.FactParser{Fact: "hostname", Regex: ".*", JSONPath: "$.name"}, true},
+		{"invalid regex", entity.FactParser{Fact: "hostname", Regex: "("}, true},
+		{"invalid json path", entity.FactParser{Fact: "hostname", JSONPath: "$.a[x]"}, true},
+		{"empty json path", entity.FactParser{Fact: "hostname", JSONPath: "$"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateFactParser(tt.parser); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFactParser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractFacts_Regex(t *testing.T) {
+	parsers := []entity.FactParser{
+		{Fact: "user", Regex: `uid=\d+\((\w+)\)`},
+		{Fact: "line", Regex: `gid=\S+`},
+		{Fact: "absent", Regex: `shell=(\S+)`},
+		{Fact: "invalid", Regex: "("},
+	}
+	facts := ExtractFacts(parsers, "uid=0(root) gid=0(root) groups=0(root)\n")
+
+	want := map[string]string{"user": "root", "line": "gid=0(root)"}
+	if !reflect.DeepEqual(facts, want) {
+		t.Errorf("ExtractFacts() = %v, want %v", facts, want)
+	}
+}
+
+func TestExtractFacts_JSONPath(t *testing.T) {
+	output := `{"host": {"name": "web-01", "addresses": [{"ip": "10.0.0.5"}], "port": 22, "tags": ["a"]}}`
+	parsers := []entity.FactParser{
+		{Fact: "name", JSONPath: "$.host.name"},
+		{Fact: "ip", JSONPath: "$.host.addresses[0].ip"},
+		{Fact: "port", JSONPath: "host.port"},
+		{Fact: "tags", JSONPath: "$.host.tags"},
+		{Fact: "out_of_range", JSONPath: "$.host.addresses[3].ip"},
+		{Fact: "absent", JSONPath: "$.host.os"},
+	}
+	facts := ExtractFacts(parsers, output)
+
+	want := map[string]string{"name": "web-01", "ip": "10.0.0.5", "port": "22", "tags": `["a"]`}
+	if !reflect.DeepEqual(facts, want) {
+		t.Errorf("ExtractFacts() = %v, want %v", facts, want)
+	}
+
+	if facts := ExtractFacts(parsers, "not json"); len(facts) != 0 {
+		t.Errorf("Expected no fact from non-JSON output, got %v", facts)
+	}
+}
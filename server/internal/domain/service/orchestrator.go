@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"autostrike/internal/domain/entity"
 	"autostrike/internal/domain/repository"
@@ -49,12 +50,23 @@ type PlannedTask struct {
 	AgentPaw    string
 	Phase       string
 	Order       int
+	Executor    string // Executor type the command runs with
 	Command     string
 	Cleanup     string
 	Timeout     int
+	Limits      *entity.ResourceLimits
+	Payload     string   // Name of the payload the command needs, if any
+	Collect     []string // Files the agent uploads as artifacts of the result
+
+	// Status and output of a task recorded without running, e.g. on an agent
+	// lacking the elevation its executors require
+	SkipStatus entity.ResultStatus
+	SkipReason string
 }
 
-// PlanExecution creates an execution plan for a scenario
+// PlanExecution creates an execution plan for a scenario. Deferred phases are
+// left out: they are planned with PlanPhase once the results they depend on are
+// known.
 func (o *AttackOrchestrator) PlanExecution(
 	ctx context.Context,
 	scenario *entity.Scenario,
@@ -67,19 +79,83 @@ func (o *AttackOrchestrator) PlanExecution(
 	}
 
 	taskOrder := 0
+	deferred := false
 	for _, phase := range scenario.Phases {
+		if o.DefersPhase(ctx, phase) {
+			deferred = true
+			continue
+		}
 		tasks := o.planPhase(ctx, phase, targetAgents, safeMode, taskOrder)
 		plan.Tasks = append(plan.Tasks, tasks...)
 		taskOrder += len(tasks)
 	}
 
-	if len(plan.Tasks) == 0 {
+	// Deferred phases are planned during the execution
+	if len(plan.Tasks) == 0 && !deferred {
 		return nil, fmt.Errorf("no executable tasks for the given scenario and agents")
 	}
 
 	return plan, nil
 }
 
+// DefersPhase reports whether a phase depends on earlier results: it has run_if
+// conditions, or one of its techniques references facts
+func (o *AttackOrchestrator) DefersPhase(ctx context.Context, phase entity.Phase) bool {
+	if phase.IsConditional() {
+		return true
+	}
+	for _, techID := range phase.Techniques {
+		technique, err := o.techniqueRepo.FindByID(ctx, techID)
+		if err != nil || technique == nil {
+			continue
+		}
+		for _, executor := range technique.Executors {
+			if slices.ContainsFunc(executor.Commands(), ReferencesFacts) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PlanPhase creates the tasks of a single phase for an agent
+func (o *AttackOrchestrator) PlanPhase(
+	ctx context.Context,
+	phase entity.Phase,
+	agent *entity.Agent,
+	safeMode bool,
+) []PlannedTask {
+	return o.planPhase(ctx, phase, []*entity.Agent{agent}, safeMode, 0)
+}
+
+// PhaseConditionsMet evaluates the run_if conditions of a phase against the
+// results of an agent. A phase without conditions always runs.
+func (o *AttackOrchestrator) PhaseConditionsMet(phase entity.Phase, results []*entity.ExecutionResult) bool {
+	for _, cond := range phase.RunIf {
+		if !conditionHolds(cond, results) {
+			o.logger.Debug("Phase condition not met",
+				zap.String("phase", phase.Name),
+				zap.String("technique_id", cond.Technique),
+				zap.String("status", string(cond.ExpectedStatus())))
+			return false
+		}
+	}
+	return true
+}
+
+// conditionHolds reports whether one of the results satisfies the condition
+func conditionHolds(cond entity.PhaseCondition, results []*entity.ExecutionResult) bool {
+	for _, r := range results {
+		if r.TechniqueID != cond.Technique || (cond.Phase != "" && r.Phase != cond.Phase) {
+			continue
+		}
+		if r.Status == cond.ExpectedStatus() {
+			return true
+		}
+	}
+	return false
+}
+
 // planPhase creates tasks for a single phase
 func (o *AttackOrchestrator) planPhase(
 	ctx context.Context,
@@ -98,7 +174,7 @@ func (o *AttackOrchestrator) planPhase(
 		}
 
 		for _, agent := range targetAgents {
-			task := o.createTaskForAgent(agent, technique, phase.Name, taskOrder)
+			task := o.createTaskForAgent(agent, technique, phase, taskOrder)
 			if task != nil {
 				tasks = append(tasks, *task)
 				taskOrder++
@@ -125,18 +201,51 @@ func (o *AttackOrchestrator) getTechnique(ctx context.Context, techID string, sa
 	return technique
 }
 
-// createTaskForAgent creates a task if the agent is compatible
+// ReplanTask plans a technique again for an agent, e.g. to re-dispatch an
+// unanswered task of a resumed execution, trying the preferred executor types
+// first. Returns nil when the technique is no longer available, allowed in safe
+// mode, or compatible with the agent, and a skipped task when the agent is no
+// longer elevated.
+func (o *AttackOrchestrator) ReplanTask(
+	ctx context.Context,
+	techniqueID string,
+	agent *entity.Agent,
+	safeMode bool,
+	preferred []string,
+) *PlannedTask {
+	technique := o.getTechnique(ctx, techniqueID, safeMode)
+	if technique == nil {
+		return nil
+	}
+	return o.createTaskForAgent(agent, technique, entity.Phase{Executors: preferred}, 0)
+}
+
+// createTaskForAgent creates a task if the agent is compatible, with the
+// executor the phase prefers among the ones the agent supports. A technique
+// whose executors all require elevation the agent lacks is planned skipped.
 func (o *AttackOrchestrator) createTaskForAgent(
 	agent *entity.Agent,
 	technique *entity.Technique,
-	phaseName string,
+	phase entity.Phase,
 	order int,
 ) *PlannedTask {
 	if !agent.IsCompatible(technique) {
 		return nil
 	}
 
-	executor := technique.GetExecutorForPlatform(agent.Platform, agent.Executors)
+	executor, needsElevation := technique.ExecutorForAgent(agent, phase.Executors)
+	if needsElevation {
+		o.logger.Info("Skipping technique requiring elevation on a non-elevated agent",
+			zap.String("technique_id", technique.ID), zap.String("agent_paw", agent.Paw))
+		return &PlannedTask{
+			TechniqueID: technique.ID,
+			AgentPaw:    agent.Paw,
+			Phase:       phase.Name,
+			Order:       order,
+			SkipStatus:  entity.StatusSkippedInsufficientPrivilege,
+			SkipReason:  "technique requires an elevated agent",
+		}
+	}
 	if executor == nil {
 		return nil
 	}
@@ -144,11 +253,15 @@ func (o *AttackOrchestrator) createTaskForAgent(
 	return &PlannedTask{
 		TechniqueID: technique.ID,
 		AgentPaw:    agent.Paw,
-		Phase:       phaseName,
+		Phase:       phase.Name,
 		Order:       order,
+		Executor:    executor.Type,
 		Command:     executor.Command,
 		Cleanup:     executor.Cleanup,
 		Timeout:     executor.Timeout,
+		Limits:      executor.Limits,
+		Payload:     executor.Payload,
+		Collect:     executor.Collect,
 	}
 }
 
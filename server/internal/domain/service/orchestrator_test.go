@@ -140,6 +140,128 @@ func TestAttackOrchestrator_PlanExecution(t *testing.T) {
 	}
 }
 
+func TestAttackOrchestrator_PlanExecution_DefersConditionalPhases(t *testing.T) {
+	techRepo := &mockTechniqueRepo{
+		techniques: map[string]*entity.Technique{
+			"T1003": {ID: "T1003", Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "dump"}}},
+			"T1021": {ID: "T1021", Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "ssh"}}},
+		},
+	}
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, techRepo, NewTechniqueValidator(), nil)
+	agent := &entity.Agent{Paw: "paw1", Platform: "linux", Executors: []string{"sh"}, Status: entity.AgentOnline}
+
+	lateral := entity.Phase{
+		Name:       "Lateral Movement",
+		Techniques: []string{"T1021"},
+		RunIf:      []entity.PhaseCondition{{Technique: "T1003"}},
+	}
+	scenario := &entity.Scenario{
+		Phases: []entity.Phase{{Name: "Credential Access", Techniques: []string{"T1003"}}, lateral},
+	}
+
+	plan, err := orchestrator.PlanExecution(context.Background(), scenario, []*entity.Agent{agent}, false)
+	if err != nil {
+		t.Fatalf("PlanExecution returned error: %v", err)
+	}
+	if len(plan.Tasks) != 1 || plan.Tasks[0].TechniqueID != "T1003" {
+		t.Fatalf("Expected only the unconditional phase to be planned, got %+v", plan.Tasks)
+	}
+
+	tasks := orchestrator.PlanPhase(context.Background(), lateral, agent, false)
+	if len(tasks) != 1 || tasks[0].TechniqueID != "T1021" || tasks[0].Phase != "Lateral Movement" {
+		t.Errorf("Unexpected phase tasks: %+v", tasks)
+	}
+}
+
+func TestAttackOrchestrator_DefersPhase(t *testing.T) {
+	techRepo := &mockTechniqueRepo{
+		techniques: map[string]*entity.Technique{
+			"T1082": {ID: "T1082", Executors: []entity.Executor{{Type: "sh", Command: "hostname"}}},
+			"T1021": {ID: "T1021", Executors: []entity.Executor{{Type: "sh", Command: "ssh #{fact.hostname}"}}},
+			"T1070": {ID: "T1070", Executors: []entity.Executor{{Type: "sh", Command: "touch x", Cleanup: "rm #{fact.path}"}}},
+		},
+	}
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, techRepo, NewTechniqueValidator(), nil)
+
+	tests := []struct {
+		name  string
+		phase entity.Phase
+		want  bool
+	}{
+		{"plain phase", entity.Phase{Techniques: []string{"T1082"}}, false},
+		{"conditional phase", entity.Phase{Techniques: []string{"T1082"}, RunIf: []entity.PhaseCondition{{Technique: "T1003"}}}, true},
+		{"fact in command", entity.Phase{Techniques: []string{"T1082", "T1021"}}, true},
+		{"fact in cleanup", entity.Phase{Techniques: []string{"T1070"}}, true},
+		{"unknown technique", entity.Phase{Techniques: []string{"T9999"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orchestrator.DefersPhase(context.Background(), tt.phase); got != tt.want {
+				t.Errorf("DefersPhase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttackOrchestrator_PhaseConditionsMet(t *testing.T) {
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, &mockTechniqueRepo{}, NewTechniqueValidator(), nil)
+	results := []*entity.ExecutionResult{
+		{TechniqueID: "T1003", Phase: "Credential Access", Status: entity.StatusSuccess},
+		{TechniqueID: "T1082", Phase: "Discovery", Status: entity.StatusBlocked},
+	}
+
+	tests := []struct {
+		name  string
+		runIf []entity.PhaseCondition
+		want  bool
+	}{
+		{"no conditions", nil, true},
+		{"succeeded technique", []entity.PhaseCondition{{Technique: "T1003"}}, true},
+		{"status mismatch", []entity.PhaseCondition{{Technique: "T1082"}}, false},
+		{"explicit status", []entity.PhaseCondition{{Technique: "T1082", Status: entity.StatusBlocked}}, true},
+		{"phase mismatch", []entity.PhaseCondition{{Technique: "T1003", Phase: "Discovery"}}, false},
+		{"all conditions must hold", []entity.PhaseCondition{{Technique: "T1003"}, {Technique: "T1082"}}, false},
+		{"missing technique", []entity.PhaseCondition{{Technique: "T1021"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phase := entity.Phase{Name: "Lateral Movement", RunIf: tt.runIf}
+			if got := orchestrator.PhaseConditionsMet(phase, results); got != tt.want {
+				t.Errorf("PhaseConditionsMet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttackOrchestrator_PlanExecution_ResourceLimits(t *testing.T) {
+	limits := &entity.ResourceLimits{CPUPercent: 50, MemoryMB: 256}
+	technique := &entity.Technique{
+		ID:        "T1059",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{
+			{Type: "sh", Command: "id", Timeout: 30, Limits: limits},
+		},
+		IsSafe: true,
+	}
+	techRepo := &mockTechniqueRepo{
+		techniques: map[string]*entity.Technique{"T1059": technique},
+	}
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, techRepo, NewTechniqueValidator(), nil)
+
+	agent := &entity.Agent{Paw: "a1", Platform: "linux", Executors: []string{"sh"}, Status: entity.AgentOnline}
+	scenario := &entity.Scenario{Phases: []entity.Phase{{Name: "Phase1", Techniques: []string{"T1059"}}}}
+
+	plan, err := orchestrator.PlanExecution(context.Background(), scenario, []*entity.Agent{agent}, false)
+	if err != nil {
+		t.Fatalf("PlanExecution returned error: %v", err)
+	}
+	if plan.Tasks[0].Limits != limits {
+		t.Errorf("Expected executor limits on the task, got %+v", plan.Tasks[0].Limits)
+	}
+}
+
 func TestAttackOrchestrator_PlanExecution_SafeMode(t *testing.T) {
 	safeTech := &entity.Technique{
 		ID:        "T1082",
@@ -444,6 +566,42 @@ func TestAttackOrchestrator_ValidatePlan_IncompatibleAgent(t *testing.T) {
 	}
 }
 
+func TestAttackOrchestrator_PlanExecution_PhaseExecutors(t *testing.T) {
+	techRepo := &mockTechniqueRepo{
+		techniques: map[string]*entity.Technique{
+			"T1059": {ID: "T1059", Platforms: []string{"windows"}, IsSafe: true, Executors: []entity.Executor{
+				{Type: "cmd", Command: "whoami"},
+				{Type: "psh", Command: "Get-LocalUser", Timeout: 30},
+			}},
+		},
+	}
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, techRepo, NewTechniqueValidator(), nil)
+	withPowerShell := &entity.Agent{Paw: "paw1", Platform: "windows", Executors: []string{"cmd", "psh"}, Status: entity.AgentOnline}
+	cmdOnly := &entity.Agent{Paw: "paw2", Platform: "windows", Executors: []string{"cmd"}, Status: entity.AgentOnline}
+
+	scenario := &entity.Scenario{
+		Phases: []entity.Phase{{Name: "Execution", Techniques: []string{"T1059"}, Executors: []string{"psh", "cmd"}}},
+	}
+	plan, err := orchestrator.PlanExecution(context.Background(), scenario, []*entity.Agent{withPowerShell, cmdOnly}, true)
+	if err != nil {
+		t.Fatalf("PlanExecution returned error: %v", err)
+	}
+	if len(plan.Tasks) != 2 {
+		t.Fatalf("Expected a task per agent, got %+v", plan.Tasks)
+	}
+	if plan.Tasks[0].Executor != "psh" || plan.Tasks[0].Command != "Get-LocalUser" || plan.Tasks[0].Timeout != 30 {
+		t.Errorf("Expected the preferred executor on the agent having it, got %+v", plan.Tasks[0])
+	}
+	if plan.Tasks[1].Executor != "cmd" || plan.Tasks[1].Command != "whoami" {
+		t.Errorf("Expected the fallback executor on the agent without it, got %+v", plan.Tasks[1])
+	}
+
+	task := orchestrator.ReplanTask(context.Background(), "T1059", withPowerShell, true, []string{"psh"})
+	if task == nil || task.Executor != "psh" {
+		t.Errorf("Expected the task to be planned again with its executor, got %+v", task)
+	}
+}
+
 func TestAttackOrchestrator_PlanExecution_NoCompatibleExecutor(t *testing.T) {
 	// Technique is compatible with platform but agent has incompatible executor
 	technique := &entity.Technique{
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// Progress computes the progress of an execution from its results. While the
+// execution is active, the deferred phases an agent has not reached yet count
+// one step per technique, since their results are only created once decided.
+//
+// The ETA adds up, per agent, the median historical duration of the techniques
+// of its unfinished steps: an agent runs its steps one after the other, agents
+// run in parallel. history holds the durations of the latest runs by technique
+// ID; techniques without any take the median of every known duration, those of
+// the finished steps of the execution included. There is no ETA when no
+// duration is known.
+func (o *AttackOrchestrator) Progress(
+	ctx context.Context,
+	execution *entity.Execution,
+	scenario *entity.Scenario,
+	results []*entity.ExecutionResult,
+	history map[string][]time.Duration,
+	now time.Time,
+) entity.ExecutionProgress {
+	var progress entity.ExecutionProgress
+	active := isActiveExecution(execution.Status)
+
+	phaseOrder := make(map[string]int)
+	if scenario != nil {
+		phases := slices.Clone(scenario.Phases)
+		sort.SliceStable(phases, func(i, j int) bool { return phases[i].Order < phases[j].Order })
+		for i, phase := range phases {
+			phaseOrder[phase.Name] = i
+		}
+	}
+
+	type step struct {
+		techniqueID string
+		phase       string
+	}
+	remaining := make(map[string][]step) // Unfinished steps by agent paw
+	planned := make(map[string]map[string]bool)
+	known := make(map[string][]time.Duration)
+	var all []time.Duration
+	for technique, durations := range history {
+		known[technique] = append(known[technique], durations...)
+		all = append(all, durations...)
+	}
+
+	for _, r := range results {
+		progress.Total++
+		if planned[r.AgentPaw] == nil {
+			planned[r.AgentPaw] = make(map[string]bool)
+		}
+		planned[r.AgentPaw][r.Phase] = true
+
+		if !r.Status.IsTerminal() {
+			remaining[r.AgentPaw] = append(remaining[r.AgentPaw], step{techniqueID: r.TechniqueID, phase: r.Phase})
+			continue
+		}
+		progress.Completed++
+		switch {
+		case r.Status.IsSkipped():
+			progress.Skipped++
+		case r.Status == entity.StatusFailed || r.Status == entity.StatusTimeout || r.Status == entity.StatusLimitExceeded:
+			progress.Failed++
+		}
+		if !r.Status.IsSkipped() && r.CompletedAt != nil && !r.CompletedAt.Before(r.StartedAt) {
+			duration := r.CompletedAt.Sub(r.StartedAt)
+			if len(history[r.TechniqueID]) == 0 {
+				known[r.TechniqueID] = append(known[r.TechniqueID], duration)
+			}
+			all = append(all, duration)
+		}
+	}
+
+	if active && scenario != nil {
+		for _, paw := range execution.AgentPaws {
+			for _, phase := range scenario.Phases {
+				if planned[paw][phase.Name] || !o.DefersPhase(ctx, phase) {
+					continue
+				}
+				for _, techID := range phase.Techniques {
+					progress.Total++
+					remaining[paw] = append(remaining[paw], step{techniqueID: techID, phase: phase.Name})
+				}
+			}
+		}
+	}
+
+	if progress.Total > 0 {
+		progress.Percent = math.Round(float64(progress.Completed)/float64(progress.Total)*1000) / 10
+	}
+	if !active {
+		return progress
+	}
+
+	// The current phase is the earliest one with unfinished steps
+	current := -1
+	for _, steps := range remaining {
+		for _, s := range steps {
+			order, ok := phaseOrder[s.phase]
+			if !ok {
+				order = len(phaseOrder)
+			}
+			if current == -1 || order < current || (order == current && s.phase < progress.CurrentPhase) {
+				current, progress.CurrentPhase = order, s.phase
+			}
+		}
+	}
+
+	if len(remaining) == 0 || len(all) == 0 {
+		return progress
+	}
+	fallback := medianDuration(all)
+	var longest time.Duration
+	for _, steps := range remaining {
+		var total time.Duration
+		for _, s := range steps {
+			if durations := known[s.techniqueID]; len(durations) > 0 {
+				total += medianDuration(durations)
+			} else {
+				total += fallback
+			}
+		}
+		longest = max(longest, total)
+	}
+	seconds := math.Round(longest.Seconds())
+	eta := now.Add(time.Duration(seconds) * time.Second)
+	progress.RemainingSeconds = &seconds
+	progress.ETA = &eta
+	return progress
+}
+
+// isActiveExecution reports whether an execution still has steps to run,
+// including an interrupted one waiting to be resumed
+func isActiveExecution(status entity.ExecutionStatus) bool {
+	return status == entity.ExecutionPending || status == entity.ExecutionRunning || status == entity.ExecutionInterrupted
+}
+
+// medianDuration returns the median of durations, leaving them unsorted
+func medianDuration(durations []time.Duration) time.Duration {
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func TestAttackOrchestrator_Progress(t *testing.T) {
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, &mockTechniqueRepo{techniques: map[string]*entity.Technique{}}, NewTechniqueValidator(), nil)
+	scenario := &entity.Scenario{Phases: []entity.Phase{
+		{Name: "Collection", Order: 2, Techniques: []string{"T1005"}, RunIf: []entity.PhaseCondition{{Technique: "T1083"}}},
+		{Name: "Discovery", Order: 1, Techniques: []string{"T1082", "T1083"}},
+	}}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	done := start.Add(10 * time.Second)
+	results := []*entity.ExecutionResult{
+		{TechniqueID: "T1082", AgentPaw: "a1", Phase: "Discovery", Status: entity.StatusSuccess, StartedAt: start, CompletedAt: &done},
+		{TechniqueID: "T1083", AgentPaw: "a1", Phase: "Discovery", Status: entity.StatusRunning, StartedAt: start},
+		{TechniqueID: "T1082", AgentPaw: "a2", Phase: "Discovery", Status: entity.StatusPending, StartedAt: start},
+		{TechniqueID: "T1083", AgentPaw: "a2", Phase: "Discovery", Status: entity.StatusPending, StartedAt: start},
+	}
+	history := map[string][]time.Duration{"T1083": {20 * time.Second, 40 * time.Second, 30 * time.Second}}
+	execution := &entity.Execution{Status: entity.ExecutionRunning, AgentPaws: []string{"a1", "a2"}}
+	now := start.Add(15 * time.Second)
+
+	progress := orchestrator.Progress(context.Background(), execution, scenario, results, history, now)
+
+	// The conditional phase counts a step per agent until it is decided
+	if progress.Total != 6 || progress.Completed != 1 || progress.Percent != 16.7 {
+		t.Errorf("Unexpected steps: %+v", progress)
+	}
+	if progress.CurrentPhase != "Discovery" {
+		t.Errorf("Expected the earliest unfinished phase, got %q", progress.CurrentPhase)
+	}
+	// a2 has T1082 (10s, from this execution), T1083 (30s, median of its
+	// history) and T1005 (25s, median of every duration) left
+	if progress.RemainingSeconds == nil || *progress.RemainingSeconds != 65 || !progress.ETA.Equal(now.Add(65*time.Second)) {
+		t.Errorf("Unexpected ETA: %+v", progress)
+	}
+}
+
+func TestAttackOrchestrator_Progress_Finished(t *testing.T) {
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, &mockTechniqueRepo{techniques: map[string]*entity.Technique{}}, NewTechniqueValidator(), nil)
+	scenario := &entity.Scenario{Phases: []entity.Phase{
+		{Name: "Discovery", Techniques: []string{"T1082", "T1083"}},
+		{Name: "Collection", Techniques: []string{"T1005"}, RunIf: []entity.PhaseCondition{{Technique: "T1083"}}},
+	}}
+	start := time.Now()
+	results := []*entity.ExecutionResult{
+		{TechniqueID: "T1082", AgentPaw: "a1", Phase: "Discovery", Status: entity.StatusFailed, StartedAt: start, CompletedAt: &start},
+		{TechniqueID: "T1083", AgentPaw: "a1", Phase: "Discovery", Status: entity.StatusSkippedInsufficientPrivilege, StartedAt: start, CompletedAt: &start},
+	}
+	execution := &entity.Execution{Status: entity.ExecutionCompleted, AgentPaws: []string{"a1"}}
+
+	progress := orchestrator.Progress(context.Background(), execution, scenario, results, nil, start)
+	if progress.Total != 2 || progress.Completed != 2 || progress.Failed != 1 || progress.Skipped != 1 || progress.Percent != 100 {
+		t.Errorf("Unexpected steps: %+v", progress)
+	}
+	if progress.CurrentPhase != "" || progress.RemainingSeconds != nil || progress.ETA != nil {
+		t.Errorf("Expected no current phase nor ETA once finished, got %+v", progress)
+	}
+}
+
+func TestAttackOrchestrator_Progress_NoDurationKnown(t *testing.T) {
+	orchestrator := NewAttackOrchestrator(&mockAgentRepo{}, &mockTechniqueRepo{techniques: map[string]*entity.Technique{}}, NewTechniqueValidator(), nil)
+	results := []*entity.ExecutionResult{{TechniqueID: "T1082", AgentPaw: "a1", Phase: "Discovery", Status: entity.StatusPending}}
+	execution := &entity.Execution{Status: entity.ExecutionRunning, AgentPaws: []string{"a1"}}
+
+	progress := orchestrator.Progress(context.Background(), execution, nil, results, nil, time.Now())
+	if progress.Total != 1 || progress.Percent != 0 || progress.CurrentPhase != "Discovery" || progress.ETA != nil {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+}
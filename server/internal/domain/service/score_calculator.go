@@ -27,7 +27,7 @@ func (s *ScoreCalculator) CalculateScore(results []*entity.ExecutionResult) *ent
 	var blocked, detected, successful, total int
 
 	for _, result := range results {
-		if result.Status == entity.StatusSkipped || result.Status == entity.StatusPending {
+		if result.Status.IsSkipped() || result.Status == entity.StatusPending {
 			continue
 		}
 
@@ -58,6 +58,64 @@ func (s *ScoreCalculator) CalculateScore(results []*entity.ExecutionResult) *ent
 	return score
 }
 
+// CalculateProfileScore calculates the security score from execution results
+// with a scoring profile. Counts are those of CalculateScore; the overall and
+// per tactic scores weigh each result by the profile weight of its technique,
+// and undetected ones by its undetected weight too.
+// Results of techniques missing from techniques weigh 1. A nil profile falls
+// back to CalculateScore.
+func (s *ScoreCalculator) CalculateProfileScore(
+	results []*entity.ExecutionResult,
+	techniques map[string]*entity.Technique,
+	profile *entity.ScoringProfile,
+) *entity.SecurityScore {
+	score := s.CalculateScore(results)
+	if profile == nil {
+		return score
+	}
+
+	var earned, total float64
+	tacticEarned := make(map[string]float64)
+	tacticTotal := make(map[string]float64)
+	for _, result := range results {
+		if result.Status.IsSkipped() || result.Status == entity.StatusPending {
+			continue
+		}
+		var credit float64 // Successful and errored techniques earn nothing
+		switch result.Status {
+		case entity.StatusBlocked:
+			credit = profile.BlockedCredit
+		case entity.StatusDetected:
+			credit = profile.DetectedCredit
+		}
+
+		technique := techniques[result.TechniqueID]
+		weight := profile.Weight(technique)
+		if result.Status == entity.StatusSuccess {
+			weight *= profile.UndetectedWeight(technique)
+		}
+		earned += weight * credit
+		total += weight
+		if technique != nil {
+			for _, tactic := range technique.AllTactics() {
+				tacticEarned[string(tactic)] += weight * credit
+				tacticTotal[string(tactic)] += weight
+			}
+		}
+	}
+
+	score.Overall = 0
+	if total > 0 {
+		score.Overall = earned / total * 100
+	}
+	for tactic, weight := range tacticTotal {
+		if weight > 0 {
+			score.ByTactic[tactic] = tacticEarned[tactic] / weight * 100
+		}
+	}
+	return score
+}
+
 // CalculateScoreByTactic calculates scores grouped by MITRE tactic
 func (s *ScoreCalculator) CalculateScoreByTactic(
 	results []*entity.ExecutionResult,
@@ -67,7 +125,10 @@ func (s *ScoreCalculator) CalculateScoreByTactic(
 
 	for _, result := range results {
 		if tech, ok := techniques[result.TechniqueID]; ok {
-			tacticResults[tech.Tactic] = append(tacticResults[tech.Tactic], result)
+			// A technique spanning several tactics counts toward each of them
+			for _, tactic := range tech.AllTactics() {
+				tacticResults[tactic] = append(tacticResults[tactic], result)
+			}
 		}
 	}
 
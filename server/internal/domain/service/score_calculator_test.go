@@ -74,6 +74,7 @@ func TestScoreCalculator_CalculateScore(t *testing.T) {
 			results: []*entity.ExecutionResult{
 				{Status: entity.StatusBlocked},
 				{Status: entity.StatusSkipped},
+				{Status: entity.StatusSkippedInsufficientPrivilege},
 				{Status: entity.StatusPending},
 			},
 			wantOverall: 100.0,
@@ -221,3 +222,100 @@ func TestScoreCalculator_CalculateScoreByTactic(t *testing.T) {
 		t.Errorf("Execution score = %f, want 0.0", executionScore.Overall)
 	}
 }
+
+func TestScoreCalculator_CalculateScoreByTactic_MultipleTactics(t *testing.T) {
+	calc := NewScoreCalculator()
+
+	techniques := map[string]*entity.Technique{
+		"T1078": {ID: "T1078", Tactic: entity.TacticInitialAccess,
+			Tactics: []entity.TacticType{entity.TacticInitialAccess, entity.TacticPersistence}},
+	}
+	results := []*entity.ExecutionResult{{TechniqueID: "T1078", Status: entity.StatusBlocked}}
+
+	scores := calc.CalculateScoreByTactic(results, techniques)
+	if len(scores) != 2 || scores[entity.TacticInitialAccess] == nil || scores[entity.TacticPersistence] == nil {
+		t.Fatalf("Expected a score for both tactics, got %v", scores)
+	}
+	if scores[entity.TacticPersistence].Blocked != 1 {
+		t.Errorf("Expected the result to count toward persistence, got %+v", scores[entity.TacticPersistence])
+	}
+}
+
+func TestScoreCalculator_CalculateProfileScore(t *testing.T) {
+	calc := NewScoreCalculator()
+	techniques := map[string]*entity.Technique{
+		"T1059": {ID: "T1059", Tactic: entity.TacticExecution, Metadata: map[string]string{"severity": "high"}},
+		"T1082": {ID: "T1082", Tactic: entity.TacticDiscovery},
+	}
+	results := []*entity.ExecutionResult{
+		{TechniqueID: "T1059", Status: entity.StatusBlocked},
+		{TechniqueID: "T1082", Status: entity.StatusDetected},
+		{TechniqueID: "T1082", Status: entity.StatusSuccess},
+		{TechniqueID: "T1059", Status: entity.StatusSkipped},
+	}
+
+	t.Run("nil profile uses the built-in formula", func(t *testing.T) {
+		score := calc.CalculateProfileScore(results, techniques, nil)
+		if score.Overall != calc.CalculateScore(results).Overall {
+			t.Errorf("Overall = %v, want %v", score.Overall, calc.CalculateScore(results).Overall)
+		}
+	})
+
+	t.Run("default credits without weights match the built-in formula", func(t *testing.T) {
+		profile := &entity.ScoringProfile{BlockedCredit: entity.DefaultBlockedCredit, DetectedCredit: entity.DefaultDetectedCredit}
+		score := calc.CalculateProfileScore(results, techniques, profile)
+		if score.Overall != 50 {
+			t.Errorf("Overall = %v, want 50", score.Overall)
+		}
+		if score.Blocked != 1 || score.Detected != 1 || score.Successful != 1 || score.Total != 3 {
+			t.Errorf("unexpected counts %+v", score)
+		}
+	})
+
+	t.Run("weights and partial credit", func(t *testing.T) {
+		profile := &entity.ScoringProfile{
+			BlockedCredit:   1,
+			DetectedCredit:  0.25,
+			TacticWeights:   map[string]float64{"execution": 2},
+			SeverityWeights: map[string]float64{"high": 3},
+		}
+		score := calc.CalculateProfileScore(results, techniques, profile)
+		// Earned 6*1 + 1*0.25 out of 6 + 1 + 1
+		if want := 6.25 / 8 * 100; score.Overall != want {
+			t.Errorf("Overall = %v, want %v", score.Overall, want)
+		}
+		if score.ByTactic["execution"] != 100 {
+			t.Errorf("execution = %v, want 100", score.ByTactic["execution"])
+		}
+		if score.ByTactic["discovery"] != 12.5 {
+			t.Errorf("discovery = %v, want 12.5", score.ByTactic["discovery"])
+		}
+	})
+
+	t.Run("undetected high-severity techniques weigh more", func(t *testing.T) {
+		undetected := []*entity.ExecutionResult{
+			{TechniqueID: "T1059", Status: entity.StatusBlocked},
+			{TechniqueID: "T1059", Status: entity.StatusSuccess},
+			{TechniqueID: "T1082", Status: entity.StatusSuccess},
+		}
+		profile := &entity.ScoringProfile{
+			BlockedCredit:     1,
+			UndetectedWeights: map[string]float64{"high": 3},
+		}
+		score := calc.CalculateProfileScore(undetected, techniques, profile)
+		// Earned 1 out of 1 + 3 + 1: only the undetected high-severity result weighs 3
+		if score.Overall != 20 {
+			t.Errorf("Overall = %v, want 20", score.Overall)
+		}
+		if score.ByTactic["execution"] != 25 {
+			t.Errorf("execution = %v, want 25", score.ByTactic["execution"])
+		}
+	})
+
+	t.Run("zero total weight scores 0", func(t *testing.T) {
+		profile := &entity.ScoringProfile{BlockedCredit: 1, TacticWeights: map[string]float64{"execution": 0, "discovery": 0}}
+		if score := calc.CalculateProfileScore(results, techniques, profile); score.Overall != 0 {
+			t.Errorf("Overall = %v, want 0", score.Overall)
+		}
+	})
+}
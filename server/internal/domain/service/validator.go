@@ -1,6 +1,8 @@
 package service
 
 import (
+	"slices"
+
 	"autostrike/internal/domain/entity"
 )
 
@@ -17,6 +19,61 @@ type ValidationResult struct {
 	IsValid  bool
 	Errors   []string
 	Warnings []string
+
+	// Scenario validation also locates each error and warning
+	ErrorDetails   []ValidationIssue
+	WarningDetails []ValidationIssue
+}
+
+// Codes of scenario validation issues
+const (
+	IssueNoPhases            = "no_phases"
+	IssueEmptyPhase          = "empty_phase"
+	IssueUnknownTechnique    = "unknown_technique"
+	IssueRetiredTechnique    = "retired_technique"
+	IssueInvalidCondition    = "invalid_condition"
+	IssueInvalidExecutor     = "invalid_executor"
+	IssueUnusedExecutor      = "unused_executor"
+	IssueCircularDependency  = "circular_dependency"
+	IssueUnresolvedFact      = "unresolved_fact"
+	IssueNoExecutor          = "no_executor"
+	IssueUnsupportedPlatform = "unsupported_platform"
+	IssueUnsafeTechnique     = "unsafe_technique"
+	IssueNothingToRun        = "nothing_to_run"
+)
+
+// ValidationIssue is a scenario validation error or warning, with the phase
+// and technique it is about when it has one
+type ValidationIssue struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Phase     string `json:"phase,omitempty"`
+	Technique string `json:"technique,omitempty"`
+}
+
+// ScenarioCheck holds the execution settings a scenario is checked against
+type ScenarioCheck struct {
+	Platforms []string // Target platforms; any platform when empty
+	SafeMode  bool
+}
+
+func newValidationResult() *ValidationResult {
+	return &ValidationResult{
+		IsValid:  true,
+		Errors:   make([]string, 0),
+		Warnings: make([]string, 0),
+	}
+}
+
+func (r *ValidationResult) addError(issue ValidationIssue) {
+	r.IsValid = false
+	r.Errors = append(r.Errors, issue.Message)
+	r.ErrorDetails = append(r.ErrorDetails, issue)
+}
+
+func (r *ValidationResult) addWarning(issue ValidationIssue) {
+	r.Warnings = append(r.Warnings, issue.Message)
+	r.WarningDetails = append(r.WarningDetails, issue)
 }
 
 // ValidateAgentCompatibility checks if an agent can execute a technique
@@ -24,11 +81,7 @@ func (v *TechniqueValidator) ValidateAgentCompatibility(
 	agent *entity.Agent,
 	technique *entity.Technique,
 ) *ValidationResult {
-	result := &ValidationResult{
-		IsValid:  true,
-		Errors:   make([]string, 0),
-		Warnings: make([]string, 0),
-	}
+	result := newValidationResult()
 
 	// Check platform compatibility
 	platformMatch := false
@@ -67,6 +120,13 @@ func (v *TechniqueValidator) ValidateAgentCompatibility(
 		return result
 	}
 
+	if _, needsElevation := technique.ExecutorForAgent(agent, nil); needsElevation {
+		result.IsValid = false
+		result.Errors = append(result.Errors,
+			"technique requires an elevated agent")
+		return result
+	}
+
 	// Check agent status
 	if agent.Status != entity.AgentOnline {
 		result.Warnings = append(result.Warnings,
@@ -82,20 +142,44 @@ func (v *TechniqueValidator) ValidateAgentCompatibility(
 	return result
 }
 
-// ValidateScenario validates a complete scenario
+// ValidateScenario validates a complete scenario. Deprecated and broken
+// techniques are rejected.
 func (v *TechniqueValidator) ValidateScenario(
 	scenario *entity.Scenario,
 	techniques []*entity.Technique,
 ) *ValidationResult {
-	result := &ValidationResult{
-		IsValid:  true,
-		Errors:   make([]string, 0),
-		Warnings: make([]string, 0),
+	return v.validateScenario(scenario, techniques, nil)
+}
+
+// ValidateScenarioUpdate validates an edited scenario. Deprecated and broken
+// techniques it already contained only raise warnings so the scenario stays
+// editable; adding new ones is rejected.
+func (v *TechniqueValidator) ValidateScenarioUpdate(
+	scenario *entity.Scenario,
+	previous *entity.Scenario,
+	techniques []*entity.Technique,
+) *ValidationResult {
+	existing := make(map[string]bool)
+	if previous != nil {
+		for _, id := range previous.GetAllTechniques() {
+			existing[id] = true
+		}
 	}
+	return v.validateScenario(scenario, techniques, existing)
+}
 
+// ValidateScenarioDraft validates a scenario like ValidateScenarioUpdate, then
+// checks it against the target platforms and safe mode of an execution:
+// techniques without an executor, unsupported on a target platform or skipped
+// in safe mode raise warnings, and a scenario with nothing left to run fails.
+func (v *TechniqueValidator) ValidateScenarioDraft(
+	scenario *entity.Scenario,
+	previous *entity.Scenario,
+	techniques []*entity.Technique,
+	check ScenarioCheck,
+) *ValidationResult {
+	result := v.ValidateScenarioUpdate(scenario, previous, techniques)
 	if len(scenario.Phases) == 0 {
-		result.IsValid = false
-		result.Errors = append(result.Errors, "scenario has no phases")
 		return result
 	}
 
@@ -104,20 +188,303 @@ func (v *TechniqueValidator) ValidateScenario(
 		techniqueMap[t.ID] = t
 	}
 
+	runnable := 0
+	checked := make(map[string]bool)
+	for _, phase := range scenario.Phases {
+		for _, techID := range phase.Techniques {
+			technique, exists := techniqueMap[techID]
+			if !exists || checked[techID] {
+				continue
+			}
+			checked[techID] = true
+			if v.checkTechniqueRuns(result, phase.Name, technique, check) {
+				runnable++
+			}
+		}
+	}
+
+	if runnable == 0 && len(checked) > 0 {
+		result.addError(ValidationIssue{
+			Code:    IssueNothingToRun,
+			Message: "no technique of the scenario can run on the target platforms" + safeModeSuffix(check.SafeMode),
+		})
+	}
+	return result
+}
+
+// executorPlatforms lists the platforms of the executors bound to an OS. Other
+// executors, such as pwsh or python3, may run anywhere.
+var executorPlatforms = map[string][]string{
+	"cmd":        {"windows"},
+	"psh":        {"windows"},
+	"powershell": {"windows"},
+	"sh":         {"linux", "darwin"},
+	"bash":       {"linux", "darwin"},
+	"zsh":        {"linux", "darwin"},
+}
+
+// checkTechniqueRuns reports whether a technique can run on a target platform,
+// warning about what prevents it elsewhere
+func (v *TechniqueValidator) checkTechniqueRuns(
+	result *ValidationResult,
+	phase string,
+	technique *entity.Technique,
+	check ScenarioCheck,
+) bool {
+	issue := func(code, message string) ValidationIssue {
+		return ValidationIssue{Code: code, Message: message, Phase: phase, Technique: technique.ID}
+	}
+
+	platforms := check.Platforms
+	if len(platforms) == 0 {
+		platforms = technique.Platforms
+	}
+	runs := false
+	for _, platform := range platforms {
+		switch {
+		case !slices.Contains(technique.Platforms, platform):
+			result.addWarning(issue(IssueUnsupportedPlatform,
+				"technique '"+technique.ID+"' does not support platform '"+platform+"'"))
+		case !hasExecutorFor(technique, platform):
+			result.addWarning(issue(IssueNoExecutor,
+				"technique '"+technique.ID+"' has no executor for platform '"+platform+"'"))
+		default:
+			runs = true
+		}
+	}
+
+	if runs && !technique.IsSafe && check.SafeMode {
+		result.addWarning(issue(IssueUnsafeTechnique,
+			"technique '"+technique.ID+"' is unsafe and will be skipped in safe mode"))
+		return false
+	}
+	return runs
+}
+
+// hasExecutorFor reports whether a technique has an executor able to run on the platform
+func hasExecutorFor(technique *entity.Technique, platform string) bool {
+	for _, executor := range technique.Executors {
+		if platforms, bound := executorPlatforms[executor.Type]; !bound || slices.Contains(platforms, platform) {
+			return true
+		}
+	}
+	return false
+}
+
+func safeModeSuffix(safeMode bool) string {
+	if safeMode {
+		return " in safe mode"
+	}
+	return ""
+}
+
+func (v *TechniqueValidator) validateScenario(
+	scenario *entity.Scenario,
+	techniques []*entity.Technique,
+	existing map[string]bool,
+) *ValidationResult {
+	result := newValidationResult()
+
+	if len(scenario.Phases) == 0 {
+		result.addError(ValidationIssue{Code: IssueNoPhases, Message: "scenario has no phases"})
+		return result
+	}
+
+	techniqueMap := make(map[string]*entity.Technique)
+	for _, t := range techniques {
+		techniqueMap[t.ID] = t
+	}
+
+	// Techniques of each earlier phase, which run_if conditions may refer to
+	earlier := make(map[string]map[string]bool)
+	earlierTechniques := make(map[string]bool)
+	// Facts extracted by the techniques of earlier phases
+	earlierFacts := make(map[string]bool)
+
 	for _, phase := range scenario.Phases {
 		if len(phase.Techniques) == 0 {
-			result.Warnings = append(result.Warnings,
-				"phase '"+phase.Name+"' has no techniques")
+			result.addWarning(ValidationIssue{
+				Code:    IssueEmptyPhase,
+				Message: "phase '" + phase.Name + "' has no techniques",
+				Phase:   phase.Name,
+			})
+		}
+
+		for _, issue := range validatePhaseConditions(scenario, phase, earlier, earlierTechniques) {
+			result.addError(issue)
+		}
+		for _, issue := range validatePhaseExecutors(phase) {
+			result.addError(issue)
+		}
+		for _, executorType := range unusedExecutors(phase, techniqueMap) {
+			result.addWarning(ValidationIssue{
+				Code:    IssueUnusedExecutor,
+				Message: "phase '" + phase.Name + "' prefers executor '" + executorType + "' that none of its techniques has",
+				Phase:   phase.Name,
+			})
+		}
+		if earlier[phase.Name] == nil {
+			earlier[phase.Name] = make(map[string]bool)
+		}
+		for _, techID := range phase.Techniques {
+			earlier[phase.Name][techID] = true
+			earlierTechniques[techID] = true
 		}
 
 		for _, techID := range phase.Techniques {
-			if _, exists := techniqueMap[techID]; !exists {
-				result.Errors = append(result.Errors,
-					"technique '"+techID+"' not found")
-				result.IsValid = false
+			issue := ValidationIssue{Phase: phase.Name, Technique: techID}
+			technique, exists := techniqueMap[techID]
+			switch {
+			case !exists:
+				issue.Code, issue.Message = IssueUnknownTechnique, "technique '"+techID+"' not found"
+				result.addError(issue)
+			case technique.IsRetired() && existing[techID]:
+				issue.Code = IssueRetiredTechnique
+				issue.Message = "technique '" + techID + "' is " + string(technique.LifecycleStatus()) + " and should be replaced"
+				result.addWarning(issue)
+			case technique.IsRetired():
+				issue.Code = IssueRetiredTechnique
+				issue.Message = "technique '" + techID + "' is " + string(technique.LifecycleStatus())
+				result.addError(issue)
+			}
+			if exists {
+				for _, name := range unresolvedFacts(technique, earlierFacts) {
+					issue.Code = IssueUnresolvedFact
+					issue.Message = "technique '" + techID + "' references fact '" + name + "' that no earlier phase extracts"
+					result.addWarning(issue)
+				}
+			}
+		}
+		for _, techID := range phase.Techniques {
+			if technique, exists := techniqueMap[techID]; exists {
+				for _, executor := range technique.Executors {
+					for _, parser := range executor.Parsers {
+						earlierFacts[parser.Fact] = true
+					}
+				}
 			}
 		}
 	}
 
 	return result
 }
+
+// unresolvedFacts returns the facts referenced by the executors of a technique
+// that are not in facts
+func unresolvedFacts(technique *entity.Technique, facts map[string]bool) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, executor := range technique.Executors {
+		for _, command := range executor.Commands() {
+			for _, name := range ReferencedFacts(command) {
+				if !facts[name] && !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// validatePhaseConditions checks that the run_if conditions of a phase refer to
+// techniques of earlier phases and to known result statuses. A condition on the
+// phase itself or a later phase is a circular dependency: the phases would wait
+// for each other.
+func validatePhaseConditions(
+	scenario *entity.Scenario,
+	phase entity.Phase,
+	earlier map[string]map[string]bool,
+	earlierTechniques map[string]bool,
+) []ValidationIssue {
+	var issues []ValidationIssue
+	for _, cond := range phase.RunIf {
+		prefix := "phase '" + phase.Name + "' run_if: "
+		issue := func(code, message string) ValidationIssue {
+			return ValidationIssue{Code: code, Message: prefix + message, Phase: phase.Name, Technique: cond.Technique}
+		}
+		switch {
+		case cond.Technique == "":
+			issues = append(issues, issue(IssueInvalidCondition, "technique is required"))
+		case cond.Phase != "" && earlier[cond.Phase] == nil && scenarioHasPhase(scenario, cond.Phase):
+			issues = append(issues, issue(IssueCircularDependency, "phase '"+cond.Phase+"' is not an earlier phase"))
+		case cond.Phase != "" && earlier[cond.Phase] == nil:
+			issues = append(issues, issue(IssueInvalidCondition, "phase '"+cond.Phase+"' is not an earlier phase"))
+		case cond.Phase != "" && !earlier[cond.Phase][cond.Technique]:
+			issues = append(issues, issue(IssueInvalidCondition, "technique '"+cond.Technique+"' is not in phase '"+cond.Phase+"'"))
+		case !earlierTechniques[cond.Technique] && scenarioHasTechnique(scenario, cond.Technique):
+			issues = append(issues, issue(IssueCircularDependency, "technique '"+cond.Technique+"' is not in an earlier phase"))
+		case !earlierTechniques[cond.Technique]:
+			issues = append(issues, issue(IssueInvalidCondition, "technique '"+cond.Technique+"' is not in an earlier phase"))
+		}
+		if status := cond.ExpectedStatus(); !status.IsKnown() || !status.IsTerminal() {
+			issues = append(issues, issue(IssueInvalidCondition, "invalid status '"+string(status)+"'"))
+		}
+	}
+	return issues
+}
+
+// validatePhaseExecutors checks that the executor preferences of a phase are
+// named and listed once
+func validatePhaseExecutors(phase entity.Phase) []ValidationIssue {
+	var issues []ValidationIssue
+	seen := make(map[string]bool)
+	for _, executorType := range phase.Executors {
+		message := ""
+		switch {
+		case executorType == "":
+			message = "executor type is required"
+		case seen[executorType]:
+			message = "executor '" + executorType + "' is listed twice"
+		default:
+			seen[executorType] = true
+			continue
+		}
+		issues = append(issues, ValidationIssue{
+			Code:    IssueInvalidExecutor,
+			Message: "phase '" + phase.Name + "' executors: " + message,
+			Phase:   phase.Name,
+		})
+	}
+	return issues
+}
+
+// unusedExecutors returns the executor types a phase prefers that none of its
+// known techniques has, which are never selected
+func unusedExecutors(phase entity.Phase, techniques map[string]*entity.Technique) []string {
+	var unused []string
+	for _, executorType := range phase.Executors {
+		used := executorType == ""
+		known := false
+		for _, techID := range phase.Techniques {
+			if technique, exists := techniques[techID]; exists {
+				known = true
+				used = used || technique.ExecutorOfType(executorType) != nil
+			}
+		}
+		if known && !used && !slices.Contains(unused, executorType) {
+			unused = append(unused, executorType)
+		}
+	}
+	return unused
+}
+
+// scenarioHasPhase reports whether a scenario has a phase with the name
+func scenarioHasPhase(scenario *entity.Scenario, name string) bool {
+	for _, phase := range scenario.Phases {
+		if phase.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scenarioHasTechnique reports whether a phase of the scenario runs the technique
+func scenarioHasTechnique(scenario *entity.Scenario, techID string) bool {
+	for _, id := range scenario.GetAllTechniques() {
+		if id == techID {
+			return true
+		}
+	}
+	return false
+}
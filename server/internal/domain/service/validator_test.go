@@ -216,6 +216,233 @@ func TestTechniqueValidator_ValidateScenario(t *testing.T) {
 	}
 }
 
+func TestTechniqueValidator_ValidateScenario_RunIf(t *testing.T) {
+	validator := NewTechniqueValidator()
+	techniques := []*entity.Technique{{ID: "T1003"}, {ID: "T1021"}, {ID: "T1082"}}
+
+	tests := []struct {
+		name       string
+		runIf      []entity.PhaseCondition
+		wantErrors int
+		wantCode   string
+	}{
+		{"earlier technique", []entity.PhaseCondition{{Technique: "T1003"}}, 0, ""},
+		{"earlier phase", []entity.PhaseCondition{{Technique: "T1003", Phase: "Credential Access", Status: entity.StatusDetected}}, 0, ""},
+		{"missing technique", []entity.PhaseCondition{{}}, 1, IssueInvalidCondition},
+		{"later technique", []entity.PhaseCondition{{Technique: "T1082"}}, 1, IssueCircularDependency},
+		{"own technique", []entity.PhaseCondition{{Technique: "T1021"}}, 1, IssueCircularDependency},
+		{"later phase", []entity.PhaseCondition{{Technique: "T1003", Phase: "Discovery"}}, 1, IssueCircularDependency},
+		{"unknown phase", []entity.PhaseCondition{{Technique: "T1003", Phase: "Exfiltration"}}, 1, IssueInvalidCondition},
+		{"unknown technique", []entity.PhaseCondition{{Technique: "T1486"}}, 1, IssueInvalidCondition},
+		{"technique outside phase", []entity.PhaseCondition{{Technique: "T1021", Phase: "Credential Access"}}, 1, IssueInvalidCondition},
+		{"invalid status", []entity.PhaseCondition{{Technique: "T1003", Status: "succeeded"}}, 1, IssueInvalidCondition},
+		{"non-final status", []entity.PhaseCondition{{Technique: "T1003", Status: entity.StatusRunning}}, 1, IssueInvalidCondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := &entity.Scenario{
+				Name: "Conditional",
+				Phases: []entity.Phase{
+					{Name: "Credential Access", Techniques: []string{"T1003"}},
+					{Name: "Lateral Movement", Techniques: []string{"T1021"}, RunIf: tt.runIf},
+					{Name: "Discovery", Techniques: []string{"T1082"}},
+				},
+			}
+			result := validator.ValidateScenario(scenario, techniques)
+			if len(result.Errors) != tt.wantErrors || result.IsValid != (tt.wantErrors == 0) {
+				t.Errorf("Expected %d errors, got valid=%v errors=%v", tt.wantErrors, result.IsValid, result.Errors)
+			}
+			if tt.wantCode != "" && (len(result.ErrorDetails) != 1 || result.ErrorDetails[0].Code != tt.wantCode ||
+				result.ErrorDetails[0].Phase != "Lateral Movement") {
+				t.Errorf("Expected a %s error on Lateral Movement, got %+v", tt.wantCode, result.ErrorDetails)
+			}
+		})
+	}
+}
+
+func TestTechniqueValidator_ValidateScenario_PhaseExecutors(t *testing.T) {
+	validator := NewTechniqueValidator()
+	techniques := []*entity.Technique{
+		{ID: "T1059", Platforms: []string{"windows"}, Executors: []entity.Executor{{Type: "cmd"}, {Type: "psh"}}},
+	}
+
+	tests := []struct {
+		name         string
+		executors    []string
+		wantErrors   int
+		wantWarnings []string
+	}{
+		{"preferred then fallback", []string{"psh", "cmd"}, 0, nil},
+		{"blank executor", []string{"psh", ""}, 1, nil},
+		{"executor listed twice", []string{"psh", "psh"}, 1, nil},
+		{"executor of no technique", []string{"bash", "cmd"}, 0, []string{IssueUnusedExecutor}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := &entity.Scenario{
+				Name:   "Executors",
+				Phases: []entity.Phase{{Name: "Execution", Techniques: []string{"T1059"}, Executors: tt.executors}},
+			}
+			result := validator.ValidateScenario(scenario, techniques)
+			if len(result.ErrorDetails) != tt.wantErrors {
+				t.Fatalf("Expected %d errors, got %+v", tt.wantErrors, result.ErrorDetails)
+			}
+			for _, issue := range result.ErrorDetails {
+				if issue.Code != IssueInvalidExecutor || issue.Phase != "Execution" {
+					t.Errorf("Expected an invalid_executor error on Execution, got %+v", issue)
+				}
+			}
+			var codes []string
+			for _, issue := range result.WarningDetails {
+				codes = append(codes, issue.Code)
+			}
+			if len(codes) != len(tt.wantWarnings) || (len(codes) > 0 && codes[0] != tt.wantWarnings[0]) {
+				t.Errorf("Expected warnings %v, got %+v", tt.wantWarnings, result.WarningDetails)
+			}
+		})
+	}
+}
+
+func TestTechniqueValidator_ValidateScenarioDraft(t *testing.T) {
+	validator := NewTechniqueValidator()
+	techniques := []*entity.Technique{
+		{ID: "T1082", Platforms: []string{"linux", "windows"}, IsSafe: true,
+			Executors: []entity.Executor{{Type: "bash"}, {Type: "cmd"}}},
+		{ID: "T1003", Platforms: []string{"windows"}, Executors: []entity.Executor{{Type: "powershell"}}},
+		{ID: "T1059", Platforms: []string{"linux", "windows"}, IsSafe: true, Executors: []entity.Executor{{Type: "sh"}}},
+		{ID: "T1105", Platforms: []string{"linux"}, IsSafe: true},
+	}
+
+	tests := []struct {
+		name         string
+		techniques   []string
+		check        ScenarioCheck
+		wantValid    bool
+		wantWarnings []string
+	}{
+		{"runs everywhere", []string{"T1082"}, ScenarioCheck{Platforms: []string{"linux", "windows"}}, true, nil},
+		{"platform not supported", []string{"T1003"}, ScenarioCheck{Platforms: []string{"linux"}},
+			false, []string{IssueUnsupportedPlatform}},
+		{"no executor for platform", []string{"T1059", "T1082"}, ScenarioCheck{Platforms: []string{"windows"}},
+			true, []string{IssueNoExecutor}},
+		{"no executor at all", []string{"T1105"}, ScenarioCheck{}, false, []string{IssueNoExecutor}},
+		{"unsafe in safe mode", []string{"T1003", "T1082"}, ScenarioCheck{Platforms: []string{"windows"}, SafeMode: true},
+			true, []string{IssueUnsafeTechnique}},
+		{"nothing safe", []string{"T1003"}, ScenarioCheck{SafeMode: true}, false, []string{IssueUnsafeTechnique}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := &entity.Scenario{
+				Name:   "Draft",
+				Phases: []entity.Phase{{Name: "Phase 1", Techniques: tt.techniques}},
+			}
+			result := validator.ValidateScenarioDraft(scenario, nil, techniques, tt.check)
+			if result.IsValid != tt.wantValid {
+				t.Errorf("Expected valid=%v, got errors %v", tt.wantValid, result.Errors)
+			}
+			if !tt.wantValid && (len(result.ErrorDetails) != 1 || result.ErrorDetails[0].Code != IssueNothingToRun) {
+				t.Errorf("Expected a nothing_to_run error, got %+v", result.ErrorDetails)
+			}
+			var codes []string
+			for _, issue := range result.WarningDetails {
+				codes = append(codes, issue.Code)
+			}
+			if len(codes) != len(tt.wantWarnings) || (len(codes) > 0 && codes[0] != tt.wantWarnings[0]) {
+				t.Errorf("Expected warnings %v, got %v", tt.wantWarnings, codes)
+			}
+		})
+	}
+}
+
+func TestTechniqueValidator_ValidateScenario_UnresolvedFacts(t *testing.T) {
+	validator := NewTechniqueValidator()
+	techniques := []*entity.Technique{
+		{ID: "T1082", Executors: []entity.Executor{{
+			Type:    "sh",
+			Command: "hostname",
+			Parsers: []entity.FactParser{{Fact: "hostname", Regex: `(\S+)`}},
+		}}},
+		{ID: "T1021", Executors: []entity.Executor{{Type: "sh", Command: "ssh #{fact.hostname}"}}},
+	}
+
+	tests := []struct {
+		name         string
+		phases       []entity.Phase
+		wantWarnings int
+	}{
+		{"extracted earlier", []entity.Phase{
+			{Name: "Discovery", Techniques: []string{"T1082"}},
+			{Name: "Lateral Movement", Techniques: []string{"T1021"}},
+		}, 0},
+		{"extracted in the same phase", []entity.Phase{
+			{Name: "Discovery", Techniques: []string{"T1082", "T1021"}},
+		}, 1},
+		{"never extracted", []entity.Phase{
+			{Name: "Lateral Movement", Techniques: []string{"T1021"}},
+		}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := validator.ValidateScenario(&entity.Scenario{Name: "Facts", Phases: tt.phases}, techniques)
+			if !result.IsValid || len(result.Warnings) != tt.wantWarnings {
+				t.Errorf("Expected %d warnings, got valid=%v warnings=%v", tt.wantWarnings, result.IsValid, result.Warnings)
+			}
+		})
+	}
+}
+
+func TestTechniqueValidator_ValidateScenario_Lifecycle(t *testing.T) {
+	validator := NewTechniqueValidator()
+
+	techniques := []*entity.Technique{
+		{ID: "T1082", Name: "System Info"},
+		{ID: "T1083", Name: "File Discovery", Status: entity.TechniqueDraft},
+		{ID: "T1059", Name: "Command Execution", Status: entity.TechniqueDeprecated},
+		{ID: "T1003", Name: "Credential Dumping", Status: entity.TechniqueBroken},
+	}
+	scenario := &entity.Scenario{
+		Name: "Lifecycle",
+		Phases: []entity.Phase{
+			{Name: "Phase1", Techniques: []string{"T1082", "T1083", "T1059", "T1003"}},
+		},
+	}
+
+	// New scenarios cannot use deprecated or broken techniques
+	result := validator.ValidateScenario(scenario, techniques)
+	if result.IsValid || len(result.Errors) != 2 {
+		t.Errorf("Expected 2 errors, got valid=%v errors=%v", result.IsValid, result.Errors)
+	}
+
+	// Existing scenarios keep them with a warning
+	previous := &entity.Scenario{
+		Phases: []entity.Phase{{Name: "Phase1", Techniques: []string{"T1082", "T1059", "T1003"}}},
+	}
+	result = validator.ValidateScenarioUpdate(scenario, previous, techniques)
+	if !result.IsValid || len(result.Warnings) != 2 {
+		t.Errorf("Expected valid update with 2 warnings, got valid=%v errors=%v warnings=%v",
+			result.IsValid, result.Errors, result.Warnings)
+	}
+
+	// Adding a retired technique during an update is rejected
+	previous = &entity.Scenario{
+		Phases: []entity.Phase{{Name: "Phase1", Techniques: []string{"T1082", "T1059"}}},
+	}
+	result = validator.ValidateScenarioUpdate(scenario, previous, techniques)
+	if result.IsValid || len(result.Errors) != 1 || len(result.Warnings) != 1 {
+		t.Errorf("Expected 1 error and 1 warning, got errors=%v warnings=%v", result.Errors, result.Warnings)
+	}
+
+	// Without a previous version the update behaves like a creation
+	result = validator.ValidateScenarioUpdate(scenario, nil, techniques)
+	if result.IsValid || len(result.Errors) != 2 {
+		t.Errorf("Expected 2 errors, got %v", result.Errors)
+	}
+}
+
 func TestNewTechniqueValidator(t *testing.T) {
 	validator := NewTechniqueValidator()
 	if validator == nil {
@@ -0,0 +1,362 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GeneratedFile is the file of a handler package holding its annotations
+const GeneratedFile = "openapi_annotations.go"
+
+// Annotation is the documentation of a handler method, from its godoc
+// @Summary, @Description, @Tags, @Accept, @Produce, @Param, @Success and
+// @Failure lines
+type Annotation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Accept      string
+	Produce     string
+	Params      []ParamAnnotation
+	Responses   []ResponseAnnotation
+}
+
+// ParamAnnotation is a @Param line: name, location (path, query, header,
+// body or formData), type, whether it is required and a description
+type ParamAnnotation struct {
+	Name        string
+	In          string
+	Type        string // Primitive type, for parameters that are not bodies
+	Required    bool
+	Description string
+	Model       any // Typed nil pointer to the body type
+}
+
+// ResponseAnnotation is a @Success or @Failure line
+type ResponseAnnotation struct {
+	Code        int
+	Kind        string // object, array or file; empty without a body
+	Model       any    // Typed nil pointer to the body type, or to its items for arrays
+	Description string
+}
+
+// parsedAnnotation is an annotation read from source, with the Go
+// expressions of its models
+type parsedAnnotation struct {
+	Annotation
+	paramModels    []string
+	responseModels []string
+}
+
+// primitiveTypes maps the primitive annotation types to schema types
+var primitiveTypes = map[string]string{
+	"string": "string", "int": "integer", "integer": "integer", "number": "number",
+	"bool": "boolean", "boolean": "boolean", "file": "file", "object": "object",
+}
+
+// GenerateAnnotations reads the annotations of the handler methods of the
+// package in dir and returns the Go source of a file declaring them in the
+// OpenAPIAnnotations variable, keyed by "Receiver.Method"
+func GenerateAnnotations(dir string) ([]byte, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	fset := token.NewFileSet()
+	pkgName := ""
+	annotations := make(map[string]*parsedAnnotation)
+	models := make(map[string]string) // File of each model, to resolve its package
+	fileImports := make(map[string]map[string]string)
+	pkgImports := make(map[string]string) // Imports of any file, for annotations naming packages their file does not import
+	for _, file := range files {
+		base := filepath.Base(file)
+		if strings.HasSuffix(base, "_test.go") || base == GeneratedFile {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkgName = f.Name.Name
+		fileImports[base] = importsOf(f)
+		for name, importPath := range fileImports[base] {
+			pkgImports[name] = importPath
+		}
+
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Doc == nil {
+				continue
+			}
+			annotation, err := parseAnnotation(fn.Doc.Text())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", base, fn.Name.Name, err)
+			}
+			if annotation == nil {
+				continue
+			}
+			for _, model := range append(annotation.paramModels, annotation.responseModels...) {
+				models[model] = base
+			}
+			annotations[receiverName(fn.Recv)+"."+fn.Name.Name] = annotation
+		}
+	}
+
+	imports := make(map[string]string) // Path by name
+	for model, base := range models {
+		qualifier, _, ok := strings.Cut(model, ".")
+		if !ok {
+			continue
+		}
+		importPath, found := fileImports[base][qualifier]
+		if !found {
+			importPath, found = pkgImports[qualifier]
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: unknown package of %s", base, model)
+		}
+		imports[qualifier] = importPath
+	}
+	if pkgName == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return writeAnnotations(pkgName, annotations, imports)
+}
+
+// importsOf returns the imports of f by the name they are referred to with
+func importsOf(f *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range f.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// receiverName returns the type name of a method receiver
+func receiverName(recv *ast.FieldList) string {
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// parseAnnotation parses the annotation lines of a godoc comment, nil when it has none
+func parseAnnotation(doc string) (*parsedAnnotation, error) {
+	var annotation *parsedAnnotation
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		if annotation == nil {
+			annotation = &parsedAnnotation{}
+		}
+		keyword, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+
+		switch keyword {
+		case "@Summary":
+			annotation.Summary = value
+		case "@Description":
+			annotation.Description = value
+		case "@Tags":
+			for _, tag := range strings.Split(value, ",") {
+				annotation.Tags = append(annotation.Tags, strings.TrimSpace(tag))
+			}
+		case "@Accept":
+			annotation.Accept = value
+		case "@Produce":
+			annotation.Produce = value
+		case "@Param":
+			if err := annotation.parseParam(value); err != nil {
+				return nil, err
+			}
+		case "@Success", "@Failure":
+			if err := annotation.parseResponse(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return annotation, nil
+}
+
+// splitDescription splits the fields of an annotation line from its quoted description
+func splitDescription(value string) ([]string, string) {
+	description := ""
+	if i := strings.Index(value, `"`); i >= 0 {
+		description = strings.Trim(value[i:], `"`)
+		value = value[:i]
+	}
+	return strings.Fields(value), description
+}
+
+// parseParam parses `name in type required "description"`
+func (a *parsedAnnotation) parseParam(value string) error {
+	fields, description := splitDescription(value)
+	if len(fields) != 4 {
+		return fmt.Errorf("invalid @Param %q", value)
+	}
+	required, err := strconv.ParseBool(fields[3])
+	if err != nil {
+		return fmt.Errorf("invalid @Param %q: %w", value, err)
+	}
+	param := ParamAnnotation{Name: fields[0], In: fields[1], Required: required, Description: description}
+	model := ""
+	if primitive, ok := primitiveTypes[fields[2]]; ok {
+		param.Type = primitive
+	} else {
+		model = fields[2]
+	}
+	a.Params = append(a.Params, param)
+	a.paramModels = append(a.paramModels, model)
+	return nil
+}
+
+// parseResponse parses `code {kind} type "description"`, where only the code is required
+func (a *parsedAnnotation) parseResponse(value string) error {
+	fields, description := splitDescription(value)
+	if len(fields) == 0 {
+		return fmt.Errorf("invalid response %q", value)
+	}
+	code, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("invalid response code %q", fields[0])
+	}
+	response := ResponseAnnotation{Code: code, Description: description}
+	model := ""
+	if len(fields) > 1 {
+		response.Kind = strings.Trim(fields[1], "{}")
+	}
+	if len(fields) > 2 && response.Kind != "file" && fields[2] != "gin.H" {
+		if _, ok := primitiveTypes[fields[2]]; !ok {
+			model = fields[2]
+		}
+	}
+	a.Responses = append(a.Responses, response)
+	a.responseModels = append(a.responseModels, model)
+	return nil
+}
+
+// writeAnnotations returns the formatted source of the generated file
+func writeAnnotations(pkgName string, annotations map[string]*parsedAnnotation, imports map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by openapi-gen from the handler annotations; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+
+	imports["openapi"] = "autostrike/internal/infrastructure/api/openapi"
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf.WriteString("import (\n")
+	for _, name := range names {
+		if filepath.Base(imports[name]) == name {
+			fmt.Fprintf(&buf, "%q\n", imports[name])
+		} else {
+			fmt.Fprintf(&buf, "%s %q\n", name, imports[name])
+		}
+	}
+	buf.WriteString(")\n\n")
+
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("// OpenAPIAnnotations documents the handler methods in the OpenAPI specification\n")
+	buf.WriteString("var OpenAPIAnnotations = map[string]openapi.Annotation{\n")
+	for _, key := range keys {
+		a := annotations[key]
+		fmt.Fprintf(&buf, "%q: {\n", key)
+		writeString(&buf, "Summary", a.Summary)
+		writeString(&buf, "Description", a.Description)
+		if len(a.Tags) > 0 {
+			fmt.Fprintf(&buf, "Tags: %#v,\n", a.Tags)
+		}
+		writeString(&buf, "Accept", a.Accept)
+		writeString(&buf, "Produce", a.Produce)
+		if len(a.Params) > 0 {
+			buf.WriteString("Params: []openapi.ParamAnnotation{\n")
+			for i, p := range a.Params {
+				fields := []string{fmt.Sprintf("Name: %q", p.Name), fmt.Sprintf("In: %q", p.In)}
+				if p.Type != "" {
+					fields = append(fields, fmt.Sprintf("Type: %q", p.Type))
+				}
+				if p.Required {
+					fields = append(fields, "Required: true")
+				}
+				if p.Description != "" {
+					fields = append(fields, fmt.Sprintf("Description: %q", p.Description))
+				}
+				if model := a.paramModels[i]; model != "" {
+					fields = append(fields, "Model: "+modelExpr(model))
+				}
+				fmt.Fprintf(&buf, "{%s},\n", strings.Join(fields, ", "))
+			}
+			buf.WriteString("},\n")
+		}
+		if len(a.Responses) > 0 {
+			buf.WriteString("Responses: []openapi.ResponseAnnotation{\n")
+			for i, r := range a.Responses {
+				fields := []string{fmt.Sprintf("Code: %d", r.Code)}
+				if r.Kind != "" {
+					fields = append(fields, fmt.Sprintf("Kind: %q", r.Kind))
+				}
+				if model := a.responseModels[i]; model != "" {
+					fields = append(fields, "Model: "+modelExpr(model))
+				}
+				if r.Description != "" {
+					fields = append(fields, fmt.Sprintf("Description: %q", r.Description))
+				}
+				fmt.Fprintf(&buf, "{%s},\n", strings.Join(fields, ", "))
+			}
+			buf.WriteString("},\n")
+		}
+		buf.WriteString("},\n")
+	}
+	buf.WriteString("}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+func writeString(buf *bytes.Buffer, field, value string) {
+	if value != "" {
+		fmt.Fprintf(buf, "%s: %q,\n", field, value)
+	}
+}
+
+// modelExpr returns the Go expression of a typed nil pointer to model
+func modelExpr(model string) string {
+	return "(*" + model + ")(nil)"
+}
+
+// WriteAnnotations generates the annotations file of the handler package in dir
+func WriteAnnotations(dir string) error {
+	source, err := GenerateAnnotations(dir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, GeneratedFile), source, 0o644)
+}
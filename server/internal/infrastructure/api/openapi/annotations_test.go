@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testHandlerSource = `package handlers
+
+import (
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ThingHandler struct{}
+
+// ListThings godoc
+// @Summary List things
+// @Tags things
+// @Produce json
+// @Param limit query int false "Maximum number of things"
+// @Success 200 {array} entity.Agent
+// @Failure 500 {object} gin.H
+// @Router /api/v1/things [get]
+func (h *ThingHandler) ListThings(c *gin.Context) {}
+
+// CreateThing godoc
+// @Summary Create a thing
+// @Accept json
+// @Param request body CreateThingRequest true "Thing"
+// @Success 204
+// @Router /api/v1/things [post]
+func (h *ThingHandler) CreateThing(c *gin.Context) {}
+
+// helper is not annotated
+func (h *ThingHandler) helper() {}
+`
+
+func TestGenerateAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "thing_handler.go"), []byte(testHandlerSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := GenerateAnnotations(dir)
+	if err != nil {
+		t.Fatalf("GenerateAnnotations failed: %v", err)
+	}
+	got := string(source)
+	for _, want := range []string{
+		"package handlers",
+		`"autostrike/internal/domain/entity"`,
+		`"ThingHandler.ListThings": {`,
+		`{Name: "limit", In: "query", Type: "integer", Description: "Maximum number of things"}`,
+		`{Code: 200, Kind: "array", Model: (*entity.Agent)(nil)}`,
+		`{Code: 500, Kind: "object"}`,
+		`{Name: "request", In: "body", Required: true, Description: "Thing", Model: (*CreateThingRequest)(nil)}`,
+		`{Code: 204}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected generated source to contain %s, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "helper") || strings.Contains(got, "gin-gonic") {
+		t.Errorf("unexpected annotation or import:\n%s", got)
+	}
+}
+
+func TestGenerateAnnotations_UnknownPackage(t *testing.T) {
+	dir := t.TempDir()
+	source := strings.Replace(testHandlerSource, `"autostrike/internal/domain/entity"`, "", 1)
+	if err := os.WriteFile(filepath.Join(dir, "thing_handler.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GenerateAnnotations(dir); err == nil || !strings.Contains(err.Error(), "entity.Agent") {
+		t.Errorf("expected an unknown package error, got %v", err)
+	}
+}
+
+func TestParseAnnotation_Invalid(t *testing.T) {
+	for _, doc := range []string{
+		"@Param id path string\n",
+		"@Param id path string maybe \"ID\"\n",
+		"@Success ok\n",
+	} {
+		if _, err := parseAnnotation(doc); err == nil {
+			t.Errorf("expected an error for %q", doc)
+		}
+	}
+
+	annotation, err := parseAnnotation("ListThings returns things\n")
+	if err != nil || annotation != nil {
+		t.Errorf("expected no annotation, got %+v, %v", annotation, err)
+	}
+}
@@ -0,0 +1,339 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bearerScheme is the security scheme of the operations requiring a JWT
+const bearerScheme = "bearerAuth"
+
+// Builder builds the document of the routes of a router
+type Builder struct {
+	info        Info
+	annotations map[string]Annotation
+	public      map[string]bool // Operations not requiring a token, by "METHOD path"
+}
+
+// NewBuilder creates a builder documenting the routes with annotations,
+// keyed by the "Receiver.Method" of their handlers
+func NewBuilder(info Info, annotations map[string]Annotation) *Builder {
+	return &Builder{info: info, annotations: annotations, public: make(map[string]bool)}
+}
+
+// SetPublic marks routes as not requiring a token
+func (b *Builder) SetPublic(routes gin.RoutesInfo) {
+	for _, route := range routes {
+		b.public[route.Method+" "+route.Path] = true
+	}
+}
+
+// Build documents routes. Static file routes and HEAD routes are left out.
+func (b *Builder) Build(routes gin.RoutesInfo) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    b.info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+		Security: []map[string][]string{{bearerScheme: {}}},
+	}
+	schemas := newSchemaRegistry()
+	schemas.schemas["Error"] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"error": {Type: "string"}},
+		Required:   []string{"error"},
+	}
+
+	sorted := make(gin.RoutesInfo, len(routes))
+	copy(sorted, routes)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	operationIDs := make(map[string]int)
+	tags := make(map[string]bool)
+	for _, route := range sorted {
+		if route.Method == http.MethodHead || strings.HasPrefix(route.Handler, "github.com/gin-gonic/gin.") {
+			continue
+		}
+		op := b.operation(route, schemas)
+		if operationIDs[op.OperationID]++; operationIDs[op.OperationID] > 1 {
+			op.OperationID += strconv.Itoa(operationIDs[op.OperationID])
+		}
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+
+		oapiPath := convertPath(route.Path)
+		item := doc.Paths[oapiPath]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[oapiPath] = item
+		}
+		switch route.Method {
+		case http.MethodGet:
+			item.Get = op
+		case http.MethodPost:
+			item.Post = op
+		case http.MethodPut:
+			item.Put = op
+		case http.MethodDelete:
+			item.Delete = op
+		case http.MethodPatch:
+			item.Patch = op
+		}
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components.Schemas = schemas.schemas
+	return doc
+}
+
+// operation documents a route, from the annotation of its handler when it has one
+func (b *Builder) operation(route gin.RouteInfo, schemas *schemaRegistry) *Operation {
+	key := handlerKey(route.Handler)
+	annotation, annotated := b.annotations[key]
+
+	op := &Operation{
+		OperationID: operationID(key, route),
+		Summary:     annotation.Summary,
+		Description: annotation.Description,
+		Tags:        annotation.Tags,
+		Responses:   make(map[string]*Response),
+	}
+	if op.Summary == "" {
+		op.Summary = summaryOf(key, route)
+	}
+	if len(op.Tags) == 0 {
+		op.Tags = []string{tagOf(route.Path)}
+	}
+	if b.public[route.Method+" "+route.Path] {
+		op.Security = &[]map[string][]string{}
+	}
+
+	// Path parameters come from the route, described by the annotation when it names them
+	described := make(map[string]ParamAnnotation)
+	for _, p := range annotation.Params {
+		described[p.In+" "+p.Name] = p
+	}
+	for _, segment := range strings.Split(route.Path, "/") {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			name := segment[1:]
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name: name, In: "path", Required: true,
+				Description: described["path "+name].Description,
+				Schema:      &Schema{Type: "string"},
+			})
+		}
+	}
+
+	form := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, p := range annotation.Params {
+		switch p.In {
+		case "query", "header":
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name: p.Name, In: p.In, Required: p.Required, Description: p.Description,
+				Schema: primitiveSchema(p.Type),
+			})
+		case "body":
+			schema := primitiveSchema(p.Type)
+			if p.Model != nil {
+				schema = schemas.schemaOf(reflect.TypeOf(p.Model).Elem())
+			}
+			op.RequestBody = &RequestBody{
+				Description: p.Description,
+				Required:    p.Required,
+				Content:     map[string]*MediaType{contentType(annotation.Accept): {Schema: schema}},
+			}
+		case "formData":
+			form.Properties[p.Name] = primitiveSchema(p.Type)
+			if p.Required {
+				form.Required = append(form.Required, p.Name)
+			}
+		}
+	}
+	if len(form.Properties) > 0 {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{"multipart/form-data": {Schema: form}},
+		}
+	}
+	if !annotated && (route.Method == http.MethodPost || route.Method == http.MethodPut || route.Method == http.MethodPatch) {
+		op.RequestBody = &RequestBody{
+			Content: map[string]*MediaType{"application/json": {Schema: &Schema{Type: "object"}}},
+		}
+	}
+
+	for _, r := range annotation.Responses {
+		op.Responses[strconv.Itoa(r.Code)] = response(r, annotation.Produce, schemas)
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+	return op
+}
+
+// response documents an annotated response
+func response(r ResponseAnnotation, produce string, schemas *schemaRegistry) *Response {
+	resp := &Response{Description: r.Description}
+	if resp.Description == "" {
+		resp.Description = http.StatusText(r.Code)
+	}
+
+	var schema *Schema
+	switch {
+	case r.Kind == "file":
+		return &Response{
+			Description: resp.Description,
+			Content:     map[string]*MediaType{contentType(produce): {Schema: &Schema{Type: "string", Format: "binary"}}},
+		}
+	case r.Kind == "":
+		return resp
+	case r.Model != nil:
+		schema = schemas.schemaOf(reflect.TypeOf(r.Model).Elem())
+	case r.Code >= 400:
+		schema = &Schema{Ref: "#/components/schemas/Error"}
+	default:
+		schema = &Schema{Type: "object", AdditionalProperties: true}
+	}
+	if r.Kind == "array" {
+		schema = &Schema{Type: "array", Items: schema}
+	}
+	resp.Content = map[string]*MediaType{"application/json": {Schema: schema}}
+	return resp
+}
+
+// primitiveSchema returns the schema of a primitive parameter type
+func primitiveSchema(t string) *Schema {
+	switch t {
+	case "":
+		return &Schema{Type: "string"}
+	case "file":
+		return &Schema{Type: "string", Format: "binary"}
+	default:
+		return &Schema{Type: t}
+	}
+}
+
+// contentType expands the short content types of annotations
+func contentType(short string) string {
+	switch short {
+	case "", "json":
+		return "application/json"
+	case "plain":
+		return "text/plain"
+	case "octet-stream":
+		return "application/octet-stream"
+	case "html":
+		return "text/html"
+	default:
+		return short
+	}
+}
+
+// convertPath turns the gin parameters of path into OpenAPI ones: /agents/{paw}
+func convertPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// handlerKey returns the "Receiver.Method" of a handler method from its
+// function name, "autostrike/.../handlers.(*AgentHandler).ListAgents-fm",
+// or "" for other functions
+func handlerKey(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	start := strings.Index(name, ".(*")
+	end := strings.Index(name, ").")
+	if start < 0 || end < start {
+		return ""
+	}
+	return name[start+3:end] + name[end+1:]
+}
+
+// operationID names an operation after its handler, "agentListAgents", or
+// after its method and path for anonymous handlers
+func operationID(key string, route gin.RouteInfo) string {
+	if key != "" {
+		receiver, method, _ := strings.Cut(key, ".")
+		return lowerFirst(strings.TrimSuffix(receiver, "Handler")) + method
+	}
+	id := strings.ToLower(route.Method)
+	for _, segment := range strings.Split(route.Path, "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			id += upperFirst(word)
+		}
+	}
+	return id
+}
+
+// summaryOf derives a summary from the handler method name, "List agents"
+func summaryOf(key string, route gin.RouteInfo) string {
+	_, method, ok := strings.Cut(key, ".")
+	if !ok {
+		return route.Method + " " + route.Path
+	}
+	// Words start at an uppercase letter, except inside acronyms: GetSMTPConfig
+	runes := []rune(method)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		if !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		if unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+	for i, word := range words {
+		if word != strings.ToUpper(word) || len(word) == 1 {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return upperFirst(strings.Join(words, " "))
+}
+
+// tagOf groups unannotated routes by the first segment of their path after the API prefix
+func tagOf(path string) string {
+	path = strings.TrimPrefix(path, "/api/v1")
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if segment == "" {
+		return "system"
+	}
+	return segment
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testThing struct {
+	Name string `json:"name"`
+}
+
+func testRoutes() gin.RoutesInfo {
+	return gin.RoutesInfo{
+		{Method: http.MethodPost, Path: "/api/v1/auth/login", Handler: "autostrike/internal/infrastructure/http/handlers.(*AuthHandler).Login-fm"},
+		{Method: http.MethodGet, Path: "/api/v1/things/:id", Handler: "autostrike/internal/infrastructure/http/handlers.(*ThingHandler).GetThing-fm"},
+		{Method: http.MethodHead, Path: "/api/v1/things/:id", Handler: "autostrike/internal/infrastructure/http/handlers.(*ThingHandler).GetThing-fm"},
+		{Method: http.MethodPut, Path: "/api/v1/things/:id", Handler: "autostrike/internal/infrastructure/http/handlers.(*ThingHandler).UpdateThing-fm"},
+		{Method: http.MethodGet, Path: "/metrics", Handler: "autostrike/internal/infrastructure/api/rest.NewServer.func1"},
+		{Method: http.MethodGet, Path: "/assets/*filepath", Handler: "github.com/gin-gonic/gin.(*RouterGroup).createStaticHandler.func1"},
+	}
+}
+
+func TestBuilder_Build(t *testing.T) {
+	builder := NewBuilder(Info{Title: "Test", Version: "1.0"}, map[string]Annotation{
+		"ThingHandler.GetThing": {
+			Summary: "Get a thing",
+			Tags:    []string{"things"},
+			Params:  []ParamAnnotation{{Name: "id", In: "path", Required: true, Description: "Thing ID"}},
+			Responses: []ResponseAnnotation{
+				{Code: 200, Kind: "object", Model: (*testThing)(nil)},
+				{Code: 404, Kind: "object"},
+			},
+		},
+	})
+	routes := testRoutes()
+	builder.SetPublic(routes[:1])
+	doc := builder.Build(routes)
+
+	if doc.OpenAPI != Version || doc.Info.Title != "Test" {
+		t.Errorf("unexpected header %s %+v", doc.OpenAPI, doc.Info)
+	}
+	if len(doc.Paths) != 3 {
+		t.Fatalf("expected 3 paths without static files, got %v", doc.Paths)
+	}
+
+	login := doc.Paths["/api/v1/auth/login"].Post
+	if login == nil || login.Security == nil || len(*login.Security) != 0 {
+		t.Errorf("expected the login to be public, got %+v", login)
+	}
+	if login.OperationID != "authLogin" || login.Summary != "Login" || login.Tags[0] != "auth" {
+		t.Errorf("unexpected unannotated operation %+v", login)
+	}
+	if login.RequestBody == nil {
+		t.Error("expected a generic body on an unannotated POST")
+	}
+
+	get := doc.Paths["/api/v1/things/{id}"].Get
+	if get.Security != nil {
+		t.Error("expected the document security on protected operations")
+	}
+	if get.Summary != "Get a thing" || len(get.Parameters) != 1 || get.Parameters[0].Description != "Thing ID" {
+		t.Errorf("unexpected annotated operation %+v", get)
+	}
+	if ref := get.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/openapi.testThing" {
+		t.Errorf("expected a model reference, got %q", ref)
+	}
+	if ref := get.Responses["404"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/Error" {
+		t.Errorf("expected an error reference, got %q", ref)
+	}
+	if doc.Components.Schemas["openapi.testThing"] == nil {
+		t.Error("expected the model component")
+	}
+
+	if put := doc.Paths["/api/v1/things/{id}"].Put; put == nil || put.Summary != "Update thing" {
+		t.Errorf("expected a summary from the method name, got %+v", put)
+	}
+	if metrics := doc.Paths["/metrics"].Get; metrics.OperationID != "getMetrics" || metrics.Tags[0] != "metrics" {
+		t.Errorf("unexpected anonymous operation %+v", metrics)
+	}
+}
+
+func TestBuilder_DuplicateOperationIDs(t *testing.T) {
+	handler := "autostrike/internal/infrastructure/http/handlers.(*ThingHandler).ListThings-fm"
+	doc := NewBuilder(Info{}, nil).Build(gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/api/v1/things", Handler: handler},
+		{Method: http.MethodGet, Path: "/api/v1/widgets", Handler: handler},
+	})
+
+	if doc.Paths["/api/v1/things"].Get.OperationID != "thingListThings" ||
+		doc.Paths["/api/v1/widgets"].Get.OperationID != "thingListThings2" {
+		t.Errorf("expected unique operation IDs, got %s and %s",
+			doc.Paths["/api/v1/things"].Get.OperationID, doc.Paths["/api/v1/widgets"].Get.OperationID)
+	}
+}
+
+func TestHandlerKey(t *testing.T) {
+	tests := map[string]string{
+		"autostrike/internal/infrastructure/http/handlers.(*AgentHandler).ListAgents-fm": "AgentHandler.ListAgents",
+		"autostrike/internal/infrastructure/api/rest.NewServer.func1":                    "",
+		"github.com/gin-gonic/gin.LoggerWithConfig.func1":                                "",
+	}
+	for name, want := range tests {
+		if got := handlerKey(name); got != want {
+			t.Errorf("handlerKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestConvertPath(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/agents":              "/api/v1/agents",
+		"/api/v1/agents/:paw":         "/api/v1/agents/{paw}",
+		"/api/v1/files/:id/*filepath": "/api/v1/files/{id}/{filepath}",
+	}
+	for path, want := range tests {
+		if got := convertPath(path); got != want {
+			t.Errorf("convertPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestSummaryOf(t *testing.T) {
+	tests := map[string]string{
+		"AgentHandler.ListAgents":      "List agents",
+		"SMTPHandler.GetSMTPConfig":    "Get SMTP config",
+		"ExportHandler.ExportToPDF":    "Export to PDF",
+		"TechniqueHandler.GetByTactic": "Get by tactic",
+		"HealthHandler.A":              "A",
+	}
+	for key, want := range tests {
+		if got := summaryOf(key, gin.RouteInfo{}); got != want {
+			t.Errorf("summaryOf(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
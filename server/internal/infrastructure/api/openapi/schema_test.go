@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testNode struct {
+	testBase
+	Name     string            `json:"name"`
+	Note     string            `json:"note,omitempty"`
+	Parent   *testNode         `json:"parent,omitempty"`
+	Score    *float64          `json:"score"`
+	Created  time.Time         `json:"created_at"`
+	Labels   map[string]string `json:"labels"`
+	Children []testNode        `json:"children"`
+	Internal string            `json:"-"`
+	hidden   string
+}
+
+type testRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Limit       int    `json:"limit" binding:"omitempty,min=1"`
+}
+
+func TestSchemaOf_Struct(t *testing.T) {
+	r := newSchemaRegistry()
+	ref := r.schemaOf(reflect.TypeOf(testNode{}))
+	if ref.Ref != "#/components/schemas/openapi.testNode" {
+		t.Fatalf("expected a reference, got %+v", ref)
+	}
+
+	s := r.schemas["openapi.testNode"]
+	if s == nil || s.Type != "object" {
+		t.Fatalf("expected an object component, got %+v", s)
+	}
+	for _, name := range []string{"id", "name", "note", "parent", "score", "created_at", "labels", "children"} {
+		if s.Properties[name] == nil {
+			t.Errorf("expected property %s", name)
+		}
+	}
+	if len(s.Properties) != 8 {
+		t.Errorf("expected 8 properties, got %d", len(s.Properties))
+	}
+	if got := s.Properties["parent"].Ref; got != ref.Ref {
+		t.Errorf("expected the recursive parent to reference the component, got %q", got)
+	}
+	if !s.Properties["score"].Nullable || s.Properties["score"].Format != "double" {
+		t.Errorf("expected a nullable double score, got %+v", s.Properties["score"])
+	}
+	if s.Properties["created_at"].Format != "date-time" {
+		t.Errorf("expected a date-time, got %+v", s.Properties["created_at"])
+	}
+	if s.Properties["children"].Type != "array" || s.Properties["children"].Items.Ref != ref.Ref {
+		t.Errorf("expected an array of nodes, got %+v", s.Properties["children"])
+	}
+
+	want := []string{"children", "created_at", "id", "labels", "name", "score"}
+	if !reflect.DeepEqual(s.Required, want) {
+		t.Errorf("expected required %v, got %v", want, s.Required)
+	}
+}
+
+func TestSchemaOf_RequestBody(t *testing.T) {
+	r := newSchemaRegistry()
+	r.schemaOf(reflect.TypeOf(testRequest{}))
+
+	s := r.schemas["openapi.testRequest"]
+	if !reflect.DeepEqual(s.Required, []string{"name"}) {
+		t.Errorf("expected only name to be required, got %v", s.Required)
+	}
+}
+
+func TestSchemaOf_Primitives(t *testing.T) {
+	r := newSchemaRegistry()
+	tests := []struct {
+		value  any
+		typ    string
+		format string
+	}{
+		{true, "boolean", ""},
+		{0, "integer", "int32"},
+		{int64(0), "integer", "int64"},
+		{time.Second, "integer", "int64"},
+		{"", "string", ""},
+		{[]byte{}, "string", "byte"},
+	}
+	for _, tt := range tests {
+		s := r.schemaOf(reflect.TypeOf(tt.value))
+		if s.Type != tt.typ || s.Format != tt.format {
+			t.Errorf("%T: expected %s/%s, got %s/%s", tt.value, tt.typ, tt.format, s.Type, s.Format)
+		}
+	}
+}
@@ -1,14 +1,19 @@
 package rest
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"autostrike/internal/application"
 	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/api/openapi"
 	"autostrike/internal/infrastructure/http/handlers"
 	"autostrike/internal/infrastructure/http/middleware"
 	"autostrike/internal/infrastructure/websocket"
@@ -27,6 +32,10 @@ type Server struct {
 	router       *gin.Engine
 	logger       *zap.Logger
 	cleanupFuncs []func()
+	eventStream  *handlers.EventStreamHandler // Dashboard event streams, ended on shutdown
+
+	httpMu     sync.Mutex
+	httpServer *http.Server
 }
 
 // ServerConfig contains server configuration options
@@ -35,18 +44,55 @@ type ServerConfig struct {
 	AgentSecret   string
 	EnableAuth    bool
 	DashboardPath string // Path to dashboard dist folder (empty = disabled)
+
+	// Networks allowed to reach the agent endpoints and the dashboard/API, any when empty
+	AgentAllowedNetworks []string
+	APIAllowedNetworks   []string
+	// Proxies whose X-Forwarded-For is trusted for the client IP, loopback when empty
+	TrustedProxies []string
 }
 
 // Services groups all application services for dependency injection
 type Services struct {
-	Agent        *application.AgentService
-	Scenario     *application.ScenarioService
-	Execution    *application.ExecutionService
-	Technique    *application.TechniqueService
-	Auth         *application.AuthService
-	Analytics    *application.AnalyticsService
-	Notification *application.NotificationService
-	Schedule     *application.ScheduleService
+	Agent           *application.AgentService
+	Scenario        *application.ScenarioService
+	Execution       *application.ExecutionService
+	Technique       *application.TechniqueService
+	Auth            *application.AuthService
+	Analytics       *application.AnalyticsService
+	Notification    *application.NotificationService
+	Schedule        *application.ScheduleService
+	Detection       *application.DetectionService
+	Readiness       *application.ReadinessService
+	Activity        *application.ActivityMonitor
+	ScoreBackfill   *application.ScoreBackfillService
+	AgentSelector   *application.AgentSelectorService
+	WebhookDelivery *application.WebhookDeliveryService
+	DeepLinks       *application.DeepLinkService
+	ContentImport   *application.ContentImportService
+	TechniqueSync   *application.TechniqueSyncService
+	Payload         *application.PayloadService
+	Artifact        *application.ArtifactService
+	AdHocTask       *application.AdHocTaskService
+	AgentUpdate     *application.AgentUpdateService
+	Beacon          *application.BeaconService
+	Health          *application.HealthService
+	Trash           *application.TrashService
+	Retention       *application.RetentionService
+	ConfigBundle    *application.ConfigBundleService
+	ContentPlan     *application.ContentPlanService
+	ContentReload   *application.ContentReloadService
+	Secrets         *application.SecretService
+	Search          *application.SearchService
+	Jobs            *application.JobService
+	ScoringProfile  *application.ScoringProfileService
+	Ticket          *application.TicketService
+	EmergencyStop   *application.EmergencyStopService
+	Annotation      *application.ResultAnnotationService
+	Evidence        *application.EvidenceService
+	Review          *application.ExecutionReviewService
+	APIKey          *application.APIKeyService
+	ResultQueue     *application.ResultQueue
 }
 
 // NewServerConfig creates a server config from environment variables
@@ -75,11 +121,25 @@ func NewServerConfig() *ServerConfig {
 	}
 
 	return &ServerConfig{
-		JWTSecret:     jwtSecret,
-		AgentSecret:   os.Getenv("AGENT_SECRET"),
-		EnableAuth:    enableAuth,
-		DashboardPath: dashboardPath,
+		JWTSecret:            jwtSecret,
+		AgentSecret:          os.Getenv("AGENT_SECRET"),
+		EnableAuth:           enableAuth,
+		DashboardPath:        dashboardPath,
+		AgentAllowedNetworks: splitList(os.Getenv("AGENT_ALLOWED_NETWORKS")),
+		APIAllowedNetworks:   splitList(os.Getenv("API_ALLOWED_NETWORKS")),
+		TrustedProxies:       splitList(os.Getenv("TRUSTED_PROXIES")),
+	}
+}
+
+// splitList splits a comma-separated list, skipping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
 
 // NewServer creates a new REST server with all routes configured
@@ -108,16 +168,43 @@ func NewServerWithConfig(
 
 	router := gin.New()
 
-	// Only trust the loopback proxy - prevents X-Forwarded-For spoofing in rate limiter
-	_ = router.SetTrustedProxies([]string{"127.0.0.1", "::1"})
+	// Only trust the loopback proxy by default - prevents X-Forwarded-For spoofing
+	// in the rate limiter and the network allowlists
+	trustedProxies := config.TrustedProxies
+	if len(trustedProxies) == 0 {
+		trustedProxies = []string{"127.0.0.1", "::1"}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		logger.Fatal("Configuration error: invalid TRUSTED_PROXIES", zap.Error(err))
+	}
+
+	agentNetworks, err := entity.ParseNetworkList(config.AgentAllowedNetworks)
+	if err != nil {
+		logger.Fatal("Configuration error: invalid AGENT_ALLOWED_NETWORKS", zap.Error(err))
+	}
+	apiNetworks, err := entity.ParseNetworkList(config.APIAllowedNetworks)
+	if err != nil {
+		logger.Fatal("Configuration error: invalid API_ALLOWED_NETWORKS", zap.Error(err))
+	}
 
 	// Request body size limit (10 MB)
 	const maxBodySize int64 = 10 << 20
 	router.MaxMultipartMemory = maxBodySize
 
 	// Global middleware
+	if len(agentNetworks) > 0 || len(apiNetworks) > 0 {
+		router.Use(middleware.NetworkAllowlistMiddleware(middleware.NetworkAllowlistConfig{
+			Agent: agentNetworks,
+			API:   apiNetworks,
+		}, logger))
+		logger.Info("Network allowlists enabled",
+			zap.Strings("agent_networks", agentNetworks.Strings()),
+			zap.Strings("api_networks", apiNetworks.Strings()),
+		)
+	}
 	router.Use(middleware.BodySizeLimitMiddleware(maxBodySize))
 	router.Use(middleware.SecurityHeadersMiddleware())
+	router.Use(middleware.TracingMiddleware())
 	router.Use(middleware.LoggingMiddleware(logger))
 	router.Use(middleware.RecoveryMiddleware(logger))
 
@@ -129,11 +216,9 @@ func NewServerWithConfig(
 		})
 	})
 
-	// WebSocket routes (uses agent auth)
-	if hub != nil {
-		wsHandler := handlers.NewWebSocketHandler(hub, services.Agent, logger)
-		wsHandler.SetExecutionService(services.Execution)
-		wsHandler.RegisterRoutes(router)
+	// Liveness and readiness probes (always public)
+	if services.Health != nil {
+		handlers.NewHealthHandler(services.Health).RegisterRoutes(router)
 	}
 
 	// Token blacklist for logout revocation
@@ -144,15 +229,72 @@ func NewServerWithConfig(
 		cleanupFuncs = append(cleanupFuncs, tokenBlacklist.Close)
 	}
 
+	// WebSocket routes (uses agent auth, and the user's access token for dashboards)
+	if hub != nil {
+		wsHandler := handlers.NewWebSocketHandler(hub, services.Agent, logger)
+		wsHandler.SetAgentSecret(config.AgentSecret)
+		wsHandler.SetExecutionService(services.Execution)
+		if services.ResultQueue != nil {
+			wsHandler.SetResultQueue(services.ResultQueue)
+		}
+		wsHandler.SetDetectionService(services.Detection)
+		wsHandler.SetArtifactService(services.Artifact)
+		wsHandler.SetAdHocTaskService(services.AdHocTask)
+		wsHandler.SetAgentUpdateService(services.AgentUpdate)
+		wsHandler.SetBeaconService(services.Beacon)
+		wsHandler.SetNotificationService(services.Notification)
+		if services.Activity != nil {
+			wsHandler.SetActivityMonitor(services.Activity)
+		}
+		if config.EnableAuth && config.JWTSecret != "" {
+			wsHandler.SetDashboardAuth(middleware.WebSocketAuthMiddleware(&middleware.AuthConfig{
+				JWTSecret:      config.JWTSecret,
+				TokenBlacklist: tokenBlacklist,
+			}))
+		} else {
+			wsHandler.SetDashboardAuth(middleware.NoAuthMiddleware())
+		}
+		wsHandler.RegisterRoutes(router)
+	}
+
+	// Payload downloads (agent auth - the signed token must be the agent's, for a task it runs)
+	if services.Payload != nil {
+		if config.AgentSecret == "" {
+			logger.Warn("AGENT_SECRET is not set: agents cannot download task payloads")
+		}
+		router.GET("/payloads/:token",
+			middleware.AgentAuthMiddleware(&middleware.AuthConfig{AgentSecret: config.AgentSecret}),
+			handlers.NewPayloadHandler(services.Payload).DownloadPayload)
+	}
+
 	// Auth routes (public - no auth middleware required, with rate limiting)
 	if services.Auth != nil {
 		loginLimiter := middleware.NewRateLimiter(5, 1*time.Minute)   // 5 attempts/min per IP
 		refreshLimiter := middleware.NewRateLimiter(10, 1*time.Minute) // 10 refreshes/min per IP
 		cleanupFuncs = append(cleanupFuncs, loginLimiter.Close, refreshLimiter.Close)
 		authHandler := handlers.NewAuthHandlerWithBlacklist(services.Auth, tokenBlacklist)
+		authHandler.SetLogger(logger)
+		if services.Activity != nil {
+			authHandler.SetActivityMonitor(services.Activity)
+		}
 		authHandler.RegisterRoutesWithRateLimit(router, loginLimiter, refreshLimiter)
+		resetLimiter := middleware.NewRateLimiter(5, 15*time.Minute) // 5 reset requests/15min per IP
+		cleanupFuncs = append(cleanupFuncs, resetLimiter.Close)
+		authHandler.RegisterPasswordResetRoutes(router, resetLimiter)
 	}
 
+	// OpenAPI specification and Swagger UI (public). Every route registered
+	// before the API group is documented as not requiring a token.
+	openAPIBuilder := openapi.NewBuilder(openapi.Info{
+		Title:       "AutoStrike API",
+		Description: "Breach and attack simulation platform",
+		Version:     "1.0",
+	}, handlers.OpenAPIAnnotations)
+	openAPIHandler := handlers.NewOpenAPIHandler(openAPIBuilder, router.Routes)
+	router.GET("/api/v1/openapi.json", openAPIHandler.GetSpec)
+	router.GET("/api/docs", openAPIHandler.SwaggerUI)
+	openAPIBuilder.SetPublic(router.Routes())
+
 	// API v1 routes
 	api := router.Group("/api/v1")
 
@@ -163,6 +305,9 @@ func NewServerWithConfig(
 			AgentSecret:    config.AgentSecret,
 			TokenBlacklist: tokenBlacklist,
 		}
+		if services.APIKey != nil {
+			authConfig.APIKeys = services.APIKey
+		}
 		api.Use(middleware.AuthMiddleware(authConfig))
 		logger.Info("Authentication middleware enabled for API routes")
 	} else {
@@ -175,6 +320,14 @@ func NewServerWithConfig(
 	routeCleanups := registerRoutesWithPermissions(api, services, hub, logger, tokenBlacklist)
 	cleanupFuncs = append(cleanupFuncs, routeCleanups...)
 
+	// Dashboard events as Server-Sent Events, for environments blocking WebSockets
+	var eventStream *handlers.EventStreamHandler
+	if hub != nil {
+		eventStream = handlers.NewEventStreamHandler(hub, logger)
+		eventStream.SetNotificationService(services.Notification)
+		api.GET("/events/stream", eventStream.Stream)
+	}
+
 	// Serve dashboard static files if path is configured
 	if config.DashboardPath != "" {
 		setupDashboardRoutes(router, config.DashboardPath, logger)
@@ -184,6 +337,7 @@ func NewServerWithConfig(
 		router:       router,
 		logger:       logger,
 		cleanupFuncs: cleanupFuncs,
+		eventStream:  eventStream,
 	}
 }
 
@@ -241,6 +395,19 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 		cleanups = append(cleanups, logoutLimiter.Close)
 		authHandler.RegisterLogoutRoute(api, logoutLimiter)
 
+		// API keys - long-lived credentials for scripts and autostrikectl, acting with the role of their owner
+		if services.APIKey != nil {
+			apiKeyHandler := handlers.NewAPIKeyHandler(services.APIKey)
+			api.GET("/auth/api-keys", apiKeyHandler.ListAPIKeys)
+			api.POST("/auth/api-keys", apiKeyHandler.CreateAPIKey)
+			api.DELETE("/auth/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+		}
+
+		// Profile of the current user, apart from the admin user management
+		profileHandler := handlers.NewProfileHandler(services.Auth)
+		api.GET("/users/me", profileHandler.GetProfile)
+		api.PUT("/users/me", profileHandler.UpdateProfile)
+
 		// Admin routes (requires admin role)
 		adminHandler := handlers.NewAdminHandler(services.Auth)
 		admin := api.Group("/admin")
@@ -251,12 +418,89 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 			admin.POST("/users", adminHandler.CreateUser)
 			admin.PUT(routeUserByID, adminHandler.UpdateUser)
 			admin.PUT(routeUserByID+"/role", adminHandler.UpdateUserRole)
+			admin.PUT(routeUserByID+"/workspace", adminHandler.SetUserWorkspace)
 			admin.DELETE(routeUserByID, adminHandler.DeactivateUser)
 			admin.POST(routeUserByID+"/reactivate", adminHandler.ReactivateUser)
 			admin.POST(routeUserByID+"/reset-password", adminHandler.ResetPassword)
+			admin.POST(routeUserByID+"/unlock", adminHandler.UnlockUser)
+			admin.GET("/lockouts", adminHandler.ListLockouts)
+			admin.DELETE("/lockouts/ips/:ip", adminHandler.UnlockIP)
+
+			// Activity anomalies (audit of unusual operator activity)
+			if services.Activity != nil {
+				activityHandler := handlers.NewActivityHandler(services.Activity)
+				admin.GET("/activity/anomalies", activityHandler.ListAnomalies)
+			}
+
+			// Score recomputation backfill
+			if services.ScoreBackfill != nil {
+				scoreBackfillHandler := handlers.NewScoreBackfillHandler(services.ScoreBackfill)
+				admin.POST("/scores/recompute", scoreBackfillHandler.StartRecompute)
+				admin.GET("/scores/recompute", scoreBackfillHandler.ListJobs)
+				admin.GET("/scores/recompute/:id", scoreBackfillHandler.GetJob)
+				admin.GET("/scores/executions/:id/history", scoreBackfillHandler.GetScoreHistory)
+			}
+
+			// Technique catalog sync from upstream ATT&CK content
+			if services.TechniqueSync != nil {
+				techniqueSyncHandler := handlers.NewTechniqueSyncHandler(services.TechniqueSync)
+				admin.POST("/techniques/sync-mitre", techniqueSyncHandler.StartSync)
+				admin.GET("/techniques/sync-mitre", techniqueSyncHandler.ListJobs)
+				admin.GET("/techniques/sync-mitre/:id", techniqueSyncHandler.GetJob)
+			}
+
+			// Execution data retention and archival
+			if services.Retention != nil {
+				retentionHandler := handlers.NewRetentionHandler(services.Retention)
+				retentionHandler.SetJobService(services.Jobs)
+				admin.GET("/retention", retentionHandler.GetStatus)
+				admin.POST("/retention/run", retentionHandler.RunNow)
+			}
+
+			// Result ingestion queue
+			if services.ResultQueue != nil {
+				admin.GET("/result-queue", handlers.NewResultQueueHandler(services.ResultQueue).GetStats)
+			}
 		}
 	}
 
+	// Emergency stop - kill switch for all activity, re-armed explicitly (admin only)
+	if services.EmergencyStop != nil {
+		stopHandler := handlers.NewEmergencyStopHandler(services.EmergencyStop)
+		api.GET("/admin/emergency-stop", adminOnly, stopHandler.GetStatus)
+		api.POST("/admin/emergency-stop", adminOnly, stopHandler.Trip)
+		api.POST("/admin/emergency-stop/rearm", adminOnly, stopHandler.Rearm)
+		api.GET("/admin/emergency-stop/history", adminOnly, stopHandler.ListHistory)
+	}
+
+	// Configuration bundle, to promote the configuration between servers (admin only)
+	if services.ConfigBundle != nil {
+		bundleHandler := handlers.NewConfigBundleHandler(services.ConfigBundle)
+		api.GET("/export/bundle", adminOnly, bundleHandler.ExportBundle)
+		api.POST("/import/bundle", adminOnly, bundleHandler.ImportBundle)
+	}
+
+	// Plan and apply technique and scenario YAML directories (admin only)
+	if services.ContentPlan != nil {
+		contentPlanHandler := handlers.NewContentPlanHandler(services.ContentPlan)
+		api.POST("/admin/content/plan", adminOnly, contentPlanHandler.PlanContent)
+		api.POST("/admin/content/apply", adminOnly, contentPlanHandler.ApplyContent)
+	}
+
+	// Reload the changed technique and scenario files of configs/ (admin only)
+	if services.ContentReload != nil {
+		reloadHandler := handlers.NewContentReloadHandler(services.ContentReload)
+		api.POST("/admin/reload", adminOnly, reloadHandler.ReloadContent)
+	}
+
+	// Secrets store of integration credentials (admin only, values are write-only)
+	if services.Secrets != nil {
+		secretHandler := handlers.NewSecretHandler(services.Secrets)
+		api.GET("/admin/secrets", adminOnly, secretHandler.ListSecrets)
+		api.PUT("/admin/secrets/:name", adminOnly, secretHandler.PutSecret)
+		api.DELETE("/admin/secrets/:name", adminOnly, secretHandler.DeleteSecret)
+	}
+
 	// Permission routes (all authenticated users can view)
 	permissionHandler := handlers.NewPermissionHandler()
 	permissions := api.Group("/permissions")
@@ -274,6 +518,71 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 		agents.POST("", perm(entity.PermissionAgentsCreate), agentHandler.RegisterAgent)
 		agents.DELETE("/:paw", perm(entity.PermissionAgentsDelete), agentHandler.DeleteAgent)
 		agents.POST("/:paw/heartbeat", perm(entity.PermissionAgentsView), agentHandler.Heartbeat)
+		agents.PUT("/:paw/networks", perm(entity.PermissionAgentsCreate), agentHandler.SetAllowedNetworks)
+		agents.GET("/:paw/availability", perm(entity.PermissionAgentsView), agentHandler.GetAvailability)
+		agents.POST("/bulk/preview", perm(entity.PermissionAgentsView), agentHandler.PreviewBulk)
+		agents.POST("/bulk/apply", perm(entity.PermissionAgentsDelete), agentHandler.ApplyBulk)
+
+		// Ad-hoc commands outside any scenario - admin only, every command is recorded
+		if services.AdHocTask != nil {
+			adhocHandler := handlers.NewAdHocTaskHandler(services.AdHocTask, hub)
+			agents.POST("/:paw/task", adminOnly, adhocHandler.RunTask)
+			agents.GET("/:paw/tasks", adminOnly, adhocHandler.ListTasks)
+			agents.GET("/:paw/tasks/:id", adminOnly, adhocHandler.GetTask)
+		}
+	}
+
+	// Agent releases - signed binaries for agent self-updates, managed by admins
+	if services.AgentUpdate != nil {
+		releaseHandler := handlers.NewAgentReleaseHandler(services.AgentUpdate)
+		releases := api.Group("/agent-releases")
+		{
+			releases.GET("", perm(entity.PermissionAgentsView), releaseHandler.ListReleases)
+			releases.POST("", adminOnly, releaseHandler.UploadRelease)
+			releases.PUT("/:id/rollout", adminOnly, releaseHandler.SetRollout)
+			releases.DELETE("/:id", adminOnly, releaseHandler.DeleteRelease)
+		}
+	}
+
+	// Beacon settings - check-in interval and jitter pushed to agents
+	if services.Beacon != nil {
+		beaconHandler := handlers.NewBeaconHandler(services.Beacon)
+		api.GET("/beacon-settings", perm(entity.PermissionAgentsView), beaconHandler.ListSettings)
+		api.GET("/agents/:paw/beacon", perm(entity.PermissionAgentsView), beaconHandler.GetAgentBeacon)
+		api.PUT("/agents/:paw/beacon", perm(entity.PermissionAgentsCreate), beaconHandler.SetAgentBeacon)
+		api.DELETE("/agents/:paw/beacon", perm(entity.PermissionAgentsCreate), beaconHandler.ClearAgentBeacon)
+		api.PUT("/agent-selectors/:id/beacon", perm(entity.PermissionAgentsCreate), beaconHandler.SetSelectorBeacon)
+		api.DELETE("/agent-selectors/:id/beacon", perm(entity.PermissionAgentsCreate), beaconHandler.ClearSelectorBeacon)
+	}
+
+	// Agent selectors - saved targeting expressions, managed with agent permissions
+	if services.AgentSelector != nil {
+		selectorHandler := handlers.NewAgentSelectorHandler(services.AgentSelector)
+		selectors := api.Group("/agent-selectors")
+		{
+			selectors.GET("", perm(entity.PermissionAgentsView), selectorHandler.ListSelectors)
+			selectors.GET("/:id", perm(entity.PermissionAgentsView), selectorHandler.GetSelector)
+			selectors.GET("/:id/agents", perm(entity.PermissionAgentsView), selectorHandler.GetMatchingAgents)
+			selectors.POST("", perm(entity.PermissionAgentsCreate), selectorHandler.CreateSelector)
+			selectors.PUT("/:id", perm(entity.PermissionAgentsCreate), selectorHandler.UpdateSelector)
+			selectors.DELETE("/:id", perm(entity.PermissionAgentsCreate), selectorHandler.DeleteSelector)
+		}
+	}
+
+	// Scoring profiles - versioned scoring models, edited with settings permissions
+	if services.ScoringProfile != nil {
+		profileHandler := handlers.NewScoringProfileHandler(services.ScoringProfile)
+		profiles := api.Group("/scoring-profiles")
+		{
+			profiles.GET("", perm(entity.PermissionAnalyticsView), profileHandler.ListProfiles)
+			profiles.GET("/:id", perm(entity.PermissionAnalyticsView), profileHandler.GetProfile)
+			profiles.GET("/:id/versions", perm(entity.PermissionAnalyticsView), profileHandler.ListVersions)
+			profiles.GET("/:id/versions/:version", perm(entity.PermissionAnalyticsView), profileHandler.GetVersion)
+			profiles.POST("", perm(entity.PermissionSettingsEdit), profileHandler.CreateProfile)
+			profiles.PUT("/:id", perm(entity.PermissionSettingsEdit), profileHandler.UpdateProfile)
+			profiles.DELETE("/:id", perm(entity.PermissionSettingsEdit), profileHandler.DeleteProfile)
+			profiles.POST("/:id/default", perm(entity.PermissionSettingsEdit), profileHandler.SetDefault)
+		}
 	}
 
 	// Techniques - view for all, import requires permission
@@ -286,6 +595,32 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 		techniques.GET("/platform/:platform", perm(entity.PermissionTechniquesView), techniqueHandler.GetByPlatform)
 		techniques.GET("/:id", perm(entity.PermissionTechniquesView), techniqueHandler.GetTechnique)
 		techniques.POST("/import", perm(entity.PermissionTechniquesImport), techniqueHandler.ImportTechniques)
+		techniques.PUT("/:id/status", perm(entity.PermissionTechniquesImport), techniqueHandler.UpdateTechniqueStatus)
+		techniques.PUT("/:id/metadata", perm(entity.PermissionTechniquesImport), techniqueHandler.UpdateTechniqueMetadata)
+		techniques.DELETE("/:id", perm(entity.PermissionTechniquesImport), techniqueHandler.DeleteTechnique)
+	}
+
+	// Content import - converted techniques and scenarios need both import permissions
+	if services.ContentImport != nil {
+		contentHandler := handlers.NewContentHandler(services.ContentImport)
+		contentHandler.SetJobService(services.Jobs)
+		content := api.Group("/content")
+		{
+			content.GET("/formats", perm(entity.PermissionTechniquesView), contentHandler.ListFormats)
+			content.POST("/import/:format", perm(entity.PermissionTechniquesImport, entity.PermissionScenariosImport), contentHandler.ImportContent)
+		}
+	}
+
+	// Payloads - view for all, upload and delete require the technique import permission
+	if services.Payload != nil {
+		payloadHandler := handlers.NewPayloadHandler(services.Payload)
+		payloads := api.Group("/payloads")
+		{
+			payloads.GET("", perm(entity.PermissionTechniquesView), payloadHandler.ListPayloads)
+			payloads.GET("/:name", perm(entity.PermissionTechniquesView), payloadHandler.GetPayload)
+			payloads.POST("", perm(entity.PermissionTechniquesImport), payloadHandler.UploadPayload)
+			payloads.DELETE("/:name", perm(entity.PermissionTechniquesImport), payloadHandler.DeletePayload)
+		}
 	}
 
 	// Executions - view for all, start/stop requires permission
@@ -295,31 +630,130 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 	} else {
 		executionHandler = handlers.NewExecutionHandler(services.Execution)
 	}
+	if services.Activity != nil {
+		executionHandler.SetActivityMonitor(services.Activity)
+	}
+	if services.AgentSelector != nil {
+		executionHandler.SetAgentSelectorService(services.AgentSelector)
+	}
+	executionHandler.SetJobService(services.Jobs)
 	executions := api.Group("/executions")
 	{
 		executions.GET("", perm(entity.PermissionExecutionsView), executionHandler.ListExecutions)
 		executions.GET("/:id", perm(entity.PermissionExecutionsView), executionHandler.GetExecution)
 		executions.GET("/:id/results", perm(entity.PermissionExecutionsView), executionHandler.GetResults)
+		executions.GET("/:id/facts", perm(entity.PermissionExecutionsView), executionHandler.GetFacts)
+		executions.GET("/:id/export", perm(entity.PermissionExecutionsView), executionHandler.ExportExecution)
+		executions.GET("/:id/snapshot", perm(entity.PermissionExecutionsView), executionHandler.GetSnapshot)
 		executions.POST("", perm(entity.PermissionExecutionsStart), executionHandler.StartExecution)
 		executions.POST("/:id/stop", perm(entity.PermissionExecutionsStop), executionHandler.StopExecution)
 		executions.POST("/:id/complete", perm(entity.PermissionExecutionsView), executionHandler.CompleteExecution)
 	}
 
+	// Result outputs, paged, and artifacts - files collected by agents, uploaded over the agent WebSocket
+	results := api.Group("/results")
+	results.GET("/:id/output", perm(entity.PermissionExecutionsView), executionHandler.GetResultOutput)
+	if services.Artifact != nil {
+		artifactHandler := handlers.NewArtifactHandler(services.Artifact)
+		results.GET("/:id/artifacts", perm(entity.PermissionExecutionsView), artifactHandler.ListArtifacts)
+		results.GET("/:id/artifacts/:artifactId", perm(entity.PermissionExecutionsView), artifactHandler.DownloadArtifact)
+	}
+
+	// Result evidence - screenshots and captures attached by operators for purple-team reviews
+	if services.Evidence != nil {
+		evidenceHandler := handlers.NewEvidenceHandler(services.Evidence)
+		results.GET("/:id/evidence", perm(entity.PermissionExecutionsView), evidenceHandler.ListEvidence)
+		results.GET("/:id/evidence/:evidenceId", perm(entity.PermissionExecutionsView), evidenceHandler.DownloadEvidence)
+		results.POST("/:id/evidence", perm(entity.PermissionExecutionsTriage), evidenceHandler.AttachEvidence)
+	}
+
+	// Result annotations - analyst triage, assignment and comments, included in execution exports
+	if services.Annotation != nil {
+		annotationHandler := handlers.NewResultAnnotationHandler(services.Annotation)
+		results.GET("/:id/annotation", perm(entity.PermissionExecutionsView), annotationHandler.GetAnnotation)
+		results.PUT("/:id/annotation", perm(entity.PermissionExecutionsTriage), annotationHandler.TriageResult)
+		results.POST("/:id/annotation/comments", perm(entity.PermissionExecutionsTriage), annotationHandler.AddComment)
+		results.DELETE("/:id/annotation/comments/:commentId", perm(entity.PermissionExecutionsTriage), annotationHandler.DeleteComment)
+	}
+
+	// Execution reviews - purple-team sign-off of finished executions, locking their annotations and evidence
+	if services.Review != nil {
+		reviewHandler := handlers.NewExecutionReviewHandler(services.Review)
+		api.GET("/reviews", perm(entity.PermissionExecutionsView), reviewHandler.ListReviews)
+		executions.GET("/:id/review", perm(entity.PermissionExecutionsView), reviewHandler.GetReview)
+		executions.GET("/:id/review/snapshot", perm(entity.PermissionExecutionsView), reviewHandler.GetReviewSnapshot)
+		executions.POST("/:id/review", perm(entity.PermissionExecutionsTriage), reviewHandler.RequestReview)
+		executions.PUT("/:id/review/reviewer", perm(entity.PermissionExecutionsTriage), reviewHandler.AssignReviewer)
+		executions.POST("/:id/review/start", perm(entity.PermissionExecutionsTriage), reviewHandler.StartReview)
+		executions.POST("/:id/review/sign-off", perm(entity.PermissionExecutionsTriage), reviewHandler.SignOffReview)
+	}
+
+	// Rescoring - recalculating finished execution scores with a scoring profile, with settings permissions
+	if services.ScoreBackfill != nil {
+		rescoreHandler := handlers.NewScoreBackfillHandler(services.ScoreBackfill)
+		executions.POST("/rescore", perm(entity.PermissionSettingsEdit), rescoreHandler.RescoreExecutions)
+		executions.POST("/:id/rescore", perm(entity.PermissionSettingsEdit), rescoreHandler.RescoreExecution)
+	}
+
+	// Detection verification - re-running SIEM correlation updates results and score
+	if services.Detection != nil {
+		detectionHandler := handlers.NewDetectionHandler(services.Detection)
+		api.GET("/detection/status", perm(entity.PermissionExecutionsView), detectionHandler.GetStatus)
+		executions.POST("/:id/verify-detection", perm(entity.PermissionExecutionsStart), detectionHandler.VerifyExecution)
+	}
+
+	// Tickets - tracker issues opened for undetected techniques, synced on demand with settings permissions
+	if services.Ticket != nil {
+		ticketHandler := handlers.NewTicketHandler(services.Ticket)
+		api.GET("/tickets", perm(entity.PermissionExecutionsView), ticketHandler.ListTickets)
+		api.POST("/tickets/sync", perm(entity.PermissionSettingsEdit), ticketHandler.SyncTickets)
+	}
+
 	// Scenarios - view for all, create/edit/delete/import/export requires permission
 	scenarioHandler := handlers.NewScenarioHandler(services.Scenario)
+	if services.Activity != nil {
+		scenarioHandler.SetActivityMonitor(services.Activity)
+	}
 	scenarios := api.Group("/scenarios")
 	{
 		scenarios.GET("", perm(entity.PermissionScenariosView), scenarioHandler.ListScenarios)
 		scenarios.GET("/tag/:tag", perm(entity.PermissionScenariosView), scenarioHandler.GetScenariosByTag)
 		scenarios.GET("/export", perm(entity.PermissionScenariosExport), scenarioHandler.ExportScenarios)
+		scenarios.GET("/lifecycle-report", perm(entity.PermissionScenariosView), scenarioHandler.GetLifecycleReport)
 		scenarios.GET("/:id", perm(entity.PermissionScenariosView), scenarioHandler.GetScenario)
 		scenarios.GET("/:id/export", perm(entity.PermissionScenariosExport), scenarioHandler.ExportScenario)
+		if services.Readiness != nil {
+			readinessHandler := handlers.NewReadinessHandler(services.Readiness)
+			scenarios.GET("/:id/readiness", perm(entity.PermissionScenariosView), readinessHandler.GetReadiness)
+		}
 		scenarios.POST("", perm(entity.PermissionScenariosCreate), scenarioHandler.CreateScenario)
+		scenarios.POST("/:id/clone", perm(entity.PermissionScenariosCreate), scenarioHandler.CloneScenario)
 		scenarios.POST("/import", perm(entity.PermissionScenariosImport), scenarioHandler.ImportScenarios)
+		scenarios.POST("/validate", perm(entity.PermissionScenariosView), scenarioHandler.ValidateScenario)
 		scenarios.PUT("/:id", perm(entity.PermissionScenariosEdit), scenarioHandler.UpdateScenario)
 		scenarios.DELETE("/:id", perm(entity.PermissionScenariosDelete), scenarioHandler.DeleteScenario)
 	}
 
+	// Trash - deleted scenarios and techniques, restored with the permission that deletes them
+	if services.Trash != nil {
+		trashHandler := handlers.NewTrashHandler(services.Trash)
+		scenarios.GET("/trash", perm(entity.PermissionScenariosDelete), trashHandler.ListScenarios)
+		scenarios.POST("/:id/restore", perm(entity.PermissionScenariosDelete), trashHandler.RestoreScenario)
+		techniques.GET("/trash", perm(entity.PermissionTechniquesImport), trashHandler.ListTechniques)
+		techniques.POST("/:id/restore", perm(entity.PermissionTechniquesImport), trashHandler.RestoreTechnique)
+	}
+
+	// Background jobs - every authenticated user follows their own jobs, admins every job
+	if services.Jobs != nil {
+		handlers.NewJobHandler(services.Jobs).RegisterRoutes(api)
+	}
+
+	// Search - every authenticated user, each type searched only with its view permission
+	if services.Search != nil {
+		searchHandler := handlers.NewSearchHandler(services.Search)
+		api.GET("/search", searchHandler.Search)
+	}
+
 	// Analytics - view/compare/export requires respective permissions
 	if services.Analytics != nil {
 		analyticsHandler := handlers.NewAnalyticsHandler(services.Analytics)
@@ -329,7 +763,15 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 			analytics.GET("/comparison", perm(entity.PermissionAnalyticsCompare), analyticsHandler.CompareScores)
 			analytics.GET("/trend", perm(entity.PermissionAnalyticsView), analyticsHandler.GetScoreTrend)
 			analytics.GET("/summary", perm(entity.PermissionAnalyticsView), analyticsHandler.GetExecutionSummary)
+			analytics.GET("/sigma-coverage", perm(entity.PermissionAnalyticsView), analyticsHandler.GetSigmaCoverage)
+			analytics.GET("/calendar", perm(entity.PermissionAnalyticsView), analyticsHandler.GetExecutionCalendar)
+			analytics.GET("/techniques/:id/stats", perm(entity.PermissionAnalyticsView), analyticsHandler.GetTechniqueStats)
+			analytics.GET("/scores/timeline", perm(entity.PermissionAnalyticsView), analyticsHandler.GetScoreTimeline)
+			analytics.GET("/executions/:id/regressions", perm(entity.PermissionAnalyticsView), analyticsHandler.GetExecutionRegressions)
+			analytics.GET("/agents/:paw/health", perm(entity.PermissionAnalyticsView), agentHandler.GetHealth)
 		}
+		// Before/after diff of two runs of a scenario
+		api.GET("/executions/:id/compare/:other", perm(entity.PermissionAnalyticsCompare), analyticsHandler.CompareExecutions)
 	}
 
 	// Notifications - requires various permissions
@@ -342,6 +784,8 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 			notifications.GET("/unread/count", notificationHandler.GetUnreadCount)
 			notifications.POST("/:id/read", notificationHandler.MarkAsRead)
 			notifications.POST("/read-all", notificationHandler.MarkAllAsRead)
+			notifications.DELETE("/:id", notificationHandler.DeleteNotification)
+			notifications.POST("/delete", notificationHandler.DeleteNotifications)
 			// Settings - user can manage their own
 			notifications.GET("/settings", notificationHandler.GetSettings)
 			notifications.POST("/settings", notificationHandler.CreateSettings)
@@ -349,10 +793,29 @@ func registerRoutesWithPermissions(api *gin.RouterGroup, services *Services, hub
 			notifications.DELETE("/settings/:id", notificationHandler.DeleteSettings)
 			// SMTP config - admin only
 			notifications.GET("/smtp", adminOnly, notificationHandler.GetSMTPConfig)
+			notifications.PUT("/smtp", adminOnly, notificationHandler.UpdateSMTPConfig)
+			notifications.DELETE("/smtp", adminOnly, notificationHandler.DeleteSMTPConfig)
 			notifications.POST("/smtp/test", adminOnly, notificationHandler.TestSMTP)
 		}
 	}
 
+	// Webhook delivery log - users see and redeliver their own deliveries
+	if services.WebhookDelivery != nil {
+		deliveryHandler := handlers.NewWebhookDeliveryHandler(services.WebhookDelivery)
+		deliveries := api.Group("/notifications/webhook-deliveries")
+		{
+			deliveries.GET("", deliveryHandler.ListDeliveries)
+			deliveries.GET("/:id", deliveryHandler.GetDelivery)
+			deliveries.POST("/:id/redeliver", deliveryHandler.Redeliver)
+		}
+	}
+
+	// Notification deep links - resolved for the user they were sent to
+	if services.DeepLinks != nil {
+		linkHandler := handlers.NewDeepLinkHandler(services.DeepLinks)
+		api.GET("/links/:token", linkHandler.ResolveLink)
+	}
+
 	// Schedules - view for all, create/edit/delete requires permission
 	if services.Schedule != nil {
 		scheduleHandler := handlers.NewScheduleHandler(services.Schedule)
@@ -381,9 +844,50 @@ func (s *Server) Close() {
 	}
 }
 
-// Run starts the HTTP server
+// Run starts the HTTP server. It returns nil once Shutdown stops it.
 func (s *Server) Run(addr string) error {
-	return s.router.Run(addr)
+	s.httpMu.Lock()
+	s.httpServer = &http.Server{Addr: addr, Handler: s.router}
+	httpServer := s.httpServer
+	s.httpMu.Unlock()
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// RunTLS starts the HTTPS server, with the certificates of config. It returns
+// nil once Shutdown stops it.
+func (s *Server) RunTLS(addr string, config *tls.Config) error {
+	s.httpMu.Lock()
+	s.httpServer = &http.Server{Addr: addr, Handler: s.router, TLSConfig: config}
+	httpServer := s.httpServer
+	s.httpMu.Unlock()
+
+	if err := httpServer.ListenAndServeTLS("", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests until
+// ctx is done. Upgraded WebSocket connections are not closed, so agents can
+// still report results while executions drain. Dashboard event streams are
+// ended first, as they never complete on their own.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.eventStream != nil {
+		s.eventStream.Close()
+	}
+
+	s.httpMu.Lock()
+	httpServer := s.httpServer
+	s.httpMu.Unlock()
+
+	if httpServer == nil {
+		return nil
+	}
+	return httpServer.Shutdown(ctx)
 }
 
 // Router returns the underlying gin router for testing
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -119,6 +120,10 @@ func (m *mockResultRepo) FindCompletedExecutionsByDateRange(ctx context.Context,
 	return []*entity.Execution{}, nil
 }
 
+func (m *mockResultRepo) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	return []*entity.Execution{}, nil
+}
+
 func TestNewServerConfig_Default(t *testing.T) {
 	// Clear environment variables
 	_ = os.Unsetenv("JWT_SECRET")
@@ -982,6 +987,12 @@ func (m *mockScheduleRepo) UpdateRun(ctx context.Context, run *entity.ScheduleRu
 func (m *mockScheduleRepo) FindRunsByScheduleID(ctx context.Context, scheduleID string, limit int) ([]*entity.ScheduleRun, error) {
 	return []*entity.ScheduleRun{}, nil
 }
+func (m *mockScheduleRepo) FindDependents(ctx context.Context, scheduleID string) ([]*entity.Schedule, error) {
+	return []*entity.Schedule{}, nil
+}
+func (m *mockScheduleRepo) FindRunByExecutionID(ctx context.Context, executionID string) (*entity.ScheduleRun, error) {
+	return nil, nil
+}
 func (m *mockScheduleRepo) FindActiveSchedulesDue(ctx context.Context, before time.Time) ([]*entity.Schedule, error) {
 	return []*entity.Schedule{}, nil
 }
@@ -1027,6 +1038,22 @@ func (m *mockNotificationRepo) MarkAsRead(ctx context.Context, id string) error
 func (m *mockNotificationRepo) MarkAllAsRead(ctx context.Context, userID string) error {
 	return nil
 }
+func (m *mockNotificationRepo) MarkDigestPending(ctx context.Context, id string) error { return nil }
+func (m *mockNotificationRepo) FindDigestPending(ctx context.Context, userID string) ([]*entity.Notification, error) {
+	return nil, nil
+}
+func (m *mockNotificationRepo) MarkDigestSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	return nil
+}
+func (m *mockNotificationRepo) DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error) {
+	return 0, nil
+}
+func (m *mockNotificationRepo) DeleteReadNotifications(ctx context.Context, userID string) (int64, error) {
+	return 0, nil
+}
+func (m *mockNotificationRepo) PurgeReadNotifications(ctx context.Context, createdBefore time.Time) (int64, error) {
+	return 0, nil
+}
 
 // mockUserRepo implements repository.UserRepository for testing
 type mockUserRepo struct{}
@@ -1051,6 +1078,26 @@ func (m *mockUserRepo) CountByRole(ctx context.Context, role entity.UserRole) (i
 }
 func (m *mockUserRepo) DeactivateAdminIfNotLast(ctx context.Context, id string) error { return nil }
 
+func (m *mockUserRepo) FindLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockUserRepo) AddLoginFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, at, failuresSince, lockoutsSince time.Time) (*entity.LoginThrottle, error) {
+	return &entity.LoginThrottle{Scope: scope, Key: key, Failures: 1, LastFailureAt: at}, nil
+}
+
+func (m *mockUserRepo) LockLogin(ctx context.Context, scope entity.LoginThrottleScope, key string, failures int, until time.Time) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockUserRepo) DeleteLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) error {
+	return nil
+}
+
+func (m *mockUserRepo) FindLockedLoginThrottles(ctx context.Context, at time.Time) ([]*entity.LoginThrottle, error) {
+	return nil, nil
+}
+
 // --- Helper to create standard test services ---
 func createTestServices(t *testing.T) *Services {
 	t.Helper()
@@ -1660,3 +1707,61 @@ func TestServer_APIEndpoints_MethodRouting(t *testing.T) {
 		t.Error("DELETE /api/v1/agents without paw should not return 200")
 	}
 }
+
+func TestServer_OpenAPISpec(t *testing.T) {
+	services := createTestServicesWithAuth(t)
+	config := &ServerConfig{EnableAuth: true, JWTSecret: "test-jwt-secret-key"}
+	server := NewServerWithConfig(services, websocket.NewHub(zap.NewNop()), zap.NewNop(), config)
+	defer server.Close()
+
+	// Public without a token
+	req, _ := http.NewRequest("GET", "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	server.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var spec struct {
+		OpenAPI string                                       `json:"openapi"`
+		Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatalf("Failed to decode spec: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("Expected an OpenAPI 3 document, got %q", spec.OpenAPI)
+	}
+
+	// Every route is documented
+	for _, route := range server.Router().Routes() {
+		if route.Method == http.MethodHead {
+			continue
+		}
+		path := route.Path
+		for _, segment := range strings.Split(path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				path = strings.Replace(path, segment, "{"+segment[1:]+"}", 1)
+			}
+		}
+		if spec.Paths[path][strings.ToLower(route.Method)] == nil {
+			t.Errorf("Route %s %s missing from the specification", route.Method, route.Path)
+		}
+	}
+
+	// Public routes do not require a token, API routes do
+	login := spec.Paths["/api/v1/auth/login"]["post"]
+	if security, ok := login["security"].([]interface{}); !ok || len(security) != 0 {
+		t.Errorf("Expected login public, got %v", login["security"])
+	}
+	if _, ok := spec.Paths["/api/v1/agents"]["get"]["security"]; ok {
+		t.Error("Expected API routes to use the document security")
+	}
+
+	req, _ = http.NewRequest("GET", "/api/docs", nil)
+	w = httptest.NewRecorder()
+	server.Router().ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "swagger-ui") {
+		t.Errorf("Expected the Swagger UI page, got %d", w.Code)
+	}
+}
@@ -0,0 +1,207 @@
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// DefaultCertReloadDelay is how long the reloader waits after the last change
+// of the certificate or key file, so that both are written before reloading
+const DefaultCertReloadDelay = time.Second
+
+// TLSConfig configures the TLS termination of the server: certificates
+// obtained from an ACME CA such as Let's Encrypt when ACMEDomains is set, or
+// else a certificate and key read from files
+type TLSConfig struct {
+	CertFile         string
+	KeyFile          string
+	ACMEDomains      []string
+	ACMEEmail        string
+	ACMECacheDir     string // Account key and certificates, kept across restarts
+	ACMEDirectoryURL string // Empty for Let's Encrypt, e.g. its staging directory for tests
+}
+
+// NewTLSConfig creates the TLS configuration of the server. With certificate
+// files, the returned reloader serves them and must be started to reload
+// them on change; it is nil with ACME, whose certificates renew on their own.
+func NewTLSConfig(config TLSConfig, logger *zap.Logger) (*tls.Config, *CertReloader, error) {
+	if len(config.ACMEDomains) > 0 {
+		if config.ACMECacheDir == "" {
+			return nil, nil, errors.New("an ACME cache directory is required")
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(config.ACMEDomains...),
+			Cache:      autocert.DirCache(config.ACMECacheDir),
+			Email:      config.ACMEEmail,
+		}
+		if config.ACMEDirectoryURL != "" {
+			manager.Client = &acme.Client{DirectoryURL: config.ACMEDirectoryURL}
+		}
+		// Answers the TLS-ALPN-01 challenges on the TLS port itself
+		tlsConfig := manager.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+		return tlsConfig, nil, nil
+	}
+
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, nil, errors.New("a certificate and key file, or ACME domains, are required")
+	}
+	reloader, err := NewCertReloader(config.CertFile, config.KeyFile, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+	return tlsConfig, reloader, nil
+}
+
+// CertReloader serves a certificate and key read from files, reloaded on
+// Reload and, once started, when the files change. A pair that fails to
+// load leaves the current certificate in use.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	delay    time.Duration
+	logger   *zap.Logger
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	watcher *fsnotify.Watcher
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewCertReloader loads a certificate and key pair
+func NewCertReloader(certFile, keyFile string, logger *zap.Logger) (*CertReloader, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	r := &CertReloader{certFile: certFile, keyFile: keyFile, delay: DefaultCertReloadDelay, logger: logger}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, as tls.Config expects
+func (r *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload reads the certificate and key files again
+func (r *CertReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate %s: %w", r.certFile, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse TLS certificate %s: %w", r.certFile, err)
+	}
+	cert.Leaf = leaf
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	r.logger.Info("TLS certificate loaded",
+		zap.String("file", r.certFile),
+		zap.String("subject", leaf.Subject.CommonName),
+		zap.Strings("dns_names", leaf.DNSNames),
+		zap.Time("not_after", leaf.NotAfter),
+	)
+	return nil
+}
+
+// Start watches the directories of the certificate and key files, so that
+// files replaced by a rename, as certbot and Kubernetes secrets do, are seen
+func (r *CertReloader) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for _, dir := range r.dirs() {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return err
+		}
+	}
+
+	r.watcher = watcher
+	r.stop = make(chan struct{})
+	r.wg.Add(1)
+	go r.run()
+	return nil
+}
+
+// Stop stops watching the files
+func (r *CertReloader) Stop() {
+	if r.watcher == nil {
+		return
+	}
+	close(r.stop)
+	r.watcher.Close()
+	r.wg.Wait()
+	r.watcher = nil
+}
+
+func (r *CertReloader) run() {
+	defer r.wg.Done()
+	timer := time.NewTimer(r.delay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op != fsnotify.Chmod && r.relevant(event.Name) {
+				timer.Reset(r.delay)
+			}
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.logger.Warn("TLS certificate watcher error", zap.Error(err))
+		case <-timer.C:
+			if err := r.Reload(); err != nil {
+				r.logger.Error("Failed to reload TLS certificate, keeping the current one", zap.Error(err))
+			}
+		}
+	}
+}
+
+// dirs returns the directories of the certificate and key files
+func (r *CertReloader) dirs() []string {
+	certDir, keyDir := filepath.Dir(r.certFile), filepath.Dir(r.keyFile)
+	if certDir == keyDir {
+		return []string{certDir}
+	}
+	return []string{certDir, keyDir}
+}
+
+// relevant reports whether a changed path is the certificate or key file,
+// or, for Kubernetes secret volumes, the ..data link they point through
+func (r *CertReloader) relevant(path string) bool {
+	path = filepath.Clean(path)
+	return path == filepath.Clean(r.certFile) || path == filepath.Clean(r.keyFile) ||
+		filepath.Base(path) == "..data"
+}
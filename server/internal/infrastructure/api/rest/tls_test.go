@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate for a common name and its key
+func writeTestCert(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func servedName(t *testing.T, r *CertReloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil || cert == nil {
+		t.Fatalf("GetCertificate failed: %v", err)
+	}
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestCertReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	writeTestCert(t, certFile, keyFile, "old.example.com")
+
+	tlsConfig, reloader, err := NewTLSConfig(TLSConfig{CertFile: certFile, KeyFile: keyFile}, nil)
+	if err != nil {
+		t.Fatalf("NewTLSConfig failed: %v", err)
+	}
+	if tlsConfig.GetCertificate == nil || servedName(t, reloader) != "old.example.com" {
+		t.Fatal("Expected the certificate served")
+	}
+
+	writeTestCert(t, certFile, keyFile, "new.example.com")
+	if err := reloader.Reload(); err != nil || servedName(t, reloader) != "new.example.com" {
+		t.Fatalf("Expected the new certificate, got %s (%v)", servedName(t, reloader), err)
+	}
+
+	// A broken pair keeps the current certificate
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil || servedName(t, reloader) != "new.example.com" {
+		t.Errorf("Expected the reload to fail and the certificate kept, got %v", err)
+	}
+
+	if _, _, err := NewTLSConfig(TLSConfig{}, nil); err == nil {
+		t.Error("Expected certificate files or ACME domains required")
+	}
+	if _, _, err := NewTLSConfig(TLSConfig{ACMEDomains: []string{"autostrike.example.com"}}, nil); err == nil {
+		t.Error("Expected an ACME cache directory required")
+	}
+}
+
+func TestCertReloader_WatchesFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	writeTestCert(t, certFile, keyFile, "old.example.com")
+
+	reloader, err := NewCertReloader(certFile, keyFile, nil)
+	if err != nil {
+		t.Fatalf("NewCertReloader failed: %v", err)
+	}
+	reloader.delay = 50 * time.Millisecond
+	if err := reloader.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer reloader.Stop()
+
+	// Replaced by a rename, as certbot does
+	staged := t.TempDir()
+	writeTestCert(t, filepath.Join(staged, "server.crt"), filepath.Join(staged, "server.key"), "renewed.example.com")
+	if err := os.Rename(filepath.Join(staged, "server.key"), keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(staged, "server.crt"), certFile); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for servedName(t, reloader) != "renewed.example.com" {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the renewed certificate to be reloaded")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+
+	"autostrike/internal/domain/repository"
+)
+
+// Names of the cached catalogs, as notified to the other server instances
+const (
+	CatalogTechniques = "techniques"
+	CatalogScenarios  = "scenarios"
+)
+
+// Notifier tells the other server instances that a catalog changed, so that
+// they drop their cached copy. A failure is handled by the notifier: the
+// write it follows has been made.
+type Notifier interface {
+	Notify(ctx context.Context, catalog string)
+}
+
+// CatalogTrash is a TrashRepository invalidating the technique and scenario
+// caches when a deleted technique or scenario is restored, the restore
+// writing to the catalog tables without going through the caches
+type CatalogTrash struct {
+	repository.TrashRepository
+	techniques *TechniqueCache
+	scenarios  *ScenarioCache
+}
+
+// Ensure CatalogTrash satisfies the repository port
+var _ repository.TrashRepository = (*CatalogTrash)(nil)
+
+// NewCatalogTrash wraps a trash repository, invalidating the caches given;
+// either may be nil
+func NewCatalogTrash(inner repository.TrashRepository, techniques *TechniqueCache, scenarios *ScenarioCache) *CatalogTrash {
+	return &CatalogTrash{TrashRepository: inner, techniques: techniques, scenarios: scenarios}
+}
+
+// RestoreTechnique restores a technique and invalidates the technique cache
+func (t *CatalogTrash) RestoreTechnique(ctx context.Context, id string) (bool, error) {
+	restored, err := t.TrashRepository.RestoreTechnique(ctx, id)
+	if restored && t.techniques != nil {
+		t.techniques.changed(ctx)
+	}
+	return restored, err
+}
+
+// RestoreScenario restores a scenario and invalidates the scenario cache
+func (t *CatalogTrash) RestoreScenario(ctx context.Context, id string) (bool, error) {
+	restored, err := t.TrashRepository.RestoreScenario(ctx, id)
+	if restored && t.scenarios != nil {
+		t.scenarios.changed(ctx)
+	}
+	return restored, err
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// stubTrashRepo restores into the catalogs of the counting repositories
+type stubTrashRepo struct {
+	repository.TrashRepository
+	techniques *countingTechniqueRepo
+	scenarios  *countingScenarioRepo
+}
+
+func (r *stubTrashRepo) RestoreScenario(ctx context.Context, id string) (bool, error) {
+	if id == "unknown" {
+		return false, nil
+	}
+	_ = r.scenarios.Create(ctx, &entity.Scenario{ID: id})
+	return true, nil
+}
+
+func (r *stubTrashRepo) RestoreTechnique(ctx context.Context, id string) (bool, error) {
+	_ = r.techniques.Create(ctx, &entity.Technique{ID: id})
+	return true, nil
+}
+
+func TestCatalogTrash_InvalidatesOnRestore(t *testing.T) {
+	techniqueRepo := newCountingTechniqueRepo(testTechniques()...)
+	scenarioRepo := newCountingScenarioRepo(testScenarios()...)
+	techniques, scenarios := NewTechniqueCache(techniqueRepo), NewScenarioCache(scenarioRepo)
+	trash := NewCatalogTrash(&stubTrashRepo{techniques: techniqueRepo, scenarios: scenarioRepo}, techniques, scenarios)
+	ctx := context.Background()
+
+	_, _ = techniques.FindAll(ctx)
+	_, _ = scenarios.FindAll(ctx)
+
+	if restored, err := trash.RestoreTechnique(ctx, "T1105"); !restored || err != nil {
+		t.Fatalf("RestoreTechnique failed: %v, %v", restored, err)
+	}
+	if _, err := techniques.FindByID(ctx, "T1105"); err != nil {
+		t.Errorf("Expected the restored technique to be visible, got %v", err)
+	}
+
+	if restored, err := trash.RestoreScenario(ctx, "restored"); !restored || err != nil {
+		t.Fatalf("RestoreScenario failed: %v, %v", restored, err)
+	}
+	if _, err := scenarios.FindByID(ctx, "restored"); err != nil {
+		t.Errorf("Expected the restored scenario to be visible, got %v", err)
+	}
+
+	// Nothing restored leaves the cache loaded
+	_, _ = trash.RestoreScenario(ctx, "unknown")
+	_, _ = scenarios.FindAll(ctx)
+	if got := scenarioRepo.loads(); got != 2 {
+		t.Errorf("Expected 2 scenario loads, got %d", got)
+	}
+}
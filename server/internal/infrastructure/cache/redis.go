@@ -0,0 +1,488 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DefaultRedisChannel is the channel the server instances publish their
+// catalog writes on
+const DefaultRedisChannel = "autostrike:catalog"
+
+// redisDialTimeout bounds connecting, authenticating and each command
+const redisDialTimeout = 5 * time.Second
+
+// redisPingInterval is how often the subscription is pinged; it is reopened
+// when nothing was received for two intervals
+const redisPingInterval = 30 * time.Second
+
+// redisMaxBackoff caps the wait between two attempts to subscribe again
+const redisMaxBackoff = 30 * time.Second
+
+// redisMaxBulk caps the size of a reply, notifications being a few bytes
+const redisMaxBulk = 64 * 1024
+
+// redisNotifyQueue is how many writes wait for their notification to be
+// published
+const redisNotifyQueue = 64
+
+// RedisConfig configures the Redis invalidation of the catalog caches
+type RedisConfig struct {
+	URL     string // redis://[user:password@]host:6379, or rediss:// for TLS; the database number is not used by pub/sub
+	Channel string // Channel shared by the instances of a deployment
+}
+
+// invalidatable is a cache the notifications of the other instances invalidate
+type invalidatable interface {
+	Invalidate()
+}
+
+// RedisInvalidator keeps the catalog caches of the server instances sharing a
+// database coherent. Each write is published on a Redis channel with the RESP
+// protocol, and the instances subscribed to it drop their cached copy of the
+// catalog. The caches are also dropped each time the subscription is made
+// again, notifications being missed while it was lost, and after each failed
+// attempt to make it, so that they are not served stale until Redis is back.
+//
+// The notifications are published in the background, off the requests that
+// wrote. A notification that failed is published again until Redis takes it.
+type RedisInvalidator struct {
+	config   RedisConfig
+	target   *url.URL
+	instance string // Tags the notifications of this instance, which it ignores
+	logger   *zap.Logger
+	caches   map[string]invalidatable
+	queue    chan string // Catalogs written, waiting for their notification
+
+	mu     sync.Mutex // Guards the publishing connection
+	conn   net.Conn
+	reader *bufio.Reader
+
+	subMu       sync.Mutex
+	subConn     net.Conn
+	subscribed  bool
+	unpublished int // Catalogs whose notification failed, published again later
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+	running     bool
+}
+
+// Ensure RedisInvalidator satisfies the cache notifier
+var _ Notifier = (*RedisInvalidator)(nil)
+
+// NewRedisInvalidator creates a Redis invalidator; Register the caches, then
+// Start the subscription
+func NewRedisInvalidator(config RedisConfig, logger *zap.Logger) (*RedisInvalidator, error) {
+	target, err := url.Parse(config.URL)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("invalid redis URL %q", config.URL)
+	}
+	if target.Scheme != "redis" && target.Scheme != "rediss" {
+		return nil, fmt.Errorf("unsupported redis URL scheme %q, use redis or rediss", target.Scheme)
+	}
+	if target.Port() == "" {
+		target.Host = net.JoinHostPort(target.Hostname(), "6379")
+	}
+	if config.Channel == "" {
+		config.Channel = DefaultRedisChannel
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &RedisInvalidator{
+		config:   config,
+		target:   target,
+		instance: uuid.NewString(),
+		logger:   logger,
+		caches:   make(map[string]invalidatable),
+		queue:    make(chan string, redisNotifyQueue),
+	}, nil
+}
+
+// Register invalidates cache when another instance notifies a write to catalog
+func (r *RedisInvalidator) Register(catalog string, cache invalidatable) {
+	r.caches[catalog] = cache
+}
+
+// Notify queues the notification of a write to catalog, published in the
+// background so that the write does not wait for Redis
+func (r *RedisInvalidator) Notify(_ context.Context, catalog string) {
+	select {
+	case r.queue <- catalog:
+	default:
+		r.logger.Warn("Catalog write notifications are backing up, dropping one",
+			zap.String("catalog", catalog))
+	}
+}
+
+// CheckHealth fails while the subscription is lost, the writes of the other
+// instances going unnoticed, or while the writes of this instance cannot be
+// published
+func (r *RedisInvalidator) CheckHealth(ctx context.Context) error {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	if !r.subscribed {
+		return errors.New("not subscribed to the catalog invalidations")
+	}
+	if r.unpublished > 0 {
+		return fmt.Errorf("failed to publish the invalidation of %d catalogs", r.unpublished)
+	}
+	return nil
+}
+
+// Start subscribes to the notifications of the other instances
+func (r *RedisInvalidator) Start() {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	if r.running {
+		return
+	}
+	r.running = true
+	r.stopChan = make(chan struct{})
+	r.wg.Add(2)
+	go r.run()
+	go r.publishLoop()
+	r.logger.Info("Catalog cache invalidation started",
+		zap.String("redis", r.target.Host),
+		zap.String("channel", r.config.Channel),
+	)
+}
+
+// Stop ends the subscription and closes the connections
+func (r *RedisInvalidator) Stop() {
+	r.subMu.Lock()
+	if !r.running {
+		r.subMu.Unlock()
+		return
+	}
+	r.running = false
+	close(r.stopChan)
+	if r.subConn != nil {
+		r.subConn.Close()
+	}
+	r.subMu.Unlock()
+	r.wg.Wait()
+
+	r.mu.Lock()
+	r.closeConn()
+	r.mu.Unlock()
+}
+
+// run keeps the subscription open until stopped, subscribing again after a
+// failure with an increasing delay
+func (r *RedisInvalidator) run() {
+	defer r.wg.Done()
+	backoff := time.Second
+	for {
+		subscribed, err := r.listen()
+		if subscribed {
+			backoff = time.Second
+		}
+		select {
+		case <-r.stopChan:
+			return
+		default:
+		}
+		r.logger.Warn("Catalog cache invalidation lost, subscribing again",
+			zap.Duration("retry_in", backoff), zap.Error(err))
+		// The writes of the other instances go unnoticed until subscribed again:
+		// reload from the database rather than serve a copy of unknown age
+		r.invalidateAll()
+		select {
+		case <-r.stopChan:
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, redisMaxBackoff)
+	}
+}
+
+// listen subscribes and invalidates the caches the notifications name until
+// the connection fails, reporting whether the subscription was made
+func (r *RedisInvalidator) listen() (bool, error) {
+	conn, reader, err := r.dial(context.Background())
+	if err != nil {
+		return false, err
+	}
+	r.subMu.Lock()
+	if !r.running {
+		r.subMu.Unlock()
+		conn.Close()
+		return false, nil
+	}
+	r.subConn = conn
+	r.subMu.Unlock()
+
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		r.subMu.Lock()
+		r.subConn, r.subscribed = nil, false
+		r.subMu.Unlock()
+		conn.Close()
+	}()
+
+	var writeMu sync.Mutex
+	write := func(args ...string) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_ = conn.SetWriteDeadline(time.Now().Add(redisDialTimeout))
+		return writeRedisCommand(conn, args...)
+	}
+	if err := write("SUBSCRIBE", r.config.Channel); err != nil {
+		return false, err
+	}
+	go func() {
+		ticker := time.NewTicker(redisPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// A failed ping shows as the read deadline passing
+				_ = write("PING")
+			}
+		}
+	}()
+
+	subscribed := false
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * redisPingInterval))
+		reply, err := readRedisReply(reader)
+		if err != nil {
+			return subscribed, err
+		}
+		if r.handle(reply) {
+			subscribed = true
+		}
+	}
+}
+
+// handle processes a message of the subscription, reporting whether it
+// confirms the subscription
+func (r *RedisInvalidator) handle(reply any) bool {
+	items, ok := reply.([]any)
+	if !ok || len(items) == 0 {
+		return false
+	}
+	kind, _ := items[0].(string)
+	switch kind {
+	case "subscribe":
+		r.subMu.Lock()
+		r.subscribed = true
+		r.subMu.Unlock()
+		r.invalidateAll()
+		r.logger.Info("Subscribed to the catalog cache invalidations", zap.String("channel", r.config.Channel))
+		return true
+	case "message":
+		if len(items) != 3 {
+			return false
+		}
+		payload, _ := items[2].(string)
+		instance, catalog, _ := strings.Cut(payload, " ")
+		if instance == r.instance {
+			return false
+		}
+		if cache := r.caches[catalog]; cache != nil {
+			cache.Invalidate()
+		}
+	}
+	return false
+}
+
+// invalidateAll drops the catalogs of the registered caches
+func (r *RedisInvalidator) invalidateAll() {
+	for _, cache := range r.caches {
+		cache.Invalidate()
+	}
+}
+
+// publishLoop publishes the queued notifications until stopped. The catalogs
+// whose notification failed are published again with an increasing delay,
+// the other instances serving their cached copy until then.
+func (r *RedisInvalidator) publishLoop() {
+	defer r.wg.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		// Stopping does not wait for a connection to an unreachable server
+		<-r.stopChan
+		cancel()
+	}()
+
+	failed := make(map[string]bool)
+	var retry <-chan time.Time
+	backoff := time.Second
+	for {
+		select {
+		case <-r.stopChan:
+			return
+		case catalog := <-r.queue:
+			failed[catalog] = true
+			if retry != nil {
+				// Redis is failing: wait for the retry
+				continue
+			}
+		case <-retry:
+		}
+
+		var err error
+		for catalog := range failed {
+			if err = r.publish(ctx, catalog); err != nil {
+				r.logger.Warn("Failed to notify the other instances of a catalog write",
+					zap.String("catalog", catalog), zap.Duration("retry_in", backoff), zap.Error(err))
+				break
+			}
+			delete(failed, catalog)
+		}
+		r.subMu.Lock()
+		r.unpublished = len(failed)
+		r.subMu.Unlock()
+
+		if err == nil {
+			retry, backoff = nil, time.Second
+			continue
+		}
+		retry = time.After(backoff)
+		backoff = min(backoff*2, redisMaxBackoff)
+	}
+}
+
+// publish publishes a write to catalog, connecting again once if the
+// connection failed
+func (r *RedisInvalidator) publish(ctx context.Context, catalog string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if r.conn == nil {
+			if r.conn, r.reader, err = r.dial(ctx); err != nil {
+				return err
+			}
+		}
+		if _, err = redisCommand(r.conn, r.reader, "PUBLISH", r.config.Channel, r.instance+" "+catalog); err == nil {
+			return nil
+		}
+		r.closeConn()
+	}
+	return err
+}
+
+// dial connects to the server, upgrades to TLS for rediss and authenticates
+func (r *RedisInvalidator) dial(ctx context.Context) (net.Conn, *bufio.Reader, error) {
+	dialer := net.Dialer{Timeout: redisDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.target.Host)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.target.Scheme == "rediss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: r.target.Hostname(), MinVersion: tls.VersionTLS12})
+		_ = tlsConn.SetDeadline(time.Now().Add(redisDialTimeout))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
+		conn = tlsConn
+	}
+	reader := bufio.NewReader(conn)
+
+	if password, ok := r.target.User.Password(); ok && password != "" {
+		args := []string{"AUTH", password}
+		if username := r.target.User.Username(); username != "" {
+			args = []string{"AUTH", username, password}
+		}
+		if _, err := redisCommand(conn, reader, args...); err != nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+	return conn, reader, nil
+}
+
+func (r *RedisInvalidator) closeConn() {
+	if r.conn == nil {
+		return
+	}
+	r.conn.Close()
+	r.conn, r.reader = nil, nil
+}
+
+// redisCommand sends a command and reads its reply
+func redisCommand(conn net.Conn, reader *bufio.Reader, args ...string) (any, error) {
+	_ = conn.SetDeadline(time.Now().Add(redisDialTimeout))
+	if err := writeRedisCommand(conn, args...); err != nil {
+		return nil, err
+	}
+	return readRedisReply(reader)
+}
+
+// writeRedisCommand sends a command as an array of bulk strings
+func writeRedisCommand(w io.Writer, args ...string) error {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+// readRedisReply reads a RESP2 reply: a string, an integer, nil or an array
+// of replies. An error reply is returned as an error.
+func readRedisReply(reader *bufio.Reader) (any, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("invalid redis reply %q", line)
+	}
+	kind, value := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return value, nil
+	case '-':
+		return nil, fmt.Errorf("redis error: %s", value)
+	case ':':
+		return strconv.ParseInt(value, 10, 64)
+	case '$', '*':
+		n, err := strconv.Atoi(value)
+		if err != nil || n > redisMaxBulk {
+			return nil, fmt.Errorf("invalid redis reply %q", strings.TrimSpace(line))
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if kind == '$' {
+			buf := make([]byte, n+2)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				return nil, err
+			}
+			return string(buf[:n]), nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = readRedisReply(reader); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("invalid redis reply %q", strings.TrimSpace(line))
+}
@@ -0,0 +1,305 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// redisServer is a minimal Redis server handling AUTH, PING, PUBLISH and
+// SUBSCRIBE
+type redisServer struct {
+	addr     string
+	password string
+
+	mu            sync.Mutex
+	subscribers   map[string][]*redisServerConn
+	publishFailed bool // PUBLISH fails with an error
+}
+
+type redisServerConn struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (c *redisServerConn) write(reply string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = io.WriteString(c.conn, reply)
+}
+
+func startRedisServer(t *testing.T, password string) *redisServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &redisServer{addr: listener.Addr().String(), password: password, subscribers: make(map[string][]*redisServerConn)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(&redisServerConn{conn: conn})
+		}
+	}()
+	return server
+}
+
+func (s *redisServer) serve(c *redisServerConn) {
+	defer c.conn.Close()
+	reader := bufio.NewReader(c.conn)
+	subscribed := false
+	for {
+		request, err := readRedisReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := request.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[len(args)-1] != s.password {
+				c.write("-WRONGPASS invalid username-password pair\r\n")
+				continue
+			}
+			c.write("+OK\r\n")
+		case "PING":
+			if subscribed {
+				c.write("*2\r\n$4\r\npong\r\n$0\r\n\r\n")
+			} else {
+				c.write("+PONG\r\n")
+			}
+		case "SUBSCRIBE":
+			s.mu.Lock()
+			s.subscribers[args[1]] = append(s.subscribers[args[1]], c)
+			s.mu.Unlock()
+			subscribed = true
+			c.write(fmt.Sprintf("*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:1\r\n", len(args[1]), args[1]))
+		case "PUBLISH":
+			s.mu.Lock()
+			subscribers := append([]*redisServerConn(nil), s.subscribers[args[1]]...)
+			failed := s.publishFailed
+			s.mu.Unlock()
+			if failed {
+				c.write("-READONLY You can't write against a read only replica.\r\n")
+				continue
+			}
+			for _, sub := range subscribers {
+				sub.write(fmt.Sprintf("*3\r\n$7\r\nmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(args[1]), args[1], len(args[2]), args[2]))
+			}
+			c.write(fmt.Sprintf(":%d\r\n", len(subscribers)))
+		default:
+			c.write("-ERR unknown command\r\n")
+		}
+	}
+}
+
+// dropSubscribers closes the connections of the subscribers
+func (s *redisServer) dropSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for channel, subscribers := range s.subscribers {
+		for _, sub := range subscribers {
+			sub.conn.Close()
+		}
+		delete(s.subscribers, channel)
+	}
+}
+
+// failPublish makes PUBLISH fail, or succeed again
+func (s *redisServer) failPublish(failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.publishFailed = failed
+}
+
+// startInvalidator starts an invalidator of a technique cache on the server
+func startInvalidator(t *testing.T, url string) (*RedisInvalidator, *TechniqueCache, *countingTechniqueRepo) {
+	t.Helper()
+	invalidator, err := NewRedisInvalidator(RedisConfig{URL: url}, nil)
+	if err != nil {
+		t.Fatalf("NewRedisInvalidator failed: %v", err)
+	}
+	inner := newCountingTechniqueRepo(testTechniques()...)
+	techniques := NewTechniqueCache(inner)
+	techniques.SetNotifier(invalidator)
+	invalidator.Register(CatalogTechniques, techniques)
+	invalidator.Start()
+	t.Cleanup(invalidator.Stop)
+	return invalidator, techniques, inner
+}
+
+// waitFor waits until cond holds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// isLoaded reports whether the technique cache holds the catalog
+func isLoaded(c *TechniqueCache) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.snapshot != nil
+}
+
+func TestRedisInvalidator_InvalidatesOtherInstances(t *testing.T) {
+	server := startRedisServer(t, "secret")
+	url := "redis://:secret@" + server.addr
+	first, firstCache, _ := startInvalidator(t, url)
+	second, secondCache, _ := startInvalidator(t, url)
+	ctx := context.Background()
+
+	for _, invalidator := range []*RedisInvalidator{first, second} {
+		waitFor(t, "the subscription", func() bool { return invalidator.CheckHealth(ctx) == nil })
+	}
+	_, _ = secondCache.FindAll(ctx)
+
+	if err := firstCache.Create(ctx, &entity.Technique{ID: "T1105"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	waitFor(t, "the other instance to drop its catalog", func() bool { return !isLoaded(secondCache) })
+
+	// An instance ignores its own notifications and unknown catalogs
+	_, _ = firstCache.FindAll(ctx)
+	first.handle([]any{"message", DefaultRedisChannel, first.instance + " " + CatalogTechniques})
+	first.handle([]any{"message", DefaultRedisChannel, "other-instance payloads"})
+	if !isLoaded(firstCache) {
+		t.Error("Expected the catalog kept on the instance that wrote it")
+	}
+}
+
+func TestRedisInvalidator_Resubscribes(t *testing.T) {
+	server := startRedisServer(t, "")
+	invalidator, techniques, _ := startInvalidator(t, "redis://"+server.addr)
+	ctx := context.Background()
+	waitFor(t, "the subscription", func() bool { return invalidator.CheckHealth(ctx) == nil })
+
+	// Notifications may be missed while the subscription is lost
+	_, _ = techniques.FindAll(ctx)
+	server.dropSubscribers()
+	waitFor(t, "the subscription lost", func() bool { return invalidator.CheckHealth(ctx) != nil })
+	waitFor(t, "the subscription again", func() bool { return invalidator.CheckHealth(ctx) == nil })
+	if isLoaded(techniques) {
+		t.Error("Expected the catalog dropped when subscribing again")
+	}
+}
+
+func TestRedisInvalidator_PublishesAgainAfterFailure(t *testing.T) {
+	server := startRedisServer(t, "")
+	url := "redis://" + server.addr
+	first, firstCache, _ := startInvalidator(t, url)
+	second, secondCache, _ := startInvalidator(t, url)
+	ctx := context.Background()
+	for _, invalidator := range []*RedisInvalidator{first, second} {
+		waitFor(t, "the subscription", func() bool { return invalidator.CheckHealth(ctx) == nil })
+	}
+	_, _ = secondCache.FindAll(ctx)
+
+	// The write does not wait for the failing notification, published once Redis takes it
+	server.failPublish(true)
+	if err := firstCache.Create(ctx, &entity.Technique{ID: "T1105"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	waitFor(t, "the failed notification", func() bool { return first.CheckHealth(ctx) != nil })
+	if !isLoaded(secondCache) {
+		t.Error("Expected the other instance to keep its catalog until notified")
+	}
+	server.failPublish(false)
+	waitFor(t, "the notification published again", func() bool { return first.CheckHealth(ctx) == nil })
+	waitFor(t, "the other instance to drop its catalog", func() bool { return !isLoaded(secondCache) })
+}
+
+func TestRedisInvalidator_ReloadsWhileUnsubscribed(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// Without Redis the writes of the other instances go unnoticed: the
+	// catalog is dropped on each attempt to subscribe
+	_, techniques, inner := startInvalidator(t, "redis://"+addr)
+	ctx := context.Background()
+	_, _ = techniques.FindAll(ctx)
+	waitFor(t, "the catalog dropped", func() bool { return !isLoaded(techniques) })
+	_, _ = techniques.FindAll(ctx)
+	if got := inner.loads(); got != 2 {
+		t.Errorf("Expected 2 loads, got %d", got)
+	}
+}
+
+func TestRedisInvalidator_AuthFailure(t *testing.T) {
+	server := startRedisServer(t, "secret")
+	invalidator, techniques, _ := startInvalidator(t, "redis://:wrong@"+server.addr)
+	ctx := context.Background()
+
+	// The write is made and the cache invalidated, the notification failing
+	if err := techniques.Create(ctx, &entity.Technique{ID: "T1105"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := techniques.FindByID(ctx, "T1105"); err != nil {
+		t.Errorf("Expected the created technique visible, got %v", err)
+	}
+	if err := invalidator.CheckHealth(ctx); err == nil {
+		t.Error("Expected the invalidator unhealthy without a subscription")
+	}
+}
+
+func TestNewRedisInvalidator(t *testing.T) {
+	for _, url := range []string{"", "localhost:6379", "http://localhost:6379"} {
+		if _, err := NewRedisInvalidator(RedisConfig{URL: url}, nil); err == nil {
+			t.Errorf("Expected an error for %q", url)
+		}
+	}
+
+	invalidator, err := NewRedisInvalidator(RedisConfig{URL: "rediss://cache.internal/2"}, nil)
+	if err != nil {
+		t.Fatalf("NewRedisInvalidator failed: %v", err)
+	}
+	if invalidator.target.Host != "cache.internal:6379" || invalidator.config.Channel != DefaultRedisChannel {
+		t.Errorf("Expected the default port and channel, got %s, %s", invalidator.target.Host, invalidator.config.Channel)
+	}
+}
+
+func TestReadRedisReply(t *testing.T) {
+	reply, err := readRedisReply(bufio.NewReader(strings.NewReader("*3\r\n+OK\r\n:42\r\n$-1\r\n")))
+	if err != nil {
+		t.Fatalf("readRedisReply failed: %v", err)
+	}
+	items, _ := reply.([]any)
+	if len(items) != 3 || items[0] != "OK" || items[1] != int64(42) || items[2] != nil {
+		t.Errorf("Unexpected reply: %#v", reply)
+	}
+
+	for _, invalid := range []string{"-ERR nope\r\n", "?\r\n", "+OK\n", "$999999999\r\n"} {
+		if _, err := readRedisReply(bufio.NewReader(strings.NewReader(invalid))); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// ScenarioCache is a read-through, concurrency-safe cache in front of a
+// ScenarioRepository. Like TechniqueCache, the whole catalog is loaded on
+// first read and served from memory until a write or import invalidates it.
+type ScenarioCache struct {
+	inner    repository.ScenarioRepository
+	notifier Notifier
+
+	mu       sync.RWMutex
+	snapshot *scenarioSnapshot
+}
+
+// scenarioSnapshot is an immutable view of the catalog at load time
+type scenarioSnapshot struct {
+	byID map[string]*entity.Scenario
+	all  []*entity.Scenario // most recently updated first, as returned by the repository
+}
+
+// Ensure ScenarioCache satisfies the repository port
+var _ repository.ScenarioRepository = (*ScenarioCache)(nil)
+
+// NewScenarioCache wraps a scenario repository with an in-memory cache
+func NewScenarioCache(inner repository.ScenarioRepository) *ScenarioCache {
+	return &ScenarioCache{inner: inner}
+}
+
+// SetNotifier tells the other server instances about each write, so that
+// they drop their cached catalog too
+func (c *ScenarioCache) SetNotifier(notifier Notifier) {
+	c.notifier = notifier
+}
+
+// Invalidate drops the cached catalog so the next read reloads it
+func (c *ScenarioCache) Invalidate() {
+	c.mu.Lock()
+	c.snapshot = nil
+	c.mu.Unlock()
+}
+
+// changed invalidates the catalog after a write, here and on the other instances
+func (c *ScenarioCache) changed(ctx context.Context) {
+	c.Invalidate()
+	if c.notifier != nil {
+		c.notifier.Notify(ctx, CatalogScenarios)
+	}
+}
+
+// Create creates a scenario and invalidates the cache
+func (c *ScenarioCache) Create(ctx context.Context, scenario *entity.Scenario) error {
+	defer c.changed(ctx)
+	return c.inner.Create(ctx, scenario)
+}
+
+// Update updates a scenario and invalidates the cache
+func (c *ScenarioCache) Update(ctx context.Context, scenario *entity.Scenario) error {
+	defer c.changed(ctx)
+	return c.inner.Update(ctx, scenario)
+}
+
+// Delete deletes a scenario and invalidates the cache
+func (c *ScenarioCache) Delete(ctx context.Context, id string) error {
+	defer c.changed(ctx)
+	return c.inner.Delete(ctx, id)
+}
+
+// ImportFromYAML imports scenarios and invalidates the cache.
+// Invalidation also happens on failure since the import may be partial.
+func (c *ScenarioCache) ImportFromYAML(ctx context.Context, path string) error {
+	defer c.changed(ctx)
+	return c.inner.ImportFromYAML(ctx, path)
+}
+
+// FindByID returns a scenario from the cache, or sql.ErrNoRows if unknown
+func (c *ScenarioCache) FindByID(ctx context.Context, id string) (*entity.Scenario, error) {
+	snap, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := snap.byID[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return cloneScenario(s), nil
+}
+
+// FindAll returns every cached scenario, most recently updated first
+func (c *ScenarioCache) FindAll(ctx context.Context) ([]*entity.Scenario, error) {
+	return c.filter(ctx, func(*entity.Scenario) bool { return true })
+}
+
+// FindByTag returns the cached scenarios with a tag containing tag regardless
+// of case, as the LIKE of the SQLite repository matches them
+func (c *ScenarioCache) FindByTag(ctx context.Context, tag string) ([]*entity.Scenario, error) {
+	tag = strings.ToLower(tag)
+	return c.filter(ctx, func(s *entity.Scenario) bool {
+		for _, t := range s.Tags {
+			if strings.Contains(strings.ToLower(t), tag) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (c *ScenarioCache) filter(ctx context.Context, match func(*entity.Scenario) bool) ([]*entity.Scenario, error) {
+	snap, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*entity.Scenario
+	for _, s := range snap.all {
+		if match(s) {
+			result = append(result, cloneScenario(s))
+		}
+	}
+	return result, nil
+}
+
+// load returns the current snapshot, populating it from the inner repository if needed
+func (c *ScenarioCache) load(ctx context.Context) (*scenarioSnapshot, error) {
+	c.mu.RLock()
+	snap := c.snapshot
+	c.mu.RUnlock()
+	if snap != nil {
+		return snap, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have loaded it while we waited for the lock
+	if c.snapshot != nil {
+		return c.snapshot, nil
+	}
+
+	scenarios, err := c.inner.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snap = &scenarioSnapshot{
+		byID: make(map[string]*entity.Scenario, len(scenarios)),
+		all:  scenarios,
+	}
+	for _, s := range scenarios {
+		snap.byID[s.ID] = s
+	}
+
+	c.snapshot = snap
+	return snap, nil
+}
+
+// cloneScenario returns a copy so callers cannot mutate cached entries
+func cloneScenario(s *entity.Scenario) *entity.Scenario {
+	clone := *s
+	clone.Tags = cloneSlice(s.Tags)
+	clone.Phases = cloneSlice(s.Phases)
+	for i := range clone.Phases {
+		phase := &clone.Phases[i]
+		phase.Techniques = cloneSlice(phase.Techniques)
+		phase.RunIf = cloneSlice(phase.RunIf)
+		phase.Executors = cloneSlice(phase.Executors)
+	}
+	if s.DeletedAt != nil {
+		deletedAt := *s.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	return &clone
+}
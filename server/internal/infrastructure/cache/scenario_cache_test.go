@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+type countingScenarioRepo struct {
+	mu        sync.Mutex
+	scenarios map[string]*entity.Scenario
+	findAll   int
+	err       error
+}
+
+func newCountingScenarioRepo(scenarios ...*entity.Scenario) *countingScenarioRepo {
+	repo := &countingScenarioRepo{scenarios: make(map[string]*entity.Scenario)}
+	for _, s := range scenarios {
+		repo.scenarios[s.ID] = s
+	}
+	return repo
+}
+
+func (m *countingScenarioRepo) Create(ctx context.Context, s *entity.Scenario) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scenarios[s.ID] = s
+	return m.err
+}
+
+func (m *countingScenarioRepo) Update(ctx context.Context, s *entity.Scenario) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scenarios[s.ID] = s
+	return m.err
+}
+
+func (m *countingScenarioRepo) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.scenarios, id)
+	return m.err
+}
+
+func (m *countingScenarioRepo) FindByID(ctx context.Context, id string) (*entity.Scenario, error) {
+	return nil, errors.New("FindByID should be served from cache")
+}
+
+func (m *countingScenarioRepo) FindAll(ctx context.Context) ([]*entity.Scenario, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.findAll++
+	if m.err != nil {
+		return nil, m.err
+	}
+	result := make([]*entity.Scenario, 0, len(m.scenarios))
+	for _, s := range m.scenarios {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UpdatedAt.After(result[j].UpdatedAt) })
+	return result, nil
+}
+
+func (m *countingScenarioRepo) FindByTag(ctx context.Context, tag string) ([]*entity.Scenario, error) {
+	return nil, errors.New("FindByTag should be served from cache")
+}
+
+func (m *countingScenarioRepo) ImportFromYAML(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scenarios["imported"] = &entity.Scenario{ID: "imported", Name: "Imported", UpdatedAt: time.Now()}
+	return m.err
+}
+
+func (m *countingScenarioRepo) loads() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.findAll
+}
+
+func testScenarios() []*entity.Scenario {
+	now := time.Now()
+	return []*entity.Scenario{
+		{ID: "discovery", Name: "Discovery", Tags: []string{"Recon", "quick"}, UpdatedAt: now.Add(-2 * time.Hour),
+			Phases: []entity.Phase{{Name: "Recon", Techniques: []string{"T1082"}, Executors: []string{"sh"},
+				RunIf: []entity.PhaseCondition{{Technique: "T1059", Status: entity.StatusSuccess}}}}},
+		{ID: "credentials", Name: "Credentials", Tags: []string{"credential-access"}, UpdatedAt: now,
+			Phases: []entity.Phase{{Name: "Dump", Techniques: []string{"T1003"}}}},
+	}
+}
+
+func TestScenarioCache_LoadsOnce(t *testing.T) {
+	inner := newCountingScenarioRepo(testScenarios()...)
+	c := NewScenarioCache(inner)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.FindByID(ctx, "discovery"); err != nil {
+			t.Fatalf("FindByID failed: %v", err)
+		}
+	}
+	if _, err := c.FindByTag(ctx, "quick"); err != nil {
+		t.Fatalf("FindByTag failed: %v", err)
+	}
+
+	if got := inner.loads(); got != 1 {
+		t.Errorf("Expected catalog to be loaded once, got %d", got)
+	}
+	if _, err := c.FindByID(ctx, "unknown"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestScenarioCache_Filters(t *testing.T) {
+	c := NewScenarioCache(newCountingScenarioRepo(testScenarios()...))
+	ctx := context.Background()
+
+	all, err := c.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != "credentials" {
+		t.Errorf("Expected the most recently updated scenario first, got %v", all)
+	}
+
+	// Tags match on a part, regardless of case, as the LIKE of the repository
+	for tag, want := range map[string]int{"recon": 1, "credential": 1, "c": 2, "lateral": 0} {
+		byTag, _ := c.FindByTag(ctx, tag)
+		if len(byTag) != want {
+			t.Errorf("Expected %d scenarios tagged %q, got %d", want, tag, len(byTag))
+		}
+	}
+}
+
+func TestScenarioCache_ReturnsCopies(t *testing.T) {
+	c := NewScenarioCache(newCountingScenarioRepo(testScenarios()...))
+	ctx := context.Background()
+
+	first, _ := c.FindByID(ctx, "discovery")
+	first.Name = "mutated"
+	first.Tags[0] = "mutated"
+	first.Phases[0].Techniques[0] = "mutated"
+	first.Phases[0].Executors[0] = "mutated"
+	first.Phases[0].RunIf[0].Technique = "mutated"
+
+	second, _ := c.FindByID(ctx, "discovery")
+	phase := second.Phases[0]
+	if second.Name != "Discovery" || second.Tags[0] != "Recon" || phase.Techniques[0] != "T1082" ||
+		phase.Executors[0] != "sh" || phase.RunIf[0].Technique != "T1059" {
+		t.Errorf("Cached scenario was mutated through a returned value: %+v", second)
+	}
+}
+
+func TestScenarioCache_InvalidatesOnWrites(t *testing.T) {
+	inner := newCountingScenarioRepo(testScenarios()...)
+	c := NewScenarioCache(inner)
+	notifier := &recordingNotifier{}
+	c.SetNotifier(notifier)
+	ctx := context.Background()
+
+	_, _ = c.FindAll(ctx)
+	if err := c.Create(ctx, &entity.Scenario{ID: "new", Name: "New"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := c.FindByID(ctx, "new"); err != nil {
+		t.Errorf("Expected created scenario to be visible, got %v", err)
+	}
+
+	_ = c.Update(ctx, &entity.Scenario{ID: "new", Name: "Renamed"})
+	if updated, _ := c.FindByID(ctx, "new"); updated == nil || updated.Name != "Renamed" {
+		t.Errorf("Expected updated name, got %+v", updated)
+	}
+
+	_ = c.Delete(ctx, "new")
+	if _, err := c.FindByID(ctx, "new"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected deleted scenario to be gone, got %v", err)
+	}
+
+	// A failed import may be partial
+	inner.err = errors.New("import failed halfway")
+	if err := c.ImportFromYAML(ctx, "ignored.yaml"); err == nil {
+		t.Fatal("Expected import error")
+	}
+	inner.err = nil
+	if _, err := c.FindByID(ctx, "imported"); err != nil {
+		t.Errorf("Expected imported scenario to be visible, got %v", err)
+	}
+
+	if got := inner.loads(); got != 5 {
+		t.Errorf("Expected 5 loads (initial + 4 invalidations), got %d", got)
+	}
+	if got := notifier.notified(); len(got) != 4 || got[0] != CatalogScenarios {
+		t.Errorf("Expected the 4 writes notified, got %v", got)
+	}
+}
+
+func TestScenarioCache_LoadError(t *testing.T) {
+	inner := newCountingScenarioRepo(testScenarios()...)
+	inner.err = errors.New("db down")
+	c := NewScenarioCache(inner)
+	ctx := context.Background()
+
+	if _, err := c.FindByID(ctx, "discovery"); err == nil {
+		t.Error("Expected load error from FindByID")
+	}
+
+	// A failed load must not be cached
+	inner.err = nil
+	if _, err := c.FindByID(ctx, "discovery"); err != nil {
+		t.Errorf("Expected recovery after load error, got %v", err)
+	}
+}
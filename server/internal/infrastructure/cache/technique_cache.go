@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"sync"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// TechniqueCache is a read-through, concurrency-safe cache in front of a
+// TechniqueRepository. The whole catalog is loaded on first read and served
+// from memory until a write or import invalidates it.
+type TechniqueCache struct {
+	inner    repository.TechniqueRepository
+	notifier Notifier
+
+	mu       sync.RWMutex
+	snapshot *techniqueSnapshot
+}
+
+// techniqueSnapshot is an immutable view of the catalog at load time
+type techniqueSnapshot struct {
+	byID map[string]*entity.Technique
+	all  []*entity.Technique // ordered by ID, as returned by the repository
+}
+
+// Ensure TechniqueCache satisfies the repository port
+var _ repository.TechniqueRepository = (*TechniqueCache)(nil)
+
+// NewTechniqueCache wraps a technique repository with an in-memory cache
+func NewTechniqueCache(inner repository.TechniqueRepository) *TechniqueCache {
+	return &TechniqueCache{inner: inner}
+}
+
+// SetNotifier tells the other server instances about each write, so that
+// they drop their cached catalog too
+func (c *TechniqueCache) SetNotifier(notifier Notifier) {
+	c.notifier = notifier
+}
+
+// Invalidate drops the cached catalog so the next read reloads it
+func (c *TechniqueCache) Invalidate() {
+	c.mu.Lock()
+	c.snapshot = nil
+	c.mu.Unlock()
+}
+
+// changed invalidates the catalog after a write, here and on the other instances
+func (c *TechniqueCache) changed(ctx context.Context) {
+	c.Invalidate()
+	if c.notifier != nil {
+		c.notifier.Notify(ctx, CatalogTechniques)
+	}
+}
+
+// Create creates a technique and invalidates the cache
+func (c *TechniqueCache) Create(ctx context.Context, technique *entity.Technique) error {
+	defer c.changed(ctx)
+	return c.inner.Create(ctx, technique)
+}
+
+// Update updates a technique and invalidates the cache
+func (c *TechniqueCache) Update(ctx context.Context, technique *entity.Technique) error {
+	defer c.changed(ctx)
+	return c.inner.Update(ctx, technique)
+}
+
+// Delete deletes a technique and invalidates the cache
+func (c *TechniqueCache) Delete(ctx context.Context, id string) error {
+	defer c.changed(ctx)
+	return c.inner.Delete(ctx, id)
+}
+
+// ImportFromYAML imports techniques and invalidates the cache.
+// Invalidation also happens on failure since the import may be partial.
+func (c *TechniqueCache) ImportFromYAML(ctx context.Context, path string) error {
+	defer c.changed(ctx)
+	return c.inner.ImportFromYAML(ctx, path)
+}
+
+// FindByID returns a technique from the cache, or sql.ErrNoRows if unknown
+func (c *TechniqueCache) FindByID(ctx context.Context, id string) (*entity.Technique, error) {
+	snap, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := snap.byID[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return cloneTechnique(t), nil
+}
+
+// FindAll returns every cached technique ordered by ID
+func (c *TechniqueCache) FindAll(ctx context.Context) ([]*entity.Technique, error) {
+	return c.filter(ctx, func(*entity.Technique) bool { return true })
+}
+
+// FindByTactic returns cached techniques belonging to a tactic
+func (c *TechniqueCache) FindByTactic(ctx context.Context, tactic entity.TacticType) ([]*entity.Technique, error) {
+	return c.filter(ctx, func(t *entity.Technique) bool { return t.HasTactic(tactic) })
+}
+
+// FindByPlatform returns cached techniques supporting a platform
+func (c *TechniqueCache) FindByPlatform(ctx context.Context, platform string) ([]*entity.Technique, error) {
+	return c.filter(ctx, func(t *entity.Technique) bool {
+		for _, p := range t.Platforms {
+			if p == platform {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (c *TechniqueCache) filter(ctx context.Context, match func(*entity.Technique) bool) ([]*entity.Technique, error) {
+	snap, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*entity.Technique
+	for _, t := range snap.all {
+		if match(t) {
+			result = append(result, cloneTechnique(t))
+		}
+	}
+	return result, nil
+}
+
+// load returns the current snapshot, populating it from the inner repository if needed
+func (c *TechniqueCache) load(ctx context.Context) (*techniqueSnapshot, error) {
+	c.mu.RLock()
+	snap := c.snapshot
+	c.mu.RUnlock()
+	if snap != nil {
+		return snap, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another goroutine may have loaded it while we waited for the lock
+	if c.snapshot != nil {
+		return c.snapshot, nil
+	}
+
+	techniques, err := c.inner.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snap = &techniqueSnapshot{
+		byID: make(map[string]*entity.Technique, len(techniques)),
+		all:  techniques,
+	}
+	for _, t := range techniques {
+		snap.byID[t.ID] = t
+	}
+
+	c.snapshot = snap
+	return snap, nil
+}
+
+// cloneTechnique returns a copy so callers cannot mutate cached entries
+func cloneTechnique(t *entity.Technique) *entity.Technique {
+	clone := *t
+	clone.Platforms = cloneSlice(t.Platforms)
+	clone.Executors = cloneSlice(t.Executors)
+	for i := range clone.Executors {
+		cloneExecutor(&clone.Executors[i])
+	}
+	clone.Detection = cloneSlice(t.Detection)
+	clone.References = cloneSlice(t.References)
+	clone.SigmaRules = cloneSlice(t.SigmaRules)
+	clone.Tactics = cloneSlice(t.Tactics)
+	clone.Metadata = maps.Clone(t.Metadata)
+	if t.DeletedAt != nil {
+		deletedAt := *t.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	return &clone
+}
+
+// cloneExecutor replaces the slices, map and limits an executor copy shares
+// with the original by copies
+func cloneExecutor(e *entity.Executor) {
+	e.Parsers = cloneSlice(e.Parsers)
+	e.Collect = cloneSlice(e.Collect)
+	e.Variants = maps.Clone(e.Variants)
+	if e.Limits != nil {
+		limits := *e.Limits
+		e.Limits = &limits
+	}
+}
+
+// cloneSlice copies a slice while preserving nil vs empty (JSON null vs [])
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
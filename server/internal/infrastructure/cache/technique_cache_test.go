@@ -0,0 +1,340 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+type countingTechniqueRepo struct {
+	mu         sync.Mutex
+	techniques map[string]*entity.Technique
+	findAll    int
+	err        error
+}
+
+func newCountingTechniqueRepo(techniques ...*entity.Technique) *countingTechniqueRepo {
+	repo := &countingTechniqueRepo{techniques: make(map[string]*entity.Technique)}
+	for _, t := range techniques {
+		repo.techniques[t.ID] = t
+	}
+	return repo
+}
+
+func (m *countingTechniqueRepo) Create(ctx context.Context, t *entity.Technique) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.techniques[t.ID] = t
+	return m.err
+}
+
+func (m *countingTechniqueRepo) Update(ctx context.Context, t *entity.Technique) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.techniques[t.ID] = t
+	return m.err
+}
+
+func (m *countingTechniqueRepo) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.techniques, id)
+	return m.err
+}
+
+func (m *countingTechniqueRepo) FindByID(ctx context.Context, id string) (*entity.Technique, error) {
+	return nil, errors.New("FindByID should be served from cache")
+}
+
+func (m *countingTechniqueRepo) FindAll(ctx context.Context) ([]*entity.Technique, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.findAll++
+	if m.err != nil {
+		return nil, m.err
+	}
+	result := make([]*entity.Technique, 0, len(m.techniques))
+	for _, t := range m.techniques {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (m *countingTechniqueRepo) FindByTactic(ctx context.Context, tactic entity.TacticType) ([]*entity.Technique, error) {
+	return nil, errors.New("FindByTactic should be served from cache")
+}
+
+func (m *countingTechniqueRepo) FindByPlatform(ctx context.Context, platform string) ([]*entity.Technique, error) {
+	return nil, errors.New("FindByPlatform should be served from cache")
+}
+
+func (m *countingTechniqueRepo) ImportFromYAML(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.techniques["T9999"] = &entity.Technique{ID: "T9999", Name: "Imported"}
+	return m.err
+}
+
+func (m *countingTechniqueRepo) loads() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.findAll
+}
+
+func testTechniques() []*entity.Technique {
+	return []*entity.Technique{
+		{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery, Platforms: []string{"windows", "linux"}},
+		{ID: "T1059.001", Name: "PowerShell", Tactic: entity.TacticExecution, Platforms: []string{"windows"},
+			Executors: []entity.Executor{{Type: "psh", Command: "Get-Process"}}},
+		{ID: "T1003", Name: "OS Credential Dumping", Tactic: entity.TacticCredentialAccess, Platforms: []string{"linux"}},
+	}
+}
+
+func TestTechniqueCache_LoadsOnce(t *testing.T) {
+	inner := newCountingTechniqueRepo(testTechniques()...)
+	c := NewTechniqueCache(inner)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.FindByID(ctx, "T1082"); err != nil {
+			t.Fatalf("FindByID failed: %v", err)
+		}
+	}
+	if _, err := c.FindAll(ctx); err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+
+	if got := inner.loads(); got != 1 {
+		t.Errorf("Expected catalog to be loaded once, got %d", got)
+	}
+}
+
+func TestTechniqueCache_FindByIDNotFound(t *testing.T) {
+	c := NewTechniqueCache(newCountingTechniqueRepo(testTechniques()...))
+
+	_, err := c.FindByID(context.Background(), "T0000")
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestTechniqueCache_Filters(t *testing.T) {
+	c := NewTechniqueCache(newCountingTechniqueRepo(testTechniques()...))
+	ctx := context.Background()
+
+	all, err := c.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != "T1003" || all[2].ID != "T1082" {
+		t.Errorf("Unexpected FindAll result: %v", all)
+	}
+
+	byTactic, _ := c.FindByTactic(ctx, entity.TacticExecution)
+	if len(byTactic) != 1 || byTactic[0].ID != "T1059.001" {
+		t.Errorf("Unexpected FindByTactic result: %v", byTactic)
+	}
+
+	byPlatform, _ := c.FindByPlatform(ctx, "linux")
+	if len(byPlatform) != 2 {
+		t.Errorf("Expected 2 linux techniques, got %d", len(byPlatform))
+	}
+
+	none, _ := c.FindByPlatform(ctx, "darwin")
+	if len(none) != 0 {
+		t.Errorf("Expected no darwin techniques, got %d", len(none))
+	}
+}
+
+func TestTechniqueCache_ReturnsCopies(t *testing.T) {
+	c := NewTechniqueCache(newCountingTechniqueRepo(testTechniques()...))
+	ctx := context.Background()
+
+	first, _ := c.FindByID(ctx, "T1059.001")
+	first.Name = "mutated"
+	first.Platforms[0] = "mutated"
+	first.Executors[0].Command = "mutated"
+
+	second, _ := c.FindByID(ctx, "T1059.001")
+	if second.Name != "PowerShell" || second.Platforms[0] != "windows" || second.Executors[0].Command != "Get-Process" {
+		t.Errorf("Cached technique was mutated through a returned value: %+v", second)
+	}
+}
+
+func TestTechniqueCache_ReturnsDeepCopies(t *testing.T) {
+	inner := newCountingTechniqueRepo(&entity.Technique{
+		ID:       "T1053",
+		Tactics:  []entity.TacticType{entity.TacticExecution},
+		Metadata: entity.Metadata{"owner": "red-team"},
+		Executors: []entity.Executor{{
+			Type: "sh", Command: "crontab -l",
+			Limits:   &entity.ResourceLimits{MemoryMB: 64},
+			Parsers:  []entity.FactParser{{Fact: "user", Regex: "(.*)"}},
+			Collect:  []string{"/tmp/out"},
+			Variants: map[string]entity.CommandVariant{"darwin": {Command: "launchctl list"}},
+		}},
+	})
+	c := NewTechniqueCache(inner)
+	ctx := context.Background()
+
+	first, _ := c.FindByID(ctx, "T1053")
+	first.Tactics[0] = entity.TacticPersistence
+	first.Metadata["owner"] = "mutated"
+	first.Executors[0].Limits.MemoryMB = 1
+	first.Executors[0].Parsers[0].Fact = "mutated"
+	first.Executors[0].Collect[0] = "mutated"
+	first.Executors[0].Variants["darwin"] = entity.CommandVariant{Command: "mutated"}
+
+	second, _ := c.FindByID(ctx, "T1053")
+	executor := second.Executors[0]
+	if second.Tactics[0] != entity.TacticExecution || second.Metadata["owner"] != "red-team" ||
+		executor.Limits.MemoryMB != 64 || executor.Parsers[0].Fact != "user" || executor.Collect[0] != "/tmp/out" ||
+		executor.Variants["darwin"].Command != "launchctl list" {
+		t.Errorf("Cached technique was mutated through a returned value: %+v", second)
+	}
+}
+
+// recordingNotifier records the catalogs notified
+type recordingNotifier struct {
+	mu       sync.Mutex
+	catalogs []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, catalog string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.catalogs = append(n.catalogs, catalog)
+}
+
+func (n *recordingNotifier) notified() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.catalogs...)
+}
+
+func TestTechniqueCache_NotifiesWrites(t *testing.T) {
+	c := NewTechniqueCache(newCountingTechniqueRepo(testTechniques()...))
+	notifier := &recordingNotifier{}
+	c.SetNotifier(notifier)
+	ctx := context.Background()
+
+	_, _ = c.FindAll(ctx)
+	_ = c.Create(ctx, &entity.Technique{ID: "T1105"})
+	_ = c.Delete(ctx, "T1105")
+	c.Invalidate()
+
+	if got := notifier.notified(); len(got) != 2 || got[0] != CatalogTechniques {
+		t.Errorf("Expected the 2 writes notified, not the local invalidation, got %v", got)
+	}
+}
+
+func TestTechniqueCache_InvalidatesOnWrites(t *testing.T) {
+	inner := newCountingTechniqueRepo(testTechniques()...)
+	c := NewTechniqueCache(inner)
+	ctx := context.Background()
+
+	if _, err := c.FindAll(ctx); err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+
+	if err := c.Create(ctx, &entity.Technique{ID: "T1105", Name: "Ingress Tool Transfer"}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := c.FindByID(ctx, "T1105"); err != nil {
+		t.Errorf("Expected created technique to be visible, got %v", err)
+	}
+
+	if err := c.Update(ctx, &entity.Technique{ID: "T1105", Name: "Renamed"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	updated, _ := c.FindByID(ctx, "T1105")
+	if updated == nil || updated.Name != "Renamed" {
+		t.Errorf("Expected updated name, got %+v", updated)
+	}
+
+	if err := c.Delete(ctx, "T1105"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := c.FindByID(ctx, "T1105"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("Expected deleted technique to be gone, got %v", err)
+	}
+
+	if err := c.ImportFromYAML(ctx, "ignored.yaml"); err != nil {
+		t.Fatalf("ImportFromYAML failed: %v", err)
+	}
+	if _, err := c.FindByID(ctx, "T9999"); err != nil {
+		t.Errorf("Expected imported technique to be visible, got %v", err)
+	}
+
+	if got := inner.loads(); got != 5 {
+		t.Errorf("Expected 5 loads (initial + 4 invalidations), got %d", got)
+	}
+}
+
+func TestTechniqueCache_InvalidatesOnFailedWrite(t *testing.T) {
+	inner := newCountingTechniqueRepo(testTechniques()...)
+	c := NewTechniqueCache(inner)
+	ctx := context.Background()
+
+	_, _ = c.FindAll(ctx)
+	inner.err = errors.New("import failed halfway")
+	if err := c.ImportFromYAML(ctx, "ignored.yaml"); err == nil {
+		t.Fatal("Expected import error")
+	}
+	inner.err = nil
+
+	if _, err := c.FindByID(ctx, "T9999"); err != nil {
+		t.Errorf("Expected partially imported technique to be visible, got %v", err)
+	}
+}
+
+func TestTechniqueCache_LoadError(t *testing.T) {
+	inner := newCountingTechniqueRepo(testTechniques()...)
+	inner.err = errors.New("db down")
+	c := NewTechniqueCache(inner)
+	ctx := context.Background()
+
+	if _, err := c.FindAll(ctx); err == nil {
+		t.Error("Expected load error from FindAll")
+	}
+	if _, err := c.FindByID(ctx, "T1082"); err == nil {
+		t.Error("Expected load error from FindByID")
+	}
+
+	// A failed load must not be cached
+	inner.err = nil
+	if _, err := c.FindByID(ctx, "T1082"); err != nil {
+		t.Errorf("Expected recovery after load error, got %v", err)
+	}
+}
+
+func TestTechniqueCache_ConcurrentAccess(t *testing.T) {
+	inner := newCountingTechniqueRepo(testTechniques()...)
+	c := NewTechniqueCache(inner)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%10 == 0 {
+				c.Invalidate()
+				return
+			}
+			if _, err := c.FindByID(ctx, "T1082"); err != nil {
+				t.Errorf("FindByID failed: %v", err)
+			}
+			if _, err := c.FindByPlatform(ctx, "windows"); err != nil {
+				t.Errorf("FindByPlatform failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,148 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// atomicExecutors maps the Atomic Red Team executors to the agent executors.
+// Manual tests have no command and are skipped.
+var atomicExecutors = map[string]string{
+	"command_prompt": "cmd",
+	"powershell":     "powershell",
+	"sh":             "sh",
+	"bash":           "bash",
+}
+
+// atomicPlatforms maps the Atomic Red Team platforms agents run on to the agent platforms
+var atomicPlatforms = map[string]string{
+	"windows": "windows",
+	"linux":   "linux",
+	"macos":   "darwin",
+}
+
+// atomicTechnique is a technique of the Atomic Red Team index, with its tests
+type atomicTechnique struct {
+	Technique struct {
+		Name        string `yaml:"name"`
+		Description string `yaml:"description"`
+	} `yaml:"technique"`
+	AtomicTests []atomicTest `yaml:"atomic_tests"`
+}
+
+// atomicTest is an Atomic Red Team test of a technique
+type atomicTest struct {
+	Name               string                   `yaml:"name"`
+	SupportedPlatforms []string                 `yaml:"supported_platforms"`
+	InputArguments     map[string]inputArgument `yaml:"input_arguments"`
+	Executor           struct {
+		Name              string `yaml:"name"`
+		Command           string `yaml:"command"`
+		CleanupCommand    string `yaml:"cleanup_command"`
+		ElevationRequired bool   `yaml:"elevation_required"`
+	} `yaml:"executor"`
+}
+
+// AtomicConverter converts the Atomic Red Team index (atomics/Indexes/index.yaml),
+// which lists the tests of each technique by tactic. Tests become the
+// executors of their technique, with the default of each input argument
+// substituted into the commands (the first test per executor and platform is
+// kept, the others of other platforms as command variants). Tests of platforms
+// agents do not run on, and manual tests, are skipped, and so the techniques
+// left without a test.
+type AtomicConverter struct{}
+
+// NewAtomicConverter creates an Atomic Red Team converter
+func NewAtomicConverter() *AtomicConverter {
+	return &AtomicConverter{}
+}
+
+// Format returns the format name
+func (c *AtomicConverter) Format() string {
+	return "atomic"
+}
+
+// Convert converts the Atomic Red Team index
+func (c *AtomicConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	var index map[string]map[string]atomicTechnique
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse atomics index: %w", err)
+	}
+
+	bundle := &application.ContentBundle{}
+	techniques := make(map[string]*entity.Technique)
+	for _, tactic := range killChain {
+		items := index[string(tactic)]
+		ids := make([]string, 0, len(items))
+		for id := range items {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			if technique := techniques[id]; technique != nil {
+				technique.Tactics = append(technique.Tactics, tactic)
+				continue
+			}
+			technique := atomicTechniqueOf(id, tactic, items[id])
+			if technique == nil {
+				continue
+			}
+			techniques[id] = technique
+			bundle.Techniques = append(bundle.Techniques, technique)
+		}
+	}
+	if len(bundle.Techniques) == 0 {
+		return nil, errors.New("no atomic test for a supported platform and executor")
+	}
+	compactVariants(bundle.Techniques)
+	return bundle, nil
+}
+
+// atomicTechniqueOf converts the tests of a technique, or returns nil when
+// none runs on an agent
+func atomicTechniqueOf(id string, tactic entity.TacticType, item atomicTechnique) *entity.Technique {
+	name := item.Technique.Name
+	if name == "" {
+		name = id
+	}
+	technique := &entity.Technique{
+		ID:          id,
+		Name:        name,
+		Description: strings.TrimSpace(item.Technique.Description),
+		Tactic:      tactic,
+		Tactics:     []entity.TacticType{tactic},
+	}
+
+	for _, test := range item.AtomicTests {
+		agentExecutor, ok := atomicExecutors[test.Executor.Name]
+		if !ok || strings.TrimSpace(test.Executor.Command) == "" {
+			continue
+		}
+		for _, supported := range test.SupportedPlatforms {
+			platform, ok := atomicPlatforms[supported]
+			if !ok {
+				continue
+			}
+			technique.Platforms = addPlatform(technique.Platforms, platform)
+			addProcedure(technique, platform, entity.Executor{
+				Type:              agentExecutor,
+				Command:           substituteArguments(strings.TrimSpace(test.Executor.Command), test.InputArguments),
+				Cleanup:           substituteArguments(strings.TrimSpace(test.Executor.CleanupCommand), test.InputArguments),
+				Timeout:           defaultTimeout,
+				ElevationRequired: test.Executor.ElevationRequired,
+			})
+		}
+	}
+	if len(technique.Executors) == 0 {
+		return nil
+	}
+	return technique
+}
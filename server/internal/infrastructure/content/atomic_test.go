@@ -0,0 +1,134 @@
+package content
+
+import (
+	"slices"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+const atomicIndex = `
+credential-access:
+  T1003.008:
+    technique:
+      name: "/etc/passwd and /etc/shadow"
+      description: Adversaries may dump /etc/passwd and /etc/shadow.
+    atomic_tests:
+    - name: Access /etc/shadow (Local)
+      supported_platforms: [linux]
+      input_arguments:
+        output_file:
+          description: Path where captured results will be placed
+          type: path
+          default: /tmp/T1003.008.txt
+      executor:
+        command: |
+          cat /etc/shadow > #{output_file}
+        cleanup_command: "rm -f #{output_file}"
+        name: sh
+        elevation_required: true
+    - name: Access /etc/passwd (Local)
+      supported_platforms: [linux, macos]
+      executor:
+        command: cat /etc/passwd
+        name: sh
+discovery:
+  T1082:
+    technique:
+      name: System Information Discovery
+    atomic_tests:
+    - name: System Information Discovery
+      supported_platforms: [windows]
+      executor:
+        command: systeminfo
+        name: command_prompt
+    - name: Manual check
+      supported_platforms: [windows]
+      executor:
+        steps: Open the control panel
+        name: manual
+  T1613:
+    technique:
+      name: Container and Resource Discovery
+    atomic_tests:
+    - name: Docker Container and Resource Discovery
+      supported_platforms: [containers]
+      executor:
+        command: docker ps
+        name: sh
+privilege-escalation:
+  T1548.001:
+    technique:
+      name: Setuid and Setgid
+    atomic_tests:
+    - name: Set a SetUID flag on file
+      supported_platforms: [macos, linux]
+      executor:
+        command: sudo chmod u+xs /tmp/hello
+        name: bash
+defense-evasion:
+  T1548.001:
+    technique:
+      name: Setuid and Setgid
+    atomic_tests: []
+`
+
+func TestAtomicConverter_Convert(t *testing.T) {
+	bundle, err := NewAtomicConverter().Convert([]byte(atomicIndex))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	// Techniques come in kill chain order; the container-only one is skipped
+	var ids []string
+	for _, technique := range bundle.Techniques {
+		ids = append(ids, technique.ID)
+	}
+	if !slices.Equal(ids, []string{"T1548.001", "T1003.008", "T1082"}) {
+		t.Fatalf("Unexpected techniques: %v", ids)
+	}
+
+	setuid := bundle.Techniques[0]
+	if setuid.Tactic != entity.TacticPrivilegeEscalation ||
+		!slices.Equal(setuid.Tactics, []entity.TacticType{entity.TacticPrivilegeEscalation, entity.TacticDefenseEvasion}) ||
+		!slices.Equal(setuid.Platforms, []string{"darwin", "linux"}) {
+		t.Errorf("Unexpected technique: %+v", setuid)
+	}
+
+	shadow := bundle.Techniques[1]
+	if shadow.Name != "/etc/passwd and /etc/shadow" || !slices.Equal(shadow.Platforms, []string{"linux", "darwin"}) {
+		t.Errorf("Unexpected technique: %+v", shadow)
+	}
+	if len(shadow.Executors) != 1 {
+		t.Fatalf("Expected one sh executor, got %+v", shadow.Executors)
+	}
+	sh := shadow.Executors[0]
+	if sh.Type != "sh" || sh.Command != "cat /etc/shadow > /tmp/T1003.008.txt" || sh.Cleanup != "rm -f /tmp/T1003.008.txt" ||
+		!sh.ElevationRequired || sh.Timeout != defaultTimeout {
+		t.Errorf("Unexpected executor: %+v", sh)
+	}
+	// The first test of darwin is a variant of the linux one
+	if variant := sh.Variants["darwin"]; variant.Command != "cat /etc/passwd" || len(sh.Variants) != 1 {
+		t.Errorf("Unexpected variants: %+v", sh.Variants)
+	}
+
+	systeminfo := bundle.Techniques[2]
+	if len(systeminfo.Executors) != 1 || systeminfo.Executors[0].Type != "cmd" || systeminfo.Executors[0].Command != "systeminfo" {
+		t.Errorf("Expected the manual test skipped, got %+v", systeminfo.Executors)
+	}
+}
+
+func TestAtomicConverter_ConvertErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid YAML": "credential-access: [",
+		"no test":      "discovery:\n  T1082:\n    atomic_tests: []\n",
+		"unsupported":  "discovery:\n  T1613:\n    atomic_tests:\n    - supported_platforms: [containers]\n      executor: {name: sh, command: docker ps}\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewAtomicConverter().Convert([]byte(data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
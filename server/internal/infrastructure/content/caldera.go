@@ -0,0 +1,354 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// calderaParsers maps the Caldera output parsers to a regular expression
+// extracting the first value they would find. Other parsers are dropped.
+var calderaParsers = map[string]string{
+	"basic":  `(?m)^\s*(\S.*?)\s*$`,
+	"ipaddr": `\b(\d{1,3}(?:\.\d{1,3}){3})\b`,
+}
+
+// calderaFactReference matches a Caldera fact reference such as #{host.user.name}.
+// Facts have dotted names; undotted variables (#{server}, #{paw}) are Caldera's own.
+var calderaFactReference = regexp.MustCompile(`#\{([A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)+)\}`)
+
+// calderaDocument is a Caldera ability, or an adversary profile when it orders abilities
+type calderaDocument struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Tactic      string `yaml:"tactic"`
+	Technique   struct {
+		AttackID string `yaml:"attack_id"`
+		Name     string `yaml:"name"`
+	} `yaml:"technique"`
+	Platforms    map[string]map[string]calderaProcedure `yaml:"platforms"`
+	Requirements []map[string][]calderaRelationship     `yaml:"requirements"`
+	Privilege    string                                 `yaml:"privilege"` // Elevated when the ability needs admin
+
+	AtomicOrdering []string         `yaml:"atomic_ordering"`
+	Phases         map[int][]string `yaml:"phases"` // Caldera 2 adversaries
+}
+
+// calderaProcedure is the command of an ability for one platform and executor
+type calderaProcedure struct {
+	Command calderaText                      `yaml:"command"`
+	Cleanup calderaText                      `yaml:"cleanup"`
+	Timeout int                              `yaml:"timeout"`
+	Parsers map[string][]calderaRelationship `yaml:"parsers"`
+}
+
+// calderaRelationship is a fact relationship of a parser or requirement
+type calderaRelationship struct {
+	Source string `yaml:"source"`
+	Edge   string `yaml:"edge"`
+	Target string `yaml:"target"`
+}
+
+// calderaText is a command, written as a string or a list of lines
+type calderaText string
+
+// UnmarshalYAML accepts a string or a list of strings
+func (t *calderaText) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var lines []string
+		if err := node.Decode(&lines); err != nil {
+			return err
+		}
+		*t = calderaText(strings.Join(lines, "\n"))
+		return nil
+	}
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	*t = calderaText(s)
+	return nil
+}
+
+// CalderaConverter converts MITRE Caldera abilities and adversary profiles. The
+// input is a YAML stream of ability files (a list of abilities, or one ability)
+// and adversary profiles separated by "---". Abilities become the technique of
+// their ATT&CK ID (abilities of the same technique are merged, keeping the first
+// procedure per executor and platform as command variants), with fact references such as #{host.user.name}
+// rewritten as #{fact.host.user.name}, the basic and ipaddr parsers converted
+// to fact parsers, and Elevated abilities requiring elevation. Abilities without a procedure for an agent executor are
+// skipped. Adversaries become scenarios following their atomic ordering.
+type CalderaConverter struct{}
+
+// NewCalderaConverter creates a Caldera converter
+func NewCalderaConverter() *CalderaConverter {
+	return &CalderaConverter{}
+}
+
+// Format returns the format name
+func (c *CalderaConverter) Format() string {
+	return "caldera"
+}
+
+// Convert converts a YAML stream of abilities and adversaries
+func (c *CalderaConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	abilities, adversaries, err := decodeCaldera(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(abilities) == 0 && len(adversaries) == 0 {
+		return nil, errors.New("no abilities or adversaries found")
+	}
+
+	bundle := &application.ContentBundle{}
+	techniques := make(map[string]*entity.Technique)
+	abilityTechniques := make(map[string]*entity.Technique)
+	skipped := make(map[string]bool)
+	for _, ability := range abilities {
+		technique, ok, err := c.mergeAbility(techniques[ability.Technique.AttackID], ability)
+		if err != nil {
+			return nil, fmt.Errorf("ability %s: %w", ability.ID, err)
+		}
+		if !ok {
+			skipped[ability.ID] = true
+			continue
+		}
+		if techniques[technique.ID] == nil {
+			techniques[technique.ID] = technique
+			bundle.Techniques = append(bundle.Techniques, technique)
+		}
+		abilityTechniques[ability.ID] = technique
+	}
+	compactVariants(bundle.Techniques)
+
+	for _, adversary := range adversaries {
+		scenario, err := c.adversaryScenario(adversary, abilityTechniques, skipped)
+		if err != nil {
+			return nil, fmt.Errorf("adversary %s: %w", adversary.Name, err)
+		}
+		bundle.Scenarios = append(bundle.Scenarios, scenario)
+	}
+
+	if len(bundle.Techniques) == 0 && len(bundle.Scenarios) == 0 {
+		return nil, errors.New("no ability with a procedure for a supported platform and executor")
+	}
+	return bundle, nil
+}
+
+// decodeCaldera splits a YAML stream into abilities and adversaries
+func decodeCaldera(data []byte) ([]*calderaDocument, []*calderaDocument, error) {
+	var abilities, adversaries []*calderaDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+
+		var docs []*calderaDocument
+		if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+			err = node.Decode(&docs)
+		} else {
+			doc := &calderaDocument{}
+			err = node.Decode(doc)
+			docs = append(docs, doc)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+
+		for _, doc := range docs {
+			switch {
+			case len(doc.AtomicOrdering) > 0 || len(doc.Phases) > 0:
+				adversaries = append(adversaries, doc)
+			case doc.ID != "" && doc.Technique.AttackID != "":
+				abilities = append(abilities, doc)
+			case doc.ID != "":
+				return nil, nil, fmt.Errorf("ability %s: missing ATT&CK technique ID", doc.ID)
+			}
+		}
+	}
+	return abilities, adversaries, nil
+}
+
+// mergeAbility adds the procedures of an ability to technique, or to a new
+// technique when nil. Reports false when the ability has no supported procedure.
+func (c *CalderaConverter) mergeAbility(technique *entity.Technique, ability *calderaDocument) (*entity.Technique, bool, error) {
+	tactic, err := parseTactic(ability.Tactic)
+	if err != nil {
+		return nil, false, err
+	}
+
+	merged := technique
+	if merged == nil {
+		name := ability.Technique.Name
+		if name == "" {
+			name = ability.Name
+		}
+		merged = &entity.Technique{
+			ID:          ability.Technique.AttackID,
+			Name:        name,
+			Tactic:      tactic,
+			Description: calderaDescription(ability),
+		}
+	}
+
+	added := false
+	for _, platform := range []string{"windows", "linux", "darwin"} {
+		procedures, ok := ability.Platforms[platform]
+		if !ok {
+			continue
+		}
+		for _, executors := range sortedKeys(procedures) {
+			procedure := procedures[executors]
+			if strings.TrimSpace(string(procedure.Command)) == "" {
+				continue
+			}
+			// Executors sharing a procedure are listed together ("psh,pwsh")
+			for _, executor := range strings.Split(executors, ",") {
+				agentExecutor, ok := preludeExecutors[strings.TrimSpace(executor)]
+				if !ok {
+					continue
+				}
+				added = true
+				merged.Platforms = addPlatform(merged.Platforms, platform)
+				executor := calderaExecutor(agentExecutor, procedure)
+				executor.ElevationRequired = strings.EqualFold(ability.Privilege, "elevated")
+				addProcedure(merged, platform, executor)
+			}
+		}
+	}
+	return merged, added, nil
+}
+
+// calderaExecutor converts a Caldera procedure to an executor
+func calderaExecutor(executorType string, procedure calderaProcedure) entity.Executor {
+	executor := entity.Executor{
+		Type:    executorType,
+		Command: calderaFacts(strings.TrimSpace(string(procedure.Command))),
+		Cleanup: calderaFacts(strings.TrimSpace(string(procedure.Cleanup))),
+		Timeout: defaultTimeout,
+	}
+	if procedure.Timeout > 0 {
+		executor.Timeout = procedure.Timeout
+	}
+	for _, module := range sortedKeys(procedure.Parsers) {
+		relationships := procedure.Parsers[module]
+		regex, ok := calderaParsers[module[strings.LastIndex(module, ".")+1:]]
+		if !ok {
+			continue
+		}
+		for _, r := range relationships {
+			if r.Source != "" {
+				executor.Parsers = append(executor.Parsers, entity.FactParser{Fact: r.Source, Regex: regex})
+			}
+		}
+	}
+	return executor
+}
+
+// calderaFacts rewrites the Caldera fact references of a command as AutoStrike fact references
+func calderaFacts(command string) string {
+	return calderaFactReference.ReplaceAllStringFunc(command, func(ref string) string {
+		if strings.HasPrefix(ref, "#{fact.") {
+			return ref
+		}
+		return "#{fact." + ref[2:]
+	})
+}
+
+// calderaDescription returns the description of an ability, listing the facts
+// its requirements need: AutoStrike skips a technique whose facts are missing
+// but does not check the relationships between them
+func calderaDescription(ability *calderaDocument) string {
+	description := strings.TrimSpace(ability.Description)
+	var required []string
+	for _, requirement := range ability.Requirements {
+		for _, relationships := range requirement {
+			for _, r := range relationships {
+				if r.Source != "" {
+					required = append(required, r.Source)
+				}
+			}
+		}
+	}
+	if len(required) > 0 {
+		description = strings.TrimSpace(description + "\n\nCaldera requirements: " + strings.Join(required, ", "))
+	}
+	return description
+}
+
+// adversaryScenario converts an adversary to a scenario, with a phase per run of
+// abilities of the same tactic, or per phase of a Caldera 2 adversary
+func (c *CalderaConverter) adversaryScenario(
+	adversary *calderaDocument,
+	abilityTechniques map[string]*entity.Technique,
+	skipped map[string]bool,
+) (*entity.Scenario, error) {
+	scenario := &entity.Scenario{
+		Name:        adversary.Name,
+		Description: strings.TrimSpace(adversary.Description),
+		Tags:        []string{"caldera"},
+	}
+
+	lookup := func(abilityID string) (*entity.Technique, error) {
+		if technique := abilityTechniques[abilityID]; technique != nil || skipped[abilityID] {
+			return technique, nil
+		}
+		return nil, fmt.Errorf("unknown ability %s", abilityID)
+	}
+
+	if len(adversary.AtomicOrdering) > 0 {
+		for _, abilityID := range adversary.AtomicOrdering {
+			technique, err := lookup(abilityID)
+			if err != nil {
+				return nil, err
+			}
+			if technique != nil {
+				appendTacticPhase(scenario, technique)
+			}
+		}
+		return scenario, nil
+	}
+
+	for _, number := range sortedPhaseNumbers(adversary.Phases) {
+		phase := entity.Phase{Name: fmt.Sprintf("Phase %d", number), Order: len(scenario.Phases) + 1}
+		for _, abilityID := range adversary.Phases[number] {
+			technique, err := lookup(abilityID)
+			if err != nil {
+				return nil, err
+			}
+			if technique != nil {
+				phase.Techniques = append(phase.Techniques, technique.ID)
+			}
+		}
+		if len(phase.Techniques) > 0 {
+			scenario.Phases = append(scenario.Phases, phase)
+		}
+	}
+	return scenario, nil
+}
+
+// sortedPhaseNumbers returns the phase numbers of a Caldera 2 adversary in order
+func sortedPhaseNumbers(phases map[int][]string) []int {
+	numbers := make([]int, 0, len(phases))
+	for n := range phases {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
@@ -0,0 +1,214 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+const calderaContent = `
+---
+- id: c0da588f-0001
+  name: Identify active user
+  description: Find user running agent
+  tactic: discovery
+  technique:
+    attack_id: T1033
+    name: System Owner/User Discovery
+  platforms:
+    linux:
+      sh:
+        command: whoami
+        parsers:
+          plugins.stockpile.app.parsers.basic:
+            - source: host.user.name
+    windows:
+      psh,pwsh:
+        command: $env:username
+        timeout: 30
+      proc:
+        command: whoami.exe
+---
+id: c0da588f-0002
+name: Find user processes
+tactic: discovery
+privilege: Elevated
+technique:
+  attack_id: T1057
+  name: Process Discovery
+platforms:
+  linux:
+    sh:
+      command: 'ps aux | grep #{host.user.name} > #{location}'
+      cleanup:
+        - rm -f /tmp/ps.txt
+        - echo done
+requirements:
+  - plugins.stockpile.app.requirements.paw_provenance:
+      - source: host.user.name
+---
+id: c0da588f-0003
+name: Donut
+tactic: execution
+technique:
+  attack_id: T1055
+platforms:
+  windows:
+    donut_amd64:
+      command: run.donut
+---
+id: adversary-1
+name: Hunter
+description: Discover the host
+atomic_ordering:
+  - c0da588f-0001
+  - c0da588f-0003
+  - c0da588f-0002
+---
+id: adversary-2
+name: Legacy
+phases:
+  2:
+    - c0da588f-0002
+  1:
+    - c0da588f-0001
+`
+
+func TestCalderaConverter_Convert(t *testing.T) {
+	bundle, err := NewCalderaConverter().Convert([]byte(calderaContent))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	// The donut ability has no agent executor
+	if len(bundle.Techniques) != 2 {
+		t.Fatalf("Expected 2 techniques, got %d", len(bundle.Techniques))
+	}
+	user := bundle.Techniques[0]
+	if user.ID != "T1033" || user.Name != "System Owner/User Discovery" || user.Tactic != "discovery" ||
+		strings.Join(user.Platforms, ",") != "windows,linux" {
+		t.Errorf("Unexpected technique: %+v", user)
+	}
+	types := make([]string, 0, len(user.Executors))
+	for _, e := range user.Executors {
+		types = append(types, e.Type+"="+e.Command)
+	}
+	if strings.Join(types, ";") != "powershell=$env:username;pwsh=$env:username;sh=whoami" {
+		t.Errorf("Unexpected executors: %v", types)
+	}
+	if user.Executors[0].Timeout != 30 || user.Executors[2].Timeout != defaultTimeout || user.Executors[0].ElevationRequired {
+		t.Errorf("Unexpected timeouts: %+v", user.Executors)
+	}
+	if parsers := user.Executors[2].Parsers; len(parsers) != 1 || parsers[0].Fact != "host.user.name" || parsers[0].Regex == "" {
+		t.Errorf("Unexpected parsers: %+v", parsers)
+	}
+
+	processes := bundle.Techniques[1]
+	if processes.Executors[0].Command != "ps aux | grep #{fact.host.user.name} > #{location}" ||
+		processes.Executors[0].Cleanup != "rm -f /tmp/ps.txt\necho done" || !processes.Executors[0].ElevationRequired {
+		t.Errorf("Unexpected executor: %+v", processes.Executors[0])
+	}
+	if !strings.Contains(processes.Description, "Caldera requirements: host.user.name") {
+		t.Errorf("Expected the requirements in the description, got %q", processes.Description)
+	}
+
+	if len(bundle.Scenarios) != 2 {
+		t.Fatalf("Expected 2 scenarios, got %d", len(bundle.Scenarios))
+	}
+	hunter := bundle.Scenarios[0]
+	if hunter.Name != "Hunter" || len(hunter.Phases) != 1 || strings.Join(hunter.Phases[0].Techniques, ",") != "T1033,T1057" {
+		t.Errorf("Unexpected scenario: %+v", hunter)
+	}
+	legacy := bundle.Scenarios[1].Phases
+	if len(legacy) != 2 || legacy[0].Name != "Phase 1" || legacy[0].Techniques[0] != "T1033" || legacy[1].Order != 2 {
+		t.Errorf("Unexpected phases: %+v", legacy)
+	}
+}
+
+func TestCalderaConverter_CommandVariants(t *testing.T) {
+	content := `
+- id: c0da588f-0010
+  name: OS version
+  tactic: discovery
+  technique:
+    attack_id: T1082
+  platforms:
+    linux:
+      sh:
+        command: uname -a
+    darwin:
+      sh:
+        command: sw_vers
+- id: c0da588f-0011
+  name: Kernel
+  tactic: discovery
+  technique:
+    attack_id: T1082
+  platforms:
+    darwin:
+      sh:
+        command: uname -r
+    windows:
+      psh:
+        command: Get-ComputerInfo
+`
+	bundle, err := NewCalderaConverter().Convert([]byte(content))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(bundle.Techniques) != 1 {
+		t.Fatalf("Expected the abilities merged in one technique, got %d", len(bundle.Techniques))
+	}
+
+	technique := bundle.Techniques[0]
+	if strings.Join(technique.Platforms, ",") != "linux,darwin,windows" || len(technique.Executors) != 2 {
+		t.Fatalf("Unexpected technique: %+v", technique)
+	}
+	// The first procedure of a platform wins, the one shared with the command is dropped
+	sh := technique.Executors[0]
+	if sh.Command != "uname -a" || len(sh.Variants) != 1 || sh.Variants["darwin"].Command != "sw_vers" {
+		t.Errorf("Unexpected sh executor: %+v", sh)
+	}
+	if psh := technique.Executors[1]; psh.Variants != nil {
+		t.Errorf("Expected no variant for a single platform, got %+v", psh.Variants)
+	}
+}
+
+func TestCalderaConverter_ICSTactics(t *testing.T) {
+	content := `
+- id: c0da588f-0020
+  name: Stop the safety service
+  tactic: Inhibit Response Function
+  technique:
+    attack_id: T0881
+    name: Service Stop
+  platforms:
+    linux:
+      sh:
+        command: systemctl stop safety
+`
+	bundle, err := NewCalderaConverter().Convert([]byte(content))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(bundle.Techniques) != 1 || bundle.Techniques[0].Tactic != "inhibit-response-function" {
+		t.Errorf("Expected the ICS tactic, got %+v", bundle.Techniques)
+	}
+}
+
+func TestCalderaConverter_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":             "",
+		"invalid yaml":      "id: [",
+		"missing ATT&CK":    "id: x\ntactic: discovery\nplatforms:\n  linux:\n    sh:\n      command: id",
+		"unknown tactic":    "id: x\ntactic: hacking\ntechnique:\n  attack_id: T1\nplatforms:\n  linux:\n    sh:\n      command: id",
+		"no procedure":      "id: x\ntactic: discovery\ntechnique:\n  attack_id: T1\nplatforms:\n  linux:\n    proc:\n      command: id",
+		"unknown ability":   "id: a\nname: adversary\natomic_ordering: [missing]",
+		"invalid phase key": "id: a\nname: adversary\nphases:\n  first: [x]",
+	}
+
+	for name, data := range tests {
+		if _, err := NewCalderaConverter().Convert([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package content converts open attack-content formats into AutoStrike
+// techniques and scenarios, and watches the YAML content directory for changes.
+package content
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+)
+
+// defaultTimeout is the executor timeout, in seconds, of converted procedures
+const defaultTimeout = 120
+
+// killChain lists the ATT&CK tactics in kill chain order, used to order
+// phases, the ICS tactics among the enterprise ones
+var killChain = []entity.TacticType{
+	entity.TacticReconnaissance,
+	entity.TacticResourceDevelopment,
+	entity.TacticInitialAccess,
+	entity.TacticExecution,
+	entity.TacticPersistence,
+	entity.TacticPrivilegeEscalation,
+	entity.TacticDefenseEvasion,
+	entity.TacticEvasion,
+	entity.TacticCredentialAccess,
+	entity.TacticDiscovery,
+	entity.TacticLateralMovement,
+	entity.TacticCollection,
+	entity.TacticCommandAndControl,
+	entity.TacticExfiltration,
+	entity.TacticInhibitResponseFunction,
+	entity.TacticImpairProcessControl,
+	entity.TacticImpact,
+}
+
+// parseTactic converts a tactic name ("Defense Evasion", "defense-evasion") to a tactic
+func parseTactic(name string) (entity.TacticType, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(name, "_", " ")), "-"))
+	for _, tactic := range killChain {
+		if string(tactic) == normalized {
+			return tactic, nil
+		}
+	}
+	return "", fmt.Errorf("unknown tactic %q", name)
+}
+
+// tacticPhaseName returns the display name of a tactic ("defense-evasion" -> "Defense Evasion")
+func tacticPhaseName(tactic entity.TacticType) string {
+	words := strings.Split(string(tactic), "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// appendTacticPhase adds a technique to the last phase of a scenario, or to a
+// new phase when the last one is of another tactic
+func appendTacticPhase(scenario *entity.Scenario, technique *entity.Technique) {
+	phaseName := tacticPhaseName(technique.Tactic)
+	if n := len(scenario.Phases); n == 0 || scenario.Phases[n-1].Name != phaseName {
+		scenario.Phases = append(scenario.Phases, entity.Phase{Name: phaseName, Order: n + 1})
+	}
+	phase := &scenario.Phases[len(scenario.Phases)-1]
+	phase.Techniques = append(phase.Techniques, technique.ID)
+}
+
+// addProcedure merges the procedure of a platform into the technique: the first
+// procedure of an executor type becomes the executor, and the first procedure
+// of each platform a command variant of it. compactVariants then drops the
+// variants that repeat the executor command.
+func addProcedure(technique *entity.Technique, platform string, executor entity.Executor) {
+	variant := entity.CommandVariant{Command: executor.Command, Cleanup: executor.Cleanup}
+	existing := technique.ExecutorOfType(executor.Type)
+	if existing == nil {
+		executor.Variants = map[string]entity.CommandVariant{platform: variant}
+		technique.Executors = append(technique.Executors, executor)
+		return
+	}
+	if existing.Variants == nil {
+		existing.Variants = make(map[string]entity.CommandVariant)
+	}
+	if _, ok := existing.Variants[platform]; !ok {
+		existing.Variants[platform] = variant
+	}
+}
+
+// compactVariants drops the command variants identical to the command of
+// their executor, so a procedure shared by every platform has no variant
+func compactVariants(techniques []*entity.Technique) {
+	for _, technique := range techniques {
+		for i := range technique.Executors {
+			executor := &technique.Executors[i]
+			for platform, variant := range executor.Variants {
+				if variant.Command == executor.Command && variant.Cleanup == executor.Cleanup {
+					delete(executor.Variants, platform)
+				}
+			}
+			if len(executor.Variants) == 0 {
+				executor.Variants = nil
+			}
+		}
+	}
+}
+
+// inputArgument is an argument of a command, referenced as #{name}, in the
+// CTID and Atomic Red Team formats
+type inputArgument struct {
+	Description string `yaml:"description"`
+	Default     any    `yaml:"default"`
+}
+
+// substituteArguments replaces the #{name} references to input arguments by their default
+func substituteArguments(command string, arguments map[string]inputArgument) string {
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := arguments[name].Default; value != nil {
+			command = strings.ReplaceAll(command, "#{"+name+"}", fmt.Sprint(value))
+		}
+	}
+	return command
+}
+
+// addPlatform appends platform to platforms if missing
+func addPlatform(platforms []string, platform string) []string {
+	for _, p := range platforms {
+		if p == platform {
+			return platforms
+		}
+	}
+	return append(platforms, platform)
+}
+
+// Ensure converters satisfy the application port
+var (
+	_ application.ContentConverter = (*PreludeConverter)(nil)
+	_ application.ContentConverter = (*StratusConverter)(nil)
+	_ application.ContentConverter = (*CTIDConverter)(nil)
+	_ application.ContentConverter = (*CalderaConverter)(nil)
+	_ application.ContentConverter = (*STIXConverter)(nil)
+	_ application.ContentConverter = (*AtomicConverter)(nil)
+)
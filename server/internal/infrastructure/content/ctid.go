@@ -0,0 +1,194 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ctidEntry is an item of a CTID emulation plan: the plan details, or an ability
+type ctidEntry struct {
+	PlanDetails *ctidPlanDetails `yaml:"emulation_plan_details"`
+
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Tactic      string `yaml:"tactic"`
+	Technique   struct {
+		AttackID string `yaml:"attack_id"`
+		Name     string `yaml:"name"`
+	} `yaml:"technique"`
+	ProcedureStep  string                                 `yaml:"procedure_step"`
+	Platforms      map[string]map[string]preludeProcedure `yaml:"platforms"`
+	InputArguments map[string]inputArgument               `yaml:"input_arguments"`
+}
+
+// ctidPlanDetails describes the adversary an emulation plan emulates
+type ctidPlanDetails struct {
+	AdversaryName        string `yaml:"adversary_name"`
+	AdversaryDescription string `yaml:"adversary_description"`
+}
+
+// CTIDConverter converts the adversary emulation plans of the MITRE Center for
+// Threat-Informed Defense (FIN6, menuPass, ...). The input is the YAML plan: a
+// list starting with the emulation_plan_details and followed by abilities in
+// execution order. Abilities become the technique of their ATT&CK ID, with the
+// default of each input argument substituted into the commands (abilities of
+// the same technique are merged, keeping the first procedure per executor and
+// platform as command variants).
+// Abilities without a procedure for an agent executor, such as manual steps,
+// are skipped. The plan becomes a scenario with a phase per procedure step.
+type CTIDConverter struct{}
+
+// NewCTIDConverter creates a CTID emulation plan converter
+func NewCTIDConverter() *CTIDConverter {
+	return &CTIDConverter{}
+}
+
+// Format returns the format name
+func (c *CTIDConverter) Format() string {
+	return "ctid"
+}
+
+// Convert converts a CTID emulation plan
+func (c *CTIDConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	var entries []ctidEntry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse emulation plan: %w", err)
+	}
+
+	var details *ctidPlanDetails
+	var abilities []ctidEntry
+	for _, entry := range entries {
+		if entry.PlanDetails != nil {
+			details = entry.PlanDetails
+		} else if entry.Technique.AttackID != "" {
+			abilities = append(abilities, entry)
+		}
+	}
+	if details == nil || details.AdversaryName == "" {
+		return nil, errors.New("missing emulation_plan_details with an adversary_name")
+	}
+
+	bundle := &application.ContentBundle{}
+	techniques := make(map[string]*entity.Technique)
+	scenario := &entity.Scenario{
+		Name:        details.AdversaryName + " Emulation Plan",
+		Description: strings.TrimSpace(details.AdversaryDescription),
+		Tags:        []string{"ctid", "apt", strings.ToLower(strings.Join(strings.Fields(details.AdversaryName), "-"))},
+	}
+
+	lastStep := ""
+	for _, ability := range abilities {
+		technique, ok, err := c.mergeAbility(techniques[ability.Technique.AttackID], ability)
+		if err != nil {
+			return nil, fmt.Errorf("ability %s: %w", ctidAbilityName(ability), err)
+		}
+		if !ok {
+			continue
+		}
+		if techniques[technique.ID] == nil {
+			techniques[technique.ID] = technique
+			bundle.Techniques = append(bundle.Techniques, technique)
+		}
+
+		tactic, _ := parseTactic(ability.Tactic)
+		step := ctidStep(ability, tactic)
+		if n := len(scenario.Phases); n == 0 || step != lastStep {
+			scenario.Phases = append(scenario.Phases, entity.Phase{Name: ctidPhaseName(ability, tactic), Order: n + 1})
+			lastStep = step
+		}
+		phase := &scenario.Phases[len(scenario.Phases)-1]
+		if !slices.Contains(phase.Techniques, technique.ID) {
+			phase.Techniques = append(phase.Techniques, technique.ID)
+		}
+	}
+	if len(bundle.Techniques) == 0 {
+		return nil, errors.New("no ability with a procedure for a supported platform and executor")
+	}
+	compactVariants(bundle.Techniques)
+
+	bundle.Scenarios = append(bundle.Scenarios, scenario)
+	return bundle, nil
+}
+
+// mergeAbility adds the procedures of an ability to technique, or to a new
+// technique when nil. Reports false when the ability has no supported procedure.
+func (c *CTIDConverter) mergeAbility(technique *entity.Technique, ability ctidEntry) (*entity.Technique, bool, error) {
+	tactic, err := parseTactic(ability.Tactic)
+	if err != nil {
+		return nil, false, err
+	}
+
+	merged := technique
+	if merged == nil {
+		name := ability.Technique.Name
+		if name == "" {
+			name = ability.Name
+		}
+		merged = &entity.Technique{
+			ID:          ability.Technique.AttackID,
+			Name:        name,
+			Tactic:      tactic,
+			Description: strings.TrimSpace(ability.Description),
+		}
+	}
+
+	added := false
+	for _, platform := range []string{"windows", "linux", "darwin"} {
+		procedures, ok := ability.Platforms[platform]
+		if !ok {
+			continue
+		}
+		for _, executor := range sortedKeys(procedures) {
+			agentExecutor, ok := preludeExecutors[executor]
+			procedure := procedures[executor]
+			if !ok || strings.TrimSpace(procedure.Command) == "" {
+				continue
+			}
+			added = true
+			merged.Platforms = addPlatform(merged.Platforms, platform)
+			addProcedure(merged, platform, entity.Executor{
+				Type:    agentExecutor,
+				Command: substituteArguments(strings.TrimSpace(procedure.Command), ability.InputArguments),
+				Cleanup: substituteArguments(strings.TrimSpace(procedure.Cleanup), ability.InputArguments),
+				Timeout: defaultTimeout,
+			})
+		}
+	}
+	return merged, added, nil
+}
+
+// ctidStep returns the step an ability belongs to: the first part of its
+// procedure step ("2" for "2.A.4"), or its tactic when the plan has no steps
+func ctidStep(ability ctidEntry, tactic entity.TacticType) string {
+	if step, _, _ := strings.Cut(ability.ProcedureStep, "."); step != "" {
+		return step
+	}
+	return string(tactic)
+}
+
+// ctidPhaseName returns the name of the phase starting with an ability
+func ctidPhaseName(ability ctidEntry, tactic entity.TacticType) string {
+	if step, _, _ := strings.Cut(ability.ProcedureStep, "."); step != "" {
+		return "Step " + step + " - " + tacticPhaseName(tactic)
+	}
+	return tacticPhaseName(tactic)
+}
+
+// ctidAbilityName identifies an ability in errors
+func ctidAbilityName(ability ctidEntry) string {
+	if ability.Name != "" {
+		return ability.Name
+	}
+	if ability.ID != "" {
+		return ability.ID
+	}
+	return ability.Technique.AttackID
+}
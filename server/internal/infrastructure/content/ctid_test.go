@@ -0,0 +1,132 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+const ctidPlan = `
+- emulation_plan_details:
+    id: 6e4ec7b3-0000
+    adversary_name: FIN6
+    adversary_description: FIN6 is a financially motivated group.
+    attack_version: 8
+    format_version: 1.0
+
+- id: 0001
+  name: Remote System Discovery with AdFind
+  description: Lists the computers of the domain.
+  tactic: discovery
+  technique:
+    attack_id: T1018
+    name: Remote System Discovery
+  procedure_group: procedure_discovery
+  procedure_step: 1.1.1
+  platforms:
+    windows:
+      cmd:
+        command: |
+          #{adfind_path} -f objectcategory=computer > #{output_file}
+        cleanup: 'del #{output_file}'
+  input_arguments:
+    adfind_path:
+      description: Path to AdFind
+      type: path
+      default: C:\Tools\adfind.exe
+    output_file:
+      type: path
+      default: ad_computers.txt
+
+- id: 0002
+  name: Domain Trust Discovery
+  tactic: discovery
+  technique:
+    attack_id: T1482
+    name: Domain Trust Discovery
+  procedure_step: 1.1.2
+  platforms:
+    windows:
+      cmd:
+        command: nltest /domain_trusts
+
+- id: 0003
+  name: Operator reviews the output
+  tactic: discovery
+  technique:
+    attack_id: T1087
+  procedure_step: 1.1.3
+  platforms:
+    windows:
+      manual:
+        command: Review the files
+
+- id: 0004
+  name: Dump credentials
+  tactic: credential-access
+  technique:
+    attack_id: T1003.003
+    name: NTDS
+  procedure_step: 2.1.1
+  platforms:
+    windows:
+      psh:
+        command: 'ntdsutil "ac i ntds" "ifm" "create full #{path}" q q'
+  input_arguments:
+    path:
+      default: 1
+`
+
+func TestCTIDConverter_Convert(t *testing.T) {
+	bundle, err := NewCTIDConverter().Convert([]byte(ctidPlan))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	// The manual step is skipped
+	if len(bundle.Techniques) != 3 {
+		t.Fatalf("Expected 3 techniques, got %d", len(bundle.Techniques))
+	}
+	discovery := bundle.Techniques[0]
+	if discovery.ID != "T1018" || discovery.Name != "Remote System Discovery" || discovery.Tactic != "discovery" ||
+		strings.Join(discovery.Platforms, ",") != "windows" {
+		t.Errorf("Unexpected technique: %+v", discovery)
+	}
+	if len(discovery.Executors) != 1 || discovery.Executors[0].Type != "cmd" ||
+		discovery.Executors[0].Command != `C:\Tools\adfind.exe -f objectcategory=computer > ad_computers.txt` ||
+		discovery.Executors[0].Cleanup != "del ad_computers.txt" {
+		t.Errorf("Unexpected executors: %+v", discovery.Executors)
+	}
+	if ntds := bundle.Techniques[2]; ntds.Executors[0].Type != "powershell" ||
+		!strings.Contains(ntds.Executors[0].Command, "create full 1") {
+		t.Errorf("Unexpected executors: %+v", ntds.Executors)
+	}
+
+	if len(bundle.Scenarios) != 1 {
+		t.Fatalf("Expected 1 scenario, got %d", len(bundle.Scenarios))
+	}
+	scenario := bundle.Scenarios[0]
+	if scenario.Name != "FIN6 Emulation Plan" || strings.Join(scenario.Tags, ",") != "ctid,apt,fin6" {
+		t.Errorf("Unexpected scenario: %+v", scenario)
+	}
+	phases := scenario.Phases
+	if len(phases) != 2 || phases[0].Name != "Step 1 - Discovery" || strings.Join(phases[0].Techniques, ",") != "T1018,T1482" ||
+		phases[1].Name != "Step 2 - Credential Access" || phases[1].Order != 2 {
+		t.Errorf("Unexpected phases: %+v", phases)
+	}
+}
+
+func TestCTIDConverter_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"invalid yaml":   "- id: [",
+		"no details":     "- id: x\n  tactic: discovery\n  technique:\n    attack_id: T1\n  platforms:\n    linux:\n      sh:\n        command: id",
+		"unknown tactic": "- emulation_plan_details:\n    adversary_name: X\n- tactic: hacking\n  technique:\n    attack_id: T1",
+		"no procedure":   "- emulation_plan_details:\n    adversary_name: X\n- tactic: discovery\n  technique:\n    attack_id: T1\n  platforms:\n    linux:\n      manual:\n        command: id",
+	}
+
+	for name, data := range tests {
+		if _, err := NewCTIDConverter().Convert([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
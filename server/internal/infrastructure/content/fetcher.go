@@ -0,0 +1,54 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"autostrike/internal/infrastructure/integration"
+)
+
+// MaxFetchSize caps the size of downloaded content. The STIX bundle of the
+// enterprise ATT&CK domain is about 50 MB.
+const MaxFetchSize = 128 << 20
+
+// fetchTimeout bounds the download of a document, large bundles included
+const fetchTimeout = 5 * time.Minute
+
+// HTTPFetcher downloads upstream content over HTTP
+type HTTPFetcher struct {
+	client  *http.Client
+	maxSize int
+}
+
+// NewHTTPFetcher creates a fetcher refusing content over maxSize bytes
+func NewHTTPFetcher(maxSize int) *HTTPFetcher {
+	return &HTTPFetcher{client: &http.Client{Timeout: fetchTimeout}, maxSize: maxSize}
+}
+
+// Fetch downloads the content at url
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := integration.CheckStatus(resp); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(f.maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > f.maxSize {
+		return nil, fmt.Errorf("content exceeds %d bytes", f.maxSize)
+	}
+	return data, nil
+}
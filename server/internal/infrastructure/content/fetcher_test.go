@@ -0,0 +1,37 @@
+package content
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/emu.yaml":
+			_, _ = w.Write([]byte("emulation_plan_details: {}"))
+		case "/large":
+			_, _ = w.Write([]byte(strings.Repeat("a", 1025)))
+		default:
+			http.Error(w, "missing", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPFetcher(1024)
+	ctx := context.Background()
+
+	data, err := fetcher.Fetch(ctx, server.URL+"/emu.yaml")
+	if err != nil || string(data) != "emulation_plan_details: {}" {
+		t.Errorf("Unexpected content: %q, %v", data, err)
+	}
+	if _, err := fetcher.Fetch(ctx, server.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected a status error, got %v", err)
+	}
+	if _, err := fetcher.Fetch(ctx, server.URL+"/large"); err == nil {
+		t.Error("Expected content over the size limit to be rejected")
+	}
+}
@@ -0,0 +1,194 @@
+package content
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// preludeExecutors maps Prelude Operator executors to the agent executors.
+// Other executors (keyword, exfil, ...) run inside Operator and are skipped.
+var preludeExecutors = map[string]string{
+	"sh":     "sh",
+	"bash":   "bash",
+	"zsh":    "zsh",
+	"psh":    "powershell",
+	"pwsh":   "pwsh",
+	"cmd":    "cmd",
+	"python": "python3",
+}
+
+// preludeDocument is a Prelude Operator TTP, or a chain (adversary) when it lists TTPs
+type preludeDocument struct {
+	ID          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Tactic      string `yaml:"tactic"`
+	Technique   struct {
+		ID   string `yaml:"id"`
+		Name string `yaml:"name"`
+	} `yaml:"technique"`
+	Platforms map[string]map[string]preludeProcedure `yaml:"platforms"`
+	TTPs      []string                               `yaml:"ttps"`
+}
+
+// preludeProcedure is the command of a TTP for one platform and executor
+type preludeProcedure struct {
+	Command string `yaml:"command"`
+	Cleanup string `yaml:"cleanup"`
+}
+
+// PreludeConverter converts Prelude Operator TTPs and chains. The input is a
+// YAML stream of TTP and chain documents separated by "---". TTPs become the
+// technique of their ATT&CK ID (TTPs of the same technique are merged, keeping
+// the first procedure per executor and platform as command variants) and
+// chains become scenarios.
+type PreludeConverter struct{}
+
+// NewPreludeConverter creates a Prelude Operator converter
+func NewPreludeConverter() *PreludeConverter {
+	return &PreludeConverter{}
+}
+
+// Format returns the format name
+func (c *PreludeConverter) Format() string {
+	return "prelude"
+}
+
+// Convert converts a YAML stream of TTPs and chains
+func (c *PreludeConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	var ttps, chains []*preludeDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &preludeDocument{}
+		err := decoder.Decode(doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if len(doc.TTPs) > 0 {
+			chains = append(chains, doc)
+		} else if doc.ID != "" {
+			ttps = append(ttps, doc)
+		}
+	}
+	if len(ttps) == 0 && len(chains) == 0 {
+		return nil, errors.New("no TTPs or chains found")
+	}
+
+	bundle := &application.ContentBundle{}
+	techniques := make(map[string]*entity.Technique)
+	ttpTechniques := make(map[string]*entity.Technique)
+	for _, ttp := range ttps {
+		technique, err := c.mergeTTP(techniques, ttp)
+		if err != nil {
+			return nil, fmt.Errorf("TTP %s: %w", ttp.ID, err)
+		}
+		if techniques[technique.ID] == nil {
+			techniques[technique.ID] = technique
+			bundle.Techniques = append(bundle.Techniques, technique)
+		}
+		ttpTechniques[ttp.ID] = technique
+	}
+	compactVariants(bundle.Techniques)
+
+	for _, chain := range chains {
+		scenario, err := c.chainScenario(chain, ttpTechniques)
+		if err != nil {
+			return nil, fmt.Errorf("chain %s: %w", chain.Name, err)
+		}
+		bundle.Scenarios = append(bundle.Scenarios, scenario)
+	}
+
+	return bundle, nil
+}
+
+// mergeTTP returns the technique of a TTP, adding its procedures to the
+// technique already converted for the same ATT&CK ID
+func (c *PreludeConverter) mergeTTP(techniques map[string]*entity.Technique, ttp *preludeDocument) (*entity.Technique, error) {
+	if ttp.Technique.ID == "" {
+		return nil, errors.New("missing ATT&CK technique ID")
+	}
+	tactic, err := parseTactic(ttp.Tactic)
+	if err != nil {
+		return nil, err
+	}
+
+	technique := techniques[ttp.Technique.ID]
+	if technique == nil {
+		name := ttp.Technique.Name
+		if name == "" {
+			name = ttp.Name
+		}
+		technique = &entity.Technique{
+			ID:          ttp.Technique.ID,
+			Name:        name,
+			Tactic:      tactic,
+			Description: ttp.Description,
+		}
+	}
+
+	for _, platform := range []string{"windows", "linux", "darwin"} {
+		procedures, ok := ttp.Platforms[platform]
+		if !ok {
+			continue
+		}
+		for _, executor := range sortedKeys(procedures) {
+			agentExecutor, ok := preludeExecutors[executor]
+			procedure := procedures[executor]
+			if !ok || procedure.Command == "" {
+				continue
+			}
+			technique.Platforms = addPlatform(technique.Platforms, platform)
+			addProcedure(technique, platform, entity.Executor{
+				Type:    agentExecutor,
+				Command: procedure.Command,
+				Cleanup: procedure.Cleanup,
+				Timeout: defaultTimeout,
+			})
+		}
+	}
+	if len(technique.Executors) == 0 {
+		return nil, errors.New("no procedure for a supported platform and executor")
+	}
+
+	return technique, nil
+}
+
+// chainScenario converts a chain to a scenario, with a phase per run of TTPs of the same tactic
+func (c *PreludeConverter) chainScenario(chain *preludeDocument, ttpTechniques map[string]*entity.Technique) (*entity.Scenario, error) {
+	scenario := &entity.Scenario{
+		Name:        chain.Name,
+		Description: chain.Description,
+		Tags:        []string{"prelude"},
+	}
+
+	for _, ttpID := range chain.TTPs {
+		technique := ttpTechniques[ttpID]
+		if technique == nil {
+			return nil, fmt.Errorf("unknown TTP %s", ttpID)
+		}
+		appendTacticPhase(scenario, technique)
+	}
+
+	return scenario, nil
+}
+
+// sortedKeys returns the keys of a map, such as the executors of a platform, in a stable order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,114 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+const preludeTTPs = `
+id: 6a5b1c2d-0001
+name: Enumerate users
+description: Lists local accounts
+tactic: discovery
+technique:
+  id: T1087.001
+  name: "Account Discovery: Local Account"
+platforms:
+  linux:
+    sh:
+      command: cat /etc/passwd
+  darwin:
+    sh:
+      command: dscl . list /Users
+  windows:
+    psh:
+      command: Get-LocalUser
+    keyword:
+      command: enumerate
+---
+id: 6a5b1c2d-0002
+name: Enumerate users with net
+tactic: discovery
+technique:
+  id: T1087.001
+platforms:
+  windows:
+    cmd:
+      command: net user
+      cleanup: echo done
+---
+id: 6a5b1c2d-0003
+name: Clear history
+tactic: defense-evasion
+technique:
+  id: T1070.003
+  name: Clear Command History
+platforms:
+  linux:
+    bash:
+      command: history -c
+---
+id: chain-1
+name: Recon then cover tracks
+description: A short chain
+ttps:
+  - 6a5b1c2d-0001
+  - 6a5b1c2d-0002
+  - 6a5b1c2d-0003
+`
+
+func TestPreludeConverter_Convert(t *testing.T) {
+	bundle, err := NewPreludeConverter().Convert([]byte(preludeTTPs))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	if len(bundle.Techniques) != 2 {
+		t.Fatalf("Expected 2 techniques, got %d", len(bundle.Techniques))
+	}
+
+	// Both TTPs of T1087.001 are merged, the first sh procedure is kept
+	accounts := bundle.Techniques[0]
+	if accounts.ID != "T1087.001" || accounts.Name != "Account Discovery: Local Account" || accounts.Tactic != "discovery" {
+		t.Errorf("Unexpected technique: %+v", accounts)
+	}
+	if strings.Join(accounts.Platforms, ",") != "windows,linux,darwin" {
+		t.Errorf("Unexpected platforms: %v", accounts.Platforms)
+	}
+	types := make([]string, 0, len(accounts.Executors))
+	for _, e := range accounts.Executors {
+		types = append(types, e.Type+"="+e.Command)
+	}
+	if strings.Join(types, ";") != "powershell=Get-LocalUser;sh=cat /etc/passwd;cmd=net user" {
+		t.Errorf("Unexpected executors: %v", types)
+	}
+	if accounts.IsSafe {
+		t.Error("Imported techniques should not be marked safe")
+	}
+
+	if len(bundle.Scenarios) != 1 {
+		t.Fatalf("Expected 1 scenario, got %d", len(bundle.Scenarios))
+	}
+	phases := bundle.Scenarios[0].Phases
+	if len(phases) != 2 || phases[0].Name != "Discovery" || len(phases[0].Techniques) != 2 ||
+		phases[1].Name != "Defense Evasion" || phases[1].Order != 2 {
+		t.Errorf("Unexpected phases: %+v", phases)
+	}
+}
+
+func TestPreludeConverter_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":           "",
+		"invalid yaml":    "id: [",
+		"missing ATT&CK":  "id: x\ntactic: discovery\nplatforms:\n  linux:\n    sh:\n      command: id",
+		"unknown tactic":  "id: x\ntactic: hacking\ntechnique:\n  id: T1\nplatforms:\n  linux:\n    sh:\n      command: id",
+		"no procedure":    "id: x\ntactic: discovery\ntechnique:\n  id: T1\nplatforms:\n  linux:\n    keyword:\n      command: id",
+		"unknown chained": "id: c\nname: chain\nttps: [missing]",
+	}
+
+	for name, data := range tests {
+		if _, err := NewPreludeConverter().Convert([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package content
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+)
+
+// stixKillChains maps the kill chains of the ATT&CK domains, which also name
+// the external references holding the technique IDs, to their domain
+var stixKillChains = map[string]string{
+	"mitre-attack":        entity.DomainEnterprise,
+	"mitre-ics-attack":    entity.DomainICS,
+	"mitre-mobile-attack": entity.DomainMobile,
+}
+
+// stixBundle is a STIX 2.1 bundle as MITRE publishes the ATT&CK domains
+type stixBundle struct {
+	Type    string       `json:"type"`
+	Objects []stixObject `json:"objects"`
+}
+
+// stixObject holds the fields AutoStrike reads of the bundle objects: attack
+// patterns, the subtechnique-of relationships and the x-mitre-collection
+type stixObject struct {
+	Type        string   `json:"type"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Revoked     bool     `json:"revoked"`
+	Deprecated  bool     `json:"x_mitre_deprecated"`
+	Platforms   []string `json:"x_mitre_platforms"`
+	Version     string   `json:"x_mitre_version"`
+
+	KillChainPhases []struct {
+		KillChainName string `json:"kill_chain_name"`
+		PhaseName     string `json:"phase_name"`
+	} `json:"kill_chain_phases"`
+	ExternalReferences []struct {
+		SourceName string `json:"source_name"`
+		ExternalID string `json:"external_id"`
+		URL        string `json:"url"`
+	} `json:"external_references"`
+
+	RelationshipType string `json:"relationship_type"`
+	SourceRef        string `json:"source_ref"`
+	TargetRef        string `json:"target_ref"`
+}
+
+// STIXConverter converts the ATT&CK STIX 2.1 bundles MITRE publishes for the
+// enterprise, ICS and mobile domains (attack-stix-data). Attack patterns become
+// techniques with their ATT&CK ID, domain, name, description, tactics,
+// platforms and ATT&CK page, without executors: the bundles describe techniques, not
+// procedures. Sub-techniques take the parent of their subtechnique-of
+// relationship. Revoked and deprecated attack patterns are skipped. The ATT&CK
+// release of the x-mitre-collection is the version of the bundle, recorded in
+// the attack_version field of each technique.
+type STIXConverter struct{}
+
+// NewSTIXConverter creates an ATT&CK STIX converter
+func NewSTIXConverter() *STIXConverter {
+	return &STIXConverter{}
+}
+
+// Format returns the format name
+func (c *STIXConverter) Format() string {
+	return "stix"
+}
+
+// Convert converts an ATT&CK STIX bundle
+func (c *STIXConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	var stix stixBundle
+	if err := json.Unmarshal(data, &stix); err != nil {
+		return nil, fmt.Errorf("failed to parse STIX bundle: %w", err)
+	}
+	if stix.Type != "bundle" {
+		return nil, errors.New("not a STIX bundle")
+	}
+
+	bundle := &application.ContentBundle{}
+	for _, object := range stix.Objects {
+		if object.Type == "x-mitre-collection" {
+			bundle.Version = object.Version
+		}
+	}
+	techniques := make(map[string]*entity.Technique) // By STIX ID
+	for _, object := range stix.Objects {
+		if object.Type != "attack-pattern" || object.Revoked || object.Deprecated {
+			continue
+		}
+		if technique := stixTechnique(object); technique != nil {
+			if bundle.Version != "" {
+				technique.Metadata = entity.Metadata{entity.ATTACKVersionMetadataKey: bundle.Version}
+			}
+			techniques[object.ID] = technique
+			bundle.Techniques = append(bundle.Techniques, technique)
+		}
+	}
+	if len(bundle.Techniques) == 0 {
+		return nil, errors.New("no ATT&CK technique found")
+	}
+	setSTIXParents(stix.Objects, techniques)
+	return bundle, nil
+}
+
+// setSTIXParents gives the sub-techniques the parent of their subtechnique-of
+// relationship. Revoked relationships, and those of a skipped attack pattern,
+// are ignored.
+func setSTIXParents(objects []stixObject, techniques map[string]*entity.Technique) {
+	for _, object := range objects {
+		if object.Type != "relationship" || object.RelationshipType != "subtechnique-of" || object.Revoked || object.Deprecated {
+			continue
+		}
+		sub, parent := techniques[object.SourceRef], techniques[object.TargetRef]
+		if sub != nil && parent != nil {
+			sub.ParentID = parent.ID
+		}
+	}
+}
+
+// stixTechnique converts an attack pattern, or returns nil when it has no
+// ATT&CK ID or no known tactic
+func stixTechnique(object stixObject) *entity.Technique {
+	technique := &entity.Technique{Name: object.Name, Description: strings.TrimSpace(object.Description)}
+	for _, ref := range object.ExternalReferences {
+		if domain := stixKillChains[ref.SourceName]; domain != "" && ref.ExternalID != "" {
+			technique.ID = ref.ExternalID
+			technique.Domain = domain
+			if ref.URL != "" {
+				technique.References = []string{ref.URL}
+			}
+			break
+		}
+	}
+	if technique.ID == "" {
+		return nil
+	}
+
+	for _, phase := range object.KillChainPhases {
+		if stixKillChains[phase.KillChainName] == "" {
+			continue
+		}
+		if tactic, err := parseTactic(phase.PhaseName); err == nil {
+			technique.Tactics = append(technique.Tactics, tactic)
+		}
+	}
+	if len(technique.Tactics) == 0 {
+		return nil
+	}
+	technique.Tactic = technique.Tactics[0]
+
+	for _, platform := range object.Platforms {
+		technique.Platforms = addPlatform(technique.Platforms, stixPlatform(platform))
+	}
+	return technique
+}
+
+// stixPlatform converts an ATT&CK platform to an agent platform ("macOS" ->
+// "darwin"); the platforms no agent runs on are kept lowercase
+func stixPlatform(platform string) string {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	if platform == "macos" {
+		return "darwin"
+	}
+	return platform
+}
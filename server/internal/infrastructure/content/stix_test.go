@@ -0,0 +1,132 @@
+package content
+
+import (
+	"slices"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+)
+
+const stixEnterprise = `{
+  "type": "bundle",
+  "id": "bundle--1",
+  "objects": [
+    {"type": "x-mitre-collection", "id": "x-mitre-collection--1", "name": "Enterprise ATT&CK", "x_mitre_version": "15.1"},
+    {
+      "type": "attack-pattern", "id": "attack-pattern--parent", "name": "Command and Scripting Interpreter",
+      "description": "Adversaries may abuse interpreters. ",
+      "x_mitre_platforms": ["Linux", "macOS", "Windows"],
+      "x_mitre_domains": ["enterprise-attack"],
+      "kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "execution"}],
+      "external_references": [{"source_name": "mitre-attack", "external_id": "T1059", "url": "https://attack.mitre.org/techniques/T1059"}]
+    },
+    {
+      "type": "attack-pattern", "id": "attack-pattern--sub", "name": "PowerShell",
+      "x_mitre_platforms": ["Windows"],
+      "kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "execution"}],
+      "external_references": [
+        {"source_name": "capec", "external_id": "CAPEC-1"},
+        {"source_name": "mitre-attack", "external_id": "T1059.001", "url": "https://attack.mitre.org/techniques/T1059/001"}
+      ]
+    },
+    {
+      "type": "attack-pattern", "id": "attack-pattern--multi", "name": "Valid Accounts",
+      "x_mitre_platforms": ["Windows", "Containers"],
+      "kill_chain_phases": [
+        {"kill_chain_name": "mitre-attack", "phase_name": "defense-evasion"},
+        {"kill_chain_name": "mitre-attack", "phase_name": "persistence"}
+      ],
+      "external_references": [{"source_name": "mitre-attack", "external_id": "T1078"}]
+    },
+    {
+      "type": "attack-pattern", "id": "attack-pattern--revoked", "name": "Revoked", "revoked": true,
+      "kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "execution"}],
+      "external_references": [{"source_name": "mitre-attack", "external_id": "T1086"}]
+    },
+    {
+      "type": "attack-pattern", "id": "attack-pattern--deprecated", "name": "Deprecated", "x_mitre_deprecated": true,
+      "kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "execution"}],
+      "external_references": [{"source_name": "mitre-attack", "external_id": "T1064"}]
+    },
+    {"type": "relationship", "id": "relationship--1", "relationship_type": "subtechnique-of",
+     "source_ref": "attack-pattern--sub", "target_ref": "attack-pattern--parent"},
+    {"type": "malware", "id": "malware--1", "name": "Not a technique"}
+  ]
+}`
+
+func TestSTIXConverter_Convert(t *testing.T) {
+	bundle, err := NewSTIXConverter().Convert([]byte(stixEnterprise))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	if bundle.Version != "15.1" || len(bundle.Techniques) != 3 {
+		t.Fatalf("Expected 3 techniques of ATT&CK 15.1, got %d of %q", len(bundle.Techniques), bundle.Version)
+	}
+	parent := bundle.Techniques[0]
+	if parent.ID != "T1059" || parent.Name != "Command and Scripting Interpreter" || parent.Tactic != entity.TacticExecution ||
+		parent.Description != "Adversaries may abuse interpreters." || len(parent.Executors) != 0 {
+		t.Errorf("Unexpected technique: %+v", parent)
+	}
+	if !slices.Equal(parent.Platforms, []string{"linux", "darwin", "windows"}) {
+		t.Errorf("Unexpected platforms: %v", parent.Platforms)
+	}
+	if len(parent.References) != 1 || parent.References[0] != "https://attack.mitre.org/techniques/T1059" {
+		t.Errorf("Unexpected references: %v", parent.References)
+	}
+	if parent.Domain != entity.DomainEnterprise {
+		t.Errorf("Expected the enterprise domain, got %q", parent.Domain)
+	}
+	if parent.Metadata[entity.ATTACKVersionMetadataKey] != "15.1" {
+		t.Errorf("Expected the ATT&CK release recorded, got %v", parent.Metadata)
+	}
+	if sub := bundle.Techniques[1]; sub.ID != "T1059.001" || sub.ParentID != "T1059" {
+		t.Errorf("Expected the mitre-attack ID and subtechnique-of parent of the sub-technique, got %s of %q", sub.ID, sub.ParentID)
+	}
+	if parent.ParentID != "" || bundle.Techniques[2].ParentID != "" {
+		t.Error("Expected no parent for the techniques without a subtechnique-of relationship")
+	}
+	accounts := bundle.Techniques[2]
+	if accounts.Tactic != entity.TacticDefenseEvasion ||
+		!slices.Equal(accounts.Tactics, []entity.TacticType{entity.TacticDefenseEvasion, entity.TacticPersistence}) ||
+		!slices.Equal(accounts.Platforms, []string{"windows", "containers"}) {
+		t.Errorf("Unexpected technique: %+v", accounts)
+	}
+}
+
+func TestSTIXConverter_ConvertICS(t *testing.T) {
+	data := `{"type": "bundle", "objects": [
+		{"type": "x-mitre-collection", "id": "x-mitre-collection--1", "x_mitre_version": "15"},
+		{"type": "attack-pattern", "id": "attack-pattern--1", "name": "Activate Firmware Update Mode",
+		 "x_mitre_platforms": ["None"],
+		 "kill_chain_phases": [{"kill_chain_name": "mitre-ics-attack", "phase_name": "inhibit-response-function"}],
+		 "external_references": [{"source_name": "mitre-ics-attack", "external_id": "T0800"}]}
+	]}`
+
+	bundle, err := NewSTIXConverter().Convert([]byte(data))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	technique := bundle.Techniques[0]
+	if technique.ID != "T0800" || technique.Domain != entity.DomainICS || technique.Tactic != entity.TacticInhibitResponseFunction {
+		t.Errorf("Unexpected ICS technique: %+v", technique)
+	}
+}
+
+func TestSTIXConverter_ConvertErrors(t *testing.T) {
+	tests := map[string]string{
+		"invalid JSON": "{",
+		"not a bundle": `{"type": "attack-pattern"}`,
+		"no technique": `{"type": "bundle", "objects": [{"type": "malware", "id": "malware--1"}]}`,
+		"unknown tactic": `{"type": "bundle", "objects": [{"type": "attack-pattern", "id": "attack-pattern--1", "name": "X",
+			"kill_chain_phases": [{"kill_chain_name": "mitre-attack", "phase_name": "unknown"}],
+			"external_references": [{"source_name": "mitre-attack", "external_id": "T9999"}]}]}`,
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewSTIXConverter().Convert([]byte(data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
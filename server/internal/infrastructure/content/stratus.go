@@ -0,0 +1,148 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stratusDocsURL is the documentation page of a Stratus Red Team technique
+const stratusDocsURL = "https://stratus-red-team.cloud/attack-techniques/%s/%s/"
+
+// stratusTechnique is the metadata of a Stratus Red Team attack technique
+type stratusTechnique struct {
+	ID           string   `yaml:"id"`
+	FriendlyName string   `yaml:"friendlyName"`
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description"`
+	Platform     string   `yaml:"platform"`
+	Tactics      []string `yaml:"mitreAttackTactics"`
+}
+
+// StratusConverter converts Stratus Red Team cloud attack techniques. The input
+// is a YAML or JSON list of technique metadata (id, friendlyName, description,
+// platform, mitreAttackTactics). Each technique becomes an AutoStrike technique,
+// identified by its Stratus ID, that detonates and cleans up the attack with the
+// stratus CLI of the agent; a scenario per cloud platform runs them by tactic.
+type StratusConverter struct{}
+
+// NewStratusConverter creates a Stratus Red Team converter
+func NewStratusConverter() *StratusConverter {
+	return &StratusConverter{}
+}
+
+// Format returns the format name
+func (c *StratusConverter) Format() string {
+	return "stratus"
+}
+
+// Convert converts a list of Stratus Red Team techniques
+func (c *StratusConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	var items []stratusTechnique
+	if err := yaml.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse techniques: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, errors.New("no techniques found")
+	}
+
+	bundle := &application.ContentBundle{}
+	byPlatform := make(map[string][]*entity.Technique)
+	var platforms []string
+
+	for _, item := range items {
+		technique, err := c.convertTechnique(item)
+		if err != nil {
+			return nil, fmt.Errorf("technique %s: %w", item.ID, err)
+		}
+		bundle.Techniques = append(bundle.Techniques, technique)
+
+		platform := strings.ToUpper(item.Platform)
+		if byPlatform[platform] == nil {
+			platforms = append(platforms, platform)
+		}
+		byPlatform[platform] = append(byPlatform[platform], technique)
+	}
+
+	for _, platform := range platforms {
+		bundle.Scenarios = append(bundle.Scenarios, stratusScenario(platform, byPlatform[platform]))
+	}
+
+	return bundle, nil
+}
+
+// convertTechnique converts a Stratus technique to a technique run with the stratus CLI
+func (c *StratusConverter) convertTechnique(item stratusTechnique) (*entity.Technique, error) {
+	if item.ID == "" || item.Platform == "" {
+		return nil, errors.New("id and platform are required")
+	}
+	if len(item.Tactics) == 0 {
+		return nil, errors.New("no MITRE ATT&CK tactic")
+	}
+	tactics := make([]entity.TacticType, 0, len(item.Tactics))
+	for _, name := range item.Tactics {
+		tactic, err := parseTactic(name)
+		if err != nil {
+			return nil, err
+		}
+		tactics = append(tactics, tactic)
+	}
+
+	name := item.FriendlyName
+	if name == "" {
+		name = item.Name
+	}
+	if name == "" {
+		name = item.ID
+	}
+
+	detonate := "stratus detonate " + item.ID
+	cleanup := "stratus cleanup " + item.ID
+	executors := make([]entity.Executor, 0, 3)
+	for _, executor := range []string{"sh", "bash", "powershell"} {
+		executors = append(executors, entity.Executor{Type: executor, Command: detonate, Cleanup: cleanup, Timeout: defaultTimeout})
+	}
+
+	return &entity.Technique{
+		ID:          item.ID,
+		Name:        name,
+		Tactic:      tactics[0],
+		Tactics:     tactics,
+		Description: strings.TrimSpace(item.Description),
+		Platforms:   []string{"linux", "darwin", "windows"},
+		Executors:   executors,
+		References:  []string{fmt.Sprintf(stratusDocsURL, strings.ToUpper(item.Platform), item.ID)},
+	}, nil
+}
+
+// stratusScenario builds the scenario of a cloud platform, with a phase per tactic in kill chain order
+func stratusScenario(platform string, techniques []*entity.Technique) *entity.Scenario {
+	scenario := &entity.Scenario{
+		Name:        "Stratus Red Team - " + platform,
+		Description: fmt.Sprintf("Stratus Red Team attack techniques against %s. Agents need the stratus CLI and cloud credentials.", platform),
+		Tags:        []string{"stratus", "cloud", strings.ToLower(platform)},
+	}
+
+	for _, tactic := range killChain {
+		var ids []string
+		for _, t := range techniques {
+			if t.Tactic == tactic {
+				ids = append(ids, t.ID)
+			}
+		}
+		if len(ids) > 0 {
+			scenario.Phases = append(scenario.Phases, entity.Phase{
+				Name:       tacticPhaseName(tactic),
+				Techniques: ids,
+				Order:      len(scenario.Phases) + 1,
+			})
+		}
+	}
+
+	return scenario
+}
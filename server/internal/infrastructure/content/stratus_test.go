@@ -0,0 +1,72 @@
+package content
+
+import (
+	"testing"
+)
+
+const stratusTechniques = `
+- id: aws.defense-evasion.cloudtrail-stop
+  friendlyName: Stop CloudTrail Trail
+  description: Stops a CloudTrail Trail from logging.
+  platform: aws
+  mitreAttackTactics: [Defense Evasion]
+- id: aws.credential-access.ec2-get-password-data
+  friendlyName: Retrieve EC2 Password Data
+  platform: AWS
+  mitreAttackTactics: [Credential Access]
+- id: k8s.persistence.create-admin-clusterrole
+  name: Create Admin ClusterRole
+  platform: kubernetes
+  mitreAttackTactics: [Persistence, Privilege Escalation]
+`
+
+func TestStratusConverter_Convert(t *testing.T) {
+	bundle, err := NewStratusConverter().Convert([]byte(stratusTechniques))
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+
+	if len(bundle.Techniques) != 3 {
+		t.Fatalf("Expected 3 techniques, got %d", len(bundle.Techniques))
+	}
+	stop := bundle.Techniques[0]
+	if stop.ID != "aws.defense-evasion.cloudtrail-stop" || stop.Name != "Stop CloudTrail Trail" || stop.Tactic != "defense-evasion" {
+		t.Errorf("Unexpected technique: %+v", stop)
+	}
+	if len(stop.Executors) != 3 || stop.Executors[0].Command != "stratus detonate aws.defense-evasion.cloudtrail-stop" ||
+		stop.Executors[0].Cleanup != "stratus cleanup aws.defense-evasion.cloudtrail-stop" {
+		t.Errorf("Unexpected executors: %+v", stop.Executors)
+	}
+	if len(stop.References) != 1 || stop.References[0] != "https://stratus-red-team.cloud/attack-techniques/AWS/aws.defense-evasion.cloudtrail-stop/" {
+		t.Errorf("Unexpected references: %v", stop.References)
+	}
+	if bundle.Techniques[2].Tactic != "persistence" || len(bundle.Techniques[2].Tactics) != 2 ||
+		bundle.Techniques[2].Tactics[1] != "privilege-escalation" {
+		t.Errorf("Expected both tactics, the first as primary, got %s %v", bundle.Techniques[2].Tactic, bundle.Techniques[2].Tactics)
+	}
+
+	if len(bundle.Scenarios) != 2 {
+		t.Fatalf("Expected a scenario per platform, got %d", len(bundle.Scenarios))
+	}
+	aws := bundle.Scenarios[0]
+	if aws.Name != "Stratus Red Team - AWS" || len(aws.Phases) != 2 ||
+		aws.Phases[0].Name != "Defense Evasion" || aws.Phases[1].Name != "Credential Access" {
+		t.Errorf("Unexpected AWS scenario: %+v", aws)
+	}
+}
+
+func TestStratusConverter_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "[]",
+		"not a list":     "id: aws.x",
+		"missing id":     "- platform: aws\n  mitreAttackTactics: [Discovery]",
+		"no tactic":      "- id: aws.x\n  platform: aws",
+		"unknown tactic": "- id: aws.x\n  platform: aws\n  mitreAttackTactics: [Hacking]",
+	}
+
+	for name, data := range tests {
+		if _, err := NewStratusConverter().Convert([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
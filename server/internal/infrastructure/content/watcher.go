@@ -0,0 +1,144 @@
+package content
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"autostrike/internal/application"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// DefaultReloadDelay is how long the watcher waits after the last change of a
+// content file before reloading, so that an editor saving several files, or
+// writing one in several steps, triggers a single reload
+const DefaultReloadDelay = time.Second
+
+// contentSubdirs are the subdirectories of the content directory holding YAML files
+var contentSubdirs = []string{"techniques", "scenarios"}
+
+// Watcher reloads the content directory of a reload service when its
+// technique or scenario YAML files change
+type Watcher struct {
+	service *application.ContentReloadService
+	delay   time.Duration
+	logger  *zap.Logger
+
+	watcher *fsnotify.Watcher
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewWatcher creates a watcher reloading changes after delay
+func NewWatcher(service *application.ContentReloadService, delay time.Duration, logger *zap.Logger) *Watcher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Watcher{service: service, delay: delay, logger: logger}
+}
+
+// Start watches the content directory and its techniques and scenarios
+// subdirectories, including ones created later
+func (w *Watcher) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dir := w.service.Dir()
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+	for _, sub := range contentSubdirs {
+		w.watchDir(watcher, filepath.Join(dir, sub))
+	}
+
+	w.watcher = watcher
+	w.stop = make(chan struct{})
+	w.wg.Add(1)
+	go w.run()
+	return nil
+}
+
+// Stop stops watching and waits for a running reload
+func (w *Watcher) Stop() {
+	if w.watcher == nil {
+		return
+	}
+	close(w.stop)
+	w.watcher.Close()
+	w.wg.Wait()
+	w.watcher = nil
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	timer := time.NewTimer(w.delay)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) && w.isSubdir(event.Name) {
+				w.watchDir(w.watcher, event.Name)
+			}
+			if w.relevant(event) {
+				timer.Reset(w.delay)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("Content watcher error", zap.Error(err))
+		case <-timer.C:
+			if _, err := w.service.Reload(context.Background(), false); err != nil {
+				w.logger.Warn("Failed to reload content", zap.Error(err))
+			}
+		}
+	}
+}
+
+// relevant reports whether an event changes a content file, or adds or
+// removes a subdirectory of them
+func (w *Watcher) relevant(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	return isYAML(event.Name) || w.isSubdir(event.Name)
+}
+
+// isSubdir reports whether path is a techniques or scenarios subdirectory of the content directory
+func (w *Watcher) isSubdir(path string) bool {
+	for _, sub := range contentSubdirs {
+		if filepath.Clean(path) == filepath.Join(w.service.Dir(), sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchDir watches a subdirectory of the content directory when it exists
+func (w *Watcher) watchDir(watcher *fsnotify.Watcher, dir string) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return
+	}
+	if err := watcher.Add(dir); err != nil {
+		w.logger.Warn("Failed to watch content directory", zap.String("dir", dir), zap.Error(err))
+	}
+}
+
+// isYAML reports whether a file name is a YAML file
+func isYAML(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
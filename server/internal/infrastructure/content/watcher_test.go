@@ -0,0 +1,55 @@
+package content
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/persistence/sqlite"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestWatcher_ReloadsChangedFiles(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqlite.InitSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	techniqueRepo := sqlite.NewTechniqueRepository(db)
+	plans := application.NewContentPlanService(techniqueRepo, sqlite.NewScenarioRepository(db), sqlite.NewTrashRepository(db), nil)
+
+	// The techniques subdirectory is created once the watcher runs
+	dir := t.TempDir()
+	watcher := NewWatcher(application.NewContentReloadService(plans, dir, nil), 20*time.Millisecond, nil)
+	if err := watcher.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	if err := os.Mkdir(filepath.Join(dir, "techniques"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	technique := "- id: T1082\n  name: System Information Discovery\n  tactic: discovery\n"
+	if err := os.WriteFile(filepath.Join(dir, "techniques", "discovery.yaml"), []byte(technique), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if loaded, err := techniqueRepo.FindByID(context.Background(), "T1082"); err == nil && loaded != nil {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("Expected the technique file reloaded")
+}
@@ -0,0 +1,178 @@
+package edr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+// DefaultCrowdStrikeURL is the US-1 Falcon API endpoint
+const DefaultCrowdStrikeURL = "https://api.crowdstrike.com"
+
+// crowdStrikeMaxAlerts bounds the number of alerts fetched per query
+const crowdStrikeMaxAlerts = 100
+
+// CrowdStrikeConfig configures the CrowdStrike Falcon connector
+type CrowdStrikeConfig struct {
+	URL          string // Falcon API base URL, defaults to DefaultCrowdStrikeURL
+	ClientID     string
+	ClientSecret string
+}
+
+// CrowdStrikeConnector queries Falcon alerts with a prevention disposition
+type CrowdStrikeConnector struct {
+	config CrowdStrikeConfig
+	client *http.Client
+	auth   *integration.ClientCredentials
+}
+
+// NewCrowdStrikeConnector creates a new CrowdStrike connector
+func NewCrowdStrikeConnector(config CrowdStrikeConfig) *CrowdStrikeConnector {
+	if config.URL == "" {
+		config.URL = DefaultCrowdStrikeURL
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	return &CrowdStrikeConnector{
+		config: config,
+		client: integration.NewHTTPClient(),
+		auth:   integration.NewClientCredentials(config.URL+"/oauth2/token", config.ClientID, config.ClientSecret, ""),
+	}
+}
+
+// Name returns the connector name
+func (c *CrowdStrikeConnector) Name() string {
+	return "crowdstrike"
+}
+
+type crowdStrikeAlert struct {
+	CompositeID string `json:"composite_id"`
+	Timestamp   string `json:"timestamp"`
+	Name        string `json:"display_name"`
+	TechniqueID string `json:"technique_id"`
+	Filename    string `json:"filename"`
+	Cmdline     string `json:"cmdline"`
+	Device      struct {
+		Hostname string `json:"hostname"`
+	} `json:"device"`
+	ParentDetails struct {
+		Filename string `json:"filename"`
+	} `json:"parent_details"`
+	DispositionDescription string          `json:"pattern_disposition_description"`
+	DispositionDetails     map[string]bool `json:"pattern_disposition_details"`
+}
+
+// preventionDispositions are the Falcon disposition flags that mean the activity was stopped
+var preventionDispositions = []string{
+	"kill_process", "kill_parent", "kill_subprocess", "quarantine_file",
+	"operation_blocked", "process_blocked", "registry_operation_blocked",
+}
+
+// SearchPreventions returns Falcon alerts on the host where the activity was prevented
+func (c *CrowdStrikeConnector) SearchPreventions(ctx context.Context, query application.PreventionQuery) ([]application.PreventionEvent, error) {
+	token, err := c.auth.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("crowdstrike authentication failed: %w", err)
+	}
+
+	ids, err := c.queryAlertIDs(ctx, token, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	alerts, err := c.getAlerts(ctx, token, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []application.PreventionEvent
+	for _, alert := range alerts {
+		if !alertPrevented(alert) {
+			continue
+		}
+		event := application.PreventionEvent{
+			ID:                alert.CompositeID,
+			Hostname:          alert.Device.Hostname,
+			Name:              alert.Name,
+			Action:            alert.DispositionDescription,
+			ProcessName:       alert.Filename,
+			CommandLine:       alert.Cmdline,
+			ParentProcessName: alert.ParentDetails.Filename,
+		}
+		if alert.TechniqueID != "" {
+			event.TechniqueIDs = []string{alert.TechniqueID}
+		}
+		if ts, err := time.Parse(time.RFC3339, alert.Timestamp); err == nil {
+			event.Timestamp = ts
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (c *CrowdStrikeConnector) queryAlertIDs(ctx context.Context, token string, query application.PreventionQuery) ([]string, error) {
+	filter := fmt.Sprintf("device.hostname:'%s'+timestamp:>='%s'+timestamp:<='%s'",
+		escapeQuoted(query.Hostname),
+		query.Start.UTC().Format(time.RFC3339),
+		query.End.UTC().Format(time.RFC3339),
+	)
+	params := url.Values{}
+	params.Set("filter", filter)
+	params.Set("limit", fmt.Sprint(crowdStrikeMaxAlerts))
+
+	req, err := http.NewRequest(http.MethodGet, c.config.URL+"/alerts/queries/alerts/v2?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var resp struct {
+		Resources []string `json:"resources"`
+	}
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("crowdstrike alert query failed: %w", err)
+	}
+	return resp.Resources, nil
+}
+
+func (c *CrowdStrikeConnector) getAlerts(ctx context.Context, token string, ids []string) ([]crowdStrikeAlert, error) {
+	body, err := json.Marshal(map[string][]string{"composite_ids": ids})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.config.URL+"/alerts/entities/alerts/v2", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp struct {
+		Resources []crowdStrikeAlert `json:"resources"`
+	}
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("crowdstrike alert details failed: %w", err)
+	}
+	return resp.Resources, nil
+}
+
+func alertPrevented(alert crowdStrikeAlert) bool {
+	for _, flag := range preventionDispositions {
+		if alert.DispositionDetails[flag] {
+			return true
+		}
+	}
+	return false
+}
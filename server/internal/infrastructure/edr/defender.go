@@ -0,0 +1,132 @@
+package edr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+const (
+	defaultDefenderAPIURL   = "https://api.securitycenter.microsoft.com"
+	defaultDefenderLoginURL = "https://login.microsoftonline.com"
+	defenderScope           = "https://api.securitycenter.microsoft.com/.default"
+)
+
+// DefenderConfig configures the Microsoft Defender for Endpoint connector
+type DefenderConfig struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	APIURL       string // Overrides the Defender API endpoint (GCC, tests)
+	LoginURL     string // Overrides the Entra ID login endpoint
+}
+
+// DefenderConnector runs advanced hunting queries for blocked activity
+type DefenderConnector struct {
+	config DefenderConfig
+	client *http.Client
+	auth   *integration.ClientCredentials
+}
+
+// NewDefenderConnector creates a new Defender for Endpoint connector
+func NewDefenderConnector(config DefenderConfig) *DefenderConnector {
+	if config.APIURL == "" {
+		config.APIURL = defaultDefenderAPIURL
+	}
+	if config.LoginURL == "" {
+		config.LoginURL = defaultDefenderLoginURL
+	}
+	config.APIURL = strings.TrimRight(config.APIURL, "/")
+	config.LoginURL = strings.TrimRight(config.LoginURL, "/")
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", config.LoginURL, url.PathEscape(config.TenantID))
+	return &DefenderConnector{
+		config: config,
+		client: integration.NewHTTPClient(),
+		auth:   integration.NewClientCredentials(tokenURL, config.ClientID, config.ClientSecret, defenderScope),
+	}
+}
+
+// Name returns the connector name
+func (c *DefenderConnector) Name() string {
+	return "defender"
+}
+
+type defenderHuntingResponse struct {
+	Results []struct {
+		ReportID                  json.Number `json:"ReportId"`
+		Timestamp                 string      `json:"Timestamp"`
+		DeviceName                string      `json:"DeviceName"`
+		ActionType                string      `json:"ActionType"`
+		FileName                  string      `json:"FileName"`
+		ProcessCommandLine        string      `json:"ProcessCommandLine"`
+		InitiatingProcessFileName string      `json:"InitiatingProcessFileName"`
+	} `json:"Results"`
+}
+
+// SearchPreventions returns blocked device events on the host
+func (c *DefenderConnector) SearchPreventions(ctx context.Context, query application.PreventionQuery) ([]application.PreventionEvent, error) {
+	token, err := c.auth.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("defender authentication failed: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"Query": buildDefenderQuery(query)})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.config.APIURL+"/api/advancedqueries/run", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp defenderHuntingResponse
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("defender hunting query failed: %w", err)
+	}
+
+	events := make([]application.PreventionEvent, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		event := application.PreventionEvent{
+			ID:                r.ReportID.String(),
+			Hostname:          r.DeviceName,
+			Name:              r.ActionType,
+			Action:            r.ActionType,
+			ProcessName:       r.FileName,
+			CommandLine:       r.ProcessCommandLine,
+			ParentProcessName: r.InitiatingProcessFileName,
+		}
+		if ts, err := time.Parse(time.RFC3339, r.Timestamp); err == nil {
+			event.Timestamp = ts
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// buildDefenderQuery selects ASR, exploit protection and antivirus blocks on the device.
+// DeviceName is the FQDN in Defender, so the short hostname is matched as a prefix.
+func buildDefenderQuery(query application.PreventionQuery) string {
+	host := escapeQuoted(strings.ToLower(query.Hostname))
+	return fmt.Sprintf(`DeviceEvents
+| where Timestamp between (datetime(%s) .. datetime(%s))
+| where DeviceName =~ '%s' or DeviceName startswith '%s.'
+| where ActionType endswith "Blocked" or ActionType in ("AntivirusDetectionActionType", "AntivirusDetection")
+| project ReportId, Timestamp, DeviceName, ActionType, FileName, ProcessCommandLine, InitiatingProcessFileName
+| take 100`,
+		query.Start.UTC().Format(time.RFC3339),
+		query.End.UTC().Format(time.RFC3339),
+		host, host,
+	)
+}
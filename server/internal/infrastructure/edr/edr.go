@@ -0,0 +1,21 @@
+// Package edr provides EDR connectors used to classify techniques as blocked
+// from real prevention events rather than exit codes.
+package edr
+
+import (
+	"strings"
+
+	"autostrike/internal/application"
+)
+
+// Ensure connectors satisfy the application port
+var (
+	_ application.EDRConnector = (*CrowdStrikeConnector)(nil)
+	_ application.EDRConnector = (*DefenderConnector)(nil)
+	_ application.EDRConnector = (*SentinelOneConnector)(nil)
+)
+
+// escapeQuoted escapes single quotes for FQL/KQL string literals
+func escapeQuoted(s string) string {
+	return strings.ReplaceAll(s, "'", "\\'")
+}
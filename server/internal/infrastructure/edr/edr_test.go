@@ -0,0 +1,164 @@
+package edr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+)
+
+func testQuery() application.PreventionQuery {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	return application.PreventionQuery{Hostname: "WS-01", Start: start, End: start.Add(5 * time.Minute)}
+}
+
+func TestCrowdStrikeConnector_SearchPreventions(t *testing.T) {
+	var gotFilter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/token":
+			_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":1800}`))
+		case "/alerts/queries/alerts/v2":
+			if r.Header.Get("Authorization") != "Bearer tok" {
+				t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+			}
+			gotFilter = r.URL.Query().Get("filter")
+			_, _ = w.Write([]byte(`{"resources":["id-1","id-2"]}`))
+		case "/alerts/entities/alerts/v2":
+			var body map[string][]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if len(body["composite_ids"]) != 2 {
+				t.Errorf("Expected composite IDs in body, got %v", body)
+			}
+			_, _ = w.Write([]byte(`{"resources":[
+				{"composite_id":"id-1","timestamp":"2024-01-15T10:01:00Z","display_name":"CredentialDumping","technique_id":"T1003",
+				 "cmdline":"rundll32.exe comsvcs.dll, MiniDump","device":{"hostname":"WS-01"},"parent_details":{"filename":"autostrike-agent.exe"},
+				 "pattern_disposition_description":"Prevention, process killed.","pattern_disposition_details":{"kill_process":true}},
+				{"composite_id":"id-2","display_name":"Detect only","pattern_disposition_details":{"detect":true}}
+			]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewCrowdStrikeConnector(CrowdStrikeConfig{URL: server.URL, ClientID: "id", ClientSecret: "secret"})
+	events, err := c.SearchPreventions(context.Background(), testQuery())
+	if err != nil {
+		t.Fatalf("SearchPreventions failed: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected only the prevented alert, got %+v", events)
+	}
+	e := events[0]
+	if e.ID != "id-1" || e.TechniqueIDs[0] != "T1003" || e.ParentProcessName != "autostrike-agent.exe" || e.Timestamp.IsZero() {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+	if !strings.Contains(gotFilter, "device.hostname:'WS-01'") || !strings.Contains(gotFilter, "timestamp:>='2024-01-15T10:00:00Z'") {
+		t.Errorf("Unexpected filter: %s", gotFilter)
+	}
+}
+
+func TestCrowdStrikeConnector_NoAlerts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/token":
+			_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":1800}`))
+		case "/alerts/queries/alerts/v2":
+			_, _ = w.Write([]byte(`{"resources":[]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewCrowdStrikeConnector(CrowdStrikeConfig{URL: server.URL})
+	events, err := c.SearchPreventions(context.Background(), testQuery())
+	if err != nil || len(events) != 0 {
+		t.Errorf("Expected no events, got %v (err=%v)", events, err)
+	}
+}
+
+func TestDefenderConnector_SearchPreventions(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tenant-1/oauth2/v2.0/token":
+			_ = r.ParseForm()
+			if r.PostForm.Get("scope") != defenderScope {
+				t.Errorf("Unexpected scope %q", r.PostForm.Get("scope"))
+			}
+			_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+		case "/api/advancedqueries/run":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			gotQuery = body["Query"]
+			_, _ = w.Write([]byte(`{"Results":[{"ReportId":1234,"Timestamp":"2024-01-15T10:01:00.123Z","DeviceName":"ws-01.corp.local",
+				"ActionType":"AsrLsassCredentialTheftBlocked","FileName":"rundll32.exe","ProcessCommandLine":"rundll32.exe comsvcs.dll, MiniDump",
+				"InitiatingProcessFileName":"powershell.exe"}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewDefenderConnector(DefenderConfig{TenantID: "tenant-1", APIURL: server.URL, LoginURL: server.URL})
+	events, err := c.SearchPreventions(context.Background(), testQuery())
+	if err != nil {
+		t.Fatalf("SearchPreventions failed: %v", err)
+	}
+
+	if len(events) != 1 || events[0].ID != "1234" || events[0].Action != "AsrLsassCredentialTheftBlocked" || events[0].Timestamp.IsZero() {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+	if !strings.Contains(gotQuery, "DeviceName startswith 'ws-01.'") || !strings.Contains(gotQuery, "datetime(2024-01-15T10:05:00Z)") {
+		t.Errorf("Unexpected KQL: %s", gotQuery)
+	}
+}
+
+func TestSentinelOneConnector_SearchPreventions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/web/api/v2.1/threats" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "ApiToken s1-token" {
+			t.Errorf("Expected ApiToken auth, got %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("computerName__contains") != "WS-01" {
+			t.Errorf("Unexpected query: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"data":[
+			{"id":"t1","threatInfo":{"threatName":"mimikatz.exe","mitigationStatus":"mitigated","createdAt":"2024-01-15T10:01:00Z",
+			 "originatorProcess":"autostrike-agent.exe"},"agentRealtimeInfo":{"agentComputerName":"WS-01"}},
+			{"id":"t2","threatInfo":{"threatName":"other","mitigationStatus":"not_mitigated"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	c := NewSentinelOneConnector(SentinelOneConfig{URL: server.URL + "/", APIToken: "s1-token"})
+	events, err := c.SearchPreventions(context.Background(), testQuery())
+	if err != nil {
+		t.Fatalf("SearchPreventions failed: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "t1" || events[0].ParentProcessName != "autostrike-agent.exe" {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+}
+
+func TestSentinelOneConnector_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c := NewSentinelOneConnector(SentinelOneConfig{URL: server.URL})
+	if _, err := c.SearchPreventions(context.Background(), testQuery()); err == nil {
+		t.Error("Expected error on 401")
+	}
+}
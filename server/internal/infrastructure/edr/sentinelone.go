@@ -0,0 +1,97 @@
+package edr
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+// SentinelOneConfig configures the SentinelOne connector
+type SentinelOneConfig struct {
+	URL      string // Management console URL, e.g. https://usea1.sentinelone.net
+	APIToken string
+}
+
+// SentinelOneConnector queries mitigated SentinelOne threats
+type SentinelOneConnector struct {
+	config SentinelOneConfig
+	client *http.Client
+}
+
+// NewSentinelOneConnector creates a new SentinelOne connector
+func NewSentinelOneConnector(config SentinelOneConfig) *SentinelOneConnector {
+	config.URL = strings.TrimRight(config.URL, "/")
+	return &SentinelOneConnector{config: config, client: integration.NewHTTPClient()}
+}
+
+// Name returns the connector name
+func (c *SentinelOneConnector) Name() string {
+	return "sentinelone"
+}
+
+type sentinelOneThreatsResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		ThreatInfo struct {
+			ThreatName               string `json:"threatName"`
+			MitigationStatus         string `json:"mitigationStatus"`
+			CreatedAt                string `json:"createdAt"`
+			OriginatorProcess        string `json:"originatorProcess"`
+			MaliciousProcessArgument string `json:"maliciousProcessArguments"`
+			ProcessName              string `json:"processName"`
+		} `json:"threatInfo"`
+		AgentRealtimeInfo struct {
+			AgentComputerName string `json:"agentComputerName"`
+		} `json:"agentRealtimeInfo"`
+	} `json:"data"`
+}
+
+// SearchPreventions returns mitigated threats on the host
+func (c *SentinelOneConnector) SearchPreventions(ctx context.Context, query application.PreventionQuery) ([]application.PreventionEvent, error) {
+	params := url.Values{}
+	params.Set("computerName__contains", query.Hostname)
+	params.Set("createdAt__gte", query.Start.UTC().Format(time.RFC3339))
+	params.Set("createdAt__lte", query.End.UTC().Format(time.RFC3339))
+	params.Set("mitigationStatuses", "mitigated")
+	params.Set("limit", "100")
+
+	req, err := http.NewRequest(http.MethodGet, c.config.URL+"/web/api/v2.1/threats?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "ApiToken "+c.config.APIToken)
+
+	var resp sentinelOneThreatsResponse
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("sentinelone threat query failed: %w", err)
+	}
+
+	events := make([]application.PreventionEvent, 0, len(resp.Data))
+	for _, threat := range resp.Data {
+		// Older consoles ignore the mitigationStatuses filter
+		if threat.ThreatInfo.MitigationStatus != "" && threat.ThreatInfo.MitigationStatus != "mitigated" {
+			continue
+		}
+		event := application.PreventionEvent{
+			ID:                threat.ID,
+			Hostname:          threat.AgentRealtimeInfo.AgentComputerName,
+			Name:              threat.ThreatInfo.ThreatName,
+			Action:            "mitigated",
+			ProcessName:       threat.ThreatInfo.ProcessName,
+			CommandLine:       threat.ThreatInfo.MaliciousProcessArgument,
+			ParentProcessName: threat.ThreatInfo.OriginatorProcess,
+		}
+		if ts, err := time.Parse(time.RFC3339, threat.ThreatInfo.CreatedAt); err == nil {
+			event.Timestamp = ts
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
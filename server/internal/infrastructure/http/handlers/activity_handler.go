@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityHandler exposes detected operator activity anomalies to admins
+type ActivityHandler struct {
+	monitor *application.ActivityMonitor
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(monitor *application.ActivityMonitor) *ActivityHandler {
+	return &ActivityHandler{monitor: monitor}
+}
+
+// RegisterRoutes registers activity routes (requires admin role)
+func (h *ActivityHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/admin/activity/anomalies", h.ListAnomalies)
+}
+
+// ListAnomalies godoc
+// @Summary List activity anomalies
+// @Description List unusual operator activity (new login IP/country, off-hours executions, mass deletions), newest first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Limit (default: 50, max: 500)"
+// @Success 200 {array} entity.ActivityAnomaly
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/activity/anomalies [get]
+func (h *ActivityHandler) ListAnomalies(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	anomalies, err := h.monitor.ListAnomalies(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list anomalies"})
+		return
+	}
+
+	if anomalies == nil {
+		anomalies = []*entity.ActivityAnomaly{}
+	}
+
+	c.JSON(http.StatusOK, anomalies)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockActivityRepo struct {
+	mu        sync.Mutex
+	logins    []*entity.LoginRecord
+	anomalies []*entity.ActivityAnomaly
+	err       error
+}
+
+func (m *mockActivityRepo) CreateLogin(ctx context.Context, record *entity.LoginRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logins = append(m.logins, record)
+	return nil
+}
+
+func (m *mockActivityRepo) FindLoginsByUser(ctx context.Context, userID string, limit int) ([]*entity.LoginRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*entity.LoginRecord
+	for _, login := range m.logins {
+		if login.UserID == userID {
+			result = append(result, login)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockActivityRepo) CreateAnomaly(ctx context.Context, anomaly *entity.ActivityAnomaly) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.anomalies = append(m.anomalies, anomaly)
+	return nil
+}
+
+func (m *mockActivityRepo) FindAnomalies(ctx context.Context, limit int) ([]*entity.ActivityAnomaly, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	if len(m.anomalies) < limit {
+		limit = len(m.anomalies)
+	}
+	return m.anomalies[:limit], nil
+}
+
+// newTestActivityMonitor returns a monitor that treats every moment as off-hours
+// and flags mass deletion from the second scenario deleted
+func newTestActivityMonitor(repo *mockActivityRepo, userRepo *mockUserRepo) *application.ActivityMonitor {
+	config := application.DefaultActivityMonitorConfig()
+	config.BusinessDays = nil
+	config.MassDeletionThreshold = 2
+	config.CountryHeader = "CF-IPCountry"
+	return application.NewActivityMonitor(repo, userRepo, nil, config, nil)
+}
+
+func TestActivityHandler_ListAnomalies(t *testing.T) {
+	repo := &mockActivityRepo{anomalies: []*entity.ActivityAnomaly{
+		{ID: "a1", Type: entity.AnomalyNewIP, Severity: entity.SeverityMedium, Message: "new ip", CreatedAt: time.Now()},
+		{ID: "a2", Type: entity.AnomalyMassDeletion, Severity: entity.SeverityHigh, Message: "deleted", CreatedAt: time.Now()},
+	}}
+	handler := NewActivityHandler(newTestActivityMonitor(repo, newMockUserRepo()))
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("", func(c *gin.Context) { c.Set("user_id", "admin-1") }))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/activity/anomalies?limit=1", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var anomalies []entity.ActivityAnomaly
+	if err := json.Unmarshal(w.Body.Bytes(), &anomalies); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0].ID != "a1" {
+		t.Errorf("Expected limit to apply, got %+v", anomalies)
+	}
+}
+
+func TestActivityHandler_ListAnomalies_Empty(t *testing.T) {
+	handler := NewActivityHandler(newTestActivityMonitor(&mockActivityRepo{}, newMockUserRepo()))
+
+	router := gin.New()
+	router.GET("/anomalies", withAuth(handler.ListAnomalies))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/anomalies?limit=abc", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "[]" {
+		t.Errorf("Expected empty array, got %s", w.Body.String())
+	}
+}
+
+func TestActivityHandler_ListAnomalies_Error(t *testing.T) {
+	repo := &mockActivityRepo{err: errors.New("db down")}
+	handler := NewActivityHandler(newTestActivityMonitor(repo, newMockUserRepo()))
+
+	router := gin.New()
+	router.GET("/anomalies", withAuth(handler.ListAnomalies))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/anomalies", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestActivityHandler_ListAnomalies_Unauthenticated(t *testing.T) {
+	handler := NewActivityHandler(newTestActivityMonitor(&mockActivityRepo{}, newMockUserRepo()))
+
+	router := gin.New()
+	router.GET("/anomalies", handler.ListAnomalies)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/anomalies", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
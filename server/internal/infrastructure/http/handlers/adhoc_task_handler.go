@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdHocTaskHandler lets administrators run one-off commands on agents
+type AdHocTaskHandler struct {
+	service *application.AdHocTaskService
+	hub     *websocket.Hub
+}
+
+// NewAdHocTaskHandler creates a new ad-hoc task handler
+func NewAdHocTaskHandler(service *application.AdHocTaskService, hub *websocket.Hub) *AdHocTaskHandler {
+	return &AdHocTaskHandler{service: service, hub: hub}
+}
+
+// RunTask godoc
+// @Summary Run an ad-hoc command on an agent
+// @Description Send a one-off command to a connected agent, outside any scenario. The command is recorded with the administrator who ran it; its result is broadcast over the WebSocket as adhoc_task_completed.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Param request body application.AdHocTaskRequest true "Command"
+// @Success 202 {object} entity.AdHocTask
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 423 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/agents/{paw}/task [post]
+func (h *AdHocTaskHandler) RunTask(c *gin.Context) {
+	var req application.AdHocTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	paw := c.Param("paw")
+	if !h.hub.IsAgentConnected(paw) {
+		c.JSON(http.StatusConflict, gin.H{"error": "agent is not connected"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	task, err := h.service.Create(ctx, paw, req, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if reason := sendTask(ctx, h.hub, application.TaskDispatchInfo{
+		ResultID:    task.ID,
+		AgentPaw:    task.AgentPaw,
+		TechniqueID: application.AdHocTechniqueID,
+		Command:     task.Command,
+		Executor:    task.Executor,
+		Timeout:     task.Timeout,
+	}); reason != "" {
+		if failed, err := h.service.Fail(ctx, task.ID, reason); err == nil {
+			task = failed
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": reason, "task": task})
+		return
+	}
+
+	broadcastAdHocTask(h.hub, "adhoc_task_started", task)
+	c.JSON(http.StatusAccepted, task)
+}
+
+// ListTasks godoc
+// @Summary List the ad-hoc commands of an agent
+// @Description Audit trail of the one-off commands run on an agent, newest first
+// @Tags agents
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Param limit query int false "Limit (default: 50, max: 500)"
+// @Success 200 {array} entity.AdHocTask
+// @Router /api/v1/agents/{paw}/tasks [get]
+func (h *AdHocTaskHandler) ListTasks(c *gin.Context) {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	tasks, err := h.service.ListByAgent(c.Request.Context(), c.Param("paw"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list ad-hoc tasks"})
+		return
+	}
+
+	// Return empty array instead of null
+	if tasks == nil {
+		tasks = []*entity.AdHocTask{}
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetTask godoc
+// @Summary Get an ad-hoc command
+// @Description Get an ad-hoc command of an agent with its output once completed
+// @Tags agents
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Param id path string true "Task ID"
+// @Success 200 {object} entity.AdHocTask
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agents/{paw}/tasks/{id} [get]
+func (h *AdHocTaskHandler) GetTask(c *gin.Context) {
+	task, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err == nil && task.AgentPaw != c.Param("paw") {
+		err = application.ErrAdHocTaskNotFound
+	}
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// respondError maps an ad-hoc task service error to a response
+func (h *AdHocTaskHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidAdHocTask):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAdHocTaskNotFound), errors.Is(err, application.ErrAdHocAgentUnknown):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrEmergencyStopEngaged):
+		c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ad-hoc task operation failed"})
+	}
+}
+
+// broadcastAdHocTask sends an ad-hoc task event to the connected clients
+func broadcastAdHocTask(hub *websocket.Hub, eventType string, task *entity.AdHocTask) {
+	if hub == nil {
+		return
+	}
+	msgBytes, err := json.Marshal(map[string]interface{}{
+		"type":    eventType,
+		"payload": task,
+	})
+	if err != nil {
+		return
+	}
+	hub.Broadcast(msgBytes)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// mockAdHocTaskRepo implements repository.AdHocTaskRepository for tests
+type mockAdHocTaskRepo struct {
+	tasks []*entity.AdHocTask
+}
+
+func (m *mockAdHocTaskRepo) Create(ctx context.Context, task *entity.AdHocTask) error {
+	m.tasks = append(m.tasks, task)
+	return nil
+}
+
+func (m *mockAdHocTaskRepo) Update(ctx context.Context, task *entity.AdHocTask) error {
+	return nil
+}
+
+func (m *mockAdHocTaskRepo) FindByID(ctx context.Context, id string) (*entity.AdHocTask, error) {
+	for _, task := range m.tasks {
+		if task.ID == id {
+			return task, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAdHocTaskRepo) FindByAgent(ctx context.Context, paw string, limit int) ([]*entity.AdHocTask, error) {
+	var tasks []*entity.AdHocTask
+	for _, task := range m.tasks {
+		if task.AgentPaw == paw {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func setupAdHocTaskRouter(t *testing.T) (*gin.Engine, *application.AdHocTaskService, *websocket.Hub) {
+	t.Helper()
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{Paw: "paw1", Executors: []string{"sh"}}
+	agentRepo.agents["offline"] = &entity.Agent{Paw: "offline", Executors: []string{"sh"}}
+	svc := application.NewAdHocTaskService(&mockAdHocTaskRepo{}, agentRepo, nil)
+
+	hub := websocket.NewHub(zap.NewNop())
+	go hub.Run()
+	hub.RegisterAgent("paw1", websocket.NewClient(hub, nil, "paw1", zap.NewNop()))
+	handler := NewAdHocTaskHandler(svc, hub)
+
+	router := gin.New()
+	agents := router.Group("/api/v1/agents", func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	agents.POST("/:paw/task", handler.RunTask)
+	agents.GET("/:paw/tasks", handler.ListTasks)
+	agents.GET("/:paw/tasks/:id", handler.GetTask)
+	return router, svc, hub
+}
+
+func TestAdHocTaskHandler_RunTask(t *testing.T) {
+	router, _, _ := setupAdHocTaskRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/agents/paw1/task", strings.NewReader(`{"command":"whoami","timeout":30}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d (%s)", w.Code, w.Body.String())
+	}
+	var task entity.AdHocTask
+	_ = json.Unmarshal(w.Body.Bytes(), &task)
+	if !application.IsAdHocTaskID(task.ID) || task.RequestedBy != "admin-1" || task.Executor != "sh" || task.Timeout != 30 {
+		t.Errorf("Unexpected task: %+v", task)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		code int
+	}{
+		{"no command", "/api/v1/agents/paw1/task", `{}`, http.StatusBadRequest},
+		{"bad executor", "/api/v1/agents/paw1/task", `{"command":"id","executor":"cmd"}`, http.StatusBadRequest},
+		{"offline agent", "/api/v1/agents/offline/task", `{"command":"id"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestAdHocTaskHandler_ListAndGetTasks(t *testing.T) {
+	router, svc, _ := setupAdHocTaskRouter(t)
+	task, _ := svc.Create(context.Background(), "paw1", application.AdHocTaskRequest{Command: "id"}, "admin-1")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/agents/offline/tasks", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "[]" {
+		t.Errorf("Expected an empty array, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/api/v1/agents/paw1/tasks?limit=10", http.StatusOK},
+		{"/api/v1/agents/paw1/tasks/" + task.ID, http.StatusOK},
+		{"/api/v1/agents/offline/tasks/" + task.ID, http.StatusNotFound},
+		{"/api/v1/agents/paw1/tasks/adhoc-missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.path, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestWebSocketHandler_HandleAdHocTaskResult(t *testing.T) {
+	_, svc, hub := setupAdHocTaskRouter(t)
+	task, _ := svc.Create(context.Background(), "paw1", application.AdHocTaskRequest{Command: "whoami"}, "admin-1")
+
+	handler := NewWebSocketHandler(hub, application.NewAgentService(newWSTestAgentRepo()), zap.NewNop())
+	client := websocket.NewClient(hub, nil, "paw1", zap.NewNop())
+	payload, _ := json.Marshal(TaskResultPayload{TaskID: task.ID, Success: true, Output: "root"})
+
+	// Without the ad-hoc service the result is only acknowledged
+	handler.handleTaskResult(client, payload)
+
+	handler.SetAdHocTaskService(svc)
+	handler.handleTaskResult(client, payload)
+	recorded, _ := svc.Get(context.Background(), task.ID)
+	if recorded.Status != entity.StatusSuccess || recorded.Output != "root" {
+		t.Errorf("Expected the result to be recorded, got %+v", recorded)
+	}
+
+	// A resent result is ignored
+	handler.handleTaskResult(client, payload)
+}
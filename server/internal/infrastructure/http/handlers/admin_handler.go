@@ -40,10 +40,14 @@ func (h *AdminHandler) RegisterRoutes(r *gin.RouterGroup) {
 			users.POST("", h.CreateUser)
 			users.PUT("/:id", h.UpdateUser)
 			users.PUT("/:id/role", h.UpdateUserRole)
+			users.PUT("/:id/workspace", h.SetUserWorkspace)
 			users.DELETE("/:id", h.DeactivateUser)
 			users.POST("/:id/reactivate", h.ReactivateUser)
 			users.POST("/:id/reset-password", h.ResetPassword)
+			users.POST("/:id/unlock", h.UnlockUser)
 		}
+		admin.GET("/lockouts", h.ListLockouts)
+		admin.DELETE("/lockouts/ips/:ip", h.UnlockIP)
 	}
 }
 
@@ -57,9 +61,11 @@ type ListUsersResponse struct {
 type UserResponse struct {
 	ID          string  `json:"id"`
 	Username    string  `json:"username"`
+	DisplayName string  `json:"display_name,omitempty"`
 	Email       string  `json:"email"`
 	Role        string  `json:"role"`
 	RoleDisplay string  `json:"role_display"`
+	Workspace   string  `json:"workspace,omitempty"`
 	IsActive    bool    `json:"is_active"`
 	LastLoginAt *string `json:"last_login_at,omitempty"`
 	CreatedAt   string  `json:"created_at"`
@@ -71,9 +77,11 @@ func toUserResponse(user *entity.User) *UserResponse {
 	resp := &UserResponse{
 		ID:          user.ID,
 		Username:    user.Username,
+		DisplayName: user.DisplayName,
 		Email:       user.Email,
 		Role:        string(user.Role),
 		RoleDisplay: user.Role.DisplayName(),
+		Workspace:   user.Workspace,
 		IsActive:    user.IsActive,
 		CreatedAt:   user.CreatedAt.Format(timeFormatISO8601),
 		UpdatedAt:   user.UpdatedAt.Format(timeFormatISO8601),
@@ -85,7 +93,15 @@ func toUserResponse(user *entity.User) *UserResponse {
 	return resp
 }
 
-// ListUsers returns all users
+// ListUsers godoc
+// @Summary List users
+// @Description List every user account, active or not
+// @Tags admin
+// @Produce json
+// @Success 200 {object} ListUsersResponse
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/users [get]
 func (h *AdminHandler) ListUsers(c *gin.Context) {
 	// Check admin permission
 	if !h.isAdmin(c) {
@@ -110,7 +126,16 @@ func (h *AdminHandler) ListUsers(c *gin.Context) {
 	})
 }
 
-// GetUser returns a specific user
+// GetUser godoc
+// @Summary Get a user
+// @Description Get a user account
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} UserResponse
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/users/{id} [get]
 func (h *AdminHandler) GetUser(c *gin.Context) {
 	if !h.isAdmin(c) {
 		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
@@ -144,7 +169,18 @@ type CreateUserRequest struct {
 	Role     string `json:"role" binding:"required"`
 }
 
-// CreateUser creates a new user
+// CreateUser godoc
+// @Summary Create a user
+// @Description Create a user account with a role
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body CreateUserRequest true "User"
+// @Success 201 {object} UserResponse
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/admin/users [post]
 func (h *AdminHandler) CreateUser(c *gin.Context) {
 	if !h.isAdmin(c) {
 		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
@@ -230,7 +266,20 @@ func mergeUserFields(req *UpdateUserRequest, currentUser *entity.User) (string,
 	return username, email, role, true
 }
 
-// UpdateUser updates a user's details
+// UpdateUser godoc
+// @Summary Update a user
+// @Description Update the username, email or role of a user; omitted fields are kept
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body UpdateUserRequest true "Fields to update"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/admin/users/{id} [put]
 func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	if !h.isAdmin(c) {
 		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
@@ -282,7 +331,19 @@ type UpdateRoleRequest struct {
 	Role string `json:"role" binding:"required"`
 }
 
-// UpdateUserRole updates only a user's role
+// UpdateUserRole godoc
+// @Summary Change the role of a user
+// @Description Change the role of a user, which sets their permissions
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body UpdateRoleRequest true "Role"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/users/{id}/role [put]
 func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
 	if !h.isAdmin(c) {
 		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
@@ -322,7 +383,70 @@ func (h *AdminHandler) UpdateUserRole(c *gin.Context) {
 	c.JSON(http.StatusOK, toUserResponse(user))
 }
 
-// DeactivateUser deactivates a user account
+// SetWorkspaceRequest represents the set workspace request body
+type SetWorkspaceRequest struct {
+	Workspace string `json:"workspace"` // Empty to remove the user from their workspace
+}
+
+// SetUserWorkspace godoc
+// @Summary Move a user to a workspace
+// @Description Move a user to a workspace, whose users share the per-workspace execution quota. An empty workspace removes the user from their workspace. Takes effect at the next login or token refresh.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body SetWorkspaceRequest true "Workspace"
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/users/{id}/workspace [put]
+func (h *AdminHandler) SetUserWorkspace(c *gin.Context) {
+	if !h.isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
+		return
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUserIDRequired})
+		return
+	}
+
+	var req SetWorkspaceRequest
+	if c.ShouldBindJSON(&req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	user, err := h.authService.SetUserWorkspace(c.Request.Context(), id, req.Workspace)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidWorkspace) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "workspace must be lowercase letters, digits, dots, dashes or underscores"})
+			return
+		}
+		if errors.Is(err, application.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errUserNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user workspace"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// DeactivateUser godoc
+// @Summary Deactivate a user
+// @Description Deactivate a user account; the user can no longer log in
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/users/{id} [delete]
 func (h *AdminHandler) DeactivateUser(c *gin.Context) {
 	if !h.isAdmin(c) {
 		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
@@ -358,7 +482,16 @@ func (h *AdminHandler) DeactivateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "user deactivated successfully"})
 }
 
-// ReactivateUser reactivates a deactivated user account
+// ReactivateUser godoc
+// @Summary Reactivate a user
+// @Description Reactivate a deactivated user account
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/users/{id}/reactivate [post]
 func (h *AdminHandler) ReactivateUser(c *gin.Context) {
 	if !h.isAdmin(c) {
 		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
@@ -388,7 +521,19 @@ type ResetPasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8,max=72"`
 }
 
-// ResetPassword resets a user's password (admin action)
+// ResetPassword godoc
+// @Summary Reset the password of a user
+// @Description Set a new password for a user
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body ResetPasswordRequest true "New password"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/users/{id}/reset-password [post]
 func (h *AdminHandler) ResetPassword(c *gin.Context) {
 	if !h.isAdmin(c) {
 		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
@@ -419,6 +564,91 @@ func (h *AdminHandler) ResetPassword(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
 }
 
+// UnlockUser godoc
+// @Summary Unlock a user
+// @Description Lift the lockout of a user after repeated failed logins and forget their failures
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	if !h.isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
+		return
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUserIDRequired})
+		return
+	}
+
+	if err := h.authService.UnlockUser(c.Request.Context(), id); err != nil {
+		if errors.Is(err, application.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errUserNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unlocked successfully"})
+}
+
+// ListLockouts godoc
+// @Summary List login lockouts
+// @Description List the usernames and source IPs locked out after repeated failed logins
+// @Tags admin
+// @Produce json
+// @Success 200 {array} entity.LoginThrottle
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/lockouts [get]
+func (h *AdminHandler) ListLockouts(c *gin.Context) {
+	if !h.isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
+		return
+	}
+
+	lockouts, err := h.authService.ListLockouts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list lockouts"})
+		return
+	}
+	if lockouts == nil {
+		lockouts = []*entity.LoginThrottle{}
+	}
+
+	c.JSON(http.StatusOK, lockouts)
+}
+
+// UnlockIP godoc
+// @Summary Unlock a source IP
+// @Description Lift the lockout of a source IP after repeated failed logins and forget its failures
+// @Tags admin
+// @Produce json
+// @Param ip path string true "Source IP address"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/lockouts/ips/{ip} [delete]
+func (h *AdminHandler) UnlockIP(c *gin.Context) {
+	if !h.isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": errAdminAccessRequired})
+		return
+	}
+
+	if err := h.authService.UnlockIP(c.Request.Context(), c.Param("ip")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock source IP"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "source IP unlocked successfully"})
+}
+
 // isAdmin checks if the current user has admin role
 func (h *AdminHandler) isAdmin(c *gin.Context) bool {
 	role, exists := c.Get("role")
@@ -500,6 +500,71 @@ func TestAdminHandler_ResetPassword_UserNotFound(t *testing.T) {
 	}
 }
 
+func TestAdminHandler_Lockouts(t *testing.T) {
+	repo := newMockUserRepo()
+	service := application.NewAuthService(repo, "test-secret")
+	handler := NewAdminHandler(service)
+
+	repo.users["user-1"] = &entity.User{ID: "user-1", Username: "testuser", IsActive: true}
+	for i := 0; i < application.DefaultLoginLockoutConfig().MaxUserFailures; i++ {
+		_, _ = service.LoginFrom(context.Background(), "testuser", "wrong", "10.0.0.1")
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("role", "admin")
+		c.Next()
+	})
+	router.GET("/lockouts", handler.ListLockouts)
+	router.POST("/users/:id/unlock", handler.UnlockUser)
+	router.DELETE("/lockouts/ips/:ip", handler.UnlockIP)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("GET", "/lockouts")
+	var lockouts []entity.LoginThrottle
+	json.Unmarshal(w.Body.Bytes(), &lockouts)
+	if w.Code != http.StatusOK || len(lockouts) != 1 || lockouts[0].Key != "testuser" {
+		t.Fatalf("Expected the lockout of testuser, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := serve("POST", "/users/user-1/unlock"); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := serve("GET", "/lockouts"); w.Body.String() != "[]" {
+		t.Errorf("Expected no lockout after unlock, got %s", w.Body.String())
+	}
+	if w := serve("POST", "/users/nonexistent/unlock"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if w := serve("DELETE", "/lockouts/ips/10.0.0.1"); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestAdminHandler_Lockouts_Forbidden(t *testing.T) {
+	handler := NewAdminHandler(application.NewAuthService(newMockUserRepo(), "test-secret"))
+
+	router := gin.New()
+	router.GET("/lockouts", handler.ListLockouts)
+	router.POST("/users/:id/unlock", handler.UnlockUser)
+	router.DELETE("/lockouts/ips/:ip", handler.UnlockIP)
+
+	for _, route := range [][2]string{{"GET", "/lockouts"}, {"POST", "/users/user-1/unlock"}, {"DELETE", "/lockouts/ips/10.0.0.1"}} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(route[0], route[1], nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: expected status 403, got %d", route[0], route[1], w.Code)
+		}
+	}
+}
+
 func TestAdminHandler_RegisterRoutes(t *testing.T) {
 	repo := newMockUserRepo()
 	service := application.NewAuthService(repo, "test-secret")
@@ -532,6 +597,9 @@ func TestAdminHandler_RegisterRoutes(t *testing.T) {
 	checkRoute("GET", "/api/v1/admin/users/:id")
 	checkRoute("PUT", "/api/v1/admin/users/:id")
 	checkRoute("DELETE", "/api/v1/admin/users/:id")
+	checkRoute("POST", "/api/v1/admin/users/:id/unlock")
+	checkRoute("GET", "/api/v1/admin/lockouts")
+	checkRoute("DELETE", "/api/v1/admin/lockouts/ips/:ip")
 }
 
 func TestUserResponse_ToUserResponse(t *testing.T) {
@@ -1364,6 +1432,26 @@ func (m *errorUserRepo) DeactivateAdminIfNotLast(_ context.Context, id string) e
 	return nil
 }
 
+func (m *errorUserRepo) FindLoginThrottle(_ context.Context, _ entity.LoginThrottleScope, _ string) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *errorUserRepo) AddLoginFailure(_ context.Context, scope entity.LoginThrottleScope, key string, at, _, _ time.Time) (*entity.LoginThrottle, error) {
+	return &entity.LoginThrottle{Scope: scope, Key: key, Failures: 1, LastFailureAt: at}, nil
+}
+
+func (m *errorUserRepo) LockLogin(_ context.Context, _ entity.LoginThrottleScope, _ string, _ int, _ time.Time) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *errorUserRepo) DeleteLoginThrottle(_ context.Context, _ entity.LoginThrottleScope, _ string) error {
+	return nil
+}
+
+func (m *errorUserRepo) FindLockedLoginThrottles(_ context.Context, _ time.Time) ([]*entity.LoginThrottle, error) {
+	return nil, nil
+}
+
 // --- Tests for generic error paths in admin handler ---
 
 func TestAdminHandler_ListUsers_ServiceError(t *testing.T) {
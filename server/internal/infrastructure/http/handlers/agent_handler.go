@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"autostrike/internal/application"
 	"autostrike/internal/domain/entity"
@@ -28,14 +32,38 @@ func (h *AgentHandler) RegisterRoutes(r *gin.RouterGroup) {
 		agents.POST("", h.RegisterAgent)
 		agents.DELETE("/:paw", h.DeleteAgent)
 		agents.POST("/:paw/heartbeat", h.Heartbeat)
+		agents.PUT("/:paw/networks", h.SetAllowedNetworks)
+		agents.GET("/:paw/availability", h.GetAvailability)
+		agents.POST("/bulk/preview", h.PreviewBulk)
+		agents.POST("/bulk/apply", h.ApplyBulk)
 	}
 }
 
-// ListAgents returns agents (online only by default, use ?all=true for all)
+// ListAgents godoc
+// @Summary List agents
+// @Description List the online agents, or every agent with all=true, filtered on their inventory. Repeated filter values are alternatives, except interpreter ("powershell" or "powershell:5" for 5 or later) which must all be present.
+// @Tags agents
+// @Produce json
+// @Param all query bool false "Include offline agents"
+// @Param platform query string false "Platform (repeatable)"
+// @Param executor query string false "Executor (repeatable)"
+// @Param architecture query string false "Architecture (repeatable)"
+// @Param elevated query bool false "Running elevated"
+// @Param domain query string false "Domain (repeatable)"
+// @Param security_product query string false "Security product (repeatable)"
+// @Param interpreter query string false "Interpreter with an optional minimum version (repeatable)"
+// @Success 200 {array} entity.Agent
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents [get]
 func (h *AgentHandler) ListAgents(c *gin.Context) {
-	var agents []*entity.Agent
-	var err error
+	filter, err := agentFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
+	var agents []*entity.Agent
 	if c.Query("all") == "true" {
 		agents, err = h.service.GetAllAgents(c.Request.Context())
 	} else {
@@ -48,14 +76,56 @@ func (h *AgentHandler) ListAgents(c *gin.Context) {
 	}
 
 	// Return empty array instead of null
-	if agents == nil {
-		agents = []*entity.Agent{}
+	matches := make([]*entity.Agent, 0, len(agents))
+	for _, agent := range agents {
+		if filter.Matches(agent) {
+			matches = append(matches, agent)
+		}
 	}
 
-	c.JSON(http.StatusOK, agents)
+	c.JSON(http.StatusOK, matches)
 }
 
-// GetAgent returns a specific agent
+// agentFilterFromQuery builds the selector matching the agent list filters
+func agentFilterFromQuery(c *gin.Context) (*entity.AgentSelector, error) {
+	filter := &entity.AgentSelector{
+		Name:             "agent list", // Validate requires a name
+		Platforms:        c.QueryArray("platform"),
+		Executors:        c.QueryArray("executor"),
+		Architectures:    c.QueryArray("architecture"),
+		Domains:          c.QueryArray("domain"),
+		SecurityProducts: c.QueryArray("security_product"),
+	}
+	if value := c.Query("elevated"); value != "" {
+		elevated, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("elevated must be true or false")
+		}
+		filter.Elevated = &elevated
+	}
+	for _, value := range c.QueryArray("interpreter") {
+		if filter.Interpreters == nil {
+			filter.Interpreters = make(map[string]string)
+		}
+		name, minVersion, _ := strings.Cut(value, ":")
+		filter.Interpreters[name] = minVersion
+	}
+
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// GetAgent godoc
+// @Summary Get an agent
+// @Description Get an agent with its inventory
+// @Tags agents
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Success 200 {object} entity.Agent
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agents/{paw} [get]
 func (h *AgentHandler) GetAgent(c *gin.Context) {
 	paw := c.Param("paw")
 
@@ -68,6 +138,159 @@ func (h *AgentHandler) GetAgent(c *gin.Context) {
 	c.JSON(http.StatusOK, agent)
 }
 
+// maxAvailabilityDays bounds the period of an agent's availability history
+const maxAvailabilityDays = 90
+
+// GetAvailability godoc
+// @Summary Get the availability of an agent
+// @Description Get the times an agent went online or offline over the last days, with its uptime. Transitions of a flapping agent are recorded as suppressed, and not announced until it settles.
+// @Tags agents
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Param days query int false "Days of history, 7 by default, up to 90"
+// @Success 200 {object} entity.AgentAvailability
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents/{paw}/availability [get]
+func (h *AgentHandler) GetAvailability(c *gin.Context) {
+	days := 7
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxAvailabilityDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = n
+	}
+
+	now := time.Now()
+	availability, err := h.service.GetAvailability(c.Request.Context(), c.Param("paw"), now.AddDate(0, 0, -days), now)
+	switch {
+	case errors.Is(err, application.ErrAgentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, availability)
+	}
+}
+
+// maxHealthDays bounds the period the health of an agent is rated over, the
+// check-in history being kept 30 days
+const maxHealthDays = 30
+
+// GetHealth godoc
+// @Summary Get the beacon health of an agent
+// @Description Rate how reliably an agent checked in over the last days: adherence to its beacon interval, missed beacons, gaps, latency and uptime. An agent is degraded when it falls short of a threshold, and unknown without check-ins.
+// @Tags analytics
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Param days query int false "Days of history, 1 by default, up to 30"
+// @Success 200 {object} entity.AgentHealth
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/analytics/agents/{paw}/health [get]
+func (h *AgentHandler) GetHealth(c *gin.Context) {
+	days := 1
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxHealthDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 30"})
+			return
+		}
+		days = n
+	}
+
+	now := time.Now()
+	health, err := h.service.GetHealth(c.Request.Context(), c.Param("paw"), now.AddDate(0, 0, -days), now)
+	switch {
+	case errors.Is(err, application.ErrAgentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, health)
+	}
+}
+
+// AgentBulkRequest is a bulk operation on the agents matching a filter
+type AgentBulkRequest struct {
+	application.AgentBulkOperation
+	Checksum string `json:"checksum,omitempty"` // Checksum of the reviewed preview, for apply
+}
+
+// PreviewBulk godoc
+// @Summary Preview a bulk agent operation
+// @Description List the agents a bulk delete, deregister, tag or beacon operation would change, and the matching agents it would leave alone, with the checksum to apply it. Nothing is changed.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body AgentBulkRequest true "Bulk operation"
+// @Success 200 {object} application.AgentBulkPreview
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents/bulk/preview [post]
+func (h *AgentHandler) PreviewBulk(c *gin.Context) {
+	var req AgentBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preview, err := h.service.PreviewBulk(c.Request.Context(), &req.AgentBulkOperation, time.Now())
+	if err != nil {
+		respondAgentBulkError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// ApplyBulk godoc
+// @Summary Apply a bulk agent operation
+// @Description Preview the operation again and apply it with the checksum of the reviewed preview. Nothing is changed when the agents it would change differ from the preview.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body AgentBulkRequest true "Bulk operation and checksum of its preview"
+// @Success 200 {object} application.AgentBulkResult
+// @Success 207 {object} application.AgentBulkResult
+// @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents/bulk/apply [post]
+func (h *AgentHandler) ApplyBulk(c *gin.Context) {
+	var req AgentBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.ApplyBulk(c.Request.Context(), &req.AgentBulkOperation, req.Checksum, c.GetString("user_id"), time.Now())
+	if err != nil {
+		respondAgentBulkError(c, err)
+		return
+	}
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusMultiStatus, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// respondAgentBulkError maps the errors of a bulk agent operation to responses
+func respondAgentBulkError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidAgentBulk):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAgentBulkStale):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 // RegisterAgentRequest represents the request body for agent registration
 type RegisterAgentRequest struct {
 	Paw       string   `json:"paw" binding:"required"`
@@ -75,9 +298,20 @@ type RegisterAgentRequest struct {
 	Username  string   `json:"username" binding:"required"`
 	Platform  string   `json:"platform" binding:"required"`
 	Executors []string `json:"executors" binding:"required"`
+	entity.AgentInventory
 }
 
-// RegisterAgent registers a new agent
+// RegisterAgent godoc
+// @Summary Register an agent
+// @Description Register an agent, or update the registration of an agent with the same PAW
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param request body RegisterAgentRequest true "Agent"
+// @Success 201 {object} entity.Agent
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents [post]
 func (h *AgentHandler) RegisterAgent(c *gin.Context) {
 	var req RegisterAgentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -86,11 +320,12 @@ func (h *AgentHandler) RegisterAgent(c *gin.Context) {
 	}
 
 	agent := &entity.Agent{
-		Paw:       req.Paw,
-		Hostname:  req.Hostname,
-		Username:  req.Username,
-		Platform:  req.Platform,
-		Executors: req.Executors,
+		Paw:            req.Paw,
+		Hostname:       req.Hostname,
+		Username:       req.Username,
+		Platform:       req.Platform,
+		Executors:      req.Executors,
+		AgentInventory: req.AgentInventory,
 	}
 
 	if err := h.service.RegisterAgent(c.Request.Context(), agent); err != nil {
@@ -101,7 +336,14 @@ func (h *AgentHandler) RegisterAgent(c *gin.Context) {
 	c.JSON(http.StatusCreated, agent)
 }
 
-// DeleteAgent deletes an agent
+// DeleteAgent godoc
+// @Summary Delete an agent
+// @Description Delete an agent
+// @Tags agents
+// @Param paw path string true "Agent PAW"
+// @Success 204
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents/{paw} [delete]
 func (h *AgentHandler) DeleteAgent(c *gin.Context) {
 	paw := c.Param("paw")
 
@@ -113,7 +355,15 @@ func (h *AgentHandler) DeleteAgent(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
-// Heartbeat updates agent's last seen
+// Heartbeat godoc
+// @Summary Record an agent heartbeat
+// @Description Update the last time an agent was seen
+// @Tags agents
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents/{paw}/heartbeat [post]
 func (h *AgentHandler) Heartbeat(c *gin.Context) {
 	paw := c.Param("paw")
 
@@ -124,3 +374,41 @@ func (h *AgentHandler) Heartbeat(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
+
+// AllowedNetworksRequest represents the request body pinning the networks of an agent
+type AllowedNetworksRequest struct {
+	Networks []string `json:"networks"`
+}
+
+// SetAllowedNetworks godoc
+// @Summary Pin the networks of an agent
+// @Description Set the networks, in CIDR notation, an agent is expected to connect from. A connection or heartbeat from another address raises an agent_unexpected_network security alert. An empty list expects any network.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param paw path string true "Agent PAW"
+// @Param request body AllowedNetworksRequest true "Networks"
+// @Success 200 {object} entity.Agent
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agents/{paw}/networks [put]
+func (h *AgentHandler) SetAllowedNetworks(c *gin.Context) {
+	var req AllowedNetworksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	agent, err := h.service.SetAllowedNetworks(c.Request.Context(), c.Param("paw"), req.Networks)
+	switch {
+	case errors.Is(err, application.ErrInvalidAgentNetworks):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAgentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, agent)
+	}
+}
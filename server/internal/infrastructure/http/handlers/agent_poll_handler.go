@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultPollWait is how long a poll request waits for messages when the agent does not say
+const defaultPollWait = 25 * time.Second
+
+// OpenPollSession godoc
+// @Summary Open an agent long-poll session
+// @Description Fallback for agents that cannot hold a WebSocket. The session carries the same messages as /ws/agent: the agent posts them one per request and polls for the server's. It expires when the agent stops polling.
+// @Tags agents
+// @Produce json
+// @Success 201 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Router /ws/agent/poll [post]
+func (h *WebSocketHandler) OpenPollSession(c *gin.Context) {
+	if !h.authorizeAgent(c) {
+		return
+	}
+
+	id, _ := h.polls.Open()
+	h.logger.Info("Agent long-poll session opened", zap.String("session", id))
+	c.JSON(http.StatusCreated, gin.H{
+		"session_id": id,
+		"wait":       int(defaultPollWait.Seconds()),
+		"timeout":    int(websocket.PollSessionTimeout.Seconds()),
+	})
+}
+
+// PollMessages godoc
+// @Summary Poll the messages sent to an agent
+// @Description Wait until messages are sent to the agent of the session, or the wait elapses, and return them in order
+// @Tags agents
+// @Produce json
+// @Param session path string true "Session ID"
+// @Param wait query int false "Seconds to wait for a message (default 25, at most 30)"
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 410 {object} gin.H
+// @Router /ws/agent/poll/{session} [get]
+func (h *WebSocketHandler) PollMessages(c *gin.Context) {
+	if !h.authorizeAgent(c) {
+		return
+	}
+	client, ok := h.polls.Get(c.Param("session"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "poll session not found"})
+		return
+	}
+
+	wait := defaultPollWait
+	if seconds, err := strconv.Atoi(c.Query("wait")); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+	if wait > websocket.MaxPollWait {
+		wait = websocket.MaxPollWait
+	}
+
+	messages, open := client.Poll(c.Request.Context(), wait)
+	if !open {
+		// Dropped by the hub: the agent opens a new session and registers again
+		h.polls.Close(c.Param("session"))
+		c.JSON(http.StatusGone, gin.H{"error": "poll session closed"})
+		return
+	}
+	// Polling extends the session for as long as the request was held
+	h.polls.Get(c.Param("session"))
+	c.JSON(http.StatusOK, gin.H{"messages": messages})
+}
+
+// PostMessage godoc
+// @Summary Send a message from an agent
+// @Description Handle a message of the agent of the session (register, heartbeat, task_result, ...) as if received over its WebSocket. Replies are returned by the next poll.
+// @Tags agents
+// @Accept json
+// @Param session path string true "Session ID"
+// @Param message body websocket.Message true "Message"
+// @Success 202
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /ws/agent/poll/{session} [post]
+func (h *WebSocketHandler) PostMessage(c *gin.Context) {
+	if !h.authorizeAgent(c) {
+		return
+	}
+	client, ok := h.polls.Get(c.Param("session"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "poll session not found"})
+		return
+	}
+
+	var msg websocket.Message
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, websocket.MaxPollMessageSize)
+	if err := json.NewDecoder(body).Decode(&msg); err != nil || msg.Type == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message"})
+		return
+	}
+
+	client.SetRemoteIP(c.ClientIP())
+	h.handleMessage(client, &msg)
+	c.Status(http.StatusAccepted)
+}
+
+// ClosePollSession godoc
+// @Summary Close an agent long-poll session
+// @Description End a session; its agent is disconnected
+// @Tags agents
+// @Param session path string true "Session ID"
+// @Success 204
+// @Failure 401 {object} gin.H
+// @Router /ws/agent/poll/{session} [delete]
+func (h *WebSocketHandler) ClosePollSession(c *gin.Context) {
+	if !h.authorizeAgent(c) {
+		return
+	}
+	h.polls.Close(c.Param("session"))
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestAgentPollSession_RoundTrip(t *testing.T) {
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+	go hub.Run()
+
+	handler := NewWebSocketHandler(hub, application.NewAgentService(newWSTestAgentRepo()), logger)
+	handler.agentSecret = "secret"
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	request := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("X-Agent-Key", "secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The agent key is required, as for WebSocket connections
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/ws/agent/poll", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without the agent key, got %d", w.Code)
+	}
+
+	w = request(http.MethodPost, "/ws/agent/poll", nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &session)
+	path := "/ws/agent/poll/" + session.SessionID
+
+	register, _ := json.Marshal(map[string]interface{}{
+		"type":    "register",
+		"payload": RegisterPayload{Paw: "poll-agent", Hostname: "host", Platform: "linux"},
+	})
+	if w := request(http.MethodPost, path, register); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := request(http.MethodPost, path, []byte("not json")); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid message, got %d", w.Code)
+	}
+	if !hub.IsAgentConnected("poll-agent") {
+		t.Fatal("Expected the polling agent to be connected to the hub")
+	}
+
+	// Messages sent through the hub are returned by the next poll
+	hub.SendToAgent("poll-agent", []byte(`{"type":"task","payload":{"id":"r1"}}`))
+	w = request(http.MethodGet, path+"?wait=1", nil)
+	var polled struct {
+		Messages []websocket.Message `json:"messages"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &polled); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(polled.Messages) != 2 || polled.Messages[0].Type != "registered" || polled.Messages[1].Type != "task" {
+		t.Fatalf("Expected the registration ack and the task, got %+v", polled.Messages)
+	}
+
+	// An empty poll returns once the wait elapses
+	start := time.Now()
+	w = request(http.MethodGet, path+"?wait=0", nil)
+	if w.Code != http.StatusOK || time.Since(start) > time.Second {
+		t.Errorf("Expected an immediate empty poll, got %d", w.Code)
+	}
+
+	if w := request(http.MethodDelete, path, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", w.Code)
+	}
+	if w := request(http.MethodGet, path, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a closed session, got %d", w.Code)
+	}
+	deadline := time.Now().Add(time.Second)
+	for hub.IsAgentConnected("poll-agent") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if hub.IsAgentConnected("poll-agent") {
+		t.Error("Expected the agent to be disconnected with its session")
+	}
+}
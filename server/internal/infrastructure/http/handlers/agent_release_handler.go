@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+)
+
+// agentUpdateChunkSize is the size of the binary slices sent in update_chunk
+// messages, so a release never has to fit in a single WebSocket message
+const agentUpdateChunkSize = 512 << 10
+
+// AgentReleaseHandler manages the signed agent binaries agents update to
+type AgentReleaseHandler struct {
+	service *application.AgentUpdateService
+}
+
+// NewAgentReleaseHandler creates a new agent release handler
+func NewAgentReleaseHandler(service *application.AgentUpdateService) *AgentReleaseHandler {
+	return &AgentReleaseHandler{service: service}
+}
+
+// ListReleases godoc
+// @Summary List agent releases
+// @Description List the uploaded agent binaries and their rollout, without their content
+// @Tags agent-releases
+// @Produce json
+// @Success 200 {array} entity.AgentRelease
+// @Router /api/v1/agent-releases [get]
+func (h *AgentReleaseHandler) ListReleases(c *gin.Context) {
+	releases, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list agent releases"})
+		return
+	}
+	// Return empty array instead of null
+	if releases == nil {
+		releases = []*entity.AgentRelease{}
+	}
+	c.JSON(http.StatusOK, releases)
+}
+
+// UploadRelease godoc
+// @Summary Upload an agent release
+// @Description Upload an agent binary signed with the release key. The signature, over the lines "autostrike-agent-release", version, hex SHA-256 and size of the binary, must verify with the configured public key.
+// @Tags agent-releases
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Agent binary"
+// @Param version formData string true "Version, MAJOR.MINOR.PATCH"
+// @Param platform formData string true "windows, linux or darwin"
+// @Param signature formData string true "Base64 Ed25519 signature of the release version, SHA-256 and size"
+// @Param rollout_percent formData int false "Share of the platform's agents offered the release, 0 by default"
+// @Success 201 {object} entity.AgentRelease
+// @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Failure 422 {object} gin.H
+// @Router /api/v1/agent-releases [post]
+func (h *AgentReleaseHandler) UploadRelease(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required"})
+		return
+	}
+	if header.Size > h.service.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("agent release is larger than %d bytes", h.service.MaxSize())})
+		return
+	}
+	rollout := 0
+	if value := c.PostForm("rollout_percent"); value != "" {
+		if rollout, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rollout_percent must be an integer"})
+			return
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, h.service.MaxSize()+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+
+	release, err := h.service.Upload(c.Request.Context(), c.PostForm("version"), c.PostForm("platform"),
+		c.PostForm("signature"), rollout, content, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, release)
+}
+
+// RolloutRequest sets the rollout of an agent release
+type RolloutRequest struct {
+	RolloutPercent *int `json:"rollout_percent" binding:"required"`
+}
+
+// SetRollout godoc
+// @Summary Set the rollout of an agent release
+// @Description Set the share of the platform's agents offered the release. Agents keep their place in the rollout, so raising it only adds agents; 0 pauses the release.
+// @Tags agent-releases
+// @Accept json
+// @Produce json
+// @Param id path string true "Release ID"
+// @Param request body RolloutRequest true "Rollout"
+// @Success 200 {object} entity.AgentRelease
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agent-releases/{id}/rollout [put]
+func (h *AgentReleaseHandler) SetRollout(c *gin.Context) {
+	var req RolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rollout_percent is required"})
+		return
+	}
+
+	release, err := h.service.SetRollout(c.Request.Context(), c.Param("id"), *req.RolloutPercent)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, release)
+}
+
+// DeleteRelease godoc
+// @Summary Delete an agent release
+// @Description Delete an agent release; agents are no longer offered it
+// @Tags agent-releases
+// @Param id path string true "Release ID"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agent-releases/{id} [delete]
+func (h *AgentReleaseHandler) DeleteRelease(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps an agent update service error to a response
+func (h *AgentReleaseHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidAgentRelease):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAgentReleaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAgentReleaseExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAgentReleaseTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAgentReleaseSignature):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "agent release operation failed"})
+	}
+}
+
+// sendAgentUpdate tells an agent to update to a release: an update message
+// announcing the binary, then the binary in base64 update_chunk messages
+func sendAgentUpdate(client *websocket.Client, release *entity.AgentRelease, content []byte) error {
+	chunks := (len(content) + agentUpdateChunkSize - 1) / agentUpdateChunkSize
+	err := client.Send("update", map[string]interface{}{
+		"version":    release.Version,
+		"sha256":     release.SHA256,
+		"signature":  release.Signature,
+		"size":       release.Size,
+		"chunks":     chunks,
+		"chunk_size": agentUpdateChunkSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < chunks; i++ {
+		end := min((i+1)*agentUpdateChunkSize, len(content))
+		err := client.Send("update_chunk", map[string]interface{}{
+			"version": release.Version,
+			"index":   i,
+			"data":    base64.StdEncoding.EncodeToString(content[i*agentUpdateChunkSize : end]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
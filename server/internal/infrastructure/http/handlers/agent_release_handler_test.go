@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	gorillaws "github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// mockAgentReleaseRepo implements repository.AgentReleaseRepository for tests
+type mockAgentReleaseRepo struct {
+	releases map[string]*entity.AgentRelease
+	contents map[string][]byte
+}
+
+func newMockAgentReleaseRepo() *mockAgentReleaseRepo {
+	return &mockAgentReleaseRepo{releases: make(map[string]*entity.AgentRelease), contents: make(map[string][]byte)}
+}
+
+func (m *mockAgentReleaseRepo) Create(ctx context.Context, release *entity.AgentRelease, content []byte) error {
+	m.releases[release.ID] = release
+	m.contents[release.ID] = content
+	return nil
+}
+
+func (m *mockAgentReleaseRepo) UpdateRollout(ctx context.Context, id string, percent int) error {
+	m.releases[id].RolloutPercent = percent
+	return nil
+}
+
+func (m *mockAgentReleaseRepo) Delete(ctx context.Context, id string) error {
+	delete(m.releases, id)
+	delete(m.contents, id)
+	return nil
+}
+
+func (m *mockAgentReleaseRepo) FindByID(ctx context.Context, id string) (*entity.AgentRelease, error) {
+	if release, ok := m.releases[id]; ok {
+		copied := *release
+		return &copied, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAgentReleaseRepo) FindByVersion(ctx context.Context, version, platform string) (*entity.AgentRelease, error) {
+	for _, release := range m.releases {
+		if release.Version == version && release.Platform == platform {
+			return release, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAgentReleaseRepo) FindAll(ctx context.Context) ([]*entity.AgentRelease, error) {
+	var releases []*entity.AgentRelease
+	for _, release := range m.releases {
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
+
+func (m *mockAgentReleaseRepo) FindByPlatform(ctx context.Context, platform string) ([]*entity.AgentRelease, error) {
+	var releases []*entity.AgentRelease
+	for _, release := range m.releases {
+		if release.Platform == platform {
+			releases = append(releases, release)
+		}
+	}
+	return releases, nil
+}
+
+func (m *mockAgentReleaseRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	return m.contents[id], nil
+}
+
+// signAgentRelease signs a release over its version, checksum and size
+func signAgentRelease(key ed25519.PrivateKey, version string, content []byte) string {
+	sum := sha256.Sum256(content)
+	message := entity.AgentReleaseMessage(version, hex.EncodeToString(sum[:]), int64(len(content)))
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, message))
+}
+
+func setupAgentReleaseRouter(t *testing.T) (*gin.Engine, *application.AgentUpdateService, ed25519.PrivateKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	svc := application.NewAgentUpdateService(newMockAgentReleaseRepo(), publicKey, zap.NewNop())
+	handler := NewAgentReleaseHandler(svc)
+
+	router := gin.New()
+	api := router.Group("/api/v1/agent-releases", func(c *gin.Context) { c.Set("user_id", "admin-1") })
+	api.GET("", handler.ListReleases)
+	api.POST("", handler.UploadRelease)
+	api.PUT("/:id/rollout", handler.SetRollout)
+	api.DELETE("/:id", handler.DeleteRelease)
+	return router, svc, privateKey
+}
+
+func uploadReleaseRequest(fields map[string]string, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		_ = writer.WriteField(key, value)
+	}
+	if content != "" {
+		part, _ := writer.CreateFormFile("file", "autostrike-agent")
+		_, _ = part.Write([]byte(content))
+	}
+	_ = writer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/agent-releases", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestAgentReleaseHandler_UploadRelease(t *testing.T) {
+	router, _, key := setupAgentReleaseRouter(t)
+	content := "agent-binary"
+	signature := signAgentRelease(key, "1.2.0", []byte(content))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, uploadReleaseRequest(map[string]string{
+		"version": "1.2.0", "platform": "linux", "signature": signature, "rollout_percent": "10",
+	}, content))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var release entity.AgentRelease
+	_ = json.Unmarshal(w.Body.Bytes(), &release)
+	if release.Version != "1.2.0" || release.RolloutPercent != 10 || release.UploadedBy != "admin-1" || release.Size != int64(len(content)) {
+		t.Errorf("Unexpected release: %+v", release)
+	}
+
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		content string
+		code    int
+	}{
+		{"no file", map[string]string{"version": "1.3.0", "platform": "linux", "signature": signature}, "", http.StatusBadRequest},
+		{"invalid rollout", map[string]string{"version": "1.3.0", "platform": "linux", "signature": signature, "rollout_percent": "all"}, content, http.StatusBadRequest},
+		{"invalid version", map[string]string{"version": "next", "platform": "linux", "signature": signature}, content, http.StatusBadRequest},
+		{"bad signature", map[string]string{"version": "1.3.0", "platform": "linux", "signature": "c2ln"}, content, http.StatusUnprocessableEntity},
+		{"duplicate", map[string]string{"version": "1.2.0", "platform": "linux", "signature": signature}, content, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadReleaseRequest(tt.fields, tt.content))
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/agent-releases", nil)
+	router.ServeHTTP(w, req)
+	var releases []entity.AgentRelease
+	_ = json.Unmarshal(w.Body.Bytes(), &releases)
+	if w.Code != http.StatusOK || len(releases) != 1 {
+		t.Errorf("Expected 1 release, got %d (%s)", w.Code, w.Body.String())
+	}
+}
+
+func TestAgentReleaseHandler_SetRolloutAndDelete(t *testing.T) {
+	router, svc, key := setupAgentReleaseRouter(t)
+	release, _ := svc.Upload(context.Background(), "1.2.0", "linux",
+		signAgentRelease(key, "1.2.0", []byte("x")), 0, []byte("x"), "")
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+	}{
+		{http.MethodPut, "/api/v1/agent-releases/" + release.ID + "/rollout", `{"rollout_percent": 50}`, http.StatusOK},
+		{http.MethodPut, "/api/v1/agent-releases/" + release.ID + "/rollout", `{}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/agent-releases/" + release.ID + "/rollout", `{"rollout_percent": 150}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/agent-releases/missing/rollout", `{"rollout_percent": 50}`, http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/agent-releases/" + release.ID, "", http.StatusNoContent},
+		{http.MethodDelete, "/api/v1/agent-releases/" + release.ID, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s %s %s: expected %d, got %d (%s)", tt.method, tt.path, tt.body, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestWebSocketHandler_OffersAgentUpdate(t *testing.T) {
+	_, svc, key := setupAgentReleaseRouter(t)
+	content := []byte("agent 1.2.0")
+	_, _ = svc.Upload(context.Background(), "1.2.0", "linux",
+		signAgentRelease(key, "1.2.0", content), 100, content, "")
+
+	hub := websocket.NewHub(zap.NewNop())
+	go hub.Run()
+	handler := NewWebSocketHandler(hub, application.NewAgentService(newWSTestAgentRepo()), zap.NewNop())
+	handler.SetAgentUpdateService(svc)
+
+	router := gin.New()
+	router.GET("/ws/agent", handler.HandleAgentConnection)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := gorillaws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/agent", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect WebSocket: %v", err)
+	}
+	defer conn.Close()
+
+	register, _ := json.Marshal(map[string]interface{}{
+		"type":    "register",
+		"payload": RegisterPayload{Paw: "paw1", Hostname: "host", Platform: "linux", Executors: []string{"sh"}, Version: "1.1.0"},
+	})
+	if err := conn.WriteMessage(gorillaws.TextMessage, register); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	received := make(map[string]json.RawMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(received) < 3 {
+		var msg websocket.Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Expected the update messages, got %v (received %v)", err, received)
+		}
+		received[msg.Type] = msg.Payload
+	}
+
+	var update struct {
+		Version   string `json:"version"`
+		Chunks    int    `json:"chunks"`
+		ChunkSize int    `json:"chunk_size"`
+		Size      int64  `json:"size"`
+	}
+	_ = json.Unmarshal(received["update"], &update)
+	if update.Version != "1.2.0" || update.Chunks != 1 || update.ChunkSize != agentUpdateChunkSize || update.Size != int64(len(content)) {
+		t.Errorf("Unexpected update: %s", received["update"])
+	}
+	var chunk struct {
+		Index int    `json:"index"`
+		Data  string `json:"data"`
+	}
+	_ = json.Unmarshal(received["update_chunk"], &chunk)
+	if data, _ := base64.StdEncoding.DecodeString(chunk.Data); chunk.Index != 0 || string(data) != string(content) {
+		t.Errorf("Unexpected chunk: %s", received["update_chunk"])
+	}
+}
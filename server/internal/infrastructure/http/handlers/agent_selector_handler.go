@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AgentSelectorHandler handles saved agent selector HTTP requests
+type AgentSelectorHandler struct {
+	service *application.AgentSelectorService
+}
+
+// NewAgentSelectorHandler creates a new agent selector handler
+func NewAgentSelectorHandler(service *application.AgentSelectorService) *AgentSelectorHandler {
+	return &AgentSelectorHandler{service: service}
+}
+
+// RegisterRoutes registers agent selector routes
+func (h *AgentSelectorHandler) RegisterRoutes(r *gin.RouterGroup) {
+	selectors := r.Group("/agent-selectors")
+	{
+		selectors.GET("", h.ListSelectors)
+		selectors.GET("/:id", h.GetSelector)
+		selectors.GET("/:id/agents", h.GetMatchingAgents)
+		selectors.POST("", h.CreateSelector)
+		selectors.PUT("/:id", h.UpdateSelector)
+		selectors.DELETE("/:id", h.DeleteSelector)
+	}
+}
+
+// AgentSelectorRequest represents the request to create or update an agent selector
+type AgentSelectorRequest struct {
+	Name             string               `json:"name" binding:"required"`
+	Description      string               `json:"description"`
+	Platforms        []string             `json:"platforms"`
+	Statuses         []entity.AgentStatus `json:"statuses"`
+	Executors        []string             `json:"executors"`
+	Tags             map[string]string    `json:"tags"`
+	HostnamePattern  string               `json:"hostname_pattern"`
+	Architectures    []string             `json:"architectures"`
+	Elevated         *bool                `json:"elevated"`
+	Domains          []string             `json:"domains"`
+	SecurityProducts []string             `json:"security_products"`
+	Interpreters     map[string]string    `json:"interpreters"` // Name to minimum version, "" for any
+}
+
+func (r *AgentSelectorRequest) toEntity() *entity.AgentSelector {
+	return &entity.AgentSelector{
+		Name:             r.Name,
+		Description:      r.Description,
+		Platforms:        r.Platforms,
+		Statuses:         r.Statuses,
+		Executors:        r.Executors,
+		Tags:             r.Tags,
+		HostnamePattern:  r.HostnamePattern,
+		Architectures:    r.Architectures,
+		Elevated:         r.Elevated,
+		Domains:          r.Domains,
+		SecurityProducts: r.SecurityProducts,
+		Interpreters:     r.Interpreters,
+	}
+}
+
+// ListSelectors godoc
+// @Summary List agent selectors
+// @Description List the saved agent selectors, ordered by name
+// @Tags agent-selectors
+// @Produce json
+// @Success 200 {array} entity.AgentSelector
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agent-selectors [get]
+func (h *AgentSelectorHandler) ListSelectors(c *gin.Context) {
+	selectors, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list agent selectors"})
+		return
+	}
+
+	if selectors == nil {
+		selectors = []*entity.AgentSelector{}
+	}
+	c.JSON(http.StatusOK, selectors)
+}
+
+// GetSelector godoc
+// @Summary Get agent selector
+// @Description Get a saved agent selector
+// @Tags agent-selectors
+// @Produce json
+// @Param id path string true "Selector ID"
+// @Success 200 {object} entity.AgentSelector
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agent-selectors/{id} [get]
+func (h *AgentSelectorHandler) GetSelector(c *gin.Context) {
+	selector, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAgentSelectorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, selector)
+}
+
+// GetMatchingAgents godoc
+// @Summary Preview agent selector
+// @Description List the registered agents a saved selector currently matches, online or not
+// @Tags agent-selectors
+// @Produce json
+// @Param id path string true "Selector ID"
+// @Success 200 {array} entity.Agent
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/agent-selectors/{id}/agents [get]
+func (h *AgentSelectorHandler) GetMatchingAgents(c *gin.Context) {
+	agents, err := h.service.MatchingAgents(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAgentSelectorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, agents)
+}
+
+// CreateSelector godoc
+// @Summary Create agent selector
+// @Description Save a named agent selector reusable in execution launches and schedules
+// @Tags agent-selectors
+// @Accept json
+// @Produce json
+// @Param request body AgentSelectorRequest true "Selector name and criteria"
+// @Success 201 {object} entity.AgentSelector
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/agent-selectors [post]
+func (h *AgentSelectorHandler) CreateSelector(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	var req AgentSelectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	userIDStr, _ := userID.(string)
+	selector, err := h.service.Create(c.Request.Context(), req.toEntity(), userIDStr)
+	if err != nil {
+		respondAgentSelectorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, selector)
+}
+
+// UpdateSelector godoc
+// @Summary Update agent selector
+// @Description Replace the name and criteria of a saved agent selector; schedules using it pick up the change on their next run
+// @Tags agent-selectors
+// @Accept json
+// @Produce json
+// @Param id path string true "Selector ID"
+// @Param request body AgentSelectorRequest true "Selector name and criteria"
+// @Success 200 {object} entity.AgentSelector
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/agent-selectors/{id} [put]
+func (h *AgentSelectorHandler) UpdateSelector(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	var req AgentSelectorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	selector, err := h.service.Update(c.Request.Context(), c.Param("id"), req.toEntity())
+	if err != nil {
+		respondAgentSelectorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, selector)
+}
+
+// DeleteSelector godoc
+// @Summary Delete agent selector
+// @Description Delete a saved agent selector
+// @Tags agent-selectors
+// @Param id path string true "Selector ID"
+// @Success 204
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agent-selectors/{id} [delete]
+func (h *AgentSelectorHandler) DeleteSelector(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondAgentSelectorError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// respondAgentSelectorError maps agent selector errors to HTTP responses
+func respondAgentSelectorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrAgentSelectorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidAgentSelector):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAgentSelectorNameTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "agent selector operation failed"})
+	}
+}
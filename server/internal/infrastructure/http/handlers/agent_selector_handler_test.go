@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockAgentSelectorRepoForHandler struct {
+	selectors map[string]*entity.AgentSelector
+}
+
+func (m *mockAgentSelectorRepoForHandler) Create(ctx context.Context, selector *entity.AgentSelector) error {
+	m.selectors[selector.ID] = selector
+	return nil
+}
+
+func (m *mockAgentSelectorRepoForHandler) Update(ctx context.Context, selector *entity.AgentSelector) error {
+	m.selectors[selector.ID] = selector
+	return nil
+}
+
+func (m *mockAgentSelectorRepoForHandler) Delete(ctx context.Context, id string) error {
+	delete(m.selectors, id)
+	return nil
+}
+
+func (m *mockAgentSelectorRepoForHandler) FindByID(ctx context.Context, id string) (*entity.AgentSelector, error) {
+	selector, ok := m.selectors[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return selector, nil
+}
+
+func (m *mockAgentSelectorRepoForHandler) FindAll(ctx context.Context) ([]*entity.AgentSelector, error) {
+	var selectors []*entity.AgentSelector
+	for _, selector := range m.selectors {
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+func newTestAgentSelectorService() *application.AgentSelectorService {
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw-1"] = &entity.Agent{Paw: "paw-1", Hostname: "web-01", Platform: "linux", Status: entity.AgentOnline}
+	agentRepo.agents["paw-2"] = &entity.Agent{Paw: "paw-2", Hostname: "web-02", Platform: "linux", Status: entity.AgentOffline}
+	agentRepo.agents["paw-3"] = &entity.Agent{Paw: "paw-3", Hostname: "desk-01", Platform: "windows", Status: entity.AgentOnline}
+
+	repo := &mockAgentSelectorRepoForHandler{selectors: make(map[string]*entity.AgentSelector)}
+	repo.selectors["sel-linux"] = &entity.AgentSelector{ID: "sel-linux", Name: "Linux", Platforms: []string{"linux"}}
+	repo.selectors["sel-darwin"] = &entity.AgentSelector{ID: "sel-darwin", Name: "macOS", Platforms: []string{"darwin"}}
+	return application.NewAgentSelectorService(repo, agentRepo)
+}
+
+func setupAgentSelectorRouter(authenticated bool) *gin.Engine {
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", "test-user")
+			c.Next()
+		})
+	}
+	NewAgentSelectorHandler(newTestAgentSelectorService()).RegisterRoutes(router.Group("/api/v1"))
+	return router
+}
+
+func performSelectorRequest(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAgentSelectorHandler_List(t *testing.T) {
+	router := setupAgentSelectorRouter(true)
+
+	w := performSelectorRequest(router, http.MethodGet, "/api/v1/agent-selectors", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var selectors []entity.AgentSelector
+	_ = json.Unmarshal(w.Body.Bytes(), &selectors)
+	if len(selectors) != 2 {
+		t.Errorf("Expected 2 selectors, got %d", len(selectors))
+	}
+}
+
+func TestAgentSelectorHandler_Get(t *testing.T) {
+	router := setupAgentSelectorRouter(true)
+
+	if w := performSelectorRequest(router, http.MethodGet, "/api/v1/agent-selectors/sel-linux", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected 200, got %d", w.Code)
+	}
+	if w := performSelectorRequest(router, http.MethodGet, "/api/v1/agent-selectors/missing", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+}
+
+func TestAgentSelectorHandler_GetMatchingAgents(t *testing.T) {
+	router := setupAgentSelectorRouter(true)
+
+	w := performSelectorRequest(router, http.MethodGet, "/api/v1/agent-selectors/sel-linux/agents", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var agents []entity.Agent
+	_ = json.Unmarshal(w.Body.Bytes(), &agents)
+	if len(agents) != 2 {
+		t.Errorf("Expected both linux agents in the preview, got %d", len(agents))
+	}
+}
+
+func TestAgentSelectorHandler_Create(t *testing.T) {
+	router := setupAgentSelectorRouter(true)
+
+	w := performSelectorRequest(router, http.MethodPost, "/api/v1/agent-selectors", AgentSelectorRequest{
+		Name:            "Web servers",
+		Platforms:       []string{"linux"},
+		HostnamePattern: "web-*",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created entity.AgentSelector
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.ID == "" || created.CreatedBy != "test-user" {
+		t.Errorf("Unexpected created selector: %+v", created)
+	}
+}
+
+func TestAgentSelectorHandler_CreateErrors(t *testing.T) {
+	router := setupAgentSelectorRouter(true)
+
+	tests := []struct {
+		name     string
+		body     interface{}
+		expected int
+	}{
+		{"missing name", map[string]string{"description": "x"}, http.StatusBadRequest},
+		{"invalid pattern", AgentSelectorRequest{Name: "Bad", HostnamePattern: "web-["}, http.StatusBadRequest},
+		{"invalid status", AgentSelectorRequest{Name: "Bad", Statuses: []entity.AgentStatus{"asleep"}}, http.StatusBadRequest},
+		{"duplicate name", AgentSelectorRequest{Name: "Linux"}, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performSelectorRequest(router, http.MethodPost, "/api/v1/agent-selectors", tt.body)
+			if w.Code != tt.expected {
+				t.Errorf("Expected %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAgentSelectorHandler_UpdateAndDelete(t *testing.T) {
+	router := setupAgentSelectorRouter(true)
+
+	w := performSelectorRequest(router, http.MethodPut, "/api/v1/agent-selectors/sel-linux", AgentSelectorRequest{
+		Name:      "Linux online",
+		Platforms: []string{"linux"},
+		Statuses:  []entity.AgentStatus{entity.AgentOnline},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := performSelectorRequest(router, http.MethodPut, "/api/v1/agent-selectors/sel-linux", AgentSelectorRequest{Name: "macOS"}); w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 on rename to a taken name, got %d", w.Code)
+	}
+	if w := performSelectorRequest(router, http.MethodPut, "/api/v1/agent-selectors/missing", AgentSelectorRequest{Name: "x"}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+
+	if w := performSelectorRequest(router, http.MethodDelete, "/api/v1/agent-selectors/sel-linux", nil); w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", w.Code)
+	}
+	if w := performSelectorRequest(router, http.MethodDelete, "/api/v1/agent-selectors/sel-linux", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+}
+
+func TestAgentSelectorHandler_Unauthenticated(t *testing.T) {
+	router := setupAgentSelectorRouter(false)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/agent-selectors"},
+		{http.MethodPut, "/api/v1/agent-selectors/sel-linux"},
+		{http.MethodDelete, "/api/v1/agent-selectors/sel-linux"},
+	} {
+		w := performSelectorRequest(router, tc.method, tc.path, AgentSelectorRequest{Name: "x"})
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected 401, got %d", tc.method, tc.path, w.Code)
+		}
+	}
+}
+
+func TestExecutionHandler_StartExecution_AgentSelector(t *testing.T) {
+	svc := application.NewExecutionService(newMockResultRepo(), nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name      string
+		selectors bool
+		body      map[string]interface{}
+		expected  int
+	}{
+		{"selectors unavailable", false, map[string]interface{}{"scenario_id": "s1", "agent_selector_id": "sel-linux"}, http.StatusBadRequest},
+		{"paws and selector", true, map[string]interface{}{"scenario_id": "s1", "agent_selector_id": "sel-linux", "agent_paws": []string{"paw-1"}}, http.StatusBadRequest},
+		{"unknown selector", true, map[string]interface{}{"scenario_id": "s1", "agent_selector_id": "missing"}, http.StatusNotFound},
+		{"no online match", true, map[string]interface{}{"scenario_id": "s1", "agent_selector_id": "sel-darwin"}, http.StatusBadRequest},
+		{"neither paws nor selector", true, map[string]interface{}{"scenario_id": "s1"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewExecutionHandler(svc)
+			if tt.selectors {
+				handler.SetAgentSelectorService(newTestAgentSelectorService())
+			}
+			router := gin.New()
+			handler.RegisterRoutes(router.Group("/api/v1"))
+
+			w := performSelectorRequest(router, http.MethodPost, "/api/v1/executions", tt.body)
+			if w.Code != tt.expected {
+				t.Errorf("Expected %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
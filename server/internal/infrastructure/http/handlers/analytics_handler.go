@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"autostrike/internal/application"
@@ -32,6 +34,8 @@ func (h *AnalyticsHandler) RegisterRoutes(router *gin.RouterGroup) {
 		analytics.GET("/trend", h.GetScoreTrend)
 		analytics.GET("/summary", h.GetExecutionSummary)
 		analytics.GET("/period", h.GetPeriodStats)
+		analytics.GET("/sigma-coverage", h.GetSigmaCoverage)
+		analytics.GET("/calendar", h.GetExecutionCalendar)
 	}
 }
 
@@ -190,3 +194,243 @@ func (h *AnalyticsHandler) GetPeriodStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, stats)
 }
+
+// GetSigmaCoverage godoc
+// @Summary Get Sigma rule coverage
+// @Description Report which executed techniques had their mapped Sigma rules fire, aggregated per tactic
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param days query int false "Number of days to analyze (default: 30)"
+// @Success 200 {object} application.SigmaCoverageReport
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/analytics/sigma-coverage [get]
+func (h *AnalyticsHandler) GetSigmaCoverage(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errAnalyticsNotAuthenticated})
+		return
+	}
+
+	days := 30 // Default to 30 days
+	if daysParam := c.Query("days"); daysParam != "" {
+		if d, err := strconv.Atoi(daysParam); err == nil && d > 0 && d <= 365 {
+			days = d
+		}
+	}
+
+	report, err := h.analyticsService.GetSigmaCoverage(c.Request.Context(), days)
+	if err != nil {
+		if errors.Is(err, application.ErrTechniqueCatalogUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get sigma coverage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTechniqueStats godoc
+// @Summary Get technique run statistics
+// @Description Aggregate the past runs of a technique, overall and per agent platform: success, blocked, detection and error rates, median duration, flakiness and last failure. Helps prune unreliable techniques.
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Technique ID"
+// @Param days query int false "Number of days to analyze (default: 90, max: 365)"
+// @Success 200 {object} application.TechniqueStats
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/analytics/techniques/{id}/stats [get]
+func (h *AnalyticsHandler) GetTechniqueStats(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errAnalyticsNotAuthenticated})
+		return
+	}
+
+	days := 90 // Default to 90 days
+	if daysParam := c.Query("days"); daysParam != "" {
+		if d, err := strconv.Atoi(daysParam); err == nil && d > 0 && d <= 365 {
+			days = d
+		}
+	}
+
+	stats, err := h.analyticsService.GetTechniqueStats(c.Request.Context(), c.Param("id"), days)
+	if err != nil {
+		if errors.Is(err, application.ErrTechniqueNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "technique not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get technique statistics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetScoreTimeline godoc
+// @Summary Get score timeline
+// @Description Get the overall and per tactic security score of each execution completed over a period, oldest first, for one scenario or all of them
+// @Tags analytics
+// @Produce json
+// @Param scenario_id query string false "Scenario ID, all scenarios when omitted"
+// @Param days query int false "Number of days to analyze (default: 90, max: 365)"
+// @Success 200 {object} application.ScoreTimeline
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/analytics/scores/timeline [get]
+func (h *AnalyticsHandler) GetScoreTimeline(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errAnalyticsNotAuthenticated})
+		return
+	}
+
+	days := 90 // Default to 90 days
+	if daysParam := c.Query("days"); daysParam != "" {
+		if d, err := strconv.Atoi(daysParam); err == nil && d > 0 && d <= 365 {
+			days = d
+		}
+	}
+
+	timeline, err := h.analyticsService.GetScoreTimeline(c.Request.Context(), c.Query("scenario_id"), days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get score timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// GetExecutionRegressions godoc
+// @Summary Get detection regressions of an execution
+// @Description Compare an execution with the previous completed run of its scenario and list the techniques the defenses handled worse on an agent, e.g. blocked before and successful now
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {array} entity.DetectionRegression
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/analytics/executions/{id}/regressions [get]
+func (h *AnalyticsHandler) GetExecutionRegressions(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errAnalyticsNotAuthenticated})
+		return
+	}
+
+	regressions, err := h.analyticsService.GetExecutionRegressions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, application.ErrExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to detect regressions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regressions)
+}
+
+// CompareExecutions godoc
+// @Summary Compare two executions
+// @Description Diff a run of a scenario against a baseline run of the same scenario, e.g. before and after a change of security controls: techniques that changed status, score delta overall and per tactic, agents added or removed
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Baseline execution ID"
+// @Param other path string true "Compared execution ID"
+// @Success 200 {object} entity.ExecutionDiff
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/executions/{id}/compare/{other} [get]
+func (h *AnalyticsHandler) CompareExecutions(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errAnalyticsNotAuthenticated})
+		return
+	}
+
+	diff, err := h.analyticsService.CompareExecutions(c.Request.Context(), c.Param("id"), c.Param("other"))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrExecutionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		case errors.Is(err, application.ErrExecutionsNotComparable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compare executions"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// GetExecutionCalendar godoc
+// @Summary Get execution calendar
+// @Description Get the number of executions and the average score per day, for a calendar heatmap
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param start query string false "First day (YYYY-MM-DD, default: 364 days before end)"
+// @Param end query string false "Last day (YYYY-MM-DD, default: today)"
+// @Param scenario_id query string false "Comma-separated scenario IDs to include"
+// @Success 200 {object} application.ExecutionCalendar
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/analytics/calendar [get]
+func (h *AnalyticsHandler) GetExecutionCalendar(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errAnalyticsNotAuthenticated})
+		return
+	}
+
+	end := time.Now().UTC()
+	if endStr := c.Query("end"); endStr != "" {
+		parsed, err := time.Parse(time.DateOnly, endStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid end date format, expected YYYY-MM-DD"})
+			return
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 0, -364)
+	if startStr := c.Query("start"); startStr != "" {
+		parsed, err := time.Parse(time.DateOnly, startStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid start date format, expected YYYY-MM-DD"})
+			return
+		}
+		start = parsed
+	}
+
+	var scenarioIDs []string
+	for _, id := range strings.Split(c.Query("scenario_id"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			scenarioIDs = append(scenarioIDs, id)
+		}
+	}
+
+	calendar, err := h.analyticsService.GetExecutionCalendar(c.Request.Context(), start, end, scenarioIDs)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidCalendarRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get execution calendar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
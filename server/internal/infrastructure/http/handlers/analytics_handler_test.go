@@ -43,10 +43,12 @@ func TestAnalyticsHandler_RegisterRoutes(t *testing.T) {
 
 	routes := router.Routes()
 	expectedPaths := map[string]string{
-		"/api/v1/analytics/compare": "GET",
-		"/api/v1/analytics/trend":   "GET",
-		"/api/v1/analytics/summary": "GET",
-		"/api/v1/analytics/period":  "GET",
+		"/api/v1/analytics/compare":        "GET",
+		"/api/v1/analytics/trend":          "GET",
+		"/api/v1/analytics/summary":        "GET",
+		"/api/v1/analytics/period":         "GET",
+		"/api/v1/analytics/sigma-coverage": "GET",
+		"/api/v1/analytics/calendar":       "GET",
 	}
 
 	for path, method := range expectedPaths {
@@ -413,6 +415,16 @@ func (m *mockResultRepoForHandler) FindCompletedExecutionsByDateRange(ctx contex
 	return results, nil
 }
 
+func (m *mockResultRepoForHandler) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	var results []*entity.Execution
+	for _, e := range m.executions {
+		if e.Status == status {
+			results = append(results, e)
+		}
+	}
+	return results, nil
+}
+
 func (m *mockResultRepoForHandler) CreateResult(ctx context.Context, result *entity.ExecutionResult) error {
 	return nil
 }
@@ -647,6 +659,9 @@ func (m *mockErrorResultRepoForHandler) FindExecutionsByDateRange(ctx context.Co
 func (m *mockErrorResultRepoForHandler) FindCompletedExecutionsByDateRange(ctx context.Context, start, end time.Time) ([]*entity.Execution, error) {
 	return nil, m.err
 }
+func (m *mockErrorResultRepoForHandler) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	return nil, m.err
+}
 func (m *mockErrorResultRepoForHandler) CreateResult(ctx context.Context, result *entity.ExecutionResult) error {
 	return m.err
 }
@@ -1039,3 +1054,380 @@ func TestAnalyticsHandler_GetExecutionSummary_ServiceErrorWithCustomDays(t *test
 		t.Errorf("Expected status 500, got %d", w.Code)
 	}
 }
+
+// --- Sigma coverage tests ---
+
+func TestAnalyticsHandler_GetSigmaCoverage(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", StartedAt: time.Now().Add(-time.Hour)}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", TechniqueID: "T1059.001", Detected: true, DetectedBy: "elastic: Suspicious PowerShell Invocation"},
+		{ID: "r2", TechniqueID: "T1082"},
+	}
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1059.001"] = &entity.Technique{
+		ID: "T1059.001", Name: "PowerShell", Tactic: entity.TacticExecution,
+		SigmaRules: []entity.SigmaRule{{ID: "rule-1", Title: "Suspicious PowerShell Invocation"}, {ID: "rule-2"}},
+	}
+
+	svc := application.NewAnalyticsService(resultRepo)
+	svc.SetTechniqueRepository(techniqueRepo)
+	handler := NewAnalyticsHandler(svc)
+
+	router := gin.New()
+	router.GET("/sigma-coverage", withAuthAnalytics(handler.GetSigmaCoverage))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/sigma-coverage?days=7", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response application.SigmaCoverageReport
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Period != "7d" {
+		t.Errorf("Period = %q, want 7d", response.Period)
+	}
+	if response.TotalRules != 2 || response.FiredRules != 1 || response.Coverage != 50 {
+		t.Errorf("Unexpected totals: rules=%d fired=%d coverage=%v", response.TotalRules, response.FiredRules, response.Coverage)
+	}
+	if len(response.UnmappedTechniques) != 1 || response.UnmappedTechniques[0] != "T1082" {
+		t.Errorf("UnmappedTechniques = %v, want [T1082]", response.UnmappedTechniques)
+	}
+}
+
+func TestAnalyticsHandler_GetSigmaCoverage_NoCatalog(t *testing.T) {
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(&mockResultRepoForHandler{}))
+
+	router := gin.New()
+	router.GET("/sigma-coverage", withAuthAnalytics(handler.GetSigmaCoverage))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/sigma-coverage", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+}
+
+func TestAnalyticsHandler_GetSigmaCoverage_ServiceError(t *testing.T) {
+	svc := application.NewAnalyticsService(&mockErrorResultRepoForHandler{err: errors.New("database connection failed")})
+	svc.SetTechniqueRepository(newMockTechniqueRepo())
+	handler := NewAnalyticsHandler(svc)
+
+	router := gin.New()
+	router.GET("/sigma-coverage", withAuthAnalytics(handler.GetSigmaCoverage))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/sigma-coverage", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestAnalyticsHandler_GetSigmaCoverage_Unauthenticated(t *testing.T) {
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(&mockResultRepoForHandler{}))
+
+	router := gin.New()
+	router.GET("/sigma-coverage", handler.GetSigmaCoverage)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/sigma-coverage", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+// --- Technique statistics tests ---
+
+func TestAnalyticsHandler_GetTechniqueStats(t *testing.T) {
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery"}
+	svc := application.NewAnalyticsService(newMockResultRepo())
+	svc.SetTechniqueRepository(techniqueRepo)
+	handler := NewAnalyticsHandler(svc)
+
+	router := gin.New()
+	router.GET("/techniques/:id/stats", withAuthAnalytics(handler.GetTechniqueStats))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/techniques/T1082/stats?days=7", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response application.TechniqueStats
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.TechniqueID != "T1082" || response.Days != 7 || response.Overall.Runs != 0 {
+		t.Errorf("Unexpected stats: %+v", response)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/techniques/T9999/stats", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown technique, got %d", w.Code)
+	}
+}
+
+func TestAnalyticsHandler_GetTechniqueStats_Errors(t *testing.T) {
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(&mockErrorResultRepoForHandler{err: errors.New("database connection failed")}))
+
+	router := gin.New()
+	router.GET("/auth/techniques/:id/stats", withAuthAnalytics(handler.GetTechniqueStats))
+	router.GET("/anon/techniques/:id/stats", handler.GetTechniqueStats)
+
+	for path, want := range map[string]int{
+		"/auth/techniques/T1082/stats": http.StatusInternalServerError,
+		"/anon/techniques/T1082/stats": http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+// --- Score timeline and regression tests ---
+
+func TestAnalyticsHandler_GetScoreTimeline(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", ScenarioID: "s1", Status: entity.ExecutionCompleted,
+		StartedAt: time.Now().Add(-time.Hour), Score: &entity.SecurityScore{Overall: 80}}
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(resultRepo))
+
+	router := gin.New()
+	router.GET("/scores/timeline", withAuthAnalytics(handler.GetScoreTimeline))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/scores/timeline?days=30", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response application.ScoreTimeline
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Days != 30 || len(response.Points) != 1 || response.Points[0].Overall != 80 {
+		t.Errorf("Unexpected timeline: %+v", response)
+	}
+}
+
+func TestAnalyticsHandler_GetExecutionRegressions(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", ScenarioID: "s1", Status: entity.ExecutionCompleted}
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(resultRepo))
+
+	router := gin.New()
+	router.GET("/executions/:id/regressions", withAuthAnalytics(handler.GetExecutionRegressions))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/executions/exec-1/regressions", nil)
+	router.ServeHTTP(w, req)
+	var regressions []entity.DetectionRegression
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &regressions) != nil || regressions == nil || len(regressions) != 0 {
+		t.Errorf("Expected an empty list, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/executions/missing/regressions", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown execution, got %d", w.Code)
+	}
+}
+
+func TestAnalyticsHandler_CompareExecutions(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", ScenarioID: "s1", Status: entity.ExecutionCompleted}
+	resultRepo.executions["exec-2"] = &entity.Execution{ID: "exec-2", ScenarioID: "s1", Status: entity.ExecutionCompleted}
+	resultRepo.executions["exec-3"] = &entity.Execution{ID: "exec-3", ScenarioID: "s2", Status: entity.ExecutionCompleted}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{{ID: "r1", TechniqueID: "T1059", AgentPaw: "paw-1", Status: entity.StatusSuccess}}
+	resultRepo.results["exec-2"] = []*entity.ExecutionResult{{ID: "r2", TechniqueID: "T1059", AgentPaw: "paw-1", Status: entity.StatusBlocked}}
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(resultRepo))
+
+	router := gin.New()
+	router.GET("/executions/:id/compare/:other", withAuthAnalytics(handler.CompareExecutions))
+	router.GET("/anon/executions/:id/compare/:other", handler.CompareExecutions)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/executions/exec-1/compare/exec-2", nil)
+	router.ServeHTTP(w, req)
+	var diff entity.ExecutionDiff
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &diff) != nil {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if diff.ScoreDelta != 100 || len(diff.Techniques) != 1 || diff.Techniques[0].Change != entity.ChangeImproved {
+		t.Errorf("Unexpected diff: %+v", diff)
+	}
+
+	for path, want := range map[string]int{
+		"/executions/exec-1/compare/missing":     http.StatusNotFound,
+		"/executions/exec-1/compare/exec-3":      http.StatusBadRequest,
+		"/anon/executions/exec-1/compare/exec-2": http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+func TestAnalyticsHandler_ScoreTimelineAndRegressions_Errors(t *testing.T) {
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(&mockErrorResultRepoForHandler{err: errors.New("database connection failed")}))
+
+	router := gin.New()
+	router.GET("/auth/scores/timeline", withAuthAnalytics(handler.GetScoreTimeline))
+	router.GET("/anon/scores/timeline", handler.GetScoreTimeline)
+	router.GET("/anon/executions/:id/regressions", handler.GetExecutionRegressions)
+
+	for path, want := range map[string]int{
+		"/auth/scores/timeline":               http.StatusInternalServerError,
+		"/anon/scores/timeline":               http.StatusUnauthorized,
+		"/anon/executions/exec-1/regressions": http.StatusUnauthorized,
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("%s: expected status %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
+// --- Execution calendar tests ---
+
+func TestAnalyticsHandler_GetExecutionCalendar(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	score := &entity.SecurityScore{Overall: 75}
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", ScenarioID: "s1", StartedAt: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), Score: score}
+	resultRepo.executions["exec-2"] = &entity.Execution{ID: "exec-2", ScenarioID: "s2", StartedAt: time.Date(2024, 3, 2, 11, 0, 0, 0, time.UTC)}
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(resultRepo))
+
+	router := gin.New()
+	router.GET("/calendar", withAuthAnalytics(handler.GetExecutionCalendar))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/calendar?start=2024-03-01&end=2024-03-03&scenario_id=s1,%20s3", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response application.ExecutionCalendar
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Days) != 3 || len(response.ScenarioIDs) != 2 || response.ScenarioIDs[1] != "s3" {
+		t.Fatalf("Unexpected calendar: %+v", response)
+	}
+	day := response.Days[1]
+	if day.Date != "2024-03-02" || day.ExecutionCount != 1 || day.AverageScore == nil || *day.AverageScore != 75 {
+		t.Errorf("Unexpected day: %+v", day)
+	}
+}
+
+func TestAnalyticsHandler_GetExecutionCalendar_DefaultRange(t *testing.T) {
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(&mockResultRepoForHandler{}))
+
+	router := gin.New()
+	router.GET("/calendar", withAuthAnalytics(handler.GetExecutionCalendar))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/calendar", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response application.ExecutionCalendar
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Days) != 365 {
+		t.Errorf("Expected 365 days, got %d", len(response.Days))
+	}
+	if response.EndDate != time.Now().UTC().Format("2006-01-02") {
+		t.Errorf("Expected the calendar to end today, got %s", response.EndDate)
+	}
+}
+
+func TestAnalyticsHandler_GetExecutionCalendar_BadRequest(t *testing.T) {
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(&mockResultRepoForHandler{}))
+
+	router := gin.New()
+	router.GET("/calendar", withAuthAnalytics(handler.GetExecutionCalendar))
+
+	tests := []struct {
+		name  string
+		query url.Values
+	}{
+		{"invalid start", url.Values{"start": {"2024-13-01"}}},
+		{"invalid end", url.Values{"end": {"yesterday"}}},
+		{"inverted range", url.Values{"start": {"2024-03-02"}, "end": {"2024-03-01"}}},
+		{"range too long", url.Values{"start": {"2022-01-01"}, "end": {"2024-01-01"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/calendar?"+tt.query.Encode(), nil)
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestAnalyticsHandler_GetExecutionCalendar_ServiceError(t *testing.T) {
+	repo := &mockErrorResultRepoForHandler{err: errors.New("database connection failed")}
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(repo))
+
+	router := gin.New()
+	router.GET("/calendar", withAuthAnalytics(handler.GetExecutionCalendar))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/calendar", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestAnalyticsHandler_GetExecutionCalendar_Unauthenticated(t *testing.T) {
+	handler := NewAnalyticsHandler(application.NewAnalyticsService(&mockResultRepoForHandler{}))
+
+	router := gin.New()
+	router.GET("/calendar", handler.GetExecutionCalendar)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/calendar", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler serves the API keys of the current user
+type APIKeyHandler struct {
+	service *application.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(service *application.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// CreateAPIKeyRequest names a new API key and its lifetime
+type CreateAPIKeyRequest struct {
+	Name          string `json:"name" binding:"required"`
+	ExpiresInDays int    `json:"expires_in_days"` // 0 for a key that never expires
+}
+
+// CreatedAPIKey is a new API key with its secret, returned only once
+type CreatedAPIKey struct {
+	*entity.APIKey
+	Key string `json:"key"`
+}
+
+// ListAPIKeys godoc
+// @Summary List my API keys
+// @Description List the API keys of the current user, newest first, without their secret
+// @Tags auth
+// @Produce json
+// @Success 200 {array} entity.APIKey
+// @Router /api/v1/auth/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list API keys"})
+		return
+	}
+
+	if keys == nil {
+		keys = []*entity.APIKey{}
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key acting with the role of the current user, sent as X-API-Key or as a Bearer token. The key is returned once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "API key"
+// @Success 201 {object} CreatedAPIKey
+// @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/auth/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ExpiresInDays < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be positive"})
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	key, secret, err := h.service.Create(c.Request.Context(), c.GetString("user_id"), req.Name, ttl)
+	switch {
+	case errors.Is(err, application.ErrInvalidAPIKey):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrAPIKeyLimit):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API key"})
+	default:
+		c.JSON(http.StatusCreated, CreatedAPIKey{APIKey: key, Key: secret})
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Delete an API key of the current user; administrators can revoke any key
+// @Tags auth
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Router /api/v1/auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	admin := c.GetString("role") == string(entity.RoleAdmin)
+	err := h.service.Revoke(c.Request.Context(), c.Param("id"), c.GetString("user_id"), admin)
+	switch {
+	case errors.Is(err, application.ErrAPIKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke API key"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
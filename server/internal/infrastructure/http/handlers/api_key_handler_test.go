@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockAPIKeyRepo implements repository.APIKeyRepository for tests
+type mockAPIKeyRepo struct {
+	keys map[string]*entity.APIKey
+}
+
+func (m *mockAPIKeyRepo) Create(ctx context.Context, key *entity.APIKey) error {
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *mockAPIKeyRepo) FindByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	if key, ok := m.keys[id]; ok {
+		return key, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAPIKeyRepo) FindByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockAPIKeyRepo) FindByUser(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+	for _, key := range m.keys {
+		if key.UserID == userID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockAPIKeyRepo) Delete(ctx context.Context, id string) error {
+	delete(m.keys, id)
+	return nil
+}
+
+func (m *mockAPIKeyRepo) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	return nil
+}
+
+func setupAPIKeyRouter(userID, role string) (*gin.Engine, *mockAPIKeyRepo) {
+	repo := &mockAPIKeyRepo{keys: make(map[string]*entity.APIKey)}
+	handler := NewAPIKeyHandler(application.NewAPIKeyService(repo, newMockUserRepo()))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		c.Next()
+	})
+	router.GET("/api/v1/auth/api-keys", handler.ListAPIKeys)
+	router.POST("/api/v1/auth/api-keys", handler.CreateAPIKey)
+	router.DELETE("/api/v1/auth/api-keys/:id", handler.RevokeAPIKey)
+	return router, repo
+}
+
+func TestAPIKeyHandler_CreateAndList(t *testing.T) {
+	router, repo := setupAPIKeyRouter("operator", "operator")
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"created", `{"name":"ci","expires_in_days":30}`, http.StatusCreated},
+		{"no name", `{}`, http.StatusBadRequest},
+		{"blank name", `{"name":"  "}`, http.StatusBadRequest},
+		{"negative expiry", `{"name":"ci","expires_in_days":-1}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/api-keys", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusCreated {
+				var created CreatedAPIKey
+				if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || !strings.HasPrefix(created.Key, entity.APIKeyPrefix) ||
+					created.APIKey == nil || created.ExpiresAt == nil || strings.Contains(w.Body.String(), "key_hash") {
+					t.Errorf("Unexpected key: %s", w.Body.String())
+				}
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/api-keys", nil))
+	var keys []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &keys); err != nil || len(keys) != 1 || keys[0]["key"] != nil || keys[0]["key_hash"] != nil {
+		t.Errorf("Expected the key listed without secret, got %s", w.Body.String())
+	}
+	if len(repo.keys) != 1 {
+		t.Errorf("Expected one key stored, got %d", len(repo.keys))
+	}
+}
+
+func TestAPIKeyHandler_Revoke(t *testing.T) {
+	router, repo := setupAPIKeyRouter("operator", "operator")
+	repo.keys["mine"] = &entity.APIKey{ID: "mine", UserID: "operator"}
+	repo.keys["theirs"] = &entity.APIKey{ID: "theirs", UserID: "analyst"}
+
+	for id, status := range map[string]int{"theirs": http.StatusNotFound, "missing": http.StatusNotFound, "mine": http.StatusNoContent} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/auth/api-keys/"+id, nil))
+		if w.Code != status {
+			t.Errorf("Expected status %d revoking %s, got %d", status, id, w.Code)
+		}
+	}
+	if _, ok := repo.keys["mine"]; ok || repo.keys["theirs"] == nil {
+		t.Errorf("Expected only the own key revoked, got %v", repo.keys)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ArtifactHandler serves the files agents collected with results
+type ArtifactHandler struct {
+	service *application.ArtifactService
+}
+
+// NewArtifactHandler creates a new artifact handler
+func NewArtifactHandler(service *application.ArtifactService) *ArtifactHandler {
+	return &ArtifactHandler{service: service}
+}
+
+// ListArtifacts godoc
+// @Summary List the artifacts of a result
+// @Description List the files the agent collected while running the technique, oldest first, without their content
+// @Tags results
+// @Produce json
+// @Param id path string true "Result ID"
+// @Success 200 {array} entity.Artifact
+// @Router /api/v1/results/{id}/artifacts [get]
+func (h *ArtifactHandler) ListArtifacts(c *gin.Context) {
+	artifacts, err := h.service.ListByResult(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list artifacts"})
+		return
+	}
+
+	// Return empty array instead of null
+	if artifacts == nil {
+		artifacts = []*entity.Artifact{}
+	}
+	c.JSON(http.StatusOK, artifacts)
+}
+
+// DownloadArtifact godoc
+// @Summary Download an artifact
+// @Description Download a file the agent collected for a result
+// @Tags results
+// @Produce octet-stream
+// @Param id path string true "Result ID"
+// @Param artifactId path string true "Artifact ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} gin.H
+// @Router /api/v1/results/{id}/artifacts/{artifactId} [get]
+func (h *ArtifactHandler) DownloadArtifact(c *gin.Context) {
+	artifact, content, err := h.service.Get(c.Request.Context(), c.Param("id"), c.Param("artifactId"))
+	if err != nil {
+		if errors.Is(err, application.ErrArtifactNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load artifact"})
+		return
+	}
+
+	// Always an attachment: collected files are untrusted content
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", artifact.Name))
+	c.Header("X-Artifact-SHA256", artifact.SHA256)
+	c.Data(http.StatusOK, "application/octet-stream", content)
+}
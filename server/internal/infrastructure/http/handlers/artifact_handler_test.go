@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// mockArtifactRepo implements repository.ArtifactRepository for tests
+type mockArtifactRepo struct {
+	artifacts []*entity.Artifact
+	contents  map[string][]byte
+}
+
+func (m *mockArtifactRepo) Create(ctx context.Context, artifact *entity.Artifact, content []byte) error {
+	m.artifacts = append(m.artifacts, artifact)
+	m.contents[artifact.ID] = content
+	return nil
+}
+
+func (m *mockArtifactRepo) FindByID(ctx context.Context, id string) (*entity.Artifact, error) {
+	for _, artifact := range m.artifacts {
+		if artifact.ID == id {
+			return artifact, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockArtifactRepo) FindByResult(ctx context.Context, resultID string) ([]*entity.Artifact, error) {
+	var artifacts []*entity.Artifact
+	for _, artifact := range m.artifacts {
+		if artifact.ResultID == resultID {
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	return artifacts, nil
+}
+
+func (m *mockArtifactRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	return m.contents[id], nil
+}
+
+func (m *mockArtifactRepo) TotalSizeByResult(ctx context.Context, resultID string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockArtifactRepo) StorageKeysCreatedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockArtifactRepo) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func setupArtifactRouter() (*gin.Engine, *application.ArtifactService) {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{{ID: "result-1", ExecutionID: "exec-1", AgentPaw: "paw1"}}
+	repo := &mockArtifactRepo{contents: make(map[string][]byte)}
+	svc := application.NewArtifactService(repo, resultRepo, application.DefaultArtifactConfig(), nil)
+	handler := NewArtifactHandler(svc)
+
+	router := gin.New()
+	router.GET("/api/v1/results/:id/artifacts", handler.ListArtifacts)
+	router.GET("/api/v1/results/:id/artifacts/:artifactId", handler.DownloadArtifact)
+	return router, svc
+}
+
+func TestArtifactHandler_ListArtifacts(t *testing.T) {
+	router, svc := setupArtifactRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/results/result-1/artifacts", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "[]" {
+		t.Fatalf("Expected an empty array, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	_, _ = svc.Store(context.Background(), "paw1", "result-1", "/etc/hostname", []byte("web-01"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var artifacts []entity.Artifact
+	_ = json.Unmarshal(w.Body.Bytes(), &artifacts)
+	if len(artifacts) != 1 || artifacts[0].Name != "hostname" || artifacts[0].Size != 6 {
+		t.Errorf("Unexpected artifacts: %s", w.Body.String())
+	}
+}
+
+func TestArtifactHandler_DownloadArtifact(t *testing.T) {
+	router, svc := setupArtifactRouter()
+	stored, err := svc.Store(context.Background(), "paw1", "result-1", "/etc/hostname", []byte("web-01"))
+	if err != nil {
+		t.Fatalf("Store failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/results/result-1/artifacts/"+stored.ID, nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "web-01" {
+		t.Fatalf("Expected the artifact content, got %d (%s)", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Artifact-SHA256") != stored.SHA256 ||
+		w.Header().Get("Content-Disposition") != `attachment; filename="hostname"` {
+		t.Errorf("Unexpected headers: %v", w.Header())
+	}
+
+	for _, path := range []string{
+		"/api/v1/results/result-2/artifacts/" + stored.ID,
+		"/api/v1/results/result-1/artifacts/missing",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestWebSocketHandler_HandleTaskArtifact(t *testing.T) {
+	_, svc := setupArtifactRouter()
+	hub := websocket.NewHub(zap.NewNop())
+	handler := NewWebSocketHandler(hub, application.NewAgentService(newWSTestAgentRepo()), zap.NewNop())
+	client := websocket.NewClient(hub, nil, "test-agent", zap.NewNop())
+	client.SetAgentPaw("paw1")
+
+	payload, _ := json.Marshal(TaskArtifactPayload{TaskID: "result-1", Path: "/tmp/out.txt", Data: []byte("output")})
+
+	// Without an artifact service the upload is rejected
+	handler.handleTaskArtifact(client, payload)
+	handler.handleTaskArtifact(client, json.RawMessage(`invalid`))
+
+	handler.SetArtifactService(svc)
+	handler.handleTaskArtifact(client, payload)
+	artifacts, _ := svc.ListByResult(context.Background(), "result-1")
+	if len(artifacts) != 1 || artifacts[0].Name != "out.txt" || artifacts[0].AgentPaw != "paw1" {
+		t.Errorf("Expected the uploaded artifact, got %+v", artifacts)
+	}
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,22 +14,35 @@ import (
 	"autostrike/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
 	service        *application.AuthService
 	tokenBlacklist *application.TokenBlacklist
+	activity       *application.ActivityMonitor
+	logger         *zap.Logger
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(service *application.AuthService) *AuthHandler {
-	return &AuthHandler{service: service}
+	return &AuthHandler{service: service, logger: zap.NewNop()}
 }
 
 // NewAuthHandlerWithBlacklist creates a new auth handler with token revocation support
 func NewAuthHandlerWithBlacklist(service *application.AuthService, blacklist *application.TokenBlacklist) *AuthHandler {
-	return &AuthHandler{service: service, tokenBlacklist: blacklist}
+	return &AuthHandler{service: service, tokenBlacklist: blacklist, logger: zap.NewNop()}
+}
+
+// SetLogger logs the password reset requests that failed in the background
+func (h *AuthHandler) SetLogger(logger *zap.Logger) {
+	h.logger = logger
+}
+
+// SetActivityMonitor enables login anomaly detection
+func (h *AuthHandler) SetActivityMonitor(monitor *application.ActivityMonitor) {
+	h.activity = monitor
 }
 
 // RegisterRoutes registers public auth routes (no auth middleware)
@@ -48,6 +63,16 @@ func (h *AuthHandler) RegisterRoutesWithRateLimit(r *gin.Engine, loginLimiter, r
 	}
 }
 
+// RegisterPasswordResetRoutes registers the public self-service password
+// reset routes, sharing a rate limiter
+func (h *AuthHandler) RegisterPasswordResetRoutes(r *gin.Engine, limiter *middleware.RateLimiter) {
+	auth := r.Group("/api/v1/auth")
+	{
+		auth.POST("/forgot-password", middleware.RateLimitMiddleware(limiter), h.ForgotPassword)
+		auth.POST("/reset-password", middleware.RateLimitMiddleware(limiter), h.ResetPassword)
+	}
+}
+
 // RegisterProtectedRoutes registers routes that require authentication
 func (h *AuthHandler) RegisterProtectedRoutes(r *gin.RouterGroup) {
 	auth := r.Group("/auth")
@@ -71,7 +96,18 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required,max=72"`
 }
 
-// Login handles user login
+// Login godoc
+// @Summary Log in
+// @Description Exchange a username and password for an access token and a refresh token. Repeated failures lock out the username and the source IP for a while, doubling with each lockout: logins then fail with 429 and Retry-After, even with the right password.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body LoginRequest true "Credentials"
+// @Success 200 {object} application.TokenResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if c.ShouldBindJSON(&req) != nil {
@@ -79,16 +115,34 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.service.Login(c.Request.Context(), req.Username, req.Password)
+	tokens, err := h.service.LoginFrom(c.Request.Context(), req.Username, req.Password, c.ClientIP())
 	if err != nil {
 		if errors.Is(err, application.ErrInvalidCredentials) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or password"})
 			return
 		}
+		var locked *application.LoginLockedError
+		if errors.As(err, &locked) {
+			retryAfter := int(time.Until(locked.Until).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "too many failed logins, try again later",
+				"retry_after": retryAfter,
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "authentication failed"})
 		return
 	}
 
+	if h.activity != nil {
+		var country string
+		if header := h.activity.CountryHeader(); header != "" {
+			country = c.GetHeader(header)
+		}
+		h.activity.RecordLogin(c.Request.Context(), req.Username, c.ClientIP(), country, c.Request.UserAgent())
+	}
+
 	c.JSON(http.StatusOK, tokens)
 }
 
@@ -97,7 +151,18 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
-// Refresh handles token refresh
+// Refresh godoc
+// @Summary Refresh the tokens
+// @Description Exchange a refresh token for new tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RefreshRequest true "Refresh token"
+// @Success 200 {object} application.TokenResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Router /api/v1/auth/refresh [post]
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var req RefreshRequest
 	if c.ShouldBindJSON(&req) != nil {
@@ -122,8 +187,94 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	c.JSON(http.StatusOK, tokens)
 }
 
-// Logout handles user logout by revoking the current access token.
-// Requires authentication — the auth middleware must set user_id in context.
+// ForgotPasswordRequest represents the forgot password request body
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link to the active user with this address. The response is the same whether or not an account was found.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Email address"
+// @Success 202 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if c.ShouldBindJSON(&req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a valid email is required"})
+		return
+	}
+
+	if !h.service.PasswordResetEnabled() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "password reset by email is not enabled"})
+		return
+	}
+
+	// The request is handled in the background and its failures are logged,
+	// not returned: the answer and the time taken to give it would tell which
+	// addresses have an account
+	ctx, ip := context.WithoutCancel(c.Request.Context()), c.ClientIP()
+	go func() {
+		if err := h.service.RequestPasswordReset(ctx, req.Email, ip); err != nil {
+			h.logger.Warn("Failed to handle a password reset request", zap.String("ip", ip), zap.Error(err))
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "if an account uses this address, a password reset link has been sent to it"})
+}
+
+// PasswordResetRequest represents the reset password request body
+type PasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=72"`
+}
+
+// ResetPassword godoc
+// @Summary Reset a password
+// @Description Set a new password with the token of a password reset email. The token is then spent, and the lockout of the username after failed logins lifted.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body PasswordResetRequest true "Reset token and new password"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req PasswordResetRequest
+	if c.ShouldBindJSON(&req) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token and new_password (8 to 72 characters) are required"})
+		return
+	}
+
+	err := h.service.ResetPasswordWithToken(c.Request.Context(), req.Token, req.NewPassword, c.ClientIP())
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "password reset successfully"})
+	case errors.Is(err, application.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrPasswordResetDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "password reset by email is not enabled"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+	}
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the access token of the request until it expires
+// @Tags auth
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Require authentication to prevent unauthenticated blacklist abuse
 	if _, exists := c.Get("user_id"); !exists {
@@ -186,7 +337,15 @@ func getValidTokenExpiry(tokenString string) (time.Time, bool) {
 	return time.Unix(int64(*claims.Exp), 0), true
 }
 
-// Me returns the current authenticated user
+// Me godoc
+// @Summary Get the current user
+// @Description Get the user the access token was issued to
+// @Tags auth
+// @Produce json
+// @Success 200 {object} entity.User
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/auth/me [get]
 func (h *AuthHandler) Me(c *gin.Context) {
 	// User ID is set by the auth middleware
 	userID, exists := c.Get("user_id")
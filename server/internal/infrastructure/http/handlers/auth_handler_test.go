@@ -18,6 +18,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -26,6 +28,7 @@ type mockUserRepo struct {
 	users     map[string]*entity.User
 	findErr   error
 	createErr error
+	throttles map[string]*entity.LoginThrottle
 }
 
 func newMockUserRepo() *mockUserRepo {
@@ -168,6 +171,61 @@ func (m *mockUserRepo) DeactivateAdminIfNotLast(ctx context.Context, id string)
 	return nil
 }
 
+func (m *mockUserRepo) FindLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) (*entity.LoginThrottle, error) {
+	throttle, ok := m.throttles[string(scope)+":"+key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	copied := *throttle
+	return &copied, nil
+}
+
+func (m *mockUserRepo) AddLoginFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, at, failuresSince, lockoutsSince time.Time) (*entity.LoginThrottle, error) {
+	if m.throttles == nil {
+		m.throttles = make(map[string]*entity.LoginThrottle)
+	}
+	throttle, ok := m.throttles[string(scope)+":"+key]
+	if !ok {
+		throttle = &entity.LoginThrottle{Scope: scope, Key: key}
+		m.throttles[string(scope)+":"+key] = throttle
+	}
+	if throttle.LastFailureAt.Before(failuresSince) {
+		throttle.Failures = 0
+	}
+	if throttle.LastFailureAt.Before(lockoutsSince) {
+		throttle.Lockouts = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = at
+	copied := *throttle
+	return &copied, nil
+}
+
+func (m *mockUserRepo) LockLogin(ctx context.Context, scope entity.LoginThrottleScope, key string, failures int, until time.Time) (*entity.LoginThrottle, error) {
+	throttle, ok := m.throttles[string(scope)+":"+key]
+	if !ok || throttle.Failures < failures {
+		return nil, sql.ErrNoRows
+	}
+	throttle.Failures, throttle.Lockouts, throttle.LockedUntil = 0, throttle.Lockouts+1, &until
+	copied := *throttle
+	return &copied, nil
+}
+
+func (m *mockUserRepo) DeleteLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) error {
+	delete(m.throttles, string(scope)+":"+key)
+	return nil
+}
+
+func (m *mockUserRepo) FindLockedLoginThrottles(ctx context.Context, at time.Time) ([]*entity.LoginThrottle, error) {
+	var throttles []*entity.LoginThrottle
+	for _, throttle := range m.throttles {
+		if throttle.IsLocked(at) {
+			throttles = append(throttles, throttle)
+		}
+	}
+	return throttles, nil
+}
+
 func TestNewAuthHandler(t *testing.T) {
 	repo := newMockUserRepo()
 	service := application.NewAuthService(repo, "test-secret")
@@ -260,6 +318,46 @@ func TestAuthHandler_Login_Success(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_RecordsActivity(t *testing.T) {
+	repo := newMockUserRepo()
+	service := application.NewAuthService(repo, "test-secret")
+	handler := NewAuthHandler(service)
+	activityRepo := &mockActivityRepo{}
+	handler.SetActivityMonitor(newTestActivityMonitor(activityRepo, repo))
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), 10)
+	repo.users["user-1"] = &entity.User{
+		ID:           "user-1",
+		Username:     "testuser",
+		PasswordHash: string(hashedPassword),
+		Role:         entity.RoleAdmin,
+		IsActive:     true,
+	}
+	activityRepo.logins = []*entity.LoginRecord{
+		{ID: "l1", UserID: "user-1", IPAddress: "10.0.0.1", Country: "FR"},
+	}
+
+	router := gin.New()
+	router.POST("/login", handler.Login)
+
+	jsonBody, _ := json.Marshal(LoginRequest{Username: "testuser", Password: "password123"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("CF-IPCountry", "us")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(activityRepo.logins) != 2 || activityRepo.logins[1].Country != "US" {
+		t.Errorf("Expected the login to be recorded with its country, got %+v", activityRepo.logins)
+	}
+	if len(activityRepo.anomalies) != 1 || activityRepo.anomalies[0].Type != entity.AnomalyNewCountry {
+		t.Errorf("Expected a new_country anomaly, got %+v", activityRepo.anomalies)
+	}
+}
+
 func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
 	repo := newMockUserRepo()
 	service := application.NewAuthService(repo, "test-secret")
@@ -292,6 +390,194 @@ func TestAuthHandler_Login_InvalidCredentials(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_LockedOut(t *testing.T) {
+	repo := newMockUserRepo()
+	service := application.NewAuthService(repo, "test-secret")
+	handler := NewAuthHandler(service)
+
+	router := gin.New()
+	router.POST("/login", handler.Login)
+
+	login := func() *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(LoginRequest{Username: "testuser", Password: "wrongpassword"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/login", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < application.DefaultLoginLockoutConfig().MaxUserFailures; i++ {
+		if w := login(); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected status 401, got %d", i, w.Code)
+		}
+	}
+
+	w := login()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["retry_after"] == nil {
+		t.Errorf("Expected retry_after in response, got %v", response)
+	}
+}
+
+// mockPasswordResetRepo implements repository.PasswordResetRepository for testing
+type mockPasswordResetRepo struct {
+	tokens []*entity.PasswordResetToken
+}
+
+func (m *mockPasswordResetRepo) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func (m *mockPasswordResetRepo) FindByHash(ctx context.Context, hash string) (*entity.PasswordResetToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockPasswordResetRepo) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	for _, token := range m.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			token.UsedAt = &usedAt
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *mockPasswordResetRepo) InvalidateByUser(ctx context.Context, userID string, at time.Time) error {
+	return nil
+}
+
+func (m *mockPasswordResetRepo) CountByUserSince(ctx context.Context, userID string, since time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *mockPasswordResetRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// mockResetMailer passes on the password reset tokens sent
+type mockResetMailer struct {
+	tokens chan string
+	err    error
+}
+
+func (m *mockResetMailer) SendPasswordReset(ctx context.Context, user *entity.User, token string, expiresAt time.Time) error {
+	err := m.err
+	m.tokens <- token
+	return err
+}
+
+// sent waits for the next password reset token sent in the background
+func (m *mockResetMailer) sent(t *testing.T) string {
+	t.Helper()
+	select {
+	case token := <-m.tokens:
+		return token
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the password reset email")
+		return ""
+	}
+}
+
+func TestAuthHandler_PasswordReset(t *testing.T) {
+	repo := newMockUserRepo()
+	service := application.NewAuthService(repo, "test-secret")
+	mailer := &mockResetMailer{tokens: make(chan string, 1)}
+	service.SetPasswordReset(&mockPasswordResetRepo{}, mailer, time.Minute)
+	handler := NewAuthHandler(service)
+	core, logs := observer.New(zap.WarnLevel)
+	handler.SetLogger(zap.New(core))
+
+	repo.users["user-1"] = &entity.User{ID: "user-1", Username: "testuser", Email: "test@example.com", IsActive: true}
+
+	router := gin.New()
+	router.POST("/forgot-password", handler.ForgotPassword)
+	router.POST("/reset-password", handler.ResetPassword)
+
+	post := func(path string, body interface{}) *httptest.ResponseRecorder {
+		jsonBody, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("/forgot-password", ForgotPasswordRequest{Email: "not-an-email"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if w := post("/forgot-password", ForgotPasswordRequest{Email: "test@example.com"}); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", w.Code)
+	}
+	token := mailer.sent(t)
+
+	if w := post("/reset-password", PasswordResetRequest{Token: token, NewPassword: "short"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a short password, got %d", w.Code)
+	}
+	if w := post("/reset-password", PasswordResetRequest{Token: token, NewPassword: "newpassword"}); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := post("/reset-password", PasswordResetRequest{Token: token, NewPassword: "newpassword"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a spent token, got %d", w.Code)
+	}
+
+	// A delivery failure gets the same answer and is logged
+	mailer.err = errors.New("SMTP not configured")
+	if w := post("/forgot-password", ForgotPasswordRequest{Email: "test@example.com"}); w.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", w.Code)
+	}
+	mailer.sent(t)
+	deadline := time.Now().Add(3 * time.Second)
+	for logs.FilterMessage("Failed to handle a password reset request").Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the delivery failure to be logged")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Unknown addresses get the same answer
+	if w := post("/forgot-password", ForgotPasswordRequest{Email: "unknown@example.com"}); w.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", w.Code)
+	}
+}
+
+func TestAuthHandler_PasswordReset_Disabled(t *testing.T) {
+	handler := NewAuthHandler(application.NewAuthService(newMockUserRepo(), "test-secret"))
+
+	limiter := middleware.NewRateLimiter(10, time.Minute)
+	defer limiter.Close()
+	router := gin.New()
+	handler.RegisterPasswordResetRoutes(router, limiter)
+
+	for path, body := range map[string]interface{}{
+		"/api/v1/auth/forgot-password": ForgotPasswordRequest{Email: "test@example.com"},
+		"/api/v1/auth/reset-password":  PasswordResetRequest{Token: "token", NewPassword: "newpassword"},
+	} {
+		jsonBody, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status 503, got %d", path, w.Code)
+		}
+	}
+}
+
 func TestAuthHandler_Login_UserNotFound(t *testing.T) {
 	repo := newMockUserRepo()
 	service := application.NewAuthService(repo, "test-secret")
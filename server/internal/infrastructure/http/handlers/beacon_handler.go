@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BeaconHandler manages the check-in interval and jitter of agents and agent selectors
+type BeaconHandler struct {
+	service *application.BeaconService
+}
+
+// NewBeaconHandler creates a new beacon handler
+func NewBeaconHandler(service *application.BeaconService) *BeaconHandler {
+	return &BeaconHandler{service: service}
+}
+
+// BeaconSettingsResponse lists the default beacon and every override
+type BeaconSettingsResponse struct {
+	Default   entity.BeaconSettings    `json:"default"`
+	Overrides []*entity.BeaconOverride `json:"overrides"`
+}
+
+// ListSettings godoc
+// @Summary List beacon settings
+// @Description Get the default beacon and the overrides of agents and agent selectors
+// @Tags beacon
+// @Produce json
+// @Success 200 {object} BeaconSettingsResponse
+// @Router /api/v1/beacon-settings [get]
+func (h *BeaconHandler) ListSettings(c *gin.Context) {
+	overrides, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list beacon settings"})
+		return
+	}
+	// Return empty array instead of null
+	if overrides == nil {
+		overrides = []*entity.BeaconOverride{}
+	}
+	c.JSON(http.StatusOK, BeaconSettingsResponse{Default: h.service.Defaults(), Overrides: overrides})
+}
+
+// GetAgentBeacon godoc
+// @Summary Get the beacon of an agent
+// @Description Get the interval and jitter an agent uses, and whether they come from the agent, a selector or the default
+// @Tags beacon
+// @Produce json
+// @Param paw path string true "Agent paw"
+// @Success 200 {object} application.ResolvedBeacon
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agents/{paw}/beacon [get]
+func (h *BeaconHandler) GetAgentBeacon(c *gin.Context) {
+	beacon, err := h.service.ResolveAgent(c.Request.Context(), c.Param("paw"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, beacon)
+}
+
+// SetAgentBeacon godoc
+// @Summary Set the beacon of an agent
+// @Description Set the interval and jitter of an agent, pushed to it on its next check-in
+// @Tags beacon
+// @Accept json
+// @Produce json
+// @Param paw path string true "Agent paw"
+// @Param request body entity.BeaconSettings true "Interval (seconds) and jitter (percent)"
+// @Success 200 {object} entity.BeaconOverride
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agents/{paw}/beacon [put]
+func (h *BeaconHandler) SetAgentBeacon(c *gin.Context) {
+	var settings entity.BeaconSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	override, err := h.service.SetAgent(c.Request.Context(), c.Param("paw"), settings, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, override)
+}
+
+// ClearAgentBeacon godoc
+// @Summary Clear the beacon of an agent
+// @Description Remove the override of an agent, which falls back to its selector or the default
+// @Tags beacon
+// @Param paw path string true "Agent paw"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agents/{paw}/beacon [delete]
+func (h *BeaconHandler) ClearAgentBeacon(c *gin.Context) {
+	if err := h.service.Clear(c.Request.Context(), entity.BeaconScopeAgent, c.Param("paw")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetSelectorBeacon godoc
+// @Summary Set the beacon of an agent selector
+// @Description Set the interval and jitter of the agents matching a selector that have no override of their own
+// @Tags beacon
+// @Accept json
+// @Produce json
+// @Param id path string true "Selector ID"
+// @Param request body entity.BeaconSettings true "Interval (seconds) and jitter (percent)"
+// @Success 200 {object} entity.BeaconOverride
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agent-selectors/{id}/beacon [put]
+func (h *BeaconHandler) SetSelectorBeacon(c *gin.Context) {
+	var settings entity.BeaconSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	override, err := h.service.SetSelector(c.Request.Context(), c.Param("id"), settings, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, override)
+}
+
+// ClearSelectorBeacon godoc
+// @Summary Clear the beacon of an agent selector
+// @Description Remove the override of an agent selector
+// @Tags beacon
+// @Param id path string true "Selector ID"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Router /api/v1/agent-selectors/{id}/beacon [delete]
+func (h *BeaconHandler) ClearSelectorBeacon(c *gin.Context) {
+	if err := h.service.Clear(c.Request.Context(), entity.BeaconScopeSelector, c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps a beacon service error to a response
+func (h *BeaconHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidBeacon):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrBeaconAgentUnknown),
+		errors.Is(err, application.ErrAgentSelectorNotFound),
+		errors.Is(err, application.ErrBeaconOverrideNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "beacon operation failed"})
+	}
+}
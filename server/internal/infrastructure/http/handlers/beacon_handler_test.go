@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	gorillaws "github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// mockBeaconOverrideRepo implements repository.BeaconOverrideRepository for tests
+type mockBeaconOverrideRepo struct {
+	overrides map[string]*entity.BeaconOverride
+}
+
+func (m *mockBeaconOverrideRepo) Upsert(ctx context.Context, override *entity.BeaconOverride) error {
+	m.overrides[string(override.Scope)+"/"+override.Target] = override
+	return nil
+}
+
+func (m *mockBeaconOverrideRepo) Delete(ctx context.Context, scope entity.BeaconScope, target string) error {
+	delete(m.overrides, string(scope)+"/"+target)
+	return nil
+}
+
+func (m *mockBeaconOverrideRepo) Find(ctx context.Context, scope entity.BeaconScope, target string) (*entity.BeaconOverride, error) {
+	if override, ok := m.overrides[string(scope)+"/"+target]; ok {
+		return override, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockBeaconOverrideRepo) FindAll(ctx context.Context) ([]*entity.BeaconOverride, error) {
+	var overrides []*entity.BeaconOverride
+	for _, override := range m.overrides {
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+func newTestBeaconService(agentRepo repository.AgentRepository) *application.BeaconService {
+	selectors := &mockAgentSelectorRepoForHandler{selectors: map[string]*entity.AgentSelector{
+		"sel-linux": {ID: "sel-linux", Name: "Linux", Platforms: []string{"linux"}},
+	}}
+	return application.NewBeaconService(&mockBeaconOverrideRepo{overrides: make(map[string]*entity.BeaconOverride)},
+		selectors, agentRepo, entity.BeaconSettings{Interval: 30}, zap.NewNop())
+}
+
+func TestBeaconHandler_Routes(t *testing.T) {
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{Paw: "paw1", Platform: "linux"}
+	handler := NewBeaconHandler(newTestBeaconService(agentRepo))
+
+	router := gin.New()
+	api := router.Group("/api/v1", func(c *gin.Context) { c.Set("user_id", "user-1") })
+	api.GET("/beacon-settings", handler.ListSettings)
+	api.GET("/agents/:paw/beacon", handler.GetAgentBeacon)
+	api.PUT("/agents/:paw/beacon", handler.SetAgentBeacon)
+	api.DELETE("/agents/:paw/beacon", handler.ClearAgentBeacon)
+	api.PUT("/agent-selectors/:id/beacon", handler.SetSelectorBeacon)
+	api.DELETE("/agent-selectors/:id/beacon", handler.ClearSelectorBeacon)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		code   int
+		want   string
+	}{
+		{http.MethodGet, "/api/v1/agents/paw1/beacon", "", http.StatusOK, `"source":"default"`},
+		{http.MethodPut, "/api/v1/agent-selectors/sel-linux/beacon", `{"interval": 300, "jitter": 20}`, http.StatusOK, `"scope":"selector"`},
+		{http.MethodGet, "/api/v1/agents/paw1/beacon", "", http.StatusOK, `"selector_id":"sel-linux"`},
+		{http.MethodPut, "/api/v1/agents/paw1/beacon", `{"interval": 60, "jitter": 10}`, http.StatusOK, `"updated_by":"user-1"`},
+		{http.MethodGet, "/api/v1/agents/paw1/beacon", "", http.StatusOK, `"interval":60`},
+		{http.MethodPut, "/api/v1/agents/paw1/beacon", `{"interval": 1}`, http.StatusBadRequest, "interval"},
+		{http.MethodPut, "/api/v1/agents/paw1/beacon", `not json`, http.StatusBadRequest, ""},
+		{http.MethodPut, "/api/v1/agent-selectors/missing/beacon", `{"interval": 60}`, http.StatusNotFound, ""},
+		{http.MethodGet, "/api/v1/beacon-settings", "", http.StatusOK, `"default":{"interval":30,"jitter":0}`},
+		{http.MethodDelete, "/api/v1/agents/paw1/beacon", "", http.StatusNoContent, ""},
+		{http.MethodDelete, "/api/v1/agents/paw1/beacon", "", http.StatusNotFound, ""},
+		{http.MethodDelete, "/api/v1/agent-selectors/sel-linux/beacon", "", http.StatusNoContent, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s %s %s: expected %d with %s, got %d (%s)", tt.method, tt.path, tt.body, tt.code, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestWebSocketHandler_PushesBeacon(t *testing.T) {
+	agentRepo := newWSTestAgentRepo()
+	beacons := newTestBeaconService(agentRepo)
+
+	hub := websocket.NewHub(zap.NewNop())
+	go hub.Run()
+	handler := NewWebSocketHandler(hub, application.NewAgentService(agentRepo), zap.NewNop())
+	handler.SetBeaconService(beacons)
+
+	router := gin.New()
+	router.GET("/ws/agent", handler.HandleAgentConnection)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := gorillaws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/agent", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect WebSocket: %v", err)
+	}
+	defer conn.Close()
+
+	send := func(msgType string, payload interface{}) {
+		data, _ := json.Marshal(map[string]interface{}{"type": msgType, "payload": payload})
+		if err := conn.WriteMessage(gorillaws.TextMessage, data); err != nil {
+			t.Fatalf("Failed to send %s: %v", msgType, err)
+		}
+	}
+	readBeacon := func() entity.BeaconSettings {
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		for {
+			var msg websocket.Message
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("Expected a beacon message, got %v", err)
+			}
+			if msg.Type == "beacon" {
+				var settings entity.BeaconSettings
+				_ = json.Unmarshal(msg.Payload, &settings)
+				return settings
+			}
+		}
+	}
+
+	send("register", RegisterPayload{Paw: "paw1", Hostname: "host", Platform: "linux", Executors: []string{"sh"}})
+	if settings := readBeacon(); settings.Interval != 30 {
+		t.Errorf("Expected the default beacon on registration, got %+v", settings)
+	}
+
+	if _, err := beacons.SetAgent(context.Background(), "paw1", entity.BeaconSettings{Interval: 90, Jitter: 30}, ""); err != nil {
+		t.Fatalf("SetAgent failed: %v", err)
+	}
+	send("heartbeat", HeartbeatPayload{Paw: "paw1"})
+	if settings := readBeacon(); settings.Interval != 90 || settings.Jitter != 30 {
+		t.Errorf("Expected the changed beacon on check-in, got %+v", settings)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxConfigBundleSize caps the size of an imported configuration bundle
+const maxConfigBundleSize = 50 << 20
+
+// ConfigBundleHandler exports and imports the configuration of the server as a bundle
+type ConfigBundleHandler struct {
+	service *application.ConfigBundleService
+}
+
+// NewConfigBundleHandler creates a new configuration bundle handler
+func NewConfigBundleHandler(service *application.ConfigBundleService) *ConfigBundleHandler {
+	return &ConfigBundleHandler{service: service}
+}
+
+// ExportBundle godoc
+// @Summary Export the configuration bundle
+// @Description Download the techniques, scenarios, agent selectors, schedules and beacon overrides as a versioned bundle, signed when a signing key is configured
+// @Tags admin
+// @Produce json
+// @Success 200 {object} application.ConfigBundle
+// @Failure 500 {object} gin.H
+// @Router /api/v1/export/bundle [get]
+func (h *ConfigBundleHandler) ExportBundle(c *gin.Context) {
+	bundle, err := h.service.Export(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("autostrike-config-%s.json", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.JSON(http.StatusOK, bundle)
+}
+
+// ImportBundle godoc
+// @Summary Import a configuration bundle
+// @Description Create or update, by ID, the configuration of a bundle exported by another server. With dry_run=true nothing is written.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Only report what would change"
+// @Success 200 {object} application.ConfigBundleImportResult
+// @Success 207 {object} application.ConfigBundleImportResult
+// @Failure 400 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Router /api/v1/import/bundle [post]
+func (h *ConfigBundleHandler) ImportBundle(c *gin.Context) {
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxConfigBundleSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+	if len(data) > maxConfigBundleSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "configuration bundle is too large"})
+		return
+	}
+
+	var bundle application.ConfigBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid configuration bundle: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.Import(c.Request.Context(), &bundle, c.GetString("user_id"), c.Query("dry_run") == "true")
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidConfigBundle) || errors.Is(err, application.ErrConfigBundleSignature) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusMultiStatus, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
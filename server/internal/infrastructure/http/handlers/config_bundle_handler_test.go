@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newConfigBundleRouter(key string, techniques *mockTechniqueRepo) *gin.Engine {
+	service := application.NewConfigBundleService(
+		techniques,
+		newMockScenarioRepo(),
+		&mockAgentSelectorRepoForHandler{selectors: make(map[string]*entity.AgentSelector)},
+		newMockScheduleRepo(),
+		&mockBeaconOverrideRepo{overrides: make(map[string]*entity.BeaconOverride)},
+		nil,
+	)
+	service.SetSigningKey([]byte(key))
+	handler := NewConfigBundleHandler(service)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Next()
+	})
+	router.GET("/export/bundle", handler.ExportBundle)
+	router.POST("/import/bundle", handler.ImportBundle)
+	return router
+}
+
+func TestConfigBundleHandler_ExportImport(t *testing.T) {
+	staging := newMockTechniqueRepo()
+	staging.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Command Interpreter"}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/export/bundle", nil)
+	newConfigBundleRouter("secret", staging).ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment; filename=autostrike-config-") {
+		t.Errorf("Expected a file download, got %q", w.Header().Get("Content-Disposition"))
+	}
+	bundle := w.Body.Bytes()
+
+	production := newMockTechniqueRepo()
+	router := newConfigBundleRouter("secret", production)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/import/bundle?dry_run=true", bytes.NewReader(bundle))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || len(production.techniques) != 0 {
+		t.Fatalf("Expected a dry run without changes, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/import/bundle", bytes.NewReader(bundle))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result application.ConfigBundleImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || !result.Verified || result.Techniques.Created != 1 {
+		t.Errorf("Unexpected result %+v (%v)", result, err)
+	}
+	if _, ok := production.techniques["T1059"]; !ok {
+		t.Error("Expected the technique imported")
+	}
+}
+
+func TestConfigBundleHandler_ImportErrors(t *testing.T) {
+	router := newConfigBundleRouter("secret", newMockTechniqueRepo())
+	unsigned := `{"format":"autostrike-config-bundle","version":1,"content":{}}`
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid JSON", "{", http.StatusBadRequest},
+		{"unknown format", `{"format":"other","version":1,"content":{}}`, http.StatusBadRequest},
+		{"missing signature", unsigned, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/import/bundle", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+			if w.Code != tt.code {
+				t.Errorf("Expected status %d, got %d: %s", tt.code, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Invalid items are reported with a multi-status
+	w := httptest.NewRecorder()
+	body := `{"format":"autostrike-config-bundle","version":1,"content":{"techniques":[{"id":"T1059","parent_id":"T1000"}]}}`
+	req, _ := http.NewRequest("POST", "/import/bundle", strings.NewReader(body))
+	newConfigBundleRouter("", newMockTechniqueRepo()).ServeHTTP(w, req)
+	if w.Code != http.StatusMultiStatus {
+		t.Errorf("Expected status 207, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxContentImportSize caps the size of an imported content file
+const maxContentImportSize = 10 << 20
+
+// ContentHandler imports third-party attack content
+type ContentHandler struct {
+	service *application.ContentImportService
+	jobs    *application.JobService
+}
+
+// NewContentHandler creates a new content handler
+func NewContentHandler(service *application.ContentImportService) *ContentHandler {
+	return &ContentHandler{service: service}
+}
+
+// SetJobService lets imports run as background jobs with async=true
+func (h *ContentHandler) SetJobService(jobs *application.JobService) {
+	h.jobs = jobs
+}
+
+// ListFormats godoc
+// @Summary List content formats
+// @Description List the attack-content formats that can be imported
+// @Tags content
+// @Produce json
+// @Success 200 {object} gin.H
+// @Router /api/v1/content/formats [get]
+func (h *ContentHandler) ListFormats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"formats": h.service.Formats()})
+}
+
+// ImportContent godoc
+// @Summary Import attack content
+// @Description Convert a Prelude Operator, Stratus Red Team, CTID emulation plan, Caldera, ATT&CK STIX or Atomic Red Team file into techniques and scenarios, reporting the techniques added, updated and deprecated
+// @Tags content
+// @Accept plain
+// @Produce json
+// @Param format path string true "Content format (prelude, stratus, ctid, caldera, stix, atomic)"
+// @Param incremental query bool false "Only write the techniques and scenarios that changed"
+// @Param deprecate_removed query bool false "Deprecate the techniques of the format the content no longer has"
+// @Param platform query string false "Only import the techniques of this ATT&CK platform matrix: windows, linux, macos (repeatable)"
+// @Param domain query string false "Only import the techniques of this ATT&CK domain: enterprise-attack, ics-attack, mobile-attack (repeatable, techniques without a domain are enterprise-attack)"
+// @Param async query bool false "Import in a background job, followed with GET /jobs/{id}"
+// @Success 200 {object} application.ContentImportResult
+// @Success 202 {object} entity.Job
+// @Success 207 {object} application.ContentImportResult
+// @Failure 400 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/content/import/{format} [post]
+func (h *ContentHandler) ImportContent(c *gin.Context) {
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxContentImportSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+	if len(data) > maxContentImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "content file is too large"})
+		return
+	}
+	if len(data) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "empty content file"})
+		return
+	}
+
+	opts := application.ContentImportOptions{
+		Incremental:      c.Query("incremental") == "true",
+		DeprecateRemoved: c.Query("deprecate_removed") == "true",
+		Platforms:        c.QueryArray("platform"),
+		Domains:          c.QueryArray("domain"),
+	}
+	for _, domain := range opts.Domains {
+		if !entity.IsValidDomain(domain) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%v: %q", application.ErrInvalidTechniqueDomain, domain),
+				"domains": []string{entity.DomainEnterprise, entity.DomainICS, entity.DomainMobile}})
+			return
+		}
+	}
+	format := c.Param("format")
+	if wantsAsync(c, h.jobs) {
+		if !slices.Contains(h.service.Formats(), format) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%v: %q", application.ErrUnknownContentFormat, format), "formats": h.service.Formats()})
+			return
+		}
+		submitJob(c, h.jobs, entity.JobContentImport, func(ctx context.Context, progress *application.JobProgress) (any, error) {
+			progress.Report(0, 0, "Importing "+format+" content")
+			return h.service.Import(ctx, format, data, opts)
+		})
+		return
+	}
+
+	result, err := h.service.Import(c.Request.Context(), format, data, opts)
+	if err != nil {
+		if errors.Is(err, application.ErrUnknownContentFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "formats": h.service.Formats()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if result.Failed > 0 {
+		c.JSON(http.StatusMultiStatus, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
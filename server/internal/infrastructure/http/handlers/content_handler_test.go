@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubContentConverter struct{}
+
+func (c *stubContentConverter) Format() string { return "stub" }
+
+func (c *stubContentConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	if string(data) == "invalid" {
+		return nil, errors.New("invalid content")
+	}
+	return &application.ContentBundle{
+		Techniques: []*entity.Technique{{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery,
+			Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh", Command: "uname -a"}}}},
+		Scenarios: []*entity.Scenario{{Name: string(data), Phases: []entity.Phase{{Name: "Discovery", Techniques: []string{"T1082"}, Order: 1}}}},
+	}, nil
+}
+
+func setupContentRouter() *gin.Engine {
+	techRepo := newMockTechniqueRepo()
+	svc := application.NewContentImportService(
+		application.NewTechniqueService(techRepo),
+		application.NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator()),
+		&stubContentConverter{},
+	)
+	handler := NewContentHandler(svc)
+
+	router := gin.New()
+	router.GET("/api/v1/content/formats", handler.ListFormats)
+	router.POST("/api/v1/content/import/:format", handler.ImportContent)
+	return router
+}
+
+func TestContentHandler_ImportContent(t *testing.T) {
+	router := setupContentRouter()
+
+	tests := []struct {
+		name   string
+		format string
+		body   string
+		code   int
+	}{
+		{"imported", "stub", "Stub scenario", http.StatusOK},
+		{"unknown format", "caldera", "data", http.StatusBadRequest},
+		{"conversion error", "stub", "invalid", http.StatusBadRequest},
+		{"empty body", "stub", "", http.StatusBadRequest},
+		{"unknown domain", "stub?domain=pre-attack", "data", http.StatusBadRequest},
+		{"other domain", "stub?domain=ics-attack", "Stub scenario", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/content/import/"+tt.format, strings.NewReader(tt.body))
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/content/import/stub", strings.NewReader("Stub scenario"))
+	router.ServeHTTP(w, req)
+	var result application.ContentImportResult
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	if len(result.Techniques) != 1 || len(result.Scenarios) != 1 || result.Scenarios[0].ID == "" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// An incremental import leaves the technique already imported as is
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/content/import/stub?incremental=true&deprecate_removed=true", strings.NewReader("Stub scenario"))
+	router.ServeHTTP(w, req)
+	result = application.ContentImportResult{}
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	if w.Code != http.StatusOK || len(result.Techniques) != 0 || result.Unchanged != 1 {
+		t.Errorf("Expected an incremental import, got %d %+v", w.Code, result)
+	}
+}
+
+func TestContentHandler_ListFormats(t *testing.T) {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/content/formats", nil)
+	setupContentRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"stub"`) {
+		t.Errorf("Unexpected response: %d %s", w.Code, w.Body.String())
+	}
+}
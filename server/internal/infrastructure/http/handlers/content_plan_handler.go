@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxContentSourceSize caps the size of the content sent to plan or apply
+const maxContentSourceSize = 50 << 20
+
+// ContentPlanRequest is the content of a YAML directory to plan or apply
+type ContentPlanRequest struct {
+	application.ContentSource
+	Prune    bool   `json:"prune"`              // Delete what the content does not define
+	Checksum string `json:"checksum,omitempty"` // Checksum of the reviewed plan, for apply
+}
+
+// ContentPlanHandler plans and applies technique and scenario YAML directories
+type ContentPlanHandler struct {
+	service *application.ContentPlanService
+}
+
+// NewContentPlanHandler creates a new content plan handler
+func NewContentPlanHandler(service *application.ContentPlanService) *ContentPlanHandler {
+	return &ContentPlanHandler{service: service}
+}
+
+// PlanContent godoc
+// @Summary Plan content changes
+// @Description Diff techniques and scenarios against the database and list what applying them would create, update, restore from the trash or, with prune, delete. Nothing is written.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body ContentPlanRequest true "Content to plan"
+// @Success 200 {object} application.ContentPlan
+// @Failure 400 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Router /api/v1/admin/content/plan [post]
+func (h *ContentPlanHandler) PlanContent(c *gin.Context) {
+	req, ok := readContentPlanRequest(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.service.Plan(c.Request.Context(), &req.ContentSource, req.Prune)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// ApplyContent godoc
+// @Summary Apply content changes
+// @Description Plan the content again and apply the changes. With the checksum of a reviewed plan, nothing is applied when the plan changed since.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body ContentPlanRequest true "Content to apply"
+// @Success 200 {object} application.ContentApplyResult
+// @Success 207 {object} application.ContentApplyResult
+// @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Router /api/v1/admin/content/apply [post]
+func (h *ContentPlanHandler) ApplyContent(c *gin.Context) {
+	req, ok := readContentPlanRequest(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Apply(c.Request.Context(), &req.ContentSource, req.Prune, req.Checksum)
+	if err != nil {
+		if errors.Is(err, application.ErrContentPlanStale) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		c.JSON(http.StatusMultiStatus, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// readContentPlanRequest reads the request body, responding on error
+func readContentPlanRequest(c *gin.Context) (*ContentPlanRequest, bool) {
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxContentSourceSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return nil, false
+	}
+	if len(data) > maxContentSourceSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "content is too large"})
+		return nil, false
+	}
+
+	var req ContentPlanRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid content: " + err.Error()})
+		return nil, false
+	}
+	return &req, true
+}
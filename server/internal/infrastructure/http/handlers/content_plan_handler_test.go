@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupContentPlanRouter() (*gin.Engine, *mockTechniqueRepo) {
+	techniqueRepo := newMockTechniqueRepo()
+	techniqueRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery,
+		Tactics: []entity.TacticType{entity.TacticDiscovery}}
+	trashRepo := &mockTrashRepoForHandler{scenarios: map[string]*entity.Scenario{}, techniques: map[string]*entity.Technique{}}
+	handler := NewContentPlanHandler(application.NewContentPlanService(techniqueRepo, newMockScenarioRepo(), trashRepo, nil))
+
+	router := gin.New()
+	router.POST("/api/v1/admin/content/plan", handler.PlanContent)
+	router.POST("/api/v1/admin/content/apply", handler.ApplyContent)
+	return router, techniqueRepo
+}
+
+func postContent(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestContentPlanHandler_PlanAndApply(t *testing.T) {
+	router, techniqueRepo := setupContentPlanRouter()
+	content := `"techniques":[{"id":"T1082","name":"System Information Discovery","tactic":"discovery"},{"id":"T1016","name":"Network Discovery","tactic":"discovery"}]`
+
+	w := postContent(router, "/api/v1/admin/content/plan", "{"+content+"}")
+	var plan application.ContentPlan
+	if err := json.Unmarshal(w.Body.Bytes(), &plan); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Unexpected plan: %d %s", w.Code, w.Body.String())
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].ID != "T1016" || plan.Changes[0].Action != application.ContentCreate || plan.Unchanged != 1 {
+		t.Errorf("Unexpected plan: %+v", plan)
+	}
+	if _, ok := techniqueRepo.techniques["T1016"]; ok {
+		t.Error("Expected nothing written by a plan")
+	}
+
+	w = postContent(router, "/api/v1/admin/content/apply", `{`+content+`,"checksum":"stale"}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = postContent(router, "/api/v1/admin/content/apply", `{`+content+`,"checksum":"`+plan.Checksum+`"}`)
+	var result application.ContentApplyResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil || w.Code != http.StatusOK || result.Applied != 1 {
+		t.Fatalf("Unexpected apply: %d %s", w.Code, w.Body.String())
+	}
+	if _, ok := techniqueRepo.techniques["T1016"]; !ok {
+		t.Error("Expected the technique created")
+	}
+}
+
+func TestContentPlanHandler_Errors(t *testing.T) {
+	router, techniqueRepo := setupContentPlanRouter()
+
+	if w := postContent(router, "/api/v1/admin/content/plan", `{`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+
+	techniqueRepo.createErr = errors.New("database write error")
+	w := postContent(router, "/api/v1/admin/content/apply", `{"techniques":[{"id":"T1016","name":"Network Discovery","tactic":"discovery"}]}`)
+	if w.Code != http.StatusMultiStatus || !strings.Contains(w.Body.String(), "create technique T1016") {
+		t.Errorf("Expected status 207, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestContentReloadHandler_Reload(t *testing.T) {
+	techniqueRepo := newMockTechniqueRepo()
+	trashRepo := &mockTrashRepoForHandler{scenarios: map[string]*entity.Scenario{}, techniques: map[string]*entity.Technique{}}
+	plans := application.NewContentPlanService(techniqueRepo, newMockScenarioRepo(), trashRepo, nil)
+	dir := t.TempDir()
+	handler := NewContentReloadHandler(application.NewContentReloadService(plans, dir, nil))
+	router := gin.New()
+	router.POST("/api/v1/admin/reload", handler.ReloadContent)
+
+	if err := os.Mkdir(filepath.Join(dir, "techniques"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	technique := "- id: T1082\n  name: System Information Discovery\n  tactic: discovery\n"
+	if err := os.WriteFile(filepath.Join(dir, "techniques", "discovery.yaml"), []byte(technique), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	w := postContent(router, "/api/v1/admin/reload", "")
+	var response ContentReloadResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || w.Code != http.StatusOK ||
+		len(response.Files) != 1 || response.Files[0].Status != application.ContentFileApplied {
+		t.Fatalf("Unexpected reload: %d %s", w.Code, w.Body.String())
+	}
+
+	// A file that does not parse fails the reload
+	if err := os.WriteFile(filepath.Join(dir, "techniques", "broken.yaml"), []byte("- id: ["), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w = postContent(router, "/api/v1/admin/reload?all=true", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || w.Code != http.StatusMultiStatus || len(response.Files) != 2 {
+		t.Errorf("Expected status 207 with both files, got %d: %s", w.Code, w.Body.String())
+	}
+}
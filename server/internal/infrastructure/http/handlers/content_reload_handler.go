@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentReloadResponse lists the content files a reload went through
+type ContentReloadResponse struct {
+	Files []application.ContentFileResult `json:"files"`
+}
+
+// ContentReloadHandler reloads the technique and scenario files of the content directory
+type ContentReloadHandler struct {
+	service *application.ContentReloadService
+}
+
+// NewContentReloadHandler creates a new content reload handler
+func NewContentReloadHandler(service *application.ContentReloadService) *ContentReloadHandler {
+	return &ContentReloadHandler{service: service}
+}
+
+// ReloadContent godoc
+// @Summary Reload techniques and scenarios
+// @Description Apply the technique and scenario YAML files of configs/ changed since they were last loaded, or every file with all=true, and return the outcome of each file. Nothing is deleted.
+// @Tags admin
+// @Produce json
+// @Param all query bool false "Reload every file, changed or not"
+// @Success 200 {object} ContentReloadResponse
+// @Success 207 {object} ContentReloadResponse
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/reload [post]
+func (h *ContentReloadHandler) ReloadContent(c *gin.Context) {
+	files, err := h.service.Reload(c.Request.Context(), c.Query("all") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, file := range files {
+		if file.Status == application.ContentFileFailed {
+			c.JSON(http.StatusMultiStatus, ContentReloadResponse{Files: files})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, ContentReloadResponse{Files: files})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeepLinkHandler resolves the signed links sent in notifications
+type DeepLinkHandler struct {
+	links *application.DeepLinkService
+}
+
+// NewDeepLinkHandler creates a new deep link handler
+func NewDeepLinkHandler(links *application.DeepLinkService) *DeepLinkHandler {
+	return &DeepLinkHandler{links: links}
+}
+
+// RegisterRoutes registers the deep link routes
+func (h *DeepLinkHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/links/:token", h.ResolveLink)
+}
+
+// ResolveLink godoc
+// @Summary Resolve notification link
+// @Description Check a signed notification link for the current user and return the dashboard page it opens
+// @Tags notifications
+// @Produce json
+// @Param token path string true "Link token"
+// @Success 200 {object} application.DeepLinkTarget
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 410 {object} gin.H
+// @Router /api/v1/links/{token} [get]
+func (h *DeepLinkHandler) ResolveLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	target, err := h.links.Resolve(c.Param("token"), userID.(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrDeepLinkExpired):
+			c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrDeepLinkForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": application.ErrInvalidDeepLink.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, target)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupDeepLinkRouter(links *application.DeepLinkService, authenticated bool) *gin.Engine {
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", "test-user")
+			c.Next()
+		})
+	}
+	NewDeepLinkHandler(links).RegisterRoutes(router.Group("/api/v1"))
+	return router
+}
+
+func TestDeepLinkHandler_ResolveLink(t *testing.T) {
+	links := application.NewDeepLinkService("link-secret", time.Hour)
+	router := setupDeepLinkRouter(links, true)
+
+	valid, _ := links.Token("test-user", "n1", application.DeepLinkExecution, "exec-1")
+	otherUser, _ := links.Token("other-user", "n2", application.DeepLinkExecution, "exec-1")
+	expired, _ := application.NewDeepLinkService("link-secret", -time.Minute).Token("test-user", "n3", application.DeepLinkAgent, "paw-1")
+
+	tests := []struct {
+		name  string
+		token string
+		code  int
+	}{
+		{"valid", valid, http.StatusOK},
+		{"other user", otherUser, http.StatusForbidden},
+		{"expired", expired, http.StatusGone},
+		{"garbage", "not-a-token", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/links/"+tt.token, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.code, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/links/"+valid, nil)
+	router.ServeHTTP(w, req)
+	var target application.DeepLinkTarget
+	_ = json.Unmarshal(w.Body.Bytes(), &target)
+	if target.Path != "/executions/exec-1" {
+		t.Errorf("Expected execution path, got %+v", target)
+	}
+}
+
+func TestDeepLinkHandler_ResolveLink_Unauthenticated(t *testing.T) {
+	router := setupDeepLinkRouter(application.NewDeepLinkService("link-secret", time.Hour), false)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/links/anything", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+const errDetectionNotAuthenticated = "not authenticated"
+
+// DetectionHandler handles SIEM detection verification requests
+type DetectionHandler struct {
+	service *application.DetectionService
+}
+
+// NewDetectionHandler creates a new detection handler
+func NewDetectionHandler(service *application.DetectionService) *DetectionHandler {
+	return &DetectionHandler{service: service}
+}
+
+// RegisterRoutes registers detection routes
+func (h *DetectionHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/detection/status", h.GetStatus)
+	r.POST("/executions/:id/verify-detection", h.VerifyExecution)
+}
+
+// GetStatus godoc
+// @Summary Get detection verification status
+// @Description Returns whether detection verification is enabled and which SIEM/EDR connectors are configured
+// @Tags detection
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Router /api/v1/detection/status [get]
+func (h *DetectionHandler) GetStatus(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errDetectionNotAuthenticated})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":        h.service.Enabled(),
+		"connectors":     h.service.Connectors(),
+		"edr_connectors": h.service.EDRConnectors(),
+	})
+}
+
+// VerifyExecution godoc
+// @Summary Verify detections for an execution
+// @Description Queries the configured EDRs and SIEMs for every executed technique and updates results and score
+// @Tags detection
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {array} entity.ExecutionResult
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/executions/{id}/verify-detection [post]
+func (h *DetectionHandler) VerifyExecution(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errDetectionNotAuthenticated})
+		return
+	}
+
+	if !h.service.Enabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": "no SIEM or EDR connector configured"})
+		return
+	}
+
+	results, err := h.service.VerifyExecution(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, application.ErrExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if results == nil {
+		results = []*entity.ExecutionResult{}
+	}
+	c.JSON(http.StatusOK, results)
+}
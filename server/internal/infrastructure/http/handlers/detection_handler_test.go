@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockSIEMConnectorForHandler struct {
+	alerts []application.SIEMAlert
+}
+
+func (m *mockSIEMConnectorForHandler) Name() string { return "splunk" }
+
+func (m *mockSIEMConnectorForHandler) SearchAlerts(ctx context.Context, query application.DetectionQuery) ([]application.SIEMAlert, error) {
+	return m.alerts, nil
+}
+
+func newTestDetectionHandler(connectors ...application.SIEMConnector) (*DetectionHandler, *mockResultRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionCompleted}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1059", AgentPaw: "paw-1", Status: entity.StatusSuccess},
+	}
+	svc := application.NewDetectionService(resultRepo, newMockAgentRepo(), nil, service.NewScoreCalculator(),
+		connectors, nil, application.DefaultDetectionConfig(), nil)
+	return NewDetectionHandler(svc), resultRepo
+}
+
+func setupDetectionRouter(handler *DetectionHandler, authenticated bool) *gin.Engine {
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", "test-user")
+			c.Next()
+		})
+	}
+	handler.RegisterRoutes(router.Group("/api/v1"))
+	return router
+}
+
+func TestDetectionHandler_GetStatus(t *testing.T) {
+	handler, _ := newTestDetectionHandler(&mockSIEMConnectorForHandler{})
+	router := setupDetectionRouter(handler, true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/detection/status", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var body struct {
+		Enabled    bool     `json:"enabled"`
+		Connectors []string `json:"connectors"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if !body.Enabled || len(body.Connectors) != 1 || body.Connectors[0] != "splunk" {
+		t.Errorf("Unexpected status body: %s", w.Body.String())
+	}
+}
+
+func TestDetectionHandler_Unauthenticated(t *testing.T) {
+	handler, _ := newTestDetectionHandler(&mockSIEMConnectorForHandler{})
+	router := setupDetectionRouter(handler, false)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/detection/status"},
+		{http.MethodPost, "/api/v1/executions/exec-1/verify-detection"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tc.method, tc.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected 401, got %d", tc.method, tc.path, w.Code)
+		}
+	}
+}
+
+func TestDetectionHandler_VerifyExecution(t *testing.T) {
+	connector := &mockSIEMConnectorForHandler{alerts: []application.SIEMAlert{{ID: "a1", RuleName: "Rule"}}}
+	handler, resultRepo := newTestDetectionHandler(connector)
+	router := setupDetectionRouter(handler, true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/executions/exec-1/verify-detection", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var results []entity.ExecutionResult
+	_ = json.Unmarshal(w.Body.Bytes(), &results)
+	if len(results) != 1 || results[0].Status != entity.StatusDetected {
+		t.Errorf("Expected detected result, got %s", w.Body.String())
+	}
+	if resultRepo.executions["exec-1"].Score == nil {
+		t.Error("Expected execution to be rescored")
+	}
+}
+
+func TestDetectionHandler_VerifyExecution_NotFound(t *testing.T) {
+	handler, _ := newTestDetectionHandler(&mockSIEMConnectorForHandler{})
+	router := setupDetectionRouter(handler, true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/executions/missing/verify-detection", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+}
+
+func TestDetectionHandler_VerifyExecution_NotConfigured(t *testing.T) {
+	handler, _ := newTestDetectionHandler()
+	router := setupDetectionRouter(handler, true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/executions/exec-1/verify-detection", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409, got %d", w.Code)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmergencyStopHandler exposes the kill switch for all activity to admins
+type EmergencyStopHandler struct {
+	service *application.EmergencyStopService
+}
+
+// NewEmergencyStopHandler creates a new emergency stop handler
+func NewEmergencyStopHandler(service *application.EmergencyStopService) *EmergencyStopHandler {
+	return &EmergencyStopHandler{service: service}
+}
+
+// EmergencyStopRequest is the body of an emergency stop
+type EmergencyStopRequest struct {
+	Reason string `json:"reason"`
+}
+
+// EmergencyStopStatus tells whether the emergency stop is engaged
+type EmergencyStopStatus struct {
+	Engaged bool                  `json:"engaged"`
+	Stop    *entity.EmergencyStop `json:"stop,omitempty"` // The engaged trip
+}
+
+// GetStatus godoc
+// @Summary Get the emergency stop status
+// @Description Tells whether the emergency stop is engaged, with the trip that engaged it
+// @Tags admin
+// @Produce json
+// @Success 200 {object} EmergencyStopStatus
+// @Router /api/v1/admin/emergency-stop [get]
+func (h *EmergencyStopHandler) GetStatus(c *gin.Context) {
+	stop := h.service.Status()
+	c.JSON(http.StatusOK, EmergencyStopStatus{Engaged: stop != nil, Stop: stop})
+}
+
+// Trip godoc
+// @Summary Trip the emergency stop
+// @Description Tells every agent to kill its running commands, cancels every active execution and pauses every active schedule. No execution or ad-hoc task starts until the stop is re-armed. Tripping an engaged stop enforces it again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body EmergencyStopRequest false "Reason"
+// @Success 200 {object} entity.EmergencyStop
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/emergency-stop [post]
+func (h *EmergencyStopHandler) Trip(c *gin.Context) {
+	var req EmergencyStopRequest
+	// The body is optional: stopping must not fail on a malformed reason
+	_ = c.ShouldBindJSON(&req)
+
+	userID, _ := c.Get("user_id")
+	userIDStr, _ := userID.(string)
+	stop, err := h.service.Trip(c.Request.Context(), req.Reason, userIDStr)
+	if err != nil {
+		// The stop is engaged even when it could not be fully enforced
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "stop": stop})
+		return
+	}
+	c.JSON(http.StatusOK, stop)
+}
+
+// Rearm godoc
+// @Summary Re-arm the emergency stop
+// @Description Lets executions and ad-hoc tasks start again. Schedules paused by the stop stay paused.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} entity.EmergencyStop
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/emergency-stop/rearm [post]
+func (h *EmergencyStopHandler) Rearm(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	userIDStr, _ := userID.(string)
+	stop, err := h.service.Rearm(c.Request.Context(), userIDStr)
+	if err != nil {
+		if errors.Is(err, application.ErrEmergencyStopNotEngaged) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stop)
+}
+
+// ListHistory godoc
+// @Summary List the emergency stop trips
+// @Description List the latest trips of the emergency stop, newest first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Maximum trips (default 50, max 500)"
+// @Success 200 {array} entity.EmergencyStop
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/emergency-stop/history [get]
+func (h *EmergencyStopHandler) ListHistory(c *gin.Context) {
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	stops, err := h.service.History(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Return empty array instead of null
+	if stops == nil {
+		stops = []*entity.EmergencyStop{}
+	}
+	c.JSON(http.StatusOK, stops)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockEmergencyStopRepoForHandler implements repository.EmergencyStopRepository for tests
+type mockEmergencyStopRepoForHandler struct {
+	stops []*entity.EmergencyStop
+	err   error
+}
+
+func (m *mockEmergencyStopRepoForHandler) Save(ctx context.Context, stop *entity.EmergencyStop) error {
+	if m.err != nil {
+		return m.err
+	}
+	saved := *stop
+	for i, existing := range m.stops {
+		if existing.ID == stop.ID {
+			m.stops[i] = &saved
+			return nil
+		}
+	}
+	m.stops = append(m.stops, &saved)
+	return nil
+}
+
+func (m *mockEmergencyStopRepoForHandler) FindEngaged(ctx context.Context) (*entity.EmergencyStop, error) {
+	return nil, nil
+}
+
+func (m *mockEmergencyStopRepoForHandler) FindRecent(ctx context.Context, limit int) ([]*entity.EmergencyStop, error) {
+	return m.stops, m.err
+}
+
+func TestEmergencyStopHandler_Routes(t *testing.T) {
+	repo := &mockEmergencyStopRepoForHandler{}
+	handler := NewEmergencyStopHandler(application.NewEmergencyStopService(repo, nil, nil, nil, nil))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "admin-1")
+		c.Next()
+	})
+	router.GET("/admin/emergency-stop", handler.GetStatus)
+	router.POST("/admin/emergency-stop", handler.Trip)
+	router.POST("/admin/emergency-stop/rearm", handler.Rearm)
+	router.GET("/admin/emergency-stop/history", handler.ListHistory)
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/admin/emergency-stop/rearm", nil)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 when not engaged, got %d", w.Code)
+	}
+
+	w = do("POST", "/admin/emergency-stop", []byte(`{"reason":"rogue command"}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var stop entity.EmergencyStop
+	if err := json.Unmarshal(w.Body.Bytes(), &stop); err != nil || stop.Reason != "rogue command" || stop.TrippedBy != "admin-1" {
+		t.Errorf("Unexpected trip %+v (%v)", stop, err)
+	}
+
+	w = do("GET", "/admin/emergency-stop", nil)
+	var status EmergencyStopStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil || !status.Engaged || status.Stop == nil || status.Stop.ID != stop.ID {
+		t.Errorf("Unexpected status %s", w.Body.String())
+	}
+
+	w = do("POST", "/admin/emergency-stop/rearm", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &stop); err != nil || stop.RearmedBy != "admin-1" || stop.RearmedAt == nil {
+		t.Errorf("Unexpected re-armed trip %+v (%v)", stop, err)
+	}
+
+	w = do("GET", "/admin/emergency-stop", nil)
+	if w.Body.String() != `{"engaged":false}` {
+		t.Errorf("Expected the stop to be re-armed, got %s", w.Body.String())
+	}
+
+	w = do("GET", "/admin/emergency-stop/history?limit=10", nil)
+	var history []entity.EmergencyStop
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil || len(history) != 1 {
+		t.Errorf("Expected one trip in history, got %s", w.Body.String())
+	}
+}
+
+func TestEmergencyStopHandler_TripNotSaved(t *testing.T) {
+	repo := &mockEmergencyStopRepoForHandler{err: errors.New("database is locked")}
+	handler := NewEmergencyStopHandler(application.NewEmergencyStopService(repo, nil, nil, nil, nil))
+
+	router := gin.New()
+	router.POST("/admin/emergency-stop", handler.Trip)
+	router.GET("/admin/emergency-stop/history", handler.ListHistory)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/emergency-stop", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected status 500, got %d", w.Code)
+	}
+	var body struct {
+		Error string                `json:"error"`
+		Stop  *entity.EmergencyStop `json:"stop"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == "" || body.Stop == nil || !body.Stop.Engaged() {
+		t.Errorf("Expected the engaged trip with the error, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/emergency-stop/history", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestExecutionHandler_StartExecution_EmergencyStop(t *testing.T) {
+	svc := application.NewExecutionService(newMockResultRepo(), newMockScenarioRepo(), newMockTechniqueRepo(), newMockAgentRepo(),
+		service.NewAttackOrchestrator(newMockAgentRepo(), newMockTechniqueRepo(), service.NewTechniqueValidator(), nil), service.NewScoreCalculator())
+	stop := application.NewEmergencyStopService(&mockEmergencyStopRepoForHandler{}, nil, nil, nil, nil)
+	svc.SetEmergencyStop(stop)
+	if _, err := stop.Trip(context.Background(), "drill", "admin-1"); err != nil {
+		t.Fatalf("Trip failed: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/executions", NewExecutionHandler(svc).StartExecution)
+
+	jsonBody, _ := json.Marshal(StartExecutionRequest{ScenarioID: "s1", AgentPaws: []string{"paw1"}})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/executions", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusLocked {
+		t.Errorf("Expected status 423, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventStreamKeepAlive is the interval of the comments keeping idle streams
+	// open through proxies
+	eventStreamKeepAlive = 15 * time.Second
+
+	// eventStreamRetry is the reconnection delay suggested to clients, in milliseconds
+	eventStreamRetry = 3000
+
+	// eventStreamResync is sent to a resuming client when some of the events
+	// it missed are no longer kept, so it reloads its state
+	eventStreamResync = "resync"
+)
+
+// EventStreamHandler streams the dashboard events as Server-Sent Events, for
+// environments where WebSockets are blocked
+type EventStreamHandler struct {
+	hub           *websocket.Hub
+	notifications *application.NotificationService
+	keepAlive     time.Duration
+	logger        *zap.Logger
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEventStreamHandler creates a handler streaming the dashboard events of hub
+func NewEventStreamHandler(hub *websocket.Hub, logger *zap.Logger) *EventStreamHandler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &EventStreamHandler{hub: hub, keepAlive: eventStreamKeepAlive, logger: logger, done: make(chan struct{})}
+}
+
+// Close ends the open streams, so they do not hold a graceful shutdown.
+// Clients reconnect to another server or once it is back.
+func (h *EventStreamHandler) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// SetNotificationService sends streams the unread notification count of their user as they open
+func (h *EventStreamHandler) SetNotificationService(svc *application.NotificationService) {
+	h.notifications = svc
+}
+
+// Stream godoc
+// @Summary Stream dashboard events
+// @Description Server-Sent Events fallback of the dashboard WebSocket, emitting the same event types. Clients resuming after a disconnection send the Last-Event-ID header and receive the events they missed, or a resync event when some are no longer kept.
+// @Tags events
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Success 200
+// @Failure 400 {object} gin.H
+// @Router /api/v1/events/stream [get]
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	var lastEventID uint64
+	if header := c.GetHeader("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+			return
+		}
+		lastEventID = id
+	}
+
+	userID := c.GetString("user_id")
+	client := websocket.NewStreamClient(h.hub, userID, c.GetString("role"), h.logger)
+	replay, complete := h.hub.SubscribeDashboard(client, lastEventID)
+	defer h.hub.Unregister(client)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disables response buffering in nginx
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", eventStreamRetry)
+	if !complete {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", eventStreamResync)
+	}
+	for _, message := range replay {
+		writeStreamEvent(c.Writer, message)
+	}
+	h.writeUnreadCount(c, userID)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(h.keepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-h.done:
+			return
+		case message, ok := <-client.Messages():
+			if !ok {
+				return // Dropped by the hub as too slow
+			}
+			writeStreamEvent(c.Writer, message)
+		case <-keepAlive.C:
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeUnreadCount sends the unread notification count of the user of a stream
+func (h *EventStreamHandler) writeUnreadCount(c *gin.Context, userID string) {
+	if h.notifications == nil || userID == "" {
+		return
+	}
+	unread, err := h.notifications.GetUnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.Warn("Failed to count unread notifications", zap.Error(err), zap.String("user_id", userID))
+		return
+	}
+	data, _ := json.Marshal(websocket.NotificationCountEvent{Unread: unread})
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", websocket.EventNotificationCount, data)
+}
+
+// writeStreamEvent writes a dashboard message as an event named after its
+// type, with its sequence number as the event ID
+func writeStreamEvent(w http.ResponseWriter, message []byte) {
+	var msg websocket.Message
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+	if msg.ID > 0 {
+		fmt.Fprintf(w, "id: %d\n", msg.ID)
+	}
+	data := []byte(msg.Payload)
+	if len(data) == 0 {
+		data = []byte("null")
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Type, data)
+}
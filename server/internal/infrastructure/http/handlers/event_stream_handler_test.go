@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// streamEvent is an event read from a Server-Sent Events stream
+type streamEvent struct {
+	id, event, data string
+}
+
+// readStreamEvents reads the events of a stream, skipping comments and the retry field
+func readStreamEvents(body *bufio.Reader, events chan<- streamEvent) {
+	defer close(events)
+	var current streamEvent
+	for {
+		line, err := body.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if current.event != "" {
+				events <- current
+			}
+			current = streamEvent{}
+		case strings.HasPrefix(line, "id: "):
+			current.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			current.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func nextStreamEvent(t *testing.T, events <-chan streamEvent) streamEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatal("stream closed")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+	}
+	return streamEvent{}
+}
+
+func newEventStreamServer(t *testing.T, handler *EventStreamHandler, role string) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/events/stream", func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("role", role)
+	}, handler.Stream)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func openEventStream(t *testing.T, ctx context.Context, url, lastEventID string) (<-chan streamEvent, *http.Response) {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/v1/events/stream", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	events := make(chan streamEvent, 16)
+	if resp.StatusCode == http.StatusOK {
+		go readStreamEvents(bufio.NewReader(resp.Body), events)
+	}
+	return events, resp
+}
+
+func TestEventStreamHandler_Stream(t *testing.T) {
+	hub := websocket.NewHub(zap.NewNop())
+	go hub.Run()
+	handler := NewEventStreamHandler(hub, zap.NewNop())
+	server := newEventStreamServer(t, handler, "analyst")
+
+	// Headers arrive once the stream is subscribed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, resp := openEventStream(t, ctx, server.URL, "")
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	hub.PublishDashboardEvent(websocket.EventAgentStatus, map[string]string{"paw": "agent-1"}, nil)
+	hub.PublishDashboardEvent("hidden", nil, func(c *websocket.Client) bool { return false })
+	hub.PublishDashboardEvent(websocket.EventExecutionStatus, map[string]string{"execution_id": "exec-1"}, func(c *websocket.Client) bool {
+		return c.UserID() == "user-1" && c.Role() == "analyst"
+	})
+
+	first := nextStreamEvent(t, events)
+	if first.id != "1" || first.event != websocket.EventAgentStatus || first.data != `{"paw":"agent-1"}` {
+		t.Errorf("first event = %+v", first)
+	}
+	second := nextStreamEvent(t, events)
+	if second.id != "3" || second.event != websocket.EventExecutionStatus {
+		t.Errorf("second event = %+v, want the event not filtered out", second)
+	}
+
+	// Resuming replays the missed events the user may see
+	resumed, _ := openEventStream(t, ctx, server.URL, "1")
+	if event := nextStreamEvent(t, resumed); event.id != "3" {
+		t.Errorf("replayed event = %+v, want id 3", event)
+	}
+
+	// An ID from before a restart asks the client to reload its state
+	resync, _ := openEventStream(t, ctx, server.URL, "42")
+	if event := nextStreamEvent(t, resync); event.event != eventStreamResync {
+		t.Errorf("event = %+v, want resync", event)
+	}
+
+	// Closing the handler ends the open streams
+	handler.Close()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("unexpected event after close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("stream not ended by Close")
+	}
+}
+
+func TestEventStreamHandler_InvalidLastEventID(t *testing.T) {
+	hub := websocket.NewHub(zap.NewNop())
+	go hub.Run()
+	server := newEventStreamServer(t, NewEventStreamHandler(hub, zap.NewNop()), "admin")
+
+	_, resp := openEventStream(t, context.Background(), server.URL, "abc")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EvidenceHandler serves the files operators attach to results
+type EvidenceHandler struct {
+	service *application.EvidenceService
+}
+
+// NewEvidenceHandler creates a new evidence handler
+func NewEvidenceHandler(service *application.EvidenceService) *EvidenceHandler {
+	return &EvidenceHandler{service: service}
+}
+
+// AttachEvidence godoc
+// @Summary Attach evidence to a result
+// @Description Upload a screenshot, packet capture, PDF, text file or archive as proof of the outcome of a result. The type is detected from the content; an optional sha256 field is checked against the upload.
+// @Tags results
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Result ID"
+// @Param file formData file true "Evidence file"
+// @Param description formData string false "What the file shows"
+// @Param sha256 formData string false "Hex SHA-256 of the file"
+// @Success 201 {object} entity.Evidence
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Failure 415 {object} gin.H
+// @Router /api/v1/results/{id}/evidence [post]
+func (h *EvidenceHandler) AttachEvidence(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required"})
+		return
+	}
+	if header.Size > h.service.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("evidence is larger than %d bytes", h.service.MaxSize())})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, h.service.MaxSize()+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+
+	evidence, err := h.service.Attach(c.Request.Context(), c.Param("id"), header.Filename,
+		c.PostForm("description"), c.PostForm("sha256"), content, c.GetString("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrResultNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrEvidenceTooLarge), errors.Is(err, application.ErrEvidenceQuotaExceeded):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrEvidenceType):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrInvalidEvidence):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrReviewLocked):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store evidence"})
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, evidence)
+}
+
+// ListEvidence godoc
+// @Summary List the evidence of a result
+// @Description List the files operators attached to a result, oldest first, without their content
+// @Tags results
+// @Produce json
+// @Param id path string true "Result ID"
+// @Success 200 {array} entity.Evidence
+// @Router /api/v1/results/{id}/evidence [get]
+func (h *EvidenceHandler) ListEvidence(c *gin.Context) {
+	files, err := h.service.ListByResult(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list evidence"})
+		return
+	}
+
+	// Return empty array instead of null
+	if files == nil {
+		files = []*entity.Evidence{}
+	}
+	c.JSON(http.StatusOK, files)
+}
+
+// DownloadEvidence godoc
+// @Summary Download evidence
+// @Description Download a file attached to a result
+// @Tags results
+// @Produce octet-stream
+// @Param id path string true "Result ID"
+// @Param evidenceId path string true "Evidence ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} gin.H
+// @Router /api/v1/results/{id}/evidence/{evidenceId} [get]
+func (h *EvidenceHandler) DownloadEvidence(c *gin.Context) {
+	evidence, content, err := h.service.Get(c.Request.Context(), c.Param("id"), c.Param("evidenceId"))
+	if err != nil {
+		if errors.Is(err, application.ErrEvidenceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load evidence"})
+		return
+	}
+
+	// Always an attachment: uploaded files are untrusted content
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", evidence.Name))
+	c.Header("X-Evidence-SHA256", evidence.SHA256)
+	c.Data(http.StatusOK, "application/octet-stream", content)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockEvidenceRepo implements repository.EvidenceRepository for tests
+type mockEvidenceRepo struct {
+	files    []*entity.Evidence
+	contents map[string][]byte
+}
+
+func (m *mockEvidenceRepo) Create(ctx context.Context, evidence *entity.Evidence, content []byte) error {
+	m.files = append(m.files, evidence)
+	m.contents[evidence.ID] = content
+	return nil
+}
+
+func (m *mockEvidenceRepo) FindByID(ctx context.Context, id string) (*entity.Evidence, error) {
+	for _, evidence := range m.files {
+		if evidence.ID == id {
+			return evidence, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockEvidenceRepo) FindByResult(ctx context.Context, resultID string) ([]*entity.Evidence, error) {
+	var files []*entity.Evidence
+	for _, evidence := range m.files {
+		if evidence.ResultID == resultID {
+			files = append(files, evidence)
+		}
+	}
+	return files, nil
+}
+
+func (m *mockEvidenceRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	return m.contents[id], nil
+}
+
+func (m *mockEvidenceRepo) TotalSizeByResult(ctx context.Context, resultID string) (int64, error) {
+	var total int64
+	for _, evidence := range m.files {
+		if evidence.ResultID == resultID {
+			total += evidence.Size
+		}
+	}
+	return total, nil
+}
+
+func setupEvidenceRouter() *gin.Engine {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{{ID: "result-1", ExecutionID: "exec-1"}}
+	config := application.DefaultEvidenceConfig()
+	config.MaxSize = 32
+	svc := application.NewEvidenceService(&mockEvidenceRepo{contents: make(map[string][]byte)}, resultRepo, config)
+	handler := NewEvidenceHandler(svc)
+
+	router := gin.New()
+	results := router.Group("/api/v1/results", func(c *gin.Context) { c.Set("user_id", "operator-1") })
+	results.POST("/:id/evidence", handler.AttachEvidence)
+	results.GET("/:id/evidence", handler.ListEvidence)
+	results.GET("/:id/evidence/:evidenceId", handler.DownloadEvidence)
+	return router
+}
+
+func attachEvidenceRequest(fields map[string]string, name, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		_ = writer.WriteField(key, value)
+	}
+	if name != "" {
+		part, _ := writer.CreateFormFile("file", name)
+		_, _ = part.Write([]byte(content))
+	}
+	_ = writer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/results/result-1/evidence", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestEvidenceHandler_AttachEvidence(t *testing.T) {
+	router := setupEvidenceRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, attachEvidenceRequest(map[string]string{"description": "EDR console"}, "alert.png", "\x89PNG\r\n\x1a\n"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var evidence entity.Evidence
+	_ = json.Unmarshal(w.Body.Bytes(), &evidence)
+	if evidence.Name != "alert.png" || evidence.ContentType != "image/png" || evidence.UploadedBy != "operator-1" ||
+		evidence.Description != "EDR console" || evidence.SHA256 == "" {
+		t.Errorf("Unexpected evidence: %s", w.Body.String())
+	}
+
+	tests := []struct {
+		name    string
+		fields  map[string]string
+		file    string
+		content string
+		status  int
+	}{
+		{"no file", nil, "", "", http.StatusBadRequest},
+		{"checksum mismatch", map[string]string{"sha256": "00"}, "note.txt", "blocked", http.StatusBadRequest},
+		{"too large", nil, "big.txt", string(make([]byte, 33)), http.StatusRequestEntityTooLarge},
+		{"type", nil, "tool.exe", "MZ\x90\x00\x03\x00\x00\x00", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, attachEvidenceRequest(tt.fields, tt.file, tt.content))
+		if w.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.status, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestEvidenceHandler_ListAndDownload(t *testing.T) {
+	router := setupEvidenceRouter()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/results/result-1/evidence", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "[]" {
+		t.Fatalf("Expected an empty array, got %d (%s)", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, attachEvidenceRequest(nil, "timeline.txt", "10:42 alert raised"))
+	var evidence entity.Evidence
+	_ = json.Unmarshal(w.Body.Bytes(), &evidence)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var files []entity.Evidence
+	_ = json.Unmarshal(w.Body.Bytes(), &files)
+	if len(files) != 1 || files[0].ID != evidence.ID {
+		t.Errorf("Unexpected evidence list: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/results/result-1/evidence/"+evidence.ID, nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "10:42 alert raised" ||
+		w.Header().Get("X-Evidence-SHA256") != evidence.SHA256 ||
+		w.Header().Get("Content-Disposition") != `attachment; filename="timeline.txt"` {
+		t.Errorf("Unexpected download: %d %v (%s)", w.Code, w.Header(), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodGet, "/api/v1/results/result-2/evidence/"+evidence.ID, nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another result, got %d", w.Code)
+	}
+}
@@ -3,20 +3,32 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"autostrike/internal/application"
 	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/telemetry"
 	"autostrike/internal/infrastructure/websocket"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ExecutionHandler handles execution-related HTTP requests
 type ExecutionHandler struct {
-	service *application.ExecutionService
-	hub     *websocket.Hub
+	service   *application.ExecutionService
+	hub       *websocket.Hub
+	activity  *application.ActivityMonitor
+	selectors *application.AgentSelectorService
+	jobs      *application.JobService
 }
 
 // NewExecutionHandler creates a new execution handler
@@ -29,6 +41,21 @@ func NewExecutionHandlerWithHub(service *application.ExecutionService, hub *webs
 	return &ExecutionHandler{service: service, hub: hub}
 }
 
+// SetActivityMonitor enables off-hours execution detection
+func (h *ExecutionHandler) SetActivityMonitor(monitor *application.ActivityMonitor) {
+	h.activity = monitor
+}
+
+// SetAgentSelectorService enables launching executions on a saved agent selector
+func (h *ExecutionHandler) SetAgentSelectorService(selectors *application.AgentSelectorService) {
+	h.selectors = selectors
+}
+
+// SetJobService lets exports run as background jobs with async=true
+func (h *ExecutionHandler) SetJobService(jobs *application.JobService) {
+	h.jobs = jobs
+}
+
 // broadcastExecutionEvent sends an execution event to all connected clients
 func (h *ExecutionHandler) broadcastExecutionEvent(eventType string, executionID string, data interface{}) {
 	if h.hub == nil {
@@ -58,15 +85,41 @@ func (h *ExecutionHandler) RegisterRoutes(r *gin.RouterGroup) {
 		executions.GET("", h.ListExecutions)
 		executions.GET("/:id", h.GetExecution)
 		executions.GET("/:id/results", h.GetResults)
+		executions.GET("/:id/facts", h.GetFacts)
+		executions.GET("/:id/export", h.ExportExecution)
 		executions.POST("", h.StartExecution)
 		executions.POST("/:id/complete", h.CompleteExecution)
 		executions.POST("/:id/stop", h.StopExecution)
 	}
+	r.GET("/results/:id/output", h.GetResultOutput)
 }
 
-// ListExecutions returns recent executions
+// ListExecutions godoc
+// @Summary List recent executions
+// @Description List the 50 most recent executions, with the counts of their results by status in result_summary. include=results also returns the results of each execution, outputs cut to a preview as in GET /executions/{id}/results.
+// @Tags executions
+// @Produce json
+// @Param include query string false "Related data to embed: results"
+// @Success 200 {array} entity.Execution
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/executions [get]
 func (h *ExecutionHandler) ListExecutions(c *gin.Context) {
-	executions, err := h.service.GetRecentExecutions(c.Request.Context(), 50)
+	includeResults := false
+	for _, include := range c.QueryArray("include") {
+		for _, name := range strings.Split(include, ",") {
+			switch strings.TrimSpace(name) {
+			case "results":
+				includeResults = true
+			case "":
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"error": "include must be results"})
+				return
+			}
+		}
+	}
+
+	executions, err := h.service.ListExecutions(c.Request.Context(), 50, includeResults)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -79,7 +132,15 @@ func (h *ExecutionHandler) ListExecutions(c *gin.Context) {
 	c.JSON(http.StatusOK, executions)
 }
 
-// GetExecution returns a specific execution
+// GetExecution godoc
+// @Summary Get an execution
+// @Description Get an execution with its score and progress: steps done out of the total, current phase and an ETA estimated from the median durations of past runs
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} entity.Execution
+// @Failure 404 {object} gin.H
+// @Router /api/v1/executions/{id} [get]
 func (h *ExecutionHandler) GetExecution(c *gin.Context) {
 	id := c.Param("id")
 
@@ -92,7 +153,15 @@ func (h *ExecutionHandler) GetExecution(c *gin.Context) {
 	c.JSON(http.StatusOK, execution)
 }
 
-// GetResults returns results for an execution
+// GetResults godoc
+// @Summary List the results of an execution
+// @Description List the technique results of an execution. Outputs are cut to a preview; output_size gives the size of a shortened output, read with GET /results/{id}/output
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {array} entity.ExecutionResult
+// @Failure 500 {object} gin.H
+// @Router /api/v1/executions/{id}/results [get]
 func (h *ExecutionHandler) GetResults(c *gin.Context) {
 	id := c.Param("id")
 
@@ -106,17 +175,180 @@ func (h *ExecutionHandler) GetResults(c *gin.Context) {
 	if results == nil {
 		results = []*entity.ExecutionResult{}
 	}
-	c.JSON(http.StatusOK, results)
+	c.JSON(http.StatusOK, h.service.ResultPreviews(results))
+}
+
+// GetResultOutput godoc
+// @Summary Read the output of a result
+// @Description Returns a page of the full output of a result, loaded from blob storage when it is kept there. Pages end on a character boundary; next_offset is the offset of the following page and is absent on the last one.
+// @Tags executions
+// @Produce json
+// @Param id path string true "Result ID"
+// @Param offset query int false "Byte offset in the output (default 0)"
+// @Param limit query int false "Bytes to return (default 65536, max 1048576)"
+// @Success 200 {object} application.ResultOutputPage
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/results/{id}/output [get]
+func (h *ExecutionHandler) GetResultOutput(c *gin.Context) {
+	var offset, limit int
+	if o := c.Query("offset"); o != "" {
+		parsed, err := parseInt(o)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a number"})
+			return
+		}
+		offset = parsed
+	}
+	if l := c.Query("limit"); l != "" {
+		parsed, err := parseInt(l)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+			return
+		}
+		limit = parsed
+	}
+
+	page, err := h.service.GetResultOutput(c.Request.Context(), c.Param("id"), offset, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrResultNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "result not found"})
+		case errors.Is(err, application.ErrInvalidOutputRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// GetFacts godoc
+// @Summary List the facts of an execution
+// @Description Returns the facts extracted from technique output during an execution, oldest first
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {array} entity.Fact
+// @Failure 404 {object} gin.H
+// @Router /api/v1/executions/{id}/facts [get]
+func (h *ExecutionHandler) GetFacts(c *gin.Context) {
+	facts, err := h.service.GetExecutionFacts(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, application.ErrExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Return empty array instead of null
+	if facts == nil {
+		facts = []*entity.Fact{}
+	}
+	c.JSON(http.StatusOK, facts)
+}
+
+// GetSnapshot godoc
+// @Summary Get the environment snapshot of an execution
+// @Description Returns the server settings, scenario, techniques and agent inventory captured when the execution was launched. The scenario and techniques modified or deleted since are flagged.
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} entity.ExecutionSnapshot
+// @Failure 404 {object} gin.H
+// @Router /api/v1/executions/{id}/snapshot [get]
+func (h *ExecutionHandler) GetSnapshot(c *gin.Context) {
+	snapshot, err := h.service.GetExecutionSnapshot(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrExecutionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		case errors.Is(err, application.ErrSnapshotNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "the execution has no snapshot"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// ExportExecution godoc
+// @Summary Export an execution with its results
+// @Description Returns the execution and its results; verbosity=full adds technique name, tactic, platforms and ATT&CK URL to each result
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param verbosity query string false "minimal (default) or full"
+// @Param async query bool false "Export in a background job, the export being its result"
+// @Success 200 {object} application.ExecutionExport
+// @Success 202 {object} entity.Job
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/executions/{id}/export [get]
+func (h *ExecutionHandler) ExportExecution(c *gin.Context) {
+	verbosity, err := application.ParseResultVerbosity(c.Query("verbosity"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id := c.Param("id")
+	if wantsAsync(c, h.jobs) {
+		if _, err := h.service.GetExecution(c.Request.Context(), id); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+			return
+		}
+		submitJob(c, h.jobs, entity.JobExecutionExport, func(ctx context.Context, progress *application.JobProgress) (any, error) {
+			progress.Report(0, 0, "Exporting execution "+id)
+			return h.service.ExportExecution(ctx, id, verbosity)
+		})
+		return
+	}
+
+	export, err := h.service.ExportExecution(c.Request.Context(), id, verbosity)
+	if err != nil {
+		if errors.Is(err, application.ErrExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=execution-"+export.Execution.ID+".json")
+	c.JSON(http.StatusOK, export)
 }
 
 // StartExecutionRequest represents the request body for starting an execution
 type StartExecutionRequest struct {
-	ScenarioID string   `json:"scenario_id" binding:"required"`
-	AgentPaws  []string `json:"agent_paws" binding:"required"`
-	SafeMode   bool     `json:"safe_mode"`
+	ScenarioID      string   `json:"scenario_id" binding:"required"`
+	AgentPaws       []string `json:"agent_paws"`
+	AgentSelectorID string   `json:"agent_selector_id"` // Targets the online agents matching a saved selector
+	SafeMode        bool     `json:"safe_mode"`
+	// Scoring profile the execution is scored with, the default one when empty
+	ScoringProfileID string `json:"scoring_profile_id"`
 }
 
-// StartExecution starts a new scenario execution
+// StartExecution godoc
+// @Summary Start an execution
+// @Description Run a scenario on the listed agents, or on the online agents matching a saved selector
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param request body StartExecutionRequest true "Execution"
+// @Success 201 {object} entity.Execution
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 423 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/executions [post]
 func (h *ExecutionHandler) StartExecution(c *gin.Context) {
 	var req StartExecutionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -124,60 +356,187 @@ func (h *ExecutionHandler) StartExecution(c *gin.Context) {
 		return
 	}
 
+	if req.AgentSelectorID != "" {
+		if !h.resolveAgentSelector(c, &req) {
+			return
+		}
+	}
+
 	// Validate that at least one agent is selected
 	if len(req.AgentPaws) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one agent must be selected"})
 		return
 	}
 
-	result, err := h.service.StartExecution(c.Request.Context(), req.ScenarioID, req.AgentPaws, req.SafeMode)
+	userID, _ := c.Get("user_id")
+	userIDStr, _ := userID.(string)
+	launcher := application.ExecutionLauncher{
+		UserID:    userIDStr,
+		APIKeyID:  c.GetString("api_key_id"),
+		Workspace: c.GetString("workspace"),
+	}
+	result, err := h.service.StartExecutionWithProfile(
+		c.Request.Context(), req.ScenarioID, req.AgentPaws, req.SafeMode, req.ScoringProfileID, launcher)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		var quotaErr *application.QuotaExceededError
+		switch {
+		case errors.As(err, &quotaErr):
+			writeQuotaExceeded(c, quotaErr)
+		case errors.Is(err, application.ErrScoringProfileNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrEmergencyStopEngaged):
+			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
+	if h.activity != nil {
+		h.activity.RecordExecutionStarted(c.Request.Context(), userIDStr, result.Execution)
+	}
+
 	// Broadcast execution started event to all connected clients
 	h.broadcastExecutionEvent("execution_started", result.Execution.ID, result.Execution)
 
 	// Dispatch tasks to agents via WebSocket
-	h.dispatchTasksToAgents(result.Tasks)
+	h.dispatchTasksToAgents(c.Request.Context(), result.Tasks)
 
 	c.JSON(http.StatusCreated, result.Execution)
 }
 
+// writeQuotaExceeded answers 429 with the limit and when it resets, in
+// seconds in Retry-After and as a Unix time in X-RateLimit-Reset
+func writeQuotaExceeded(c *gin.Context, err *application.QuotaExceededError) {
+	retryAfter := int(math.Ceil(time.Until(err.ResetAt).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.Header("X-RateLimit-Limit", strconv.Itoa(err.Limit))
+	c.Header("X-RateLimit-Remaining", "0")
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(err.ResetAt.Unix(), 10))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       err.Error(),
+		"scope":       err.Scope,
+		"retry_after": retryAfter,
+	})
+}
+
+// resolveAgentSelector replaces the requested agents with the online agents matching
+// the saved selector. Writes the error response and returns false on failure.
+func (h *ExecutionHandler) resolveAgentSelector(c *gin.Context, req *StartExecutionRequest) bool {
+	if h.selectors == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "agent selectors are not available"})
+		return false
+	}
+	if len(req.AgentPaws) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "agent_paws and agent_selector_id are mutually exclusive"})
+		return false
+	}
+
+	paws, err := h.selectors.ResolveAgentPaws(c.Request.Context(), req.AgentSelectorID)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrAgentSelectorNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrNoMatchingAgents):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve agent selector"})
+		}
+		return false
+	}
+
+	req.AgentPaws = paws
+	return true
+}
+
+// reasonAgentUnavailable is why a task could not be sent to an agent that is
+// not connected; such tasks can be queued until the agent checks in
+const reasonAgentUnavailable = "agent disconnected or unavailable"
+
 // dispatchTasksToAgents sends task messages to the appropriate agents
-func (h *ExecutionHandler) dispatchTasksToAgents(tasks []application.TaskDispatchInfo) {
+func (h *ExecutionHandler) dispatchTasksToAgents(ctx context.Context, tasks []application.TaskDispatchInfo) {
 	if h.hub == nil {
 		return
 	}
 
+	failed := make(map[string]bool)
 	for _, task := range tasks {
-		taskMsg := map[string]interface{}{
-			"type": "task",
-			"payload": map[string]interface{}{
-				"id":           task.ResultID,
-				"technique_id": task.TechniqueID,
-				"command":      task.Command,
-				"executor":     task.Executor,
-				"timeout":      task.Timeout,
-				"cleanup":      task.Cleanup,
-			},
-		}
-
-		msgBytes, err := json.Marshal(taskMsg)
-		if err != nil {
-			// Mark result as failed if we can't serialize the message
-			h.markResultAsFailed(task.ResultID, "failed to serialize task message")
+		reason := sendTask(ctx, h.hub, task)
+		if reason == "" || h.queueTask(ctx, task, reason) {
 			continue
 		}
+		// Mark result as failed if the task could not be sent
+		h.markResultAsFailed(task.ResultID, reason)
+		failed[task.AgentPaw] = true
+	}
 
-		if !h.hub.SendToAgent(task.AgentPaw, msgBytes) {
-			// Mark result as failed if agent is disconnected or channel is full
-			h.markResultAsFailed(task.ResultID, "agent disconnected or unavailable")
+	// A failed result may decide the conditional phases of its agent
+	if h.service == nil {
+		return
+	}
+	for paw := range failed {
+		if ready := h.service.TakeReadyTasks(ctx, paw); len(ready) > 0 {
+			h.dispatchTasksToAgents(ctx, ready)
 		}
 	}
 }
 
+// queueTask queues a task its agent is not connected for, to be delivered when
+// the agent checks in. Returns false when the task cannot be queued.
+func (h *ExecutionHandler) queueTask(ctx context.Context, task application.TaskDispatchInfo, reason string) bool {
+	if h.service == nil || reason != reasonAgentUnavailable {
+		return false
+	}
+	return h.service.QueueTask(ctx, task) == nil
+}
+
+// sendTask sends a task message to its agent, in a span whose trace context
+// travels with the task so the agent can echo it back with the result. Returns
+// why the task could not be sent, or "" once it is queued for the agent.
+func sendTask(ctx context.Context, hub *websocket.Hub, task application.TaskDispatchInfo) string {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "websocket.dispatch",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("result.id", task.ResultID),
+			attribute.String("agent.paw", task.AgentPaw),
+			attribute.String("technique.id", task.TechniqueID),
+		),
+	)
+	defer span.End()
+
+	taskMsg := map[string]interface{}{
+		"type": "task",
+		"payload": map[string]interface{}{
+			"id":            task.ResultID,
+			"technique_id":  task.TechniqueID,
+			"command":       task.Command,
+			"executor":      task.Executor,
+			"timeout":       task.Timeout,
+			"cleanup":       task.Cleanup,
+			"limits":        task.Limits,
+			"payload":       task.Payload,
+			"collect":       task.Collect,
+			"trace_context": telemetry.Inject(ctx),
+		},
+	}
+
+	msgBytes, err := json.Marshal(taskMsg)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to serialize task message")
+		return "failed to serialize task message"
+	}
+
+	if !hub.SendToAgent(task.AgentPaw, msgBytes) {
+		// Agent is disconnected or its channel is full
+		span.SetStatus(codes.Error, reasonAgentUnavailable)
+		return reasonAgentUnavailable
+	}
+	return ""
+}
+
 // markResultAsFailed marks a result as failed when dispatch fails
 func (h *ExecutionHandler) markResultAsFailed(resultID, reason string) {
 	if h.service == nil {
@@ -188,7 +547,15 @@ func (h *ExecutionHandler) markResultAsFailed(resultID, reason string) {
 	_ = h.service.UpdateResultByID(context.Background(), resultID, entity.StatusFailed, reason, -1, "")
 }
 
-// CompleteExecution marks an execution as completed
+// CompleteExecution godoc
+// @Summary Complete an execution
+// @Description Mark an execution as completed and compute its score
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/executions/{id}/complete [post]
 func (h *ExecutionHandler) CompleteExecution(c *gin.Context) {
 	id := c.Param("id")
 
@@ -205,7 +572,17 @@ func (h *ExecutionHandler) CompleteExecution(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "completed"})
 }
 
-// StopExecution cancels a running execution
+// StopExecution godoc
+// @Summary Stop an execution
+// @Description Cancel a pending or running execution
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/executions/{id}/stop [post]
 func (h *ExecutionHandler) StopExecution(c *gin.Context) {
 	id := c.Param("id")
 
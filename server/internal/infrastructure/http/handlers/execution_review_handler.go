@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExecutionReviewHandler serves the purple-team review of executions
+type ExecutionReviewHandler struct {
+	service *application.ExecutionReviewService
+}
+
+// NewExecutionReviewHandler creates a new execution review handler
+func NewExecutionReviewHandler(service *application.ExecutionReviewService) *ExecutionReviewHandler {
+	return &ExecutionReviewHandler{service: service}
+}
+
+// ReviewerRequest names the reviewer of an execution
+type ReviewerRequest struct {
+	ReviewerID string `json:"reviewer_id"`
+}
+
+// SignOffRequest closes a review with optional notes
+type SignOffRequest struct {
+	Notes string `json:"notes"`
+}
+
+// ListReviews godoc
+// @Summary List execution reviews
+// @Description List the purple-team reviews, newest request first, without their snapshot
+// @Tags reviews
+// @Produce json
+// @Param status query string false "Filter by status (needs_review, in_review, signed_off)"
+// @Param reviewer query string false "Filter by reviewer user ID, or me"
+// @Param limit query int false "Limit (default: 100, max: 500)"
+// @Success 200 {array} entity.ExecutionReview
+// @Failure 400 {object} gin.H
+// @Router /api/v1/reviews [get]
+func (h *ExecutionReviewHandler) ListReviews(c *gin.Context) {
+	reviewer := c.Query("reviewer")
+	if reviewer == "me" {
+		reviewer = c.GetString("user_id")
+	}
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	reviews, err := h.service.List(c.Request.Context(), entity.ReviewStatus(c.Query("status")), reviewer, limit)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if reviews == nil {
+		reviews = []*entity.ExecutionReview{}
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// GetReview godoc
+// @Summary Get the review of an execution
+// @Description Get the review of an execution with the number of results and of results with a disposition
+// @Tags reviews
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} application.ReviewSummary
+// @Failure 404 {object} gin.H
+// @Router /api/v1/executions/{id}/review [get]
+func (h *ExecutionReviewHandler) GetReview(c *gin.Context) {
+	review, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// RequestReview godoc
+// @Summary Request the review of an execution
+// @Description Submit a completed, failed or cancelled execution for review (needs_review), optionally assigned to a reviewer allowed to triage results
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body ReviewerRequest false "Reviewer"
+// @Success 201 {object} application.ReviewSummary
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/executions/{id}/review [post]
+func (h *ExecutionReviewHandler) RequestReview(c *gin.Context) {
+	var req ReviewerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	review, err := h.service.Request(c.Request.Context(), c.Param("id"), req.ReviewerID, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, review)
+}
+
+// AssignReviewer godoc
+// @Summary Assign the reviewer of an execution
+// @Description Hand a review not signed off yet to another reviewer
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body ReviewerRequest true "Reviewer"
+// @Success 200 {object} application.ReviewSummary
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/executions/{id}/review/reviewer [put]
+func (h *ExecutionReviewHandler) AssignReviewer(c *gin.Context) {
+	var req ReviewerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.service.Assign(c.Request.Context(), c.Param("id"), req.ReviewerID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// StartReview godoc
+// @Summary Start the review of an execution
+// @Description Move a review to in_review. A review without reviewer is assigned to the caller; otherwise only its reviewer can start it.
+// @Tags reviews
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} application.ReviewSummary
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/executions/{id}/review/start [post]
+func (h *ExecutionReviewHandler) StartReview(c *gin.Context) {
+	review, err := h.service.Start(c.Request.Context(), c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// SignOffReview godoc
+// @Summary Sign off the review of an execution
+// @Description Approve a review in progress once every result has a triage verdict. The export of the execution is frozen and its annotations and evidence are locked.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body SignOffRequest false "Notes"
+// @Success 200 {object} application.ReviewSummary
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/executions/{id}/review/sign-off [post]
+func (h *ExecutionReviewHandler) SignOffReview(c *gin.Context) {
+	var req SignOffRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	review, err := h.service.SignOff(c.Request.Context(), c.Param("id"), c.GetString("user_id"), req.Notes)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// GetReviewSnapshot godoc
+// @Summary Get the signed-off snapshot of an execution
+// @Description Get the export of the execution frozen when its review was signed off, with full technique metadata, annotations and the review
+// @Tags reviews
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} application.ExecutionExport
+// @Failure 404 {object} gin.H
+// @Router /api/v1/executions/{id}/review/snapshot [get]
+func (h *ExecutionReviewHandler) GetReviewSnapshot(c *gin.Context) {
+	snapshot, err := h.service.Snapshot(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", snapshot)
+}
+
+// respondError maps review errors to HTTP statuses
+func (h *ExecutionReviewHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrReviewNotFound), errors.Is(err, application.ErrExecutionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidReview):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrNotReviewer):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrReviewTransition), errors.Is(err, application.ErrReviewLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update review"})
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockExecutionReviewRepo implements repository.ExecutionReviewRepository for tests
+type mockExecutionReviewRepo struct {
+	reviews map[string]*entity.ExecutionReview
+}
+
+func (m *mockExecutionReviewRepo) Get(ctx context.Context, executionID string) (*entity.ExecutionReview, error) {
+	review, ok := m.reviews[executionID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *review
+	return &copied, nil
+}
+
+func (m *mockExecutionReviewRepo) Save(ctx context.Context, review *entity.ExecutionReview) error {
+	copied := *review
+	m.reviews[review.ExecutionID] = &copied
+	return nil
+}
+
+func (m *mockExecutionReviewRepo) FindAll(ctx context.Context, status entity.ReviewStatus, reviewerID string, limit int) ([]*entity.ExecutionReview, error) {
+	var reviews []*entity.ExecutionReview
+	for _, review := range m.reviews {
+		if (status == "" || review.Status == status) && (reviewerID == "" || review.ReviewerID == reviewerID) {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews, nil
+}
+
+func setupExecutionReviewRouter(userID string) (*gin.Engine, *mockExecutionReviewRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionCompleted}
+	resultRepo.executions["exec-2"] = &entity.Execution{ID: "exec-2", Status: entity.ExecutionRunning}
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{{ID: "result-1", ExecutionID: "exec-1"}}
+	userRepo := newMockUserRepo()
+	userRepo.users["analyst"] = &entity.User{ID: "analyst", Role: entity.RoleAnalyst, IsActive: true}
+	userRepo.users["viewer"] = &entity.User{ID: "viewer", Role: entity.RoleViewer, IsActive: true}
+	annotations := application.NewResultAnnotationService(
+		&mockResultAnnotationRepo{annotations: make(map[string]*entity.ResultAnnotation)}, resultRepo, userRepo)
+
+	repo := &mockExecutionReviewRepo{reviews: make(map[string]*entity.ExecutionReview)}
+	handler := NewExecutionReviewHandler(application.NewExecutionReviewService(repo, resultRepo, userRepo, annotations, nil))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	router.GET("/api/v1/reviews", handler.ListReviews)
+	router.GET("/api/v1/executions/:id/review", handler.GetReview)
+	router.GET("/api/v1/executions/:id/review/snapshot", handler.GetReviewSnapshot)
+	router.POST("/api/v1/executions/:id/review", handler.RequestReview)
+	router.PUT("/api/v1/executions/:id/review/reviewer", handler.AssignReviewer)
+	router.POST("/api/v1/executions/:id/review/start", handler.StartReview)
+	router.POST("/api/v1/executions/:id/review/sign-off", handler.SignOffReview)
+	return router, repo
+}
+
+func TestExecutionReviewHandler_Workflow(t *testing.T) {
+	router, repo := setupExecutionReviewRouter("lead")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"not requested", http.MethodGet, "/api/v1/executions/exec-1/review", "", http.StatusNotFound},
+		{"unknown execution", http.MethodPost, "/api/v1/executions/missing/review", "", http.StatusNotFound},
+		{"running execution", http.MethodPost, "/api/v1/executions/exec-2/review", "", http.StatusBadRequest},
+		{"reviewer cannot triage", http.MethodPost, "/api/v1/executions/exec-1/review", `{"reviewer_id":"viewer"}`, http.StatusBadRequest},
+		{"requested", http.MethodPost, "/api/v1/executions/exec-1/review", `{"reviewer_id":"analyst"}`, http.StatusCreated},
+		{"requested twice", http.MethodPost, "/api/v1/executions/exec-1/review", "", http.StatusConflict},
+		{"sign-off before start", http.MethodPost, "/api/v1/executions/exec-1/review/sign-off", "", http.StatusConflict},
+		{"started by another user", http.MethodPost, "/api/v1/executions/exec-1/review/start", "", http.StatusForbidden},
+		{"no reviewer", http.MethodPut, "/api/v1/executions/exec-1/review/reviewer", `{}`, http.StatusBadRequest},
+		{"invalid JSON", http.MethodPut, "/api/v1/executions/exec-1/review/reviewer", `{`, http.StatusBadRequest},
+		{"not signed off", http.MethodGet, "/api/v1/executions/exec-1/review/snapshot", "", http.StatusNotFound},
+		{"unknown status", http.MethodGet, "/api/v1/reviews?status=closed", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if review := repo.reviews["exec-1"]; review == nil || review.Status != entity.ReviewNeeded ||
+		review.ReviewerID != "analyst" || review.RequestedBy != "lead" {
+		t.Errorf("Expected the review requested, got %+v", review)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/executions/exec-1/review", nil))
+	var summary application.ReviewSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil || w.Code != http.StatusOK ||
+		summary.ExecutionReview == nil || summary.Results != 1 || summary.Dispositioned != 0 {
+		t.Errorf("Unexpected review: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/reviews?status=needs_review&reviewer=analyst", nil))
+	var reviews []entity.ExecutionReview
+	if err := json.Unmarshal(w.Body.Bytes(), &reviews); err != nil || len(reviews) != 1 {
+		t.Errorf("Expected the review listed, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestExecutionReviewHandler_StartAndSignOff(t *testing.T) {
+	router, repo := setupExecutionReviewRouter("analyst")
+	repo.reviews["exec-1"] = &entity.ExecutionReview{ExecutionID: "exec-1", Status: entity.ReviewNeeded}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/executions/exec-1/review/start", nil))
+	if w.Code != http.StatusOK || repo.reviews["exec-1"].ReviewerID != "analyst" {
+		t.Fatalf("Expected the review started by the caller, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// The result has no disposition yet
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/executions/exec-1/review/sign-off", strings.NewReader(`{"notes":"ok"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A signed-off review serves its snapshot and can no longer change
+	repo.reviews["exec-1"].Status = entity.ReviewSignedOff
+	repo.reviews["exec-1"].Snapshot = []byte(`{"execution":{"id":"exec-1"}}`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/executions/exec-1/review/snapshot", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"execution":{"id":"exec-1"}}` {
+		t.Errorf("Unexpected snapshot: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/executions/exec-1/review/reviewer", strings.NewReader(`{"reviewer_id":"analyst"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -155,6 +157,53 @@ func TestAgentHandler_ListAgents(t *testing.T) {
 	}
 }
 
+func TestAgentHandler_ListAgents_InventoryFilters(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["win"] = &entity.Agent{Paw: "win", Platform: "windows", Status: entity.AgentOnline, AgentInventory: entity.AgentInventory{
+		Architecture: "x86_64", Elevated: true, Domain: "CORP", Interpreters: map[string]string{"powershell": "5.1"},
+	}}
+	repo.agents["win-user"] = &entity.Agent{Paw: "win-user", Platform: "windows", Status: entity.AgentOnline, AgentInventory: entity.AgentInventory{
+		Architecture: "x86_64", Interpreters: map[string]string{"powershell": "5.1"},
+	}}
+	repo.agents["mac"] = &entity.Agent{Paw: "mac", Platform: "darwin", Status: entity.AgentOnline, AgentInventory: entity.AgentInventory{
+		Architecture: "aarch64", Elevated: true,
+	}}
+	handler := NewAgentHandler(application.NewAgentService(repo))
+
+	router := gin.New()
+	router.GET("/agents", handler.ListAgents)
+
+	tests := []struct {
+		query string
+		code  int
+		count int
+	}{
+		{"", http.StatusOK, 3},
+		{"?platform=windows&elevated=true&interpreter=powershell:5", http.StatusOK, 1},
+		{"?interpreter=powershell:7", http.StatusOK, 0},
+		{"?architecture=arm64&architecture=amd64", http.StatusOK, 3},
+		{"?domain=corp", http.StatusOK, 1},
+		{"?elevated=maybe", http.StatusBadRequest, 0},
+		{"?interpreter=powershell:latest", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/agents"+tt.query, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.query, tt.code, w.Code)
+			continue
+		}
+		var agents []entity.Agent
+		if tt.code == http.StatusOK {
+			_ = json.Unmarshal(w.Body.Bytes(), &agents)
+			if len(agents) != tt.count {
+				t.Errorf("%s: expected %d agents, got %d", tt.query, tt.count, len(agents))
+			}
+		}
+	}
+}
+
 func TestAgentHandler_ListAgents_Error(t *testing.T) {
 	repo := newMockAgentRepo()
 	repo.findErr = errors.New("db error")
@@ -208,6 +257,122 @@ func TestAgentHandler_GetAgent_NotFound(t *testing.T) {
 	}
 }
 
+func TestAgentHandler_GetAvailability(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Status: entity.AgentOnline}
+	handler := NewAgentHandler(application.NewAgentService(repo))
+
+	router := gin.New()
+	router.GET("/agents/:paw/availability", handler.GetAvailability)
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/agents/paw1/availability", http.StatusOK},
+		{"/agents/paw1/availability?days=30", http.StatusOK},
+		{"/agents/paw1/availability?days=0", http.StatusBadRequest},
+		{"/agents/paw1/availability?days=91", http.StatusBadRequest},
+		{"/agents/missing/availability", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.code, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/agents/paw1/availability", nil)
+	router.ServeHTTP(w, req)
+	var availability entity.AgentAvailability
+	_ = json.Unmarshal(w.Body.Bytes(), &availability)
+	if availability.UptimePercent != 100 || availability.Events == nil {
+		t.Errorf("Expected an agent online throughout without events, got %+v", availability)
+	}
+}
+
+func TestAgentHandler_GetHealth(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Status: entity.AgentOnline}
+	handler := NewAgentHandler(application.NewAgentService(repo))
+
+	router := gin.New()
+	router.GET("/analytics/agents/:paw/health", handler.GetHealth)
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/analytics/agents/paw1/health", http.StatusOK},
+		{"/analytics/agents/paw1/health?days=30", http.StatusOK},
+		{"/analytics/agents/paw1/health?days=0", http.StatusBadRequest},
+		{"/analytics/agents/paw1/health?days=31", http.StatusBadRequest},
+		{"/analytics/agents/missing/health", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.code, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/analytics/agents/paw1/health", nil)
+	router.ServeHTTP(w, req)
+	var health entity.AgentHealth
+	_ = json.Unmarshal(w.Body.Bytes(), &health)
+	if health.Health != entity.AgentHealthUnknown || health.Issues == nil {
+		t.Errorf("Expected an unknown health without check-ins, got %+v", health)
+	}
+}
+
+func TestAgentHandler_Bulk(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Platform: "linux", Status: entity.AgentOffline, LastSeen: time.Now().Add(-96 * time.Hour)}
+	repo.agents["paw2"] = &entity.Agent{Paw: "paw2", Platform: "linux", Status: entity.AgentOnline, LastSeen: time.Now()}
+	handler := NewAgentHandler(application.NewAgentService(repo))
+
+	router := gin.New()
+	router.POST("/agents/bulk/preview", handler.PreviewBulk)
+	router.POST("/agents/bulk/apply", handler.ApplyBulk)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	op := `"action": "delete", "filter": {"platforms": ["linux"], "not_seen_for": "72h"}`
+	w := post("/agents/bulk/preview", "{"+op+"}")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var preview application.AgentBulkPreview
+	_ = json.Unmarshal(w.Body.Bytes(), &preview)
+	if len(preview.Agents) != 1 || preview.Checksum == "" {
+		t.Fatalf("Expected one agent to delete, got %+v", preview)
+	}
+
+	if w := post("/agents/bulk/preview", `{"action": "delete", "filter": {}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without filter, got %d", w.Code)
+	}
+	if w := post("/agents/bulk/apply", "{"+op+`, "checksum": "stale"}`); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for a stale checksum, got %d", w.Code)
+	}
+	if w := post("/agents/bulk/apply", "{"+op+`, "checksum": "`+preview.Checksum+`"}`); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := repo.agents["paw1"]; ok {
+		t.Error("Expected paw1 deleted")
+	}
+}
+
 func TestAgentHandler_RegisterAgent(t *testing.T) {
 	repo := newMockAgentRepo()
 	svc := application.NewAgentService(repo)
@@ -353,6 +518,38 @@ func TestAgentHandler_Heartbeat_Error(t *testing.T) {
 	}
 }
 
+func TestAgentHandler_SetAllowedNetworks(t *testing.T) {
+	repo := newMockAgentRepo()
+	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Hostname: "host1"}
+	handler := NewAgentHandler(application.NewAgentService(repo))
+
+	router := gin.New()
+	router.PUT("/agents/:paw/networks", handler.SetAllowedNetworks)
+
+	tests := []struct {
+		paw  string
+		body string
+		want int
+	}{
+		{"paw1", `{"networks": ["10.0.0.0/8"]}`, http.StatusOK},
+		{"paw1", `{"networks": ["10.0.0.0/99"]}`, http.StatusBadRequest},
+		{"paw1", `not json`, http.StatusBadRequest},
+		{"unknown", `{"networks": []}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PUT", "/agents/"+tt.paw+"/networks", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d", tt.paw, tt.body, tt.want, w.Code)
+		}
+	}
+	if networks := repo.agents["paw1"].AllowedNetworks; len(networks) != 1 || networks[0] != "10.0.0.0/8" {
+		t.Errorf("Expected the networks saved, got %v", networks)
+	}
+}
+
 func TestAgentHandler_ListAgents_AllTrue(t *testing.T) {
 	repo := newMockAgentRepo()
 	repo.agents["paw1"] = &entity.Agent{Paw: "paw1", Hostname: "host1", Status: entity.AgentOnline}
@@ -550,6 +747,25 @@ func TestTechniqueHandler_ListTechniques_Error(t *testing.T) {
 	}
 }
 
+func TestTechniqueHandler_DeleteTechnique(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Command Execution"}
+	handler := NewTechniqueHandler(application.NewTechniqueService(repo))
+
+	router := gin.New()
+	router.DELETE("/techniques/:id", handler.DeleteTechnique)
+
+	for id, code := range map[string]int{"T1059": http.StatusNoContent, "T9999": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/techniques/"+id, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != code {
+			t.Errorf("%s: expected status %d, got %d", id, code, w.Code)
+		}
+	}
+}
+
 func TestTechniqueHandler_GetTechnique(t *testing.T) {
 	repo := newMockTechniqueRepo()
 	repo.techniques["T1059"] = &entity.Technique{ID: "T1059", Name: "Command Execution"}
@@ -953,6 +1169,19 @@ func (m *mockResultRepo) FindCompletedExecutionsByDateRange(ctx context.Context,
 	return result, nil
 }
 
+func (m *mockResultRepo) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var result []*entity.Execution
+	for _, e := range m.executions {
+		if e.Status == status {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
 type mockScenarioRepo struct {
 	scenarios map[string]*entity.Scenario
 	err       error
@@ -1031,6 +1260,43 @@ func TestExecutionHandler_ListExecutions(t *testing.T) {
 	}
 }
 
+func TestExecutionHandler_ListExecutions_IncludeResults(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionCompleted}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{{ID: "r1", ExecutionID: "e1", Status: entity.StatusBlocked}}
+	svc := application.NewExecutionService(resultRepo, nil, nil, nil, nil, nil)
+	handler := NewExecutionHandler(svc)
+
+	router := gin.New()
+	router.GET("/executions", handler.ListExecutions)
+
+	for _, query := range []string{"", "?include=results"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/executions"+query, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected status 200, got %d", query, w.Code)
+		}
+		var executions []entity.Execution
+		if err := json.Unmarshal(w.Body.Bytes(), &executions); err != nil || len(executions) != 1 {
+			t.Fatalf("%q: expected 1 execution, got %s", query, w.Body.String())
+		}
+		if summary := executions[0].ResultSummary; summary == nil || summary.ByStatus[entity.StatusBlocked] != 1 {
+			t.Errorf("%q: expected the result summary, got %+v", query, summary)
+		}
+		if wantResults := query != ""; (len(executions[0].Results) == 1) != wantResults {
+			t.Errorf("%q: expected results %v, got %d", query, wantResults, len(executions[0].Results))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/executions?include=facts", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown include, got %d", w.Code)
+	}
+}
+
 func TestExecutionHandler_ListExecutions_Error(t *testing.T) {
 	resultRepo := newMockResultRepo()
 	resultRepo.err = errors.New("db error")
@@ -1122,6 +1388,118 @@ func TestExecutionHandler_GetResults_Error(t *testing.T) {
 	}
 }
 
+func TestExecutionHandler_GetFacts(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1"}
+	svc := application.NewExecutionService(resultRepo, nil, nil, nil, nil, nil)
+	handler := NewExecutionHandler(svc)
+
+	router := gin.New()
+	router.GET("/executions/:id/facts", handler.GetFacts)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/executions/e1/facts", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "[]" {
+		t.Errorf("Expected an empty array, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/executions/missing/facts", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+// mockExecutionSnapshotRepo implements repository.ExecutionSnapshotRepository for tests
+type mockExecutionSnapshotRepo struct {
+	snapshots map[string]*entity.ExecutionSnapshot
+}
+
+func (m *mockExecutionSnapshotRepo) Save(ctx context.Context, snapshot *entity.ExecutionSnapshot) error {
+	m.snapshots[snapshot.ExecutionID] = snapshot
+	return nil
+}
+
+func (m *mockExecutionSnapshotRepo) Get(ctx context.Context, executionID string) (*entity.ExecutionSnapshot, error) {
+	return m.snapshots[executionID], nil
+}
+
+func TestExecutionHandler_GetSnapshot(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1"}
+	resultRepo.executions["e2"] = &entity.Execution{ID: "e2"}
+	svc := application.NewExecutionService(resultRepo, nil, nil, nil, nil, nil)
+	svc.SetSnapshotRepository(&mockExecutionSnapshotRepo{snapshots: map[string]*entity.ExecutionSnapshot{
+		"e1": {ExecutionID: "e1", Server: entity.ServerSnapshot{Version: "v1.0.0"}},
+	}}, "v1.0.0")
+	handler := NewExecutionHandler(svc)
+
+	router := gin.New()
+	router.GET("/executions/:id/snapshot", handler.GetSnapshot)
+
+	tests := []struct {
+		id     string
+		status int
+	}{
+		{"e1", http.StatusOK},
+		{"e2", http.StatusNotFound}, // Started without a snapshot
+		{"missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/executions/"+tt.id+"/snapshot", nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.id, tt.status, w.Code)
+		}
+	}
+}
+
+func TestExecutionHandler_GetResultOutput(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", Output: "0123456789"},
+	}
+	svc := application.NewExecutionService(resultRepo, nil, nil, nil, nil, nil)
+	handler := NewExecutionHandler(svc)
+
+	router := gin.New()
+	router.GET("/results/:id/output", handler.GetResultOutput)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/results/r1/output?offset=2&limit=4", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var page application.ResultOutputPage
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("Failed to decode page: %v", err)
+	}
+	if page.Output != "2345" || page.Size != 10 || page.NextOffset == nil || *page.NextOffset != 6 {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	for _, query := range []string{"offset=abc", "limit=x", "offset=11", "limit=2000000"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/results/r1/output?"+query, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, w.Code)
+		}
+	}
+}
+
 func TestExecutionHandler_StartExecution_BadRequest(t *testing.T) {
 	resultRepo := newMockResultRepo()
 	svc := application.NewExecutionService(resultRepo, nil, nil, nil, nil, nil)
@@ -1228,6 +1606,63 @@ func TestExecutionHandler_StartExecution_Success(t *testing.T) {
 	time.Sleep(10 * time.Millisecond)
 }
 
+func TestExecutionHandler_StartExecution_QuotaExceeded(t *testing.T) {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:     "s1",
+		Phases: []entity.Phase{{Name: "Phase1", Techniques: []string{"T1059"}}},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{
+		ID:        "T1059",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "echo test"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{
+		Paw: "paw1", Status: entity.AgentOnline, Platform: "linux", Executors: []string{"sh"}, LastSeen: time.Now(),
+	}
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, service.NewTechniqueValidator(), nil)
+	svc := application.NewExecutionService(newMockResultRepo(), scenarioRepo, techRepo, agentRepo, orchestrator, service.NewScoreCalculator())
+	svc.SetExecutionQuota(application.NewExecutionQuota(application.ExecutionQuotaConfig{PerWorkspace: 1, Window: time.Hour}))
+	handler := NewExecutionHandler(svc)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Set("workspace", "red")
+		c.Next()
+	})
+	router.POST("/executions", handler.StartExecution)
+
+	jsonBody, _ := json.Marshal(StartExecutionRequest{ScenarioID: "s1", AgentPaws: []string{"paw1"}})
+	codes := make([]int, 2)
+	var w *httptest.ResponseRecorder
+	for i := range codes {
+		w = httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/executions", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		codes[i] = w.Code
+	}
+
+	if codes[0] != http.StatusCreated || codes[1] != http.StatusTooManyRequests {
+		t.Fatalf("Expected 201 then 429, got %v", codes)
+	}
+	if w.Header().Get("X-RateLimit-Limit") != "1" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("Unexpected quota headers %v", w.Header())
+	}
+	if !strings.Contains(w.Body.String(), `"scope":"workspace"`) {
+		t.Errorf("Expected the workspace quota exceeded, got %s", w.Body.String())
+	}
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter == "" || retryAfter == "0" {
+		t.Errorf("Expected a Retry-After header, got %q", retryAfter)
+	}
+	if reset, _ := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64); reset <= time.Now().Unix() {
+		t.Errorf("Expected a reset time in the future, got %d", reset)
+	}
+}
+
 func TestExecutionHandler_CompleteExecution(t *testing.T) {
 	resultRepo := newMockResultRepo()
 	resultRepo.executions["e1"] = &entity.Execution{
@@ -1515,6 +1950,60 @@ func TestExecutionHandler_GetResults_NilResults(t *testing.T) {
 	}
 }
 
+func TestExecutionHandler_StartExecution_OffHours(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:   "s1",
+		Name: "Test Scenario",
+		Phases: []entity.Phase{
+			{Name: "Phase1", Techniques: []string{"T1059"}},
+		},
+	}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1059"] = &entity.Technique{
+		ID:        "T1059",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "sh", Command: "echo test"}},
+	}
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["paw1"] = &entity.Agent{
+		Paw:       "paw1",
+		Status:    entity.AgentOnline,
+		Platform:  "linux",
+		Executors: []string{"sh"},
+		LastSeen:  time.Now(),
+	}
+
+	validator := service.NewTechniqueValidator()
+	orchestrator := service.NewAttackOrchestrator(agentRepo, techRepo, validator, nil)
+	calculator := service.NewScoreCalculator()
+	svc := application.NewExecutionService(resultRepo, scenarioRepo, techRepo, agentRepo, orchestrator, calculator)
+
+	handler := NewExecutionHandler(svc)
+	activityRepo := &mockActivityRepo{}
+	handler.SetActivityMonitor(newTestActivityMonitor(activityRepo, newMockUserRepo()))
+
+	router := gin.New()
+	router.POST("/executions", withAuth(handler.StartExecution))
+
+	jsonBody, _ := json.Marshal(StartExecutionRequest{ScenarioID: "s1", AgentPaws: []string{"paw1"}})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/executions", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(activityRepo.anomalies) != 1 || activityRepo.anomalies[0].Type != entity.AnomalyOffHoursExecution {
+		t.Fatalf("Expected an off_hours_execution anomaly, got %+v", activityRepo.anomalies)
+	}
+	if activityRepo.anomalies[0].UserID != "test-user-id" {
+		t.Errorf("Expected anomaly attributed to test-user-id, got %s", activityRepo.anomalies[0].UserID)
+	}
+}
+
 func TestExecutionHandler_StartExecution_EmptyAgentPaws(t *testing.T) {
 	resultRepo := newMockResultRepo()
 	svc := application.NewExecutionService(resultRepo, nil, nil, nil, nil, nil)
@@ -1549,7 +2038,7 @@ func TestExecutionHandler_DispatchTasksToAgents_NilHub(t *testing.T) {
 	tasks := []application.TaskDispatchInfo{
 		{ResultID: "r1", AgentPaw: "paw1", TechniqueID: "T1082", Command: "echo test"},
 	}
-	handler.dispatchTasksToAgents(tasks)
+	handler.dispatchTasksToAgents(context.Background(), tasks)
 }
 
 func TestExecutionHandler_DispatchTasksToAgents_WithHub(t *testing.T) {
@@ -1566,7 +2055,7 @@ func TestExecutionHandler_DispatchTasksToAgents_WithHub(t *testing.T) {
 	tasks := []application.TaskDispatchInfo{
 		{ResultID: "r1", AgentPaw: "nonexistent", TechniqueID: "T1082", Command: "echo test"},
 	}
-	handler.dispatchTasksToAgents(tasks)
+	handler.dispatchTasksToAgents(context.Background(), tasks)
 
 	// Give time for processing
 	time.Sleep(10 * time.Millisecond)
@@ -1947,3 +2436,259 @@ func TestValidateImportPath_ConfigsPrefixNotDir(t *testing.T) {
 		t.Error("Expected error for configs_backup (not a valid directory prefix)")
 	}
 }
+
+func TestExecutionHandler_ExportExecution(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionCompleted}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{{ID: "r1", ExecutionID: "e1", TechniqueID: "T1082"}}
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery}
+	svc := application.NewExecutionService(resultRepo, nil, techRepo, nil, nil, nil)
+	handler := NewExecutionHandler(svc)
+
+	router := gin.New()
+	router.GET("/executions/:id/export", handler.ExportExecution)
+
+	tests := []struct {
+		url        string
+		wantStatus int
+		wantName   string
+	}{
+		{"/executions/e1/export", http.StatusOK, ""},
+		{"/executions/e1/export?verbosity=full", http.StatusOK, "System Information Discovery"},
+		{"/executions/e1/export?verbosity=loud", http.StatusBadRequest, ""},
+		{"/executions/missing/export", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.url, tt.wantStatus, w.Code)
+			continue
+		}
+		if w.Code != http.StatusOK {
+			continue
+		}
+
+		var export application.ExecutionExport
+		if err := json.Unmarshal(w.Body.Bytes(), &export); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", tt.url, err)
+		}
+		name := ""
+		if export.Results[0].Technique != nil {
+			name = export.Results[0].Technique.Name
+		}
+		if name != tt.wantName {
+			t.Errorf("%s: expected technique name %q, got %q", tt.url, tt.wantName, name)
+		}
+	}
+}
+
+func TestTechniqueHandler_ListTechniques_StatusFilter(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059"}
+	repo.techniques["T1082"] = &entity.Technique{ID: "T1082", Status: entity.TechniqueDeprecated}
+	svc := application.NewTechniqueService(repo)
+	handler := NewTechniqueHandler(svc)
+
+	router := gin.New()
+	router.GET("/techniques", handler.ListTechniques)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/techniques?status=deprecated", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var techniques []*entity.Technique
+	if err := json.Unmarshal(w.Body.Bytes(), &techniques); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(techniques) != 1 || techniques[0].ID != "T1082" {
+		t.Errorf("Expected only T1082, got %+v", techniques)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/techniques?status=retired", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown status, got %d", w.Code)
+	}
+}
+
+func TestTechniqueHandler_ListTechniques_MetadataFilter(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059", Metadata: entity.Metadata{"owner_team": "Red", "risk_rating": "high"}}
+	repo.techniques["T1082"] = &entity.Technique{ID: "T1082", Metadata: entity.Metadata{"owner_team": "blue"}}
+	repo.techniques["T1003"] = &entity.Technique{ID: "T1003"}
+	svc := application.NewTechniqueService(repo)
+	handler := NewTechniqueHandler(svc)
+
+	router := gin.New()
+	router.GET("/techniques", handler.ListTechniques)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/techniques?metadata.owner_team=red&metadata.risk_rating=high", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var techniques []*entity.Technique
+	if err := json.Unmarshal(w.Body.Bytes(), &techniques); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(techniques) != 1 || techniques[0].ID != "T1059" {
+		t.Errorf("Expected only T1059, got %+v", techniques)
+	}
+}
+
+func TestTechniqueHandler_ListTechniques_SeverityFilter(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059", Severity: entity.TechniqueSeverityHigh}
+	repo.techniques["T1082"] = &entity.Technique{ID: "T1082", Severity: entity.TechniqueSeverityLow}
+	repo.techniques["T1003"] = &entity.Technique{ID: "T1003", Severity: entity.TechniqueSeverityLow, Metadata: entity.Metadata{"severity": "high"}}
+	handler := NewTechniqueHandler(application.NewTechniqueService(repo))
+
+	router := gin.New()
+	router.GET("/techniques", handler.ListTechniques)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/techniques?severity=HIGH", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var techniques []*entity.Technique
+	if err := json.Unmarshal(w.Body.Bytes(), &techniques); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(techniques) != 2 {
+		t.Errorf("Expected T1059 and the overridden T1003, got %+v", techniques)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/techniques?severity=severe", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown severity, got %d", w.Code)
+	}
+}
+
+func TestTechniqueHandler_UpdateTechniqueMetadata(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059"}
+	svc := application.NewTechniqueService(repo)
+	handler := NewTechniqueHandler(svc)
+
+	router := gin.New()
+	router.PUT("/techniques/:id/metadata", handler.UpdateTechniqueMetadata)
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+		want int
+	}{
+		{"replaces fields", "T1059", `{"metadata":{"owner_team":"red","related_ticket":"SEC-42"}}`, http.StatusOK},
+		{"missing metadata", "T1059", `{}`, http.StatusBadRequest},
+		{"invalid field", "T1059", `{"metadata":{"Owner":"red"}}`, http.StatusBadRequest},
+		{"not found", "T9999", `{"metadata":{}}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/techniques/"+tt.id+"/metadata", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+	if repo.techniques["T1059"].Metadata["related_ticket"] != "SEC-42" {
+		t.Errorf("Expected the custom fields to be stored, got %v", repo.techniques["T1059"].Metadata)
+	}
+
+	repo.updateErr = errors.New("db error")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/techniques/T1059/metadata", bytes.NewBufferString(`{"metadata":{}}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func TestTechniqueHandler_UpdateTechniqueStatus(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059"}
+	svc := application.NewTechniqueService(repo)
+	handler := NewTechniqueHandler(svc)
+
+	router := gin.New()
+	router.PUT("/techniques/:id/status", handler.UpdateTechniqueStatus)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/techniques/T1059/status", bytes.NewBufferString(`{"status":"broken"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.techniques["T1059"].Status != entity.TechniqueBroken {
+		t.Errorf("Expected technique to be broken, got %s", repo.techniques["T1059"].Status)
+	}
+}
+
+func TestTechniqueHandler_UpdateTechniqueStatus_Errors(t *testing.T) {
+	repo := newMockTechniqueRepo()
+	repo.techniques["T1059"] = &entity.Technique{ID: "T1059", Status: entity.TechniqueDraft}
+	svc := application.NewTechniqueService(repo)
+	handler := NewTechniqueHandler(svc)
+
+	router := gin.New()
+	router.PUT("/techniques/:id/status", handler.UpdateTechniqueStatus)
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+		want int
+	}{
+		{"missing status", "T1059", `{}`, http.StatusBadRequest},
+		{"unknown status", "T1059", `{"status":"retired"}`, http.StatusBadRequest},
+		{"not found", "T9999", `{"status":"active"}`, http.StatusNotFound},
+		{"invalid transition", "T1059", `{"status":"broken"}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/techniques/"+tt.id+"/status", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+
+	repo.updateErr = errors.New("db error")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/techniques/T1059/status", bytes.NewBufferString(`{"status":"active"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+	service *application.HealthService
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(service *application.HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// RegisterRoutes registers the probe routes (public, outside /api/v1)
+func (h *HealthHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/healthz", h.Liveness)
+	router.GET("/readyz", h.Readiness)
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Checks the in-process loops (scheduler, WebSocket hub). Returns 503 when the process should be restarted
+// @Tags health
+// @Produce json
+// @Success 200 {object} application.HealthReport
+// @Failure 503 {object} application.HealthReport
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	h.respond(c, h.service.Liveness(c.Request.Context()))
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Checks the liveness components plus the database and, when configured, SMTP. Returns 503 when a required component fails; optional failures report "degraded"
+// @Tags health
+// @Produce json
+// @Success 200 {object} application.HealthReport
+// @Failure 503 {object} application.HealthReport
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	h.respond(c, h.service.Readiness(c.Request.Context()))
+}
+
+// respond writes a report, with 503 when a required component is failing
+func (h *HealthHandler) respond(c *gin.Context, report *application.HealthReport) {
+	c.Header("Cache-Control", "no-store")
+	if !report.Healthy() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
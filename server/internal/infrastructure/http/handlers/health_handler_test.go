@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupHealthRouter(database error) *gin.Engine {
+	health := application.NewHealthService()
+	health.AddLivenessCheck("scheduler", func(context.Context) error { return nil })
+	health.AddReadinessCheck("database", func(context.Context) error { return database }, true)
+	health.AddReadinessCheck("smtp", func(context.Context) error { return errors.New("connection refused") }, false)
+
+	router := gin.New()
+	NewHealthHandler(health).RegisterRoutes(router)
+	return router
+}
+
+func TestHealthHandler_Liveness(t *testing.T) {
+	router := setupHealthRouter(errors.New("database is locked"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report application.HealthReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.Status != application.HealthOK || len(report.Components) != 1 {
+		t.Errorf("unexpected liveness report: %+v", report)
+	}
+}
+
+func TestHealthHandler_Readiness_Degraded(t *testing.T) {
+	router := setupHealthRouter(nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var report application.HealthReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.Status != application.HealthDegraded || report.Components["smtp"].Error != "connection refused" {
+		t.Errorf("unexpected readiness report: %+v", report)
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Error("probe responses should not be cached")
+	}
+}
+
+func TestHealthHandler_Readiness_Unavailable(t *testing.T) {
+	router := setupHealthRouter(errors.New("database is locked"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", w.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobHandler exposes the background jobs to the users who started them
+type JobHandler struct {
+	service *application.JobService
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(service *application.JobService) *JobHandler {
+	return &JobHandler{service: service}
+}
+
+// RegisterRoutes registers job routes
+func (h *JobHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/jobs", h.ListJobs)
+	r.GET("/jobs/:id", h.GetJob)
+}
+
+// ListJobs godoc
+// @Summary List background jobs
+// @Description List the latest background jobs of the current user, newest first; administrators list every job
+// @Tags jobs
+// @Produce json
+// @Param type query string false "Job type (content_import, execution_export, retention, technique_sync)"
+// @Param limit query int false "Maximum jobs returned (default and max 100)"
+// @Success 200 {array} entity.Job
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	admin := c.GetString("role") == string(entity.RoleAdmin)
+	jobs, err := h.service.List(c.Request.Context(), entity.JobType(c.Query("type")), c.GetString("user_id"), admin, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJob godoc
+// @Summary Get a background job
+// @Description Get the status, progress and result of a background job of the current user; administrators get any job
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} entity.Job
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	admin := c.GetString("role") == string(entity.RoleAdmin)
+	job, err := h.service.Get(c.Request.Context(), c.Param("id"), c.GetString("user_id"), admin)
+	switch {
+	case errors.Is(err, application.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get job"})
+	default:
+		c.JSON(http.StatusOK, job)
+	}
+}
+
+// wantsAsync tells whether a request asks to run as a background job, which
+// needs a job service
+func wantsAsync(c *gin.Context, jobs *application.JobService) bool {
+	return jobs != nil && c.Query("async") == "true"
+}
+
+// submitJob runs fn as a background job of the current user and answers 202
+// with the job to follow with GET /jobs/:id
+func submitJob(c *gin.Context, jobs *application.JobService, jobType entity.JobType, fn application.JobFunc) {
+	job, err := jobs.Submit(c.Request.Context(), jobType, c.GetString("user_id"), fn)
+	switch {
+	case errors.Is(err, application.ErrJobQueueFull):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start job"})
+	default:
+		c.JSON(http.StatusAccepted, job)
+	}
+}
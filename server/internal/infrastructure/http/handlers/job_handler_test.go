@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockJobRepo implements repository.JobRepository for tests, storing copies of the jobs
+type mockJobRepo struct {
+	mu   sync.Mutex
+	jobs map[string]entity.Job
+}
+
+func newMockJobRepo() *mockJobRepo {
+	return &mockJobRepo{jobs: make(map[string]entity.Job)}
+}
+
+func (m *mockJobRepo) Create(ctx context.Context, job *entity.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = *job
+	return nil
+}
+
+func (m *mockJobRepo) Update(ctx context.Context, job *entity.Job) error {
+	return m.Create(ctx, job)
+}
+
+func (m *mockJobRepo) FindByID(ctx context.Context, id string) (*entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &job, nil
+}
+
+func (m *mockJobRepo) FindRecent(ctx context.Context, jobType entity.JobType, createdBy string, limit int) ([]*entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []*entity.Job
+	for _, job := range m.jobs {
+		if (jobType == "" || job.Type == jobType) && (createdBy == "" || job.CreatedBy == createdBy) {
+			job := job
+			jobs = append(jobs, &job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *mockJobRepo) FailUnfinished(ctx context.Context, reason string, at time.Time) (int64, error) {
+	return 0, nil
+}
+
+// withUser authenticates requests as a user with a role
+func withUser(userID, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+	}
+}
+
+func waitForBackgroundJob(t *testing.T, repo *mockJobRepo, id string) *entity.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := repo.FindByID(context.Background(), id)
+		if err != nil {
+			t.Fatalf("FindByID failed: %v", err)
+		}
+		if job.Finished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("job did not finish in time")
+	return nil
+}
+
+func TestJobHandler_GetJob(t *testing.T) {
+	repo := newMockJobRepo()
+	repo.jobs["job-1"] = entity.Job{ID: "job-1", Type: entity.JobContentImport, Status: entity.JobRunning, CreatedBy: "user-1"}
+	handler := NewJobHandler(application.NewJobService(repo, 1, nil))
+
+	tests := []struct {
+		name   string
+		userID string
+		role   string
+		id     string
+		code   int
+	}{
+		{"own job", "user-1", "operator", "job-1", http.StatusOK},
+		{"job of another user", "user-2", "operator", "job-1", http.StatusNotFound},
+		{"admin", "user-2", "admin", "job-1", http.StatusOK},
+		{"missing", "user-1", "admin", "missing", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		router := gin.New()
+		handler.RegisterRoutes(router.Group("", withUser(tt.userID, tt.role)))
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs/"+tt.id, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.code, w.Code)
+		}
+	}
+}
+
+func TestJobHandler_ListJobs(t *testing.T) {
+	repo := newMockJobRepo()
+	repo.jobs["job-1"] = entity.Job{ID: "job-1", Type: entity.JobContentImport, Status: entity.JobCompleted, CreatedBy: "user-1"}
+	repo.jobs["job-2"] = entity.Job{ID: "job-2", Type: entity.JobRetention, Status: entity.JobCompleted, CreatedBy: "user-2"}
+	handler := NewJobHandler(application.NewJobService(repo, 1, nil))
+
+	list := func(role, query string) []entity.Job {
+		router := gin.New()
+		handler.RegisterRoutes(router.Group("", withUser("user-1", role)))
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/jobs"+query, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var jobs []entity.Job
+		_ = json.Unmarshal(w.Body.Bytes(), &jobs)
+		return jobs
+	}
+
+	if jobs := list("operator", ""); len(jobs) != 1 || jobs[0].ID != "job-1" {
+		t.Errorf("Expected the user's own jobs, got %+v", jobs)
+	}
+	if jobs := list("admin", ""); len(jobs) != 2 {
+		t.Errorf("Expected every job for admins, got %+v", jobs)
+	}
+	if jobs := list("admin", "?type=retention"); len(jobs) != 1 || jobs[0].ID != "job-2" {
+		t.Errorf("Expected the jobs of the type, got %+v", jobs)
+	}
+}
+
+func TestJobHandler_Unauthenticated(t *testing.T) {
+	handler := NewJobHandler(application.NewJobService(newMockJobRepo(), 1, nil))
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	for _, path := range []string{"/jobs", "/jobs/job-1"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status 401, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestContentHandler_ImportContent_Async(t *testing.T) {
+	repo := newMockJobRepo()
+	jobs := application.NewJobService(repo, 1, nil)
+	jobs.Start()
+	defer jobs.Stop()
+
+	techRepo := newMockTechniqueRepo()
+	handler := NewContentHandler(application.NewContentImportService(
+		application.NewTechniqueService(techRepo),
+		application.NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator()),
+		&stubContentConverter{},
+	))
+	handler.SetJobService(jobs)
+	router := gin.New()
+	router.POST("/content/import/:format", withUser("user-1", "operator"), handler.ImportContent)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/content/import/caldera?async=true", strings.NewReader("data"))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected an unknown format rejected before starting a job, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/content/import/stub?async=true&incremental=true", strings.NewReader("data"))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var started entity.Job
+	_ = json.Unmarshal(w.Body.Bytes(), &started)
+	if started.Type != entity.JobContentImport || started.CreatedBy != "user-1" {
+		t.Errorf("Unexpected job: %+v", started)
+	}
+
+	job := waitForBackgroundJob(t, repo, started.ID)
+	var result application.ContentImportResult
+	if err := json.Unmarshal(job.Result, &result); err != nil || len(result.Added) != 1 {
+		t.Errorf("Expected the import result stored on the job, got %+v (%v)", job, err)
+	}
+	if _, ok := techRepo.techniques["T1082"]; !ok {
+		t.Error("Expected the technique imported by the job")
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/mail"
@@ -21,6 +22,9 @@ const (
 	routeSettings            = "/settings"
 )
 
+// minWebhookSecretLength is the shortest accepted webhook signing secret
+const minWebhookSecretLength = 16
+
 // NotificationHandler handles notification-related HTTP requests
 type NotificationHandler struct {
 	notificationService *application.NotificationService
@@ -41,6 +45,8 @@ func (h *NotificationHandler) RegisterRoutes(router *gin.RouterGroup) {
 		notifications.GET("/unread/count", h.GetUnreadCount)
 		notifications.POST("/:id/read", h.MarkAsRead)
 		notifications.POST("/read-all", h.MarkAllAsRead)
+		notifications.DELETE("/:id", h.DeleteNotification)
+		notifications.POST("/delete", h.DeleteNotifications)
 
 		// Settings
 		notifications.GET(routeSettings, h.GetSettings)
@@ -50,42 +56,105 @@ func (h *NotificationHandler) RegisterRoutes(router *gin.RouterGroup) {
 
 		// SMTP config (admin only)
 		notifications.GET("/smtp", h.GetSMTPConfig)
+		notifications.PUT("/smtp", h.UpdateSMTPConfig)
+		notifications.DELETE("/smtp", h.DeleteSMTPConfig)
 		notifications.POST("/smtp/test", h.TestSMTP)
 	}
 }
 
 // NotificationSettingsRequest represents the request to create/update notification settings
 type NotificationSettingsRequest struct {
-	Channel              string  `json:"channel" binding:"required,oneof=email webhook"`
-	Enabled              bool    `json:"enabled"`
-	EmailAddress         string  `json:"email_address"`
-	WebhookURL           string  `json:"webhook_url"`
-	NotifyOnStart        bool    `json:"notify_on_start"`
-	NotifyOnComplete     bool    `json:"notify_on_complete"`
-	NotifyOnFailure      bool    `json:"notify_on_failure"`
-	NotifyOnScoreAlert   bool    `json:"notify_on_score_alert"`
-	ScoreAlertThreshold  float64 `json:"score_alert_threshold"`
-	NotifyOnAgentOffline bool    `json:"notify_on_agent_offline"`
+	Enabled             bool                           `json:"enabled"`
+	EmailAddress        string                         `json:"email_address"`
+	WebhookURL          string                         `json:"webhook_url"`
+	TeamsWebhookURL     string                         `json:"teams_webhook_url"`
+	WebhookSecret       string                         `json:"webhook_secret"`
+	PagerDutyRoutingKey string                         `json:"pagerduty_routing_key"`
+	OpsgenieAPIKey      string                         `json:"opsgenie_api_key"`
+	Preferences         entity.NotificationPreferences `json:"preferences"`
+	ScoreAlertThreshold float64                        `json:"score_alert_threshold"`
+	QuietHours          *entity.QuietHours             `json:"quiet_hours"` // Omit to email at any time
+	Digest              entity.NotificationDigest      `json:"digest"`      // hourly or daily, empty for one email per event
+
+	// Single-channel fields of older clients, used when preferences is omitted
+	Channel              string `json:"channel,omitempty"`
+	NotifyOnStart        bool   `json:"notify_on_start,omitempty"`
+	NotifyOnComplete     bool   `json:"notify_on_complete,omitempty"`
+	NotifyOnFailure      bool   `json:"notify_on_failure,omitempty"`
+	NotifyOnScoreAlert   bool   `json:"notify_on_score_alert,omitempty"`
+	NotifyOnAgentOffline bool   `json:"notify_on_agent_offline,omitempty"`
+}
+
+// preferences returns the requested preference matrix, converting the single-channel fields if needed
+func (r *NotificationSettingsRequest) preferences() entity.NotificationPreferences {
+	if r.Preferences != nil {
+		return r.Preferences
+	}
+	return entity.LegacyNotificationPreferences(
+		entity.NotificationChannel(r.Channel),
+		r.NotifyOnStart, r.NotifyOnComplete, r.NotifyOnFailure, r.NotifyOnScoreAlert, r.NotifyOnAgentOffline,
+	)
 }
 
 // Validate validates the notification settings request
 func (r *NotificationSettingsRequest) Validate() error {
-	if r.Channel == "email" && r.Enabled {
+	if r.Preferences == nil && !entity.IsValidNotificationChannel(entity.NotificationChannel(r.Channel)) {
+		return fmt.Errorf("preferences are required")
+	}
+
+	prefs := r.preferences()
+	for notificationType, channels := range prefs {
+		if !entity.IsValidNotificationType(notificationType) {
+			return fmt.Errorf("unknown notification type: %s", notificationType)
+		}
+		for _, channel := range channels {
+			if !entity.IsValidNotificationChannel(channel) {
+				return fmt.Errorf("unknown notification channel: %s", channel)
+			}
+		}
+	}
+
+	if prefs.UsesChannel(entity.ChannelEmail) && r.Enabled {
 		if r.EmailAddress == "" {
-			return fmt.Errorf("email address is required when channel is email")
+			return fmt.Errorf("email address is required when email notifications are selected")
 		}
 		if _, err := mail.ParseAddress(r.EmailAddress); err != nil {
 			return fmt.Errorf("invalid email address format")
 		}
 	}
-	if r.Channel == "webhook" && r.Enabled {
+	if prefs.UsesChannel(entity.ChannelWebhook) && r.Enabled {
 		if r.WebhookURL == "" {
-			return fmt.Errorf("webhook URL is required when channel is webhook")
+			return fmt.Errorf("webhook URL is required when webhook notifications are selected")
 		}
 		if _, err := url.ParseRequestURI(r.WebhookURL); err != nil {
 			return fmt.Errorf("invalid webhook URL format")
 		}
 	}
+	if r.WebhookSecret != "" && len(r.WebhookSecret) < minWebhookSecretLength {
+		return fmt.Errorf("webhook secret must be at least %d characters", minWebhookSecretLength)
+	}
+	if prefs.UsesChannel(entity.ChannelTeams) && r.Enabled {
+		if r.TeamsWebhookURL == "" {
+			return fmt.Errorf("a Teams webhook URL is required when Teams notifications are selected")
+		}
+		if _, err := url.ParseRequestURI(r.TeamsWebhookURL); err != nil {
+			return fmt.Errorf("invalid Teams webhook URL format")
+		}
+	}
+	if prefs.UsesChannel(entity.ChannelPagerDuty) && r.Enabled && r.PagerDutyRoutingKey == "" {
+		return fmt.Errorf("a PagerDuty routing key is required when PagerDuty notifications are selected")
+	}
+	if prefs.UsesChannel(entity.ChannelOpsgenie) && r.Enabled && r.OpsgenieAPIKey == "" {
+		return fmt.Errorf("an Opsgenie API key is required when Opsgenie notifications are selected")
+	}
+	if r.QuietHours != nil {
+		if err := r.QuietHours.Validate(); err != nil {
+			return err
+		}
+	}
+	if !entity.IsValidNotificationDigest(r.Digest) {
+		return fmt.Errorf("unknown digest: %s", r.Digest)
+	}
 	return nil
 }
 
@@ -217,6 +286,84 @@ func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
+// DeleteNotification godoc
+// @Summary Delete a notification
+// @Description Delete a notification of the current user
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Notification ID"
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/notifications/{id} [delete]
+func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	if err := h.notificationService.DeleteForUser(c.Request.Context(), c.Param("id"), userID.(string)); err != nil {
+		if errors.Is(err, application.ErrNotificationNotFound) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "notification not found or access denied"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// DeleteNotificationsRequest selects the notifications to delete
+type DeleteNotificationsRequest struct {
+	IDs  []string `json:"ids"`  // Notifications of other users are ignored
+	Read bool     `json:"read"` // Also delete every read notification
+}
+
+// DeleteNotifications godoc
+// @Summary Delete notifications in bulk
+// @Description Delete the given notifications of the current user (at most 500), and all their read notifications when read is set
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body DeleteNotificationsRequest true "Notifications to delete"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/notifications/delete [post]
+func (h *NotificationHandler) DeleteNotifications(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	var req DeleteNotificationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.IDs) == 0 && !req.Read {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids or read is required"})
+		return
+	}
+	if len(req.IDs) > application.MaxNotificationBulkDelete {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d notifications can be deleted at once", application.MaxNotificationBulkDelete)})
+		return
+	}
+
+	deleted, err := h.notificationService.DeleteManyForUser(c.Request.Context(), userID.(string), req.IDs, req.Read)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
+
 // GetSettings godoc
 // @Summary Get notification settings
 // @Description Get notification settings for the current user
@@ -283,17 +430,18 @@ func (h *NotificationHandler) CreateSettings(c *gin.Context) {
 	}
 
 	settings := &entity.NotificationSettings{
-		UserID:               userID.(string),
-		Channel:              entity.NotificationChannel(req.Channel),
-		Enabled:              req.Enabled,
-		EmailAddress:         req.EmailAddress,
-		WebhookURL:           req.WebhookURL,
-		NotifyOnStart:        req.NotifyOnStart,
-		NotifyOnComplete:     req.NotifyOnComplete,
-		NotifyOnFailure:      req.NotifyOnFailure,
-		NotifyOnScoreAlert:   req.NotifyOnScoreAlert,
-		ScoreAlertThreshold:  req.ScoreAlertThreshold,
-		NotifyOnAgentOffline: req.NotifyOnAgentOffline,
+		UserID:              userID.(string),
+		Enabled:             req.Enabled,
+		EmailAddress:        req.EmailAddress,
+		WebhookURL:          req.WebhookURL,
+		TeamsWebhookURL:     req.TeamsWebhookURL,
+		WebhookSecret:       req.WebhookSecret,
+		PagerDutyRoutingKey: req.PagerDutyRoutingKey,
+		OpsgenieAPIKey:      req.OpsgenieAPIKey,
+		Preferences:         req.preferences(),
+		ScoreAlertThreshold: req.ScoreAlertThreshold,
+		QuietHours:          req.QuietHours,
+		Digest:              req.Digest,
 	}
 
 	if h.notificationService.CreateSettings(c.Request.Context(), settings) != nil {
@@ -351,16 +499,17 @@ func (h *NotificationHandler) UpdateSettings(c *gin.Context) {
 	}
 
 	// Update fields
-	settings.Channel = entity.NotificationChannel(req.Channel)
 	settings.Enabled = req.Enabled
 	settings.EmailAddress = req.EmailAddress
 	settings.WebhookURL = req.WebhookURL
-	settings.NotifyOnStart = req.NotifyOnStart
-	settings.NotifyOnComplete = req.NotifyOnComplete
-	settings.NotifyOnFailure = req.NotifyOnFailure
-	settings.NotifyOnScoreAlert = req.NotifyOnScoreAlert
+	settings.TeamsWebhookURL = req.TeamsWebhookURL
+	settings.WebhookSecret = req.WebhookSecret
+	settings.PagerDutyRoutingKey = req.PagerDutyRoutingKey
+	settings.OpsgenieAPIKey = req.OpsgenieAPIKey
+	settings.Preferences = req.preferences()
 	settings.ScoreAlertThreshold = req.ScoreAlertThreshold
-	settings.NotifyOnAgentOffline = req.NotifyOnAgentOffline
+	settings.QuietHours = req.QuietHours
+	settings.Digest = req.Digest
 
 	if h.notificationService.UpdateSettings(c.Request.Context(), settings) != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update settings"})
@@ -442,6 +591,106 @@ func (h *NotificationHandler) GetSMTPConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, config)
 }
 
+// SMTPConfigRequest represents the request to set the SMTP configuration
+type SMTPConfigRequest struct {
+	Host     string `json:"host" binding:"required"`
+	Port     int    `json:"port" binding:"required"`
+	Username string `json:"username"`
+	Password string `json:"password"` // Empty keeps the current password
+	From     string `json:"from" binding:"required"`
+	UseTLS   bool   `json:"use_tls"`
+}
+
+// UpdateSMTPConfig godoc
+// @Summary Set SMTP configuration
+// @Description Store the SMTP configuration, password encrypted, and use it at once instead of the SMTP_* environment variables - admin only. An empty password keeps the current one.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param body body SMTPConfigRequest true "SMTP configuration"
+// @Success 200 {object} entity.SMTPConfig
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/notifications/smtp [put]
+func (h *NotificationHandler) UpdateSMTPConfig(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	role, roleExists := c.Get("role")
+	if !roleExists || role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+		return
+	}
+
+	var req SMTPConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	config, err := h.notificationService.SaveSMTPConfig(c.Request.Context(), &entity.SMTPConfig{
+		Host:     req.Host,
+		Port:     req.Port,
+		Username: req.Username,
+		Password: req.Password,
+		From:     req.From,
+		UseTLS:   req.UseTLS,
+	}, userID.(string))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidSMTPConfig):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrSMTPConfigStoreDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save SMTP configuration"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, config)
+}
+
+// DeleteSMTPConfig godoc
+// @Summary Delete SMTP configuration
+// @Description Delete the SMTP configuration set through the API and go back to the SMTP_* environment variables - admin only
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/notifications/smtp [delete]
+func (h *NotificationHandler) DeleteSMTPConfig(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	role, roleExists := c.Get("role")
+	if !roleExists || role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+		return
+	}
+
+	if err := h.notificationService.DeleteSMTPConfig(c.Request.Context()); err != nil {
+		if errors.Is(err, application.ErrSMTPConfigStoreDisabled) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete SMTP configuration"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted", "smtp": h.notificationService.GetSMTPConfig()})
+}
+
 // TestSMTPRequest represents the request to test SMTP
 type TestSMTPRequest struct {
 	Email string `json:"email" binding:"required,email"`
@@ -449,7 +698,7 @@ type TestSMTPRequest struct {
 
 // TestSMTP godoc
 // @Summary Test SMTP configuration
-// @Description Send a test email to verify SMTP configuration - admin only
+// @Description Send a test email through the SMTP configuration in use, stored or from the environment - admin only
 // @Tags notifications
 // @Accept json
 // @Produce json
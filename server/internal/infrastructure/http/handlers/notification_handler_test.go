@@ -5,9 +5,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -127,6 +129,51 @@ func (m *mockNotificationRepoForHandler) MarkAllAsRead(ctx context.Context, user
 	return nil
 }
 
+func (m *mockNotificationRepoForHandler) MarkDigestPending(ctx context.Context, id string) error {
+	return nil
+}
+
+func (m *mockNotificationRepoForHandler) FindDigestPending(ctx context.Context, userID string) ([]*entity.Notification, error) {
+	return nil, nil
+}
+
+func (m *mockNotificationRepoForHandler) MarkDigestSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	return nil
+}
+
+func (m *mockNotificationRepoForHandler) DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error) {
+	var deleted int64
+	for _, id := range ids {
+		if n, ok := m.notifications[id]; ok && n.UserID == userID {
+			delete(m.notifications, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockNotificationRepoForHandler) DeleteReadNotifications(ctx context.Context, userID string) (int64, error) {
+	var deleted int64
+	for id, n := range m.notifications {
+		if n.UserID == userID && n.Read {
+			delete(m.notifications, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockNotificationRepoForHandler) PurgeReadNotifications(ctx context.Context, createdBefore time.Time) (int64, error) {
+	var deleted int64
+	for id, n := range m.notifications {
+		if n.Read && !n.DigestPending && n.CreatedAt.Before(createdBefore) {
+			delete(m.notifications, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
 // mockUserRepoForNotificationHandler implements repository.UserRepository for notification handler tests
 type mockUserRepoForNotificationHandler struct{}
 
@@ -182,6 +229,26 @@ func (m *mockUserRepoForNotificationHandler) DeactivateAdminIfNotLast(ctx contex
 	return nil
 }
 
+func (m *mockUserRepoForNotificationHandler) FindLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockUserRepoForNotificationHandler) AddLoginFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, at, failuresSince, lockoutsSince time.Time) (*entity.LoginThrottle, error) {
+	return &entity.LoginThrottle{Scope: scope, Key: key, Failures: 1, LastFailureAt: at}, nil
+}
+
+func (m *mockUserRepoForNotificationHandler) LockLogin(ctx context.Context, scope entity.LoginThrottleScope, key string, failures int, until time.Time) (*entity.LoginThrottle, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockUserRepoForNotificationHandler) DeleteLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) error {
+	return nil
+}
+
+func (m *mockUserRepoForNotificationHandler) FindLockedLoginThrottles(ctx context.Context, at time.Time) ([]*entity.LoginThrottle, error) {
+	return nil, nil
+}
+
 func setupNotificationHandler() (*NotificationHandler, *mockNotificationRepoForHandler) {
 	repo := newMockNotificationRepoForHandler()
 	userRepo := &mockUserRepoForNotificationHandler{}
@@ -397,6 +464,89 @@ func TestNotificationHandler_MarkAllAsRead(t *testing.T) {
 	}
 }
 
+func TestNotificationHandler_DeleteNotification(t *testing.T) {
+	handler, repo := setupNotificationHandler()
+	router := setupNotificationRouter(handler)
+
+	repo.notifications["notif-1"] = &entity.Notification{ID: "notif-1", UserID: "test-user-id"}
+	repo.notifications["notif-2"] = &entity.Notification{ID: "notif-2", UserID: "other-user"}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/v1/notifications/notif-1", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := repo.notifications["notif-1"]; ok {
+		t.Error("Notification should be deleted")
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v1/notifications/notif-2", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, ok := repo.notifications["notif-2"]; !ok {
+		t.Error("Notification of another user should be kept")
+	}
+}
+
+func TestNotificationHandler_DeleteNotifications(t *testing.T) {
+	handler, repo := setupNotificationHandler()
+	router := setupNotificationRouter(handler)
+
+	repo.notifications["notif-1"] = &entity.Notification{ID: "notif-1", UserID: "test-user-id"}
+	repo.notifications["notif-2"] = &entity.Notification{ID: "notif-2", UserID: "test-user-id", Read: true}
+	repo.notifications["notif-3"] = &entity.Notification{ID: "notif-3", UserID: "test-user-id"}
+	repo.notifications["notif-4"] = &entity.Notification{ID: "notif-4", UserID: "other-user", Read: true}
+
+	do := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/api/v1/notifications/delete", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(`{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a selection, got %d", w.Code)
+	}
+	if w := do(`{"ids":[` + strings.Repeat(`"x",`, 500) + `"x"]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 above the limit, got %d", w.Code)
+	}
+
+	w := do(`{"ids":["notif-1","notif-4"],"read":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Body.String() != `{"deleted":2}` {
+		t.Errorf("Unexpected response: %s", w.Body.String())
+	}
+	if len(repo.notifications) != 2 || repo.notifications["notif-3"] == nil || repo.notifications["notif-4"] == nil {
+		t.Errorf("Expected the unread and foreign notifications to be kept, got %v", repo.notifications)
+	}
+}
+
+func TestNotificationHandler_DeleteNotifications_Error(t *testing.T) {
+	handler := setupErrorNotificationHandler(&errorNotificationRepo{deleteNotificationsErr: errors.New("db error")})
+	router := setupNotificationRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/api/v1/notifications/notif-1", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/notifications/delete", bytes.NewBufferString(`{"read":true}`))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
 func TestNotificationHandler_GetSettings(t *testing.T) {
 	handler, repo := setupNotificationHandler()
 	router := setupNotificationRouter(handler)
@@ -404,7 +554,6 @@ func TestNotificationHandler_GetSettings(t *testing.T) {
 	repo.settings["settings-1"] = &entity.NotificationSettings{
 		ID:           "settings-1",
 		UserID:       "test-user-id",
-		Channel:      entity.ChannelEmail,
 		Enabled:      true,
 		EmailAddress: "test@example.com",
 	}
@@ -463,6 +612,43 @@ func TestNotificationHandler_CreateSettings(t *testing.T) {
 	}
 }
 
+func TestNotificationHandler_CreateSettings_Preferences(t *testing.T) {
+	handler, _ := setupNotificationHandler()
+	router := setupNotificationRouter(handler)
+
+	body := `{
+		"enabled": true,
+		"email_address": "test@example.com",
+		"webhook_url": "https://example.com/hook",
+		"preferences": {
+			"execution_failed": ["email", "webhook"],
+			"execution_completed": ["webhook"],
+			"execution_started": []
+		}
+	}`
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/notifications/settings", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var settings entity.NotificationSettings
+	if err := json.Unmarshal(w.Body.Bytes(), &settings); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	prefs := settings.Preferences
+	if !prefs.Has(entity.NotificationExecutionFailed, entity.ChannelEmail) || !prefs.Has(entity.NotificationExecutionFailed, entity.ChannelWebhook) {
+		t.Errorf("Failures should be delivered on both channels, got %v", prefs)
+	}
+	if prefs.Has(entity.NotificationExecutionCompleted, entity.ChannelEmail) || prefs.Wants(entity.NotificationExecutionStarted) {
+		t.Errorf("Unexpected preferences: %v", prefs)
+	}
+}
+
 func TestNotificationHandler_CreateSettings_InvalidChannel(t *testing.T) {
 	handler, _ := setupNotificationHandler()
 	router := setupNotificationRouter(handler)
@@ -490,7 +676,6 @@ func TestNotificationHandler_UpdateSettings(t *testing.T) {
 	repo.settings["settings-1"] = &entity.NotificationSettings{
 		ID:           "settings-1",
 		UserID:       "test-user-id",
-		Channel:      entity.ChannelEmail,
 		Enabled:      true,
 		EmailAddress: "old@example.com",
 	}
@@ -1082,6 +1267,44 @@ func TestNotificationSettingsRequest_Validate(t *testing.T) {
 			req:     NotificationSettingsRequest{Channel: "webhook", Enabled: true, WebhookURL: ""},
 			wantErr: true,
 		},
+		{
+			name:    "no preferences nor channel",
+			req:     NotificationSettingsRequest{Enabled: true},
+			wantErr: true,
+		},
+		{
+			name: "matrix with both channels",
+			req: NotificationSettingsRequest{
+				Enabled: true, EmailAddress: "test@example.com", WebhookURL: "https://example.com/hook",
+				Preferences: entity.NotificationPreferences{
+					entity.NotificationExecutionFailed:    {entity.ChannelEmail, entity.ChannelWebhook},
+					entity.NotificationExecutionCompleted: {entity.ChannelWebhook},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "matrix email without address",
+			req: NotificationSettingsRequest{
+				Enabled: true, WebhookURL: "https://example.com/hook",
+				Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "matrix unknown type",
+			req: NotificationSettingsRequest{
+				Preferences: entity.NotificationPreferences{"execution_paused": {entity.ChannelWebhook}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "matrix unknown channel",
+			req: NotificationSettingsRequest{
+				Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {"sms"}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1124,17 +1347,18 @@ func TestParseInt(t *testing.T) {
 
 // errorNotificationRepo is a mock that can be configured to return errors
 type errorNotificationRepo struct {
-	findSettingsByUserIDErr   error
-	findSettingsByUserIDVal   *entity.NotificationSettings
-	createSettingsErr         error
-	updateSettingsErr         error
-	deleteSettingsErr         error
-	findNotificationsByErr    error
-	findUnreadByUserIDErr     error
-	markAsReadErr             error
-	markAllAsReadErr          error
-	findNotificationByIDErr   error
-	findNotificationByIDVal   *entity.Notification
+	findSettingsByUserIDErr error
+	findSettingsByUserIDVal *entity.NotificationSettings
+	createSettingsErr       error
+	updateSettingsErr       error
+	deleteSettingsErr       error
+	findNotificationsByErr  error
+	findUnreadByUserIDErr   error
+	markAsReadErr           error
+	markAllAsReadErr        error
+	findNotificationByIDErr error
+	findNotificationByIDVal *entity.Notification
+	deleteNotificationsErr  error
 }
 
 func (m *errorNotificationRepo) CreateSettings(_ context.Context, _ *entity.NotificationSettings) error {
@@ -1189,6 +1413,30 @@ func (m *errorNotificationRepo) MarkAllAsRead(_ context.Context, _ string) error
 	return m.markAllAsReadErr
 }
 
+func (m *errorNotificationRepo) MarkDigestPending(_ context.Context, _ string) error {
+	return nil
+}
+
+func (m *errorNotificationRepo) FindDigestPending(_ context.Context, _ string) ([]*entity.Notification, error) {
+	return nil, nil
+}
+
+func (m *errorNotificationRepo) MarkDigestSent(_ context.Context, _ []string, _ time.Time) error {
+	return nil
+}
+
+func (m *errorNotificationRepo) DeleteNotifications(_ context.Context, _ string, _ []string) (int64, error) {
+	return 0, m.deleteNotificationsErr
+}
+
+func (m *errorNotificationRepo) DeleteReadNotifications(_ context.Context, _ string) (int64, error) {
+	return 0, m.deleteNotificationsErr
+}
+
+func (m *errorNotificationRepo) PurgeReadNotifications(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
 func setupErrorNotificationHandler(repo *errorNotificationRepo) *NotificationHandler {
 	userRepo := &mockUserRepoForNotificationHandler{}
 	service := application.NewNotificationService(repo, userRepo, nil, "https://localhost:8443", nil)
@@ -1498,7 +1746,6 @@ func TestNotificationHandler_UpdateSettings_UpdateServiceError(t *testing.T) {
 		findSettingsByUserIDVal: &entity.NotificationSettings{
 			ID:           "settings-1",
 			UserID:       "test-user-id",
-			Channel:      entity.ChannelEmail,
 			Enabled:      true,
 			EmailAddress: "old@example.com",
 		},
@@ -1715,6 +1962,99 @@ func TestNotificationHandler_GetSMTPConfig_WithConfig(t *testing.T) {
 	}
 }
 
+// mockSMTPConfigRepoForHandler implements repository.SMTPConfigRepository for handler tests
+type mockSMTPConfigRepoForHandler struct {
+	config *entity.SMTPConfig
+}
+
+func (m *mockSMTPConfigRepoForHandler) Get(ctx context.Context) (*entity.SMTPConfig, error) {
+	return m.config, nil
+}
+
+func (m *mockSMTPConfigRepoForHandler) Save(ctx context.Context, config *entity.SMTPConfig) error {
+	m.config = config
+	return nil
+}
+
+func (m *mockSMTPConfigRepoForHandler) Delete(ctx context.Context) error {
+	m.config = nil
+	return nil
+}
+
+func TestNotificationHandler_UpdateSMTPConfig(t *testing.T) {
+	env := &entity.SMTPConfig{Host: "env.example.com", Port: 25, From: "env@example.com", Source: entity.SMTPConfigFromEnv}
+	service := application.NewNotificationService(newMockNotificationRepoForHandler(), &mockUserRepoForNotificationHandler{}, env, "https://localhost:8443", nil)
+	store := &mockSMTPConfigRepoForHandler{}
+	key, _ := application.NewLocalMasterKey("master-key")
+	service.SetSMTPConfigStore(store, application.NewSecretService(nil, key))
+	router := setupNotificationRouterWithAdmin(NewNotificationHandler(service))
+
+	do := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/api/v1/notifications/smtp", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("PUT", `{"host":"smtp.example.com","port":587,"from":"not-an-address"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := do("PUT", `{"host":"smtp.example.com","port":587,"username":"mailer","password":"s3cret","from":"noreply@example.com"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "s3cret") {
+		t.Error("Password should not be exposed in response")
+	}
+	var config entity.SMTPConfig
+	if err := json.Unmarshal(w.Body.Bytes(), &config); err != nil || config.Host != "smtp.example.com" ||
+		config.Source != entity.SMTPConfigFromAPI || config.UpdatedBy != "test-user-id" {
+		t.Errorf("Unexpected configuration %s", w.Body.String())
+	}
+	if store.config == nil || store.config.Password == "s3cret" {
+		t.Error("Expected the configuration to be stored with its password encrypted")
+	}
+
+	w = do("DELETE", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if store.config != nil || service.GetSMTPConfig().Host != "env.example.com" {
+		t.Error("Expected the environment configuration back")
+	}
+}
+
+func TestNotificationHandler_UpdateSMTPConfig_StoreDisabled(t *testing.T) {
+	handler, _ := setupNotificationHandler()
+	router := setupNotificationRouterWithAdmin(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/v1/notifications/smtp",
+		bytes.NewBufferString(`{"host":"smtp.example.com","port":587,"from":"noreply@example.com"}`))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/v1/notifications/smtp", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Only admins set the configuration
+	router = setupNotificationRouter(handler)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/api/v1/notifications/smtp", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
+
 func TestNotificationHandler_TestSMTP_Unauthenticated_NoUserID(t *testing.T) {
 	handler, _ := setupNotificationHandler()
 	router := setupUnauthRouter(handler)
@@ -1942,8 +2282,8 @@ func TestNotificationHandler_CreateSettings_WithAllFields(t *testing.T) {
 	if settings.ScoreAlertThreshold != 75.0 {
 		t.Errorf("ScoreAlertThreshold = %f, want 75.0", settings.ScoreAlertThreshold)
 	}
-	if !settings.NotifyOnAgentOffline {
-		t.Error("NotifyOnAgentOffline should be true")
+	if !settings.Preferences.Has(entity.NotificationAgentOffline, entity.ChannelEmail) {
+		t.Error("Agent offline notifications should be delivered by email")
 	}
 }
 
@@ -1987,11 +2327,97 @@ func TestNotificationSettingsRequest_Validate_WebhookMissingURL(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected validation error for missing webhook URL")
 	}
-	if err.Error() != "webhook URL is required when channel is webhook" {
+	if err.Error() != "webhook URL is required when webhook notifications are selected" {
 		t.Errorf("Unexpected error message: %s", err.Error())
 	}
 }
 
+func TestNotificationSettingsRequest_Validate_Teams(t *testing.T) {
+	prefs := entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelTeams}}
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{"missing URL", "", "a Teams webhook URL is required when Teams notifications are selected"},
+		{"invalid URL", "not a valid url with spaces", "invalid Teams webhook URL format"},
+		{"valid URL", "https://example.webhook.office.com/webhookb2/1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NotificationSettingsRequest{Enabled: true, Preferences: prefs, TeamsWebhookURL: tt.url}
+			err := req.Validate()
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestNotificationSettingsRequest_Validate_IncidentChannels(t *testing.T) {
+	prefs := entity.NotificationPreferences{entity.NotificationCriticalUndetected: {entity.ChannelPagerDuty, entity.ChannelOpsgenie}}
+
+	req := NotificationSettingsRequest{Enabled: true, Preferences: prefs, OpsgenieAPIKey: "genie-key"}
+	if err := req.Validate(); err == nil || err.Error() != "a PagerDuty routing key is required when PagerDuty notifications are selected" {
+		t.Errorf("Expected a routing key error, got %v", err)
+	}
+	req = NotificationSettingsRequest{Enabled: true, Preferences: prefs, PagerDutyRoutingKey: "routing-key"}
+	if err := req.Validate(); err == nil || err.Error() != "an Opsgenie API key is required when Opsgenie notifications are selected" {
+		t.Errorf("Expected an API key error, got %v", err)
+	}
+	req.OpsgenieAPIKey = "genie-key"
+	if err := req.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestNotificationSettingsRequest_Validate_WebhookSecret(t *testing.T) {
+	prefs := entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelWebhook}}
+
+	req := NotificationSettingsRequest{Enabled: true, Preferences: prefs, WebhookURL: "https://hooks.example.com", WebhookSecret: "short"}
+	if err := req.Validate(); err == nil || err.Error() != "webhook secret must be at least 16 characters" {
+		t.Errorf("Expected a short secret to be rejected, got %v", err)
+	}
+
+	req.WebhookSecret = "0123456789abcdef"
+	if err := req.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	req.WebhookSecret = ""
+	if err := req.Validate(); err != nil {
+		t.Errorf("Unsigned webhooks should stay allowed, got %v", err)
+	}
+}
+
+func TestNotificationSettingsRequest_Validate_QuietHoursAndDigest(t *testing.T) {
+	req := NotificationSettingsRequest{
+		Preferences: entity.NotificationPreferences{entity.NotificationExecutionFailed: {entity.ChannelEmail}},
+		QuietHours:  &entity.QuietHours{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"},
+	}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected an unknown timezone to be rejected")
+	}
+
+	req.QuietHours.Timezone = "Europe/Paris"
+	req.Digest = "weekly"
+	if err := req.Validate(); err == nil || err.Error() != "unknown digest: weekly" {
+		t.Errorf("Expected an unknown digest to be rejected, got %v", err)
+	}
+
+	req.Digest = entity.DigestDaily
+	if err := req.Validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
 func TestNotificationSettingsRequest_Validate_EmailMissingAddress(t *testing.T) {
 	req := NotificationSettingsRequest{
 		Channel:      "email",
@@ -2002,7 +2428,7 @@ func TestNotificationSettingsRequest_Validate_EmailMissingAddress(t *testing.T)
 	if err == nil {
 		t.Fatal("Expected validation error for missing email address")
 	}
-	if err.Error() != "email address is required when channel is email" {
+	if err.Error() != "email address is required when email notifications are selected" {
 		t.Errorf("Unexpected error message: %s", err.Error())
 	}
 }
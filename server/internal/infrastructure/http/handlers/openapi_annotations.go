@@ -0,0 +1,2885 @@
+// Code generated by openapi-gen from the handler annotations; DO NOT EDIT.
+
+package handlers
+
+import (
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/api/openapi"
+	"autostrike/internal/infrastructure/websocket"
+)
+
+// OpenAPIAnnotations documents the handler methods in the OpenAPI specification
+var OpenAPIAnnotations = map[string]openapi.Annotation{
+	"APIKeyHandler.CreateAPIKey": {
+		Summary:     "Create an API key",
+		Description: "Create an API key acting with the role of the current user, sent as X-API-Key or as a Bearer token. The key is returned once.",
+		Tags:        []string{"auth"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "API key", Model: (*CreateAPIKeyRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*CreatedAPIKey)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"APIKeyHandler.ListAPIKeys": {
+		Summary:     "List my API keys",
+		Description: "List the API keys of the current user, newest first, without their secret",
+		Tags:        []string{"auth"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.APIKey)(nil)},
+		},
+	},
+	"APIKeyHandler.RevokeAPIKey": {
+		Summary:     "Revoke an API key",
+		Description: "Delete an API key of the current user; administrators can revoke any key",
+		Tags:        []string{"auth"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "API key ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ActivityHandler.ListAnomalies": {
+		Summary:     "List activity anomalies",
+		Description: "List unusual operator activity (new login IP/country, off-hours executions, mass deletions), newest first",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 50, max: 500)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.ActivityAnomaly)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AdHocTaskHandler.GetTask": {
+		Summary:     "Get an ad-hoc command",
+		Description: "Get an ad-hoc command of an agent with its output once completed",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Task ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.AdHocTask)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AdHocTaskHandler.ListTasks": {
+		Summary:     "List the ad-hoc commands of an agent",
+		Description: "Audit trail of the one-off commands run on an agent, newest first",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 50, max: 500)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.AdHocTask)(nil)},
+		},
+	},
+	"AdHocTaskHandler.RunTask": {
+		Summary:     "Run an ad-hoc command on an agent",
+		Description: "Send a one-off command to a connected agent, outside any scenario. The command is recorded with the administrator who ran it; its result is broadcast over the WebSocket as adhoc_task_completed.",
+		Tags:        []string{"agents"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+			{Name: "request", In: "body", Required: true, Description: "Command", Model: (*application.AdHocTaskRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 202, Kind: "object", Model: (*entity.AdHocTask)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 423, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"AdminHandler.CreateUser": {
+		Summary:     "Create a user",
+		Description: "Create a user account with a role",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "User", Model: (*CreateUserRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*UserResponse)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"AdminHandler.DeactivateUser": {
+		Summary:     "Deactivate a user",
+		Description: "Deactivate a user account; the user can no longer log in",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AdminHandler.GetUser": {
+		Summary:     "Get a user",
+		Description: "Get a user account",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*UserResponse)(nil)},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AdminHandler.ListLockouts": {
+		Summary:     "List login lockouts",
+		Description: "List the usernames and source IPs locked out after repeated failed logins",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.LoginThrottle)(nil)},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AdminHandler.ListUsers": {
+		Summary:     "List users",
+		Description: "List every user account, active or not",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*ListUsersResponse)(nil)},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AdminHandler.ReactivateUser": {
+		Summary:     "Reactivate a user",
+		Description: "Reactivate a deactivated user account",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AdminHandler.ResetPassword": {
+		Summary:     "Reset the password of a user",
+		Description: "Set a new password for a user",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+			{Name: "request", In: "body", Required: true, Description: "New password", Model: (*ResetPasswordRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AdminHandler.SetUserWorkspace": {
+		Summary:     "Move a user to a workspace",
+		Description: "Move a user to a workspace, whose users share the per-workspace execution quota. An empty workspace removes the user from their workspace. Takes effect at the next login or token refresh.",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+			{Name: "request", In: "body", Required: true, Description: "Workspace", Model: (*SetWorkspaceRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*UserResponse)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AdminHandler.UnlockIP": {
+		Summary:     "Unlock a source IP",
+		Description: "Lift the lockout of a source IP after repeated failed logins and forget its failures",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "ip", In: "path", Type: "string", Required: true, Description: "Source IP address"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AdminHandler.UnlockUser": {
+		Summary:     "Unlock a user",
+		Description: "Lift the lockout of a user after repeated failed logins and forget their failures",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AdminHandler.UpdateUser": {
+		Summary:     "Update a user",
+		Description: "Update the username, email or role of a user; omitted fields are kept",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+			{Name: "request", In: "body", Required: true, Description: "Fields to update", Model: (*UpdateUserRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*UserResponse)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"AdminHandler.UpdateUserRole": {
+		Summary:     "Change the role of a user",
+		Description: "Change the role of a user, which sets their permissions",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID"},
+			{Name: "request", In: "body", Required: true, Description: "Role", Model: (*UpdateRoleRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*UserResponse)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AgentHandler.ApplyBulk": {
+		Summary:     "Apply a bulk agent operation",
+		Description: "Preview the operation again and apply it with the checksum of the reviewed preview. Nothing is changed when the agents it would change differ from the preview.",
+		Tags:        []string{"agents"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Bulk operation and checksum of its preview", Model: (*AgentBulkRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.AgentBulkResult)(nil)},
+			{Code: 207, Kind: "object", Model: (*application.AgentBulkResult)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.DeleteAgent": {
+		Summary:     "Delete an agent",
+		Description: "Delete an agent",
+		Tags:        []string{"agents"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.GetAgent": {
+		Summary:     "Get an agent",
+		Description: "Get an agent with its inventory",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Agent)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AgentHandler.GetAvailability": {
+		Summary:     "Get the availability of an agent",
+		Description: "Get the times an agent went online or offline over the last days, with its uptime. Transitions of a flapping agent are recorded as suppressed, and not announced until it settles.",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+			{Name: "days", In: "query", Type: "integer", Description: "Days of history, 7 by default, up to 90"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.AgentAvailability)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.GetHealth": {
+		Summary:     "Get the beacon health of an agent",
+		Description: "Rate how reliably an agent checked in over the last days: adherence to its beacon interval, missed beacons, gaps, latency and uptime. An agent is degraded when it falls short of a threshold, and unknown without check-ins.",
+		Tags:        []string{"analytics"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+			{Name: "days", In: "query", Type: "integer", Description: "Days of history, 1 by default, up to 30"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.AgentHealth)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.Heartbeat": {
+		Summary:     "Record an agent heartbeat",
+		Description: "Update the last time an agent was seen",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.ListAgents": {
+		Summary:     "List agents",
+		Description: "List the online agents, or every agent with all=true, filtered on their inventory. Repeated filter values are alternatives, except interpreter (\"powershell\" or \"powershell:5\" for 5 or later) which must all be present.",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "all", In: "query", Type: "boolean", Description: "Include offline agents"},
+			{Name: "platform", In: "query", Type: "string", Description: "Platform (repeatable)"},
+			{Name: "executor", In: "query", Type: "string", Description: "Executor (repeatable)"},
+			{Name: "architecture", In: "query", Type: "string", Description: "Architecture (repeatable)"},
+			{Name: "elevated", In: "query", Type: "boolean", Description: "Running elevated"},
+			{Name: "domain", In: "query", Type: "string", Description: "Domain (repeatable)"},
+			{Name: "security_product", In: "query", Type: "string", Description: "Security product (repeatable)"},
+			{Name: "interpreter", In: "query", Type: "string", Description: "Interpreter with an optional minimum version (repeatable)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Agent)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.PreviewBulk": {
+		Summary:     "Preview a bulk agent operation",
+		Description: "List the agents a bulk delete, deregister, tag or beacon operation would change, and the matching agents it would leave alone, with the checksum to apply it. Nothing is changed.",
+		Tags:        []string{"agents"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Bulk operation", Model: (*AgentBulkRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.AgentBulkPreview)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.RegisterAgent": {
+		Summary:     "Register an agent",
+		Description: "Register an agent, or update the registration of an agent with the same PAW",
+		Tags:        []string{"agents"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Agent", Model: (*RegisterAgentRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.Agent)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentHandler.SetAllowedNetworks": {
+		Summary:     "Pin the networks of an agent",
+		Description: "Set the networks, in CIDR notation, an agent is expected to connect from. A connection or heartbeat from another address raises an agent_unexpected_network security alert. An empty list expects any network.",
+		Tags:        []string{"agents"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent PAW"},
+			{Name: "request", In: "body", Required: true, Description: "Networks", Model: (*AllowedNetworksRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Agent)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentReleaseHandler.DeleteRelease": {
+		Summary:     "Delete an agent release",
+		Description: "Delete an agent release; agents are no longer offered it",
+		Tags:        []string{"agent-releases"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Release ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AgentReleaseHandler.ListReleases": {
+		Summary:     "List agent releases",
+		Description: "List the uploaded agent binaries and their rollout, without their content",
+		Tags:        []string{"agent-releases"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.AgentRelease)(nil)},
+		},
+	},
+	"AgentReleaseHandler.SetRollout": {
+		Summary:     "Set the rollout of an agent release",
+		Description: "Set the share of the platform's agents offered the release. Agents keep their place in the rollout, so raising it only adds agents; 0 pauses the release.",
+		Tags:        []string{"agent-releases"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Release ID"},
+			{Name: "request", In: "body", Required: true, Description: "Rollout", Model: (*RolloutRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.AgentRelease)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AgentReleaseHandler.UploadRelease": {
+		Summary:     "Upload an agent release",
+		Description: "Upload an agent binary signed with the release key. The signature, over the lines \"autostrike-agent-release\", version, hex SHA-256 and size of the binary, must verify with the configured public key.",
+		Tags:        []string{"agent-releases"},
+		Accept:      "multipart/form-data",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "file", In: "formData", Type: "file", Required: true, Description: "Agent binary"},
+			{Name: "version", In: "formData", Type: "string", Required: true, Description: "Version, MAJOR.MINOR.PATCH"},
+			{Name: "platform", In: "formData", Type: "string", Required: true, Description: "windows, linux or darwin"},
+			{Name: "signature", In: "formData", Type: "string", Required: true, Description: "Base64 Ed25519 signature of the release version, SHA-256 and size"},
+			{Name: "rollout_percent", In: "formData", Type: "integer", Description: "Share of the platform's agents offered the release, 0 by default"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.AgentRelease)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 413, Kind: "object"},
+			{Code: 422, Kind: "object"},
+		},
+	},
+	"AgentSelectorHandler.CreateSelector": {
+		Summary:     "Create agent selector",
+		Description: "Save a named agent selector reusable in execution launches and schedules",
+		Tags:        []string{"agent-selectors"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Selector name and criteria", Model: (*AgentSelectorRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.AgentSelector)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"AgentSelectorHandler.DeleteSelector": {
+		Summary:     "Delete agent selector",
+		Description: "Delete a saved agent selector",
+		Tags:        []string{"agent-selectors"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Selector ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AgentSelectorHandler.GetMatchingAgents": {
+		Summary:     "Preview agent selector",
+		Description: "List the registered agents a saved selector currently matches, online or not",
+		Tags:        []string{"agent-selectors"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Selector ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Agent)(nil)},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentSelectorHandler.GetSelector": {
+		Summary:     "Get agent selector",
+		Description: "Get a saved agent selector",
+		Tags:        []string{"agent-selectors"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Selector ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.AgentSelector)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AgentSelectorHandler.ListSelectors": {
+		Summary:     "List agent selectors",
+		Description: "List the saved agent selectors, ordered by name",
+		Tags:        []string{"agent-selectors"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.AgentSelector)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AgentSelectorHandler.UpdateSelector": {
+		Summary:     "Update agent selector",
+		Description: "Replace the name and criteria of a saved agent selector; schedules using it pick up the change on their next run",
+		Tags:        []string{"agent-selectors"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Selector ID"},
+			{Name: "request", In: "body", Required: true, Description: "Selector name and criteria", Model: (*AgentSelectorRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.AgentSelector)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.CompareExecutions": {
+		Summary:     "Compare two executions",
+		Description: "Diff a run of a scenario against a baseline run of the same scenario, e.g. before and after a change of security controls: techniques that changed status, score delta overall and per tactic, agents added or removed",
+		Tags:        []string{"analytics"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Baseline execution ID"},
+			{Name: "other", In: "path", Type: "string", Required: true, Description: "Compared execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ExecutionDiff)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.CompareScores": {
+		Summary:     "Compare scores between periods",
+		Description: "Compare security scores between current and previous period",
+		Tags:        []string{"analytics"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "days", In: "query", Type: "integer", Description: "Period in days (default: 7)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ScoreComparison)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetExecutionCalendar": {
+		Summary:     "Get execution calendar",
+		Description: "Get the number of executions and the average score per day, for a calendar heatmap",
+		Tags:        []string{"analytics"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "start", In: "query", Type: "string", Description: "First day (YYYY-MM-DD, default: 364 days before end)"},
+			{Name: "end", In: "query", Type: "string", Description: "Last day (YYYY-MM-DD, default: today)"},
+			{Name: "scenario_id", In: "query", Type: "string", Description: "Comma-separated scenario IDs to include"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ExecutionCalendar)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetExecutionRegressions": {
+		Summary:     "Get detection regressions of an execution",
+		Description: "Compare an execution with the previous completed run of its scenario and list the techniques the defenses handled worse on an agent, e.g. blocked before and successful now",
+		Tags:        []string{"analytics"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.DetectionRegression)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetExecutionSummary": {
+		Summary:     "Get execution summary",
+		Description: "Get overall execution analytics summary",
+		Tags:        []string{"analytics"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "days", In: "query", Type: "integer", Description: "Number of days to analyze (default: 30)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ExecutionSummary)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetPeriodStats": {
+		Summary:     "Get period statistics",
+		Description: "Get statistics for a specific time period",
+		Tags:        []string{"analytics"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "start", In: "query", Type: "string", Required: true, Description: "Start date (RFC3339 format)"},
+			{Name: "end", In: "query", Type: "string", Required: true, Description: "End date (RFC3339 format)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.PeriodStats)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetScoreTimeline": {
+		Summary:     "Get score timeline",
+		Description: "Get the overall and per tactic security score of each execution completed over a period, oldest first, for one scenario or all of them",
+		Tags:        []string{"analytics"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "scenario_id", In: "query", Type: "string", Description: "Scenario ID, all scenarios when omitted"},
+			{Name: "days", In: "query", Type: "integer", Description: "Number of days to analyze (default: 90, max: 365)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ScoreTimeline)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetScoreTrend": {
+		Summary:     "Get score trend over time",
+		Description: "Get score trend data points over a specified period",
+		Tags:        []string{"analytics"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "days", In: "query", Type: "integer", Description: "Number of days to analyze (default: 30)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ScoreTrend)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetSigmaCoverage": {
+		Summary:     "Get Sigma rule coverage",
+		Description: "Report which executed techniques had their mapped Sigma rules fire, aggregated per tactic",
+		Tags:        []string{"analytics"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "days", In: "query", Type: "integer", Description: "Number of days to analyze (default: 30)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.SigmaCoverageReport)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"AnalyticsHandler.GetTechniqueStats": {
+		Summary:     "Get technique run statistics",
+		Description: "Aggregate the past runs of a technique, overall and per agent platform: success, blocked, detection and error rates, median duration, flakiness and last failure. Helps prune unreliable techniques.",
+		Tags:        []string{"analytics"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Technique ID"},
+			{Name: "days", In: "query", Type: "integer", Description: "Number of days to analyze (default: 90, max: 365)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.TechniqueStats)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ArtifactHandler.DownloadArtifact": {
+		Summary:     "Download an artifact",
+		Description: "Download a file the agent collected for a result",
+		Tags:        []string{"results"},
+		Produce:     "octet-stream",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+			{Name: "artifactId", In: "path", Type: "string", Required: true, Description: "Artifact ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "file"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ArtifactHandler.ListArtifacts": {
+		Summary:     "List the artifacts of a result",
+		Description: "List the files the agent collected while running the technique, oldest first, without their content",
+		Tags:        []string{"results"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Artifact)(nil)},
+		},
+	},
+	"AuthHandler.ForgotPassword": {
+		Summary:     "Request a password reset",
+		Description: "Email a single-use password reset link to the active user with this address. The response is the same whether or not an account was found.",
+		Tags:        []string{"auth"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Email address", Model: (*ForgotPasswordRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 202, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 429, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"AuthHandler.Login": {
+		Summary:     "Log in",
+		Description: "Exchange a username and password for an access token and a refresh token. Repeated failures lock out the username and the source IP for a while, doubling with each lockout: logins then fail with 429 and Retry-After, even with the right password.",
+		Tags:        []string{"auth"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Credentials", Model: (*LoginRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.TokenResponse)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 429, Kind: "object"},
+		},
+	},
+	"AuthHandler.Logout": {
+		Summary:     "Log out",
+		Description: "Revoke the access token of the request until it expires",
+		Tags:        []string{"auth"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"AuthHandler.Me": {
+		Summary:     "Get the current user",
+		Description: "Get the user the access token was issued to",
+		Tags:        []string{"auth"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.User)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"AuthHandler.Refresh": {
+		Summary:     "Refresh the tokens",
+		Description: "Exchange a refresh token for new tokens",
+		Tags:        []string{"auth"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Refresh token", Model: (*RefreshRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.TokenResponse)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 429, Kind: "object"},
+		},
+	},
+	"AuthHandler.ResetPassword": {
+		Summary:     "Reset a password",
+		Description: "Set a new password with the token of a password reset email. The token is then spent, and the lockout of the username after failed logins lifted.",
+		Tags:        []string{"auth"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Reset token and new password", Model: (*PasswordResetRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 429, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"BeaconHandler.ClearAgentBeacon": {
+		Summary:     "Clear the beacon of an agent",
+		Description: "Remove the override of an agent, which falls back to its selector or the default",
+		Tags:        []string{"beacon"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent paw"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"BeaconHandler.ClearSelectorBeacon": {
+		Summary:     "Clear the beacon of an agent selector",
+		Description: "Remove the override of an agent selector",
+		Tags:        []string{"beacon"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Selector ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"BeaconHandler.GetAgentBeacon": {
+		Summary:     "Get the beacon of an agent",
+		Description: "Get the interval and jitter an agent uses, and whether they come from the agent, a selector or the default",
+		Tags:        []string{"beacon"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent paw"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ResolvedBeacon)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"BeaconHandler.ListSettings": {
+		Summary:     "List beacon settings",
+		Description: "Get the default beacon and the overrides of agents and agent selectors",
+		Tags:        []string{"beacon"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*BeaconSettingsResponse)(nil)},
+		},
+	},
+	"BeaconHandler.SetAgentBeacon": {
+		Summary:     "Set the beacon of an agent",
+		Description: "Set the interval and jitter of an agent, pushed to it on its next check-in",
+		Tags:        []string{"beacon"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "paw", In: "path", Type: "string", Required: true, Description: "Agent paw"},
+			{Name: "request", In: "body", Required: true, Description: "Interval (seconds) and jitter (percent)", Model: (*entity.BeaconSettings)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.BeaconOverride)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"BeaconHandler.SetSelectorBeacon": {
+		Summary:     "Set the beacon of an agent selector",
+		Description: "Set the interval and jitter of the agents matching a selector that have no override of their own",
+		Tags:        []string{"beacon"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Selector ID"},
+			{Name: "request", In: "body", Required: true, Description: "Interval (seconds) and jitter (percent)", Model: (*entity.BeaconSettings)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.BeaconOverride)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ConfigBundleHandler.ExportBundle": {
+		Summary:     "Export the configuration bundle",
+		Description: "Download the techniques, scenarios, agent selectors, schedules and beacon overrides as a versioned bundle, signed when a signing key is configured",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ConfigBundle)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ConfigBundleHandler.ImportBundle": {
+		Summary:     "Import a configuration bundle",
+		Description: "Create or update, by ID, the configuration of a bundle exported by another server. With dry_run=true nothing is written.",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "dry_run", In: "query", Type: "boolean", Description: "Only report what would change"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ConfigBundleImportResult)(nil)},
+			{Code: 207, Kind: "object", Model: (*application.ConfigBundleImportResult)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 413, Kind: "object"},
+		},
+	},
+	"ContentHandler.ImportContent": {
+		Summary:     "Import attack content",
+		Description: "Convert a Prelude Operator, Stratus Red Team, CTID emulation plan, Caldera, ATT&CK STIX or Atomic Red Team file into techniques and scenarios, reporting the techniques added, updated and deprecated",
+		Tags:        []string{"content"},
+		Accept:      "plain",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "format", In: "path", Type: "string", Required: true, Description: "Content format (prelude, stratus, ctid, caldera, stix, atomic)"},
+			{Name: "incremental", In: "query", Type: "boolean", Description: "Only write the techniques and scenarios that changed"},
+			{Name: "deprecate_removed", In: "query", Type: "boolean", Description: "Deprecate the techniques of the format the content no longer has"},
+			{Name: "platform", In: "query", Type: "string", Description: "Only import the techniques of this ATT&CK platform matrix: windows, linux, macos (repeatable)"},
+			{Name: "domain", In: "query", Type: "string", Description: "Only import the techniques of this ATT&CK domain: enterprise-attack, ics-attack, mobile-attack (repeatable, techniques without a domain are enterprise-attack)"},
+			{Name: "async", In: "query", Type: "boolean", Description: "Import in a background job, followed with GET /jobs/{id}"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ContentImportResult)(nil)},
+			{Code: 202, Kind: "object", Model: (*entity.Job)(nil)},
+			{Code: 207, Kind: "object", Model: (*application.ContentImportResult)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 413, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"ContentHandler.ListFormats": {
+		Summary:     "List content formats",
+		Description: "List the attack-content formats that can be imported",
+		Tags:        []string{"content"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+		},
+	},
+	"ContentPlanHandler.ApplyContent": {
+		Summary:     "Apply content changes",
+		Description: "Plan the content again and apply the changes. With the checksum of a reviewed plan, nothing is applied when the plan changed since.",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Content to apply", Model: (*ContentPlanRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ContentApplyResult)(nil)},
+			{Code: 207, Kind: "object", Model: (*application.ContentApplyResult)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 413, Kind: "object"},
+		},
+	},
+	"ContentPlanHandler.PlanContent": {
+		Summary:     "Plan content changes",
+		Description: "Diff techniques and scenarios against the database and list what applying them would create, update, restore from the trash or, with prune, delete. Nothing is written.",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Content to plan", Model: (*ContentPlanRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ContentPlan)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 413, Kind: "object"},
+		},
+	},
+	"ContentReloadHandler.ReloadContent": {
+		Summary:     "Reload techniques and scenarios",
+		Description: "Apply the technique and scenario YAML files of configs/ changed since they were last loaded, or every file with all=true, and return the outcome of each file. Nothing is deleted.",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "all", In: "query", Type: "boolean", Description: "Reload every file, changed or not"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*ContentReloadResponse)(nil)},
+			{Code: 207, Kind: "object", Model: (*ContentReloadResponse)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"DeepLinkHandler.ResolveLink": {
+		Summary:     "Resolve notification link",
+		Description: "Check a signed notification link for the current user and return the dashboard page it opens",
+		Tags:        []string{"notifications"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "Link token"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.DeepLinkTarget)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 410, Kind: "object"},
+		},
+	},
+	"DetectionHandler.GetStatus": {
+		Summary:     "Get detection verification status",
+		Description: "Returns whether detection verification is enabled and which SIEM/EDR connectors are configured",
+		Tags:        []string{"detection"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"DetectionHandler.VerifyExecution": {
+		Summary:     "Verify detections for an execution",
+		Description: "Queries the configured EDRs and SIEMs for every executed technique and updates results and score",
+		Tags:        []string{"detection"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.ExecutionResult)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"EmergencyStopHandler.GetStatus": {
+		Summary:     "Get the emergency stop status",
+		Description: "Tells whether the emergency stop is engaged, with the trip that engaged it",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*EmergencyStopStatus)(nil)},
+		},
+	},
+	"EmergencyStopHandler.ListHistory": {
+		Summary:     "List the emergency stop trips",
+		Description: "List the latest trips of the emergency stop, newest first",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "limit", In: "query", Type: "integer", Description: "Maximum trips (default 50, max 500)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.EmergencyStop)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"EmergencyStopHandler.Rearm": {
+		Summary:     "Re-arm the emergency stop",
+		Description: "Lets executions and ad-hoc tasks start again. Schedules paused by the stop stay paused.",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.EmergencyStop)(nil)},
+			{Code: 409, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"EmergencyStopHandler.Trip": {
+		Summary:     "Trip the emergency stop",
+		Description: "Tells every agent to kill its running commands, cancels every active execution and pauses every active schedule. No execution or ad-hoc task starts until the stop is re-armed. Tripping an engaged stop enforces it again.",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Description: "Reason", Model: (*EmergencyStopRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.EmergencyStop)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"EventStreamHandler.Stream": {
+		Summary:     "Stream dashboard events",
+		Description: "Server-Sent Events fallback of the dashboard WebSocket, emitting the same event types. Clients resuming after a disconnection send the Last-Event-ID header and receive the events they missed, or a resync event when some are no longer kept.",
+		Tags:        []string{"events"},
+		Produce:     "text/event-stream",
+		Params: []openapi.ParamAnnotation{
+			{Name: "Last-Event-ID", In: "header", Type: "string", Description: "ID of the last event received"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200},
+			{Code: 400, Kind: "object"},
+		},
+	},
+	"EvidenceHandler.AttachEvidence": {
+		Summary:     "Attach evidence to a result",
+		Description: "Upload a screenshot, packet capture, PDF, text file or archive as proof of the outcome of a result. The type is detected from the content; an optional sha256 field is checked against the upload.",
+		Tags:        []string{"results"},
+		Accept:      "multipart/form-data",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+			{Name: "file", In: "formData", Type: "file", Required: true, Description: "Evidence file"},
+			{Name: "description", In: "formData", Type: "string", Description: "What the file shows"},
+			{Name: "sha256", In: "formData", Type: "string", Description: "Hex SHA-256 of the file"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.Evidence)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 413, Kind: "object"},
+			{Code: 415, Kind: "object"},
+		},
+	},
+	"EvidenceHandler.DownloadEvidence": {
+		Summary:     "Download evidence",
+		Description: "Download a file attached to a result",
+		Tags:        []string{"results"},
+		Produce:     "octet-stream",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+			{Name: "evidenceId", In: "path", Type: "string", Required: true, Description: "Evidence ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "file"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"EvidenceHandler.ListEvidence": {
+		Summary:     "List the evidence of a result",
+		Description: "List the files operators attached to a result, oldest first, without their content",
+		Tags:        []string{"results"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Evidence)(nil)},
+		},
+	},
+	"ExecutionHandler.CompleteExecution": {
+		Summary:     "Complete an execution",
+		Description: "Mark an execution as completed and compute its score",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.ExportExecution": {
+		Summary:     "Export an execution with its results",
+		Description: "Returns the execution and its results; verbosity=full adds technique name, tactic, platforms and ATT&CK URL to each result",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+			{Name: "verbosity", In: "query", Type: "string", Description: "minimal (default) or full"},
+			{Name: "async", In: "query", Type: "boolean", Description: "Export in a background job, the export being its result"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ExecutionExport)(nil)},
+			{Code: 202, Kind: "object", Model: (*entity.Job)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.GetExecution": {
+		Summary:     "Get an execution",
+		Description: "Get an execution with its score and progress: steps done out of the total, current phase and an ETA estimated from the median durations of past runs",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Execution)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.GetFacts": {
+		Summary:     "List the facts of an execution",
+		Description: "Returns the facts extracted from technique output during an execution, oldest first",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Fact)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.GetResultOutput": {
+		Summary:     "Read the output of a result",
+		Description: "Returns a page of the full output of a result, loaded from blob storage when it is kept there. Pages end on a character boundary; next_offset is the offset of the following page and is absent on the last one.",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+			{Name: "offset", In: "query", Type: "integer", Description: "Byte offset in the output (default 0)"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Bytes to return (default 65536, max 1048576)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ResultOutputPage)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.GetResults": {
+		Summary:     "List the results of an execution",
+		Description: "List the technique results of an execution. Outputs are cut to a preview; output_size gives the size of a shortened output, read with GET /results/{id}/output",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.ExecutionResult)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.GetSnapshot": {
+		Summary:     "Get the environment snapshot of an execution",
+		Description: "Returns the server settings, scenario, techniques and agent inventory captured when the execution was launched. The scenario and techniques modified or deleted since are flagged.",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ExecutionSnapshot)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.ListExecutions": {
+		Summary:     "List recent executions",
+		Description: "List the 50 most recent executions, with the counts of their results by status in result_summary. include=results also returns the results of each execution, outputs cut to a preview as in GET /executions/{id}/results.",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "include", In: "query", Type: "string", Description: "Related data to embed: results"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Execution)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.StartExecution": {
+		Summary:     "Start an execution",
+		Description: "Run a scenario on the listed agents, or on the online agents matching a saved selector",
+		Tags:        []string{"executions"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Execution", Model: (*StartExecutionRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.Execution)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 423, Kind: "object"},
+			{Code: 429, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ExecutionHandler.StopExecution": {
+		Summary:     "Stop an execution",
+		Description: "Cancel a pending or running execution",
+		Tags:        []string{"executions"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ExecutionReviewHandler.AssignReviewer": {
+		Summary:     "Assign the reviewer of an execution",
+		Description: "Hand a review not signed off yet to another reviewer",
+		Tags:        []string{"reviews"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+			{Name: "request", In: "body", Required: true, Description: "Reviewer", Model: (*ReviewerRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ReviewSummary)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ExecutionReviewHandler.GetReview": {
+		Summary:     "Get the review of an execution",
+		Description: "Get the review of an execution with the number of results and of results with a disposition",
+		Tags:        []string{"reviews"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ReviewSummary)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ExecutionReviewHandler.GetReviewSnapshot": {
+		Summary:     "Get the signed-off snapshot of an execution",
+		Description: "Get the export of the execution frozen when its review was signed off, with full technique metadata, annotations and the review",
+		Tags:        []string{"reviews"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ExecutionExport)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ExecutionReviewHandler.ListReviews": {
+		Summary:     "List execution reviews",
+		Description: "List the purple-team reviews, newest request first, without their snapshot",
+		Tags:        []string{"reviews"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string", Description: "Filter by status (needs_review, in_review, signed_off)"},
+			{Name: "reviewer", In: "query", Type: "string", Description: "Filter by reviewer user ID, or me"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 100, max: 500)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.ExecutionReview)(nil)},
+			{Code: 400, Kind: "object"},
+		},
+	},
+	"ExecutionReviewHandler.RequestReview": {
+		Summary:     "Request the review of an execution",
+		Description: "Submit a completed, failed or cancelled execution for review (needs_review), optionally assigned to a reviewer allowed to triage results",
+		Tags:        []string{"reviews"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+			{Name: "request", In: "body", Description: "Reviewer", Model: (*ReviewerRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*application.ReviewSummary)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ExecutionReviewHandler.SignOffReview": {
+		Summary:     "Sign off the review of an execution",
+		Description: "Approve a review in progress once every result has a triage verdict. The export of the execution is frozen and its annotations and evidence are locked.",
+		Tags:        []string{"reviews"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+			{Name: "request", In: "body", Description: "Notes", Model: (*SignOffRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ReviewSummary)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ExecutionReviewHandler.StartReview": {
+		Summary:     "Start the review of an execution",
+		Description: "Move a review to in_review. A review without reviewer is assigned to the caller; otherwise only its reviewer can start it.",
+		Tags:        []string{"reviews"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ReviewSummary)(nil)},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"HealthHandler.Liveness": {
+		Summary:     "Liveness probe",
+		Description: "Checks the in-process loops (scheduler, WebSocket hub). Returns 503 when the process should be restarted",
+		Tags:        []string{"health"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.HealthReport)(nil)},
+			{Code: 503, Kind: "object", Model: (*application.HealthReport)(nil)},
+		},
+	},
+	"HealthHandler.Readiness": {
+		Summary:     "Readiness probe",
+		Description: "Checks the liveness components plus the database and, when configured, SMTP. Returns 503 when a required component fails; optional failures report \"degraded\"",
+		Tags:        []string{"health"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.HealthReport)(nil)},
+			{Code: 503, Kind: "object", Model: (*application.HealthReport)(nil)},
+		},
+	},
+	"JobHandler.GetJob": {
+		Summary:     "Get a background job",
+		Description: "Get the status, progress and result of a background job of the current user; administrators get any job",
+		Tags:        []string{"jobs"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Job ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Job)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"JobHandler.ListJobs": {
+		Summary:     "List background jobs",
+		Description: "List the latest background jobs of the current user, newest first; administrators list every job",
+		Tags:        []string{"jobs"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "type", In: "query", Type: "string", Description: "Job type (content_import, execution_export, retention, technique_sync)"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Maximum jobs returned (default and max 100)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Job)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.CreateSettings": {
+		Summary:     "Create notification settings",
+		Description: "Create notification settings for the current user",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "body", In: "body", Required: true, Description: "Settings", Model: (*NotificationSettingsRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.NotificationSettings)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.DeleteNotification": {
+		Summary:     "Delete a notification",
+		Description: "Delete a notification of the current user",
+		Tags:        []string{"notifications"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Notification ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.DeleteNotifications": {
+		Summary:     "Delete notifications in bulk",
+		Description: "Delete the given notifications of the current user (at most 500), and all their read notifications when read is set",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Notifications to delete", Model: (*DeleteNotificationsRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.DeleteSMTPConfig": {
+		Summary:     "Delete SMTP configuration",
+		Description: "Delete the SMTP configuration set through the API and go back to the SMTP_* environment variables - admin only",
+		Tags:        []string{"notifications"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"NotificationHandler.DeleteSettings": {
+		Summary:     "Delete notification settings",
+		Description: "Delete notification settings for the current user",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.GetNotifications": {
+		Summary:     "Get notifications",
+		Description: "Get notifications for the current user",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 50)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Notification)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.GetSMTPConfig": {
+		Summary:     "Get SMTP configuration",
+		Description: "Get SMTP configuration (without password) - admin only",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.SMTPConfig)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"NotificationHandler.GetSettings": {
+		Summary:     "Get notification settings",
+		Description: "Get notification settings for the current user",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.NotificationSettings)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.GetUnreadCount": {
+		Summary:     "Get unread count",
+		Description: "Get count of unread notifications",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.MarkAllAsRead": {
+		Summary:     "Mark all notifications as read",
+		Description: "Mark all notifications as read for the current user",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.MarkAsRead": {
+		Summary:     "Mark notification as read",
+		Description: "Mark a notification as read",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Notification ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.TestSMTP": {
+		Summary:     "Test SMTP configuration",
+		Description: "Send a test email through the SMTP configuration in use, stored or from the environment - admin only",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "body", In: "body", Required: true, Description: "Test email address", Model: (*TestSMTPRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"NotificationHandler.UpdateSMTPConfig": {
+		Summary:     "Set SMTP configuration",
+		Description: "Store the SMTP configuration, password encrypted, and use it at once instead of the SMTP_* environment variables - admin only. An empty password keeps the current one.",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "body", In: "body", Required: true, Description: "SMTP configuration", Model: (*SMTPConfigRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.SMTPConfig)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"NotificationHandler.UpdateSettings": {
+		Summary:     "Update notification settings",
+		Description: "Update notification settings for the current user",
+		Tags:        []string{"notifications"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "body", In: "body", Required: true, Description: "Settings", Model: (*NotificationSettingsRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.NotificationSettings)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"OpenAPIHandler.GetSpec": {
+		Summary:     "Get the OpenAPI specification",
+		Description: "Get the OpenAPI 3 specification of every route of the API, to generate typed clients",
+		Tags:        []string{"system"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*openapi.Document)(nil)},
+		},
+	},
+	"OpenAPIHandler.SwaggerUI": {
+		Summary:     "Browse the API documentation",
+		Description: "Swagger UI page rendering the OpenAPI specification",
+		Tags:        []string{"system"},
+		Produce:     "html",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200},
+		},
+	},
+	"PayloadHandler.DeletePayload": {
+		Summary:     "Delete a payload",
+		Description: "Delete a payload no technique references",
+		Tags:        []string{"payloads"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "name", In: "path", Type: "string", Required: true, Description: "Payload name"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"PayloadHandler.DownloadPayload": {
+		Summary:     "Download a task payload",
+		Description: "Download the payload of a task. Agents authenticate with their X-Agent-Key and identify with X-Agent-Paw; the signed token of the URL sent with the task must have been issued to that agent, for a task it has not finished, and expires.",
+		Tags:        []string{"payloads"},
+		Produce:     "octet-stream",
+		Params: []openapi.ParamAnnotation{
+			{Name: "token", In: "path", Type: "string", Required: true, Description: "Payload token"},
+			{Name: "X-Agent-Key", In: "header", Type: "string", Required: true, Description: "Agent secret"},
+			{Name: "X-Agent-Paw", In: "header", Type: "string", Required: true, Description: "Paw of the agent running the task"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "file"},
+			{Code: 401, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"PayloadHandler.GetPayload": {
+		Summary:     "Get a payload",
+		Description: "Get a payload's name, size and SHA-256 by name",
+		Tags:        []string{"payloads"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "name", In: "path", Type: "string", Required: true, Description: "Payload name"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Payload)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"PayloadHandler.ListPayloads": {
+		Summary:     "List payloads",
+		Description: "List the stored payloads, without their content",
+		Tags:        []string{"payloads"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Payload)(nil)},
+		},
+	},
+	"PayloadHandler.UploadPayload": {
+		Summary:     "Upload a payload",
+		Description: "Upload a file techniques can deliver with \"payload: <name>\". The file is scanned when a malware scan is configured.",
+		Tags:        []string{"payloads"},
+		Accept:      "multipart/form-data",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "file", In: "formData", Type: "file", Required: true, Description: "Payload file"},
+			{Name: "name", In: "formData", Type: "string", Description: "Payload name, the file name by default"},
+			{Name: "description", In: "formData", Type: "string", Description: "Description"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.Payload)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 413, Kind: "object"},
+			{Code: 422, Kind: "object"},
+		},
+	},
+	"PermissionHandler.GetMyPermissions": {
+		Summary:     "Get my permissions",
+		Description: "Get the role of the current user and its permissions",
+		Tags:        []string{"permissions"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"PermissionHandler.GetPermissionMatrix": {
+		Summary:     "Get the permission matrix",
+		Description: "Get the roles, the permissions by category and the permissions of each role",
+		Tags:        []string{"permissions"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.PermissionMatrix)(nil)},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"PermissionHandler.GetRoles": {
+		Summary:     "List roles",
+		Description: "List the roles with their display names and permissions",
+		Tags:        []string{"permissions"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"ProfileHandler.GetProfile": {
+		Summary:     "Get my profile",
+		Description: "Get the profile and dashboard preferences of the current user",
+		Tags:        []string{"users"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.User)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ProfileHandler.UpdateProfile": {
+		Summary:     "Update my profile",
+		Description: "Change the display name, email, password, timezone or dashboard preferences of the current user. Changing the email or the password takes the current password.",
+		Tags:        []string{"users"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Profile changes", Model: (*UpdateProfileRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.User)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ReadinessHandler.GetReadiness": {
+		Summary:     "Get scenario readiness",
+		Description: "Scores how much of a scenario the online fleet can execute, listing missing platforms and unsafe techniques",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Scenario ID"},
+			{Name: "agents", In: "query", Type: "string", Description: "Comma-separated agent paws to evaluate instead of the whole online fleet"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ScenarioReadiness)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ResultAnnotationHandler.AddComment": {
+		Summary:     "Comment a result",
+		Description: "Add a free-text comment to a result, up to 4000 characters",
+		Tags:        []string{"results"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+			{Name: "request", In: "body", Required: true, Description: "Comment", Model: (*CommentRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.ResultComment)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ResultAnnotationHandler.DeleteComment": {
+		Summary:     "Delete a comment of a result",
+		Description: "Delete a comment. Only its author or an administrator can delete it.",
+		Tags:        []string{"results"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+			{Name: "commentId", In: "path", Type: "string", Required: true, Description: "Comment ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ResultAnnotationHandler.GetAnnotation": {
+		Summary:     "Get the annotation of a result",
+		Description: "Get the triage verdict, assignee and comments of a result. A result never annotated has an empty annotation.",
+		Tags:        []string{"results"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ResultAnnotation)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ResultAnnotationHandler.TriageResult": {
+		Summary:     "Triage a result",
+		Description: "Set the verdict of a result (true_positive, expected_block, needs_tuning) and the teammate assigned to follow it up. Empty values clear them; the assignee must be an active user.",
+		Tags:        []string{"results"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Result ID"},
+			{Name: "request", In: "body", Required: true, Description: "Verdict and assignee", Model: (*TriageRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ResultAnnotation)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ResultQueueHandler.GetStats": {
+		Summary:     "Get the result queue state",
+		Description: "Get the results waiting to be written, the queue capacity and high-water mark, the results enqueued, written and refused while the queue was full since the server started, and the last write",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ResultQueueStats)(nil)},
+		},
+	},
+	"RetentionHandler.GetStatus": {
+		Summary:     "Get the execution retention status",
+		Description: "Get the retention policies, the archive location, the next and last runs of the nightly job and the rows reclaimed since the server started",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.RetentionStatus)(nil)},
+		},
+	},
+	"RetentionHandler.RunNow": {
+		Summary:     "Apply the execution retention now",
+		Description: "Archive and remove the execution data older than the retention policies without waiting for the nightly job",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "async", In: "query", Type: "boolean", Description: "Run in a background job, the run report being its result"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.RetentionRun)(nil)},
+			{Code: 202, Kind: "object", Model: (*entity.Job)(nil)},
+			{Code: 409, Kind: "object"},
+			{Code: 500, Kind: "object"},
+			{Code: 503, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.CloneScenario": {
+		Summary:     "Clone a scenario",
+		Description: "Creates an editable copy of a scenario or built-in template, named after the source unless a name is given",
+		Tags:        []string{"scenarios"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Scenario ID"},
+			{Name: "request", In: "body", Description: "Name of the copy", Model: (*CloneScenarioRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.Scenario)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.CreateScenario": {
+		Summary:     "Create a scenario",
+		Description: "Create a scenario from phases of techniques",
+		Tags:        []string{"scenarios"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Scenario", Model: (*CreateScenarioRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.Scenario)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.DeleteScenario": {
+		Summary:     "Delete a scenario",
+		Description: "Move a scenario to the trash, from which it can be restored. Built-in templates cannot be deleted.",
+		Tags:        []string{"scenarios"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Scenario ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 403, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.ExportScenario": {
+		Summary:     "Export a scenario",
+		Description: "Download a scenario as a JSON file",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Scenario ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*ScenarioExport)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.ExportScenarios": {
+		Summary:     "Export scenarios",
+		Description: "Download every scenario, or the ones listed in ids, as a JSON file",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "ids", In: "query", Type: "string", Description: "Comma-separated scenario IDs"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*ScenarioExport)(nil)},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.GetLifecycleReport": {
+		Summary:     "Get the technique lifecycle report",
+		Description: "List the scenarios that use deprecated or broken techniques",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*application.ScenarioLifecycleIssue)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.GetScenario": {
+		Summary:     "Get a scenario",
+		Description: "Get a scenario with its phases",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Scenario ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Scenario)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.GetScenariosByTag": {
+		Summary:     "List the scenarios with a tag",
+		Description: "List the scenarios with a tag",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "tag", In: "path", Type: "string", Required: true, Description: "Tag"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Scenario)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.ImportScenarios": {
+		Summary:     "Import scenarios",
+		Description: "Create the scenarios of an export file",
+		Tags:        []string{"scenarios"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Scenarios", Model: (*ImportScenariosRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*ImportScenariosResponse)(nil)},
+			{Code: 207, Kind: "object", Model: (*ImportScenariosResponse)(nil)},
+			{Code: 400, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.ListScenarios": {
+		Summary:     "List scenarios",
+		Description: "List the scenarios, or only the built-in templates (template=true) or only the editable scenarios (template=false)",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "template", In: "query", Type: "boolean", Description: "Templates only, or editable scenarios only"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Scenario)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.UpdateScenario": {
+		Summary:     "Update a scenario",
+		Description: "Replace the name, description, phases and tags of a scenario. Built-in templates cannot be changed.",
+		Tags:        []string{"scenarios"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Scenario ID"},
+			{Name: "request", In: "body", Required: true, Description: "Scenario", Model: (*UpdateScenarioRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Scenario)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 403, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScenarioHandler.ValidateScenario": {
+		Summary:     "Validate a scenario",
+		Description: "Checks a scenario without saving it: unknown or retired techniques, empty phases, circular run_if dependencies, techniques without an executor for the target platforms, invalid or unused phase executor preferences and techniques skipped in safe mode. Each error and warning carries a code and the phase and technique it is about.",
+		Tags:        []string{"scenarios"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Scenario and execution settings", Model: (*ValidateScenarioRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ScenarioValidation)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.Create": {
+		Summary:     "Create a schedule",
+		Description: "Create a new schedule",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "body", In: "body", Required: true, Description: "Schedule data", Model: (*CreateScheduleRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.Schedule)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.Delete": {
+		Summary:     "Delete a schedule",
+		Description: "Delete a schedule",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Schedule ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.GetAll": {
+		Summary:     "Get all schedules",
+		Description: "Get all schedules",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Schedule)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.GetByID": {
+		Summary:     "Get schedule by ID",
+		Description: "Get a schedule by its ID",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Schedule ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Schedule)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.GetRuns": {
+		Summary:     "Get schedule runs",
+		Description: "Get recent runs for a schedule",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Schedule ID"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 20)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.ScheduleRun)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.Pause": {
+		Summary:     "Pause a schedule",
+		Description: "Pause a schedule",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Schedule ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Schedule)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.Resume": {
+		Summary:     "Resume a schedule",
+		Description: "Resume a paused schedule",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Schedule ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Schedule)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.RunNow": {
+		Summary:     "Run schedule immediately",
+		Description: "Manually trigger a schedule to run now",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Schedule ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ScheduleRun)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScheduleHandler.Update": {
+		Summary:     "Update a schedule",
+		Description: "Update an existing schedule",
+		Tags:        []string{"schedules"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Schedule ID"},
+			{Name: "body", In: "body", Required: true, Description: "Schedule data", Model: (*CreateScheduleRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Schedule)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScoreBackfillHandler.GetJob": {
+		Summary:     "Get score recompute job",
+		Description: "Get the progress of a score recompute job and the score changes it made",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Job ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.RecomputeJob)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScoreBackfillHandler.GetScoreHistory": {
+		Summary:     "Get execution score history",
+		Description: "Compare the original and current score of an execution with every recomputation in between",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.ScoreHistory)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScoreBackfillHandler.ListJobs": {
+		Summary:     "List score recompute jobs",
+		Description: "List the score recompute jobs started since the server booted, newest first",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*application.RecomputeJob)(nil)},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"ScoreBackfillHandler.RescoreExecution": {
+		Summary:     "Rescore an execution",
+		Description: "Recalculate the score of a completed execution with the current version of a scoring profile, keeping the original score in its history",
+		Tags:        []string{"executions"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Execution ID"},
+			{Name: "request", In: "body", Description: "Scoring profile, the default one when omitted", Model: (*RescoreRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.RescoreResult)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ScoreBackfillHandler.RescoreExecutions": {
+		Summary:     "Rescore executions",
+		Description: "Recalculate the scores of up to 100 completed executions with the current version of a scoring profile, keeping the original scores in their history",
+		Tags:        []string{"executions"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Executions and scoring profile", Model: (*RescoreExecutionsRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.RescoreReport)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ScoreBackfillHandler.StartRecompute": {
+		Summary:     "Recompute historical scores",
+		Description: "Recompute the scores of completed executions in the background, keeping the overwritten scores",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Description: "Period, batch size and dry run flag", Model: (*RecomputeScoresRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 202, Kind: "object", Model: (*application.RecomputeJob)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.CreateProfile": {
+		Summary:     "Create scoring profile",
+		Description: "Save a scoring profile weighting techniques by tactic and severity, with partial credit for detected-but-not-blocked techniques",
+		Tags:        []string{"scoring-profiles"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Profile name, credits and weights", Model: (*ScoringProfileRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object", Model: (*entity.ScoringProfile)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.DeleteProfile": {
+		Summary:     "Delete scoring profile",
+		Description: "Remove a scoring profile from listings; executions scored with one of its versions keep it",
+		Tags:        []string{"scoring-profiles"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Profile ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.GetProfile": {
+		Summary:     "Get scoring profile",
+		Description: "Get the current version of a scoring profile",
+		Tags:        []string{"scoring-profiles"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Profile ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ScoringProfile)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.GetVersion": {
+		Summary:     "Get scoring profile version",
+		Description: "Get a version of a scoring profile, e.g. the one an execution is scored with",
+		Tags:        []string{"scoring-profiles"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Profile ID"},
+			{Name: "version", In: "path", Type: "integer", Required: true, Description: "Profile version"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ScoringProfile)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.ListProfiles": {
+		Summary:     "List scoring profiles",
+		Description: "List the current version of every scoring profile, ordered by name",
+		Tags:        []string{"scoring-profiles"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.ScoringProfile)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.ListVersions": {
+		Summary:     "List scoring profile versions",
+		Description: "List every version of a scoring profile, oldest first",
+		Tags:        []string{"scoring-profiles"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Profile ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.ScoringProfile)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.SetDefault": {
+		Summary:     "Set default scoring profile",
+		Description: "Score the executions started without a scoring profile with this one",
+		Tags:        []string{"scoring-profiles"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Profile ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ScoringProfile)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"ScoringProfileHandler.UpdateProfile": {
+		Summary:     "Update scoring profile",
+		Description: "Save a new version of a scoring profile; executions scored with earlier versions keep them",
+		Tags:        []string{"scoring-profiles"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Profile ID"},
+			{Name: "request", In: "body", Required: true, Description: "Profile name, credits and weights", Model: (*ScoringProfileRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.ScoringProfile)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"SearchHandler.Search": {
+		Summary:     "Search techniques, scenarios and results",
+		Description: "Full-text search of technique names, descriptions and commands, scenario names, descriptions and tags, and result outputs, best matches first. Every term must match; a term ending with * matches the words it prefixes. Only the types the role may view are searched.",
+		Tags:        []string{"search"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "q", In: "query", Type: "string", Required: true, Description: "Search terms"},
+			{Name: "type", In: "query", Type: "string", Description: "Comma-separated types to search: technique, scenario, result (default: all)"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 20, max: 100)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.SearchHit)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"SecretHandler.DeleteSecret": {
+		Summary:     "Delete a secret",
+		Description: "Delete a secret; the configuration values referring to it no longer resolve on the next start",
+		Tags:        []string{"admin"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "name", In: "path", Type: "string", Required: true, Description: "Secret name"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"SecretHandler.ListSecrets": {
+		Summary:     "List secrets",
+		Description: "List the secrets of the secrets store by name, without their value",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Secret)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"SecretHandler.PutSecret": {
+		Summary:     "Create or update a secret",
+		Description: "Encrypt and store the value of a secret, referred to as secret://{name} by configuration values. The value is never returned.",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "name", In: "path", Type: "string", Required: true, Description: "Secret name"},
+			{Name: "request", In: "body", Required: true, Description: "Secret", Model: (*PutSecretRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Secret)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.DeleteTechnique": {
+		Summary:     "Delete a technique",
+		Description: "Move a technique to the trash, from which it can be restored",
+		Tags:        []string{"techniques"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Technique ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.GetByPlatform": {
+		Summary:     "List the techniques of a platform",
+		Description: "List the techniques with an executor for a platform",
+		Tags:        []string{"techniques"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "platform", In: "path", Type: "string", Required: true, Description: "Platform, e.g. linux"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Technique)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.GetByTactic": {
+		Summary:     "List the techniques of a tactic",
+		Description: "List the techniques of a MITRE ATT&CK tactic",
+		Tags:        []string{"techniques"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "tactic", In: "path", Type: "string", Required: true, Description: "Tactic, e.g. discovery"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Technique)(nil)},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.GetCoverage": {
+		Summary:     "Get the ATT&CK coverage",
+		Description: "Count the techniques of each MITRE ATT&CK tactic",
+		Tags:        []string{"techniques"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.GetTechnique": {
+		Summary:     "Get a technique",
+		Description: "Get a technique with its executors",
+		Tags:        []string{"techniques"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Technique ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Technique)(nil)},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.ImportTechniques": {
+		Summary:     "Import techniques from a file",
+		Description: "Import the techniques of a YAML file on the server",
+		Tags:        []string{"techniques"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "File path", Model: (*ImportRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.ImportTechniquesJSON": {
+		Summary:     "Import techniques",
+		Description: "Create or update the techniques of the request",
+		Tags:        []string{"techniques"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Techniques", Model: (*ImportJSONRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*ImportJSONResponse)(nil)},
+			{Code: 400, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.ListTechniques": {
+		Summary:     "List techniques",
+		Description: "List the techniques, optionally filtered by lifecycle status, severity and custom fields (metadata.owner_team=red)",
+		Tags:        []string{"techniques"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string", Description: "Lifecycle status"},
+			{Name: "severity", In: "query", Type: "string", Description: "Severity: low, medium, high or critical"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Technique)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.UpdateTechniqueMetadata": {
+		Summary:     "Set the custom fields of a technique",
+		Description: "Replace the organization custom fields of a technique",
+		Tags:        []string{"techniques"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Technique ID"},
+			{Name: "request", In: "body", Required: true, Description: "Custom fields", Model: (*UpdateTechniqueMetadataRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Technique)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"TechniqueHandler.UpdateTechniqueStatus": {
+		Summary:     "Change the lifecycle status of a technique",
+		Description: "Move a technique to another lifecycle state",
+		Tags:        []string{"techniques"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Technique ID"},
+			{Name: "request", In: "body", Required: true, Description: "Status", Model: (*UpdateTechniqueStatusRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.Technique)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"TechniqueSyncHandler.GetJob": {
+		Summary:     "Get technique sync job",
+		Description: "Get the progress of a technique sync job, the version of each downloaded source and the import result",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Job ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*application.TechniqueSyncJob)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"TechniqueSyncHandler.ListJobs": {
+		Summary:     "List technique sync jobs",
+		Description: "List the technique sync jobs started since the server booted, newest first",
+		Tags:        []string{"admin"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*application.TechniqueSyncJob)(nil)},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"TechniqueSyncHandler.StartSync": {
+		Summary:     "Sync techniques from MITRE ATT&CK and Atomic Red Team",
+		Description: "Download the ATT&CK STIX bundle of each configured domain and the Atomic Red Team index in the background, give each ATT&CK technique the executors of its atomic tests (techniques without one become drafts) and import the merge incrementally. Curated techniques are left as they are, and nothing is imported when the upstream versions did not change since the last sync.",
+		Tags:        []string{"admin"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Description: "Whether to deprecate the techniques removed upstream", Model: (*SyncTechniquesRequest)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 202, Kind: "object", Model: (*application.TechniqueSyncJob)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 409, Kind: "object"},
+		},
+	},
+	"TicketHandler.ListTickets": {
+		Summary:     "List tickets",
+		Description: "List the most recent Jira or ServiceNow issues opened for techniques that ran undetected, newest first",
+		Tags:        []string{"tickets"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string", Description: "Filter by status (open, resolved)"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 100, max: 500)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Ticket)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"TicketHandler.SyncTickets": {
+		Summary:     "Sync tickets with the tracker",
+		Description: "Resolve the open tickets closed in the tracker or whose technique was since blocked or detected, without waiting for the periodic sync",
+		Tags:        []string{"tickets"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 502, Kind: "object"},
+		},
+	},
+	"TrashHandler.ListScenarios": {
+		Summary:     "List deleted scenarios",
+		Description: "Get the scenarios in the trash, most recently deleted first. They are purged once kept for longer than the trash retention, unless past executions still reference them.",
+		Tags:        []string{"scenarios"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Scenario)(nil)},
+		},
+	},
+	"TrashHandler.ListTechniques": {
+		Summary:     "List deleted techniques",
+		Description: "Get the techniques in the trash, most recently deleted first. They are purged once kept for longer than the trash retention, unless past results still reference them.",
+		Tags:        []string{"techniques"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.Technique)(nil)},
+		},
+	},
+	"TrashHandler.RestoreScenario": {
+		Summary:     "Restore a deleted scenario",
+		Description: "Take a scenario out of the trash",
+		Tags:        []string{"scenarios"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Scenario ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"TrashHandler.RestoreTechnique": {
+		Summary:     "Restore a deleted technique",
+		Description: "Take a technique out of the trash",
+		Tags:        []string{"techniques"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Technique ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"WebSocketHandler.ClosePollSession": {
+		Summary:     "Close an agent long-poll session",
+		Description: "End a session; its agent is disconnected",
+		Tags:        []string{"agents"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "session", In: "path", Type: "string", Required: true, Description: "Session ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 204},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"WebSocketHandler.OpenPollSession": {
+		Summary:     "Open an agent long-poll session",
+		Description: "Fallback for agents that cannot hold a WebSocket. The session carries the same messages as /ws/agent: the agent posts them one per request and polls for the server's. It expires when the agent stops polling.",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 201, Kind: "object"},
+			{Code: 401, Kind: "object"},
+		},
+	},
+	"WebSocketHandler.PollMessages": {
+		Summary:     "Poll the messages sent to an agent",
+		Description: "Wait until messages are sent to the agent of the session, or the wait elapses, and return them in order",
+		Tags:        []string{"agents"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "session", In: "path", Type: "string", Required: true, Description: "Session ID"},
+			{Name: "wait", In: "query", Type: "integer", Description: "Seconds to wait for a message (default 25, at most 30)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 410, Kind: "object"},
+		},
+	},
+	"WebSocketHandler.PostMessage": {
+		Summary:     "Send a message from an agent",
+		Description: "Handle a message of the agent of the session (register, heartbeat, task_result, ...) as if received over its WebSocket. Replies are returned by the next poll.",
+		Tags:        []string{"agents"},
+		Accept:      "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "session", In: "path", Type: "string", Required: true, Description: "Session ID"},
+			{Name: "message", In: "body", Required: true, Description: "Message", Model: (*websocket.Message)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 202},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"WebSocketHandler.PostTaskResults": {
+		Summary:     "Send a batch of task results from an agent",
+		Description: "Apply up to 100 results of the agent of a long-poll session in one call, as the task_results message does. Each result is acknowledged on its own: received, duplicate (a retried submission_id already applied) or failed with its error. Results that fail do not fail the others. While the result queue is full the batch is refused with 503 and a Retry-After header, to be resent unchanged.",
+		Tags:        []string{"agents"},
+		Accept:      "json",
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "session", In: "path", Type: "string", Required: true, Description: "Session ID"},
+			{Name: "results", In: "body", Required: true, Description: "Results", Model: (*TaskResultsPayload)(nil)},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*TaskResultsAck)(nil)},
+			{Code: 207, Kind: "object", Model: (*TaskResultsAck)(nil), Description: "Some results failed"},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+			{Code: 409, Kind: "object"},
+			{Code: 500, Kind: "object"},
+			{Code: 503, Kind: "object", Model: (*TaskResultsAck)(nil), Description: "Result queue full"},
+		},
+	},
+	"WebhookDeliveryHandler.GetDelivery": {
+		Summary:     "Get webhook delivery",
+		Description: "Get a webhook delivery of the current user, with its payload and last attempt outcome",
+		Tags:        []string{"notifications"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Delivery ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.WebhookDelivery)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+	"WebhookDeliveryHandler.ListDeliveries": {
+		Summary:     "List webhook deliveries",
+		Description: "List the most recent webhook and Teams deliveries of the current user, newest first",
+		Tags:        []string{"notifications"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string", Description: "Filter by status (pending, delivered, failed)"},
+			{Name: "limit", In: "query", Type: "integer", Description: "Limit (default: 50, max: 200)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "array", Model: (*entity.WebhookDelivery)(nil)},
+			{Code: 400, Kind: "object"},
+			{Code: 401, Kind: "object"},
+			{Code: 500, Kind: "object"},
+		},
+	},
+	"WebhookDeliveryHandler.Redeliver": {
+		Summary:     "Redeliver webhook",
+		Description: "Send a webhook delivery again right away, whatever its status",
+		Tags:        []string{"notifications"},
+		Produce:     "json",
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Delivery ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Code: 200, Kind: "object", Model: (*entity.WebhookDelivery)(nil)},
+			{Code: 401, Kind: "object"},
+			{Code: 404, Kind: "object"},
+		},
+	},
+}
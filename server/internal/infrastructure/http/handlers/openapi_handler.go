@@ -0,0 +1,76 @@
+package handlers
+
+//go:generate go run ../../../../cmd/openapi-gen
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"autostrike/internal/infrastructure/api/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIPage renders the specification with Swagger UI, loaded from a CDN
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>AutoStrike API</title>
+  <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    SwaggerUIBundle({ url: "/api/v1/openapi.json", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>`
+
+// swaggerUICSP lets the Swagger UI page load its assets from the CDN
+const swaggerUICSP = "default-src 'self'; script-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; " +
+	"style-src 'self' 'unsafe-inline' https://cdn.jsdelivr.net; img-src 'self' data: https://cdn.jsdelivr.net; connect-src 'self'"
+
+// OpenAPIHandler serves the OpenAPI specification of the REST API
+type OpenAPIHandler struct {
+	builder *openapi.Builder
+	routes  func() gin.RoutesInfo
+
+	once sync.Once
+	spec []byte
+}
+
+// NewOpenAPIHandler creates a handler documenting the routes returned by
+// routes. The specification is built on the first request, once every route
+// is registered.
+func NewOpenAPIHandler(builder *openapi.Builder, routes func() gin.RoutesInfo) *OpenAPIHandler {
+	return &OpenAPIHandler{builder: builder, routes: routes}
+}
+
+// GetSpec godoc
+// @Summary Get the OpenAPI specification
+// @Description Get the OpenAPI 3 specification of every route of the API, to generate typed clients
+// @Tags system
+// @Produce json
+// @Success 200 {object} openapi.Document
+// @Router /api/v1/openapi.json [get]
+func (h *OpenAPIHandler) GetSpec(c *gin.Context) {
+	h.once.Do(func() {
+		h.spec, _ = json.Marshal(h.builder.Build(h.routes()))
+	})
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.spec)
+}
+
+// SwaggerUI godoc
+// @Summary Browse the API documentation
+// @Description Swagger UI page rendering the OpenAPI specification
+// @Tags system
+// @Produce html
+// @Success 200
+// @Router /api/docs [get]
+func (h *OpenAPIHandler) SwaggerUI(c *gin.Context) {
+	c.Header("Content-Security-Policy", swaggerUICSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"autostrike/internal/infrastructure/api/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOpenAPIAnnotations_UpToDate(t *testing.T) {
+	want, err := openapi.GenerateAnnotations(".")
+	if err != nil {
+		t.Fatalf("GenerateAnnotations failed: %v", err)
+	}
+	got, err := os.ReadFile(openapi.GeneratedFile)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", openapi.GeneratedFile, err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s is out of date, run go generate ./internal/infrastructure/http/handlers/", openapi.GeneratedFile)
+	}
+}
+
+func TestOpenAPIHandler_GetSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewOpenAPIHandler(openapi.NewBuilder(openapi.Info{Title: "AutoStrike API", Version: "1.0"}, OpenAPIAnnotations), router.Routes)
+	router.GET("/api/v1/openapi.json", handler.GetSpec)
+	router.GET("/api/v1/agents", NewAgentHandler(nil).ListAgents)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode the specification: %v", err)
+	}
+	op := doc.Paths["/api/v1/agents"].Get
+	if op == nil || op.Summary != OpenAPIAnnotations["AgentHandler.ListAgents"].Summary {
+		t.Fatalf("expected the annotated agents operation, got %+v", op)
+	}
+	if doc.Components.Schemas["entity.Agent"] == nil {
+		t.Error("expected the agent schema")
+	}
+}
+
+func TestOpenAPIHandler_SwaggerUI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/docs", NewOpenAPIHandler(nil, router.Routes).SwaggerUI)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "/api/v1/openapi.json") {
+		t.Error("expected the page to load the specification")
+	}
+	if !strings.Contains(w.Header().Get("Content-Security-Policy"), "cdn.jsdelivr.net") {
+		t.Errorf("expected the CDN to be allowed, got %q", w.Header().Get("Content-Security-Policy"))
+	}
+}
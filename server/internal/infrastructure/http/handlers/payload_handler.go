@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PayloadHandler manages the payloads techniques deliver to agents
+type PayloadHandler struct {
+	service *application.PayloadService
+}
+
+// NewPayloadHandler creates a new payload handler
+func NewPayloadHandler(service *application.PayloadService) *PayloadHandler {
+	return &PayloadHandler{service: service}
+}
+
+// ListPayloads godoc
+// @Summary List payloads
+// @Description List the stored payloads, without their content
+// @Tags payloads
+// @Produce json
+// @Success 200 {array} entity.Payload
+// @Router /api/v1/payloads [get]
+func (h *PayloadHandler) ListPayloads(c *gin.Context) {
+	payloads, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list payloads"})
+		return
+	}
+	c.JSON(http.StatusOK, payloads)
+}
+
+// GetPayload godoc
+// @Summary Get a payload
+// @Description Get a payload's name, size and SHA-256 by name
+// @Tags payloads
+// @Produce json
+// @Param name path string true "Payload name"
+// @Success 200 {object} entity.Payload
+// @Failure 404 {object} gin.H
+// @Router /api/v1/payloads/{name} [get]
+func (h *PayloadHandler) GetPayload(c *gin.Context) {
+	payload, err := h.service.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, payload)
+}
+
+// UploadPayload godoc
+// @Summary Upload a payload
+// @Description Upload a file techniques can deliver with "payload: <name>". The file is scanned when a malware scan is configured.
+// @Tags payloads
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Payload file"
+// @Param name formData string false "Payload name, the file name by default"
+// @Param description formData string false "Description"
+// @Success 201 {object} entity.Payload
+// @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 413 {object} gin.H
+// @Failure 422 {object} gin.H
+// @Router /api/v1/payloads [post]
+func (h *PayloadHandler) UploadPayload(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a file is required"})
+		return
+	}
+	if header.Size > h.service.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("payload is larger than %d bytes", h.service.MaxSize())})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, h.service.MaxSize()+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = header.Filename
+	}
+
+	payload, err := h.service.Upload(c.Request.Context(), name, c.PostForm("description"), content, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, payload)
+}
+
+// DeletePayload godoc
+// @Summary Delete a payload
+// @Description Delete a payload no technique references
+// @Tags payloads
+// @Param name path string true "Payload name"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/payloads/{name} [delete]
+func (h *PayloadHandler) DeletePayload(c *gin.Context) {
+	if err := h.service.Delete(c.Request.Context(), c.Param("name")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DownloadPayload godoc
+// @Summary Download a task payload
+// @Description Download the payload of a task. Agents authenticate with their X-Agent-Key and identify with X-Agent-Paw; the signed token of the URL sent with the task must have been issued to that agent, for a task it has not finished, and expires.
+// @Tags payloads
+// @Produce octet-stream
+// @Param token path string true "Payload token"
+// @Param X-Agent-Key header string true "Agent secret"
+// @Param X-Agent-Paw header string true "Paw of the agent running the task"
+// @Success 200 {file} binary
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /payloads/{token} [get]
+func (h *PayloadHandler) DownloadPayload(c *gin.Context) {
+	payload, content, err := h.service.Download(c.Request.Context(), c.Param("token"), c.GetHeader("X-Agent-Paw"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", payload.Name))
+	c.Header("X-Payload-SHA256", payload.SHA256)
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/octet-stream", content)
+}
+
+// respondError maps a payload service error to a response
+func (h *PayloadHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidPayload):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrPayloadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrPayloadNameTaken), errors.Is(err, application.ErrPayloadInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrPayloadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrPayloadRejected):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidPayloadLink), errors.Is(err, application.ErrPayloadLinkExpired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrPayloadLinkDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "payload operation failed"})
+	}
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockPayloadRepo implements repository.PayloadRepository for tests
+type mockPayloadRepo struct {
+	payloads map[string]*entity.Payload
+	contents map[string][]byte
+}
+
+func newMockPayloadRepo() *mockPayloadRepo {
+	return &mockPayloadRepo{payloads: make(map[string]*entity.Payload), contents: make(map[string][]byte)}
+}
+
+func (m *mockPayloadRepo) Create(ctx context.Context, payload *entity.Payload, content []byte) error {
+	m.payloads[payload.ID] = payload
+	m.contents[payload.ID] = content
+	return nil
+}
+
+func (m *mockPayloadRepo) Delete(ctx context.Context, id string) error {
+	delete(m.payloads, id)
+	delete(m.contents, id)
+	return nil
+}
+
+func (m *mockPayloadRepo) FindByID(ctx context.Context, id string) (*entity.Payload, error) {
+	if payload, ok := m.payloads[id]; ok {
+		return payload, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockPayloadRepo) FindByName(ctx context.Context, name string) (*entity.Payload, error) {
+	for _, payload := range m.payloads {
+		if payload.Name == name {
+			return payload, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockPayloadRepo) FindAll(ctx context.Context) ([]*entity.Payload, error) {
+	var payloads []*entity.Payload
+	for _, payload := range m.payloads {
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+func (m *mockPayloadRepo) Content(ctx context.Context, id string) ([]byte, error) {
+	return m.contents[id], nil
+}
+
+func setupPayloadRouter() (*gin.Engine, *application.PayloadService) {
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1105"] = &entity.Technique{
+		ID:        "T1105",
+		Executors: []entity.Executor{{Type: "sh", Command: "curl -so /tmp/tool #{payload.url}", Payload: "used.sh"}},
+	}
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{
+		{ID: "result-1", ExecutionID: "exec-1", AgentPaw: "paw1", Status: entity.StatusRunning},
+	}
+	svc := application.NewPayloadService(newMockPayloadRepo(), techRepo, resultRepo, "secret", time.Hour)
+	svc.SetMaxSize(64)
+	handler := NewPayloadHandler(svc)
+
+	router := gin.New()
+	api := router.Group("/api/v1/payloads", func(c *gin.Context) { c.Set("user_id", "user-1") })
+	api.GET("", handler.ListPayloads)
+	api.GET("/:name", handler.GetPayload)
+	api.POST("", handler.UploadPayload)
+	api.DELETE("/:name", handler.DeletePayload)
+	router.GET("/payloads/:token", handler.DownloadPayload)
+	return router, svc
+}
+
+func uploadPayloadRequest(fields map[string]string, filename, content string) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		_ = writer.WriteField(key, value)
+	}
+	if filename != "" {
+		part, _ := writer.CreateFormFile("file", filename)
+		_, _ = part.Write([]byte(content))
+	}
+	_ = writer.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/payloads", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestPayloadHandler_UploadPayload(t *testing.T) {
+	router, _ := setupPayloadRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, uploadPayloadRequest(map[string]string{"description": "Test tool"}, "tool.sh", "echo tool"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d (%s)", w.Code, w.Body.String())
+	}
+	var payload entity.Payload
+	_ = json.Unmarshal(w.Body.Bytes(), &payload)
+	if payload.Name != "tool.sh" || payload.Description != "Test tool" || payload.Size != 9 || payload.SHA256 == "" || payload.UploadedBy != "user-1" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+
+	tests := []struct {
+		name     string
+		fields   map[string]string
+		filename string
+		content  string
+		code     int
+	}{
+		{"named", map[string]string{"name": "renamed.sh"}, "tool.sh", "x", http.StatusCreated},
+		{"no file", nil, "", "", http.StatusBadRequest},
+		{"invalid name", map[string]string{"name": "../tool"}, "tool.sh", "x", http.StatusBadRequest},
+		{"duplicate", nil, "tool.sh", "x", http.StatusConflict},
+		{"too large", nil, "large.bin", strings.Repeat("x", 65), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, uploadPayloadRequest(tt.fields, tt.filename, tt.content))
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/payloads", nil)
+	router.ServeHTTP(w, req)
+	var payloads []entity.Payload
+	_ = json.Unmarshal(w.Body.Bytes(), &payloads)
+	if w.Code != http.StatusOK || len(payloads) != 2 {
+		t.Errorf("Expected 2 payloads, got %d (%s)", w.Code, w.Body.String())
+	}
+}
+
+func TestPayloadHandler_GetAndDeletePayload(t *testing.T) {
+	router, svc := setupPayloadRouter()
+	ctx := context.Background()
+	_, _ = svc.Upload(ctx, "tool.sh", "", []byte("x"), "")
+	_, _ = svc.Upload(ctx, "used.sh", "", []byte("x"), "")
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodGet, "/api/v1/payloads/tool.sh", http.StatusOK},
+		{http.MethodGet, "/api/v1/payloads/missing", http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/payloads/used.sh", http.StatusConflict},
+		{http.MethodDelete, "/api/v1/payloads/tool.sh", http.StatusNoContent},
+		{http.MethodDelete, "/api/v1/payloads/tool.sh", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected %d, got %d (%s)", tt.method, tt.path, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestPayloadHandler_DownloadPayload(t *testing.T) {
+	router, svc := setupPayloadRouter()
+	uploaded, _ := svc.Upload(context.Background(), "tool.sh", "", []byte("echo tool"), "")
+	link, err := svc.Link(context.Background(), "tool.sh", "result-1", "paw1")
+	if err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, link.URL, nil)
+	req.Header.Set("X-Agent-Paw", "paw1")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "echo tool" {
+		t.Fatalf("Expected the payload content, got %d (%s)", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Payload-SHA256") != uploaded.SHA256 ||
+		w.Header().Get("Content-Disposition") != `attachment; filename="tool.sh"` {
+		t.Errorf("Unexpected headers: %v", w.Header())
+	}
+
+	tests := []struct {
+		name string
+		path string
+		paw  string
+		code int
+	}{
+		{"invalid token", "/payloads/invalid", "paw1", http.StatusUnauthorized},
+		{"other agent", link.URL, "paw2", http.StatusForbidden},
+		{"no agent", link.URL, "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.paw != "" {
+			req.Header.Set("X-Agent-Paw", tt.paw)
+		}
+		router.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.code, w.Code, w.Body.String())
+		}
+	}
+}
@@ -28,7 +28,14 @@ func (h *PermissionHandler) RegisterRoutes(r *gin.RouterGroup) {
 	}
 }
 
-// GetPermissionMatrix returns the complete permission matrix
+// GetPermissionMatrix godoc
+// @Summary Get the permission matrix
+// @Description Get the roles, the permissions by category and the permissions of each role
+// @Tags permissions
+// @Produce json
+// @Success 200 {object} entity.PermissionMatrix
+// @Failure 401 {object} gin.H
+// @Router /api/v1/permissions/matrix [get]
 func (h *PermissionHandler) GetPermissionMatrix(c *gin.Context) {
 	// Require authentication
 	_, exists := c.Get("user_id")
@@ -41,7 +48,14 @@ func (h *PermissionHandler) GetPermissionMatrix(c *gin.Context) {
 	c.JSON(http.StatusOK, matrix)
 }
 
-// GetMyPermissions returns the current user's permissions
+// GetMyPermissions godoc
+// @Summary Get my permissions
+// @Description Get the role of the current user and its permissions
+// @Tags permissions
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Router /api/v1/permissions/me [get]
 func (h *PermissionHandler) GetMyPermissions(c *gin.Context) {
 	// Require authentication - check both user_id and role
 	_, userExists := c.Get("user_id")
@@ -84,7 +98,14 @@ type RoleInfo struct {
 	Permissions []string `json:"permissions"`
 }
 
-// GetRoles returns all available roles with their permissions
+// GetRoles godoc
+// @Summary List roles
+// @Description List the roles with their display names and permissions
+// @Tags permissions
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Router /api/v1/permissions/roles [get]
 func (h *PermissionHandler) GetRoles(c *gin.Context) {
 	// Require authentication
 	_, exists := c.Get("user_id")
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfileHandler serves the profile of the current user, apart from the
+// admin user management
+type ProfileHandler struct {
+	service *application.AuthService
+}
+
+// NewProfileHandler creates a new profile handler
+func NewProfileHandler(service *application.AuthService) *ProfileHandler {
+	return &ProfileHandler{service: service}
+}
+
+// UpdateProfileRequest changes the profile of the current user. Omitted fields
+// are kept; changing the email or the password takes the current password.
+type UpdateProfileRequest struct {
+	DisplayName     *string                 `json:"display_name"`
+	Email           *string                 `json:"email" binding:"omitempty,email"`
+	Timezone        *string                 `json:"timezone"`
+	Preferences     *entity.UserPreferences `json:"preferences"`
+	CurrentPassword string                  `json:"current_password" binding:"max=72"`
+	NewPassword     string                  `json:"new_password" binding:"omitempty,min=8,max=72"`
+}
+
+// GetProfile godoc
+// @Summary Get my profile
+// @Description Get the profile and dashboard preferences of the current user
+// @Tags users
+// @Produce json
+// @Success 200 {object} entity.User
+// @Failure 404 {object} gin.H
+// @Router /api/v1/users/me [get]
+func (h *ProfileHandler) GetProfile(c *gin.Context) {
+	user, err := h.service.GetCurrentUser(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, application.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errUserNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateProfile godoc
+// @Summary Update my profile
+// @Description Change the display name, email, password, timezone or dashboard preferences of the current user. Changing the email or the password takes the current password.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body UpdateProfileRequest true "Profile changes"
+// @Success 200 {object} entity.User
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/users/me [put]
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	user, err := h.service.UpdateProfile(c.Request.Context(), c.GetString("user_id"), application.ProfileUpdate{
+		DisplayName:     req.DisplayName,
+		Email:           req.Email,
+		Timezone:        req.Timezone,
+		Preferences:     req.Preferences,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	})
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, user)
+	case errors.Is(err, application.ErrInvalidProfile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidCredentials):
+		c.JSON(http.StatusForbidden, gin.H{"error": "the current password is required to change the email or the password"})
+	case errors.Is(err, application.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": errUserNotFound})
+	case errors.Is(err, application.ErrUserAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "email already in use"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+	}
+}
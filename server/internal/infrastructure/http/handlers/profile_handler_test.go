@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProfileHandler_GetProfile_NotFound(t *testing.T) {
+	handler := NewProfileHandler(application.NewAuthService(newMockUserRepo(), "test-secret"))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "nonexistent")
+		c.Next()
+	})
+	router.GET("/api/v1/users/me", handler.GetProfile)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestProfileHandler_UpdateProfile(t *testing.T) {
+	repo := newMockUserRepo()
+	service := application.NewAuthService(repo, "test-secret")
+	user, _ := service.CreateUser(context.Background(), "testuser", "test@test.com", "password123", entity.RoleViewer)
+	_, _ = service.CreateUser(context.Background(), "other", "other@test.com", "password123", entity.RoleViewer)
+	handler := NewProfileHandler(service)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", user.ID)
+		c.Next()
+	})
+	router.GET("/api/v1/users/me", handler.GetProfile)
+	router.PUT("/api/v1/users/me", handler.UpdateProfile)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"invalid email", `{"email":"not-an-email"}`, http.StatusBadRequest},
+		{"short password", `{"current_password":"password123","new_password":"short"}`, http.StatusBadRequest},
+		{"unknown timezone", `{"timezone":"Mars/Olympus_Mons"}`, http.StatusBadRequest},
+		{"unknown theme", `{"preferences":{"theme":"pink"}}`, http.StatusBadRequest},
+		{"email without password", `{"email":"new@test.com"}`, http.StatusForbidden},
+		{"email in use", `{"email":"other@test.com","current_password":"password123"}`, http.StatusConflict},
+		{"profile", `{"display_name":"Test User","timezone":"Europe/Paris","preferences":{"landing_page":"/analytics","analytics_period":30,"theme":"dark"}}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/users/me", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var got entity.User
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode profile: %v", err)
+	}
+	if got.DisplayName != "Test User" || got.Timezone != "Europe/Paris" || got.Preferences.LandingPage != "/analytics" {
+		t.Errorf("Unexpected profile: %+v", got)
+	}
+	if got.Email != "test@test.com" {
+		t.Errorf("Email should be kept, got %q", got.Email)
+	}
+	if strings.Contains(w.Body.String(), "password") {
+		t.Error("The profile should not expose the password hash")
+	}
+}
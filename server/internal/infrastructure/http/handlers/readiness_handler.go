@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessHandler handles scenario readiness requests
+type ReadinessHandler struct {
+	service *application.ReadinessService
+}
+
+// NewReadinessHandler creates a new readiness handler
+func NewReadinessHandler(service *application.ReadinessService) *ReadinessHandler {
+	return &ReadinessHandler{service: service}
+}
+
+// RegisterRoutes registers readiness routes
+func (h *ReadinessHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.GET("/scenarios/:id/readiness", h.GetReadiness)
+}
+
+// GetReadiness godoc
+// @Summary Get scenario readiness
+// @Description Scores how much of a scenario the online fleet can execute, listing missing platforms and unsafe techniques
+// @Tags scenarios
+// @Produce json
+// @Param id path string true "Scenario ID"
+// @Param agents query string false "Comma-separated agent paws to evaluate instead of the whole online fleet"
+// @Success 200 {object} application.ScenarioReadiness
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scenarios/{id}/readiness [get]
+func (h *ReadinessHandler) GetReadiness(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errScenarioNotAuthenticated})
+		return
+	}
+
+	var paws []string
+	for _, paw := range strings.Split(c.Query("agents"), ",") {
+		if paw = strings.TrimSpace(paw); paw != "" {
+			paws = append(paws, paw)
+		}
+	}
+
+	readiness, err := h.service.GetReadiness(c.Request.Context(), c.Param("id"), paws)
+	if err != nil {
+		if errors.Is(err, application.ErrScenarioNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": errScenarioNotFound})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
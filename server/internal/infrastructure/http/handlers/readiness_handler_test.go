@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+func setupReadinessRouter(authenticated bool) *gin.Engine {
+	scenarioRepo := newMockScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "Discovery",
+		Phases: []entity.Phase{{Name: "p1", Techniques: []string{"T1082", "T1003"}}}}
+
+	techRepo := newMockTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", IsSafe: true,
+		Platforms: []string{"linux"}, Executors: []entity.Executor{{Type: "sh"}}}
+	techRepo.techniques["T1003"] = &entity.Technique{ID: "T1003",
+		Platforms: []string{"windows"}, Executors: []entity.Executor{{Type: "psh"}}}
+
+	agentRepo := newMockAgentRepo()
+	agentRepo.agents["lin-1"] = &entity.Agent{Paw: "lin-1", Platform: "linux", Executors: []string{"sh"}, Status: entity.AgentOnline}
+
+	handler := NewReadinessHandler(application.NewReadinessService(scenarioRepo, techRepo, agentRepo))
+
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", "test-user")
+			c.Next()
+		})
+	}
+	handler.RegisterRoutes(router.Group("/api/v1"))
+	return router
+}
+
+func TestReadinessHandler_GetReadiness(t *testing.T) {
+	router := setupReadinessRouter(true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/scenarios/s1/readiness", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body application.ScenarioReadiness
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if body.Score != 50 || len(body.MissingPlatforms) != 1 || body.MissingPlatforms[0] != "windows" {
+		t.Errorf("Unexpected readiness: %+v", body)
+	}
+	if len(body.UnsafeTechniques) != 1 || body.UnsafeTechniques[0] != "T1003" {
+		t.Errorf("Expected T1003 flagged unsafe, got %v", body.UnsafeTechniques)
+	}
+}
+
+func TestReadinessHandler_SelectedAgents(t *testing.T) {
+	router := setupReadinessRouter(true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/scenarios/s1/readiness?agents=unknown-paw", nil)
+	router.ServeHTTP(w, req)
+
+	var body application.ScenarioReadiness
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || body.OnlineAgents != 0 || body.Score != 0 {
+		t.Errorf("Expected no ready techniques for unknown agent, got %d %+v", w.Code, body)
+	}
+}
+
+func TestReadinessHandler_NotFound(t *testing.T) {
+	router := setupReadinessRouter(true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/scenarios/missing/readiness", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", w.Code)
+	}
+}
+
+func TestReadinessHandler_Unauthenticated(t *testing.T) {
+	router := setupReadinessRouter(false)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/scenarios/s1/readiness", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResultAnnotationHandler serves the analyst triage and comments of results
+type ResultAnnotationHandler struct {
+	service *application.ResultAnnotationService
+}
+
+// NewResultAnnotationHandler creates a new result annotation handler
+func NewResultAnnotationHandler(service *application.ResultAnnotationService) *ResultAnnotationHandler {
+	return &ResultAnnotationHandler{service: service}
+}
+
+// TriageRequest sets the verdict and assignee of a result. Empty values clear them.
+type TriageRequest struct {
+	Triage     entity.ResultTriage `json:"triage"` // true_positive, expected_block, needs_tuning or empty
+	AssigneeID string              `json:"assignee_id,omitempty"`
+}
+
+// CommentRequest is a comment left on a result
+type CommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// GetAnnotation godoc
+// @Summary Get the annotation of a result
+// @Description Get the triage verdict, assignee and comments of a result. A result never annotated has an empty annotation.
+// @Tags results
+// @Produce json
+// @Param id path string true "Result ID"
+// @Success 200 {object} entity.ResultAnnotation
+// @Failure 404 {object} gin.H
+// @Router /api/v1/results/{id}/annotation [get]
+func (h *ResultAnnotationHandler) GetAnnotation(c *gin.Context) {
+	annotation, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, annotation)
+}
+
+// TriageResult godoc
+// @Summary Triage a result
+// @Description Set the verdict of a result (true_positive, expected_block, needs_tuning) and the teammate assigned to follow it up. Empty values clear them; the assignee must be an active user.
+// @Tags results
+// @Accept json
+// @Produce json
+// @Param id path string true "Result ID"
+// @Param request body TriageRequest true "Verdict and assignee"
+// @Success 200 {object} entity.ResultAnnotation
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/results/{id}/annotation [put]
+func (h *ResultAnnotationHandler) TriageResult(c *gin.Context) {
+	var req TriageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	annotation, err := h.service.Triage(c.Request.Context(), c.Param("id"), req.Triage, req.AssigneeID, c.GetString("user_id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, annotation)
+}
+
+// AddComment godoc
+// @Summary Comment a result
+// @Description Add a free-text comment to a result, up to 4000 characters
+// @Tags results
+// @Accept json
+// @Produce json
+// @Param id path string true "Result ID"
+// @Param request body CommentRequest true "Comment"
+// @Success 201 {object} entity.ResultComment
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/results/{id}/annotation/comments [post]
+func (h *ResultAnnotationHandler) AddComment(c *gin.Context) {
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.AddComment(c.Request.Context(), c.Param("id"), c.GetString("user_id"), req.Body)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment of a result
+// @Description Delete a comment. Only its author or an administrator can delete it.
+// @Tags results
+// @Param id path string true "Result ID"
+// @Param commentId path string true "Comment ID"
+// @Success 204
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/results/{id}/annotation/comments/{commentId} [delete]
+func (h *ResultAnnotationHandler) DeleteComment(c *gin.Context) {
+	admin := c.GetString("role") == string(entity.RoleAdmin)
+	err := h.service.DeleteComment(c.Request.Context(), c.Param("id"), c.Param("commentId"), c.GetString("user_id"), admin)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondError maps annotation errors to HTTP statuses
+func (h *ResultAnnotationHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrResultNotFound), errors.Is(err, application.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidAnnotation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrCommentNotDeleteable):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrReviewLocked):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update annotation"})
+	}
+}
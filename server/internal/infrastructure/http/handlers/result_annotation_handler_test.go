@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockResultAnnotationRepo implements repository.ResultAnnotationRepository for tests
+type mockResultAnnotationRepo struct {
+	annotations map[string]*entity.ResultAnnotation
+	comments    []*entity.ResultComment
+}
+
+func (m *mockResultAnnotationRepo) Get(ctx context.Context, resultID string) (*entity.ResultAnnotation, error) {
+	annotation, ok := m.annotations[resultID]
+	if !ok {
+		annotation = &entity.ResultAnnotation{ResultID: resultID}
+	}
+	annotation.Comments = []entity.ResultComment{}
+	for _, comment := range m.comments {
+		if comment.ResultID == resultID {
+			annotation.Comments = append(annotation.Comments, *comment)
+		}
+	}
+	return annotation, nil
+}
+
+func (m *mockResultAnnotationRepo) FindByExecution(ctx context.Context, executionID string) ([]*entity.ResultAnnotation, error) {
+	return nil, nil
+}
+
+func (m *mockResultAnnotationRepo) Save(ctx context.Context, annotation *entity.ResultAnnotation) error {
+	m.annotations[annotation.ResultID] = annotation
+	return nil
+}
+
+func (m *mockResultAnnotationRepo) AddComment(ctx context.Context, comment *entity.ResultComment) error {
+	m.comments = append(m.comments, comment)
+	return nil
+}
+
+func (m *mockResultAnnotationRepo) FindComment(ctx context.Context, id string) (*entity.ResultComment, error) {
+	for _, comment := range m.comments {
+		if comment.ID == id {
+			return comment, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockResultAnnotationRepo) DeleteComment(ctx context.Context, id string) error {
+	for i, comment := range m.comments {
+		if comment.ID == id {
+			m.comments = append(m.comments[:i], m.comments[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func setupResultAnnotationRouter(userID, role string) (*gin.Engine, *mockResultAnnotationRepo) {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["exec-1"] = []*entity.ExecutionResult{{ID: "result-1", ExecutionID: "exec-1"}}
+	userRepo := newMockUserRepo()
+	userRepo.users["analyst"] = &entity.User{ID: "analyst", IsActive: true}
+	repo := &mockResultAnnotationRepo{annotations: make(map[string]*entity.ResultAnnotation)}
+	handler := NewResultAnnotationHandler(application.NewResultAnnotationService(repo, resultRepo, userRepo))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		c.Next()
+	})
+	router.GET("/api/v1/results/:id/annotation", handler.GetAnnotation)
+	router.PUT("/api/v1/results/:id/annotation", handler.TriageResult)
+	router.POST("/api/v1/results/:id/annotation/comments", handler.AddComment)
+	router.DELETE("/api/v1/results/:id/annotation/comments/:commentId", handler.DeleteComment)
+	return router, repo
+}
+
+func TestResultAnnotationHandler_TriageResult(t *testing.T) {
+	router, repo := setupResultAnnotationRouter("lead", "rssi")
+
+	tests := []struct {
+		name   string
+		result string
+		body   string
+		status int
+	}{
+		{"triaged", "result-1", `{"triage":"needs_tuning","assignee_id":"analyst"}`, http.StatusOK},
+		{"unknown verdict", "result-1", `{"triage":"false_positive"}`, http.StatusBadRequest},
+		{"unknown assignee", "result-1", `{"triage":"true_positive","assignee_id":"ghost"}`, http.StatusBadRequest},
+		{"invalid JSON", "result-1", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/results/"+tt.result+"/annotation", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if annotation := repo.annotations["result-1"]; annotation == nil || annotation.Triage != entity.TriageNeedsTuning ||
+		annotation.AssigneeID != "analyst" || annotation.UpdatedBy != "lead" {
+		t.Errorf("Expected the triage saved, got %+v", annotation)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/results/result-1/annotation", nil))
+	var annotation entity.ResultAnnotation
+	if err := json.Unmarshal(w.Body.Bytes(), &annotation); err != nil || w.Code != http.StatusOK || annotation.Triage != entity.TriageNeedsTuning {
+		t.Errorf("Unexpected annotation: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestResultAnnotationHandler_Comments(t *testing.T) {
+	router, repo := setupResultAnnotationRouter("analyst", "analyst")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/results/result-1/annotation/comments",
+		strings.NewReader(`{"body":"Alert raised on the parent process only"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated || len(repo.comments) != 1 || repo.comments[0].AuthorID != "analyst" {
+		t.Fatalf("Expected the comment created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	for body, status := range map[string]int{`{}`: http.StatusBadRequest, `{"body":"  "}`: http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/results/result-1/annotation/comments", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != status {
+			t.Errorf("Expected status %d for %s, got %d", status, body, w.Code)
+		}
+	}
+
+	// Only the author deletes a comment, without administrator role
+	commentID := repo.comments[0].ID
+	repo.comments = append(repo.comments, &entity.ResultComment{ID: "c-other", ResultID: "result-1", AuthorID: "other"})
+	for id, status := range map[string]int{"c-other": http.StatusForbidden, "missing": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/results/result-1/annotation/comments/"+id, nil))
+		if w.Code != status {
+			t.Errorf("Expected status %d deleting %s, got %d", status, id, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/results/result-1/annotation/comments/"+commentID, nil))
+	if w.Code != http.StatusNoContent || len(repo.comments) != 1 {
+		t.Errorf("Expected the comment deleted, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResultQueueHandler exposes the state of the result ingestion queue to admins
+type ResultQueueHandler struct {
+	queue *application.ResultQueue
+}
+
+// NewResultQueueHandler creates a new result queue handler
+func NewResultQueueHandler(queue *application.ResultQueue) *ResultQueueHandler {
+	return &ResultQueueHandler{queue: queue}
+}
+
+// GetStats godoc
+// @Summary Get the result queue state
+// @Description Get the results waiting to be written, the queue capacity and high-water mark, the results enqueued, written and refused while the queue was full since the server started, and the last write
+// @Tags admin
+// @Produce json
+// @Success 200 {object} application.ResultQueueStats
+// @Router /api/v1/admin/result-queue [get]
+func (h *ResultQueueHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.queue.Stats())
+}
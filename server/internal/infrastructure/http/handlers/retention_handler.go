@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RetentionHandler exposes the execution data retention job to admins
+type RetentionHandler struct {
+	service *application.RetentionService
+	jobs    *application.JobService
+}
+
+// NewRetentionHandler creates a new retention handler
+func NewRetentionHandler(service *application.RetentionService) *RetentionHandler {
+	return &RetentionHandler{service: service}
+}
+
+// SetJobService lets on-demand runs go to background jobs with async=true
+func (h *RetentionHandler) SetJobService(jobs *application.JobService) {
+	h.jobs = jobs
+}
+
+// GetStatus godoc
+// @Summary Get the execution retention status
+// @Description Get the retention policies, the archive location, the next and last runs of the nightly job and the rows reclaimed since the server started
+// @Tags admin
+// @Produce json
+// @Success 200 {object} application.RetentionStatus
+// @Router /api/v1/admin/retention [get]
+func (h *RetentionHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Status())
+}
+
+// RunNow godoc
+// @Summary Apply the execution retention now
+// @Description Archive and remove the execution data older than the retention policies without waiting for the nightly job
+// @Tags admin
+// @Produce json
+// @Param async query bool false "Run in a background job, the run report being its result"
+// @Success 200 {object} application.RetentionRun
+// @Success 202 {object} entity.Job
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 503 {object} gin.H
+// @Router /api/v1/admin/retention/run [post]
+func (h *RetentionHandler) RunNow(c *gin.Context) {
+	if wantsAsync(c, h.jobs) {
+		submitJob(c, h.jobs, entity.JobRetention, func(ctx context.Context, progress *application.JobProgress) (any, error) {
+			progress.Report(0, 0, "Applying the retention policies")
+			return h.service.Run(ctx, time.Now())
+		})
+		return
+	}
+
+	run, err := h.service.Run(c.Request.Context(), time.Now())
+	if err != nil {
+		if errors.Is(err, application.ErrRetentionInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "run": run})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockRetentionRepoForHandler implements repository.RetentionRepository for tests
+type mockRetentionRepoForHandler struct {
+	executions []*entity.Execution
+}
+
+func (m *mockRetentionRepoForHandler) FindExpiredOutputs(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.ExecutionResult, error) {
+	return nil, nil
+}
+
+func (m *mockRetentionRepoForHandler) ClearOutputs(ctx context.Context, resultIDs []string) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockRetentionRepoForHandler) FindBlobKeys(ctx context.Context, executionIDs []string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockRetentionRepoForHandler) FindExpiredExecutions(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.Execution, error) {
+	return m.executions, nil
+}
+
+func (m *mockRetentionRepoForHandler) DeleteExecutions(ctx context.Context, executionIDs []string) (int64, error) {
+	m.executions = nil
+	return int64(len(executionIDs)) * 3, nil
+}
+
+func TestRetentionHandler_Routes(t *testing.T) {
+	repo := &mockRetentionRepoForHandler{executions: []*entity.Execution{{ID: "exec-1"}, {ID: "exec-2"}}}
+	service := application.NewRetentionService(repo, nil, application.RetentionConfig{ExecutionRetention: 730 * 24 * time.Hour}, nil)
+	handler := NewRetentionHandler(service)
+
+	router := gin.New()
+	router.GET("/admin/retention", handler.GetStatus)
+	router.POST("/admin/retention/run", handler.RunNow)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/retention/run", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var run application.RetentionRun
+	if err := json.Unmarshal(w.Body.Bytes(), &run); err != nil || run.ExecutionsDeleted != 2 || run.ResultsDeleted != 6 {
+		t.Errorf("Unexpected run %+v (%v)", run, err)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/retention", nil)
+	router.ServeHTTP(w, req)
+	var status application.RetentionStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Expected the status, got %d: %s", w.Code, w.Body.String())
+	}
+	if status.ExecutionRetentionDays != 730 || status.Totals.ExecutionsDeleted != 2 || status.LastRun == nil {
+		t.Errorf("Unexpected status %+v", status)
+	}
+}
+
+func TestRetentionHandler_RunNow_Async(t *testing.T) {
+	repo := &mockRetentionRepoForHandler{executions: []*entity.Execution{{ID: "exec-1"}}}
+	service := application.NewRetentionService(repo, nil, application.RetentionConfig{ExecutionRetention: 730 * 24 * time.Hour}, nil)
+	jobRepo := newMockJobRepo()
+	jobs := application.NewJobService(jobRepo, 1, nil)
+	jobs.Start()
+	defer jobs.Stop()
+	handler := NewRetentionHandler(service)
+	handler.SetJobService(jobs)
+
+	router := gin.New()
+	router.POST("/admin/retention/run", withUser("admin-1", "admin"), handler.RunNow)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/retention/run?async=true", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var started entity.Job
+	_ = json.Unmarshal(w.Body.Bytes(), &started)
+
+	job := waitForBackgroundJob(t, jobRepo, started.ID)
+	var run application.RetentionRun
+	if err := json.Unmarshal(job.Result, &run); err != nil || job.Type != entity.JobRetention || run.ExecutionsDeleted != 1 {
+		t.Errorf("Expected the run report stored on the job, got %+v (%v)", job, err)
+	}
+}
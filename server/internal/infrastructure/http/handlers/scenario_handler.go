@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"autostrike/internal/application"
 	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,7 +21,8 @@ const (
 
 // ScenarioHandler handles scenario-related HTTP requests
 type ScenarioHandler struct {
-	service *application.ScenarioService
+	service  *application.ScenarioService
+	activity *application.ActivityMonitor
 }
 
 // NewScenarioHandler creates a new scenario handler
@@ -27,6 +30,11 @@ func NewScenarioHandler(service *application.ScenarioService) *ScenarioHandler {
 	return &ScenarioHandler{service: service}
 }
 
+// SetActivityMonitor enables mass deletion detection
+func (h *ScenarioHandler) SetActivityMonitor(monitor *application.ActivityMonitor) {
+	h.activity = monitor
+}
+
 // RegisterRoutes registers scenario routes
 func (h *ScenarioHandler) RegisterRoutes(r *gin.RouterGroup) {
 	scenarios := r.Group("/scenarios")
@@ -34,16 +42,27 @@ func (h *ScenarioHandler) RegisterRoutes(r *gin.RouterGroup) {
 		scenarios.GET("", h.ListScenarios)
 		scenarios.GET("/export", h.ExportScenarios)
 		scenarios.POST("/import", h.ImportScenarios)
+		scenarios.POST("/validate", h.ValidateScenario)
+		scenarios.GET("/lifecycle-report", h.GetLifecycleReport) // Must be before /:id
 		scenarios.GET("/tag/:tag", h.GetScenariosByTag) // Must be before /:id
 		scenarios.GET("/:id", h.GetScenario)
 		scenarios.GET("/:id/export", h.ExportScenario)
 		scenarios.POST("", h.CreateScenario)
+		scenarios.POST("/:id/clone", h.CloneScenario)
 		scenarios.PUT("/:id", h.UpdateScenario)
 		scenarios.DELETE("/:id", h.DeleteScenario)
 	}
 }
 
-// ListScenarios returns all scenarios
+// ListScenarios godoc
+// @Summary List scenarios
+// @Description List the scenarios, or only the built-in templates (template=true) or only the editable scenarios (template=false)
+// @Tags scenarios
+// @Produce json
+// @Param template query bool false "Templates only, or editable scenarios only"
+// @Success 200 {array} entity.Scenario
+// @Failure 500 {object} gin.H
+// @Router /api/v1/scenarios [get]
 func (h *ScenarioHandler) ListScenarios(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -51,20 +70,37 @@ func (h *ScenarioHandler) ListScenarios(c *gin.Context) {
 		return
 	}
 
+	template := c.Query("template")
+	if template != "" && template != "true" && template != "false" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "template must be true or false"})
+		return
+	}
+
 	scenarios, err := h.service.GetAllScenarios(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Return empty array instead of null
-	if scenarios == nil {
-		scenarios = []*entity.Scenario{}
+	// Filtered into a non-nil slice so an empty list is returned as an array
+	filtered := make([]*entity.Scenario, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		if template == "" || scenario.IsTemplate == (template == "true") {
+			filtered = append(filtered, scenario)
+		}
 	}
-	c.JSON(http.StatusOK, scenarios)
+	c.JSON(http.StatusOK, filtered)
 }
 
-// GetScenario returns a specific scenario
+// GetScenario godoc
+// @Summary Get a scenario
+// @Description Get a scenario with its phases
+// @Tags scenarios
+// @Produce json
+// @Param id path string true "Scenario ID"
+// @Success 200 {object} entity.Scenario
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scenarios/{id} [get]
 func (h *ScenarioHandler) GetScenario(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -83,7 +119,15 @@ func (h *ScenarioHandler) GetScenario(c *gin.Context) {
 	c.JSON(http.StatusOK, scenario)
 }
 
-// GetScenariosByTag returns scenarios filtered by tag
+// GetScenariosByTag godoc
+// @Summary List the scenarios with a tag
+// @Description List the scenarios with a tag
+// @Tags scenarios
+// @Produce json
+// @Param tag path string true "Tag"
+// @Success 200 {array} entity.Scenario
+// @Failure 500 {object} gin.H
+// @Router /api/v1/scenarios/tag/{tag} [get]
 func (h *ScenarioHandler) GetScenariosByTag(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -106,6 +150,31 @@ func (h *ScenarioHandler) GetScenariosByTag(c *gin.Context) {
 	c.JSON(http.StatusOK, scenarios)
 }
 
+// GetLifecycleReport godoc
+// @Summary Get the technique lifecycle report
+// @Description List the scenarios that use deprecated or broken techniques
+// @Tags scenarios
+// @Produce json
+// @Success 200 {array} application.ScenarioLifecycleIssue
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/scenarios/lifecycle-report [get]
+func (h *ScenarioHandler) GetLifecycleReport(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errScenarioNotAuthenticated})
+		return
+	}
+
+	report, err := h.service.GetLifecycleReport(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // CreateScenarioRequest represents the request body for scenario creation
 type CreateScenarioRequest struct {
 	Name        string         `json:"name" binding:"required"`
@@ -114,7 +183,18 @@ type CreateScenarioRequest struct {
 	Tags        []string       `json:"tags,omitempty"`
 }
 
-// CreateScenario creates a new scenario
+// CreateScenario godoc
+// @Summary Create a scenario
+// @Description Create a scenario from phases of techniques
+// @Tags scenarios
+// @Accept json
+// @Produce json
+// @Param request body CreateScenarioRequest true "Scenario"
+// @Success 201 {object} entity.Scenario
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/scenarios [post]
 func (h *ScenarioHandler) CreateScenario(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -148,6 +228,117 @@ func (h *ScenarioHandler) CreateScenario(c *gin.Context) {
 	c.JSON(http.StatusCreated, scenario)
 }
 
+// ValidateScenarioRequest represents the request body for scenario validation
+type ValidateScenarioRequest struct {
+	ScenarioID  string         `json:"scenario_id,omitempty"` // Validate as an update of this scenario
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Phases      []entity.Phase `json:"phases"`
+	Tags        []string       `json:"tags,omitempty"`
+	Platforms   []string       `json:"platforms,omitempty"` // Target platforms, all supported ones when empty
+	SafeMode    bool           `json:"safe_mode"`
+}
+
+// ValidateScenario godoc
+// @Summary Validate a scenario
+// @Description Checks a scenario without saving it: unknown or retired techniques, empty phases, circular run_if dependencies, techniques without an executor for the target platforms, invalid or unused phase executor preferences and techniques skipped in safe mode. Each error and warning carries a code and the phase and technique it is about.
+// @Tags scenarios
+// @Accept json
+// @Produce json
+// @Param request body ValidateScenarioRequest true "Scenario and execution settings"
+// @Success 200 {object} application.ScenarioValidation
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scenarios/validate [post]
+func (h *ScenarioHandler) ValidateScenario(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errScenarioNotAuthenticated})
+		return
+	}
+
+	var req ValidateScenarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ScenarioID != "" {
+		if _, err := h.service.GetScenario(c.Request.Context(), req.ScenarioID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": errScenarioNotFound})
+			return
+		}
+	}
+
+	scenario := &entity.Scenario{
+		ID:          req.ScenarioID,
+		Name:        req.Name,
+		Description: req.Description,
+		Phases:      req.Phases,
+		Tags:        req.Tags,
+	}
+	check := service.ScenarioCheck{Platforms: req.Platforms, SafeMode: req.SafeMode}
+
+	validation, err := h.service.ValidateScenarioDraft(c.Request.Context(), scenario, check)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, validation)
+}
+
+// CloneScenarioRequest represents the optional request body for scenario cloning
+type CloneScenarioRequest struct {
+	Name string `json:"name"`
+}
+
+// CloneScenario godoc
+// @Summary Clone a scenario
+// @Description Creates an editable copy of a scenario or built-in template, named after the source unless a name is given
+// @Tags scenarios
+// @Accept json
+// @Produce json
+// @Param id path string true "Scenario ID"
+// @Param request body CloneScenarioRequest false "Name of the copy"
+// @Success 201 {object} entity.Scenario
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scenarios/{id}/clone [post]
+func (h *ScenarioHandler) CloneScenario(c *gin.Context) {
+	_, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errScenarioNotAuthenticated})
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := h.service.GetScenario(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": errScenarioNotFound})
+		return
+	}
+
+	var req CloneScenarioRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	clone, err := h.service.CloneScenario(c.Request.Context(), id, strings.TrimSpace(req.Name))
+	if err != nil {
+		if _, ok := err.(*application.ValidationError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, clone)
+}
+
 // UpdateScenarioRequest represents the request body for scenario update
 type UpdateScenarioRequest struct {
 	Name        string         `json:"name" binding:"required"`
@@ -156,7 +347,19 @@ type UpdateScenarioRequest struct {
 	Tags        []string       `json:"tags,omitempty"`
 }
 
-// UpdateScenario updates an existing scenario
+// UpdateScenario godoc
+// @Summary Update a scenario
+// @Description Replace the name, description, phases and tags of a scenario. Built-in templates cannot be changed.
+// @Tags scenarios
+// @Accept json
+// @Produce json
+// @Param id path string true "Scenario ID"
+// @Param request body UpdateScenarioRequest true "Scenario"
+// @Success 200 {object} entity.Scenario
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scenarios/{id} [put]
 func (h *ScenarioHandler) UpdateScenario(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -195,6 +398,10 @@ func (h *ScenarioHandler) UpdateScenario(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, application.ErrScenarioTemplate) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -202,9 +409,17 @@ func (h *ScenarioHandler) UpdateScenario(c *gin.Context) {
 	c.JSON(http.StatusOK, scenario)
 }
 
-// DeleteScenario deletes a scenario
+// DeleteScenario godoc
+// @Summary Delete a scenario
+// @Description Move a scenario to the trash, from which it can be restored. Built-in templates cannot be deleted.
+// @Tags scenarios
+// @Param id path string true "Scenario ID"
+// @Success 204
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/scenarios/{id} [delete]
 func (h *ScenarioHandler) DeleteScenario(c *gin.Context) {
-	_, exists := c.Get("user_id")
+	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": errScenarioNotAuthenticated})
 		return
@@ -213,10 +428,19 @@ func (h *ScenarioHandler) DeleteScenario(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := h.service.DeleteScenario(c.Request.Context(), id); err != nil {
+		if errors.Is(err, application.ErrScenarioTemplate) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if h.activity != nil {
+		userIDStr, _ := userID.(string)
+		h.activity.RecordScenarioDeleted(c.Request.Context(), userIDStr, id)
+	}
+
 	c.Status(http.StatusNoContent)
 }
 
@@ -227,7 +451,16 @@ type ScenarioExport struct {
 	Scenarios  []*entity.Scenario `json:"scenarios"`
 }
 
-// ExportScenarios exports all scenarios (or selected ones) as JSON
+// ExportScenarios godoc
+// @Summary Export scenarios
+// @Description Download every scenario, or the ones listed in ids, as a JSON file
+// @Tags scenarios
+// @Produce json
+// @Param ids query string false "Comma-separated scenario IDs"
+// @Success 200 {object} ScenarioExport
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/scenarios/export [get]
 func (h *ScenarioHandler) ExportScenarios(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -284,7 +517,15 @@ func (h *ScenarioHandler) ExportScenarios(c *gin.Context) {
 	c.JSON(http.StatusOK, export)
 }
 
-// ExportScenario exports a single scenario as JSON
+// ExportScenario godoc
+// @Summary Export a scenario
+// @Description Download a scenario as a JSON file
+// @Tags scenarios
+// @Produce json
+// @Param id path string true "Scenario ID"
+// @Success 200 {object} ScenarioExport
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scenarios/{id}/export [get]
 func (h *ScenarioHandler) ExportScenario(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -336,7 +577,17 @@ type ImportScenariosResponse struct {
 	Scenarios []*entity.Scenario `json:"scenarios"`
 }
 
-// ImportScenarios imports scenarios from JSON
+// ImportScenarios godoc
+// @Summary Import scenarios
+// @Description Create the scenarios of an export file
+// @Tags scenarios
+// @Accept json
+// @Produce json
+// @Param request body ImportScenariosRequest true "Scenarios"
+// @Success 201 {object} ImportScenariosResponse
+// @Success 207 {object} ImportScenariosResponse
+// @Failure 400 {object} gin.H
+// @Router /api/v1/scenarios/import [post]
 func (h *ScenarioHandler) ImportScenarios(c *gin.Context) {
 	_, exists := c.Get("user_id")
 	if !exists {
@@ -514,6 +514,34 @@ func TestScenarioHandler_DeleteScenario(t *testing.T) {
 	}
 }
 
+func TestScenarioHandler_DeleteScenario_MassDeletion(t *testing.T) {
+	scenarioRepo := newTestScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "First"}
+	scenarioRepo.scenarios["s2"] = &entity.Scenario{ID: "s2", Name: "Second"}
+	techRepo := newTestTechniqueRepo()
+	svc := createTestScenarioService(scenarioRepo, techRepo)
+	handler := NewScenarioHandler(svc)
+	activityRepo := &mockActivityRepo{}
+	handler.SetActivityMonitor(newTestActivityMonitor(activityRepo, newMockUserRepo()))
+
+	router := gin.New()
+	router.DELETE("/scenarios/:id", withAuth(handler.DeleteScenario))
+
+	for _, id := range []string{"s1", "s2"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/scenarios/"+id, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", w.Code)
+		}
+	}
+
+	if len(activityRepo.anomalies) != 1 || activityRepo.anomalies[0].Type != entity.AnomalyMassDeletion {
+		t.Errorf("Expected a mass_deletion anomaly, got %+v", activityRepo.anomalies)
+	}
+}
+
 func TestScenarioHandler_DeleteScenario_Error(t *testing.T) {
 	scenarioRepo := newTestScenarioRepo()
 	scenarioRepo.err = errors.New("database error")
@@ -1830,3 +1858,249 @@ func TestScenarioHandler_UpdateScenario_MissingPhases(t *testing.T) {
 		t.Errorf("Expected status 400, got %d", w.Code)
 	}
 }
+
+func TestScenarioHandler_GetLifecycleReport(t *testing.T) {
+	scenarioRepo := newTestScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{
+		ID:     "s1",
+		Name:   "Test Scenario",
+		Phases: []entity.Phase{{Name: "Phase 1", Techniques: []string{"T1082"}, Order: 1}},
+	}
+	techRepo := newTestTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "Discovery", Status: entity.TechniqueBroken}
+	svc := createTestScenarioService(scenarioRepo, techRepo)
+	handler := NewScenarioHandler(svc)
+
+	router := gin.New()
+	router.GET("/scenarios/lifecycle-report", withAuth(handler.GetLifecycleReport))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/scenarios/lifecycle-report", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var report []application.ScenarioLifecycleIssue
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(report) != 1 || report[0].ScenarioID != "s1" || report[0].Techniques[0].Status != entity.TechniqueBroken {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestScenarioHandler_GetLifecycleReport_Empty(t *testing.T) {
+	svc := createTestScenarioService(newTestScenarioRepo(), newTestTechniqueRepo())
+	handler := NewScenarioHandler(svc)
+
+	router := gin.New()
+	router.GET("/scenarios/lifecycle-report", withAuth(handler.GetLifecycleReport))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/scenarios/lifecycle-report", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "[]" {
+		t.Errorf("Expected 200 with '[]', got %d '%s'", w.Code, w.Body.String())
+	}
+}
+
+func TestScenarioHandler_GetLifecycleReport_Errors(t *testing.T) {
+	scenarioRepo := newTestScenarioRepo()
+	scenarioRepo.err = errors.New("database error")
+	svc := createTestScenarioService(scenarioRepo, newTestTechniqueRepo())
+	handler := NewScenarioHandler(svc)
+
+	router := gin.New()
+	router.GET("/report", withAuth(handler.GetLifecycleReport))
+	router.GET("/report-anonymous", handler.GetLifecycleReport)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/report", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/report-anonymous", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestScenarioHandler_ListScenarios_TemplateFilter(t *testing.T) {
+	scenarioRepo := newTestScenarioRepo()
+	scenarioRepo.scenarios["s1"] = &entity.Scenario{ID: "s1", Name: "Mine"}
+	scenarioRepo.scenarios["template-apt29"] = &entity.Scenario{ID: "template-apt29", Name: "APT29", IsTemplate: true}
+	svc := createTestScenarioService(scenarioRepo, newTestTechniqueRepo())
+	handler := NewScenarioHandler(svc)
+
+	router := gin.New()
+	router.GET("/scenarios", withAuth(handler.ListScenarios))
+
+	tests := []struct {
+		query    string
+		wantCode int
+		wantIDs  []string
+	}{
+		{"?template=true", http.StatusOK, []string{"template-apt29"}},
+		{"?template=false", http.StatusOK, []string{"s1"}},
+		{"?template=yes", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/scenarios"+tt.query, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.wantCode {
+			t.Fatalf("%s: expected status %d, got %d", tt.query, tt.wantCode, w.Code)
+		}
+		if tt.wantIDs == nil {
+			continue
+		}
+		var scenarios []*entity.Scenario
+		_ = json.Unmarshal(w.Body.Bytes(), &scenarios)
+		if len(scenarios) != len(tt.wantIDs) || scenarios[0].ID != tt.wantIDs[0] {
+			t.Errorf("%s: expected %v, got %+v", tt.query, tt.wantIDs, scenarios)
+		}
+	}
+}
+
+func TestScenarioHandler_CloneScenario(t *testing.T) {
+	scenarioRepo := newTestScenarioRepo()
+	scenarioRepo.scenarios["template-apt29"] = &entity.Scenario{
+		ID:         "template-apt29",
+		Name:       "APT29",
+		IsTemplate: true,
+		Phases:     []entity.Phase{{Name: "Discovery", Techniques: []string{"T1082"}, Order: 1}},
+	}
+	techRepo := newTestTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery"}
+	svc := createTestScenarioService(scenarioRepo, techRepo)
+	handler := NewScenarioHandler(svc)
+
+	router := gin.New()
+	router.POST("/scenarios/:id/clone", withAuth(handler.CloneScenario))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/scenarios/template-apt29/clone", bytes.NewBufferString(`{"name": "Team APT29"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var clone entity.Scenario
+	_ = json.Unmarshal(w.Body.Bytes(), &clone)
+	if clone.Name != "Team APT29" || clone.IsTemplate || clone.ClonedFrom != "template-apt29" {
+		t.Errorf("Unexpected clone: %+v", clone)
+	}
+
+	// The body is optional
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/scenarios/template-apt29/clone", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 without body, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/scenarios/missing/clone", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestScenarioHandler_TemplateReadOnly(t *testing.T) {
+	scenarioRepo := newTestScenarioRepo()
+	scenarioRepo.scenarios["template-apt29"] = &entity.Scenario{
+		ID:         "template-apt29",
+		Name:       "APT29",
+		IsTemplate: true,
+		Phases:     []entity.Phase{{Name: "Discovery", Techniques: []string{"T1082"}, Order: 1}},
+	}
+	techRepo := newTestTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{ID: "T1082", Name: "System Information Discovery"}
+	svc := createTestScenarioService(scenarioRepo, techRepo)
+	handler := NewScenarioHandler(svc)
+
+	router := gin.New()
+	router.PUT("/scenarios/:id", withAuth(handler.UpdateScenario))
+	router.DELETE("/scenarios/:id", withAuth(handler.DeleteScenario))
+
+	body := `{"name": "Edited", "phases": [{"name": "Discovery", "techniques": ["T1082"]}]}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/scenarios/template-apt29", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 on update, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/scenarios/template-apt29", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 on delete, got %d", w.Code)
+	}
+}
+
+func TestScenarioHandler_ValidateScenario(t *testing.T) {
+	techRepo := newTestTechniqueRepo()
+	techRepo.techniques["T1082"] = &entity.Technique{
+		ID:        "T1082",
+		Name:      "System Information Discovery",
+		Platforms: []string{"linux"},
+		Executors: []entity.Executor{{Type: "bash", Command: "uname -a"}},
+		IsSafe:    true,
+	}
+	svc := createTestScenarioService(newTestScenarioRepo(), techRepo)
+	handler := NewScenarioHandler(svc)
+
+	router := gin.New()
+	router.POST("/scenarios/validate", withAuth(handler.ValidateScenario))
+
+	validate := func(body string) (int, application.ScenarioValidation) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/scenarios/validate", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		var validation application.ScenarioValidation
+		_ = json.Unmarshal(w.Body.Bytes(), &validation)
+		return w.Code, validation
+	}
+
+	code, validation := validate(`{"name": "Draft", "platforms": ["linux"], "phases": [{"name": "Discovery", "techniques": ["T1082"]}]}`)
+	if code != http.StatusOK || !validation.Valid || len(validation.Errors) != 0 || len(validation.Warnings) != 0 {
+		t.Errorf("Expected a valid scenario, got %d %+v", code, validation)
+	}
+
+	code, validation = validate(`{"name": "Draft", "platforms": ["windows"], "phases": [
+		{"name": "Discovery", "techniques": ["T1082", "T9999"], "run_if": [{"technique": "T1082"}]}]}`)
+	if code != http.StatusOK || validation.Valid {
+		t.Fatalf("Expected an invalid scenario, got %d %+v", code, validation)
+	}
+	codes := make(map[string]bool)
+	for _, issue := range validation.Errors {
+		codes[issue.Code] = true
+	}
+	for _, want := range []string{service.IssueCircularDependency, service.IssueUnknownTechnique, service.IssueNothingToRun} {
+		if !codes[want] {
+			t.Errorf("Expected a %s error, got %+v", want, validation.Errors)
+		}
+	}
+	if len(validation.Warnings) != 1 || validation.Warnings[0].Code != service.IssueUnsupportedPlatform {
+		t.Errorf("Expected an unsupported platform warning, got %+v", validation.Warnings)
+	}
+
+	if code, _ := validate(`{"scenario_id": "missing", "phases": []}`); code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", code)
+	}
+	if code, _ := validate(`{"phases": "invalid"}`); code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+}
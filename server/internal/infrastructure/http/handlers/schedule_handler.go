@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -46,14 +47,21 @@ func (h *ScheduleHandler) RegisterRoutes(router *gin.RouterGroup) {
 
 // CreateScheduleRequest represents the request to create a schedule
 type CreateScheduleRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	ScenarioID  string `json:"scenario_id" binding:"required"`
-	AgentPaw    string `json:"agent_paw"`
-	Frequency   string `json:"frequency" binding:"required,oneof=once hourly daily weekly monthly cron"`
-	CronExpr    string `json:"cron_expr"`
-	SafeMode    bool   `json:"safe_mode"`
-	StartAt     string `json:"start_at"`
+	Name            string                   `json:"name" binding:"required"`
+	Description     string                   `json:"description"`
+	ScenarioID      string                   `json:"scenario_id" binding:"required"`
+	AgentPaw        string                   `json:"agent_paw"`
+	AgentSelectorID string                   `json:"agent_selector_id"` // Saved selector resolved at each run
+	Frequency       string                   `json:"frequency" binding:"required,oneof=once hourly daily weekly monthly cron chained"`
+	CronExpr        string                   `json:"cron_expr"` // Five fields, or six with leading seconds
+	Timezone        string                   `json:"timezone"`  // IANA name, e.g. Europe/Paris; empty = server local time
+	MisfirePolicy   string                   `json:"misfire_policy" binding:"omitempty,oneof=skip run_once_immediately run_all_missed"`
+	DependsOn       string                   `json:"depends_on"`      // Upstream schedule of a chained schedule
+	ChainCondition  string                   `json:"chain_condition"` // success, score_below or score_at_least
+	ChainThreshold  float64                  `json:"chain_threshold"` // Overall score of the score conditions
+	Alerting        *entity.ScheduleAlerting `json:"alerting"`        // PagerDuty and Opsgenie paging of the schedule's executions
+	SafeMode        bool                     `json:"safe_mode"`
+	StartAt         string                   `json:"start_at"`
 }
 
 // GetAll godoc
@@ -160,18 +168,29 @@ func (h *ScheduleHandler) Create(c *gin.Context) {
 	}
 
 	createReq := &application.CreateScheduleRequest{
-		Name:        req.Name,
-		Description: req.Description,
-		ScenarioID:  req.ScenarioID,
-		AgentPaw:    req.AgentPaw,
-		Frequency:   entity.ScheduleFrequency(req.Frequency),
-		CronExpr:    req.CronExpr,
-		SafeMode:    req.SafeMode,
-		StartAt:     startAt,
+		Name:            req.Name,
+		Description:     req.Description,
+		ScenarioID:      req.ScenarioID,
+		AgentPaw:        req.AgentPaw,
+		AgentSelectorID: req.AgentSelectorID,
+		Frequency:       entity.ScheduleFrequency(req.Frequency),
+		CronExpr:        req.CronExpr,
+		Timezone:        req.Timezone,
+		MisfirePolicy:   entity.MisfirePolicy(req.MisfirePolicy),
+		DependsOn:       req.DependsOn,
+		ChainCondition:  entity.ChainCondition(req.ChainCondition),
+		ChainThreshold:  req.ChainThreshold,
+		Alerting:        req.Alerting,
+		SafeMode:        req.SafeMode,
+		StartAt:         startAt,
 	}
 
 	schedule, err := h.scheduleService.Create(c.Request.Context(), createReq, userID.(string))
 	if err != nil {
+		if isScheduleRequestError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -223,18 +242,29 @@ func (h *ScheduleHandler) Update(c *gin.Context) {
 	}
 
 	updateReq := &application.CreateScheduleRequest{
-		Name:        req.Name,
-		Description: req.Description,
-		ScenarioID:  req.ScenarioID,
-		AgentPaw:    req.AgentPaw,
-		Frequency:   entity.ScheduleFrequency(req.Frequency),
-		CronExpr:    req.CronExpr,
-		SafeMode:    req.SafeMode,
-		StartAt:     startAt,
+		Name:            req.Name,
+		Description:     req.Description,
+		ScenarioID:      req.ScenarioID,
+		AgentPaw:        req.AgentPaw,
+		AgentSelectorID: req.AgentSelectorID,
+		Frequency:       entity.ScheduleFrequency(req.Frequency),
+		CronExpr:        req.CronExpr,
+		Timezone:        req.Timezone,
+		MisfirePolicy:   entity.MisfirePolicy(req.MisfirePolicy),
+		DependsOn:       req.DependsOn,
+		ChainCondition:  entity.ChainCondition(req.ChainCondition),
+		ChainThreshold:  req.ChainThreshold,
+		Alerting:        req.Alerting,
+		SafeMode:        req.SafeMode,
+		StartAt:         startAt,
 	}
 
 	schedule, err := h.scheduleService.Update(c.Request.Context(), id, updateReq)
 	if err != nil {
+		if isScheduleRequestError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if err.Error() == errScheduleNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -436,3 +466,14 @@ func (h *ScheduleHandler) GetRuns(c *gin.Context) {
 
 	c.JSON(http.StatusOK, runs)
 }
+
+// isScheduleRequestError reports whether a schedule was rejected for its content
+func isScheduleRequestError(err error) bool {
+	return errors.Is(err, application.ErrAgentSelectorNotFound) ||
+		errors.Is(err, application.ErrInvalidCronExpr) ||
+		errors.Is(err, application.ErrInvalidTimezone) ||
+		errors.Is(err, application.ErrInvalidMisfirePolicy) ||
+		errors.Is(err, application.ErrInvalidScheduleChain) ||
+		errors.Is(err, application.ErrInvalidScheduleAlerting) ||
+		errors.Is(err, application.ErrScheduleCycle)
+}
@@ -108,6 +108,16 @@ func (m *mockScheduleRepo) FindByScenarioID(ctx context.Context, scenarioID stri
 	return result, nil
 }
 
+func (m *mockScheduleRepo) FindDependents(ctx context.Context, scheduleID string) ([]*entity.Schedule, error) {
+	var result []*entity.Schedule
+	for _, s := range m.schedules {
+		if s.DependsOn == scheduleID {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
 func (m *mockScheduleRepo) CreateRun(ctx context.Context, run *entity.ScheduleRun) error {
 	if m.createRunErr != nil {
 		return m.createRunErr
@@ -131,6 +141,10 @@ func (m *mockScheduleRepo) FindRunsByScheduleID(ctx context.Context, scheduleID
 	return runs, nil
 }
 
+func (m *mockScheduleRepo) FindRunByExecutionID(ctx context.Context, executionID string) (*entity.ScheduleRun, error) {
+	return nil, nil
+}
+
 // setupRealScheduleHandler creates a handler with real service using mock repo
 func setupRealScheduleHandler(repo *mockScheduleRepo) (*ScheduleHandler, *gin.Engine) {
 	gin.SetMode(gin.TestMode)
@@ -934,8 +948,8 @@ func TestScheduleHandler_Create_CronFrequency_MissingExpr(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("Status = %d, want %d", w.Code, http.StatusInternalServerError)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
 	}
 }
 
@@ -956,8 +970,109 @@ func TestScheduleHandler_Create_CronFrequency_InvalidExpr(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("Status = %d, want %d", w.Code, http.StatusInternalServerError)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestScheduleHandler_Create_InvalidTimezone(t *testing.T) {
+	repo := newMockScheduleRepo()
+	_, router := setupRealScheduleHandler(repo)
+
+	body := CreateScheduleRequest{
+		Name:       "Cron Schedule",
+		ScenarioID: "scenario-1",
+		Frequency:  "cron",
+		CronExpr:   "0 9 * * 1-5",
+		Timezone:   "Europe/Atlantis",
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestScheduleHandler_Create_InvalidAlerting(t *testing.T) {
+	repo := newMockScheduleRepo()
+	_, router := setupRealScheduleHandler(repo)
+
+	body := CreateScheduleRequest{
+		Name:       "Nightly",
+		ScenarioID: "scenario-1",
+		Frequency:  "daily",
+		Alerting:   &entity.ScheduleAlerting{ScoreThreshold: 150},
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestScheduleHandler_Create_MisfirePolicy(t *testing.T) {
+	repo := newMockScheduleRepo()
+	_, router := setupRealScheduleHandler(repo)
+
+	for policy, want := range map[string]int{"run_all_missed": http.StatusCreated, "later": http.StatusBadRequest} {
+		body := CreateScheduleRequest{
+			Name:          "Daily Schedule",
+			ScenarioID:    "scenario-1",
+			Frequency:     "daily",
+			MisfirePolicy: policy,
+		}
+		jsonBody, _ := json.Marshal(body)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", bytes.NewReader(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("misfire_policy %q: Status = %d, want %d", policy, w.Code, want)
+		}
+	}
+}
+
+func TestScheduleHandler_Create_ReturnsNextRuns(t *testing.T) {
+	repo := newMockScheduleRepo()
+	_, router := setupRealScheduleHandler(repo)
+
+	body := CreateScheduleRequest{
+		Name:       "Cron Schedule",
+		ScenarioID: "scenario-1",
+		Frequency:  "cron",
+		CronExpr:   "0 0 9 * * 1-5",
+		Timezone:   "UTC",
+	}
+	jsonBody, _ := json.Marshal(body)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var schedule entity.Schedule
+	if err := json.Unmarshal(w.Body.Bytes(), &schedule); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("Status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	if schedule.Timezone != "UTC" || len(schedule.NextRuns) != entity.UpcomingRunCount {
+		t.Fatalf("Expected the timezone and %d upcoming runs, got %+v", entity.UpcomingRunCount, schedule)
+	}
+	for _, run := range schedule.NextRuns {
+		if run.UTC().Hour() != 9 || run.UTC().Weekday() == time.Saturday || run.UTC().Weekday() == time.Sunday {
+			t.Errorf("Unexpected run time %v", run)
+		}
 	}
 }
 
@@ -1341,3 +1456,30 @@ func TestScheduleHandler_Delete_NotFound_Succeeds(t *testing.T) {
 		t.Errorf("Status = %d, want %d", w.Code, http.StatusOK)
 	}
 }
+
+func TestScheduleHandler_Create_Chained(t *testing.T) {
+	repo := newMockScheduleRepo()
+	repo.schedules["recon"] = &entity.Schedule{ID: "recon", Frequency: entity.FrequencyDaily, Status: entity.ScheduleStatusActive}
+	_, router := setupRealScheduleHandler(repo)
+
+	for dependsOn, want := range map[string]int{"recon": http.StatusCreated, "missing": http.StatusBadRequest} {
+		body := CreateScheduleRequest{
+			Name:           "Lateral Movement",
+			ScenarioID:     "scenario-2",
+			Frequency:      "chained",
+			DependsOn:      dependsOn,
+			ChainCondition: "score_below",
+			ChainThreshold: 50,
+		}
+		jsonBody, _ := json.Marshal(body)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/schedules", bytes.NewReader(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != want {
+			t.Errorf("depends_on %q: Status = %d, want %d", dependsOn, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoreBackfillHandler exposes score recomputation backfills to admins
+type ScoreBackfillHandler struct {
+	service *application.ScoreBackfillService
+}
+
+// NewScoreBackfillHandler creates a new score backfill handler
+func NewScoreBackfillHandler(service *application.ScoreBackfillService) *ScoreBackfillHandler {
+	return &ScoreBackfillHandler{service: service}
+}
+
+// RegisterRoutes registers score backfill routes (requires admin role) and
+// rescore routes (requires settings edit permission)
+func (h *ScoreBackfillHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/admin/scores/recompute", h.StartRecompute)
+	r.GET("/admin/scores/recompute", h.ListJobs)
+	r.GET("/admin/scores/recompute/:id", h.GetJob)
+	r.GET("/admin/scores/executions/:id/history", h.GetScoreHistory)
+	r.POST("/executions/rescore", h.RescoreExecutions)
+	r.POST("/executions/:id/rescore", h.RescoreExecution)
+}
+
+// RecomputeScoresRequest represents the request to recompute historical scores
+type RecomputeScoresRequest struct {
+	From      *time.Time `json:"from"`
+	To        *time.Time `json:"to"`
+	BatchSize int        `json:"batch_size"`
+	DryRun    bool       `json:"dry_run"`
+}
+
+// StartRecompute godoc
+// @Summary Recompute historical scores
+// @Description Recompute the scores of completed executions in the background, keeping the overwritten scores
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body RecomputeScoresRequest false "Period, batch size and dry run flag"
+// @Success 202 {object} application.RecomputeJob
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/admin/scores/recompute [post]
+func (h *ScoreBackfillHandler) StartRecompute(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	// An empty body recomputes every completed execution
+	var req RecomputeScoresRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	recompute := application.RecomputeRequest{BatchSize: req.BatchSize, DryRun: req.DryRun}
+	if req.From != nil {
+		recompute.From = *req.From
+	}
+	if req.To != nil {
+		recompute.To = *req.To
+	}
+
+	job, err := h.service.StartRecompute(recompute)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrRecomputeInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrInvalidRecomputeRange):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start recomputation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListJobs godoc
+// @Summary List score recompute jobs
+// @Description List the score recompute jobs started since the server booted, newest first
+// @Tags admin
+// @Produce json
+// @Success 200 {array} application.RecomputeJob
+// @Failure 401 {object} gin.H
+// @Router /api/v1/admin/scores/recompute [get]
+func (h *ScoreBackfillHandler) ListJobs(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.service.ListJobs())
+}
+
+// GetJob godoc
+// @Summary Get score recompute job
+// @Description Get the progress of a score recompute job and the score changes it made
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} application.RecomputeJob
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/scores/recompute/{id} [get]
+func (h *ScoreBackfillHandler) GetJob(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	job, err := h.service.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GetScoreHistory godoc
+// @Summary Get execution score history
+// @Description Compare the original and current score of an execution with every recomputation in between
+// @Tags admin
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} application.ScoreHistory
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/scores/executions/{id}/history [get]
+func (h *ScoreBackfillHandler) GetScoreHistory(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	history, err := h.service.GetScoreHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, application.ErrExecutionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load score history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// RescoreRequest represents the request to rescore an execution
+type RescoreRequest struct {
+	ScoringProfileID string `json:"scoring_profile_id"` // Empty for the default profile
+}
+
+// RescoreExecutionsRequest represents the request to rescore several executions
+type RescoreExecutionsRequest struct {
+	ExecutionIDs     []string `json:"execution_ids" binding:"required"`
+	ScoringProfileID string   `json:"scoring_profile_id"` // Empty for the default profile
+}
+
+// RescoreExecution godoc
+// @Summary Rescore an execution
+// @Description Recalculate the score of a completed execution with the current version of a scoring profile, keeping the original score in its history
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Param request body RescoreRequest false "Scoring profile, the default one when omitted"
+// @Success 200 {object} application.RescoreResult
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/executions/{id}/rescore [post]
+func (h *ScoreBackfillHandler) RescoreExecution(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	// An empty body rescores with the default profile
+	var req RescoreRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	result, err := h.service.RescoreExecution(c.Request.Context(), c.Param("id"), req.ScoringProfileID, c.GetString("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrExecutionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		case errors.Is(err, application.ErrExecutionNotCompleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.rescoreError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RescoreExecutions godoc
+// @Summary Rescore executions
+// @Description Recalculate the scores of up to 100 completed executions with the current version of a scoring profile, keeping the original scores in their history
+// @Tags executions
+// @Accept json
+// @Produce json
+// @Param request body RescoreExecutionsRequest true "Executions and scoring profile"
+// @Success 200 {object} application.RescoreReport
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/executions/rescore [post]
+func (h *ScoreBackfillHandler) RescoreExecutions(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	var req RescoreExecutionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	report, err := h.service.Rescore(c.Request.Context(), req.ExecutionIDs, req.ScoringProfileID, c.GetString("user_id"))
+	if err != nil {
+		h.rescoreError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// rescoreError maps the errors shared by rescore requests to HTTP responses
+func (h *ScoreBackfillHandler) rescoreError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidRescore):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrScoringProfileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "scoring profile not found"})
+	case errors.Is(err, application.ErrRecomputeInProgress):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rescore"})
+	}
+}
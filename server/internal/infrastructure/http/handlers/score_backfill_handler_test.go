@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockScoreHistoryRepo struct {
+	mu             sync.Mutex
+	recomputations []*entity.ScoreRecomputation
+	findErr        error
+}
+
+func (m *mockScoreHistoryRepo) Create(ctx context.Context, rec *entity.ScoreRecomputation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recomputations = append(m.recomputations, rec)
+	return nil
+}
+
+func (m *mockScoreHistoryRepo) FindByExecution(ctx context.Context, executionID string) ([]*entity.ScoreRecomputation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.findErr != nil {
+		return nil, m.findErr
+	}
+	var result []*entity.ScoreRecomputation
+	for _, rec := range m.recomputations {
+		if rec.ExecutionID == executionID {
+			result = append(result, rec)
+		}
+	}
+	return result, nil
+}
+
+func setupScoreBackfillRouter(resultRepo *mockResultRepo, historyRepo *mockScoreHistoryRepo) (*gin.Engine, *application.ScoreBackfillService) {
+	svc := application.NewScoreBackfillService(resultRepo, historyRepo, service.NewScoreCalculator(), nil)
+	handler := NewScoreBackfillHandler(svc)
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("", func(c *gin.Context) { c.Set("user_id", "admin-1") }))
+	return router, svc
+}
+
+func waitForRecomputeJob(t *testing.T, svc *application.ScoreBackfillService, id string) *application.RecomputeJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		if job.Status != application.RecomputeRunning {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("recompute job did not finish in time")
+	return nil
+}
+
+func TestScoreBackfillHandler_StartRecompute(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{
+		ID:        "e1",
+		Status:    entity.ExecutionCompleted,
+		StartedAt: time.Now().Add(-time.Hour),
+		Score:     &entity.SecurityScore{},
+	}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{{ID: "r1", ExecutionID: "e1", Status: entity.StatusBlocked}}
+	router, svc := setupScoreBackfillRouter(resultRepo, &mockScoreHistoryRepo{})
+
+	body, _ := json.Marshal(RecomputeScoresRequest{BatchSize: 10, DryRun: true})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/scores/recompute", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var started application.RecomputeJob
+	if err := json.Unmarshal(w.Body.Bytes(), &started); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !started.DryRun || started.BatchSize != 10 {
+		t.Errorf("Expected request options on the job, got %+v", started)
+	}
+
+	waitForRecomputeJob(t, svc, started.ID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/scores/recompute/"+started.ID, nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var job application.RecomputeJob
+	if err := json.Unmarshal(w.Body.Bytes(), &job); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if job.Status != application.RecomputeCompleted || job.Changed != 1 || len(job.Changes) != 1 {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+}
+
+func TestScoreBackfillHandler_StartRecompute_EmptyBody(t *testing.T) {
+	router, svc := setupScoreBackfillRouter(newMockResultRepo(), &mockScoreHistoryRepo{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/scores/recompute", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var started application.RecomputeJob
+	_ = json.Unmarshal(w.Body.Bytes(), &started)
+	waitForRecomputeJob(t, svc, started.ID)
+}
+
+func TestScoreBackfillHandler_StartRecompute_BadRequest(t *testing.T) {
+	router, _ := setupScoreBackfillRouter(newMockResultRepo(), &mockScoreHistoryRepo{})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "{"},
+		{"inverted range", `{"from":"2024-02-01T00:00:00Z","to":"2024-01-01T00:00:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/admin/scores/recompute", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+// blockingResultRepo holds a recomputation on its first query until release is closed
+type blockingResultRepo struct {
+	*mockResultRepo
+	release chan struct{}
+}
+
+func (r *blockingResultRepo) FindCompletedExecutionsByDateRange(ctx context.Context, start, end time.Time) ([]*entity.Execution, error) {
+	<-r.release
+	return r.mockResultRepo.FindCompletedExecutionsByDateRange(ctx, start, end)
+}
+
+func TestScoreBackfillHandler_StartRecompute_Conflict(t *testing.T) {
+	resultRepo := &blockingResultRepo{mockResultRepo: newMockResultRepo(), release: make(chan struct{})}
+	svc := application.NewScoreBackfillService(resultRepo, &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+	handler := NewScoreBackfillHandler(svc)
+
+	router := gin.New()
+	router.POST("/recompute", withAuth(handler.StartRecompute))
+
+	job, err := svc.StartRecompute(application.RecomputeRequest{})
+	if err != nil {
+		t.Fatalf("StartRecompute failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/recompute", nil)
+	router.ServeHTTP(w, req)
+
+	close(resultRepo.release)
+	waitForRecomputeJob(t, svc, job.ID)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+}
+
+func TestScoreBackfillHandler_ListJobs(t *testing.T) {
+	router, svc := setupScoreBackfillRouter(newMockResultRepo(), &mockScoreHistoryRepo{})
+
+	job, _ := svc.StartRecompute(application.RecomputeRequest{})
+	waitForRecomputeJob(t, svc, job.ID)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/scores/recompute", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var jobs []application.RecomputeJob
+	if err := json.Unmarshal(w.Body.Bytes(), &jobs); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != job.ID {
+		t.Errorf("Expected the started job, got %+v", jobs)
+	}
+}
+
+func TestScoreBackfillHandler_GetJob_NotFound(t *testing.T) {
+	router, _ := setupScoreBackfillRouter(newMockResultRepo(), &mockScoreHistoryRepo{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/scores/recompute/missing", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestScoreBackfillHandler_GetScoreHistory(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Score: &entity.SecurityScore{Overall: 100}}
+	historyRepo := &mockScoreHistoryRepo{recomputations: []*entity.ScoreRecomputation{
+		{ID: "rec-1", ExecutionID: "e1", PreviousScore: entity.SecurityScore{Overall: 50}, NewScore: entity.SecurityScore{Overall: 100}},
+	}}
+	router, _ := setupScoreBackfillRouter(resultRepo, historyRepo)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/scores/executions/e1/history", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var history application.ScoreHistory
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if history.OriginalScore.Overall != 50 || history.CurrentScore.Overall != 100 {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestScoreBackfillHandler_GetScoreHistory_Errors(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Score: &entity.SecurityScore{}}
+	router, _ := setupScoreBackfillRouter(resultRepo, &mockScoreHistoryRepo{findErr: errors.New("db error")})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/scores/executions/missing/history", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/scores/executions/e1/history", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+}
+
+func newRescoreFixture() *mockResultRepo {
+	resultRepo := newMockResultRepo()
+	resultRepo.executions["e1"] = &entity.Execution{ID: "e1", Status: entity.ExecutionCompleted, Score: &entity.SecurityScore{}}
+	resultRepo.results["e1"] = []*entity.ExecutionResult{{ID: "r1", ExecutionID: "e1", Status: entity.StatusBlocked}}
+	resultRepo.executions["e2"] = &entity.Execution{ID: "e2", Status: entity.ExecutionRunning}
+	return resultRepo
+}
+
+func TestScoreBackfillHandler_RescoreExecution(t *testing.T) {
+	resultRepo := newRescoreFixture()
+	historyRepo := &mockScoreHistoryRepo{}
+	router, _ := setupScoreBackfillRouter(resultRepo, historyRepo)
+
+	// An empty body rescores with the default profile
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/executions/e1/rescore", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result application.RescoreResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !result.Changed || result.ScoreVersion != 2 || result.NewScore.Overall != 100 {
+		t.Errorf("Unexpected rescore result: %+v", result)
+	}
+	if len(historyRepo.recomputations) != 1 || historyRepo.recomputations[0].RecomputedBy != "admin-1" {
+		t.Errorf("Expected the rescore to be attributed to admin-1, got %+v", historyRepo.recomputations)
+	}
+}
+
+func TestScoreBackfillHandler_RescoreExecution_Errors(t *testing.T) {
+	router, _ := setupScoreBackfillRouter(newRescoreFixture(), &mockScoreHistoryRepo{})
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{"invalid body", "/executions/e1/rescore", "{", http.StatusBadRequest},
+		{"missing execution", "/executions/missing/rescore", "", http.StatusNotFound},
+		{"running execution", "/executions/e2/rescore", "", http.StatusConflict},
+		{"unknown profile", "/executions/e1/rescore", `{"scoring_profile_id":"strict"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+func TestScoreBackfillHandler_RescoreExecutions(t *testing.T) {
+	router, _ := setupScoreBackfillRouter(newRescoreFixture(), &mockScoreHistoryRepo{})
+
+	body, _ := json.Marshal(RescoreExecutionsRequest{ExecutionIDs: []string{"e1", "e2"}})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/executions/rescore", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report application.RescoreReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if report.Changed != 1 || report.Failed != 1 || len(report.Results) != 2 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	for _, payload := range []string{`{}`, `{"execution_ids":[]}`} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/executions/rescore", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", payload, w.Code)
+		}
+	}
+}
+
+func TestScoreBackfillHandler_Unauthenticated(t *testing.T) {
+	svc := application.NewScoreBackfillService(newMockResultRepo(), &mockScoreHistoryRepo{}, service.NewScoreCalculator(), nil)
+	handler := NewScoreBackfillHandler(svc)
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	routes := []struct{ method, path string }{
+		{"POST", "/admin/scores/recompute"},
+		{"GET", "/admin/scores/recompute"},
+		{"GET", "/admin/scores/recompute/j1"},
+		{"GET", "/admin/scores/executions/e1/history"},
+		{"POST", "/executions/rescore"},
+		{"POST", "/executions/e1/rescore"},
+	}
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(route.method, route.path, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected status 401, got %d", route.method, route.path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScoringProfileHandler handles scoring profile HTTP requests
+type ScoringProfileHandler struct {
+	service *application.ScoringProfileService
+}
+
+// NewScoringProfileHandler creates a new scoring profile handler
+func NewScoringProfileHandler(service *application.ScoringProfileService) *ScoringProfileHandler {
+	return &ScoringProfileHandler{service: service}
+}
+
+// RegisterRoutes registers scoring profile routes
+func (h *ScoringProfileHandler) RegisterRoutes(r *gin.RouterGroup) {
+	profiles := r.Group("/scoring-profiles")
+	{
+		profiles.GET("", h.ListProfiles)
+		profiles.GET("/:id", h.GetProfile)
+		profiles.GET("/:id/versions", h.ListVersions)
+		profiles.GET("/:id/versions/:version", h.GetVersion)
+		profiles.POST("", h.CreateProfile)
+		profiles.PUT("/:id", h.UpdateProfile)
+		profiles.DELETE("/:id", h.DeleteProfile)
+		profiles.POST("/:id/default", h.SetDefault)
+	}
+}
+
+// ScoringProfileRequest represents the request to create or update a scoring profile
+type ScoringProfileRequest struct {
+	Name              string             `json:"name" binding:"required"`
+	Description       string             `json:"description"`
+	BlockedCredit     *float64           `json:"blocked_credit"`     // 0-1, defaults to 1
+	DetectedCredit    *float64           `json:"detected_credit"`    // 0-1, defaults to 0.5
+	TacticWeights     map[string]float64 `json:"tactic_weights"`     // Weight per MITRE tactic, 1 when unset
+	SeverityWeights   map[string]float64 `json:"severity_weights"`   // Weight per technique severity, 1 when unset
+	UndetectedWeights map[string]float64 `json:"undetected_weights"` // Extra weight per severity of undetected techniques, 1 when unset
+}
+
+func (r *ScoringProfileRequest) toEntity() *entity.ScoringProfile {
+	profile := &entity.ScoringProfile{
+		Name:              r.Name,
+		Description:       r.Description,
+		BlockedCredit:     entity.DefaultBlockedCredit,
+		DetectedCredit:    entity.DefaultDetectedCredit,
+		TacticWeights:     r.TacticWeights,
+		SeverityWeights:   r.SeverityWeights,
+		UndetectedWeights: r.UndetectedWeights,
+	}
+	if r.BlockedCredit != nil {
+		profile.BlockedCredit = *r.BlockedCredit
+	}
+	if r.DetectedCredit != nil {
+		profile.DetectedCredit = *r.DetectedCredit
+	}
+	return profile
+}
+
+// ListProfiles godoc
+// @Summary List scoring profiles
+// @Description List the current version of every scoring profile, ordered by name
+// @Tags scoring-profiles
+// @Produce json
+// @Success 200 {array} entity.ScoringProfile
+// @Failure 500 {object} gin.H
+// @Router /api/v1/scoring-profiles [get]
+func (h *ScoringProfileHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list scoring profiles"})
+		return
+	}
+
+	if profiles == nil {
+		profiles = []*entity.ScoringProfile{}
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+// GetProfile godoc
+// @Summary Get scoring profile
+// @Description Get the current version of a scoring profile
+// @Tags scoring-profiles
+// @Produce json
+// @Param id path string true "Profile ID"
+// @Success 200 {object} entity.ScoringProfile
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scoring-profiles/{id} [get]
+func (h *ScoringProfileHandler) GetProfile(c *gin.Context) {
+	profile, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondScoringProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// ListVersions godoc
+// @Summary List scoring profile versions
+// @Description List every version of a scoring profile, oldest first
+// @Tags scoring-profiles
+// @Produce json
+// @Param id path string true "Profile ID"
+// @Success 200 {array} entity.ScoringProfile
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scoring-profiles/{id}/versions [get]
+func (h *ScoringProfileHandler) ListVersions(c *gin.Context) {
+	versions, err := h.service.Versions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondScoringProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetVersion godoc
+// @Summary Get scoring profile version
+// @Description Get a version of a scoring profile, e.g. the one an execution is scored with
+// @Tags scoring-profiles
+// @Produce json
+// @Param id path string true "Profile ID"
+// @Param version path int true "Profile version"
+// @Success 200 {object} entity.ScoringProfile
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scoring-profiles/{id}/versions/{version} [get]
+func (h *ScoringProfileHandler) GetVersion(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return
+	}
+
+	profile, err := h.service.GetVersion(c.Request.Context(), c.Param("id"), version)
+	if err != nil {
+		respondScoringProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// CreateProfile godoc
+// @Summary Create scoring profile
+// @Description Save a scoring profile weighting techniques by tactic and severity, with partial credit for detected-but-not-blocked techniques
+// @Tags scoring-profiles
+// @Accept json
+// @Produce json
+// @Param request body ScoringProfileRequest true "Profile name, credits and weights"
+// @Success 201 {object} entity.ScoringProfile
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Router /api/v1/scoring-profiles [post]
+func (h *ScoringProfileHandler) CreateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	var req ScoringProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	userIDStr, _ := userID.(string)
+	profile, err := h.service.Create(c.Request.Context(), req.toEntity(), userIDStr)
+	if err != nil {
+		respondScoringProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+// UpdateProfile godoc
+// @Summary Update scoring profile
+// @Description Save a new version of a scoring profile; executions scored with earlier versions keep them
+// @Tags scoring-profiles
+// @Accept json
+// @Produce json
+// @Param id path string true "Profile ID"
+// @Param request body ScoringProfileRequest true "Profile name, credits and weights"
+// @Success 200 {object} entity.ScoringProfile
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scoring-profiles/{id} [put]
+func (h *ScoringProfileHandler) UpdateProfile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	var req ScoringProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+		return
+	}
+
+	userIDStr, _ := userID.(string)
+	profile, err := h.service.Update(c.Request.Context(), c.Param("id"), req.toEntity(), userIDStr)
+	if err != nil {
+		respondScoringProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteProfile godoc
+// @Summary Delete scoring profile
+// @Description Remove a scoring profile from listings; executions scored with one of its versions keep it
+// @Tags scoring-profiles
+// @Param id path string true "Profile ID"
+// @Success 204
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scoring-profiles/{id} [delete]
+func (h *ScoringProfileHandler) DeleteProfile(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		respondScoringProfileError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetDefault godoc
+// @Summary Set default scoring profile
+// @Description Score the executions started without a scoring profile with this one
+// @Tags scoring-profiles
+// @Produce json
+// @Param id path string true "Profile ID"
+// @Success 200 {object} entity.ScoringProfile
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scoring-profiles/{id}/default [post]
+func (h *ScoringProfileHandler) SetDefault(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	profile, err := h.service.SetDefault(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondScoringProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// respondScoringProfileError maps scoring profile errors to HTTP responses
+func respondScoringProfileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, application.ErrScoringProfileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, application.ErrInvalidScoringProfile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "scoring profile operation failed"})
+	}
+}
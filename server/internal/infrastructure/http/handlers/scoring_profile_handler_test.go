@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockScoringProfileRepoForHandler struct {
+	versions  map[string][]*entity.ScoringProfile
+	defaultID string
+}
+
+func (m *mockScoringProfileRepoForHandler) Create(ctx context.Context, profile *entity.ScoringProfile) error {
+	m.versions[profile.ID] = []*entity.ScoringProfile{profile}
+	return nil
+}
+
+func (m *mockScoringProfileRepoForHandler) AddVersion(ctx context.Context, profile *entity.ScoringProfile) error {
+	m.versions[profile.ID] = append(m.versions[profile.ID], profile)
+	return nil
+}
+
+func (m *mockScoringProfileRepoForHandler) Delete(ctx context.Context, id string) error {
+	delete(m.versions, id)
+	return nil
+}
+
+func (m *mockScoringProfileRepoForHandler) FindByID(ctx context.Context, id string) (*entity.ScoringProfile, error) {
+	versions := m.versions[id]
+	if len(versions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	profile := *versions[len(versions)-1]
+	profile.IsDefault = m.defaultID == id
+	return &profile, nil
+}
+
+func (m *mockScoringProfileRepoForHandler) FindVersion(ctx context.Context, id string, version int) (*entity.ScoringProfile, error) {
+	for _, profile := range m.versions[id] {
+		if profile.Version == version {
+			return profile, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockScoringProfileRepoForHandler) FindVersions(ctx context.Context, id string) ([]*entity.ScoringProfile, error) {
+	return m.versions[id], nil
+}
+
+func (m *mockScoringProfileRepoForHandler) FindAll(ctx context.Context) ([]*entity.ScoringProfile, error) {
+	var profiles []*entity.ScoringProfile
+	for id := range m.versions {
+		profile, _ := m.FindByID(ctx, id)
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func (m *mockScoringProfileRepoForHandler) FindDefault(ctx context.Context) (*entity.ScoringProfile, error) {
+	if m.defaultID == "" {
+		return nil, nil
+	}
+	return m.FindByID(ctx, m.defaultID)
+}
+
+func (m *mockScoringProfileRepoForHandler) SetDefault(ctx context.Context, id string) error {
+	m.defaultID = id
+	return nil
+}
+
+func setupScoringProfileRouter(authenticated bool) *gin.Engine {
+	repo := &mockScoringProfileRepoForHandler{versions: make(map[string][]*entity.ScoringProfile)}
+	repo.versions["prof-1"] = []*entity.ScoringProfile{
+		{ID: "prof-1", Version: 1, Name: "Weighted", BlockedCredit: 1, DetectedCredit: 0.5},
+	}
+	svc := application.NewScoringProfileService(repo, nil, service.NewScoreCalculator())
+
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", "test-user")
+			c.Next()
+		})
+	}
+	NewScoringProfileHandler(svc).RegisterRoutes(router.Group("/api/v1"))
+	return router
+}
+
+func TestScoringProfileHandler_List(t *testing.T) {
+	router := setupScoringProfileRouter(true)
+
+	w := performSelectorRequest(router, http.MethodGet, "/api/v1/scoring-profiles", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var profiles []entity.ScoringProfile
+	_ = json.Unmarshal(w.Body.Bytes(), &profiles)
+	if len(profiles) != 1 || profiles[0].Name != "Weighted" {
+		t.Errorf("Unexpected profiles: %+v", profiles)
+	}
+}
+
+func TestScoringProfileHandler_CreateAppliesDefaultCredits(t *testing.T) {
+	router := setupScoringProfileRouter(true)
+
+	w := performSelectorRequest(router, http.MethodPost, "/api/v1/scoring-profiles", map[string]interface{}{
+		"name":           "Execution first",
+		"tactic_weights": map[string]float64{"execution": 2},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var profile entity.ScoringProfile
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	if profile.Version != 1 || profile.BlockedCredit != 1 || profile.DetectedCredit != 0.5 || profile.CreatedBy != "test-user" {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+
+	w = performSelectorRequest(router, http.MethodPost, "/api/v1/scoring-profiles", map[string]interface{}{
+		"name": "Bad", "detected_credit": 2,
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid credit, got %d", w.Code)
+	}
+}
+
+func TestScoringProfileHandler_UpdateCreatesVersion(t *testing.T) {
+	router := setupScoringProfileRouter(true)
+
+	w := performSelectorRequest(router, http.MethodPut, "/api/v1/scoring-profiles/prof-1", map[string]interface{}{
+		"name": "Weighted", "detected_credit": 0,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var profile entity.ScoringProfile
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	if profile.Version != 2 || profile.DetectedCredit != 0 {
+		t.Errorf("Unexpected profile: %+v", profile)
+	}
+
+	w = performSelectorRequest(router, http.MethodGet, "/api/v1/scoring-profiles/prof-1/versions", nil)
+	var versions []entity.ScoringProfile
+	_ = json.Unmarshal(w.Body.Bytes(), &versions)
+	if w.Code != http.StatusOK || len(versions) != 2 {
+		t.Errorf("Expected 2 versions, got %d (%d)", len(versions), w.Code)
+	}
+
+	w = performSelectorRequest(router, http.MethodGet, "/api/v1/scoring-profiles/prof-1/versions/1", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	if w.Code != http.StatusOK || profile.DetectedCredit != 0.5 {
+		t.Errorf("Expected version 1 unchanged, got %+v (%d)", profile, w.Code)
+	}
+	if w := performSelectorRequest(router, http.MethodGet, "/api/v1/scoring-profiles/prof-1/versions/x", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid version, got %d", w.Code)
+	}
+	if w := performSelectorRequest(router, http.MethodGet, "/api/v1/scoring-profiles/prof-1/versions/7", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing version, got %d", w.Code)
+	}
+}
+
+func TestScoringProfileHandler_SetDefaultAndDelete(t *testing.T) {
+	router := setupScoringProfileRouter(true)
+
+	w := performSelectorRequest(router, http.MethodPost, "/api/v1/scoring-profiles/prof-1/default", nil)
+	var profile entity.ScoringProfile
+	_ = json.Unmarshal(w.Body.Bytes(), &profile)
+	if w.Code != http.StatusOK || !profile.IsDefault {
+		t.Errorf("Expected the default profile, got %+v (%d)", profile, w.Code)
+	}
+
+	if w := performSelectorRequest(router, http.MethodDelete, "/api/v1/scoring-profiles/prof-1", nil); w.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", w.Code)
+	}
+	if w := performSelectorRequest(router, http.MethodGet, "/api/v1/scoring-profiles/prof-1", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestScoringProfileHandler_RequiresAuthentication(t *testing.T) {
+	router := setupScoringProfileRouter(false)
+
+	if w := performSelectorRequest(router, http.MethodPost, "/api/v1/scoring-profiles", map[string]interface{}{"name": "p"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+	if w := performSelectorRequest(router, http.MethodDelete, "/api/v1/scoring-profiles/prof-1", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+}
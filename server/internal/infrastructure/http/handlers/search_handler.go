@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles the full-text search
+type SearchHandler struct {
+	service *application.SearchService
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(service *application.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+// Search godoc
+// @Summary Search techniques, scenarios and results
+// @Description Full-text search of technique names, descriptions and commands, scenario names, descriptions and tags, and result outputs, best matches first. Every term must match; a term ending with * matches the words it prefixes. Only the types the role may view are searched.
+// @Tags search
+// @Produce json
+// @Param q query string true "Search terms"
+// @Param type query string false "Comma-separated types to search: technique, scenario, result (default: all)"
+// @Param limit query int false "Limit (default: 20, max: 100)"
+// @Success 200 {array} entity.SearchHit
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	requested := entity.SearchTypes()
+	if param := c.Query("type"); param != "" {
+		requested = nil
+		for _, name := range strings.Split(param, ",") {
+			t := entity.SearchType(strings.TrimSpace(name))
+			if !t.IsValid() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid search type: " + string(t)})
+				return
+			}
+			requested = append(requested, t)
+		}
+	}
+
+	role := entity.UserRole(c.GetString("role"))
+	var types []entity.SearchType
+	for _, t := range requested {
+		if entity.HasPermission(role, t.Permission()) {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		c.JSON(http.StatusOK, []*entity.SearchHit{})
+		return
+	}
+
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	hits, err := h.service.Search(c.Request.Context(), c.Query("q"), types, limit)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidSearchQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search"})
+		return
+	}
+
+	if hits == nil {
+		hits = []*entity.SearchHit{}
+	}
+	c.JSON(http.StatusOK, hits)
+}
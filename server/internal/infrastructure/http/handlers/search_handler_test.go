@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockSearchRepo struct {
+	types []entity.SearchType
+	limit int
+	err   error
+}
+
+func (m *mockSearchRepo) Search(ctx context.Context, query string, types []entity.SearchType, limit int) ([]*entity.SearchHit, error) {
+	m.types, m.limit = types, limit
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []*entity.SearchHit{{Type: entity.SearchTechnique, ID: "T1033", Title: "System Owner Discovery", Score: 1.5}}, nil
+}
+
+func TestSearchHandler_Search(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &mockSearchRepo{}
+	handler := NewSearchHandler(application.NewSearchService(repo))
+
+	search := func(role, query string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/search", func(c *gin.Context) { c.Set("role", role) }, handler.Search)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?"+query, nil))
+		return w
+	}
+
+	w := search("viewer", "q=whoami&type=technique,%20result&limit=5")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var hits []entity.SearchHit
+	if err := json.Unmarshal(w.Body.Bytes(), &hits); err != nil || len(hits) != 1 || hits[0].ID != "T1033" {
+		t.Errorf("hits = %v, %v", hits, err)
+	}
+	if len(repo.types) != 2 || repo.types[1] != entity.SearchResult || repo.limit != 5 {
+		t.Errorf("searched %v limit %d", repo.types, repo.limit)
+	}
+
+	// Roles only search the types they may view
+	repo.types = nil
+	if w := search("guest", "q=whoami"); w.Code != http.StatusOK || w.Body.String() != "[]" || repo.types != nil {
+		t.Errorf("unknown role: status %d, body %s, searched %v", w.Code, w.Body.String(), repo.types)
+	}
+
+	for query, want := range map[string]int{
+		"q=":                   http.StatusBadRequest,
+		"q=whoami&type=agent":  http.StatusBadRequest,
+		"q=whoami&type=result": http.StatusOK,
+	} {
+		if w := search("admin", query); w.Code != want {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, want)
+		}
+	}
+
+	repo.err = errors.New("database locked")
+	if w := search("admin", "q=whoami"); w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecretHandler manages the secrets store. Values are written, never read back.
+type SecretHandler struct {
+	service *application.SecretService
+}
+
+// NewSecretHandler creates a new secret handler
+func NewSecretHandler(service *application.SecretService) *SecretHandler {
+	return &SecretHandler{service: service}
+}
+
+// PutSecretRequest is the value of a secret and its description
+type PutSecretRequest struct {
+	Value       string `json:"value" binding:"required"`
+	Description string `json:"description"`
+}
+
+// ListSecrets godoc
+// @Summary List secrets
+// @Description List the secrets of the secrets store by name, without their value
+// @Tags admin
+// @Produce json
+// @Success 200 {array} entity.Secret
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/secrets [get]
+func (h *SecretHandler) ListSecrets(c *gin.Context) {
+	secrets, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list secrets"})
+		return
+	}
+
+	if secrets == nil {
+		secrets = []*entity.Secret{}
+	}
+	c.JSON(http.StatusOK, secrets)
+}
+
+// PutSecret godoc
+// @Summary Create or update a secret
+// @Description Encrypt and store the value of a secret, referred to as secret://{name} by configuration values. The value is never returned.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "Secret name"
+// @Param request body PutSecretRequest true "Secret"
+// @Success 200 {object} entity.Secret
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/secrets/{name} [put]
+func (h *SecretHandler) PutSecret(c *gin.Context) {
+	var req PutSecretRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret, err := h.service.Put(c.Request.Context(), c.Param("name"), req.Description, req.Value, c.GetString("user_id"))
+	switch {
+	case errors.Is(err, application.ErrInvalidSecret):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save secret"})
+	default:
+		c.JSON(http.StatusOK, secret)
+	}
+}
+
+// DeleteSecret godoc
+// @Summary Delete a secret
+// @Description Delete a secret; the configuration values referring to it no longer resolve on the next start
+// @Tags admin
+// @Param name path string true "Secret name"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/admin/secrets/{name} [delete]
+func (h *SecretHandler) DeleteSecret(c *gin.Context) {
+	err := h.service.Delete(c.Request.Context(), c.Param("name"))
+	switch {
+	case errors.Is(err, application.ErrSecretNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete secret"})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
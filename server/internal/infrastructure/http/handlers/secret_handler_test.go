@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockSecretRepoForHandler struct {
+	secrets map[string]*entity.Secret
+}
+
+func (m *mockSecretRepoForHandler) Save(ctx context.Context, secret *entity.Secret) error {
+	m.secrets[secret.Name] = secret
+	return nil
+}
+
+func (m *mockSecretRepoForHandler) FindByName(ctx context.Context, name string) (*entity.Secret, error) {
+	if secret, ok := m.secrets[name]; ok {
+		return secret, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *mockSecretRepoForHandler) FindAll(ctx context.Context) ([]*entity.Secret, error) {
+	var secrets []*entity.Secret
+	for _, secret := range m.secrets {
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+func (m *mockSecretRepoForHandler) Delete(ctx context.Context, name string) error {
+	delete(m.secrets, name)
+	return nil
+}
+
+func TestSecretHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, _ := application.NewLocalMasterKey("master-key")
+	repo := &mockSecretRepoForHandler{secrets: map[string]*entity.Secret{}}
+	handler := NewSecretHandler(application.NewSecretService(repo, key))
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", "admin-1"); c.Next() })
+	router.GET("/api/v1/admin/secrets", handler.ListSecrets)
+	router.PUT("/api/v1/admin/secrets/:name", handler.PutSecret)
+	router.DELETE("/api/v1/admin/secrets/:name", handler.DeleteSecret)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/api/v1/admin/secrets", "")
+	if w.Code != http.StatusOK || w.Body.String() != "[]" {
+		t.Fatalf("Expected an empty list, got %d: %s", w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+	}{
+		{"stored", "/api/v1/admin/secrets/splunk-token", `{"value":"token-value","description":"Splunk"}`, http.StatusOK},
+		{"no value", "/api/v1/admin/secrets/splunk-token", `{}`, http.StatusBadRequest},
+		{"invalid name", "/api/v1/admin/secrets/Splunk", `{"value":"token-value"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(http.MethodPut, tt.path, tt.body)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if strings.Contains(w.Body.String(), "token-value") {
+				t.Errorf("Expected the value never returned: %s", w.Body.String())
+			}
+		})
+	}
+
+	w = serve(http.MethodGet, "/api/v1/admin/secrets", "")
+	var secrets []entity.Secret
+	if err := json.Unmarshal(w.Body.Bytes(), &secrets); err != nil || len(secrets) != 1 ||
+		secrets[0].Name != "splunk-token" || secrets[0].UpdatedBy != "admin-1" || strings.Contains(w.Body.String(), "ciphertext") {
+		t.Fatalf("Unexpected secrets: %s", w.Body.String())
+	}
+
+	if w := serve(http.MethodDelete, "/api/v1/admin/secrets/splunk-token", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if w := serve(http.MethodDelete, "/api/v1/admin/secrets/splunk-token", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Outcomes of a result of a batch
+const (
+	taskResultReceived  = "received"
+	taskResultDuplicate = "duplicate"
+	taskResultFailed    = "failed"
+	taskResultBusy      = "busy" // Not taken while the result queue is full, to resend later
+)
+
+// errAgentNotRegistered is returned for results sent before the agent registered
+var errAgentNotRegistered = errors.New("agent is not registered")
+
+// TaskResultsPayload is a batch of task results from an agent
+type TaskResultsPayload struct {
+	Results []TaskResultPayload `json:"results"`
+}
+
+// TaskResultAck is the outcome of a result of a batch
+type TaskResultAck struct {
+	TaskID string `json:"task_id"`
+	Status string `json:"status"` // received, duplicate or failed
+	Error  string `json:"error,omitempty"`
+}
+
+// TaskResultsAck acknowledges a batch of task results, result by result
+type TaskResultsAck struct {
+	Results    []TaskResultAck `json:"results"`
+	Error      string          `json:"error,omitempty"`       // Set when the whole batch was rejected
+	RetryAfter int             `json:"retry_after,omitempty"` // Seconds to wait before resending a batch rejected while the server is busy
+}
+
+// handleTaskResults applies a batch of results sent over the connection of an
+// agent and acknowledges each
+func (h *WebSocketHandler) handleTaskResults(client *websocket.Client, payload json.RawMessage) {
+	var batch TaskResultsPayload
+	if err := json.Unmarshal(payload, &batch); err != nil {
+		h.logger.Warn("Failed to parse task results payload", zap.Error(err))
+		return
+	}
+
+	acks, err := h.ingestResults(client, batch.Results)
+	if err != nil {
+		h.logger.Warn("Rejected task results", zap.Error(err), zap.String("paw", client.GetAgentPaw()))
+		ack := TaskResultsAck{Results: []TaskResultAck{}, Error: err.Error()}
+		if errors.Is(err, application.ErrResultQueueFull) {
+			ack.RetryAfter = h.retryAfterSeconds()
+		}
+		_ = client.Send("task_results_ack", ack)
+		return
+	}
+	_ = client.Send("task_results_ack", TaskResultsAck{Results: acks})
+}
+
+// PostTaskResults godoc
+// @Summary Send a batch of task results from an agent
+// @Description Apply up to 100 results of the agent of a long-poll session in one call, as the task_results message does. Each result is acknowledged on its own: received, duplicate (a retried submission_id already applied) or failed with its error. Results that fail do not fail the others. While the result queue is full the batch is refused with 503 and a Retry-After header, to be resent unchanged.
+// @Tags agents
+// @Accept json
+// @Produce json
+// @Param session path string true "Session ID"
+// @Param results body TaskResultsPayload true "Results"
+// @Success 200 {object} TaskResultsAck
+// @Success 207 {object} TaskResultsAck "Some results failed"
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 503 {object} TaskResultsAck "Result queue full"
+// @Router /ws/agent/poll/{session}/results [post]
+func (h *WebSocketHandler) PostTaskResults(c *gin.Context) {
+	if !h.authorizeAgent(c) {
+		return
+	}
+	client, ok := h.polls.Get(c.Param("session"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "poll session not found"})
+		return
+	}
+
+	var batch TaskResultsPayload
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, websocket.MaxPollMessageSize)
+	if err := json.NewDecoder(body).Decode(&batch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid results"})
+		return
+	}
+
+	acks, err := h.ingestResults(client, batch.Results)
+	switch {
+	case errors.Is(err, errAgentNotRegistered):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, application.ErrInvalidResultBatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, application.ErrResultQueueFull):
+		retryAfter := h.retryAfterSeconds()
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		c.JSON(http.StatusServiceUnavailable, TaskResultsAck{Results: []TaskResultAck{}, Error: err.Error(), RetryAfter: retryAfter})
+		return
+	case err != nil:
+		h.logger.Error("Failed to apply task results", zap.Error(err), zap.String("paw", client.GetAgentPaw()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to apply task results"})
+		return
+	}
+
+	status := http.StatusOK
+	for _, ack := range acks {
+		if ack.Status == taskResultFailed {
+			status = http.StatusMultiStatus
+			break
+		}
+	}
+	c.JSON(status, TaskResultsAck{Results: acks})
+}
+
+// ingestResults applies a batch of results of the agent of client, storing
+// the results of executions together, and returns the outcome of each
+func (h *WebSocketHandler) ingestResults(client *websocket.Client, results []TaskResultPayload) ([]TaskResultAck, error) {
+	agentPaw := client.GetAgentPaw()
+	if agentPaw == "" {
+		return nil, errAgentNotRegistered
+	}
+	if len(results) == 0 || len(results) > application.MaxResultBatch {
+		return nil, application.ErrInvalidResultBatch
+	}
+	ctx := client.Context()
+
+	acks := make([]TaskResultAck, len(results))
+	var batch []application.ResultSubmission
+	var indexes []int // Result of each submission
+	for i, result := range results {
+		acks[i] = TaskResultAck{TaskID: result.TaskID, Status: taskResultReceived}
+		if application.IsAdHocTaskID(result.TaskID) {
+			h.completeAdHocTask(client, result, &acks[i])
+			continue
+		}
+		batch = append(batch, application.ResultSubmission{
+			SubmissionID: result.SubmissionID,
+			ResultID:     result.TaskID,
+			Status:       taskResultStatus(result),
+			Output:       taskResultOutput(result),
+			ExitCode:     result.ExitCode,
+		})
+		indexes = append(indexes, i)
+	}
+	if len(batch) == 0 {
+		return acks, nil
+	}
+	if h.executionService == nil {
+		for _, i := range indexes {
+			acks[i].Status, acks[i].Error = taskResultFailed, "results are not accepted"
+		}
+		return acks, nil
+	}
+
+	outcomes, err := h.submitResults(ctx, agentPaw, batch)
+	if err != nil {
+		return nil, err
+	}
+	applied := false
+	for j, outcome := range outcomes {
+		ack := &acks[indexes[j]]
+		switch {
+		case outcome.Duplicate:
+			ack.Status = taskResultDuplicate
+		case errors.Is(outcome.Err, entity.ErrResultTransition):
+			// Acknowledged, so the agent stops resending a result that is already final
+			h.logger.Warn("Ignoring out-of-order result update", zap.Error(outcome.Err), zap.String("task_id", ack.TaskID))
+		case outcome.Err != nil:
+			h.logger.Error("Failed to update result", zap.Error(outcome.Err), zap.String("task_id", ack.TaskID))
+			ack.Status, ack.Error = taskResultFailed, outcome.Err.Error()
+		default:
+			applied = true
+			if status := batch[j].Status; (status == entity.StatusSuccess || status == entity.StatusFailed) && h.detectionService != nil {
+				h.detectionService.ScheduleVerification(ack.TaskID)
+			}
+		}
+	}
+	if applied {
+		h.dispatchReadyTasks(ctx, agentPaw)
+	}
+
+	h.logger.Info("Received task results", zap.String("paw", agentPaw), zap.Int("results", len(results)))
+	return acks, nil
+}
+
+// submitResults applies a batch of results through the result queue when set
+func (h *WebSocketHandler) submitResults(ctx context.Context, agentPaw string, batch []application.ResultSubmission) ([]application.ResultSubmissionOutcome, error) {
+	if h.resultQueue != nil {
+		return h.resultQueue.SubmitResults(ctx, agentPaw, batch)
+	}
+	return h.executionService.SubmitResults(ctx, agentPaw, batch)
+}
+
+// submitResult applies a single result through the result queue when set,
+// reporting whether it is a retry already applied
+func (h *WebSocketHandler) submitResult(ctx context.Context, result TaskResultPayload, status entity.ResultStatus, output, agentPaw string) (bool, error) {
+	if h.resultQueue == nil {
+		return h.executionService.SubmitResult(ctx, result.SubmissionID, result.TaskID, status, output, result.ExitCode, agentPaw)
+	}
+	outcomes, err := h.resultQueue.SubmitResults(ctx, agentPaw, []application.ResultSubmission{{
+		SubmissionID: result.SubmissionID,
+		ResultID:     result.TaskID,
+		Status:       status,
+		Output:       output,
+		ExitCode:     result.ExitCode,
+	}})
+	if err != nil {
+		return false, err
+	}
+	return outcomes[0].Duplicate, outcomes[0].Err
+}
+
+// retryAfterSeconds returns how long agents wait before resending results
+// refused by the full result queue
+func (h *WebSocketHandler) retryAfterSeconds() int {
+	if h.resultQueue == nil {
+		return 0
+	}
+	return int(h.resultQueue.RetryAfter() / time.Second)
+}
+
+// completeAdHocTask records the result of an ad-hoc command of a batch in ack
+func (h *WebSocketHandler) completeAdHocTask(client *websocket.Client, result TaskResultPayload, ack *TaskResultAck) {
+	if h.adhocService == nil {
+		ack.Status, ack.Error = taskResultFailed, "ad-hoc results are not accepted"
+		return
+	}
+	task, err := h.adhocService.Complete(client.Context(), result.TaskID, client.GetAgentPaw(),
+		taskResultStatus(result), taskResultOutput(result), result.ExitCode)
+	switch {
+	case errors.Is(err, entity.ErrResultTransition):
+		ack.Status = taskResultDuplicate
+	case err != nil:
+		ack.Status, ack.Error = taskResultFailed, err.Error()
+	default:
+		broadcastAdHocTask(h.hub, "adhoc_task_completed", task)
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+	"autostrike/internal/infrastructure/websocket"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestWebSocketHandler_PostTaskResults(t *testing.T) {
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+	go hub.Run()
+
+	agentRepo := newWSTestAgentRepo()
+	handler := NewWebSocketHandler(hub, application.NewAgentService(agentRepo), logger)
+	resultRepo := newWSTestResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionRunning}
+	for _, id := range []string{"task-1", "task-2", "task-3"} {
+		resultRepo.results[id] = &entity.ExecutionResult{ID: id, ExecutionID: "exec-1", AgentPaw: "batch-agent", Status: entity.StatusRunning}
+	}
+	execService := application.NewExecutionService(resultRepo, &wsTestScenarioRepo{}, &wsTestTechniqueRepo{}, agentRepo, nil, service.NewScoreCalculator())
+	execService.SetSubmissionLog(&wsTestSubmissionRepo{keys: make(map[string]bool)})
+	handler.SetExecutionService(execService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		return w
+	}
+
+	w := request(http.MethodPost, "/ws/agent/poll", nil)
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &session)
+	path := "/ws/agent/poll/" + session.SessionID
+
+	batch := TaskResultsPayload{Results: []TaskResultPayload{
+		{TaskID: "task-1", Success: true, Output: "ok", SubmissionID: "sub-1"},
+		{TaskID: "unknown", Success: true, SubmissionID: "sub-2"},
+	}}
+	if w := request(http.MethodPost, path+"/results", batch); w.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 before the agent registered, got %d: %s", w.Code, w.Body.String())
+	}
+	request(http.MethodPost, path, map[string]interface{}{
+		"type": "register", "payload": RegisterPayload{Paw: "batch-agent", Hostname: "host", Platform: "linux"},
+	})
+
+	// A result that fails is reported without failing the others
+	w = request(http.MethodPost, path+"/results", batch)
+	if w.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected 207, got %d: %s", w.Code, w.Body.String())
+	}
+	var ack TaskResultsAck
+	_ = json.Unmarshal(w.Body.Bytes(), &ack)
+	if len(ack.Results) != 2 || ack.Results[0].Status != "received" || ack.Results[1].Status != "failed" || ack.Results[1].Error == "" {
+		t.Fatalf("Unexpected acks: %+v", ack.Results)
+	}
+	if resultRepo.results["task-1"].Status != entity.StatusSuccess {
+		t.Errorf("Expected task-1 applied, got %s", resultRepo.results["task-1"].Status)
+	}
+
+	// A resent result is a duplicate
+	batch.Results = []TaskResultPayload{
+		{TaskID: "task-1", Success: true, Output: "ok", SubmissionID: "sub-1"},
+		{TaskID: "task-2", Success: false, ExitCode: 1, SubmissionID: "sub-3"},
+	}
+	w = request(http.MethodPost, path+"/results", batch)
+	_ = json.Unmarshal(w.Body.Bytes(), &ack)
+	if w.Code != http.StatusOK || ack.Results[0].Status != "duplicate" || ack.Results[1].Status != "received" {
+		t.Fatalf("Expected 200 with a duplicate, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := request(http.MethodPost, path+"/results", TaskResultsPayload{}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an empty batch, got %d", w.Code)
+	}
+	if w := request(http.MethodPost, "/ws/agent/poll/unknown/results", batch); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown session, got %d", w.Code)
+	}
+
+	// The task_results message is acknowledged with a task_results_ack
+	request(http.MethodPost, path, map[string]interface{}{
+		"type": "task_results", "payload": TaskResultsPayload{Results: []TaskResultPayload{{TaskID: "task-3", Success: true}}},
+	})
+	w = request(http.MethodGet, path+"?wait=1", nil)
+	var polled struct {
+		Messages []websocket.Message `json:"messages"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &polled)
+	last := polled.Messages[len(polled.Messages)-1]
+	_ = json.Unmarshal(last.Payload, &ack)
+	if last.Type != "task_results_ack" || len(ack.Results) != 1 || ack.Results[0].Status != "received" {
+		t.Errorf("Expected the batch acknowledged, got %s %s", last.Type, last.Payload)
+	}
+	if resultRepo.results["task-3"].Status != entity.StatusSuccess {
+		t.Errorf("Expected task-3 applied, got %s", resultRepo.results["task-3"].Status)
+	}
+	if resultRepo.executions["exec-1"].Status != entity.ExecutionCompleted {
+		t.Errorf("Expected the execution completed, got %s", resultRepo.executions["exec-1"].Status)
+	}
+}
+
+// heldResultRepo holds result updates until released
+type heldResultRepo struct {
+	*wsTestResultRepo
+	release chan struct{}
+}
+
+func (m *heldResultRepo) UpdateResult(ctx context.Context, result *entity.ExecutionResult) error {
+	<-m.release
+	return m.wsTestResultRepo.UpdateResult(ctx, result)
+}
+
+func TestWebSocketHandler_PostTaskResults_QueueFull(t *testing.T) {
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+	go hub.Run()
+
+	agentRepo := newWSTestAgentRepo()
+	handler := NewWebSocketHandler(hub, application.NewAgentService(agentRepo), logger)
+	resultRepo := &heldResultRepo{wsTestResultRepo: newWSTestResultRepo(), release: make(chan struct{})}
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionRunning}
+	for _, id := range []string{"task-1", "task-2"} {
+		resultRepo.results[id] = &entity.ExecutionResult{ID: id, ExecutionID: "exec-1", AgentPaw: "busy-agent", Status: entity.StatusRunning}
+	}
+	execService := application.NewExecutionService(resultRepo, &wsTestScenarioRepo{}, &wsTestTechniqueRepo{}, agentRepo, nil, service.NewScoreCalculator())
+	handler.SetExecutionService(execService)
+	queue := application.NewResultQueue(execService, application.ResultQueueConfig{Size: 1}, logger)
+	queue.Start()
+	defer queue.Stop()
+	handler.SetResultQueue(queue)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	router.GET("/admin/result-queue", NewResultQueueHandler(queue).GetStats)
+	request := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		return w
+	}
+
+	w := request(http.MethodPost, "/ws/agent/poll", nil)
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &session)
+	path := "/ws/agent/poll/" + session.SessionID
+	request(http.MethodPost, path, map[string]interface{}{
+		"type": "register", "payload": RegisterPayload{Paw: "busy-agent", Hostname: "host", Platform: "linux"},
+	})
+
+	// The first result fills the queue while its write is held
+	first := make(chan int, 1)
+	go func() {
+		first <- request(http.MethodPost, path+"/results", TaskResultsPayload{Results: []TaskResultPayload{{TaskID: "task-1", Success: true}}}).Code
+	}()
+	deadline := time.Now().Add(time.Second)
+	for queue.Stats().Depth != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the first result queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	w = request(http.MethodPost, path+"/results", TaskResultsPayload{Results: []TaskResultPayload{{TaskID: "task-2", Success: true}}})
+	var ack TaskResultsAck
+	_ = json.Unmarshal(w.Body.Bytes(), &ack)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "5" || ack.RetryAfter != 5 {
+		t.Fatalf("Expected 503 with Retry-After, got %d %q: %s", w.Code, w.Header().Get("Retry-After"), w.Body.String())
+	}
+
+	w = request(http.MethodGet, "/admin/result-queue", nil)
+	var stats application.ResultQueueStats
+	_ = json.Unmarshal(w.Body.Bytes(), &stats)
+	if stats.Depth != 1 || stats.Capacity != 1 || stats.Rejected != 1 {
+		t.Errorf("Unexpected queue stats: %s", w.Body.String())
+	}
+
+	close(resultRepo.release)
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("Expected the queued result applied, got %d", code)
+	}
+	if resultRepo.results["task-1"].Status != entity.StatusSuccess {
+		t.Errorf("Expected task-1 applied, got %s", resultRepo.results["task-1"].Status)
+	}
+}
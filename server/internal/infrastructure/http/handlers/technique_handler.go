@@ -36,10 +36,26 @@ func (h *TechniqueHandler) RegisterRoutes(r *gin.RouterGroup) {
 		techniques.GET("/coverage", h.GetCoverage)
 		techniques.POST("/import", h.ImportTechniques)
 		techniques.POST("/import/json", h.ImportTechniquesJSON)
+		techniques.PUT("/:id/status", h.UpdateTechniqueStatus)
+		techniques.PUT("/:id/metadata", h.UpdateTechniqueMetadata)
+		techniques.DELETE("/:id", h.DeleteTechnique)
 	}
 }
 
-// ListTechniques returns all techniques
+// metadataQueryPrefix prefixes the query parameters filtering techniques by custom field
+const metadataQueryPrefix = "metadata."
+
+// ListTechniques godoc
+// @Summary List techniques
+// @Description List the techniques, optionally filtered by lifecycle status, severity and custom fields (metadata.owner_team=red)
+// @Tags techniques
+// @Produce json
+// @Param status query string false "Lifecycle status"
+// @Param severity query string false "Severity: low, medium, high or critical"
+// @Success 200 {array} entity.Technique
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/techniques [get]
 func (h *TechniqueHandler) ListTechniques(c *gin.Context) {
 	techniques, err := h.service.GetAllTechniques(c.Request.Context())
 	if err != nil {
@@ -47,6 +63,44 @@ func (h *TechniqueHandler) ListTechniques(c *gin.Context) {
 		return
 	}
 
+	if status := entity.TechniqueStatus(c.Query("status")); status != "" {
+		if !status.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid technique status"})
+			return
+		}
+		filtered := make([]*entity.Technique, 0, len(techniques))
+		for _, t := range techniques {
+			if t.LifecycleStatus() == status {
+				filtered = append(filtered, t)
+			}
+		}
+		techniques = filtered
+	}
+
+	if severity := strings.ToLower(c.Query("severity")); severity != "" {
+		if !entity.IsValidSeverity(severity) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid technique severity"})
+			return
+		}
+		filtered := make([]*entity.Technique, 0, len(techniques))
+		for _, t := range techniques {
+			if t.EffectiveSeverity() == severity {
+				filtered = append(filtered, t)
+			}
+		}
+		techniques = filtered
+	}
+
+	if filter := metadataFilter(c); len(filter) > 0 {
+		filtered := make([]*entity.Technique, 0, len(techniques))
+		for _, t := range techniques {
+			if t.MatchesMetadata(filter) {
+				filtered = append(filtered, t)
+			}
+		}
+		techniques = filtered
+	}
+
 	// Return empty array instead of null
 	if techniques == nil {
 		techniques = []*entity.Technique{}
@@ -54,7 +108,15 @@ func (h *TechniqueHandler) ListTechniques(c *gin.Context) {
 	c.JSON(http.StatusOK, techniques)
 }
 
-// GetTechnique returns a specific technique
+// GetTechnique godoc
+// @Summary Get a technique
+// @Description Get a technique with its executors
+// @Tags techniques
+// @Produce json
+// @Param id path string true "Technique ID"
+// @Success 200 {object} entity.Technique
+// @Failure 404 {object} gin.H
+// @Router /api/v1/techniques/{id} [get]
 func (h *TechniqueHandler) GetTechnique(c *gin.Context) {
 	id := c.Param("id")
 
@@ -67,7 +129,15 @@ func (h *TechniqueHandler) GetTechnique(c *gin.Context) {
 	c.JSON(http.StatusOK, technique)
 }
 
-// GetByTactic returns techniques by MITRE tactic
+// GetByTactic godoc
+// @Summary List the techniques of a tactic
+// @Description List the techniques of a MITRE ATT&CK tactic
+// @Tags techniques
+// @Produce json
+// @Param tactic path string true "Tactic, e.g. discovery"
+// @Success 200 {array} entity.Technique
+// @Failure 500 {object} gin.H
+// @Router /api/v1/techniques/tactic/{tactic} [get]
 func (h *TechniqueHandler) GetByTactic(c *gin.Context) {
 	tactic := entity.TacticType(c.Param("tactic"))
 
@@ -84,7 +154,15 @@ func (h *TechniqueHandler) GetByTactic(c *gin.Context) {
 	c.JSON(http.StatusOK, techniques)
 }
 
-// GetByPlatform returns techniques by platform
+// GetByPlatform godoc
+// @Summary List the techniques of a platform
+// @Description List the techniques with an executor for a platform
+// @Tags techniques
+// @Produce json
+// @Param platform path string true "Platform, e.g. linux"
+// @Success 200 {array} entity.Technique
+// @Failure 500 {object} gin.H
+// @Router /api/v1/techniques/platform/{platform} [get]
 func (h *TechniqueHandler) GetByPlatform(c *gin.Context) {
 	platform := c.Param("platform")
 
@@ -101,7 +179,14 @@ func (h *TechniqueHandler) GetByPlatform(c *gin.Context) {
 	c.JSON(http.StatusOK, techniques)
 }
 
-// GetCoverage returns MITRE ATT&CK coverage statistics
+// GetCoverage godoc
+// @Summary Get the ATT&CK coverage
+// @Description Count the techniques of each MITRE ATT&CK tactic
+// @Tags techniques
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/techniques/coverage [get]
 func (h *TechniqueHandler) GetCoverage(c *gin.Context) {
 	coverage, err := h.service.GetCoverage(c.Request.Context())
 	if err != nil {
@@ -112,6 +197,123 @@ func (h *TechniqueHandler) GetCoverage(c *gin.Context) {
 	c.JSON(http.StatusOK, coverage)
 }
 
+// DeleteTechnique godoc
+// @Summary Delete a technique
+// @Description Move a technique to the trash, from which it can be restored
+// @Tags techniques
+// @Param id path string true "Technique ID"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/techniques/{id} [delete]
+func (h *TechniqueHandler) DeleteTechnique(c *gin.Context) {
+	if err := h.service.DeleteTechnique(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, application.ErrTechniqueNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "technique not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateTechniqueStatusRequest represents the request body for a lifecycle transition
+type UpdateTechniqueStatusRequest struct {
+	Status entity.TechniqueStatus `json:"status" binding:"required"`
+}
+
+// UpdateTechniqueStatus godoc
+// @Summary Change the lifecycle status of a technique
+// @Description Move a technique to another lifecycle state
+// @Tags techniques
+// @Accept json
+// @Produce json
+// @Param id path string true "Technique ID"
+// @Param request body UpdateTechniqueStatusRequest true "Status"
+// @Success 200 {object} entity.Technique
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/techniques/{id}/status [put]
+func (h *TechniqueHandler) UpdateTechniqueStatus(c *gin.Context) {
+	var req UpdateTechniqueStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	technique, err := h.service.SetTechniqueStatus(c.Request.Context(), c.Param("id"), req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrTechniqueNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "technique not found"})
+		case errors.Is(err, application.ErrInvalidTechniqueStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, application.ErrInvalidTechniqueTransition):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update technique status"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, technique)
+}
+
+// metadataFilter returns the custom fields a request filters on, from its
+// metadata.<field>=<value> query parameters
+func metadataFilter(c *gin.Context) entity.Metadata {
+	filter := entity.Metadata{}
+	for key, values := range c.Request.URL.Query() {
+		if field, ok := strings.CutPrefix(key, metadataQueryPrefix); ok && field != "" && len(values) > 0 {
+			filter[field] = values[0]
+		}
+	}
+	return filter
+}
+
+// UpdateTechniqueMetadataRequest represents the request body replacing the custom fields of a technique
+type UpdateTechniqueMetadataRequest struct {
+	Metadata entity.Metadata `json:"metadata" binding:"required"`
+}
+
+// UpdateTechniqueMetadata godoc
+// @Summary Set the custom fields of a technique
+// @Description Replace the organization custom fields of a technique
+// @Tags techniques
+// @Accept json
+// @Produce json
+// @Param id path string true "Technique ID"
+// @Param request body UpdateTechniqueMetadataRequest true "Custom fields"
+// @Success 200 {object} entity.Technique
+// @Failure 400 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/techniques/{id}/metadata [put]
+func (h *TechniqueHandler) UpdateTechniqueMetadata(c *gin.Context) {
+	var req UpdateTechniqueMetadataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	technique, err := h.service.SetTechniqueMetadata(c.Request.Context(), c.Param("id"), req.Metadata)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrTechniqueNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "technique not found"})
+		case errors.Is(err, application.ErrInvalidTechniqueMetadata):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update technique metadata"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, technique)
+}
+
 // ImportRequest represents the request body for importing techniques
 type ImportRequest struct {
 	Path string `json:"path" binding:"required"`
@@ -146,7 +348,17 @@ func validateImportPath(requestPath string) error {
 	return errors.New("import path must be within the configs directory")
 }
 
-// ImportTechniques imports techniques from YAML file
+// ImportTechniques godoc
+// @Summary Import techniques from a file
+// @Description Import the techniques of a YAML file on the server
+// @Tags techniques
+// @Accept json
+// @Produce json
+// @Param request body ImportRequest true "File path"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/techniques/import [post]
 func (h *TechniqueHandler) ImportTechniques(c *gin.Context) {
 	var req ImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -180,7 +392,16 @@ type ImportJSONResponse struct {
 	Errors   []string `json:"errors,omitempty"`
 }
 
-// ImportTechniquesJSON imports techniques directly from JSON request body
+// ImportTechniquesJSON godoc
+// @Summary Import techniques
+// @Description Create or update the techniques of the request
+// @Tags techniques
+// @Accept json
+// @Produce json
+// @Param request body ImportJSONRequest true "Techniques"
+// @Success 200 {object} ImportJSONResponse
+// @Failure 400 {object} gin.H
+// @Router /api/v1/techniques/import/json [post]
 func (h *TechniqueHandler) ImportTechniquesJSON(c *gin.Context) {
 	var req ImportJSONRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
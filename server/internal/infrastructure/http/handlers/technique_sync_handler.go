@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"autostrike/internal/application"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TechniqueSyncHandler exposes technique catalog syncs to admins
+type TechniqueSyncHandler struct {
+	service *application.TechniqueSyncService
+}
+
+// NewTechniqueSyncHandler creates a new technique sync handler
+func NewTechniqueSyncHandler(service *application.TechniqueSyncService) *TechniqueSyncHandler {
+	return &TechniqueSyncHandler{service: service}
+}
+
+// RegisterRoutes registers technique sync routes (requires admin role)
+func (h *TechniqueSyncHandler) RegisterRoutes(r *gin.RouterGroup) {
+	r.POST("/admin/techniques/sync-mitre", h.StartSync)
+	r.GET("/admin/techniques/sync-mitre", h.ListJobs)
+	r.GET("/admin/techniques/sync-mitre/:id", h.GetJob)
+}
+
+// SyncTechniquesRequest represents the request to sync the technique catalog
+type SyncTechniquesRequest struct {
+	DeprecateRemoved bool `json:"deprecate_removed"`
+}
+
+// StartSync godoc
+// @Summary Sync techniques from MITRE ATT&CK and Atomic Red Team
+// @Description Download the ATT&CK STIX bundle of each configured domain and the Atomic Red Team index in the background, give each ATT&CK technique the executors of its atomic tests (techniques without one become drafts) and import the merge incrementally. Curated techniques are left as they are, and nothing is imported when the upstream versions did not change since the last sync.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body SyncTechniquesRequest false "Whether to deprecate the techniques removed upstream"
+// @Success 202 {object} application.TechniqueSyncJob
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /api/v1/admin/techniques/sync-mitre [post]
+func (h *TechniqueSyncHandler) StartSync(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	// An empty body syncs without deprecating removed techniques
+	var req SyncTechniquesRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	job, err := h.service.StartSync(application.TechniqueSyncRequest{DeprecateRemoved: req.DeprecateRemoved}, c.GetString("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrTechniqueSyncInProgress):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start technique sync"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ListJobs godoc
+// @Summary List technique sync jobs
+// @Description List the technique sync jobs started since the server booted, newest first
+// @Tags admin
+// @Produce json
+// @Success 200 {array} application.TechniqueSyncJob
+// @Failure 401 {object} gin.H
+// @Router /api/v1/admin/techniques/sync-mitre [get]
+func (h *TechniqueSyncHandler) ListJobs(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.service.ListJobs())
+}
+
+// GetJob godoc
+// @Summary Get technique sync job
+// @Description Get the progress of a technique sync job, the version of each downloaded source and the import result
+// @Tags admin
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} application.TechniqueSyncJob
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/admin/techniques/sync-mitre/{id} [get]
+func (h *TechniqueSyncHandler) GetJob(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	job, err := h.service.GetJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stubContentFetcher serves the same content for every URL, blocking until release is closed when set
+type stubContentFetcher struct {
+	release chan struct{}
+}
+
+func (f *stubContentFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	if f.release != nil {
+		<-f.release
+	}
+	return []byte("Synced scenario"), nil
+}
+
+// stubSyncConverter converts any content into T1082, in the format of a sync source
+type stubSyncConverter struct {
+	format string
+}
+
+func (c *stubSyncConverter) Format() string { return c.format }
+
+func (c *stubSyncConverter) Convert(data []byte) (*application.ContentBundle, error) {
+	technique := &entity.Technique{ID: "T1082", Name: "System Information Discovery", Tactic: entity.TacticDiscovery,
+		Tactics: []entity.TacticType{entity.TacticDiscovery}, Platforms: []string{"linux"}}
+	if c.format == application.AtomicContentFormat {
+		technique.Executors = []entity.Executor{{Type: "sh", Command: "uname -a"}}
+	}
+	return &application.ContentBundle{Techniques: []*entity.Technique{technique}}, nil
+}
+
+func setupTechniqueSyncRouter(fetcher application.ContentFetcher) (*gin.Engine, *application.TechniqueSyncService) {
+	techRepo := newMockTechniqueRepo()
+	imports := application.NewContentImportService(
+		application.NewTechniqueService(techRepo),
+		application.NewScenarioService(newMockScenarioRepo(), techRepo, service.NewTechniqueValidator()),
+		&stubSyncConverter{format: application.STIXContentFormat}, &stubSyncConverter{format: application.AtomicContentFormat},
+	)
+	config := application.TechniqueSyncConfig{STIXURL: "https://example.com/{domain}.json", AtomicsURL: "https://example.com/atomics.yaml"}
+	svc := application.NewTechniqueSyncService(imports, fetcher, config, nil)
+	handler := NewTechniqueSyncHandler(svc)
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group("", func(c *gin.Context) { c.Set("user_id", "admin-1") }))
+	return router, svc
+}
+
+func waitForTechniqueSyncJob(t *testing.T, svc *application.TechniqueSyncService, id string) *application.TechniqueSyncJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := svc.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob failed: %v", err)
+		}
+		if job.Status != application.TechniqueSyncRunning {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("technique sync job did not finish in time")
+	return nil
+}
+
+func TestTechniqueSyncHandler_StartSync(t *testing.T) {
+	router, svc := setupTechniqueSyncRouter(&stubContentFetcher{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/techniques/sync-mitre", strings.NewReader(`{"deprecate_removed":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var started application.TechniqueSyncJob
+	if err := json.Unmarshal(w.Body.Bytes(), &started); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if !started.DeprecateRemoved || started.StartedBy != "admin-1" {
+		t.Errorf("Unexpected started job: %+v", started)
+	}
+
+	job := waitForTechniqueSyncJob(t, svc, started.ID)
+	if job.Status != application.TechniqueSyncCompleted || job.Added != 1 {
+		t.Errorf("Expected the source imported, got %+v", job)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/techniques/sync-mitre/"+started.ID, nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/techniques/sync-mitre", nil)
+	router.ServeHTTP(w, req)
+	var jobs []application.TechniqueSyncJob
+	_ = json.Unmarshal(w.Body.Bytes(), &jobs)
+	if w.Code != http.StatusOK || len(jobs) != 1 {
+		t.Errorf("Expected the started job listed, got %d %+v", w.Code, jobs)
+	}
+}
+
+func TestTechniqueSyncHandler_StartSync_Errors(t *testing.T) {
+	router, _ := setupTechniqueSyncRouter(&stubContentFetcher{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/techniques/sync-mitre", strings.NewReader("{invalid"))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/admin/techniques/sync-mitre/missing", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestTechniqueSyncHandler_StartSync_Conflict(t *testing.T) {
+	fetcher := &stubContentFetcher{release: make(chan struct{})}
+	router, svc := setupTechniqueSyncRouter(fetcher)
+
+	job, err := svc.StartSync(application.TechniqueSyncRequest{}, "admin-1")
+	if err != nil {
+		t.Fatalf("StartSync failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/techniques/sync-mitre", nil)
+	router.ServeHTTP(w, req)
+
+	close(fetcher.release)
+	waitForTechniqueSyncJob(t, svc, job.ID)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+}
+
+func TestTechniqueSyncHandler_Unauthenticated(t *testing.T) {
+	_, svc := setupTechniqueSyncRouter(&stubContentFetcher{})
+	handler := NewTechniqueSyncHandler(svc)
+
+	router := gin.New()
+	handler.RegisterRoutes(router.Group(""))
+
+	for _, r := range []struct{ method, path string }{
+		{"POST", "/admin/techniques/sync-mitre"},
+		{"GET", "/admin/techniques/sync-mitre"},
+		{"GET", "/admin/techniques/sync-mitre/job-1"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(r.method, r.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected status 401, got %d", r.method, r.path, w.Code)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TicketHandler serves the tracker issues opened for undetected techniques
+type TicketHandler struct {
+	service *application.TicketService
+}
+
+// NewTicketHandler creates a new ticket handler
+func NewTicketHandler(service *application.TicketService) *TicketHandler {
+	return &TicketHandler{service: service}
+}
+
+// RegisterRoutes registers the ticket routes
+func (h *TicketHandler) RegisterRoutes(r *gin.RouterGroup) {
+	tickets := r.Group("/tickets")
+	{
+		tickets.GET("", h.ListTickets)
+		tickets.POST("/sync", h.SyncTickets)
+	}
+}
+
+// ListTickets godoc
+// @Summary List tickets
+// @Description List the most recent Jira or ServiceNow issues opened for techniques that ran undetected, newest first
+// @Tags tickets
+// @Produce json
+// @Param status query string false "Filter by status (open, resolved)"
+// @Param limit query int false "Limit (default: 100, max: 500)"
+// @Success 200 {array} entity.Ticket
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/tickets [get]
+func (h *TicketHandler) ListTickets(c *gin.Context) {
+	status := entity.TicketStatus(c.Query("status"))
+	if status != "" && !entity.IsValidTicketStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status, must be open or resolved"})
+		return
+	}
+
+	limit := 100
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 && parsed <= 500 {
+			limit = parsed
+		}
+	}
+
+	tickets, err := h.service.List(c.Request.Context(), status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list tickets"})
+		return
+	}
+
+	if tickets == nil {
+		tickets = []*entity.Ticket{}
+	}
+	c.JSON(http.StatusOK, tickets)
+}
+
+// SyncTickets godoc
+// @Summary Sync tickets with the tracker
+// @Description Resolve the open tickets closed in the tracker or whose technique was since blocked or detected, without waiting for the periodic sync
+// @Tags tickets
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 502 {object} gin.H
+// @Router /api/v1/tickets/sync [post]
+func (h *TicketHandler) SyncTickets(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
+		return
+	}
+
+	resolved, err := h.service.Sync(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error(), "resolved": resolved})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"provider": h.service.Provider(), "resolved": resolved})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockTicketRepoForHandler implements repository.TicketRepository for tests
+type mockTicketRepoForHandler struct {
+	tickets []*entity.Ticket
+}
+
+func (m *mockTicketRepoForHandler) Create(ctx context.Context, ticket *entity.Ticket) error {
+	m.tickets = append(m.tickets, ticket)
+	return nil
+}
+
+func (m *mockTicketRepoForHandler) Update(ctx context.Context, ticket *entity.Ticket) error {
+	return nil
+}
+
+func (m *mockTicketRepoForHandler) FindByID(ctx context.Context, id string) (*entity.Ticket, error) {
+	return nil, nil
+}
+
+func (m *mockTicketRepoForHandler) FindOpen(ctx context.Context, techniqueID, agentPaw string) (*entity.Ticket, error) {
+	return nil, nil
+}
+
+func (m *mockTicketRepoForHandler) FindAll(ctx context.Context, status entity.TicketStatus, limit int) ([]*entity.Ticket, error) {
+	var tickets []*entity.Ticket
+	for _, ticket := range m.tickets {
+		if status == "" || ticket.Status == status {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+// closedTicketConnector reports every issue closed in the tracker
+type closedTicketConnector struct{}
+
+func (closedTicketConnector) Name() string { return "jira" }
+
+func (closedTicketConnector) CreateTicket(ctx context.Context, req application.TicketRequest) (*application.TicketRef, error) {
+	return &application.TicketRef{Key: "SEC-1"}, nil
+}
+
+func (closedTicketConnector) GetTicket(ctx context.Context, key string) (*application.TicketState, error) {
+	return &application.TicketState{Status: "Done", Closed: true}, nil
+}
+
+func (closedTicketConnector) CommentTicket(ctx context.Context, key, comment string) error {
+	return nil
+}
+
+func (closedTicketConnector) ResolveTicket(ctx context.Context, key, comment string) error {
+	return nil
+}
+
+func setupTicketRouter(authenticated bool) (*gin.Engine, *mockTicketRepoForHandler) {
+	repo := &mockTicketRepoForHandler{tickets: []*entity.Ticket{
+		{ID: "t1", Provider: "jira", ExternalKey: "SEC-1", TechniqueID: "T1059", AgentPaw: "paw1", ResultID: "r1", Status: entity.TicketOpen},
+		{ID: "t2", Provider: "jira", ExternalKey: "SEC-2", TechniqueID: "T1082", AgentPaw: "paw1", Status: entity.TicketResolved},
+	}}
+	svc := application.NewTicketService(repo, newMockResultRepo(), nil, nil, nil, closedTicketConnector{},
+		application.DefaultTicketConfig(), nil)
+
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", "test-user")
+			c.Next()
+		})
+	}
+	NewTicketHandler(svc).RegisterRoutes(router.Group("/api/v1"))
+	return router, repo
+}
+
+func TestTicketHandler_ListTickets(t *testing.T) {
+	router, _ := setupTicketRouter(true)
+
+	w := performSelectorRequest(router, http.MethodGet, "/api/v1/tickets?status=open", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	var tickets []entity.Ticket
+	_ = json.Unmarshal(w.Body.Bytes(), &tickets)
+	if len(tickets) != 1 || tickets[0].ExternalKey != "SEC-1" {
+		t.Errorf("Expected the open ticket, got %+v", tickets)
+	}
+
+	if w := performSelectorRequest(router, http.MethodGet, "/api/v1/tickets?status=closed", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid status, got %d", w.Code)
+	}
+}
+
+func TestTicketHandler_SyncTickets(t *testing.T) {
+	router, repo := setupTicketRouter(true)
+
+	w := performSelectorRequest(router, http.MethodPost, "/api/v1/tickets/sync", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Provider string `json:"provider"`
+		Resolved int    `json:"resolved"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Provider != "jira" || body.Resolved != 1 {
+		t.Errorf("Unexpected sync response %+v", body)
+	}
+	if repo.tickets[0].Status != entity.TicketResolved || repo.tickets[0].Resolution != entity.TicketResolutionClosed {
+		t.Errorf("Expected SEC-1 resolved as closed, got %+v", repo.tickets[0])
+	}
+}
+
+func TestTicketHandler_SyncRequiresAuthentication(t *testing.T) {
+	router, _ := setupTicketRouter(false)
+
+	if w := performSelectorRequest(router, http.MethodPost, "/api/v1/tickets/sync", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrashHandler lists and restores the deleted scenarios and techniques
+type TrashHandler struct {
+	service *application.TrashService
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(service *application.TrashService) *TrashHandler {
+	return &TrashHandler{service: service}
+}
+
+// ListScenarios godoc
+// @Summary List deleted scenarios
+// @Description Get the scenarios in the trash, most recently deleted first. They are purged once kept for longer than the trash retention, unless past executions still reference them.
+// @Tags scenarios
+// @Produce json
+// @Success 200 {array} entity.Scenario
+// @Router /api/v1/scenarios/trash [get]
+func (h *TrashHandler) ListScenarios(c *gin.Context) {
+	scenarios, err := h.service.ListScenarios(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deleted scenarios"})
+		return
+	}
+	// Return empty array instead of null
+	if scenarios == nil {
+		scenarios = []*entity.Scenario{}
+	}
+	c.JSON(http.StatusOK, scenarios)
+}
+
+// RestoreScenario godoc
+// @Summary Restore a deleted scenario
+// @Description Take a scenario out of the trash
+// @Tags scenarios
+// @Param id path string true "Scenario ID"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Router /api/v1/scenarios/{id}/restore [post]
+func (h *TrashHandler) RestoreScenario(c *gin.Context) {
+	if err := h.service.RestoreScenario(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, application.ErrNotInTrash) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "scenario not in the trash"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore scenario"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListTechniques godoc
+// @Summary List deleted techniques
+// @Description Get the techniques in the trash, most recently deleted first. They are purged once kept for longer than the trash retention, unless past results still reference them.
+// @Tags techniques
+// @Produce json
+// @Success 200 {array} entity.Technique
+// @Router /api/v1/techniques/trash [get]
+func (h *TrashHandler) ListTechniques(c *gin.Context) {
+	techniques, err := h.service.ListTechniques(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deleted techniques"})
+		return
+	}
+	// Return empty array instead of null
+	if techniques == nil {
+		techniques = []*entity.Technique{}
+	}
+	c.JSON(http.StatusOK, techniques)
+}
+
+// RestoreTechnique godoc
+// @Summary Restore a deleted technique
+// @Description Take a technique out of the trash
+// @Tags techniques
+// @Param id path string true "Technique ID"
+// @Success 204
+// @Failure 404 {object} gin.H
+// @Router /api/v1/techniques/{id}/restore [post]
+func (h *TrashHandler) RestoreTechnique(c *gin.Context) {
+	if err := h.service.RestoreTechnique(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, application.ErrNotInTrash) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "technique not in the trash"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore technique"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockTrashRepoForHandler implements repository.TrashRepository for tests
+type mockTrashRepoForHandler struct {
+	scenarios  map[string]*entity.Scenario
+	techniques map[string]*entity.Technique
+}
+
+func (m *mockTrashRepoForHandler) FindDeletedScenarios(ctx context.Context) ([]*entity.Scenario, error) {
+	var scenarios []*entity.Scenario
+	for _, s := range m.scenarios {
+		scenarios = append(scenarios, s)
+	}
+	return scenarios, nil
+}
+
+func (m *mockTrashRepoForHandler) FindDeletedTechniques(ctx context.Context) ([]*entity.Technique, error) {
+	var techniques []*entity.Technique
+	for _, t := range m.techniques {
+		techniques = append(techniques, t)
+	}
+	return techniques, nil
+}
+
+func (m *mockTrashRepoForHandler) RestoreScenario(ctx context.Context, id string) (bool, error) {
+	_, ok := m.scenarios[id]
+	delete(m.scenarios, id)
+	return ok, nil
+}
+
+func (m *mockTrashRepoForHandler) RestoreTechnique(ctx context.Context, id string) (bool, error) {
+	_, ok := m.techniques[id]
+	delete(m.techniques, id)
+	return ok, nil
+}
+
+func (m *mockTrashRepoForHandler) PurgeScenarios(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockTrashRepoForHandler) PurgeTechniques(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestTrashHandler_Routes(t *testing.T) {
+	deletedAt := time.Now()
+	repo := &mockTrashRepoForHandler{
+		scenarios:  map[string]*entity.Scenario{"s1": {ID: "s1", Name: "Old", DeletedAt: &deletedAt}},
+		techniques: map[string]*entity.Technique{},
+	}
+	handler := NewTrashHandler(application.NewTrashService(repo, application.DefaultTrashRetention, nil))
+
+	router := gin.New()
+	router.GET("/scenarios/trash", handler.ListScenarios)
+	router.POST("/scenarios/:id/restore", handler.RestoreScenario)
+	router.GET("/techniques/trash", handler.ListTechniques)
+	router.POST("/techniques/:id/restore", handler.RestoreTechnique)
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		want   string
+	}{
+		{"GET", "/scenarios/trash", http.StatusOK, `"deleted_at"`},
+		{"GET", "/techniques/trash", http.StatusOK, "[]"},
+		{"POST", "/scenarios/s1/restore", http.StatusNoContent, ""},
+		{"POST", "/scenarios/s1/restore", http.StatusNotFound, "scenario not in the trash"},
+		{"POST", "/techniques/T1059/restore", http.StatusNotFound, "technique not in the trash"},
+		{"GET", "/scenarios/trash", http.StatusOK, "[]"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.code, w.Code)
+		}
+		if tt.want != "" && !json.Valid(w.Body.Bytes()) {
+			t.Errorf("%s %s: expected JSON, got %s", tt.method, tt.path, w.Body.String())
+		}
+		if tt.want != "" && !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s %s: expected %q in %s", tt.method, tt.path, tt.want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookDeliveryHandler serves the webhook delivery log of the current user
+type WebhookDeliveryHandler struct {
+	service *application.WebhookDeliveryService
+}
+
+// NewWebhookDeliveryHandler creates a new webhook delivery handler
+func NewWebhookDeliveryHandler(service *application.WebhookDeliveryService) *WebhookDeliveryHandler {
+	return &WebhookDeliveryHandler{service: service}
+}
+
+// RegisterRoutes registers the webhook delivery routes
+func (h *WebhookDeliveryHandler) RegisterRoutes(router *gin.RouterGroup) {
+	deliveries := router.Group("/notifications/webhook-deliveries")
+	{
+		deliveries.GET("", h.ListDeliveries)
+		deliveries.GET("/:id", h.GetDelivery)
+		deliveries.POST("/:id/redeliver", h.Redeliver)
+	}
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description List the most recent webhook and Teams deliveries of the current user, newest first
+// @Tags notifications
+// @Produce json
+// @Param status query string false "Filter by status (pending, delivered, failed)"
+// @Param limit query int false "Limit (default: 50, max: 200)"
+// @Success 200 {array} entity.WebhookDelivery
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /api/v1/notifications/webhook-deliveries [get]
+func (h *WebhookDeliveryHandler) ListDeliveries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	status := entity.WebhookDeliveryStatus(c.Query("status"))
+	if status != "" && !entity.IsValidWebhookDeliveryStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status, must be pending, delivered or failed"})
+		return
+	}
+
+	limit := 50
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parseInt(l); err == nil && parsed > 0 && parsed <= 200 {
+			limit = parsed
+		}
+	}
+
+	deliveries, err := h.service.List(c.Request.Context(), userID.(string), status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list webhook deliveries"})
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []*entity.WebhookDelivery{}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery godoc
+// @Summary Get webhook delivery
+// @Description Get a webhook delivery of the current user, with its payload and last attempt outcome
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} entity.WebhookDelivery
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/notifications/webhook-deliveries/{id} [get]
+func (h *WebhookDeliveryHandler) GetDelivery(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	delivery, err := h.service.Get(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondWebhookDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook
+// @Description Send a webhook delivery again right away, whatever its status
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Delivery ID"
+// @Success 200 {object} entity.WebhookDelivery
+// @Failure 401 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Router /api/v1/notifications/webhook-deliveries/{id}/redeliver [post]
+func (h *WebhookDeliveryHandler) Redeliver(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errNotifNotAuthenticated})
+		return
+	}
+
+	delivery, err := h.service.Redeliver(c.Request.Context(), c.Param("id"), userID.(string))
+	if err != nil {
+		respondWebhookDeliveryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// respondWebhookDeliveryError maps webhook delivery errors to HTTP responses
+func respondWebhookDeliveryError(c *gin.Context, err error) {
+	if errors.Is(err, application.ErrWebhookDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "webhook delivery operation failed"})
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockWebhookDeliveryRepoForHandler struct {
+	deliveries map[string]*entity.WebhookDelivery
+}
+
+func (m *mockWebhookDeliveryRepoForHandler) Create(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *mockWebhookDeliveryRepoForHandler) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *mockWebhookDeliveryRepoForHandler) FindByID(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	delivery, ok := m.deliveries[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return delivery, nil
+}
+
+func (m *mockWebhookDeliveryRepoForHandler) FindByUserID(ctx context.Context, userID string, status entity.WebhookDeliveryStatus, limit int) ([]*entity.WebhookDelivery, error) {
+	var result []*entity.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.UserID == userID && (status == "" || delivery.Status == status) {
+			result = append(result, delivery)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookDeliveryRepoForHandler) FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func setupWebhookDeliveryRouter(t *testing.T, authenticated bool) (*gin.Engine, *mockWebhookDeliveryRepoForHandler) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(receiver.Close)
+
+	now := time.Now()
+	repo := &mockWebhookDeliveryRepoForHandler{deliveries: map[string]*entity.WebhookDelivery{
+		"d1": {ID: "d1", UserID: "test-user", Channel: entity.ChannelWebhook, Event: entity.NotificationExecutionFailed,
+			URL: receiver.URL, Payload: json.RawMessage(`{}`), Status: entity.WebhookDeliveryFailed, Attempts: 6,
+			LastStatusCode: 500, CreatedAt: now, UpdatedAt: now},
+		"d2": {ID: "d2", UserID: "test-user", Channel: entity.ChannelTeams, Event: entity.NotificationAgentOffline,
+			URL: receiver.URL, Payload: json.RawMessage(`{}`), Status: entity.WebhookDeliveryDelivered, Attempts: 1,
+			CreatedAt: now, UpdatedAt: now},
+		"d3": {ID: "d3", UserID: "other-user", Channel: entity.ChannelWebhook, Event: entity.NotificationAgentOffline,
+			URL: receiver.URL, Payload: json.RawMessage(`{}`), Status: entity.WebhookDeliveryFailed, CreatedAt: now, UpdatedAt: now},
+	}}
+	svc := application.NewWebhookDeliveryService(repo, nil, application.DefaultWebhookDeliveryConfig(), nil)
+
+	router := gin.New()
+	if authenticated {
+		router.Use(func(c *gin.Context) {
+			c.Set("user_id", "test-user")
+			c.Next()
+		})
+	}
+	NewWebhookDeliveryHandler(svc).RegisterRoutes(router.Group("/api/v1"))
+	return router, repo
+}
+
+func TestWebhookDeliveryHandler_ListDeliveries(t *testing.T) {
+	router, _ := setupWebhookDeliveryRouter(t, true)
+
+	tests := []struct {
+		query    string
+		code     int
+		expected int
+	}{
+		{"", http.StatusOK, 2},
+		{"?status=failed", http.StatusOK, 1},
+		{"?status=pending&limit=10", http.StatusOK, 0},
+		{"?status=retrying", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notifications/webhook-deliveries"+tt.query, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%q: expected %d, got %d", tt.query, tt.code, w.Code)
+			continue
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		var deliveries []entity.WebhookDelivery
+		_ = json.Unmarshal(w.Body.Bytes(), &deliveries)
+		if len(deliveries) != tt.expected {
+			t.Errorf("%q: expected %d deliveries, got %d", tt.query, tt.expected, len(deliveries))
+		}
+	}
+}
+
+func TestWebhookDeliveryHandler_GetDelivery(t *testing.T) {
+	router, _ := setupWebhookDeliveryRouter(t, true)
+
+	for id, expected := range map[string]int{"d1": http.StatusOK, "d3": http.StatusNotFound, "missing": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/notifications/webhook-deliveries/"+id, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != expected {
+			t.Errorf("%s: expected %d, got %d", id, expected, w.Code)
+		}
+	}
+}
+
+func TestWebhookDeliveryHandler_Redeliver(t *testing.T) {
+	router, repo := setupWebhookDeliveryRouter(t, true)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/notifications/webhook-deliveries/d1/redeliver", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if d := repo.deliveries["d1"]; d.Status != entity.WebhookDeliveryDelivered || d.Attempts != 7 {
+		t.Errorf("Expected the failed delivery to be delivered, got %+v", d)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest(http.MethodPost, "/api/v1/notifications/webhook-deliveries/d3/redeliver", nil)
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for another user's delivery, got %d", w.Code)
+	}
+}
+
+func TestWebhookDeliveryHandler_Unauthenticated(t *testing.T) {
+	router, _ := setupWebhookDeliveryRouter(t, false)
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/notifications/webhook-deliveries"},
+		{http.MethodGet, "/api/v1/notifications/webhook-deliveries/d1"},
+		{http.MethodPost, "/api/v1/notifications/webhook-deliveries/d1/redeliver"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tc.method, tc.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected 401, got %d", tc.method, tc.path, w.Code)
+		}
+	}
+}
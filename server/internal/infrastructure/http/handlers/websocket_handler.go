@@ -1,20 +1,31 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"autostrike/internal/application"
 	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/telemetry"
 	"autostrike/internal/infrastructure/websocket"
 
 	"github.com/gin-gonic/gin"
 	gorillaws "github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+// tracerName is the instrumentation name of the task dispatch and result spans
+const tracerName = "autostrike/websocket"
+
 // getAllowedOrigins returns the list of allowed origins from environment
 func getAllowedOrigins() []string {
 	origins := os.Getenv("ALLOWED_ORIGINS")
@@ -25,9 +36,14 @@ func getAllowedOrigins() []string {
 	return strings.Split(origins, ",")
 }
 
+// dashboardProtocol is the subprotocol dashboards offer with their access
+// token, echoed on upgrade as browsers require for the connection to open
+const dashboardProtocol = "autostrike.events"
+
 var upgrader = gorillaws.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
+	Subprotocols:    []string{dashboardProtocol},
 	CheckOrigin: func(r *http.Request) bool {
 		origin := r.Header.Get("Origin")
 		if origin == "" {
@@ -48,6 +64,16 @@ type WebSocketHandler struct {
 	hub              *websocket.Hub
 	agentService     *application.AgentService
 	executionService *application.ExecutionService
+	resultQueue      *application.ResultQueue
+	detectionService *application.DetectionService
+	artifactService  *application.ArtifactService
+	adhocService     *application.AdHocTaskService
+	updateService    *application.AgentUpdateService
+	beaconService    *application.BeaconService
+	notifications    *application.NotificationService
+	activity         *application.ActivityMonitor
+	dashboardAuth    gin.HandlerFunc
+	polls            *websocket.PollSessions
 	logger           *zap.Logger
 	agentSecret      string
 }
@@ -57,26 +83,73 @@ func NewWebSocketHandler(hub *websocket.Hub, agentService *application.AgentServ
 	return &WebSocketHandler{
 		hub:          hub,
 		agentService: agentService,
+		polls:        websocket.NewPollSessions(hub, logger),
 		logger:       logger,
 		agentSecret:  os.Getenv("AGENT_SECRET"),
 	}
 }
 
+// SetAgentSecret sets the X-Agent-Key agents must connect with, none allowing every agent
+func (h *WebSocketHandler) SetAgentSecret(secret string) {
+	h.agentSecret = secret
+}
+
 // SetExecutionService sets the execution service for result updates
 func (h *WebSocketHandler) SetExecutionService(svc *application.ExecutionService) {
 	h.executionService = svc
 }
 
+// SetResultQueue writes the results agents report through queue, agents
+// being asked to resend them later while it is full
+func (h *WebSocketHandler) SetResultQueue(queue *application.ResultQueue) {
+	h.resultQueue = queue
+}
+
+// SetDetectionService sets the service used to verify executed techniques against SIEMs
+func (h *WebSocketHandler) SetDetectionService(svc *application.DetectionService) {
+	h.detectionService = svc
+}
+
+// SetArtifactService sets the service storing the files agents collect with results
+func (h *WebSocketHandler) SetArtifactService(svc *application.ArtifactService) {
+	h.artifactService = svc
+}
+
+// SetAdHocTaskService sets the service recording the results of ad-hoc commands
+func (h *WebSocketHandler) SetAdHocTaskService(svc *application.AdHocTaskService) {
+	h.adhocService = svc
+}
+
+// SetAgentUpdateService sets the service deciding which agents are told to self-update
+func (h *WebSocketHandler) SetAgentUpdateService(svc *application.AgentUpdateService) {
+	h.updateService = svc
+}
+
+// SetBeaconService enables pushing beacon settings to agents as they check in
+func (h *WebSocketHandler) SetBeaconService(svc *application.BeaconService) {
+	h.beaconService = svc
+}
+
+// SetNotificationService sends dashboards the unread notification count of their user as they connect
+func (h *WebSocketHandler) SetNotificationService(svc *application.NotificationService) {
+	h.notifications = svc
+}
+
+// SetActivityMonitor raises alerts when agents connect from outside their allowed networks
+func (h *WebSocketHandler) SetActivityMonitor(monitor *application.ActivityMonitor) {
+	h.activity = monitor
+}
+
+// SetDashboardAuth sets the middleware authenticating dashboard connections,
+// which sets the user_id and role of the connection
+func (h *WebSocketHandler) SetDashboardAuth(auth gin.HandlerFunc) {
+	h.dashboardAuth = auth
+}
+
 // HandleAgentConnection handles WebSocket connections from agents
 func (h *WebSocketHandler) HandleAgentConnection(c *gin.Context) {
-	// Validate agent secret if configured
-	if h.agentSecret != "" {
-		agentKey := c.GetHeader("X-Agent-Key")
-		if agentKey != h.agentSecret {
-			h.logger.Warn("Agent connection rejected: invalid or missing X-Agent-Key")
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid agent key"})
-			return
-		}
+	if !h.authorizeAgent(c) {
+		return
 	}
 
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
@@ -87,6 +160,7 @@ func (h *WebSocketHandler) HandleAgentConnection(c *gin.Context) {
 
 	// Create client with empty paw (will be set on registration)
 	client := websocket.NewClient(h.hub, conn, "", h.logger)
+	client.SetRemoteIP(c.ClientIP())
 
 	// Register client
 	h.hub.Register(client)
@@ -96,6 +170,16 @@ func (h *WebSocketHandler) HandleAgentConnection(c *gin.Context) {
 	go client.ReadPump(h.handleMessage)
 }
 
+// authorizeAgent validates the agent secret if configured, and aborts the request otherwise
+func (h *WebSocketHandler) authorizeAgent(c *gin.Context) bool {
+	if h.agentSecret == "" || c.GetHeader("X-Agent-Key") == h.agentSecret {
+		return true
+	}
+	h.logger.Warn("Agent connection rejected: invalid or missing X-Agent-Key")
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid agent key"})
+	return false
+}
+
 // HandleDashboardConnection handles WebSocket connections from dashboard clients
 func (h *WebSocketHandler) HandleDashboardConnection(c *gin.Context) {
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
@@ -107,18 +191,33 @@ func (h *WebSocketHandler) HandleDashboardConnection(c *gin.Context) {
 	// Create client with empty paw - dashboard clients are not agents
 	// They receive broadcasts via the clients map, not the agents map
 	// Using empty paw prevents collision when multiple dashboards connect
-	client := websocket.NewClient(h.hub, conn, "", h.logger)
+	userID := c.GetString("user_id")
+	client := websocket.NewDashboardClient(h.hub, conn, userID, c.GetString("role"), h.logger)
 
 	// Register client to receive broadcasts
 	h.hub.Register(client)
 
-	h.logger.Info("Dashboard client connected")
+	h.logger.Info("Dashboard client connected", zap.String("user_id", userID))
+	h.sendUnreadCount(client, userID)
 
 	// Start read/write pumps (dashboard only receives, but needs read pump to detect disconnection)
 	go client.WritePump()
 	go client.ReadPump(h.handleDashboardMessage)
 }
 
+// sendUnreadCount sends a dashboard the unread notification count of its user
+func (h *WebSocketHandler) sendUnreadCount(client *websocket.Client, userID string) {
+	if h.notifications == nil || userID == "" {
+		return
+	}
+	unread, err := h.notifications.GetUnreadCount(client.Context(), userID)
+	if err != nil {
+		h.logger.Warn("Failed to count unread notifications", zap.Error(err), zap.String("user_id", userID))
+		return
+	}
+	_ = client.Send(websocket.EventNotificationCount, websocket.NotificationCountEvent{Unread: unread})
+}
+
 // handleDashboardMessage processes incoming messages from dashboard (mostly ping/pong)
 func (h *WebSocketHandler) handleDashboardMessage(client *websocket.Client, msg *websocket.Message) {
 	// Dashboard clients primarily receive broadcasts, but may send pings
@@ -139,6 +238,12 @@ func (h *WebSocketHandler) handleMessage(client *websocket.Client, msg *websocke
 		h.handleHeartbeat(client, msg.Payload)
 	case "task_result":
 		h.handleTaskResult(client, msg.Payload)
+	case "task_results":
+		h.handleTaskResults(client, msg.Payload)
+	case "task_artifact":
+		h.handleTaskArtifact(client, msg.Payload)
+	case "update_status":
+		h.handleUpdateStatus(client, msg.Payload)
 	default:
 		h.logger.Warn("Unknown message type", zap.String("type", msg.Type))
 	}
@@ -151,6 +256,8 @@ type RegisterPayload struct {
 	Username  string   `json:"username"`
 	Platform  string   `json:"platform"`
 	Executors []string `json:"executors"`
+	Version   string   `json:"version,omitempty"`
+	entity.AgentInventory
 }
 
 func (h *WebSocketHandler) handleRegister(client *websocket.Client, payload json.RawMessage) {
@@ -164,6 +271,7 @@ func (h *WebSocketHandler) handleRegister(client *websocket.Client, payload json
 		zap.String("paw", reg.Paw),
 		zap.String("hostname", reg.Hostname),
 		zap.String("platform", reg.Platform),
+		zap.String("version", reg.Version),
 	)
 
 	// Update client paw
@@ -171,14 +279,110 @@ func (h *WebSocketHandler) handleRegister(client *websocket.Client, payload json
 
 	// Register/update agent in database
 	ctx := client.Context()
-	err := h.agentService.RegisterOrUpdate(ctx, reg.Paw, reg.Hostname, reg.Username, reg.Platform, reg.Executors)
+	err := h.agentService.RegisterOrUpdate(ctx, reg.Paw, reg.Hostname, reg.Username, reg.Platform, reg.Version, reg.Executors, reg.AgentInventory)
 	if err != nil {
 		h.logger.Error("Failed to register agent", zap.Error(err), zap.String("paw", reg.Paw))
 		return
 	}
 
+	h.recordSource(ctx, client, reg.Paw)
+
 	// Send acknowledgment
 	_ = client.Send("registered", map[string]string{"status": "ok", "paw": reg.Paw})
+
+	h.dispatchResumedTasks(ctx, reg.Paw)
+	h.dispatchReadyTasks(ctx, reg.Paw)
+	h.dispatchQueuedTasks(ctx, reg.Paw)
+	if h.beaconService != nil {
+		if agent, err := h.agentService.GetAgent(ctx, reg.Paw); err == nil {
+			h.pushBeacon(client, agent, true)
+		}
+	}
+	h.offerUpdate(client, reg.Paw, reg.Platform, reg.Version)
+}
+
+// recordSource keeps the address an agent connects from, alerting when it is
+// outside the networks the agent is pinned to
+func (h *WebSocketHandler) recordSource(ctx context.Context, client *websocket.Client, paw string) {
+	ip := client.RemoteIP()
+	if ip == "" {
+		return
+	}
+	agent, err := h.agentService.RecordSource(ctx, paw, ip)
+	if err != nil {
+		h.logger.Warn("Failed to record agent source address", zap.Error(err), zap.String("paw", paw))
+		return
+	}
+	if h.activity != nil {
+		h.activity.RecordAgentSource(ctx, agent, ip)
+	}
+}
+
+// dispatchResumedTasks re-dispatches the unanswered tasks of executions resumed
+// after a restart, once their agent is back
+func (h *WebSocketHandler) dispatchResumedTasks(ctx context.Context, paw string) {
+	if h.executionService == nil {
+		return
+	}
+
+	tasks := h.executionService.TakeResumedTasks(ctx, paw)
+	if len(tasks) == 0 {
+		return
+	}
+	h.logger.Info("Re-dispatching tasks of resumed executions", zap.String("paw", paw), zap.Int("tasks", len(tasks)))
+	h.sendTasks(ctx, tasks)
+}
+
+// dispatchReadyTasks dispatches the tasks of conditional phases planned for an agent
+func (h *WebSocketHandler) dispatchReadyTasks(ctx context.Context, paw string) {
+	if h.executionService == nil {
+		return
+	}
+
+	tasks := h.executionService.TakeReadyTasks(ctx, paw)
+	if len(tasks) == 0 {
+		return
+	}
+	h.logger.Info("Dispatching tasks of conditional phases", zap.String("paw", paw), zap.Int("tasks", len(tasks)))
+	h.sendTasks(ctx, tasks)
+}
+
+// dispatchQueuedTasks delivers the tasks queued while an agent was offline
+func (h *WebSocketHandler) dispatchQueuedTasks(ctx context.Context, paw string) {
+	if h.executionService == nil {
+		return
+	}
+
+	tasks := h.executionService.TakeQueuedTasks(ctx, paw)
+	if len(tasks) == 0 {
+		return
+	}
+	h.logger.Info("Delivering queued tasks", zap.String("paw", paw), zap.Int("tasks", len(tasks)))
+	h.sendTasks(ctx, tasks)
+}
+
+// sendTasks sends tasks to their agents, queueing the tasks of agents that are
+// not connected and failing the results of the other tasks that cannot be sent
+func (h *WebSocketHandler) sendTasks(ctx context.Context, tasks []application.TaskDispatchInfo) {
+	for _, task := range tasks {
+		reason := sendTask(ctx, h.hub, task)
+		if reason == "" {
+			continue
+		}
+		if reason == reasonAgentUnavailable && h.executionService.QueueTask(ctx, task) == nil {
+			continue
+		}
+		if err := h.executionService.UpdateResultByID(ctx, task.ResultID, entity.StatusFailed, reason, -1, ""); err != nil {
+			h.logger.Warn("Failed to mark task as failed", zap.Error(err), zap.String("task_id", task.ResultID))
+		}
+	}
+}
+
+// HeartbeatPayload represents the periodic beacon of an agent
+type HeartbeatPayload struct {
+	Paw     string `json:"paw"`
+	Version string `json:"version,omitempty"`
+	SentAt  int64  `json:"sent_at,omitempty"` // Unix milliseconds by the agent clock, for the beacon latency
 }
 
 func (h *WebSocketHandler) handleHeartbeat(client *websocket.Client, payload json.RawMessage) {
@@ -187,20 +391,135 @@ func (h *WebSocketHandler) handleHeartbeat(client *websocket.Client, payload jso
 		return
 	}
 
+	// Older agents send no payload fields beyond their paw
+	var beat HeartbeatPayload
+	_ = json.Unmarshal(payload, &beat)
+	var sentAt *time.Time
+	if beat.SentAt > 0 {
+		at := time.UnixMilli(beat.SentAt)
+		sentAt = &at
+	}
+
 	ctx := client.Context()
-	if err := h.agentService.UpdateHeartbeat(ctx, paw); err != nil {
+	if err := h.agentService.CheckIn(ctx, paw, sentAt); err != nil {
 		h.logger.Error("Failed to update heartbeat", zap.Error(err), zap.String("paw", paw))
 	}
+	h.recordSource(ctx, client, paw)
+	h.dispatchQueuedTasks(ctx, paw)
+
+	if h.beaconService == nil && h.updateService == nil {
+		return
+	}
+	agent, err := h.agentService.GetAgent(ctx, paw)
+	if err != nil {
+		return
+	}
+	h.pushBeacon(client, agent, false)
+
+	// Agents beaconing a version are checked for updates released since they registered
+	if beat.Version == "" {
+		return
+	}
+	h.offerUpdate(client, paw, agent.Platform, beat.Version)
+}
+
+// pushBeacon sends an agent its beacon settings when it registers and when
+// they changed since they were last sent
+func (h *WebSocketHandler) pushBeacon(client *websocket.Client, agent *entity.Agent, registering bool) {
+	if h.beaconService == nil {
+		return
+	}
+
+	settings, err := h.beaconService.CheckIn(client.Context(), agent, registering)
+	if err != nil {
+		h.logger.Warn("Failed to resolve beacon settings", zap.Error(err), zap.String("paw", agent.Paw))
+		return
+	}
+	if settings == nil {
+		return
+	}
+	if err := client.Send("beacon", settings); err != nil {
+		h.logger.Warn("Failed to send beacon settings", zap.Error(err), zap.String("paw", agent.Paw))
+	}
+}
+
+// offerUpdate sends an agent the release it should update to, if any
+func (h *WebSocketHandler) offerUpdate(client *websocket.Client, paw, platform, version string) {
+	if h.updateService == nil {
+		return
+	}
+
+	release, content, err := h.updateService.UpdateFor(client.Context(), paw, platform, version)
+	if err != nil {
+		h.logger.Warn("Failed to check agent update", zap.Error(err), zap.String("paw", paw))
+		return
+	}
+	if release == nil {
+		return
+	}
+	if err := sendAgentUpdate(client, release, content); err != nil {
+		h.logger.Warn("Failed to send agent update", zap.Error(err), zap.String("paw", paw))
+	}
+}
+
+// UpdateStatusPayload represents the outcome of a self-update reported by an agent
+type UpdateStatusPayload struct {
+	Version string `json:"version"`
+	Status  string `json:"status"` // "installed", "failed"
+	Error   string `json:"error,omitempty"`
+}
+
+func (h *WebSocketHandler) handleUpdateStatus(client *websocket.Client, payload json.RawMessage) {
+	paw := client.GetAgentPaw()
+	if h.updateService == nil || paw == "" {
+		return
+	}
+
+	var status UpdateStatusPayload
+	if err := json.Unmarshal(payload, &status); err != nil {
+		h.logger.Warn("Failed to parse update status payload", zap.Error(err))
+		return
+	}
+	h.updateService.ReportStatus(paw, status.Version, status.Status, status.Error)
 }
 
 // TaskResultPayload represents task execution result from agent
 type TaskResultPayload struct {
-	TaskID      string `json:"task_id"`
-	TechniqueID string `json:"technique_id"`
-	Success     bool   `json:"success"`
-	ExitCode    int    `json:"exit_code"`
-	Output      string `json:"output"`
-	Error       string `json:"error,omitempty"`
+	TaskID        string            `json:"task_id"`
+	TechniqueID   string            `json:"technique_id"`
+	Success       bool              `json:"success"`
+	ExitCode      int               `json:"exit_code"`
+	Output        string            `json:"output"`
+	Error         string            `json:"error,omitempty"`
+	LimitExceeded string            `json:"limit_exceeded,omitempty"` // "time", "memory"
+	TraceContext  map[string]string `json:"trace_context,omitempty"`  // Echoed from the task, links the result to its dispatch
+	SubmissionID  string            `json:"submission_id,omitempty"`  // Idempotency key, the same across the retries of the agent
+}
+
+// taskResultStatus maps the outcome reported by the agent to a result status
+func taskResultStatus(result TaskResultPayload) entity.ResultStatus {
+	switch {
+	case result.LimitExceeded == "time":
+		return entity.StatusTimeout
+	case result.LimitExceeded != "":
+		return entity.StatusLimitExceeded
+	case result.Success:
+		return entity.StatusSuccess
+	default:
+		return entity.StatusFailed
+	}
+}
+
+// taskResultOutput prefixes the output reported by the agent with its error and exceeded limit
+func taskResultOutput(result TaskResultPayload) string {
+	output := result.Output
+	if result.Error != "" {
+		output = result.Error + "\n" + output
+	}
+	if result.LimitExceeded != "" {
+		output = "resource limit exceeded: " + result.LimitExceeded + "\n" + output
+	}
+	return output
 }
 
 func (h *WebSocketHandler) handleTaskResult(client *websocket.Client, payload json.RawMessage) {
@@ -215,20 +534,28 @@ func (h *WebSocketHandler) handleTaskResult(client *websocket.Client, payload js
 		zap.String("task_id", result.TaskID),
 		zap.Bool("success", result.Success),
 		zap.Int("exit_code", result.ExitCode),
+		zap.String("limit_exceeded", result.LimitExceeded),
 	)
 
+	if application.IsAdHocTaskID(result.TaskID) {
+		h.handleAdHocTaskResult(client, result)
+		return
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(telemetry.Extract(client.Context(), result.TraceContext), "websocket.task_result",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("result.id", result.TaskID),
+			attribute.String("agent.paw", client.GetAgentPaw()),
+			attribute.String("technique.id", result.TechniqueID),
+		),
+	)
+	defer span.End()
+
 	// Update result in database
 	if h.executionService != nil {
-		ctx := client.Context()
-		status := entity.StatusSuccess
-		if !result.Success {
-			status = entity.StatusFailed
-		}
-
-		output := result.Output
-		if result.Error != "" {
-			output = result.Error + "\n" + output
-		}
+		status := taskResultStatus(result)
+		output := taskResultOutput(result)
 
 		h.logger.Info("Updating result in database",
 			zap.String("task_id", result.TaskID),
@@ -236,10 +563,34 @@ func (h *WebSocketHandler) handleTaskResult(client *websocket.Client, payload js
 		)
 
 		agentPaw := client.GetAgentPaw()
-		if err := h.executionService.UpdateResultByID(ctx, result.TaskID, status, output, result.ExitCode, agentPaw); err != nil {
+		duplicate, err := h.submitResult(ctx, result, status, output, agentPaw)
+		if errors.Is(err, application.ErrResultQueueFull) {
+			// Kept by the agent, which resends it once the queue drained
+			h.logger.Warn("Result queue full, deferring result", zap.String("task_id", result.TaskID))
+			_ = client.Send("task_ack", map[string]interface{}{
+				"task_id": result.TaskID, "status": taskResultBusy, "retry_after": h.retryAfterSeconds(),
+			})
+			return
+		}
+		if duplicate {
+			// A retry of a submission already applied, acknowledged again
+			h.logger.Info("Ignoring duplicate result submission",
+				zap.String("task_id", result.TaskID), zap.String("submission_id", result.SubmissionID))
+			_ = client.Send("task_ack", map[string]string{"task_id": result.TaskID, "status": "duplicate"})
+			return
+		}
+		if errors.Is(err, entity.ErrResultTransition) {
+			// Still acknowledged, so the agent stops resending a result that is already final
+			h.logger.Warn("Ignoring out-of-order result update", zap.Error(err), zap.String("task_id", result.TaskID))
+		} else if err != nil {
+			span.SetStatus(codes.Error, err.Error())
 			h.logger.Error("Failed to update result", zap.Error(err), zap.String("task_id", result.TaskID))
 		} else {
 			h.logger.Info("Result updated successfully", zap.String("task_id", result.TaskID))
+			if (status == entity.StatusSuccess || status == entity.StatusFailed) && h.detectionService != nil {
+				h.detectionService.ScheduleVerification(result.TaskID)
+			}
+			h.dispatchReadyTasks(ctx, agentPaw)
 		}
 	} else {
 		h.logger.Warn("executionService is nil, cannot update result", zap.String("task_id", result.TaskID))
@@ -249,8 +600,61 @@ func (h *WebSocketHandler) handleTaskResult(client *websocket.Client, payload js
 	_ = client.Send("task_ack", map[string]string{"task_id": result.TaskID, "status": "received"})
 }
 
+// handleAdHocTaskResult records the result of an ad-hoc command and streams it to the dashboards
+func (h *WebSocketHandler) handleAdHocTaskResult(client *websocket.Client, result TaskResultPayload) {
+	if h.adhocService == nil {
+		h.logger.Warn("adhocService is nil, cannot record ad-hoc result", zap.String("task_id", result.TaskID))
+	} else if task, err := h.adhocService.Complete(client.Context(), result.TaskID, client.GetAgentPaw(),
+		taskResultStatus(result), taskResultOutput(result), result.ExitCode); errors.Is(err, entity.ErrResultTransition) {
+		h.logger.Warn("Ignoring repeated ad-hoc result", zap.String("task_id", result.TaskID))
+	} else if err != nil {
+		h.logger.Error("Failed to record ad-hoc result", zap.Error(err), zap.String("task_id", result.TaskID))
+	} else {
+		broadcastAdHocTask(h.hub, "adhoc_task_completed", task)
+	}
+
+	_ = client.Send("task_ack", map[string]string{"task_id": result.TaskID, "status": "received"})
+}
+
+// TaskArtifactPayload is a file an agent collected for a task
+type TaskArtifactPayload struct {
+	TaskID string `json:"task_id"`
+	Path   string `json:"path"` // Path the file was read from
+	Data   []byte `json:"data"` // Base64 in JSON
+}
+
+// handleTaskArtifact stores a collected file and tells the agent whether it was kept
+func (h *WebSocketHandler) handleTaskArtifact(client *websocket.Client, payload json.RawMessage) {
+	var artifact TaskArtifactPayload
+	if err := json.Unmarshal(payload, &artifact); err != nil {
+		h.logger.Warn("Failed to parse task artifact payload", zap.Error(err))
+		return
+	}
+
+	ack := map[string]string{"task_id": artifact.TaskID, "path": artifact.Path, "status": "stored"}
+	if h.artifactService == nil {
+		ack["status"] = "rejected"
+		ack["error"] = "artifacts are not enabled"
+	} else if _, err := h.artifactService.Store(client.Context(), client.GetAgentPaw(), artifact.TaskID, artifact.Path, artifact.Data); err != nil {
+		h.logger.Warn("Rejected task artifact", zap.Error(err),
+			zap.String("paw", client.GetAgentPaw()), zap.String("task_id", artifact.TaskID))
+		ack["status"] = "rejected"
+		ack["error"] = err.Error()
+	}
+	_ = client.Send("artifact_ack", ack)
+}
+
 // RegisterRoutes registers WebSocket routes
 func (h *WebSocketHandler) RegisterRoutes(router *gin.Engine) {
 	router.GET("/ws/agent", h.HandleAgentConnection)
-	router.GET("/ws/dashboard", h.HandleDashboardConnection)
+	router.POST("/ws/agent/poll", h.OpenPollSession)
+	router.GET("/ws/agent/poll/:session", h.PollMessages)
+	router.POST("/ws/agent/poll/:session", h.PostMessage)
+	router.POST("/ws/agent/poll/:session/results", h.PostTaskResults)
+	router.DELETE("/ws/agent/poll/:session", h.ClosePollSession)
+	if h.dashboardAuth != nil {
+		router.GET("/ws/dashboard", h.dashboardAuth, h.HandleDashboardConnection)
+	} else {
+		router.GET("/ws/dashboard", h.HandleDashboardConnection)
+	}
 }
@@ -18,6 +18,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	gorillaws "github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/zap"
 )
 
@@ -534,6 +538,34 @@ func TestWebSocketHandler_HandleHeartbeat_ServiceError(t *testing.T) {
 	handler.handleHeartbeat(client, json.RawMessage(`{}`))
 }
 
+func TestWebSocketHandler_HandleHeartbeat_UnexpectedNetwork(t *testing.T) {
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+
+	repo := newWSTestAgentRepo()
+	repo.agents["test-agent"] = &entity.Agent{Paw: "test-agent", AllowedNetworks: []string{"10.0.0.0/8"}}
+	activityRepo := &mockActivityRepo{}
+
+	handler := NewWebSocketHandler(hub, application.NewAgentService(repo), logger)
+	handler.SetActivityMonitor(newTestActivityMonitor(activityRepo, newMockUserRepo()))
+
+	client := websocket.NewClient(hub, nil, "test-agent", logger)
+	client.SetRemoteIP("10.1.2.3")
+	handler.handleHeartbeat(client, json.RawMessage(`{}`))
+	if len(activityRepo.anomalies) != 0 {
+		t.Fatalf("Expected no alert from an allowed network")
+	}
+
+	client.SetRemoteIP("203.0.113.7")
+	handler.handleHeartbeat(client, json.RawMessage(`{}`))
+	if len(activityRepo.anomalies) != 1 || activityRepo.anomalies[0].Type != entity.AnomalyAgentUnexpectedNetwork {
+		t.Fatalf("Expected an unexpected network alert, got %+v", activityRepo.anomalies)
+	}
+	if repo.agents["test-agent"].IPAddress != "203.0.113.7" {
+		t.Errorf("Expected the source address kept, got %s", repo.agents["test-agent"].IPAddress)
+	}
+}
+
 func TestRegisterPayload_JSONMarshal(t *testing.T) {
 	payload := RegisterPayload{
 		Paw:       "agent-123",
@@ -817,6 +849,69 @@ func TestWebSocketHandler_HandleDashboardConnection(t *testing.T) {
 	}
 }
 
+func TestWebSocketHandler_DashboardAuthAndEvents(t *testing.T) {
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+	go hub.Run()
+
+	handler := NewWebSocketHandler(hub, application.NewAgentService(newWSTestAgentRepo()), logger)
+	handler.SetDashboardAuth(func(c *gin.Context) {
+		if c.GetHeader("Sec-WebSocket-Protocol") != "autostrike.events, bearer.valid" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set("user_id", "user-1")
+		c.Set("role", "viewer")
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/dashboard"
+
+	if _, resp, err := gorillaws.DefaultDialer.Dial(wsURL, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected an unauthenticated connection to be rejected, got %v", err)
+	}
+
+	dialer := gorillaws.Dialer{Subprotocols: []string{"autostrike.events", "bearer.valid"}}
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if protocol := resp.Header.Get("Sec-WebSocket-Protocol"); protocol != "autostrike.events" {
+		t.Errorf("Expected the events protocol to be selected, got %q", protocol)
+	}
+
+	// The connection is registered asynchronously by the hub
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		n, _ := hub.PublishDashboardEvent(websocket.EventNotificationCount, websocket.NotificationCountEvent{Unread: 2},
+			func(c *websocket.Client) bool { return c.UserID() == "user-1" })
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the dashboard to be registered for user-1")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var msg websocket.Message
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to read event: %v", err)
+	}
+	if msg.Type != websocket.EventNotificationCount {
+		t.Errorf("Unexpected message %s %s", msg.Type, msg.Payload)
+	}
+}
+
 func TestWebSocketHandler_HandleDashboardMessage_Ping(t *testing.T) {
 	logger := zap.NewNop()
 	hub := websocket.NewHub(logger)
@@ -1289,6 +1384,10 @@ func (m *wsTestResultRepo) FindCompletedExecutionsByDateRange(ctx context.Contex
 	return []*entity.Execution{}, nil
 }
 
+func (m *wsTestResultRepo) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	return []*entity.Execution{}, nil
+}
+
 // Mock scenario repository
 type wsTestScenarioRepo struct{}
 
@@ -1388,6 +1487,69 @@ func TestWebSocketHandler_HandleTaskResult_WithRealExecutionService(t *testing.T
 	}
 }
 
+// wsTestSubmissionRepo records the idempotency keys of result submissions
+type wsTestSubmissionRepo struct {
+	keys map[string]bool
+}
+
+func (m *wsTestSubmissionRepo) ClaimSubmission(ctx context.Context, paw, submissionID, resultID string, receivedAt time.Time) (bool, error) {
+	if m.keys[paw+"/"+submissionID] {
+		return false, nil
+	}
+	m.keys[paw+"/"+submissionID] = true
+	return true, nil
+}
+
+func (m *wsTestSubmissionRepo) ClaimSubmissions(ctx context.Context, paw string, submissions map[string]string, receivedAt time.Time) (map[string]bool, error) {
+	claimed := make(map[string]bool)
+	for submissionID, resultID := range submissions {
+		claimed[submissionID], _ = m.ClaimSubmission(ctx, paw, submissionID, resultID, receivedAt)
+	}
+	return claimed, nil
+}
+
+func (m *wsTestSubmissionRepo) ReleaseSubmission(ctx context.Context, paw, submissionID string) error {
+	delete(m.keys, paw+"/"+submissionID)
+	return nil
+}
+
+func (m *wsTestSubmissionRepo) DeleteSubmissionsBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestWebSocketHandler_HandleTaskResult_DuplicateSubmission(t *testing.T) {
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+	go hub.Run()
+
+	agentRepo := newWSTestAgentRepo()
+	handler := NewWebSocketHandler(hub, application.NewAgentService(agentRepo), logger)
+
+	resultRepo := newWSTestResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionRunning}
+	resultRepo.results["task-dup"] = &entity.ExecutionResult{
+		ID: "task-dup", ExecutionID: "exec-1", AgentPaw: "test-agent", Status: entity.StatusRunning,
+	}
+	execService := application.NewExecutionService(resultRepo, &wsTestScenarioRepo{}, &wsTestTechniqueRepo{}, agentRepo, nil, nil)
+	execService.SetSubmissionLog(&wsTestSubmissionRepo{keys: make(map[string]bool)})
+	handler.SetExecutionService(execService)
+
+	client := websocket.NewClient(hub, nil, "test-agent", logger)
+	payload, _ := json.Marshal(TaskResultPayload{TaskID: "task-dup", Success: true, Output: "first", SubmissionID: "sub-1"})
+	handler.handleTaskResult(client, payload)
+	if result := resultRepo.results["task-dup"]; result.Status != entity.StatusSuccess || result.Output != "first" {
+		t.Fatalf("Expected the first submission applied, got %s %q", result.Status, result.Output)
+	}
+
+	// A retry under the same key is not applied again, whatever it carries
+	resultRepo.results["task-dup"].Status = entity.StatusRunning
+	payload, _ = json.Marshal(TaskResultPayload{TaskID: "task-dup", Success: false, Output: "retry", SubmissionID: "sub-1"})
+	handler.handleTaskResult(client, payload)
+	if result := resultRepo.results["task-dup"]; result.Status != entity.StatusRunning || result.Output != "first" {
+		t.Errorf("Expected the retry dropped, got %s %q", result.Status, result.Output)
+	}
+}
+
 func TestWebSocketHandler_HandleTaskResult_WithExecutionServiceError(t *testing.T) {
 	logger := zap.NewNop()
 	hub := websocket.NewHub(logger)
@@ -1491,3 +1653,202 @@ func TestWebSocketHandler_HandleTaskResult_FailedResultWithError(t *testing.T) {
 		t.Errorf("Expected status 'failed', got '%s'", result.Status)
 	}
 }
+
+func TestTaskResultStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		result TaskResultPayload
+		want   entity.ResultStatus
+	}{
+		{"success", TaskResultPayload{Success: true}, entity.StatusSuccess},
+		{"failure", TaskResultPayload{Success: false}, entity.StatusFailed},
+		{"time limit", TaskResultPayload{LimitExceeded: "time"}, entity.StatusTimeout},
+		{"memory limit", TaskResultPayload{Success: true, LimitExceeded: "memory"}, entity.StatusLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskResultStatus(tt.result); got != tt.want {
+				t.Errorf("taskResultStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketHandler_HandleTaskResult_LimitExceeded(t *testing.T) {
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+	go hub.Run()
+
+	agentRepo := newWSTestAgentRepo()
+	handler := NewWebSocketHandler(hub, application.NewAgentService(agentRepo), logger)
+
+	resultRepo := newWSTestResultRepo()
+	resultRepo.executions["exec-1"] = &entity.Execution{ID: "exec-1", Status: entity.ExecutionRunning}
+	resultRepo.results["task-limit"] = &entity.ExecutionResult{
+		ID:          "task-limit",
+		ExecutionID: "exec-1",
+		AgentPaw:    "test-agent",
+		Status:      entity.StatusPending,
+	}
+	execService := application.NewExecutionService(
+		resultRepo, &wsTestScenarioRepo{}, &wsTestTechniqueRepo{}, agentRepo, nil, nil,
+	)
+	handler.SetExecutionService(execService)
+
+	client := websocket.NewClient(hub, nil, "test-agent", logger)
+	payloadBytes, _ := json.Marshal(TaskResultPayload{
+		TaskID:        "task-limit",
+		TechniqueID:   "T1082",
+		Output:        "partial output",
+		LimitExceeded: "memory",
+	})
+	handler.handleTaskResult(client, payloadBytes)
+
+	result, err := resultRepo.FindResultByID(context.Background(), "task-limit")
+	if err != nil {
+		t.Fatalf("Result not found after update: %v", err)
+	}
+	if result.Status != entity.StatusLimitExceeded {
+		t.Errorf("Expected status 'limit_exceeded', got '%s'", result.Status)
+	}
+	if !strings.HasPrefix(result.Output, "resource limit exceeded: memory") {
+		t.Errorf("Expected violation in output, got %q", result.Output)
+	}
+}
+
+func TestWebSocketHandler_HandleTaskResult_ContinuesDispatchTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(previous)
+
+	logger := zap.NewNop()
+	hub := websocket.NewHub(logger)
+	handler := NewWebSocketHandler(hub, application.NewAgentService(newWSTestAgentRepo()), logger)
+	client := websocket.NewClient(hub, nil, "test-agent", logger)
+
+	payload := TaskResultPayload{
+		TaskID:       "task-traced",
+		TechniqueID:  "T1082",
+		Success:      true,
+		TraceContext: map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	handler.handleTaskResult(client, payloadBytes)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "websocket.task_result" {
+		t.Fatalf("expected a websocket.task_result span, got %v", spans)
+	}
+	if parent := spans[0].Parent(); parent.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("expected the result span to continue the dispatch span, parent = %v", parent)
+	}
+}
+
+// mockTaskQueueRepo implements repository.TaskQueueRepository for tests
+type mockTaskQueueRepo struct {
+	mu    sync.Mutex
+	tasks map[string]*entity.QueuedTask
+}
+
+func (m *mockTaskQueueRepo) Enqueue(ctx context.Context, task *entity.QueuedTask) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[task.ResultID] = task
+	return nil
+}
+
+func (m *mockTaskQueueRepo) Delete(ctx context.Context, resultID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.tasks[resultID]
+	delete(m.tasks, resultID)
+	return ok, nil
+}
+
+func (m *mockTaskQueueRepo) DeleteByExecution(ctx context.Context, executionID string) error {
+	return nil
+}
+
+func (m *mockTaskQueueRepo) FindByAgent(ctx context.Context, paw string) ([]*entity.QueuedTask, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var tasks []*entity.QueuedTask
+	for _, task := range m.tasks {
+		if task.AgentPaw == paw {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *mockTaskQueueRepo) FindExpired(ctx context.Context, now time.Time) ([]*entity.QueuedTask, error) {
+	return nil, nil
+}
+
+func TestWebSocketHandler_DeliversQueuedTasks(t *testing.T) {
+	resultRepo := newMockResultRepo()
+	resultRepo.results["e1"] = []*entity.ExecutionResult{
+		{ID: "r1", ExecutionID: "e1", AgentPaw: "paw1", TechniqueID: "T1082", Status: entity.StatusPending},
+	}
+	execService := application.NewExecutionService(resultRepo, nil, nil, nil, nil, nil)
+	execService.SetTaskQueue(&mockTaskQueueRepo{tasks: make(map[string]*entity.QueuedTask)}, time.Hour)
+
+	hub := websocket.NewHub(zap.NewNop())
+	go hub.Run()
+
+	// The agent is offline: its task is queued instead of failed
+	NewExecutionHandlerWithHub(execService, hub).dispatchTasksToAgents(context.Background(), []application.TaskDispatchInfo{
+		{ResultID: "r1", AgentPaw: "paw1", TechniqueID: "T1082", Command: "hostname", Executor: "sh"},
+	})
+	if status := resultRepo.results["e1"][0].Status; status != entity.StatusQueued {
+		t.Fatalf("Expected the result to be queued, got %s", status)
+	}
+
+	handler := NewWebSocketHandler(hub, application.NewAgentService(newWSTestAgentRepo()), zap.NewNop())
+	handler.SetExecutionService(execService)
+	router := gin.New()
+	router.GET("/ws/agent", handler.HandleAgentConnection)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := gorillaws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/agent", nil)
+	if err != nil {
+		t.Fatalf("Failed to connect WebSocket: %v", err)
+	}
+	defer conn.Close()
+
+	register, _ := json.Marshal(map[string]interface{}{
+		"type":    "register",
+		"payload": RegisterPayload{Paw: "paw1", Hostname: "host", Platform: "linux", Executors: []string{"sh"}},
+	})
+	if err := conn.WriteMessage(gorillaws.TextMessage, register); err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg websocket.Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Expected the queued task, got %v", err)
+		}
+		if msg.Type != "task" {
+			continue
+		}
+		var task struct {
+			ID      string `json:"id"`
+			Command string `json:"command"`
+		}
+		_ = json.Unmarshal(msg.Payload, &task)
+		if task.ID != "r1" || task.Command != "hostname" {
+			t.Errorf("Unexpected task: %s", msg.Payload)
+		}
+		break
+	}
+	if status := resultRepo.results["e1"][0].Status; status != entity.StatusPending {
+		t.Errorf("Expected the delivered result to be pending, got %s", status)
+	}
+}
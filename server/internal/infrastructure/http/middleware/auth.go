@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -22,11 +23,20 @@ type TokenBlacklistChecker interface {
 	IsRevoked(token string) bool
 }
 
+// APIKeyAuthenticator resolves an API key and the user it acts for
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*entity.APIKey, *entity.User, error)
+}
+
+// APIKeyHeader carries an API key, as an alternative to a Bearer API key
+const APIKeyHeader = "X-API-Key"
+
 // AuthConfig contains authentication configuration
 type AuthConfig struct {
 	JWTSecret      string
 	AgentSecret    string
 	TokenBlacklist TokenBlacklistChecker
+	APIKeys        APIKeyAuthenticator // Nil disables API keys
 }
 
 // NoAuthMiddleware creates a middleware that sets default user context when auth is disabled
@@ -43,6 +53,21 @@ func NoAuthMiddleware() gin.HandlerFunc {
 // AuthMiddleware creates an authentication middleware
 func AuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if key := requestAPIKey(c); key != "" && config.APIKeys != nil {
+			apiKey, user, err := config.APIKeys.Authenticate(c.Request.Context(), key)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid API key"})
+				c.Abort()
+				return
+			}
+			c.Set("user_id", user.ID)
+			c.Set("role", string(user.Role))
+			c.Set("workspace", user.Workspace)
+			c.Set("api_key_id", apiKey.ID)
+			c.Next()
+			return
+		}
+
 		tokenString, err := extractBearerToken(c.GetHeader("Authorization"))
 		if err != "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err})
@@ -59,10 +84,73 @@ func AuthMiddleware(config *AuthConfig) gin.HandlerFunc {
 
 		c.Set("user_id", claims["sub"])
 		c.Set("role", claims["role"])
+		if workspace, ok := claims["workspace"].(string); ok {
+			c.Set("workspace", workspace)
+		}
 		c.Next()
 	}
 }
 
+// WebSocketTokenProtocol prefixes the access token offered as a WebSocket
+// subprotocol by browsers, which cannot set headers on WebSocket requests:
+// new WebSocket(url, ["autostrike.events", "bearer." + token])
+const WebSocketTokenProtocol = "bearer."
+
+// WebSocketAuthMiddleware authenticates WebSocket upgrades with an access
+// token from the Authorization header or from the Sec-WebSocket-Protocol
+// header. Tokens are never read from the query string, which is logged.
+func WebSocketAuthMiddleware(config *AuthConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := ""
+		if header := c.GetHeader("Authorization"); header != "" {
+			token, err := extractBearerToken(header)
+			if err != "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err})
+				return
+			}
+			tokenString = token
+		} else {
+			tokenString = websocketProtocolToken(c.GetHeader("Sec-WebSocket-Protocol"))
+		}
+		if tokenString == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "access token required"})
+			return
+		}
+
+		claims, validationErr := validateAccessToken(tokenString, config)
+		if validationErr != "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": validationErr})
+			return
+		}
+
+		c.Set("user_id", claims["sub"])
+		c.Set("role", claims["role"])
+		c.Next()
+	}
+}
+
+// websocketProtocolToken returns the token offered in a Sec-WebSocket-Protocol header
+func websocketProtocolToken(header string) string {
+	for _, protocol := range strings.Split(header, ",") {
+		if token, ok := strings.CutPrefix(strings.TrimSpace(protocol), WebSocketTokenProtocol); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// requestAPIKey returns the API key of a request, from the X-API-Key header or
+// a Bearer token carrying the API key prefix
+func requestAPIKey(c *gin.Context) string {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return key
+	}
+	if token, err := extractBearerToken(c.GetHeader("Authorization")); err == "" && strings.HasPrefix(token, entity.APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
+
 // extractBearerToken extracts the token from an Authorization header.
 // Returns the token string and an empty error, or empty token and error message.
 func extractBearerToken(authHeader string) (string, string) {
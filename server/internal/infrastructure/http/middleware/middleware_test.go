@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -11,6 +13,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -196,6 +204,112 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 	}
 }
 
+// stubAPIKeys accepts the API key "ask_valid" for an analyst of the red workspace
+type stubAPIKeys struct{}
+
+func (stubAPIKeys) Authenticate(ctx context.Context, key string) (*entity.APIKey, *entity.User, error) {
+	if key != "ask_valid" {
+		return nil, nil, errors.New("invalid API key")
+	}
+	return &entity.APIKey{ID: "key-3", UserID: "user-7"}, &entity.User{ID: "user-7", Role: entity.RoleAnalyst, Workspace: "red"}, nil
+}
+
+func TestAuthMiddleware_APIKey(t *testing.T) {
+	router := gin.New()
+	router.Use(AuthMiddleware(&AuthConfig{JWTSecret: "test-secret-key", APIKeys: stubAPIKeys{}}))
+	router.GET("/test", func(c *gin.Context) {
+		if c.GetString("user_id") != "user-7" || c.GetString("role") != "analyst" ||
+			c.GetString("api_key_id") != "key-3" || c.GetString("workspace") != "red" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{"header", APIKeyHeader, "ask_valid", http.StatusOK},
+		{"bearer", "Authorization", "Bearer ask_valid", http.StatusOK},
+		{"unknown key", APIKeyHeader, "ask_revoked", http.StatusUnauthorized},
+		{"unknown bearer key", "Authorization", "Bearer ask_revoked", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/test", nil)
+			req.Header.Set(tt.header, tt.value)
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+
+	// Without API key support, the key is not an access token
+	router = gin.New()
+	router.Use(AuthMiddleware(&AuthConfig{JWTSecret: "test-secret-key"}))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer ask_valid")
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestWebSocketAuthMiddleware(t *testing.T) {
+	secret := "test-secret-key"
+	config := &AuthConfig{JWTSecret: secret}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  "user123",
+		"role": "viewer",
+		"type": "access",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString([]byte(secret))
+
+	router := gin.New()
+	router.GET("/ws", WebSocketAuthMiddleware(config), func(c *gin.Context) {
+		if c.GetString("user_id") != "user123" || c.GetString("role") != "viewer" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"protocol token", "Sec-WebSocket-Protocol", "autostrike.events, bearer." + tokenString, http.StatusOK},
+		{"authorization header", "Authorization", "Bearer " + tokenString, http.StatusOK},
+		{"invalid protocol token", "Sec-WebSocket-Protocol", "autostrike.events, bearer.invalid", http.StatusUnauthorized},
+		{"protocol without token", "Sec-WebSocket-Protocol", "autostrike.events", http.StatusUnauthorized},
+		{"no token", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/ws?token="+tokenString, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
 func TestAuthMiddleware_RefreshTokenRejected(t *testing.T) {
 	secret := "test-secret-key"
 	config := &AuthConfig{JWTSecret: secret}
@@ -1167,3 +1281,90 @@ func TestAuthMiddleware_NilBlacklist(t *testing.T) {
 		t.Errorf("Expected status 200 with nil blacklist, got %d", w.Code)
 	}
 }
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(previous)
+
+	router := gin.New()
+	router.Use(TracingMiddleware())
+	router.GET("/executions/:id", func(c *gin.Context) {
+		if !trace.SpanFromContext(c.Request.Context()).SpanContext().IsValid() {
+			t.Error("expected the handler context to carry the request span")
+		}
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/executions/exec-1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /executions/:id" {
+		t.Errorf("span name = %q", span.Name())
+	}
+	if span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("expected the span to continue the incoming trace, parent = %v", span.Parent())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected an error status for a 500 response, got %v", span.Status().Code)
+	}
+}
+
+func TestNetworkAllowlistMiddleware(t *testing.T) {
+	agentNetworks, _ := entity.ParseNetworkList([]string{"10.0.0.0/8"})
+	apiNetworks, _ := entity.ParseNetworkList([]string{"192.168.1.0/24"})
+
+	router := gin.New()
+	router.Use(NetworkAllowlistMiddleware(NetworkAllowlistConfig{Agent: agentNetworks, API: apiNetworks}, nil))
+	for _, path := range []string{"/ws/agent", "/ws/agent/poll", "/payloads/:token", "/api/v1/agents", "/health"} {
+		router.GET(path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+
+	tests := []struct {
+		path       string
+		remoteAddr string
+		want       int
+	}{
+		{"/ws/agent", "10.1.2.3:4000", http.StatusOK},
+		{"/ws/agent/poll", "10.1.2.3:4000", http.StatusOK},
+		{"/payloads/abc", "10.1.2.3:4000", http.StatusOK},
+		{"/ws/agent", "192.168.1.5:4000", http.StatusForbidden},
+		{"/api/v1/agents", "192.168.1.5:4000", http.StatusOK},
+		{"/api/v1/agents", "10.1.2.3:4000", http.StatusForbidden},
+		{"/health", "203.0.113.7:4000", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		req.RemoteAddr = tt.remoteAddr
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s from %s: expected status %d, got %d", tt.path, tt.remoteAddr, tt.want, w.Code)
+		}
+	}
+}
+
+func TestNetworkAllowlistMiddleware_ZoneWithoutNetworks(t *testing.T) {
+	agentNetworks, _ := entity.ParseNetworkList([]string{"10.0.0.0/8"})
+
+	router := gin.New()
+	router.Use(NetworkAllowlistMiddleware(NetworkAllowlistConfig{Agent: agentNetworks}, nil))
+	router.GET("/api/v1/agents", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/agents", nil)
+	req.RemoteAddr = "203.0.113.7:4000"
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected any source for a zone without networks, got %d", w.Code)
+	}
+}
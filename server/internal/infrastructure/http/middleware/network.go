@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// NetworkZone is a group of endpoints sharing an allowlist of source networks
+type NetworkZone string
+
+const (
+	ZoneAgent NetworkZone = "agent" // Agent connections and payload downloads
+	ZoneAPI   NetworkZone = "api"   // Dashboard and REST API
+)
+
+// NetworkAllowlistConfig holds the networks each zone accepts requests from.
+// A zone without networks accepts any source.
+type NetworkAllowlistConfig struct {
+	Agent entity.NetworkList
+	API   entity.NetworkList
+}
+
+// RequestZone returns the zone of a request path. Health probes belong to no
+// zone, so that load balancers and orchestrators can always reach them.
+func RequestZone(path string) (NetworkZone, bool) {
+	switch {
+	case path == "/health" || path == "/healthz" || path == "/readyz":
+		return "", false
+	case path == "/ws/agent" || strings.HasPrefix(path, "/ws/agent/") || strings.HasPrefix(path, "/payloads/"):
+		return ZoneAgent, true
+	default:
+		return ZoneAPI, true
+	}
+}
+
+// NetworkAllowlistMiddleware rejects requests from a source address outside
+// the networks of their zone with 403. The source is the client IP as resolved
+// from the trusted proxies.
+func NetworkAllowlistMiddleware(config NetworkAllowlistConfig, logger *zap.Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return func(c *gin.Context) {
+		zone, ok := RequestZone(c.Request.URL.Path)
+		if !ok {
+			c.Next()
+			return
+		}
+		networks := config.API
+		if zone == ZoneAgent {
+			networks = config.Agent
+		}
+		if len(networks) == 0 || networks.Contains(c.ClientIP()) {
+			c.Next()
+			return
+		}
+
+		logger.Warn("Request from a source address outside the allowed networks",
+			zap.String("zone", string(zone)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.String("path", c.Request.URL.Path),
+		)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "source address not allowed"})
+	}
+}
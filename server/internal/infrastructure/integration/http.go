@@ -0,0 +1,52 @@
+// Package integration holds HTTP helpers shared by third-party connectors
+// (SIEM, EDR, ticketing, paging).
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a single request to a third-party API
+const DefaultTimeout = 30 * time.Second
+
+// MaxErrorBody limits how much of an error response is included in errors
+const MaxErrorBody = 512
+
+// NewHTTPClient returns an HTTP client with the default timeout
+func NewHTTPClient() *http.Client {
+	return &http.Client{Timeout: DefaultTimeout}
+}
+
+// DoJSON sends a request and decodes a JSON response into out.
+// Non-2xx responses are returned as errors including the start of the body.
+func DoJSON(ctx context.Context, client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckStatus(resp); err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// CheckStatus returns an error for non-2xx responses
+func CheckStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, MaxErrorBody))
+	return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDoJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "boom", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"value":"ok"}`))
+	}))
+	defer server.Close()
+
+	var out struct {
+		Value string `json:"value"`
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err := DoJSON(context.Background(), NewHTTPClient(), req, &out); err != nil || out.Value != "ok" {
+		t.Errorf("Expected decoded body, got %+v err=%v", out, err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/fail", nil)
+	err := DoJSON(context.Background(), NewHTTPClient(), req, &out)
+	if err == nil || !strings.Contains(err.Error(), "502") || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected status error with body, got %v", err)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	if err := DoJSON(context.Background(), NewHTTPClient(), req, nil); err != nil {
+		t.Errorf("Expected nil out to be allowed, got %v", err)
+	}
+}
+
+func TestClientCredentials_Token(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = r.ParseForm()
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_secret") != "secret" {
+			t.Errorf("Unexpected token request: %v", r.PostForm)
+		}
+		if r.PostForm.Get("scope") != "api/.default" {
+			t.Errorf("Expected scope, got %q", r.PostForm.Get("scope"))
+		}
+		_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	cc := NewClientCredentials(server.URL, "id", "secret", "api/.default")
+	for i := 0; i < 3; i++ {
+		token, err := cc.Token(context.Background())
+		if err != nil || token != "tok" {
+			t.Fatalf("Expected token, got %q err=%v", token, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected token to be cached, got %d requests", requests)
+	}
+
+	cc.Invalidate()
+	_, _ = cc.Token(context.Background())
+	if requests != 2 {
+		t.Errorf("Expected refresh after Invalidate, got %d requests", requests)
+	}
+}
+
+func TestClientCredentials_EmptyToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cc := NewClientCredentials(server.URL, "id", "secret", "")
+	if _, err := cc.Token(context.Background()); err == nil {
+		t.Error("Expected error for empty token")
+	}
+}
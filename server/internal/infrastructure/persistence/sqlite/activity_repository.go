@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"autostrike/internal/domain/entity"
+)
+
+// ActivityRepository implements repository.ActivityRepository using SQLite
+type ActivityRepository struct {
+	db *sql.DB
+}
+
+// NewActivityRepository creates a new SQLite activity repository
+func NewActivityRepository(db *sql.DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// CreateLogin records a successful login
+func (r *ActivityRepository) CreateLogin(ctx context.Context, record *entity.LoginRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_history (id, user_id, ip_address, country, user_agent, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, record.ID, record.UserID, record.IPAddress, record.Country, record.UserAgent, record.CreatedAt)
+
+	return err
+}
+
+// FindLoginsByUser returns the most recent logins of a user, newest first
+func (r *ActivityRepository) FindLoginsByUser(ctx context.Context, userID string, limit int) ([]*entity.LoginRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, ip_address, country, user_agent, created_at
+		FROM login_history WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []*entity.LoginRecord
+	for rows.Next() {
+		record := &entity.LoginRecord{}
+		var country, userAgent sql.NullString
+
+		if err := rows.Scan(&record.ID, &record.UserID, &record.IPAddress, &country, &userAgent, &record.CreatedAt); err != nil {
+			return nil, err
+		}
+		record.Country = country.String
+		record.UserAgent = userAgent.String
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// CreateAnomaly stores a detected activity anomaly
+func (r *ActivityRepository) CreateAnomaly(ctx context.Context, anomaly *entity.ActivityAnomaly) error {
+	dataJSON, err := json.Marshal(anomaly.Data)
+	if err != nil {
+		dataJSON = []byte("{}")
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO activity_anomalies (id, type, severity, user_id, username, message, data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, anomaly.ID, anomaly.Type, anomaly.Severity, anomaly.UserID, anomaly.Username,
+		anomaly.Message, string(dataJSON), anomaly.CreatedAt)
+
+	return err
+}
+
+// FindAnomalies returns the most recent anomalies, newest first
+func (r *ActivityRepository) FindAnomalies(ctx context.Context, limit int) ([]*entity.ActivityAnomaly, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, type, severity, user_id, username, message, data, created_at
+		FROM activity_anomalies
+		ORDER BY created_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var anomalies []*entity.ActivityAnomaly
+	for rows.Next() {
+		anomaly := &entity.ActivityAnomaly{}
+		var userID, username, dataJSON sql.NullString
+
+		err := rows.Scan(&anomaly.ID, &anomaly.Type, &anomaly.Severity, &userID, &username,
+			&anomaly.Message, &dataJSON, &anomaly.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		anomaly.UserID = userID.String
+		anomaly.Username = username.String
+
+		if dataJSON.Valid && dataJSON.String != "" {
+			_ = json.Unmarshal([]byte(dataJSON.String), &anomaly.Data)
+		}
+
+		anomalies = append(anomalies, anomaly)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return anomalies, nil
+}
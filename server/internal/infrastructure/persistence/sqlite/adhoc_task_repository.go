@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+// adhocTaskColumns are the columns of an ad-hoc task
+const adhocTaskColumns = "id, agent_paw, command, executor, timeout, status, output, exit_code, requested_by, created_at, completed_at"
+
+// AdHocTaskRepository implements repository.AdHocTaskRepository using SQLite
+type AdHocTaskRepository struct {
+	db *sql.DB
+}
+
+// NewAdHocTaskRepository creates a new SQLite ad-hoc task repository
+func NewAdHocTaskRepository(db *sql.DB) *AdHocTaskRepository {
+	return &AdHocTaskRepository{db: db}
+}
+
+// Create inserts an ad-hoc task
+func (r *AdHocTaskRepository) Create(ctx context.Context, task *entity.AdHocTask) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO adhoc_tasks (id, agent_paw, command, executor, timeout, status, output, exit_code, requested_by, created_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, task.ID, task.AgentPaw, task.Command, task.Executor, task.Timeout, task.Status, task.Output,
+		task.ExitCode, task.RequestedBy, task.CreatedAt, task.CompletedAt)
+
+	return err
+}
+
+// Update records the outcome of an ad-hoc task
+func (r *AdHocTaskRepository) Update(ctx context.Context, task *entity.AdHocTask) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE adhoc_tasks SET status = ?, output = ?, exit_code = ?, completed_at = ?
+		WHERE id = ?
+	`, task.Status, task.Output, task.ExitCode, task.CompletedAt, task.ID)
+
+	return err
+}
+
+// FindByID finds an ad-hoc task by ID
+func (r *AdHocTaskRepository) FindByID(ctx context.Context, id string) (*entity.AdHocTask, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+adhocTaskColumns+" FROM adhoc_tasks WHERE id = ?", id)
+	return scanAdHocTask(row)
+}
+
+// FindByAgent returns the latest ad-hoc tasks of an agent, newest first
+func (r *AdHocTaskRepository) FindByAgent(ctx context.Context, paw string, limit int) ([]*entity.AdHocTask, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+adhocTaskColumns+
+		" FROM adhoc_tasks WHERE agent_paw = ? ORDER BY created_at DESC, rowid DESC LIMIT ?", paw, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*entity.AdHocTask
+	for rows.Next() {
+		task, err := scanAdHocTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
+
+// scanAdHocTask scans an ad-hoc task row
+func scanAdHocTask(row interface{ Scan(dest ...any) error }) (*entity.AdHocTask, error) {
+	task := &entity.AdHocTask{}
+	var output sql.NullString
+	var completedAt sql.NullTime
+
+	err := row.Scan(&task.ID, &task.AgentPaw, &task.Command, &task.Executor, &task.Timeout, &task.Status,
+		&output, &task.ExitCode, &task.RequestedBy, &task.CreatedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Output = output.String
+	if completedAt.Valid {
+		task.CompletedAt = &completedAt.Time
+	}
+	return task, nil
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// AgentAvailabilityRepository implements repository.AgentAvailabilityRepository using SQLite
+type AgentAvailabilityRepository struct {
+	db *sql.DB
+}
+
+// NewAgentAvailabilityRepository creates a new SQLite agent availability repository
+func NewAgentAvailabilityRepository(db *sql.DB) *AgentAvailabilityRepository {
+	return &AgentAvailabilityRepository{db: db}
+}
+
+// Create records an agent going online or offline
+func (r *AgentAvailabilityRepository) Create(ctx context.Context, event *entity.AgentAvailabilityEvent) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO agent_availability (id, agent_paw, status, reason, suppressed, occurred_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, event.ID, event.AgentPaw, event.Status, event.Reason, event.Suppressed, event.OccurredAt)
+
+	return err
+}
+
+// FindByAgent returns the events of an agent since a time, oldest first
+func (r *AgentAvailabilityRepository) FindByAgent(ctx context.Context, paw string, since time.Time) ([]*entity.AgentAvailabilityEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, agent_paw, status, reason, suppressed, occurred_at
+		FROM agent_availability WHERE agent_paw = ? AND occurred_at >= ?
+		ORDER BY occurred_at
+	`, paw, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.AgentAvailabilityEvent
+	for rows.Next() {
+		event := &entity.AgentAvailabilityEvent{}
+		if err := rows.Scan(&event.ID, &event.AgentPaw, &event.Status, &event.Reason, &event.Suppressed, &event.OccurredAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// DeleteBefore deletes the events older than before, returning how many were deleted
+func (r *AgentAvailabilityRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM agent_availability WHERE occurred_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// AgentBeaconRepository implements repository.AgentBeaconRepository using SQLite
+type AgentBeaconRepository struct {
+	db *sql.DB
+}
+
+// NewAgentBeaconRepository creates a new SQLite agent beacon repository
+func NewAgentBeaconRepository(db *sql.DB) *AgentBeaconRepository {
+	return &AgentBeaconRepository{db: db}
+}
+
+// Create records a check-in of an agent
+func (r *AgentBeaconRepository) Create(ctx context.Context, beacon *entity.AgentBeacon) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO agent_beacons (agent_paw, received_at, interval_seconds, jitter, gap_ms, latency_ms, missed)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, beacon.AgentPaw, beacon.ReceivedAt, beacon.Interval, beacon.Jitter,
+		nullableMillis(beacon.Gap), nullableMillis(beacon.Latency), beacon.Missed)
+
+	return err
+}
+
+// FindByAgent returns the check-ins of an agent since a time, oldest first
+func (r *AgentBeaconRepository) FindByAgent(ctx context.Context, paw string, since time.Time) ([]*entity.AgentBeacon, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT agent_paw, received_at, interval_seconds, jitter, gap_ms, latency_ms, missed
+		FROM agent_beacons WHERE agent_paw = ? AND received_at >= ?
+		ORDER BY received_at
+	`, paw, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var beacons []*entity.AgentBeacon
+	for rows.Next() {
+		beacon := &entity.AgentBeacon{}
+		var gap, latency sql.NullInt64
+		if err := rows.Scan(&beacon.AgentPaw, &beacon.ReceivedAt, &beacon.Interval, &beacon.Jitter, &gap, &latency, &beacon.Missed); err != nil {
+			return nil, err
+		}
+		beacon.Gap = millisDuration(gap)
+		beacon.Latency = millisDuration(latency)
+		beacons = append(beacons, beacon)
+	}
+
+	return beacons, rows.Err()
+}
+
+// DeleteBefore deletes the check-ins older than before, returning how many were deleted
+func (r *AgentBeaconRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM agent_beacons WHERE received_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// nullableMillis stores an optional duration as milliseconds
+func nullableMillis(d *time.Duration) sql.NullInt64 {
+	if d == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: d.Milliseconds(), Valid: true}
+}
+
+// millisDuration reads an optional duration stored as milliseconds
+func millisDuration(ms sql.NullInt64) *time.Duration {
+	if !ms.Valid {
+		return nil
+	}
+	d := time.Duration(ms.Int64) * time.Millisecond
+	return &d
+}
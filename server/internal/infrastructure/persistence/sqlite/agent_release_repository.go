@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+// agentReleaseColumns are the metadata columns of an agent release, without its binary
+const agentReleaseColumns = "id, version, platform, sha256, signature, size, rollout_percent, uploaded_by, created_at"
+
+// AgentReleaseRepository implements repository.AgentReleaseRepository using SQLite
+type AgentReleaseRepository struct {
+	db *sql.DB
+}
+
+// NewAgentReleaseRepository creates a new SQLite agent release repository
+func NewAgentReleaseRepository(db *sql.DB) *AgentReleaseRepository {
+	return &AgentReleaseRepository{db: db}
+}
+
+// Create inserts a release with its binary
+func (r *AgentReleaseRepository) Create(ctx context.Context, release *entity.AgentRelease, content []byte) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO agent_releases (id, version, platform, sha256, signature, size, content, rollout_percent, uploaded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, release.ID, release.Version, release.Platform, release.SHA256, release.Signature, release.Size, content,
+		release.RolloutPercent, release.UploadedBy, release.CreatedAt)
+
+	return err
+}
+
+// UpdateRollout sets the share of agents offered a release
+func (r *AgentReleaseRepository) UpdateRollout(ctx context.Context, id string, percent int) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE agent_releases SET rollout_percent = ? WHERE id = ?", percent, id)
+	return err
+}
+
+// Delete removes a release and its binary
+func (r *AgentReleaseRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM agent_releases WHERE id = ?", id)
+	return err
+}
+
+// FindByID finds a release by ID
+func (r *AgentReleaseRepository) FindByID(ctx context.Context, id string) (*entity.AgentRelease, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+agentReleaseColumns+" FROM agent_releases WHERE id = ?", id)
+	return scanAgentRelease(row)
+}
+
+// FindByVersion finds the release of a version for a platform
+func (r *AgentReleaseRepository) FindByVersion(ctx context.Context, version, platform string) (*entity.AgentRelease, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT "+agentReleaseColumns+" FROM agent_releases WHERE version = ? AND platform = ?", version, platform)
+	return scanAgentRelease(row)
+}
+
+// FindAll returns all releases, newest upload first
+func (r *AgentReleaseRepository) FindAll(ctx context.Context) ([]*entity.AgentRelease, error) {
+	return r.query(ctx, "SELECT "+agentReleaseColumns+" FROM agent_releases ORDER BY created_at DESC")
+}
+
+// FindByPlatform returns the releases of a platform, newest upload first
+func (r *AgentReleaseRepository) FindByPlatform(ctx context.Context, platform string) ([]*entity.AgentRelease, error) {
+	return r.query(ctx,
+		"SELECT "+agentReleaseColumns+" FROM agent_releases WHERE platform = ? ORDER BY created_at DESC", platform)
+}
+
+// Content loads the binary of a release
+func (r *AgentReleaseRepository) Content(ctx context.Context, id string) ([]byte, error) {
+	var content []byte
+	err := r.db.QueryRowContext(ctx, "SELECT content FROM agent_releases WHERE id = ?", id).Scan(&content)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func (r *AgentReleaseRepository) query(ctx context.Context, query string, args ...any) ([]*entity.AgentRelease, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var releases []*entity.AgentRelease
+	for rows.Next() {
+		release, err := scanAgentRelease(rows)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+
+	return releases, rows.Err()
+}
+
+// scanAgentRelease scans an agent release metadata row
+func scanAgentRelease(row interface{ Scan(dest ...any) error }) (*entity.AgentRelease, error) {
+	release := &entity.AgentRelease{}
+	var uploadedBy sql.NullString
+
+	err := row.Scan(&release.ID, &release.Version, &release.Platform, &release.SHA256, &release.Signature,
+		&release.Size, &release.RolloutPercent, &uploadedBy, &release.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	release.UploadedBy = uploadedBy.String
+	return release, nil
+}
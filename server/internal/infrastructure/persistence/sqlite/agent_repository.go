@@ -11,14 +11,23 @@ import (
 	"autostrike/internal/domain/entity"
 )
 
+const agentColumns = `paw, hostname, username, platform, executors, status, last_seen, created_at, version,
+	os_version, architecture, elevated, domain, security_products, interpreters, ip_address, allowed_networks, metadata`
+
 // AgentRepository implements repository.AgentRepository using SQLite
 type AgentRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts *statementCache // Lookups run on each check-in and dispatch
 }
 
 // NewAgentRepository creates a new SQLite agent repository
 func NewAgentRepository(db *sql.DB) *AgentRepository {
-	return &AgentRepository{db: db}
+	return &AgentRepository{db: db, stmts: newStatementCache(db)}
+}
+
+// Close closes the statements the repository prepared, before the database is closed
+func (r *AgentRepository) Close() error {
+	return r.stmts.Close()
 }
 
 // Create creates a new agent
@@ -27,11 +36,24 @@ func (r *AgentRepository) Create(ctx context.Context, agent *entity.Agent) error
 	if err != nil {
 		return fmt.Errorf("failed to marshal executors: %w", err)
 	}
+	securityProducts, interpreters, err := marshalAgentInventory(agent)
+	if err != nil {
+		return err
+	}
+	allowedNetworks, err := json.Marshal(agent.AllowedNetworks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed networks: %w", err)
+	}
+	metadata, err := json.Marshal(agent.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
 
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO agents (paw, hostname, username, platform, executors, status, last_seen, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, agent.Paw, agent.Hostname, agent.Username, agent.Platform, executors, agent.Status, agent.LastSeen, agent.CreatedAt)
+		INSERT INTO agents (`+agentColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, agent.Paw, agent.Hostname, agent.Username, agent.Platform, executors, agent.Status, agent.LastSeen, agent.CreatedAt, agent.Version,
+		agent.OSVersion, agent.Architecture, agent.Elevated, agent.Domain, securityProducts, interpreters, agent.IPAddress, allowedNetworks, metadata)
 
 	return err
 }
@@ -42,11 +64,27 @@ func (r *AgentRepository) Update(ctx context.Context, agent *entity.Agent) error
 	if err != nil {
 		return fmt.Errorf("failed to marshal executors: %w", err)
 	}
+	securityProducts, interpreters, err := marshalAgentInventory(agent)
+	if err != nil {
+		return err
+	}
+	allowedNetworks, err := json.Marshal(agent.AllowedNetworks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal allowed networks: %w", err)
+	}
+	metadata, err := json.Marshal(agent.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
 
 	_, err = r.db.ExecContext(ctx, `
-		UPDATE agents SET hostname = ?, username = ?, platform = ?, executors = ?, status = ?, last_seen = ?
+		UPDATE agents SET hostname = ?, username = ?, platform = ?, executors = ?, status = ?, last_seen = ?, version = ?,
+			os_version = ?, architecture = ?, elevated = ?, domain = ?, security_products = ?, interpreters = ?,
+			ip_address = ?, allowed_networks = ?, metadata = ?
 		WHERE paw = ?
-	`, agent.Hostname, agent.Username, agent.Platform, executors, agent.Status, agent.LastSeen, agent.Paw)
+	`, agent.Hostname, agent.Username, agent.Platform, executors, agent.Status, agent.LastSeen, agent.Version,
+		agent.OSVersion, agent.Architecture, agent.Elevated, agent.Domain, securityProducts, interpreters,
+		agent.IPAddress, allowedNetworks, metadata, agent.Paw)
 
 	return err
 }
@@ -59,22 +97,8 @@ func (r *AgentRepository) Delete(ctx context.Context, paw string) error {
 
 // FindByPaw finds an agent by paw
 func (r *AgentRepository) FindByPaw(ctx context.Context, paw string) (*entity.Agent, error) {
-	agent := &entity.Agent{}
-	var executors string
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT paw, hostname, username, platform, executors, status, last_seen, created_at
-		FROM agents WHERE paw = ?
-	`, paw).Scan(&agent.Paw, &agent.Hostname, &agent.Username, &agent.Platform, &executors, &agent.Status, &agent.LastSeen, &agent.CreatedAt)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if json.Unmarshal([]byte(executors), &agent.Executors) != nil {
-		agent.Executors = []string{} // Default to empty on parse error
-	}
-	return agent, nil
+	row := r.stmts.QueryRowContext(ctx, "SELECT "+agentColumns+" FROM agents WHERE paw = ?", paw)
+	return scanAgent(row)
 }
 
 // FindByPaws finds multiple agents by their paws in a single query (batch operation)
@@ -96,7 +120,7 @@ func (r *AgentRepository) FindByPaws(ctx context.Context, paws []string) ([]*ent
 
 	// NOSONAR: This is safe - we're only joining "?" placeholders, not user data.
 	// The actual values are passed via args... as prepared statement parameters.
-	query := "SELECT paw, hostname, username, platform, executors, status, last_seen, created_at FROM agents WHERE paw IN (" + strings.Join(placeholders, ",") + ")"
+	query := "SELECT " + agentColumns + " FROM agents WHERE paw IN (" + strings.Join(placeholders, ",") + ")"
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -109,10 +133,7 @@ func (r *AgentRepository) FindByPaws(ctx context.Context, paws []string) ([]*ent
 
 // FindAll finds all agents
 func (r *AgentRepository) FindAll(ctx context.Context) ([]*entity.Agent, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT paw, hostname, username, platform, executors, status, last_seen, created_at
-		FROM agents ORDER BY last_seen DESC
-	`)
+	rows, err := r.stmts.QueryContext(ctx, "SELECT "+agentColumns+" FROM agents ORDER BY last_seen DESC")
 	if err != nil {
 		return nil, err
 	}
@@ -123,10 +144,7 @@ func (r *AgentRepository) FindAll(ctx context.Context) ([]*entity.Agent, error)
 
 // FindByStatus finds agents by status
 func (r *AgentRepository) FindByStatus(ctx context.Context, status entity.AgentStatus) ([]*entity.Agent, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT paw, hostname, username, platform, executors, status, last_seen, created_at
-		FROM agents WHERE status = ? ORDER BY last_seen DESC
-	`, status)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+agentColumns+" FROM agents WHERE status = ? ORDER BY last_seen DESC", status)
 	if err != nil {
 		return nil, err
 	}
@@ -137,10 +155,7 @@ func (r *AgentRepository) FindByStatus(ctx context.Context, status entity.AgentS
 
 // FindByPlatform finds agents by platform
 func (r *AgentRepository) FindByPlatform(ctx context.Context, platform string) ([]*entity.Agent, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT paw, hostname, username, platform, executors, status, last_seen, created_at
-		FROM agents WHERE platform = ? ORDER BY last_seen DESC
-	`, platform)
+	rows, err := r.db.QueryContext(ctx, "SELECT "+agentColumns+" FROM agents WHERE platform = ? ORDER BY last_seen DESC", platform)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +166,7 @@ func (r *AgentRepository) FindByPlatform(ctx context.Context, platform string) (
 
 // UpdateLastSeen updates the last seen timestamp
 func (r *AgentRepository) UpdateLastSeen(ctx context.Context, paw string) error {
-	_, err := r.db.ExecContext(ctx, `
+	_, err := r.stmts.ExecContext(ctx, `
 		UPDATE agents SET last_seen = ?, status = ? WHERE paw = ?
 	`, time.Now(), entity.AgentOnline, paw)
 	return err
@@ -161,17 +176,10 @@ func (r *AgentRepository) scanAgents(rows *sql.Rows) ([]*entity.Agent, error) {
 	var agents []*entity.Agent
 
 	for rows.Next() {
-		agent := &entity.Agent{}
-		var executors string
-
-		err := rows.Scan(&agent.Paw, &agent.Hostname, &agent.Username, &agent.Platform, &executors, &agent.Status, &agent.LastSeen, &agent.CreatedAt)
+		agent, err := scanAgent(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if json.Unmarshal([]byte(executors), &agent.Executors) != nil {
-			agent.Executors = []string{} // Default to empty on parse error
-		}
 		agents = append(agents, agent)
 	}
 
@@ -181,3 +189,49 @@ func (r *AgentRepository) scanAgents(rows *sql.Rows) ([]*entity.Agent, error) {
 
 	return agents, nil
 }
+
+// marshalAgentInventory encodes the list and map fields of the agent inventory
+func marshalAgentInventory(agent *entity.Agent) (securityProducts, interpreters []byte, err error) {
+	if securityProducts, err = json.Marshal(agent.SecurityProducts); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal security products: %w", err)
+	}
+	if interpreters, err = json.Marshal(agent.Interpreters); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal interpreters: %w", err)
+	}
+	return securityProducts, interpreters, nil
+}
+
+// scanAgent scans an agent row
+func scanAgent(row interface{ Scan(dest ...any) error }) (*entity.Agent, error) {
+	agent := &entity.Agent{}
+	var executors string
+	var version, osVersion, architecture, domain, securityProducts, interpreters, ipAddress, allowedNetworks, metadata sql.NullString
+
+	err := row.Scan(&agent.Paw, &agent.Hostname, &agent.Username, &agent.Platform, &executors, &agent.Status, &agent.LastSeen, &agent.CreatedAt, &version,
+		&osVersion, &architecture, &agent.Elevated, &domain, &securityProducts, &interpreters, &ipAddress, &allowedNetworks, &metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	if json.Unmarshal([]byte(executors), &agent.Executors) != nil {
+		agent.Executors = []string{} // Default to empty on parse error
+	}
+	agent.Version = version.String
+	agent.OSVersion = osVersion.String
+	agent.Architecture = architecture.String
+	agent.Domain = domain.String
+	if securityProducts.Valid {
+		_ = json.Unmarshal([]byte(securityProducts.String), &agent.SecurityProducts)
+	}
+	if interpreters.Valid {
+		_ = json.Unmarshal([]byte(interpreters.String), &agent.Interpreters)
+	}
+	agent.IPAddress = ipAddress.String
+	if allowedNetworks.Valid {
+		_ = json.Unmarshal([]byte(allowedNetworks.String), &agent.AllowedNetworks)
+	}
+	if metadata.Valid {
+		_ = json.Unmarshal([]byte(metadata.String), &agent.Metadata)
+	}
+	return agent, nil
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"autostrike/internal/domain/entity"
+)
+
+// AgentSelectorRepository implements repository.AgentSelectorRepository using SQLite
+type AgentSelectorRepository struct {
+	db *sql.DB
+}
+
+// NewAgentSelectorRepository creates a new SQLite agent selector repository
+func NewAgentSelectorRepository(db *sql.DB) *AgentSelectorRepository {
+	return &AgentSelectorRepository{db: db}
+}
+
+// selectorCriteria is the JSON document storing the matching criteria of a selector
+type selectorCriteria struct {
+	Platforms        []string             `json:"platforms,omitempty"`
+	Statuses         []entity.AgentStatus `json:"statuses,omitempty"`
+	Executors        []string             `json:"executors,omitempty"`
+	Tags             map[string]string    `json:"tags,omitempty"`
+	HostnamePattern  string               `json:"hostname_pattern,omitempty"`
+	Architectures    []string             `json:"architectures,omitempty"`
+	Elevated         *bool                `json:"elevated,omitempty"`
+	Domains          []string             `json:"domains,omitempty"`
+	SecurityProducts []string             `json:"security_products,omitempty"`
+	Interpreters     map[string]string    `json:"interpreters,omitempty"`
+}
+
+// Create inserts a new agent selector
+func (r *AgentSelectorRepository) Create(ctx context.Context, selector *entity.AgentSelector) error {
+	criteria, err := marshalSelectorCriteria(selector)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO agent_selectors (id, name, description, criteria, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, selector.ID, selector.Name, selector.Description, criteria,
+		selector.CreatedBy, selector.CreatedAt, selector.UpdatedAt)
+
+	return err
+}
+
+// Update updates an existing agent selector
+func (r *AgentSelectorRepository) Update(ctx context.Context, selector *entity.AgentSelector) error {
+	criteria, err := marshalSelectorCriteria(selector)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE agent_selectors SET name = ?, description = ?, criteria = ?, updated_at = ?
+		WHERE id = ?
+	`, selector.Name, selector.Description, criteria, selector.UpdatedAt, selector.ID)
+
+	return err
+}
+
+// Delete removes an agent selector
+func (r *AgentSelectorRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM agent_selectors WHERE id = ?", id)
+	return err
+}
+
+// FindByID finds an agent selector by ID
+func (r *AgentSelectorRepository) FindByID(ctx context.Context, id string) (*entity.AgentSelector, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, name, description, criteria, created_by, created_at, updated_at
+		FROM agent_selectors WHERE id = ?
+	`, id)
+
+	return scanAgentSelector(row)
+}
+
+// FindAll returns all agent selectors ordered by name
+func (r *AgentSelectorRepository) FindAll(ctx context.Context) ([]*entity.AgentSelector, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, description, criteria, created_by, created_at, updated_at
+		FROM agent_selectors ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var selectors []*entity.AgentSelector
+	for rows.Next() {
+		selector, err := scanAgentSelector(rows)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+
+	return selectors, rows.Err()
+}
+
+// marshalSelectorCriteria encodes the matching criteria of a selector
+func marshalSelectorCriteria(selector *entity.AgentSelector) (string, error) {
+	data, err := json.Marshal(selectorCriteria{
+		Platforms:        selector.Platforms,
+		Statuses:         selector.Statuses,
+		Executors:        selector.Executors,
+		Tags:             selector.Tags,
+		HostnamePattern:  selector.HostnamePattern,
+		Architectures:    selector.Architectures,
+		Elevated:         selector.Elevated,
+		Domains:          selector.Domains,
+		SecurityProducts: selector.SecurityProducts,
+		Interpreters:     selector.Interpreters,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// scanAgentSelector scans an agent selector row
+func scanAgentSelector(row interface{ Scan(dest ...any) error }) (*entity.AgentSelector, error) {
+	selector := &entity.AgentSelector{}
+	var description, criteriaJSON, createdBy sql.NullString
+
+	err := row.Scan(&selector.ID, &selector.Name, &description, &criteriaJSON,
+		&createdBy, &selector.CreatedAt, &selector.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	selector.Description = description.String
+	selector.CreatedBy = createdBy.String
+	if criteriaJSON.Valid && criteriaJSON.String != "" {
+		var criteria selectorCriteria
+		if err := json.Unmarshal([]byte(criteriaJSON.String), &criteria); err != nil {
+			return nil, err
+		}
+		selector.Platforms = criteria.Platforms
+		selector.Statuses = criteria.Statuses
+		selector.Executors = criteria.Executors
+		selector.Tags = criteria.Tags
+		selector.HostnamePattern = criteria.HostnamePattern
+		selector.Architectures = criteria.Architectures
+		selector.Elevated = criteria.Elevated
+		selector.Domains = criteria.Domains
+		selector.SecurityProducts = criteria.SecurityProducts
+		selector.Interpreters = criteria.Interpreters
+	}
+
+	return selector, nil
+}
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// apiKeyColumns are the columns of an API key
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, created_at, expires_at, last_used_at"
+
+// APIKeyRepository implements repository.APIKeyRepository using SQLite
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new SQLite API key repository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// Create inserts an API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, created_at, expires_at, last_used_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, key.CreatedAt, key.ExpiresAt, key.LastUsedAt)
+
+	return err
+}
+
+// FindByID finds an API key by ID
+func (r *APIKeyRepository) FindByID(ctx context.Context, id string) (*entity.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE id = ?", id)
+	return scanAPIKey(row)
+}
+
+// FindByHash finds an API key by the hash of its secret
+func (r *APIKeyRepository) FindByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?", hash)
+	return scanAPIKey(row)
+}
+
+// FindByUser returns the API keys of a user, newest first
+func (r *APIKeyRepository) FindByUser(ctx context.Context, userID string) ([]*entity.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+apiKeyColumns+
+		" FROM api_keys WHERE user_id = ? ORDER BY created_at DESC, rowid DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*entity.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Delete deletes an API key
+func (r *APIKeyRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM api_keys WHERE id = ?", id)
+	return err
+}
+
+// Touch records the last use of an API key
+func (r *APIKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = ? WHERE id = ?", usedAt, id)
+	return err
+}
+
+// scanAPIKey scans an API key row
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*entity.APIKey, error) {
+	key := &entity.APIKey{}
+	var expiresAt, lastUsedAt sql.NullTime
+
+	err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.CreatedAt, &expiresAt, &lastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	return key, nil
+}
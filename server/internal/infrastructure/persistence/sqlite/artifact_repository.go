@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// artifactColumns are the metadata columns of an artifact, without its content
+const artifactColumns = "id, result_id, execution_id, agent_paw, name, path, content_type, sha256, size, storage_key, created_at"
+
+// ArtifactRepository implements repository.ArtifactRepository using SQLite
+type ArtifactRepository struct {
+	db *sql.DB
+}
+
+// NewArtifactRepository creates a new SQLite artifact repository
+func NewArtifactRepository(db *sql.DB) *ArtifactRepository {
+	return &ArtifactRepository{db: db}
+}
+
+// Create inserts an artifact with its content. The content of an artifact
+// kept in object storage (StorageKey set) is nil and stored empty.
+func (r *ArtifactRepository) Create(ctx context.Context, artifact *entity.Artifact, content []byte) error {
+	if content == nil {
+		content = []byte{}
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO result_artifacts (id, result_id, execution_id, agent_paw, name, path, content_type, sha256, size, content, storage_key, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, artifact.ID, artifact.ResultID, artifact.ExecutionID, artifact.AgentPaw, artifact.Name, artifact.Path,
+		artifact.ContentType, artifact.SHA256, artifact.Size, content, artifact.StorageKey, artifact.CreatedAt)
+
+	return err
+}
+
+// FindByID finds an artifact by ID
+func (r *ArtifactRepository) FindByID(ctx context.Context, id string) (*entity.Artifact, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+artifactColumns+" FROM result_artifacts WHERE id = ?", id)
+	return scanArtifact(row)
+}
+
+// FindByResult returns the artifacts of a result, oldest first
+func (r *ArtifactRepository) FindByResult(ctx context.Context, resultID string) ([]*entity.Artifact, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+artifactColumns+
+		" FROM result_artifacts WHERE result_id = ? ORDER BY created_at ASC, rowid ASC", resultID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []*entity.Artifact
+	for rows.Next() {
+		artifact, err := scanArtifact(rows)
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	return artifacts, rows.Err()
+}
+
+// Content loads the content of an artifact
+func (r *ArtifactRepository) Content(ctx context.Context, id string) ([]byte, error) {
+	var content []byte
+	err := r.db.QueryRowContext(ctx, "SELECT content FROM result_artifacts WHERE id = ?", id).Scan(&content)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// TotalSizeByResult returns the bytes stored for a result
+func (r *ArtifactRepository) TotalSizeByResult(ctx context.Context, resultID string) (int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(size), 0) FROM result_artifacts WHERE result_id = ?", resultID).Scan(&total)
+	return total, err
+}
+
+// StorageKeysCreatedBefore returns the object storage keys of the artifacts
+// stored before a time
+func (r *ArtifactRepository) StorageKeysCreatedBefore(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT storage_key FROM result_artifacts WHERE created_at < ? AND storage_key IS NOT NULL AND storage_key != ''", before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// DeleteCreatedBefore deletes the artifacts stored before a time and returns how many were deleted
+func (r *ArtifactRepository) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM result_artifacts WHERE created_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// scanArtifact scans an artifact metadata row
+func scanArtifact(row interface{ Scan(dest ...any) error }) (*entity.Artifact, error) {
+	artifact := &entity.Artifact{}
+	var path, contentType, storageKey sql.NullString
+
+	err := row.Scan(&artifact.ID, &artifact.ResultID, &artifact.ExecutionID, &artifact.AgentPaw, &artifact.Name,
+		&path, &contentType, &artifact.SHA256, &artifact.Size, &storageKey, &artifact.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact.Path = path.String
+	artifact.ContentType = contentType.String
+	artifact.StorageKey = storageKey.String
+	return artifact, nil
+}
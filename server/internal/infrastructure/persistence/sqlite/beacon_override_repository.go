@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+const beaconOverrideColumns = "scope, target, interval_seconds, jitter_percent, updated_by, updated_at"
+
+// BeaconOverrideRepository implements repository.BeaconOverrideRepository using SQLite
+type BeaconOverrideRepository struct {
+	db *sql.DB
+}
+
+// NewBeaconOverrideRepository creates a new SQLite beacon override repository
+func NewBeaconOverrideRepository(db *sql.DB) *BeaconOverrideRepository {
+	return &BeaconOverrideRepository{db: db}
+}
+
+// Upsert creates or replaces the override of a scope and target
+func (r *BeaconOverrideRepository) Upsert(ctx context.Context, override *entity.BeaconOverride) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO beacon_overrides (`+beaconOverrideColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (scope, target) DO UPDATE SET
+			interval_seconds = excluded.interval_seconds,
+			jitter_percent = excluded.jitter_percent,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, override.Scope, override.Target, override.Interval, override.Jitter, override.UpdatedBy, override.UpdatedAt)
+
+	return err
+}
+
+// Delete removes the override of a scope and target
+func (r *BeaconOverrideRepository) Delete(ctx context.Context, scope entity.BeaconScope, target string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM beacon_overrides WHERE scope = ? AND target = ?", scope, target)
+	return err
+}
+
+// Find finds the override of a scope and target
+func (r *BeaconOverrideRepository) Find(ctx context.Context, scope entity.BeaconScope, target string) (*entity.BeaconOverride, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+beaconOverrideColumns+" FROM beacon_overrides WHERE scope = ? AND target = ?", scope, target)
+	return scanBeaconOverride(row)
+}
+
+// FindAll returns all overrides ordered by scope and target
+func (r *BeaconOverrideRepository) FindAll(ctx context.Context) ([]*entity.BeaconOverride, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+beaconOverrideColumns+" FROM beacon_overrides ORDER BY scope, target")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*entity.BeaconOverride
+	for rows.Next() {
+		override, err := scanBeaconOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// scanBeaconOverride scans a beacon override row
+func scanBeaconOverride(row interface{ Scan(dest ...any) error }) (*entity.BeaconOverride, error) {
+	override := &entity.BeaconOverride{}
+	var updatedBy sql.NullString
+
+	err := row.Scan(&override.Scope, &override.Target, &override.Interval, &override.Jitter, &updatedBy, &override.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	override.UpdatedBy = updatedBy.String
+	return override, nil
+}
@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of the database configuration
+const (
+	DefaultMaxOpenConns = 10
+	DefaultMaxIdleConns = 10
+	DefaultBusyTimeout  = 5 * time.Second
+	DefaultJournalMode  = "WAL"
+	DefaultSynchronous  = "NORMAL"
+)
+
+// Config configures the connection pool and the pragmas set on each connection
+type Config struct {
+	Path            string
+	MaxOpenConns    int           // Connections open at once; readers run concurrently under WAL
+	MaxIdleConns    int           // Connections kept open, with their prepared statements
+	ConnMaxLifetime time.Duration // 0 keeps connections open
+	BusyTimeout     time.Duration // How long a statement waits for a lock before failing with SQLITE_BUSY
+	JournalMode     string        // WAL lets readers run while a writer commits
+	Synchronous     string        // NORMAL is safe with WAL and saves an fsync per commit
+}
+
+// DefaultConfig returns the configuration of the database at path
+func DefaultConfig(path string) Config {
+	return Config{
+		Path:         path,
+		MaxOpenConns: DefaultMaxOpenConns,
+		MaxIdleConns: DefaultMaxIdleConns,
+		BusyTimeout:  DefaultBusyTimeout,
+		JournalMode:  DefaultJournalMode,
+		Synchronous:  DefaultSynchronous,
+	}
+}
+
+// Open opens the database with the pool and pragmas of config. The pragmas
+// are set in the DSN so that every connection of the pool gets them, foreign
+// keys included.
+func Open(config Config) (*sql.DB, error) {
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	if config.BusyTimeout > 0 {
+		params.Set("_busy_timeout", fmt.Sprint(config.BusyTimeout.Milliseconds()))
+	}
+	if config.JournalMode != "" {
+		params.Set("_journal_mode", config.JournalMode)
+	}
+	if config.Synchronous != "" {
+		params.Set("_synchronous", config.Synchronous)
+	}
+	separator := "?"
+	if strings.Contains(config.Path, "?") {
+		separator = "&"
+	}
+
+	db, err := sql.Open("sqlite3", config.Path+separator+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	return db, nil
+}
+
+// statementCache prepares the queries of a repository on first use and
+// reuses them afterwards, sparing SQLite the parsing and planning of each
+// call. database/sql prepares a statement again on the connections it was not
+// prepared on yet.
+type statementCache struct {
+	db    *sql.DB
+	mu    sync.RWMutex
+	stmts map[string]*sql.Stmt
+}
+
+func newStatementCache(db *sql.DB) *statementCache {
+	return &statementCache{db: db, stmts: make(map[string]*sql.Stmt)}
+}
+
+// prepare returns the prepared statement of query, preparing it once
+func (c *statementCache) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	if stmt := c.cached(query); stmt != nil {
+		return stmt, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stmt := c.stmts[query]; stmt != nil {
+		return stmt, nil
+	}
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.stmts[query] = stmt
+	return stmt, nil
+}
+
+// ExecContext runs a prepared statement that returns no rows
+func (c *statementCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.ExecContext(ctx, args...)
+}
+
+// QueryContext runs a prepared query
+func (c *statementCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.QueryContext(ctx, args...)
+}
+
+// QueryRowContext runs a prepared query returning at most one row. A query
+// that cannot be prepared is run directly, its row reporting the error.
+func (c *statementCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return c.db.QueryRowContext(ctx, query, args...)
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+// Tx runs the statements within tx, reusing those already prepared. The
+// others are run directly: preparing them would take another connection
+// while tx holds one.
+func (c *statementCache) Tx(tx *sql.Tx) execQuerier {
+	return &txStatements{tx: tx, cache: c}
+}
+
+// Close closes the prepared statements; the cache prepares them again if
+// used afterwards
+func (c *statementCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for query, stmt := range c.stmts {
+		errs = append(errs, stmt.Close())
+		delete(c.stmts, query)
+	}
+	return errors.Join(errs...)
+}
+
+// cached returns the prepared statement of query, nil when not prepared yet
+func (c *statementCache) cached(query string) *sql.Stmt {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stmts[query]
+}
+
+// txStatements runs the statements of a cache within a transaction
+type txStatements struct {
+	tx    *sql.Tx
+	cache *statementCache
+}
+
+func (t *txStatements) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if stmt := t.cache.cached(query); stmt != nil {
+		return t.tx.StmtContext(ctx, stmt).ExecContext(ctx, args...)
+	}
+	return t.tx.ExecContext(ctx, query, args...)
+}
+
+func (t *txStatements) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if stmt := t.cache.cached(query); stmt != nil {
+		return t.tx.StmtContext(ctx, stmt).QueryRowContext(ctx, args...)
+	}
+	return t.tx.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,237 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// openTestFileDB opens a file database with the default configuration and its schema
+func openTestFileDB(tb testing.TB) *sql.DB {
+	tb.Helper()
+	db, err := Open(DefaultConfig(filepath.Join(tb.TempDir(), "autostrike.db")))
+	if err != nil {
+		tb.Fatalf("Open failed: %v", err)
+	}
+	tb.Cleanup(func() { _ = db.Close() })
+	if err := InitSchema(db); err != nil {
+		tb.Fatalf("Failed to init schema: %v", err)
+	}
+	return db
+}
+
+func TestOpen(t *testing.T) {
+	db := openTestFileDB(t)
+	ctx := context.Background()
+
+	// Every connection of the pool gets the pragmas, not only the first
+	conns := make([]*sql.Conn, 2)
+	for i := range conns {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Conn failed: %v", err)
+		}
+		defer conn.Close()
+		conns[i] = conn
+	}
+	for i, conn := range conns {
+		var journalMode string
+		var busyTimeout, foreignKeys, synchronous int
+		if err := conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode); err != nil {
+			t.Fatalf("journal_mode failed: %v", err)
+		}
+		_ = conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout)
+		_ = conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys)
+		_ = conn.QueryRowContext(ctx, "PRAGMA synchronous").Scan(&synchronous)
+		if journalMode != "wal" || busyTimeout != 5000 || foreignKeys != 1 || synchronous != 1 {
+			t.Errorf("Connection %d: journal_mode=%s busy_timeout=%d foreign_keys=%d synchronous=%d",
+				i, journalMode, busyTimeout, foreignKeys, synchronous)
+		}
+	}
+
+	if stats := db.Stats(); stats.MaxOpenConnections != DefaultMaxOpenConns {
+		t.Errorf("Expected %d connections at most, got %d", DefaultMaxOpenConns, stats.MaxOpenConnections)
+	}
+}
+
+func TestStatementCache(t *testing.T) {
+	db := setupTestDBWithFKData(t)
+	ctx := context.Background()
+	cache := newStatementCache(db)
+
+	query := "SELECT COUNT(*) FROM agents WHERE paw = ?"
+	for i := 0; i < 2; i++ {
+		var n int
+		if err := cache.QueryRowContext(ctx, query, testAgentPaw).Scan(&n); err != nil || n != 1 {
+			t.Fatalf("Query failed: %d, %v", n, err)
+		}
+	}
+	if len(cache.stmts) != 1 {
+		t.Errorf("Expected the query prepared once, got %d statements", len(cache.stmts))
+	}
+
+	// A query that cannot be prepared reports its error
+	if err := cache.QueryRowContext(ctx, "SELECT * FROM missing").Scan(new(int)); err == nil {
+		t.Error("Expected an error for an unknown table")
+	}
+	if _, err := cache.ExecContext(ctx, "DELETE FROM missing"); err == nil {
+		t.Error("Expected an error for an unknown table")
+	}
+
+	// Statements run within a transaction too, prepared or not
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var n int
+	if err := cache.Tx(tx).QueryRowContext(ctx, query, testAgentPaw).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("Prepared query within the transaction failed: %d, %v", n, err)
+	}
+	if _, err := cache.Tx(tx).ExecContext(ctx, "UPDATE agents SET hostname = ? WHERE paw = ?", "renamed", testAgentPaw); err != nil {
+		t.Fatalf("Statement within the transaction failed: %v", err)
+	}
+}
+
+func TestAgentRepository_Close(t *testing.T) {
+	db := openTestFileDB(t)
+	repo := NewAgentRepository(db)
+	if _, err := repo.FindAll(context.Background()); err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(repo.stmts.stmts) == 0 {
+		t.Fatal("Expected the query prepared")
+	}
+	prepared := make([]*sql.Stmt, 0, len(repo.stmts.stmts))
+	for _, stmt := range repo.stmts.stmts {
+		prepared = append(prepared, stmt)
+	}
+
+	// The statements are closed with the repository
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if len(repo.stmts.stmts) != 0 {
+		t.Errorf("Expected the statement cache emptied, got %d statements", len(repo.stmts.stmts))
+	}
+	for _, stmt := range prepared {
+		if _, err := stmt.Exec(); err == nil || !strings.Contains(err.Error(), "statement is closed") {
+			t.Errorf("Expected the statement closed, got %v", err)
+		}
+	}
+
+	// A repository used after Close prepares its statements again
+	if _, err := repo.FindAll(context.Background()); err != nil {
+		t.Errorf("FindAll after Close failed: %v", err)
+	}
+	_ = repo.Close()
+}
+
+// The benchmarks compare the queries of the hot paths run ad hoc, parsed and
+// planned at each call, with their prepared statements, and the writes with
+// the driver defaults with those under WAL:
+//
+//	go test -run '^$' -bench . ./internal/infrastructure/persistence/sqlite/
+
+func BenchmarkAgentRepository_FindAll(b *testing.B) {
+	db := openTestFileDB(b)
+	for i := 0; i < 50; i++ {
+		createTestAgent(b, db, fmt.Sprintf("agent-%d", i))
+	}
+	repo := NewAgentRepository(db)
+	ctx := context.Background()
+
+	b.Run("ad-hoc", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rows, err := db.QueryContext(ctx, "SELECT "+agentColumns+" FROM agents ORDER BY last_seen DESC")
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := repo.scanAgents(rows); err != nil {
+				b.Fatal(err)
+			}
+			rows.Close()
+		}
+	})
+	b.Run("prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.FindAll(ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("prepared-parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := repo.FindAll(ctx); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+}
+
+func BenchmarkResultRepository_CreateResult(b *testing.B) {
+	ctx := context.Background()
+	attempt := 0
+	newResult := func() *entity.ExecutionResult {
+		attempt++
+		return &entity.ExecutionResult{
+			ID:          fmt.Sprintf("result-%d", attempt),
+			ExecutionID: testExecID,
+			TechniqueID: testTechID,
+			AgentPaw:    testAgentPaw,
+			Attempt:     attempt,
+			Status:      entity.StatusPending,
+			StartedAt:   time.Now(),
+		}
+	}
+	seed := func(db *sql.DB) {
+		createTestScenario(b, db, testScenarioID)
+		createTestTechnique(b, db, testTechID)
+		createTestAgent(b, db, testAgentPaw)
+		createTestExecution(b, db, testExecID, testScenarioID)
+	}
+	adHoc := func(db *sql.DB) func(b *testing.B) {
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				r := newResult()
+				if _, err := db.ExecContext(ctx, createResultQuery, r.ID, r.ExecutionID, r.TechniqueID, r.AgentPaw, r.Attempt,
+					r.Phase, r.Executor, r.Status, r.Output, r.StartedAt, r.CompletedAt); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+
+	// The driver defaults: rollback journal, a sync at each commit
+	defaults, err := sql.Open("sqlite3", filepath.Join(b.TempDir(), "autostrike.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer defaults.Close()
+	if err := InitSchema(defaults); err != nil {
+		b.Fatal(err)
+	}
+	seed(defaults)
+	b.Run("driver-defaults/ad-hoc", adHoc(defaults))
+
+	db := openTestFileDB(b)
+	seed(db)
+	repo := NewResultRepository(db)
+	b.Run("wal/ad-hoc", adHoc(db))
+	b.Run("wal/prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := repo.CreateResult(ctx, newResult()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"autostrike/internal/domain/entity"
+)
+
+const emergencyStopColumns = `id, reason, automatic, tripped_by, tripped_at, cancelled_executions,
+	paused_schedules, agents_notified, rearmed_by, rearmed_at`
+
+// EmergencyStopRepository implements repository.EmergencyStopRepository using SQLite
+type EmergencyStopRepository struct {
+	db *sql.DB
+}
+
+// NewEmergencyStopRepository creates a new SQLite emergency stop repository
+func NewEmergencyStopRepository(db *sql.DB) *EmergencyStopRepository {
+	return &EmergencyStopRepository{db: db}
+}
+
+// Save inserts a trip, or replaces it once it was enforced or re-armed
+func (r *EmergencyStopRepository) Save(ctx context.Context, stop *entity.EmergencyStop) error {
+	cancelled, err := json.Marshal(nonNilStrings(stop.CancelledExecutions))
+	if err != nil {
+		return err
+	}
+	paused, err := json.Marshal(nonNilStrings(stop.PausedSchedules))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO emergency_stops (`+emergencyStopColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, stop.ID, stop.Reason, stop.Automatic, stop.TrippedBy, stop.TrippedAt, string(cancelled),
+		string(paused), stop.AgentsNotified, stop.RearmedBy, stop.RearmedAt)
+
+	return err
+}
+
+// FindEngaged returns the latest trip not re-armed yet, or nil
+func (r *EmergencyStopRepository) FindEngaged(ctx context.Context) (*entity.EmergencyStop, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+emergencyStopColumns+` FROM emergency_stops
+		WHERE rearmed_at IS NULL ORDER BY tripped_at DESC LIMIT 1
+	`)
+	stop, err := scanEmergencyStop(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return stop, err
+}
+
+// FindRecent returns the latest trips, newest first
+func (r *EmergencyStopRepository) FindRecent(ctx context.Context, limit int) ([]*entity.EmergencyStop, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+emergencyStopColumns+` FROM emergency_stops
+		ORDER BY tripped_at DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stops []*entity.EmergencyStop
+	for rows.Next() {
+		stop, err := scanEmergencyStop(rows)
+		if err != nil {
+			return nil, err
+		}
+		stops = append(stops, stop)
+	}
+
+	return stops, rows.Err()
+}
+
+func scanEmergencyStop(row interface{ Scan(dest ...any) error }) (*entity.EmergencyStop, error) {
+	stop := &entity.EmergencyStop{}
+	var trippedBy, rearmedBy sql.NullString
+	var cancelled, paused string
+	var rearmedAt sql.NullTime
+	if err := row.Scan(&stop.ID, &stop.Reason, &stop.Automatic, &trippedBy, &stop.TrippedAt, &cancelled,
+		&paused, &stop.AgentsNotified, &rearmedBy, &rearmedAt); err != nil {
+		return nil, err
+	}
+	stop.TrippedBy = trippedBy.String
+	stop.RearmedBy = rearmedBy.String
+	if rearmedAt.Valid {
+		stop.RearmedAt = &rearmedAt.Time
+	}
+	if err := json.Unmarshal([]byte(cancelled), &stop.CancelledExecutions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(paused), &stop.PausedSchedules); err != nil {
+		return nil, err
+	}
+	return stop, nil
+}
+
+// nonNilStrings stores an empty list as [] rather than null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+// evidenceColumns are the metadata columns of evidence, without its content
+const evidenceColumns = "id, result_id, execution_id, name, description, content_type, sha256, size, storage_key, uploaded_by, created_at"
+
+// EvidenceRepository implements repository.EvidenceRepository using SQLite
+type EvidenceRepository struct {
+	db *sql.DB
+}
+
+// NewEvidenceRepository creates a new SQLite evidence repository
+func NewEvidenceRepository(db *sql.DB) *EvidenceRepository {
+	return &EvidenceRepository{db: db}
+}
+
+// Create inserts evidence with its content. The content of evidence kept in
+// object storage (StorageKey set) is nil and stored empty.
+func (r *EvidenceRepository) Create(ctx context.Context, evidence *entity.Evidence, content []byte) error {
+	if content == nil {
+		content = []byte{}
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO result_evidence (id, result_id, execution_id, name, description, content_type, sha256, size, content, storage_key, uploaded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, evidence.ID, evidence.ResultID, evidence.ExecutionID, evidence.Name, evidence.Description, evidence.ContentType,
+		evidence.SHA256, evidence.Size, content, evidence.StorageKey, evidence.UploadedBy, evidence.CreatedAt)
+
+	return err
+}
+
+// FindByID finds evidence by ID
+func (r *EvidenceRepository) FindByID(ctx context.Context, id string) (*entity.Evidence, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+evidenceColumns+" FROM result_evidence WHERE id = ?", id)
+	return scanEvidence(row)
+}
+
+// FindByResult returns the evidence of a result, oldest first
+func (r *EvidenceRepository) FindByResult(ctx context.Context, resultID string) ([]*entity.Evidence, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+evidenceColumns+
+		" FROM result_evidence WHERE result_id = ? ORDER BY created_at ASC, rowid ASC", resultID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*entity.Evidence
+	for rows.Next() {
+		evidence, err := scanEvidence(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, evidence)
+	}
+
+	return files, rows.Err()
+}
+
+// Content loads the content of evidence
+func (r *EvidenceRepository) Content(ctx context.Context, id string) ([]byte, error) {
+	var content []byte
+	err := r.db.QueryRowContext(ctx, "SELECT content FROM result_evidence WHERE id = ?", id).Scan(&content)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// TotalSizeByResult returns the bytes of evidence stored for a result
+func (r *EvidenceRepository) TotalSizeByResult(ctx context.Context, resultID string) (int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(size), 0) FROM result_evidence WHERE result_id = ?", resultID).Scan(&total)
+	return total, err
+}
+
+// scanEvidence scans an evidence metadata row
+func scanEvidence(row interface{ Scan(dest ...any) error }) (*entity.Evidence, error) {
+	evidence := &entity.Evidence{}
+	var description, storageKey, uploadedBy sql.NullString
+
+	err := row.Scan(&evidence.ID, &evidence.ResultID, &evidence.ExecutionID, &evidence.Name, &description,
+		&evidence.ContentType, &evidence.SHA256, &evidence.Size, &storageKey, &uploadedBy, &evidence.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence.Description = description.String
+	evidence.StorageKey = storageKey.String
+	evidence.UploadedBy = uploadedBy.String
+	return evidence, nil
+}
@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"autostrike/internal/domain/entity"
+)
+
+// executionReviewColumns are the columns of a review, without its snapshot
+const executionReviewColumns = "execution_id, status, reviewer_id, requested_by, requested_at, started_at, signed_off_by, signed_off_at, notes"
+
+// ExecutionReviewRepository implements repository.ExecutionReviewRepository using SQLite
+type ExecutionReviewRepository struct {
+	db *sql.DB
+}
+
+// NewExecutionReviewRepository creates a new SQLite execution review repository
+func NewExecutionReviewRepository(db *sql.DB) *ExecutionReviewRepository {
+	return &ExecutionReviewRepository{db: db}
+}
+
+// Get returns the review of an execution with its snapshot, or nil when the
+// execution was never submitted for review
+func (r *ExecutionReviewRepository) Get(ctx context.Context, executionID string) (*entity.ExecutionReview, error) {
+	var snapshot sql.NullString
+	row := r.db.QueryRowContext(ctx, "SELECT "+executionReviewColumns+", snapshot FROM execution_reviews WHERE execution_id = ?", executionID)
+	review, err := scanExecutionReview(row, &snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Valid && snapshot.String != "" {
+		review.Snapshot = []byte(snapshot.String)
+	}
+	return review, nil
+}
+
+// Save inserts or replaces the review of an execution
+func (r *ExecutionReviewRepository) Save(ctx context.Context, review *entity.ExecutionReview) error {
+	var snapshot interface{}
+	if len(review.Snapshot) > 0 {
+		snapshot = string(review.Snapshot)
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO execution_reviews (execution_id, status, reviewer_id, requested_by, requested_at,
+			started_at, signed_off_by, signed_off_at, notes, snapshot)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, review.ExecutionID, string(review.Status), review.ReviewerID, review.RequestedBy, review.RequestedAt,
+		review.StartedAt, review.SignedOffBy, review.SignedOffAt, review.Notes, snapshot)
+
+	return err
+}
+
+// FindAll returns up to limit reviews, newest request first, filtered by
+// status and reviewer when set
+func (r *ExecutionReviewRepository) FindAll(ctx context.Context, status entity.ReviewStatus, reviewerID string, limit int) ([]*entity.ExecutionReview, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+executionReviewColumns+` FROM execution_reviews
+		WHERE (? = '' OR status = ?) AND (? = '' OR reviewer_id = ?)
+		ORDER BY requested_at DESC LIMIT ?`, string(status), string(status), reviewerID, reviewerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*entity.ExecutionReview
+	for rows.Next() {
+		review, err := scanExecutionReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// scanExecutionReview scans a review row, followed by the extra columns given
+func scanExecutionReview(row interface{ Scan(dest ...any) error }, extra ...any) (*entity.ExecutionReview, error) {
+	review := &entity.ExecutionReview{}
+	var reviewerID, requestedBy, signedOffBy, notes sql.NullString
+	var startedAt, signedOffAt sql.NullTime
+
+	dest := []any{&review.ExecutionID, &review.Status, &reviewerID, &requestedBy, &review.RequestedAt,
+		&startedAt, &signedOffBy, &signedOffAt, &notes}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	review.ReviewerID = reviewerID.String
+	review.RequestedBy = requestedBy.String
+	review.SignedOffBy = signedOffBy.String
+	review.Notes = notes.String
+	if startedAt.Valid {
+		review.StartedAt = &startedAt.Time
+	}
+	if signedOffAt.Valid {
+		review.SignedOffAt = &signedOffAt.Time
+	}
+	return review, nil
+}
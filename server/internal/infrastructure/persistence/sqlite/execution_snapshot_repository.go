@@ -0,0 +1,52 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"autostrike/internal/domain/entity"
+)
+
+// ExecutionSnapshotRepository implements repository.ExecutionSnapshotRepository using SQLite
+type ExecutionSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewExecutionSnapshotRepository creates a new SQLite execution snapshot repository
+func NewExecutionSnapshotRepository(db *sql.DB) *ExecutionSnapshotRepository {
+	return &ExecutionSnapshotRepository{db: db}
+}
+
+// Save inserts or replaces the snapshot of an execution
+func (r *ExecutionSnapshotRepository) Save(ctx context.Context, snapshot *entity.ExecutionSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO execution_snapshots (execution_id, captured_at, snapshot)
+		VALUES (?, ?, ?)
+	`, snapshot.ExecutionID, snapshot.CapturedAt, string(data))
+
+	return err
+}
+
+// Get returns the snapshot of an execution, or nil when it has none
+func (r *ExecutionSnapshotRepository) Get(ctx context.Context, executionID string) (*entity.ExecutionSnapshot, error) {
+	var data string
+	err := r.db.QueryRowContext(ctx, "SELECT snapshot FROM execution_snapshots WHERE execution_id = ?", executionID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &entity.ExecutionSnapshot{}
+	if err := json.Unmarshal([]byte(data), snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
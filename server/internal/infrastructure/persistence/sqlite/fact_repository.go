@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+// FactRepository implements repository.FactRepository using SQLite
+type FactRepository struct {
+	db *sql.DB
+}
+
+// NewFactRepository creates a new SQLite fact repository
+func NewFactRepository(db *sql.DB) *FactRepository {
+	return &FactRepository{db: db}
+}
+
+// Create records a fact
+func (r *FactRepository) Create(ctx context.Context, fact *entity.Fact) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO execution_facts (id, execution_id, agent_paw, result_id, technique_id, name, value, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, fact.ID, fact.ExecutionID, fact.AgentPaw, fact.ResultID, fact.TechniqueID, fact.Name, fact.Value, fact.CreatedAt)
+
+	return err
+}
+
+// FindByExecution returns the facts of an execution, oldest first
+func (r *FactRepository) FindByExecution(ctx context.Context, executionID string) ([]*entity.Fact, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, execution_id, agent_paw, result_id, technique_id, name, value, created_at
+		FROM execution_facts WHERE execution_id = ?
+		ORDER BY created_at ASC, rowid ASC
+	`, executionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facts []*entity.Fact
+	for rows.Next() {
+		fact := &entity.Fact{}
+		err := rows.Scan(&fact.ID, &fact.ExecutionID, &fact.AgentPaw, &fact.ResultID, &fact.TechniqueID,
+			&fact.Name, &fact.Value, &fact.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		facts = append(facts, fact)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return facts, nil
+}
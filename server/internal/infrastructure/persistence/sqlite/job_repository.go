@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// jobColumns are the columns of a background job
+const jobColumns = "id, type, status, progress, total, message, result, error, created_by, created_at, started_at, completed_at"
+
+// JobRepository implements repository.JobRepository using SQLite
+type JobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a new SQLite job repository
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Create inserts a job
+func (r *JobRepository) Create(ctx context.Context, job *entity.Job) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO jobs (id, type, status, progress, total, message, result, error, created_by, created_at, started_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, job.ID, job.Type, job.Status, job.Progress, job.Total, job.Message, nullableJSON(job.Result),
+		job.Error, job.CreatedBy, job.CreatedAt, job.StartedAt, job.CompletedAt)
+
+	return err
+}
+
+// Update records the status, progress and outcome of a job
+func (r *JobRepository) Update(ctx context.Context, job *entity.Job) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET status = ?, progress = ?, total = ?, message = ?, result = ?, error = ?, started_at = ?, completed_at = ?
+		WHERE id = ?
+	`, job.Status, job.Progress, job.Total, job.Message, nullableJSON(job.Result), job.Error,
+		job.StartedAt, job.CompletedAt, job.ID)
+
+	return err
+}
+
+// FindByID finds a job by ID
+func (r *JobRepository) FindByID(ctx context.Context, id string) (*entity.Job, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = ?", id)
+	return scanJob(row)
+}
+
+// FindRecent returns the latest jobs, of a type and creator when they are set
+func (r *JobRepository) FindRecent(ctx context.Context, jobType entity.JobType, createdBy string, limit int) ([]*entity.Job, error) {
+	var conditions []string
+	var args []any
+	if jobType != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, jobType)
+	}
+	if createdBy != "" {
+		conditions = append(conditions, "created_by = ?")
+		args = append(args, createdBy)
+	}
+	query := "SELECT " + jobColumns + " FROM jobs"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, rowid DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*entity.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// FailUnfinished fails the pending and running jobs with reason and returns how many there were
+func (r *JobRepository) FailUnfinished(ctx context.Context, reason string, at time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET status = ?, error = ?, completed_at = ?
+		WHERE status IN (?, ?)
+	`, entity.JobFailed, reason, at, entity.JobPending, entity.JobRunning)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// nullableJSON stores an empty JSON document as NULL
+func nullableJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+// scanJob scans a job row
+func scanJob(row interface{ Scan(dest ...any) error }) (*entity.Job, error) {
+	job := &entity.Job{}
+	var message, result, jobErr, createdBy sql.NullString
+	var startedAt, completedAt sql.NullTime
+
+	err := row.Scan(&job.ID, &job.Type, &job.Status, &job.Progress, &job.Total, &message, &result,
+		&jobErr, &createdBy, &job.CreatedAt, &startedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Message = message.String
+	job.Error = jobErr.String
+	job.CreatedBy = createdBy.String
+	if result.Valid {
+		job.Result = []byte(result.String)
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	return job, nil
+}
@@ -4,10 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"autostrike/internal/domain/entity"
 )
 
+const settingsColumns = `id, user_id, enabled, email_address, webhook_url, teams_webhook_url, webhook_secret,
+	pagerduty_routing_key, opsgenie_api_key, preferences, score_alert_threshold, quiet_hours, digest,
+	created_at, updated_at`
+
+const notificationColumns = `id, user_id, type, title, message, data, read, sent_at, created_at, digest_pending`
+
 // NotificationRepository implements repository.NotificationRepository using SQLite
 type NotificationRepository struct {
 	db *sql.DB
@@ -20,17 +27,23 @@ func NewNotificationRepository(db *sql.DB) *NotificationRepository {
 
 // CreateSettings creates new notification settings
 func (r *NotificationRepository) CreateSettings(ctx context.Context, settings *entity.NotificationSettings) error {
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO notification_settings (
-			id, user_id, channel, enabled, email_address, webhook_url,
-			notify_on_start, notify_on_complete, notify_on_failure,
-			notify_on_score_alert, score_alert_threshold, notify_on_agent_offline,
-			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, settings.ID, settings.UserID, settings.Channel, settings.Enabled,
-		settings.EmailAddress, settings.WebhookURL,
-		settings.NotifyOnStart, settings.NotifyOnComplete, settings.NotifyOnFailure,
-		settings.NotifyOnScoreAlert, settings.ScoreAlertThreshold, settings.NotifyOnAgentOffline,
+	preferencesJSON, err := marshalPreferences(settings.Preferences)
+	if err != nil {
+		return err
+	}
+
+	quietHoursJSON, err := marshalQuietHours(settings.QuietHours)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO notification_settings (`+settingsColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, settings.ID, settings.UserID, settings.Enabled,
+		settings.EmailAddress, settings.WebhookURL, settings.TeamsWebhookURL, settings.WebhookSecret,
+		settings.PagerDutyRoutingKey, settings.OpsgenieAPIKey,
+		preferencesJSON, settings.ScoreAlertThreshold, quietHoursJSON, string(settings.Digest),
 		settings.CreatedAt, settings.UpdatedAt)
 
 	return err
@@ -38,16 +51,26 @@ func (r *NotificationRepository) CreateSettings(ctx context.Context, settings *e
 
 // UpdateSettings updates notification settings
 func (r *NotificationRepository) UpdateSettings(ctx context.Context, settings *entity.NotificationSettings) error {
-	_, err := r.db.ExecContext(ctx, `
+	preferencesJSON, err := marshalPreferences(settings.Preferences)
+	if err != nil {
+		return err
+	}
+
+	quietHoursJSON, err := marshalQuietHours(settings.QuietHours)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
 		UPDATE notification_settings SET
-			channel = ?, enabled = ?, email_address = ?, webhook_url = ?,
-			notify_on_start = ?, notify_on_complete = ?, notify_on_failure = ?,
-			notify_on_score_alert = ?, score_alert_threshold = ?, notify_on_agent_offline = ?,
+			enabled = ?, email_address = ?, webhook_url = ?, teams_webhook_url = ?, webhook_secret = ?,
+			pagerduty_routing_key = ?, opsgenie_api_key = ?,
+			preferences = ?, score_alert_threshold = ?, quiet_hours = ?, digest = ?,
 			updated_at = ?
 		WHERE id = ?
-	`, settings.Channel, settings.Enabled, settings.EmailAddress, settings.WebhookURL,
-		settings.NotifyOnStart, settings.NotifyOnComplete, settings.NotifyOnFailure,
-		settings.NotifyOnScoreAlert, settings.ScoreAlertThreshold, settings.NotifyOnAgentOffline,
+	`, settings.Enabled, settings.EmailAddress, settings.WebhookURL, settings.TeamsWebhookURL, settings.WebhookSecret,
+		settings.PagerDutyRoutingKey, settings.OpsgenieAPIKey,
+		preferencesJSON, settings.ScoreAlertThreshold, quietHoursJSON, string(settings.Digest),
 		settings.UpdatedAt, settings.ID)
 
 	return err
@@ -55,44 +78,18 @@ func (r *NotificationRepository) UpdateSettings(ctx context.Context, settings *e
 
 // FindSettingsByUserID finds notification settings by user ID
 func (r *NotificationRepository) FindSettingsByUserID(ctx context.Context, userID string) (*entity.NotificationSettings, error) {
-	settings := &entity.NotificationSettings{}
-	var emailAddress, webhookURL sql.NullString
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, user_id, channel, enabled, email_address, webhook_url,
-			notify_on_start, notify_on_complete, notify_on_failure,
-			notify_on_score_alert, score_alert_threshold, notify_on_agent_offline,
-			created_at, updated_at
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+settingsColumns+`
 		FROM notification_settings WHERE user_id = ?
-	`, userID).Scan(
-		&settings.ID, &settings.UserID, &settings.Channel, &settings.Enabled,
-		&emailAddress, &webhookURL,
-		&settings.NotifyOnStart, &settings.NotifyOnComplete, &settings.NotifyOnFailure,
-		&settings.NotifyOnScoreAlert, &settings.ScoreAlertThreshold, &settings.NotifyOnAgentOffline,
-		&settings.CreatedAt, &settings.UpdatedAt,
-	)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if emailAddress.Valid {
-		settings.EmailAddress = emailAddress.String
-	}
-	if webhookURL.Valid {
-		settings.WebhookURL = webhookURL.String
-	}
+	`, userID)
 
-	return settings, nil
+	return scanSettings(row)
 }
 
 // FindAllEnabledSettings finds all enabled notification settings
 func (r *NotificationRepository) FindAllEnabledSettings(ctx context.Context) ([]*entity.NotificationSettings, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, user_id, channel, enabled, email_address, webhook_url,
-			notify_on_start, notify_on_complete, notify_on_failure,
-			notify_on_score_alert, score_alert_threshold, notify_on_agent_offline,
-			created_at, updated_at
+		SELECT `+settingsColumns+`
 		FROM notification_settings WHERE enabled = 1
 	`)
 	if err != nil {
@@ -102,31 +99,83 @@ func (r *NotificationRepository) FindAllEnabledSettings(ctx context.Context) ([]
 
 	var settingsList []*entity.NotificationSettings
 	for rows.Next() {
-		settings := &entity.NotificationSettings{}
-		var emailAddress, webhookURL sql.NullString
-
-		err := rows.Scan(
-			&settings.ID, &settings.UserID, &settings.Channel, &settings.Enabled,
-			&emailAddress, &webhookURL,
-			&settings.NotifyOnStart, &settings.NotifyOnComplete, &settings.NotifyOnFailure,
-			&settings.NotifyOnScoreAlert, &settings.ScoreAlertThreshold, &settings.NotifyOnAgentOffline,
-			&settings.CreatedAt, &settings.UpdatedAt,
-		)
+		settings, err := scanSettings(rows)
 		if err != nil {
 			return nil, err
 		}
+		settingsList = append(settingsList, settings)
+	}
 
-		if emailAddress.Valid {
-			settings.EmailAddress = emailAddress.String
+	return settingsList, rows.Err()
+}
+
+// marshalPreferences encodes the preference matrix, storing nil as an empty object
+func marshalPreferences(prefs entity.NotificationPreferences) (string, error) {
+	if prefs == nil {
+		prefs = entity.NotificationPreferences{}
+	}
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// marshalQuietHours encodes quiet hours, storing nil as NULL
+func marshalQuietHours(quietHours *entity.QuietHours) (any, error) {
+	if quietHours == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(quietHours)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// scanSettings scans a notification settings row
+func scanSettings(row interface{ Scan(dest ...any) error }) (*entity.NotificationSettings, error) {
+	settings := &entity.NotificationSettings{}
+	var emailAddress, webhookURL, teamsWebhookURL, webhookSecret, pagerDutyRoutingKey, opsgenieAPIKey, preferencesJSON sql.NullString
+	var quietHoursJSON, digest sql.NullString
+
+	err := row.Scan(
+		&settings.ID, &settings.UserID, &settings.Enabled,
+		&emailAddress, &webhookURL, &teamsWebhookURL, &webhookSecret,
+		&pagerDutyRoutingKey, &opsgenieAPIKey, &preferencesJSON, &settings.ScoreAlertThreshold,
+		&quietHoursJSON, &digest, &settings.CreatedAt, &settings.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailAddress.Valid {
+		settings.EmailAddress = emailAddress.String
+	}
+	if webhookURL.Valid {
+		settings.WebhookURL = webhookURL.String
+	}
+	if teamsWebhookURL.Valid {
+		settings.TeamsWebhookURL = teamsWebhookURL.String
+	}
+	settings.WebhookSecret = webhookSecret.String
+	settings.PagerDutyRoutingKey = pagerDutyRoutingKey.String
+	settings.OpsgenieAPIKey = opsgenieAPIKey.String
+	settings.Preferences = entity.NotificationPreferences{}
+	if preferencesJSON.Valid && preferencesJSON.String != "" {
+		if err := json.Unmarshal([]byte(preferencesJSON.String), &settings.Preferences); err != nil {
+			return nil, err
 		}
-		if webhookURL.Valid {
-			settings.WebhookURL = webhookURL.String
+	}
+	if quietHoursJSON.Valid && quietHoursJSON.String != "" {
+		settings.QuietHours = &entity.QuietHours{}
+		if err := json.Unmarshal([]byte(quietHoursJSON.String), settings.QuietHours); err != nil {
+			return nil, err
 		}
-
-		settingsList = append(settingsList, settings)
 	}
+	settings.Digest = entity.NotificationDigest(digest.String)
 
-	return settingsList, rows.Err()
+	return settings, nil
 }
 
 // DeleteSettings deletes notification settings
@@ -143,10 +192,11 @@ func (r *NotificationRepository) CreateNotification(ctx context.Context, notific
 	}
 
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO notifications (id, user_id, type, title, message, data, read, sent_at, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO notifications (`+notificationColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, notification.ID, notification.UserID, notification.Type, notification.Title,
-		notification.Message, string(dataJSON), notification.Read, notification.SentAt, notification.CreatedAt)
+		notification.Message, string(dataJSON), notification.Read, notification.SentAt, notification.CreatedAt,
+		notification.DigestPending)
 
 	return err
 }
@@ -158,12 +208,12 @@ func (r *NotificationRepository) FindNotificationByID(ctx context.Context, id st
 	var sentAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id, user_id, type, title, message, data, read, sent_at, created_at
+		SELECT `+notificationColumns+`
 		FROM notifications WHERE id = ?
 	`, id).Scan(
 		&notification.ID, &notification.UserID, &notification.Type,
 		&notification.Title, &notification.Message, &dataJSON,
-		&notification.Read, &sentAt, &notification.CreatedAt,
+		&notification.Read, &sentAt, &notification.CreatedAt, &notification.DigestPending,
 	)
 	if err != nil {
 		return nil, err
@@ -182,7 +232,7 @@ func (r *NotificationRepository) FindNotificationByID(ctx context.Context, id st
 // FindNotificationsByUserID finds notifications by user ID
 func (r *NotificationRepository) FindNotificationsByUserID(ctx context.Context, userID string, limit int) ([]*entity.Notification, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, user_id, type, title, message, data, read, sent_at, created_at
+		SELECT `+notificationColumns+`
 		FROM notifications WHERE user_id = ?
 		ORDER BY created_at DESC
 		LIMIT ?
@@ -198,7 +248,7 @@ func (r *NotificationRepository) FindNotificationsByUserID(ctx context.Context,
 // FindUnreadByUserID finds unread notifications by user ID
 func (r *NotificationRepository) FindUnreadByUserID(ctx context.Context, userID string) ([]*entity.Notification, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, user_id, type, title, message, data, read, sent_at, created_at
+		SELECT `+notificationColumns+`
 		FROM notifications WHERE user_id = ? AND read = 0
 		ORDER BY created_at DESC
 	`, userID)
@@ -222,6 +272,80 @@ func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID strin
 	return err
 }
 
+// DeleteNotifications deletes the given notifications of a user, ignoring
+// those of other users. Returns the number deleted.
+func (r *NotificationRepository) DeleteNotifications(ctx context.Context, userID string, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	placeholders, args := inClause(ids)
+	// NOSONAR: only "?" placeholders are joined, the IDs are query parameters
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM notifications WHERE user_id = ? AND id IN (`+placeholders+`)
+	`, append([]any{userID}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DeleteReadNotifications deletes the read notifications of a user
+func (r *NotificationRepository) DeleteReadNotifications(ctx context.Context, userID string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notifications WHERE user_id = ? AND read = 1`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeReadNotifications deletes the read notifications created before
+// createdBefore. Notifications still held for a digest are kept.
+func (r *NotificationRepository) PurgeReadNotifications(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM notifications
+		WHERE read = 1 AND digest_pending = 0 AND created_at < ?
+	`, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// MarkDigestPending holds the email of a notification for the next digest
+func (r *NotificationRepository) MarkDigestPending(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE notifications SET digest_pending = 1 WHERE id = ?`, id)
+	return err
+}
+
+// FindDigestPending finds the notifications of a user held for the next digest, oldest first
+func (r *NotificationRepository) FindDigestPending(ctx context.Context, userID string) ([]*entity.Notification, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+notificationColumns+`
+		FROM notifications WHERE user_id = ? AND digest_pending = 1
+		ORDER BY created_at ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanNotifications(rows)
+}
+
+// MarkDigestSent records that the held notifications were emailed in a digest
+func (r *NotificationRepository) MarkDigestSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders, args := inClause(ids)
+	// NOSONAR: only "?" placeholders are joined, the IDs are query parameters
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET digest_pending = 0, sent_at = ?
+		WHERE id IN (`+placeholders+`)
+	`, append([]any{sentAt}, args...)...)
+	return err
+}
+
 func (r *NotificationRepository) scanNotifications(rows *sql.Rows) ([]*entity.Notification, error) {
 	var notifications []*entity.Notification
 
@@ -233,7 +357,7 @@ func (r *NotificationRepository) scanNotifications(rows *sql.Rows) ([]*entity.No
 		err := rows.Scan(
 			&notification.ID, &notification.UserID, &notification.Type,
 			&notification.Title, &notification.Message, &dataJSON,
-			&notification.Read, &sentAt, &notification.CreatedAt,
+			&notification.Read, &sentAt, &notification.CreatedAt, &notification.DigestPending,
 		)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// passwordResetColumns are the columns of a password reset token
+const passwordResetColumns = "id, user_id, token_hash, request_ip, created_at, expires_at, used_at"
+
+// PasswordResetRepository implements repository.PasswordResetRepository using SQLite
+type PasswordResetRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetRepository creates a new SQLite password reset token repository
+func NewPasswordResetRepository(db *sql.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: db}
+}
+
+// Create inserts a password reset token
+func (r *PasswordResetRepository) Create(ctx context.Context, token *entity.PasswordResetToken) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, request_ip, created_at, expires_at, used_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, token.ID, token.UserID, token.TokenHash, token.RequestIP, token.CreatedAt, token.ExpiresAt, token.UsedAt)
+
+	return err
+}
+
+// FindByHash finds a password reset token by the hash of its secret
+func (r *PasswordResetRepository) FindByHash(ctx context.Context, hash string) (*entity.PasswordResetToken, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+passwordResetColumns+" FROM password_reset_tokens WHERE token_hash = ?", hash)
+	return scanPasswordResetToken(row)
+}
+
+// MarkUsed marks a token used, failing with sql.ErrNoRows when it already was
+// so that concurrent resets with the same token cannot both succeed
+func (r *PasswordResetRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE password_reset_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL", usedAt, id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// InvalidateByUser marks the unused tokens of a user used
+func (r *PasswordResetRepository) InvalidateByUser(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE password_reset_tokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL", at, userID)
+	return err
+}
+
+// CountByUserSince counts the tokens issued to a user since a time
+func (r *PasswordResetRepository) CountByUserSince(ctx context.Context, userID string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM password_reset_tokens WHERE user_id = ? AND created_at >= ?", userID, since).Scan(&count)
+	return count, err
+}
+
+// DeleteExpired deletes the tokens expired before a time
+func (r *PasswordResetRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM password_reset_tokens WHERE expires_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// scanPasswordResetToken scans a password reset token row
+func scanPasswordResetToken(row interface{ Scan(dest ...any) error }) (*entity.PasswordResetToken, error) {
+	token := &entity.PasswordResetToken{}
+	var usedAt sql.NullTime
+
+	err := row.Scan(&token.ID, &token.UserID, &token.TokenHash, &token.RequestIP, &token.CreatedAt, &token.ExpiresAt, &usedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return token, nil
+}
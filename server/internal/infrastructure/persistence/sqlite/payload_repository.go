@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+// payloadColumns are the metadata columns of a payload, without its content
+const payloadColumns = "id, name, description, sha256, size, uploaded_by, created_at"
+
+// PayloadRepository implements repository.PayloadRepository using SQLite
+type PayloadRepository struct {
+	db *sql.DB
+}
+
+// NewPayloadRepository creates a new SQLite payload repository
+func NewPayloadRepository(db *sql.DB) *PayloadRepository {
+	return &PayloadRepository{db: db}
+}
+
+// Create inserts a payload with its content
+func (r *PayloadRepository) Create(ctx context.Context, payload *entity.Payload, content []byte) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO payloads (id, name, description, sha256, size, content, uploaded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, payload.ID, payload.Name, payload.Description, payload.SHA256, payload.Size, content,
+		payload.UploadedBy, payload.CreatedAt)
+
+	return err
+}
+
+// Delete removes a payload and its content
+func (r *PayloadRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM payloads WHERE id = ?", id)
+	return err
+}
+
+// FindByID finds a payload by ID
+func (r *PayloadRepository) FindByID(ctx context.Context, id string) (*entity.Payload, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+payloadColumns+" FROM payloads WHERE id = ?", id)
+	return scanPayload(row)
+}
+
+// FindByName finds a payload by the name executors reference it with
+func (r *PayloadRepository) FindByName(ctx context.Context, name string) (*entity.Payload, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+payloadColumns+" FROM payloads WHERE name = ?", name)
+	return scanPayload(row)
+}
+
+// FindAll returns all payloads ordered by name
+func (r *PayloadRepository) FindAll(ctx context.Context) ([]*entity.Payload, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+payloadColumns+" FROM payloads ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payloads []*entity.Payload
+	for rows.Next() {
+		payload, err := scanPayload(rows)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload)
+	}
+
+	return payloads, rows.Err()
+}
+
+// Content loads the content of a payload
+func (r *PayloadRepository) Content(ctx context.Context, id string) ([]byte, error) {
+	var content []byte
+	err := r.db.QueryRowContext(ctx, "SELECT content FROM payloads WHERE id = ?", id).Scan(&content)
+	if err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// scanPayload scans a payload metadata row
+func scanPayload(row interface{ Scan(dest ...any) error }) (*entity.Payload, error) {
+	payload := &entity.Payload{}
+	var description, uploadedBy sql.NullString
+
+	err := row.Scan(&payload.ID, &payload.Name, &description, &payload.SHA256, &payload.Size,
+		&uploadedBy, &payload.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	payload.Description = description.String
+	payload.UploadedBy = uploadedBy.String
+	return payload, nil
+}
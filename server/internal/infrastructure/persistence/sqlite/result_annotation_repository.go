@@ -0,0 +1,181 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// resultCommentColumns are the columns of a result comment
+const resultCommentColumns = "id, result_id, execution_id, author_id, body, created_at"
+
+// ResultAnnotationRepository implements repository.ResultAnnotationRepository using SQLite
+type ResultAnnotationRepository struct {
+	db *sql.DB
+}
+
+// NewResultAnnotationRepository creates a new SQLite result annotation repository
+func NewResultAnnotationRepository(db *sql.DB) *ResultAnnotationRepository {
+	return &ResultAnnotationRepository{db: db}
+}
+
+// Get returns the annotation of a result with its comments, or nil when the
+// result was neither triaged, assigned nor commented
+func (r *ResultAnnotationRepository) Get(ctx context.Context, resultID string) (*entity.ResultAnnotation, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT result_id, execution_id, triage, assignee_id, updated_by, updated_at
+		FROM result_annotations WHERE result_id = ?
+	`, resultID)
+	annotation, err := scanResultAnnotation(row)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	comments, err := r.findComments(ctx, "result_id", resultID)
+	if err != nil {
+		return nil, err
+	}
+	if annotation == nil {
+		if len(comments) == 0 {
+			return nil, nil
+		}
+		annotation = &entity.ResultAnnotation{ResultID: resultID, ExecutionID: comments[0].ExecutionID}
+	}
+	annotation.Comments = comments
+	if annotation.Comments == nil {
+		annotation.Comments = []entity.ResultComment{}
+	}
+	return annotation, nil
+}
+
+// FindByExecution returns the annotations of the results of an execution, with
+// their comments, for the results that have any
+func (r *ResultAnnotationRepository) FindByExecution(ctx context.Context, executionID string) ([]*entity.ResultAnnotation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT result_id, execution_id, triage, assignee_id, updated_by, updated_at
+		FROM result_annotations WHERE execution_id = ? ORDER BY result_id
+	`, executionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var annotations []*entity.ResultAnnotation
+	byResult := make(map[string]*entity.ResultAnnotation)
+	for rows.Next() {
+		annotation, err := scanResultAnnotation(rows)
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation)
+		byResult[annotation.ResultID] = annotation
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	comments, err := r.findComments(ctx, "execution_id", executionID)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		annotation, ok := byResult[comment.ResultID]
+		if !ok {
+			annotation = &entity.ResultAnnotation{ResultID: comment.ResultID, ExecutionID: executionID}
+			annotations = append(annotations, annotation)
+			byResult[comment.ResultID] = annotation
+		}
+		annotation.Comments = append(annotation.Comments, comment)
+	}
+	for _, annotation := range annotations {
+		if annotation.Comments == nil {
+			annotation.Comments = []entity.ResultComment{}
+		}
+	}
+	return annotations, nil
+}
+
+// Save inserts or replaces the triage and assignee of a result
+func (r *ResultAnnotationRepository) Save(ctx context.Context, annotation *entity.ResultAnnotation) error {
+	updatedAt := time.Now()
+	if annotation.UpdatedAt != nil {
+		updatedAt = *annotation.UpdatedAt
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO result_annotations (result_id, execution_id, triage, assignee_id, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, annotation.ResultID, annotation.ExecutionID, string(annotation.Triage), annotation.AssigneeID,
+		annotation.UpdatedBy, updatedAt)
+
+	return err
+}
+
+// AddComment inserts a comment on a result
+func (r *ResultAnnotationRepository) AddComment(ctx context.Context, comment *entity.ResultComment) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO result_comments (id, result_id, execution_id, author_id, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, comment.ID, comment.ResultID, comment.ExecutionID, comment.AuthorID, comment.Body, comment.CreatedAt)
+
+	return err
+}
+
+// FindComment finds a comment by ID
+func (r *ResultAnnotationRepository) FindComment(ctx context.Context, id string) (*entity.ResultComment, error) {
+	comment := entity.ResultComment{}
+	err := r.db.QueryRowContext(ctx, "SELECT "+resultCommentColumns+" FROM result_comments WHERE id = ?", id).Scan(
+		&comment.ID, &comment.ResultID, &comment.ExecutionID, &comment.AuthorID, &comment.Body, &comment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// DeleteComment deletes a comment
+func (r *ResultAnnotationRepository) DeleteComment(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM result_comments WHERE id = ?", id)
+	return err
+}
+
+// findComments returns the comments matching a result or execution ID, oldest first
+func (r *ResultAnnotationRepository) findComments(ctx context.Context, column, id string) ([]entity.ResultComment, error) {
+	// NOSONAR: column is one of two constant names, the ID is a query parameter
+	rows, err := r.db.QueryContext(ctx, "SELECT "+resultCommentColumns+
+		" FROM result_comments WHERE "+column+" = ? ORDER BY created_at ASC, rowid ASC", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []entity.ResultComment
+	for rows.Next() {
+		var comment entity.ResultComment
+		if err := rows.Scan(&comment.ID, &comment.ResultID, &comment.ExecutionID, &comment.AuthorID,
+			&comment.Body, &comment.CreatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+// scanResultAnnotation scans a result annotation row, without its comments
+func scanResultAnnotation(row interface{ Scan(dest ...any) error }) (*entity.ResultAnnotation, error) {
+	annotation := &entity.ResultAnnotation{}
+	var triage, assigneeID, updatedBy sql.NullString
+	var updatedAt time.Time
+
+	err := row.Scan(&annotation.ResultID, &annotation.ExecutionID, &triage, &assigneeID, &updatedBy, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	annotation.Triage = entity.ResultTriage(triage.String)
+	annotation.AssigneeID = assigneeID.String
+	annotation.UpdatedBy = updatedBy.String
+	annotation.UpdatedAt = &updatedAt
+	return annotation, nil
+}
@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"autostrike/internal/domain/entity"
@@ -10,25 +11,32 @@ import (
 
 // ResultRepository implements repository.ResultRepository using SQLite
 type ResultRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts *statementCache // Statements run for each dispatched task and reported result
 }
 
 // NewResultRepository creates a new SQLite result repository
 func NewResultRepository(db *sql.DB) *ResultRepository {
-	return &ResultRepository{db: db}
+	return &ResultRepository{db: db, stmts: newStatementCache(db)}
+}
+
+// Close closes the statements the repository prepared, before the database is closed
+func (r *ResultRepository) Close() error {
+	return r.stmts.Close()
 }
 
 // CreateExecution creates a new execution
 func (r *ResultRepository) CreateExecution(ctx context.Context, execution *entity.Execution) error {
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO executions (id, scenario_id, status, started_at, safe_mode)
-		VALUES (?, ?, ?, ?, ?)
-	`, execution.ID, execution.ScenarioID, execution.Status, execution.StartedAt, execution.SafeMode)
+		INSERT INTO executions (id, scenario_id, status, started_at, safe_mode, scoring_profile_id, scoring_profile_version)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, execution.ID, execution.ScenarioID, execution.Status, execution.StartedAt, execution.SafeMode,
+		execution.ScoringProfileID, execution.ScoringProfileVersion)
 
 	return err
 }
 
-// UpdateExecution updates an existing execution
+// UpdateExecution updates the status, score, scoring profile version and failure reason of an execution
 func (r *ResultRepository) UpdateExecution(ctx context.Context, execution *entity.Execution) error {
 	// Ensure Score is initialized to prevent nil pointer dereference
 	score := execution.Score
@@ -38,10 +46,12 @@ func (r *ResultRepository) UpdateExecution(ctx context.Context, execution *entit
 
 	_, err := r.db.ExecContext(ctx, `
 		UPDATE executions SET status = ?, completed_at = ?,
-		score_overall = ?, score_blocked = ?, score_detected = ?, score_successful = ?, score_total = ?
+		score_overall = ?, score_blocked = ?, score_detected = ?, score_successful = ?, score_total = ?,
+		scoring_profile_id = ?, scoring_profile_version = ?, failure_reason = ?
 		WHERE id = ?
 	`, execution.Status, execution.CompletedAt,
 		score.Overall, score.Blocked, score.Detected, score.Successful, score.Total,
+		execution.ScoringProfileID, execution.ScoringProfileVersion, execution.FailureReason,
 		execution.ID)
 
 	return err
@@ -56,11 +66,13 @@ func (r *ResultRepository) FindExecutionByID(ctx context.Context, id string) (*e
 
 	err := r.db.QueryRowContext(ctx, `
 		SELECT id, scenario_id, status, started_at, completed_at, safe_mode,
-		score_overall, score_blocked, score_detected, score_successful, score_total
+		score_overall, score_blocked, score_detected, score_successful, score_total,
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0), COALESCE(failure_reason, '')
 		FROM executions WHERE id = ?
 	`, id).Scan(&execution.ID, &execution.ScenarioID, &execution.Status, &execution.StartedAt, &completedAt,
 		&execution.SafeMode, &execution.Score.Overall, &execution.Score.Blocked, &execution.Score.Detected,
-		&execution.Score.Successful, &execution.Score.Total)
+		&execution.Score.Successful, &execution.Score.Total, &execution.ScoringProfileID, &execution.ScoringProfileVersion,
+		&execution.FailureReason)
 
 	if err != nil {
 		return nil, err
@@ -77,7 +89,8 @@ func (r *ResultRepository) FindExecutionByID(ctx context.Context, id string) (*e
 func (r *ResultRepository) FindExecutionsByScenario(ctx context.Context, scenarioID string) ([]*entity.Execution, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, scenario_id, status, started_at, completed_at, safe_mode,
-		score_overall, score_blocked, score_detected, score_successful, score_total
+		score_overall, score_blocked, score_detected, score_successful, score_total,
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0), COALESCE(failure_reason, '')
 		FROM executions WHERE scenario_id = ? ORDER BY started_at DESC
 	`, scenarioID)
 	if err != nil {
@@ -92,7 +105,8 @@ func (r *ResultRepository) FindExecutionsByScenario(ctx context.Context, scenari
 func (r *ResultRepository) FindRecentExecutions(ctx context.Context, limit int) ([]*entity.Execution, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, scenario_id, status, started_at, completed_at, safe_mode,
-		score_overall, score_blocked, score_detected, score_successful, score_total
+		score_overall, score_blocked, score_detected, score_successful, score_total,
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0), COALESCE(failure_reason, '')
 		FROM executions ORDER BY started_at DESC LIMIT ?
 	`, limit)
 	if err != nil {
@@ -103,11 +117,83 @@ func (r *ResultRepository) FindRecentExecutions(ctx context.Context, limit int)
 	return r.scanExecutions(rows)
 }
 
+// FindRecentExecutionsWithSummary finds the most recent executions with the
+// counts of their results by status, in a single query
+func (r *ResultRepository) FindRecentExecutionsWithSummary(ctx context.Context, limit int) ([]*entity.Execution, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.id, e.scenario_id, e.status, e.started_at, e.completed_at, e.safe_mode,
+		e.score_overall, e.score_blocked, e.score_detected, e.score_successful, e.score_total,
+		COALESCE(e.scoring_profile_id, ''), COALESCE(e.scoring_profile_version, 0), COALESCE(e.failure_reason, ''),
+		r.status, COUNT(r.id)
+		FROM (SELECT * FROM executions ORDER BY started_at DESC LIMIT ?) e
+		LEFT JOIN execution_results r ON r.execution_id = e.id
+		GROUP BY e.id, r.status
+		ORDER BY e.started_at DESC, e.id
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Each execution comes as one row per status of its results, or a single
+	// row without status when it has none
+	var executions []*entity.Execution
+	var current *entity.Execution
+	for rows.Next() {
+		execution := &entity.Execution{Score: &entity.SecurityScore{}}
+		var completedAt sql.NullTime
+		var status sql.NullString
+		var count int
+		err := rows.Scan(&execution.ID, &execution.ScenarioID, &execution.Status, &execution.StartedAt, &completedAt,
+			&execution.SafeMode, &execution.Score.Overall, &execution.Score.Blocked, &execution.Score.Detected,
+			&execution.Score.Successful, &execution.Score.Total, &execution.ScoringProfileID, &execution.ScoringProfileVersion,
+			&execution.FailureReason, &status, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		if current == nil || current.ID != execution.ID {
+			if completedAt.Valid {
+				execution.CompletedAt = &completedAt.Time
+			}
+			execution.ResultSummary = &entity.ExecutionResultSummary{ByStatus: make(map[entity.ResultStatus]int)}
+			executions = append(executions, execution)
+			current = execution
+		}
+		if status.Valid {
+			current.ResultSummary.ByStatus[entity.ResultStatus(status.String)] = count
+			current.ResultSummary.Total += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// FindExecutionsByStatus finds the executions in a status, oldest first
+func (r *ResultRepository) FindExecutionsByStatus(ctx context.Context, status entity.ExecutionStatus) ([]*entity.Execution, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, scenario_id, status, started_at, completed_at, safe_mode,
+		score_overall, score_blocked, score_detected, score_successful, score_total,
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0), COALESCE(failure_reason, '')
+		FROM executions WHERE status = ? ORDER BY started_at
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanExecutions(rows)
+}
+
 // FindExecutionsByDateRange finds all executions within a date range
 func (r *ResultRepository) FindExecutionsByDateRange(ctx context.Context, start, end time.Time) ([]*entity.Execution, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, scenario_id, status, started_at, completed_at, safe_mode,
-		score_overall, score_blocked, score_detected, score_successful, score_total
+		score_overall, score_blocked, score_detected, score_successful, score_total,
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0), COALESCE(failure_reason, '')
 		FROM executions
 		WHERE started_at >= ? AND started_at <= ?
 		ORDER BY started_at DESC
@@ -124,7 +210,8 @@ func (r *ResultRepository) FindExecutionsByDateRange(ctx context.Context, start,
 func (r *ResultRepository) FindCompletedExecutionsByDateRange(ctx context.Context, start, end time.Time) ([]*entity.Execution, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, scenario_id, status, started_at, completed_at, safe_mode,
-		score_overall, score_blocked, score_detected, score_successful, score_total
+		score_overall, score_blocked, score_detected, score_successful, score_total,
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0), COALESCE(failure_reason, '')
 		FROM executions
 		WHERE started_at >= ? AND started_at <= ? AND status = 'completed'
 		ORDER BY started_at DESC
@@ -137,35 +224,109 @@ func (r *ResultRepository) FindCompletedExecutionsByDateRange(ctx context.Contex
 	return r.scanExecutions(rows)
 }
 
-// CreateResult creates a new execution result
+// createResultQuery inserts a result, unless the attempt of its technique on
+// its agent was already dispatched
+const createResultQuery = `
+	INSERT INTO execution_results (id, execution_id, technique_id, agent_paw, attempt, phase, executor, status, output, started_at, completed_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(execution_id, technique_id, agent_paw, attempt) DO NOTHING
+`
+
+// CreateResult creates a new execution result. Creating a result whose
+// execution, technique, agent and attempt are already recorded leaves the
+// stored row untouched and loads its ID and status into result.
 func (r *ResultRepository) CreateResult(ctx context.Context, result *entity.ExecutionResult) error {
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO execution_results (id, execution_id, technique_id, agent_paw, status, started_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, result.ID, result.ExecutionID, result.TechniqueID, result.AgentPaw, result.Status, result.StartedAt)
+	if result.Attempt < 1 {
+		result.Attempt = 1
+	}
 
-	return err
+	res, err := r.stmts.ExecContext(ctx, createResultQuery, result.ID, result.ExecutionID, result.TechniqueID, result.AgentPaw, result.Attempt, result.Phase, result.Executor, result.Status,
+		result.Output, result.StartedAt, result.CompletedAt)
+	if err != nil {
+		return err
+	}
+	if inserted, err := res.RowsAffected(); err != nil || inserted > 0 {
+		return err
+	}
+
+	return r.stmts.QueryRowContext(ctx, `
+		SELECT id, status FROM execution_results
+		WHERE execution_id = ? AND technique_id = ? AND agent_paw = ? AND attempt = ?
+	`, result.ExecutionID, result.TechniqueID, result.AgentPaw, result.Attempt).Scan(&result.ID, &result.Status)
 }
 
-// UpdateResult updates an existing execution result
+// UpdateResult updates an existing execution result. The status can only move
+// forward (pending or queued, running, then a final status); an update that would move
+// it back returns entity.ErrResultTransition and changes nothing.
 func (r *ResultRepository) UpdateResult(ctx context.Context, result *entity.ExecutionResult) error {
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE execution_results SET status = ?, output = ?, exit_code = ?, detected = ?, completed_at = ?
-		WHERE id = ?
-	`, result.Status, result.Output, result.ExitCode, result.Detected, result.CompletedAt, result.ID)
+	return updateResult(ctx, r.stmts, result)
+}
 
-	return err
+// UpdateResults updates the reported results of a batch in a single
+// transaction, returning the error of each result: entity.ErrResultTransition
+// for a result that would move backward. The error returned last fails the
+// whole batch, no result being updated.
+func (r *ResultRepository) UpdateResults(ctx context.Context, results []*entity.ExecutionResult) ([]error, error) {
+	errs := make([]error, len(results))
+	if len(results) == 0 {
+		return errs, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i, result := range results {
+		err := updateResult(ctx, r.stmts.Tx(tx), result)
+		if err != nil && !errors.Is(err, entity.ErrResultTransition) {
+			return nil, err
+		}
+		errs[i] = err
+	}
+	return errs, tx.Commit()
+}
+
+// execQuerier runs the statements of a database or of a transaction
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// updateResult updates a result unless it would move backward
+func updateResult(ctx context.Context, db execQuerier, result *entity.ExecutionResult) error {
+	res, err := db.ExecContext(ctx, `
+		UPDATE execution_results SET status = ?, output = ?, output_ref = ?, output_size = ?, output_truncated = ?, exit_code = ?, detected = ?, detected_by = ?, completed_at = ?
+		WHERE id = ? AND (CASE status WHEN 'pending' THEN 0 WHEN 'queued' THEN 0 WHEN 'running' THEN 1 ELSE 2 END) <= ?
+	`, result.Status, result.Output, result.OutputRef, result.OutputSize, result.OutputTruncated, result.ExitCode, result.Detected, result.DetectedBy,
+		result.CompletedAt, result.ID, result.Status.Stage())
+	if err != nil {
+		return err
+	}
+	if updated, err := res.RowsAffected(); err != nil || updated > 0 {
+		return err
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM execution_results WHERE id = ?)`, result.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return entity.ErrResultTransition
+	}
+	return nil
 }
 
 // FindResultByID finds a result by its ID
 func (r *ResultRepository) FindResultByID(ctx context.Context, id string) (*entity.ExecutionResult, error) {
-	row := r.db.QueryRowContext(ctx, `
-		SELECT id, execution_id, technique_id, agent_paw, status, output, exit_code, detected, started_at, completed_at
+	row := r.stmts.QueryRowContext(ctx, `
+		SELECT id, execution_id, technique_id, agent_paw, attempt, phase, executor, status, output, output_ref, output_size, output_truncated, exit_code, detected, detected_by, started_at, completed_at
 		FROM execution_results WHERE id = ?
 	`, id)
 
 	result := &entity.ExecutionResult{}
-	var output sql.NullString
+	var phase, executor, output, outputRef, detectedBy sql.NullString
 	var completedAt sql.NullTime
 
 	err := row.Scan(
@@ -173,10 +334,17 @@ func (r *ResultRepository) FindResultByID(ctx context.Context, id string) (*enti
 		&result.ExecutionID,
 		&result.TechniqueID,
 		&result.AgentPaw,
+		&result.Attempt,
+		&phase,
+		&executor,
 		&result.Status,
 		&output,
+		&outputRef,
+		&result.OutputSize,
+		&result.OutputTruncated,
 		&result.ExitCode,
 		&result.Detected,
+		&detectedBy,
 		&result.StartedAt,
 		&completedAt,
 	)
@@ -184,9 +352,15 @@ func (r *ResultRepository) FindResultByID(ctx context.Context, id string) (*enti
 		return nil, err
 	}
 
+	result.Phase = phase.String
+	result.Executor = executor.String
+	result.OutputRef = outputRef.String
 	if output.Valid {
 		result.Output = output.String
 	}
+	if detectedBy.Valid {
+		result.DetectedBy = detectedBy.String
+	}
 	if completedAt.Valid {
 		result.CompletedAt = &completedAt.Time
 	}
@@ -196,9 +370,9 @@ func (r *ResultRepository) FindResultByID(ctx context.Context, id string) (*enti
 
 // FindResultsByExecution finds results by execution ID
 func (r *ResultRepository) FindResultsByExecution(ctx context.Context, executionID string) ([]*entity.ExecutionResult, error) {
-	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, execution_id, technique_id, agent_paw, status, output, exit_code, detected, started_at, completed_at
-		FROM execution_results WHERE execution_id = ? ORDER BY started_at
+	rows, err := r.stmts.QueryContext(ctx, `
+		SELECT id, execution_id, technique_id, agent_paw, attempt, phase, executor, status, output, output_ref, output_size, output_truncated, exit_code, detected, detected_by, started_at, completed_at
+		FROM execution_results WHERE execution_id = ? ORDER BY started_at, attempt
 	`, executionID)
 	if err != nil {
 		return nil, err
@@ -208,10 +382,55 @@ func (r *ResultRepository) FindResultsByExecution(ctx context.Context, execution
 	return r.scanResults(rows)
 }
 
+// FindResultsByExecutions finds the results of several executions in a
+// single query, ordered by execution
+func (r *ResultRepository) FindResultsByExecutions(ctx context.Context, executionIDs []string) ([]*entity.ExecutionResult, error) {
+	if len(executionIDs) == 0 {
+		return nil, nil
+	}
+	placeholders, args := inClause(executionIDs)
+	// NOSONAR: only "?" placeholders are joined, the IDs are query parameters
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, execution_id, technique_id, agent_paw, attempt, phase, executor, status, output, output_ref, output_size, output_truncated, exit_code, detected, detected_by, started_at, completed_at
+		FROM execution_results WHERE execution_id IN (`+placeholders+`) ORDER BY execution_id, started_at, attempt
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanResults(rows)
+}
+
+// FindPawsWithResults reports which of the agents have execution results
+func (r *ResultRepository) FindPawsWithResults(ctx context.Context, paws []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(paws) == 0 {
+		return found, nil
+	}
+	placeholders, args := inClause(paws)
+	// NOSONAR: only "?" placeholders are joined, the paws are query parameters
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT DISTINCT agent_paw FROM execution_results WHERE agent_paw IN (`+placeholders+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var paw string
+		if err := rows.Scan(&paw); err != nil {
+			return nil, err
+		}
+		found[paw] = true
+	}
+	return found, rows.Err()
+}
+
 // FindResultsByTechnique finds results by technique ID
 func (r *ResultRepository) FindResultsByTechnique(ctx context.Context, techniqueID string) ([]*entity.ExecutionResult, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, execution_id, technique_id, agent_paw, status, output, exit_code, detected, started_at, completed_at
+		SELECT id, execution_id, technique_id, agent_paw, attempt, phase, executor, status, output, output_ref, output_size, output_truncated, exit_code, detected, detected_by, started_at, completed_at
 		FROM execution_results WHERE technique_id = ? ORDER BY started_at DESC
 	`, techniqueID)
 	if err != nil {
@@ -222,6 +441,113 @@ func (r *ResultRepository) FindResultsByTechnique(ctx context.Context, technique
 	return r.scanResults(rows)
 }
 
+// FindRecentDurations returns the durations of the latest finished runs of
+// each technique, at most limit per technique. Skipped results never ran.
+func (r *ResultRepository) FindRecentDurations(ctx context.Context, techniqueIDs []string, limit int) (map[string][]time.Duration, error) {
+	durations := make(map[string][]time.Duration)
+	if len(techniqueIDs) == 0 {
+		return durations, nil
+	}
+	placeholders, args := inClause(techniqueIDs)
+	// NOSONAR: only "?" placeholders are joined, the IDs are query parameters
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT technique_id, started_at, completed_at FROM (
+			SELECT technique_id, started_at, completed_at,
+				ROW_NUMBER() OVER (PARTITION BY technique_id ORDER BY completed_at DESC) AS n
+			FROM execution_results
+			WHERE technique_id IN (`+placeholders+`) AND completed_at IS NOT NULL
+				AND status NOT IN (?, ?, ?, ?, ?)
+		) WHERE n <= ?
+	`, append(args, entity.StatusPending, entity.StatusQueued, entity.StatusRunning,
+		entity.StatusSkipped, entity.StatusSkippedInsufficientPrivilege, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var techniqueID string
+		var startedAt, completedAt time.Time
+		if err := rows.Scan(&techniqueID, &startedAt, &completedAt); err != nil {
+			return nil, err
+		}
+		if !completedAt.Before(startedAt) {
+			durations[techniqueID] = append(durations[techniqueID], completedAt.Sub(startedAt))
+		}
+	}
+	return durations, rows.Err()
+}
+
+// ClaimSubmission records the idempotency key of a result an agent submitted,
+// reporting false when the agent already submitted it
+func (r *ResultRepository) ClaimSubmission(ctx context.Context, paw, submissionID, resultID string, receivedAt time.Time) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO result_submissions (agent_paw, submission_id, result_id, received_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(agent_paw, submission_id) DO NOTHING
+	`, paw, submissionID, resultID, receivedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// ClaimSubmissions records the idempotency keys of a batch of results an agent
+// submitted, by submission ID to result ID, in a single transaction. It
+// reports the keys claimed, those the agent already submitted being left out.
+func (r *ResultRepository) ClaimSubmissions(ctx context.Context, paw string, submissions map[string]string, receivedAt time.Time) (map[string]bool, error) {
+	claimed := make(map[string]bool, len(submissions))
+	if len(submissions) == 0 {
+		return claimed, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO result_submissions (agent_paw, submission_id, result_id, received_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(agent_paw, submission_id) DO NOTHING
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	for submissionID, resultID := range submissions {
+		res, err := stmt.ExecContext(ctx, paw, submissionID, resultID, receivedAt)
+		if err != nil {
+			return nil, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return nil, err
+		} else if n == 1 {
+			claimed[submissionID] = true
+		}
+	}
+	return claimed, tx.Commit()
+}
+
+// ReleaseSubmission forgets the idempotency key of a submission, so that its retry is processed
+func (r *ResultRepository) ReleaseSubmission(ctx context.Context, paw, submissionID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM result_submissions WHERE agent_paw = ? AND submission_id = ?`, paw, submissionID)
+	return err
+}
+
+// DeleteSubmissionsBefore deletes the idempotency keys received before a time,
+// returning how many were deleted
+func (r *ResultRepository) DeleteSubmissionsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM result_submissions WHERE received_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (r *ResultRepository) scanExecutions(rows *sql.Rows) ([]*entity.Execution, error) {
 	var executions []*entity.Execution
 
@@ -233,7 +559,8 @@ func (r *ResultRepository) scanExecutions(rows *sql.Rows) ([]*entity.Execution,
 
 		err := rows.Scan(&execution.ID, &execution.ScenarioID, &execution.Status, &execution.StartedAt, &completedAt,
 			&execution.SafeMode, &execution.Score.Overall, &execution.Score.Blocked, &execution.Score.Detected,
-			&execution.Score.Successful, &execution.Score.Total)
+			&execution.Score.Successful, &execution.Score.Total, &execution.ScoringProfileID, &execution.ScoringProfileVersion,
+			&execution.FailureReason)
 		if err != nil {
 			return nil, err
 		}
@@ -257,18 +584,25 @@ func (r *ResultRepository) scanResults(rows *sql.Rows) ([]*entity.ExecutionResul
 
 	for rows.Next() {
 		result := &entity.ExecutionResult{}
-		var output sql.NullString
+		var phase, executor, output, outputRef, detectedBy sql.NullString
 		var completedAt sql.NullTime
 
-		err := rows.Scan(&result.ID, &result.ExecutionID, &result.TechniqueID, &result.AgentPaw,
-			&result.Status, &output, &result.ExitCode, &result.Detected, &result.StartedAt, &completedAt)
+		err := rows.Scan(&result.ID, &result.ExecutionID, &result.TechniqueID, &result.AgentPaw, &result.Attempt,
+			&phase, &executor, &result.Status, &output, &outputRef, &result.OutputSize, &result.OutputTruncated, &result.ExitCode, &result.Detected, &detectedBy,
+			&result.StartedAt, &completedAt)
 		if err != nil {
 			return nil, err
 		}
 
+		result.Phase = phase.String
+		result.Executor = executor.String
+		result.OutputRef = outputRef.String
 		if output.Valid {
 			result.Output = output.String
 		}
+		if detectedBy.Valid {
+			result.DetectedBy = detectedBy.String
+		}
 		if completedAt.Valid {
 			result.CompletedAt = &completedAt.Time
 		}
@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// executionDependents are the tables whose rows are deleted with their execution
+var executionDependents = []string{
+	"execution_facts", "result_artifacts", "result_evidence", "result_annotations", "result_comments", "execution_reviews",
+	"execution_snapshots", "task_queue", "score_recomputations", "execution_results",
+}
+
+// RetentionRepository implements repository.RetentionRepository using SQLite
+type RetentionRepository struct {
+	db *sql.DB
+}
+
+// NewRetentionRepository creates a new SQLite retention repository
+func NewRetentionRepository(db *sql.DB) *RetentionRepository {
+	return &RetentionRepository{db: db}
+}
+
+// FindExpiredOutputs finds up to limit results that still have an output, of
+// executions completed before completedBefore, oldest first
+func (r *RetentionRepository) FindExpiredOutputs(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.ExecutionResult, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.id, r.execution_id, r.technique_id, r.agent_paw, r.attempt, r.phase, r.executor, r.status, r.output, r.output_ref, r.output_size, r.output_truncated, r.exit_code, r.detected, r.detected_by, r.started_at, r.completed_at
+		FROM execution_results r JOIN executions e ON e.id = r.execution_id
+		WHERE e.completed_at IS NOT NULL AND e.completed_at < ? AND r.output IS NOT NULL AND r.output != ''
+		ORDER BY e.completed_at, r.started_at LIMIT ?
+	`, completedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return NewResultRepository(r.db).scanResults(rows)
+}
+
+// ClearOutputs empties the output of results and drops the reference to their
+// stored full output, keeping their status and detection
+func (r *RetentionRepository) ClearOutputs(ctx context.Context, resultIDs []string) (int64, error) {
+	if len(resultIDs) == 0 {
+		return 0, nil
+	}
+	placeholders, args := inClause(resultIDs)
+
+	// NOSONAR: only "?" placeholders are joined, the IDs are query parameters
+	result, err := r.db.ExecContext(ctx, "UPDATE execution_results SET output = '', output_ref = NULL, output_size = 0 WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FindBlobKeys returns the object storage keys of the full outputs, the
+// artifacts and the evidence of executions
+func (r *RetentionRepository) FindBlobKeys(ctx context.Context, executionIDs []string) ([]string, error) {
+	if len(executionIDs) == 0 {
+		return nil, nil
+	}
+	placeholders, args := inClause(executionIDs)
+
+	// NOSONAR: only "?" placeholders are joined, the IDs are query parameters
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT output_ref FROM execution_results WHERE execution_id IN (`+placeholders+`) AND output_ref IS NOT NULL AND output_ref != ''
+		UNION ALL
+		SELECT storage_key FROM result_artifacts WHERE execution_id IN (`+placeholders+`) AND storage_key IS NOT NULL AND storage_key != ''
+		UNION ALL
+		SELECT storage_key FROM result_evidence WHERE execution_id IN (`+placeholders+`) AND storage_key IS NOT NULL AND storage_key != ''
+	`, append(append(args, args...), args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// FindExpiredExecutions finds up to limit executions completed before
+// completedBefore, oldest first
+func (r *RetentionRepository) FindExpiredExecutions(ctx context.Context, completedBefore time.Time, limit int) ([]*entity.Execution, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, scenario_id, status, started_at, completed_at, safe_mode,
+		score_overall, score_blocked, score_detected, score_successful, score_total,
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0), COALESCE(failure_reason, '')
+		FROM executions
+		WHERE completed_at IS NOT NULL AND completed_at < ?
+		ORDER BY completed_at LIMIT ?
+	`, completedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return NewResultRepository(r.db).scanExecutions(rows)
+}
+
+// DeleteExecutions deletes executions with their results, facts, artifacts,
+// evidence, annotations, reviews, snapshots, queued tasks and score history in a transaction. Schedule runs are kept
+// without their execution. Returns the number of results deleted.
+func (r *RetentionRepository) DeleteExecutions(ctx context.Context, executionIDs []string) (int64, error) {
+	if len(executionIDs) == 0 {
+		return 0, nil
+	}
+	placeholders, args := inClause(executionIDs)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// NOSONAR: table names are constants and only "?" placeholders are joined
+	var results int64
+	for _, table := range executionDependents {
+		result, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE execution_id IN ("+placeholders+")", args...)
+		if err != nil {
+			return 0, err
+		}
+		if table == "execution_results" {
+			if results, err = result.RowsAffected(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE schedule_runs SET execution_id = NULL WHERE execution_id IN ("+placeholders+")", args...); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM executions WHERE id IN ("+placeholders+")", args...); err != nil {
+		return 0, err
+	}
+
+	return results, tx.Commit()
+}
+
+// inClause returns the "?" placeholders and arguments of an IN clause
+func inClause(ids []string) (string, []interface{}) {
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ","), args
+}
@@ -15,19 +15,25 @@ import (
 
 // SQL column constants and error messages for scenarios
 const (
-	scenarioColumns       = "id, name, description, phases, tags, created_at, updated_at"
-	errMarshalPhases      = "failed to marshal phases: %w"
-	errMarshalTags        = "failed to marshal tags: %w"
+	scenarioColumns  = "id, name, description, phases, tags, is_template, cloned_from, created_at, updated_at, deleted_at"
+	errMarshalPhases = "failed to marshal phases: %w"
+	errMarshalTags   = "failed to marshal tags: %w"
 )
 
 // ScenarioRepository implements repository.ScenarioRepository using SQLite
 type ScenarioRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	stmts *statementCache
 }
 
 // NewScenarioRepository creates a new SQLite scenario repository
 func NewScenarioRepository(db *sql.DB) *ScenarioRepository {
-	return &ScenarioRepository{db: db}
+	return &ScenarioRepository{db: db, stmts: newStatementCache(db)}
+}
+
+// Close closes the statements the repository prepared, before the database is closed
+func (r *ScenarioRepository) Close() error {
+	return r.stmts.Close()
 }
 
 // Create creates a new scenario
@@ -42,9 +48,10 @@ func (r *ScenarioRepository) Create(ctx context.Context, scenario *entity.Scenar
 	}
 
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO scenarios (id, name, description, phases, tags, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, scenario.ID, scenario.Name, scenario.Description, phases, tags, scenario.CreatedAt, scenario.UpdatedAt)
+		INSERT INTO scenarios (id, name, description, phases, tags, is_template, cloned_from, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, scenario.ID, scenario.Name, scenario.Description, phases, tags, scenario.IsTemplate, scenario.ClonedFrom,
+		scenario.CreatedAt, scenario.UpdatedAt)
 
 	return err
 }
@@ -68,9 +75,10 @@ func (r *ScenarioRepository) Update(ctx context.Context, scenario *entity.Scenar
 	return err
 }
 
-// Delete deletes a scenario
+// Delete moves a scenario to the trash. The row is kept for the executions
+// referencing it until the trash is purged.
 func (r *ScenarioRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM scenarios WHERE id = ?", id)
+	_, err := r.db.ExecContext(ctx, "UPDATE scenarios SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now(), id)
 	return err
 }
 
@@ -78,14 +86,18 @@ func (r *ScenarioRepository) Delete(ctx context.Context, id string) error {
 func (r *ScenarioRepository) FindByID(ctx context.Context, id string) (*entity.Scenario, error) {
 	scenario := &entity.Scenario{}
 	var phases, tags string
+	var clonedFrom sql.NullString
+	var deletedAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx,
-		fmt.Sprintf("SELECT %s FROM scenarios WHERE id = ?", scenarioColumns),
-		id).Scan(&scenario.ID, &scenario.Name, &scenario.Description, &phases, &tags, &scenario.CreatedAt, &scenario.UpdatedAt)
+		fmt.Sprintf("SELECT %s FROM scenarios WHERE id = ? AND deleted_at IS NULL", scenarioColumns),
+		id).Scan(&scenario.ID, &scenario.Name, &scenario.Description, &phases, &tags,
+		&scenario.IsTemplate, &clonedFrom, &scenario.CreatedAt, &scenario.UpdatedAt, &deletedAt)
 
 	if err != nil {
 		return nil, err
 	}
+	scenario.ClonedFrom = clonedFrom.String
 
 	// Parse JSON fields, default to empty on error
 	if json.Unmarshal([]byte(phases), &scenario.Phases) != nil {
@@ -100,8 +112,8 @@ func (r *ScenarioRepository) FindByID(ctx context.Context, id string) (*entity.S
 
 // FindAll finds all scenarios
 func (r *ScenarioRepository) FindAll(ctx context.Context) ([]*entity.Scenario, error) {
-	rows, err := r.db.QueryContext(ctx,
-		fmt.Sprintf("SELECT %s FROM scenarios ORDER BY updated_at DESC", scenarioColumns))
+	rows, err := r.stmts.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM scenarios WHERE deleted_at IS NULL ORDER BY updated_at DESC", scenarioColumns))
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +125,7 @@ func (r *ScenarioRepository) FindAll(ctx context.Context) ([]*entity.Scenario, e
 // FindByTag finds scenarios by tag
 func (r *ScenarioRepository) FindByTag(ctx context.Context, tag string) ([]*entity.Scenario, error) {
 	rows, err := r.db.QueryContext(ctx,
-		fmt.Sprintf("SELECT %s FROM scenarios WHERE tags LIKE ? ORDER BY updated_at DESC", scenarioColumns),
+		fmt.Sprintf("SELECT %s FROM scenarios WHERE tags LIKE ? AND deleted_at IS NULL ORDER BY updated_at DESC", scenarioColumns),
 		"%"+tag+"%")
 	if err != nil {
 		return nil, err
@@ -129,11 +141,18 @@ func (r *ScenarioRepository) scanScenarios(rows *sql.Rows) ([]*entity.Scenario,
 	for rows.Next() {
 		scenario := &entity.Scenario{}
 		var phases, tags string
+		var clonedFrom sql.NullString
+		var deletedAt sql.NullTime
 
-		err := rows.Scan(&scenario.ID, &scenario.Name, &scenario.Description, &phases, &tags, &scenario.CreatedAt, &scenario.UpdatedAt)
+		err := rows.Scan(&scenario.ID, &scenario.Name, &scenario.Description, &phases, &tags,
+			&scenario.IsTemplate, &clonedFrom, &scenario.CreatedAt, &scenario.UpdatedAt, &deletedAt)
 		if err != nil {
 			return nil, err
 		}
+		scenario.ClonedFrom = clonedFrom.String
+		if deletedAt.Valid {
+			scenario.DeletedAt = &deletedAt.Time
+		}
 
 		// Parse JSON fields, default to empty on error
 		if json.Unmarshal([]byte(phases), &scenario.Phases) != nil {
@@ -193,15 +212,16 @@ func (r *ScenarioRepository) upsert(ctx context.Context, scenario *entity.Scenar
 	}
 
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO scenarios (id, name, description, phases, tags, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO scenarios (id, name, description, phases, tags, is_template, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name = excluded.name,
 			description = excluded.description,
 			phases = excluded.phases,
 			tags = excluded.tags,
+			is_template = excluded.is_template,
 			updated_at = excluded.updated_at
-	`, scenario.ID, scenario.Name, scenario.Description, phases, tags, scenario.CreatedAt, scenario.UpdatedAt)
+	`, scenario.ID, scenario.Name, scenario.Description, phases, tags, scenario.IsTemplate, scenario.CreatedAt, scenario.UpdatedAt)
 
 	return err
 }
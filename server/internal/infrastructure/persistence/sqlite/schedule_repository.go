@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"autostrike/internal/domain/entity"
@@ -20,11 +21,15 @@ func NewScheduleRepository(db *sql.DB) *ScheduleRepository {
 
 // Create inserts a new schedule into the database
 func (r *ScheduleRepository) Create(ctx context.Context, schedule *entity.Schedule) error {
+	alerting, err := marshalScheduleAlerting(schedule.Alerting)
+	if err != nil {
+		return err
+	}
 	query := `
-		INSERT INTO schedules (id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, created_by, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO schedules (id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, agent_selector_id, timezone, misfire_policy, depends_on, chain_condition, chain_threshold, alerting, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		schedule.ID,
 		schedule.Name,
 		schedule.Description,
@@ -37,6 +42,13 @@ func (r *ScheduleRepository) Create(ctx context.Context, schedule *entity.Schedu
 		schedule.NextRunAt,
 		schedule.LastRunAt,
 		schedule.LastRunID,
+		schedule.AgentSelectorID,
+		schedule.Timezone,
+		string(schedule.MisfirePolicy),
+		schedule.DependsOn,
+		string(schedule.ChainCondition),
+		schedule.ChainThreshold,
+		alerting,
 		schedule.CreatedBy,
 		schedule.CreatedAt,
 		schedule.UpdatedAt,
@@ -46,12 +58,16 @@ func (r *ScheduleRepository) Create(ctx context.Context, schedule *entity.Schedu
 
 // Update updates an existing schedule
 func (r *ScheduleRepository) Update(ctx context.Context, schedule *entity.Schedule) error {
+	alerting, err := marshalScheduleAlerting(schedule.Alerting)
+	if err != nil {
+		return err
+	}
 	query := `
 		UPDATE schedules
-		SET name = ?, description = ?, scenario_id = ?, agent_paw = ?, frequency = ?, cron_expr = ?, safe_mode = ?, status = ?, next_run_at = ?, last_run_at = ?, last_run_id = ?, updated_at = ?
+		SET name = ?, description = ?, scenario_id = ?, agent_paw = ?, frequency = ?, cron_expr = ?, safe_mode = ?, status = ?, next_run_at = ?, last_run_at = ?, last_run_id = ?, agent_selector_id = ?, timezone = ?, misfire_policy = ?, depends_on = ?, chain_condition = ?, chain_threshold = ?, alerting = ?, updated_at = ?
 		WHERE id = ?
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		schedule.Name,
 		schedule.Description,
 		schedule.ScenarioID,
@@ -63,6 +79,13 @@ func (r *ScheduleRepository) Update(ctx context.Context, schedule *entity.Schedu
 		schedule.NextRunAt,
 		schedule.LastRunAt,
 		schedule.LastRunID,
+		schedule.AgentSelectorID,
+		schedule.Timezone,
+		string(schedule.MisfirePolicy),
+		schedule.DependsOn,
+		string(schedule.ChainCondition),
+		schedule.ChainThreshold,
+		alerting,
 		schedule.UpdatedAt,
 		schedule.ID,
 	)
@@ -95,7 +118,7 @@ func (r *ScheduleRepository) Delete(ctx context.Context, id string) error {
 // FindByID retrieves a schedule by ID
 func (r *ScheduleRepository) FindByID(ctx context.Context, id string) (*entity.Schedule, error) {
 	query := `
-		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, created_by, created_at, updated_at
+		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, agent_selector_id, timezone, misfire_policy, depends_on, chain_condition, chain_threshold, alerting, created_by, created_at, updated_at
 		FROM schedules WHERE id = ?
 	`
 	row := r.db.QueryRowContext(ctx, query, id)
@@ -105,7 +128,7 @@ func (r *ScheduleRepository) FindByID(ctx context.Context, id string) (*entity.S
 // FindAll retrieves all schedules
 func (r *ScheduleRepository) FindAll(ctx context.Context) ([]*entity.Schedule, error) {
 	query := `
-		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, created_by, created_at, updated_at
+		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, agent_selector_id, timezone, misfire_policy, depends_on, chain_condition, chain_threshold, alerting, created_by, created_at, updated_at
 		FROM schedules ORDER BY created_at DESC
 	`
 	rows, err := r.db.QueryContext(ctx, query)
@@ -119,7 +142,7 @@ func (r *ScheduleRepository) FindAll(ctx context.Context) ([]*entity.Schedule, e
 // FindByStatus retrieves schedules by status
 func (r *ScheduleRepository) FindByStatus(ctx context.Context, status entity.ScheduleStatus) ([]*entity.Schedule, error) {
 	query := `
-		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, created_by, created_at, updated_at
+		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, agent_selector_id, timezone, misfire_policy, depends_on, chain_condition, chain_threshold, alerting, created_by, created_at, updated_at
 		FROM schedules WHERE status = ? ORDER BY next_run_at ASC
 	`
 	rows, err := r.db.QueryContext(ctx, query, status)
@@ -133,7 +156,7 @@ func (r *ScheduleRepository) FindByStatus(ctx context.Context, status entity.Sch
 // FindActiveSchedulesDue retrieves active schedules that are due to run
 func (r *ScheduleRepository) FindActiveSchedulesDue(ctx context.Context, now time.Time) ([]*entity.Schedule, error) {
 	query := `
-		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, created_by, created_at, updated_at
+		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, agent_selector_id, timezone, misfire_policy, depends_on, chain_condition, chain_threshold, alerting, created_by, created_at, updated_at
 		FROM schedules
 		WHERE status = 'active' AND next_run_at IS NOT NULL AND next_run_at <= ?
 		ORDER BY next_run_at ASC
@@ -149,7 +172,7 @@ func (r *ScheduleRepository) FindActiveSchedulesDue(ctx context.Context, now tim
 // FindByScenarioID retrieves schedules for a specific scenario
 func (r *ScheduleRepository) FindByScenarioID(ctx context.Context, scenarioID string) ([]*entity.Schedule, error) {
 	query := `
-		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, created_by, created_at, updated_at
+		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, agent_selector_id, timezone, misfire_policy, depends_on, chain_condition, chain_threshold, alerting, created_by, created_at, updated_at
 		FROM schedules WHERE scenario_id = ? ORDER BY created_at DESC
 	`
 	rows, err := r.db.QueryContext(ctx, query, scenarioID)
@@ -160,6 +183,20 @@ func (r *ScheduleRepository) FindByScenarioID(ctx context.Context, scenarioID st
 	return r.scanSchedules(rows)
 }
 
+// FindDependents retrieves the chained schedules depending on a schedule
+func (r *ScheduleRepository) FindDependents(ctx context.Context, scheduleID string) ([]*entity.Schedule, error) {
+	query := `
+		SELECT id, name, description, scenario_id, agent_paw, frequency, cron_expr, safe_mode, status, next_run_at, last_run_at, last_run_id, agent_selector_id, timezone, misfire_policy, depends_on, chain_condition, chain_threshold, alerting, created_by, created_at, updated_at
+		FROM schedules WHERE depends_on = ? ORDER BY created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return r.scanSchedules(rows)
+}
+
 // CreateRun inserts a new schedule run
 func (r *ScheduleRepository) CreateRun(ctx context.Context, run *entity.ScheduleRun) error {
 	query := `
@@ -246,11 +283,45 @@ func (r *ScheduleRepository) FindRunsByScheduleID(ctx context.Context, scheduleI
 	return runs, nil
 }
 
+// FindRunByExecutionID retrieves the schedule run that started an execution,
+// or nil for executions not started by a schedule
+func (r *ScheduleRepository) FindRunByExecutionID(ctx context.Context, executionID string) (*entity.ScheduleRun, error) {
+	query := `
+		SELECT id, schedule_id, execution_id, started_at, completed_at, status, error
+		FROM schedule_runs WHERE execution_id = ?
+		ORDER BY started_at DESC LIMIT 1
+	`
+	run := &entity.ScheduleRun{}
+	var completedAt sql.NullTime
+	var errStr sql.NullString
+	err := r.db.QueryRowContext(ctx, query, executionID).Scan(
+		&run.ID,
+		&run.ScheduleID,
+		&run.ExecutionID,
+		&run.StartedAt,
+		&completedAt,
+		&run.Status,
+		&errStr,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		run.CompletedAt = &completedAt.Time
+	}
+	run.Error = errStr.String
+	return run, nil
+}
+
 // scanSchedule scans a single schedule from a row
 func (r *ScheduleRepository) scanSchedule(row *sql.Row) (*entity.Schedule, error) {
 	schedule := &entity.Schedule{}
 	var nextRunAt, lastRunAt sql.NullTime
-	var agentPaw, description, cronExpr, lastRunID sql.NullString
+	var agentPaw, description, cronExpr, lastRunID, agentSelectorID, timezone, misfirePolicy, dependsOn, chainCondition, alerting sql.NullString
+	var chainThreshold sql.NullFloat64
 
 	err := row.Scan(
 		&schedule.ID,
@@ -265,6 +336,13 @@ func (r *ScheduleRepository) scanSchedule(row *sql.Row) (*entity.Schedule, error
 		&nextRunAt,
 		&lastRunAt,
 		&lastRunID,
+		&agentSelectorID,
+		&timezone,
+		&misfirePolicy,
+		&dependsOn,
+		&chainCondition,
+		&chainThreshold,
+		&alerting,
 		&schedule.CreatedBy,
 		&schedule.CreatedAt,
 		&schedule.UpdatedAt,
@@ -291,10 +369,43 @@ func (r *ScheduleRepository) scanSchedule(row *sql.Row) (*entity.Schedule, error
 	if lastRunID.Valid {
 		schedule.LastRunID = lastRunID.String
 	}
+	schedule.AgentSelectorID = agentSelectorID.String
+	schedule.Timezone = timezone.String
+	schedule.MisfirePolicy = entity.MisfirePolicy(misfirePolicy.String)
+	schedule.DependsOn = dependsOn.String
+	schedule.ChainCondition = entity.ChainCondition(chainCondition.String)
+	schedule.ChainThreshold = chainThreshold.Float64
+	if schedule.Alerting, err = unmarshalScheduleAlerting(alerting); err != nil {
+		return nil, err
+	}
 
 	return schedule, nil
 }
 
+// marshalScheduleAlerting encodes the alerting settings of a schedule, storing nil as NULL
+func marshalScheduleAlerting(alerting *entity.ScheduleAlerting) (sql.NullString, error) {
+	if alerting == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(alerting)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// unmarshalScheduleAlerting decodes the alerting settings of a schedule, nil when unset
+func unmarshalScheduleAlerting(data sql.NullString) (*entity.ScheduleAlerting, error) {
+	if !data.Valid || data.String == "" {
+		return nil, nil
+	}
+	alerting := &entity.ScheduleAlerting{}
+	if err := json.Unmarshal([]byte(data.String), alerting); err != nil {
+		return nil, err
+	}
+	return alerting, nil
+}
+
 // applyNullableFields applies nullable field values to a schedule
 func applyNullableFields(schedule *entity.Schedule, description, agentPaw, cronExpr, lastRunID sql.NullString, nextRunAt, lastRunAt sql.NullTime) {
 	if description.Valid {
@@ -323,7 +434,8 @@ func (r *ScheduleRepository) scanSchedules(rows *sql.Rows) ([]*entity.Schedule,
 	for rows.Next() {
 		schedule := &entity.Schedule{}
 		var nextRunAt, lastRunAt sql.NullTime
-		var agentPaw, description, cronExpr, lastRunID sql.NullString
+		var agentPaw, description, cronExpr, lastRunID, agentSelectorID, timezone, misfirePolicy, dependsOn, chainCondition, alerting sql.NullString
+		var chainThreshold sql.NullFloat64
 
 		err := rows.Scan(
 			&schedule.ID,
@@ -338,6 +450,13 @@ func (r *ScheduleRepository) scanSchedules(rows *sql.Rows) ([]*entity.Schedule,
 			&nextRunAt,
 			&lastRunAt,
 			&lastRunID,
+			&agentSelectorID,
+			&timezone,
+			&misfirePolicy,
+			&dependsOn,
+			&chainCondition,
+			&chainThreshold,
+			&alerting,
 			&schedule.CreatedBy,
 			&schedule.CreatedAt,
 			&schedule.UpdatedAt,
@@ -347,6 +466,15 @@ func (r *ScheduleRepository) scanSchedules(rows *sql.Rows) ([]*entity.Schedule,
 		}
 
 		applyNullableFields(schedule, description, agentPaw, cronExpr, lastRunID, nextRunAt, lastRunAt)
+		schedule.AgentSelectorID = agentSelectorID.String
+		schedule.Timezone = timezone.String
+		schedule.MisfirePolicy = entity.MisfirePolicy(misfirePolicy.String)
+		schedule.DependsOn = dependsOn.String
+		schedule.ChainCondition = entity.ChainCondition(chainCondition.String)
+		schedule.ChainThreshold = chainThreshold.Float64
+		if schedule.Alerting, err = unmarshalScheduleAlerting(alerting); err != nil {
+			return nil, err
+		}
 		schedules = append(schedules, schedule)
 	}
 	if err := rows.Err(); err != nil {
@@ -1,11 +1,20 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+
+	"autostrike/internal/domain/entity"
 )
 
+// Ping verifies the database answers a query
+func Ping(ctx context.Context, db *sql.DB) error {
+	var one int
+	return db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
 // InitSchema initializes the database schema
 func InitSchema(db *sql.DB) error {
 	// Enable foreign key enforcement (disabled by default in SQLite)
@@ -23,7 +32,14 @@ func InitSchema(db *sql.DB) error {
 		executors TEXT NOT NULL,
 		status TEXT NOT NULL,
 		last_seen DATETIME NOT NULL,
-		created_at DATETIME NOT NULL
+		created_at DATETIME NOT NULL,
+		version TEXT,
+		os_version TEXT,
+		architecture TEXT,
+		elevated BOOLEAN NOT NULL DEFAULT 0,
+		domain TEXT,
+		security_products TEXT,
+		interpreters TEXT
 	);
 
 	-- Techniques table
@@ -35,8 +51,17 @@ func InitSchema(db *sql.DB) error {
 		platforms TEXT NOT NULL,
 		executors TEXT NOT NULL,
 		detection TEXT,
+		sigma_rules TEXT,
 		is_safe BOOLEAN DEFAULT 1,
-		created_at DATETIME NOT NULL
+		status TEXT,
+		parent_id TEXT,
+		tactics TEXT,
+		metadata TEXT,
+		severity TEXT,
+		impact REAL,
+		domain TEXT,
+		created_at DATETIME NOT NULL,
+		deleted_at DATETIME
 	);
 
 	-- Scenarios table
@@ -46,8 +71,11 @@ func InitSchema(db *sql.DB) error {
 		description TEXT,
 		phases TEXT NOT NULL,
 		tags TEXT,
+		is_template BOOLEAN NOT NULL DEFAULT 0,
+		cloned_from TEXT,
 		created_at DATETIME NOT NULL,
-		updated_at DATETIME NOT NULL
+		updated_at DATETIME NOT NULL,
+		deleted_at DATETIME
 	);
 
 	-- Executions table
@@ -63,6 +91,8 @@ func InitSchema(db *sql.DB) error {
 		score_detected INTEGER DEFAULT 0,
 		score_successful INTEGER DEFAULT 0,
 		score_total INTEGER DEFAULT 0,
+		scoring_profile_id TEXT,
+		scoring_profile_version INTEGER,
 		FOREIGN KEY (scenario_id) REFERENCES scenarios(id)
 	);
 
@@ -76,8 +106,15 @@ func InitSchema(db *sql.DB) error {
 		output TEXT,
 		exit_code INTEGER DEFAULT 0,
 		detected BOOLEAN DEFAULT 0,
+		detected_by TEXT,
 		started_at DATETIME NOT NULL,
 		completed_at DATETIME,
+		attempt INTEGER NOT NULL DEFAULT 1,
+		phase TEXT,
+		output_ref TEXT,
+		output_size INTEGER NOT NULL DEFAULT 0,
+		output_truncated BOOLEAN NOT NULL DEFAULT 0,
+		executor TEXT,
 		FOREIGN KEY (execution_id) REFERENCES executions(id),
 		FOREIGN KEY (technique_id) REFERENCES techniques(id),
 		FOREIGN KEY (agent_paw) REFERENCES agents(paw)
@@ -102,23 +139,28 @@ func InitSchema(db *sql.DB) error {
 		is_active BOOLEAN NOT NULL DEFAULT 1,
 		last_login_at DATETIME,
 		created_at DATETIME NOT NULL,
-		updated_at DATETIME NOT NULL
+		updated_at DATETIME NOT NULL,
+		display_name TEXT NOT NULL DEFAULT '',
+		timezone TEXT NOT NULL DEFAULT '',
+		preferences TEXT,
+		workspace TEXT NOT NULL DEFAULT ''
 	);
 
 	-- Notification settings table
 	CREATE TABLE IF NOT EXISTS notification_settings (
 		id TEXT PRIMARY KEY,
 		user_id TEXT NOT NULL UNIQUE,
-		channel TEXT NOT NULL DEFAULT 'email',
 		enabled BOOLEAN NOT NULL DEFAULT 0,
 		email_address TEXT,
 		webhook_url TEXT,
-		notify_on_start BOOLEAN DEFAULT 0,
-		notify_on_complete BOOLEAN DEFAULT 1,
-		notify_on_failure BOOLEAN DEFAULT 1,
-		notify_on_score_alert BOOLEAN DEFAULT 1,
+		teams_webhook_url TEXT,
+		webhook_secret TEXT,
+		pagerduty_routing_key TEXT,
+		opsgenie_api_key TEXT,
+		preferences TEXT,
 		score_alert_threshold REAL DEFAULT 50.0,
-		notify_on_agent_offline BOOLEAN DEFAULT 0,
+		quiet_hours TEXT,
+		digest TEXT,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
 		FOREIGN KEY (user_id) REFERENCES users(id)
@@ -135,6 +177,26 @@ func InitSchema(db *sql.DB) error {
 		read BOOLEAN DEFAULT 0,
 		sent_at DATETIME,
 		created_at DATETIME NOT NULL,
+		digest_pending BOOLEAN NOT NULL DEFAULT 0,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+
+	-- Webhook deliveries table (retry queue and delivery log of outgoing webhooks)
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		channel TEXT NOT NULL,
+		event TEXT NOT NULL,
+		url TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_status_code INTEGER,
+		last_error TEXT,
+		next_attempt_at DATETIME,
+		delivered_at DATETIME,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
 		FOREIGN KEY (user_id) REFERENCES users(id)
 	);
 
@@ -152,6 +214,13 @@ func InitSchema(db *sql.DB) error {
 		next_run_at DATETIME,
 		last_run_at DATETIME,
 		last_run_id TEXT,
+		agent_selector_id TEXT,
+		timezone TEXT,
+		misfire_policy TEXT,
+		depends_on TEXT,
+		chain_condition TEXT,
+		chain_threshold REAL DEFAULT 0,
+		alerting TEXT,
 		created_by TEXT NOT NULL,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
@@ -172,6 +241,387 @@ func InitSchema(db *sql.DB) error {
 		FOREIGN KEY (execution_id) REFERENCES executions(id)
 	);
 
+	-- Agent selectors table (saved agent targeting expressions)
+	CREATE TABLE IF NOT EXISTS agent_selectors (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT,
+		criteria TEXT NOT NULL,
+		created_by TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Scoring profiles table (current version and default flag of each scoring model)
+	CREATE TABLE IF NOT EXISTS scoring_profiles (
+		id TEXT PRIMARY KEY,
+		current_version INTEGER NOT NULL,
+		is_default BOOLEAN NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		deleted_at DATETIME
+	);
+
+	-- Scoring profile versions table (immutable, executions reference the version they are scored with)
+	CREATE TABLE IF NOT EXISTS scoring_profile_versions (
+		profile_id TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		blocked_credit REAL NOT NULL,
+		detected_credit REAL NOT NULL,
+		tactic_weights TEXT,
+		severity_weights TEXT,
+		undetected_weights TEXT,
+		created_by TEXT,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY (profile_id, version),
+		FOREIGN KEY (profile_id) REFERENCES scoring_profiles(id)
+	);
+
+	-- Tickets table (tracker issues opened for undetected techniques, kept when executions are purged)
+	CREATE TABLE IF NOT EXISTS tickets (
+		id TEXT PRIMARY KEY,
+		provider TEXT NOT NULL,
+		external_key TEXT NOT NULL,
+		url TEXT,
+		technique_id TEXT NOT NULL,
+		agent_paw TEXT NOT NULL,
+		execution_id TEXT NOT NULL,
+		result_id TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		external_status TEXT,
+		resolution TEXT,
+		occurrences INTEGER NOT NULL DEFAULT 1,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		resolved_at DATETIME
+	);
+
+	-- Payloads table (binaries and scripts delivered to agents, content stored inline)
+	CREATE TABLE IF NOT EXISTS payloads (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		description TEXT,
+		sha256 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		content BLOB NOT NULL,
+		uploaded_by TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Login history table (baseline for login anomaly detection)
+	CREATE TABLE IF NOT EXISTS login_history (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		ip_address TEXT NOT NULL,
+		country TEXT,
+		user_agent TEXT,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+
+	-- Failed logins per username and per source IP, for lockouts
+	CREATE TABLE IF NOT EXISTS login_throttles (
+		scope TEXT NOT NULL,
+		key TEXT NOT NULL,
+		failures INTEGER NOT NULL DEFAULT 0,
+		lockouts INTEGER NOT NULL DEFAULT 0,
+		locked_until DATETIME,
+		last_failure_at DATETIME NOT NULL,
+		PRIMARY KEY (scope, key)
+	);
+
+	-- Password reset tokens emailed to users, stored hashed
+	CREATE TABLE IF NOT EXISTS password_reset_tokens (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		request_ip TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		used_at DATETIME,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+
+	-- Activity anomalies table (audit trail of unusual operator activity)
+	CREATE TABLE IF NOT EXISTS activity_anomalies (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		severity TEXT NOT NULL,
+		user_id TEXT,
+		username TEXT,
+		message TEXT NOT NULL,
+		data TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	-- Score recomputations table (scores overwritten by backfills and rescores, earliest row holds the original)
+	CREATE TABLE IF NOT EXISTS score_recomputations (
+		id TEXT PRIMARY KEY,
+		execution_id TEXT NOT NULL,
+		job_id TEXT NOT NULL,
+		previous_overall REAL DEFAULT 0,
+		previous_blocked INTEGER DEFAULT 0,
+		previous_detected INTEGER DEFAULT 0,
+		previous_successful INTEGER DEFAULT 0,
+		previous_total INTEGER DEFAULT 0,
+		new_overall REAL DEFAULT 0,
+		new_blocked INTEGER DEFAULT 0,
+		new_detected INTEGER DEFAULT 0,
+		new_successful INTEGER DEFAULT 0,
+		new_total INTEGER DEFAULT 0,
+		previous_scoring_profile_id TEXT,
+		previous_scoring_profile_version INTEGER,
+		scoring_profile_id TEXT,
+		scoring_profile_version INTEGER,
+		recomputed_by TEXT,
+		recomputed_at DATETIME NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Execution facts table (values extracted from technique output)
+	CREATE TABLE IF NOT EXISTS execution_facts (
+		id TEXT PRIMARY KEY,
+		execution_id TEXT NOT NULL,
+		agent_paw TEXT NOT NULL,
+		result_id TEXT NOT NULL,
+		technique_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Result artifacts table (files collected by agents, purged after the retention period)
+	CREATE TABLE IF NOT EXISTS result_artifacts (
+		id TEXT PRIMARY KEY,
+		result_id TEXT NOT NULL,
+		execution_id TEXT NOT NULL,
+		agent_paw TEXT NOT NULL,
+		name TEXT NOT NULL,
+		path TEXT,
+		content_type TEXT,
+		sha256 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		content BLOB NOT NULL,
+		storage_key TEXT,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Ad-hoc tasks table (audit trail of one-off commands run on agents by administrators)
+	CREATE TABLE IF NOT EXISTS adhoc_tasks (
+		id TEXT PRIMARY KEY,
+		agent_paw TEXT NOT NULL,
+		command TEXT NOT NULL,
+		executor TEXT NOT NULL,
+		timeout INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		output TEXT,
+		exit_code INTEGER NOT NULL DEFAULT 0,
+		requested_by TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		completed_at DATETIME
+	);
+
+	-- Agent releases table (signed agent binaries offered to out-of-date agents)
+	CREATE TABLE IF NOT EXISTS agent_releases (
+		id TEXT PRIMARY KEY,
+		version TEXT NOT NULL,
+		platform TEXT NOT NULL,
+		sha256 TEXT NOT NULL,
+		signature TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		content BLOB NOT NULL,
+		rollout_percent INTEGER NOT NULL DEFAULT 0,
+		uploaded_by TEXT,
+		created_at DATETIME NOT NULL,
+		UNIQUE (version, platform)
+	);
+
+	-- Beacon overrides table (check-in interval and jitter of an agent or an agent selector)
+	CREATE TABLE IF NOT EXISTS beacon_overrides (
+		scope TEXT NOT NULL,
+		target TEXT NOT NULL,
+		interval_seconds INTEGER NOT NULL,
+		jitter_percent INTEGER NOT NULL DEFAULT 0,
+		updated_by TEXT,
+		updated_at DATETIME NOT NULL,
+		PRIMARY KEY (scope, target)
+	);
+
+	-- Task queue table (tasks dispatched to offline agents, delivered when they check in)
+	CREATE TABLE IF NOT EXISTS task_queue (
+		result_id TEXT PRIMARY KEY,
+		execution_id TEXT NOT NULL,
+		agent_paw TEXT NOT NULL,
+		task TEXT NOT NULL,
+		queued_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Emergency stops table (kill switch trips, engaged until rearmed_at is set)
+	CREATE TABLE IF NOT EXISTS emergency_stops (
+		id TEXT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		automatic BOOLEAN NOT NULL DEFAULT 0,
+		tripped_by TEXT,
+		tripped_at DATETIME NOT NULL,
+		cancelled_executions TEXT NOT NULL DEFAULT '[]',
+		paused_schedules TEXT NOT NULL DEFAULT '[]',
+		agents_notified INTEGER NOT NULL DEFAULT 0,
+		rearmed_by TEXT,
+		rearmed_at DATETIME
+	);
+
+	-- SMTP configuration set through the API, overriding the environment
+	CREATE TABLE IF NOT EXISTS smtp_config (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		host TEXT NOT NULL,
+		port INTEGER NOT NULL,
+		username TEXT,
+		password_encrypted TEXT,
+		from_address TEXT NOT NULL,
+		use_tls BOOLEAN NOT NULL DEFAULT 0,
+		updated_by TEXT,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Execution reviews table (purple-team review lifecycle, export frozen at sign-off)
+	CREATE TABLE IF NOT EXISTS execution_reviews (
+		execution_id TEXT PRIMARY KEY,
+		status TEXT NOT NULL,
+		reviewer_id TEXT,
+		requested_by TEXT,
+		requested_at DATETIME NOT NULL,
+		started_at DATETIME,
+		signed_off_by TEXT,
+		signed_off_at DATETIME,
+		notes TEXT,
+		snapshot TEXT,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Execution snapshots table (server settings, scenario, techniques and agents at launch)
+	CREATE TABLE IF NOT EXISTS execution_snapshots (
+		execution_id TEXT PRIMARY KEY,
+		captured_at DATETIME NOT NULL,
+		snapshot TEXT NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Result evidence table (files attached by operators, deleted with their execution)
+	CREATE TABLE IF NOT EXISTS result_evidence (
+		id TEXT PRIMARY KEY,
+		result_id TEXT NOT NULL,
+		execution_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		description TEXT,
+		content_type TEXT NOT NULL,
+		sha256 TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		content BLOB NOT NULL,
+		storage_key TEXT,
+		uploaded_by TEXT,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Result annotations table (analyst triage verdict and assignee, one per result)
+	CREATE TABLE IF NOT EXISTS result_annotations (
+		result_id TEXT PRIMARY KEY,
+		execution_id TEXT NOT NULL,
+		triage TEXT,
+		assignee_id TEXT,
+		updated_by TEXT,
+		updated_at DATETIME NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- Result comments table (analyst discussion of results)
+	CREATE TABLE IF NOT EXISTS result_comments (
+		id TEXT PRIMARY KEY,
+		result_id TEXT NOT NULL,
+		execution_id TEXT NOT NULL,
+		author_id TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (execution_id) REFERENCES executions(id)
+	);
+
+	-- User API keys, stored hashed
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT UNIQUE NOT NULL,
+		created_at DATETIME NOT NULL,
+		expires_at DATETIME,
+		last_used_at DATETIME,
+		FOREIGN KEY (user_id) REFERENCES users(id)
+	);
+
+	-- Secrets store, each value sealed with a data key wrapped with the master key
+	CREATE TABLE IF NOT EXISTS secrets (
+		name TEXT PRIMARY KEY,
+		description TEXT,
+		key_id TEXT NOT NULL,
+		data_key BLOB NOT NULL,
+		ciphertext BLOB NOT NULL,
+		updated_by TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+
+	-- Background jobs table (imports, exports, retention runs, catalog syncs)
+	CREATE TABLE IF NOT EXISTS jobs (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		status TEXT NOT NULL,
+		progress INTEGER NOT NULL DEFAULT 0,
+		total INTEGER NOT NULL DEFAULT 0,
+		message TEXT,
+		result TEXT,
+		error TEXT,
+		created_by TEXT,
+		created_at DATETIME NOT NULL,
+		started_at DATETIME,
+		completed_at DATETIME
+	);
+
+	-- Agent availability table (agents going online or offline, kept for the availability history)
+	CREATE TABLE IF NOT EXISTS agent_availability (
+		id TEXT PRIMARY KEY,
+		agent_paw TEXT NOT NULL,
+		status TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		suppressed BOOLEAN NOT NULL DEFAULT 0,
+		occurred_at DATETIME NOT NULL
+	);
+
+	-- Agent beacons table (check-ins of agents, kept for their beacon health)
+	CREATE TABLE IF NOT EXISTS agent_beacons (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		agent_paw TEXT NOT NULL,
+		received_at DATETIME NOT NULL,
+		interval_seconds INTEGER NOT NULL,
+		jitter INTEGER NOT NULL DEFAULT 0,
+		gap_ms INTEGER,
+		latency_ms INTEGER,
+		missed INTEGER NOT NULL DEFAULT 0
+	);
+
+	-- Result submissions table (idempotency keys of the results agents submitted, to drop their retries)
+	CREATE TABLE IF NOT EXISTS result_submissions (
+		agent_paw TEXT NOT NULL,
+		submission_id TEXT NOT NULL,
+		result_id TEXT NOT NULL,
+		received_at DATETIME NOT NULL,
+		PRIMARY KEY (agent_paw, submission_id)
+	);
+
 	-- Indexes
 	CREATE INDEX IF NOT EXISTS idx_agents_status ON agents(status);
 	CREATE INDEX IF NOT EXISTS idx_agents_platform ON agents(platform);
@@ -184,10 +634,37 @@ func InitSchema(db *sql.DB) error {
 	CREATE INDEX IF NOT EXISTS idx_notification_settings_user ON notification_settings(user_id);
 	CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id);
 	CREATE INDEX IF NOT EXISTS idx_notifications_read ON notifications(read);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_user ON webhook_deliveries(user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
 	CREATE INDEX IF NOT EXISTS idx_schedules_status ON schedules(status);
 	CREATE INDEX IF NOT EXISTS idx_schedules_scenario ON schedules(scenario_id);
 	CREATE INDEX IF NOT EXISTS idx_schedules_next_run ON schedules(next_run_at);
 	CREATE INDEX IF NOT EXISTS idx_schedule_runs_schedule ON schedule_runs(schedule_id);
+	CREATE INDEX IF NOT EXISTS idx_login_history_user ON login_history(user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens(user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_activity_anomalies_created ON activity_anomalies(created_at);
+	CREATE INDEX IF NOT EXISTS idx_score_recomputations_execution ON score_recomputations(execution_id, recomputed_at);
+	CREATE INDEX IF NOT EXISTS idx_execution_facts_execution ON execution_facts(execution_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_result_artifacts_result ON result_artifacts(result_id);
+	CREATE INDEX IF NOT EXISTS idx_result_artifacts_created ON result_artifacts(created_at);
+	CREATE INDEX IF NOT EXISTS idx_adhoc_tasks_agent ON adhoc_tasks(agent_paw, created_at);
+	CREATE INDEX IF NOT EXISTS idx_agent_releases_platform ON agent_releases(platform);
+	CREATE INDEX IF NOT EXISTS idx_task_queue_agent ON task_queue(agent_paw, queued_at);
+	CREATE INDEX IF NOT EXISTS idx_task_queue_expires ON task_queue(expires_at);
+	CREATE INDEX IF NOT EXISTS idx_tickets_open ON tickets(status, technique_id, agent_paw);
+	CREATE INDEX IF NOT EXISTS idx_tickets_created ON tickets(created_at);
+	CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys(user_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_execution_reviews_status ON execution_reviews(status, reviewer_id);
+	CREATE INDEX IF NOT EXISTS idx_result_evidence_result ON result_evidence(result_id);
+	CREATE INDEX IF NOT EXISTS idx_result_annotations_execution ON result_annotations(execution_id);
+	CREATE INDEX IF NOT EXISTS idx_result_comments_result ON result_comments(result_id, created_at);
+	CREATE INDEX IF NOT EXISTS idx_result_comments_execution ON result_comments(execution_id);
+	CREATE INDEX IF NOT EXISTS idx_jobs_created ON jobs(created_at);
+	CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status);
+	CREATE INDEX IF NOT EXISTS idx_agent_availability_agent ON agent_availability(agent_paw, occurred_at);
+	CREATE INDEX IF NOT EXISTS idx_agent_beacons_agent ON agent_beacons(agent_paw, received_at);
+	CREATE INDEX IF NOT EXISTS idx_agent_beacons_received ON agent_beacons(received_at);
+	CREATE INDEX IF NOT EXISTS idx_result_submissions_received ON result_submissions(received_at);
 	`
 
 	_, err := db.Exec(schema)
@@ -209,37 +686,343 @@ func Migrate(db *sql.DB) error {
 		return fmt.Errorf("failed to add last_login_at column: %w", err)
 	}
 
+	// Migration: Add detected_by column to execution_results table
+	if err := addColumnIfNotExists(db, "execution_results", "detected_by", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add detected_by column: %w", err)
+	}
+
+	// Migration: Add sigma_rules column to techniques table
+	if err := addColumnIfNotExists(db, "techniques", "sigma_rules", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add sigma_rules column: %w", err)
+	}
+
+	// Migration: Add status column to techniques table (NULL means active)
+	if err := addColumnIfNotExists(db, "techniques", "status", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add status column: %w", err)
+	}
+
+	// Migration: Add parent_id column to techniques table, backfilled once the
+	// metadata column exists
+	if err := addColumnIfNotExists(db, "techniques", "parent_id", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add parent_id column: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_techniques_parent ON techniques(parent_id)`); err != nil {
+		return fmt.Errorf("failed to create technique parent index: %w", err)
+	}
+
+	// Migration: Add tactics column to techniques table, listing the single tactic of existing rows
+	if err := addColumnIfNotExists(db, "techniques", "tactics", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add tactics column: %w", err)
+	}
+	if _, err := db.Exec(`UPDATE techniques SET tactics = '["' || tactic || '"]' WHERE tactics IS NULL`); err != nil {
+		return fmt.Errorf("failed to backfill technique tactics: %w", err)
+	}
+
+	// Migration: Add metadata column to techniques table for organization custom fields
+	if err := addColumnIfNotExists(db, "techniques", "metadata", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add metadata column: %w", err)
+	}
+
+	// Migration: Derive the parent of the sub-techniques of the YAML catalog
+	// from their IDs. Imported techniques have an import_source and take their
+	// parent from their content.
+	if _, err := db.Exec(`UPDATE techniques SET parent_id = substr(id, 1, 5)
+		WHERE parent_id IS NULL AND id GLOB 'T[0-9][0-9][0-9][0-9].[0-9][0-9][0-9]'
+		AND json_extract(COALESCE(metadata, '{}'), '$.import_source') IS NULL`); err != nil {
+		return fmt.Errorf("failed to backfill technique parents: %w", err)
+	}
+
+	// Migration: Replace single-channel notification flags with a preference matrix
+	if err := addColumnIfNotExists(db, "notification_settings", "preferences", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add preferences column: %w", err)
+	}
+	if err := migrateNotificationPreferences(db); err != nil {
+		return fmt.Errorf("failed to migrate notification preferences: %w", err)
+	}
+
+	// Migration: Add teams_webhook_url column to notification_settings table
+	if err := addColumnIfNotExists(db, "notification_settings", "teams_webhook_url", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add teams_webhook_url column: %w", err)
+	}
+
+	// Migration: Add agent_selector_id column to schedules table
+	if err := addColumnIfNotExists(db, "schedules", "agent_selector_id", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add agent_selector_id column: %w", err)
+	}
+
+	// Migration: Add webhook_secret column to notification_settings table
+	if err := addColumnIfNotExists(db, "notification_settings", "webhook_secret", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add webhook_secret column: %w", err)
+	}
+
+	// Migration: Key execution results on (execution, technique, agent, attempt)
+	if err := addColumnIfNotExists(db, "execution_results", "attempt", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return fmt.Errorf("failed to add attempt column: %w", err)
+	}
+	if err := migrateResultAttempts(db); err != nil {
+		return fmt.Errorf("failed to migrate result attempts: %w", err)
+	}
+
+	// Migration: Add phase column to execution_results table
+	if err := addColumnIfNotExists(db, "execution_results", "phase", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add phase column: %w", err)
+	}
+
+	// Migration: Add is_template and cloned_from columns to scenarios table
+	if err := addColumnIfNotExists(db, "scenarios", "is_template", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("failed to add is_template column: %w", err)
+	}
+	if err := addColumnIfNotExists(db, "scenarios", "cloned_from", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add cloned_from column: %w", err)
+	}
+
+	// Migration: Add version column to agents table
+	if err := addColumnIfNotExists(db, "agents", "version", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add agent version column: %w", err)
+	}
+
+	// Migration: Add host inventory columns to agents table
+	for _, col := range []struct{ name, def string }{
+		{"os_version", "TEXT"},
+		{"architecture", "TEXT"},
+		{"elevated", "BOOLEAN NOT NULL DEFAULT 0"},
+		{"domain", "TEXT"},
+		{"security_products", "TEXT"},
+		{"interpreters", "TEXT"},
+	} {
+		if err := addColumnIfNotExists(db, "agents", col.name, col.def); err != nil {
+			return fmt.Errorf("failed to add agent %s column: %w", col.name, err)
+		}
+	}
+
+	// Migration: Add source address and allowed networks columns to agents table
+	for _, col := range []string{"ip_address", "allowed_networks"} {
+		if err := addColumnIfNotExists(db, "agents", col, "TEXT"); err != nil {
+			return fmt.Errorf("failed to add agent %s column: %w", col, err)
+		}
+	}
+
+	// Migration: Add the metadata column of agents, the tags selectors match
+	if err := addColumnIfNotExists(db, "agents", "metadata", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add agent metadata column: %w", err)
+	}
+
+	// Migration: Add the profile columns of users
+	for _, col := range []string{"display_name", "timezone"} {
+		if err := addColumnIfNotExists(db, "users", col, "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return fmt.Errorf("failed to add user %s column: %w", col, err)
+		}
+	}
+	if err := addColumnIfNotExists(db, "users", "preferences", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add user preferences column: %w", err)
+	}
+
+	// Migration: Add the workspace column of users, execution quotas count per workspace
+	if err := addColumnIfNotExists(db, "users", "workspace", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("failed to add user workspace column: %w", err)
+	}
+
+	// Migration: Add timezone column to schedules table
+	if err := addColumnIfNotExists(db, "schedules", "timezone", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add schedule timezone column: %w", err)
+	}
+
+	// Migration: Add misfire_policy column to schedules table
+	if err := addColumnIfNotExists(db, "schedules", "misfire_policy", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add schedule misfire_policy column: %w", err)
+	}
+
+	// Migration: Add chaining columns to schedules table
+	for _, col := range []struct{ name, def string }{
+		{"depends_on", "TEXT"},
+		{"chain_condition", "TEXT"},
+		{"chain_threshold", "REAL DEFAULT 0"},
+	} {
+		if err := addColumnIfNotExists(db, "schedules", col.name, col.def); err != nil {
+			return fmt.Errorf("failed to add schedule %s column: %w", col.name, err)
+		}
+	}
+
+	// Migration: Add soft deletion to scenarios and techniques
+	for _, table := range []string{"scenarios", "techniques"} {
+		if err := addColumnIfNotExists(db, table, "deleted_at", "DATETIME"); err != nil {
+			return fmt.Errorf("failed to add %s deleted_at column: %w", table, err)
+		}
+	}
+
+	// Migration: Add the scoring profile version executions are scored with
+	if err := addColumnIfNotExists(db, "executions", "scoring_profile_id", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add execution scoring_profile_id column: %w", err)
+	}
+	if err := addColumnIfNotExists(db, "executions", "scoring_profile_version", "INTEGER"); err != nil {
+		return fmt.Errorf("failed to add execution scoring_profile_version column: %w", err)
+	}
+
+	// Migration: Add the reason an execution failed, e.g. stuck running
+	if err := addColumnIfNotExists(db, "executions", "failure_reason", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add execution failure_reason column: %w", err)
+	}
+
+	// Migration: Add the incident channels and the per-schedule alerting
+	for _, col := range []string{"pagerduty_routing_key", "opsgenie_api_key"} {
+		if err := addColumnIfNotExists(db, "notification_settings", col, "TEXT"); err != nil {
+			return fmt.Errorf("failed to add notification %s column: %w", col, err)
+		}
+	}
+	if err := addColumnIfNotExists(db, "schedules", "alerting", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add schedule alerting column: %w", err)
+	}
+
+	// Migration: Add the blob references of outputs and artifacts kept in object storage
+	if err := addColumnIfNotExists(db, "execution_results", "output_ref", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add result output_ref column: %w", err)
+	}
+	if err := addColumnIfNotExists(db, "execution_results", "output_size", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("failed to add result output_size column: %w", err)
+	}
+	if err := addColumnIfNotExists(db, "result_artifacts", "storage_key", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add artifact storage_key column: %w", err)
+	}
+
+	// Migration: Flag the outputs truncated to the maximum output size
+	if err := addColumnIfNotExists(db, "execution_results", "output_truncated", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("failed to add result output_truncated column: %w", err)
+	}
+
+	// Migration: Add the quiet hours and digest of notification settings
+	for _, col := range []string{"quiet_hours", "digest"} {
+		if err := addColumnIfNotExists(db, "notification_settings", col, "TEXT"); err != nil {
+			return fmt.Errorf("failed to add notification %s column: %w", col, err)
+		}
+	}
+	if err := addColumnIfNotExists(db, "notifications", "digest_pending", "BOOLEAN NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("failed to add notification digest_pending column: %w", err)
+	}
+
+	// Migration: Record the scoring profile versions and user of score recomputations
+	for col, definition := range map[string]string{
+		"previous_scoring_profile_id":      "TEXT",
+		"previous_scoring_profile_version": "INTEGER",
+		"scoring_profile_id":               "TEXT",
+		"scoring_profile_version":          "INTEGER",
+		"recomputed_by":                    "TEXT",
+	} {
+		if err := addColumnIfNotExists(db, "score_recomputations", col, definition); err != nil {
+			return fmt.Errorf("failed to add score recomputation %s column: %w", col, err)
+		}
+	}
+
+	// Migration: Add the severity and impact of techniques, assessed when read
+	// until they are saved, and the undetected weights of scoring profiles
+	if err := addColumnIfNotExists(db, "techniques", "severity", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add technique severity column: %w", err)
+	}
+	if err := addColumnIfNotExists(db, "techniques", "impact", "REAL"); err != nil {
+		return fmt.Errorf("failed to add technique impact column: %w", err)
+	}
+	if err := addColumnIfNotExists(db, "scoring_profile_versions", "undetected_weights", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add scoring profile undetected_weights column: %w", err)
+	}
+
+	// Migration: Add the executor results were planned with
+	if err := addColumnIfNotExists(db, "execution_results", "executor", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add result executor column: %w", err)
+	}
+
+	// Migration: Add the ATT&CK domain of techniques (NULL means enterprise-attack)
+	if err := addColumnIfNotExists(db, "techniques", "domain", "TEXT"); err != nil {
+		return fmt.Errorf("failed to add technique domain column: %w", err)
+	}
+
+	// Migration: Add the full-text search index, filled from the existing rows
+	if err := createSearchIndex(db); err != nil {
+		return fmt.Errorf("failed to create search index: %w", err)
+	}
+
 	return nil
 }
 
-// addColumnIfNotExists adds a column to a table if it doesn't already exist
-func addColumnIfNotExists(db *sql.DB, table, column, definition string) error {
-	// Check if column exists
-	query := fmt.Sprintf("PRAGMA table_info(%s)", table)
-	rows, err := db.Query(query)
+// migrateResultAttempts numbers the repeated runs of a technique on an agent
+// within an execution, then enforces the result key with a unique index
+func migrateResultAttempts(db *sql.DB) error {
+	_, err := db.Exec(`
+		UPDATE execution_results SET attempt = 1 + (
+			SELECT COUNT(*) FROM execution_results prev
+			WHERE prev.execution_id = execution_results.execution_id
+			AND prev.technique_id = execution_results.technique_id
+			AND prev.agent_paw = execution_results.agent_paw
+			AND (prev.started_at < execution_results.started_at
+				OR (prev.started_at = execution_results.started_at AND prev.id < execution_results.id))
+		)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM sqlite_master WHERE type = 'index' AND name = 'idx_execution_results_key'
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_execution_results_key
+		ON execution_results(execution_id, technique_id, agent_paw, attempt)`)
+	return err
+}
+
+// migrateNotificationPreferences fills the preference matrix of settings rows
+// created before it existed, from their legacy channel and notify_on_* columns
+func migrateNotificationPreferences(db *sql.DB) error {
+	legacy, err := columnExists(db, "notification_settings", "notify_on_start")
+	if err != nil || !legacy {
+		return err
+	}
+
+	rows, err := db.Query(`
+		SELECT id, channel, notify_on_start, notify_on_complete, notify_on_failure,
+			notify_on_score_alert, notify_on_agent_offline
+		FROM notification_settings WHERE preferences IS NULL
+	`)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
 
-	var exists bool
+	migrated := make(map[string]entity.NotificationPreferences)
 	for rows.Next() {
-		var cid int
-		var name, colType string
-		var notNull, pk int
-		var dfltValue sql.NullString
-		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+		var id string
+		var channel sql.NullString
+		var onStart, onComplete, onFailure, onScoreAlert, onAgentOffline sql.NullBool
+		if err := rows.Scan(&id, &channel, &onStart, &onComplete, &onFailure, &onScoreAlert, &onAgentOffline); err != nil {
+			rows.Close()
 			return err
 		}
-		if strings.EqualFold(name, column) {
-			exists = true
-			break
-		}
+		migrated[id] = entity.LegacyNotificationPreferences(
+			entity.NotificationChannel(channel.String),
+			onStart.Bool, onComplete.Bool, onFailure.Bool, onScoreAlert.Bool, onAgentOffline.Bool,
+		)
 	}
+	rows.Close()
 	if err := rows.Err(); err != nil {
 		return err
 	}
 
+	for id, prefs := range migrated {
+		preferencesJSON, err := marshalPreferences(prefs)
+		if err != nil {
+			return err
+		}
+		if _, err := db.Exec(`UPDATE notification_settings SET preferences = ? WHERE id = ?`, preferencesJSON, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addColumnIfNotExists adds a column to a table if it doesn't already exist
+func addColumnIfNotExists(db *sql.DB, table, column, definition string) error {
+	exists, err := columnExists(db, table, column)
+	if err != nil {
+		return err
+	}
+
 	if !exists {
 		alterQuery := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)
 		_, err := db.Exec(alterQuery)
@@ -248,3 +1031,28 @@ func addColumnIfNotExists(db *sql.DB, table, column, definition string) error {
 
 	return nil
 }
+
+// columnExists checks if a table has a column
+func columnExists(db *sql.DB, table, column string) (bool, error) {
+	query := fmt.Sprintf("PRAGMA table_info(%s)", table)
+	rows, err := db.Query(query)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, colType string
+		var notNull, pk int
+		var dfltValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if strings.EqualFold(name, column) {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
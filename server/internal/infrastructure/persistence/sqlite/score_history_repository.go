@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+// ScoreHistoryRepository implements repository.ScoreHistoryRepository using SQLite
+type ScoreHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewScoreHistoryRepository creates a new SQLite score history repository
+func NewScoreHistoryRepository(db *sql.DB) *ScoreHistoryRepository {
+	return &ScoreHistoryRepository{db: db}
+}
+
+// Create records a score overwritten by a recomputation
+func (r *ScoreHistoryRepository) Create(ctx context.Context, rec *entity.ScoreRecomputation) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO score_recomputations (id, execution_id, job_id,
+		previous_overall, previous_blocked, previous_detected, previous_successful, previous_total,
+		new_overall, new_blocked, new_detected, new_successful, new_total,
+		previous_scoring_profile_id, previous_scoring_profile_version, scoring_profile_id, scoring_profile_version,
+		recomputed_by, recomputed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rec.ID, rec.ExecutionID, rec.JobID,
+		rec.PreviousScore.Overall, rec.PreviousScore.Blocked, rec.PreviousScore.Detected,
+		rec.PreviousScore.Successful, rec.PreviousScore.Total,
+		rec.NewScore.Overall, rec.NewScore.Blocked, rec.NewScore.Detected,
+		rec.NewScore.Successful, rec.NewScore.Total,
+		rec.PreviousScoringProfileID, rec.PreviousScoringProfileVersion, rec.ScoringProfileID, rec.ScoringProfileVersion,
+		rec.RecomputedBy, rec.RecomputedAt)
+
+	return err
+}
+
+// FindByExecution returns the recomputations of an execution, oldest first
+func (r *ScoreHistoryRepository) FindByExecution(ctx context.Context, executionID string) ([]*entity.ScoreRecomputation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, execution_id, job_id,
+		previous_overall, previous_blocked, previous_detected, previous_successful, previous_total,
+		new_overall, new_blocked, new_detected, new_successful, new_total,
+		COALESCE(previous_scoring_profile_id, ''), COALESCE(previous_scoring_profile_version, 0),
+		COALESCE(scoring_profile_id, ''), COALESCE(scoring_profile_version, 0),
+		COALESCE(recomputed_by, ''), recomputed_at
+		FROM score_recomputations WHERE execution_id = ?
+		ORDER BY recomputed_at ASC
+	`, executionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recomputations []*entity.ScoreRecomputation
+	for rows.Next() {
+		rec := &entity.ScoreRecomputation{}
+
+		err := rows.Scan(&rec.ID, &rec.ExecutionID, &rec.JobID,
+			&rec.PreviousScore.Overall, &rec.PreviousScore.Blocked, &rec.PreviousScore.Detected,
+			&rec.PreviousScore.Successful, &rec.PreviousScore.Total,
+			&rec.NewScore.Overall, &rec.NewScore.Blocked, &rec.NewScore.Detected,
+			&rec.NewScore.Successful, &rec.NewScore.Total,
+			&rec.PreviousScoringProfileID, &rec.PreviousScoringProfileVersion,
+			&rec.ScoringProfileID, &rec.ScoringProfileVersion,
+			&rec.RecomputedBy, &rec.RecomputedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		recomputations = append(recomputations, rec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return recomputations, nil
+}
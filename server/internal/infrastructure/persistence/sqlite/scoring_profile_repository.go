@@ -0,0 +1,223 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// ScoringProfileRepository implements repository.ScoringProfileRepository using SQLite
+type ScoringProfileRepository struct {
+	db *sql.DB
+}
+
+// NewScoringProfileRepository creates a new SQLite scoring profile repository
+func NewScoringProfileRepository(db *sql.DB) *ScoringProfileRepository {
+	return &ScoringProfileRepository{db: db}
+}
+
+// scoringProfileColumns selects scoring profile versions joined with their profile
+const scoringProfileColumns = `
+	SELECT p.id, v.version, v.name, v.description, v.blocked_credit, v.detected_credit,
+		v.tactic_weights, v.severity_weights, v.undetected_weights, p.is_default, v.created_by, v.created_at
+	FROM scoring_profiles p
+	JOIN scoring_profile_versions v ON v.profile_id = p.id`
+
+// Create inserts a new scoring profile with its first version
+func (r *ScoringProfileRepository) Create(ctx context.Context, profile *entity.ScoringProfile) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO scoring_profiles (id, current_version, is_default, created_at)
+		VALUES (?, ?, 0, ?)
+	`, profile.ID, profile.Version, profile.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := insertScoringProfileVersion(ctx, tx, profile); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddVersion inserts a new version of a scoring profile and makes it current
+func (r *ScoringProfileRepository) AddVersion(ctx context.Context, profile *entity.ScoringProfile) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := insertScoringProfileVersion(ctx, tx, profile); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE scoring_profiles SET current_version = ? WHERE id = ?", profile.Version, profile.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete hides a scoring profile; its versions are kept for the executions pinned to them
+func (r *ScoringProfileRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE scoring_profiles SET deleted_at = ?, is_default = 0 WHERE id = ?", time.Now(), id)
+	return err
+}
+
+// FindByID finds the current version of a scoring profile
+func (r *ScoringProfileRepository) FindByID(ctx context.Context, id string) (*entity.ScoringProfile, error) {
+	row := r.db.QueryRowContext(ctx, scoringProfileColumns+`
+		AND v.version = p.current_version
+		WHERE p.id = ? AND p.deleted_at IS NULL
+	`, id)
+
+	return scanScoringProfile(row)
+}
+
+// FindVersion finds a version of a scoring profile, deleted or not
+func (r *ScoringProfileRepository) FindVersion(ctx context.Context, id string, version int) (*entity.ScoringProfile, error) {
+	row := r.db.QueryRowContext(ctx, scoringProfileColumns+`
+		WHERE p.id = ? AND v.version = ?
+	`, id, version)
+
+	return scanScoringProfile(row)
+}
+
+// FindVersions returns every version of a scoring profile, oldest first
+func (r *ScoringProfileRepository) FindVersions(ctx context.Context, id string) ([]*entity.ScoringProfile, error) {
+	return r.query(ctx, scoringProfileColumns+`
+		WHERE p.id = ? ORDER BY v.version
+	`, id)
+}
+
+// FindAll returns the current version of every scoring profile ordered by name
+func (r *ScoringProfileRepository) FindAll(ctx context.Context) ([]*entity.ScoringProfile, error) {
+	return r.query(ctx, scoringProfileColumns+`
+		AND v.version = p.current_version
+		WHERE p.deleted_at IS NULL ORDER BY v.name
+	`)
+}
+
+// FindDefault returns the current version of the default scoring profile, or nil
+func (r *ScoringProfileRepository) FindDefault(ctx context.Context) (*entity.ScoringProfile, error) {
+	row := r.db.QueryRowContext(ctx, scoringProfileColumns+`
+		AND v.version = p.current_version
+		WHERE p.is_default = 1 AND p.deleted_at IS NULL
+	`)
+
+	profile, err := scanScoringProfile(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return profile, err
+}
+
+// SetDefault makes a scoring profile the only default one
+func (r *ScoringProfileRepository) SetDefault(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE scoring_profiles SET is_default = 0 WHERE is_default = 1"); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE scoring_profiles SET is_default = 1 WHERE id = ? AND deleted_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// query runs a scoring profile query returning several versions
+func (r *ScoringProfileRepository) query(ctx context.Context, query string, args ...any) ([]*entity.ScoringProfile, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*entity.ScoringProfile
+	for rows.Next() {
+		profile, err := scanScoringProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// insertScoringProfileVersion inserts a version of a scoring profile, weights encoded as JSON
+func insertScoringProfileVersion(ctx context.Context, tx *sql.Tx, profile *entity.ScoringProfile) error {
+	tacticWeights, err := json.Marshal(profile.TacticWeights)
+	if err != nil {
+		return err
+	}
+	severityWeights, err := json.Marshal(profile.SeverityWeights)
+	if err != nil {
+		return err
+	}
+	undetectedWeights, err := json.Marshal(profile.UndetectedWeights)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO scoring_profile_versions (profile_id, version, name, description, blocked_credit,
+			detected_credit, tactic_weights, severity_weights, undetected_weights, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, profile.ID, profile.Version, profile.Name, profile.Description, profile.BlockedCredit,
+		profile.DetectedCredit, string(tacticWeights), string(severityWeights), string(undetectedWeights),
+		profile.CreatedBy, profile.CreatedAt)
+
+	return err
+}
+
+// scanScoringProfile scans a scoring profile version row
+func scanScoringProfile(row interface{ Scan(dest ...any) error }) (*entity.ScoringProfile, error) {
+	profile := &entity.ScoringProfile{}
+	var description, tacticWeights, severityWeights, undetectedWeights, createdBy sql.NullString
+
+	err := row.Scan(&profile.ID, &profile.Version, &profile.Name, &description,
+		&profile.BlockedCredit, &profile.DetectedCredit, &tacticWeights, &severityWeights,
+		&undetectedWeights, &profile.IsDefault, &createdBy, &profile.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Description = description.String
+	profile.CreatedBy = createdBy.String
+	if tacticWeights.Valid && tacticWeights.String != "" {
+		if err := json.Unmarshal([]byte(tacticWeights.String), &profile.TacticWeights); err != nil {
+			return nil, err
+		}
+	}
+	if severityWeights.Valid && severityWeights.String != "" {
+		if err := json.Unmarshal([]byte(severityWeights.String), &profile.SeverityWeights); err != nil {
+			return nil, err
+		}
+	}
+	if undetectedWeights.Valid && undetectedWeights.String != "" {
+		if err := json.Unmarshal([]byte(undetectedWeights.String), &profile.UndetectedWeights); err != nil {
+			return nil, err
+		}
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,338 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"autostrike/internal/domain/entity"
+)
+
+// searchWeights are the BM25 weights of the title, keywords and body columns
+// of the search index
+var searchWeights = []float64{4, 2, 1}
+
+// searchSource describes how the rows of a table are indexed. Title, keywords
+// and body are SQL expressions over the row, written with %[1]s as its name.
+type searchSource struct {
+	searchType entity.SearchType
+	table      string
+	live       string // Condition of the indexed rows, empty for all
+	updateOf   string // Columns whose update reindexes a row, empty for any
+	title      string
+	keywords   string
+	body       string
+}
+
+// searchSources are the indexed tables. Deleted scenarios and techniques are
+// left out of the index until restored.
+var searchSources = []searchSource{
+	{
+		searchType: entity.SearchTechnique,
+		table:      "techniques",
+		live:       "%[1]s.deleted_at IS NULL",
+		title:      "%[1]s.name",
+		keywords: `%[1]s.id || ' ' || COALESCE((SELECT group_concat(json_extract(value, '$.command'), char(10))
+			FROM json_each(CASE WHEN json_valid(%[1]s.executors) THEN %[1]s.executors END) WHERE type = 'object'), '')`,
+		body: "%[1]s.description",
+	},
+	{
+		searchType: entity.SearchScenario,
+		table:      "scenarios",
+		live:       "%[1]s.deleted_at IS NULL",
+		title:      "%[1]s.name",
+		keywords: `COALESCE((SELECT group_concat(value, ' ')
+			FROM json_each(CASE WHEN json_valid(%[1]s.tags) THEN %[1]s.tags END)), '')`,
+		body: "%[1]s.description",
+	},
+	{
+		searchType: entity.SearchResult,
+		table:      "execution_results",
+		updateOf:   "output, status",
+		title:      "%[1]s.technique_id",
+		keywords:   "%[1]s.agent_paw || ' ' || %[1]s.status",
+		body:       "%[1]s.output",
+	},
+}
+
+// createSearchIndex creates the full-text index of techniques, scenarios and
+// results, kept up to date by triggers. search_documents numbers the indexed
+// rows, so the index does not depend on the rowids of the tables. Rows
+// existing when the index is created are indexed then.
+func createSearchIndex(db *sql.DB) error {
+	var existing int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'search_documents'`).Scan(&existing); err != nil {
+		return err
+	}
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS search_documents (
+			doc_id INTEGER PRIMARY KEY,
+			type TEXT NOT NULL,
+			ref_id TEXT NOT NULL,
+			UNIQUE (type, ref_id)
+		)`,
+		`CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts4(title, keywords, body, tokenize=unicode61)`,
+	}
+	for _, source := range searchSources {
+		statements = append(statements, source.triggers()...)
+	}
+	if existing == 0 {
+		for _, source := range searchSources {
+			statements = append(statements, source.backfill()...)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// expr writes an expression of the source over the row named row
+func (s searchSource) expr(format, row string) string {
+	return fmt.Sprintf(format, row)
+}
+
+// index returns the statements indexing the row named row
+func (s searchSource) index(row string) []string {
+	insert := fmt.Sprintf("INSERT INTO search_documents (type, ref_id) SELECT '%s', %s.id", s.searchType, row)
+	if s.live != "" {
+		insert += " WHERE " + s.expr(s.live, row)
+	}
+	return []string{
+		insert,
+		fmt.Sprintf(`INSERT INTO search_index (docid, title, keywords, body)
+			SELECT doc_id, %s, %s, %s FROM search_documents WHERE type = '%s' AND ref_id = %s.id`,
+			s.expr(s.title, row), s.expr(s.keywords, row), s.expr(s.body, row), s.searchType, row),
+	}
+}
+
+// unindex returns the statements removing the row named row from the index
+func (s searchSource) unindex(row string) []string {
+	return []string{
+		fmt.Sprintf("DELETE FROM search_index WHERE docid = (SELECT doc_id FROM search_documents WHERE type = '%s' AND ref_id = %s.id)", s.searchType, row),
+		fmt.Sprintf("DELETE FROM search_documents WHERE type = '%s' AND ref_id = %s.id", s.searchType, row),
+	}
+}
+
+// triggers returns the statements creating the triggers indexing the rows of the source
+func (s searchSource) triggers() []string {
+	body := func(statements []string) string {
+		return strings.Join(statements, ";\n") + ";"
+	}
+	update := "UPDATE"
+	if s.updateOf != "" {
+		update += " OF " + s.updateOf
+	}
+	return []string{
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_search_insert AFTER INSERT ON %[1]s BEGIN\n%[2]s\nEND",
+			s.table, body(s.index("NEW"))),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_search_update AFTER %[2]s ON %[1]s BEGIN\n%[3]s\nEND",
+			s.table, update, body(append(s.unindex("OLD"), s.index("NEW")...))),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %[1]s_search_delete AFTER DELETE ON %[1]s BEGIN\n%[2]s\nEND",
+			s.table, body(s.unindex("OLD"))),
+	}
+}
+
+// backfill returns the statements indexing the existing rows of the source
+func (s searchSource) backfill() []string {
+	insert := fmt.Sprintf("INSERT INTO search_documents (type, ref_id) SELECT '%s', src.id FROM %s src", s.searchType, s.table)
+	if s.live != "" {
+		insert += " WHERE " + s.expr(s.live, "src")
+	}
+	return []string{
+		insert,
+		fmt.Sprintf(`INSERT INTO search_index (docid, title, keywords, body)
+			SELECT d.doc_id, %s, %s, %s FROM %s src JOIN search_documents d ON d.type = '%s' AND d.ref_id = src.id`,
+			s.expr(s.title, "src"), s.expr(s.keywords, "src"), s.expr(s.body, "src"), s.table, s.searchType),
+	}
+}
+
+// SearchRepository implements repository.SearchRepository with an SQLite FTS4 index
+type SearchRepository struct {
+	db *sql.DB
+}
+
+// NewSearchRepository creates a new SQLite search repository
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search finds up to limit items of types matching every term of query,
+// ranked by BM25. A term ending with * matches the words it prefixes.
+func (r *SearchRepository) Search(ctx context.Context, query string, types []entity.SearchType, limit int) ([]*entity.SearchHit, error) {
+	match := ftsQuery(query)
+	if match == "" || len(types) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	// Every match is ranked from its match info, only the best ones are then read
+	typeNames := make([]string, len(types))
+	for i, t := range types {
+		typeNames[i] = string(t)
+	}
+	placeholders, args := inClause(typeNames)
+	// NOSONAR: only "?" placeholders are joined, the types are query parameters
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT search_index.docid, d.type, d.ref_id, matchinfo(search_index, 'pcnalx')
+		FROM search_index JOIN search_documents d ON d.doc_id = search_index.docid
+		WHERE search_index MATCH ? AND d.type IN (`+placeholders+`)
+	`, append([]any{match}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := make(map[int64]*entity.SearchHit)
+	var ranked []int64
+	for rows.Next() {
+		var docID int64
+		var hit entity.SearchHit
+		var info []byte
+		if err := rows.Scan(&docID, &hit.Type, &hit.ID, &info); err != nil {
+			return nil, err
+		}
+		hit.Score = bm25(info, searchWeights)
+		hits[docID] = &hit
+		ranked = append(ranked, docID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := hits[ranked[i]], hits[ranked[j]]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.ID < b.ID
+	})
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	if len(ranked) == 0 {
+		return nil, nil
+	}
+
+	if err := r.readHits(ctx, match, ranked, hits); err != nil {
+		return nil, err
+	}
+	result := make([]*entity.SearchHit, len(ranked))
+	for i, docID := range ranked {
+		result[i] = hits[docID]
+	}
+	return result, nil
+}
+
+// readHits fills the title, snippet and execution of the hits of docIDs
+func (r *SearchRepository) readHits(ctx context.Context, match string, docIDs []int64, hits map[int64]*entity.SearchHit) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(docIDs)), ",")
+	args := []any{match}
+	for _, docID := range docIDs {
+		args = append(args, docID)
+	}
+	// NOSONAR: only "?" placeholders are joined, the IDs are query parameters
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT search_index.docid, search_index.title, snippet(search_index, '<mark>', '</mark>', '…', -1, 24),
+			COALESCE(res.execution_id, '')
+		FROM search_index
+		JOIN search_documents d ON d.doc_id = search_index.docid
+		LEFT JOIN execution_results res ON d.type = 'result' AND res.id = d.ref_id
+		WHERE search_index MATCH ? AND search_index.docid IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var docID int64
+		var title sql.NullString
+		var snippet, executionID string
+		if err := rows.Scan(&docID, &title, &snippet, &executionID); err != nil {
+			return err
+		}
+		if hit, ok := hits[docID]; ok {
+			hit.Title = title.String
+			hit.Snippet = snippet
+			hit.ExecutionID = executionID
+		}
+	}
+	return rows.Err()
+}
+
+// ftsQuery turns a user query into an FTS query matching every term, each
+// quoted so operators and punctuation in it are not interpreted. Returns ""
+// when the query has no term.
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.TrimRight(word, "*")
+		// Quotes cannot be escaped in FTS4 phrases, the tokenizer drops them anyway
+		term := strings.TrimSpace(strings.ReplaceAll(word, `"`, " "))
+		if term == "" {
+			continue
+		}
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, `"`+term+`"`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// bm25 computes the Okapi BM25 score of a match from its FTS4 'pcnalx' match
+// info, with a weight per column
+func bm25(info []byte, weights []float64) float64 {
+	const k1, b = 1.2, 0.75
+
+	values := make([]uint32, len(info)/4)
+	for i := range values {
+		values[i] = binary.NativeEndian.Uint32(info[i*4:])
+	}
+	if len(values) < 3 {
+		return 0
+	}
+	phrases, columns, docs := int(values[0]), int(values[1]), float64(values[2])
+	avgLength := values[3 : 3+columns]
+	length := values[3+columns : 3+2*columns]
+	hitInfo := values[3+2*columns:]
+	if len(hitInfo) < 3*phrases*columns {
+		return 0
+	}
+
+	score := 0.0
+	for p := 0; p < phrases; p++ {
+		for c := 0; c < columns && c < len(weights); c++ {
+			x := 3 * (c + p*columns)
+			hits, docsWithHits := float64(hitInfo[x]), float64(hitInfo[x+2])
+			if hits == 0 {
+				continue
+			}
+			idf := math.Log((docs - docsWithHits + 0.5) / (docsWithHits + 0.5))
+			if idf <= 0 {
+				idf = 1e-6 // Terms in most documents still rank their matches
+			}
+			avg := math.Max(float64(avgLength[c]), 1)
+			score += weights[c] * idf * hits * (k1 + 1) / (hits + k1*(1-b+b*float64(length[c])/avg))
+		}
+	}
+	return score
+}
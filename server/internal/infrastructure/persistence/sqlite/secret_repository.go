@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"autostrike/internal/domain/entity"
+)
+
+// secretColumns are the columns of a secret
+const secretColumns = "name, description, key_id, data_key, ciphertext, updated_by, created_at, updated_at"
+
+// SecretRepository implements repository.SecretRepository using SQLite
+type SecretRepository struct {
+	db *sql.DB
+}
+
+// NewSecretRepository creates a new SQLite secret repository
+func NewSecretRepository(db *sql.DB) *SecretRepository {
+	return &SecretRepository{db: db}
+}
+
+// Save inserts a secret or replaces the one of the same name
+func (r *SecretRepository) Save(ctx context.Context, secret *entity.Secret) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO secrets (name, description, key_id, data_key, ciphertext, updated_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			description = excluded.description,
+			key_id = excluded.key_id,
+			data_key = excluded.data_key,
+			ciphertext = excluded.ciphertext,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at
+	`, secret.Name, secret.Description, secret.KeyID, secret.DataKey, secret.Ciphertext,
+		secret.UpdatedBy, secret.CreatedAt, secret.UpdatedAt)
+
+	return err
+}
+
+// FindByName finds a secret by name
+func (r *SecretRepository) FindByName(ctx context.Context, name string) (*entity.Secret, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+secretColumns+" FROM secrets WHERE name = ?", name)
+	return scanSecret(row)
+}
+
+// FindAll returns every secret, by name
+func (r *SecretRepository) FindAll(ctx context.Context) ([]*entity.Secret, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+secretColumns+" FROM secrets ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var secrets []*entity.Secret
+	for rows.Next() {
+		secret, err := scanSecret(rows)
+		if err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, rows.Err()
+}
+
+// Delete deletes a secret
+func (r *SecretRepository) Delete(ctx context.Context, name string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM secrets WHERE name = ?", name)
+	return err
+}
+
+// scanSecret scans a secret row
+func scanSecret(row interface{ Scan(dest ...any) error }) (*entity.Secret, error) {
+	secret := &entity.Secret{}
+	var description, updatedBy sql.NullString
+	if err := row.Scan(&secret.Name, &description, &secret.KeyID, &secret.DataKey, &secret.Ciphertext,
+		&updatedBy, &secret.CreatedAt, &secret.UpdatedAt); err != nil {
+		return nil, err
+	}
+	secret.Description = description.String
+	secret.UpdatedBy = updatedBy.String
+	return secret, nil
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"autostrike/internal/domain/entity"
+)
+
+// SMTPConfigRepository implements repository.SMTPConfigRepository using SQLite
+type SMTPConfigRepository struct {
+	db *sql.DB
+}
+
+// NewSMTPConfigRepository creates a new SQLite SMTP configuration repository
+func NewSMTPConfigRepository(db *sql.DB) *SMTPConfigRepository {
+	return &SMTPConfigRepository{db: db}
+}
+
+// Get returns the stored SMTP configuration, or nil
+func (r *SMTPConfigRepository) Get(ctx context.Context) (*entity.SMTPConfig, error) {
+	config := &entity.SMTPConfig{Source: entity.SMTPConfigFromAPI}
+	var username, password, updatedBy sql.NullString
+	var updatedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT host, port, username, password_encrypted, from_address, use_tls, updated_by, updated_at
+		FROM smtp_config WHERE id = 1
+	`).Scan(&config.Host, &config.Port, &username, &password, &config.From, &config.UseTLS, &updatedBy, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config.Username = username.String
+	config.Password = password.String
+	config.UpdatedBy = updatedBy.String
+	if updatedAt.Valid {
+		config.UpdatedAt = &updatedAt.Time
+	}
+	return config, nil
+}
+
+// Save stores the SMTP configuration, replacing the previous one
+func (r *SMTPConfigRepository) Save(ctx context.Context, config *entity.SMTPConfig) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO smtp_config
+			(id, host, port, username, password_encrypted, from_address, use_tls, updated_by, updated_at)
+		VALUES (1, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, config.Host, config.Port, config.Username, config.Password, config.From, config.UseTLS,
+		config.UpdatedBy, config.UpdatedAt)
+	return err
+}
+
+// Delete removes the stored SMTP configuration
+func (r *SMTPConfigRepository) Delete(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM smtp_config WHERE id = 1`)
+	return err
+}
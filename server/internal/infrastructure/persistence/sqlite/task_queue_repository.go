@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+const queuedTaskColumns = "result_id, execution_id, agent_paw, task, queued_at, expires_at"
+
+// TaskQueueRepository implements repository.TaskQueueRepository using SQLite
+type TaskQueueRepository struct {
+	db *sql.DB
+}
+
+// NewTaskQueueRepository creates a new SQLite task queue repository
+func NewTaskQueueRepository(db *sql.DB) *TaskQueueRepository {
+	return &TaskQueueRepository{db: db}
+}
+
+// Enqueue queues a task, replacing the one already queued for its result
+func (r *TaskQueueRepository) Enqueue(ctx context.Context, task *entity.QueuedTask) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO task_queue (`+queuedTaskColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, task.ResultID, task.ExecutionID, task.AgentPaw, string(task.Task), task.QueuedAt, task.ExpiresAt)
+
+	return err
+}
+
+// Delete removes a queued task and reports whether it was queued
+func (r *TaskQueueRepository) Delete(ctx context.Context, resultID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM task_queue WHERE result_id = ?", resultID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := res.RowsAffected()
+	return deleted > 0, err
+}
+
+// DeleteByExecution removes the queued tasks of an execution
+func (r *TaskQueueRepository) DeleteByExecution(ctx context.Context, executionID string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM task_queue WHERE execution_id = ?", executionID)
+	return err
+}
+
+// FindByAgent returns the tasks queued for an agent, oldest first
+func (r *TaskQueueRepository) FindByAgent(ctx context.Context, paw string) ([]*entity.QueuedTask, error) {
+	return r.query(ctx, "SELECT "+queuedTaskColumns+" FROM task_queue WHERE agent_paw = ? ORDER BY queued_at", paw)
+}
+
+// FindExpired returns the tasks that expired by now, oldest first
+func (r *TaskQueueRepository) FindExpired(ctx context.Context, now time.Time) ([]*entity.QueuedTask, error) {
+	return r.query(ctx, "SELECT "+queuedTaskColumns+" FROM task_queue WHERE expires_at <= ? ORDER BY queued_at", now)
+}
+
+func (r *TaskQueueRepository) query(ctx context.Context, query string, args ...any) ([]*entity.QueuedTask, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*entity.QueuedTask
+	for rows.Next() {
+		task := &entity.QueuedTask{}
+		var payload string
+		if err := rows.Scan(&task.ResultID, &task.ExecutionID, &task.AgentPaw, &payload, &task.QueuedAt, &task.ExpiresAt); err != nil {
+			return nil, err
+		}
+		task.Task = []byte(payload)
+		tasks = append(tasks, task)
+	}
+
+	return tasks, rows.Err()
+}
@@ -15,10 +15,13 @@ import (
 
 // SQL column constants and error messages for techniques
 const (
-	techniqueColumns      = "id, name, description, tactic, platforms, executors, detection, is_safe"
-	errMarshalPlatforms   = "failed to marshal platforms: %w"
-	errMarshalExecutors   = "failed to marshal executors: %w"
-	errMarshalDetection   = "failed to marshal detection: %w"
+	techniqueColumns     = "id, name, description, tactic, platforms, executors, detection, COALESCE(sigma_rules, '[]'), is_safe, COALESCE(status, 'active'), COALESCE(parent_id, ''), COALESCE(tactics, '[]'), COALESCE(metadata, '{}'), COALESCE(severity, ''), COALESCE(impact, 0), COALESCE(domain, 'enterprise-attack'), deleted_at"
+	errMarshalPlatforms  = "failed to marshal platforms: %w"
+	errMarshalExecutors  = "failed to marshal executors: %w"
+	errMarshalDetection  = "failed to marshal detection: %w"
+	errMarshalSigmaRules = "failed to marshal sigma rules: %w"
+	errMarshalTactics    = "failed to marshal tactics: %w"
+	errMarshalMetadata   = "failed to marshal metadata: %w"
 )
 
 // TechniqueRepository implements repository.TechniqueRepository using SQLite
@@ -45,16 +48,31 @@ func (r *TechniqueRepository) Create(ctx context.Context, technique *entity.Tech
 	if err != nil {
 		return fmt.Errorf(errMarshalDetection, err)
 	}
+	sigmaRules, err := json.Marshal(technique.SigmaRules)
+	if err != nil {
+		return fmt.Errorf(errMarshalSigmaRules, err)
+	}
+	technique.NormalizeTactics()
+	technique.AssessRisk()
+	tactics, err := json.Marshal(technique.Tactics)
+	if err != nil {
+		return fmt.Errorf(errMarshalTactics, err)
+	}
+	metadata, err := marshalMetadata(technique.Metadata)
+	if err != nil {
+		return fmt.Errorf(errMarshalMetadata, err)
+	}
 
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO techniques (id, name, description, tactic, platforms, executors, detection, is_safe, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, technique.ID, technique.Name, technique.Description, technique.Tactic, platforms, executors, detection, technique.IsSafe, time.Now())
+		INSERT INTO techniques (id, name, description, tactic, platforms, executors, detection, sigma_rules, is_safe, status, parent_id, tactics, metadata, severity, impact, domain, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, NULLIF(?, ''), ?)
+	`, technique.ID, technique.Name, technique.Description, technique.Tactic, platforms, executors, detection, sigmaRules, technique.IsSafe, technique.Status, technique.ParentID, tactics, metadata, technique.Severity, technique.Impact, technique.Domain, time.Now())
 
 	return err
 }
 
-// Update updates an existing technique
+// Update updates an existing technique. An empty status keeps the current lifecycle state,
+// and an empty parent and domain the current ones.
 func (r *TechniqueRepository) Update(ctx context.Context, technique *entity.Technique) error {
 	platforms, err := json.Marshal(technique.Platforms)
 	if err != nil {
@@ -68,29 +86,47 @@ func (r *TechniqueRepository) Update(ctx context.Context, technique *entity.Tech
 	if err != nil {
 		return fmt.Errorf(errMarshalDetection, err)
 	}
+	sigmaRules, err := json.Marshal(technique.SigmaRules)
+	if err != nil {
+		return fmt.Errorf(errMarshalSigmaRules, err)
+	}
+	technique.NormalizeTactics()
+	technique.AssessRisk()
+	tactics, err := json.Marshal(technique.Tactics)
+	if err != nil {
+		return fmt.Errorf(errMarshalTactics, err)
+	}
+	metadata, err := marshalMetadata(technique.Metadata)
+	if err != nil {
+		return fmt.Errorf(errMarshalMetadata, err)
+	}
 
 	_, err = r.db.ExecContext(ctx, `
-		UPDATE techniques SET name = ?, description = ?, tactic = ?, platforms = ?, executors = ?, detection = ?, is_safe = ?
+		UPDATE techniques SET name = ?, description = ?, tactic = ?, platforms = ?, executors = ?, detection = ?, sigma_rules = ?, is_safe = ?,
+		status = COALESCE(NULLIF(?, ''), status), parent_id = COALESCE(NULLIF(?, ''), parent_id), tactics = ?,
+		metadata = COALESCE(?, metadata), severity = ?, impact = ?, domain = COALESCE(NULLIF(?, ''), domain)
 		WHERE id = ?
-	`, technique.Name, technique.Description, technique.Tactic, platforms, executors, detection, technique.IsSafe, technique.ID)
+	`, technique.Name, technique.Description, technique.Tactic, platforms, executors, detection, sigmaRules, technique.IsSafe, technique.Status, technique.ParentID, tactics, metadata, technique.Severity, technique.Impact, technique.Domain, technique.ID)
 
 	return err
 }
 
-// Delete deletes a technique
+// Delete moves a technique to the trash. The row is kept for the results
+// referencing it until the trash is purged.
 func (r *TechniqueRepository) Delete(ctx context.Context, id string) error {
-	_, err := r.db.ExecContext(ctx, "DELETE FROM techniques WHERE id = ?", id)
+	_, err := r.db.ExecContext(ctx, "UPDATE techniques SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now(), id)
 	return err
 }
 
 // FindByID finds a technique by ID
 func (r *TechniqueRepository) FindByID(ctx context.Context, id string) (*entity.Technique, error) {
 	technique := &entity.Technique{}
-	var platforms, executors, detection string
+	var platforms, executors, detection, sigmaRules, tactics, metadata string
+	var deletedAt sql.NullTime
 
 	err := r.db.QueryRowContext(ctx,
-		fmt.Sprintf("SELECT %s FROM techniques WHERE id = ?", techniqueColumns),
-		id).Scan(&technique.ID, &technique.Name, &technique.Description, &technique.Tactic, &platforms, &executors, &detection, &technique.IsSafe)
+		fmt.Sprintf("SELECT %s FROM techniques WHERE id = ? AND deleted_at IS NULL", techniqueColumns),
+		id).Scan(&technique.ID, &technique.Name, &technique.Description, &technique.Tactic, &platforms, &executors, &detection, &sigmaRules, &technique.IsSafe, &technique.Status, &technique.ParentID, &tactics, &metadata, &technique.Severity, &technique.Impact, &technique.Domain, &deletedAt)
 
 	if err != nil {
 		return nil, err
@@ -106,6 +142,18 @@ func (r *TechniqueRepository) FindByID(ctx context.Context, id string) (*entity.
 	if json.Unmarshal([]byte(detection), &technique.Detection) != nil {
 		technique.Detection = []entity.Detection{}
 	}
+	if json.Unmarshal([]byte(sigmaRules), &technique.SigmaRules) != nil {
+		technique.SigmaRules = nil
+	}
+	if json.Unmarshal([]byte(tactics), &technique.Tactics) != nil {
+		technique.Tactics = nil
+	}
+	// Fields removed through the API are stored as {} so re-imports keep them removed
+	if json.Unmarshal([]byte(metadata), &technique.Metadata) != nil || len(technique.Metadata) == 0 {
+		technique.Metadata = nil
+	}
+	technique.NormalizeTactics()
+	technique.AssessRisk()
 
 	return technique, nil
 }
@@ -113,7 +161,7 @@ func (r *TechniqueRepository) FindByID(ctx context.Context, id string) (*entity.
 // FindAll finds all techniques
 func (r *TechniqueRepository) FindAll(ctx context.Context) ([]*entity.Technique, error) {
 	rows, err := r.db.QueryContext(ctx,
-		fmt.Sprintf("SELECT %s FROM techniques ORDER BY id", techniqueColumns))
+		fmt.Sprintf("SELECT %s FROM techniques WHERE deleted_at IS NULL ORDER BY id", techniqueColumns))
 	if err != nil {
 		return nil, err
 	}
@@ -122,11 +170,11 @@ func (r *TechniqueRepository) FindAll(ctx context.Context) ([]*entity.Technique,
 	return r.scanTechniques(rows)
 }
 
-// FindByTactic finds techniques by tactic
+// FindByTactic finds the techniques belonging to a tactic, as their primary tactic or another one
 func (r *TechniqueRepository) FindByTactic(ctx context.Context, tactic entity.TacticType) ([]*entity.Technique, error) {
 	rows, err := r.db.QueryContext(ctx,
-		fmt.Sprintf("SELECT %s FROM techniques WHERE tactic = ? ORDER BY id", techniqueColumns),
-		tactic)
+		fmt.Sprintf("SELECT %s FROM techniques WHERE (tactic = ? OR tactics LIKE ?) AND deleted_at IS NULL ORDER BY id", techniqueColumns),
+		tactic, `%"`+string(tactic)+`"%`)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +186,7 @@ func (r *TechniqueRepository) FindByTactic(ctx context.Context, tactic entity.Ta
 // FindByPlatform finds techniques by platform
 func (r *TechniqueRepository) FindByPlatform(ctx context.Context, platform string) ([]*entity.Technique, error) {
 	rows, err := r.db.QueryContext(ctx,
-		fmt.Sprintf("SELECT %s FROM techniques WHERE platforms LIKE ? ORDER BY id", techniqueColumns),
+		fmt.Sprintf("SELECT %s FROM techniques WHERE platforms LIKE ? AND deleted_at IS NULL ORDER BY id", techniqueColumns),
 		"%"+platform+"%")
 	if err != nil {
 		return nil, err
@@ -161,6 +209,10 @@ func (r *TechniqueRepository) ImportFromYAML(ctx context.Context, path string) e
 	}
 
 	for _, t := range techniques {
+		// Catalog files list sub-techniques by ID only
+		if t.ParentID == "" {
+			t.ParentID = entity.ParentTechniqueID(t.ID)
+		}
 		if err := r.upsert(ctx, t); err != nil {
 			return fmt.Errorf("failed to import technique %s: %w", t.ID, err)
 		}
@@ -169,7 +221,22 @@ func (r *TechniqueRepository) ImportFromYAML(ctx context.Context, path string) e
 	return nil
 }
 
-// upsert inserts or updates a technique
+// marshalMetadata returns the metadata column value: NULL for unset metadata, so
+// that updates and catalog re-imports keep the stored fields
+func marshalMetadata(metadata entity.Metadata) (any, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// upsert inserts or updates a technique. Catalog files usually omit the status
+// and metadata, so re-importing them keeps the changes made through the API,
+// severity and impact overrides included since they live in the metadata.
 func (r *TechniqueRepository) upsert(ctx context.Context, technique *entity.Technique) error {
 	platforms, err := json.Marshal(technique.Platforms)
 	if err != nil {
@@ -183,10 +250,24 @@ func (r *TechniqueRepository) upsert(ctx context.Context, technique *entity.Tech
 	if err != nil {
 		return fmt.Errorf(errMarshalDetection, err)
 	}
+	sigmaRules, err := json.Marshal(technique.SigmaRules)
+	if err != nil {
+		return fmt.Errorf(errMarshalSigmaRules, err)
+	}
+	technique.NormalizeTactics()
+	technique.AssessRisk()
+	tactics, err := json.Marshal(technique.Tactics)
+	if err != nil {
+		return fmt.Errorf(errMarshalTactics, err)
+	}
+	metadata, err := marshalMetadata(technique.Metadata)
+	if err != nil {
+		return fmt.Errorf(errMarshalMetadata, err)
+	}
 
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO techniques (id, name, description, tactic, platforms, executors, detection, is_safe, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO techniques (id, name, description, tactic, platforms, executors, detection, sigma_rules, is_safe, status, parent_id, tactics, metadata, severity, impact, domain, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?, NULLIF(?, ''), ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name = excluded.name,
 			description = excluded.description,
@@ -194,8 +275,16 @@ func (r *TechniqueRepository) upsert(ctx context.Context, technique *entity.Tech
 			platforms = excluded.platforms,
 			executors = excluded.executors,
 			detection = excluded.detection,
-			is_safe = excluded.is_safe
-	`, technique.ID, technique.Name, technique.Description, technique.Tactic, platforms, executors, detection, technique.IsSafe, time.Now())
+			sigma_rules = excluded.sigma_rules,
+			is_safe = excluded.is_safe,
+			status = COALESCE(excluded.status, techniques.status),
+			parent_id = excluded.parent_id,
+			tactics = excluded.tactics,
+			metadata = COALESCE(excluded.metadata, techniques.metadata),
+			severity = excluded.severity,
+			impact = excluded.impact,
+			domain = COALESCE(excluded.domain, techniques.domain)
+	`, technique.ID, technique.Name, technique.Description, technique.Tactic, platforms, executors, detection, sigmaRules, technique.IsSafe, technique.Status, technique.ParentID, tactics, metadata, technique.Severity, technique.Impact, technique.Domain, time.Now())
 
 	return err
 }
@@ -205,12 +294,16 @@ func (r *TechniqueRepository) scanTechniques(rows *sql.Rows) ([]*entity.Techniqu
 
 	for rows.Next() {
 		technique := &entity.Technique{}
-		var platforms, executors, detection string
+		var platforms, executors, detection, sigmaRules, tactics, metadata string
+		var deletedAt sql.NullTime
 
-		err := rows.Scan(&technique.ID, &technique.Name, &technique.Description, &technique.Tactic, &platforms, &executors, &detection, &technique.IsSafe)
+		err := rows.Scan(&technique.ID, &technique.Name, &technique.Description, &technique.Tactic, &platforms, &executors, &detection, &sigmaRules, &technique.IsSafe, &technique.Status, &technique.ParentID, &tactics, &metadata, &technique.Severity, &technique.Impact, &technique.Domain, &deletedAt)
 		if err != nil {
 			return nil, err
 		}
+		if deletedAt.Valid {
+			technique.DeletedAt = &deletedAt.Time
+		}
 
 		// Parse JSON fields, default to empty on error
 		if json.Unmarshal([]byte(platforms), &technique.Platforms) != nil {
@@ -222,6 +315,18 @@ func (r *TechniqueRepository) scanTechniques(rows *sql.Rows) ([]*entity.Techniqu
 		if json.Unmarshal([]byte(detection), &technique.Detection) != nil {
 			technique.Detection = []entity.Detection{}
 		}
+		if json.Unmarshal([]byte(sigmaRules), &technique.SigmaRules) != nil {
+			technique.SigmaRules = nil
+		}
+		if json.Unmarshal([]byte(tactics), &technique.Tactics) != nil {
+			technique.Tactics = nil
+		}
+		// Fields removed through the API are stored as {} so re-imports keep them removed
+		if json.Unmarshal([]byte(metadata), &technique.Metadata) != nil || len(technique.Metadata) == 0 {
+			technique.Metadata = nil
+		}
+		technique.NormalizeTactics()
+		technique.AssessRisk()
 
 		techniques = append(techniques, technique)
 	}
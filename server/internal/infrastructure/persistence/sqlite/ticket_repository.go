@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"autostrike/internal/domain/entity"
+)
+
+// ticketColumns lists the selected columns in scan order
+const ticketColumns = `id, provider, external_key, url, technique_id, agent_paw, execution_id, result_id,
+	status, external_status, resolution, occurrences, created_at, updated_at, resolved_at`
+
+// TicketRepository implements repository.TicketRepository using SQLite
+type TicketRepository struct {
+	db *sql.DB
+}
+
+// NewTicketRepository creates a new SQLite ticket repository
+func NewTicketRepository(db *sql.DB) *TicketRepository {
+	return &TicketRepository{db: db}
+}
+
+// Create inserts a new ticket
+func (r *TicketRepository) Create(ctx context.Context, ticket *entity.Ticket) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO tickets (
+			id, provider, external_key, url, technique_id, agent_paw, execution_id, result_id,
+			status, external_status, resolution, occurrences, created_at, updated_at, resolved_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, ticket.ID, ticket.Provider, ticket.ExternalKey, ticket.URL, ticket.TechniqueID, ticket.AgentPaw,
+		ticket.ExecutionID, ticket.ResultID, ticket.Status, ticket.ExternalStatus, ticket.Resolution,
+		ticket.Occurrences, ticket.CreatedAt, ticket.UpdatedAt, ticket.ResolvedAt)
+
+	return err
+}
+
+// Update saves the latest failure and the synced status of a ticket
+func (r *TicketRepository) Update(ctx context.Context, ticket *entity.Ticket) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE tickets SET
+			execution_id = ?, result_id = ?, status = ?, external_status = ?, resolution = ?,
+			occurrences = ?, updated_at = ?, resolved_at = ?
+		WHERE id = ?
+	`, ticket.ExecutionID, ticket.ResultID, ticket.Status, ticket.ExternalStatus, ticket.Resolution,
+		ticket.Occurrences, ticket.UpdatedAt, ticket.ResolvedAt, ticket.ID)
+
+	return err
+}
+
+// FindByID finds a ticket by ID
+func (r *TicketRepository) FindByID(ctx context.Context, id string) (*entity.Ticket, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+ticketColumns+" FROM tickets WHERE id = ?", id)
+	return scanTicket(row)
+}
+
+// FindOpen returns the open ticket of a technique on an agent, nil if there is none
+func (r *TicketRepository) FindOpen(ctx context.Context, techniqueID, agentPaw string) (*entity.Ticket, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+ticketColumns+` FROM tickets
+		WHERE status = ? AND technique_id = ? AND agent_paw = ?
+		ORDER BY created_at DESC LIMIT 1`, entity.TicketOpen, techniqueID, agentPaw)
+	ticket, err := scanTicket(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return ticket, err
+}
+
+// FindAll returns the most recent tickets, optionally filtered by status
+func (r *TicketRepository) FindAll(ctx context.Context, status entity.TicketStatus, limit int) ([]*entity.Ticket, error) {
+	query := "SELECT " + ticketColumns + " FROM tickets"
+	var args []any
+	if status != "" {
+		query += " WHERE status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []*entity.Ticket
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, rows.Err()
+}
+
+// scanTicket scans a ticket row
+func scanTicket(row interface{ Scan(dest ...any) error }) (*entity.Ticket, error) {
+	ticket := &entity.Ticket{}
+	var url, externalStatus, resolution sql.NullString
+	var resolvedAt sql.NullTime
+
+	err := row.Scan(
+		&ticket.ID, &ticket.Provider, &ticket.ExternalKey, &url, &ticket.TechniqueID, &ticket.AgentPaw,
+		&ticket.ExecutionID, &ticket.ResultID, &ticket.Status, &externalStatus, &resolution,
+		&ticket.Occurrences, &ticket.CreatedAt, &ticket.UpdatedAt, &resolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket.URL = url.String
+	ticket.ExternalStatus = externalStatus.String
+	ticket.Resolution = resolution.String
+	if resolvedAt.Valid {
+		ticket.ResolvedAt = &resolvedAt.Time
+	}
+
+	return ticket, nil
+}
@@ -0,0 +1,95 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// TrashRepository implements repository.TrashRepository using SQLite
+type TrashRepository struct {
+	db *sql.DB
+}
+
+// NewTrashRepository creates a new SQLite trash repository
+func NewTrashRepository(db *sql.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// FindDeletedScenarios finds the scenarios in the trash, most recently deleted first
+func (r *TrashRepository) FindDeletedScenarios(ctx context.Context) ([]*entity.Scenario, error) {
+	rows, err := r.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM scenarios WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC", scenarioColumns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return NewScenarioRepository(r.db).scanScenarios(rows)
+}
+
+// FindDeletedTechniques finds the techniques in the trash, most recently deleted first
+func (r *TrashRepository) FindDeletedTechniques(ctx context.Context) ([]*entity.Technique, error) {
+	rows, err := r.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT %s FROM techniques WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC", techniqueColumns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return NewTechniqueRepository(r.db).scanTechniques(rows)
+}
+
+// RestoreScenario takes a scenario out of the trash. Returns false when it is not in the trash.
+func (r *TrashRepository) RestoreScenario(ctx context.Context, id string) (bool, error) {
+	return r.restore(ctx, "scenarios", id)
+}
+
+// RestoreTechnique takes a technique out of the trash. Returns false when it is not in the trash.
+func (r *TrashRepository) RestoreTechnique(ctx context.Context, id string) (bool, error) {
+	return r.restore(ctx, "techniques", id)
+}
+
+// PurgeScenarios permanently deletes the scenarios put in the trash before
+// deletedBefore. Scenarios still referenced by an execution or a schedule stay
+// in the trash.
+func (r *TrashRepository) PurgeScenarios(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM scenarios
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		AND id NOT IN (SELECT scenario_id FROM executions)
+		AND id NOT IN (SELECT scenario_id FROM schedules)
+	`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeTechniques permanently deletes the techniques put in the trash before
+// deletedBefore. Techniques still referenced by a result stay in the trash.
+func (r *TrashRepository) PurgeTechniques(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM techniques
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		AND id NOT IN (SELECT technique_id FROM execution_results)
+	`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// restore clears the deletion time of a trashed row of table
+func (r *TrashRepository) restore(ctx context.Context, table, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL", table), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
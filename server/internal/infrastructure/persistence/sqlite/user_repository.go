@@ -3,12 +3,16 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"autostrike/internal/domain/entity"
 )
 
+// userColumns are the columns of a user
+const userColumns = "id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at, display_name, timezone, preferences, workspace"
+
 // UserRepository implements repository.UserRepository using SQLite
 type UserRepository struct {
 	db *sql.DB
@@ -32,10 +36,17 @@ func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 		user.IsActive = true
 	}
 
-	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO users (id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, user.ID, user.Username, user.Email, user.PasswordHash, user.Role, user.IsActive, user.LastLoginAt, user.CreatedAt, user.UpdatedAt)
+	preferences, err := json.Marshal(user.Preferences)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO users (id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at,
+			display_name, timezone, preferences, workspace)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, user.ID, user.Username, user.Email, user.PasswordHash, user.Role, user.IsActive, user.LastLoginAt, user.CreatedAt, user.UpdatedAt,
+		user.DisplayName, user.Timezone, string(preferences), user.Workspace)
 
 	return err
 }
@@ -44,10 +55,17 @@ func (r *UserRepository) Create(ctx context.Context, user *entity.User) error {
 func (r *UserRepository) Update(ctx context.Context, user *entity.User) error {
 	user.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE users SET username = ?, email = ?, password_hash = ?, role = ?, is_active = ?, updated_at = ?
+	preferences, err := json.Marshal(user.Preferences)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE users SET username = ?, email = ?, password_hash = ?, role = ?, is_active = ?, updated_at = ?,
+			display_name = ?, timezone = ?, preferences = ?, workspace = ?
 		WHERE id = ?
-	`, user.Username, user.Email, user.PasswordHash, user.Role, user.IsActive, user.UpdatedAt, user.ID)
+	`, user.Username, user.Email, user.PasswordHash, user.Role, user.IsActive, user.UpdatedAt,
+		user.DisplayName, user.Timezone, string(preferences), user.Workspace, user.ID)
 
 	return err
 }
@@ -60,71 +78,26 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 
 // FindByID finds a user by ID
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*entity.User, error) {
-	user := &entity.User{}
-	var lastLoginAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at
-		FROM users WHERE id = ?
-	`, id).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive, &lastLoginAt, &user.CreatedAt, &user.UpdatedAt)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if lastLoginAt.Valid {
-		user.LastLoginAt = &lastLoginAt.Time
-	}
-
-	return user, nil
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = ?", id)
+	return scanUser(row)
 }
 
 // FindByUsername finds a user by username
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
-	user := &entity.User{}
-	var lastLoginAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at
-		FROM users WHERE username = ?
-	`, username).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive, &lastLoginAt, &user.CreatedAt, &user.UpdatedAt)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if lastLoginAt.Valid {
-		user.LastLoginAt = &lastLoginAt.Time
-	}
-
-	return user, nil
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE username = ?", username)
+	return scanUser(row)
 }
 
 // FindByEmail finds a user by email
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
-	user := &entity.User{}
-	var lastLoginAt sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at
-		FROM users WHERE email = ?
-	`, email).Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive, &lastLoginAt, &user.CreatedAt, &user.UpdatedAt)
-
-	if err != nil {
-		return nil, err
-	}
-
-	if lastLoginAt.Valid {
-		user.LastLoginAt = &lastLoginAt.Time
-	}
-
-	return user, nil
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE email = ?", email)
+	return scanUser(row)
 }
 
 // FindAll finds all users (including inactive)
 func (r *UserRepository) FindAll(ctx context.Context) ([]*entity.User, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at
+		SELECT `+userColumns+`
 		FROM users ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -138,7 +111,7 @@ func (r *UserRepository) FindAll(ctx context.Context) ([]*entity.User, error) {
 // FindActive finds all active users
 func (r *UserRepository) FindActive(ctx context.Context) ([]*entity.User, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, username, email, password_hash, role, is_active, last_login_at, created_at, updated_at
+		SELECT `+userColumns+`
 		FROM users WHERE is_active = 1 ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -243,22 +216,90 @@ func (r *UserRepository) DeactivateAdminIfNotLast(ctx context.Context, id string
 	return tx.Commit()
 }
 
-func (r *UserRepository) scanUsers(rows *sql.Rows) ([]*entity.User, error) {
-	var users []*entity.User
+// FindLoginThrottle finds the failed logins of a username or a source IP
+func (r *UserRepository) FindLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) (*entity.LoginThrottle, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT scope, key, failures, lockouts, locked_until, last_failure_at
+		FROM login_throttles WHERE scope = ? AND key = ?
+	`, scope, key)
+	return scanLoginThrottle(row)
+}
 
-	for rows.Next() {
-		user := &entity.User{}
-		var lastLoginAt sql.NullTime
+// AddLoginFailure counts a failed login of a username or a source IP in a
+// single statement, so that concurrent failures are all counted
+func (r *UserRepository) AddLoginFailure(ctx context.Context, scope entity.LoginThrottleScope, key string, at, failuresSince, lockoutsSince time.Time) (*entity.LoginThrottle, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO login_throttles (scope, key, failures, lockouts, locked_until, last_failure_at)
+		VALUES (?, ?, 1, 0, NULL, ?)
+		ON CONFLICT(scope, key) DO UPDATE SET
+			failures = CASE WHEN last_failure_at < ? THEN 1 ELSE failures + 1 END,
+			lockouts = CASE WHEN last_failure_at < ? THEN 0 ELSE lockouts END,
+			last_failure_at = excluded.last_failure_at
+		RETURNING scope, key, failures, lockouts, locked_until, last_failure_at
+	`, scope, key, at, failuresSince, lockoutsSince)
+	return scanLoginThrottle(row)
+}
+
+// LockLogin locks out a username or a source IP which still counts at least
+// failures, so that concurrent failures reaching the limit lock it out once
+func (r *UserRepository) LockLogin(ctx context.Context, scope entity.LoginThrottleScope, key string, failures int, until time.Time) (*entity.LoginThrottle, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE login_throttles SET failures = 0, lockouts = lockouts + 1, locked_until = ?
+		WHERE scope = ? AND key = ? AND failures >= ?
+		RETURNING scope, key, failures, lockouts, locked_until, last_failure_at
+	`, until, scope, key, failures)
+	return scanLoginThrottle(row)
+}
+
+// DeleteLoginThrottle forgets the failed logins of a username or a source IP, lifting its lockout
+func (r *UserRepository) DeleteLoginThrottle(ctx context.Context, scope entity.LoginThrottleScope, key string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM login_throttles WHERE scope = ? AND key = ?", scope, key)
+	return err
+}
 
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive, &lastLoginAt, &user.CreatedAt, &user.UpdatedAt)
+// FindLockedLoginThrottles finds the usernames and source IPs locked out at a time, longest locked first
+func (r *UserRepository) FindLockedLoginThrottles(ctx context.Context, at time.Time) ([]*entity.LoginThrottle, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT scope, key, failures, lockouts, locked_until, last_failure_at
+		FROM login_throttles WHERE locked_until > ? ORDER BY locked_until DESC
+	`, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	throttles := []*entity.LoginThrottle{}
+	for rows.Next() {
+		throttle, err := scanLoginThrottle(rows)
 		if err != nil {
 			return nil, err
 		}
+		throttles = append(throttles, throttle)
+	}
+	return throttles, rows.Err()
+}
 
-		if lastLoginAt.Valid {
-			user.LastLoginAt = &lastLoginAt.Time
-		}
+// scanLoginThrottle scans a login throttle row
+func scanLoginThrottle(row interface{ Scan(dest ...any) error }) (*entity.LoginThrottle, error) {
+	throttle := &entity.LoginThrottle{}
+	var lockedUntil sql.NullTime
+	if err := row.Scan(&throttle.Scope, &throttle.Key, &throttle.Failures, &throttle.Lockouts, &lockedUntil, &throttle.LastFailureAt); err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		throttle.LockedUntil = &lockedUntil.Time
+	}
+	return throttle, nil
+}
+
+func (r *UserRepository) scanUsers(rows *sql.Rows) ([]*entity.User, error) {
+	var users []*entity.User
 
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
 		users = append(users, user)
 	}
 
@@ -268,3 +309,28 @@ func (r *UserRepository) scanUsers(rows *sql.Rows) ([]*entity.User, error) {
 
 	return users, nil
 }
+
+// scanUser scans a user row
+func scanUser(row interface{ Scan(dest ...any) error }) (*entity.User, error) {
+	user := &entity.User{}
+	var lastLoginAt sql.NullTime
+	var displayName, timezone, preferences sql.NullString
+
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive, &lastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt, &displayName, &timezone, &preferences, &user.Workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastLoginAt.Valid {
+		user.LastLoginAt = &lastLoginAt.Time
+	}
+	user.DisplayName = displayName.String
+	user.Timezone = timezone.String
+	if preferences.Valid && preferences.String != "" {
+		// Preferences are a convenience: unreadable ones fall back to the defaults
+		_ = json.Unmarshal([]byte(preferences.String), &user.Preferences)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,133 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// webhookDeliveryColumns lists the selected columns in scan order
+const webhookDeliveryColumns = `id, user_id, channel, event, url, payload, status, attempts,
+	last_status_code, last_error, next_attempt_at, delivered_at, created_at, updated_at`
+
+// WebhookDeliveryRepository implements repository.WebhookDeliveryRepository using SQLite
+type WebhookDeliveryRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookDeliveryRepository creates a new SQLite webhook delivery repository
+func NewWebhookDeliveryRepository(db *sql.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+// Create inserts a new webhook delivery
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (
+			id, user_id, channel, event, url, payload, status, attempts,
+			last_status_code, last_error, next_attempt_at, delivered_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, delivery.ID, delivery.UserID, delivery.Channel, delivery.Event, delivery.URL,
+		string(delivery.Payload), delivery.Status, delivery.Attempts,
+		delivery.LastStatusCode, delivery.LastError, delivery.NextAttemptAt, delivery.DeliveredAt,
+		delivery.CreatedAt, delivery.UpdatedAt)
+
+	return err
+}
+
+// Update saves the outcome of a delivery attempt
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries SET
+			status = ?, attempts = ?, last_status_code = ?, last_error = ?,
+			next_attempt_at = ?, delivered_at = ?, updated_at = ?
+		WHERE id = ?
+	`, delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError,
+		delivery.NextAttemptAt, delivery.DeliveredAt, delivery.UpdatedAt, delivery.ID)
+
+	return err
+}
+
+// FindByID finds a webhook delivery by ID
+func (r *WebhookDeliveryRepository) FindByID(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries WHERE id = ?", id)
+	return scanWebhookDelivery(row)
+}
+
+// FindByUserID returns the most recent deliveries of a user, optionally filtered by status
+func (r *WebhookDeliveryRepository) FindByUserID(
+	ctx context.Context,
+	userID string,
+	status entity.WebhookDeliveryStatus,
+	limit int,
+) ([]*entity.WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE user_id = ?"
+	args := []any{userID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	return r.query(ctx, query, args...)
+}
+
+// FindDue returns pending deliveries whose next attempt is due, oldest first
+func (r *WebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	return r.query(ctx, "SELECT "+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at IS NOT NULL AND next_attempt_at <= ?
+		ORDER BY next_attempt_at LIMIT ?`, entity.WebhookDeliveryPending, now, limit)
+}
+
+func (r *WebhookDeliveryRepository) query(ctx context.Context, query string, args ...any) ([]*entity.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// scanWebhookDelivery scans a webhook delivery row
+func scanWebhookDelivery(row interface{ Scan(dest ...any) error }) (*entity.WebhookDelivery, error) {
+	delivery := &entity.WebhookDelivery{}
+	var payload string
+	var lastStatusCode sql.NullInt64
+	var lastError sql.NullString
+	var nextAttemptAt, deliveredAt sql.NullTime
+
+	err := row.Scan(
+		&delivery.ID, &delivery.UserID, &delivery.Channel, &delivery.Event, &delivery.URL,
+		&payload, &delivery.Status, &delivery.Attempts,
+		&lastStatusCode, &lastError, &nextAttemptAt, &deliveredAt,
+		&delivery.CreatedAt, &delivery.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = []byte(payload)
+	delivery.LastStatusCode = int(lastStatusCode.Int64)
+	delivery.LastError = lastError.String
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,66 @@
+// Package scan checks uploaded payloads for malware before they are stored.
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultCommandTimeout bounds a scan when no timeout is set
+const defaultCommandTimeout = 2 * time.Minute
+
+// CommandScanner scans a payload with an external command, e.g. clamdscan -.
+// The payload is written to the command's standard input and its name is in
+// the PAYLOAD_NAME environment variable. A zero exit status accepts the
+// payload; any other status rejects it, with the command output as the reason.
+type CommandScanner struct {
+	command []string
+	timeout time.Duration
+}
+
+// NewCommandScanner creates a scanner running command, split on whitespace
+func NewCommandScanner(command string, timeout time.Duration) (*CommandScanner, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, errors.New("scan command is required")
+	}
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	return &CommandScanner{command: fields, timeout: timeout}, nil
+}
+
+// Scan runs the command on a payload
+func (s *CommandScanner) Scan(ctx context.Context, name string, content []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Env = append(cmd.Environ(), "PAYLOAD_NAME="+name)
+	cmd.Stdin = bytes.NewReader(content)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("scan timed out after %s", s.timeout)
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	reason := strings.TrimSpace(output.String())
+	if reason == "" {
+		reason = exitErr.Error()
+	}
+	return errors.New(reason)
+}
@@ -0,0 +1,39 @@
+package scan
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandScanner_Scan(t *testing.T) {
+	// Reject payloads containing the EICAR marker
+	scanner := &CommandScanner{
+		command: []string{"sh", "-c", `if grep -q EICAR; then echo "$PAYLOAD_NAME: Eicar-Signature FOUND"; exit 1; fi`},
+		timeout: time.Minute,
+	}
+	if err := scanner.Scan(context.Background(), "clean.sh", []byte("echo hello")); err != nil {
+		t.Errorf("Expected a clean payload to be accepted, got %v", err)
+	}
+	err := scanner.Scan(context.Background(), "eicar.com", []byte("X5O!P%@AP EICAR-STANDARD-ANTIVIRUS-TEST-FILE"))
+	if err == nil || err.Error() != "eicar.com: Eicar-Signature FOUND" {
+		t.Errorf("Expected the scan output as the reason, got %v", err)
+	}
+}
+
+func TestCommandScanner_Errors(t *testing.T) {
+	if _, err := NewCommandScanner("  ", 0); err == nil {
+		t.Error("Expected an error for an empty command")
+	}
+
+	scanner, _ := NewCommandScanner("/nonexistent/scanner", 0)
+	if err := scanner.Scan(context.Background(), "x", []byte("x")); err == nil || !strings.Contains(err.Error(), "scan failed") {
+		t.Errorf("Expected a scan failure, got %v", err)
+	}
+
+	scanner, _ = NewCommandScanner("sleep 5", 50*time.Millisecond)
+	if err := scanner.Scan(context.Background(), "x", nil); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
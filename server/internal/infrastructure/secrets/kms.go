@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/infrastructure/integration"
+)
+
+// KMSConfig locates a symmetric key of AWS KMS
+type KMSConfig struct {
+	KeyID       string // Key ID, ARN or alias, e.g. alias/autostrike
+	Region      string
+	Endpoint    string // e.g. http://localstack:4566; empty for AWS
+	Credentials integration.AWSCredentials
+}
+
+// KMSKey is a master key held by AWS KMS: data keys are wrapped and
+// unwrapped by its Encrypt and Decrypt operations and the key never leaves KMS
+type KMSKey struct {
+	config   KMSConfig
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewKMSKey creates a master key wrapping data keys with a KMS key
+func NewKMSKey(config KMSConfig) (*KMSKey, error) {
+	if config.KeyID == "" {
+		return nil, fmt.Errorf("KMS key ID is required")
+	}
+	if config.Credentials.AccessKeyID == "" || config.Credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("KMS credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://kms." + config.Region + ".amazonaws.com"
+	}
+	if u, err := url.Parse(endpoint); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid KMS endpoint %q", config.Endpoint)
+	}
+
+	return &KMSKey{
+		config:   config,
+		endpoint: strings.TrimSuffix(endpoint, "/") + "/",
+		client:   integration.NewHTTPClient(),
+		now:      time.Now,
+	}, nil
+}
+
+// ID identifies the key by its KMS key ID
+func (k *KMSKey) ID() string {
+	return "kms:" + k.config.KeyID
+}
+
+// WrapKey encrypts a data key with the KMS key
+func (k *KMSKey) WrapKey(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var out struct {
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+	}
+	in := map[string]interface{}{"KeyId": k.config.KeyID, "Plaintext": dataKey}
+	if err := k.call(ctx, "Encrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return out.CiphertextBlob, nil
+}
+
+// UnwrapKey decrypts a data key encrypted by WrapKey
+func (k *KMSKey) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	in := map[string]interface{}{"KeyId": k.config.KeyID, "CiphertextBlob": wrapped}
+	if err := k.call(ctx, "Decrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
+
+// call calls an operation of the KMS JSON API. Byte fields are sent and
+// received in base64, as encoding/json does.
+func (k *KMSKey) call(ctx context.Context, operation string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+operation)
+	integration.SignAWS(req, body, k.config.Credentials, k.config.Region, "kms", k.now())
+
+	if err := integration.DoJSON(ctx, k.client, req, out); err != nil {
+		return fmt.Errorf("KMS %s failed: %w", operation, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Package secrets holds the master keys of the secrets store, the repositories
+// encrypting the credentials they keep and the HashiCorp Vault client.
+package secrets
+
+import (
+	"context"
+
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/domain/repository"
+)
+
+// Sealer encrypts the values kept in the database, as application.SecretService does
+type Sealer interface {
+	Seal(ctx context.Context, value string) (string, error)
+	Open(ctx context.Context, value string) (string, error)
+}
+
+// NotificationRepository encrypts the webhook secret, PagerDuty routing key
+// and Opsgenie API key of notification settings before they are stored, and
+// decrypts them when read. Values stored in plaintext are read as is and
+// encrypted on their next update.
+type NotificationRepository struct {
+	repository.NotificationRepository
+	sealer Sealer
+}
+
+// Ensure NotificationRepository satisfies the repository port
+var _ repository.NotificationRepository = (*NotificationRepository)(nil)
+
+// NewNotificationRepository wraps a notification repository
+func NewNotificationRepository(inner repository.NotificationRepository, sealer Sealer) *NotificationRepository {
+	return &NotificationRepository{NotificationRepository: inner, sealer: sealer}
+}
+
+// CreateSettings stores settings with their credentials encrypted
+func (r *NotificationRepository) CreateSettings(ctx context.Context, settings *entity.NotificationSettings) error {
+	sealed, err := r.seal(ctx, settings)
+	if err != nil {
+		return err
+	}
+	return r.NotificationRepository.CreateSettings(ctx, sealed)
+}
+
+// UpdateSettings stores settings with their credentials encrypted
+func (r *NotificationRepository) UpdateSettings(ctx context.Context, settings *entity.NotificationSettings) error {
+	sealed, err := r.seal(ctx, settings)
+	if err != nil {
+		return err
+	}
+	return r.NotificationRepository.UpdateSettings(ctx, sealed)
+}
+
+// FindSettingsByUserID finds the settings of a user, with their credentials decrypted
+func (r *NotificationRepository) FindSettingsByUserID(ctx context.Context, userID string) (*entity.NotificationSettings, error) {
+	settings, err := r.NotificationRepository.FindSettingsByUserID(ctx, userID)
+	if err != nil || settings == nil {
+		return settings, err
+	}
+	return settings, r.open(ctx, settings)
+}
+
+// FindAllEnabledSettings finds the enabled settings, with their credentials decrypted
+func (r *NotificationRepository) FindAllEnabledSettings(ctx context.Context) ([]*entity.NotificationSettings, error) {
+	all, err := r.NotificationRepository.FindAllEnabledSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, settings := range all {
+		if err := r.open(ctx, settings); err != nil {
+			return nil, err
+		}
+	}
+	return all, nil
+}
+
+// seal returns a copy of settings with their credentials encrypted, leaving
+// the caller's settings untouched
+func (r *NotificationRepository) seal(ctx context.Context, settings *entity.NotificationSettings) (*entity.NotificationSettings, error) {
+	sealed := *settings
+	for _, field := range credentialFields(&sealed) {
+		value, err := r.sealer.Seal(ctx, *field)
+		if err != nil {
+			return nil, err
+		}
+		*field = value
+	}
+	return &sealed, nil
+}
+
+// open decrypts the credentials of settings in place
+func (r *NotificationRepository) open(ctx context.Context, settings *entity.NotificationSettings) error {
+	for _, field := range credentialFields(settings) {
+		value, err := r.sealer.Open(ctx, *field)
+		if err != nil {
+			return err
+		}
+		*field = value
+	}
+	return nil
+}
+
+// credentialFields returns the credentials of notification settings
+func credentialFields(settings *entity.NotificationSettings) []*string {
+	return []*string{&settings.WebhookSecret, &settings.PagerDutyRoutingKey, &settings.OpsgenieAPIKey}
+}
@@ -0,0 +1,191 @@
+package secrets
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+	"autostrike/internal/domain/entity"
+	"autostrike/internal/infrastructure/integration"
+	"autostrike/internal/infrastructure/persistence/sqlite"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestNotificationRepository_SealsCredentials(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqlite.InitSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO users (id, username, email, password_hash, role, created_at, updated_at)
+		VALUES ('user-1', 'user', 'user@example.com', 'hash', 'admin', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := application.NewLocalMasterKey("master-key")
+	inner := sqlite.NewNotificationRepository(db)
+	repo := NewNotificationRepository(inner, application.NewSecretService(sqlite.NewSecretRepository(db), key))
+	ctx := context.Background()
+
+	settings := &entity.NotificationSettings{ID: "set-1", UserID: "user-1", Enabled: true,
+		WebhookURL: "https://hooks.example.com", WebhookSecret: "webhook-signing-secret", OpsgenieAPIKey: "opsgenie-key"}
+	if err := repo.CreateSettings(ctx, settings); err != nil {
+		t.Fatalf("CreateSettings failed: %v", err)
+	}
+	if settings.WebhookSecret != "webhook-signing-secret" {
+		t.Error("Expected the caller's settings untouched")
+	}
+
+	stored, _ := inner.FindSettingsByUserID(ctx, "user-1")
+	if !strings.HasPrefix(stored.WebhookSecret, "enc:v1:") || !strings.HasPrefix(stored.OpsgenieAPIKey, "enc:v1:") ||
+		stored.PagerDutyRoutingKey != "" {
+		t.Fatalf("Expected the credentials stored encrypted, got %+v", stored)
+	}
+	found, err := repo.FindSettingsByUserID(ctx, "user-1")
+	if err != nil || found.WebhookSecret != "webhook-signing-secret" || found.OpsgenieAPIKey != "opsgenie-key" {
+		t.Fatalf("Expected the credentials decrypted, got %+v (%v)", found, err)
+	}
+
+	// Settings stored in plaintext before the secrets store are still read
+	stored.WebhookSecret = "plaintext-secret"
+	if err := inner.UpdateSettings(ctx, stored); err != nil {
+		t.Fatal(err)
+	}
+	all, err := repo.FindAllEnabledSettings(ctx)
+	if err != nil || len(all) != 1 || all[0].WebhookSecret != "plaintext-secret" || all[0].OpsgenieAPIKey != "opsgenie-key" {
+		t.Errorf("Unexpected settings: %+v (%v)", all, err)
+	}
+}
+
+func TestKMSKey_WrapUnwrap(t *testing.T) {
+	var targets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-west-1/kms/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		targets = append(targets, r.Header.Get("X-Amz-Target"))
+		var in map[string][]byte
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		// The fake KMS "encrypts" by reversing the bytes
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"CiphertextBlob": reverse(in["Plaintext"])})
+		case "TrentService.Decrypt":
+			_ = json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": reverse(in["CiphertextBlob"])})
+		default:
+			http.Error(w, `{"__type":"UnknownOperationException"}`, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	key, err := NewKMSKey(KMSConfig{KeyID: "alias/autostrike", Region: "eu-west-1", Endpoint: server.URL,
+		Credentials: integration.AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}})
+	if err != nil {
+		t.Fatalf("NewKMSKey failed: %v", err)
+	}
+	if key.ID() != "kms:alias/autostrike" {
+		t.Errorf("Unexpected ID %s", key.ID())
+	}
+
+	wrapped, err := key.WrapKey(context.Background(), []byte("data-key"))
+	if err != nil || string(wrapped) != "yek-atad" {
+		t.Fatalf("Unexpected wrapped key %q (%v)", wrapped, err)
+	}
+	unwrapped, err := key.UnwrapKey(context.Background(), wrapped)
+	if err != nil || string(unwrapped) != "data-key" {
+		t.Fatalf("Unexpected data key %q (%v)", unwrapped, err)
+	}
+	if len(targets) != 2 || targets[0] != "TrentService.Encrypt" || targets[1] != "TrentService.Decrypt" {
+		t.Errorf("Unexpected operations %v", targets)
+	}
+
+	if _, err := NewKMSKey(KMSConfig{KeyID: "alias/autostrike"}); err == nil {
+		t.Error("Expected credentials required")
+	}
+}
+
+func reverse(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out
+}
+
+func TestVault_LoadRefresh(t *testing.T) {
+	jwtSecret, dbPassword, dbReads, renewals := "jwt-v1", "pass-1", 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/auth/approle/login" && r.Header.Get("X-Vault-Token") != "app-token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var in map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			if in["role_id"] != "role" || in["secret_id"] != "secret" {
+				http.Error(w, `{"errors":["invalid role ID"]}`, http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"app-token","lease_duration":3600,"renewable":true}}`))
+		case "/v1/auth/token/renew-self":
+			renewals++
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"app-token","lease_duration":3600,"renewable":true}}`))
+		case "/v1/secret/data/autostrike":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{
+				"data": map[string]interface{}{"jwt_secret": jwtSecret}, "metadata": map[string]interface{}{"version": 1}}})
+		case "/v1/database/creds/autostrike":
+			dbReads++
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"lease_id": "database/creds/autostrike/1",
+				"renewable": true, "data": map[string]interface{}{"username": "v-autostrike", "password": dbPassword}})
+		case "/v1/sys/leases/renew":
+			// The lease reached its maximum TTL
+			http.Error(w, `{"errors":["lease expired"]}`, http.StatusBadRequest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	vault, err := NewVault(VaultConfig{Address: server.URL, RoleID: "role", SecretID: "secret", Secrets: map[string]string{
+		"JWT_SECRET":  "secret/data/autostrike#jwt_secret",
+		"DB_USER":     "database/creds/autostrike#username",
+		"DB_PASSWORD": "database/creds/autostrike#password",
+	}}, nil)
+	if err != nil {
+		t.Fatalf("NewVault failed: %v", err)
+	}
+	values, err := vault.Load(context.Background())
+	if err != nil || values["JWT_SECRET"] != "jwt-v1" || values["DB_USER"] != "v-autostrike" || values["DB_PASSWORD"] != "pass-1" {
+		t.Fatalf("Unexpected values %v (%v)", values, err)
+	}
+	if dbReads != 1 {
+		t.Errorf("Expected the fields of a path read together, got %d reads", dbReads)
+	}
+
+	changed := make(map[string]string)
+	vault.OnChange(func(name, value string) { changed[name] = value })
+	jwtSecret, dbPassword = "jwt-v2", "pass-2"
+	vault.Refresh(context.Background())
+	if renewals != 1 || len(changed) != 2 || changed["JWT_SECRET"] != "jwt-v2" || changed["DB_PASSWORD"] != "pass-2" {
+		t.Errorf("Expected the token renewed and 2 values changed, got %d renewals and %v", renewals, changed)
+	}
+
+	if _, err := NewVault(VaultConfig{Address: server.URL, Token: "t", Secrets: map[string]string{"X": "secret/data/x"}}, nil); err == nil {
+		t.Error("Expected a reference without field rejected")
+	}
+	if _, err := NewVault(VaultConfig{Address: server.URL}, nil); err == nil {
+		t.Error("Expected credentials required")
+	}
+}
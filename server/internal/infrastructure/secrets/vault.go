@@ -0,0 +1,384 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/infrastructure/integration"
+
+	"go.uber.org/zap"
+)
+
+// DefaultVaultRefreshInterval is how often Vault tokens and leases are renewed
+// and the secrets read again
+const DefaultVaultRefreshInterval = 5 * time.Minute
+
+// VaultConfig locates a HashiCorp Vault server and the secrets read from it
+type VaultConfig struct {
+	Address   string // e.g. https://vault:8200
+	Namespace string // Vault Enterprise namespace, if any
+	Token     string // Static token; ignored with AppRole
+	RoleID    string // AppRole login, with SecretID
+	SecretID  string
+	AuthMount string // Mount of the AppRole auth method, approle by default
+
+	// Secrets maps the environment variables to set to Vault references,
+	// <path>#<field>, e.g. secret/data/autostrike#jwt_secret for KV version 2
+	Secrets         map[string]string
+	RefreshInterval time.Duration
+}
+
+// vaultRef is the field of a secret read from a Vault path
+type vaultRef struct {
+	path  string
+	field string
+}
+
+// vaultLease is the lease of a secret read from a path, renewed until it expires
+type vaultLease struct {
+	id        string
+	renewable bool
+}
+
+// vaultResponse is the body of Vault API responses
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	Renewable     bool                   `json:"renewable"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// Vault reads runtime secrets from HashiCorp Vault into environment
+// variables. Once started, it renews its token and the leases of the secrets
+// and reads them again at each refresh, reporting the values that changed.
+type Vault struct {
+	config VaultConfig
+	refs   map[string]vaultRef // By environment variable
+	client *http.Client
+	logger *zap.Logger
+
+	mu        sync.Mutex
+	token     string
+	renewable bool                  // Whether the token can be renewed
+	leases    map[string]vaultLease // By path
+	values    map[string]string     // By environment variable
+	onChange  func(name, value string)
+
+	running  bool
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewVault creates a Vault client reading the secrets of config
+func NewVault(config VaultConfig, logger *zap.Logger) (*Vault, error) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if u, err := url.Parse(config.Address); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid Vault address %q", config.Address)
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	if config.Token == "" && (config.RoleID == "" || config.SecretID == "") {
+		return nil, fmt.Errorf("a Vault token or an AppRole role ID and secret ID are required")
+	}
+	if config.AuthMount == "" {
+		config.AuthMount = "approle"
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultVaultRefreshInterval
+	}
+
+	refs := make(map[string]vaultRef, len(config.Secrets))
+	for name, ref := range config.Secrets {
+		path, field, ok := strings.Cut(ref, "#")
+		path = strings.Trim(path, "/")
+		if !ok || path == "" || field == "" {
+			return nil, fmt.Errorf("invalid Vault reference %q of %s, <path>#<field> is expected", ref, name)
+		}
+		refs[name] = vaultRef{path: path, field: field}
+	}
+
+	return &Vault{
+		config: config,
+		refs:   refs,
+		client: integration.NewHTTPClient(),
+		logger: logger,
+		token:  config.Token,
+		leases: make(map[string]vaultLease),
+		values: make(map[string]string),
+	}, nil
+}
+
+// OnChange sets the function called, after a refresh, for each secret whose
+// value changed in Vault
+func (v *Vault) OnChange(fn func(name, value string)) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.onChange = fn
+}
+
+// Load logs in and reads every secret, returning their values by environment variable
+func (v *Vault) Load(ctx context.Context) (map[string]string, error) {
+	if v.config.RoleID != "" {
+		if err := v.login(ctx); err != nil {
+			return nil, err
+		}
+	} else if err := v.lookupToken(ctx); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(v.refs))
+	for _, path := range v.paths() {
+		fields, err := v.read(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fields {
+			values[name] = value
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for name, value := range values {
+		v.values[name] = value
+	}
+	return values, nil
+}
+
+// Start renews the token and the leases, and reads the secrets again, every refresh interval
+func (v *Vault) Start() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.running {
+		return
+	}
+	v.running = true
+	v.stopChan = make(chan struct{})
+	v.wg.Add(1)
+	go v.run()
+	v.logger.Info("Vault secret renewal started", zap.Duration("interval", v.config.RefreshInterval))
+}
+
+// Stop stops the renewal
+func (v *Vault) Stop() {
+	v.mu.Lock()
+	if !v.running {
+		v.mu.Unlock()
+		return
+	}
+	v.running = false
+	close(v.stopChan)
+	v.mu.Unlock()
+	v.wg.Wait()
+}
+
+// run refreshes the secrets until stopped
+func (v *Vault) run() {
+	defer v.wg.Done()
+	ticker := time.NewTicker(v.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-v.stopChan:
+			return
+		case <-ticker.C:
+			v.Refresh(context.Background())
+		}
+	}
+}
+
+// Refresh renews the token, then renews the lease of each path or reads it
+// again when the lease cannot be renewed, calling the change function for
+// the values that changed. Errors are logged, the last values being kept.
+func (v *Vault) Refresh(ctx context.Context) {
+	if err := v.renewToken(ctx); err != nil {
+		v.logger.Warn("Failed to renew the Vault token", zap.Error(err))
+	}
+
+	changed := make(map[string]string)
+	for _, path := range v.paths() {
+		if v.renewLease(ctx, path) {
+			continue
+		}
+		fields, err := v.read(ctx, path)
+		if err != nil {
+			v.logger.Warn("Failed to read Vault secret, keeping the last value", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		v.mu.Lock()
+		for name, value := range fields {
+			if v.values[name] != value {
+				v.values[name] = value
+				changed[name] = value
+			}
+		}
+		v.mu.Unlock()
+	}
+
+	v.mu.Lock()
+	onChange := v.onChange
+	v.mu.Unlock()
+	for name, value := range changed {
+		v.logger.Info("Vault secret changed", zap.String("env", name))
+		if onChange != nil {
+			onChange(name, value)
+		}
+	}
+}
+
+// paths returns the Vault paths to read, each once, so that the fields of
+// a dynamic secret come from the same lease
+func (v *Vault) paths() []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, ref := range v.refs {
+		if !seen[ref.path] {
+			seen[ref.path] = true
+			paths = append(paths, ref.path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// read reads a path, returning the referenced fields by environment variable
+// and keeping its lease
+func (v *Vault) read(ctx context.Context, path string) (map[string]string, error) {
+	var resp vaultResponse
+	if err := v.call(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	data := resp.Data
+	// KV version 2 nests the fields under data, next to the metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok && data["metadata"] != nil {
+		data = inner
+	}
+
+	fields := make(map[string]string)
+	for name, ref := range v.refs {
+		if ref.path != path {
+			continue
+		}
+		value, ok := data[ref.field]
+		if !ok || value == nil {
+			return nil, fmt.Errorf("field %s not found in %s", ref.field, path)
+		}
+		if s, ok := value.(string); ok {
+			fields[name] = s
+		} else {
+			fields[name] = fmt.Sprint(value)
+		}
+	}
+
+	v.mu.Lock()
+	v.leases[path] = vaultLease{id: resp.LeaseID, renewable: resp.Renewable}
+	v.mu.Unlock()
+	return fields, nil
+}
+
+// renewLease renews the lease of a path, reporting false when the path has
+// no renewable lease or the renewal failed, and it must be read again
+func (v *Vault) renewLease(ctx context.Context, path string) bool {
+	v.mu.Lock()
+	lease := v.leases[path]
+	v.mu.Unlock()
+	if lease.id == "" || !lease.renewable {
+		return false
+	}
+	if err := v.call(ctx, http.MethodPut, "sys/leases/renew", map[string]string{"lease_id": lease.id}, nil); err != nil {
+		v.logger.Info("Vault lease not renewed, reading the secret again", zap.String("path", path), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// login logs in with AppRole
+func (v *Vault) login(ctx context.Context) error {
+	var resp vaultResponse
+	in := map[string]string{"role_id": v.config.RoleID, "secret_id": v.config.SecretID}
+	if err := v.call(ctx, http.MethodPost, "auth/"+v.config.AuthMount+"/login", in, &resp); err != nil {
+		return fmt.Errorf("Vault AppRole login failed: %w", err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return fmt.Errorf("Vault AppRole login returned no token")
+	}
+	v.mu.Lock()
+	v.token, v.renewable = resp.Auth.ClientToken, resp.Auth.Renewable
+	v.mu.Unlock()
+	return nil
+}
+
+// lookupToken checks the static token, and whether it can be renewed
+func (v *Vault) lookupToken(ctx context.Context) error {
+	var resp vaultResponse
+	if err := v.call(ctx, http.MethodGet, "auth/token/lookup-self", nil, &resp); err != nil {
+		return fmt.Errorf("invalid Vault token: %w", err)
+	}
+	renewable, _ := resp.Data["renewable"].(bool)
+	v.mu.Lock()
+	v.renewable = renewable
+	v.mu.Unlock()
+	return nil
+}
+
+// renewToken renews the token, or logs in again with AppRole when it cannot be renewed
+func (v *Vault) renewToken(ctx context.Context) error {
+	v.mu.Lock()
+	renewable := v.renewable
+	v.mu.Unlock()
+	if renewable {
+		err := v.call(ctx, http.MethodPost, "auth/token/renew-self", map[string]string{}, nil)
+		if err == nil || v.config.RoleID == "" {
+			return err
+		}
+	}
+	if v.config.RoleID == "" {
+		return nil
+	}
+	return v.login(ctx)
+}
+
+// call calls the Vault HTTP API with the current token
+func (v *Vault) call(ctx context.Context, method, path string, in, out interface{}) error {
+	var body *bytes.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, v.config.Address+"/v1/"+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	v.mu.Lock()
+	token := v.token
+	v.mu.Unlock()
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+	return integration.DoJSON(ctx, v.client, req, out)
+}
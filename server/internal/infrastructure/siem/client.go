@@ -0,0 +1,26 @@
+// Package siem provides SIEM connectors used to verify technique detections,
+// and the syslog exporter feeding execution results to SIEM collectors.
+package siem
+
+import (
+	"strings"
+
+	"autostrike/internal/application"
+)
+
+// quoteList returns the values quoted and joined with sep, for query languages
+func quoteList(values []string, sep string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = `"` + strings.ReplaceAll(v, `"`, `\"`) + `"`
+	}
+	return strings.Join(quoted, sep)
+}
+
+// Ensure connectors and the exporter satisfy the application ports
+var (
+	_ application.SIEMConnector = (*SplunkConnector)(nil)
+	_ application.SIEMConnector = (*ElasticConnector)(nil)
+	_ application.SIEMConnector = (*SentinelConnector)(nil)
+	_ application.EventSink     = (*SyslogExporter)(nil)
+)
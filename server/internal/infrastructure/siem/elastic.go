@@ -0,0 +1,133 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+// DefaultElasticIndex is the Elastic Security alerts index for the default space
+const DefaultElasticIndex = ".alerts-security.alerts-default"
+
+// elasticMaxHits bounds the number of alerts fetched per query
+const elasticMaxHits = 50
+
+// ElasticConfig configures the Elastic connector
+type ElasticConfig struct {
+	URL    string // e.g. https://elastic.example.com:9200
+	APIKey string // Base64 encoded API key
+	Index  string // Alerts index, defaults to DefaultElasticIndex
+}
+
+// ElasticConnector queries Elastic Security detection alerts
+type ElasticConnector struct {
+	config ElasticConfig
+	client *http.Client
+}
+
+// NewElasticConnector creates a new Elastic connector
+func NewElasticConnector(config ElasticConfig) *ElasticConnector {
+	if config.Index == "" {
+		config.Index = DefaultElasticIndex
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	return &ElasticConnector{config: config, client: integration.NewHTTPClient()}
+}
+
+// Name returns the connector name
+func (c *ElasticConnector) Name() string {
+	return "elastic"
+}
+
+type elasticSearchResponse struct {
+	Hits struct {
+		Hits []struct {
+			ID     string                   `json:"_id"`
+			Fields map[string][]interface{} `json:"fields"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// SearchAlerts searches alerts on the host tagged with the technique
+func (c *ElasticConnector) SearchAlerts(ctx context.Context, query application.DetectionQuery) ([]application.SIEMAlert, error) {
+	body, err := json.Marshal(buildElasticQuery(query))
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/_search", c.config.URL, url.PathEscape(c.config.Index))
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+c.config.APIKey)
+	}
+
+	var resp elasticSearchResponse
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("elastic search failed: %w", err)
+	}
+
+	alerts := make([]application.SIEMAlert, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		alert := application.SIEMAlert{
+			ID:       hit.ID,
+			RuleName: elasticField(hit.Fields, "kibana.alert.rule.name"),
+			Hostname: elasticField(hit.Fields, "host.name"),
+		}
+		if ts, err := time.Parse(time.RFC3339, elasticField(hit.Fields, "@timestamp")); err == nil {
+			alert.Timestamp = ts
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, nil
+}
+
+func buildElasticQuery(query application.DetectionQuery) map[string]interface{} {
+	return map[string]interface{}{
+		"size":    elasticMaxHits,
+		"_source": false,
+		"fields":  []string{"kibana.alert.rule.name", "host.name", "@timestamp"},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"range": map[string]interface{}{
+						"@timestamp": map[string]string{
+							"gte": query.Start.UTC().Format(time.RFC3339),
+							"lte": query.End.UTC().Format(time.RFC3339),
+						},
+					}},
+					map[string]interface{}{"term": map[string]string{"host.name": query.Hostname}},
+				},
+				"should": []interface{}{
+					map[string]interface{}{"terms": map[string][]string{"kibana.alert.rule.threat.technique.id": query.TechniqueIDs}},
+					map[string]interface{}{"terms": map[string][]string{"kibana.alert.rule.threat.technique.subtechnique.id": query.TechniqueIDs}},
+				},
+				"minimum_should_match": 1,
+			},
+		},
+	}
+}
+
+// elasticField returns the first value of a field from the fields API response
+func elasticField(fields map[string][]interface{}, name string) string {
+	values := fields[name]
+	if len(values) == 0 {
+		return ""
+	}
+	if s, ok := values[0].(string); ok {
+		return s
+	}
+	return fmt.Sprint(values[0])
+}
@@ -0,0 +1,62 @@
+package siem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestElasticConnector_SearchAlerts(t *testing.T) {
+	var gotBody map[string]interface{}
+	var gotAuth, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`{"hits":{"hits":[{"_id":"a1","fields":{
+			"kibana.alert.rule.name":["Credential Dumping"],
+			"host.name":["WS-01"],
+			"@timestamp":["2024-01-15T10:03:00Z"]}}]}}`))
+	}))
+	defer server.Close()
+
+	c := NewElasticConnector(ElasticConfig{URL: server.URL, APIKey: "key"})
+	alerts, err := c.SearchAlerts(context.Background(), testQuery())
+	if err != nil {
+		t.Fatalf("SearchAlerts failed: %v", err)
+	}
+
+	if gotPath != "/"+DefaultElasticIndex+"/_search" {
+		t.Errorf("Unexpected path %s", gotPath)
+	}
+	if gotAuth != "ApiKey key" {
+		t.Errorf("Expected ApiKey auth, got %q", gotAuth)
+	}
+	if gotBody["query"] == nil {
+		t.Error("Expected query in request body")
+	}
+	if len(alerts) != 1 || alerts[0].RuleName != "Credential Dumping" || alerts[0].Hostname != "WS-01" || alerts[0].Timestamp.IsZero() {
+		t.Errorf("Unexpected alerts: %+v", alerts)
+	}
+}
+
+func TestElasticConnector_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"index_not_found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	c := NewElasticConnector(ElasticConfig{URL: server.URL, Index: "custom"})
+	if _, err := c.SearchAlerts(context.Background(), testQuery()); err == nil {
+		t.Error("Expected error for 404")
+	}
+}
+
+func TestElasticField(t *testing.T) {
+	fields := map[string][]interface{}{"n": {float64(3)}, "s": {"x"}}
+	if elasticField(fields, "s") != "x" || elasticField(fields, "n") != "3" || elasticField(fields, "missing") != "" {
+		t.Error("Unexpected elasticField result")
+	}
+}
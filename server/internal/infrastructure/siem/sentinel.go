@@ -0,0 +1,140 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+const (
+	defaultSentinelAPIURL   = "https://api.loganalytics.io"
+	defaultSentinelLoginURL = "https://login.microsoftonline.com"
+	sentinelScope           = "https://api.loganalytics.io/.default"
+)
+
+// SentinelConfig configures the Microsoft Sentinel connector
+type SentinelConfig struct {
+	WorkspaceID  string
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	APIURL       string // Overrides the Log Analytics endpoint (sovereign clouds, tests)
+	LoginURL     string // Overrides the Entra ID login endpoint
+}
+
+// SentinelConnector queries the SecurityAlert table of a Sentinel workspace
+type SentinelConnector struct {
+	config SentinelConfig
+	client *http.Client
+	auth   *integration.ClientCredentials
+}
+
+// NewSentinelConnector creates a new Sentinel connector
+func NewSentinelConnector(config SentinelConfig) *SentinelConnector {
+	if config.APIURL == "" {
+		config.APIURL = defaultSentinelAPIURL
+	}
+	if config.LoginURL == "" {
+		config.LoginURL = defaultSentinelLoginURL
+	}
+	config.APIURL = strings.TrimRight(config.APIURL, "/")
+	config.LoginURL = strings.TrimRight(config.LoginURL, "/")
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", config.LoginURL, url.PathEscape(config.TenantID))
+	return &SentinelConnector{
+		config: config,
+		client: integration.NewHTTPClient(),
+		auth:   integration.NewClientCredentials(tokenURL, config.ClientID, config.ClientSecret, sentinelScope),
+	}
+}
+
+// Name returns the connector name
+func (c *SentinelConnector) Name() string {
+	return "sentinel"
+}
+
+type sentinelQueryResponse struct {
+	Tables []struct {
+		Columns []struct {
+			Name string `json:"name"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"tables"`
+}
+
+// SearchAlerts runs a KQL query against SecurityAlert
+func (c *SentinelConnector) SearchAlerts(ctx context.Context, query application.DetectionQuery) ([]application.SIEMAlert, error) {
+	token, err := c.auth.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sentinel authentication failed: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"query": buildSentinelQuery(query)})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/workspaces/%s/query", c.config.APIURL, url.PathEscape(c.config.WorkspaceID))
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var resp sentinelQueryResponse
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("sentinel query failed: %w", err)
+	}
+
+	var alerts []application.SIEMAlert
+	for _, table := range resp.Tables {
+		index := make(map[string]int, len(table.Columns))
+		for i, col := range table.Columns {
+			index[col.Name] = i
+		}
+		get := func(row []interface{}, name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(row) || row[i] == nil {
+				return ""
+			}
+			return fmt.Sprint(row[i])
+		}
+
+		for _, row := range table.Rows {
+			alert := application.SIEMAlert{
+				ID:       get(row, "SystemAlertId"),
+				RuleName: get(row, "AlertName"),
+				Hostname: get(row, "CompromisedEntity"),
+			}
+			if ts, err := time.Parse(time.RFC3339, get(row, "TimeGenerated")); err == nil {
+				alert.Timestamp = ts
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+func buildSentinelQuery(query application.DetectionQuery) string {
+	host := strings.ReplaceAll(query.Hostname, `"`, `\"`)
+	return fmt.Sprintf(`SecurityAlert
+| where TimeGenerated between (datetime(%s) .. datetime(%s))
+| where CompromisedEntity =~ "%s" or Entities has "%s"
+| where Techniques has_any (%s)
+| project SystemAlertId, AlertName, CompromisedEntity, TimeGenerated
+| take 50`,
+		query.Start.UTC().Format(time.RFC3339),
+		query.End.UTC().Format(time.RFC3339),
+		host, host,
+		quoteList(query.TechniqueIDs, ", "),
+	)
+}
@@ -0,0 +1,72 @@
+package siem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSentinelConnector_SearchAlerts(t *testing.T) {
+	tokenRequests := 0
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/tenant-1/oauth2/v2.0/token":
+			tokenRequests++
+			_ = r.ParseForm()
+			if r.PostForm.Get("client_secret") != "secret" {
+				t.Errorf("Expected client secret in token request")
+			}
+			_, _ = w.Write([]byte(`{"access_token":"tok","expires_in":3600}`))
+		case r.URL.Path == "/v1/workspaces/ws-1/query":
+			if r.Header.Get("Authorization") != "Bearer tok" {
+				t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+			}
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			gotQuery = body["query"]
+			_, _ = w.Write([]byte(`{"tables":[{"columns":[{"name":"SystemAlertId"},{"name":"AlertName"},{"name":"CompromisedEntity"},{"name":"TimeGenerated"}],
+				"rows":[["id-1","Suspicious PowerShell","WS-01","2024-01-15T10:04:00Z"]]}]}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewSentinelConnector(SentinelConfig{
+		WorkspaceID: "ws-1", TenantID: "tenant-1", ClientID: "client", ClientSecret: "secret",
+		APIURL: server.URL, LoginURL: server.URL,
+	})
+
+	for i := 0; i < 2; i++ {
+		alerts, err := c.SearchAlerts(context.Background(), testQuery())
+		if err != nil {
+			t.Fatalf("SearchAlerts failed: %v", err)
+		}
+		if len(alerts) != 1 || alerts[0].ID != "id-1" || alerts[0].RuleName != "Suspicious PowerShell" || alerts[0].Timestamp.IsZero() {
+			t.Errorf("Unexpected alerts: %+v", alerts)
+		}
+	}
+
+	if tokenRequests != 1 {
+		t.Errorf("Expected token to be cached, got %d token requests", tokenRequests)
+	}
+	if !strings.Contains(gotQuery, `CompromisedEntity =~ "WS-01"`) || !strings.Contains(gotQuery, `has_any ("T1059.001", "T1059")`) {
+		t.Errorf("Unexpected KQL: %s", gotQuery)
+	}
+}
+
+func TestSentinelConnector_AuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_client", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c := NewSentinelConnector(SentinelConfig{WorkspaceID: "ws", TenantID: "t", APIURL: server.URL, LoginURL: server.URL})
+	if _, err := c.SearchAlerts(context.Background(), testQuery()); err == nil || !strings.Contains(err.Error(), "authentication") {
+		t.Errorf("Expected authentication error, got %v", err)
+	}
+}
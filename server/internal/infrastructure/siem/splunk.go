@@ -0,0 +1,135 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+// DefaultSplunkSearch searches Enterprise Security notable events.
+// {host} and {techniques} are replaced with the quoted hostname and technique IDs.
+const DefaultSplunkSearch = `search index=notable (dest={host} OR host={host}) (annotations.mitre_attack IN ({techniques}) OR {techniques_or})`
+
+// SplunkConfig configures the Splunk connector
+type SplunkConfig struct {
+	URL    string // e.g. https://splunk.example.com:8089
+	Token  string // Bearer authentication token
+	Search string // SPL template, defaults to DefaultSplunkSearch
+}
+
+// SplunkConnector queries Splunk via the search export REST endpoint
+type SplunkConnector struct {
+	config SplunkConfig
+	client *http.Client
+}
+
+// NewSplunkConnector creates a new Splunk connector
+func NewSplunkConnector(config SplunkConfig) *SplunkConnector {
+	if config.Search == "" {
+		config.Search = DefaultSplunkSearch
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	return &SplunkConnector{config: config, client: integration.NewHTTPClient()}
+}
+
+// Name returns the connector name
+func (c *SplunkConnector) Name() string {
+	return "splunk"
+}
+
+// SearchAlerts runs the configured search over the correlation window
+func (c *SplunkConnector) SearchAlerts(ctx context.Context, query application.DetectionQuery) ([]application.SIEMAlert, error) {
+	form := url.Values{}
+	form.Set("search", c.buildSearch(query))
+	form.Set("output_mode", "json")
+	form.Set("earliest_time", strconv.FormatInt(query.Start.Unix(), 10))
+	form.Set("latest_time", strconv.FormatInt(query.End.Unix(), 10))
+
+	req, err := http.NewRequest(http.MethodPost, c.config.URL+"/services/search/jobs/export", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+c.config.Token)
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("splunk search failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := integration.CheckStatus(resp); err != nil {
+		return nil, fmt.Errorf("splunk search failed: %w", err)
+	}
+
+	return parseSplunkExport(resp.Body, query.Hostname)
+}
+
+func (c *SplunkConnector) buildSearch(query application.DetectionQuery) string {
+	return strings.NewReplacer(
+		"{host}", quoteList([]string{query.Hostname}, ""),
+		"{techniques}", quoteList(query.TechniqueIDs, ", "),
+		"{techniques_or}", quoteList(query.TechniqueIDs, " OR "),
+	).Replace(c.config.Search)
+}
+
+// splunkExportLine is one line of the newline-delimited JSON export stream
+type splunkExportLine struct {
+	Preview bool              `json:"preview"`
+	Result  map[string]string `json:"result"`
+}
+
+func parseSplunkExport(r io.Reader, hostname string) ([]application.SIEMAlert, error) {
+	var alerts []application.SIEMAlert
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var entry splunkExportLine
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse splunk response: %w", err)
+		}
+		if entry.Preview || entry.Result == nil {
+			continue
+		}
+
+		alert := application.SIEMAlert{
+			ID:       firstNonEmpty(entry.Result["event_id"], entry.Result["_cd"]),
+			RuleName: firstNonEmpty(entry.Result["search_name"], entry.Result["rule_name"], entry.Result["source"]),
+			Hostname: firstNonEmpty(entry.Result["dest"], entry.Result["host"], hostname),
+		}
+		if ts, err := time.Parse(time.RFC3339, entry.Result["_time"]); err == nil {
+			alert.Timestamp = ts
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read splunk response: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package siem
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/application"
+)
+
+func testQuery() application.DetectionQuery {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	return application.DetectionQuery{
+		TechniqueIDs: []string{"T1059.001", "T1059"},
+		Hostname:     "WS-01",
+		Start:        start,
+		End:          start.Add(10 * time.Minute),
+	}
+}
+
+func TestSplunkConnector_SearchAlerts(t *testing.T) {
+	var gotSearch, gotAuth, gotEarliest string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/services/search/jobs/export" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		_ = r.ParseForm()
+		gotSearch = r.PostForm.Get("search")
+		gotEarliest = r.PostForm.Get("earliest_time")
+		gotAuth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"preview":true,"result":{"search_name":"partial"}}
+{"preview":false,"result":{"event_id":"e1","search_name":"Suspicious PowerShell","dest":"WS-01","_time":"2024-01-15T10:02:00.000+00:00"}}
+`))
+	}))
+	defer server.Close()
+
+	c := NewSplunkConnector(SplunkConfig{URL: server.URL + "/", Token: "secret"})
+	alerts, err := c.SearchAlerts(context.Background(), testQuery())
+	if err != nil {
+		t.Fatalf("SearchAlerts failed: %v", err)
+	}
+
+	if gotAuth != "Bearer secret" {
+		t.Errorf("Expected bearer auth, got %q", gotAuth)
+	}
+	if !strings.Contains(gotSearch, `dest="WS-01"`) || !strings.Contains(gotSearch, `"T1059.001", "T1059"`) {
+		t.Errorf("Search not templated: %s", gotSearch)
+	}
+	if gotEarliest != "1705312800" {
+		t.Errorf("Unexpected earliest_time %s", gotEarliest)
+	}
+	if len(alerts) != 1 {
+		t.Fatalf("Expected preview rows to be skipped, got %d alerts", len(alerts))
+	}
+	if alerts[0].ID != "e1" || alerts[0].RuleName != "Suspicious PowerShell" || alerts[0].Timestamp.IsZero() {
+		t.Errorf("Unexpected alert: %+v", alerts[0])
+	}
+}
+
+func TestSplunkConnector_CustomSearchEscapesHost(t *testing.T) {
+	c := NewSplunkConnector(SplunkConfig{URL: "http://splunk", Search: "search index=main host={host} {techniques_or}"})
+	q := testQuery()
+	q.Hostname = `WS"01`
+
+	got := c.buildSearch(q)
+	want := `search index=main host="WS\"01" "T1059.001" OR "T1059"`
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestSplunkConnector_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c := NewSplunkConnector(SplunkConfig{URL: server.URL})
+	if _, err := c.SearchAlerts(context.Background(), testQuery()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+}
+
+func TestSplunkConnector_InvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+
+	c := NewSplunkConnector(SplunkConfig{URL: server.URL})
+	if _, err := c.SearchAlerts(context.Background(), testQuery()); err == nil {
+		t.Error("Expected parse error")
+	}
+}
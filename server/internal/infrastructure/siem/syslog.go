@@ -0,0 +1,270 @@
+package siem
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// Syslog message formats
+const (
+	SyslogFormatRFC5424 = "rfc5424"
+	SyslogFormatCEF     = "cef"
+)
+
+const (
+	syslogAppName    = "autostrike"
+	syslogFacility   = 16    // local0
+	syslogEnterprise = 32473 // Private enterprise number reserved for examples (RFC 5612)
+	syslogTimeout    = 5 * time.Second
+)
+
+// SyslogConfig configures the syslog exporter
+type SyslogConfig struct {
+	Address  string // Collector host:port
+	Protocol string // udp (default) or tcp
+	Format   string // rfc5424 (default) or cef
+}
+
+// SyslogExporter sends every completed execution result to a syslog collector,
+// as an RFC 5424 message or a CEF event, so SOC teams can correlate simulated
+// techniques with their detections. It is registered as an event bus sink.
+type SyslogExporter struct {
+	config    SyslogConfig
+	hostname  string
+	logger    *zap.Logger
+	semaphore chan struct{} // Bounds pending sends
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogExporter creates a syslog exporter. The connection is opened on the
+// first message and reopened after a write error.
+func NewSyslogExporter(config SyslogConfig, logger *zap.Logger) (*SyslogExporter, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+	if config.Protocol == "" {
+		config.Protocol = "udp"
+	}
+	if config.Protocol != "udp" && config.Protocol != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog protocol %q", config.Protocol)
+	}
+	if config.Format == "" {
+		config.Format = SyslogFormatRFC5424
+	}
+	if config.Format != SyslogFormatRFC5424 && config.Format != SyslogFormatCEF {
+		return nil, fmt.Errorf("unsupported syslog format %q", config.Format)
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogExporter{
+		config:    config,
+		hostname:  hostname,
+		logger:    logger,
+		semaphore: make(chan struct{}, 10),
+	}, nil
+}
+
+// Name identifies the sink in logs
+func (e *SyslogExporter) Name() string {
+	return "syslog"
+}
+
+// Handle sends completed results in the background and ignores other events
+func (e *SyslogExporter) Handle(_ context.Context, event *entity.Event) error {
+	if event.Type != entity.EventResultCompleted || event.Result == nil {
+		return nil
+	}
+
+	message := e.Format(event.Result, event.OccurredAt)
+	go func() {
+		e.semaphore <- struct{}{}
+		defer func() { <-e.semaphore }()
+		if err := e.send(message); err != nil {
+			e.logger.Error("Failed to send result to syslog",
+				zap.String("result_id", event.Result.ID),
+				zap.String("address", e.config.Address),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
+
+// Close closes the collector connection
+func (e *SyslogExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// Format renders a result as a syslog message in the configured format
+func (e *SyslogExporter) Format(result *entity.ExecutionResult, occurredAt time.Time) string {
+	timestamp := occurredAt
+	if result.CompletedAt != nil {
+		timestamp = *result.CompletedAt
+	}
+
+	structuredData := "-"
+	msg := cefEvent(result, timestamp)
+	if e.config.Format == SyslogFormatRFC5424 {
+		structuredData = resultStructuredData(result)
+		msg = fmt.Sprintf("Technique %s %s on agent %s", result.TechniqueID, result.Status, result.AgentPaw)
+	}
+
+	priority := syslogFacility*8 + syslogSeverity(result.Status)
+	return fmt.Sprintf("<%d>1 %s %s %s - result %s %s",
+		priority, timestamp.UTC().Format(time.RFC3339Nano), e.hostname, syslogAppName, structuredData, msg)
+}
+
+// send writes a message, reconnecting once if the connection was lost
+func (e *SyslogExporter) send(message string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if e.conn == nil {
+			e.conn, err = net.DialTimeout(e.config.Protocol, e.config.Address, syslogTimeout)
+			if err != nil {
+				e.conn = nil
+				return err
+			}
+		}
+
+		_ = e.conn.SetWriteDeadline(time.Now().Add(syslogTimeout))
+		if _, err = e.conn.Write(e.frame(message)); err == nil {
+			return nil
+		}
+		_ = e.conn.Close()
+		e.conn = nil
+	}
+	return err
+}
+
+// frame prefixes TCP messages with their length (octet counting, RFC 6587);
+// UDP carries one message per datagram
+func (e *SyslogExporter) frame(message string) []byte {
+	if e.config.Protocol == "tcp" {
+		return []byte(strconv.Itoa(len(message)) + " " + message)
+	}
+	return []byte(message)
+}
+
+// syslogSeverity maps a result to a syslog severity: an undetected technique
+// is a warning, a stopped one is informational
+func syslogSeverity(status entity.ResultStatus) int {
+	switch status {
+	case entity.StatusSuccess:
+		return 4 // warning
+	case entity.StatusBlocked, entity.StatusDetected:
+		return 6 // informational
+	default:
+		return 5 // notice
+	}
+}
+
+// cefSeverity maps a result to a CEF severity (0-10)
+func cefSeverity(status entity.ResultStatus) int {
+	switch status {
+	case entity.StatusSuccess:
+		return 7
+	case entity.StatusDetected:
+		return 3
+	case entity.StatusBlocked:
+		return 1
+	default:
+		return 5
+	}
+}
+
+// resultStructuredData renders the RFC 5424 structured data element of a result
+func resultStructuredData(result *entity.ExecutionResult) string {
+	params := [][2]string{
+		{"execution", result.ExecutionID},
+		{"result", result.ID},
+		{"technique", result.TechniqueID},
+		{"agent", result.AgentPaw},
+		{"attempt", strconv.Itoa(result.Attempt)},
+		{"status", string(result.Status)},
+		{"detected", strconv.FormatBool(result.Detected)},
+		{"exit_code", strconv.Itoa(result.ExitCode)},
+	}
+	if result.DetectedBy != "" {
+		params = append(params, [2]string{"detected_by", result.DetectedBy})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s@%d", syslogAppName, syslogEnterprise)
+	for _, p := range params {
+		fmt.Fprintf(&b, ` %s="%s"`, p[0], sdEscaper.Replace(p[1]))
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// cefEvent renders a result as a CEF event
+func cefEvent(result *entity.ExecutionResult, timestamp time.Time) string {
+	name := fmt.Sprintf("Technique %s %s", result.TechniqueID, result.Status)
+	outcome := "undetected"
+	if result.Detected {
+		outcome = "detected"
+	}
+	extensions := [][2]string{
+		{"rt", strconv.FormatInt(timestamp.UnixMilli(), 10)},
+		{"act", string(result.Status)},
+		{"outcome", outcome},
+		{"cs1Label", "executionId"},
+		{"cs1", result.ExecutionID},
+		{"cs2Label", "resultId"},
+		{"cs2", result.ID},
+		{"cs3Label", "agentPaw"},
+		{"cs3", result.AgentPaw},
+		{"cs4Label", "detectedBy"},
+		{"cs4", result.DetectedBy},
+		{"cn1Label", "exitCode"},
+		{"cn1", strconv.Itoa(result.ExitCode)},
+		{"cn2Label", "attempt"},
+		{"cn2", strconv.Itoa(result.Attempt)},
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|AutoStrike|AutoStrike|1.0|%s|%s|%d|",
+		cefHeaderEscaper.Replace(result.TechniqueID), cefHeaderEscaper.Replace(name), cefSeverity(result.Status))
+	for i, ext := range extensions {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(ext[0] + "=" + cefExtensionEscaper.Replace(ext[1]))
+	}
+	return b.String()
+}
+
+var (
+	sdEscaper           = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+)
@@ -0,0 +1,133 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+func testSyslogResult() *entity.ExecutionResult {
+	completed := time.Date(2024, 1, 15, 10, 5, 0, 0, time.UTC)
+	return &entity.ExecutionResult{
+		ID: "r1", ExecutionID: "e1", TechniqueID: "T1059.001", AgentPaw: "paw-1", Attempt: 1,
+		Status: entity.StatusDetected, Detected: true, DetectedBy: `splunk: "PowerShell" [encoded]`,
+		CompletedAt: &completed,
+	}
+}
+
+func TestSyslogExporter_FormatRFC5424(t *testing.T) {
+	e, err := NewSyslogExporter(SyslogConfig{Address: "127.0.0.1:514"}, nil)
+	if err != nil {
+		t.Fatalf("NewSyslogExporter failed: %v", err)
+	}
+	e.hostname = "autostrike-01"
+
+	msg := e.Format(testSyslogResult(), time.Now())
+	expected := `<134>1 2024-01-15T10:05:00Z autostrike-01 autostrike - result ` +
+		`[autostrike@32473 execution="e1" result="r1" technique="T1059.001" agent="paw-1" attempt="1" status="detected" detected="true" exit_code="0" detected_by="splunk: \"PowerShell\" [encoded\]"] ` +
+		`Technique T1059.001 detected on agent paw-1`
+	if msg != expected {
+		t.Errorf("Unexpected message:\n got %s\nwant %s", msg, expected)
+	}
+}
+
+func TestSyslogExporter_FormatCEF(t *testing.T) {
+	e, _ := NewSyslogExporter(SyslogConfig{Address: "127.0.0.1:514", Format: SyslogFormatCEF}, nil)
+	e.hostname = "autostrike-01"
+
+	result := testSyslogResult()
+	result.Status = entity.StatusSuccess
+	result.Detected = false
+	result.DetectedBy = "a=b|c"
+
+	msg := e.Format(result, time.Now())
+	if !strings.HasPrefix(msg, "<132>1 2024-01-15T10:05:00Z autostrike-01 autostrike - result - CEF:0|AutoStrike|AutoStrike|1.0|T1059.001|Technique T1059.001 success|7|") {
+		t.Errorf("Unexpected CEF header: %s", msg)
+	}
+	for _, ext := range []string{"rt=1705313100000", "act=success", "outcome=undetected", "cs1=e1", "cs4=a\\=b|c", "cn2=1"} {
+		if !strings.Contains(msg, " "+ext) && !strings.Contains(msg, "|"+ext) {
+			t.Errorf("Expected %q in %s", ext, msg)
+		}
+	}
+}
+
+func TestNewSyslogExporter_InvalidConfig(t *testing.T) {
+	configs := []SyslogConfig{
+		{},
+		{Address: "127.0.0.1:514", Protocol: "http"},
+		{Address: "127.0.0.1:514", Format: "json"},
+	}
+	for _, config := range configs {
+		if _, err := NewSyslogExporter(config, nil); err == nil {
+			t.Errorf("Expected error for %+v", config)
+		}
+	}
+}
+
+func TestSyslogExporter_SendsUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer conn.Close()
+
+	e, _ := NewSyslogExporter(SyslogConfig{Address: conn.LocalAddr().String()}, nil)
+	defer e.Close()
+
+	// Events other than completed results are not exported
+	_ = e.Handle(context.Background(), &entity.Event{Type: entity.EventExecutionStarted})
+	_ = e.Handle(context.Background(), &entity.Event{Type: entity.EventResultCompleted, Result: testSyslogResult()})
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 2048)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("Expected a datagram: %v", err)
+	}
+	if !strings.Contains(string(buf[:n]), `result="r1"`) {
+		t.Errorf("Unexpected datagram: %s", buf[:n])
+	}
+}
+
+func TestSyslogExporter_SendsTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		length, _ := reader.ReadString(' ')
+		size, _ := strconv.Atoi(strings.TrimSpace(length))
+		buf := make([]byte, size)
+		_, _ = io.ReadFull(reader, buf)
+		received <- string(buf)
+	}()
+
+	e, _ := NewSyslogExporter(SyslogConfig{Address: listener.Addr().String(), Protocol: "tcp", Format: SyslogFormatCEF}, nil)
+	defer e.Close()
+	_ = e.Handle(context.Background(), &entity.Event{Type: entity.EventResultCompleted, Result: testSyslogResult()})
+
+	select {
+	case msg := <-received:
+		if !strings.Contains(msg, "CEF:0|AutoStrike|") || !strings.HasSuffix(msg, "cn2=1") {
+			t.Errorf("Unexpected framed message: %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for message")
+	}
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"autostrike/internal/infrastructure/integration"
+)
+
+// S3Config locates a bucket of AWS S3 or of an S3-compatible service such as MinIO
+type S3Config struct {
+	Endpoint        string // e.g. https://minio:9000; empty for AWS
+	Region          string
+	Bucket          string
+	Prefix          string // Prepended to every key, e.g. autostrike/archive
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Temporary credentials only
+	PathStyle       bool   // Bucket in the path instead of the host name, as MinIO expects
+}
+
+// S3Store writes objects to an S3 bucket, signing requests with AWS Signature Version 4
+type S3Store struct {
+	config S3Config
+	base   *url.URL
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Store creates a store writing to the bucket of config
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	base, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+
+	return &S3Store{config: config, base: base, client: integration.NewHTTPClient(), now: time.Now}, nil
+}
+
+// Location returns the bucket and prefix objects are written to
+func (s *S3Store) Location() string {
+	if s.config.Prefix == "" {
+		return "s3://" + s.config.Bucket
+	}
+	return "s3://" + s.config.Bucket + "/" + s.config.Prefix
+}
+
+// Put uploads an object
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	s.sign(req, data)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if err := integration.CheckStatus(resp); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, nil)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("failed to download %s: %w", key, fs.ErrNotExist)
+	}
+	if err := integration.CheckStatus(resp); err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// Delete removes an object; S3 answers 204 for missing objects too
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	s.sign(req, nil)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err := integration.CheckStatus(resp); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// objectURL returns the URL of a key, with the bucket in the host name or the path
+func (s *S3Store) objectURL(key string) string {
+	if s.config.Prefix != "" {
+		key = s.config.Prefix + "/" + key
+	}
+	u := *s.base
+	if s.config.PathStyle {
+		u.Path = "/" + s.config.Bucket + "/" + key
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = integration.EscapeAWSPath(u.Path)
+	return u.String()
+}
+
+// sign adds the AWS Signature Version 4 headers of a request to S3
+func (s *S3Store) sign(req *http.Request, payload []byte) {
+	credentials := integration.AWSCredentials{
+		AccessKeyID:     s.config.AccessKeyID,
+		SecretAccessKey: s.config.SecretAccessKey,
+		SessionToken:    s.config.SessionToken,
+	}
+	integration.SignAWS(req, payload, credentials, s.config.Region, "s3", s.now())
+}
@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"autostrike/internal/infrastructure/integration"
+)
+
+// kafkaJSONContentType is the embedded JSON format of the REST proxy v2 API
+const kafkaJSONContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaConfig configures the Kafka publisher
+type KafkaConfig struct {
+	RESTURL  string // REST proxy URL (Confluent REST Proxy, Redpanda HTTP Proxy), e.g. http://kafka-rest:8082
+	Username string // Basic authentication, optional
+	Password string
+}
+
+// KafkaPublisher produces records through a Kafka REST proxy, so the server
+// needs no broker client or network access to the brokers themselves
+type KafkaPublisher struct {
+	config KafkaConfig
+	client *http.Client
+}
+
+// NewKafkaPublisher creates a Kafka publisher
+func NewKafkaPublisher(config KafkaConfig) *KafkaPublisher {
+	config.RESTURL = strings.TrimRight(config.RESTURL, "/")
+	return &KafkaPublisher{config: config, client: integration.NewHTTPClient()}
+}
+
+// Name returns the publisher name
+func (p *KafkaPublisher) Name() string {
+	return DriverKafka
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// Publish produces a batch of records to a topic in one request
+func (p *KafkaPublisher) Publish(ctx context.Context, topic string, messages []Message) error {
+	records := make([]kafkaRecord, len(messages))
+	for i, message := range messages {
+		records[i] = kafkaRecord{Key: message.Key, Value: message.Value}
+	}
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.config.RESTURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaJSONContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json, application/json")
+	if p.config.Username != "" {
+		req.SetBasicAuth(p.config.Username, p.config.Password)
+	}
+
+	var resp kafkaProduceResponse
+	if err := integration.DoJSON(ctx, p.client, req, &resp); err != nil {
+		return fmt.Errorf("kafka produce to %s failed: %w", topic, err)
+	}
+	// The proxy answers 200 even when some records were rejected
+	for _, offset := range resp.Offsets {
+		if offset.ErrorCode != nil || offset.Error != "" {
+			return fmt.Errorf("kafka produce to %s failed: %s", topic, offset.Error)
+		}
+	}
+	return nil
+}
+
+// Close releases nothing; requests are independent
+func (p *KafkaPublisher) Close() error {
+	return nil
+}
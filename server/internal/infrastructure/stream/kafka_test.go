@@ -0,0 +1,69 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKafkaPublisher_Publish(t *testing.T) {
+	var gotPath, gotType, gotUser, gotPass string
+	var gotBody struct {
+		Records []struct {
+			Key   string          `json:"key"`
+			Value json.RawMessage `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotType = r.Header.Get("Content-Type")
+		gotUser, gotPass, _ = r.BasicAuth()
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"partition":0,"offset":2}]}`))
+	}))
+	defer server.Close()
+
+	p := NewKafkaPublisher(KafkaConfig{RESTURL: server.URL + "/", Username: "user", Password: "pass"})
+	messages := []Message{
+		{Key: "exec-1", Value: json.RawMessage(`{"id":"e1"}`)},
+		{Key: "exec-1", Value: json.RawMessage(`{"id":"e2"}`)},
+	}
+	if err := p.Publish(context.Background(), "autostrike.results", messages); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if gotPath != "/topics/autostrike.results" {
+		t.Errorf("Unexpected path %s", gotPath)
+	}
+	if gotType != kafkaJSONContentType {
+		t.Errorf("Unexpected content type %s", gotType)
+	}
+	if gotUser != "user" || gotPass != "pass" {
+		t.Errorf("Expected basic auth, got %q %q", gotUser, gotPass)
+	}
+	if len(gotBody.Records) != 2 || gotBody.Records[0].Key != "exec-1" || string(gotBody.Records[1].Value) != `{"id":"e2"}` {
+		t.Errorf("Unexpected records: %+v", gotBody.Records)
+	}
+}
+
+func TestKafkaPublisher_Errors(t *testing.T) {
+	status, body := http.StatusOK, `{"offsets":[{"partition":0,"offset":1},{"error_code":40403,"error":"Topic not authorized"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	p := NewKafkaPublisher(KafkaConfig{RESTURL: server.URL})
+	messages := []Message{{Value: json.RawMessage(`{}`)}}
+	if err := p.Publish(context.Background(), "t", messages); err == nil {
+		t.Error("Expected error for a rejected record")
+	}
+
+	status, body = http.StatusNotFound, `{"error_code":40401,"message":"Topic not found"}`
+	if err := p.Publish(context.Background(), "t", messages); err == nil {
+		t.Error("Expected error for 404")
+	}
+}
@@ -0,0 +1,195 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsDialTimeout bounds connecting and the handshake with the server
+const natsDialTimeout = 5 * time.Second
+
+// NATSConfig configures the NATS publisher
+type NATSConfig struct {
+	URL      string // nats://host:4222, or tls://host:4222; credentials may be set in the URL
+	Token    string // Authentication token, optional
+	Username string // Username and password, optional
+	Password string
+}
+
+// NATSPublisher publishes messages on NATS subjects with the core text
+// protocol. The connection is opened on the first batch and reopened after
+// an error.
+type NATSPublisher struct {
+	config NATSConfig
+	target *url.URL
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSPublisher creates a NATS publisher
+func NewNATSPublisher(config NATSConfig) (*NATSPublisher, error) {
+	target, err := url.Parse(config.URL)
+	if err != nil || target.Host == "" {
+		return nil, fmt.Errorf("invalid nats URL %q", config.URL)
+	}
+	if target.Scheme != "nats" && target.Scheme != "tls" {
+		return nil, fmt.Errorf("unsupported nats URL scheme %q, use nats or tls", target.Scheme)
+	}
+	if target.Port() == "" {
+		target.Host = net.JoinHostPort(target.Hostname(), "4222")
+	}
+	if target.User != nil && config.Username == "" {
+		config.Username = target.User.Username()
+		config.Password, _ = target.User.Password()
+	}
+	return &NATSPublisher{config: config, target: target}, nil
+}
+
+// Name returns the publisher name
+func (p *NATSPublisher) Name() string {
+	return DriverNATS
+}
+
+// Publish sends a batch of messages on a subject, then waits for the server
+// to acknowledge them with a PONG. The key is not part of core NATS messages.
+func (p *NATSPublisher) Publish(ctx context.Context, subject string, messages []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var buf strings.Builder
+	for _, message := range messages {
+		fmt.Fprintf(&buf, "PUB %s %d\r\n", subject, len(message.Value))
+		buf.Write(message.Value)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("PING\r\n")
+
+	var err error
+	for i := 0; i < 2; i++ {
+		if p.conn == nil {
+			if err = p.connect(ctx); err != nil {
+				return fmt.Errorf("nats connection failed: %w", err)
+			}
+		}
+		if err = p.send(ctx, buf.String()); err == nil {
+			return nil
+		}
+		p.closeConn()
+	}
+	return fmt.Errorf("nats publish to %s failed: %w", subject, err)
+}
+
+// Close closes the server connection
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closeConn()
+}
+
+func (p *NATSPublisher) closeConn() error {
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn, p.reader = nil, nil
+	return err
+}
+
+// natsInfo is the part of the server INFO used by the client
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+// connect dials the server, upgrades to TLS when required and authenticates
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.target.Host)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(natsDialTimeout))
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(line))
+	}
+	var info natsInfo
+	_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "INFO ")), &info)
+
+	if info.TLSRequired || p.target.Scheme == "tls" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.target.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return err
+		}
+		conn = tlsConn
+		reader = bufio.NewReader(conn)
+	}
+	p.conn, p.reader = conn, reader
+
+	options := map[string]any{
+		"verbose": false, "pedantic": false, "tls_required": info.TLSRequired || p.target.Scheme == "tls",
+		"name": "autostrike", "lang": "go", "version": "1.0", "protocol": 1,
+	}
+	if p.config.Token != "" {
+		options["auth_token"] = p.config.Token
+	}
+	if p.config.Username != "" {
+		options["user"] = p.config.Username
+		options["pass"] = p.config.Password
+	}
+	connect, _ := json.Marshal(options)
+	if err := p.send(ctx, "CONNECT "+string(connect)+"\r\nPING\r\n"); err != nil {
+		p.closeConn()
+		return err
+	}
+	return nil
+}
+
+// send writes protocol lines ending with a PING and reads until the PONG,
+// answering server pings and failing on -ERR
+func (p *NATSPublisher) send(ctx context.Context, data string) error {
+	deadline := time.Now().Add(natsDialTimeout)
+	if d, ok := ctx.Deadline(); ok {
+		deadline = d
+	}
+	_ = p.conn.SetDeadline(deadline)
+
+	if _, err := p.conn.Write([]byte(data)); err != nil {
+		return err
+	}
+	for {
+		line, err := p.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// INFO updates and +OK are ignored
+	}
+}
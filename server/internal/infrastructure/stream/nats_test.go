@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// natsPub is a message received by the test server
+type natsPub struct {
+	subject, payload string
+}
+
+// startNATSServer runs a minimal NATS server accepting one connection. The
+// CONNECT options and the published messages are sent on the channels.
+func startNATSServer(t *testing.T, authErr bool) (string, chan map[string]any, chan natsPub) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	connects := make(chan map[string]any, 1)
+	pubs := make(chan natsPub, 16)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			switch fields[0] {
+			case "CONNECT":
+				var options map[string]any
+				_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &options)
+				connects <- options
+				if authErr {
+					fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+					return
+				}
+			case "PUB":
+				size, _ := strconv.Atoi(fields[len(fields)-1])
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(reader, payload); err != nil {
+					return
+				}
+				pubs <- natsPub{subject: fields[1], payload: string(payload[:size])}
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			}
+		}
+	}()
+	return listener.Addr().String(), connects, pubs
+}
+
+func TestNATSPublisher_Publish(t *testing.T) {
+	addr, connects, pubs := startNATSServer(t, false)
+
+	p, err := NewNATSPublisher(NATSConfig{URL: "nats://user:pass@" + addr})
+	if err != nil {
+		t.Fatalf("NewNATSPublisher failed: %v", err)
+	}
+	defer p.Close()
+
+	messages := []Message{
+		{Key: "exec-1", Value: json.RawMessage(`{"id":"e1"}`)},
+		{Key: "exec-1", Value: json.RawMessage(`{"id":"e2"}`)},
+	}
+	if err := p.Publish(context.Background(), "autostrike.results", messages); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	options := <-connects
+	if options["user"] != "user" || options["pass"] != "pass" || options["verbose"] != false {
+		t.Errorf("Unexpected CONNECT options: %+v", options)
+	}
+	for _, want := range []string{`{"id":"e1"}`, `{"id":"e2"}`} {
+		got := <-pubs
+		if got.subject != "autostrike.results" || got.payload != want {
+			t.Errorf("Unexpected message %+v, want %s", got, want)
+		}
+	}
+}
+
+func TestNATSPublisher_AuthError(t *testing.T) {
+	addr, _, _ := startNATSServer(t, true)
+
+	p, err := NewNATSPublisher(NATSConfig{URL: "nats://" + addr, Token: "bad"})
+	if err != nil {
+		t.Fatalf("NewNATSPublisher failed: %v", err)
+	}
+	defer p.Close()
+
+	err = p.Publish(context.Background(), "s", []Message{{Value: json.RawMessage(`{}`)}})
+	if err == nil || !strings.Contains(err.Error(), "Authorization Violation") {
+		t.Errorf("Expected authorization error, got %v", err)
+	}
+}
+
+func TestNewNATSPublisher_DefaultPort(t *testing.T) {
+	p, err := NewNATSPublisher(NATSConfig{URL: "nats://nats.local"})
+	if err != nil {
+		t.Fatalf("NewNATSPublisher failed: %v", err)
+	}
+	if p.target.Host != "nats.local:4222" {
+		t.Errorf("Expected default port, got %s", p.target.Host)
+	}
+	if _, err := NewNATSPublisher(NATSConfig{URL: "::bad"}); err == nil {
+		t.Error("Expected error for invalid URL")
+	}
+}
@@ -0,0 +1,270 @@
+// Package stream publishes platform events to a message broker, Kafka or NATS,
+// so data teams can consume execution results without polling the REST API.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// Supported brokers
+const (
+	DriverKafka = "kafka"
+	DriverNATS  = "nats"
+)
+
+// Defaults of the sink configuration
+const (
+	DefaultTopicPrefix   = "autostrike"
+	DefaultBatchSize     = 100
+	DefaultBufferSize    = 10000
+	DefaultFlushInterval = time.Second
+)
+
+// Message is a record published to a topic. Key keeps the records of an
+// execution in order on partitioned brokers.
+type Message struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// Publisher sends batches of messages to a broker topic (a Kafka topic or a
+// NATS subject)
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, topic string, messages []Message) error
+	Close() error
+}
+
+// Config configures the streaming sink
+type Config struct {
+	Driver        string             // kafka or nats
+	TopicPrefix   string             // Topics are <prefix>.results, <prefix>.executions and <prefix>.events
+	Events        []entity.EventType // Streamed event types, every event when empty
+	BatchSize     int                // Messages published per request
+	BufferSize    int                // Events waiting to be published; newer events are dropped when full
+	FlushInterval time.Duration      // Publication delay of incomplete batches
+	Kafka         KafkaConfig
+	NATS          NATSConfig
+}
+
+// NewPublisher creates the publisher of the configured driver
+func NewPublisher(config Config) (Publisher, error) {
+	switch config.Driver {
+	case DriverKafka:
+		if config.Kafka.RESTURL == "" {
+			return nil, fmt.Errorf("kafka REST proxy URL is required")
+		}
+		return NewKafkaPublisher(config.Kafka), nil
+	case DriverNATS:
+		if config.NATS.URL == "" {
+			return nil, fmt.Errorf("nats URL is required")
+		}
+		return NewNATSPublisher(config.NATS)
+	default:
+		return nil, fmt.Errorf("unsupported stream driver %q, use kafka or nats", config.Driver)
+	}
+}
+
+// Topic returns the topic an event is published to: results, execution status
+// changes, and every other event on their own topics
+func Topic(prefix string, eventType entity.EventType) string {
+	switch {
+	case eventType == entity.EventResultCompleted:
+		return prefix + ".results"
+	case strings.HasPrefix(string(eventType), "execution."):
+		return prefix + ".executions"
+	default:
+		return prefix + ".events"
+	}
+}
+
+// eventKey returns the partition key of an event
+func eventKey(event *entity.Event) string {
+	switch {
+	case event.Result != nil:
+		return event.Result.ExecutionID
+	case event.Execution != nil:
+		return event.Execution.ID
+	case event.Agent != nil:
+		return event.Agent.Paw
+	default:
+		return event.ID
+	}
+}
+
+type queuedMessage struct {
+	topic   string
+	message Message
+}
+
+// Sink streams bus events to a broker. Events are queued and published in
+// batches by a background worker, so a slow broker never blocks the bus.
+type Sink struct {
+	publisher Publisher
+	config    Config
+	types     map[entity.EventType]bool
+	logger    *zap.Logger
+	queue     chan queuedMessage
+
+	mu       sync.Mutex
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+}
+
+// NewSink creates a sink publishing with publisher; Start runs its worker
+func NewSink(publisher Publisher, config Config, logger *zap.Logger) *Sink {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if config.TopicPrefix == "" {
+		config.TopicPrefix = DefaultTopicPrefix
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultBufferSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	types := make(map[entity.EventType]bool, len(config.Events))
+	for _, t := range config.Events {
+		types[t] = true
+	}
+	return &Sink{
+		publisher: publisher,
+		config:    config,
+		types:     types,
+		logger:    logger,
+		queue:     make(chan queuedMessage, config.BufferSize),
+	}
+}
+
+// Name identifies the sink in logs
+func (s *Sink) Name() string {
+	return "stream-" + s.publisher.Name()
+}
+
+// Handle queues the event for publication, dropping it when the queue is full
+func (s *Sink) Handle(_ context.Context, event *entity.Event) error {
+	if len(s.types) > 0 && !s.types[event.Type] {
+		return nil
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	select {
+	case s.queue <- queuedMessage{topic: Topic(s.config.TopicPrefix, event.Type), message: Message{Key: eventKey(event), Value: value}}:
+	default:
+		s.logger.Warn("Stream queue full, dropping event",
+			zap.String("event_id", event.ID),
+			zap.String("event", string(event.Type)),
+		)
+	}
+	return nil
+}
+
+// Start runs the publishing worker
+func (s *Sink) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stopChan = make(chan struct{})
+	s.wg.Add(1)
+	go s.run()
+	s.logger.Info("Event streaming started",
+		zap.String("driver", s.publisher.Name()),
+		zap.String("topic_prefix", s.config.TopicPrefix),
+	)
+}
+
+// Stop publishes the queued events and closes the broker connection
+func (s *Sink) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stopChan)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	if err := s.publisher.Close(); err != nil {
+		s.logger.Warn("Failed to close stream publisher", zap.Error(err))
+	}
+}
+
+// run batches queued messages per topic, publishing a topic when its batch is
+// full and every topic at each flush interval
+func (s *Sink) run() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batches := make(map[string][]Message)
+	for {
+		select {
+		case queued := <-s.queue:
+			batches[queued.topic] = append(batches[queued.topic], queued.message)
+			if len(batches[queued.topic]) >= s.config.BatchSize {
+				s.publish(queued.topic, batches[queued.topic])
+				delete(batches, queued.topic)
+			}
+		case <-ticker.C:
+			s.flush(batches)
+		case <-s.stopChan:
+			for {
+				select {
+				case queued := <-s.queue:
+					batches[queued.topic] = append(batches[queued.topic], queued.message)
+				default:
+					s.flush(batches)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush publishes and clears every pending batch
+func (s *Sink) flush(batches map[string][]Message) {
+	for topic, messages := range batches {
+		s.publish(topic, messages)
+		delete(batches, topic)
+	}
+}
+
+// publish sends a batch, logging the failure; a failed batch is not retried
+func (s *Sink) publish(topic string, messages []Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	if err := s.publisher.Publish(ctx, topic, messages); err != nil {
+		s.logger.Error("Failed to stream events",
+			zap.String("driver", s.publisher.Name()),
+			zap.String("topic", topic),
+			zap.Int("count", len(messages)),
+			zap.Error(err),
+		)
+	}
+}
+
+// publishTimeout bounds the publication of a batch
+const publishTimeout = 10 * time.Second
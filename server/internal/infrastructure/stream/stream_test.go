@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"autostrike/internal/domain/entity"
+)
+
+// mockPublisher records the published batches
+type mockPublisher struct {
+	mu      sync.Mutex
+	batches map[string][][]Message
+	closed  bool
+}
+
+func newMockPublisher() *mockPublisher {
+	return &mockPublisher{batches: make(map[string][][]Message)}
+}
+
+func (p *mockPublisher) Name() string { return "mock" }
+
+func (p *mockPublisher) Publish(_ context.Context, topic string, messages []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches[topic] = append(p.batches[topic], append([]Message(nil), messages...))
+	return nil
+}
+
+func (p *mockPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *mockPublisher) count(topic string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, batch := range p.batches[topic] {
+		n += len(batch)
+	}
+	return n
+}
+
+func TestTopic(t *testing.T) {
+	tests := []struct {
+		eventType entity.EventType
+		want      string
+	}{
+		{entity.EventResultCompleted, "as.results"},
+		{entity.EventExecutionStarted, "as.executions"},
+		{entity.EventExecutionCompleted, "as.executions"},
+		{entity.EventAgentOnline, "as.events"},
+		{entity.EventSecurityAlert, "as.events"},
+	}
+	for _, tt := range tests {
+		if got := Topic("as", tt.eventType); got != tt.want {
+			t.Errorf("Topic(%s) = %s, want %s", tt.eventType, got, tt.want)
+		}
+	}
+}
+
+func TestNewPublisher(t *testing.T) {
+	if _, err := NewPublisher(Config{Driver: "rabbitmq"}); err == nil {
+		t.Error("Expected error for unsupported driver")
+	}
+	if _, err := NewPublisher(Config{Driver: DriverKafka}); err == nil {
+		t.Error("Expected error without REST proxy URL")
+	}
+	if _, err := NewPublisher(Config{Driver: DriverNATS, NATS: NATSConfig{URL: "http://nats:4222"}}); err == nil {
+		t.Error("Expected error for non-nats URL scheme")
+	}
+	p, err := NewPublisher(Config{Driver: DriverKafka, Kafka: KafkaConfig{RESTURL: "http://kafka-rest:8082"}})
+	if err != nil || p.Name() != DriverKafka {
+		t.Errorf("NewPublisher(kafka) = %v, %v", p, err)
+	}
+}
+
+func TestSink_BatchesPerTopic(t *testing.T) {
+	publisher := newMockPublisher()
+	sink := NewSink(publisher, Config{TopicPrefix: "as", BatchSize: 2, FlushInterval: time.Hour}, nil)
+	sink.Start()
+
+	ctx := context.Background()
+	result := &entity.ExecutionResult{ID: "r1", ExecutionID: "exec-1", TechniqueID: "T1082"}
+	_ = sink.Handle(ctx, &entity.Event{ID: "e1", Type: entity.EventResultCompleted, Result: result})
+	_ = sink.Handle(ctx, &entity.Event{ID: "e2", Type: entity.EventResultCompleted, Result: result})
+	_ = sink.Handle(ctx, &entity.Event{ID: "e3", Type: entity.EventExecutionCompleted, Execution: &entity.Execution{ID: "exec-1"}})
+
+	// A full batch is published without waiting for the flush interval
+	deadline := time.Now().Add(2 * time.Second)
+	for publisher.count("as.results") < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if publisher.count("as.results") != 2 {
+		t.Fatalf("Expected a batch of 2 results, got %d", publisher.count("as.results"))
+	}
+	if publisher.count("as.executions") != 0 {
+		t.Error("Expected the incomplete batch to wait for the flush")
+	}
+
+	// Stop flushes the pending batch and closes the publisher
+	sink.Stop()
+	if publisher.count("as.executions") != 1 || !publisher.closed {
+		t.Errorf("Expected pending events flushed and publisher closed, got %d", publisher.count("as.executions"))
+	}
+
+	message := publisher.batches["as.results"][0][0]
+	var event entity.Event
+	if err := json.Unmarshal(message.Value, &event); err != nil || event.ID != "e1" {
+		t.Errorf("Unexpected message value %s: %v", message.Value, err)
+	}
+	if message.Key != "exec-1" {
+		t.Errorf("Expected the execution ID as key, got %q", message.Key)
+	}
+}
+
+func TestSink_EventFilter(t *testing.T) {
+	publisher := newMockPublisher()
+	sink := NewSink(publisher, Config{Events: []entity.EventType{entity.EventResultCompleted}}, nil)
+	sink.Start()
+
+	ctx := context.Background()
+	_ = sink.Handle(ctx, &entity.Event{ID: "e1", Type: entity.EventAgentOnline, Agent: &entity.Agent{Paw: "paw1"}})
+	_ = sink.Handle(ctx, &entity.Event{ID: "e2", Type: entity.EventResultCompleted, Result: &entity.ExecutionResult{ExecutionID: "exec-1"}})
+	sink.Stop()
+
+	if publisher.count(DefaultTopicPrefix+".events") != 0 || publisher.count(DefaultTopicPrefix+".results") != 1 {
+		t.Errorf("Unexpected published events: %+v", publisher.batches)
+	}
+	if sink.Name() != "stream-mock" {
+		t.Errorf("Unexpected sink name %s", sink.Name())
+	}
+}
+
+func TestSink_DropsWhenQueueFull(t *testing.T) {
+	publisher := newMockPublisher()
+	sink := NewSink(publisher, Config{BufferSize: 1}, nil)
+
+	// Not started: the second event does not fit in the queue
+	ctx := context.Background()
+	for _, id := range []string{"e1", "e2"} {
+		if err := sink.Handle(ctx, &entity.Event{ID: id, Type: entity.EventAgentOnline}); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+	sink.Start()
+	sink.Stop()
+	if publisher.count(DefaultTopicPrefix+".events") != 1 {
+		t.Errorf("Expected one published event, got %d", publisher.count(DefaultTopicPrefix+".events"))
+	}
+}
@@ -0,0 +1,83 @@
+// Package telemetry configures OpenTelemetry tracing. Spans follow an execution
+// from the REST request through the ExecutionService and AttackOrchestrator to
+// the WebSocket task dispatch, and back from the agent's task result, whose
+// trace context is echoed by the agent.
+package telemetry
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.uber.org/zap"
+)
+
+// DefaultServiceName is the service name of the spans when OTEL_SERVICE_NAME is not set
+const DefaultServiceName = "autostrike-server"
+
+// Enabled reports whether an OTLP endpoint is configured
+func Enabled() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// InitTracing installs the global tracer provider and the W3C trace context
+// propagator. Spans are exported over OTLP/HTTP when an OTLP endpoint is set;
+// the exporter and sampler read the standard OTEL_* variables. Otherwise spans
+// are not recorded. The returned function flushes and stops the exporter.
+func InitTracing(ctx context.Context, logger *zap.Logger) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	logger.Info("OpenTelemetry tracing enabled", zap.String("service", serviceName))
+	return provider.Shutdown, nil
+}
+
+// Inject returns the trace context of ctx as a string map, to send to an agent.
+// The map is empty when ctx carries no sampled span.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// Extract returns ctx with the remote span of a trace context received from an agent
+func Extract(ctx context.Context, traceContext map[string]string) context.Context {
+	if len(traceContext) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(traceContext))
+}
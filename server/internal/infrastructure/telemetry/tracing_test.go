@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestInitTracing_Disabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	if Enabled() {
+		t.Fatal("expected tracing to be disabled without an OTLP endpoint")
+	}
+	shutdown, err := InitTracing(context.Background(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("InitTracing() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+	if carrier := Inject(context.Background()); len(carrier) != 0 {
+		t.Errorf("expected no trace context without a span, got %v", carrier)
+	}
+}
+
+func TestInitTracing_Enabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://127.0.0.1:4318")
+	t.Setenv("OTEL_SERVICE_NAME", "autostrike-test")
+
+	shutdown, err := InitTracing(context.Background(), zap.NewNop())
+	if err != nil {
+		t.Fatalf("InitTracing() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = shutdown(ctx)
+}
+
+func TestInjectExtract_RoundTrip(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	if _, err := InitTracing(context.Background(), zap.NewNop()); err != nil {
+		t.Fatalf("InitTracing() error = %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "dispatch")
+	defer span.End()
+
+	carrier := Inject(ctx)
+	if carrier["traceparent"] == "" {
+		t.Fatalf("expected a traceparent, got %v", carrier)
+	}
+
+	remote := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	if !remote.IsRemote() {
+		t.Error("expected a remote span context")
+	}
+	if remote.TraceID() != span.SpanContext().TraceID() || remote.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("extracted %s/%s, want %s/%s", remote.TraceID(), remote.SpanID(), span.SpanContext().TraceID(), span.SpanContext().SpanID())
+	}
+}
+
+func TestExtract_Empty(t *testing.T) {
+	ctx := context.Background()
+	if got := Extract(ctx, nil); got != ctx {
+		t.Error("expected the context to be returned unchanged")
+	}
+}
@@ -0,0 +1,186 @@
+package ticketing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+// jiraDoneCategory is the status category of done, resolved and closed issues
+const jiraDoneCategory = "done"
+
+// JiraConfig configures the Jira connector
+type JiraConfig struct {
+	URL               string // Jira base URL, e.g. https://example.atlassian.net
+	Email             string // Account email for Jira Cloud API tokens, empty for a Data Center personal access token
+	APIToken          string
+	Project           string // Project key issues are opened in
+	IssueType         string // Defaults to "Bug"
+	ResolveTransition string // Transition used to resolve issues, defaults to the first one leading to a done status
+}
+
+// JiraConnector opens issues with the Jira REST API v2
+type JiraConnector struct {
+	config JiraConfig
+	client *http.Client
+}
+
+// NewJiraConnector creates a new Jira connector
+func NewJiraConnector(config JiraConfig) *JiraConnector {
+	config.URL = strings.TrimRight(config.URL, "/")
+	if config.IssueType == "" {
+		config.IssueType = "Bug"
+	}
+	return &JiraConnector{config: config, client: integration.NewHTTPClient()}
+}
+
+// Name returns the connector name
+func (c *JiraConnector) Name() string {
+	return "jira"
+}
+
+// CreateTicket opens an issue in the configured project
+func (c *JiraConnector) CreateTicket(ctx context.Context, ticket application.TicketRequest) (*application.TicketRef, error) {
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": c.config.Project},
+		"issuetype":   map[string]string{"name": c.config.IssueType},
+		"summary":     ticket.Summary,
+		"description": ticket.Description,
+	}
+	if len(ticket.Labels) > 0 {
+		fields["labels"] = ticket.Labels
+	}
+	req, err := c.newRequest(http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("jira issue creation failed: %w", err)
+	}
+	return &application.TicketRef{Key: resp.Key, URL: c.config.URL + "/browse/" + resp.Key}, nil
+}
+
+// GetTicket returns the status of an issue
+func (c *JiraConnector) GetTicket(ctx context.Context, key string) (*application.TicketState, error) {
+	req, err := c.newRequest(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Fields struct {
+			Status jiraStatus `json:"status"`
+		} `json:"fields"`
+	}
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("jira issue query failed: %w", err)
+	}
+	status := resp.Fields.Status
+	return &application.TicketState{Status: status.Name, Closed: status.StatusCategory.Key == jiraDoneCategory}, nil
+}
+
+// CommentTicket adds a comment to an issue
+func (c *JiraConnector) CommentTicket(ctx context.Context, key, comment string) error {
+	req, err := c.newRequest(http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment",
+		map[string]string{"body": comment})
+	if err != nil {
+		return err
+	}
+	if err := integration.DoJSON(ctx, c.client, req, nil); err != nil {
+		return fmt.Errorf("jira comment failed: %w", err)
+	}
+	return nil
+}
+
+// ResolveTicket comments an issue and moves it to a done status
+func (c *JiraConnector) ResolveTicket(ctx context.Context, key, comment string) error {
+	if err := c.CommentTicket(ctx, key, comment); err != nil {
+		return err
+	}
+
+	transitionID, err := c.resolveTransition(ctx, key)
+	if err != nil {
+		return err
+	}
+	if transitionID == "" {
+		return nil // Already done, or the workflow offers no way to resolve it
+	}
+	req, err := c.newRequest(http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions",
+		map[string]interface{}{"transition": map[string]string{"id": transitionID}})
+	if err != nil {
+		return err
+	}
+	if err := integration.DoJSON(ctx, c.client, req, nil); err != nil {
+		return fmt.Errorf("jira transition failed: %w", err)
+	}
+	return nil
+}
+
+type jiraStatus struct {
+	Name           string `json:"name"`
+	StatusCategory struct {
+		Key string `json:"key"`
+	} `json:"statusCategory"`
+}
+
+// resolveTransition returns the configured transition, else the first one to a done status
+func (c *JiraConnector) resolveTransition(ctx context.Context, key string) (string, error) {
+	req, err := c.newRequest(http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"/transitions", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Transitions []struct {
+			ID   string     `json:"id"`
+			Name string     `json:"name"`
+			To   jiraStatus `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return "", fmt.Errorf("jira transitions query failed: %w", err)
+	}
+	for _, transition := range resp.Transitions {
+		if c.config.ResolveTransition != "" {
+			if strings.EqualFold(transition.Name, c.config.ResolveTransition) {
+				return transition.ID, nil
+			}
+		} else if transition.To.StatusCategory.Key == jiraDoneCategory {
+			return transition.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// newRequest builds an authenticated Jira API request
+func (c *JiraConnector) newRequest(method, path string, body interface{}) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = newJSONRequest(method, c.config.URL+path, body)
+	} else {
+		req, err = http.NewRequest(method, c.config.URL+path, nil)
+		if err == nil {
+			req.Header.Set("Accept", "application/json")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.config.Email != "" {
+		req.SetBasicAuth(c.config.Email, c.config.APIToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIToken)
+	}
+	return req, nil
+}
@@ -0,0 +1,153 @@
+package ticketing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"autostrike/internal/application"
+	"autostrike/internal/infrastructure/integration"
+)
+
+// ServiceNow incident states
+const (
+	serviceNowResolved = "6"
+	serviceNowClosed   = "7"
+	serviceNowCanceled = "8"
+)
+
+// serviceNowStates names the incident states of the default workflow
+var serviceNowStates = map[string]string{
+	"1": "New", "2": "In Progress", "3": "On Hold",
+	serviceNowResolved: "Resolved", serviceNowClosed: "Closed", serviceNowCanceled: "Canceled",
+}
+
+// ServiceNowConfig configures the ServiceNow connector
+type ServiceNowConfig struct {
+	URL             string // Instance URL, e.g. https://example.service-now.com
+	Username        string
+	Password        string
+	AssignmentGroup string // Group incidents are assigned to, optional
+	CloseCode       string // Resolution code of resolved incidents, defaults to "Solved (Permanently)"
+}
+
+// ServiceNowConnector opens incidents with the ServiceNow Table API
+type ServiceNowConnector struct {
+	config ServiceNowConfig
+	client *http.Client
+}
+
+// NewServiceNowConnector creates a new ServiceNow connector
+func NewServiceNowConnector(config ServiceNowConfig) *ServiceNowConnector {
+	config.URL = strings.TrimRight(config.URL, "/")
+	if config.CloseCode == "" {
+		config.CloseCode = "Solved (Permanently)"
+	}
+	return &ServiceNowConnector{config: config, client: integration.NewHTTPClient()}
+}
+
+// Name returns the connector name
+func (c *ServiceNowConnector) Name() string {
+	return "servicenow"
+}
+
+type serviceNowIncident struct {
+	SysID  string `json:"sys_id"`
+	Number string `json:"number"`
+	State  string `json:"state"`
+}
+
+// CreateTicket opens an incident
+func (c *ServiceNowConnector) CreateTicket(ctx context.Context, ticket application.TicketRequest) (*application.TicketRef, error) {
+	record := map[string]string{
+		"short_description": ticket.Summary,
+		"description":       ticket.Description,
+		"category":          "security",
+	}
+	if c.config.AssignmentGroup != "" {
+		record["assignment_group"] = c.config.AssignmentGroup
+	}
+	req, err := c.newRequest(http.MethodPost, "/api/now/table/incident", record)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result serviceNowIncident `json:"result"`
+	}
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("servicenow incident creation failed: %w", err)
+	}
+	return &application.TicketRef{
+		Key: resp.Result.SysID,
+		URL: c.config.URL + "/nav_to.do?uri=" + url.QueryEscape("incident.do?sys_id="+resp.Result.SysID),
+	}, nil
+}
+
+// GetTicket returns the state of an incident
+func (c *ServiceNowConnector) GetTicket(ctx context.Context, key string) (*application.TicketState, error) {
+	req, err := c.newRequest(http.MethodGet, "/api/now/table/incident/"+url.PathEscape(key)+"?sysparm_fields=sys_id,number,state", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result serviceNowIncident `json:"result"`
+	}
+	if err := integration.DoJSON(ctx, c.client, req, &resp); err != nil {
+		return nil, fmt.Errorf("servicenow incident query failed: %w", err)
+	}
+	state := resp.Result.State
+	name, ok := serviceNowStates[state]
+	if !ok {
+		name = state
+	}
+	closed := state == serviceNowResolved || state == serviceNowClosed || state == serviceNowCanceled
+	return &application.TicketState{Status: name, Closed: closed}, nil
+}
+
+// CommentTicket adds a work note to an incident
+func (c *ServiceNowConnector) CommentTicket(ctx context.Context, key, comment string) error {
+	return c.patch(ctx, key, map[string]string{"work_notes": comment})
+}
+
+// ResolveTicket resolves an incident with the comment as close notes
+func (c *ServiceNowConnector) ResolveTicket(ctx context.Context, key, comment string) error {
+	return c.patch(ctx, key, map[string]string{
+		"state":       serviceNowResolved,
+		"close_code":  c.config.CloseCode,
+		"close_notes": comment,
+	})
+}
+
+func (c *ServiceNowConnector) patch(ctx context.Context, key string, fields map[string]string) error {
+	req, err := c.newRequest(http.MethodPatch, "/api/now/table/incident/"+url.PathEscape(key), fields)
+	if err != nil {
+		return err
+	}
+	if err := integration.DoJSON(ctx, c.client, req, nil); err != nil {
+		return fmt.Errorf("servicenow incident update failed: %w", err)
+	}
+	return nil
+}
+
+// newRequest builds an authenticated Table API request
+func (c *ServiceNowConnector) newRequest(method, path string, body interface{}) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = newJSONRequest(method, c.config.URL+path, body)
+	} else {
+		req, err = http.NewRequest(method, c.config.URL+path, nil)
+		if err == nil {
+			req.Header.Set("Accept", "application/json")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.config.Username, c.config.Password)
+	return req, nil
+}
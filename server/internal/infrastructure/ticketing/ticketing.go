@@ -0,0 +1,32 @@
+// Package ticketing provides issue tracker connectors used to open tickets for
+// techniques that ran undetected and to follow their status.
+package ticketing
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"autostrike/internal/application"
+)
+
+// Ensure connectors satisfy the application port
+var (
+	_ application.TicketConnector = (*JiraConnector)(nil)
+	_ application.TicketConnector = (*ServiceNowConnector)(nil)
+)
+
+// newJSONRequest builds a request with a JSON body
+func newJSONRequest(method, url string, body interface{}) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
@@ -0,0 +1,158 @@
+package ticketing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"autostrike/internal/application"
+)
+
+func TestJiraConnector_CreateAndGetTicket(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		if !ok || user != "bot@example.com" || token != "tok" {
+			t.Errorf("Expected basic auth, got %q %q", user, token)
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+			var body struct {
+				Fields map[string]interface{} `json:"fields"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body.Fields["summary"] != "T1059 not detected" || body.Fields["project"].(map[string]interface{})["key"] != "SEC" {
+				t.Errorf("Unexpected fields: %v", body.Fields)
+			}
+			if body.Fields["issuetype"].(map[string]interface{})["name"] != "Bug" {
+				t.Errorf("Expected the default issue type, got %v", body.Fields["issuetype"])
+			}
+			_, _ = w.Write([]byte(`{"id":"10001","key":"SEC-12"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/SEC-12":
+			_, _ = w.Write([]byte(`{"fields":{"status":{"name":"Done","statusCategory":{"key":"done"}}}}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewJiraConnector(JiraConfig{URL: server.URL + "/", Email: "bot@example.com", APIToken: "tok", Project: "SEC"})
+	ref, err := c.CreateTicket(context.Background(), application.TicketRequest{Summary: "T1059 not detected", Labels: []string{"autostrike"}})
+	if err != nil {
+		t.Fatalf("CreateTicket failed: %v", err)
+	}
+	if ref.Key != "SEC-12" || ref.URL != server.URL+"/browse/SEC-12" {
+		t.Errorf("Unexpected ref: %+v", ref)
+	}
+
+	state, err := c.GetTicket(context.Background(), "SEC-12")
+	if err != nil {
+		t.Fatalf("GetTicket failed: %v", err)
+	}
+	if state.Status != "Done" || !state.Closed {
+		t.Errorf("Expected a closed issue, got %+v", state)
+	}
+}
+
+func TestJiraConnector_ResolveTicket(t *testing.T) {
+	var transitioned, commented string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			t.Errorf("Expected a bearer token without email, got %q", r.Header.Get("Authorization"))
+		}
+		switch {
+		case r.URL.Path == "/rest/api/2/issue/SEC-12/comment":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			commented = body["body"]
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/SEC-12/transitions":
+			_, _ = w.Write([]byte(`{"transitions":[
+				{"id":"11","name":"Start","to":{"statusCategory":{"key":"indeterminate"}}},
+				{"id":"31","name":"Close","to":{"statusCategory":{"key":"done"}}}
+			]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/SEC-12/transitions":
+			var body struct {
+				Transition map[string]string `json:"transition"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			transitioned = body.Transition["id"]
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewJiraConnector(JiraConfig{URL: server.URL, APIToken: "pat", Project: "SEC"})
+	if err := c.ResolveTicket(context.Background(), "SEC-12", "T1059 was blocked"); err != nil {
+		t.Fatalf("ResolveTicket failed: %v", err)
+	}
+	if commented != "T1059 was blocked" || transitioned != "31" {
+		t.Errorf("Expected a comment and the done transition, got %q, %q", commented, transitioned)
+	}
+}
+
+func TestServiceNowConnector_Incidents(t *testing.T) {
+	var patched map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "secret" {
+			t.Errorf("Expected basic auth, got %q %q", user, pass)
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/now/table/incident":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["short_description"] != "T1059 not detected" || body["assignment_group"] != "SOC" {
+				t.Errorf("Unexpected incident: %v", body)
+			}
+			_, _ = w.Write([]byte(`{"result":{"sys_id":"abc123","number":"INC0010001","state":"1"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/now/table/incident/abc123":
+			_, _ = w.Write([]byte(`{"result":{"sys_id":"abc123","state":"7"}}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/api/now/table/incident/abc123":
+			_ = json.NewDecoder(r.Body).Decode(&patched)
+			_, _ = w.Write([]byte(`{"result":{}}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	c := NewServiceNowConnector(ServiceNowConfig{URL: server.URL, Username: "admin", Password: "secret", AssignmentGroup: "SOC"})
+	ctx := context.Background()
+	ref, err := c.CreateTicket(ctx, application.TicketRequest{Summary: "T1059 not detected"})
+	if err != nil {
+		t.Fatalf("CreateTicket failed: %v", err)
+	}
+	if ref.Key != "abc123" || !strings.Contains(ref.URL, "abc123") {
+		t.Errorf("Unexpected ref: %+v", ref)
+	}
+
+	state, err := c.GetTicket(ctx, "abc123")
+	if err != nil || state.Status != "Closed" || !state.Closed {
+		t.Errorf("Expected a closed incident, got %+v (%v)", state, err)
+	}
+
+	if err := c.ResolveTicket(ctx, "abc123", "blocked"); err != nil {
+		t.Fatalf("ResolveTicket failed: %v", err)
+	}
+	if patched["state"] != "6" || patched["close_notes"] != "blocked" || patched["close_code"] == "" {
+		t.Errorf("Unexpected resolution: %v", patched)
+	}
+}
+
+func TestServiceNowConnector_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"User Not Authenticated"}}`))
+	}))
+	defer server.Close()
+
+	c := NewServiceNowConnector(ServiceNowConfig{URL: server.URL})
+	_, err := c.CreateTicket(context.Background(), application.TicketRequest{Summary: "s"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected the status in the error, got %v", err)
+	}
+}
@@ -23,12 +23,19 @@ type Client struct {
 	conn     *websocket.Conn
 	send     chan []byte
 	agentPaw string
+	remoteIP string // Source address of the agent, behind trusted proxies
 	pawMu    sync.RWMutex
 	logger   *zap.Logger
+
+	// Dashboard connections carry the user they were authenticated as
+	dashboard bool
+	userID    string
+	role      string
 }
 
 // Message represents a WebSocket message
 type Message struct {
+	ID      uint64          `json:"id,omitempty"` // Sequence number of dashboard events, for resuming streams
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload"`
 }
@@ -47,6 +54,28 @@ func NewClient(hub *Hub, conn *websocket.Conn, agentPaw string, logger *zap.Logg
 	}
 }
 
+// NewDashboardClient creates a client for a dashboard connection of an
+// authenticated user, which receives the dashboard events their role may see
+func NewDashboardClient(hub *Hub, conn *websocket.Conn, userID, role string, logger *zap.Logger) *Client {
+	client := NewClient(hub, conn, "", logger)
+	client.dashboard = true
+	client.userID = userID
+	client.role = role
+	return client
+}
+
+// NewStreamClient creates a dashboard client without a WebSocket connection,
+// for transports reading its messages from Messages, such as Server-Sent Events
+func NewStreamClient(hub *Hub, userID, role string, logger *zap.Logger) *Client {
+	return NewDashboardClient(hub, nil, userID, role, logger)
+}
+
+// Messages returns the channel of the messages queued for the client, closed
+// when the hub drops it
+func (c *Client) Messages() <-chan []byte {
+	return c.send
+}
+
 // ReadPump reads messages from the WebSocket connection
 func (c *Client) ReadPump(handler func(*Client, *Message)) {
 	defer func() {
@@ -184,6 +213,36 @@ func (c *Client) SetAgentPaw(paw string) {
 	}
 }
 
+// RemoteIP returns the address the agent connects from, empty when unknown
+func (c *Client) RemoteIP() string {
+	c.pawMu.RLock()
+	defer c.pawMu.RUnlock()
+	return c.remoteIP
+}
+
+// SetRemoteIP sets the address the agent connects from; long-poll clients
+// set it on every request, as the address may change between them
+func (c *Client) SetRemoteIP(ip string) {
+	c.pawMu.Lock()
+	defer c.pawMu.Unlock()
+	c.remoteIP = ip
+}
+
+// IsDashboard reports whether this is a dashboard connection
+func (c *Client) IsDashboard() bool {
+	return c.dashboard
+}
+
+// UserID returns the user of a dashboard connection
+func (c *Client) UserID() string {
+	return c.userID
+}
+
+// Role returns the role of the user of a dashboard connection
+func (c *Client) Role() string {
+	return c.role
+}
+
 // Context returns a background context for database operations
 func (c *Client) Context() context.Context {
 	return context.Background()
@@ -0,0 +1,159 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// Types of the events pushed to dashboards
+const (
+	EventExecutionStatus   = "execution_status"
+	EventResultCompleted   = "result_completed"
+	EventExecutionProgress = "execution_progress"
+	EventAgentStatus       = "agent_status"
+	EventNotificationCount = "notification_count"
+)
+
+// ExecutionStatusEvent is pushed when an execution starts, completes, fails,
+// is cancelled or is interrupted by a shutdown
+type ExecutionStatusEvent struct {
+	ExecutionID  string                   `json:"execution_id"`
+	ScenarioID   string                   `json:"scenario_id"`
+	ScenarioName string                   `json:"scenario_name,omitempty"`
+	Status       entity.ExecutionStatus   `json:"status"`
+	Progress     entity.ExecutionProgress `json:"progress"`
+	Score        *entity.SecurityScore    `json:"score,omitempty"`
+	Error        string                   `json:"error,omitempty"`
+	OccurredAt   time.Time                `json:"occurred_at"`
+}
+
+// ResultCompletedEvent is pushed when a result of an execution reaches a final status
+type ResultCompletedEvent struct {
+	ExecutionID string                  `json:"execution_id"`
+	Result      *entity.ExecutionResult `json:"result"`
+	OccurredAt  time.Time               `json:"occurred_at"`
+}
+
+// ExecutionProgressEvent is pushed after each result of a running execution
+// reaching a final status, with the steps done, current phase and ETA
+type ExecutionProgressEvent struct {
+	ExecutionID string                   `json:"execution_id"`
+	Status      entity.ExecutionStatus   `json:"status"`
+	Progress    entity.ExecutionProgress `json:"progress"`
+	OccurredAt  time.Time                `json:"occurred_at"`
+}
+
+// AgentStatusEvent is pushed when an agent comes online or goes offline
+type AgentStatusEvent struct {
+	Paw        string             `json:"paw"`
+	Hostname   string             `json:"hostname"`
+	Platform   string             `json:"platform"`
+	Status     entity.AgentStatus `json:"status"`
+	OccurredAt time.Time          `json:"occurred_at"`
+}
+
+// NotificationCountEvent is pushed to the dashboards of a user when their
+// unread notification count changes
+type NotificationCountEvent struct {
+	Unread int `json:"unread"`
+}
+
+// DashboardEvents pushes typed platform events to the dashboard connections,
+// so the dashboard does not have to poll. Each event only reaches the users
+// whose role may view it.
+type DashboardEvents struct {
+	hub    *Hub
+	logger *zap.Logger
+}
+
+// NewDashboardEvents creates the event bus sink pushing events to the dashboards of hub
+func NewDashboardEvents(hub *Hub, logger *zap.Logger) *DashboardEvents {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &DashboardEvents{hub: hub, logger: logger}
+}
+
+// Name identifies the dashboard events as an event sink
+func (d *DashboardEvents) Name() string {
+	return "dashboard"
+}
+
+// Handle pushes execution, result and agent events to the dashboards, and the
+// progress of the execution of a result when the event carries it. Other
+// events are ignored.
+func (d *DashboardEvents) Handle(_ context.Context, event *entity.Event) error {
+	switch event.Type {
+	case entity.EventExecutionStarted, entity.EventExecutionCompleted, entity.EventExecutionFailed,
+		entity.EventExecutionCancelled, entity.EventExecutionInterrupted:
+		if event.Execution == nil {
+			return nil
+		}
+		status := ExecutionStatusEvent{
+			ExecutionID:  event.Execution.ID,
+			ScenarioID:   event.Execution.ScenarioID,
+			ScenarioName: event.ScenarioName,
+			Status:       event.Execution.Status,
+			Progress:     event.Execution.Progress,
+			Score:        event.Execution.Score,
+			Error:        event.Error,
+			OccurredAt:   event.OccurredAt,
+		}
+		return d.publish(EventExecutionStatus, status, allowPermission(entity.PermissionExecutionsView))
+	case entity.EventResultCompleted:
+		if event.Result == nil {
+			return nil
+		}
+		result := ResultCompletedEvent{ExecutionID: event.Result.ExecutionID, Result: event.Result, OccurredAt: event.OccurredAt}
+		if err := d.publish(EventResultCompleted, result, allowPermission(entity.PermissionExecutionsView)); err != nil || event.Execution == nil {
+			return err
+		}
+		progress := ExecutionProgressEvent{
+			ExecutionID: event.Execution.ID,
+			Status:      event.Execution.Status,
+			Progress:    event.Execution.Progress,
+			OccurredAt:  event.OccurredAt,
+		}
+		return d.publish(EventExecutionProgress, progress, allowPermission(entity.PermissionExecutionsView))
+	case entity.EventAgentOnline, entity.EventAgentOffline:
+		if event.Agent == nil {
+			return nil
+		}
+		agent := AgentStatusEvent{
+			Paw:        event.Agent.Paw,
+			Hostname:   event.Agent.Hostname,
+			Platform:   event.Agent.Platform,
+			Status:     event.Agent.Status,
+			OccurredAt: event.OccurredAt,
+		}
+		return d.publish(EventAgentStatus, agent, allowPermission(entity.PermissionAgentsView))
+	}
+	return nil
+}
+
+// UnreadCountChanged pushes the unread notification count of a user to their dashboards
+func (d *DashboardEvents) UnreadCountChanged(userID string, unread int) {
+	err := d.publish(EventNotificationCount, NotificationCountEvent{Unread: unread}, func(c *Client) bool {
+		return c.UserID() == userID
+	})
+	if err != nil {
+		d.logger.Warn("Failed to push notification count", zap.String("user_id", userID), zap.Error(err))
+	}
+}
+
+// publish queues an event for the dashboards accepted by allow
+func (d *DashboardEvents) publish(eventType string, payload any, allow func(*Client) bool) error {
+	_, err := d.hub.PublishDashboardEvent(eventType, payload, allow)
+	return err
+}
+
+// allowPermission accepts the dashboards of users whose role has permission
+func allowPermission(permission entity.Permission) func(*Client) bool {
+	return func(c *Client) bool {
+		return entity.HasPermission(entity.UserRole(c.Role()), permission)
+	}
+}
@@ -0,0 +1,138 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"autostrike/internal/domain/entity"
+
+	"go.uber.org/zap"
+)
+
+// registerDashboard registers a dashboard connection of a user
+func registerDashboard(hub *Hub, userID string, role entity.UserRole) *Client {
+	client := &Client{hub: hub, send: make(chan []byte, 8), dashboard: true, userID: userID, role: string(role)}
+	hub.handleRegister(client)
+	return client
+}
+
+// received decodes the messages queued for a client
+func received(t *testing.T, client *Client) []Message {
+	t.Helper()
+	var messages []Message
+	for len(client.send) > 0 {
+		var msg Message
+		if err := json.Unmarshal(<-client.send, &msg); err != nil {
+			t.Fatalf("invalid message: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestDashboardEvents_ExecutionStatus(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	viewer := registerDashboard(hub, "u1", entity.RoleViewer)
+	events := NewDashboardEvents(hub, nil)
+
+	err := events.Handle(context.Background(), &entity.Event{
+		Type:         entity.EventExecutionCompleted,
+		Execution:    &entity.Execution{ID: "e1", ScenarioID: "s1", Status: entity.ExecutionCompleted, Score: &entity.SecurityScore{Overall: 80}},
+		ScenarioName: "Discovery",
+	})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	messages := received(t, viewer)
+	if len(messages) != 1 || messages[0].Type != EventExecutionStatus {
+		t.Fatalf("Expected an execution status event, got %+v", messages)
+	}
+	var status ExecutionStatusEvent
+	if err := json.Unmarshal(messages[0].Payload, &status); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if status.ExecutionID != "e1" || status.Status != entity.ExecutionCompleted || status.ScenarioName != "Discovery" || status.Score.Overall != 80 {
+		t.Errorf("Unexpected event %+v", status)
+	}
+}
+
+func TestDashboardEvents_ResultsAndAgents(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	admin := registerDashboard(hub, "u1", entity.RoleAdmin)
+	events := NewDashboardEvents(hub, nil)
+	ctx := context.Background()
+
+	_ = events.Handle(ctx, &entity.Event{Type: entity.EventResultCompleted, Result: &entity.ExecutionResult{ID: "r1", ExecutionID: "e1", Status: entity.StatusBlocked}})
+	_ = events.Handle(ctx, &entity.Event{Type: entity.EventAgentOnline, Agent: &entity.Agent{Paw: "a1", Status: entity.AgentOnline}})
+	_ = events.Handle(ctx, &entity.Event{Type: entity.EventScheduleFailed, Schedule: &entity.Schedule{ID: "sched-1"}})
+
+	messages := received(t, admin)
+	if len(messages) != 2 || messages[0].Type != EventResultCompleted || messages[1].Type != EventAgentStatus {
+		t.Fatalf("Expected a result and an agent event, got %+v", messages)
+	}
+	var agent AgentStatusEvent
+	if err := json.Unmarshal(messages[1].Payload, &agent); err != nil || agent.Paw != "a1" || agent.Status != entity.AgentOnline {
+		t.Errorf("Unexpected agent event %+v (%v)", agent, err)
+	}
+}
+
+func TestDashboardEvents_FiltersByPermission(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	anonymous := registerDashboard(hub, "", "")
+	events := NewDashboardEvents(hub, nil)
+
+	_ = events.Handle(context.Background(), &entity.Event{Type: entity.EventAgentOffline, Agent: &entity.Agent{Paw: "a1"}})
+
+	if messages := received(t, anonymous); len(messages) != 0 {
+		t.Errorf("Expected no events without a role, got %+v", messages)
+	}
+}
+
+func TestDashboardEvents_UnreadCountChanged(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	mine := registerDashboard(hub, "u1", entity.RoleViewer)
+	other := registerDashboard(hub, "u2", entity.RoleAdmin)
+	events := NewDashboardEvents(hub, nil)
+
+	events.UnreadCountChanged("u1", 3)
+
+	messages := received(t, mine)
+	if len(messages) != 1 || messages[0].Type != EventNotificationCount || string(messages[0].Payload) != `{"unread":3}` {
+		t.Errorf("Unexpected messages %+v", messages)
+	}
+	if len(received(t, other)) != 0 {
+		t.Error("Other users should not receive the count")
+	}
+}
+
+func TestDashboardEvents_ExecutionProgress(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	viewer := registerDashboard(hub, "u1", entity.RoleViewer)
+	events := NewDashboardEvents(hub, nil)
+
+	remaining := 30.0
+	err := events.Handle(context.Background(), &entity.Event{
+		Type:   entity.EventResultCompleted,
+		Result: &entity.ExecutionResult{ID: "r1", ExecutionID: "e1", Status: entity.StatusSuccess},
+		Execution: &entity.Execution{ID: "e1", Status: entity.ExecutionRunning, Progress: entity.ExecutionProgress{
+			Total: 4, Completed: 1, Percent: 25, CurrentPhase: "Discovery", RemainingSeconds: &remaining,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	messages := received(t, viewer)
+	if len(messages) != 2 || messages[0].Type != EventResultCompleted || messages[1].Type != EventExecutionProgress {
+		t.Fatalf("Expected a result and a progress event, got %+v", messages)
+	}
+	var progress ExecutionProgressEvent
+	if err := json.Unmarshal(messages[1].Payload, &progress); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if progress.ExecutionID != "e1" || progress.Progress.Percent != 25 || progress.Progress.CurrentPhase != "Discovery" || *progress.Progress.RemainingSeconds != 30 {
+		t.Errorf("Unexpected event %+v", progress)
+	}
+}
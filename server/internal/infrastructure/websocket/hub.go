@@ -1,11 +1,24 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
 	"sync"
 
 	"go.uber.org/zap"
 )
 
+// dashboardHistory is the number of dashboard events kept for the streams
+// resuming after a disconnection
+const dashboardHistory = 1000
+
+// dashboardEvent is a published dashboard event, with its audience
+type dashboardEvent struct {
+	id      uint64
+	message []byte
+	allow   func(*Client) bool
+}
+
 // AgentDisconnectCallback is called when an agent disconnects
 type AgentDisconnectCallback func(paw string)
 
@@ -16,9 +29,13 @@ type Hub struct {
 	broadcast         chan []byte
 	register          chan *Client
 	unregister        chan *Client
+	ping              chan chan struct{}
 	mu                sync.RWMutex
 	logger            *zap.Logger
 	onAgentDisconnect AgentDisconnectCallback
+
+	history     []dashboardEvent // Latest dashboard events, oldest first
+	lastEventID uint64
 }
 
 // NewHub creates a new WebSocket hub
@@ -29,6 +46,7 @@ func NewHub(logger *zap.Logger) *Hub {
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		ping:       make(chan chan struct{}),
 		logger:     logger,
 	}
 }
@@ -45,6 +63,9 @@ func (h *Hub) Run() {
 
 		case message := <-h.broadcast:
 			h.handleBroadcast(message)
+
+		case reply := <-h.ping:
+			close(reply)
 		}
 	}
 }
@@ -69,6 +90,10 @@ func (h *Hub) handleUnregister(client *Client) {
 	if _, ok := h.clients[client]; ok {
 		delete(h.clients, client)
 		paw = client.GetAgentPaw()
+		// An agent that reconnected before its old connection went away keeps its new one
+		if paw != "" && h.agents[paw] != client {
+			paw = ""
+		}
 		if paw != "" {
 			delete(h.agents, paw)
 			h.logger.Info("Agent disconnected", zap.String("paw", paw))
@@ -132,6 +157,88 @@ func (h *Hub) Broadcast(message []byte) {
 	h.broadcast <- message
 }
 
+// NotifyAgents queues a message for every registered agent, without waiting,
+// and returns the number of agents notified
+func (h *Hub) NotifyAgents(message []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	notified := 0
+	for _, client := range h.agents {
+		select {
+		case client.send <- message:
+			notified++
+		default:
+		}
+	}
+	return notified
+}
+
+// PublishDashboardEvent queues an event for the dashboard connections
+// accepted by allow, without waiting, and returns the number of dashboards
+// reached. A nil allow accepts every dashboard. The event is numbered and
+// kept, so streams resuming after a disconnection can replay it.
+func (h *Hub) PublishDashboardEvent(eventType string, payload any, allow func(*Client) bool) (int, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	message, err := json.Marshal(Message{ID: h.lastEventID + 1, Type: eventType, Payload: data})
+	if err != nil {
+		return 0, err
+	}
+	h.lastEventID++
+	h.history = append(h.history, dashboardEvent{id: h.lastEventID, message: message, allow: allow})
+	if len(h.history) > dashboardHistory {
+		h.history = h.history[len(h.history)-dashboardHistory:]
+	}
+
+	published := 0
+	for client := range h.clients {
+		if !client.dashboard || (allow != nil && !allow(client)) {
+			continue
+		}
+		select {
+		case client.send <- message:
+			published++
+		default:
+		}
+	}
+	return published, nil
+}
+
+// SubscribeDashboard registers a dashboard client without a WebSocket
+// connection, such as an event stream, and returns the events it may see
+// that were published after lastEventID. The returned flag is false when
+// some of them are no longer kept, or were numbered before a restart, in
+// which case the client should reload its state. A zero lastEventID replays
+// nothing.
+func (h *Hub) SubscribeDashboard(client *Client, lastEventID uint64) ([][]byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.clients[client] = true
+	if lastEventID == 0 {
+		return nil, true
+	}
+
+	complete := lastEventID <= h.lastEventID
+	if len(h.history) > 0 && h.history[0].id > lastEventID+1 {
+		complete = false
+	}
+	var replay [][]byte
+	for _, event := range h.history {
+		if event.id > lastEventID && (event.allow == nil || event.allow(client)) {
+			replay = append(replay, event.message)
+		}
+	}
+	return replay, complete
+}
+
 // GetConnectedAgents returns list of connected agent paws
 func (h *Hub) GetConnectedAgents() []string {
 	h.mu.RLock()
@@ -167,6 +274,23 @@ func (h *Hub) Register(client *Client) {
 	h.register <- client
 }
 
+// Ping verifies the hub loop is running and responsive: it fails when Run
+// has not been started or is blocked until ctx is done
+func (h *Hub) Ping(ctx context.Context) error {
+	reply := make(chan struct{})
+	select {
+	case h.ping <- reply:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-reply:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Unregister removes a client from the hub
 func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -666,3 +667,104 @@ func TestHub_handleBroadcast_DisconnectCallback(t *testing.T) {
 		t.Error("Agent should have been removed")
 	}
 }
+
+func TestHub_Ping(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := hub.Ping(ctx); err == nil {
+		t.Error("Ping should fail when the hub is not running")
+	}
+
+	go hub.Run()
+	if err := hub.Ping(context.Background()); err != nil {
+		t.Errorf("Ping failed on a running hub: %v", err)
+	}
+}
+
+func TestHub_NotifyAgents(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+
+	agent := &Client{hub: hub, send: make(chan []byte, 1), agentPaw: "test-agent"}
+	dashboard := &Client{hub: hub, send: make(chan []byte, 1)}
+	full := &Client{hub: hub, send: make(chan []byte), agentPaw: "full-agent"}
+	hub.handleRegister(agent)
+	hub.handleRegister(dashboard)
+	hub.handleRegister(full)
+
+	if n := hub.NotifyAgents([]byte("shutdown")); n != 1 {
+		t.Errorf("Expected 1 notified agent, got %d", n)
+	}
+	if msg := <-agent.send; string(msg) != "shutdown" {
+		t.Errorf("Unexpected message: %s", msg)
+	}
+	if len(dashboard.send) != 0 {
+		t.Error("Dashboard clients should not be notified")
+	}
+}
+
+func TestHub_PublishDashboardEvent(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+
+	agent := &Client{hub: hub, send: make(chan []byte, 1), agentPaw: "test-agent"}
+	admin := &Client{hub: hub, send: make(chan []byte, 1), dashboard: true, userID: "u1", role: "admin"}
+	viewer := &Client{hub: hub, send: make(chan []byte, 1), dashboard: true, userID: "u2", role: "viewer"}
+	full := &Client{hub: hub, send: make(chan []byte), dashboard: true, userID: "u3", role: "admin"}
+	for _, client := range []*Client{agent, admin, viewer, full} {
+		hub.handleRegister(client)
+	}
+
+	if n, err := hub.PublishDashboardEvent("all", nil, nil); err != nil || n != 2 {
+		t.Errorf("Expected 2 dashboards reached, got %d (%v)", n, err)
+	}
+	if len(agent.send) != 0 {
+		t.Error("Agents should not receive dashboard events")
+	}
+	if msg := <-admin.send; string(msg) != `{"id":1,"type":"all","payload":null}` {
+		t.Errorf("Unexpected message %s", msg)
+	}
+	<-viewer.send
+
+	n, _ := hub.PublishDashboardEvent("admins", nil, func(c *Client) bool { return c.Role() == "admin" })
+	if n != 1 || len(admin.send) != 1 || len(viewer.send) != 0 {
+		t.Errorf("Expected only the admin dashboard, got %d", n)
+	}
+}
+
+func TestHub_SubscribeDashboard(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	onlyAdmins := func(c *Client) bool { return c.Role() == "admin" }
+	for i := 0; i < dashboardHistory+10; i++ {
+		allow := onlyAdmins
+		if i%2 == 0 {
+			allow = nil
+		}
+		if _, err := hub.PublishDashboardEvent("event", i, allow); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	viewer := NewStreamClient(hub, "u1", "viewer", nil)
+	replay, complete := hub.SubscribeDashboard(viewer, dashboardHistory+4)
+	if !complete || len(replay) != 3 {
+		t.Errorf("Expected the 3 events for everyone after 1004, got %d (complete %v)", len(replay), complete)
+	}
+	if _, err := hub.PublishDashboardEvent("later", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(viewer.Messages()) != 1 {
+		t.Error("Expected the subscribed stream to receive new events")
+	}
+
+	admin := NewStreamClient(hub, "u2", "admin", nil)
+	if replay, complete := hub.SubscribeDashboard(admin, 5); complete || len(replay) != dashboardHistory {
+		t.Errorf("Expected a partial replay of the kept events, got %d (complete %v)", len(replay), complete)
+	}
+	if _, complete := hub.SubscribeDashboard(NewStreamClient(hub, "u3", "admin", nil), 5000); complete {
+		t.Error("Expected IDs ahead of the hub to be reported as incomplete")
+	}
+	if replay, complete := hub.SubscribeDashboard(NewStreamClient(hub, "u4", "admin", nil), 0); !complete || len(replay) != 0 {
+		t.Error("Expected new streams not to replay events")
+	}
+}
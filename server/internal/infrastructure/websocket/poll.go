@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// PollSessionTimeout is how long a long-poll session lives without a
+	// request from its agent, like a WebSocket without a pong
+	PollSessionTimeout = pongWait
+	// MaxPollWait caps how long a poll request is held open, below the
+	// session timeout
+	MaxPollWait = 30 * time.Second
+	// MaxPollMessageSize is the largest message an agent posts, as over WebSocket
+	MaxPollMessageSize = maxMessageSize
+)
+
+// NewPollClient creates a client for an agent polling over HTTP instead of
+// holding a WebSocket. Messages sent to it wait in its queue until polled.
+func NewPollClient(hub *Hub, logger *zap.Logger) *Client {
+	return NewClient(hub, nil, "", logger)
+}
+
+// Poll waits up to wait for messages sent to the client, then returns every
+// queued message. It returns false once the client was unregistered.
+func (c *Client) Poll(ctx context.Context, wait time.Duration) ([]json.RawMessage, bool) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	messages := []json.RawMessage{}
+	select {
+	case message, ok := <-c.send:
+		if !ok {
+			return nil, false
+		}
+		messages = append(messages, message)
+	case <-timer.C:
+		return messages, true
+	case <-ctx.Done():
+		return messages, true
+	}
+
+	for n := len(c.send); n > 0; n-- {
+		message, ok := <-c.send
+		if !ok {
+			break
+		}
+		messages = append(messages, message)
+	}
+	return messages, true
+}
+
+// pollSession is a long-poll client and the timer expiring it
+type pollSession struct {
+	client *Client
+	timer  *time.Timer
+}
+
+// PollSessions tracks the agents connected by HTTP long-polling. Their
+// clients are registered with the hub, so tasks and broadcasts reach them as
+// they reach WebSocket agents.
+type PollSessions struct {
+	hub      *Hub
+	logger   *zap.Logger
+	timeout  time.Duration
+	mu       sync.Mutex
+	sessions map[string]*pollSession
+}
+
+// NewPollSessions creates an empty long-poll session registry
+func NewPollSessions(hub *Hub, logger *zap.Logger) *PollSessions {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &PollSessions{
+		hub:      hub,
+		logger:   logger,
+		timeout:  PollSessionTimeout,
+		sessions: make(map[string]*pollSession),
+	}
+}
+
+// Open starts a session and registers its client with the hub
+func (s *PollSessions) Open() (string, *Client) {
+	id := uuid.New().String()
+	client := NewPollClient(s.hub, s.logger)
+	s.hub.Register(client)
+
+	s.mu.Lock()
+	s.sessions[id] = &pollSession{
+		client: client,
+		timer:  time.AfterFunc(s.timeout, func() { s.expire(id) }),
+	}
+	s.mu.Unlock()
+	return id, client
+}
+
+// Get returns the client of a session and extends the session
+func (s *PollSessions) Get(id string) (*Client, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	session.timer.Reset(s.timeout)
+	return session.client, true
+}
+
+// Close ends a session and unregisters its client
+func (s *PollSessions) Close(id string) {
+	s.mu.Lock()
+	session, ok := s.sessions[id]
+	if ok {
+		session.timer.Stop()
+		delete(s.sessions, id)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.hub.Unregister(session.client)
+	}
+}
+
+// expire closes a session its agent stopped polling
+func (s *PollSessions) expire(id string) {
+	s.logger.Info("Long-poll session expired", zap.String("session", id))
+	s.Close(id)
+}
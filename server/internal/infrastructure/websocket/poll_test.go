@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestClient_Poll(t *testing.T) {
+	client := NewPollClient(nil, zap.NewNop())
+
+	messages, open := client.Poll(context.Background(), 10*time.Millisecond)
+	if !open || len(messages) != 0 {
+		t.Fatalf("Expected an empty poll, got %d messages (open %v)", len(messages), open)
+	}
+
+	client.send <- []byte(`{"type":"a"}`)
+	client.send <- []byte(`{"type":"b"}`)
+	messages, _ = client.Poll(context.Background(), time.Second)
+	if len(messages) != 2 || string(messages[1]) != `{"type":"b"}` {
+		t.Fatalf("Expected both queued messages in order, got %q", messages)
+	}
+
+	close(client.send)
+	if _, open := client.Poll(context.Background(), time.Second); open {
+		t.Error("Expected a closed client to report it")
+	}
+}
+
+func TestPollSessions_Expire(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	go hub.Run()
+	sessions := NewPollSessions(hub, nil)
+	sessions.timeout = 50 * time.Millisecond
+
+	id, client := sessions.Open()
+	client.SetAgentPaw("poll-agent")
+	if got, ok := sessions.Get(id); !ok || got != client {
+		t.Fatal("Expected the session to be found")
+	}
+
+	// A session is dropped once its agent stops polling
+	time.Sleep(150 * time.Millisecond)
+	if _, ok := sessions.Get(id); ok {
+		t.Error("Expected the session to expire")
+	}
+	if _, open := client.Poll(context.Background(), time.Second); open {
+		t.Error("Expected the expired client to be unregistered")
+	}
+	if hub.IsAgentConnected("poll-agent") {
+		t.Error("Expected the agent to be disconnected")
+	}
+}
+
+func TestHub_UnregisterStaleConnection(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	go hub.Run()
+
+	stale := NewPollClient(hub, nil)
+	current := NewPollClient(hub, nil)
+	hub.Register(stale)
+	hub.Register(current)
+	stale.SetAgentPaw("agent")
+	current.SetAgentPaw("agent")
+
+	disconnected := make(chan string, 1)
+	hub.SetOnAgentDisconnect(func(paw string) { disconnected <- paw })
+	hub.Unregister(stale)
+	_ = hub.Ping(context.Background())
+
+	if !hub.IsAgentConnected("agent") {
+		t.Error("Expected the agent to keep its new connection")
+	}
+	select {
+	case paw := <-disconnected:
+		t.Errorf("Expected no disconnect callback, got %s", paw)
+	default:
+	}
+}